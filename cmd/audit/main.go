@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"exchange/internal/pkg/audit"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/database"
+)
+
+// 审计日志工具
+// 用法:
+//   go run cmd/audit/main.go verify [-env production] [-dir configs] [-from 1] [-expect 1024:hash] [-json]
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	var (
+		valid bool
+		err   error
+	)
+	switch os.Args[1] {
+	case "verify":
+		valid, err = runVerify(os.Args[2:])
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Printf("执行失败: %v\n", err)
+		os.Exit(1)
+	}
+	if !valid {
+		os.Exit(2)
+	}
+}
+
+// usage 打印使用说明
+func usage() {
+	fmt.Println("用法:")
+	fmt.Println("  audit verify [-env 环境] [-dir 配置目录] [-from 序号] [-expect 序号:哈希] [-json]")
+	fmt.Println("      校验审计日志哈希链，链完整时退出码为0，发现断链或检查点不一致时为2")
+}
+
+// runVerify 校验审计日志哈希链
+// -expect为之前记录在外部（如工单、对象存储）的检查点，用于发现链尾被截断或整条链被重建
+func runVerify(args []string) (bool, error) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	env := fs.String("env", config.GetEnv(), "运行环境")
+	dir := fs.String("dir", config.GetConfigDir(), "配置目录")
+	from := fs.Int64("from", 1, "起始序号，大于1时以该记录的prev_hash为起点")
+	expect := fs.String("expect", "", "检查点，格式为 序号:哈希")
+	jsonOutput := fs.Bool("json", false, "以JSON格式输出报告")
+	fs.Parse(args)
+
+	var checkpointSeq int64
+	var checkpointHash string
+	if *expect != "" {
+		seqText, hash, ok := strings.Cut(*expect, ":")
+		seq, err := strconv.ParseInt(seqText, 10, 64)
+		if !ok || err != nil || seq < 1 || hash == "" {
+			return false, fmt.Errorf("无效的检查点: %s", *expect)
+		}
+		checkpointSeq, checkpointHash = seq, strings.ToLower(hash)
+	}
+
+	cfg, err := config.LoadProfile(*dir, *env)
+	if err != nil {
+		return false, err
+	}
+	mongoService, err := database.NewMongoDBService(cfg)
+	if err != nil {
+		return false, err
+	}
+	defer mongoService.Close()
+
+	ctx := context.Background()
+	store := audit.NewStore(mongoService)
+	report, err := store.Verify(ctx, *from)
+	if err != nil {
+		return false, err
+	}
+
+	checkpointErr := ""
+	if checkpointSeq > 0 {
+		record, err := store.Get(ctx, checkpointSeq)
+		switch {
+		case err != nil:
+			checkpointErr = fmt.Sprintf("检查点记录 %d 不存在: %v", checkpointSeq, err)
+		case record.Hash != checkpointHash:
+			checkpointErr = fmt.Sprintf("检查点记录 %d 的哈希不一致: %s", checkpointSeq, record.Hash)
+		}
+	}
+
+	valid := report.Valid() && checkpointErr == ""
+	if *jsonOutput {
+		data, _ := json.MarshalIndent(map[string]interface{}{
+			"valid":      valid,
+			"report":     report,
+			"checkpoint": checkpointErr,
+		}, "", "  ")
+		fmt.Println(string(data))
+		return valid, nil
+	}
+
+	fmt.Printf("# 环境: %s\n", *env)
+	fmt.Printf("校验记录: %d（序号 %d - %d）\n", report.Checked, report.FromSeq, report.LastSeq)
+	fmt.Printf("链尾哈希: %s\n", report.HeadHash)
+	for _, failure := range report.Failures {
+		fmt.Printf("断链: 序号 %d，%s\n", failure.Seq, failure.Reason)
+	}
+	if checkpointErr != "" {
+		fmt.Println(checkpointErr)
+	}
+	if valid {
+		fmt.Println("结果: 哈希链完整")
+	} else {
+		fmt.Println("结果: 哈希链校验失败")
+	}
+	return valid, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"exchange/internal/pkg/config"
+)
+
+// 配置工具
+// 用法:
+//   go run cmd/config/main.go show [-env development] [-dir configs]
+//   go run cmd/config/main.go diff [-dir configs] development production
+//   go run cmd/config/main.go check [-env development] [-dir configs]
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "show":
+		err = runShow(os.Args[2:])
+	case "diff":
+		err = runDiff(os.Args[2:])
+	case "check":
+		err = runCheck(os.Args[2:])
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Printf("执行失败: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// usage 打印使用说明
+func usage() {
+	fmt.Println("用法:")
+	fmt.Println("  config show [-env 环境] [-dir 配置目录]    打印合并后的有效配置（敏感信息已脱敏）")
+	fmt.Println("  config diff [-dir 配置目录] 环境A 环境B    比较两个环境的有效配置")
+	fmt.Println("  config check [-env 环境] [-dir 配置目录]   检查未知及已废弃的配置项")
+}
+
+// runShow 打印指定环境合并后的有效配置
+func runShow(args []string) error {
+	fs := flag.NewFlagSet("show", flag.ExitOnError)
+	env := fs.String("env", config.GetEnv(), "运行环境")
+	dir := fs.String("dir", config.GetConfigDir(), "配置目录")
+	fs.Parse(args)
+
+	cfg, err := config.LoadProfile(*dir, *env)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cfg.Masked(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化配置失败: %w", err)
+	}
+
+	fmt.Printf("# 环境: %s\n", *env)
+	for _, file := range config.LayerFiles(*dir, *env) {
+		if _, err := os.Stat(file); err == nil {
+			fmt.Printf("# 配置层: %s\n", file)
+		}
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// runDiff 比较两个环境的有效配置
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	dir := fs.String("dir", config.GetConfigDir(), "配置目录")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		usage()
+		return fmt.Errorf("diff需要指定两个环境")
+	}
+	leftEnv, rightEnv := fs.Arg(0), fs.Arg(1)
+
+	left, err := config.LoadProfile(*dir, leftEnv)
+	if err != nil {
+		return fmt.Errorf("加载环境 %s 失败: %w", leftEnv, err)
+	}
+	right, err := config.LoadProfile(*dir, rightEnv)
+	if err != nil {
+		return fmt.Errorf("加载环境 %s 失败: %w", rightEnv, err)
+	}
+
+	diffs := config.Diff(left, right)
+	if len(diffs) == 0 {
+		fmt.Printf("环境 %s 与 %s 的有效配置一致\n", leftEnv, rightEnv)
+		return nil
+	}
+
+	fmt.Printf("--- %s\n+++ %s\n", leftEnv, rightEnv)
+	for _, d := range diffs {
+		fmt.Printf("%s:\n  - %v\n  + %v\n", d.Path, d.Left, d.Right)
+	}
+	return nil
+}
+
+// runCheck 检查指定环境配置层中的未知及废弃配置项
+func runCheck(args []string) error {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	env := fs.String("env", config.GetEnv(), "运行环境")
+	dir := fs.String("dir", config.GetConfigDir(), "配置目录")
+	fs.Parse(args)
+
+	report, err := config.CheckProfile(*dir, *env)
+	if err != nil {
+		return err
+	}
+
+	if !report.HasIssues() {
+		fmt.Printf("环境 %s 的配置检查通过\n", *env)
+		return nil
+	}
+
+	report.PrintWarnings()
+	return report.StrictError()
+}
@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"exchange/cmd/cron/task"
 	pkgCron "exchange/internal/pkg/cron"
+	appErrors "exchange/internal/pkg/errors"
 	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/loglevel"
 	"exchange/internal/pkg/services"
+	"exchange/internal/pkg/tracing"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 func main() {
@@ -29,6 +34,25 @@ func main() {
 		panic("初始化日志失败: " + err.Error())
 	}
 
+	// 任务失败和panic上报到错误跟踪服务
+	if err := appErrors.EnableReporting(cfg.ErrorTracking, "exchange-cron"); err != nil {
+		panic("初始化错误上报失败: " + err.Error())
+	}
+	defer appErrors.CloseReporting(5 * time.Second)
+
+	// 任务执行和数据库调用的span导出到OTLP Collector
+	if err := tracing.Enable(cfg.Tracing, "exchange-cron"); err != nil {
+		panic("初始化分布式追踪失败: " + err.Error())
+	}
+	defer tracing.Shutdown(5 * time.Second)
+
+	// 任务中使用的业务错误码与API服务共用错误目录
+	if cfg.ErrorCatalog.File != "" {
+		if _, err := appErrors.LoadCatalog(cfg.ErrorCatalog.File); err != nil {
+			panic("加载错误目录失败: " + err.Error())
+		}
+	}
+
 	appLogger.Info("启动分布式定时任务执行器", map[string]interface{}{
 		"version": "1.0.0",
 		"mode":    "worker",
@@ -44,6 +68,16 @@ func main() {
 		"addr": cfg.GetRedisAddr(),
 	})
 
+	// 加载管理后台设置的日志级别并订阅变更
+	logLevelSync := loglevel.NewSync(redisService)
+	if err := logLevelSync.Start(context.Background()); err != nil {
+		appLogger.Warn("日志级别同步启动失败", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else {
+		defer logLevelSync.Stop()
+	}
+
 	// 创建任务执行器
 	worker := pkgCron.NewWorker(redisService)
 
@@ -57,6 +91,27 @@ func main() {
 	// 注册日志清理任务
 	worker.RegisterTaskDailyAt(task.LogCleanupTask{}, "02:00") // 每天02:00执行日志清理
 
+	// 注册账户注销任务
+	worker.RegisterTaskEveryHours(task.AccountDeletionTask{}, 1) // 每小时执行冷静期已结束的注销申请
+
+	// 注册会话导出文件清理任务
+	worker.RegisterTaskEveryHours(task.ChatExportCleanupTask{}, 1) // 每小时删除已过期的导出文件
+
+	// 注册数据保留期清理任务
+	worker.RegisterTaskDailyAt(task.RetentionTask{}, "03:00") // 每天03:00按保留策略清理过期数据
+
+	// 注册聊天消息归档任务
+	worker.RegisterTaskDailyAt(task.MessageArchiveTask{}, "02:30") // 每天02:30归档旧消息（在保留期清理之前）
+
+	// 注册领域事件导出任务
+	worker.RegisterTaskDailyAt(task.EventExportTask{}, "00:30") // 每天00:30导出已结束日期的领域事件
+
+	// 注册离线推送发送任务
+	worker.RegisterTaskEverySeconds(task.PushDispatchTask{}, 5) // 每5秒发送推送队列中的新消息
+
+	// 注册未读数对账任务
+	worker.RegisterTaskEveryHours(task.UnreadReconcileTask{}, 1) // 每小时用MongoDB的统计结果修正缓存的未读数
+
 	// 启动任务执行器
 	worker.Start()
 
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"exchange/internal/middleware"
+	"exchange/internal/modules/admin"
+	"exchange/internal/pkg/audit"
 	"exchange/internal/pkg/cron"
 	"exchange/internal/pkg/logger"
 	"exchange/internal/pkg/services"
+	mysqlRepo "exchange/internal/repository/mysql"
 	"log"
 	"os"
 	"os/signal"
@@ -46,12 +50,42 @@ func main() {
 		"addr": cfg.GetRedisAddr(),
 	})
 
+	// 获取MySQL服务（管理员认证与审计日志）
+	mysqlService := globalServices.GetMySQL()
+	if mysqlService == nil {
+		log.Fatal("MySQL服务不可用")
+	}
+
+	// 复用Admin模块的认证逻辑：只有管理员可以查看，只有指定角色可以控制任务
+	adminModule := admin.NewModule(cfg, mysqlService, redisService, globalServices.GetMongoDB())
+	authMiddleware := adminModule.GetAuthMiddleware()
+
 	// 创建监控界面
 	monitor := cron.NewMonitor(redisService)
+	monitor.SetAuth(&cron.MonitorAuth{
+		Login: adminModule.GetAdminHandler().Login,
+		View: []gin.HandlerFunc{
+			authMiddleware.RequireAuth(),
+			authMiddleware.RequireAdmin(),
+		},
+		Control: []gin.HandlerFunc{
+			adminModule.GetAuditMiddleware().Record(), // 任务控制（包括角色不足被拒绝的请求）记录到管理员操作审计
+			authMiddleware.RequireRole(cfg.Monitor.ControlRoles...),
+		},
+	})
+	monitor.SetAuditLogRepository(mysqlRepo.NewAdminLogRepository(mysqlService.DB()))
+	monitor.SetLogReader(logger.NewLogReader(&cfg.Log))
+
+	// 任务控制审计事件同时写入审计日志哈希链
+	auditPipeline, err := audit.Enable(cfg.Audit, globalServices.GetMongoDB())
+	if err != nil {
+		log.Fatal("初始化审计日志失败:", err)
+	}
 
 	// 创建Web服务器
 	gin.SetMode(gin.ReleaseMode)
-	r := gin.Default()
+	r := gin.New()
+	r.Use(gin.Recovery(), middleware.RequestIDMiddleware(), middleware.AccessLogMiddleware(cfg.Log.Access))
 
 	// 加载HTML模板
 	r.LoadHTMLGlob("cmd/cron/monitor/templates/*.html")
@@ -61,7 +95,8 @@ func main() {
 
 	// 启动Web服务器
 	logger.Info("启动Web监控界面", map[string]interface{}{
-		"port": "8081",
+		"address":       cfg.Monitor.Address,
+		"control_roles": cfg.Monitor.ControlRoles,
 	})
 
 	// 捕获退出信号
@@ -70,7 +105,7 @@ func main() {
 
 	// 启动服务器
 	go func() {
-		if err := r.Run(":8081"); err != nil {
+		if err := r.Run(cfg.Monitor.Address); err != nil {
 			logger.Error("Web服务器启动失败", map[string]interface{}{
 				"error": err.Error(),
 			})
@@ -81,5 +116,8 @@ func main() {
 	<-sig
 
 	logger.Info("收到退出信号，正在停止监控界面...")
+	if auditPipeline != nil {
+		auditPipeline.Close()
+	}
 	logger.Info("监控界面已停止")
 }
@@ -0,0 +1,107 @@
+package task
+
+import (
+	"context"
+	"exchange/internal/modules/api/logic"
+	pkgCron "exchange/internal/pkg/cron"
+	"exchange/internal/pkg/i18n"
+	"exchange/internal/pkg/logger"
+	"exchange/internal/pkg/mailer"
+	"exchange/internal/pkg/notification"
+	"exchange/internal/pkg/retention"
+	"exchange/internal/pkg/services"
+	"exchange/internal/pkg/session"
+	"exchange/internal/pkg/tokenrevoke"
+	"exchange/internal/pkg/trading"
+	userRepo "exchange/internal/repository/mysql"
+	"fmt"
+	"time"
+)
+
+// AccountDeletionTask 账户注销任务
+type AccountDeletionTask struct{}
+
+// AccountDeletionTaskConfig 账户注销任务配置（configs 中的 tasks.AccountDeletionTask）
+type AccountDeletionTaskConfig struct {
+	BatchSize int `json:"batch_size"` // 每次最多处理的注销申请数量
+}
+
+// Validate 校验配置
+func (c *AccountDeletionTaskConfig) Validate() error {
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("batch_size必须大于0")
+	}
+	return nil
+}
+
+func (a AccountDeletionTask) Name() string {
+	return "AccountDeletionTask"
+}
+
+func (a AccountDeletionTask) Description() string {
+	return "账户注销任务，冷静期结束后校验并匿名化用户个人信息"
+}
+
+// Semantics 执行语义：实例宕机时需要补偿执行
+func (a AccountDeletionTask) Semantics() pkgCron.ExecutionSemantics {
+	return pkgCron.AtLeastOnce
+}
+
+// DefaultConfig 默认配置
+func (a AccountDeletionTask) DefaultConfig() interface{} {
+	return &AccountDeletionTaskConfig{
+		BatchSize: 100,
+	}
+}
+
+// Run 任务执行方法
+func (a AccountDeletionTask) Run(ctx context.Context, globalServices *services.GlobalServices) error {
+	if !globalServices.IsInitialized() {
+		return fmt.Errorf("全局服务未初始化")
+	}
+
+	mysqlService := globalServices.GetMySQL()
+	if mysqlService == nil {
+		return fmt.Errorf("MySQL服务不可用")
+	}
+	redisService := globalServices.GetRedis()
+	if redisService == nil {
+		return fmt.Errorf("Redis服务不可用")
+	}
+
+	taskConfig := a.DefaultConfig().(*AccountDeletionTaskConfig)
+	if tc, ok := pkgCron.GetTaskContext(ctx); ok {
+		if c, ok := tc.Config.(*AccountDeletionTaskConfig); ok {
+			taskConfig = c
+		}
+	}
+
+	sender, err := mailer.NewSenderFromConfig(globalServices.GetConfig())
+	if err != nil {
+		return fmt.Errorf("初始化邮件发送失败: %w", err)
+	}
+	renderer := notification.NewRenderer(userRepo.NewNotificationTemplateRepository(mysqlService.DB()), i18n.GetGlobalI18n().GetDefaultLanguage())
+	cfg := globalServices.GetConfig()
+	deletionLogic := logic.NewAPIAccountDeletionLogic(
+		cfg,
+		userRepo.NewUserRepository(mysqlService.DB()),
+		userRepo.NewAccountDeletionRepository(mysqlService.DB()),
+		session.NewStore(redisService, time.Duration(cfg.JWT.RefreshTokenDays)*24*time.Hour),
+		tokenrevoke.NewStore(redisService, cfg.JWT.MaxTokenLifetime()),
+		notification.NewTemplateNotifier(notification.NewEmailNotifier(notification.NewLogNotifier(), sender), renderer),
+	)
+	deletionLogic.AddDeletionGuard(retention.NewLegalHoldGuard(userRepo.NewRetentionRepository(mysqlService.DB())))
+	deletionLogic.AddDeletionGuard(trading.NewDeletionGuard(nil)) // 尚未接入订单和余额数据，到期的申请一律驳回
+
+	completed, err := deletionLogic.ProcessDueDeletions(ctx, taskConfig.BatchSize)
+	if err != nil {
+		return fmt.Errorf("执行账户注销失败: %w", err)
+	}
+
+	logger.Info("账户注销任务执行完成", map[string]interface{}{
+		"task_name": a.Name(),
+		"completed": completed,
+	})
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package task
+
+import (
+	"context"
+	"exchange/internal/modules/api/logic"
+	pkgCron "exchange/internal/pkg/cron"
+	"exchange/internal/pkg/export"
+	"exchange/internal/pkg/logger"
+	"exchange/internal/pkg/secrets"
+	"exchange/internal/pkg/services"
+	mongoRepo "exchange/internal/repository/mongodb"
+	userRepo "exchange/internal/repository/mysql"
+	"fmt"
+)
+
+// ChatExportCleanupTask 会话导出文件清理任务
+type ChatExportCleanupTask struct{}
+
+// ChatExportCleanupTaskConfig 会话导出文件清理任务配置（configs 中的 tasks.ChatExportCleanupTask）
+type ChatExportCleanupTaskConfig struct {
+	BatchSize int `json:"batch_size"` // 每次最多清理的导出文件数量
+}
+
+// Validate 校验配置
+func (c *ChatExportCleanupTaskConfig) Validate() error {
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("batch_size必须大于0")
+	}
+	return nil
+}
+
+func (t ChatExportCleanupTask) Name() string {
+	return "ChatExportCleanupTask"
+}
+
+func (t ChatExportCleanupTask) Description() string {
+	return "会话导出文件清理任务，删除已过期的导出文件"
+}
+
+// Semantics 执行语义：删除文件和标记过期都是幂等的，漏执行时下次补上即可
+func (t ChatExportCleanupTask) Semantics() pkgCron.ExecutionSemantics {
+	return pkgCron.AtLeastOnce
+}
+
+// DefaultConfig 默认配置
+func (t ChatExportCleanupTask) DefaultConfig() interface{} {
+	return &ChatExportCleanupTaskConfig{
+		BatchSize: 200,
+	}
+}
+
+// Run 任务执行方法
+func (t ChatExportCleanupTask) Run(ctx context.Context, globalServices *services.GlobalServices) error {
+	if !globalServices.IsInitialized() {
+		return fmt.Errorf("全局服务未初始化")
+	}
+
+	mysqlService := globalServices.GetMySQL()
+	if mysqlService == nil {
+		return fmt.Errorf("MySQL服务不可用")
+	}
+
+	taskConfig := t.DefaultConfig().(*ChatExportCleanupTaskConfig)
+	if tc, ok := pkgCron.GetTaskContext(ctx); ok {
+		if c, ok := tc.Config.(*ChatExportCleanupTaskConfig); ok {
+			taskConfig = c
+		}
+	}
+
+	cfg := globalServices.GetConfig()
+	storage, err := export.NewLocalStorage(cfg.Export.Dir)
+	if err != nil {
+		return err
+	}
+	provider, err := secrets.NewProvider(cfg.Secrets)
+	if err != nil {
+		return fmt.Errorf("密钥提供者初始化失败: %w", err)
+	}
+
+	exportLogic := logic.NewAPIChatExportLogic(
+		cfg,
+		userRepo.NewUserRepository(mysqlService.DB()),
+		userRepo.NewChatExportRepository(mysqlService.DB()),
+		mongoRepo.NewMessageRepository(globalServices.GetMongoDB()),
+		storage,
+		export.NewLinkSigner(provider),
+	)
+
+	cleaned, err := exportLogic.CleanupExpired(ctx, taskConfig.BatchSize)
+	if err != nil {
+		return fmt.Errorf("清理会话导出文件失败: %w", err)
+	}
+
+	logger.Info("会话导出文件清理任务执行完成", map[string]interface{}{
+		"task_name": t.Name(),
+		"cleaned":   cleaned,
+	})
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"exchange/internal/pkg/clock"
+	pkgCron "exchange/internal/pkg/cron"
+	"exchange/internal/pkg/eventlog"
+	"exchange/internal/pkg/export"
+	"exchange/internal/pkg/logger"
+	"exchange/internal/pkg/services"
+	"fmt"
+	"time"
+)
+
+// EventExportTask 领域事件导出任务
+type EventExportTask struct{}
+
+// EventExportTaskConfig 领域事件导出任务配置（configs 中的 tasks.EventExportTask）
+type EventExportTaskConfig struct {
+	LookbackDays   int  `json:"lookback_days"`    // 检查最近多少天（不含当天）的分区，补导出漏执行的日期
+	FileMaxRecords int  `json:"file_max_records"` // 单个导出文件的最大记录数
+	Overwrite      bool `json:"overwrite"`        // 是否重新导出已有manifest的分区（用于补录后重放）
+}
+
+// Validate 校验配置
+func (c *EventExportTaskConfig) Validate() error {
+	if c.LookbackDays <= 0 {
+		return fmt.Errorf("lookback_days必须大于0")
+	}
+	if c.FileMaxRecords <= 0 {
+		return fmt.Errorf("file_max_records必须大于0")
+	}
+	return nil
+}
+
+func (t EventExportTask) Name() string {
+	return "EventExportTask"
+}
+
+func (t EventExportTask) Description() string {
+	return "领域事件导出任务，按UTC日期分区导出事件和manifest供数据仓库加载"
+}
+
+// Semantics 执行语义：未导出的分区在下次执行时补上，重复导出会替换整个分区
+func (t EventExportTask) Semantics() pkgCron.ExecutionSemantics {
+	return pkgCron.AtLeastOnce
+}
+
+// DefaultConfig 默认配置
+func (t EventExportTask) DefaultConfig() interface{} {
+	return &EventExportTaskConfig{
+		LookbackDays:   3,
+		FileMaxRecords: 100000,
+		Overwrite:      false,
+	}
+}
+
+// Run 任务执行方法
+func (t EventExportTask) Run(ctx context.Context, globalServices *services.GlobalServices) error {
+	if !globalServices.IsInitialized() {
+		return fmt.Errorf("全局服务未初始化")
+	}
+
+	mongoService := globalServices.GetMongoDB()
+	if mongoService == nil {
+		return fmt.Errorf("MongoDB服务不可用")
+	}
+
+	taskConfig := t.DefaultConfig().(*EventExportTaskConfig)
+	if tc, ok := pkgCron.GetTaskContext(ctx); ok {
+		if c, ok := tc.Config.(*EventExportTaskConfig); ok {
+			taskConfig = c
+		}
+	}
+
+	cfg := globalServices.GetConfig()
+	storage, err := export.NewLocalStorage(cfg.EventLog.ExportDir)
+	if err != nil {
+		return err
+	}
+	exporter := eventlog.NewExporter(eventlog.NewStore(mongoService), storage, taskConfig.FileMaxRecords)
+
+	// 只导出已结束的UTC日期，从最早的日期开始
+	today := clock.Now().UTC().Truncate(24 * time.Hour)
+	exported := 0
+	for i := taskConfig.LookbackDays; i >= 1; i-- {
+		date := today.AddDate(0, 0, -i)
+		if !taskConfig.Overwrite {
+			_, err := exporter.Manifest(date)
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, eventlog.ErrManifestNotFound) {
+				return fmt.Errorf("读取事件导出manifest失败: %w", err)
+			}
+		}
+
+		manifest, err := exporter.Export(ctx, date)
+		if err != nil {
+			return fmt.Errorf("导出%s的领域事件失败: %w", date.Format(eventlog.DateLayout), err)
+		}
+		exported++
+
+		logger.Info("领域事件分区导出完成", map[string]interface{}{
+			"task_name": t.Name(),
+			"date":      manifest.Date,
+			"records":   manifest.Records,
+			"files":     len(manifest.Files),
+		})
+	}
+
+	logger.Info("领域事件导出任务执行完成", map[string]interface{}{
+		"task_name": t.Name(),
+		"exported":  exported,
+	})
+
+	return nil
+}
@@ -3,6 +3,7 @@ package task
 import (
 	"context"
 	userModel "exchange/internal/models/mysql"
+	pkgCron "exchange/internal/pkg/cron"
 	"exchange/internal/pkg/services"
 	userRepo "exchange/internal/repository/mysql"
 	"exchange/internal/utils"
@@ -13,6 +14,26 @@ import (
 // ExampleTask 示例任务
 type ExampleTask struct{}
 
+// ExampleTaskConfig 示例任务配置（configs 中的 tasks.ExampleTask）
+type ExampleTaskConfig struct {
+	UserID uint `json:"user_id"` // 需要封禁的用户ID
+}
+
+// Validate 校验配置
+func (c *ExampleTaskConfig) Validate() error {
+	if c.UserID == 0 {
+		return fmt.Errorf("user_id必须大于0")
+	}
+	return nil
+}
+
+// DefaultConfig 默认配置
+func (e ExampleTask) DefaultConfig() interface{} {
+	return &ExampleTaskConfig{
+		UserID: 1,
+	}
+}
+
 func (e ExampleTask) Name() string {
 	return "ExampleTask"
 }
@@ -41,11 +62,19 @@ func (e ExampleTask) Run(ctx context.Context, globalServices *services.GlobalSer
 		return fmt.Errorf("MySQL服务不可用")
 	}
 
+	// 获取任务配置
+	taskConfig := e.DefaultConfig().(*ExampleTaskConfig)
+	if tc, ok := pkgCron.GetTaskContext(ctx); ok {
+		if c, ok := tc.Config.(*ExampleTaskConfig); ok {
+			taskConfig = c
+		}
+	}
+
 	// 按需创建用户Repository
 	userRepository := userRepo.NewUserRepository(mysqlService.DB())
 
-	// 获取用户ID为1的用户
-	user, err := userRepository.GetByID(ctx, 1)
+	// 获取配置中指定的用户
+	user, err := userRepository.GetByID(ctx, taskConfig.UserID)
 	if err != nil {
 		return fmt.Errorf("获取用户失败: %w", err)
 	}
@@ -59,15 +88,15 @@ func (e ExampleTask) Run(ctx context.Context, globalServices *services.GlobalSer
 	}
 
 	// 更新用户状态为banned
-	err = userRepository.UpdateStatus(ctx, 1, userModel.UserStatusBanned)
+	err = userRepository.UpdateStatus(ctx, taskConfig.UserID, userModel.UserStatusBanned)
 	if err != nil {
 		return fmt.Errorf("更新用户状态失败: %w", err)
 	}
 
-	fmt.Println("✅ 成功将用户ID为1的用户状态更新为banned")
+	fmt.Printf("✅ 成功将用户ID为%d的用户状态更新为banned\n", taskConfig.UserID)
 
 	// 验证更新结果
-	updatedUser, err := userRepository.GetByID(ctx, 1)
+	updatedUser, err := userRepository.GetByID(ctx, taskConfig.UserID)
 	if err != nil {
 		return fmt.Errorf("验证更新结果失败: %w", err)
 	}
@@ -2,6 +2,7 @@ package task
 
 import (
 	"context"
+	pkgCron "exchange/internal/pkg/cron"
 	"exchange/internal/pkg/logger"
 	"exchange/internal/pkg/services"
 	"fmt"
@@ -11,6 +12,23 @@ import (
 // LogCleanupTask 日志清理任务
 type LogCleanupTask struct{}
 
+// LogCleanupTaskConfig 日志清理任务配置（configs 中的 tasks.LogCleanupTask）
+type LogCleanupTaskConfig struct {
+	DryRun bool `json:"dry_run"` // 试运行，只统计不删除
+}
+
+// Semantics 执行语义：每天只调度一次，实例宕机时需要补偿执行
+func (l LogCleanupTask) Semantics() pkgCron.ExecutionSemantics {
+	return pkgCron.AtLeastOnce
+}
+
+// DefaultConfig 默认配置
+func (l LogCleanupTask) DefaultConfig() interface{} {
+	return &LogCleanupTaskConfig{
+		DryRun: false,
+	}
+}
+
 func (l LogCleanupTask) Name() string {
 	return "LogCleanupTask"
 }
@@ -55,9 +73,17 @@ func (l LogCleanupTask) Run(ctx context.Context, globalServices *services.Global
 		logger.Info("清理前的日志统计", statsBefore)
 	}
 
+	// 获取任务配置
+	taskConfig := &LogCleanupTaskConfig{}
+	if tc, ok := pkgCron.GetTaskContext(ctx); ok {
+		if c, ok := tc.Config.(*LogCleanupTaskConfig); ok {
+			taskConfig = c
+		}
+	}
+
 	// 执行清理
 	startTime := time.Now()
-	if err := logger.ForceCleanup(false); err != nil {
+	if err := logger.ForceCleanup(taskConfig.DryRun); err != nil {
 		logger.Error("日志清理失败", map[string]interface{}{
 			"error": err.Error(),
 		})
@@ -99,6 +125,7 @@ func (l LogCleanupTask) Run(ctx context.Context, globalServices *services.Global
 		"log_dir":         cfg.Log.LogDir,
 		"max_age":         cfg.Log.MaxAge,
 		"max_backups":     cfg.Log.MaxBackups,
+		"dry_run":         taskConfig.DryRun,
 	})
 
 	return nil
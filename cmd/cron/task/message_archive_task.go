@@ -0,0 +1,90 @@
+package task
+
+import (
+	"context"
+	pkgCron "exchange/internal/pkg/cron"
+	"exchange/internal/pkg/logger"
+	"exchange/internal/pkg/retention"
+	"exchange/internal/pkg/services"
+	mongoRepo "exchange/internal/repository/mongodb"
+	mysqlRepo "exchange/internal/repository/mysql"
+	"fmt"
+)
+
+// MessageArchiveTask 聊天消息归档任务
+type MessageArchiveTask struct{}
+
+// MessageArchiveTaskConfig 聊天消息归档任务配置（configs 中的 tasks.MessageArchiveTask）
+type MessageArchiveTaskConfig struct {
+	DryRun bool `json:"dry_run"` // 演练模式，只统计待归档的消息数，结果同样写入执行记录
+}
+
+func (t MessageArchiveTask) Name() string {
+	return "MessageArchiveTask"
+}
+
+func (t MessageArchiveTask) Description() string {
+	return "聊天消息归档任务，按retention.archive配置和会话的归档策略将旧消息移到冷集合或对象存储"
+}
+
+// Semantics 执行语义：消息写入归档目标后才从热集合删除，重复执行不产生重复数据，实例宕机时需要补偿执行
+func (t MessageArchiveTask) Semantics() pkgCron.ExecutionSemantics {
+	return pkgCron.AtLeastOnce
+}
+
+// DefaultConfig 默认配置
+func (t MessageArchiveTask) DefaultConfig() interface{} {
+	return &MessageArchiveTaskConfig{
+		DryRun: false,
+	}
+}
+
+// Run 任务执行方法
+func (t MessageArchiveTask) Run(ctx context.Context, globalServices *services.GlobalServices) error {
+	if !globalServices.IsInitialized() {
+		return fmt.Errorf("全局服务未初始化")
+	}
+
+	cfg := globalServices.GetConfig()
+	if !cfg.Retention.Archive.Enabled {
+		return nil
+	}
+
+	mysqlService := globalServices.GetMySQL()
+	if mysqlService == nil {
+		return fmt.Errorf("MySQL服务不可用")
+	}
+	mongoService := globalServices.GetMongoDB()
+	if mongoService == nil {
+		return fmt.Errorf("MongoDB服务不可用")
+	}
+
+	taskConfig := t.DefaultConfig().(*MessageArchiveTaskConfig)
+	if tc, ok := pkgCron.GetTaskContext(ctx); ok {
+		if c, ok := tc.Config.(*MessageArchiveTaskConfig); ok {
+			taskConfig = c
+		}
+	}
+
+	messages := mongoRepo.NewMessageArchiveRepository(mongoService)
+	sink, err := retention.NewArchiveSink(cfg, messages)
+	if err != nil {
+		return fmt.Errorf("创建消息归档目标失败: %w", err)
+	}
+
+	archiver := retention.NewArchiver(cfg.Retention.Archive, mysqlRepo.NewRetentionRepository(mysqlService.DB()), messages, sink)
+	run, err := archiver.Run(ctx, taskConfig.DryRun)
+	logger.Info("消息归档结果", map[string]interface{}{
+		"task_name": t.Name(),
+		"target":    run.Target,
+		"dry_run":   run.DryRun,
+		"status":    run.Status,
+		"matched":   run.Matched,
+		"archived":  run.Purged,
+	})
+	if err != nil {
+		return fmt.Errorf("执行消息归档失败: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package task
+
+import (
+	"context"
+	"exchange/internal/modules/api/logic"
+	wsLogic "exchange/internal/modules/websocket/logic"
+	pkgCron "exchange/internal/pkg/cron"
+	"exchange/internal/pkg/logger"
+	"exchange/internal/pkg/push"
+	"exchange/internal/pkg/services"
+	mongoRepo "exchange/internal/repository/mongodb"
+	userRepo "exchange/internal/repository/mysql"
+	"fmt"
+	"sync"
+)
+
+// PushDispatchTask 离线推送发送任务
+type PushDispatchTask struct{}
+
+// PushDispatchTaskConfig 离线推送发送任务配置（configs 中的 tasks.PushDispatchTask）
+type PushDispatchTaskConfig struct {
+	BatchSize int `json:"batch_size"` // 每次最多发送的推送任务数，剩余的在下次执行时发送
+}
+
+// Validate 校验配置
+func (c *PushDispatchTaskConfig) Validate() error {
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("batch_size必须大于0")
+	}
+	return nil
+}
+
+// 推送业务逻辑在首次执行时创建并复用，FCM访问token和APNs provider token在多次执行之间缓存
+var (
+	pushLogicOnce sync.Once
+	pushLogic     logic.PushLogic
+	pushLogicErr  error
+)
+
+func (t PushDispatchTask) Name() string {
+	return "PushDispatchTask"
+}
+
+func (t PushDispatchTask) Description() string {
+	return "离线推送发送任务，按用户的通知设置和免打扰时段将推送队列中的新消息发送到FCM和APNs"
+}
+
+// Semantics 执行语义：任务取出后才从队列删除，漏执行时留在队列中由下次执行发送
+func (t PushDispatchTask) Semantics() pkgCron.ExecutionSemantics {
+	return pkgCron.AtMostOnce
+}
+
+// DefaultConfig 默认配置
+func (t PushDispatchTask) DefaultConfig() interface{} {
+	return &PushDispatchTaskConfig{
+		BatchSize: 500,
+	}
+}
+
+// Run 任务执行方法
+func (t PushDispatchTask) Run(ctx context.Context, globalServices *services.GlobalServices) error {
+	if !globalServices.IsInitialized() {
+		return fmt.Errorf("全局服务未初始化")
+	}
+
+	cfg := globalServices.GetConfig()
+	if !cfg.Push.Enabled {
+		return nil
+	}
+
+	mysqlService := globalServices.GetMySQL()
+	if mysqlService == nil {
+		return fmt.Errorf("MySQL服务不可用")
+	}
+	redisService := globalServices.GetRedis()
+	if redisService == nil {
+		return fmt.Errorf("Redis服务不可用")
+	}
+
+	taskConfig := t.DefaultConfig().(*PushDispatchTaskConfig)
+	if tc, ok := pkgCron.GetTaskContext(ctx); ok {
+		if c, ok := tc.Config.(*PushDispatchTaskConfig); ok {
+			taskConfig = c
+		}
+	}
+
+	pushLogicOnce.Do(func() {
+		router, err := push.NewRouterFromConfig(cfg)
+		if err != nil {
+			pushLogicErr = err
+			return
+		}
+		pushLogic = logic.NewAPIPushLogic(
+			cfg,
+			router,
+			push.NewQueue(redisService),
+			wsLogic.NewRedisPresence(redisService, cfg.WebSocket),
+			userRepo.NewUserRepository(mysqlService.DB()),
+			mongoRepo.NewGroupRepository(globalServices.GetMongoDB()),
+			userRepo.NewPushDeviceRepository(mysqlService.DB()),
+			userRepo.NewNotificationPreferenceRepository(mysqlService.DB()),
+		)
+	})
+	if pushLogicErr != nil {
+		return fmt.Errorf("离线推送初始化失败: %w", pushLogicErr)
+	}
+
+	result, err := pushLogic.Dispatch(ctx, taskConfig.BatchSize)
+	if err != nil {
+		return fmt.Errorf("发送离线推送失败: %w", err)
+	}
+	if result.Jobs == 0 {
+		return nil
+	}
+
+	logger.Info("离线推送发送任务执行完成", map[string]interface{}{
+		"task_name": t.Name(),
+		"jobs":      result.Jobs,
+		"sent":      result.Sent,
+		"skipped":   result.Skipped,
+		"retried":   result.Retried,
+		"dropped":   result.Dropped,
+	})
+
+	return nil
+}
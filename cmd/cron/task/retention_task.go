@@ -0,0 +1,85 @@
+package task
+
+import (
+	"context"
+	pkgCron "exchange/internal/pkg/cron"
+	"exchange/internal/pkg/logger"
+	"exchange/internal/pkg/retention"
+	"exchange/internal/pkg/services"
+	mysqlRepo "exchange/internal/repository/mysql"
+	"fmt"
+)
+
+// RetentionTask 数据保留期清理任务
+type RetentionTask struct{}
+
+// RetentionTaskConfig 数据保留期清理任务配置（configs 中的 tasks.RetentionTask）
+type RetentionTaskConfig struct {
+	DryRun bool `json:"dry_run"` // 演练模式，只统计不删除，结果同样写入合规报告
+}
+
+func (r RetentionTask) Name() string {
+	return "RetentionTask"
+}
+
+func (r RetentionTask) Description() string {
+	return "数据保留期清理任务，按retention配置清理MySQL、MongoDB和Redis中的过期数据，跳过法律保全用户"
+}
+
+// Semantics 执行语义：清理可重复执行，实例宕机时需要补偿执行
+func (r RetentionTask) Semantics() pkgCron.ExecutionSemantics {
+	return pkgCron.AtLeastOnce
+}
+
+// DefaultConfig 默认配置
+func (r RetentionTask) DefaultConfig() interface{} {
+	return &RetentionTaskConfig{
+		DryRun: false,
+	}
+}
+
+// Run 任务执行方法
+func (r RetentionTask) Run(ctx context.Context, globalServices *services.GlobalServices) error {
+	if !globalServices.IsInitialized() {
+		return fmt.Errorf("全局服务未初始化")
+	}
+
+	mysqlService := globalServices.GetMySQL()
+	if mysqlService == nil {
+		return fmt.Errorf("MySQL服务不可用")
+	}
+
+	taskConfig := r.DefaultConfig().(*RetentionTaskConfig)
+	if tc, ok := pkgCron.GetTaskContext(ctx); ok {
+		if c, ok := tc.Config.(*RetentionTaskConfig); ok {
+			taskConfig = c
+		}
+	}
+
+	engine := retention.NewEngine(
+		globalServices.GetConfig().Retention,
+		mysqlRepo.NewRetentionRepository(mysqlService.DB()),
+		retention.DefaultTargets(mysqlService, globalServices.GetMongoDB(), globalServices.GetRedis())...,
+	)
+
+	report, err := engine.Run(ctx, taskConfig.DryRun)
+	if report != nil {
+		for _, run := range report.Runs {
+			logger.Info("数据保留清理结果", map[string]interface{}{
+				"task_name": r.Name(),
+				"category":  run.Category,
+				"store":     run.Store,
+				"target":    run.Target,
+				"dry_run":   run.DryRun,
+				"status":    run.Status,
+				"matched":   run.Matched,
+				"purged":    run.Purged,
+			})
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("执行数据保留清理失败: %w", err)
+	}
+
+	return nil
+}
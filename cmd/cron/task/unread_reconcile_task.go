@@ -0,0 +1,115 @@
+package task
+
+import (
+	"context"
+	"errors"
+	pkgCron "exchange/internal/pkg/cron"
+	"exchange/internal/pkg/logger"
+	"exchange/internal/pkg/services"
+	"exchange/internal/repository/cached"
+	mongoRepo "exchange/internal/repository/mongodb"
+	"fmt"
+)
+
+// errReconcileLimit 本次对账的用户数已达上限
+var errReconcileLimit = errors.New("reconcile limit reached")
+
+// UnreadReconcileTask 未读数对账任务
+type UnreadReconcileTask struct{}
+
+// UnreadReconcileTaskConfig 未读数对账任务配置（configs 中的 tasks.UnreadReconcileTask）
+type UnreadReconcileTaskConfig struct {
+	MaxUsers int `json:"max_users"` // 每次最多对账的用户数，缓存过期后会重新统计，未覆盖的用户不影响正确性
+}
+
+// Validate 校验配置
+func (c *UnreadReconcileTaskConfig) Validate() error {
+	if c.MaxUsers <= 0 {
+		return fmt.Errorf("max_users必须大于0")
+	}
+	return nil
+}
+
+func (t UnreadReconcileTask) Name() string {
+	return "UnreadReconcileTask"
+}
+
+func (t UnreadReconcileTask) Description() string {
+	return "未读数对账任务，用MongoDB的统计结果修正Redis中缓存的单聊未读数"
+}
+
+// Semantics 执行语义：对账可以重复执行，漏执行时由下次执行修正
+func (t UnreadReconcileTask) Semantics() pkgCron.ExecutionSemantics {
+	return pkgCron.AtMostOnce
+}
+
+// DefaultConfig 默认配置
+func (t UnreadReconcileTask) DefaultConfig() interface{} {
+	return &UnreadReconcileTaskConfig{
+		MaxUsers: 10000,
+	}
+}
+
+// Run 任务执行方法
+func (t UnreadReconcileTask) Run(ctx context.Context, globalServices *services.GlobalServices) error {
+	if !globalServices.IsInitialized() {
+		return fmt.Errorf("全局服务未初始化")
+	}
+
+	mongoService := globalServices.GetMongoDB()
+	if mongoService == nil {
+		return fmt.Errorf("MongoDB服务不可用")
+	}
+	redisService := globalServices.GetRedis()
+	if redisService == nil {
+		return fmt.Errorf("Redis服务不可用")
+	}
+
+	taskConfig := t.DefaultConfig().(*UnreadReconcileTaskConfig)
+	if tc, ok := pkgCron.GetTaskContext(ctx); ok {
+		if c, ok := tc.Config.(*UnreadReconcileTaskConfig); ok {
+			taskConfig = c
+		}
+	}
+
+	repo := cached.NewCachedMessageRepository(mongoRepo.NewMessageRepository(mongoService), redisService, globalServices.GetConfig().Chat)
+
+	var checked, corrected, failed int
+	err := repo.ForEachUnreadUser(ctx, func(ctx context.Context, userID string) error {
+		if checked >= taskConfig.MaxUsers {
+			return errReconcileLimit
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		checked++
+
+		drifted, err := repo.ReconcileUnread(ctx, userID)
+		if err != nil {
+			// 单个用户对账失败不影响其他用户
+			failed++
+			logger.Warn("未读数对账失败", map[string]interface{}{
+				"task_name": t.Name(),
+				"user_id":   userID,
+				"error":     err.Error(),
+			})
+			return nil
+		}
+		if drifted {
+			corrected++
+		}
+		return nil
+	})
+
+	logger.Info("未读数对账结果", map[string]interface{}{
+		"task_name": t.Name(),
+		"checked":   checked,
+		"corrected": corrected,
+		"failed":    failed,
+	})
+	if err != nil && !errors.Is(err, errReconcileLimit) {
+		return fmt.Errorf("遍历未读数缓存失败: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/modules/admin/logic"
+	"exchange/internal/pkg/i18n"
+	"exchange/internal/pkg/mailer"
+	"exchange/internal/pkg/notification"
+	"exchange/internal/pkg/services"
+	"exchange/internal/repository"
+	mysqlRepo "exchange/internal/repository/mysql"
+)
+
+// 用户批量导入工具
+// 用法:
+//   go run cmd/import_users/main.go -file users.csv -admin-id 1 [-mode password_hash|invite] [-dry-run]
+//
+// CSV第一行为表头，支持的列：username、email、password_hash、role、status
+// 导入任务与管理后台上传的导入任务记录在同一张表中，可在后台查询
+
+func main() {
+	file := flag.String("file", "", "CSV文件路径")
+	mode := flag.String("mode", string(mysql.UserImportModePasswordHash), "导入方式：password_hash/invite")
+	dryRun := flag.Bool("dry-run", false, "只校验不写入")
+	adminID := flag.Uint("admin-id", 0, "执行导入的管理员ID")
+	flag.Parse()
+
+	if err := run(*file, mysql.UserImportMode(*mode), *dryRun, *adminID); err != nil {
+		fmt.Printf("导入失败: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run 解析CSV并同步执行导入，打印导入报告
+func run(path string, mode mysql.UserImportMode, dryRun bool, adminID uint) error {
+	if path == "" {
+		return fmt.Errorf("请通过-file指定CSV文件")
+	}
+	if adminID == 0 {
+		return fmt.Errorf("请通过-admin-id指定执行导入的管理员ID")
+	}
+	if mode != mysql.UserImportModePasswordHash && mode != mysql.UserImportModeInvite {
+		return fmt.Errorf("导入方式只能为password_hash或invite")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开CSV文件失败: %w", err)
+	}
+	defer f.Close()
+
+	rows, err := logic.ParseUserImportCSV(f, mode)
+	if err != nil {
+		return err
+	}
+
+	globalServices := services.GetGlobalServices()
+	if err := globalServices.Init(); err != nil {
+		return fmt.Errorf("初始化全局服务失败: %w", err)
+	}
+	defer globalServices.Close()
+
+	mysqlService := globalServices.GetMySQL()
+	if mysqlService == nil {
+		return fmt.Errorf("MySQL服务不可用")
+	}
+
+	sender, err := mailer.NewSenderFromConfig(globalServices.GetConfig())
+	if err != nil {
+		return fmt.Errorf("初始化邮件发送失败: %w", err)
+	}
+
+	db := mysqlService.DB()
+	renderer := notification.NewRenderer(mysqlRepo.NewNotificationTemplateRepository(db), i18n.GetGlobalI18n().GetDefaultLanguage())
+	importLogic := logic.NewUserImportLogic(
+		globalServices.GetConfig(),
+		mysqlRepo.NewUserRepository(db),
+		mysqlRepo.NewUserImportRepository(db),
+		repository.NewRedisCacheRepository(globalServices.GetRedis()),
+		notification.NewTemplateNotifier(notification.NewEmailNotifier(notification.NewLogNotifier(), sender), renderer),
+	)
+
+	job, err := importLogic.RunImport(context.Background(), adminID, filepath.Base(path), mode, dryRun, rows)
+	if err != nil {
+		return err
+	}
+
+	printReport(job)
+
+	if job.Status == mysql.UserImportStatusFailed {
+		return fmt.Errorf("%s", job.Error)
+	}
+	return nil
+}
+
+// printReport 打印导入报告
+func printReport(job *mysql.UserImportJob) {
+	fmt.Printf("导入任务: #%d (%s)\n", job.ID, job.Status)
+	fmt.Printf("导入方式: %s  演练: %v\n", job.Mode, job.DryRun)
+	fmt.Printf("总行数: %d  校验通过: %d  已导入: %d  失败: %d\n", job.TotalRows, job.ValidRows, job.ImportedRows, job.FailedRows)
+
+	rowErrors, err := job.GetRowErrors()
+	if err != nil {
+		fmt.Printf("读取逐行错误失败: %v\n", err)
+		return
+	}
+
+	for _, rowError := range rowErrors {
+		fmt.Printf("  第%d行 %s <%s>:\n", rowError.Row, rowError.Username, rowError.Email)
+		for _, msg := range rowError.Errors {
+			fmt.Printf("    - %s\n", msg)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/selftest"
+)
+
+// 外部依赖自检工具，任一检查失败时以非零状态退出，供部署流水线和值班人员使用
+// 用法:
+//   go run cmd/selftest/main.go [-env production] [-dir configs] [-only mysql,redis] [-timeout 10s] [-json]
+
+func main() {
+	env := flag.String("env", config.GetEnv(), "运行环境")
+	dir := flag.String("dir", config.GetConfigDir(), "配置目录")
+	only := flag.String("only", "", "只执行指定的检查项，逗号分隔")
+	timeout := flag.Duration("timeout", 10*time.Second, "单项检查超时时间")
+	jsonOutput := flag.Bool("json", false, "以JSON格式输出结果")
+	list := flag.Bool("list", false, "列出全部检查项")
+	flag.Parse()
+
+	cfg, err := config.LoadProfile(*dir, *env)
+	if err != nil {
+		fmt.Printf("加载配置失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	checker := selftest.NewChecker(cfg)
+	defer checker.Close()
+
+	checks := checker.Checks()
+	if *list {
+		for _, check := range checks {
+			fmt.Printf("  %-14s %s\n", check.Name, check.Description)
+		}
+		return
+	}
+
+	checks, err = filterChecks(checks, *only)
+	if err != nil {
+		fmt.Printf("执行失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	results := selftest.Run(context.Background(), checks, *timeout)
+	if *jsonOutput {
+		data, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("# 环境: %s\n\n", *env)
+		selftest.PrintReport(os.Stdout, results)
+	}
+
+	if selftest.Failed(results) {
+		checker.Close()
+		os.Exit(1)
+	}
+}
+
+// filterChecks 按-only参数筛选检查项
+func filterChecks(checks []selftest.Check, only string) ([]selftest.Check, error) {
+	if only == "" {
+		return checks, nil
+	}
+
+	byName := make(map[string]selftest.Check, len(checks))
+	for _, check := range checks {
+		byName[check.Name] = check
+	}
+
+	var selected []selftest.Check
+	for _, name := range strings.Split(only, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		check, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("未知的检查项: %s", name)
+		}
+		selected = append(selected, check)
+	}
+	return selected, nil
+}
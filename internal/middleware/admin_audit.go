@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	mongoModel "exchange/internal/models/mongodb"
+	"exchange/internal/pkg/adminaudit"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/utils"
+)
+
+// adminAuditWriteTimeout 写入审计记录的超时时间，请求的context可能已取消，使用独立的context
+const adminAuditWriteTimeout = 3 * time.Second
+
+// adminAuditLogger 管理员操作审计日志
+var adminAuditLogger = appLogger.Module("admin_audit")
+
+// AdminAuditMiddleware 管理员操作审计中间件
+// 在认证之后执行，记录管理员的每个写操作（包括失败和被拒绝的请求）：操作者、操作对象、变更前后的数据、
+// 响应结果、客户端IP和请求ID。处理器通过utils.SetAuditAction/SetAuditTarget/SetAuditChange补充操作名称、
+// 操作对象和变更前后的数据，未补充时操作为"方法 路由"，路由参数记录在params中
+type AdminAuditMiddleware struct {
+	store *adminaudit.Store
+}
+
+// NewAdminAuditMiddleware 创建管理员操作审计中间件
+func NewAdminAuditMiddleware(store *adminaudit.Store) *AdminAuditMiddleware {
+	return &AdminAuditMiddleware{
+		store: store,
+	}
+}
+
+// Record 记录管理员的写操作，在处理器执行之后写入，写入失败只记录日志
+func (m *AdminAuditMiddleware) Record() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if !isWriteMethod(c.Request.Method) || utils.IsAuditSkipped(c) {
+			return
+		}
+		adminID, exists := utils.GetAdminID(c)
+		if !exists {
+			return
+		}
+
+		log := m.buildLog(c, adminID)
+		ctx, cancel := context.WithTimeout(context.Background(), adminAuditWriteTimeout)
+		defer cancel()
+		if err := m.store.Append(ctx, log); err != nil {
+			adminAuditLogger.Error("写入管理员操作审计记录失败", map[string]interface{}{
+				"admin_id":   adminID,
+				"action":     log.Action,
+				"request_id": log.RequestID,
+				"error":      err.Error(),
+			})
+		}
+	}
+}
+
+// buildLog 按请求、响应和处理器补充的信息构建审计记录
+func (m *AdminAuditMiddleware) buildLog(c *gin.Context, adminID uint) *mongoModel.AdminAuditLog {
+	role, _ := utils.GetAdminRole(c)
+	entry := utils.GetAuditEntry(c)
+
+	log := &mongoModel.AdminAuditLog{
+		ActorID:    adminID,
+		ActorRole:  role,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Before:     entry.Before,
+		After:      entry.After,
+		Method:     c.Request.Method,
+		Path:       c.FullPath(),
+		Status:     c.Writer.Status(),
+		IP:         c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		RequestID:  GetRequestID(c),
+	}
+	if log.Path == "" {
+		log.Path = c.Request.URL.Path
+	}
+	if log.Action == "" {
+		log.Action = log.Method + " " + log.Path
+	}
+	if len(c.Params) > 0 {
+		log.Params = make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			log.Params[param.Key] = param.Value
+		}
+	}
+
+	// 统一响应以响应码判断结果（业务失败时HTTP状态码也为200），其他响应以HTTP状态码判断
+	code, messageKey, ok := utils.GetResponseResult(c)
+	if ok {
+		log.Success = code == utils.CodeSuccess
+	} else {
+		log.Success = log.Status < http.StatusBadRequest
+	}
+	if !log.Success {
+		log.Error = messageKey
+	} else {
+		log.Changes = adminaudit.Diff(log.Before, log.After)
+	}
+	return log
+}
@@ -9,21 +9,24 @@ import (
 	"exchange/internal/modules/admin/logic"
 	"exchange/internal/pkg/config"
 	"exchange/internal/pkg/database"
+	"exchange/internal/pkg/rbac"
 	"exchange/internal/utils"
 )
 
 // AdminAuthMiddleware Admin认证中间件
 type AdminAuthMiddleware struct {
-	authLogic logic.AdminAuthLogic
-	redis     *database.RedisService
-	config    *config.Config
+	authLogic   logic.AdminAuthLogic
+	permissions *rbac.PermissionChecker
+	redis       *database.RedisService
+	config      *config.Config
 }
 
 // NewAdminAuthMiddleware 创建Admin认证中间件
 func NewAdminAuthMiddleware(redis *database.RedisService, cfg *config.Config) *AdminAuthMiddleware {
 	return &AdminAuthMiddleware{
-		redis:  redis,
-		config: cfg,
+		permissions: rbac.NewPermissionChecker(nil, 0),
+		redis:       redis,
+		config:      cfg,
 	}
 }
 
@@ -32,6 +35,11 @@ func (m *AdminAuthMiddleware) SetAuthLogic(authLogic logic.AdminAuthLogic) {
 	m.authLogic = authLogic
 }
 
+// SetPermissionChecker 设置权限检查，未设置时只使用内置角色的默认权限
+func (m *AdminAuthMiddleware) SetPermissionChecker(permissions *rbac.PermissionChecker) {
+	m.permissions = permissions
+}
+
 // getAuthLogic 获取认证逻辑（延迟初始化）
 func (m *AdminAuthMiddleware) getAuthLogic() (logic.AdminAuthLogic, error) {
 	if m.authLogic != nil {
@@ -50,7 +58,7 @@ func (m *AdminAuthMiddleware) getAuthLogic() (logic.AdminAuthLogic, error) {
 
 // RequireAuth 需要Admin认证的中间件
 func (m *AdminAuthMiddleware) RequireAuth() gin.HandlerFunc {
-	return func(c *gin.Context) {
+	return declareRequirement(AdminRequirement(), func(c *gin.Context) {
 		// 获取认证逻辑（延迟初始化）
 		authLogic, err := m.getAuthLogic()
 		if err != nil {
@@ -90,16 +98,14 @@ func (m *AdminAuthMiddleware) RequireAuth() gin.HandlerFunc {
 			return
 		}
 
-		// 检查是否为admin token
-		if !strings.HasPrefix(claims.Role, "admin:") {
+		// 检查是否为admin token并提取admin角色
+		adminRole, ok := rbac.ParseAdminTokenRole(claims.Role)
+		if !ok {
 			utils.ErrorResponseWithAuth(c, "unauthorized", map[string]interface{}{"error": "user token not allowed for admin endpoints"})
 			c.Abort()
 			return
 		}
 
-		// 提取admin角色（去掉"admin:"前缀）
-		adminRole := strings.TrimPrefix(claims.Role, "admin:")
-
 		// 将admin信息存储到上下文中
 		c.Set("admin_id", claims.UserID)
 		c.Set("admin_role", adminRole)
@@ -107,12 +113,12 @@ func (m *AdminAuthMiddleware) RequireAuth() gin.HandlerFunc {
 		c.Set("token", token)
 
 		c.Next()
-	}
+	})
 }
 
-// RequireAdmin 需要admin角色的中间件
+// RequireAdmin 需要有效管理员角色的中间件（角色为内置角色或roles表中的角色）
 func (m *AdminAuthMiddleware) RequireAdmin() gin.HandlerFunc {
-	return func(c *gin.Context) {
+	return declareRequirement(AdminRequirement(), func(c *gin.Context) {
 		// 先检查是否已经通过认证
 		adminRole, exists := c.Get("admin_role")
 		if !exists {
@@ -121,20 +127,20 @@ func (m *AdminAuthMiddleware) RequireAdmin() gin.HandlerFunc {
 			return
 		}
 
-		// 检查是否为admin角色
-		if adminRole.(string) != "admin" && adminRole.(string) != "super" {
-			utils.ErrorResponseWithAuth(c, "insufficient_permissions", map[string]interface{}{"required_role": "admin", "current_role": adminRole})
+		// 检查角色是否存在（角色被删除后该角色的管理员无法访问）
+		if !m.permissions.RoleExists(c.Request.Context(), adminRole.(string)) {
+			utils.ErrorResponseWithAuth(c, "insufficient_permissions", map[string]interface{}{"current_role": adminRole})
 			c.Abort()
 			return
 		}
 
 		c.Next()
-	}
+	})
 }
 
-// RequireSuper 需要super角色的中间件
-func (m *AdminAuthMiddleware) RequireSuper() gin.HandlerFunc {
-	return func(c *gin.Context) {
+// RequirePermission 需要权限的中间件，管理员角色拥有permission（含通配符）时放行
+func (m *AdminAuthMiddleware) RequirePermission(permission string) gin.HandlerFunc {
+	return declareRequirement(PermissionRequirement(permission), func(c *gin.Context) {
 		// 先检查是否已经通过认证
 		adminRole, exists := c.Get("admin_role")
 		if !exists {
@@ -143,20 +149,20 @@ func (m *AdminAuthMiddleware) RequireSuper() gin.HandlerFunc {
 			return
 		}
 
-		// 检查是否为super角色
-		if adminRole.(string) != "super" {
-			utils.ErrorResponseWithAuth(c, "insufficient_permissions", map[string]interface{}{"required_role": "super"})
+		// 检查角色权限
+		if !m.permissions.HasPermission(c.Request.Context(), adminRole.(string), permission) {
+			utils.ErrorResponseWithAuth(c, "insufficient_permissions", map[string]interface{}{"required_permission": permission, "current_role": adminRole})
 			c.Abort()
 			return
 		}
 
 		c.Next()
-	}
+	})
 }
 
 // RequireRole 需要特定admin角色的中间件
 func (m *AdminAuthMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
-	return func(c *gin.Context) {
+	return declareRequirement(AdminRequirement(roles...), func(c *gin.Context) {
 		// 先检查是否已经通过认证
 		adminRole, exists := c.Get("admin_role")
 		if !exists {
@@ -181,7 +187,7 @@ func (m *AdminAuthMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
 		}
 
 		c.Next()
-	}
+	})
 }
 
 // OptionalAuth 可选的Admin认证中间件
@@ -220,15 +226,13 @@ func (m *AdminAuthMiddleware) OptionalAuth() gin.HandlerFunc {
 			return
 		}
 
-		// 检查是否为admin token
-		if !strings.HasPrefix(claims.Role, "admin:") {
+		// 检查是否为admin token并提取admin角色
+		adminRole, ok := rbac.ParseAdminTokenRole(claims.Role)
+		if !ok {
 			c.Next()
 			return
 		}
 
-		// 提取admin角色
-		adminRole := strings.TrimPrefix(claims.Role, "admin:")
-
 		// 将admin信息存储到上下文中
 		c.Set("admin_id", claims.UserID)
 		c.Set("admin_role", adminRole)
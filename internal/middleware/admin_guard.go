@@ -0,0 +1,258 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/database"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/utils"
+)
+
+// 异常类型
+const (
+	AnomalyMassDeletion = "mass_deletion"         // 短时间内大量删除
+	AnomalyOffHoursBulk = "off_hours_bulk_export" // 非工作时间批量导出/导入
+)
+
+const (
+	adminGuardKeyPrefix  = "admin_guard:" // Redis键前缀
+	adminGuardRateWindow = time.Minute    // 限流窗口
+)
+
+// adminGuardLogger 管理端限流和异常检测日志
+var adminGuardLogger = appLogger.Module("admin_guard")
+
+// windowIncrScript 固定窗口计数，首次计数时设置过期时间，返回计数和剩余毫秒数
+var windowIncrScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return {count, redis.call('PTTL', KEYS[1])}
+`)
+
+// AdminGuardMiddleware 管理端按管理员限流和异常操作检测
+// 请求数和写操作数超过每分钟上限时返回429；检测窗口内删除操作过多，或非工作时间批量导出/导入过多时，
+// 临时停用该管理员（停用期间所有令牌均被拒绝）并发送安全告警。计数保存在Redis中，多实例共享；
+// Redis不可用时放行请求，只记录日志
+type AdminGuardMiddleware struct {
+	redis      *database.RedisService
+	cfg        config.AdminGuardConfig
+	bulkRoutes map[string]bool // "METHOD 路由模板"
+}
+
+// NewAdminGuardMiddleware 创建管理端限流和异常检测中间件
+func NewAdminGuardMiddleware(redis *database.RedisService, cfg config.AdminGuardConfig) *AdminGuardMiddleware {
+	bulkRoutes := make(map[string]bool, len(cfg.BulkRoutes))
+	for _, route := range cfg.BulkRoutes {
+		bulkRoutes[route] = true
+	}
+	return &AdminGuardMiddleware{
+		redis:      redis,
+		cfg:        cfg,
+		bulkRoutes: bulkRoutes,
+	}
+}
+
+// Guard 限流和异常检测中间件，需在RequireAuth之后使用
+func (m *AdminGuardMiddleware) Guard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adminID := c.GetUint("admin_id")
+		if !m.cfg.Enabled || m.redis == nil || adminID == 0 {
+			c.Next()
+			return
+		}
+		ctx := c.Request.Context()
+
+		// 已停用的管理员
+		remaining, err := m.redis.Client().PTTL(ctx, m.suspendedKey(adminID)).Result()
+		if err != nil {
+			m.failOpen(c, "查询管理员停用状态失败", err)
+			return
+		}
+		if remaining > 0 {
+			m.rejectSuspended(c, remaining)
+			return
+		}
+
+		// 每分钟请求数和写操作数限制
+		if exceeded, retryAfter, err := m.exceeds(ctx, m.counterKey("requests", adminID), adminGuardRateWindow, m.cfg.RequestsPerMinute); err != nil {
+			m.failOpen(c, "管理端限流计数失败", err)
+			return
+		} else if exceeded {
+			m.rejectRateLimited(c, adminID, retryAfter)
+			return
+		}
+		if isWriteMethod(c.Request.Method) {
+			if exceeded, retryAfter, err := m.exceeds(ctx, m.counterKey("writes", adminID), adminGuardRateWindow, m.cfg.WritesPerMinute); err != nil {
+				m.failOpen(c, "管理端限流计数失败", err)
+				return
+			} else if exceeded {
+				m.rejectRateLimited(c, adminID, retryAfter)
+				return
+			}
+		}
+
+		// 异常操作检测，达到阈值的请求同样被拒绝
+		anomaly, count, err := m.detect(ctx, c, adminID)
+		if err != nil {
+			m.failOpen(c, "管理端异常检测失败", err)
+			return
+		}
+		if anomaly != "" {
+			suspendFor := time.Duration(m.cfg.SuspendMinutes) * time.Minute
+			if err := m.suspend(ctx, c, adminID, anomaly, count, suspendFor); err != nil {
+				adminGuardLogger.Error("停用管理员失败", map[string]interface{}{
+					"admin_id": adminID,
+					"anomaly":  anomaly,
+					"error":    err.Error(),
+				})
+			}
+			m.rejectSuspended(c, suspendFor)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// detect 检测异常操作，返回异常类型和检测窗口内的次数
+func (m *AdminGuardMiddleware) detect(ctx context.Context, c *gin.Context, adminID uint) (string, int64, error) {
+	window := time.Duration(m.cfg.DetectWindowSeconds) * time.Second
+
+	if c.Request.Method == "DELETE" {
+		count, _, err := m.incr(ctx, m.counterKey("deletes", adminID), window)
+		if err != nil {
+			return "", 0, err
+		}
+		if count >= int64(m.cfg.DeleteThreshold) {
+			return AnomalyMassDeletion, count, nil
+		}
+	}
+
+	if m.bulkRoutes[c.Request.Method+" "+c.FullPath()] && m.inOffHours(clock.Now()) {
+		count, _, err := m.incr(ctx, m.counterKey("off_hours_bulk", adminID), window)
+		if err != nil {
+			return "", 0, err
+		}
+		if count >= int64(m.cfg.OffHoursBulkLimit) {
+			return AnomalyOffHoursBulk, count, nil
+		}
+	}
+	return "", 0, nil
+}
+
+// suspend 停用管理员，记录审计日志并发送安全告警
+func (m *AdminGuardMiddleware) suspend(ctx context.Context, c *gin.Context, adminID uint, anomaly string, count int64, suspendFor time.Duration) error {
+	err := m.redis.Client().Set(ctx, m.suspendedKey(adminID), anomaly, suspendFor).Err()
+
+	details := map[string]interface{}{
+		"admin_id":        adminID,
+		"admin_role":      c.GetString("admin_role"),
+		"anomaly":         anomaly,
+		"count":           count,
+		"window_seconds":  m.cfg.DetectWindowSeconds,
+		"suspend_minutes": m.cfg.SuspendMinutes,
+		"method":          c.Request.Method,
+		"route":           c.FullPath(),
+		"client_ip":       c.ClientIP(),
+		"request_id":      c.GetString("request_id"),
+	}
+	appLogger.SecurityAlert(fmt.Sprintf("管理员%d异常操作(%s)，已临时停用%d分钟", adminID, anomaly, m.cfg.SuspendMinutes), details)
+	appLogger.Audit("管理员因异常操作被临时停用", details)
+	return err
+}
+
+// exceeds 计数加1并检查是否超过上限，返回窗口剩余时间
+func (m *AdminGuardMiddleware) exceeds(ctx context.Context, key string, window time.Duration, limit int) (bool, time.Duration, error) {
+	count, remaining, err := m.incr(ctx, key, window)
+	if err != nil {
+		return false, 0, err
+	}
+	return count > int64(limit), remaining, nil
+}
+
+// incr 固定窗口计数
+func (m *AdminGuardMiddleware) incr(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	result, err := windowIncrScript.Run(ctx, m.redis.Client(), []string{key}, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, 0, err
+	}
+	return result[0], time.Duration(result[1]) * time.Millisecond, nil
+}
+
+// inOffHours 是否处于非工作时间，开始和结束相同表示未设置
+func (m *AdminGuardMiddleware) inOffHours(now time.Time) bool {
+	start, end, hour := m.cfg.OffHoursStart, m.cfg.OffHoursEnd, now.Hour()
+	switch {
+	case start == end:
+		return false
+	case start < end:
+		return hour >= start && hour < end
+	default:
+		return hour >= start || hour < end
+	}
+}
+
+// rejectRateLimited 超过限流上限
+func (m *AdminGuardMiddleware) rejectRateLimited(c *gin.Context, adminID uint, retryAfter time.Duration) {
+	adminGuardLogger.Warn("管理员请求超过限流上限", map[string]interface{}{
+		"admin_id": adminID,
+		"method":   c.Request.Method,
+		"route":    c.FullPath(),
+	})
+	seconds := retryAfterSeconds(retryAfter)
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	utils.TooManyRequests(c, "too_many_requests", map[string]interface{}{"retry_after": seconds})
+	c.Abort()
+}
+
+// rejectSuspended 管理员已停用
+func (m *AdminGuardMiddleware) rejectSuspended(c *gin.Context, remaining time.Duration) {
+	seconds := retryAfterSeconds(remaining)
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	utils.ErrorResponseWithAuth(c, "admin_suspended", map[string]interface{}{"retry_after": seconds})
+	c.Abort()
+}
+
+// failOpen Redis不可用时放行请求
+func (m *AdminGuardMiddleware) failOpen(c *gin.Context, message string, err error) {
+	adminGuardLogger.Warn(message, map[string]interface{}{
+		"admin_id": c.GetUint("admin_id"),
+		"error":    err.Error(),
+	})
+	c.Next()
+}
+
+// counterKey 计数器键
+func (m *AdminGuardMiddleware) counterKey(kind string, adminID uint) string {
+	return fmt.Sprintf("%s%s:%d", adminGuardKeyPrefix, kind, adminID)
+}
+
+// suspendedKey 停用标记键
+func (m *AdminGuardMiddleware) suspendedKey(adminID uint) string {
+	return fmt.Sprintf("%ssuspended:%d", adminGuardKeyPrefix, adminID)
+}
+
+// isWriteMethod 是否为写操作
+func isWriteMethod(method string) bool {
+	switch method {
+	case "POST", "PUT", "PATCH", "DELETE":
+		return true
+	}
+	return false
+}
+
+// retryAfterSeconds 向上取整的秒数，至少为1
+func retryAfterSeconds(d time.Duration) int {
+	return int(math.Max(1, math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,243 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/modules/api/logic"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/database"
+	"exchange/internal/pkg/signing"
+	"exchange/internal/utils"
+)
+
+// apiKeyNonceKeyPrefix 已使用nonce的Redis键前缀，后接key ID和nonce
+const apiKeyNonceKeyPrefix = "api_key_auth:nonce:"
+
+// HeaderAPISecret 用户创建的API key的密钥，与X-API-Key一起使用
+const HeaderAPISecret = "X-API-Secret"
+
+// StoredAPIKeyAuthenticator 校验用户创建的API key（保存在api_keys表中）的密钥、有效期和允许的IP，
+// 并检查key所属用户的状态（配置中的key也通过它检查）
+type StoredAPIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, keyID, secret, clientIP string) (*mysql.APIKey, error)
+	CheckAPIKeyOwner(ctx context.Context, userID uint) error
+}
+
+// APIKeyAuthMiddleware API key认证
+// 机器人、做市商等程序化客户端无法使用浏览器登录流程，以API key代替JWT访问用户接口，由UserAuthMiddleware.RequireAuth
+// 在请求带X-API-Key头时调用：
+// - 配置中的key对请求签名：验证签名和时间戳窗口，通过Redis拒绝重复的nonce，所属用户的状态与用户创建的key一样检查
+// - 用户创建的key在X-API-Secret头中携带密钥：校验密钥哈希、有效期和允许的IP
+// 两种key都按权限检查请求方法，并执行key单独的限流上限
+type APIKeyAuthMiddleware struct {
+	keyring   *signing.Keyring
+	redis     *database.RedisService
+	rateLimit *RateLimitMiddleware
+	stored    StoredAPIKeyAuthenticator
+	enabled   bool
+	window    time.Duration
+	keys      map[string]*apiKey // key ID -> key
+}
+
+// apiKey 配置的API key
+type apiKey struct {
+	config.APIKeyConfig
+	permissions map[string]bool
+	limit       *rateLimitPolicy // 为nil时不单独限流
+}
+
+// NewAPIKeyAuthMiddleware 创建API key签名认证中间件，rateLimit用于执行key的限流上限
+func NewAPIKeyAuthMiddleware(redis *database.RedisService, cfg config.APIKeyAuthConfig, keyring *signing.Keyring, rateLimit *RateLimitMiddleware) *APIKeyAuthMiddleware {
+	m := &APIKeyAuthMiddleware{
+		keyring:   keyring,
+		redis:     redis,
+		rateLimit: rateLimit,
+		enabled:   cfg.Enabled,
+		window:    time.Duration(cfg.ReplayWindow) * time.Second,
+		keys:      make(map[string]*apiKey, len(cfg.Keys)),
+	}
+	for _, key := range cfg.Keys {
+		k := &apiKey{APIKeyConfig: key, permissions: make(map[string]bool, len(key.Permissions))}
+		for _, permission := range key.Permissions {
+			k.permissions[permission] = true
+		}
+		if key.RateLimit > 0 {
+			k.limit = newRateLimitPolicy("api_key", config.RateLimitPolicy{
+				Algorithm:     config.RateLimitSlidingWindow,
+				Key:           config.RateLimitByUser,
+				Limit:         key.RateLimit,
+				WindowSeconds: key.WindowSeconds,
+			})
+		}
+		m.keys[key.KeyID] = k
+	}
+	return m
+}
+
+// SetStoredKeys 设置用户创建的API key的校验和key所属用户的检查，未设置时无法检查所属用户，拒绝所有key
+func (m *APIKeyAuthMiddleware) SetStoredKeys(stored StoredAPIKeyAuthenticator) {
+	m.stored = stored
+}
+
+// authenticate 验证API key，通过后以key所属用户的身份继续处理请求
+func (m *APIKeyAuthMiddleware) authenticate(c *gin.Context) {
+	if !m.enabled {
+		utils.ErrorResponseWithAuth(c, "unauthorized", map[string]interface{}{"error": "api key authentication is disabled"})
+		c.Abort()
+		return
+	}
+
+	if secret := c.GetHeader(HeaderAPISecret); secret != "" {
+		m.authenticateStored(c, c.GetHeader(signing.HeaderAPIKey), secret)
+		return
+	}
+
+	sig, err := signing.ParseAPIKeyHeaders(c.Request.Header)
+	if err != nil {
+		utils.ErrorResponseWithAuth(c, "invalid_signature", map[string]interface{}{"error": err.Error()})
+		c.Abort()
+		return
+	}
+
+	key, ok := m.keys[sig.Service]
+	if !ok {
+		utils.ErrorResponseWithAuth(c, "invalid_signature", map[string]interface{}{"error": signing.ErrUnknownAPIKey.Error()})
+		c.Abort()
+		return
+	}
+	if key.Disabled {
+		utils.ErrorResponseWithAuth(c, "unauthorized", map[string]interface{}{"error": "api key disabled", "key_id": key.KeyID})
+		c.Abort()
+		return
+	}
+
+	// 读取请求体用于校验签名，读取后恢复供后续处理器使用
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSignedBodySize))
+	if err != nil {
+		utils.ErrorResponse(c, "invalid_request", map[string]interface{}{"error": err.Error()})
+		c.Abort()
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	ctx := c.Request.Context()
+	if err := m.keyring.Verify(ctx, sig, c.Request.Method, c.Request.URL.RequestURI(), body, m.window, time.Now()); err != nil {
+		if !errors.Is(err, signing.ErrExpired) && !errors.Is(err, signing.ErrInvalidSignature) && !errors.Is(err, signing.ErrUnknownAPIKey) {
+			// 密钥提供者不可用等内部错误
+			utils.ErrorResponseWithAuth(c, "internal_server_error", map[string]interface{}{"error": err.Error()})
+			c.Abort()
+			return
+		}
+		utils.ErrorResponseWithAuth(c, "invalid_signature", map[string]interface{}{"error": err.Error()})
+		c.Abort()
+		return
+	}
+
+	// 签名正确但权限不足的请求不占用nonce
+	permission := requiredAPIKeyPermission(c)
+	if !key.permissions[permission] {
+		utils.ErrorResponseWithAuth(c, "insufficient_permissions", map[string]interface{}{"error": "api key lacks permission", "permission": permission})
+		c.Abort()
+		return
+	}
+
+	// 所属用户被封禁、锁定、删除或要求重置密码时拒绝，与用户创建的key相同
+	if m.stored == nil {
+		utils.ErrorResponseWithAuth(c, "invalid_api_key", map[string]interface{}{"error": "api key owner cannot be checked", "key_id": key.KeyID})
+		c.Abort()
+		return
+	}
+	if err := m.stored.CheckAPIKeyOwner(ctx, key.UserID); err != nil {
+		if errors.Is(err, logic.ErrAPIKeyOwnerDisabled) {
+			utils.ErrorResponseWithAuth(c, "invalid_api_key", map[string]interface{}{"error": err.Error(), "key_id": key.KeyID})
+		} else {
+			utils.ErrorResponseWithAuth(c, "internal_server_error", map[string]interface{}{"error": err.Error()})
+		}
+		c.Abort()
+		return
+	}
+
+	// nonce在两倍时间窗口内只能使用一次，超出窗口的请求已被时间戳校验拒绝
+	nonceKey := apiKeyNonceKeyPrefix + key.KeyID + ":" + sig.Nonce
+	fresh, err := m.redis.Client().SetNX(ctx, nonceKey, 1, 2*m.window).Result()
+	if err != nil {
+		utils.ErrorResponseWithAuth(c, "internal_server_error", map[string]interface{}{"error": err.Error()})
+		c.Abort()
+		return
+	}
+	if !fresh {
+		utils.ErrorResponseWithAuth(c, "invalid_signature", map[string]interface{}{"error": "nonce already used"})
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", key.UserID)
+	c.Set("role", string(mysql.UserRoleUser))
+	c.Set("api_key_id", key.KeyID)
+
+	m.rateLimit.enforceAPIKey(c, key.limit, key.KeyID)
+}
+
+// authenticateStored 验证用户创建的API key的密钥
+func (m *APIKeyAuthMiddleware) authenticateStored(c *gin.Context, keyID, secret string) {
+	if m.stored == nil {
+		utils.ErrorResponseWithAuth(c, "invalid_api_key", map[string]interface{}{"error": logic.ErrInvalidAPIKey.Error()})
+		c.Abort()
+		return
+	}
+
+	key, err := m.stored.AuthenticateAPIKey(c.Request.Context(), keyID, secret, c.ClientIP())
+	switch {
+	case errors.Is(err, logic.ErrInvalidAPIKey), errors.Is(err, logic.ErrAPIKeyExpired), errors.Is(err, logic.ErrAPIKeyIPNotAllowed), errors.Is(err, logic.ErrAPIKeyOwnerDisabled):
+		utils.ErrorResponseWithAuth(c, "invalid_api_key", map[string]interface{}{"error": err.Error()})
+		c.Abort()
+		return
+	case err != nil:
+		utils.ErrorResponseWithAuth(c, "internal_server_error", map[string]interface{}{"error": err.Error()})
+		c.Abort()
+		return
+	}
+
+	permission := requiredAPIKeyPermission(c)
+	if !key.HasScope(permission) {
+		utils.ErrorResponseWithAuth(c, "insufficient_permissions", map[string]interface{}{"error": "api key lacks permission", "permission": permission})
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", key.UserID)
+	c.Set("role", string(mysql.UserRoleUser))
+	c.Set("api_key_id", key.KeyID)
+
+	var limit *rateLimitPolicy
+	if key.RateLimit > 0 && key.WindowSeconds > 0 {
+		limit = newRateLimitPolicy("api_key", config.RateLimitPolicy{
+			Algorithm:     config.RateLimitSlidingWindow,
+			Key:           config.RateLimitByUser,
+			Limit:         key.RateLimit,
+			WindowSeconds: key.WindowSeconds,
+		})
+	}
+	m.rateLimit.enforceAPIKey(c, limit, key.KeyID)
+}
+
+// requiredAPIKeyPermission 请求需要的key权限：GET、HEAD请求需要read，其他请求需要write
+func requiredAPIKeyPermission(c *gin.Context) string {
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		return config.APIKeyPermissionRead
+	}
+	return config.APIKeyPermissionWrite
+}
+
+// GetAPIKeyID 从上下文获取认证使用的API key ID，JWT认证的请求为空
+func GetAPIKeyID(c *gin.Context) string {
+	return c.GetString("api_key_id")
+}
@@ -0,0 +1,297 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 认证类型
+const (
+	AuthPublic  = "public"  // 无需认证
+	AuthUser    = "user"    // 需要用户认证
+	AuthAdmin   = "admin"   // 需要管理员认证
+	AuthService = "service" // 需要内部服务签名
+)
+
+// AuthRequirement 路由的认证要求
+type AuthRequirement struct {
+	Auth        string   `json:"auth"`                  // 认证类型：public/user/admin/service
+	Roles       []string `json:"roles,omitempty"`       // 允许的角色
+	Permissions []string `json:"permissions,omitempty"` // 需要的权限
+	Services    []string `json:"services,omitempty"`    // 允许的内部服务
+	JWTOnly     bool     `json:"jwt_only,omitempty"`    // 只允许登录token，不接受API key
+}
+
+// PublicRequirement 无需认证
+func PublicRequirement() AuthRequirement {
+	return AuthRequirement{Auth: AuthPublic}
+}
+
+// UserRequirement 需要用户认证
+func UserRequirement() AuthRequirement {
+	return AuthRequirement{Auth: AuthUser}
+}
+
+// AdminRequirement 需要管理员认证，roles为允许的管理员角色
+func AdminRequirement(roles ...string) AuthRequirement {
+	return AuthRequirement{Auth: AuthAdmin, Roles: roles}
+}
+
+// PermissionRequirement 需要管理员认证，permissions为需要的权限
+func PermissionRequirement(permissions ...string) AuthRequirement {
+	return AuthRequirement{Auth: AuthAdmin, Permissions: permissions}
+}
+
+// ServiceRequirement 需要内部服务签名，services为允许的服务
+func ServiceRequirement(services ...string) AuthRequirement {
+	return AuthRequirement{Auth: AuthService, Services: services}
+}
+
+// AuthMatrixEntry 权限矩阵中的一条路由记录
+type AuthMatrixEntry struct {
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Classified bool   `json:"classified"`       // 是否已显式声明认证要求
+	Source     string `json:"source,omitempty"` // 声明来源：路由或路由组前缀
+	AuthRequirement
+}
+
+// AuthMatrix 路由权限矩阵
+// 需要认证的路由通过Routes包装的路由组注册，认证要求由认证中间件自身声明；
+// 公开路由通过ClassifyGroup/ClassifyRoute显式声明。
+// 启动后与Gin实际注册的路由比对，找出未声明认证要求的路由
+type AuthMatrix struct {
+	mu     sync.RWMutex
+	groups map[string]AuthRequirement // 路由组前缀 -> 认证要求
+	routes map[string]AuthRequirement // "METHOD path" -> 认证要求（优先于路由组）
+}
+
+var (
+	globalAuthMatrix *AuthMatrix
+	authMatrixOnce   sync.Once
+)
+
+// GetAuthMatrix 获取全局路由权限矩阵
+func GetAuthMatrix() *AuthMatrix {
+	authMatrixOnce.Do(func() {
+		globalAuthMatrix = NewAuthMatrix()
+	})
+	return globalAuthMatrix
+}
+
+// NewAuthMatrix 创建路由权限矩阵
+func NewAuthMatrix() *AuthMatrix {
+	return &AuthMatrix{
+		groups: make(map[string]AuthRequirement),
+		routes: make(map[string]AuthRequirement),
+	}
+}
+
+// ClassifyGroup 声明路由组下所有路由的认证要求
+func (m *AuthMatrix) ClassifyGroup(group *gin.RouterGroup, req AuthRequirement) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.groups[strings.TrimSuffix(group.BasePath(), "/")] = req
+}
+
+// ClassifyRoute 声明单个路由的认证要求，优先级高于路由组
+func (m *AuthMatrix) ClassifyRoute(method, path string, req AuthRequirement) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.routes[routeKey(method, path)] = req
+}
+
+// Lookup 查询路由的认证要求，返回声明来源
+func (m *AuthMatrix) Lookup(method, path string) (AuthRequirement, string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	key := routeKey(method, path)
+	if req, ok := m.routes[key]; ok {
+		return req, key, true
+	}
+
+	// 按最长前缀匹配路由组
+	matched := ""
+	found := false
+	for prefix := range m.groups {
+		if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+			continue
+		}
+		if !found || len(prefix) > len(matched) {
+			matched = prefix
+			found = true
+		}
+	}
+	if !found {
+		return AuthRequirement{}, "", false
+	}
+	return m.groups[matched], matched, true
+}
+
+// Build 根据已注册的路由生成权限矩阵
+func (m *AuthMatrix) Build(routes gin.RoutesInfo) []AuthMatrixEntry {
+	entries := make([]AuthMatrixEntry, 0, len(routes))
+	for _, route := range routes {
+		req, source, ok := m.Lookup(route.Method, route.Path)
+		entries = append(entries, AuthMatrixEntry{
+			Method:          route.Method,
+			Path:            route.Path,
+			Classified:      ok,
+			Source:          source,
+			AuthRequirement: req,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Path != entries[j].Path {
+			return entries[i].Path < entries[j].Path
+		}
+		return entries[i].Method < entries[j].Method
+	})
+	return entries
+}
+
+// Verify 检查是否存在未声明认证要求的路由
+func (m *AuthMatrix) Verify(routes gin.RoutesInfo) error {
+	var unclassified []string
+	for _, entry := range m.Build(routes) {
+		if !entry.Classified {
+			unclassified = append(unclassified, routeKey(entry.Method, entry.Path))
+		}
+	}
+	if len(unclassified) > 0 {
+		return fmt.Errorf("以下路由未声明认证要求: %s", strings.Join(unclassified, ", "))
+	}
+	return nil
+}
+
+// authRequirementProbeKey 探测认证要求时写入上下文的键
+const authRequirementProbeKey = "auth_requirement_probe"
+
+// declareRequirement 为认证中间件附加认证要求
+// 正常请求直接执行handler；上下文中存在探测键时只写入认证要求并返回，
+// 路由注册时据此从处理链推导认证要求，避免与手写声明不一致
+func declareRequirement(req AuthRequirement, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if probe, ok := c.Get(authRequirementProbeKey); ok {
+			*probe.(*AuthRequirement) = req
+			return
+		}
+		handler(c)
+	}
+}
+
+// declaredRequirementPC declareRequirement返回的闭包的代码地址，用于在处理链中识别认证中间件
+var declaredRequirementPC = reflect.ValueOf(declareRequirement(AuthRequirement{}, nil)).Pointer()
+
+// authRank 认证类型的强度，处理链中有多个认证中间件时取最强的一个
+var authRank = map[string]int{
+	AuthPublic:  0,
+	AuthUser:    1,
+	AuthAdmin:   2,
+	AuthService: 2,
+}
+
+// requirementOf 从处理链推导认证要求，处理链中没有认证中间件时返回false
+func requirementOf(chain gin.HandlersChain) (AuthRequirement, bool) {
+	var merged AuthRequirement
+	found := false
+	for _, handler := range chain {
+		if reflect.ValueOf(handler).Pointer() != declaredRequirementPC {
+			continue
+		}
+
+		var req AuthRequirement
+		probe := &gin.Context{}
+		probe.Set(authRequirementProbeKey, &req)
+		handler(probe)
+
+		if !found || authRank[req.Auth] > authRank[merged.Auth] {
+			merged.Auth = req.Auth
+		}
+		merged.Roles = append(merged.Roles, req.Roles...)
+		merged.Permissions = append(merged.Permissions, req.Permissions...)
+		merged.Services = append(merged.Services, req.Services...)
+		merged.JWTOnly = merged.JWTOnly || req.JWTOnly
+		found = true
+	}
+	return merged, found
+}
+
+// AuthRoutes 登记认证要求的路由组
+// 注册路由时从路由组和路由的处理链推导认证要求并写入权限矩阵
+type AuthRoutes struct {
+	*gin.RouterGroup
+	matrix *AuthMatrix
+}
+
+// Routes 包装路由组，通过返回值注册的路由自动登记认证要求
+func (m *AuthMatrix) Routes(group *gin.RouterGroup) *AuthRoutes {
+	return &AuthRoutes{RouterGroup: group, matrix: m}
+}
+
+// Group 创建子路由组
+func (r *AuthRoutes) Group(relativePath string, handlers ...gin.HandlerFunc) *AuthRoutes {
+	return r.matrix.Routes(r.RouterGroup.Group(relativePath, handlers...))
+}
+
+// Handle 注册路由并登记认证要求
+func (r *AuthRoutes) Handle(httpMethod, relativePath string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	chain := make(gin.HandlersChain, 0, len(r.RouterGroup.Handlers)+len(handlers))
+	chain = append(chain, r.RouterGroup.Handlers...)
+	chain = append(chain, handlers...)
+	if req, ok := requirementOf(chain); ok {
+		r.matrix.ClassifyRoute(httpMethod, joinPath(r.BasePath(), relativePath), req)
+	}
+	return r.RouterGroup.Handle(httpMethod, relativePath, handlers...)
+}
+
+// GET 注册GET路由
+func (r *AuthRoutes) GET(relativePath string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	return r.Handle(http.MethodGet, relativePath, handlers...)
+}
+
+// POST 注册POST路由
+func (r *AuthRoutes) POST(relativePath string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	return r.Handle(http.MethodPost, relativePath, handlers...)
+}
+
+// PUT 注册PUT路由
+func (r *AuthRoutes) PUT(relativePath string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	return r.Handle(http.MethodPut, relativePath, handlers...)
+}
+
+// PATCH 注册PATCH路由
+func (r *AuthRoutes) PATCH(relativePath string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	return r.Handle(http.MethodPatch, relativePath, handlers...)
+}
+
+// DELETE 注册DELETE路由
+func (r *AuthRoutes) DELETE(relativePath string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	return r.Handle(http.MethodDelete, relativePath, handlers...)
+}
+
+// joinPath 拼接路由路径，与Gin的规则一致（保留末尾斜杠）
+func joinPath(basePath, relativePath string) string {
+	if relativePath == "" {
+		return basePath
+	}
+	joined := path.Join(basePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(joined, "/") {
+		return joined + "/"
+	}
+	return joined
+}
+
+// routeKey 生成路由键
+func routeKey(method, path string) string {
+	return method + " " + path
+}
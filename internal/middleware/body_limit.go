@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/pkg/config"
+	appErrors "exchange/internal/pkg/errors"
+	"exchange/internal/utils"
+)
+
+// BodyLimitMiddleware 请求体大小限制中间件
+// 声明的Content-Length超过路由的上限时不进入处理器，直接返回HTTP 413、file_too_large和错误码10012；
+// 未声明长度的请求体通过http.MaxBytesReader读取，超过上限时读取返回错误。
+// 路由依次匹配"METHOD 路由模板"、"* 路由模板"，都没有时使用default_bytes
+func BodyLimitMiddleware(cfg config.BodyLimitConfig) gin.HandlerFunc {
+	routes := make(map[string]int64, len(cfg.Routes))
+	for _, route := range cfg.Routes {
+		routes[route.Route] = route.Bytes
+	}
+
+	return func(c *gin.Context) {
+		limit := bodyLimitFor(c, cfg.DefaultBytes, routes)
+		if !cfg.Enabled || limit <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			// 不读取请求体，响应后关闭连接
+			c.Header("Connection", "close")
+			utils.ErrorResponseFromError(c, "file_too_large", appErrors.FileTooLargeError(limit))
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// bodyLimitFor 请求的请求体大小上限
+func bodyLimitFor(c *gin.Context, def int64, routes map[string]int64) int64 {
+	route := c.FullPath()
+	if route != "" {
+		if limit, ok := routes[c.Request.Method+" "+route]; ok {
+			return limit
+		}
+		if limit, ok := routes["* "+route]; ok {
+			return limit
+		}
+	}
+	return def
+}
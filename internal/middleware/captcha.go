@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/pkg/captcha"
+	"exchange/internal/pkg/config"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/utils"
+)
+
+// CaptchaTokenHeader 前端验证组件返回的token所在的请求头
+const CaptchaTokenHeader = "X-Captcha-Token"
+
+// captchaUncountedKeys 不计为失败的响应（需要继续完成两步验证或确认新设备，不是凭据错误）
+var captchaUncountedKeys = map[string]bool{
+	"two_factor_required":         true,
+	"two_factor_setup_required":   true,
+	"login_confirmation_required": true,
+}
+
+// CaptchaMiddleware 人机验证中间件
+// 同一客户端IP在窗口内失败次数达到阈值后，请求需携带X-Captcha-Token头并通过校验才进入处理器；
+// 缺少token时返回captcha_required及前端渲染验证组件需要的provider和site_key
+type CaptchaMiddleware struct {
+	verifier captcha.Verifier
+	failures *captcha.FailureCounter
+	cfg      config.CaptchaConfig
+}
+
+// NewCaptchaMiddleware 创建人机验证中间件，未开启时verifier可以为nil
+func NewCaptchaMiddleware(verifier captcha.Verifier, failures *captcha.FailureCounter, cfg config.CaptchaConfig) *CaptchaMiddleware {
+	return &CaptchaMiddleware{
+		verifier: verifier,
+		failures: failures,
+		cfg:      cfg,
+	}
+}
+
+// Protect 按失败的响应统计次数（登录、注册、使用重置token设置密码等会返回失败的接口）
+func (m *CaptchaMiddleware) Protect(scope string) gin.HandlerFunc {
+	return m.handler(scope, false)
+}
+
+// ProtectRequests 每个请求都计入次数（发送重置密码邮件等不向请求方透露结果、总是返回成功的接口）
+func (m *CaptchaMiddleware) ProtectRequests(scope string) gin.HandlerFunc {
+	return m.handler(scope, true)
+}
+
+// handler 需要验证时校验token，处理器执行后按结果统计次数
+func (m *CaptchaMiddleware) handler(scope string, countAll bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !m.cfg.Enabled {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		clientIP := c.ClientIP()
+
+		// 统计存储不可用时不要求验证，登录失败限制和接口限流仍然生效
+		required, err := m.failures.Required(ctx, scope, clientIP)
+		if err != nil {
+			appLogger.Warn("查询人机验证失败次数失败", map[string]interface{}{
+				"scope": scope,
+				"error": err.Error(),
+			})
+		}
+		if required && !m.verify(c, scope, clientIP) {
+			c.Abort()
+			return
+		}
+
+		c.Next()
+
+		code, messageKey, ok := utils.GetResponseResult(c)
+		failed := ok && code != utils.CodeSuccess && !captchaUncountedKeys[messageKey]
+		if countAll || failed {
+			m.fail(c, scope, clientIP)
+		}
+	}
+}
+
+// verify 校验请求头中的token，未通过时写出响应并返回false
+func (m *CaptchaMiddleware) verify(c *gin.Context, scope, clientIP string) bool {
+	token := c.GetHeader(CaptchaTokenHeader)
+	if token == "" {
+		utils.ErrorWithData(c, "captcha_required", m.challenge(), nil)
+		return false
+	}
+
+	err := m.verifier.Verify(c.Request.Context(), token, clientIP)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, captcha.ErrInvalidToken):
+		appLogger.Security("人机验证未通过", map[string]interface{}{
+			"scope": scope,
+			"ip":    clientIP,
+			"error": err.Error(),
+		})
+		m.fail(c, scope, clientIP)
+		utils.ErrorWithData(c, "captcha_invalid", m.challenge(), nil)
+	default:
+		// 校验服务不可用时拒绝请求，避免攻击者在服务故障期间绕过验证
+		appLogger.Error("人机验证服务不可用", map[string]interface{}{
+			"scope": scope,
+			"error": err.Error(),
+		})
+		utils.ErrorResponse(c, "captcha_unavailable", nil)
+	}
+	return false
+}
+
+// fail 记录一次失败
+func (m *CaptchaMiddleware) fail(c *gin.Context, scope, clientIP string) {
+	if err := m.failures.Fail(c.Request.Context(), scope, clientIP); err != nil {
+		appLogger.Warn("记录人机验证失败次数失败", map[string]interface{}{
+			"scope": scope,
+			"error": err.Error(),
+		})
+	}
+}
+
+// challenge 前端渲染验证组件需要的信息
+func (m *CaptchaMiddleware) challenge() map[string]interface{} {
+	return map[string]interface{}{
+		"provider": m.cfg.Provider,
+		"site_key": m.cfg.SiteKey,
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/pkg/breaker"
+	"exchange/internal/pkg/config"
+	appErrors "exchange/internal/pkg/errors"
+	"exchange/internal/utils"
+)
+
+// CircuitBreakerMiddleware 依赖服务熔断快速失败中间件
+// fail_fast中的依赖处于熔断打开期间时，请求不进入处理器，直接返回HTTP 503、Retry-After头和错误码
+// 10007（熔断由超时引起）或10008（连接失败）；半开状态放行请求，由客户端熔断器限制探测请求数。
+// 未匹配路由的请求和exempt_routes中的路由（如健康检查）不受影响
+func CircuitBreakerMiddleware(cfg config.CircuitBreakerConfig) gin.HandlerFunc {
+	exempt := make(map[string]bool, len(cfg.ExemptRoutes))
+	for _, route := range cfg.ExemptRoutes {
+		exempt[route] = true
+	}
+	registry := breaker.Default()
+
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if !cfg.Enabled || route == "" || exempt[c.Request.Method+" "+route] {
+			c.Next()
+			return
+		}
+
+		err := registry.FirstOpen(cfg.FailFast)
+		if err == nil {
+			c.Next()
+			return
+		}
+
+		if appErr, ok := appErrors.GetAppError(err); ok {
+			if seconds, ok := appErr.Context["retry_after"].(int); ok {
+				c.Header("Retry-After", strconv.Itoa(seconds))
+			}
+		}
+		utils.ErrorResponseFromError(c, "service_unavailable", err)
+		c.Abort()
+	}
+}
@@ -0,0 +1,232 @@
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/pkg/config"
+)
+
+// 支持的响应编码，按优先级排列
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+)
+
+// compressor gzip.Writer和flate.Writer的公共方法
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// CompressionMiddleware 响应压缩中间件
+// 按Accept-Encoding选择gzip或deflate，只压缩content_types中的类型；未声明Content-Length的响应先缓冲min_bytes字节，
+// 处理器结束时仍不足的不压缩。HEAD请求、协议升级（WebSocket）、部分内容（Range）和已编码的响应不压缩
+func CompressionMiddleware(cfg config.CompressionConfig) gin.HandlerFunc {
+	types := make(map[string]bool, len(cfg.ContentTypes))
+	for _, contentType := range cfg.ContentTypes {
+		types[strings.ToLower(contentType)] = true
+	}
+	pools := map[string]*sync.Pool{
+		encodingGzip: {New: func() interface{} {
+			w, _ := gzip.NewWriterLevel(io.Discard, cfg.Level)
+			return w
+		}},
+		encodingDeflate: {New: func() interface{} {
+			w, _ := flate.NewWriter(io.Discard, cfg.Level)
+			return w
+		}},
+	}
+
+	return func(c *gin.Context) {
+		if !cfg.Enabled || c.Request.Method == http.MethodHead || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+
+		c.Header("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		writer := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       encoding,
+			pool:           pools[encoding],
+			minBytes:       cfg.MinBytes,
+			types:          types,
+		}
+		c.Writer = writer
+		defer func() {
+			writer.close()
+			c.Writer = writer.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
+
+// negotiateEncoding 按Accept-Encoding选择响应编码，q=0表示不接受，都不支持时返回空
+func negotiateEncoding(header string) string {
+	accepted := make(map[string]bool, 2)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if q, err := strconv.ParseFloat(value, 64); err != nil || q <= 0 {
+				continue
+			}
+		}
+		accepted[name] = true
+	}
+	for _, encoding := range []string{encodingGzip, encodingDeflate} {
+		if accepted[encoding] || accepted["*"] {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// compressWriter 压缩响应体的writer
+// 在第一次写入时决定是否压缩：类型不匹配时直接写出；大小未知时先缓冲，达到min_bytes后开始压缩
+type compressWriter struct {
+	gin.ResponseWriter
+
+	encoding string
+	pool     *sync.Pool
+	minBytes int
+	types    map[string]bool
+
+	buf     []byte
+	decided bool
+	enc     compressor // 为nil时不压缩
+}
+
+// Write 写入响应体
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		if !w.compressible() {
+			w.decide(false)
+		} else if length, err := strconv.Atoi(w.Header().Get("Content-Length")); err == nil {
+			w.decide(length >= w.minBytes)
+		} else {
+			w.buf = append(w.buf, data...)
+			if len(w.buf) < w.minBytes {
+				return len(data), nil
+			}
+			w.decide(true)
+			return len(data), w.flushBuffer()
+		}
+	}
+	if w.enc != nil {
+		return w.enc.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 写入响应体
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow 立即写出响应头，之后不再改变是否压缩
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		w.decide(len(w.buf) > 0 && w.compressible())
+		_ = w.flushBuffer()
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush 刷新已写入的数据（流式响应），未决定是否压缩时按类型决定
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(w.compressible())
+		_ = w.flushBuffer()
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Written 响应是否已写出（已缓冲的数据视为已写出，避免之后的中间件重复写响应）
+func (w *compressWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// Size 已写出的响应体大小（压缩后），只有缓冲数据时为缓冲的大小
+func (w *compressWriter) Size() int {
+	if !w.decided && len(w.buf) > 0 {
+		return len(w.buf)
+	}
+	return w.ResponseWriter.Size()
+}
+
+// compressible 响应的状态码、编码和类型是否允许压缩
+func (w *compressWriter) compressible() bool {
+	status := w.Status()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusPartialContent || status == http.StatusNotModified {
+		return false
+	}
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && w.types[mediaType]
+}
+
+// decide 决定是否压缩，压缩时设置Content-Encoding并删除Content-Length
+func (w *compressWriter) decide(compress bool) {
+	w.decided = true
+	if !compress {
+		return
+	}
+	header := w.Header()
+	header.Set("Content-Encoding", w.encoding)
+	header.Del("Content-Length")
+	w.enc = w.pool.Get().(compressor)
+	w.enc.Reset(w.ResponseWriter)
+}
+
+// flushBuffer 写出缓冲的数据
+func (w *compressWriter) flushBuffer() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	buf := w.buf
+	w.buf = nil
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// close 处理器结束，写出缓冲的数据（不足min_bytes，不压缩）并结束压缩流
+func (w *compressWriter) close() {
+	if !w.decided {
+		w.decide(false)
+	}
+	_ = w.flushBuffer()
+	if w.enc != nil {
+		_ = w.enc.Close()
+		w.enc.Reset(io.Discard)
+		w.pool.Put(w.enc)
+		w.enc = nil
+	}
+}
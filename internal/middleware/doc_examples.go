@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/pkg/apidocs"
+	appLogger "exchange/internal/pkg/logger"
+)
+
+// DocExamplesPath 示例文档接口路径，该接口本身不记录示例
+const DocExamplesPath = "/docs/examples"
+
+// bodyCaptureWriter 在写出响应的同时保留响应体，超过上限后不再保留
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// Write 写出响应并保留响应体
+func (w *bodyCaptureWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 写出响应并保留响应体
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// capture 保留响应体
+func (w *bodyCaptureWriter) capture(data []byte) {
+	if w.truncated {
+		return
+	}
+	if w.body.Len()+len(data) > w.limit {
+		w.truncated = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(data)
+}
+
+// DocExamplesMiddleware 记录接口文档示例（仅用于开发和测试环境）
+// 已匹配路由的JSON请求完成后，记录请求参数、请求体和响应体，认证层级取自路由权限矩阵；
+// 请求体或响应体超过maxBodyBytes时不记录
+func DocExamplesMiddleware(recorder *apidocs.Recorder, maxBodyBytes int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var requestBody []byte
+		if c.Request.Body != nil {
+			body, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(maxBodyBytes)+1))
+			if err != nil {
+				c.Next()
+				return
+			}
+			// 读取的部分放回请求体，不影响处理器
+			c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+			requestBody = body
+		}
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer, limit: maxBodyBytes}
+		c.Writer = writer
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" || route == DocExamplesPath || len(requestBody) > maxBodyBytes || writer.truncated ||
+			!strings.HasPrefix(writer.Header().Get("Content-Type"), "application/json") {
+			return
+		}
+
+		auth := AuthPublic
+		if req, _, ok := GetAuthMatrix().Lookup(c.Request.Method, route); ok {
+			auth = req.Auth
+		}
+
+		var response struct {
+			Code int `json:"code"`
+		}
+		_ = json.Unmarshal(writer.body.Bytes(), &response)
+
+		example := apidocs.Example{
+			Method:       c.Request.Method,
+			Route:        route,
+			Auth:         auth,
+			Status:       writer.Status(),
+			Code:         response.Code,
+			Query:        c.Request.URL.RawQuery,
+			RequestBody:  requestBody,
+			ResponseBody: writer.body.Bytes(),
+			CapturedAt:   time.Now().UTC(),
+		}
+		if err := recorder.Record(example); err != nil {
+			appLogger.Warn("记录接口文档示例失败", map[string]interface{}{
+				"route": route,
+				"error": err.Error(),
+			})
+		}
+	}
+}
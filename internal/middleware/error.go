@@ -6,6 +6,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	appErrors "exchange/internal/pkg/errors"
+	appLogger "exchange/internal/pkg/logger"
 	"exchange/internal/utils"
 )
 
@@ -14,9 +16,17 @@ func ErrorHandlerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				// 记录panic错误
-				fmt.Printf("Panic recovered: %v\n", err)
-				fmt.Printf("Stack trace: %s\n", debug.Stack())
+				// 记录panic错误，日志带请求ID
+				appLogger.FromContext(c.Request.Context()).Error("请求处理panic", map[string]interface{}{
+					"panic":  fmt.Sprintf("%v", err),
+					"method": c.Request.Method,
+					"path":   c.Request.URL.Path,
+					"stack":  string(debug.Stack()),
+				})
+
+				// 上报到错误跟踪服务（需在recover所在函数中调用以保留panic位置）
+				appErrors.ReportPanic(err, utils.ErrorRequestInfo(c))
+				appErrors.ObservePanic(utils.MetricsRoute(c))
 
 				// 返回500错误
 				if !c.Writer.Written() {
@@ -34,14 +44,16 @@ func ErrorHandlerMiddleware() gin.HandlerFunc {
 		if len(c.Errors) > 0 {
 			ginErr := c.Errors.Last()
 
-			// 记录错误
-			fmt.Printf("Request error: %v\n", ginErr.Error())
+			// 记录错误，日志带请求ID
+			appLogger.FromContext(c.Request.Context()).Error("请求处理失败", map[string]interface{}{
+				"error":  ginErr.Error(),
+				"method": c.Request.Method,
+				"path":   c.Request.URL.Path,
+			})
 
-			// 如果响应还没有写入，返回错误响应
+			// 如果响应还没有写入，返回错误响应（AppError附带错误码和上下文）
 			if !c.Writer.Written() {
-				utils.ErrorResponse(c, "request_error", map[string]interface{}{
-					"error": ginErr.Error(),
-				})
+				utils.ErrorResponseFromError(c, "request_error", ginErr.Err)
 			}
 		}
 	}
@@ -12,9 +12,8 @@ func I18nMiddleware(i18nManager *i18n.I18nManager) gin.HandlerFunc {
 		// 获取客户端语言偏好
 		lang := i18n.GetLanguageFromContext(c)
 		
-		// 验证语言是否支持
-		supportedLangs := i18nManager.GetSupportedLanguages()
-		if !containsLang(supportedLangs, lang) {
+		// 验证语言是否支持（语言或其回退链中的语言有翻译）
+		if !i18nManager.IsSupported(lang) {
 			lang = i18nManager.GetDefaultLanguage()
 		}
 		
@@ -29,6 +28,20 @@ func I18nMiddleware(i18nManager *i18n.I18nManager) gin.HandlerFunc {
 	}
 }
 
+// SetUserLanguage 设置认证用户保存的首选语言，重新选择请求的语言
+// 用户偏好优先于Accept-Language，低于请求中显式指定的lang参数和X-Language头；lang为空表示用户未设置
+func SetUserLanguage(c *gin.Context, lang string) {
+	c.Set(i18n.UserLanguageKey, lang)
+
+	i18nManager := GetI18nFromContext(c)
+	lang = i18n.GetLanguageFromContext(c)
+	if !i18nManager.IsSupported(lang) {
+		return
+	}
+	c.Set("language", lang)
+	c.Header("Content-Language", lang)
+}
+
 // GetI18nFromContext 从上下文获取i18n管理器
 func GetI18nFromContext(c *gin.Context) *i18n.I18nManager {
 	if i18nManager, exists := c.Get("i18n"); exists {
@@ -57,12 +70,7 @@ func Translate(c *gin.Context, key string, templateData map[string]interface{})
 	return i18nManager.Translate(lang, key, templateData)
 }
 
-// containsLang 检查切片是否包含指定元素
-func containsLang(slice []string, item string) bool {
-	for _, s := range slice {
-		if s == item {
-			return true
-		}
-	}
-	return false
+// TranslatePlural 按count选择复数形式翻译（从上下文获取语言），count同时作为模板数据Count
+func TranslatePlural(c *gin.Context, key string, count interface{}, templateData map[string]interface{}) string {
+	return GetI18nFromContext(c).TranslatePlural(GetLanguageFromContext(c), key, count, templateData)
 }
\ No newline at end of file
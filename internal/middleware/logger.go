@@ -1,57 +1,171 @@
 package middleware
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"exchange/internal/pkg/config"
 	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/utils"
 )
 
-// DefaultLoggerMiddleware 默认日志中间件
-func DefaultLoggerMiddleware() gin.HandlerFunc {
+// AccessLogMiddleware 访问日志中间件
+// 每个请求结束后通过logger.Access记录一条结构化访问日志（写入访问日志文件，经过敏感信息脱敏），包含路由、状态码、
+// 耗时、响应字节数、请求ID、trace_id和认证主体；错误响应按body_sample_rate附带截断后的请求体和响应体
+func AccessLogMiddleware(cfg config.LogAccessConfig) gin.HandlerFunc {
+	skip := make(map[string]bool, len(cfg.SkipPaths))
+	for _, path := range cfg.SkipPaths {
+		skip[path] = true
+	}
+
 	return func(c *gin.Context) {
-		start := time.Now()
 		path := c.Request.URL.Path
+		if skip[path] {
+			c.Next()
+			return
+		}
+		start := time.Now()
+
+		// 是否采样在请求开始时决定，采样的请求在处理过程中记录请求体和响应体的前max_body_bytes字节
+		var reqBody, respBody *accessBodyBuffer
+		if cfg.BodySampleRate > 0 && rand.Float64() < cfg.BodySampleRate {
+			reqBody = &accessBodyBuffer{limit: cfg.MaxBodyBytes}
+			respBody = &accessBodyBuffer{limit: cfg.MaxBodyBytes}
+			if c.Request.Body != nil {
+				c.Request.Body = &accessBodyReader{ReadCloser: c.Request.Body, buf: reqBody}
+			}
+			writer := &accessLogWriter{ResponseWriter: c.Writer, buf: respBody}
+			c.Writer = writer
+			defer func() {
+				c.Writer = writer.ResponseWriter
+			}()
+		}
 
 		// 处理请求
 		c.Next()
 
-		// 计算处理时间
-		latency := time.Since(start)
-
-		// 记录日志
-		appLogger.Info("HTTP Request", map[string]interface{}{
-			"method":     c.Request.Method,
-			"path":       path,
-			"status":     c.Writer.Status(),
-			"latency_ms": float64(latency.Nanoseconds()) / 1e6,
-			"client_ip":  c.ClientIP(),
-		})
+		status := c.Writer.Status()
+		entry := appLogger.FromContext(c.Request.Context()).Fields()
+		entry["method"] = c.Request.Method
+		entry["path"] = path
+		entry["route"] = utils.MetricsRoute(c)
+		entry["status"] = status
+		entry["latency_ms"] = float64(time.Since(start).Nanoseconds()) / 1e6
+		entry["bytes"] = max(c.Writer.Size(), 0)
+		entry["client_ip"] = c.ClientIP()
+		entry["user_agent"] = c.Request.UserAgent()
+		if id := GetRequestID(c); id != "" {
+			entry["request_id"] = id
+		}
+		if userID, ok := utils.GetUserID(c); ok {
+			entry["user_id"] = userID
+		}
+		if adminID, ok := utils.GetAdminID(c); ok {
+			entry["admin_id"] = adminID
+		}
+		if keyID := GetAPIKeyID(c); keyID != "" {
+			entry["api_key_id"] = keyID
+		}
+		if len(c.Errors) > 0 {
+			entry["errors"] = c.Errors.String()
+		}
+		if reqBody != nil && status >= 400 {
+			if body, ok := reqBody.value(c.Request.Header.Get("Content-Type")); ok {
+				entry["request_body"] = body
+			}
+			if body, ok := respBody.value(c.Writer.Header().Get("Content-Type")); ok {
+				entry["response_body"] = body
+			}
+		}
+
+		appLogger.Access("HTTP Request", entry)
 	}
 }
 
-// DetailedLoggerMiddleware 详细日志中间件（开发环境）
-func DetailedLoggerMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		start := time.Now()
-		path := c.Request.URL.Path
+// accessBodyBuffer 记录请求体或响应体的前limit字节
+type accessBodyBuffer struct {
+	limit     int
+	data      bytes.Buffer
+	truncated bool
+}
 
-		// 处理请求
-		c.Next()
+// write 记录数据，超出limit的部分丢弃
+func (b *accessBodyBuffer) write(p []byte) {
+	if remaining := b.limit - b.data.Len(); remaining < len(p) {
+		p = p[:max(remaining, 0)]
+		b.truncated = true
+	}
+	b.data.Write(p)
+}
+
+// value 日志中记录的内容：JSON和表单解码后记录，使日志脱敏能按字段名屏蔽密码等字段，无法解码（如被截断）时不记录；
+// 其他文本类型记录截断后的字符串；multipart和二进制内容不记录
+func (b *accessBodyBuffer) value(contentType string) (interface{}, bool) {
+	if b.data.Len() == 0 {
+		return nil, false
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var decoded interface{}
+		if b.truncated || json.Unmarshal(b.data.Bytes(), &decoded) != nil {
+			return nil, false
+		}
+		return decoded, true
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(b.data.String())
+		if b.truncated || err != nil {
+			return nil, false
+		}
+		form := make(map[string]interface{}, len(values))
+		for key := range values {
+			form[key] = values.Get(key)
+		}
+		return form, true
+	case strings.HasPrefix(mediaType, "text/"):
+		return b.data.String(), true
+	default:
+		return nil, false
+	}
+}
+
+// accessBodyReader 处理器读取请求体时记录读到的内容
+type accessBodyReader struct {
+	io.ReadCloser
+	buf *accessBodyBuffer
+}
 
-		// 计算处理时间
-		latency := time.Since(start)
-
-		// 记录详细日志
-		appLogger.Info("HTTP Request", map[string]interface{}{
-			"method":     c.Request.Method,
-			"path":       path,
-			"status":     c.Writer.Status(),
-			"latency_ms": float64(latency.Nanoseconds()) / 1e6,
-			"client_ip":  c.ClientIP(),
-			"user_agent": c.Request.UserAgent(),
-			"request_id": c.GetString("request_id"),
-		})
+// Read 读取请求体
+func (r *accessBodyReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.buf.write(p[:n])
 	}
+	return n, err
+}
+
+// accessLogWriter 写出响应体时记录写出的内容
+type accessLogWriter struct {
+	gin.ResponseWriter
+	buf *accessBodyBuffer
+}
+
+// Write 写出响应体
+func (w *accessLogWriter) Write(data []byte) (int, error) {
+	w.buf.write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 写出响应体
+func (w *accessLogWriter) WriteString(s string) (int, error) {
+	w.buf.write([]byte(s))
+	return w.ResponseWriter.WriteString(s)
 }
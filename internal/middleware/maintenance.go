@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/pkg/config"
+	appErrors "exchange/internal/pkg/errors"
+	"exchange/internal/pkg/maintenance"
+	"exchange/internal/utils"
+)
+
+// MaintenanceMiddleware 维护模式中间件
+// 维护模式开启期间，路径不以exempt_prefixes开头的请求不进入处理器，直接返回HTTP 503、Retry-After头、
+// maintenance_mode消息和错误码10011；管理后台、健康检查和指标导出默认不受影响，便于在维护期间操作和观察
+type MaintenanceMiddleware struct {
+	store *maintenance.Store
+	cfg   config.MaintenanceConfig
+}
+
+// NewMaintenanceMiddleware 创建维护模式中间件
+func NewMaintenanceMiddleware(store *maintenance.Store, cfg config.MaintenanceConfig) *MaintenanceMiddleware {
+	return &MaintenanceMiddleware{
+		store: store,
+		cfg:   cfg,
+	}
+}
+
+// Store 维护状态存储（管理接口使用）
+func (m *MaintenanceMiddleware) Store() *maintenance.Store {
+	return m.store
+}
+
+// Block 维护期间拒绝非豁免路径的请求
+func (m *MaintenanceMiddleware) Block() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if m.exempt(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		state := m.store.Current(c.Request.Context())
+		if !state.Enabled {
+			c.Next()
+			return
+		}
+
+		retryAfter := state.RetryAfter
+		if retryAfter <= 0 {
+			retryAfter = m.cfg.RetryAfter
+		}
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		utils.ErrorResponseFromError(c, "maintenance_mode", appErrors.MaintenanceError(retryAfter, state.Notice))
+		c.Abort()
+	}
+}
+
+// exempt 路径是否不受维护模式影响
+func (m *MaintenanceMiddleware) exempt(path string) bool {
+	for _, prefix := range m.cfg.ExemptPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
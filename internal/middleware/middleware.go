@@ -3,26 +3,82 @@ package middleware
 import (
 	"github.com/gin-gonic/gin"
 
+	"exchange/internal/pkg/cache"
+	"exchange/internal/pkg/config"
 	"exchange/internal/pkg/database"
+	"exchange/internal/pkg/ipaccess"
+	"exchange/internal/pkg/maintenance"
 )
 
+// rateLimitMemoryCacheSize 限流缓存管理器的内存缓存容量（限流计数只使用Redis）
+const rateLimitMemoryCacheSize = 1000
+
 // MiddlewareManager 中间件管理器
 type MiddlewareManager struct {
-	redis *database.RedisService
+	config      *config.Config
+	redis       *database.RedisService
+	cache       *cache.CacheManager
+	rateLimit   *RateLimitMiddleware
+	ipAccess    *IPAccessMiddleware
+	maintenance *MaintenanceMiddleware
 }
 
-// NewMiddlewareManager 创建中间件管理器
-func NewMiddlewareManager(redis *database.RedisService) *MiddlewareManager {
+// NewMiddlewareManager 创建中间件管理器，接口限流计数、IP名单和维护状态保存在Redis中
+func NewMiddlewareManager(redis *database.RedisService, cfg *config.Config) *MiddlewareManager {
+	var cacheManager *cache.CacheManager
+	if redis != nil {
+		memoryCache := cache.NewMemoryAdapterWithOptions(cache.MemoryCacheOptions{
+			MaxItems: rateLimitMemoryCacheSize,
+			MaxBytes: cfg.Cache.Memory.MaxBytes,
+			Policy:   cfg.Cache.Memory.Policy,
+		})
+		cacheManager = cache.NewCacheManager(memoryCache, cache.NewRedisAdapter(redis))
+		if err := cacheManager.Configure(cfg.Cache); err != nil {
+			panic("缓存初始化失败: " + err.Error())
+		}
+	}
+	ipStore, err := ipaccess.NewStore(redis, cfg.IPAccess)
+	if err != nil {
+		panic("IP名单初始化失败: " + err.Error())
+	}
 	return &MiddlewareManager{
-		redis: redis,
+		config:      cfg,
+		redis:       redis,
+		cache:       cacheManager,
+		rateLimit:   NewRateLimitMiddleware(cacheManager, cfg.RateLimit),
+		ipAccess:    NewIPAccessMiddleware(ipStore, cfg.IPAccess),
+		maintenance: NewMaintenanceMiddleware(maintenance.NewStore(redis, cfg.Maintenance), cfg.Maintenance),
 	}
 }
 
+// Cache 获取缓存管理器（Redis未配置时为nil）
+func (m *MiddlewareManager) Cache() *cache.CacheManager {
+	return m.cache
+}
+
+// RateLimit 获取接口限流中间件（需要认证的路由组在认证之后使用LimitUser）
+func (m *MiddlewareManager) RateLimit() *RateLimitMiddleware {
+	return m.rateLimit
+}
+
+// IPAccess 获取IP访问控制中间件（管理后台路由组使用AdminAllowlist）
+func (m *MiddlewareManager) IPAccess() *IPAccessMiddleware {
+	return m.ipAccess
+}
+
+// Maintenance 获取维护模式中间件（管理接口使用其状态存储）
+func (m *MiddlewareManager) Maintenance() *MaintenanceMiddleware {
+	return m.maintenance
+}
+
 // SetupCommonMiddlewares 设置通用中间件
 func (m *MiddlewareManager) SetupCommonMiddlewares(r *gin.Engine, isDevelopment bool) {
 	// 请求ID中间件（最先执行）
 	r.Use(RequestIDMiddleware())
 
+	// 请求追踪中间件（在错误处理之前，panic恢复后的500响应同样记录到span）
+	r.Use(TracingMiddleware())
+
 	// 错误处理中间件
 	r.Use(ErrorHandlerMiddleware())
 
@@ -33,16 +89,30 @@ func (m *MiddlewareManager) SetupCommonMiddlewares(r *gin.Engine, isDevelopment
 		r.Use(DefaultCORSMiddleware())
 	}
 
-	// 日志中间件
-	if isDevelopment {
-		r.Use(DetailedLoggerMiddleware())
-	} else {
-		r.Use(DefaultLoggerMiddleware())
-	}
+	// 访问日志中间件
+	r.Use(AccessLogMiddleware(m.config.Log.Access))
 
 	// 安全头中间件
 	r.Use(SecurityHeadersMiddleware())
 
+	// IP黑名单中间件
+	r.Use(m.ipAccess.Deny())
+
+	// 维护模式中间件（维护期间非豁免路径直接返回503）
+	r.Use(m.maintenance.Block())
+
+	// 并发请求数限制中间件（在进入超时、限流等需要访问Redis的中间件之前卸载负载）
+	r.Use(ConcurrencyLimitMiddleware(m.config.Concurrency))
+
+	// 请求处理超时中间件（之后的中间件和处理器使用带超时的context）
+	r.Use(RequestTimeoutMiddleware(m.config.RequestTimeout))
+
+	// 依赖服务熔断快速失败中间件（需在使用Redis的限流之前）
+	r.Use(CircuitBreakerMiddleware(m.config.CircuitBreaker))
+
+	// 接口限流中间件（按用户限流的策略在认证之后执行）
+	r.Use(m.rateLimit.Limit())
+
 	// 404处理中间件
 	r.NoRoute(func(c *gin.Context) {
 		NotFoundMiddleware()(c)
@@ -0,0 +1,256 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/pkg/cache"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	appErrors "exchange/internal/pkg/errors"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/utils"
+)
+
+// rateLimitPolicyKey Gin上下文中等待认证后按用户执行的限流策略
+const rateLimitPolicyKey = "rate_limit_policy"
+
+// rateLimitLogger 接口限流日志
+var rateLimitLogger = appLogger.Module("rate_limit")
+
+// RateLimitMiddleware 接口限流中间件
+// 按路由选择限流策略（未单独配置的路由共用默认策略），按滑动窗口或令牌桶计数，响应带X-RateLimit-Limit、
+// X-RateLimit-Remaining和X-RateLimit-Reset（距当前窗口结束的秒数，令牌桶为距补满的秒数）头；超过上限时返回429、Retry-After头和错误码10006。
+// Limit()在通用中间件中执行，此时尚未认证：按用户限流的策略在需要用户或管理员认证的路由上由认证之后的LimitUser()执行，
+// 其他路由按客户端IP执行。计数保存在Redis中，多实例共享；Redis不可用时放行请求，只记录日志
+type RateLimitMiddleware struct {
+	cache   *cache.CacheManager
+	enabled bool
+	def     *rateLimitPolicy
+	routes  map[string]*rateLimitPolicy // "METHOD 路由模板" -> 策略
+}
+
+// rateLimitPolicy 限流策略
+type rateLimitPolicy struct {
+	config.RateLimitPolicy
+	name   string // 计数键中的策略名：路由策略为路由，默认策略为default（所有路由共用配额）
+	window time.Duration
+}
+
+// rateLimitDecision 限流判断结果
+type rateLimitDecision struct {
+	allowed    bool
+	remaining  int64
+	reset      time.Duration // 距当前窗口结束或令牌桶补满的时间
+	retryAfter time.Duration // 被拒绝时建议的等待时间
+}
+
+// NewRateLimitMiddleware 创建接口限流中间件，cacheManager为nil或未启用时不限流
+func NewRateLimitMiddleware(cacheManager *cache.CacheManager, cfg config.RateLimitConfig) *RateLimitMiddleware {
+	m := &RateLimitMiddleware{
+		cache:   cacheManager,
+		enabled: cfg.Enabled && cacheManager != nil,
+		routes:  make(map[string]*rateLimitPolicy, len(cfg.Routes)),
+	}
+	if cfg.Default.Limit > 0 {
+		m.def = newRateLimitPolicy("default", cfg.Default)
+	}
+	for _, policy := range cfg.Routes {
+		m.routes[policy.Route] = newRateLimitPolicy(policy.Route, policy)
+	}
+	return m
+}
+
+// newRateLimitPolicy 创建限流策略
+func newRateLimitPolicy(name string, policy config.RateLimitPolicy) *rateLimitPolicy {
+	return &rateLimitPolicy{
+		RateLimitPolicy: policy,
+		name:            name,
+		window:          time.Duration(policy.WindowSeconds) * time.Second,
+	}
+}
+
+// Limit 接口限流中间件，在通用中间件中使用
+func (m *RateLimitMiddleware) Limit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy := m.policyFor(c)
+		if !m.enabled || policy == nil {
+			c.Next()
+			return
+		}
+
+		// 需要登录的路由认证后才能确定用户，交给LimitUser
+		if policy.Key == config.RateLimitByUser && requiresLogin(c) {
+			c.Set(rateLimitPolicyKey, policy)
+			c.Next()
+			return
+		}
+		m.enforce(c, policy, "ip:"+c.ClientIP())
+	}
+}
+
+// LimitUser 按用户执行限流策略，需在RequireAuth之后使用
+func (m *RateLimitMiddleware) LimitUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get(rateLimitPolicyKey)
+		policy, ok := value.(*rateLimitPolicy)
+		if !m.enabled || !ok {
+			c.Next()
+			return
+		}
+		m.enforce(c, policy, rateLimitSubject(c))
+	}
+}
+
+// policyFor 请求对应的限流策略，依次匹配"METHOD 路由模板"、"* 路由模板"和默认策略，不限流时返回nil
+// 未匹配路由的请求（404）使用默认策略
+func (m *RateLimitMiddleware) policyFor(c *gin.Context) *rateLimitPolicy {
+	route := c.FullPath()
+	if route != "" {
+		if policy, ok := m.routes[c.Request.Method+" "+route]; ok {
+			return limitedPolicy(policy)
+		}
+		if policy, ok := m.routes["* "+route]; ok {
+			return limitedPolicy(policy)
+		}
+	}
+	return m.def
+}
+
+// limitedPolicy limit为0的路由策略表示该路由不限流
+func limitedPolicy(policy *rateLimitPolicy) *rateLimitPolicy {
+	if policy.Limit <= 0 {
+		return nil
+	}
+	return policy
+}
+
+// enforce 计数并设置响应头，超过上限时返回429
+func (m *RateLimitMiddleware) enforce(c *gin.Context, policy *rateLimitPolicy, subject string) {
+	var (
+		decision rateLimitDecision
+		err      error
+	)
+	if policy.Algorithm == config.RateLimitTokenBucket {
+		decision, err = m.tokenBucket(policy, subject)
+	} else {
+		decision, err = m.slidingWindow(policy, subject)
+	}
+	if err != nil {
+		rateLimitLogger.WithContext(c.Request.Context()).Warn("限流计数失败，放行请求", map[string]interface{}{
+			"policy": policy.name,
+			"error":  err.Error(),
+		})
+		c.Next()
+		return
+	}
+
+	c.Header("X-RateLimit-Limit", strconv.Itoa(policy.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.FormatInt(decision.remaining, 10))
+	c.Header("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(decision.reset.Seconds()))))
+	if decision.allowed {
+		c.Next()
+		return
+	}
+
+	seconds := retryAfterSeconds(decision.retryAfter)
+	rateLimitLogger.WithContext(c.Request.Context()).Debug("请求超过限流上限", map[string]interface{}{
+		"policy":  policy.name,
+		"subject": subject,
+		"method":  c.Request.Method,
+		"route":   c.FullPath(),
+	})
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	utils.ErrorResponseFromError(c, "too_many_requests", appErrors.RateLimitedError(policy.name, policy.Limit, seconds))
+	c.Abort()
+}
+
+// enforceAPIKey 执行API key单独的限流上限（不受rate_limit.enabled影响），policy为nil或Redis不可用时直接放行
+func (m *RateLimitMiddleware) enforceAPIKey(c *gin.Context, policy *rateLimitPolicy, keyID string) {
+	if m == nil || m.cache == nil || policy == nil {
+		c.Next()
+		return
+	}
+	m.enforce(c, policy, "api_key:"+keyID)
+}
+
+// slidingWindow 滑动窗口计数：当前窗口的计数加上上一窗口的计数按剩余比例加权，被拒绝的请求不占用配额
+func (m *RateLimitMiddleware) slidingWindow(policy *rateLimitPolicy, subject string) (rateLimitDecision, error) {
+	now := clock.Now().UnixNano()
+	index := now / int64(policy.window)
+	elapsed := time.Duration(now - index*int64(policy.window))
+
+	endpoint := fmt.Sprintf("%s:%d", policy.name, index)
+	current, err := m.cache.IncrementRateLimit(subject, endpoint, 2*policy.window)
+	if err != nil {
+		return rateLimitDecision{}, err
+	}
+	// 上一窗口没有请求时计数不存在，按0计
+	previous, _ := m.cache.GetRateLimit(subject, fmt.Sprintf("%s:%d", policy.name, index-1))
+
+	weight := 1 - float64(elapsed)/float64(policy.window)
+	estimate := float64(previous)*weight + float64(current)
+	decision := rateLimitDecision{reset: policy.window - elapsed}
+	if estimate <= float64(policy.Limit) {
+		decision.allowed = true
+		decision.remaining = int64(float64(policy.Limit) - estimate)
+		return decision, nil
+	}
+
+	if err := m.cache.ReleaseRateLimit(subject, endpoint); err != nil {
+		return rateLimitDecision{}, err
+	}
+	decision.retryAfter = slidingRetryAfter(previous, current-1, policy.Limit, elapsed, policy.window)
+	return decision, nil
+}
+
+// slidingRetryAfter 滑动窗口下再次请求可被接受的等待时间，current为当前窗口不含本次请求的计数
+func slidingRetryAfter(previous, current int64, limit int, elapsed, window time.Duration) time.Duration {
+	// 当前窗口仍有余量：等待上一窗口的加权计数下降
+	if room := float64(int64(limit) - 1 - current); room >= 0 && previous > 0 {
+		at := time.Duration(float64(window) * (1 - room/float64(previous)))
+		return at - elapsed
+	}
+	// 当前窗口已满：进入下一窗口后当前窗口的计数成为上一窗口的计数
+	if current <= 0 {
+		return window - elapsed
+	}
+	at := time.Duration(float64(window) * max(0, 1-float64(limit-1)/float64(current)))
+	return window - elapsed + at
+}
+
+// tokenBucket 令牌桶：桶容量为limit，每window/limit补充一个令牌
+func (m *RateLimitMiddleware) tokenBucket(policy *rateLimitPolicy, subject string) (rateLimitDecision, error) {
+	refillEvery := policy.window / time.Duration(policy.Limit)
+	result, err := m.cache.TakeRateLimitToken(subject, policy.name, int64(policy.Limit), refillEvery)
+	if err != nil {
+		return rateLimitDecision{}, err
+	}
+	return rateLimitDecision{
+		allowed:    result.Allowed,
+		remaining:  result.Remaining,
+		reset:      time.Duration(int64(policy.Limit)-result.Remaining) * refillEvery,
+		retryAfter: result.RetryAfter,
+	}, nil
+}
+
+// requiresLogin 路由是否需要用户或管理员认证（认证之后才能按用户限流）
+func requiresLogin(c *gin.Context) bool {
+	req, _, ok := GetAuthMatrix().Lookup(c.Request.Method, c.FullPath())
+	return ok && (req.Auth == AuthUser || req.Auth == AuthAdmin)
+}
+
+// rateLimitSubject 按用户限流的计数对象，未认证时为客户端IP
+func rateLimitSubject(c *gin.Context) string {
+	if userID := c.GetUint("user_id"); userID != 0 {
+		return fmt.Sprintf("user:%d", userID)
+	}
+	if adminID := c.GetUint("admin_id"); adminID != 0 {
+		return fmt.Sprintf("admin:%d", adminID)
+	}
+	return "ip:" + c.ClientIP()
+}
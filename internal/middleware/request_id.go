@@ -1,46 +1,33 @@
 package middleware
 
 import (
-	"crypto/rand"
-	"encoding/hex"
-
 	"github.com/gin-gonic/gin"
+
+	"exchange/internal/pkg/requestid"
 )
 
 // RequestIDMiddleware 请求ID中间件
+// 沿用客户端传入的X-Request-ID（格式无效时重新生成），写入Gin上下文、请求的context.Context和响应头；
+// 日志记录器（logger.FromContext）、错误响应和错误上报据此关联同一请求
 func RequestIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 尝试从请求头获取请求ID
-		requestID := c.GetHeader("X-Request-ID")
-		
-		// 如果没有请求ID，生成一个新的
-		if requestID == "" {
-			requestID = generateRequestID()
+		requestID := c.GetHeader(requestid.Header)
+		if !requestid.Valid(requestID) {
+			requestID = requestid.Generate()
 		}
-		
-		// 设置到上下文中
-		c.Set("request_id", requestID)
-		
-		// 设置响应头
-		c.Header("X-Request-ID", requestID)
-		
+
+		c.Set(requestid.ContextKey, requestID)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), requestID))
+		c.Header(requestid.Header, requestID)
+
 		c.Next()
 	}
 }
 
-// generateRequestID 生成请求ID
-func generateRequestID() string {
-	bytes := make([]byte, 16)
-	rand.Read(bytes)
-	return hex.EncodeToString(bytes)
-}
-
 // GetRequestID 从上下文获取请求ID
 func GetRequestID(c *gin.Context) string {
-	if requestID, exists := c.Get("request_id"); exists {
-		if id, ok := requestID.(string); ok {
-			return id
-		}
+	if requestID := c.GetString(requestid.ContextKey); requestID != "" {
+		return requestID
 	}
-	return ""
-}
\ No newline at end of file
+	return requestid.FromContext(c.Request.Context())
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/database"
+	"exchange/internal/pkg/signing"
+	"exchange/internal/utils"
+)
+
+const (
+	// 已使用nonce的Redis键前缀，后接服务名和nonce
+	serviceNonceKeyPrefix = "service_auth:nonce:"
+	// 签名请求体的最大长度
+	maxSignedBodySize = 10 << 20
+)
+
+// ServiceAuthMiddleware 内部服务请求签名认证中间件
+// 内部服务之间的HTTP调用使用共享密钥签名代替JWT：验证签名、时间戳窗口，并通过Redis拒绝重复的nonce
+type ServiceAuthMiddleware struct {
+	keyring *signing.Keyring
+	redis   *database.RedisService
+	config  *config.Config
+}
+
+// NewServiceAuthMiddleware 创建内部服务认证中间件
+func NewServiceAuthMiddleware(redis *database.RedisService, cfg *config.Config, keyring *signing.Keyring) *ServiceAuthMiddleware {
+	return &ServiceAuthMiddleware{
+		keyring: keyring,
+		redis:   redis,
+		config:  cfg,
+	}
+}
+
+// RequireService 需要内部服务签名的中间件
+// services为允许访问的服务，为空时允许配置中service_auth.services列出的所有服务
+func (m *ServiceAuthMiddleware) RequireService(services ...string) gin.HandlerFunc {
+	if len(services) == 0 {
+		services = m.config.ServiceAuth.Services
+	}
+	allowed := make(map[string]bool, len(services))
+	for _, service := range services {
+		allowed[service] = true
+	}
+	window := time.Duration(m.config.ServiceAuth.ReplayWindow) * time.Second
+
+	return declareRequirement(ServiceRequirement(services...), func(c *gin.Context) {
+		if !m.config.ServiceAuth.Enabled {
+			utils.ErrorResponseWithAuth(c, "unauthorized", map[string]interface{}{"error": "service authentication is disabled"})
+			c.Abort()
+			return
+		}
+
+		sig, err := signing.ParseHeaders(c.Request.Header)
+		if err != nil {
+			utils.ErrorResponseWithAuth(c, "invalid_signature", map[string]interface{}{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		if !allowed[sig.Service] {
+			utils.ErrorResponseWithAuth(c, "insufficient_permissions", map[string]interface{}{"error": "service not allowed", "service": sig.Service})
+			c.Abort()
+			return
+		}
+
+		// 读取请求体用于校验签名，读取后恢复供后续处理器使用
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSignedBodySize))
+		if err != nil {
+			utils.ErrorResponse(c, "invalid_request", map[string]interface{}{"error": err.Error()})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		if err := m.keyring.Verify(ctx, sig, c.Request.Method, c.Request.URL.RequestURI(), body, window, time.Now()); err != nil {
+			if !errors.Is(err, signing.ErrExpired) && !errors.Is(err, signing.ErrInvalidSignature) && !errors.Is(err, signing.ErrUnknownService) {
+				// 密钥提供者不可用等内部错误
+				utils.ErrorResponseWithAuth(c, "internal_server_error", map[string]interface{}{"error": err.Error()})
+				c.Abort()
+				return
+			}
+			utils.ErrorResponseWithAuth(c, "invalid_signature", map[string]interface{}{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		// nonce在两倍时间窗口内只能使用一次，超出窗口的请求已被时间戳校验拒绝
+		key := serviceNonceKeyPrefix + sig.Service + ":" + sig.Nonce
+		fresh, err := m.redis.Client().SetNX(ctx, key, 1, 2*window).Result()
+		if err != nil {
+			utils.ErrorResponseWithAuth(c, "internal_server_error", map[string]interface{}{"error": err.Error()})
+			c.Abort()
+			return
+		}
+		if !fresh {
+			utils.ErrorResponseWithAuth(c, "invalid_signature", map[string]interface{}{"error": "nonce already used"})
+			c.Abort()
+			return
+		}
+
+		c.Set("service_name", sig.Service)
+		c.Set("user_type", "service")
+
+		c.Next()
+	})
+}
+
+// GetServiceName 从上下文获取调用方服务名
+func GetServiceName(c *gin.Context) string {
+	if service, exists := c.Get("service_name"); exists {
+		if name, ok := service.(string); ok {
+			return name
+		}
+	}
+	return ""
+}
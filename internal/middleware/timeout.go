@@ -0,0 +1,272 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/pkg/config"
+	appErrors "exchange/internal/pkg/errors"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/utils"
+)
+
+// errTimeoutHijack 请求超时中间件缓冲响应，不支持接管连接（WebSocket等路由需配置为不限制处理时间）
+var errTimeoutHijack = errors.New("hijack is not supported under request timeout")
+
+// RequestTimeoutMiddleware 请求处理超时中间件
+// 处理器使用的context（c.Request.Context()）在超时后取消；超时时中间件立即写出超时响应，处理器仍在读取请求体
+// （客户端发送过慢）时返回408，否则返回504。处理器的响应先写到缓冲区，按时完成后再写出，超时后的写入返回
+// http.ErrHandlerTimeout并被丢弃。处理器不检查context时仍会执行到结束，只是结果不再写出
+func RequestTimeoutMiddleware(cfg config.RequestTimeoutConfig) gin.HandlerFunc {
+	def := time.Duration(cfg.Default) * time.Second
+	routes := make(map[string]time.Duration, len(cfg.Routes))
+	for _, route := range cfg.Routes {
+		routes[route.Route] = time.Duration(route.Seconds) * time.Second
+	}
+
+	return func(c *gin.Context) {
+		timeout := requestTimeoutFor(c, def, routes)
+		if !cfg.Enabled || timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		// 在当前goroutine中初始化查询参数缓存，超时响应读取语言参数时不再写gin.Context
+		c.Query("lang")
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		body := &timeoutBody{ReadCloser: c.Request.Body}
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			c.Request.Body = body
+		}
+
+		writer := newTimeoutWriter(c.Writer)
+		c.Writer = writer
+
+		// 写出超时响应，请求体未读完时返回408
+		respondTimeout := func() {
+			reading := body.reading.Load()
+			err := appErrors.ErrRequestTimeout.WithContext("timeout_seconds", int(timeout.Seconds()))
+			if reading {
+				err = appErrors.ErrRequestBodyTimeout.WithContext("timeout_seconds", int(timeout.Seconds()))
+			}
+			if writer.timeout(c, err, reading) {
+				appLogger.FromContext(ctx).Warn("请求处理超时", map[string]interface{}{
+					"method":          c.Request.Method,
+					"route":           utils.MetricsRoute(c),
+					"timeout_seconds": timeout.Seconds(),
+					"error_code":      err.Code,
+				})
+			}
+		}
+		// 只有超过处理时间才写超时响应，客户端断开导致的取消不写
+		stop := context.AfterFunc(ctx, func() {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				respondTimeout()
+			}
+		})
+
+		completed := false
+		defer func() {
+			stop()
+			// 处理器在超时的同时结束（如收到context取消后返回）时同样返回超时响应，不写出处理器的结果
+			if completed && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				respondTimeout()
+			}
+			// 处理器panic时丢弃已缓冲的响应，由错误处理中间件写出500
+			writer.finish(completed)
+			c.Writer = writer.ResponseWriter
+		}()
+
+		c.Next()
+		completed = true
+	}
+}
+
+// requestTimeoutFor 请求的处理超时，依次匹配"METHOD 路由模板"、"* 路由模板"和默认值
+func requestTimeoutFor(c *gin.Context, def time.Duration, routes map[string]time.Duration) time.Duration {
+	route := c.FullPath()
+	if route != "" {
+		if timeout, ok := routes[c.Request.Method+" "+route]; ok {
+			return timeout
+		}
+		if timeout, ok := routes["* "+route]; ok {
+			return timeout
+		}
+	}
+	return def
+}
+
+// timeoutBody 记录处理器是否正在读取请求体，超时时用于区分客户端发送过慢（408）和处理过慢（504）
+type timeoutBody struct {
+	io.ReadCloser
+	reading atomic.Bool
+}
+
+// Read 读取请求体
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	b.reading.Store(true)
+	defer b.reading.Store(false)
+	return b.ReadCloser.Read(p)
+}
+
+// timeoutWriter 缓冲处理器的响应头和响应体，与写超时响应的goroutine互斥
+// 处理器只访问缓冲区，原始writer只在超时时（计时器goroutine）或按时完成后（请求goroutine）写入一次
+type timeoutWriter struct {
+	gin.ResponseWriter // 原始writer
+
+	header http.Header // 处理器的响应头，只在请求goroutine中访问
+
+	mu       sync.Mutex
+	body     bytes.Buffer
+	status   int
+	written  bool // 处理器已写出响应（gin.ResponseWriter.Written的语义）
+	timedOut bool // 已写出超时响应
+	finished bool // 处理器已结束，不再写超时响应
+}
+
+// newTimeoutWriter 创建缓冲writer，响应头从原始writer复制（保留之前的中间件设置的响应头）
+func newTimeoutWriter(w gin.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{
+		ResponseWriter: w,
+		header:         w.Header().Clone(),
+		status:         http.StatusOK,
+	}
+}
+
+// timeout 写出超时响应，处理器已结束或已写出超时响应时返回false
+// closeConn为true时（请求体未读完）关闭连接，否则net/http写响应前会等待读完剩余的请求体
+func (w *timeoutWriter) timeout(c *gin.Context, err error, closeConn bool) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.finished || w.timedOut {
+		return false
+	}
+	w.timedOut = true
+	if closeConn {
+		w.ResponseWriter.Header().Set("Connection", "close")
+	}
+	utils.WriteErrorFromError(w.ResponseWriter, c, "request_timeout", err)
+	// 处理器返回之前net/http不会发送缓冲的响应，立即刷新
+	w.ResponseWriter.Flush()
+	return true
+}
+
+// finish 处理器结束，未超时且completed时将缓冲的响应写到原始writer
+func (w *timeoutWriter) finish(completed bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.finished = true
+	if w.timedOut || !completed {
+		return
+	}
+
+	dst := w.ResponseWriter.Header()
+	for key := range dst {
+		if _, ok := w.header[key]; !ok {
+			dst.Del(key)
+		}
+	}
+	for key, values := range w.header {
+		dst[key] = values
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if !w.written {
+		return
+	}
+	if w.body.Len() == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	_, _ = w.ResponseWriter.Write(w.body.Bytes())
+}
+
+// Header 处理器的响应头
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader 设置状态码，写出响应体后不再改变
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.written && !w.timedOut {
+		w.status = code
+	}
+}
+
+// WriteHeaderNow 标记响应已写出
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.timedOut {
+		w.written = true
+	}
+}
+
+// Write 写到缓冲区，超时后返回http.ErrHandlerTimeout
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.written = true
+	return w.body.Write(data)
+}
+
+// WriteString 写到缓冲区
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Status 处理器设置的状态码
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status
+}
+
+// Size 已缓冲的响应体大小，未写出时为-1
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+// Written 响应是否已写出（超时后视为已写出，之后的中间件不再写响应）
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.written || w.timedOut
+}
+
+// Flush 响应在处理器结束后整体写出，不支持提前刷新
+func (w *timeoutWriter) Flush() {
+	w.WriteHeaderNow()
+}
+
+// Hijack 不支持接管连接
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errTimeoutHijack
+}
+
+// Pusher 不支持HTTP/2服务端推送
+func (w *timeoutWriter) Pusher() http.Pusher {
+	return nil
+}
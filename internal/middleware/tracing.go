@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"exchange/internal/pkg/requestid"
+	"exchange/internal/pkg/tracing"
+	"exchange/internal/utils"
+)
+
+// TraceIDHeader 返回链路ID的响应头，便于按请求在追踪系统中检索
+const TraceIDHeader = "X-Trace-ID"
+
+// TracingMiddleware 请求追踪中间件
+// 请求头带有traceparent时作为上游链路的子span，否则开始新链路；span随c.Request.Context()传给处理器，
+// 处理器中的数据库调用成为其子span。错误响应的AppError错误码和分类由ErrorResponseFromError记录到span。
+// 未启用追踪时直接跳过
+func TracingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !tracing.Enabled() {
+			c.Next()
+			return
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+		ctx, span := tracing.Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+				semconv.UserAgentOriginal(c.Request.UserAgent()),
+				attribute.String(requestid.ContextKey, requestid.FromContext(ctx)),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		if span.SpanContext().IsValid() {
+			c.Header(TraceIDHeader, span.SpanContext().TraceID().String())
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if userID, ok := utils.GetUserID(c); ok {
+			span.SetAttributes(semconv.UserID(strconv.FormatUint(uint64(userID), 10)))
+		}
+		if len(c.Errors) > 0 {
+			tracing.RecordError(ctx, c.Errors.Last().Err)
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
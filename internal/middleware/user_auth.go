@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -8,14 +10,31 @@ import (
 	"exchange/internal/modules/api/logic"
 	"exchange/internal/pkg/config"
 	"exchange/internal/pkg/database"
+	"exchange/internal/pkg/emailverify"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/rbac"
+	"exchange/internal/pkg/signing"
 	"exchange/internal/utils"
 )
 
+// UserLanguageResolver 查询用户保存的首选语言
+type UserLanguageResolver interface {
+	GetLanguage(ctx context.Context, userID uint) (string, error)
+}
+
+// EmailVerificationChecker 检查用户邮箱是否已验证，未验证时返回emailverify.ErrNotVerified
+type EmailVerificationChecker interface {
+	RequireVerifiedEmail(ctx context.Context, userID uint) error
+}
+
 // UserAuthMiddleware 用户认证中间件
 type UserAuthMiddleware struct {
-	authLogic logic.AuthLogic
-	redis     *database.RedisService
-	config    *config.Config
+	authLogic    logic.AuthLogic
+	apiKeys      *APIKeyAuthMiddleware
+	languages    UserLanguageResolver
+	verification EmailVerificationChecker
+	redis        *database.RedisService
+	config       *config.Config
 }
 
 // NewUserAuthMiddleware 创建用户认证中间件
@@ -31,15 +50,54 @@ func (m *UserAuthMiddleware) SetAuthLogic(authLogic logic.AuthLogic) {
 	m.authLogic = authLogic
 }
 
+// SetAPIKeyAuth 设置API key签名认证，设置后带X-API-Key头的请求使用签名认证代替JWT
+func (m *UserAuthMiddleware) SetAPIKeyAuth(apiKeys *APIKeyAuthMiddleware) {
+	m.apiKeys = apiKeys
+}
+
+// SetLanguageResolver 设置用户首选语言查询，设置后认证通过的请求按用户保存的语言本地化响应
+func (m *UserAuthMiddleware) SetLanguageResolver(languages UserLanguageResolver) {
+	m.languages = languages
+}
+
+// SetEmailVerificationChecker 设置邮箱验证检查，供RequireVerifiedEmail使用
+func (m *UserAuthMiddleware) SetEmailVerificationChecker(verification EmailVerificationChecker) {
+	m.verification = verification
+}
+
+// applyUserLanguage 按用户保存的首选语言选择请求语言，查询失败时使用请求头中的语言
+func (m *UserAuthMiddleware) applyUserLanguage(c *gin.Context, userID uint) {
+	if m.languages == nil {
+		return
+	}
+	lang, err := m.languages.GetLanguage(c.Request.Context(), userID)
+	if err != nil {
+		appLogger.Warn("查询用户首选语言失败", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if lang != "" {
+		SetUserLanguage(c, lang)
+	}
+}
+
 // RequireAuth 需要用户认证的中间件
 func (m *UserAuthMiddleware) RequireAuth() gin.HandlerFunc {
-	return func(c *gin.Context) {
+	return declareRequirement(UserRequirement(), func(c *gin.Context) {
 		if m.authLogic == nil {
 			utils.ErrorResponseWithAuth(c, "internal_server_error", map[string]interface{}{"error": "认证服务未初始化"})
 			c.Abort()
 			return
 		}
 
+		// 程序化客户端以API key签名认证
+		if m.apiKeys != nil && c.GetHeader(signing.HeaderAPIKey) != "" {
+			m.apiKeys.authenticate(c)
+			return
+		}
+
 		// 获取token
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -72,7 +130,7 @@ func (m *UserAuthMiddleware) RequireAuth() gin.HandlerFunc {
 		}
 
 		// 检查是否为用户token
-		if strings.HasPrefix(claims.Role, "admin:") {
+		if _, isAdmin := rbac.ParseAdminTokenRole(claims.Role); isAdmin {
 			utils.ErrorResponseWithAuth(c, "unauthorized", map[string]interface{}{"error": "管理员token不能用于用户接口"})
 			c.Abort()
 			return
@@ -81,14 +139,62 @@ func (m *UserAuthMiddleware) RequireAuth() gin.HandlerFunc {
 		// 将用户信息存储到上下文中
 		c.Set("user_id", claims.UserID)
 		c.Set("role", claims.Role)
+		c.Set("session_id", claims.SessionID)
+		m.applyUserLanguage(c, claims.UserID)
+
+		c.Next()
+	})
+}
+
+// RequireVerifiedEmail 需要已验证邮箱的中间件，用于提现、会话导出等敏感操作，须在RequireAuth之后使用
+// 未验证邮箱时返回403；查询失败时拒绝请求
+func (m *UserAuthMiddleware) RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetUint("user_id")
+		if userID == 0 {
+			utils.ErrorResponseWithAuth(c, "unauthorized", nil)
+			c.Abort()
+			return
+		}
+		if m.verification == nil {
+			utils.ErrorResponse(c, "internal_server_error", map[string]interface{}{"error": "邮箱验证服务未初始化"})
+			c.Abort()
+			return
+		}
+
+		err := m.verification.RequireVerifiedEmail(c.Request.Context(), userID)
+		if errors.Is(err, emailverify.ErrNotVerified) {
+			utils.Forbidden(c, "email_not_verified", nil)
+			c.Abort()
+			return
+		}
+		if err != nil {
+			utils.ErrorResponseFromError(c, "internal_server_error", err)
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
 }
 
+// RequireJWT 只允许登录token的中间件，用于会话、两步验证、注销、导出等账户管理操作，须在RequireAuth之后使用
+// API key认证的请求返回403，避免程序化客户端的key被用于撤销登录会话或修改账户安全设置
+func (m *UserAuthMiddleware) RequireJWT() gin.HandlerFunc {
+	return declareRequirement(AuthRequirement{Auth: AuthUser, JWTOnly: true}, func(c *gin.Context) {
+		if GetAPIKeyID(c) != "" {
+			utils.Forbidden(c, "login_session_required", nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	})
+}
+
 // RequireRole 需要特定角色的中间件
 func (m *UserAuthMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
-	return func(c *gin.Context) {
+	return declareRequirement(AuthRequirement{Auth: AuthUser, Roles: roles}, func(c *gin.Context) {
 		// 先进行认证
 		m.RequireAuth()(c)
 		if c.IsAborted() {
@@ -106,7 +212,7 @@ func (m *UserAuthMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
 
 		utils.ErrorResponseWithAuth(c, "forbidden", map[string]interface{}{"error": "权限不足"})
 		c.Abort()
-	}
+	})
 }
 
 // OptionalAuth 可选的认证中间件
@@ -133,9 +239,10 @@ func (m *UserAuthMiddleware) OptionalAuth() gin.HandlerFunc {
 		// 尝试验证token
 		if m.authLogic != nil {
 			if claims, err := m.authLogic.ValidateToken(token); err == nil {
-				if !strings.HasPrefix(claims.Role, "admin:") {
+				if _, isAdmin := rbac.ParseAdminTokenRole(claims.Role); !isAdmin {
 					c.Set("user_id", claims.UserID)
 					c.Set("role", claims.Role)
+					m.applyUserLanguage(c, claims.UserID)
 				}
 			}
 		}
@@ -0,0 +1,42 @@
+package mongodb
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AdminAuditLog 管理员操作审计记录
+// 管理后台的每个写操作（包括失败和被拒绝的请求）记录一条，包含操作者、操作对象、变更前后的数据和请求信息
+type AdminAuditLog struct {
+	ID         primitive.ObjectID          `json:"id" bson:"_id,omitempty"`
+	ActorID    uint                        `json:"actor_id" bson:"actor_id"`
+	ActorRole  string                      `json:"actor_role" bson:"actor_role"`
+	Action     string                      `json:"action" bson:"action"`                       // 操作，处理器未指定时为"方法 路由"
+	TargetType string                      `json:"target_type" bson:"target_type"`             // 操作对象类型，如user、role
+	TargetID   string                      `json:"target_id" bson:"target_id"`                 // 操作对象ID
+	Params     map[string]string           `json:"params,omitempty" bson:"params,omitempty"`   // 路由参数
+	Before     map[string]interface{}      `json:"before,omitempty" bson:"before,omitempty"`   // 变更前的数据
+	After      map[string]interface{}      `json:"after,omitempty" bson:"after,omitempty"`     // 变更后的数据
+	Changes    map[string]AdminAuditChange `json:"changes,omitempty" bson:"changes,omitempty"` // 变更的字段
+	Method     string                      `json:"method" bson:"method"`
+	Path       string                      `json:"path" bson:"path"` // 路由模板
+	Status     int                         `json:"status" bson:"status"`
+	Success    bool                        `json:"success" bson:"success"`
+	Error      string                      `json:"error,omitempty" bson:"error,omitempty"` // 失败时的响应消息键
+	IP         string                      `json:"ip" bson:"ip"`
+	UserAgent  string                      `json:"user_agent" bson:"user_agent"`
+	RequestID  string                      `json:"request_id" bson:"request_id"`
+	CreatedAt  time.Time                   `json:"created_at" bson:"created_at"`
+}
+
+// AdminAuditChange 字段变更前后的值，新增的字段Before为空，删除的字段After为空
+type AdminAuditChange struct {
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
+
+// CollectionName 返回集合名称
+func (AdminAuditLog) CollectionName() string {
+	return "admin_audit_logs"
+}
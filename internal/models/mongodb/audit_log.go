@@ -0,0 +1,27 @@
+package mongodb
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditLog 审计日志模型
+// 记录按Seq组成哈希链：Payload为参与哈希的规范化JSON（包含PrevHash），Hash = sha256(Payload)，
+// 修改、删除或插入任一记录都会使后续记录的校验失败
+type AuditLog struct {
+	ID        primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	Seq       int64                  `json:"seq" bson:"seq"`
+	Service   string                 `json:"service" bson:"service"`
+	Message   string                 `json:"message" bson:"message"`
+	Fields    map[string]interface{} `json:"fields" bson:"fields"`
+	CreatedAt time.Time              `json:"created_at" bson:"created_at"`
+	PrevHash  string                 `json:"prev_hash" bson:"prev_hash"`
+	Hash      string                 `json:"hash" bson:"hash"`
+	Payload   string                 `json:"payload" bson:"payload"`
+}
+
+// CollectionName 返回集合名称
+func (AuditLog) CollectionName() string {
+	return "audit_logs"
+}
@@ -0,0 +1,56 @@
+package mongodb
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GroupRole 群成员角色
+type GroupRole string
+
+const (
+	GroupRoleOwner  GroupRole = "owner"  // 群主，每个群只有一个，可以管理管理员和解散群
+	GroupRoleAdmin  GroupRole = "admin"  // 管理员，可以修改群资料、邀请和移除普通成员
+	GroupRoleMember GroupRole = "member" // 普通成员
+)
+
+// ChatGroup 群聊
+// 群消息只保存一份（chat_messages中group_id为群ID），成员列表保存在chat_group_members中
+type ChatGroup struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description,omitempty" bson:"description,omitempty"`
+	OwnerID     string             `json:"owner_id" bson:"owner_id"`
+	CreatorID   string             `json:"creator_id" bson:"creator_id"`     // 创建者，群主转让后不变，用于统计每个用户创建的群数
+	MemberCount int                `json:"member_count" bson:"member_count"` // 成员数（含群主），加入和移除成员时原子更新
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// CollectionName 返回集合名称
+func (ChatGroup) CollectionName() string {
+	return "chat_groups"
+}
+
+// ChatGroupMember 群成员
+// 每个成员有自己的已读位置（最后一条已读消息的created_at和_id），未读数为该位置之后其他成员发送的消息数
+type ChatGroupMember struct {
+	ID                primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	GroupID           string             `json:"group_id" bson:"group_id"`
+	UserID            string             `json:"user_id" bson:"user_id"`
+	Role              GroupRole          `json:"role" bson:"role"`
+	LastReadMessageID string             `json:"last_read_message_id,omitempty" bson:"last_read_message_id,omitempty"`
+	LastReadAt        *time.Time         `json:"last_read_at,omitempty" bson:"last_read_at,omitempty"` // 最后一条已读消息的发送时间
+	JoinedAt          time.Time          `json:"joined_at" bson:"joined_at"`
+}
+
+// CollectionName 返回集合名称
+func (ChatGroupMember) CollectionName() string {
+	return "chat_group_members"
+}
+
+// CanManage 是否可以修改群资料和管理普通成员
+func (m *ChatGroupMember) CanManage() bool {
+	return m.Role == GroupRoleOwner || m.Role == GroupRoleAdmin
+}
@@ -2,9 +2,12 @@ package mongodb
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"exchange/internal/pkg/clock"
 )
 
 // MessageType 消息类型
@@ -18,17 +21,53 @@ const (
 	MessageTypeVideo MessageType = "video"
 )
 
+// MessageStatus 单聊消息的投递状态，只会按sent → delivered → read前进
+type MessageStatus string
+
+const (
+	MessageStatusSent      MessageStatus = "sent"      // 已保存，尚未投递到接收方
+	MessageStatusDelivered MessageStatus = "delivered" // 接收方的WebSocket连接已确认收到
+	MessageStatusRead      MessageStatus = "read"      // 接收方已读
+)
+
+// MessageStatusChange 消息状态变化记录
+type MessageStatusChange struct {
+	Status MessageStatus `json:"status"`
+	At     time.Time     `json:"at"`
+}
+
+// DeletedMessageContent 已删除的消息展示给用户时的内容
+const DeletedMessageContent = "message deleted"
+
+// MessageEdit 消息修改历史，记录被替换的内容和修改时间
+type MessageEdit struct {
+	Content  string    `json:"content" bson:"content"`
+	EditedAt time.Time `json:"edited_at" bson:"edited_at"`
+}
+
 // ChatMessage 聊天消息模型
 type ChatMessage struct {
 	ID          primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
 	FromUserID  string                 `json:"from_user_id" bson:"from_user_id"`
 	ToUserID    string                 `json:"to_user_id" bson:"to_user_id"`
+	GroupID     string                 `json:"group_id,omitempty" bson:"group_id,omitempty"` // 群聊消息所属的群，此时to_user_id为空
 	MessageType MessageType            `json:"message_type" bson:"message_type"`
 	Content     string                 `json:"content" bson:"content"`
 	Metadata    map[string]interface{} `json:"metadata" bson:"metadata"`
 	IsRead      bool                   `json:"is_read" bson:"is_read"`
 	CreatedAt   time.Time              `json:"created_at" bson:"created_at"`
 	UpdatedAt   time.Time              `json:"updated_at" bson:"updated_at"`
+	// 修改历史（从旧到新）和最后修改时间，未修改过时为空
+	EditHistory []MessageEdit `json:"edit_history,omitempty" bson:"edit_history,omitempty"`
+	EditedAt    *time.Time    `json:"edited_at,omitempty" bson:"edited_at,omitempty"`
+	// 删除时间，软删除的消息保留原内容，展示时替换为DeletedMessageContent
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	// 单聊消息的投递状态和各状态的时间（发送时间为created_at），群聊消息使用成员的已读位置
+	Status      MessageStatus `json:"status,omitempty" bson:"status,omitempty"`
+	DeliveredAt *time.Time    `json:"delivered_at,omitempty" bson:"delivered_at,omitempty"`
+	ReadAt      *time.Time    `json:"read_at,omitempty" bson:"read_at,omitempty"`
+	// 状态时间线，不保存，返回给用户前由FillStatus生成
+	StatusTimeline []MessageStatusChange `json:"status_timeline,omitempty" bson:"-"`
 }
 
 // CollectionName 返回集合名称
@@ -36,18 +75,27 @@ func (ChatMessage) CollectionName() string {
 	return "chat_messages"
 }
 
+// ArchivedMessageCollection 归档消息的冷集合，文档结构与chat_messages相同
+const ArchivedMessageCollection = "chat_messages_archive"
+
 // Validate 验证消息数据
 func (cm *ChatMessage) Validate() error {
 	if cm.FromUserID == "" {
 		return errors.New("from_user_id is required")
 	}
 	
-	if cm.ToUserID == "" {
-		return errors.New("to_user_id is required")
-	}
-	
-	if cm.FromUserID == cm.ToUserID {
-		return errors.New("cannot send message to yourself")
+	if cm.IsGroupMessage() {
+		if cm.ToUserID != "" {
+			return errors.New("group message cannot have to_user_id")
+		}
+	} else {
+		if cm.ToUserID == "" {
+			return errors.New("to_user_id is required")
+		}
+
+		if cm.FromUserID == cm.ToUserID {
+			return errors.New("cannot send message to yourself")
+		}
 	}
 	
 	if cm.Content == "" {
@@ -82,7 +130,7 @@ func (cm *ChatMessage) Validate() error {
 			return errors.New("text message too long (max 5000 characters)")
 		}
 	case MessageTypeImage, MessageTypeFile, MessageTypeAudio, MessageTypeVideo:
-		// 对于文件类型，content应该是文件URL或路径
+		// 对于文件类型，content为附件在对象存储中的key（旧消息为文件路径）
 		if len(cm.Content) > 500 {
 			return errors.New("file path too long (max 500 characters)")
 		}
@@ -93,21 +141,80 @@ func (cm *ChatMessage) Validate() error {
 
 // SetTimestamps 设置时间戳
 func (cm *ChatMessage) SetTimestamps() {
-	now := time.Now()
+	now := clock.Now()
 	if cm.CreatedAt.IsZero() {
 		cm.CreatedAt = now
 	}
 	cm.UpdatedAt = now
 }
 
+// IsDeleted 是否已删除
+func (cm *ChatMessage) IsDeleted() bool {
+	return cm.DeletedAt != nil
+}
+
+// Redact 已删除的消息隐藏原内容、元数据和修改历史，用于返回给用户
+func (cm *ChatMessage) Redact() {
+	if !cm.IsDeleted() {
+		return
+	}
+	cm.Content = DeletedMessageContent
+	cm.Metadata = nil
+	cm.EditHistory = nil
+}
+
 // MarkAsRead 标记为已读
 func (cm *ChatMessage) MarkAsRead() {
+	now := clock.Now()
 	cm.IsRead = true
-	cm.UpdatedAt = time.Now()
+	cm.Status = MessageStatusRead
+	cm.ReadAt = &now
+	if cm.DeliveredAt == nil {
+		cm.DeliveredAt = &now
+	}
+	cm.UpdatedAt = now
+}
+
+// CurrentStatus 当前投递状态，兼容没有记录状态的旧消息（按is_read判断）
+func (cm *ChatMessage) CurrentStatus() MessageStatus {
+	switch {
+	case cm.IsGroupMessage():
+		return ""
+	case cm.ReadAt != nil || cm.IsRead:
+		return MessageStatusRead
+	case cm.DeliveredAt != nil:
+		return MessageStatusDelivered
+	default:
+		return MessageStatusSent
+	}
+}
+
+// FillStatus 设置当前状态和状态时间线（按时间先后排列），用于返回给用户
+// 旧消息没有投递和已读时间时时间线只包含发送
+func (cm *ChatMessage) FillStatus() {
+	if cm.IsGroupMessage() {
+		return
+	}
+	cm.Status = cm.CurrentStatus()
+	cm.StatusTimeline = []MessageStatusChange{{Status: MessageStatusSent, At: cm.CreatedAt}}
+	if cm.DeliveredAt != nil {
+		cm.StatusTimeline = append(cm.StatusTimeline, MessageStatusChange{Status: MessageStatusDelivered, At: *cm.DeliveredAt})
+	}
+	if cm.ReadAt != nil {
+		cm.StatusTimeline = append(cm.StatusTimeline, MessageStatusChange{Status: MessageStatusRead, At: *cm.ReadAt})
+	}
+}
+
+// IsGroupMessage 是否为群聊消息
+func (cm *ChatMessage) IsGroupMessage() bool {
+	return cm.GroupID != ""
 }
 
 // GetConversationID 获取会话ID（用于索引和查询）
 func (cm *ChatMessage) GetConversationID() string {
+	if cm.IsGroupMessage() {
+		return "group_" + cm.GroupID
+	}
 	// 确保会话ID的一致性，较小的用户ID在前
 	if cm.FromUserID < cm.ToUserID {
 		return cm.FromUserID + "_" + cm.ToUserID
@@ -140,6 +247,9 @@ func (cm *ChatMessage) GetFileInfo() map[string]interface{} {
 		if mimeType, ok := cm.Metadata["mime_type"]; ok {
 			fileInfo["mime_type"] = mimeType
 		}
+		if objectKey, ok := cm.Metadata["object_key"]; ok {
+			fileInfo["object_key"] = objectKey
+		}
 	}
 	
 	return fileInfo
@@ -156,6 +266,29 @@ func (cm *ChatMessage) SetFileInfo(fileName string, fileSize int64, mimeType str
 	cm.Metadata["mime_type"] = mimeType
 }
 
+// ObjectKey 附件在对象存储中的key，旧的本地路径文件消息没有key
+func (cm *ChatMessage) ObjectKey() string {
+	if !cm.IsFileMessage() || cm.Metadata == nil {
+		return ""
+	}
+	objectKey, _ := cm.Metadata["object_key"].(string)
+	return objectKey
+}
+
+// FileMessageType 按MIME类型确定文件消息的类型
+func FileMessageType(mimeType string) MessageType {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return MessageTypeImage
+	case strings.HasPrefix(mimeType, "audio/"):
+		return MessageTypeAudio
+	case strings.HasPrefix(mimeType, "video/"):
+		return MessageTypeVideo
+	default:
+		return MessageTypeFile
+	}
+}
+
 // CreateTextMessage 创建文本消息
 func CreateTextMessage(fromUserID, toUserID, content string) *ChatMessage {
 	msg := &ChatMessage{
@@ -169,16 +302,37 @@ func CreateTextMessage(fromUserID, toUserID, content string) *ChatMessage {
 	return msg
 }
 
-// CreateFileMessage 创建文件消息
-func CreateFileMessage(fromUserID, toUserID, filePath string, messageType MessageType, fileName string, fileSize int64, mimeType string) *ChatMessage {
+// CreateGroupTextMessage 创建群聊文本消息
+func CreateGroupTextMessage(fromUserID, groupID, content string) *ChatMessage {
+	msg := &ChatMessage{
+		FromUserID:  fromUserID,
+		GroupID:     groupID,
+		MessageType: MessageTypeText,
+		Content:     content,
+		IsRead:      false,
+	}
+	msg.SetTimestamps()
+	return msg
+}
+
+// CreateFileMessage 创建文件消息，只保存附件的对象key，下载链接在读取时生成
+func CreateFileMessage(fromUserID, toUserID, objectKey string, messageType MessageType, fileName string, fileSize int64, mimeType string) *ChatMessage {
 	msg := &ChatMessage{
 		FromUserID:  fromUserID,
 		ToUserID:    toUserID,
 		MessageType: messageType,
-		Content:     filePath,
+		Content:     objectKey,
 		IsRead:      false,
 	}
 	msg.SetFileInfo(fileName, fileSize, mimeType)
+	msg.Metadata["object_key"] = objectKey
 	msg.SetTimestamps()
 	return msg
+}
+
+// CreateGroupFileMessage 创建群聊文件消息
+func CreateGroupFileMessage(fromUserID, groupID, objectKey string, messageType MessageType, fileName string, fileSize int64, mimeType string) *ChatMessage {
+	msg := CreateFileMessage(fromUserID, "", objectKey, messageType, fileName, fileSize, mimeType)
+	msg.GroupID = groupID
+	return msg
 }
\ No newline at end of file
@@ -0,0 +1,26 @@
+package mongodb
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DomainEvent 领域事件记录
+// 事件总线上发布的事件按EventID去重后追加写入，不修改，供按时间范围重放和导出到数据仓库
+type DomainEvent struct {
+	ID            primitive.ObjectID     `json:"-" bson:"_id,omitempty"`
+	EventID       string                 `json:"event_id" bson:"event_id"`
+	Type          string                 `json:"type" bson:"type"`
+	UserID        uint                   `json:"user_id" bson:"user_id"`
+	Language      string                 `json:"language" bson:"language"`
+	Data          map[string]interface{} `json:"data" bson:"data"`
+	OccurredAt    time.Time              `json:"occurred_at" bson:"occurred_at"`
+	RecordedAt    time.Time              `json:"recorded_at" bson:"recorded_at"`
+	SchemaVersion int                    `json:"schema_version" bson:"schema_version"`
+}
+
+// CollectionName 返回集合名称
+func (DomainEvent) CollectionName() string {
+	return "domain_events"
+}
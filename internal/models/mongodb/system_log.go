@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"exchange/internal/pkg/clock"
 )
 
 // SystemLog 系统日志模型
@@ -56,7 +58,7 @@ func (sl *SystemLog) Validate() error {
 // SetTimestamp 设置时间戳
 func (sl *SystemLog) SetTimestamp() {
 	if sl.Timestamp.IsZero() {
-		sl.Timestamp = time.Now()
+		sl.Timestamp = clock.Now()
 	}
 }
 
@@ -0,0 +1,49 @@
+package mongodb
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"exchange/internal/pkg/clock"
+)
+
+// TranslationOverride 管理后台编辑的翻译，覆盖翻译文件中同一语言的同名消息
+type TranslationOverride struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Language  string             `json:"language" bson:"language"`
+	Key       string             `json:"key" bson:"key"`
+	Forms     map[string]string  `json:"forms" bson:"forms"` // 复数形式(zero/one/two/few/many/other)到消息模板，至少包含other
+	UpdatedBy uint               `json:"updated_by" bson:"updated_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// CollectionName 返回集合名称
+func (TranslationOverride) CollectionName() string {
+	return "translation_overrides"
+}
+
+// Validate 验证翻译覆盖数据
+func (t *TranslationOverride) Validate() error {
+	if t.Language == "" {
+		return errors.New("language is required")
+	}
+	if t.Key == "" {
+		return errors.New("key is required")
+	}
+	if t.Forms["other"] == "" {
+		return errors.New("other form is required")
+	}
+	return nil
+}
+
+// SetTimestamps 设置时间戳
+func (t *TranslationOverride) SetTimestamps() {
+	now := clock.Now()
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = now
+	}
+	t.UpdatedAt = now
+}
@@ -0,0 +1,59 @@
+package mysql
+
+import (
+	"errors"
+	"time"
+)
+
+// AccountDeletionStatus 账户注销请求状态
+type AccountDeletionStatus string
+
+const (
+	AccountDeletionStatusPending   AccountDeletionStatus = "pending"   // 冷静期中，等待执行
+	AccountDeletionStatusCancelled AccountDeletionStatus = "cancelled" // 用户已撤销
+	AccountDeletionStatusCompleted AccountDeletionStatus = "completed" // 已完成匿名化
+	AccountDeletionStatusRejected  AccountDeletionStatus = "rejected"  // 执行时校验未通过（如存在未完成订单/余额）
+)
+
+// AccountDeletionRequest 账户注销请求模型
+type AccountDeletionRequest struct {
+	BaseModel
+	UserID       uint                  `json:"user_id" gorm:"not null;index"`
+	Status       AccountDeletionStatus `json:"status" gorm:"type:enum('pending','cancelled','completed','rejected');default:'pending';index"`
+	Reason       string                `json:"reason" gorm:"size:500"`
+	ScheduledAt  int64                 `json:"scheduled_at" gorm:"not null;index"` // 计划执行时间（纳秒时间戳），冷静期结束
+	CompletedAt  int64                 `json:"completed_at"`                       // 完成/撤销/拒绝时间（纳秒时间戳）
+	RejectReason string                `json:"reject_reason" gorm:"size:500"`
+}
+
+// TableName 指定表名
+func (AccountDeletionRequest) TableName() string {
+	return "account_deletion_requests"
+}
+
+// IsPending 是否处于冷静期
+func (r *AccountDeletionRequest) IsPending() bool {
+	return r.Status == AccountDeletionStatusPending
+}
+
+// IsDue 冷静期是否已结束
+func (r *AccountDeletionRequest) IsDue(now time.Time) bool {
+	return r.IsPending() && now.UnixNano() >= r.ScheduledAt
+}
+
+// Validate 验证注销请求数据
+func (r *AccountDeletionRequest) Validate() error {
+	if r.UserID == 0 {
+		return errors.New("user_id is required")
+	}
+
+	if r.ScheduledAt == 0 {
+		return errors.New("scheduled_at is required")
+	}
+
+	if len(r.Reason) > 500 {
+		return errors.New("reason must be less than 500 characters")
+	}
+
+	return nil
+}
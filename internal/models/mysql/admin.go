@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"exchange/internal/pkg/clock"
 	"exchange/internal/utils"
 
 	"golang.org/x/crypto/bcrypt"
@@ -33,11 +34,13 @@ type Admin struct {
 	Username     string      `json:"username" gorm:"uniqueIndex;size:50;not null"`
 	Email        string      `json:"email" gorm:"uniqueIndex;size:100;not null"`
 	PasswordHash string      `json:"-" gorm:"size:255;not null"`
-	Role         AdminRole   `json:"role" gorm:"type:enum('super','admin');default:'admin'"`
+	Role         AdminRole   `json:"role" gorm:"size:50;not null;default:'admin'"` // 角色，权限见roles表和rbac.BuiltinRoles
 	Status       AdminStatus `json:"status" gorm:"type:enum('active','inactive','banned');default:'active'"`
 	LastLoginAt  *time.Time  `json:"last_login_at" gorm:"type:timestamp null"`
 	LoginCount   int         `json:"login_count" gorm:"default:0"`
 	CreatedBy    uint        `json:"created_by" gorm:"default:0"` // 创建者ID
+
+	TwoFactorEnabled bool `json:"two_factor_enabled" gorm:"not null;default:false"` // 是否已启用两步验证，密钥见two_factor_credentials表
 }
 
 // TableName 指定表名
@@ -99,7 +102,7 @@ func (a *Admin) CanLogin() bool {
 
 // UpdateLoginInfo 更新登录信息
 func (a *Admin) UpdateLoginInfo() {
-	now := time.Now()
+	now := clock.Now()
 	a.LastLoginAt = &now
 	a.LoginCount++
 }
@@ -114,11 +117,8 @@ func (a *Admin) Validate() error {
 		return err
 	}
 
-	// 验证角色
-	switch a.Role {
-	case AdminRoleSuper, AdminRoleAdmin:
-		// 角色有效
-	default:
+	// 验证角色（角色是否存在由业务逻辑按roles表检查）
+	if err := ValidateRoleName(string(a.Role)); err != nil {
 		return errors.New("invalid admin role")
 	}
 
@@ -146,6 +146,8 @@ func (a *Admin) ToPublicAdmin() *PublicAdmin {
 		CreatedBy:   a.CreatedBy,
 		CreatedAt:   a.CreatedAt,
 		UpdatedAt:   a.UpdatedAt,
+
+		TwoFactorEnabled: a.TwoFactorEnabled,
 	}
 }
 
@@ -161,4 +163,6 @@ type PublicAdmin struct {
 	CreatedBy   uint        `json:"created_by"`
 	CreatedAt   int64       `json:"created_at"`
 	UpdatedAt   int64       `json:"updated_at"`
+
+	TwoFactorEnabled bool `json:"two_factor_enabled"`
 }
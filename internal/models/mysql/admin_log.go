@@ -16,6 +16,12 @@ const (
 	AdminLogActionLogout AdminLogAction = "logout"
 	AdminLogActionView   AdminLogAction = "view"
 	AdminLogActionExport AdminLogAction = "export"
+
+	// 定时任务控制操作
+	AdminLogActionTrigger AdminLogAction = "trigger"
+	AdminLogActionPause   AdminLogAction = "pause"
+	AdminLogActionResume  AdminLogAction = "resume"
+	AdminLogActionKill    AdminLogAction = "kill"
 )
 
 // AdminLogTargetType 操作目标类型
@@ -25,6 +31,7 @@ const (
 	AdminLogTargetUser   AdminLogTargetType = "user"
 	AdminLogTargetSystem AdminLogTargetType = "system"
 	AdminLogTargetConfig AdminLogTargetType = "config"
+	AdminLogTargetTask   AdminLogTargetType = "task"
 )
 
 // AdminLog 管理员操作日志模型
@@ -91,6 +98,10 @@ func (al *AdminLog) Validate() error {
 		AdminLogActionLogout,
 		AdminLogActionView,
 		AdminLogActionExport,
+		AdminLogActionTrigger,
+		AdminLogActionPause,
+		AdminLogActionResume,
+		AdminLogActionKill,
 	}
 	
 	isValidAction := false
@@ -111,6 +122,7 @@ func (al *AdminLog) Validate() error {
 			AdminLogTargetUser,
 			AdminLogTargetSystem,
 			AdminLogTargetConfig,
+			AdminLogTargetTask,
 		}
 		
 		isValidTargetType := false
@@ -180,4 +192,22 @@ func CreateConfigLog(adminID uint, action AdminLogAction, configKey string, deta
 	}
 	
 	return log
-}
\ No newline at end of file
+}
+
+// CreateTaskLog 创建定时任务控制操作日志
+func CreateTaskLog(adminID uint, action AdminLogAction, taskName string, details interface{}, ipAddress, userAgent string) *AdminLog {
+	log := &AdminLog{
+		AdminID:    adminID,
+		Action:     action,
+		TargetType: AdminLogTargetTask,
+		TargetID:   taskName,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+	}
+	
+	if details != nil {
+		log.SetDetails(details)
+	}
+	
+	return log
+}
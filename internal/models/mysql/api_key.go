@@ -0,0 +1,92 @@
+package mysql
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// APIKey 用户创建的API key，供机器人等程序化客户端代替JWT访问用户接口
+// 密钥只保存SHA-256哈希，明文只在创建时返回一次；删除后立即失效
+type APIKey struct {
+	BaseModel
+	KeyID         string     `json:"key_id" gorm:"uniqueIndex;size:40;not null"`      // 公开的key ID，请求头X-API-Key
+	UserID        uint       `json:"user_id" gorm:"index;not null"`                   // 所属用户，认证后以该用户身份访问
+	Name          string     `json:"name" gorm:"size:100;not null"`                   // 用户填写的名称，便于区分用途
+	SecretHash    string     `json:"-" gorm:"size:64;not null"`                       // 密钥的SHA-256哈希（十六进制）
+	Scopes        string     `json:"-" gorm:"size:100;not null"`                      // 权限，逗号分隔（read、write）
+	AllowedIPs    string     `json:"-" gorm:"type:text"`                              // 允许使用的IP或CIDR，逗号分隔，为空表示不限制
+	RateLimit     int        `json:"rate_limit" gorm:"not null;default:0"`            // 每个窗口的请求数上限，0表示只受接口限流约束
+	WindowSeconds int        `json:"window_seconds" gorm:"not null;default:0"`        // 限流窗口长度(秒)
+	ExpiresAt     *time.Time `json:"expires_at" gorm:"type:timestamp null"`           // 过期时间，为空表示不过期
+	LastUsedAt    *time.Time `json:"last_used_at" gorm:"type:timestamp null"`         // 最近一次认证通过的时间
+	LastUsedIP    string     `json:"last_used_ip" gorm:"size:45;not null;default:''"` // 最近一次认证通过的客户端IP
+}
+
+// TableName 指定表名
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// ScopeList 权限列表
+func (k *APIKey) ScopeList() []string {
+	return splitList(k.Scopes)
+}
+
+// HasScope 是否有指定权限
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedIPList 允许使用的IP或CIDR列表
+func (k *APIKey) AllowedIPList() []string {
+	return splitList(k.AllowedIPs)
+}
+
+// SetScopes 设置权限
+func (k *APIKey) SetScopes(scopes []string) {
+	k.Scopes = strings.Join(scopes, ",")
+}
+
+// SetAllowedIPs 设置允许使用的IP或CIDR
+func (k *APIKey) SetAllowedIPs(ips []string) {
+	k.AllowedIPs = strings.Join(ips, ",")
+}
+
+// IsExpired 是否已过期
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// Validate 验证API key
+func (k *APIKey) Validate() error {
+	if k.KeyID == "" || k.UserID == 0 {
+		return errors.New("api key id and user id are required")
+	}
+	if strings.TrimSpace(k.Name) == "" {
+		return errors.New("api key name is required")
+	}
+	if k.SecretHash == "" {
+		return errors.New("api key secret hash is required")
+	}
+	if len(k.ScopeList()) == 0 {
+		return errors.New("api key scopes are required")
+	}
+	return nil
+}
+
+// splitList 拆分逗号分隔的列表，忽略空项
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,99 @@
+package mysql
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// AutomationTrigger 自动化规则触发事件，取值与事件总线的事件类型一致
+type AutomationTrigger string
+
+const (
+	AutomationTriggerSignup       AutomationTrigger = "user.registered" // 用户注册
+	AutomationTriggerFirstDeposit AutomationTrigger = "deposit.first"   // 首次充值
+	AutomationTriggerKYCApproved  AutomationTrigger = "kyc.approved"    // KYC审核通过
+)
+
+// AutomationTriggers 所有支持的触发事件
+var AutomationTriggers = []AutomationTrigger{
+	AutomationTriggerSignup,
+	AutomationTriggerFirstDeposit,
+	AutomationTriggerKYCApproved,
+}
+
+// IsValid 是否为支持的触发事件
+func (t AutomationTrigger) IsValid() bool {
+	for _, trigger := range AutomationTriggers {
+		if t == trigger {
+			return true
+		}
+	}
+	return false
+}
+
+// MessageTemplate 单个语言的消息模板（Go text/template语法）
+type MessageTemplate struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// AutomationRule 消息自动化规则
+// 触发事件发生时，按用户语言选择模板渲染后通过通知框架发送给用户
+type AutomationRule struct {
+	BaseModel
+	Name      string            `json:"name" gorm:"size:100;not null"`
+	Trigger   AutomationTrigger `json:"trigger" gorm:"type:enum('user.registered','deposit.first','kyc.approved');not null;index"`
+	Enabled   bool              `json:"enabled" gorm:"index"`
+	Templates string            `json:"-" gorm:"type:json"` // 按语言的消息模板（JSON），键为语言代码
+	CreatedBy uint              `json:"created_by"`
+	UpdatedBy uint              `json:"updated_by"`
+}
+
+// TableName 指定表名
+func (AutomationRule) TableName() string {
+	return "automation_rules"
+}
+
+// SetTemplates 设置消息模板
+func (r *AutomationRule) SetTemplates(templates map[string]MessageTemplate) error {
+	data, err := json.Marshal(templates)
+	if err != nil {
+		return err
+	}
+	r.Templates = string(data)
+	return nil
+}
+
+// GetTemplates 获取消息模板
+func (r *AutomationRule) GetTemplates() (map[string]MessageTemplate, error) {
+	templates := make(map[string]MessageTemplate)
+	if r.Templates == "" {
+		return templates, nil
+	}
+
+	if err := json.Unmarshal([]byte(r.Templates), &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// Validate 验证规则数据
+func (r *AutomationRule) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+
+	if !r.Trigger.IsValid() {
+		return errors.New("invalid trigger")
+	}
+
+	templates, err := r.GetTemplates()
+	if err != nil {
+		return errors.New("invalid templates")
+	}
+	if len(templates) == 0 {
+		return errors.New("at least one template is required")
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package mysql
+
+// ChatExportStatus 会话导出任务状态
+type ChatExportStatus string
+
+const (
+	ChatExportStatusPending   ChatExportStatus = "pending"   // 等待执行
+	ChatExportStatusRunning   ChatExportStatus = "running"   // 生成中
+	ChatExportStatusCompleted ChatExportStatus = "completed" // 已完成，可下载
+	ChatExportStatusFailed    ChatExportStatus = "failed"    // 生成失败
+	ChatExportStatusExpired   ChatExportStatus = "expired"   // 文件已过期删除
+)
+
+// ChatExportJob 用户会话导出任务
+type ChatExportJob struct {
+	BaseModel
+	UserID        uint             `json:"user_id" gorm:"not null;index"` // 发起导出的用户
+	PeerID        uint             `json:"peer_id" gorm:"not null"`       // 会话对方
+	Format        string           `json:"format" gorm:"type:enum('json','html');not null"`
+	Status        ChatExportStatus `json:"status" gorm:"type:enum('pending','running','completed','failed','expired');default:'pending';index"`
+	FromTime      int64            `json:"from_time"` // 导出起始时间（纳秒时间戳），0表示不限
+	ToTime        int64            `json:"to_time"`   // 导出截止时间（纳秒时间戳）
+	FileName      string           `json:"-" gorm:"size:128"`
+	FileSize      int64            `json:"file_size"`
+	MessageCount  int              `json:"message_count"`
+	RedactedCount int              `json:"redacted_count"` // 因对方未授权被隐去内容的消息数
+	Error         string           `json:"error" gorm:"size:1000"`
+	StartedAt     int64            `json:"started_at"`              // 开始时间（纳秒时间戳）
+	FinishedAt    int64            `json:"finished_at"`             // 结束时间（纳秒时间戳）
+	ExpiresAt     int64            `json:"expires_at" gorm:"index"` // 文件过期时间（纳秒时间戳）
+}
+
+// TableName 指定表名
+func (ChatExportJob) TableName() string {
+	return "chat_export_jobs"
+}
+
+// IsFinished 任务是否已结束
+func (j *ChatExportJob) IsFinished() bool {
+	return j.Status != ChatExportStatusPending && j.Status != ChatExportStatusRunning
+}
+
+// IsDownloadable 文件在now（纳秒时间戳）时是否可下载
+func (j *ChatExportJob) IsDownloadable(now int64) bool {
+	return j.Status == ChatExportStatusCompleted && j.FileName != "" && now < j.ExpiresAt
+}
+
+// ChatExportConsent 用户的会话导出授权设置
+// 对方导出与该用户的会话时，未授权则该用户发送的消息内容被隐去
+type ChatExportConsent struct {
+	BaseModel
+	UserID uint `json:"user_id" gorm:"not null;uniqueIndex"`
+	Allow  bool `json:"allow"`
+}
+
+// TableName 指定表名
+func (ChatExportConsent) TableName() string {
+	return "chat_export_consents"
+}
@@ -0,0 +1,37 @@
+package mysql
+
+import (
+	"errors"
+	"time"
+)
+
+// LoginDevice 用户登录过的设备
+// 设备按指纹识别（设备ID或去掉版本号的User-Agent的SHA-256），从未记录的设备或国家登录时视为新设备或异地登录
+type LoginDevice struct {
+	BaseModel
+	UserID      uint      `json:"user_id" gorm:"uniqueIndex:idx_login_device_user_fingerprint;not null"`
+	Fingerprint string    `json:"-" gorm:"uniqueIndex:idx_login_device_user_fingerprint;size:64;not null"`
+	UserAgent   string    `json:"user_agent" gorm:"size:255;not null;default:''"`
+	LastIP      string    `json:"last_ip" gorm:"size:45;not null;default:''"`
+	Country     string    `json:"country" gorm:"size:2;not null;default:''"` // 最近一次登录所在国家，无法确定时为空
+	LastSeenAt  time.Time `json:"last_seen_at" gorm:"index;not null"`
+}
+
+// TableName 指定表名
+func (LoginDevice) TableName() string {
+	return "login_devices"
+}
+
+// Validate 验证登录设备
+func (d *LoginDevice) Validate() error {
+	if d.UserID == 0 {
+		return errors.New("login device user id is required")
+	}
+	if len(d.Fingerprint) != 64 {
+		return errors.New("invalid login device fingerprint")
+	}
+	if len(d.UserAgent) > 255 {
+		d.UserAgent = d.UserAgent[:255]
+	}
+	return nil
+}
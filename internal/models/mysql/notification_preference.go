@@ -0,0 +1,87 @@
+package mysql
+
+import (
+	"errors"
+	"time"
+)
+
+// QuietTimeLayout 免打扰时段的时间格式
+const QuietTimeLayout = "15:04"
+
+// NotificationPreference 用户的推送通知设置
+// 没有记录的用户使用DefaultNotificationPreference；免打扰时段按用户的时区计算，结束时间早于开始时间时跨越午夜
+type NotificationPreference struct {
+	BaseModel
+	UserID         uint   `json:"user_id" gorm:"uniqueIndex;not null"`
+	PushEnabled    bool   `json:"push_enabled" gorm:"not null"`
+	DirectMessages bool   `json:"direct_messages" gorm:"not null"`                // 是否推送单聊消息
+	GroupMessages  bool   `json:"group_messages" gorm:"not null"`                 // 是否推送群聊消息
+	ShowPreview    bool   `json:"show_preview" gorm:"not null"`                   // 通知中是否显示消息内容
+	QuietStart     string `json:"quiet_start" gorm:"size:5;not null;default:''"`  // 免打扰开始时间(HH:MM)，为空时不启用
+	QuietEnd       string `json:"quiet_end" gorm:"size:5;not null;default:''"`    // 免打扰结束时间(HH:MM)
+	Timezone       string `json:"timezone" gorm:"size:64;not null;default:'UTC'"` // IANA时区，如Asia/Shanghai
+}
+
+// TableName 指定表名
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+// DefaultNotificationPreference 用户未设置时的通知设置：全部推送并显示内容，不启用免打扰
+func DefaultNotificationPreference(userID uint) *NotificationPreference {
+	return &NotificationPreference{
+		UserID:         userID,
+		PushEnabled:    true,
+		DirectMessages: true,
+		GroupMessages:  true,
+		ShowPreview:    true,
+		Timezone:       "UTC",
+	}
+}
+
+// Validate 验证通知设置
+func (p *NotificationPreference) Validate() error {
+	if p.UserID == 0 {
+		return errors.New("notification preference user id is required")
+	}
+	if (p.QuietStart == "") != (p.QuietEnd == "") {
+		return errors.New("quiet hours require both start and end")
+	}
+	if p.QuietStart != "" {
+		if _, err := time.Parse(QuietTimeLayout, p.QuietStart); err != nil {
+			return errors.New("invalid quiet hours start")
+		}
+		if _, err := time.Parse(QuietTimeLayout, p.QuietEnd); err != nil {
+			return errors.New("invalid quiet hours end")
+		}
+	}
+	if _, err := time.LoadLocation(p.Timezone); err != nil || p.Timezone == "" {
+		return errors.New("invalid timezone")
+	}
+	return nil
+}
+
+// InQuietHours 判断时间是否在免打扰时段内（含开始时间，不含结束时间）
+func (p *NotificationPreference) InQuietHours(t time.Time) bool {
+	if p.QuietStart == "" || p.QuietStart == p.QuietEnd {
+		return false
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	start, err1 := time.Parse(QuietTimeLayout, p.QuietStart)
+	end, err2 := time.Parse(QuietTimeLayout, p.QuietEnd)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	if from < to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
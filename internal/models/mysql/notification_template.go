@@ -0,0 +1,42 @@
+package mysql
+
+import (
+	"errors"
+)
+
+// NotificationTemplate 管理员编辑的通知模板（按事件、渠道、语言保存多个版本）
+// 同一事件、渠道、语言下最多一个版本处于启用状态；没有启用版本时使用内置默认模板
+type NotificationTemplate struct {
+	BaseModel
+	Event     string `json:"event" gorm:"size:64;not null;uniqueIndex:idx_notification_template_version,priority:1"`
+	Channel   string `json:"channel" gorm:"size:16;not null;uniqueIndex:idx_notification_template_version,priority:2"`
+	Locale    string `json:"locale" gorm:"size:16;not null;uniqueIndex:idx_notification_template_version,priority:3"`
+	Version   int    `json:"version" gorm:"not null;uniqueIndex:idx_notification_template_version,priority:4"`
+	Subject   string `json:"subject" gorm:"size:255"`        // 邮件主题/站内信标题（Go text/template语法）
+	Body      string `json:"body" gorm:"type:text;not null"` // 正文（Go text/template语法）
+	Active    bool   `json:"active" gorm:"index"`            // 是否为当前启用的版本
+	Comment   string `json:"comment" gorm:"size:255"`        // 修改说明
+	CreatedBy uint   `json:"created_by"`
+}
+
+// TableName 指定表名
+func (NotificationTemplate) TableName() string {
+	return "notification_templates"
+}
+
+// Validate 验证模板数据
+func (t *NotificationTemplate) Validate() error {
+	if t.Event == "" {
+		return errors.New("event is required")
+	}
+	if t.Channel == "" {
+		return errors.New("channel is required")
+	}
+	if t.Locale == "" {
+		return errors.New("locale is required")
+	}
+	if t.Body == "" {
+		return errors.New("body is required")
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package mysql
+
+import (
+	"errors"
+)
+
+// OAuthIdentity 用户关联的第三方登录账户
+// 同一服务商的同一账户只能关联一个用户；第一次第三方登录时按服务商已验证的邮箱关联已有用户或创建新用户
+type OAuthIdentity struct {
+	BaseModel
+	Provider string `json:"provider" gorm:"uniqueIndex:idx_oauth_provider_subject;size:20;not null"` // google, github, apple
+	Subject  string `json:"subject" gorm:"uniqueIndex:idx_oauth_provider_subject;size:255;not null"` // 服务商内用户的唯一标识
+	UserID   uint   `json:"user_id" gorm:"index;not null"`
+	Email    string `json:"email" gorm:"size:100;not null;default:''"` // 关联时服务商返回的邮箱
+}
+
+// TableName 指定表名
+func (OAuthIdentity) TableName() string {
+	return "oauth_identities"
+}
+
+// Validate 验证第三方登录账户
+func (i *OAuthIdentity) Validate() error {
+	if i.Provider == "" || i.Subject == "" {
+		return errors.New("oauth provider and subject are required")
+	}
+	if i.UserID == 0 {
+		return errors.New("oauth identity user id is required")
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package mysql
+
+import (
+	"errors"
+	"time"
+)
+
+// PushDevice 用户注册的推送设备
+// 同一token只属于一个用户，其他用户在同一设备登录后注册时转移到该用户；推送服务返回token失效时删除
+type PushDevice struct {
+	BaseModel
+	UserID     uint      `json:"user_id" gorm:"index;not null"`
+	Platform   string    `json:"platform" gorm:"size:10;not null"` // ios, android, web
+	Token      string    `json:"-" gorm:"uniqueIndex;size:255;not null"`
+	LastSeenAt time.Time `json:"last_seen_at" gorm:"not null"` // 最近一次注册（客户端每次启动时注册）
+}
+
+// TableName 指定表名
+func (PushDevice) TableName() string {
+	return "push_devices"
+}
+
+// Validate 验证推送设备
+func (d *PushDevice) Validate() error {
+	if d.UserID == 0 {
+		return errors.New("push device user id is required")
+	}
+	if d.Platform == "" {
+		return errors.New("push device platform is required")
+	}
+	if d.Token == "" || len(d.Token) > 255 {
+		return errors.New("invalid push device token")
+	}
+	return nil
+}
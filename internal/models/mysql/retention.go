@@ -0,0 +1,148 @@
+package mysql
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// LegalHold 法律保全记录，保全期间用户相关数据不参与保留期清理
+type LegalHold struct {
+	BaseModel
+	UserID     uint   `json:"user_id" gorm:"not null;index"`
+	Reason     string `json:"reason" gorm:"size:500;not null"`
+	CreatedBy  uint   `json:"created_by" gorm:"not null"` // 设置保全的管理员ID
+	ReleasedBy uint   `json:"released_by"`                // 解除保全的管理员ID
+	ReleasedAt int64  `json:"released_at" gorm:"index"`   // 解除时间（纳秒时间戳），0表示仍在保全中
+}
+
+// TableName 指定表名
+func (LegalHold) TableName() string {
+	return "legal_holds"
+}
+
+// IsActive 是否仍在保全中
+func (h *LegalHold) IsActive() bool {
+	return h.ReleasedAt == 0
+}
+
+// Validate 验证法律保全数据
+func (h *LegalHold) Validate() error {
+	if h.UserID == 0 {
+		return errors.New("user_id is required")
+	}
+
+	if h.Reason == "" {
+		return errors.New("reason is required")
+	}
+
+	if len(h.Reason) > 500 {
+		return errors.New("reason must be less than 500 characters")
+	}
+
+	if h.CreatedBy == 0 {
+		return errors.New("created_by is required")
+	}
+
+	return nil
+}
+
+// RetentionRunStatus 保留期清理执行状态
+type RetentionRunStatus string
+
+const (
+	RetentionRunStatusSuccess RetentionRunStatus = "success" // 执行成功
+	RetentionRunStatusFailed  RetentionRunStatus = "failed"  // 执行失败
+	RetentionRunStatusSkipped RetentionRunStatus = "skipped" // 策略未启用
+)
+
+// RetentionRun 保留期清理执行记录，用于合规报告
+type RetentionRun struct {
+	BaseModel
+	Category   string             `json:"category" gorm:"size:50;not null;index"` // 数据类别
+	Store      string             `json:"store" gorm:"size:20;not null"`          // 存储：mysql/mongodb/redis
+	Target     string             `json:"target" gorm:"size:100;not null"`        // 表名/集合名/键模式
+	DryRun     bool               `json:"dry_run" gorm:"index"`
+	Status     RetentionRunStatus `json:"status" gorm:"type:enum('success','failed','skipped');not null"`
+	RetainDays int                `json:"retain_days"`
+	Cutoff     int64              `json:"cutoff"`                           // 截止时间（纳秒时间戳），早于该时间的数据被清理
+	Matched    int64              `json:"matched"`                          // 符合清理条件的记录数
+	Purged     int64              `json:"purged"`                           // 实际清理的记录数，演练时为0
+	HeldUsers  int                `json:"held_users"`                       // 因法律保全被排除的用户数
+	Error      string             `json:"error" gorm:"size:1000"`           // 失败原因
+	StartedAt  int64              `json:"started_at" gorm:"not null;index"` // 开始时间（纳秒时间戳）
+	FinishedAt int64              `json:"finished_at"`                      // 结束时间（纳秒时间戳）
+}
+
+// TableName 指定表名
+func (RetentionRun) TableName() string {
+	return "retention_runs"
+}
+
+// 消息归档单独策略的会话类型
+const (
+	ArchiveConversationDirect = "direct" // 单聊，ConversationID为两个用户ID按从小到大用":"连接
+	ArchiveConversationGroup  = "group"  // 群聊，ConversationID为群ID
+)
+
+// MessageArchiveOverride 单个会话的消息归档策略，覆盖retention.archive.days
+type MessageArchiveOverride struct {
+	BaseModel
+	ConversationType string `json:"conversation_type" gorm:"size:10;not null;uniqueIndex:idx_archive_override_conversation"`
+	ConversationID   string `json:"conversation_id" gorm:"size:100;not null;uniqueIndex:idx_archive_override_conversation"`
+	Days             int    `json:"days" gorm:"not null"`       // 消息在热集合中保留的天数，0表示不归档
+	Reason           string `json:"reason" gorm:"size:500"`     // 设置原因
+	CreatedBy        uint   `json:"created_by" gorm:"not null"` // 最近一次设置的管理员ID
+}
+
+// TableName 指定表名
+func (MessageArchiveOverride) TableName() string {
+	return "message_archive_overrides"
+}
+
+// DirectArchiveConversationID 单聊会话的ConversationID，与参数顺序无关
+func DirectArchiveConversationID(userID1, userID2 uint) string {
+	if userID1 > userID2 {
+		userID1, userID2 = userID2, userID1
+	}
+	return strconv.FormatUint(uint64(userID1), 10) + ":" + strconv.FormatUint(uint64(userID2), 10)
+}
+
+// DirectUsers 解析单聊会话的两个用户ID（字符串形式，与消息中的用户ID一致）
+func (o *MessageArchiveOverride) DirectUsers() (string, string, bool) {
+	if o.ConversationType != ArchiveConversationDirect {
+		return "", "", false
+	}
+	return strings.Cut(o.ConversationID, ":")
+}
+
+// Validate 验证消息归档策略
+func (o *MessageArchiveOverride) Validate() error {
+	switch o.ConversationType {
+	case ArchiveConversationGroup:
+		if o.ConversationID == "" || len(o.ConversationID) > 100 {
+			return errors.New("invalid group id")
+		}
+	case ArchiveConversationDirect:
+		user1, user2, ok := o.DirectUsers()
+		if !ok || user1 == "" || user2 == "" || user1 == user2 {
+			return errors.New("invalid direct conversation id")
+		}
+	default:
+		return errors.New("invalid conversation type")
+	}
+
+	if o.Days < 0 {
+		return errors.New("days must not be negative")
+	}
+
+	if len(o.Reason) > 500 {
+		return errors.New("reason must be less than 500 characters")
+	}
+
+	if o.CreatedBy == 0 {
+		return errors.New("created_by is required")
+	}
+
+	return nil
+}
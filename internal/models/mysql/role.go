@@ -0,0 +1,49 @@
+package mysql
+
+import (
+	"errors"
+	"regexp"
+)
+
+// roleNamePattern 角色名：小写字母开头，由小写字母、数字、下划线和连字符组成
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+
+// Role 管理员角色，权限保存在role_permissions表
+// 内置角色（super、admin）未保存到表中时使用代码中的默认权限
+type Role struct {
+	BaseModel
+	Name        string           `json:"name" gorm:"uniqueIndex;size:50;not null"`
+	Description string           `json:"description" gorm:"size:255"`
+	UpdatedBy   uint             `json:"updated_by"`
+	Permissions []RolePermission `json:"permissions" gorm:"foreignKey:RoleID"`
+}
+
+// TableName 指定表名
+func (Role) TableName() string {
+	return "roles"
+}
+
+// Validate 验证角色数据
+func (r *Role) Validate() error {
+	return ValidateRoleName(r.Name)
+}
+
+// RolePermission 角色拥有的权限
+type RolePermission struct {
+	ID         uint   `json:"-" gorm:"primaryKey"`
+	RoleID     uint   `json:"-" gorm:"uniqueIndex:idx_role_permission;not null"`
+	Permission string `json:"permission" gorm:"uniqueIndex:idx_role_permission;size:100;not null"`
+}
+
+// TableName 指定表名
+func (RolePermission) TableName() string {
+	return "role_permissions"
+}
+
+// ValidateRoleName 验证角色名
+func ValidateRoleName(name string) error {
+	if !roleNamePattern.MatchString(name) {
+		return errors.New("role name must be 2-50 lowercase letters, digits, underscores or hyphens and start with a letter")
+	}
+	return nil
+}
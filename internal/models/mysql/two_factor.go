@@ -0,0 +1,42 @@
+package mysql
+
+import (
+	"errors"
+)
+
+// 两步验证的账户类型
+const (
+	TwoFactorSubjectUser  = "user"  // 用户
+	TwoFactorSubjectAdmin = "admin" // 管理员
+)
+
+// TwoFactorCredential 两步验证凭据（TOTP密钥和备用码）
+// 启用两步验证时创建，同时设置users/admins表的two_factor_enabled；关闭时删除
+type TwoFactorCredential struct {
+	BaseModel
+	SubjectType  string `json:"subject_type" gorm:"uniqueIndex:idx_two_factor_subject;size:10;not null"` // user/admin
+	SubjectID    uint   `json:"subject_id" gorm:"uniqueIndex:idx_two_factor_subject;not null"`
+	Secret       string `json:"-" gorm:"size:64;not null"`   // TOTP密钥（Base32）
+	BackupCodes  string `json:"-" gorm:"type:text;not null"` // 未使用的备用码哈希，JSON数组
+	LastUsedStep int64  `json:"-" gorm:"not null;default:0"` // 最近一次验证通过的时间步，同一验证码不能重复使用
+	EnabledAt    int64  `json:"enabled_at" gorm:"not null"`  // 启用时间(秒)
+}
+
+// TableName 指定表名
+func (TwoFactorCredential) TableName() string {
+	return "two_factor_credentials"
+}
+
+// Validate 验证两步验证凭据
+func (c *TwoFactorCredential) Validate() error {
+	if c.SubjectType != TwoFactorSubjectUser && c.SubjectType != TwoFactorSubjectAdmin {
+		return errors.New("invalid two-factor subject type")
+	}
+	if c.SubjectID == 0 {
+		return errors.New("two-factor subject id is required")
+	}
+	if c.Secret == "" {
+		return errors.New("two-factor secret is required")
+	}
+	return nil
+}
@@ -2,9 +2,11 @@ package mysql
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
+	"exchange/internal/pkg/clock"
 	"exchange/internal/utils"
 
 	"golang.org/x/crypto/bcrypt"
@@ -25,6 +27,7 @@ const (
 	UserStatusActive   UserStatus = "active"
 	UserStatusInactive UserStatus = "inactive"
 	UserStatusBanned   UserStatus = "banned"
+	UserStatusDeleted  UserStatus = "deleted" // 已注销（个人信息已匿名化）
 )
 
 // User 用户模型
@@ -34,9 +37,25 @@ type User struct {
 	Email        string     `json:"email" gorm:"uniqueIndex;size:100;not null"`
 	PasswordHash string     `json:"-" gorm:"size:255;not null"`
 	Role         UserRole   `json:"role" gorm:"type:enum('user','admin');default:'user'"`
-	Status       UserStatus `json:"status" gorm:"type:enum('active','inactive','banned');default:'active'"`
+	Status       UserStatus `json:"status" gorm:"type:enum('active','inactive','banned','deleted');default:'active'"`
 	LastLoginAt  *time.Time `json:"last_login_at" gorm:"type:timestamp null"`
 	LoginCount   int        `json:"login_count" gorm:"default:0"`
+	Language     string     `json:"language" gorm:"size:16;not null;default:''"` // 首选语言，为空时按请求头选择
+
+	TwoFactorEnabled bool `json:"two_factor_enabled" gorm:"not null;default:false"` // 是否已启用两步验证，密钥见two_factor_credentials表
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at" gorm:"type:timestamp null"` // 邮箱验证时间，为空表示未验证，修改邮箱后清空
+
+	LockedUntil           *time.Time `json:"locked_until" gorm:"type:timestamp null"`               // 管理员锁定账户的截止时间，锁定期间不能登录
+	PasswordResetRequired bool       `json:"password_reset_required" gorm:"not null;default:false"` // 管理员要求重置密码，通过找回密码设置新密码前不能登录
+}
+
+// UserLanguageKeyPrefix 用户首选语言的缓存键前缀
+const UserLanguageKeyPrefix = "user_language:"
+
+// UserLanguageKey 用户首选语言的缓存键
+func UserLanguageKey(userID uint) string {
+	return fmt.Sprintf("%s%d", UserLanguageKeyPrefix, userID)
 }
 
 // TableName 指定表名
@@ -94,9 +113,33 @@ func (u *User) CanLogin() bool {
 	return u.IsActive()
 }
 
+// IsLocked 检查账户在now时是否处于管理员锁定期间
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// IsAccountDeleted 检查账户是否已注销
+func (u *User) IsAccountDeleted() bool {
+	return u.Status == UserStatusDeleted
+}
+
+// IsEmailVerified 检查邮箱是否已验证
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// MarkEmailVerified 标记邮箱已验证，已验证时保留原验证时间
+func (u *User) MarkEmailVerified() {
+	if u.EmailVerifiedAt != nil {
+		return
+	}
+	now := clock.Now()
+	u.EmailVerifiedAt = &now
+}
+
 // UpdateLoginInfo 更新登录信息
 func (u *User) UpdateLoginInfo() {
-	now := time.Now()
+	now := clock.Now()
 	u.LastLoginAt = &now
 	u.LoginCount++
 }
@@ -128,8 +171,15 @@ func (u *User) ToPublicUser() *PublicUser {
 		Status:      u.Status,
 		LastLoginAt: u.LastLoginAt,
 		LoginCount:  u.LoginCount,
+		Language:    u.Language,
 		CreatedAt:   u.CreatedAt,
 		UpdatedAt:   u.UpdatedAt,
+
+		TwoFactorEnabled: u.TwoFactorEnabled,
+		EmailVerifiedAt:  u.EmailVerifiedAt,
+
+		LockedUntil:           u.LockedUntil,
+		PasswordResetRequired: u.PasswordResetRequired,
 	}
 }
 
@@ -142,6 +192,13 @@ type PublicUser struct {
 	Status      UserStatus `json:"status"`
 	LastLoginAt *time.Time `json:"last_login_at"`
 	LoginCount  int        `json:"login_count"`
+	Language    string     `json:"language"`
 	CreatedAt   int64      `json:"created_at"`
 	UpdatedAt   int64      `json:"updated_at"`
+
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at"`
+
+	LockedUntil           *time.Time `json:"locked_until"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
 }
@@ -0,0 +1,109 @@
+package mysql
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// UserInviteKeyPrefix 邀请链接token的缓存键前缀，值为被邀请的用户ID
+const UserInviteKeyPrefix = "user_invite:"
+
+// UserInviteKey 邀请链接token的缓存键
+func UserInviteKey(token string) string {
+	return UserInviteKeyPrefix + token
+}
+
+// UserImportMode 批量导入方式
+type UserImportMode string
+
+const (
+	UserImportModePasswordHash UserImportMode = "password_hash" // CSV中提供bcrypt密码哈希，导入后直接可登录
+	UserImportModeInvite       UserImportMode = "invite"        // 不提供密码，导入后发送邀请链接由用户设置密码
+)
+
+// UserImportStatus 批量导入任务状态
+type UserImportStatus string
+
+const (
+	UserImportStatusPending   UserImportStatus = "pending"   // 等待执行
+	UserImportStatusRunning   UserImportStatus = "running"   // 执行中
+	UserImportStatusCompleted UserImportStatus = "completed" // 已完成（可能包含失败行）
+	UserImportStatusFailed    UserImportStatus = "failed"    // 执行失败
+)
+
+// UserImportRowError 单行导入错误
+type UserImportRowError struct {
+	Row      int      `json:"row"` // CSV行号（含表头，从1开始）
+	Username string   `json:"username,omitempty"`
+	Email    string   `json:"email,omitempty"`
+	Errors   []string `json:"errors"`
+}
+
+// UserImportJob 用户批量导入任务
+type UserImportJob struct {
+	BaseModel
+	AdminID      uint             `json:"admin_id" gorm:"not null;index"`
+	FileName     string           `json:"file_name" gorm:"size:255"`
+	Mode         UserImportMode   `json:"mode" gorm:"type:enum('password_hash','invite');not null"`
+	DryRun       bool             `json:"dry_run"`
+	Status       UserImportStatus `json:"status" gorm:"type:enum('pending','running','completed','failed');default:'pending';index"`
+	TotalRows    int              `json:"total_rows"`
+	ValidRows    int              `json:"valid_rows"`    // 校验通过的行数
+	ImportedRows int              `json:"imported_rows"` // 实际导入的行数，演练时为0
+	FailedRows   int              `json:"failed_rows"`
+	RowErrors    string           `json:"-" gorm:"type:json"` // 逐行错误（JSON）
+	Error        string           `json:"error" gorm:"size:1000"`
+	StartedAt    int64            `json:"started_at"`  // 开始时间（纳秒时间戳）
+	FinishedAt   int64            `json:"finished_at"` // 结束时间（纳秒时间戳）
+}
+
+// TableName 指定表名
+func (UserImportJob) TableName() string {
+	return "user_import_jobs"
+}
+
+// IsFinished 任务是否已结束
+func (j *UserImportJob) IsFinished() bool {
+	return j.Status == UserImportStatusCompleted || j.Status == UserImportStatusFailed
+}
+
+// SetRowErrors 设置逐行错误
+func (j *UserImportJob) SetRowErrors(rowErrors []UserImportRowError) error {
+	if len(rowErrors) == 0 {
+		j.RowErrors = "[]"
+		return nil
+	}
+
+	data, err := json.Marshal(rowErrors)
+	if err != nil {
+		return err
+	}
+	j.RowErrors = string(data)
+	return nil
+}
+
+// GetRowErrors 获取逐行错误
+func (j *UserImportJob) GetRowErrors() ([]UserImportRowError, error) {
+	var rowErrors []UserImportRowError
+	if j.RowErrors == "" {
+		return rowErrors, nil
+	}
+
+	if err := json.Unmarshal([]byte(j.RowErrors), &rowErrors); err != nil {
+		return nil, err
+	}
+	return rowErrors, nil
+}
+
+// Validate 验证导入任务数据
+func (j *UserImportJob) Validate() error {
+	if j.AdminID == 0 {
+		return errors.New("admin_id is required")
+	}
+
+	if j.Mode != UserImportModePasswordHash && j.Mode != UserImportModeInvite {
+		return errors.New("invalid import mode")
+	}
+
+	return nil
+}
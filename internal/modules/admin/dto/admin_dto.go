@@ -4,6 +4,7 @@ package dto
 type AdminLoginRequest struct {
 	Username string `json:"username" binding:"required"` // 用户名
 	Password string `json:"password" binding:"required"` // 密码
+	TOTPCode string `json:"totp_code"`                   // 两步验证的验证码或备用码，已启用两步验证时必填
 }
 
 // Validate 验证管理员登录请求
@@ -18,6 +19,12 @@ type AdminLoginResponse struct {
 	Token string      `json:"token"` // 登录token
 }
 
+// ForceLogoutResponse 强制用户下线响应
+type ForceLogoutResponse struct {
+	UserID  uint `json:"user_id"` // 用户ID
+	Revoked int  `json:"revoked"` // 撤销的登录会话数
+}
+
 // DashboardResponse 仪表板响应
 type DashboardResponse struct {
 	TotalUsers       int64 `json:"total_users"`       // 总用户数
@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"errors"
+	"time"
+
+	"exchange/internal/pkg/adminaudit"
+	"exchange/internal/utils"
+)
+
+// ListAuditLogsRequest 查询管理员操作审计记录请求，时间为RFC3339格式，范围包含from、不包含to
+type ListAuditLogsRequest struct {
+	Page       int64  `form:"page" binding:"omitempty,min=1"`              // 页码
+	PageSize   int64  `form:"page_size" binding:"omitempty,min=1,max=100"` // 每页大小
+	ActorID    uint   `form:"actor_id"`                                    // 操作者（管理员ID）
+	Action     string `form:"action"`                                      // 操作
+	TargetType string `form:"target_type"`                                 // 操作对象类型
+	TargetID   string `form:"target_id"`                                   // 操作对象ID
+	RequestID  string `form:"request_id"`                                  // 请求ID
+	Success    *bool  `form:"success"`                                     // 是否成功
+	From       string `form:"from"`                                        // 开始时间
+	To         string `form:"to"`                                          // 结束时间
+
+	filter adminaudit.Filter
+}
+
+// Validate 验证查询审计记录请求，并转换为查询条件
+func (r *ListAuditLogsRequest) Validate() error {
+	r.Page, r.PageSize = utils.ValidatePageParams(r.Page, r.PageSize)
+
+	r.filter = adminaudit.Filter{
+		ActorID:    r.ActorID,
+		Action:     r.Action,
+		TargetType: r.TargetType,
+		TargetID:   r.TargetID,
+		RequestID:  r.RequestID,
+		Success:    r.Success,
+	}
+	var err error
+	if r.From != "" {
+		if r.filter.From, err = time.Parse(time.RFC3339, r.From); err != nil {
+			return errors.New("from must be an RFC3339 time")
+		}
+	}
+	if r.To != "" {
+		if r.filter.To, err = time.Parse(time.RFC3339, r.To); err != nil {
+			return errors.New("to must be an RFC3339 time")
+		}
+	}
+	if !r.filter.From.IsZero() && !r.filter.To.IsZero() && !r.filter.From.Before(r.filter.To) {
+		return errors.New("from must be before to")
+	}
+	return nil
+}
+
+// Filter 审计记录查询条件，需要先调用Validate
+func (r *ListAuditLogsRequest) Filter() adminaudit.Filter {
+	return r.filter
+}
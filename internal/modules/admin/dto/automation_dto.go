@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/automation"
+)
+
+// 模板语言代码，如 en、zh、zh-CN
+var templateLanguagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})?$`)
+
+// AutomationRuleRequest 创建/更新消息自动化规则请求
+type AutomationRuleRequest struct {
+	Name      string                           `json:"name" binding:"required"`
+	Trigger   string                           `json:"trigger" binding:"required"`   // user.registered/deposit.first/kyc.approved
+	Enabled   *bool                            `json:"enabled"`                      // 是否启用，默认启用
+	Templates map[string]mysql.MessageTemplate `json:"templates" binding:"required"` // 按语言的消息模板，键为语言代码
+}
+
+// Validate 验证消息自动化规则请求
+func (r *AutomationRuleRequest) Validate() error {
+	if len(r.Name) == 0 || len(r.Name) > 100 {
+		return errors.New("name must be between 1 and 100 characters")
+	}
+
+	if !mysql.AutomationTrigger(r.Trigger).IsValid() {
+		return errors.New("trigger must be one of user.registered, deposit.first, kyc.approved")
+	}
+
+	if len(r.Templates) == 0 {
+		return errors.New("at least one template is required")
+	}
+	for language, tpl := range r.Templates {
+		if !templateLanguagePattern.MatchString(language) {
+			return fmt.Errorf("invalid template language: %s", language)
+		}
+		if err := automation.ValidateTemplate(tpl); err != nil {
+			return fmt.Errorf("template %s: %w", language, err)
+		}
+	}
+
+	if r.Enabled == nil {
+		enabled := true
+		r.Enabled = &enabled
+	}
+	return nil
+}
+
+// ListAutomationRulesRequest 获取消息自动化规则列表请求
+type ListAutomationRulesRequest struct {
+	Trigger string `form:"trigger"` // 按触发事件过滤
+}
+
+// Validate 验证获取规则列表请求
+func (r *ListAutomationRulesRequest) Validate() error {
+	if r.Trigger != "" && !mysql.AutomationTrigger(r.Trigger).IsValid() {
+		return errors.New("invalid trigger")
+	}
+	return nil
+}
+
+// PreviewAutomationRuleRequest 预览消息自动化规则请求
+type PreviewAutomationRuleRequest struct {
+	Language string                 `json:"language"` // 预览语言，为空时使用默认语言
+	UserID   uint                   `json:"user_id"`  // 使用指定用户的数据渲染，为0时使用示例数据
+	Data     map[string]interface{} `json:"data"`     // 事件数据，如首次充值的金额
+}
+
+// Validate 验证预览请求
+func (r *PreviewAutomationRuleRequest) Validate() error {
+	if r.Language != "" && !templateLanguagePattern.MatchString(r.Language) {
+		return errors.New("invalid language")
+	}
+	return nil
+}
+
+// AutomationRuleInfo 消息自动化规则详情
+type AutomationRuleInfo struct {
+	*mysql.AutomationRule
+	Templates map[string]mysql.MessageTemplate `json:"templates"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+import (
+	"errors"
+
+	"exchange/internal/pkg/ipaccess"
+)
+
+// IPAccessEntryRequest 添加或删除IP名单条目请求（删除时通过查询参数传递）
+type IPAccessEntryRequest struct {
+	CIDR   string `json:"cidr" form:"cidr" binding:"required"` // IP地址或CIDR
+	Reason string `json:"reason" form:"reason"`                // 操作原因，记录到审计日志
+}
+
+// Validate 验证IP名单条目请求
+func (r *IPAccessEntryRequest) Validate() error {
+	if _, err := ipaccess.ParsePrefix(r.CIDR); err != nil {
+		return err
+	}
+	if len(r.Reason) > 200 {
+		return errors.New("reason must be at most 200 characters")
+	}
+	return nil
+}
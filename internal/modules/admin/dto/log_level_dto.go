@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"errors"
+	"regexp"
+
+	"exchange/internal/pkg/logger"
+)
+
+// 模块名只能包含字母、数字、下划线、点和连字符
+var logModulePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{1,64}$`)
+
+// SetLogLevelRequest 设置日志级别请求
+type SetLogLevelRequest struct {
+	Module string `json:"module" binding:"required"` // 模块名，root表示全局级别
+	Level  string `json:"level" binding:"required"`  // debug/info/warn/error
+}
+
+// Validate 验证设置日志级别请求
+func (r *SetLogLevelRequest) Validate() error {
+	if !logModulePattern.MatchString(r.Module) {
+		return errors.New("invalid module name")
+	}
+	if _, err := logger.ParseLevel(r.Level); err != nil {
+		return errors.New("level must be one of debug, info, warn, error")
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package dto
+
+import "errors"
+
+// maxMaintenanceRetryAfter 维护模式Retry-After的上限(秒)
+const maxMaintenanceRetryAfter = 86400
+
+// EnableMaintenanceRequest 开启维护模式请求
+type EnableMaintenanceRequest struct {
+	Notice     string `json:"notice"`      // 附加说明，随503响应返回给客户端
+	RetryAfter int    `json:"retry_after"` // 建议客户端重试的等待秒数，0表示使用maintenance.retry_after
+	Reason     string `json:"reason"`      // 操作原因，记录到审计日志
+}
+
+// Validate 验证开启维护模式请求
+func (r *EnableMaintenanceRequest) Validate() error {
+	if len(r.Notice) > 500 {
+		return errors.New("notice must be at most 500 characters")
+	}
+	if r.RetryAfter < 0 || r.RetryAfter > maxMaintenanceRetryAfter {
+		return errors.New("retry_after must be between 0 and 86400 seconds")
+	}
+	if len(r.Reason) > 200 {
+		return errors.New("reason must be at most 200 characters")
+	}
+	return nil
+}
+
+// DisableMaintenanceRequest 关闭维护模式请求（通过查询参数传递）
+type DisableMaintenanceRequest struct {
+	Reason string `form:"reason"` // 操作原因，记录到审计日志
+}
+
+// Validate 验证关闭维护模式请求
+func (r *DisableMaintenanceRequest) Validate() error {
+	if len(r.Reason) > 200 {
+		return errors.New("reason must be at most 200 characters")
+	}
+	return nil
+}
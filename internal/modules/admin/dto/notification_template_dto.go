@@ -0,0 +1,112 @@
+package dto
+
+import (
+	"errors"
+	"unicode/utf8"
+
+	"exchange/internal/pkg/notification"
+)
+
+// 模板长度限制
+const (
+	maxTemplateSubjectLength = 255
+	maxTemplateBodyLength    = 64 * 1024
+	maxTemplateCommentLength = 255
+)
+
+// NotificationTemplateTarget 模板定位参数（路径参数）
+type NotificationTemplateTarget struct {
+	Event   string `uri:"event" binding:"required"`
+	Channel string `uri:"channel" binding:"required"` // email/in_app
+	Locale  string `uri:"locale" binding:"required"`  // 语言代码，如 en、zh、zh-CN
+}
+
+// Validate 验证模板定位参数
+func (t *NotificationTemplateTarget) Validate() error {
+	if _, ok := notification.LookupSchema(t.Event); !ok {
+		return errors.New("unsupported event")
+	}
+	if !notification.IsValidChannel(t.Channel) {
+		return errors.New("channel must be one of email, in_app")
+	}
+	if !templateLanguagePattern.MatchString(t.Locale) {
+		return errors.New("invalid locale")
+	}
+	return nil
+}
+
+// ListNotificationTemplatesRequest 获取通知模板列表请求
+type ListNotificationTemplatesRequest struct {
+	Event   string `form:"event"`   // 按事件过滤
+	Channel string `form:"channel"` // 按渠道过滤
+}
+
+// Validate 验证获取模板列表请求
+func (r *ListNotificationTemplatesRequest) Validate() error {
+	if r.Event != "" {
+		if _, ok := notification.LookupSchema(r.Event); !ok {
+			return errors.New("unsupported event")
+		}
+	}
+	if r.Channel != "" && !notification.IsValidChannel(r.Channel) {
+		return errors.New("channel must be one of email, in_app")
+	}
+	return nil
+}
+
+// SaveNotificationTemplateRequest 保存通知模板请求（保存为新版本并立即启用）
+type SaveNotificationTemplateRequest struct {
+	Subject string `json:"subject"`                 // 邮件主题/站内信标题，邮件必填
+	Body    string `json:"body" binding:"required"` // 正文
+	Comment string `json:"comment"`                 // 修改说明
+}
+
+// Validate 验证保存模板请求（模板语法和变量在业务逻辑中按事件校验）
+func (r *SaveNotificationTemplateRequest) Validate() error {
+	return validateTemplateContent(r.Subject, r.Body, r.Comment)
+}
+
+// PreviewNotificationTemplateRequest 预览通知模板请求
+type PreviewNotificationTemplateRequest struct {
+	Subject *string                `json:"subject"` // 与body同时为空时预览当前生效的模板
+	Body    *string                `json:"body"`
+	Data    map[string]interface{} `json:"data"` // 覆盖示例数据中的同名变量
+}
+
+// Validate 验证预览请求
+func (r *PreviewNotificationTemplateRequest) Validate() error {
+	if r.Subject == nil && r.Body == nil {
+		return nil
+	}
+	return validateTemplateContent(r.GetDraft().Subject, r.GetDraft().Body, "")
+}
+
+// GetDraft 获取待预览的模板内容，未提供时返回nil
+func (r *PreviewNotificationTemplateRequest) GetDraft() *notification.Template {
+	if r.Subject == nil && r.Body == nil {
+		return nil
+	}
+
+	draft := &notification.Template{}
+	if r.Subject != nil {
+		draft.Subject = *r.Subject
+	}
+	if r.Body != nil {
+		draft.Body = *r.Body
+	}
+	return draft
+}
+
+// validateTemplateContent 检查模板内容长度
+func validateTemplateContent(subject, body, comment string) error {
+	if utf8.RuneCountInString(subject) > maxTemplateSubjectLength {
+		return errors.New("subject must be at most 255 characters")
+	}
+	if len(body) > maxTemplateBodyLength {
+		return errors.New("body must be at most 64KB")
+	}
+	if utf8.RuneCountInString(comment) > maxTemplateCommentLength {
+		return errors.New("comment must be at most 255 characters")
+	}
+	return nil
+}
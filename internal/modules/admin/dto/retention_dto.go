@@ -0,0 +1,91 @@
+package dto
+
+import (
+	"errors"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/config"
+)
+
+// 合规报告默认和最大统计天数
+const (
+	DefaultComplianceReportDays = 30
+	MaxComplianceReportDays     = 365
+)
+
+// ComplianceReportRequest 合规报告请求
+type ComplianceReportRequest struct {
+	Days     int    `form:"days"`     // 统计最近多少天的执行记录
+	Category string `form:"category"` // 数据类别，为空时统计所有类别
+}
+
+// Validate 验证合规报告请求
+func (r *ComplianceReportRequest) Validate() error {
+	if r.Days == 0 {
+		r.Days = DefaultComplianceReportDays
+	}
+	if r.Days < 0 || r.Days > MaxComplianceReportDays {
+		return errors.New("days must be between 1 and 365")
+	}
+	if r.Category != "" {
+		if _, ok := (config.RetentionConfig{}).Policies()[r.Category]; !ok {
+			return errors.New("invalid category")
+		}
+	}
+	return nil
+}
+
+// PlaceLegalHoldRequest 设置法律保全请求
+type PlaceLegalHoldRequest struct {
+	UserID uint   `json:"user_id" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+// Validate 验证设置法律保全请求
+func (r *PlaceLegalHoldRequest) Validate() error {
+	if r.UserID == 0 {
+		return errors.New("user_id is required")
+	}
+	if r.Reason == "" {
+		return errors.New("reason is required")
+	}
+	if len(r.Reason) > 500 {
+		return errors.New("reason must be less than 500 characters")
+	}
+	return nil
+}
+
+// 会话归档天数上限
+const MaxArchiveOverrideDays = 3650
+
+// SetArchiveOverrideRequest 设置会话归档策略请求
+type SetArchiveOverrideRequest struct {
+	ConversationType string `json:"conversation_type" binding:"required"` // group 或 direct
+	GroupID          string `json:"group_id"`                             // 群聊的群ID
+	UserIDs          []uint `json:"user_ids"`                             // 单聊的两个用户ID
+	Days             *int   `json:"days" binding:"required"`              // 消息在热集合中保留的天数，0表示不归档
+	Reason           string `json:"reason"`
+}
+
+// Validate 验证设置会话归档策略请求
+func (r *SetArchiveOverrideRequest) Validate() error {
+	switch r.ConversationType {
+	case mysql.ArchiveConversationGroup:
+		if r.GroupID == "" || len(r.GroupID) > 100 {
+			return errors.New("group_id is required")
+		}
+	case mysql.ArchiveConversationDirect:
+		if len(r.UserIDs) != 2 || r.UserIDs[0] == 0 || r.UserIDs[1] == 0 || r.UserIDs[0] == r.UserIDs[1] {
+			return errors.New("user_ids must be two different user ids")
+		}
+	default:
+		return errors.New("conversation_type must be group or direct")
+	}
+	if *r.Days < 0 || *r.Days > MaxArchiveOverrideDays {
+		return errors.New("days must be between 0 and 3650")
+	}
+	if len(r.Reason) > 500 {
+		return errors.New("reason must be less than 500 characters")
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"unicode/utf8"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/rbac"
+)
+
+// maxRoleDescriptionLength 角色说明长度上限(字符)
+const maxRoleDescriptionLength = 255
+
+// RoleTarget 角色定位参数（路径参数）
+type RoleTarget struct {
+	Name string `uri:"name" binding:"required"` // 角色名
+}
+
+// Validate 验证角色定位参数
+func (t *RoleTarget) Validate() error {
+	return mysql.ValidateRoleName(t.Name)
+}
+
+// SaveRoleRequest 保存角色请求，permissions替换角色现有的全部权限
+type SaveRoleRequest struct {
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" binding:"required"` // 权限，支持"*"和"资源:*"通配符
+}
+
+// Validate 验证保存角色请求，并去除重复的权限
+func (r *SaveRoleRequest) Validate() error {
+	if utf8.RuneCountInString(r.Description) > maxRoleDescriptionLength {
+		return errors.New("description is too long")
+	}
+	for _, permission := range r.Permissions {
+		if !rbac.IsValid(permission) {
+			return fmt.Errorf("unknown permission %q", permission)
+		}
+	}
+	slices.Sort(r.Permissions)
+	r.Permissions = slices.Compact(r.Permissions)
+	return nil
+}
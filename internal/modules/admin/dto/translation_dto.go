@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"text/template"
+	"unicode/utf8"
+
+	"exchange/internal/pkg/i18n"
+)
+
+// 消息键只能包含字母、数字、下划线、点和连字符
+var translationKeyPattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{1,128}$`)
+
+// maxTranslationLength 单个复数形式的消息模板长度上限(字符)
+const maxTranslationLength = 4096
+
+// TranslationTarget 翻译覆盖定位参数（路径参数）
+type TranslationTarget struct {
+	Language string `uri:"language" binding:"required"` // 语言代码，如 en、zh、zh-TW
+	Key      string `uri:"key" binding:"required"`      // 消息键
+}
+
+// Validate 验证翻译覆盖定位参数
+func (t *TranslationTarget) Validate() error {
+	if !templateLanguagePattern.MatchString(t.Language) {
+		return errors.New("invalid language")
+	}
+	if !translationKeyPattern.MatchString(t.Key) {
+		return errors.New("invalid message key")
+	}
+	return nil
+}
+
+// ListTranslationsRequest 获取翻译请求
+type ListTranslationsRequest struct {
+	Language string `form:"language"` // 为空时使用默认语言；查询翻译覆盖时为空表示所有语言
+}
+
+// Validate 验证获取翻译请求
+func (r *ListTranslationsRequest) Validate() error {
+	if r.Language != "" && !templateLanguagePattern.MatchString(r.Language) {
+		return errors.New("invalid language")
+	}
+	return nil
+}
+
+// SaveTranslationRequest 保存翻译覆盖请求
+type SaveTranslationRequest struct {
+	Forms map[string]string `json:"forms" binding:"required"` // 复数形式(zero/one/two/few/many/other)到消息模板，至少包含other
+}
+
+// Validate 验证保存翻译覆盖请求，消息模板需能按Go模板语法解析
+func (r *SaveTranslationRequest) Validate() error {
+	if r.Forms["other"] == "" {
+		return errors.New("other form is required")
+	}
+	for form, text := range r.Forms {
+		if !slices.Contains(i18n.PluralForms, form) {
+			return fmt.Errorf("unsupported plural form %q", form)
+		}
+		if text == "" {
+			return fmt.Errorf("%s form is empty", form)
+		}
+		if utf8.RuneCountInString(text) > maxTranslationLength {
+			return fmt.Errorf("%s form is too long", form)
+		}
+		if _, err := template.New(form).Parse(text); err != nil {
+			return fmt.Errorf("invalid template in %s form: %w", form, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/twofactor"
+)
+
+// TwoFactorCodeRequest 携带两步验证码的请求（启用、关闭、重新生成备用码）
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"` // 验证器应用中的验证码，关闭和重新生成备用码时也可使用备用码
+}
+
+// ConfirmTwoFactorSetupRequest 登录时确认绑定请求
+type ConfirmTwoFactorSetupRequest struct {
+	SetupToken string `json:"setup_token" binding:"required"` // 登录响应中的绑定token
+	Code       string `json:"code" binding:"required"`        // 验证器应用中的验证码
+}
+
+// TwoFactorSetupResponse 绑定信息，setup_token只在登录时要求绑定的响应中返回
+type TwoFactorSetupResponse struct {
+	SetupToken string `json:"setup_token,omitempty"`
+	Secret     string `json:"secret"` // 无法扫码时手动输入的密钥
+	URI        string `json:"uri"`    // otpauth://地址，渲染为二维码供验证器应用扫描
+}
+
+// NewTwoFactorSetupResponse 创建绑定信息响应
+func NewTwoFactorSetupResponse(enrollment *twofactor.Enrollment, setupToken string) *TwoFactorSetupResponse {
+	return &TwoFactorSetupResponse{
+		SetupToken: setupToken,
+		Secret:     enrollment.Secret,
+		URI:        enrollment.URI,
+	}
+}
+
+// BackupCodesResponse 备用码响应，备用码只在生成时返回一次
+type BackupCodesResponse struct {
+	BackupCodes []string `json:"backup_codes"`
+}
+
+// ConfirmTwoFactorSetupResponse 登录时确认绑定的响应，包含备用码和登录token
+type ConfirmTwoFactorSetupResponse struct {
+	Admin       *mysql.PublicAdmin `json:"admin"`
+	Token       string             `json:"token"`
+	BackupCodes []string           `json:"backup_codes"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"errors"
+
+	"exchange/internal/models/mysql"
+)
+
+// MaxUserImportFileSize 用户导入CSV文件的最大大小（字节）
+const MaxUserImportFileSize = 10 << 20
+
+// UserImportRequest 用户批量导入请求（multipart/form-data，CSV文件字段名为file）
+type UserImportRequest struct {
+	Mode   string `form:"mode"`    // 导入方式：password_hash/invite，默认password_hash
+	DryRun bool   `form:"dry_run"` // 是否演练（只校验不写入）
+}
+
+// Validate 验证用户批量导入请求
+func (r *UserImportRequest) Validate() error {
+	if r.Mode == "" {
+		r.Mode = string(mysql.UserImportModePasswordHash)
+	}
+	if r.Mode != string(mysql.UserImportModePasswordHash) && r.Mode != string(mysql.UserImportModeInvite) {
+		return errors.New("mode must be password_hash or invite")
+	}
+	return nil
+}
+
+// UserImportJobResponse 用户批量导入任务详情
+type UserImportJobResponse struct {
+	*mysql.UserImportJob
+	RowErrors []mysql.UserImportRowError `json:"row_errors"` // 逐行错误
+}
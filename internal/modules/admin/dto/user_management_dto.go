@@ -5,25 +5,45 @@ import (
 	"regexp"
 	"strings"
 
+	"exchange/internal/models/mysql"
+	"exchange/internal/repository"
 	"exchange/internal/utils"
 )
 
 // GetUsersRequest 获取用户列表请求
 type GetUsersRequest struct {
-	Page     int64  `form:"page" binding:"min=1"`              // 页码
-	PageSize int64  `form:"page_size" binding:"min=1,max=100"` // 每页大小
-	Status   string `form:"status"`                            // 用户状态
-	Role     string `form:"role"`                              // 用户角色
-	Keyword  string `form:"keyword"`                           // 搜索关键词
+	Page     int64  `form:"page" binding:"omitempty,min=1"`                                                     // 页码
+	PageSize int64  `form:"page_size" binding:"omitempty,min=1,max=100"`                                        // 每页大小
+	Status   string `form:"status" binding:"omitempty,oneof=active inactive banned deleted"`                    // 用户状态
+	Role     string `form:"role" binding:"omitempty,oneof=user admin"`                                          // 用户角色
+	Keyword  string `form:"keyword" binding:"max=100"`                                                          // 搜索关键词（用户名或邮箱）
+	Deleted  bool   `form:"deleted"`                                                                            // 为true时只查询已删除的用户（用于恢复）
+	SortBy   string `form:"sort_by" binding:"omitempty,oneof=id username created_at last_login_at login_count"` // 排序字段，默认id
+	Order    string `form:"order" binding:"omitempty,oneof=asc desc"`                                           // 排序方向，默认desc
 }
 
 // Validate 验证获取用户列表请求
 func (r *GetUsersRequest) Validate() error {
 	// 验证并修正分页参数
 	r.Page, r.PageSize = utils.ValidatePageParams(r.Page, r.PageSize)
+	r.Keyword = strings.TrimSpace(r.Keyword)
 	return nil
 }
 
+// Query 转换为用户查询条件
+func (r *GetUsersRequest) Query() repository.UserQuery {
+	return repository.UserQuery{
+		Keyword: r.Keyword,
+		Status:  mysql.UserStatus(r.Status),
+		Role:    mysql.UserRole(r.Role),
+		Deleted: r.Deleted,
+		SortBy:  r.SortBy,
+		Desc:    r.Order != "asc",
+		Limit:   int(r.PageSize),
+		Offset:  int((r.Page - 1) * r.PageSize),
+	}
+}
+
 // UserInfo 用户信息（用于列表展示）
 type UserInfo struct {
 	ID        uint   `json:"id"`         // 用户ID
@@ -34,6 +54,11 @@ type UserInfo struct {
 	CreatedAt string `json:"created_at"` // 创建时间
 	UpdatedAt string `json:"updated_at"` // 更新时间
 	LastLogin string `json:"last_login"` // 最后登录时间
+
+	LoginCount            int    `json:"login_count"`             // 累计登录次数
+	LockedUntil           string `json:"locked_until,omitempty"`  // 锁定截止时间，未锁定时为空
+	PasswordResetRequired bool   `json:"password_reset_required"` // 是否要求重置密码
+	DeletedAt             string `json:"deleted_at,omitempty"`    // 删除时间，未删除时为空
 }
 
 // GetUsersResponse 获取用户列表响应
@@ -155,6 +180,17 @@ func (r *UpdateUserRequest) Validate() error {
 	return nil
 }
 
+// LockUserRequest 锁定用户请求
+type LockUserRequest struct {
+	Minutes int `json:"minutes" binding:"required,min=1,max=525600"` // 锁定时长(分钟)，最长一年
+}
+
+// ForcePasswordResetResponse 要求用户重置密码响应
+type ForcePasswordResetResponse struct {
+	User      UserInfo `json:"user"`
+	EmailSent bool     `json:"email_sent"` // 是否已发送重置密码邮件，超过发送上限时为false
+}
+
 // UserStatsResponse 用户统计响应
 type UserStatsResponse struct {
 	TotalUsers    int64 `json:"total_users"`
@@ -163,3 +199,19 @@ type UserStatsResponse struct {
 	BannedUsers   int64 `json:"banned_users"`
 	UserAdmins    int64 `json:"user_admins"`
 }
+
+// UserOverviewRequest 用户概览请求
+type UserOverviewRequest struct {
+	Sections string `form:"sections"` // 需要的部分，逗号分隔，为空时返回全部
+}
+
+// SectionList 解析需要的部分
+func (r *UserOverviewRequest) SectionList() []string {
+	var sections []string
+	for _, section := range strings.Split(r.Sections, ",") {
+		if section = strings.TrimSpace(section); section != "" {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
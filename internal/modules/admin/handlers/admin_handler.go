@@ -1,11 +1,18 @@
 package admin
 
 import (
+	"context"
+	"errors"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 
 	"exchange/internal/models/mysql"
 	"exchange/internal/modules/admin/dto"
 	"exchange/internal/modules/admin/logic"
+	"exchange/internal/pkg/clock"
+	appErrors "exchange/internal/pkg/errors"
+	appLogger "exchange/internal/pkg/logger"
 	"exchange/internal/utils"
 	"time"
 )
@@ -40,12 +47,20 @@ func (h *AdminHandler) Login(c *gin.Context) {
 	// 第一步：解析登录请求
 	var req dto.AdminLoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": err.Error()})
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
-	// 第二步：验证管理员凭据（用户名和密码）
-	admin, err := h.authLogic.AuthenticateAdmin(c.Request.Context(), req.Username, req.Password)
+	// 第二步：验证管理员凭据（用户名、密码和两步验证码）
+	admin, err := h.authLogic.AuthenticateAdmin(c.Request.Context(), req.Username, req.Password, req.TOTPCode, c.ClientIP())
+	if isTwoFactorError(err) {
+		twoFactorErrorResponse(c, err)
+		return
+	}
+	if errors.Is(err, appErrors.ErrAccountLocked) {
+		accountLockedResponse(c, err)
+		return
+	}
 	if err != nil {
 		utils.ErrorResponse(c, "invalid_credentials", map[string]interface{}{"error": err.Error()})
 		return
@@ -68,6 +83,48 @@ func (h *AdminHandler) Login(c *gin.Context) {
 	utils.SuccessWithMessage(c, "admin_login_successful", response, nil)
 }
 
+// accountLockedResponse 登录失败次数过多的响应（HTTP 429），Retry-After头为可以再次登录的等待秒数
+func accountLockedResponse(c *gin.Context, err error) {
+	if appErr, ok := appErrors.GetAppError(err); ok {
+		if seconds, ok := appErr.Context["retry_after"].(int); ok {
+			c.Header("Retry-After", strconv.Itoa(seconds))
+		}
+	}
+	utils.ErrorResponseFromError(c, "account_locked", err)
+}
+
+// ForceLogoutUser 强制用户下线
+// 撤销用户在所有设备上的登录会话，用户需重新登录
+func (h *AdminHandler) ForceLogoutUser(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	utils.SetAuditAction(c, "user.logout")
+	utils.SetAuditTarget(c, "user", c.Param("id"))
+
+	revoked, err := h.userLogic.ForceLogout(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponseFromError(c, "session_revoke_failed", err)
+		return
+	}
+
+	appLogger.Audit("强制用户下线", map[string]interface{}{
+		"admin_id": adminID,
+		"user_id":  userID,
+		"revoked":  revoked,
+		"ip":       c.ClientIP(),
+	})
+
+	utils.SuccessWithMessage(c, "user_logged_out", dto.ForceLogoutResponse{UserID: userID, Revoked: revoked}, nil)
+}
+
 // GetDashboard 获取管理员仪表板
 // 处理流程：
 // 1. 从token中获取管理员ID
@@ -96,14 +153,14 @@ func (h *AdminHandler) GetDashboard(c *gin.Context) {
 // 处理流程：
 // 1. 解析请求参数
 // 2. 验证请求参数
-// 3. 获取用户列表
+// 3. 获取用户列表（关键词搜索、状态和角色过滤、排序）
 // 4. 转换用户数据
 // 5. 返回分页结果
 func (h *AdminHandler) GetUsers(c *gin.Context) {
 	// 第一步：解析请求参数
 	var req dto.GetUsersRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": err.Error()})
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
@@ -114,50 +171,154 @@ func (h *AdminHandler) GetUsers(c *gin.Context) {
 	}
 
 	// 第三步：获取用户列表
-	users, total, err := h.userLogic.GetUsers(
-		c.Request.Context(),
-		req.Page,
-		req.PageSize,
-		req.Keyword,
-		req.Status,
-		req.Role,
-	)
+	users, total, err := h.userLogic.GetUsers(c.Request.Context(), req.Query())
 	if err != nil {
 		utils.ErrorResponse(c, "user_list_retrieval_failed", map[string]interface{}{"error": err.Error()})
 		return
 	}
 
 	// 第四步：转换用户数据
-	response := utils.ConvertPage(
-		users,
-		func(user *mysql.User) dto.UserInfo {
-			// 转换时间戳为时间字符串
-			createdAt := time.Unix(0, user.CreatedAt).Format("2006-01-02 15:04:05")
-			updatedAt := time.Unix(0, user.UpdatedAt).Format("2006-01-02 15:04:05")
-
-			var lastLogin string
-			if user.LastLoginAt != nil {
-				lastLogin = user.LastLoginAt.Format("2006-01-02 15:04:05")
-			} else {
-				lastLogin = "从未登录"
-			}
-
-			return dto.UserInfo{
-				ID:        user.ID,
-				Username:  user.Username,
-				Email:     user.Email,
-				Role:      string(user.Role),
-				Status:    string(user.Status),
-				CreatedAt: createdAt,
-				UpdatedAt: updatedAt,
-				LastLogin: lastLogin,
-			}
-		},
-		total,
-		req.Page,
-		req.PageSize,
-	)
+	response := utils.ConvertPage(users, toUserInfo, total, req.Page, req.PageSize)
 
 	// 第五步：返回分页结果
 	utils.SuccessWithMessage(c, "user_list_retrieved", response, nil)
 }
+
+// BanUser 封禁用户并强制下线
+func (h *AdminHandler) BanUser(c *gin.Context) {
+	h.changeUserState(c, "user.ban", "user_banned", h.userLogic.BanUser)
+}
+
+// UnbanUser 解除封禁
+func (h *AdminHandler) UnbanUser(c *gin.Context) {
+	h.changeUserState(c, "user.unban", "user_unbanned", h.userLogic.UnbanUser)
+}
+
+// UnlockUser 解除锁定
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	h.changeUserState(c, "user.unlock", "user_unlocked", h.userLogic.UnlockUser)
+}
+
+// LockUser 锁定用户一段时间并强制下线，锁定期间不能登录
+func (h *AdminHandler) LockUser(c *gin.Context) {
+	var req dto.LockUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	h.changeUserState(c, "user.lock", "user_locked", func(ctx context.Context, userID uint) (*mysql.User, error) {
+		return h.userLogic.LockUser(ctx, userID, time.Duration(req.Minutes)*time.Minute)
+	})
+}
+
+// ForcePasswordReset 要求用户重置密码：强制下线并发送重置密码邮件，重置前不能使用原密码登录
+func (h *AdminHandler) ForcePasswordReset(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	utils.SetAuditAction(c, "user.force_password_reset")
+	utils.SetAuditTarget(c, "user", c.Param("id"))
+	before, _ := h.userLogic.GetUserByID(c.Request.Context(), userID)
+
+	user, emailSent, err := h.userLogic.ForcePasswordReset(c.Request.Context(), userID)
+	if err != nil {
+		userStateErrorResponse(c, err)
+		return
+	}
+	utils.SetAuditChange(c, before, user)
+
+	utils.SuccessWithMessage(c, "user_password_reset_forced", dto.ForcePasswordResetResponse{User: toUserInfo(user), EmailSent: emailSent}, nil)
+}
+
+// DeleteUser 删除用户（软删除）并强制下线，删除后可以恢复
+func (h *AdminHandler) DeleteUser(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	utils.SetAuditAction(c, "user.delete")
+	utils.SetAuditTarget(c, "user", c.Param("id"))
+
+	if err := h.userLogic.DeleteUser(c.Request.Context(), userID); err != nil {
+		utils.ErrorResponseFromError(c, "user_delete_failed", err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "user_deleted", nil, nil)
+}
+
+// RestoreUser 恢复已删除的用户
+func (h *AdminHandler) RestoreUser(c *gin.Context) {
+	h.changeUserState(c, "user.restore", "user_restored", h.userLogic.RestoreUser)
+}
+
+// changeUserState 执行用户状态操作，记录审计信息并返回操作后的用户
+func (h *AdminHandler) changeUserState(c *gin.Context, action, messageKey string, change func(ctx context.Context, userID uint) (*mysql.User, error)) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	utils.SetAuditAction(c, action)
+	utils.SetAuditTarget(c, "user", c.Param("id"))
+	before, _ := h.userLogic.GetUserByID(c.Request.Context(), userID)
+
+	user, err := change(c.Request.Context(), userID)
+	if err != nil {
+		userStateErrorResponse(c, err)
+		return
+	}
+	utils.SetAuditChange(c, before, user)
+
+	utils.SuccessWithMessage(c, messageKey, toUserInfo(user), nil)
+}
+
+// userStateErrorResponse 用户状态操作失败的响应
+func userStateErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, logic.ErrUserNotBanned):
+		utils.ErrorResponse(c, "user_not_banned", nil)
+	case errors.Is(err, logic.ErrUserAccountClosed):
+		utils.ErrorResponse(c, "user_account_closed", nil)
+	default:
+		utils.ErrorResponseFromError(c, "user_status_update_failed", err)
+	}
+}
+
+// parseUserID 解析路径中的用户ID，失败时直接返回错误响应
+func parseUserID(c *gin.Context) (uint, bool) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || userID == 0 {
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": "invalid user id"})
+		return 0, false
+	}
+	return uint(userID), true
+}
+
+// toUserInfo 转换为列表展示的用户信息
+func toUserInfo(user *mysql.User) dto.UserInfo {
+	// 转换时间戳为时间字符串
+	info := dto.UserInfo{
+		ID:                    user.ID,
+		Username:              user.Username,
+		Email:                 user.Email,
+		Role:                  string(user.Role),
+		Status:                string(user.Status),
+		CreatedAt:             time.Unix(0, user.CreatedAt).Format("2006-01-02 15:04:05"),
+		UpdatedAt:             time.Unix(0, user.UpdatedAt).Format("2006-01-02 15:04:05"),
+		LastLogin:             "从未登录",
+		LoginCount:            user.LoginCount,
+		PasswordResetRequired: user.PasswordResetRequired,
+	}
+	if user.LastLoginAt != nil {
+		info.LastLogin = user.LastLoginAt.Format("2006-01-02 15:04:05")
+	}
+	if user.IsLocked(clock.Now()) {
+		info.LockedUntil = user.LockedUntil.Format("2006-01-02 15:04:05")
+	}
+	if user.IsDeleted() {
+		info.DeletedAt = time.Unix(0, int64(user.DeletedAt)).Format("2006-01-02 15:04:05")
+	}
+	return info
+}
@@ -0,0 +1,65 @@
+package admin
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/models/mongodb"
+	"exchange/internal/modules/admin/dto"
+	"exchange/internal/modules/admin/logic"
+	"exchange/internal/pkg/adminaudit"
+	"exchange/internal/utils"
+)
+
+// AuditLogHandler 操作审计处理器 - 处理查询管理员操作审计记录的HTTP请求
+type AuditLogHandler struct {
+	auditLogic logic.AdminAuditLogic // 操作审计业务逻辑
+}
+
+// NewAuditLogHandler 创建操作审计处理器
+func NewAuditLogHandler(auditLogic logic.AdminAuditLogic) *AuditLogHandler {
+	return &AuditLogHandler{
+		auditLogic: auditLogic,
+	}
+}
+
+// ListLogs 按操作者、操作、操作对象、请求ID和时间范围分页查询审计记录
+func (h *AuditLogHandler) ListLogs(c *gin.Context) {
+	var req dto.ListAuditLogsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	logs, total, err := h.auditLogic.ListLogs(c.Request.Context(), req.Filter(), req.Page, req.PageSize)
+	if err != nil {
+		utils.ErrorResponseFromError(c, "audit_log_list_failed", err)
+		return
+	}
+
+	utils.Success(c, &utils.PageResponse[*mongodb.AdminAuditLog]{
+		Paginate: utils.NewPaginate(total, req.Page, req.PageSize),
+		List:     logs,
+	})
+}
+
+// GetLog 获取审计记录详情
+func (h *AuditLogHandler) GetLog(c *gin.Context) {
+	log, err := h.auditLogic.GetLog(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, adminaudit.ErrNotFound) {
+		utils.ErrorResponse(c, "audit_log_not_found", nil)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponseFromError(c, "audit_log_list_failed", err)
+		return
+	}
+
+	utils.Success(c, log)
+}
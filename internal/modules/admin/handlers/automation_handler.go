@@ -0,0 +1,224 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/modules/admin/dto"
+	"exchange/internal/modules/admin/logic"
+	"exchange/internal/utils"
+)
+
+// AutomationHandler 消息自动化处理器 - 处理自动化规则管理和预览的HTTP请求
+type AutomationHandler struct {
+	automationLogic logic.AdminAutomationLogic // 消息自动化业务逻辑
+}
+
+// NewAutomationHandler 创建消息自动化处理器
+func NewAutomationHandler(automationLogic logic.AdminAutomationLogic) *AutomationHandler {
+	return &AutomationHandler{
+		automationLogic: automationLogic,
+	}
+}
+
+// ListRules 获取规则列表
+func (h *AutomationHandler) ListRules(c *gin.Context) {
+	var req dto.ListAutomationRulesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	rules, err := h.automationLogic.ListRules(c.Request.Context(), req.Trigger)
+	if err != nil {
+		utils.ErrorResponse(c, "automation_rule_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	list := make([]dto.AutomationRuleInfo, 0, len(rules))
+	for _, rule := range rules {
+		info, err := toAutomationRuleInfo(rule)
+		if err != nil {
+			utils.ErrorResponse(c, "automation_rule_failed", map[string]interface{}{"error": err.Error()})
+			return
+		}
+		list = append(list, *info)
+	}
+
+	utils.Success(c, list)
+}
+
+// GetRule 获取规则详情
+func (h *AutomationHandler) GetRule(c *gin.Context) {
+	ruleID, ok := parseRuleID(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.automationLogic.GetRule(c.Request.Context(), ruleID)
+	if err != nil {
+		utils.ErrorResponse(c, "automation_rule_not_found", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	h.respondRule(c, "", rule)
+}
+
+// CreateRule 创建规则
+func (h *AutomationHandler) CreateRule(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.AutomationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.SetAuditAction(c, "automation_rule.create")
+	rule, err := h.automationLogic.CreateRule(c.Request.Context(), adminID, req.Name, req.Trigger, *req.Enabled, req.Templates)
+	if err != nil {
+		utils.ErrorResponse(c, "automation_rule_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	utils.SetAuditTarget(c, "automation_rule", strconv.FormatUint(uint64(rule.ID), 10))
+	utils.SetAuditChange(c, nil, rule)
+
+	h.respondRule(c, "automation_rule_created", rule)
+}
+
+// UpdateRule 更新规则
+func (h *AutomationHandler) UpdateRule(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	ruleID, ok := parseRuleID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AutomationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.SetAuditAction(c, "automation_rule.update")
+	utils.SetAuditTarget(c, "automation_rule", c.Param("id"))
+	before, _ := h.automationLogic.GetRule(c.Request.Context(), ruleID)
+
+	rule, err := h.automationLogic.UpdateRule(c.Request.Context(), adminID, ruleID, req.Name, req.Trigger, *req.Enabled, req.Templates)
+	if err != nil {
+		utils.ErrorResponse(c, "automation_rule_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	utils.SetAuditChange(c, before, rule)
+
+	h.respondRule(c, "automation_rule_updated", rule)
+}
+
+// DeleteRule 删除规则
+func (h *AutomationHandler) DeleteRule(c *gin.Context) {
+	ruleID, ok := parseRuleID(c)
+	if !ok {
+		return
+	}
+
+	utils.SetAuditAction(c, "automation_rule.delete")
+	utils.SetAuditTarget(c, "automation_rule", c.Param("id"))
+	before, _ := h.automationLogic.GetRule(c.Request.Context(), ruleID)
+
+	if err := h.automationLogic.DeleteRule(c.Request.Context(), ruleID); err != nil {
+		utils.ErrorResponse(c, "automation_rule_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	utils.SetAuditChange(c, before, nil)
+
+	utils.SuccessWithMessage(c, "automation_rule_deleted", nil, nil)
+}
+
+// PreviewRule 预览规则渲染结果（不发送消息）
+func (h *AutomationHandler) PreviewRule(c *gin.Context) {
+	utils.SkipAudit(c)
+
+	ruleID, ok := parseRuleID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.PreviewAutomationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	message, err := h.automationLogic.PreviewRule(c.Request.Context(), ruleID, req.Language, req.UserID, req.Data)
+	if err != nil {
+		utils.ErrorResponse(c, "automation_rule_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.Success(c, message)
+}
+
+// respondRule 返回规则详情，messageKey为空时不带提示消息
+func (h *AutomationHandler) respondRule(c *gin.Context, messageKey string, rule *mysql.AutomationRule) {
+	info, err := toAutomationRuleInfo(rule)
+	if err != nil {
+		utils.ErrorResponse(c, "automation_rule_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	if messageKey == "" {
+		utils.Success(c, info)
+		return
+	}
+	utils.SuccessWithMessage(c, messageKey, info, nil)
+}
+
+// toAutomationRuleInfo 转换为规则详情
+func toAutomationRuleInfo(rule *mysql.AutomationRule) (*dto.AutomationRuleInfo, error) {
+	templates, err := rule.GetTemplates()
+	if err != nil {
+		return nil, err
+	}
+	return &dto.AutomationRuleInfo{AutomationRule: rule, Templates: templates}, nil
+}
+
+// parseRuleID 解析路径中的规则ID，失败时直接返回错误响应
+func parseRuleID(c *gin.Context) (uint, bool) {
+	ruleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || ruleID == 0 {
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": "invalid id"})
+		return 0, false
+	}
+	return uint(ruleID), true
+}
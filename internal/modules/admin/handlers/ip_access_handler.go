@@ -0,0 +1,128 @@
+package admin
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/modules/admin/dto"
+	"exchange/internal/modules/admin/logic"
+	"exchange/internal/pkg/ipaccess"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/utils"
+)
+
+// IPAccessHandler IP访问控制处理器 - 处理管理后台IP白名单和客户端IP黑名单管理的HTTP请求
+type IPAccessHandler struct {
+	ipAccessLogic logic.AdminIPAccessLogic // IP访问控制业务逻辑
+}
+
+// NewIPAccessHandler 创建IP访问控制处理器
+func NewIPAccessHandler(ipAccessLogic logic.AdminIPAccessLogic) *IPAccessHandler {
+	return &IPAccessHandler{
+		ipAccessLogic: ipAccessLogic,
+	}
+}
+
+// ListEntries 获取名单条目
+func (h *IPAccessHandler) ListEntries(c *gin.Context) {
+	list, err := h.ipAccessLogic.ListEntries(c.Request.Context(), c.Param("list"))
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+
+	utils.Success(c, list)
+}
+
+// AddEntry 添加名单条目，所有实例在ip_access.cache_seconds内生效
+func (h *IPAccessHandler) AddEntry(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.IPAccessEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	list := c.Param("list")
+	utils.SetAuditAction(c, "ip_access.add")
+	utils.SetAuditTarget(c, "ip_access_"+list, req.CIDR)
+	entry, err := h.ipAccessLogic.AddEntry(c.Request.Context(), list, req.CIDR)
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+	utils.SetAuditChange(c, nil, map[string]interface{}{"list": list, "cidr": entry})
+
+	appLogger.Audit("添加IP名单条目", map[string]interface{}{
+		"admin_id": adminID,
+		"list":     list,
+		"cidr":     entry,
+		"reason":   req.Reason,
+		"ip":       c.ClientIP(),
+	})
+
+	utils.SuccessWithMessage(c, "ip_entry_added", map[string]interface{}{"list": list, "cidr": entry}, nil)
+}
+
+// RemoveEntry 删除名单条目，配置文件中的条目不能删除
+func (h *IPAccessHandler) RemoveEntry(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.IPAccessEntryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	list := c.Param("list")
+	utils.SetAuditAction(c, "ip_access.remove")
+	utils.SetAuditTarget(c, "ip_access_"+list, req.CIDR)
+	entry, err := h.ipAccessLogic.RemoveEntry(c.Request.Context(), list, req.CIDR, c.ClientIP())
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+	utils.SetAuditChange(c, map[string]interface{}{"list": list, "cidr": entry}, nil)
+
+	appLogger.Audit("删除IP名单条目", map[string]interface{}{
+		"admin_id": adminID,
+		"list":     list,
+		"cidr":     entry,
+		"reason":   req.Reason,
+		"ip":       c.ClientIP(),
+	})
+
+	utils.SuccessWithMessage(c, "ip_entry_removed", nil, nil)
+}
+
+// errorResponse 名单操作失败的响应
+func (h *IPAccessHandler) errorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ipaccess.ErrEntryMissing):
+		utils.ErrorResponse(c, "ip_entry_not_found", nil)
+	case errors.Is(err, ipaccess.ErrUnknownList), errors.Is(err, ipaccess.ErrConfigEntry), errors.Is(err, logic.ErrIPAccessLockout):
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+	default:
+		utils.ErrorResponse(c, "ip_access_failed", map[string]interface{}{"error": err.Error()})
+	}
+}
@@ -0,0 +1,82 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/modules/admin/dto"
+	"exchange/internal/modules/admin/logic"
+	"exchange/internal/utils"
+)
+
+// LogLevelHandler 日志级别处理器 - 处理运行时调整日志级别的HTTP请求
+type LogLevelHandler struct {
+	logLevelLogic logic.AdminLogLevelLogic // 日志级别业务逻辑
+}
+
+// NewLogLevelHandler 创建日志级别处理器
+func NewLogLevelHandler(logLevelLogic logic.AdminLogLevelLogic) *LogLevelHandler {
+	return &LogLevelHandler{
+		logLevelLogic: logLevelLogic,
+	}
+}
+
+// GetLogLevels 获取日志级别
+func (h *LogLevelHandler) GetLogLevels(c *gin.Context) {
+	levels, err := h.logLevelLogic.GetLogLevels(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, "log_level_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.Success(c, levels)
+}
+
+// SetLogLevel 设置日志级别，无需重启，所有实例同步生效
+func (h *LogLevelHandler) SetLogLevel(c *gin.Context) {
+	var req dto.SetLogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.SetAuditAction(c, "log_level.set")
+	utils.SetAuditTarget(c, "log_level", req.Module)
+	before := h.overrides(c)
+
+	if err := h.logLevelLogic.SetLogLevel(c.Request.Context(), req.Module, req.Level); err != nil {
+		utils.ErrorResponse(c, "log_level_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	utils.SetAuditChange(c, before, h.overrides(c))
+
+	utils.SuccessWithMessage(c, "log_level_updated", nil, nil)
+}
+
+// ResetLogLevel 清除运行时设置的级别，恢复为配置文件中的级别
+func (h *LogLevelHandler) ResetLogLevel(c *gin.Context) {
+	utils.SetAuditAction(c, "log_level.reset")
+	utils.SetAuditTarget(c, "log_level", c.Param("module"))
+	before := h.overrides(c)
+
+	if err := h.logLevelLogic.ResetLogLevel(c.Request.Context(), c.Param("module")); err != nil {
+		utils.ErrorResponse(c, "log_level_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	utils.SetAuditChange(c, before, h.overrides(c))
+
+	utils.SuccessWithMessage(c, "log_level_reset", nil, nil)
+}
+
+// overrides 运行时设置的日志级别，查询失败时返回nil（只用于审计记录）
+func (h *LogLevelHandler) overrides(c *gin.Context) map[string]string {
+	levels, err := h.logLevelLogic.GetLogLevels(c.Request.Context())
+	if err != nil {
+		return nil
+	}
+	return levels.Overrides
+}
@@ -0,0 +1,113 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/modules/admin/dto"
+	"exchange/internal/modules/admin/logic"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/utils"
+)
+
+// MaintenanceHandler 维护模式处理器 - 处理开启、关闭和查询集群维护模式的HTTP请求
+type MaintenanceHandler struct {
+	maintenanceLogic logic.AdminMaintenanceLogic // 维护模式业务逻辑
+}
+
+// NewMaintenanceHandler 创建维护模式处理器
+func NewMaintenanceHandler(maintenanceLogic logic.AdminMaintenanceLogic) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		maintenanceLogic: maintenanceLogic,
+	}
+}
+
+// GetStatus 获取当前维护状态
+func (h *MaintenanceHandler) GetStatus(c *gin.Context) {
+	state, err := h.maintenanceLogic.GetStatus(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, "maintenance_update_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.Success(c, state)
+}
+
+// Enable 开启维护模式，所有实例在maintenance.check_seconds内生效
+func (h *MaintenanceHandler) Enable(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.EnableMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.SetAuditAction(c, "maintenance.enable")
+	utils.SetAuditTarget(c, "config", "maintenance")
+	before, _ := h.maintenanceLogic.GetStatus(c.Request.Context())
+
+	state, err := h.maintenanceLogic.Enable(c.Request.Context(), adminID, req.Notice, req.RetryAfter)
+	if err != nil {
+		utils.ErrorResponse(c, "maintenance_update_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	utils.SetAuditChange(c, before, state)
+
+	appLogger.Audit("开启维护模式", map[string]interface{}{
+		"admin_id":    adminID,
+		"notice":      state.Notice,
+		"retry_after": state.RetryAfter,
+		"reason":      req.Reason,
+		"ip":          c.ClientIP(),
+	})
+
+	utils.SuccessWithMessage(c, "maintenance_enabled", state, nil)
+}
+
+// Disable 关闭维护模式
+func (h *MaintenanceHandler) Disable(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.DisableMaintenanceRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.SetAuditAction(c, "maintenance.disable")
+	utils.SetAuditTarget(c, "config", "maintenance")
+	previous, err := h.maintenanceLogic.Disable(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, "maintenance_update_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	utils.SetAuditChange(c, previous, map[string]interface{}{"enabled": false})
+
+	appLogger.Audit("关闭维护模式", map[string]interface{}{
+		"admin_id":   adminID,
+		"was_active": previous.Enabled,
+		"started_at": previous.StartedAt,
+		"reason":     req.Reason,
+		"ip":         c.ClientIP(),
+	})
+
+	utils.SuccessWithMessage(c, "maintenance_disabled", nil, nil)
+}
@@ -0,0 +1,204 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/modules/admin/dto"
+	"exchange/internal/modules/admin/logic"
+	"exchange/internal/utils"
+)
+
+// NotificationTemplateHandler 通知模板处理器 - 处理通知模板的编辑、版本管理和预览请求
+type NotificationTemplateHandler struct {
+	templateLogic logic.AdminNotificationTemplateLogic // 通知模板业务逻辑
+}
+
+// NewNotificationTemplateHandler 创建通知模板处理器
+func NewNotificationTemplateHandler(templateLogic logic.AdminNotificationTemplateLogic) *NotificationTemplateHandler {
+	return &NotificationTemplateHandler{
+		templateLogic: templateLogic,
+	}
+}
+
+// ListEvents 获取支持模板的事件及可用变量
+func (h *NotificationTemplateHandler) ListEvents(c *gin.Context) {
+	utils.Success(c, h.templateLogic.ListEvents())
+}
+
+// ListTemplates 获取管理员启用的模板列表
+func (h *NotificationTemplateHandler) ListTemplates(c *gin.Context) {
+	var req dto.ListNotificationTemplatesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	templates, err := h.templateLogic.ListTemplates(c.Request.Context(), req.Event, req.Channel)
+	if err != nil {
+		utils.ErrorResponse(c, "notification_template_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.Success(c, templates)
+}
+
+// GetTemplate 获取当前生效的模板和历史版本
+func (h *NotificationTemplateHandler) GetTemplate(c *gin.Context) {
+	target, ok := bindTemplateTarget(c)
+	if !ok {
+		return
+	}
+
+	detail, err := h.templateLogic.GetTemplate(c.Request.Context(), target.Event, target.Channel, target.Locale)
+	if err != nil {
+		utils.ErrorResponse(c, "notification_template_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.Success(c, detail)
+}
+
+// SaveTemplate 保存为新版本并立即启用
+func (h *NotificationTemplateHandler) SaveTemplate(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	target, ok := bindTemplateTarget(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SaveNotificationTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	before := h.auditTemplate(c, "notification_template.save", target)
+	tpl, err := h.templateLogic.SaveTemplate(c.Request.Context(), adminID, target.Event, target.Channel, target.Locale, req.Subject, req.Body, req.Comment)
+	if err != nil {
+		utils.ErrorResponse(c, "notification_template_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	utils.SetAuditChange(c, before, tpl)
+
+	utils.SuccessWithMessage(c, "notification_template_saved", tpl, nil)
+}
+
+// ActivateVersion 启用指定的历史版本
+func (h *NotificationTemplateHandler) ActivateVersion(c *gin.Context) {
+	target, ok := bindTemplateTarget(c)
+	if !ok {
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": "invalid version"})
+		return
+	}
+
+	before := h.auditTemplate(c, "notification_template.activate", target)
+	tpl, err := h.templateLogic.ActivateVersion(c.Request.Context(), target.Event, target.Channel, target.Locale, version)
+	if errors.Is(err, logic.ErrNotificationTemplateNotFound) {
+		utils.ErrorResponse(c, "notification_template_not_found", nil)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, "notification_template_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	utils.SetAuditChange(c, before, tpl)
+
+	utils.SuccessWithMessage(c, "notification_template_activated", tpl, nil)
+}
+
+// ResetTemplate 恢复使用内置默认模板
+func (h *NotificationTemplateHandler) ResetTemplate(c *gin.Context) {
+	target, ok := bindTemplateTarget(c)
+	if !ok {
+		return
+	}
+
+	before := h.auditTemplate(c, "notification_template.reset", target)
+	if err := h.templateLogic.ResetTemplate(c.Request.Context(), target.Event, target.Channel, target.Locale); err != nil {
+		utils.ErrorResponse(c, "notification_template_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	utils.SetAuditChange(c, before, nil)
+
+	utils.SuccessWithMessage(c, "notification_template_reset", nil, nil)
+}
+
+// PreviewTemplate 预览渲染结果（不发送通知）
+func (h *NotificationTemplateHandler) PreviewTemplate(c *gin.Context) {
+	utils.SkipAudit(c)
+
+	target, ok := bindTemplateTarget(c)
+	if !ok {
+		return
+	}
+
+	var req dto.PreviewNotificationTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	message, err := h.templateLogic.PreviewTemplate(c.Request.Context(), target.Event, target.Channel, target.Locale, req.GetDraft(), req.Data)
+	if err != nil {
+		utils.ErrorResponse(c, "notification_template_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.Success(c, message)
+}
+
+// auditTemplate 设置审计记录的操作和模板，返回修改前启用的管理员模板
+func (h *NotificationTemplateHandler) auditTemplate(c *gin.Context, action string, target *dto.NotificationTemplateTarget) *mysql.NotificationTemplate {
+	utils.SetAuditAction(c, action)
+	utils.SetAuditTarget(c, "notification_template", target.Event+"/"+target.Channel+"/"+target.Locale)
+
+	detail, err := h.templateLogic.GetTemplate(c.Request.Context(), target.Event, target.Channel, target.Locale)
+	if err != nil {
+		return nil
+	}
+	return detail.Active
+}
+
+// bindTemplateTarget 解析路径中的事件、渠道和语言，失败时直接返回错误响应
+func bindTemplateTarget(c *gin.Context) (*dto.NotificationTemplateTarget, bool) {
+	var target dto.NotificationTemplateTarget
+	if err := c.ShouldBindUri(&target); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return nil, false
+	}
+
+	if err := target.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return nil, false
+	}
+	return &target, true
+}
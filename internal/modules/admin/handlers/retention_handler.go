@@ -0,0 +1,224 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/modules/admin/dto"
+	"exchange/internal/modules/admin/logic"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/utils"
+)
+
+// RetentionHandler 数据保留处理器 - 处理合规报告、法律保全和会话归档策略相关的HTTP请求
+type RetentionHandler struct {
+	retentionLogic logic.AdminRetentionLogic // 数据保留业务逻辑
+}
+
+// NewRetentionHandler 创建数据保留处理器
+func NewRetentionHandler(retentionLogic logic.AdminRetentionLogic) *RetentionHandler {
+	return &RetentionHandler{
+		retentionLogic: retentionLogic,
+	}
+}
+
+// GetComplianceReport 获取合规报告
+// 汇总统计期内各类数据的清理次数、清理数量、最近一次演练的待清理数量
+func (h *RetentionHandler) GetComplianceReport(c *gin.Context) {
+	var req dto.ComplianceReportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	report, err := h.retentionLogic.GetComplianceReport(c.Request.Context(), req.Days, req.Category)
+	if err != nil {
+		utils.ErrorResponse(c, "compliance_report_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.SuccessWithMessage(c, "compliance_report_retrieved", report, nil)
+}
+
+// GetLegalHolds 获取生效中的法律保全
+func (h *RetentionHandler) GetLegalHolds(c *gin.Context) {
+	holds, err := h.retentionLogic.GetLegalHolds(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponseFromError(c, "legal_hold_failed", err)
+		return
+	}
+
+	utils.Success(c, holds)
+}
+
+// PlaceLegalHold 设置法律保全
+func (h *RetentionHandler) PlaceLegalHold(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.PlaceLegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.SetAuditAction(c, "legal_hold.place")
+	utils.SetAuditTarget(c, "user", strconv.FormatUint(uint64(req.UserID), 10))
+	hold, err := h.retentionLogic.PlaceLegalHold(c.Request.Context(), adminID, req.UserID, req.Reason)
+	if err != nil {
+		utils.ErrorResponse(c, "legal_hold_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	utils.SetAuditChange(c, nil, hold)
+
+	appLogger.Audit("设置法律保全", map[string]interface{}{
+		"admin_id": adminID,
+		"user_id":  req.UserID,
+		"hold_id":  hold.ID,
+		"ip":       c.ClientIP(),
+	})
+
+	utils.SuccessWithMessage(c, "legal_hold_placed", hold, nil)
+}
+
+// ReleaseLegalHold 解除法律保全
+func (h *RetentionHandler) ReleaseLegalHold(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil || userID == 0 {
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": "invalid user_id"})
+		return
+	}
+
+	utils.SetAuditAction(c, "legal_hold.release")
+	utils.SetAuditTarget(c, "user", c.Param("user_id"))
+	if err := h.retentionLogic.ReleaseLegalHold(c.Request.Context(), adminID, uint(userID)); err != nil {
+		utils.ErrorResponse(c, "legal_hold_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	appLogger.Audit("解除法律保全", map[string]interface{}{
+		"admin_id": adminID,
+		"user_id":  userID,
+		"ip":       c.ClientIP(),
+	})
+
+	utils.SuccessWithMessage(c, "legal_hold_released", nil, nil)
+}
+
+// GetArchiveOverrides 获取所有会话的消息归档策略
+func (h *RetentionHandler) GetArchiveOverrides(c *gin.Context) {
+	overrides, err := h.retentionLogic.GetArchiveOverrides(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponseFromError(c, "archive_override_failed", err)
+		return
+	}
+
+	utils.Success(c, overrides)
+}
+
+// SetArchiveOverride 设置会话的消息归档策略
+func (h *RetentionHandler) SetArchiveOverride(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.SetArchiveOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.SetAuditAction(c, "archive_override.set")
+	override, err := h.retentionLogic.SetArchiveOverride(c.Request.Context(), adminID, logic.ArchiveOverrideInput{
+		ConversationType: req.ConversationType,
+		GroupID:          req.GroupID,
+		UserIDs:          req.UserIDs,
+		Days:             *req.Days,
+		Reason:           req.Reason,
+	})
+	if errors.Is(err, logic.ErrInvalidArchiveOverride) {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		utils.ErrorResponseFromError(c, "archive_override_failed", err)
+		return
+	}
+	utils.SetAuditTarget(c, "archive_override", override.ConversationType+":"+override.ConversationID)
+	utils.SetAuditChange(c, nil, override)
+
+	appLogger.Audit("设置会话归档策略", map[string]interface{}{
+		"admin_id":          adminID,
+		"conversation_type": override.ConversationType,
+		"conversation_id":   override.ConversationID,
+		"days":              override.Days,
+		"ip":                c.ClientIP(),
+	})
+
+	utils.SuccessWithMessage(c, "archive_override_saved", override, nil)
+}
+
+// DeleteArchiveOverride 删除会话的消息归档策略
+func (h *RetentionHandler) DeleteArchiveOverride(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": "invalid id"})
+		return
+	}
+
+	utils.SetAuditAction(c, "archive_override.delete")
+	override, err := h.retentionLogic.DeleteArchiveOverride(c.Request.Context(), uint(id))
+	if errors.Is(err, logic.ErrArchiveOverrideNotFound) {
+		utils.ErrorWithNotFund(c, "archive_override_not_found", nil)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponseFromError(c, "archive_override_failed", err)
+		return
+	}
+	utils.SetAuditTarget(c, "archive_override", override.ConversationType+":"+override.ConversationID)
+	utils.SetAuditChange(c, override, nil)
+
+	appLogger.Audit("删除会话归档策略", map[string]interface{}{
+		"admin_id":          adminID,
+		"conversation_type": override.ConversationType,
+		"conversation_id":   override.ConversationID,
+		"ip":                c.ClientIP(),
+	})
+
+	utils.SuccessWithMessage(c, "archive_override_deleted", nil, nil)
+}
@@ -0,0 +1,156 @@
+package admin
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/modules/admin/dto"
+	"exchange/internal/modules/admin/logic"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/rbac"
+	"exchange/internal/utils"
+)
+
+// RoleHandler 角色管理处理器 - 处理查看角色和修改角色权限的HTTP请求
+type RoleHandler struct {
+	roleLogic logic.AdminRoleLogic // 角色管理业务逻辑
+}
+
+// NewRoleHandler 创建角色管理处理器
+func NewRoleHandler(roleLogic logic.AdminRoleLogic) *RoleHandler {
+	return &RoleHandler{
+		roleLogic: roleLogic,
+	}
+}
+
+// ListRoles 获取所有角色及其权限
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleLogic.ListRoles(c.Request.Context())
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+
+	utils.Success(c, roles)
+}
+
+// ListPermissions 获取可分配的权限
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	utils.Success(c, h.roleLogic.ListPermissions(c.Request.Context()))
+}
+
+// SaveRole 创建角色或替换角色的全部权限
+func (h *RoleHandler) SaveRole(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	target, ok := bindRoleTarget(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SaveRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.SetAuditAction(c, "role.save")
+	utils.SetAuditTarget(c, "role", target.Name)
+	before := h.findRole(c, target.Name)
+
+	role, err := h.roleLogic.SaveRole(c.Request.Context(), adminID, target.Name, req.Description, req.Permissions)
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+	utils.SetAuditChange(c, before, role)
+
+	appLogger.Audit("保存角色", map[string]interface{}{
+		"admin_id":    adminID,
+		"role":        target.Name,
+		"permissions": req.Permissions,
+		"ip":          c.ClientIP(),
+	})
+
+	utils.SuccessWithMessage(c, "role_saved", role, nil)
+}
+
+// DeleteRole 删除角色，内置角色恢复默认权限
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	target, ok := bindRoleTarget(c)
+	if !ok {
+		return
+	}
+
+	utils.SetAuditAction(c, "role.delete")
+	utils.SetAuditTarget(c, "role", target.Name)
+	before := h.findRole(c, target.Name)
+
+	if err := h.roleLogic.DeleteRole(c.Request.Context(), target.Name); err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+	utils.SetAuditChange(c, before, h.findRole(c, target.Name)) // 内置角色删除后恢复默认权限
+
+	adminID, _ := utils.GetAdminID(c)
+	appLogger.Audit("删除角色", map[string]interface{}{
+		"admin_id": adminID,
+		"role":     target.Name,
+		"ip":       c.ClientIP(),
+	})
+
+	utils.SuccessWithMessage(c, "role_deleted", nil, nil)
+}
+
+// findRole 查找角色，不存在或查询失败时返回nil（只用于审计记录）
+func (h *RoleHandler) findRole(c *gin.Context, name string) *rbac.Role {
+	roles, err := h.roleLogic.ListRoles(c.Request.Context())
+	if err != nil {
+		return nil
+	}
+	for _, role := range roles {
+		if role.Name == name {
+			return role
+		}
+	}
+	return nil
+}
+
+// errorResponse 将业务错误转换为响应
+func (h *RoleHandler) errorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, logic.ErrRoleNotFound):
+		utils.ErrorResponse(c, "role_not_found", nil)
+	case errors.Is(err, logic.ErrRoleInUse):
+		utils.ErrorResponse(c, "role_in_use", nil)
+	case errors.Is(err, logic.ErrRoleProtected):
+		utils.ErrorResponse(c, "role_protected", nil)
+	default:
+		utils.ErrorResponse(c, "role_failed", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+// bindRoleTarget 解析路径中的角色名，失败时直接返回错误响应
+func bindRoleTarget(c *gin.Context) (*dto.RoleTarget, bool) {
+	var target dto.RoleTarget
+	if err := c.ShouldBindUri(&target); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return nil, false
+	}
+
+	if err := target.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return nil, false
+	}
+	return &target, true
+}
@@ -0,0 +1,185 @@
+package admin
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/models/mongodb"
+	"exchange/internal/modules/admin/dto"
+	"exchange/internal/modules/admin/logic"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/utils"
+)
+
+// TranslationHandler 翻译管理处理器 - 处理查看翻译、缺失翻译和编辑翻译覆盖的请求
+type TranslationHandler struct {
+	translationLogic logic.AdminTranslationLogic // 翻译管理业务逻辑
+}
+
+// NewTranslationHandler 创建翻译管理处理器
+func NewTranslationHandler(translationLogic logic.AdminTranslationLogic) *TranslationHandler {
+	return &TranslationHandler{
+		translationLogic: translationLogic,
+	}
+}
+
+// GetCoverage 获取各语言的翻译覆盖率
+func (h *TranslationHandler) GetCoverage(c *gin.Context) {
+	utils.Success(c, h.translationLogic.GetCoverage(c.Request.Context()))
+}
+
+// ListTranslations 获取语言当前生效的消息和缺失的消息键
+func (h *TranslationHandler) ListTranslations(c *gin.Context) {
+	req, ok := bindListTranslations(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.translationLogic.ListTranslations(c.Request.Context(), req.Language)
+	if err != nil {
+		utils.ErrorResponse(c, "translation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.Success(c, list)
+}
+
+// ListOverrides 获取管理员编辑的翻译覆盖
+func (h *TranslationHandler) ListOverrides(c *gin.Context) {
+	req, ok := bindListTranslations(c)
+	if !ok {
+		return
+	}
+
+	overrides, err := h.translationLogic.ListOverrides(c.Request.Context(), req.Language)
+	if err != nil {
+		utils.ErrorResponse(c, "translation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.Success(c, overrides)
+}
+
+// SaveOverride 保存翻译覆盖，无需重新发布，所有实例同步生效
+func (h *TranslationHandler) SaveOverride(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	target, ok := bindTranslationTarget(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SaveTranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	before := h.auditOverride(c, "translation_override.save", target)
+	override, err := h.translationLogic.SaveOverride(c.Request.Context(), adminID, target.Language, target.Key, req.Forms)
+	if errors.Is(err, logic.ErrUnknownTranslationKey) {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, "translation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	utils.SetAuditChange(c, before, override)
+
+	appLogger.Audit("保存翻译覆盖", map[string]interface{}{
+		"admin_id": adminID,
+		"language": target.Language,
+		"key":      target.Key,
+		"ip":       c.ClientIP(),
+	})
+
+	utils.SuccessWithMessage(c, "translation_saved", override, nil)
+}
+
+// DeleteOverride 删除翻译覆盖，恢复为翻译文件中的消息
+func (h *TranslationHandler) DeleteOverride(c *gin.Context) {
+	target, ok := bindTranslationTarget(c)
+	if !ok {
+		return
+	}
+
+	before := h.auditOverride(c, "translation_override.delete", target)
+	err := h.translationLogic.DeleteOverride(c.Request.Context(), target.Language, target.Key)
+	if errors.Is(err, logic.ErrTranslationOverrideNotFound) {
+		utils.ErrorResponse(c, "translation_override_not_found", nil)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, "translation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	utils.SetAuditChange(c, before, nil)
+
+	adminID, _ := utils.GetAdminID(c)
+	appLogger.Audit("删除翻译覆盖", map[string]interface{}{
+		"admin_id": adminID,
+		"language": target.Language,
+		"key":      target.Key,
+		"ip":       c.ClientIP(),
+	})
+
+	utils.SuccessWithMessage(c, "translation_deleted", nil, nil)
+}
+
+// bindListTranslations 解析查询参数中的语言，失败时直接返回错误响应
+func bindListTranslations(c *gin.Context) (*dto.ListTranslationsRequest, bool) {
+	var req dto.ListTranslationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return nil, false
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return nil, false
+	}
+	return &req, true
+}
+
+// auditOverride 设置审计记录的操作和翻译覆盖，返回修改前的翻译覆盖
+func (h *TranslationHandler) auditOverride(c *gin.Context, action string, target *dto.TranslationTarget) *mongodb.TranslationOverride {
+	utils.SetAuditAction(c, action)
+	utils.SetAuditTarget(c, "translation_override", target.Language+"/"+target.Key)
+
+	overrides, err := h.translationLogic.ListOverrides(c.Request.Context(), target.Language)
+	if err != nil {
+		return nil
+	}
+	for _, override := range overrides {
+		if override.Key == target.Key {
+			return override
+		}
+	}
+	return nil
+}
+
+// bindTranslationTarget 解析路径中的语言和消息键，失败时直接返回错误响应
+func bindTranslationTarget(c *gin.Context) (*dto.TranslationTarget, bool) {
+	var target dto.TranslationTarget
+	if err := c.ShouldBindUri(&target); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return nil, false
+	}
+
+	if err := target.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return nil, false
+	}
+	return &target, true
+}
@@ -0,0 +1,221 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/modules/admin/dto"
+	"exchange/internal/modules/admin/logic"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/twofactor"
+	"exchange/internal/utils"
+)
+
+// TwoFactorHandler 管理员两步验证处理器 - 处理绑定、启用、关闭两步验证和登录时确认绑定
+type TwoFactorHandler struct {
+	twoFactorLogic logic.AdminTwoFactorLogic // 两步验证业务逻辑
+	authLogic      logic.AdminAuthLogic      // 认证业务逻辑，确认绑定后签发登录token
+}
+
+// NewTwoFactorHandler 创建管理员两步验证处理器
+func NewTwoFactorHandler(twoFactorLogic logic.AdminTwoFactorLogic, authLogic logic.AdminAuthLogic) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorLogic: twoFactorLogic,
+		authLogic:      authLogic,
+	}
+}
+
+// GetStatus 获取两步验证状态
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	status, err := h.twoFactorLogic.GetStatus(c.Request.Context(), adminID)
+	if err != nil {
+		twoFactorErrorResponse(c, err)
+		return
+	}
+
+	utils.Success(c, status)
+}
+
+// BeginSetup 开始绑定，返回密钥和绑定地址，使用验证器应用扫码后调用Enable确认
+func (h *TwoFactorHandler) BeginSetup(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	auditTwoFactor(c, "two_factor.setup", adminID)
+	enrollment, err := h.twoFactorLogic.BeginSetup(c.Request.Context(), adminID)
+	if err != nil {
+		twoFactorErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "two_factor_setup_started", dto.NewTwoFactorSetupResponse(enrollment, ""), nil)
+}
+
+// Enable 使用验证码确认绑定并启用两步验证，返回备用码
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	auditTwoFactor(c, "two_factor.enable", adminID)
+	codes, err := h.twoFactorLogic.Enable(c.Request.Context(), adminID, req.Code)
+	if err != nil {
+		twoFactorErrorResponse(c, err)
+		return
+	}
+
+	appLogger.Audit("启用两步验证", map[string]interface{}{
+		"admin_id": adminID,
+		"ip":       c.ClientIP(),
+	})
+
+	utils.SuccessWithMessage(c, "two_factor_enabled", dto.BackupCodesResponse{BackupCodes: codes}, nil)
+}
+
+// Disable 关闭两步验证
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	auditTwoFactor(c, "two_factor.disable", adminID)
+	if err := h.twoFactorLogic.Disable(c.Request.Context(), adminID, req.Code); err != nil {
+		twoFactorErrorResponse(c, err)
+		return
+	}
+
+	appLogger.Audit("关闭两步验证", map[string]interface{}{
+		"admin_id": adminID,
+		"ip":       c.ClientIP(),
+	})
+
+	utils.SuccessWithMessage(c, "two_factor_disabled", nil, nil)
+}
+
+// RegenerateBackupCodes 重新生成备用码，之前的备用码全部失效
+func (h *TwoFactorHandler) RegenerateBackupCodes(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	auditTwoFactor(c, "two_factor.backup_codes", adminID)
+	codes, err := h.twoFactorLogic.RegenerateBackupCodes(c.Request.Context(), adminID, req.Code)
+	if err != nil {
+		twoFactorErrorResponse(c, err)
+		return
+	}
+
+	appLogger.Audit("重新生成两步验证备用码", map[string]interface{}{
+		"admin_id": adminID,
+		"ip":       c.ClientIP(),
+	})
+
+	utils.SuccessWithMessage(c, "two_factor_backup_codes_regenerated", dto.BackupCodesResponse{BackupCodes: codes}, nil)
+}
+
+// ConfirmSetup 强制启用两步验证的管理员登录时确认绑定，确认后完成登录
+func (h *TwoFactorHandler) ConfirmSetup(c *gin.Context) {
+	var req dto.ConfirmTwoFactorSetupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	admin, codes, err := h.twoFactorLogic.ConfirmSetup(c.Request.Context(), req.SetupToken, req.Code)
+	if err != nil {
+		twoFactorErrorResponse(c, err)
+		return
+	}
+
+	token, err := h.authLogic.GenerateAdminToken(admin.ID, string(admin.Role))
+	if err != nil {
+		utils.ErrorResponse(c, "token_generation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	appLogger.Audit("启用两步验证", map[string]interface{}{
+		"admin_id": admin.ID,
+		"ip":       c.ClientIP(),
+	})
+
+	response := dto.ConfirmTwoFactorSetupResponse{
+		Admin:       admin.ToPublicAdmin(),
+		Token:       token,
+		BackupCodes: codes,
+	}
+
+	utils.SuccessWithMessage(c, "two_factor_enabled", response, nil)
+}
+
+// auditTwoFactor 设置审计记录的操作，操作对象为管理员本人（不记录密钥和备用码）
+func auditTwoFactor(c *gin.Context, action string, adminID uint) {
+	utils.SetAuditAction(c, action)
+	utils.SetAuditTarget(c, "admin", strconv.FormatUint(uint64(adminID), 10))
+}
+
+// twoFactorErrorResponse 两步验证错误响应，登录时要求绑定的响应中附带绑定信息
+func twoFactorErrorResponse(c *gin.Context, err error) {
+	var setupErr *twofactor.SetupRequiredError
+	switch {
+	case errors.As(err, &setupErr):
+		utils.ErrorWithData(c, "two_factor_setup_required", dto.NewTwoFactorSetupResponse(setupErr.Enrollment, setupErr.SetupToken), nil)
+	case errors.Is(err, twofactor.ErrCodeRequired):
+		utils.ErrorResponse(c, "two_factor_required", nil)
+	case errors.Is(err, twofactor.ErrInvalidCode):
+		utils.ErrorResponse(c, "invalid_two_factor_code", nil)
+	case errors.Is(err, twofactor.ErrNotEnabled):
+		utils.ErrorResponse(c, "two_factor_not_enabled", nil)
+	case errors.Is(err, twofactor.ErrAlreadyEnabled):
+		utils.ErrorResponse(c, "two_factor_already_enabled", nil)
+	case errors.Is(err, twofactor.ErrNoPendingSetup):
+		utils.ErrorResponse(c, "two_factor_setup_expired", nil)
+	case errors.Is(err, twofactor.ErrInvalidSetupToken):
+		utils.ErrorResponse(c, "invalid_setup_token", nil)
+	case errors.Is(err, twofactor.ErrEnforced):
+		utils.ErrorResponse(c, "two_factor_enforced", nil)
+	default:
+		utils.ErrorResponse(c, "two_factor_failed", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+// isTwoFactorError 是否为两步验证错误（登录时区分密码错误和两步验证错误）
+func isTwoFactorError(err error) bool {
+	return errors.Is(err, twofactor.ErrSetupRequired) ||
+		errors.Is(err, twofactor.ErrCodeRequired) ||
+		errors.Is(err, twofactor.ErrInvalidCode)
+}
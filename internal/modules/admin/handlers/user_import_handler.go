@@ -0,0 +1,112 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/modules/admin/dto"
+	"exchange/internal/modules/admin/logic"
+	"exchange/internal/utils"
+)
+
+// UserImportHandler 用户批量导入处理器 - 处理CSV导入和导入任务查询的HTTP请求
+type UserImportHandler struct {
+	importLogic logic.UserImportLogic // 用户批量导入业务逻辑
+}
+
+// NewUserImportHandler 创建用户批量导入处理器
+func NewUserImportHandler(importLogic logic.UserImportLogic) *UserImportHandler {
+	return &UserImportHandler{
+		importLogic: importLogic,
+	}
+}
+
+// ImportUsers 上传CSV批量导入用户
+// CSV解析失败时直接返回错误；解析成功后创建导入任务在后台执行，通过GetImportJob查询进度和逐行错误
+func (h *UserImportHandler) ImportUsers(c *gin.Context) {
+	adminID, exists := utils.GetAdminID(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.UserImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": "file is required"})
+		return
+	}
+	if fileHeader.Size > dto.MaxUserImportFileSize {
+		utils.ErrorResponse(c, "file_too_large", nil)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.ErrorResponse(c, "file_upload_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	mode := mysql.UserImportMode(req.Mode)
+	rows, err := logic.ParseUserImportCSV(file, mode)
+	if err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.SetAuditAction(c, "user.import")
+	job, err := h.importLogic.StartImport(c.Request.Context(), adminID, fileHeader.Filename, mode, req.DryRun, rows)
+	if err != nil {
+		utils.ErrorResponse(c, "user_import_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	// 导入任务在后台执行，只记录发起时的参数
+	utils.SetAuditTarget(c, "user_import_job", strconv.FormatUint(uint64(job.ID), 10))
+	utils.SetAuditChange(c, nil, map[string]interface{}{
+		"file_name":  fileHeader.Filename,
+		"mode":       mode,
+		"dry_run":    req.DryRun,
+		"total_rows": len(rows),
+	})
+
+	utils.SuccessWithMessage(c, "user_import_started", job, nil)
+}
+
+// GetImportJob 获取导入任务详情（含逐行错误）
+func (h *UserImportHandler) GetImportJob(c *gin.Context) {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || jobID == 0 {
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": "invalid id"})
+		return
+	}
+
+	job, err := h.importLogic.GetImportJob(c.Request.Context(), uint(jobID))
+	if err != nil {
+		utils.ErrorResponse(c, "user_import_job_not_found", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	rowErrors, err := job.GetRowErrors()
+	if err != nil {
+		utils.ErrorResponse(c, "user_import_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.SuccessWithMessage(c, "user_import_job_retrieved", dto.UserImportJobResponse{
+		UserImportJob: job,
+		RowErrors:     rowErrors,
+	}, nil)
+}
@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/modules/admin/dto"
+	"exchange/internal/modules/admin/logic"
+	"exchange/internal/utils"
+)
+
+// UserOverviewHandler 用户概览处理器 - 客服查看用户时一次获取资料、账户状态、登录、消息和风险标记
+type UserOverviewHandler struct {
+	overviewLogic logic.AdminUserOverviewLogic // 用户概览业务逻辑
+}
+
+// NewUserOverviewHandler 创建用户概览处理器
+func NewUserOverviewHandler(overviewLogic logic.AdminUserOverviewLogic) *UserOverviewHandler {
+	return &UserOverviewHandler{
+		overviewLogic: overviewLogic,
+	}
+}
+
+// GetUserOverview 获取用户概览
+// 各部分并行加载，当前管理员角色无权查看的部分列在hidden中，加载失败的部分列在errors中
+func (h *UserOverviewHandler) GetUserOverview(c *gin.Context) {
+	adminRole, exists := utils.GetAdminRole(c)
+	if !exists {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || userID == 0 {
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": "invalid user id"})
+		return
+	}
+
+	var req dto.UserOverviewRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	overview, err := h.overviewLogic.GetUserOverview(c.Request.Context(), adminRole, uint(userID), req.SectionList())
+	if err != nil {
+		if errors.Is(err, logic.ErrUnknownOverviewSection) {
+			utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+			return
+		}
+		utils.ErrorResponseFromError(c, "user_overview_failed", err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "user_overview_retrieved", overview, nil)
+}
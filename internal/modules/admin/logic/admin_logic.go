@@ -13,15 +13,25 @@ import (
 
 	"exchange/internal/models/mysql"
 	"exchange/internal/modules/api/logic" // 导入API模块的logic以使用Claims类型
+	"exchange/internal/pkg/clock"
 	"exchange/internal/pkg/config"
+	appErrors "exchange/internal/pkg/errors"
+	"exchange/internal/pkg/jwtkeys"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/loginthrottle"
+	"exchange/internal/pkg/notification"
+	"exchange/internal/pkg/passwordreset"
+	"exchange/internal/pkg/rbac"
+	"exchange/internal/pkg/session"
+	"exchange/internal/pkg/tokenrevoke"
+	"exchange/internal/pkg/twofactor"
 	"exchange/internal/repository"
-	"exchange/internal/utils"
 )
 
 // AdminLogic 管理员业务逻辑接口 - 定义管理员相关的业务操作
 type AdminLogic interface {
 	// GetDashboard 获取管理员仪表板数据
-	GetDashboard(ctx context.Context, adminID uint) (interface{}, error)
+	GetDashboard(ctx context.Context, adminID uint) (*DashboardStats, error)
 
 	// GetAdminByID 根据管理员ID获取管理员信息
 	GetAdminByID(ctx context.Context, adminID uint) (*mysql.Admin, error)
@@ -44,11 +54,11 @@ type AdminAuthLogic interface {
 	HashPassword(password string) (string, error)
 	CheckPassword(password, hash string) bool
 
-	// 管理员认证方法
-	AuthenticateAdmin(ctx context.Context, username, password string) (*mysql.Admin, error)
-	AuthenticateUser(ctx context.Context, username, password string) (*mysql.User, error) // 实现API接口
+	// 管理员认证方法，code为两步验证的验证码或备用码（未启用两步验证时忽略），clientIP用于统计登录失败次数
+	AuthenticateAdmin(ctx context.Context, username, password, code, clientIP string) (*mysql.Admin, error)
+	AuthenticateUser(ctx context.Context, username, password, code, clientIP string) (*mysql.User, error) // 实现API接口
 
-	// Token黑名单管理
+	// Token撤销（按jti记录，token过期后自动清除）
 	RevokeToken(ctx context.Context, tokenString string) error
 	IsTokenRevoked(ctx context.Context, tokenString string) (bool, error)
 
@@ -59,29 +69,62 @@ type AdminAuthLogic interface {
 	GenerateRandomToken(length int) (string, error)
 }
 
+const (
+	dashboardCacheKey     = "admin:dashboard:stats" // 仪表板统计的缓存键，所有管理员共用
+	dashboardCacheTTL     = time.Minute             // 统计结果的缓存时间，仪表板允许有短暂延迟
+	dashboardDays         = 14                      // 按日统计的天数（含当天，UTC）
+	dashboardOnlineWindow = 5 * time.Minute         // 最近该时间内有活动的用户视为在线
+	dashboardLoginWindow  = 24 * time.Hour          // 最近登录用户数的统计窗口
+)
+
+// DashboardStats 管理员仪表板统计
+// 按日统计的序列按日期升序，包含没有数据的日期（数量为0）
+type DashboardStats struct {
+	TotalUsers       int64        `json:"total_users"`       // 总用户数
+	ActiveUsers      int64        `json:"active_users"`      // 状态为active的用户数
+	TotalAdmins      int64        `json:"total_admins"`      // 总管理员数
+	ActiveAdmins     int64        `json:"active_admins"`     // 状态为active的管理员数
+	OnlineUsers      int64        `json:"online_users"`      // 最近5分钟有活动的用户数
+	RecentLogins     int64        `json:"recent_logins"`     // 最近24小时登录过的用户数
+	NewRegistrations int64        `json:"new_registrations"` // 当天（UTC）注册数
+	SignupsPerDay    []DailyCount `json:"signups_per_day"`   // 每天注册数
+	LoginsPerDay     []DailyCount `json:"logins_per_day"`    // 每天登录次数
+	MessagesPerDay   []DailyCount `json:"messages_per_day"`  // 每天消息数
+	GeneratedAt      time.Time    `json:"generated_at"`      // 统计时间，缓存命中时为缓存写入时的统计时间
+}
+
+// DailyCount 某天的数量
+type DailyCount struct {
+	Date  string `json:"date"` // 2006-01-02（UTC）
+	Count int64  `json:"count"`
+}
+
 // AdminLogicImpl 管理员业务逻辑实现
 type AdminLogicImpl struct {
-	userRepo  repository.UserRepository  // 用户数据访问层
-	adminRepo repository.AdminRepository // 管理员数据访问层
+	userRepo    repository.UserRepository        // 用户数据访问层
+	adminRepo   repository.AdminRepository       // 管理员数据访问层
+	messageRepo repository.UserMessageRepository // 消息数据访问层
+	sessions    *session.Store                   // 登录会话（在线用户和每日登录次数）
+	cacheRepo   repository.CacheRepository       // 仪表板统计缓存
 }
 
 // NewAdminLogic 创建管理员业务逻辑实例
-func NewAdminLogic(userRepo repository.UserRepository, adminRepo repository.AdminRepository) *AdminLogicImpl {
+func NewAdminLogic(userRepo repository.UserRepository, adminRepo repository.AdminRepository, messageRepo repository.UserMessageRepository, sessions *session.Store, cacheRepo repository.CacheRepository) *AdminLogicImpl {
 	return &AdminLogicImpl{
-		userRepo:  userRepo,
-		adminRepo: adminRepo,
+		userRepo:    userRepo,
+		adminRepo:   adminRepo,
+		messageRepo: messageRepo,
+		sessions:    sessions,
+		cacheRepo:   cacheRepo,
 	}
 }
 
 // GetDashboard 获取管理员仪表板数据
 // 业务规则：
-// 1. 获取总用户数
-// 2. 获取活跃用户数
-// 3. 获取总管理员数
-// 4. 获取活跃管理员数
-// 5. 获取最近登录数
-// 6. 获取新注册数
-func (l *AdminLogicImpl) GetDashboard(ctx context.Context, adminID uint) (interface{}, error) {
+// 1. 验证管理员是否存在
+// 2. 统计结果缓存dashboardCacheTTL，缓存未命中时重新统计
+// 3. 用户和管理员数量、注册数从MySQL统计，消息数从MongoDB统计，在线用户和登录次数从Redis中的会话记录统计
+func (l *AdminLogicImpl) GetDashboard(ctx context.Context, adminID uint) (*DashboardStats, error) {
 	// 第一步：验证管理员是否存在
 	admin, err := l.adminRepo.GetByID(ctx, adminID)
 	if err != nil {
@@ -91,17 +134,79 @@ func (l *AdminLogicImpl) GetDashboard(ctx context.Context, adminID uint) (interf
 		return nil, errors.New("管理员不存在")
 	}
 
-	// 第二步：获取仪表板数据（这里简化处理，实际应该从数据库统计）
-	dashboard := map[string]interface{}{
-		"total_users":       0, // 总用户数
-		"active_users":      0, // 活跃用户数
-		"total_admins":      0, // 总管理员数
-		"active_admins":     0, // 活跃管理员数
-		"recent_logins":     0, // 最近登录数
-		"new_registrations": 0, // 新注册数
+	// 第二步：读取缓存
+	var cached DashboardStats
+	if err := l.cacheRepo.GetJSON(dashboardCacheKey, &cached); err == nil {
+		return &cached, nil
+	}
+
+	// 第三步：重新统计并写入缓存，写缓存失败不影响返回
+	stats, err := l.collectDashboardStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.cacheRepo.SetJSON(dashboardCacheKey, stats, dashboardCacheTTL); err != nil {
+		appLogger.Warn("缓存仪表板统计失败", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return stats, nil
+}
+
+// collectDashboardStats 统计仪表板数据
+func (l *AdminLogicImpl) collectDashboardStats(ctx context.Context) (*DashboardStats, error) {
+	now := clock.Now().UTC()
+	stats := &DashboardStats{GeneratedAt: now}
+	var err error
+
+	if stats.TotalUsers, err = l.userRepo.Count(ctx); err != nil {
+		return nil, fmt.Errorf("统计用户数失败: %w", err)
+	}
+	if stats.ActiveUsers, err = l.userRepo.CountByStatus(ctx, mysql.UserStatusActive); err != nil {
+		return nil, fmt.Errorf("统计活跃用户数失败: %w", err)
+	}
+	if stats.TotalAdmins, err = l.adminRepo.Count(ctx); err != nil {
+		return nil, fmt.Errorf("统计管理员数失败: %w", err)
+	}
+	if stats.ActiveAdmins, err = l.adminRepo.CountByStatus(ctx, mysql.AdminStatusActive); err != nil {
+		return nil, fmt.Errorf("统计活跃管理员数失败: %w", err)
+	}
+	if stats.RecentLogins, err = l.userRepo.CountLoggedInSince(ctx, now.Add(-dashboardLoginWindow)); err != nil {
+		return nil, fmt.Errorf("统计最近登录用户数失败: %w", err)
+	}
+	if stats.OnlineUsers, err = l.sessions.CountOnline(ctx, dashboardOnlineWindow); err != nil {
+		return nil, fmt.Errorf("统计在线用户数失败: %w", err)
+	}
+
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1-dashboardDays)
+	signups, err := l.userRepo.CountCreatedPerDay(ctx, start)
+	if err != nil {
+		return nil, fmt.Errorf("统计每日注册数失败: %w", err)
 	}
+	logins, err := l.sessions.LoginsPerDay(ctx, dashboardDays)
+	if err != nil {
+		return nil, fmt.Errorf("统计每日登录次数失败: %w", err)
+	}
+	messages, err := l.messageRepo.CountPerDay(ctx, start)
+	if err != nil {
+		return nil, fmt.Errorf("统计每日消息数失败: %w", err)
+	}
+
+	stats.SignupsPerDay = dailySeries(start, dashboardDays, signups)
+	stats.LoginsPerDay = dailySeries(start, dashboardDays, logins)
+	stats.MessagesPerDay = dailySeries(start, dashboardDays, messages)
+	stats.NewRegistrations = signups[now.Format("2006-01-02")]
+	return stats, nil
+}
 
-	return dashboard, nil
+// dailySeries 将按日期统计的数量展开为从start开始连续days天的序列，缺少的日期数量为0
+func dailySeries(start time.Time, days int, counts map[string]int64) []DailyCount {
+	series := make([]DailyCount, days)
+	for i := range series {
+		date := start.AddDate(0, 0, i).Format("2006-01-02")
+		series[i] = DailyCount{Date: date, Count: counts[date]}
+	}
+	return series
 }
 
 // GetAdminByID 根据管理员ID获取管理员信息
@@ -192,27 +297,66 @@ type AdminUserLogic interface {
 	// GetUserByID 根据用户ID获取用户信息
 	GetUserByID(ctx context.Context, userID uint) (*mysql.User, error)
 
-	// GetUsers 获取用户列表
-	GetUsers(ctx context.Context, page, pageSize int64, keyword, status, role string) ([]*mysql.User, int64, error)
+	// GetUsers 按条件分页查询用户，返回当前页的用户和符合条件的总数
+	GetUsers(ctx context.Context, query repository.UserQuery) ([]*mysql.User, int64, error)
 
 	// UpdateUser 更新用户信息
 	UpdateUser(ctx context.Context, userID uint, username, email string) (*mysql.User, error)
 
-	// DeleteUser 删除用户
+	// DeleteUser 删除用户（软删除）并强制下线
 	DeleteUser(ctx context.Context, userID uint) error
+
+	// RestoreUser 恢复已删除（软删除）的用户
+	RestoreUser(ctx context.Context, userID uint) (*mysql.User, error)
+
+	// ForceLogout 强制用户下线，撤销用户的所有登录会话和已签发的token，返回撤销的会话数
+	ForceLogout(ctx context.Context, userID uint) (int, error)
+
+	// BanUser 封禁用户并强制下线，封禁后不能登录
+	BanUser(ctx context.Context, userID uint) (*mysql.User, error)
+
+	// UnbanUser 解除封禁，用户未被封禁时返回ErrUserNotBanned
+	UnbanUser(ctx context.Context, userID uint) (*mysql.User, error)
+
+	// LockUser 锁定用户duration时间并强制下线，锁定期间不能登录
+	LockUser(ctx context.Context, userID uint, duration time.Duration) (*mysql.User, error)
+
+	// UnlockUser 解除锁定
+	UnlockUser(ctx context.Context, userID uint) (*mysql.User, error)
+
+	// ForcePasswordReset 要求用户重置密码：强制下线，通过找回密码设置新密码前不能登录，并发送重置密码邮件
+	// 返回是否已发送邮件（超过重置邮件发送上限时不发送，用户可稍后自行找回密码）
+	ForcePasswordReset(ctx context.Context, userID uint) (*mysql.User, bool, error)
 }
 
+var (
+	// ErrUserNotBanned 用户未被封禁
+	ErrUserNotBanned = errors.New("user is not banned")
+	// ErrUserAccountClosed 用户已注销（个人信息已匿名化），不能封禁、锁定、要求重置密码或恢复
+	ErrUserAccountClosed = errors.New("user account is closed")
+)
+
 // AdminUserLogicImpl 管理员用户业务逻辑实现
 type AdminUserLogicImpl struct {
+	config    *config.Config
 	userRepo  repository.UserRepository  // 用户数据访问层
 	adminRepo repository.AdminRepository // 管理员数据访问层
+	sessions  *session.Store             // 用户登录会话
+	revoked   *tokenrevoke.Store         // token撤销记录
+	resets    *passwordreset.Store       // 重置密码token，与API模块的找回密码共用
+	notifier  notification.Notifier      // 发送重置密码邮件
 }
 
 // NewAdminUserLogic 创建管理员用户业务逻辑实例
-func NewAdminUserLogic(userRepo repository.UserRepository, adminRepo repository.AdminRepository) *AdminUserLogicImpl {
+func NewAdminUserLogic(cfg *config.Config, userRepo repository.UserRepository, adminRepo repository.AdminRepository, sessions *session.Store, revoked *tokenrevoke.Store, resets *passwordreset.Store, notifier notification.Notifier) *AdminUserLogicImpl {
 	return &AdminUserLogicImpl{
+		config:    cfg,
 		userRepo:  userRepo,
 		adminRepo: adminRepo,
+		sessions:  sessions,
+		revoked:   revoked,
+		resets:    resets,
+		notifier:  notifier,
 	}
 }
 
@@ -220,61 +364,20 @@ func NewAdminUserLogic(userRepo repository.UserRepository, adminRepo repository.
 func (l *AdminUserLogicImpl) GetUserByID(ctx context.Context, userID uint) (*mysql.User, error) {
 	user, err := l.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("查询用户失败: %w", err)
+		return nil, appErrors.TranslateUserError(err, "查询用户失败", userID)
 	}
 	if user == nil {
-		return nil, errors.New("用户不存在")
+		return nil, appErrors.ErrUserNotFound.WithContext("user_id", userID)
 	}
 	return user, nil
 }
 
-// GetUsers 获取用户列表
-func (l *AdminUserLogicImpl) GetUsers(ctx context.Context, page, pageSize int64, keyword, status, role string) ([]*mysql.User, int64, error) {
-	var users []*mysql.User
-	var total int64
-	var err error
-
-	// 构建查询条件
-	conditions := make(map[string]interface{})
-	if status != "" {
-		conditions["status"] = status
-	}
-	if role != "" {
-		conditions["role"] = role
-	}
-
-	// 定义搜索字段
-	searchFields := []string{"username", "email"}
-
-	// 使用GORM分页工具进行查询
-	if keyword != "" {
-		// 带关键词搜索的分页查询
-		total, err = utils.GormPaginateWithKeyword(
-			l.userRepo.DB(), // 数据库实例
-			&mysql.User{},   // 模型
-			int(page),       // 页码
-			int(pageSize),   // 每页大小
-			keyword,         // 搜索关键词
-			searchFields,    // 搜索字段
-			conditions,      // 查询条件
-			&users,          // 结果
-		)
-	} else {
-		// 普通分页查询
-		total, err = utils.GormPaginateWithCount(
-			l.userRepo.DB(), // 数据库实例
-			&mysql.User{},   // 模型
-			int(page),       // 页码
-			int(pageSize),   // 每页大小
-			conditions,      // 查询条件
-			&users,          // 结果
-		)
-	}
-
+// GetUsers 按条件分页查询用户
+func (l *AdminUserLogicImpl) GetUsers(ctx context.Context, query repository.UserQuery) ([]*mysql.User, int64, error) {
+	users, total, err := l.userRepo.Query(ctx, query)
 	if err != nil {
 		return nil, 0, fmt.Errorf("获取用户列表失败: %w", err)
 	}
-
 	return users, total, nil
 }
 
@@ -283,17 +386,17 @@ func (l *AdminUserLogicImpl) UpdateUser(ctx context.Context, userID uint, userna
 	// 获取用户
 	user, err := l.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("查询用户失败: %w", err)
+		return nil, appErrors.TranslateUserError(err, "查询用户失败", userID)
 	}
 	if user == nil {
-		return nil, errors.New("用户不存在")
+		return nil, appErrors.ErrUserNotFound.WithContext("user_id", userID)
 	}
 
 	// 更新用户名
 	if username != "" && username != user.Username {
 		existingUser, err := l.userRepo.GetByUsername(ctx, username)
 		if err == nil && existingUser != nil {
-			return nil, errors.New("用户名已被其他用户使用")
+			return nil, appErrors.UserConflictError("用户名已被其他用户使用", "username", username)
 		}
 		user.Username = username
 	}
@@ -302,19 +405,19 @@ func (l *AdminUserLogicImpl) UpdateUser(ctx context.Context, userID uint, userna
 	if email != "" && email != user.Email {
 		existingUser, err := l.userRepo.GetByEmail(ctx, email)
 		if err == nil && existingUser != nil {
-			return nil, errors.New("邮箱已被其他用户使用")
+			return nil, appErrors.UserConflictError("邮箱已被其他用户使用", "email", email)
 		}
 		user.Email = email
 	}
 
 	// 验证用户数据
 	if err := user.Validate(); err != nil {
-		return nil, fmt.Errorf("用户数据验证失败: %w", err)
+		return nil, appErrors.InvalidUserError(err, user.ID)
 	}
 
 	// 保存到数据库
 	if err := l.userRepo.Update(ctx, user); err != nil {
-		return nil, fmt.Errorf("用户更新失败: %w", err)
+		return nil, appErrors.TranslateUserError(err, "用户更新失败", user.ID)
 	}
 
 	return user, nil
@@ -325,100 +428,298 @@ func (l *AdminUserLogicImpl) DeleteUser(ctx context.Context, userID uint) error
 	// 获取用户
 	user, err := l.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return fmt.Errorf("查询用户失败: %w", err)
+		return appErrors.TranslateUserError(err, "查询用户失败", userID)
 	}
 	if user == nil {
-		return errors.New("用户不存在")
+		return appErrors.ErrUserNotFound.WithContext("user_id", userID)
 	}
 
 	// 删除用户
 	if err := l.userRepo.Delete(ctx, userID); err != nil {
-		return fmt.Errorf("用户删除失败: %w", err)
+		return appErrors.TranslateUserError(err, "用户删除失败", userID)
+	}
+
+	// 强制下线
+	if _, err := l.revokeAccess(ctx, userID); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+// RestoreUser 恢复已删除（软删除）的用户，已注销的账户不能恢复
+func (l *AdminUserLogicImpl) RestoreUser(ctx context.Context, userID uint) (*mysql.User, error) {
+	user, err := l.userRepo.GetDeletedByID(ctx, userID)
+	if err != nil {
+		return nil, appErrors.TranslateUserError(err, "查询用户失败", userID)
+	}
+	if user.IsAccountDeleted() {
+		return nil, ErrUserAccountClosed
+	}
+
+	if err := l.userRepo.Restore(ctx, userID); err != nil {
+		return nil, appErrors.TranslateUserError(err, "用户恢复失败", userID)
+	}
+	return l.GetUserByID(ctx, userID)
+}
+
+// ForceLogout 强制用户下线，用户所有设备上的访问token和刷新token随即失效
+func (l *AdminUserLogicImpl) ForceLogout(ctx context.Context, userID uint) (int, error) {
+	if _, err := l.GetUserByID(ctx, userID); err != nil {
+		return 0, err
+	}
+	return l.revokeAccess(ctx, userID)
+}
+
+// BanUser 封禁用户，已封禁时只重新强制下线
+func (l *AdminUserLogicImpl) BanUser(ctx context.Context, userID uint) (*mysql.User, error) {
+	user, err := l.getManageableUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Status != mysql.UserStatusBanned {
+		if err := l.userRepo.UpdateStatus(ctx, userID, mysql.UserStatusBanned); err != nil {
+			return nil, appErrors.TranslateUserError(err, "用户状态更新失败", userID)
+		}
+		user.Status = mysql.UserStatusBanned
+	}
+
+	if _, err := l.revokeAccess(ctx, userID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UnbanUser 解除封禁，用户恢复为active状态
+func (l *AdminUserLogicImpl) UnbanUser(ctx context.Context, userID uint) (*mysql.User, error) {
+	user, err := l.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != mysql.UserStatusBanned {
+		return nil, ErrUserNotBanned
+	}
+
+	if err := l.userRepo.UpdateStatus(ctx, userID, mysql.UserStatusActive); err != nil {
+		return nil, appErrors.TranslateUserError(err, "用户状态更新失败", userID)
+	}
+	user.Status = mysql.UserStatusActive
+	return user, nil
+}
+
+// LockUser 锁定用户，已锁定时以本次的截止时间为准
+func (l *AdminUserLogicImpl) LockUser(ctx context.Context, userID uint, duration time.Duration) (*mysql.User, error) {
+	if duration <= 0 {
+		return nil, errors.New("lock duration must be positive")
+	}
+	user, err := l.getManageableUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	lockedUntil := clock.Now().Add(duration)
+	user.LockedUntil = &lockedUntil
+	if err := l.userRepo.Update(ctx, user); err != nil {
+		return nil, appErrors.TranslateUserError(err, "用户更新失败", userID)
+	}
+
+	if _, err := l.revokeAccess(ctx, userID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UnlockUser 解除锁定，未锁定时直接返回
+func (l *AdminUserLogicImpl) UnlockUser(ctx context.Context, userID uint) (*mysql.User, error) {
+	user, err := l.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.LockedUntil == nil {
+		return user, nil
+	}
+
+	user.LockedUntil = nil
+	if err := l.userRepo.Update(ctx, user); err != nil {
+		return nil, appErrors.TranslateUserError(err, "用户更新失败", userID)
+	}
+	return user, nil
+}
+
+// ForcePasswordReset 要求用户重置密码
+// 业务规则：
+// 1. 标记需要重置密码，用户使用原密码登录时返回appErrors.ErrPasswordResetRequired
+// 2. 撤销所有登录会话和已签发的token
+// 3. 签发重置token并发送重置密码邮件，重置成功后清除标记
+func (l *AdminUserLogicImpl) ForcePasswordReset(ctx context.Context, userID uint) (*mysql.User, bool, error) {
+	user, err := l.getManageableUser(ctx, userID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !user.PasswordResetRequired {
+		user.PasswordResetRequired = true
+		if err := l.userRepo.Update(ctx, user); err != nil {
+			return nil, false, appErrors.TranslateUserError(err, "用户更新失败", userID)
+		}
+	}
+
+	if _, err := l.revokeAccess(ctx, userID); err != nil {
+		return nil, false, err
+	}
+
+	token, expiresAt, err := l.resets.Issue(ctx, user.ID)
+	if errors.Is(err, passwordreset.ErrTooManyRequests) {
+		return user, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("签发重置密码token失败: %w", err)
+	}
+
+	if err := l.notifier.Notify(ctx, &notification.Notification{
+		UserID:   user.ID,
+		Event:    "password_reset_requested",
+		Email:    user.Email,
+		Language: user.Language,
+		Data: map[string]interface{}{
+			"reset_link": l.config.Account.PasswordResetURL + token,
+			"expires_at": expiresAt,
+		},
+		CreatedAt: clock.Now(),
+	}); err != nil {
+		return nil, false, fmt.Errorf("发送重置密码邮件失败: %w", err)
+	}
+	return user, true, nil
+}
+
+// getManageableUser 获取可以封禁、锁定或要求重置密码的用户（未注销）
+func (l *AdminUserLogicImpl) getManageableUser(ctx context.Context, userID uint) (*mysql.User, error) {
+	user, err := l.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsAccountDeleted() {
+		return nil, ErrUserAccountClosed
+	}
+	return user, nil
+}
+
+// revokeAccess 撤销用户的所有登录会话和已签发的token，返回撤销的会话数
+func (l *AdminUserLogicImpl) revokeAccess(ctx context.Context, userID uint) (int, error) {
+	revoked, err := l.sessions.RevokeAll(ctx, userID, "")
+	if err != nil {
+		return 0, fmt.Errorf("撤销用户登录会话失败: %w", err)
+	}
+
+	// 会话检查失败时放行访问token，按jti撤销确保已签发的token同样失效
+	claims := &logic.Claims{UserID: userID}
+	if _, err := l.revoked.RevokeAll(ctx, claims.Principal()); err != nil {
+		return 0, fmt.Errorf("撤销用户token失败: %w", err)
+	}
+	return revoked, nil
+}
+
 // AdminAuthLogicImpl 管理员认证业务逻辑实现
 type AdminAuthLogicImpl struct {
 	config    *config.Config
-	secretKey []byte
+	keys      *jwtkeys.Keyset
 	userRepo  repository.UserRepository
 	adminRepo repository.AdminRepository
 	cacheRepo repository.CacheRepository
+	revoked   *tokenrevoke.Store
+	twoFactor *twofactor.Service
+	throttle  *loginthrottle.Throttle
 }
 
 // NewAdminAuthLogic 创建管理员认证业务逻辑实例
-func NewAdminAuthLogic(cfg *config.Config, userRepo repository.UserRepository, adminRepo repository.AdminRepository, cacheRepo repository.CacheRepository) (*AdminAuthLogicImpl, error) {
-	// 从配置中获取密钥，如果没有则生成一个
-	secretKey := []byte(cfg.JWT.SecretKey)
-	if len(secretKey) == 0 {
-		// 生成随机密钥
-		key := make([]byte, 32)
-		if _, err := rand.Read(key); err != nil {
-			return nil, fmt.Errorf("failed to generate secret key: %w", err)
-		}
-		secretKey = key
+// keys必须与API模块使用相同的JWT配置，管理员token由API模块的认证中间件验证
+// revoked为token撤销记录，与API模块共用，签发的token按jti登记
+// twoFactor为nil时登录不进行两步验证，throttle为nil时不限制登录失败次数
+func NewAdminAuthLogic(cfg *config.Config, keys *jwtkeys.Keyset, userRepo repository.UserRepository, adminRepo repository.AdminRepository, cacheRepo repository.CacheRepository, revoked *tokenrevoke.Store, twoFactor *twofactor.Service, throttle *loginthrottle.Throttle) (*AdminAuthLogicImpl, error) {
+	if keys == nil {
+		return nil, errors.New("jwt keyset is required")
 	}
 
 	return &AdminAuthLogicImpl{
 		config:    cfg,
-		secretKey: secretKey,
+		keys:      keys,
 		userRepo:  userRepo,
 		adminRepo: adminRepo,
 		cacheRepo: cacheRepo,
+		revoked:   revoked,
+		twoFactor: twoFactor,
+		throttle:  throttle,
 	}, nil
 }
 
 // GenerateAdminToken 生成管理员token
 func (l *AdminAuthLogicImpl) GenerateAdminToken(adminID uint, role string) (string, error) {
-	// 管理员token带有"admin:"前缀
-	adminRole := "admin:" + role
-	return l.GenerateToken(adminID, adminRole)
+	// 管理员token的角色带有前缀，与用户token区分
+	return l.GenerateToken(adminID, rbac.AdminTokenRole(role))
 }
 
 // GenerateToken 生成JWT token
 func (l *AdminAuthLogicImpl) GenerateToken(userID uint, role string) (string, error) {
-	now := time.Now()
+	now := clock.Now()
 	expirationTime := now.Add(time.Duration(l.config.JWT.ExpirationHours) * time.Hour)
 
+	jti, err := tokenrevoke.NewJTI()
+	if err != nil {
+		return "", err
+	}
+
 	claims := &logic.Claims{ // 使用API模块的Claims类型
 		UserID: userID,
 		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(l.secretKey)
+	tokenString, err := l.keys.Sign(claims)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
 
+	// 登记到所属主体的有效token集合，撤销主体的全部token时使用
+	if err := l.revoked.Register(context.Background(), claims.Principal(), jti, expirationTime); err != nil {
+		return "", err
+	}
+
 	return tokenString, nil
 }
 
 // ValidateToken 验证JWT token
 func (l *AdminAuthLogicImpl) ValidateToken(tokenString string) (*logic.Claims, error) { // 使用API模块的Claims类型
-	token, err := jwt.ParseWithClaims(tokenString, &logic.Claims{}, func(token *jwt.Token) (interface{}, error) { // 使用API模块的Claims类型
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return l.secretKey, nil
-	})
+	token, err := l.keys.Parse(tokenString, &logic.Claims{}, jwt.WithTimeFunc(clock.Now)) // 使用API模块的Claims类型，过期时间按业务时间校验（模拟时钟）
 
 	if err != nil {
 		return nil, fmt.Errorf("invalid token: %w", err)
 	}
 
-	if claims, ok := token.Claims.(*logic.Claims); ok && token.Valid { // 使用API模块的Claims类型
-		return claims, nil
+	claims, ok := token.Claims.(*logic.Claims) // 使用API模块的Claims类型
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid token claims")
 	}
 
-	return nil, fmt.Errorf("invalid token claims")
+	// 已按jti撤销的token拒绝访问，查询失败时放行，只记录日志
+	if claims.ID != "" {
+		revoked, err := l.revoked.IsRevoked(context.Background(), claims.ID)
+		if err != nil {
+			appLogger.Warn("查询token撤销记录失败", map[string]interface{}{
+				"jti":   claims.ID,
+				"error": err.Error(),
+			})
+		} else if revoked {
+			return nil, fmt.Errorf("invalid token: %w", tokenrevoke.ErrRevoked)
+		}
+	}
+
+	return claims, nil
 }
 
 // RefreshToken 刷新token
@@ -457,7 +758,28 @@ func (l *AdminAuthLogicImpl) CheckPassword(password, hash string) bool {
 }
 
 // AuthenticateAdmin 管理员认证
-func (l *AdminAuthLogicImpl) AuthenticateAdmin(ctx context.Context, username, password string) (*mysql.Admin, error) {
+// 用户名或客户端IP登录失败次数过多时直接返回appErrors.ErrAccountLocked，不校验密码
+// 已启用两步验证时校验code；角色强制启用两步验证但未启用时返回*twofactor.SetupRequiredError
+func (l *AdminAuthLogicImpl) AuthenticateAdmin(ctx context.Context, username, password, code, clientIP string) (*mysql.Admin, error) {
+	if err := l.throttle.Check(ctx, loginthrottle.ScopeAdmin, username, clientIP); err != nil {
+		return nil, err
+	}
+
+	admin, err := l.authenticateAdmin(ctx, username, password, code)
+	if err != nil {
+		// 需要输入两步验证码或完成绑定时不计为失败
+		if !errors.Is(err, twofactor.ErrCodeRequired) && !errors.Is(err, twofactor.ErrSetupRequired) {
+			l.throttle.Fail(ctx, loginthrottle.ScopeAdmin, username, clientIP)
+		}
+		return nil, err
+	}
+
+	l.throttle.Succeed(ctx, loginthrottle.ScopeAdmin, username)
+	return admin, nil
+}
+
+// authenticateAdmin 校验用户名、密码和两步验证码
+func (l *AdminAuthLogicImpl) authenticateAdmin(ctx context.Context, username, password, code string) (*mysql.Admin, error) {
 	// 获取管理员
 	admin, err := l.adminRepo.GetByUsername(ctx, username)
 	if err != nil {
@@ -474,6 +796,14 @@ func (l *AdminAuthLogicImpl) AuthenticateAdmin(ctx context.Context, username, pa
 		return nil, errors.New("invalid password")
 	}
 
+	// 两步验证
+	if l.twoFactor != nil {
+		subject := twofactor.Subject{Type: mysql.TwoFactorSubjectAdmin, ID: admin.ID}
+		if err := l.twoFactor.CheckLogin(ctx, subject, admin.TwoFactorEnabled, string(admin.Role), admin.Username, code); err != nil {
+			return nil, err
+		}
+	}
+
 	// 更新登录信息
 	admin.UpdateLoginInfo()
 	if err := l.adminRepo.UpdateLastLogin(ctx, admin.ID); err != nil {
@@ -485,7 +815,28 @@ func (l *AdminAuthLogicImpl) AuthenticateAdmin(ctx context.Context, username, pa
 }
 
 // AuthenticateUser 用户认证（Admin模块不需要，但需要实现接口）
-func (l *AdminAuthLogicImpl) AuthenticateUser(ctx context.Context, username, password string) (*mysql.User, error) {
+// 用户名或客户端IP登录失败次数过多时直接返回appErrors.ErrAccountLocked，不校验密码
+// 已启用两步验证时校验code；角色强制启用两步验证但未启用时返回*twofactor.SetupRequiredError
+func (l *AdminAuthLogicImpl) AuthenticateUser(ctx context.Context, username, password, code, clientIP string) (*mysql.User, error) {
+	if err := l.throttle.Check(ctx, loginthrottle.ScopeUser, username, clientIP); err != nil {
+		return nil, err
+	}
+
+	user, err := l.authenticateUser(ctx, username, password, code)
+	if err != nil {
+		// 需要输入两步验证码或完成绑定、账户被锁定或需要重置密码时不计为失败
+		if !errors.Is(err, twofactor.ErrCodeRequired) && !errors.Is(err, twofactor.ErrSetupRequired) && !logic.IsAccountRestricted(err) {
+			l.throttle.Fail(ctx, loginthrottle.ScopeUser, username, clientIP)
+		}
+		return nil, err
+	}
+
+	l.throttle.Succeed(ctx, loginthrottle.ScopeUser, username)
+	return user, nil
+}
+
+// authenticateUser 校验用户名、密码和两步验证码
+func (l *AdminAuthLogicImpl) authenticateUser(ctx context.Context, username, password, code string) (*mysql.User, error) {
 	// 获取用户
 	user, err := l.userRepo.GetByUsername(ctx, username)
 	if err != nil {
@@ -502,6 +853,22 @@ func (l *AdminAuthLogicImpl) AuthenticateUser(ctx context.Context, username, pas
 		return nil, errors.New("invalid password")
 	}
 
+	// 管理员锁定或要求重置密码（密码正确后才提示，不向猜测密码的请求透露账户状态）
+	if user.IsLocked(clock.Now()) {
+		return nil, appErrors.AccountSuspendedError(user.LockedUntil.Format(time.RFC3339))
+	}
+	if user.PasswordResetRequired {
+		return nil, appErrors.ErrPasswordResetRequired
+	}
+
+	// 两步验证
+	if l.twoFactor != nil {
+		subject := twofactor.Subject{Type: mysql.TwoFactorSubjectUser, ID: user.ID}
+		if err := l.twoFactor.CheckLogin(ctx, subject, user.TwoFactorEnabled, string(user.Role), user.Username, code); err != nil {
+			return nil, err
+		}
+	}
+
 	// 更新登录信息
 	user.UpdateLoginInfo()
 	if err := l.userRepo.UpdateLastLogin(ctx, user.ID); err != nil {
@@ -512,41 +879,28 @@ func (l *AdminAuthLogicImpl) AuthenticateUser(ctx context.Context, username, pas
 	return user, nil
 }
 
-// RevokeToken 撤销token
+// RevokeToken 按jti撤销token，撤销后至过期前验证失败；未带jti的旧token无法单独撤销
 func (l *AdminAuthLogicImpl) RevokeToken(ctx context.Context, tokenString string) error {
-	// 解析token获取过期时间
 	claims, err := l.ValidateToken(tokenString)
 	if err != nil {
 		return fmt.Errorf("invalid token: %w", err)
 	}
-
-	// 计算剩余时间
-	expirationTime := time.Unix(claims.ExpiresAt.Unix(), 0)
-	remainingTime := time.Until(expirationTime)
-
-	if remainingTime <= 0 {
-		// token已过期，无需撤销
-		return nil
+	if claims.ID == "" {
+		return errors.New("token cannot be revoked individually")
 	}
-
-	// 将token加入黑名单
-	key := l.getRevokedTokenKey(tokenString)
-	return l.cacheRepo.Set(key, "revoked", remainingTime)
+	return l.revoked.Revoke(ctx, claims.Principal(), claims.ID, claims.ExpiresAt.Time)
 }
 
 // IsTokenRevoked 检查token是否被撤销
 func (l *AdminAuthLogicImpl) IsTokenRevoked(ctx context.Context, tokenString string) (bool, error) {
-	key := l.getRevokedTokenKey(tokenString)
-	exists, err := l.cacheRepo.Exists(key)
-	if err != nil {
-		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	claims := &logic.Claims{}
+	if _, err := l.keys.Parse(tokenString, claims, jwt.WithTimeFunc(clock.Now)); err != nil {
+		return false, fmt.Errorf("invalid token: %w", err)
 	}
-	return exists, nil
-}
-
-// getRevokedTokenKey 获取撤销token的缓存键
-func (l *AdminAuthLogicImpl) getRevokedTokenKey(tokenString string) string {
-	return "revoked_token:" + tokenString
+	if claims.ID == "" {
+		return false, nil
+	}
+	return l.revoked.IsRevoked(ctx, claims.ID)
 }
 
 // ValidatePasswordStrength 验证密码强度
@@ -0,0 +1,52 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"exchange/internal/models/mongodb"
+	"exchange/internal/pkg/adminaudit"
+)
+
+// AdminAuditLogic 管理员操作审计业务逻辑接口 - 查询管理后台写操作的审计记录
+type AdminAuditLogic interface {
+	// ListLogs 按条件分页查询审计记录，按时间倒序
+	ListLogs(ctx context.Context, filter adminaudit.Filter, page, pageSize int64) ([]*mongodb.AdminAuditLog, int64, error)
+
+	// GetLog 获取审计记录详情，不存在时返回adminaudit.ErrNotFound
+	GetLog(ctx context.Context, id string) (*mongodb.AdminAuditLog, error)
+}
+
+// AdminAuditLogicImpl 管理员操作审计业务逻辑实现
+type AdminAuditLogicImpl struct {
+	store *adminaudit.Store
+}
+
+// NewAdminAuditLogic 创建管理员操作审计业务逻辑实例
+func NewAdminAuditLogic(store *adminaudit.Store) *AdminAuditLogicImpl {
+	return &AdminAuditLogicImpl{
+		store: store,
+	}
+}
+
+// ListLogs 按条件分页查询审计记录
+func (l *AdminAuditLogicImpl) ListLogs(ctx context.Context, filter adminaudit.Filter, page, pageSize int64) ([]*mongodb.AdminAuditLog, int64, error) {
+	logs, total, err := l.store.Query(ctx, filter, page, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询操作审计记录失败: %w", err)
+	}
+	return logs, total, nil
+}
+
+// GetLog 获取审计记录详情
+func (l *AdminAuditLogicImpl) GetLog(ctx context.Context, id string) (*mongodb.AdminAuditLog, error) {
+	log, err := l.store.Get(ctx, id)
+	if errors.Is(err, adminaudit.ErrNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询操作审计记录失败: %w", err)
+	}
+	return log, nil
+}
@@ -0,0 +1,158 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/automation"
+	"exchange/internal/pkg/clock"
+	appErrors "exchange/internal/pkg/errors"
+	"exchange/internal/repository"
+)
+
+// AdminAutomationLogic 消息自动化规则业务逻辑接口
+type AdminAutomationLogic interface {
+	// ListRules 获取规则列表，trigger为空时返回所有规则
+	ListRules(ctx context.Context, trigger string) ([]*mysql.AutomationRule, error)
+
+	// GetRule 获取规则
+	GetRule(ctx context.Context, ruleID uint) (*mysql.AutomationRule, error)
+
+	// CreateRule 创建规则
+	CreateRule(ctx context.Context, adminID uint, name, trigger string, enabled bool, templates map[string]mysql.MessageTemplate) (*mysql.AutomationRule, error)
+
+	// UpdateRule 更新规则
+	UpdateRule(ctx context.Context, adminID, ruleID uint, name, trigger string, enabled bool, templates map[string]mysql.MessageTemplate) (*mysql.AutomationRule, error)
+
+	// DeleteRule 删除规则
+	DeleteRule(ctx context.Context, ruleID uint) error
+
+	// PreviewRule 预览规则渲染结果，userID为0时使用示例数据
+	PreviewRule(ctx context.Context, ruleID uint, language string, userID uint, data map[string]interface{}) (*automation.Message, error)
+}
+
+// AdminAutomationLogicImpl 消息自动化规则业务逻辑实现
+type AdminAutomationLogicImpl struct {
+	userRepo        repository.UserRepository           // 用户数据访问层
+	ruleRepo        repository.AutomationRuleRepository // 规则数据访问层
+	defaultLanguage string                              // 用户语言没有对应模板时使用的语言
+}
+
+// NewAdminAutomationLogic 创建消息自动化规则业务逻辑实例
+func NewAdminAutomationLogic(userRepo repository.UserRepository, ruleRepo repository.AutomationRuleRepository, defaultLanguage string) *AdminAutomationLogicImpl {
+	return &AdminAutomationLogicImpl{
+		userRepo:        userRepo,
+		ruleRepo:        ruleRepo,
+		defaultLanguage: defaultLanguage,
+	}
+}
+
+// ListRules 获取规则列表
+func (l *AdminAutomationLogicImpl) ListRules(ctx context.Context, trigger string) ([]*mysql.AutomationRule, error) {
+	rules, err := l.ruleRepo.List(ctx, mysql.AutomationTrigger(trigger))
+	if err != nil {
+		return nil, fmt.Errorf("查询规则列表失败: %w", err)
+	}
+	return rules, nil
+}
+
+// GetRule 获取规则
+func (l *AdminAutomationLogicImpl) GetRule(ctx context.Context, ruleID uint) (*mysql.AutomationRule, error) {
+	rule, err := l.ruleRepo.GetByID(ctx, ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("查询规则失败: %w", err)
+	}
+	if rule == nil {
+		return nil, errors.New("规则不存在")
+	}
+	return rule, nil
+}
+
+// CreateRule 创建规则
+func (l *AdminAutomationLogicImpl) CreateRule(ctx context.Context, adminID uint, name, trigger string, enabled bool, templates map[string]mysql.MessageTemplate) (*mysql.AutomationRule, error) {
+	rule := &mysql.AutomationRule{
+		Name:      name,
+		Trigger:   mysql.AutomationTrigger(trigger),
+		Enabled:   enabled,
+		CreatedBy: adminID,
+		UpdatedBy: adminID,
+	}
+	if err := rule.SetTemplates(templates); err != nil {
+		return nil, fmt.Errorf("模板编码失败: %w", err)
+	}
+
+	if err := l.ruleRepo.Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("创建规则失败: %w", err)
+	}
+	return rule, nil
+}
+
+// UpdateRule 更新规则
+func (l *AdminAutomationLogicImpl) UpdateRule(ctx context.Context, adminID, ruleID uint, name, trigger string, enabled bool, templates map[string]mysql.MessageTemplate) (*mysql.AutomationRule, error) {
+	rule, err := l.GetRule(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Name = name
+	rule.Trigger = mysql.AutomationTrigger(trigger)
+	rule.Enabled = enabled
+	rule.UpdatedBy = adminID
+	if err := rule.SetTemplates(templates); err != nil {
+		return nil, fmt.Errorf("模板编码失败: %w", err)
+	}
+
+	if err := l.ruleRepo.Update(ctx, rule); err != nil {
+		return nil, fmt.Errorf("更新规则失败: %w", err)
+	}
+	return rule, nil
+}
+
+// DeleteRule 删除规则
+func (l *AdminAutomationLogicImpl) DeleteRule(ctx context.Context, ruleID uint) error {
+	if err := l.ruleRepo.Delete(ctx, ruleID); err != nil {
+		return fmt.Errorf("删除规则失败: %w", err)
+	}
+	return nil
+}
+
+// PreviewRule 预览规则渲染结果
+// 指定用户时使用该用户的数据，否则使用示例数据；预览不发送消息
+func (l *AdminAutomationLogicImpl) PreviewRule(ctx context.Context, ruleID uint, language string, userID uint, data map[string]interface{}) (*automation.Message, error) {
+	rule, err := l.GetRule(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if language == "" {
+		language = l.defaultLanguage
+	}
+
+	templateData := automation.TemplateData{
+		UserID:     0,
+		Username:   "example_user",
+		Email:      "user@example.com",
+		Trigger:    string(rule.Trigger),
+		Language:   language,
+		Data:       data,
+		OccurredAt: clock.Now(),
+	}
+
+	if userID != 0 {
+		user, err := l.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return nil, appErrors.TranslateUserError(err, "查询用户失败", userID)
+		}
+		templateData.UserID = user.ID
+		templateData.Username = user.Username
+		templateData.Email = user.Email
+	}
+
+	message, err := automation.Render(rule, templateData, l.defaultLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("模板渲染失败: %w", err)
+	}
+	return message, nil
+}
@@ -0,0 +1,113 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/ipaccess"
+)
+
+// ErrIPAccessLockout 删除后操作者自己的IP将不在管理后台白名单中
+var ErrIPAccessLockout = errors.New("removing this entry would block your own ip from the admin console")
+
+// AdminIPAccessLogic IP访问控制业务逻辑接口 - 管理后台IP白名单和客户端IP黑名单
+type AdminIPAccessLogic interface {
+	// ListEntries 获取名单的所有条目（含配置文件中的条目）
+	ListEntries(ctx context.Context, list string) (*IPAccessList, error)
+
+	// AddEntry 添加条目，返回规范化的CIDR
+	AddEntry(ctx context.Context, list, cidr string) (string, error)
+
+	// RemoveEntry 删除管理接口添加的条目，返回规范化的CIDR；operatorIP为操作者的IP，白名单生效时不能删除操作者自己所在的唯一条目
+	RemoveEntry(ctx context.Context, list, cidr, operatorIP string) (string, error)
+}
+
+// IPAccessList 名单
+type IPAccessList struct {
+	List     string           `json:"list"`     // allow, deny
+	Enforced bool             `json:"enforced"` // 名单是否生效（ip_access.admin_allowlist、ip_access.enabled）
+	Entries  []ipaccess.Entry `json:"entries"`
+}
+
+// AdminIPAccessLogicImpl IP访问控制业务逻辑实现
+type AdminIPAccessLogicImpl struct {
+	store *ipaccess.Store
+	cfg   config.IPAccessConfig
+}
+
+// NewAdminIPAccessLogic 创建IP访问控制业务逻辑实例
+func NewAdminIPAccessLogic(store *ipaccess.Store, cfg config.IPAccessConfig) *AdminIPAccessLogicImpl {
+	return &AdminIPAccessLogicImpl{
+		store: store,
+		cfg:   cfg,
+	}
+}
+
+// ListEntries 获取名单的所有条目
+func (l *AdminIPAccessLogicImpl) ListEntries(ctx context.Context, list string) (*IPAccessList, error) {
+	entries, err := l.store.List(ctx, list)
+	if err != nil {
+		return nil, fmt.Errorf("查询IP名单失败: %w", err)
+	}
+
+	enforced := l.cfg.Enabled
+	if list == ipaccess.ListAllow {
+		enforced = l.cfg.AdminAllowlist
+	}
+	return &IPAccessList{List: list, Enforced: enforced, Entries: entries}, nil
+}
+
+// AddEntry 添加条目
+func (l *AdminIPAccessLogicImpl) AddEntry(ctx context.Context, list, cidr string) (string, error) {
+	entry, err := l.store.Add(ctx, list, cidr)
+	if err != nil {
+		return "", fmt.Errorf("添加IP名单条目失败: %w", err)
+	}
+	return entry, nil
+}
+
+// RemoveEntry 删除条目
+func (l *AdminIPAccessLogicImpl) RemoveEntry(ctx context.Context, list, cidr, operatorIP string) (string, error) {
+	if list == ipaccess.ListAllow && l.cfg.AdminAllowlist {
+		if err := l.checkLockout(ctx, cidr, operatorIP); err != nil {
+			return "", err
+		}
+	}
+
+	entry, err := l.store.Remove(ctx, list, cidr)
+	if err != nil {
+		return "", fmt.Errorf("删除IP名单条目失败: %w", err)
+	}
+	return entry, nil
+}
+
+// checkLockout 删除条目后操作者的IP是否仍在白名单中
+func (l *AdminIPAccessLogicImpl) checkLockout(ctx context.Context, cidr, operatorIP string) error {
+	removed, err := ipaccess.ParsePrefix(cidr)
+	if err != nil {
+		return err
+	}
+	operator, err := netip.ParseAddr(operatorIP)
+	if err != nil {
+		return nil
+	}
+	operator = operator.Unmap()
+	if !removed.Contains(operator) {
+		return nil
+	}
+
+	entries, err := l.store.List(ctx, ipaccess.ListAllow)
+	if err != nil {
+		return fmt.Errorf("查询IP名单失败: %w", err)
+	}
+	for _, entry := range entries {
+		prefix, err := ipaccess.ParsePrefix(entry.CIDR)
+		if err == nil && prefix != removed && prefix.Contains(operator) {
+			return nil
+		}
+	}
+	return ErrIPAccessLockout
+}
@@ -0,0 +1,76 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"exchange/internal/pkg/logger"
+	"exchange/internal/pkg/loglevel"
+)
+
+// AdminLogLevelLogic 日志级别业务逻辑接口 - 运行时调整全局及各模块日志级别
+type AdminLogLevelLogic interface {
+	// GetLogLevels 获取当前实例生效的日志级别和运行时设置的级别
+	GetLogLevels(ctx context.Context) (*LogLevels, error)
+
+	// SetLogLevel 设置日志级别，所有实例通过Redis订阅同步生效，module为root时设置全局级别
+	SetLogLevel(ctx context.Context, module, level string) error
+
+	// ResetLogLevel 清除运行时设置的级别，恢复为配置文件中的级别
+	ResetLogLevel(ctx context.Context, module string) error
+}
+
+// LogLevels 日志级别
+type LogLevels struct {
+	Root      string            `json:"root"`      // 当前实例的全局级别
+	Modules   map[string]string `json:"modules"`   // 当前实例各模块生效的级别（配置文件+运行时设置）
+	Overrides map[string]string `json:"overrides"` // 运行时设置的级别（所有实例共享）
+}
+
+// AdminLogLevelLogicImpl 日志级别业务逻辑实现
+type AdminLogLevelLogicImpl struct {
+	levelSync *loglevel.Sync // 日志级别同步器
+}
+
+// NewAdminLogLevelLogic 创建日志级别业务逻辑实例
+func NewAdminLogLevelLogic(levelSync *loglevel.Sync) *AdminLogLevelLogicImpl {
+	return &AdminLogLevelLogicImpl{
+		levelSync: levelSync,
+	}
+}
+
+// GetLogLevels 获取当前实例生效的日志级别和运行时设置的级别
+func (l *AdminLogLevelLogicImpl) GetLogLevels(ctx context.Context) (*LogLevels, error) {
+	overrides, err := l.levelSync.Overrides(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("查询日志级别失败: %w", err)
+	}
+
+	levels := &LogLevels{
+		Root:      strings.ToLower(logger.GetLevel().String()),
+		Modules:   make(map[string]string),
+		Overrides: overrides,
+	}
+	for module, level := range logger.ModuleLevels() {
+		levels.Modules[module] = strings.ToLower(level.String())
+	}
+
+	return levels, nil
+}
+
+// SetLogLevel 设置日志级别
+func (l *AdminLogLevelLogicImpl) SetLogLevel(ctx context.Context, module, level string) error {
+	if err := l.levelSync.Set(ctx, module, level); err != nil {
+		return fmt.Errorf("设置日志级别失败: %w", err)
+	}
+	return nil
+}
+
+// ResetLogLevel 清除运行时设置的级别
+func (l *AdminLogLevelLogicImpl) ResetLogLevel(ctx context.Context, module string) error {
+	if err := l.levelSync.Reset(ctx, module); err != nil {
+		return fmt.Errorf("重置日志级别失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/maintenance"
+)
+
+// AdminMaintenanceLogic 维护模式业务逻辑接口 - 开启和关闭集群维护模式
+type AdminMaintenanceLogic interface {
+	// GetStatus 获取当前维护状态
+	GetStatus(ctx context.Context) (*maintenance.State, error)
+
+	// Enable 开启维护模式，retryAfter为0时使用配置的默认值；已开启时更新说明和重试时间
+	Enable(ctx context.Context, adminID uint, notice string, retryAfter int) (*maintenance.State, error)
+
+	// Disable 关闭维护模式，返回关闭前的状态
+	Disable(ctx context.Context) (*maintenance.State, error)
+}
+
+// AdminMaintenanceLogicImpl 维护模式业务逻辑实现
+type AdminMaintenanceLogicImpl struct {
+	store *maintenance.Store
+	cfg   config.MaintenanceConfig
+}
+
+// NewAdminMaintenanceLogic 创建维护模式业务逻辑实例
+func NewAdminMaintenanceLogic(store *maintenance.Store, cfg config.MaintenanceConfig) *AdminMaintenanceLogicImpl {
+	return &AdminMaintenanceLogicImpl{
+		store: store,
+		cfg:   cfg,
+	}
+}
+
+// GetStatus 获取当前维护状态
+func (l *AdminMaintenanceLogicImpl) GetStatus(ctx context.Context) (*maintenance.State, error) {
+	state, err := l.store.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("查询维护状态失败: %w", err)
+	}
+	return &state, nil
+}
+
+// Enable 开启维护模式
+func (l *AdminMaintenanceLogicImpl) Enable(ctx context.Context, adminID uint, notice string, retryAfter int) (*maintenance.State, error) {
+	if retryAfter <= 0 {
+		retryAfter = l.cfg.RetryAfter
+	}
+	state, err := l.store.Enable(ctx, maintenance.State{
+		Notice:     notice,
+		RetryAfter: retryAfter,
+		AdminID:    adminID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("开启维护模式失败: %w", err)
+	}
+	return &state, nil
+}
+
+// Disable 关闭维护模式
+func (l *AdminMaintenanceLogicImpl) Disable(ctx context.Context) (*maintenance.State, error) {
+	state, err := l.store.Disable(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("关闭维护模式失败: %w", err)
+	}
+	return &state, nil
+}
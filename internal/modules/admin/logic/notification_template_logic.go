@@ -0,0 +1,232 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/notification"
+	"exchange/internal/repository"
+)
+
+// SourceDraft 预览未保存的模板内容
+const SourceDraft = "draft"
+
+// ErrNotificationTemplateNotFound 通知模板版本不存在
+var ErrNotificationTemplateNotFound = errors.New("通知模板版本不存在")
+
+// AdminNotificationTemplateLogic 通知模板业务逻辑接口 - 管理员按事件、渠道、语言编辑通知模板
+type AdminNotificationTemplateLogic interface {
+	// ListEvents 获取支持模板的事件、可用变量及内置默认模板的语言
+	ListEvents() []*NotificationTemplateEvent
+
+	// ListTemplates 获取管理员启用的模板，event、channel为空时不过滤
+	ListTemplates(ctx context.Context, event, channel string) ([]*mysql.NotificationTemplate, error)
+
+	// GetTemplate 获取事件、渠道、语言下当前生效的模板和所有历史版本
+	GetTemplate(ctx context.Context, event, channel, locale string) (*NotificationTemplateDetail, error)
+
+	// SaveTemplate 保存为新版本并立即启用
+	SaveTemplate(ctx context.Context, adminID uint, event, channel, locale, subject, body, comment string) (*mysql.NotificationTemplate, error)
+
+	// ActivateVersion 启用指定的历史版本（回滚）
+	ActivateVersion(ctx context.Context, event, channel, locale string, version int) (*mysql.NotificationTemplate, error)
+
+	// ResetTemplate 取消启用管理员模板，恢复使用内置默认模板
+	ResetTemplate(ctx context.Context, event, channel, locale string) error
+
+	// PreviewTemplate 预览渲染结果，draft为nil时预览当前生效的模板；data覆盖示例数据中的同名变量
+	PreviewTemplate(ctx context.Context, event, channel, locale string, draft *notification.Template, data map[string]interface{}) (*notification.RenderedMessage, error)
+}
+
+// NotificationTemplateEvent 支持模板的事件
+type NotificationTemplateEvent struct {
+	*notification.EventSchema
+	DefaultLocales map[string][]string `json:"default_locales"` // 各渠道内置默认模板的语言
+}
+
+// NotificationTemplateDetail 事件、渠道、语言下的模板详情
+type NotificationTemplateDetail struct {
+	Event    string                        `json:"event"`
+	Channel  string                        `json:"channel"`
+	Locale   string                        `json:"locale"`
+	Source   string                        `json:"source"`            // 当前生效的模板来源：custom/default，都不存在时为空（发送时按语言回退）
+	Active   *mysql.NotificationTemplate   `json:"active,omitempty"`  // 启用的管理员模板
+	Default  *notification.Template        `json:"default,omitempty"` // 该语言的内置默认模板
+	Versions []*mysql.NotificationTemplate `json:"versions"`          // 所有版本，按版本号倒序
+}
+
+// AdminNotificationTemplateLogicImpl 通知模板业务逻辑实现
+type AdminNotificationTemplateLogicImpl struct {
+	templateRepo repository.NotificationTemplateRepository // 通知模板数据访问层
+	renderer     *notification.Renderer                    // 与发送通知共用的渲染器，预览结果与实际发送一致
+}
+
+// NewAdminNotificationTemplateLogic 创建通知模板业务逻辑实例
+func NewAdminNotificationTemplateLogic(templateRepo repository.NotificationTemplateRepository, renderer *notification.Renderer) *AdminNotificationTemplateLogicImpl {
+	return &AdminNotificationTemplateLogicImpl{
+		templateRepo: templateRepo,
+		renderer:     renderer,
+	}
+}
+
+// ListEvents 获取支持模板的事件
+func (l *AdminNotificationTemplateLogicImpl) ListEvents() []*NotificationTemplateEvent {
+	schemas := notification.Schemas()
+	events := make([]*NotificationTemplateEvent, 0, len(schemas))
+	for _, schema := range schemas {
+		locales := make(map[string][]string, len(schema.Channels))
+		for _, channel := range schema.Channels {
+			list := notification.DefaultLocales(schema.Event, channel)
+			sort.Strings(list)
+			locales[channel] = list
+		}
+		events = append(events, &NotificationTemplateEvent{EventSchema: schema, DefaultLocales: locales})
+	}
+	return events
+}
+
+// ListTemplates 获取管理员启用的模板
+func (l *AdminNotificationTemplateLogicImpl) ListTemplates(ctx context.Context, event, channel string) ([]*mysql.NotificationTemplate, error) {
+	templates, err := l.templateRepo.ListActive(ctx, event, channel)
+	if err != nil {
+		return nil, fmt.Errorf("查询通知模板失败: %w", err)
+	}
+	return templates, nil
+}
+
+// GetTemplate 获取模板详情
+func (l *AdminNotificationTemplateLogicImpl) GetTemplate(ctx context.Context, event, channel, locale string) (*NotificationTemplateDetail, error) {
+	if err := checkTemplateTarget(event, channel); err != nil {
+		return nil, err
+	}
+
+	versions, err := l.templateRepo.ListVersions(ctx, event, channel, locale)
+	if err != nil {
+		return nil, fmt.Errorf("查询通知模板版本失败: %w", err)
+	}
+
+	detail := &NotificationTemplateDetail{
+		Event:    event,
+		Channel:  channel,
+		Locale:   locale,
+		Versions: versions,
+	}
+	if tpl, ok := notification.DefaultTemplate(event, channel, locale); ok {
+		detail.Default = &tpl
+		detail.Source = notification.SourceDefault
+	}
+	for _, version := range versions {
+		if version.Active {
+			detail.Active = version
+			detail.Source = notification.SourceCustom
+			break
+		}
+	}
+	return detail, nil
+}
+
+// SaveTemplate 保存为新版本并立即启用
+// 模板只能引用事件变量定义中的变量，避免发送时渲染出空值
+func (l *AdminNotificationTemplateLogicImpl) SaveTemplate(ctx context.Context, adminID uint, event, channel, locale, subject, body, comment string) (*mysql.NotificationTemplate, error) {
+	if err := notification.ValidateTemplate(event, channel, subject, body); err != nil {
+		return nil, err
+	}
+
+	tpl := &mysql.NotificationTemplate{
+		Event:     event,
+		Channel:   channel,
+		Locale:    locale,
+		Subject:   subject,
+		Body:      body,
+		Comment:   comment,
+		CreatedBy: adminID,
+	}
+	if err := l.templateRepo.CreateVersion(ctx, tpl); err != nil {
+		return nil, fmt.Errorf("保存通知模板失败: %w", err)
+	}
+	return tpl, nil
+}
+
+// ActivateVersion 启用指定的历史版本
+// 变量定义可能在版本保存后发生变化，启用前重新校验
+func (l *AdminNotificationTemplateLogicImpl) ActivateVersion(ctx context.Context, event, channel, locale string, version int) (*mysql.NotificationTemplate, error) {
+	tpl, err := l.templateRepo.GetVersion(ctx, event, channel, locale, version)
+	if err != nil {
+		return nil, fmt.Errorf("查询通知模板失败: %w", err)
+	}
+	if tpl == nil {
+		return nil, ErrNotificationTemplateNotFound
+	}
+
+	if err := notification.ValidateTemplate(event, channel, tpl.Subject, tpl.Body); err != nil {
+		return nil, err
+	}
+
+	if err := l.templateRepo.Activate(ctx, tpl); err != nil {
+		return nil, fmt.Errorf("启用通知模板失败: %w", err)
+	}
+	return tpl, nil
+}
+
+// ResetTemplate 恢复使用内置默认模板
+func (l *AdminNotificationTemplateLogicImpl) ResetTemplate(ctx context.Context, event, channel, locale string) error {
+	if err := checkTemplateTarget(event, channel); err != nil {
+		return err
+	}
+
+	if err := l.templateRepo.Deactivate(ctx, event, channel, locale); err != nil {
+		return fmt.Errorf("恢复默认通知模板失败: %w", err)
+	}
+	return nil
+}
+
+// PreviewTemplate 预览渲染结果，预览不发送通知
+func (l *AdminNotificationTemplateLogicImpl) PreviewTemplate(ctx context.Context, event, channel, locale string, draft *notification.Template, data map[string]interface{}) (*notification.RenderedMessage, error) {
+	if err := checkTemplateTarget(event, channel); err != nil {
+		return nil, err
+	}
+
+	schema, _ := notification.LookupSchema(event)
+	templateData := schema.SampleData(locale)
+	for key, value := range data {
+		templateData.Data[key] = value
+	}
+
+	if draft == nil {
+		message, err := l.renderer.Render(ctx, event, channel, locale, templateData)
+		if err != nil {
+			return nil, fmt.Errorf("模板渲染失败: %w", err)
+		}
+		return message, nil
+	}
+
+	if err := notification.ValidateTemplate(event, channel, draft.Subject, draft.Body); err != nil {
+		return nil, err
+	}
+	subject, body, err := notification.RenderTemplate(*draft, templateData)
+	if err != nil {
+		return nil, fmt.Errorf("模板渲染失败: %w", err)
+	}
+	return &notification.RenderedMessage{
+		Channel: channel,
+		Locale:  locale,
+		Source:  SourceDraft,
+		Subject: subject,
+		Body:    body,
+	}, nil
+}
+
+// checkTemplateTarget 检查事件和渠道是否支持模板
+func checkTemplateTarget(event, channel string) error {
+	schema, ok := notification.LookupSchema(event)
+	if !ok {
+		return fmt.Errorf("unsupported event: %s", event)
+	}
+	if !schema.SupportsChannel(channel) {
+		return fmt.Errorf("event %s does not support channel %s", event, channel)
+	}
+	return nil
+}
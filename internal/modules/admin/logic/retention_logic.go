@@ -0,0 +1,272 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	appErrors "exchange/internal/pkg/errors"
+	"exchange/internal/repository"
+)
+
+// 合规报告最多返回的执行明细条数
+const complianceReportRunLimit = 500
+
+// 会话归档策略错误
+var (
+	ErrArchiveOverrideNotFound = errors.New("会话归档策略不存在")
+	ErrInvalidArchiveOverride  = errors.New("归档天数必须小于消息保留天数")
+)
+
+// AdminRetentionLogic 数据保留业务逻辑接口 - 合规报告和法律保全管理
+type AdminRetentionLogic interface {
+	// GetComplianceReport 汇总最近days天内各类数据的清理情况，category为空时统计所有类别
+	GetComplianceReport(ctx context.Context, days int, category string) (*ComplianceReport, error)
+
+	// GetLegalHolds 获取生效中的法律保全
+	GetLegalHolds(ctx context.Context) ([]*mysql.LegalHold, error)
+
+	// PlaceLegalHold 对用户设置法律保全，保全期间其数据不参与保留期清理
+	PlaceLegalHold(ctx context.Context, adminID, userID uint, reason string) (*mysql.LegalHold, error)
+
+	// ReleaseLegalHold 解除用户的法律保全
+	ReleaseLegalHold(ctx context.Context, adminID, userID uint) error
+
+	// GetArchiveOverrides 获取所有会话的消息归档策略
+	GetArchiveOverrides(ctx context.Context) ([]*mysql.MessageArchiveOverride, error)
+
+	// SetArchiveOverride 设置会话的消息归档策略，覆盖retention.archive.days
+	SetArchiveOverride(ctx context.Context, adminID uint, input ArchiveOverrideInput) (*mysql.MessageArchiveOverride, error)
+
+	// DeleteArchiveOverride 删除会话的消息归档策略，恢复按默认天数归档
+	DeleteArchiveOverride(ctx context.Context, id uint) (*mysql.MessageArchiveOverride, error)
+}
+
+// ArchiveOverrideInput 会话归档策略参数，群聊使用GroupID，单聊使用两个用户ID
+type ArchiveOverrideInput struct {
+	ConversationType string
+	GroupID          string
+	UserIDs          []uint
+	Days             int // 0表示不归档
+	Reason           string
+}
+
+// CategoryCompliance 单个数据类别的合规汇总
+type CategoryCompliance struct {
+	Category       string                 `json:"category"`
+	Policy         config.RetentionPolicy `json:"policy"`          // 当前保留策略
+	Runs           int                    `json:"runs"`            // 统计期内正式执行次数
+	DryRuns        int                    `json:"dry_runs"`        // 统计期内演练次数
+	Failures       int                    `json:"failures"`        // 统计期内失败次数
+	PurgedTotal    int64                  `json:"purged_total"`    // 统计期内清理总数
+	LastPurgedAt   int64                  `json:"last_purged_at"`  // 最近一次正式执行时间（纳秒时间戳）
+	LastCutoff     int64                  `json:"last_cutoff"`     // 最近一次正式执行的截止时间（纳秒时间戳）
+	LastDryRunAt   int64                  `json:"last_dry_run_at"` // 最近一次演练时间（纳秒时间戳）
+	PendingMatched int64                  `json:"pending_matched"` // 最近一次演练统计到的待清理数量
+}
+
+// ComplianceReport 合规报告
+type ComplianceReport struct {
+	Since       int64                 `json:"since"`        // 统计起始时间（纳秒时间戳）
+	ActiveHolds int                   `json:"active_holds"` // 生效中的法律保全数量
+	Categories  []CategoryCompliance  `json:"categories"`
+	Runs        []*mysql.RetentionRun `json:"runs"` // 执行明细，按时间倒序
+}
+
+// AdminRetentionLogicImpl 数据保留业务逻辑实现
+type AdminRetentionLogicImpl struct {
+	config        *config.Config
+	userRepo      repository.UserRepository      // 用户数据访问层
+	retentionRepo repository.RetentionRepository // 数据保留数据访问层
+}
+
+// NewAdminRetentionLogic 创建数据保留业务逻辑实例
+func NewAdminRetentionLogic(cfg *config.Config, userRepo repository.UserRepository, retentionRepo repository.RetentionRepository) *AdminRetentionLogicImpl {
+	return &AdminRetentionLogicImpl{
+		config:        cfg,
+		userRepo:      userRepo,
+		retentionRepo: retentionRepo,
+	}
+}
+
+// GetComplianceReport 汇总最近days天内各类数据的清理情况
+func (l *AdminRetentionLogicImpl) GetComplianceReport(ctx context.Context, days int, category string) (*ComplianceReport, error) {
+	since := clock.Now().AddDate(0, 0, -days).UnixNano()
+
+	runs, err := l.retentionRepo.GetRuns(ctx, category, since, complianceReportRunLimit)
+	if err != nil {
+		return nil, fmt.Errorf("查询清理执行记录失败: %w", err)
+	}
+
+	holds, err := l.retentionRepo.GetActiveHolds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("查询法律保全失败: %w", err)
+	}
+
+	// 按类别汇总，执行记录按时间倒序，每类第一条即最近一次
+	summaries := make(map[string]*CategoryCompliance)
+	for name, policy := range l.config.Retention.Policies() {
+		if category != "" && name != category {
+			continue
+		}
+		summaries[name] = &CategoryCompliance{Category: name, Policy: policy}
+	}
+
+	for _, run := range runs {
+		summary, ok := summaries[run.Category]
+		if !ok {
+			continue
+		}
+
+		if run.Status == mysql.RetentionRunStatusFailed {
+			summary.Failures++
+		}
+
+		if run.DryRun {
+			summary.DryRuns++
+			if summary.LastDryRunAt == 0 {
+				summary.LastDryRunAt = run.StartedAt
+				summary.PendingMatched = run.Matched
+			}
+			continue
+		}
+
+		summary.Runs++
+		summary.PurgedTotal += run.Purged
+		if summary.LastPurgedAt == 0 && run.Status == mysql.RetentionRunStatusSuccess {
+			summary.LastPurgedAt = run.StartedAt
+			summary.LastCutoff = run.Cutoff
+		}
+	}
+
+	report := &ComplianceReport{
+		Since:       since,
+		ActiveHolds: len(holds),
+		Runs:        runs,
+	}
+	for _, summary := range summaries {
+		report.Categories = append(report.Categories, *summary)
+	}
+	sort.Slice(report.Categories, func(i, j int) bool {
+		return report.Categories[i].Category < report.Categories[j].Category
+	})
+
+	return report, nil
+}
+
+// GetLegalHolds 获取生效中的法律保全
+func (l *AdminRetentionLogicImpl) GetLegalHolds(ctx context.Context) ([]*mysql.LegalHold, error) {
+	holds, err := l.retentionRepo.GetActiveHolds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("查询法律保全失败: %w", err)
+	}
+	return holds, nil
+}
+
+// PlaceLegalHold 对用户设置法律保全
+// 业务规则：
+// 1. 用户必须存在
+// 2. 同一用户可以有多条保全（不同案件），解除时一并解除
+func (l *AdminRetentionLogicImpl) PlaceLegalHold(ctx context.Context, adminID, userID uint, reason string) (*mysql.LegalHold, error) {
+	// 第一步：验证用户是否存在
+	if _, err := l.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, appErrors.TranslateUserError(err, "查询用户失败", userID)
+	}
+
+	// 第二步：创建保全记录
+	hold := &mysql.LegalHold{
+		UserID:    userID,
+		Reason:    reason,
+		CreatedBy: adminID,
+	}
+	if err := l.retentionRepo.CreateHold(ctx, hold); err != nil {
+		return nil, fmt.Errorf("设置法律保全失败: %w", err)
+	}
+
+	return hold, nil
+}
+
+// ReleaseLegalHold 解除用户的法律保全
+func (l *AdminRetentionLogicImpl) ReleaseLegalHold(ctx context.Context, adminID, userID uint) error {
+	released, err := l.retentionRepo.ReleaseHolds(ctx, userID, adminID, clock.Now().UnixNano())
+	if err != nil {
+		return fmt.Errorf("解除法律保全失败: %w", err)
+	}
+	if released == 0 {
+		return errors.New("用户没有生效中的法律保全")
+	}
+	return nil
+}
+
+// GetArchiveOverrides 获取所有会话的消息归档策略
+func (l *AdminRetentionLogicImpl) GetArchiveOverrides(ctx context.Context) ([]*mysql.MessageArchiveOverride, error) {
+	overrides, err := l.retentionRepo.ListArchiveOverrides(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("查询会话归档策略失败: %w", err)
+	}
+	return overrides, nil
+}
+
+// SetArchiveOverride 设置会话的消息归档策略
+// 业务规则：
+// 1. 单聊的两个用户必须存在；群聊不检查群是否存在，解散的群的消息仍在热集合中
+// 2. 消息保留期清理启用时，归档天数必须小于保留天数，否则消息在归档前就被清理
+// 3. 同一会话只有一条策略，重复设置时覆盖
+func (l *AdminRetentionLogicImpl) SetArchiveOverride(ctx context.Context, adminID uint, input ArchiveOverrideInput) (*mysql.MessageArchiveOverride, error) {
+	// 第一步：确定会话
+	override := &mysql.MessageArchiveOverride{
+		ConversationType: input.ConversationType,
+		Days:             input.Days,
+		Reason:           input.Reason,
+		CreatedBy:        adminID,
+	}
+	if input.ConversationType == mysql.ArchiveConversationGroup {
+		override.ConversationID = input.GroupID
+	} else {
+		for _, userID := range input.UserIDs {
+			if _, err := l.userRepo.GetByID(ctx, userID); err != nil {
+				return nil, appErrors.TranslateUserError(err, "查询用户失败", userID)
+			}
+		}
+		override.ConversationID = mysql.DirectArchiveConversationID(input.UserIDs[0], input.UserIDs[1])
+	}
+
+	// 第二步：检查与消息保留期的关系
+	messages := l.config.Retention.Messages
+	if input.Days > 0 && messages.Enabled && input.Days >= messages.Days {
+		return nil, ErrInvalidArchiveOverride
+	}
+
+	// 第三步：保存策略
+	if err := l.retentionRepo.SetArchiveOverride(ctx, override); err != nil {
+		return nil, fmt.Errorf("设置会话归档策略失败: %w", err)
+	}
+
+	return override, nil
+}
+
+// DeleteArchiveOverride 删除会话的消息归档策略，返回删除前的策略用于审计
+func (l *AdminRetentionLogicImpl) DeleteArchiveOverride(ctx context.Context, id uint) (*mysql.MessageArchiveOverride, error) {
+	override, err := l.retentionRepo.GetArchiveOverride(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrArchiveOverrideNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询会话归档策略失败: %w", err)
+	}
+
+	if err := l.retentionRepo.DeleteArchiveOverride(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrArchiveOverrideNotFound
+		}
+		return nil, fmt.Errorf("删除会话归档策略失败: %w", err)
+	}
+
+	return override, nil
+}
@@ -0,0 +1,119 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/rbac"
+	"exchange/internal/repository"
+)
+
+// 角色管理错误
+var (
+	ErrRoleNotFound  = errors.New("角色不存在")
+	ErrRoleInUse     = errors.New("角色仍有管理员使用")
+	ErrRoleProtected = errors.New("super角色的权限不能修改")
+)
+
+// AdminRoleLogic 角色管理业务逻辑接口 - 查看角色和修改角色拥有的权限
+type AdminRoleLogic interface {
+	// ListRoles 获取所有角色及其生效的权限
+	ListRoles(ctx context.Context) ([]*rbac.Role, error)
+
+	// ListPermissions 获取可分配的权限
+	ListPermissions(ctx context.Context) []rbac.Permission
+
+	// SaveRole 创建角色或替换角色的全部权限，所有实例在rbac.cache_seconds内生效
+	SaveRole(ctx context.Context, adminID uint, name, description string, permissions []string) (*rbac.Role, error)
+
+	// DeleteRole 删除角色，内置角色删除后恢复默认权限
+	DeleteRole(ctx context.Context, name string) error
+}
+
+// AdminRoleLogicImpl 角色管理业务逻辑实现
+type AdminRoleLogicImpl struct {
+	roleRepo    repository.RoleRepository
+	adminRepo   repository.AdminRepository
+	permissions *rbac.PermissionChecker // 与认证中间件共用，修改后立即重新加载
+}
+
+// NewAdminRoleLogic 创建角色管理业务逻辑实例
+func NewAdminRoleLogic(roleRepo repository.RoleRepository, adminRepo repository.AdminRepository, permissions *rbac.PermissionChecker) *AdminRoleLogicImpl {
+	return &AdminRoleLogicImpl{
+		roleRepo:    roleRepo,
+		adminRepo:   adminRepo,
+		permissions: permissions,
+	}
+}
+
+// ListRoles 获取所有角色
+func (l *AdminRoleLogicImpl) ListRoles(ctx context.Context) ([]*rbac.Role, error) {
+	roles, err := l.permissions.Roles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("查询角色失败: %w", err)
+	}
+	return roles, nil
+}
+
+// ListPermissions 获取可分配的权限
+func (l *AdminRoleLogicImpl) ListPermissions(ctx context.Context) []rbac.Permission {
+	return rbac.Permissions
+}
+
+// SaveRole 保存角色，super角色始终拥有所有权限，避免误操作后无人可以管理角色
+func (l *AdminRoleLogicImpl) SaveRole(ctx context.Context, adminID uint, name, description string, permissions []string) (*rbac.Role, error) {
+	if name == rbac.RoleSuper {
+		return nil, ErrRoleProtected
+	}
+
+	role := &mysql.Role{
+		Name:        name,
+		Description: description,
+		UpdatedBy:   adminID,
+	}
+	if err := l.roleRepo.SaveRole(ctx, role, permissions); err != nil {
+		return nil, fmt.Errorf("保存角色失败: %w", err)
+	}
+	l.permissions.Invalidate(ctx)
+
+	return &rbac.Role{
+		Name:        name,
+		Description: description,
+		Permissions: permissions,
+		Builtin:     rbac.IsBuiltin(name),
+		Customized:  true,
+	}, nil
+}
+
+// DeleteRole 删除角色，自定义角色仍有管理员使用时不能删除
+func (l *AdminRoleLogicImpl) DeleteRole(ctx context.Context, name string) error {
+	if name == rbac.RoleSuper {
+		return ErrRoleProtected
+	}
+
+	role, err := l.roleRepo.GetRole(ctx, name)
+	if err != nil {
+		return fmt.Errorf("查询角色失败: %w", err)
+	}
+	if role == nil {
+		return ErrRoleNotFound
+	}
+
+	if !rbac.IsBuiltin(name) {
+		admins, err := l.adminRepo.GetAdminsByRole(ctx, mysql.AdminRole(name), 1, 0)
+		if err != nil {
+			return fmt.Errorf("查询角色的管理员失败: %w", err)
+		}
+		if len(admins) > 0 {
+			return ErrRoleInUse
+		}
+	}
+
+	if err := l.roleRepo.DeleteRole(ctx, name); err != nil {
+		return fmt.Errorf("删除角色失败: %w", err)
+	}
+	l.permissions.Invalidate(ctx)
+	return nil
+}
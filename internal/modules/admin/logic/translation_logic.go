@@ -0,0 +1,150 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"exchange/internal/models/mongodb"
+	"exchange/internal/pkg/i18n"
+	"exchange/internal/pkg/translation"
+)
+
+// 翻译管理错误
+var (
+	ErrTranslationOverrideNotFound = errors.New("翻译覆盖不存在")
+	ErrUnknownTranslationKey       = errors.New("消息键不存在")
+)
+
+// AdminTranslationLogic 翻译管理业务逻辑接口 - 查看各语言的翻译和缺失的消息，编辑覆盖翻译文件的消息
+type AdminTranslationLogic interface {
+	// GetCoverage 获取各支持语言的翻译覆盖率
+	GetCoverage(ctx context.Context) []*TranslationCoverage
+
+	// ListTranslations 获取语言当前生效的消息和缺失的消息键，language为空时使用默认语言
+	ListTranslations(ctx context.Context, language string) (*TranslationList, error)
+
+	// ListOverrides 获取管理员编辑的翻译覆盖，language为空时获取所有语言
+	ListOverrides(ctx context.Context, language string) ([]*mongodb.TranslationOverride, error)
+
+	// SaveOverride 保存翻译覆盖，所有实例通过Redis通知重新加载后生效
+	SaveOverride(ctx context.Context, adminID uint, language, key string, forms map[string]string) (*mongodb.TranslationOverride, error)
+
+	// DeleteOverride 删除翻译覆盖，恢复为翻译文件中的消息
+	DeleteOverride(ctx context.Context, language, key string) error
+}
+
+// TranslationCoverage 语言的翻译覆盖率
+type TranslationCoverage struct {
+	Language   string `json:"language"`
+	Total      int    `json:"total"`      // 所有语言中出现过的消息键数量
+	Translated int    `json:"translated"` // 该语言已翻译的数量
+	Missing    int    `json:"missing"`    // 该语言缺少的数量
+}
+
+// TranslationList 语言的翻译
+type TranslationList struct {
+	Language string         `json:"language"`
+	Messages []i18n.Message `json:"messages"` // 当前生效的消息，按消息键排序
+	Missing  []string       `json:"missing"`  // 缺少翻译的消息键，翻译时回退到默认语言
+}
+
+// AdminTranslationLogicImpl 翻译管理业务逻辑实现
+type AdminTranslationLogicImpl struct {
+	manager         *i18n.I18nManager
+	translationSync *translation.Sync // 翻译覆盖同步器
+}
+
+// NewAdminTranslationLogic 创建翻译管理业务逻辑实例
+func NewAdminTranslationLogic(manager *i18n.I18nManager, translationSync *translation.Sync) *AdminTranslationLogicImpl {
+	return &AdminTranslationLogicImpl{
+		manager:         manager,
+		translationSync: translationSync,
+	}
+}
+
+// GetCoverage 获取各支持语言的翻译覆盖率
+func (l *AdminTranslationLogicImpl) GetCoverage(ctx context.Context) []*TranslationCoverage {
+	total := len(l.manager.Keys())
+	languages := l.manager.GetSupportedLanguages()
+
+	coverage := make([]*TranslationCoverage, 0, len(languages))
+	for _, language := range languages {
+		missing, err := l.manager.MissingKeys(language)
+		if err != nil {
+			continue
+		}
+		coverage = append(coverage, &TranslationCoverage{
+			Language:   language,
+			Total:      total,
+			Translated: total - len(missing),
+			Missing:    len(missing),
+		})
+	}
+	return coverage
+}
+
+// ListTranslations 获取语言当前生效的消息和缺失的消息键
+func (l *AdminTranslationLogicImpl) ListTranslations(ctx context.Context, language string) (*TranslationList, error) {
+	if language == "" {
+		language = l.manager.GetDefaultLanguage()
+	}
+
+	messages, err := l.manager.Messages(language)
+	if err != nil {
+		return nil, err
+	}
+	missing, err := l.manager.MissingKeys(language)
+	if err != nil {
+		return nil, err
+	}
+	if missing == nil {
+		missing = []string{}
+	}
+
+	return &TranslationList{
+		Language: language,
+		Messages: messages,
+		Missing:  missing,
+	}, nil
+}
+
+// ListOverrides 获取管理员编辑的翻译覆盖
+func (l *AdminTranslationLogicImpl) ListOverrides(ctx context.Context, language string) ([]*mongodb.TranslationOverride, error) {
+	overrides, err := l.translationSync.Overrides(ctx, language)
+	if err != nil {
+		return nil, fmt.Errorf("查询翻译覆盖失败: %w", err)
+	}
+	return overrides, nil
+}
+
+// SaveOverride 保存翻译覆盖，只能覆盖代码中使用的消息键（出现在任一语言的翻译中）
+func (l *AdminTranslationLogicImpl) SaveOverride(ctx context.Context, adminID uint, language, key string, forms map[string]string) (*mongodb.TranslationOverride, error) {
+	if _, found := slices.BinarySearch(l.manager.Keys(), key); !found {
+		return nil, ErrUnknownTranslationKey
+	}
+
+	override := &mongodb.TranslationOverride{
+		Language:  language,
+		Key:       key,
+		Forms:     forms,
+		UpdatedBy: adminID,
+	}
+	if err := l.translationSync.Save(ctx, override); err != nil {
+		return nil, fmt.Errorf("保存翻译覆盖失败: %w", err)
+	}
+	return override, nil
+}
+
+// DeleteOverride 删除翻译覆盖
+func (l *AdminTranslationLogicImpl) DeleteOverride(ctx context.Context, language, key string) error {
+	err := l.translationSync.Delete(ctx, language, key)
+	if errors.Is(err, translation.ErrOverrideNotFound) {
+		return ErrTranslationOverrideNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("删除翻译覆盖失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/twofactor"
+	"exchange/internal/repository"
+)
+
+// AdminTwoFactorLogic 管理员两步验证业务逻辑接口
+type AdminTwoFactorLogic interface {
+	// GetStatus 两步验证状态
+	GetStatus(ctx context.Context, adminID uint) (*twofactor.Status, error)
+
+	// BeginSetup 开始绑定，返回密钥和验证器应用的绑定地址
+	BeginSetup(ctx context.Context, adminID uint) (*twofactor.Enrollment, error)
+
+	// Enable 使用验证码确认绑定，返回备用码
+	Enable(ctx context.Context, adminID uint, code string) ([]string, error)
+
+	// Disable 校验验证码后关闭两步验证，强制启用的角色不能关闭
+	Disable(ctx context.Context, adminID uint, code string) error
+
+	// RegenerateBackupCodes 校验验证码后重新生成备用码
+	RegenerateBackupCodes(ctx context.Context, adminID uint, code string) ([]string, error)
+
+	// ConfirmSetup 强制启用两步验证的管理员登录时确认绑定，返回管理员和备用码
+	ConfirmSetup(ctx context.Context, setupToken, code string) (*mysql.Admin, []string, error)
+}
+
+// AdminTwoFactorLogicImpl 管理员两步验证业务逻辑实现
+type AdminTwoFactorLogicImpl struct {
+	adminRepo repository.AdminRepository
+	twoFactor *twofactor.Service
+}
+
+// NewAdminTwoFactorLogic 创建管理员两步验证业务逻辑实例
+func NewAdminTwoFactorLogic(adminRepo repository.AdminRepository, twoFactor *twofactor.Service) *AdminTwoFactorLogicImpl {
+	return &AdminTwoFactorLogicImpl{
+		adminRepo: adminRepo,
+		twoFactor: twoFactor,
+	}
+}
+
+// GetStatus 两步验证状态
+func (l *AdminTwoFactorLogicImpl) GetStatus(ctx context.Context, adminID uint) (*twofactor.Status, error) {
+	admin, err := l.getAdmin(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+	return l.twoFactor.Status(ctx, adminSubject(admin.ID), string(admin.Role))
+}
+
+// BeginSetup 开始绑定，验证器应用中以管理员用户名作为账户名
+func (l *AdminTwoFactorLogicImpl) BeginSetup(ctx context.Context, adminID uint) (*twofactor.Enrollment, error) {
+	admin, err := l.getAdmin(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+	return l.twoFactor.Begin(ctx, adminSubject(admin.ID), admin.Username)
+}
+
+// Enable 使用验证码确认绑定
+func (l *AdminTwoFactorLogicImpl) Enable(ctx context.Context, adminID uint, code string) ([]string, error) {
+	return l.twoFactor.Enable(ctx, adminSubject(adminID), code)
+}
+
+// Disable 关闭两步验证
+func (l *AdminTwoFactorLogicImpl) Disable(ctx context.Context, adminID uint, code string) error {
+	admin, err := l.getAdmin(ctx, adminID)
+	if err != nil {
+		return err
+	}
+	return l.twoFactor.Disable(ctx, adminSubject(admin.ID), string(admin.Role), code)
+}
+
+// RegenerateBackupCodes 重新生成备用码
+func (l *AdminTwoFactorLogicImpl) RegenerateBackupCodes(ctx context.Context, adminID uint, code string) ([]string, error) {
+	return l.twoFactor.RegenerateBackupCodes(ctx, adminSubject(adminID), code)
+}
+
+// ConfirmSetup 确认登录时开始的绑定，确认后管理员完成登录
+func (l *AdminTwoFactorLogicImpl) ConfirmSetup(ctx context.Context, setupToken, code string) (*mysql.Admin, []string, error) {
+	subject, codes, err := l.twoFactor.ConfirmSetup(ctx, setupToken, code)
+	if err != nil {
+		return nil, nil, err
+	}
+	if subject.Type != mysql.TwoFactorSubjectAdmin {
+		return nil, nil, twofactor.ErrInvalidSetupToken
+	}
+
+	admin, err := l.getAdmin(ctx, subject.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !admin.CanLogin() {
+		return nil, nil, errors.New("管理员账户未激活")
+	}
+	return admin, codes, nil
+}
+
+// getAdmin 获取管理员
+func (l *AdminTwoFactorLogicImpl) getAdmin(ctx context.Context, adminID uint) (*mysql.Admin, error) {
+	admin, err := l.adminRepo.GetByID(ctx, adminID)
+	if err != nil {
+		return nil, fmt.Errorf("查询管理员失败: %w", err)
+	}
+	if admin == nil {
+		return nil, errors.New("管理员不存在")
+	}
+	return admin, nil
+}
+
+// adminSubject 管理员的两步验证账户
+func adminSubject(adminID uint) twofactor.Subject {
+	return twofactor.Subject{Type: mysql.TwoFactorSubjectAdmin, ID: adminID}
+}
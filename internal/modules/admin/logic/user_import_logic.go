@@ -0,0 +1,434 @@
+package logic
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	appErrors "exchange/internal/pkg/errors"
+	"exchange/internal/pkg/notification"
+	"exchange/internal/repository"
+	"exchange/internal/utils"
+)
+
+// MaxImportRows 单次导入的最大行数（不含表头）
+const MaxImportRows = 10000
+
+// 每处理多少行更新一次任务进度
+const importProgressInterval = 200
+
+// UserImportRow CSV中的一行用户数据
+type UserImportRow struct {
+	Row          int    // CSV行号（含表头，从1开始）
+	Username     string // 用户名
+	Email        string // 邮箱
+	PasswordHash string // bcrypt密码哈希，仅password_hash模式
+	Role         string // 角色，为空时为user
+	Status       string // 状态，为空时为active；invite模式固定为inactive
+}
+
+// ParseUserImportCSV 解析用户导入CSV
+// 第一行为表头，列顺序不限，支持的列：username、email、password_hash、role、status
+// username和email为必填列，password_hash模式下password_hash为必填列
+func ParseUserImportCSV(r io.Reader, mode mysql.UserImportMode) ([]UserImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("CSV文件为空")
+		}
+		return nil, fmt.Errorf("读取CSV表头失败: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, exists := columns[name]; exists {
+			return nil, fmt.Errorf("CSV表头存在重复列: %s", name)
+		}
+		columns[name] = i
+	}
+
+	required := []string{"username", "email"}
+	if mode == mysql.UserImportModePasswordHash {
+		required = append(required, "password_hash")
+	}
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV缺少必填列: %s", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []UserImportRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取CSV第%d行失败: %w", line, err)
+		}
+
+		if len(rows) >= MaxImportRows {
+			return nil, fmt.Errorf("单次最多导入%d行", MaxImportRows)
+		}
+
+		rows = append(rows, UserImportRow{
+			Row:          line,
+			Username:     field(record, "username"),
+			Email:        strings.ToLower(field(record, "email")),
+			PasswordHash: field(record, "password_hash"),
+			Role:         strings.ToLower(field(record, "role")),
+			Status:       strings.ToLower(field(record, "status")),
+		})
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("CSV文件没有数据行")
+	}
+
+	return rows, nil
+}
+
+// UserImportLogic 用户批量导入业务逻辑接口
+type UserImportLogic interface {
+	// StartImport 创建导入任务并在后台执行，立即返回任务
+	StartImport(ctx context.Context, adminID uint, fileName string, mode mysql.UserImportMode, dryRun bool, rows []UserImportRow) (*mysql.UserImportJob, error)
+
+	// RunImport 创建导入任务并同步执行，返回执行完成的任务（供命令行工具使用）
+	RunImport(ctx context.Context, adminID uint, fileName string, mode mysql.UserImportMode, dryRun bool, rows []UserImportRow) (*mysql.UserImportJob, error)
+
+	// GetImportJob 获取导入任务
+	GetImportJob(ctx context.Context, jobID uint) (*mysql.UserImportJob, error)
+}
+
+// UserImportLogicImpl 用户批量导入业务逻辑实现
+type UserImportLogicImpl struct {
+	config     *config.Config
+	userRepo   repository.UserRepository       // 用户数据访问层
+	importRepo repository.UserImportRepository // 导入任务数据访问层
+	cacheRepo  repository.CacheRepository      // 缓存数据访问层，保存邀请token
+	notifier   notification.Notifier           // 发送邀请链接
+}
+
+// NewUserImportLogic 创建用户批量导入业务逻辑实例
+func NewUserImportLogic(
+	cfg *config.Config,
+	userRepo repository.UserRepository,
+	importRepo repository.UserImportRepository,
+	cacheRepo repository.CacheRepository,
+	notifier notification.Notifier,
+) *UserImportLogicImpl {
+	return &UserImportLogicImpl{
+		config:     cfg,
+		userRepo:   userRepo,
+		importRepo: importRepo,
+		cacheRepo:  cacheRepo,
+		notifier:   notifier,
+	}
+}
+
+// StartImport 创建导入任务并在后台执行
+func (l *UserImportLogicImpl) StartImport(ctx context.Context, adminID uint, fileName string, mode mysql.UserImportMode, dryRun bool, rows []UserImportRow) (*mysql.UserImportJob, error) {
+	job, err := l.createJob(ctx, adminID, fileName, mode, dryRun, len(rows))
+	if err != nil {
+		return nil, err
+	}
+
+	// 返回创建时的快照，后台执行过程中会持续修改job
+	snapshot := *job
+
+	// 请求结束后任务仍需继续执行，不使用请求的context
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				l.finishJob(context.Background(), job, fmt.Errorf("导入任务异常: %v", r))
+			}
+		}()
+		l.execute(context.Background(), job, rows)
+	}()
+
+	return &snapshot, nil
+}
+
+// RunImport 创建导入任务并同步执行
+func (l *UserImportLogicImpl) RunImport(ctx context.Context, adminID uint, fileName string, mode mysql.UserImportMode, dryRun bool, rows []UserImportRow) (*mysql.UserImportJob, error) {
+	job, err := l.createJob(ctx, adminID, fileName, mode, dryRun, len(rows))
+	if err != nil {
+		return nil, err
+	}
+
+	l.execute(ctx, job, rows)
+	return job, nil
+}
+
+// GetImportJob 获取导入任务
+func (l *UserImportLogicImpl) GetImportJob(ctx context.Context, jobID uint) (*mysql.UserImportJob, error) {
+	job, err := l.importRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("查询导入任务失败: %w", err)
+	}
+	if job == nil {
+		return nil, errors.New("导入任务不存在")
+	}
+	return job, nil
+}
+
+// createJob 创建导入任务记录
+func (l *UserImportLogicImpl) createJob(ctx context.Context, adminID uint, fileName string, mode mysql.UserImportMode, dryRun bool, totalRows int) (*mysql.UserImportJob, error) {
+	job := &mysql.UserImportJob{
+		AdminID:   adminID,
+		FileName:  fileName,
+		Mode:      mode,
+		DryRun:    dryRun,
+		Status:    mysql.UserImportStatusPending,
+		TotalRows: totalRows,
+		RowErrors: "[]",
+	}
+
+	if err := l.importRepo.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("创建导入任务失败: %w", err)
+	}
+
+	return job, nil
+}
+
+// execute 执行导入任务
+// 执行流程：
+// 1. 逐行校验（格式、文件内重复、与已有用户重复）
+// 2. 演练模式只统计校验结果，不写入数据
+// 3. 正式执行时逐行创建用户，单行失败不影响其他行
+// 4. invite模式为每个用户生成邀请token并发送邀请链接
+func (l *UserImportLogicImpl) execute(ctx context.Context, job *mysql.UserImportJob, rows []UserImportRow) {
+	job.Status = mysql.UserImportStatusRunning
+	job.StartedAt = clock.Now().UnixNano()
+	if err := l.importRepo.Update(ctx, job); err != nil {
+		l.finishJob(ctx, job, fmt.Errorf("更新导入任务状态失败: %w", err))
+		return
+	}
+
+	var rowErrors []mysql.UserImportRowError
+	seenUsernames := make(map[string]bool)
+	seenEmails := make(map[string]bool)
+
+	for i, row := range rows {
+		if err := ctx.Err(); err != nil {
+			job.SetRowErrors(rowErrors)
+			l.finishJob(ctx, job, fmt.Errorf("导入任务已取消: %w", err))
+			return
+		}
+
+		user, errs := l.validateRow(ctx, job.Mode, row, seenUsernames, seenEmails)
+		if len(errs) == 0 {
+			job.ValidRows++
+			if !job.DryRun {
+				if err := l.importUser(ctx, job.Mode, user); err != nil {
+					errs = append(errs, err.Error())
+				} else {
+					job.ImportedRows++
+				}
+			}
+		}
+
+		if len(errs) > 0 {
+			job.FailedRows++
+			rowErrors = append(rowErrors, mysql.UserImportRowError{
+				Row:      row.Row,
+				Username: row.Username,
+				Email:    row.Email,
+				Errors:   errs,
+			})
+		}
+
+		// 定期更新进度，便于管理员查询执行情况
+		if (i+1)%importProgressInterval == 0 {
+			job.SetRowErrors(rowErrors)
+			if err := l.importRepo.Update(ctx, job); err != nil {
+				fmt.Printf("failed to update user import job %d progress: %v\n", job.ID, err)
+			}
+		}
+	}
+
+	if err := job.SetRowErrors(rowErrors); err != nil {
+		l.finishJob(ctx, job, fmt.Errorf("保存逐行错误失败: %w", err))
+		return
+	}
+	l.finishJob(ctx, job, nil)
+}
+
+// validateRow 校验单行数据，返回待创建的用户和错误列表
+// 规则与用户模型一致：用户名3-50位字母数字下划线连字符、邮箱格式、角色和状态取值
+func (l *UserImportLogicImpl) validateRow(ctx context.Context, mode mysql.UserImportMode, row UserImportRow, seenUsernames, seenEmails map[string]bool) (*mysql.User, []string) {
+	var errs []string
+
+	if err := utils.ValidateUsername(row.Username); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := utils.ValidateEmail(row.Email); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	role := mysql.UserRole(row.Role)
+	if role == "" {
+		role = mysql.UserRoleUser
+	}
+	if role != mysql.UserRoleUser && role != mysql.UserRoleAdmin {
+		errs = append(errs, "角色只能为user或admin")
+	}
+
+	status := mysql.UserStatus(row.Status)
+	if status == "" {
+		status = mysql.UserStatusActive
+	}
+	if status != mysql.UserStatusActive && status != mysql.UserStatusInactive && status != mysql.UserStatusBanned {
+		errs = append(errs, "状态只能为active、inactive或banned")
+	}
+	// 邀请模式下用户设置密码前不能登录
+	if mode == mysql.UserImportModeInvite {
+		status = mysql.UserStatusInactive
+	}
+
+	if mode == mysql.UserImportModePasswordHash {
+		if _, err := bcrypt.Cost([]byte(row.PasswordHash)); err != nil {
+			errs = append(errs, "密码哈希不是有效的bcrypt哈希")
+		}
+	}
+
+	// 文件内重复
+	if row.Username != "" {
+		if seenUsernames[strings.ToLower(row.Username)] {
+			errs = append(errs, "用户名在文件中重复")
+		}
+		seenUsernames[strings.ToLower(row.Username)] = true
+	}
+	if row.Email != "" {
+		if seenEmails[row.Email] {
+			errs = append(errs, "邮箱在文件中重复")
+		}
+		seenEmails[row.Email] = true
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	// 与已有用户重复
+	existingUser, err := l.userRepo.GetByUsername(ctx, row.Username)
+	if err == nil && existingUser != nil {
+		errs = append(errs, "用户名已存在")
+	}
+	existingUser, err = l.userRepo.GetByEmail(ctx, row.Email)
+	if err == nil && existingUser != nil {
+		errs = append(errs, "邮箱已存在")
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return &mysql.User{
+		Username:     row.Username,
+		Email:        row.Email,
+		PasswordHash: row.PasswordHash,
+		Role:         role,
+		Status:       status,
+	}, nil
+}
+
+// importUser 创建用户，invite模式下生成邀请token并发送邀请链接
+func (l *UserImportLogicImpl) importUser(ctx context.Context, mode mysql.UserImportMode, user *mysql.User) error {
+	if mode == mysql.UserImportModeInvite {
+		// 邀请用户在接受邀请前没有可用密码，使用随机密码占位
+		placeholder, err := generateInviteToken()
+		if err != nil {
+			return err
+		}
+		if err := user.SetPassword(placeholder); err != nil {
+			return fmt.Errorf("密码加密失败: %w", err)
+		}
+	}
+
+	if err := user.Validate(); err != nil {
+		return appErrors.InvalidUserError(err, user.ID)
+	}
+
+	if err := l.userRepo.Create(ctx, user); err != nil {
+		return appErrors.UserTranslator.Translate(err, "用户创建失败", map[string]interface{}{"username": user.Username})
+	}
+
+	if mode != mysql.UserImportModeInvite {
+		return nil
+	}
+
+	token, err := generateInviteToken()
+	if err != nil {
+		return err
+	}
+
+	ttl := time.Duration(l.config.Account.InviteTTLHours) * time.Hour
+	if err := l.cacheRepo.Set(mysql.UserInviteKey(token), user.ID, ttl); err != nil {
+		return fmt.Errorf("保存邀请token失败: %w", err)
+	}
+
+	if l.notifier != nil {
+		if err := l.notifier.Notify(ctx, &notification.Notification{
+			UserID:   user.ID,
+			Event:    "user_invited",
+			Email:    user.Email,
+			Language: user.Language,
+			Data: map[string]interface{}{
+				"invite_link": l.config.Account.InviteURL + token,
+				"expires_at":  clock.Now().Add(ttl),
+			},
+			CreatedAt: clock.Now(),
+		}); err != nil {
+			return fmt.Errorf("发送邀请失败: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// finishJob 结束导入任务，err不为空时任务标记为失败
+func (l *UserImportLogicImpl) finishJob(ctx context.Context, job *mysql.UserImportJob, err error) {
+	job.Status = mysql.UserImportStatusCompleted
+	if err != nil {
+		job.Status = mysql.UserImportStatusFailed
+		job.Error = err.Error()
+	}
+	job.FinishedAt = clock.Now().UnixNano()
+
+	// 任务被取消时仍需记录最终状态
+	if updateErr := l.importRepo.Update(context.WithoutCancel(ctx), job); updateErr != nil {
+		fmt.Printf("failed to finish user import job %d: %v\n", job.ID, updateErr)
+	}
+}
+
+// generateInviteToken 生成邀请token
+func generateInviteToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
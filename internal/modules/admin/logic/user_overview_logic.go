@@ -0,0 +1,341 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"exchange/internal/models/mongodb"
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/clock"
+	appErrors "exchange/internal/pkg/errors"
+	"exchange/internal/pkg/rbac"
+	"exchange/internal/repository"
+)
+
+// 用户概览的组成部分
+const (
+	OverviewSectionProfile   = "profile"    // 基本资料
+	OverviewSectionAccount   = "account"    // 账户状态（注销请求、法律保全）
+	OverviewSectionLogins    = "logins"     // 登录情况
+	OverviewSectionMessages  = "messages"   // 最近消息
+	OverviewSectionRiskFlags = "risk_flags" // 风险标记
+)
+
+// 风险标记
+const (
+	RiskFlagBanned          = "banned"           // 已封禁
+	RiskFlagInactive        = "inactive"         // 未激活或已停用
+	RiskFlagDeleted         = "deleted"          // 已注销
+	RiskFlagPendingDeletion = "pending_deletion" // 注销冷静期中
+	RiskFlagLegalHold       = "legal_hold"       // 法律保全中
+	RiskFlagNeverLoggedIn   = "never_logged_in"  // 从未登录
+)
+
+const (
+	userOverviewMessageLimit   = 20              // 最近消息条数
+	userOverviewSectionTimeout = 3 * time.Second // 单个部分的加载超时，超时的部分记入errors，不影响其他部分
+)
+
+// ErrUnknownOverviewSection 请求了不存在的概览部分
+var ErrUnknownOverviewSection = errors.New("unknown overview section")
+
+// AdminUserOverviewLogic 用户概览业务逻辑接口 - 客服查看用户时一次获取所需的全部信息
+type AdminUserOverviewLogic interface {
+	// GetUserOverview 并行加载用户概览的各部分，adminRole无权查看的部分不加载；sections为空时加载全部
+	GetUserOverview(ctx context.Context, adminRole string, userID uint, sections []string) (*UserOverview, error)
+}
+
+// UserOverview 用户概览
+// 单个部分加载失败时记入Errors，其他部分正常返回
+type UserOverview struct {
+	UserID    uint              `json:"user_id"`
+	Profile   *mysql.PublicUser `json:"profile,omitempty"`
+	Account   *AccountOverview  `json:"account,omitempty"`
+	Logins    *LoginOverview    `json:"logins,omitempty"`
+	Messages  *MessageOverview  `json:"messages,omitempty"`
+	RiskFlags []string          `json:"risk_flags"`       // 未加载时为null
+	Hidden    []string          `json:"hidden,omitempty"` // 无权查看的部分
+	Errors    map[string]string `json:"errors,omitempty"` // 加载失败的部分及原因
+}
+
+// AccountOverview 账户状态
+type AccountOverview struct {
+	Status     mysql.UserStatus              `json:"status"`
+	Deletion   *mysql.AccountDeletionRequest `json:"deletion,omitempty"` // 最近一次注销请求
+	LegalHolds []*mysql.LegalHold            `json:"legal_holds"`        // 生效中的法律保全
+}
+
+// LoginOverview 登录情况（未保存逐次登录记录，只有最近登录时间和累计次数）
+type LoginOverview struct {
+	LastLoginAt *time.Time `json:"last_login_at"`
+	LoginCount  int        `json:"login_count"`
+	DaysSince   *int       `json:"days_since_last_login,omitempty"`
+}
+
+// MessageOverview 最近消息和消息统计
+type MessageOverview struct {
+	Stats  map[string]interface{} `json:"stats"`
+	Recent []*mongodb.ChatMessage `json:"recent"`
+}
+
+// overviewSection 概览部分的加载方式和查看需要的权限
+type overviewSection struct {
+	name       string
+	permission string
+	load       func(ctx context.Context, user *mysql.User, overview *UserOverview) error
+}
+
+// PermissionChecker 检查管理员角色的权限
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, role, permission string) bool
+}
+
+// AdminUserOverviewLogicImpl 用户概览业务逻辑实现
+type AdminUserOverviewLogicImpl struct {
+	userRepo      repository.UserRepository
+	deletionRepo  repository.AccountDeletionRepository
+	retentionRepo repository.RetentionRepository
+	messageRepo   repository.UserMessageRepository
+	permissions   PermissionChecker
+	sections      []overviewSection
+}
+
+// NewAdminUserOverviewLogic 创建用户概览业务逻辑实例
+func NewAdminUserOverviewLogic(userRepo repository.UserRepository, deletionRepo repository.AccountDeletionRepository, retentionRepo repository.RetentionRepository, messageRepo repository.UserMessageRepository, permissions PermissionChecker) *AdminUserOverviewLogicImpl {
+	l := &AdminUserOverviewLogicImpl{
+		userRepo:      userRepo,
+		deletionRepo:  deletionRepo,
+		retentionRepo: retentionRepo,
+		messageRepo:   messageRepo,
+		permissions:   permissions,
+	}
+
+	l.sections = []overviewSection{
+		{name: OverviewSectionProfile, permission: rbac.PermUsersRead, load: l.loadProfile},
+		{name: OverviewSectionAccount, permission: rbac.PermUsersRead, load: l.loadAccount},
+		{name: OverviewSectionLogins, permission: rbac.PermUsersRead, load: l.loadLogins},
+		{name: OverviewSectionMessages, permission: rbac.PermMessagesRead, load: l.loadMessages}, // 消息内容需要单独的权限
+		{name: OverviewSectionRiskFlags, permission: rbac.PermUsersRead, load: l.loadRiskFlags},
+	}
+	return l
+}
+
+// GetUserOverview 并行加载用户概览
+func (l *AdminUserOverviewLogicImpl) GetUserOverview(ctx context.Context, adminRole string, userID uint, sections []string) (*UserOverview, error) {
+	selected, err := l.selectSections(sections)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := l.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, appErrors.TranslateUserError(err, "查询用户失败", userID)
+	}
+	if user == nil {
+		return nil, appErrors.ErrUserNotFound.WithContext("user_id", userID)
+	}
+
+	overview := &UserOverview{UserID: userID}
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		partial = make([]*UserOverview, len(selected))
+	)
+	for i, section := range selected {
+		if !l.permissions.HasPermission(ctx, adminRole, section.permission) {
+			overview.Hidden = append(overview.Hidden, section.name)
+			continue
+		}
+
+		// 每个部分写入独立的结果，全部完成后合并，避免并发写同一结构
+		partial[i] = &UserOverview{}
+		wg.Add(1)
+		go func(section overviewSection, result *UserOverview) {
+			defer wg.Done()
+			if err := l.loadSection(ctx, section, user, result); err != nil {
+				mu.Lock()
+				if overview.Errors == nil {
+					overview.Errors = make(map[string]string)
+				}
+				overview.Errors[section.name] = err.Error()
+				mu.Unlock()
+			}
+		}(section, partial[i])
+	}
+	wg.Wait()
+
+	for _, result := range partial {
+		if result != nil {
+			mergeOverview(overview, result)
+		}
+	}
+	return overview, nil
+}
+
+// selectSections 按请求选择概览部分，保持定义顺序
+func (l *AdminUserOverviewLogicImpl) selectSections(names []string) ([]overviewSection, error) {
+	if len(names) == 0 {
+		return l.sections, nil
+	}
+
+	for _, name := range names {
+		if !slices.ContainsFunc(l.sections, func(s overviewSection) bool { return s.name == name }) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownOverviewSection, name)
+		}
+	}
+	selected := make([]overviewSection, 0, len(names))
+	for _, section := range l.sections {
+		if slices.Contains(names, section.name) {
+			selected = append(selected, section)
+		}
+	}
+	return selected, nil
+}
+
+// loadSection 加载单个部分，超时或panic时返回错误
+func (l *AdminUserOverviewLogicImpl) loadSection(ctx context.Context, section overviewSection, user *mysql.User, result *UserOverview) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("load %s panicked: %v", section.name, r)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, userOverviewSectionTimeout)
+	defer cancel()
+	return section.load(ctx, user, result)
+}
+
+// mergeOverview 合并单个部分的加载结果
+func mergeOverview(overview, result *UserOverview) {
+	if result.Profile != nil {
+		overview.Profile = result.Profile
+	}
+	if result.Account != nil {
+		overview.Account = result.Account
+	}
+	if result.Logins != nil {
+		overview.Logins = result.Logins
+	}
+	if result.Messages != nil {
+		overview.Messages = result.Messages
+	}
+	if result.RiskFlags != nil {
+		overview.RiskFlags = result.RiskFlags
+	}
+}
+
+// loadProfile 基本资料
+func (l *AdminUserOverviewLogicImpl) loadProfile(ctx context.Context, user *mysql.User, overview *UserOverview) error {
+	overview.Profile = user.ToPublicUser()
+	return nil
+}
+
+// loadAccount 账户状态
+func (l *AdminUserOverviewLogicImpl) loadAccount(ctx context.Context, user *mysql.User, overview *UserOverview) error {
+	deletion, err := l.deletionRepo.GetLatestByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("查询注销请求失败: %w", err)
+	}
+	holds, err := l.activeHolds(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	overview.Account = &AccountOverview{
+		Status:     user.Status,
+		Deletion:   deletion,
+		LegalHolds: holds,
+	}
+	return nil
+}
+
+// loadLogins 登录情况
+func (l *AdminUserOverviewLogicImpl) loadLogins(ctx context.Context, user *mysql.User, overview *UserOverview) error {
+	logins := &LoginOverview{
+		LastLoginAt: user.LastLoginAt,
+		LoginCount:  user.LoginCount,
+	}
+	if user.LastLoginAt != nil {
+		days := int(clock.Now().Sub(*user.LastLoginAt).Hours() / 24)
+		logins.DaysSince = &days
+	}
+	overview.Logins = logins
+	return nil
+}
+
+// loadMessages 最近消息和消息统计
+func (l *AdminUserOverviewLogicImpl) loadMessages(ctx context.Context, user *mysql.User, overview *UserOverview) error {
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	stats, err := l.messageRepo.GetMessageStats(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("查询消息统计失败: %w", err)
+	}
+	recent, err := l.messageRepo.GetUserMessages(ctx, userID, userOverviewMessageLimit, 0)
+	if err != nil {
+		return fmt.Errorf("查询最近消息失败: %w", err)
+	}
+	if recent == nil {
+		recent = []*mongodb.ChatMessage{}
+	}
+
+	overview.Messages = &MessageOverview{Stats: stats, Recent: recent}
+	return nil
+}
+
+// loadRiskFlags 风险标记，按账户状态、注销请求、法律保全和登录情况计算
+func (l *AdminUserOverviewLogicImpl) loadRiskFlags(ctx context.Context, user *mysql.User, overview *UserOverview) error {
+	flags := []string{}
+	switch user.Status {
+	case mysql.UserStatusBanned:
+		flags = append(flags, RiskFlagBanned)
+	case mysql.UserStatusInactive:
+		flags = append(flags, RiskFlagInactive)
+	case mysql.UserStatusDeleted:
+		flags = append(flags, RiskFlagDeleted)
+	}
+
+	deletion, err := l.deletionRepo.GetLatestByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("查询注销请求失败: %w", err)
+	}
+	if deletion != nil && deletion.IsPending() {
+		flags = append(flags, RiskFlagPendingDeletion)
+	}
+
+	holds, err := l.activeHolds(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if len(holds) > 0 {
+		flags = append(flags, RiskFlagLegalHold)
+	}
+
+	if user.LastLoginAt == nil {
+		flags = append(flags, RiskFlagNeverLoggedIn)
+	}
+
+	overview.RiskFlags = flags
+	return nil
+}
+
+// activeHolds 用户生效中的法律保全
+func (l *AdminUserOverviewLogicImpl) activeHolds(ctx context.Context, userID uint) ([]*mysql.LegalHold, error) {
+	all, err := l.retentionRepo.GetActiveHolds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("查询法律保全失败: %w", err)
+	}
+
+	holds := []*mysql.LegalHold{}
+	for _, hold := range all {
+		if hold.UserID == userID {
+			holds = append(holds, hold)
+		}
+	}
+	return holds, nil
+}
@@ -1,15 +1,35 @@
 package admin
 
 import (
+	"context"
+	"time"
+
 	"github.com/gin-gonic/gin"
 
 	"exchange/internal/middleware"
 	adminHandlers "exchange/internal/modules/admin/handlers"
 	"exchange/internal/modules/admin/logic"
 	"exchange/internal/modules/admin/routes"
+	"exchange/internal/pkg/adminaudit"
+	"exchange/internal/pkg/automation"
 	"exchange/internal/pkg/config"
 	"exchange/internal/pkg/database"
+	"exchange/internal/pkg/events"
+	"exchange/internal/pkg/i18n"
+	"exchange/internal/pkg/jwtkeys"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/loginthrottle"
+	"exchange/internal/pkg/loglevel"
+	"exchange/internal/pkg/mailer"
+	"exchange/internal/pkg/notification"
+	"exchange/internal/pkg/passwordreset"
+	"exchange/internal/pkg/rbac"
+	"exchange/internal/pkg/session"
+	"exchange/internal/pkg/tokenrevoke"
+	"exchange/internal/pkg/translation"
+	"exchange/internal/pkg/twofactor"
 	"exchange/internal/repository"
+	"exchange/internal/repository/mongodb"
 	"exchange/internal/repository/mysql"
 )
 
@@ -19,25 +39,66 @@ type Module struct {
 	config *config.Config
 
 	// 数据库服务
-	mysql *database.MySQLService
-	redis *database.RedisService
+	mysql   *database.MySQLService
+	redis   *database.RedisService
+	mongodb *database.MongoDBService
 
 	// 数据访问层（Admin模块专用）
-	userRepo  repository.UserRepository
-	adminRepo repository.AdminRepository
-	cacheRepo repository.CacheRepository
+	userRepo      repository.UserRepository
+	adminRepo     repository.AdminRepository
+	cacheRepo     repository.CacheRepository
+	retentionRepo repository.RetentionRepository
+	importRepo    repository.UserImportRepository
+	ruleRepo      repository.AutomationRuleRepository
+	templateRepo  repository.NotificationTemplateRepository
+	deletionRepo  repository.AccountDeletionRepository
+	messageRepo   repository.UserMessageRepository
+	roleRepo      repository.RoleRepository
 
 	// 中间件（Admin模块专用）
 	middlewareManager *middleware.MiddlewareManager
 	authMiddleware    *middleware.AdminAuthMiddleware
+	guardMiddleware   *middleware.AdminGuardMiddleware
+	auditMiddleware   *middleware.AdminAuditMiddleware
+	auditStore        *adminaudit.Store
+	permissions       *rbac.PermissionChecker
 
 	// 业务逻辑层（Admin模块专用）
 	userLogic  logic.AdminUserLogic
 	adminLogic logic.AdminLogic
 	authLogic  logic.AdminAuthLogic
 
+	retentionLogic logic.AdminRetentionLogic
+	importLogic    logic.UserImportLogic
+	logLevelLogic  logic.AdminLogLevelLogic
+
+	automationLogic  logic.AdminAutomationLogic
+	automationEngine *automation.Engine
+
+	notifier         notification.Notifier
+	templateLogic    logic.AdminNotificationTemplateLogic
+	overviewLogic    logic.AdminUserOverviewLogic
+	ipAccessLogic    logic.AdminIPAccessLogic
+	maintenanceLogic logic.AdminMaintenanceLogic
+	translationLogic logic.AdminTranslationLogic
+	roleLogic        logic.AdminRoleLogic
+	twoFactorLogic   logic.AdminTwoFactorLogic
+	auditLogic       logic.AdminAuditLogic
+
 	// 处理器层
-	adminHandler *adminHandlers.AdminHandler
+	adminHandler       *adminHandlers.AdminHandler
+	retentionHandler   *adminHandlers.RetentionHandler
+	userImportHandler  *adminHandlers.UserImportHandler
+	logLevelHandler    *adminHandlers.LogLevelHandler
+	automationHandler  *adminHandlers.AutomationHandler
+	templateHandler    *adminHandlers.NotificationTemplateHandler
+	overviewHandler    *adminHandlers.UserOverviewHandler
+	ipAccessHandler    *adminHandlers.IPAccessHandler
+	maintenanceHandler *adminHandlers.MaintenanceHandler
+	translationHandler *adminHandlers.TranslationHandler
+	roleHandler        *adminHandlers.RoleHandler
+	twoFactorHandler   *adminHandlers.TwoFactorHandler
+	auditLogHandler    *adminHandlers.AuditLogHandler
 
 	// 路由层
 	adminRouter *routes.AdminRouter
@@ -48,16 +109,19 @@ type Module struct {
 // - cfg: 应用配置
 // - mysql: MySQL数据库服务
 // - redis: Redis缓存服务
+// - mongodb: MongoDB服务（用户概览读取消息、翻译覆盖、操作审计记录）
 func NewModule(
 	cfg *config.Config,
 	mysql *database.MySQLService,
 	redis *database.RedisService,
+	mongodb *database.MongoDBService,
 ) *Module {
 	// 创建模块实例
 	module := &Module{
-		config: cfg,
-		mysql:  mysql,
-		redis:  redis,
+		config:  cfg,
+		mysql:   mysql,
+		redis:   redis,
+		mongodb: mongodb,
 	}
 
 	// 初始化所有组件
@@ -93,36 +157,137 @@ func (module *Module) initRepositories() {
 
 	// 创建缓存数据访问层
 	module.cacheRepo = repository.NewRedisCacheRepository(module.redis)
+
+	// 创建数据保留数据访问层
+	module.retentionRepo = mysql.NewRetentionRepository(module.mysql.DB())
+
+	// 创建用户批量导入任务数据访问层
+	module.importRepo = mysql.NewUserImportRepository(module.mysql.DB())
+
+	// 创建消息自动化规则数据访问层
+	module.ruleRepo = mysql.NewAutomationRuleRepository(module.mysql.DB())
+
+	// 创建通知模板数据访问层
+	module.templateRepo = mysql.NewNotificationTemplateRepository(module.mysql.DB())
+
+	// 创建账户注销请求数据访问层
+	module.deletionRepo = mysql.NewAccountDeletionRepository(module.mysql.DB())
+
+	// 创建消息数据访问层（用户概览）
+	module.messageRepo = mongodb.NewMessageRepository(module.mongodb)
+
+	// 创建角色数据访问层
+	module.roleRepo = mysql.NewRoleRepository(module.mysql.DB())
 }
 
 // initMiddlewares 初始化中间件（Admin模块专用）
 func (module *Module) initMiddlewares() {
 	// 创建中间件管理器
-	module.middlewareManager = middleware.NewMiddlewareManager(module.redis)
+	module.middlewareManager = middleware.NewMiddlewareManager(module.redis, module.config)
 
 	// 创建Admin专用的认证中间件
 	module.authMiddleware = middleware.NewAdminAuthMiddleware(module.redis, module.config)
+
+	// 创建管理端按管理员限流和异常检测中间件
+	module.guardMiddleware = middleware.NewAdminGuardMiddleware(module.redis, module.config.AdminGuard)
+
+	// 创建权限检查，认证中间件按管理员角色的权限授权
+	module.permissions = rbac.NewPermissionChecker(module.roleRepo, time.Duration(module.config.RBAC.CacheSeconds)*time.Second)
+	module.authMiddleware.SetPermissionChecker(module.permissions)
+
+	// 创建管理员操作审计中间件，记录管理员的写操作
+	module.auditStore = adminaudit.NewStore(module.mongodb)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := module.auditStore.EnsureIndexes(ctx); err != nil {
+		appLogger.Warn("创建操作审计记录索引失败", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	module.auditMiddleware = middleware.NewAdminAuditMiddleware(module.auditStore)
 }
 
 // initLogic 初始化业务逻辑层（Admin模块专用）
 func (module *Module) initLogic() {
-	// 创建用户业务逻辑
-	module.userLogic = logic.NewAdminUserLogic(module.userRepo, module.adminRepo)
+	// 与API模块共用Redis中的用户登录会话和token撤销记录（强制用户下线）
+	sessions := session.NewStore(module.redis, time.Duration(module.config.JWT.RefreshTokenDays)*24*time.Hour)
+	revoked := tokenrevoke.NewStore(module.redis, module.config.JWT.MaxTokenLifetime())
+
+	// 创建管理员业务逻辑，仪表板的在线用户和每日登录次数来自用户登录会话
+	module.adminLogic = logic.NewAdminLogic(module.userRepo, module.adminRepo, module.messageRepo, sessions, module.cacheRepo)
 
-	// 创建管理员业务逻辑
-	module.adminLogic = logic.NewAdminLogic(module.userRepo, module.adminRepo)
+	// 创建两步验证服务，登录时校验验证码并按角色强制启用
+	twoFactor := twofactor.NewService(mysql.NewTwoFactorRepository(module.mysql.DB()), module.redis, module.config.TwoFactor)
 
-	// 创建认证业务逻辑
+	// 创建登录失败限制，失败次数过多时暂时禁止登录
+	throttle := loginthrottle.NewThrottle(module.redis, module.config.LoginThrottle)
+
+	// 创建认证业务逻辑，JWT密钥与API模块使用相同的配置
+	jwtKeys, err := jwtkeys.LoadFromConfig(context.Background(), module.config)
+	if err != nil {
+		panic("JWT密钥加载失败: " + err.Error())
+	}
 	authLogic, err := logic.NewAdminAuthLogic(
 		module.config,
+		jwtKeys,
 		module.userRepo,
 		module.adminRepo,
 		module.cacheRepo,
+		revoked,
+		twoFactor,
+		throttle,
 	)
 	if err != nil {
 		panic("Admin认证逻辑初始化失败: " + err.Error())
 	}
 	module.authLogic = authLogic
+	module.twoFactorLogic = logic.NewAdminTwoFactorLogic(module.adminRepo, twoFactor)
+
+	// 创建数据保留业务逻辑
+	module.retentionLogic = logic.NewAdminRetentionLogic(module.config, module.userRepo, module.retentionRepo)
+
+	// 创建通知模板业务逻辑，通知发送前按管理员编辑的模板（或内置默认模板）渲染，渲染和邮件发送由异步工作池执行
+	defaultLanguage := i18n.GetGlobalI18n().GetDefaultLanguage()
+	renderer := notification.NewRenderer(module.templateRepo, defaultLanguage)
+	sender, err := mailer.NewSenderFromConfig(module.config)
+	if err != nil {
+		panic("邮件发送初始化失败: " + err.Error())
+	}
+	module.notifier = notification.NewAsyncNotifier(notification.NewTemplateNotifier(notification.NewEmailNotifier(notification.NewLogNotifier(), sender), renderer))
+	module.templateLogic = logic.NewAdminNotificationTemplateLogic(module.templateRepo, renderer)
+
+	// 创建用户管理业务逻辑，要求用户重置密码时使用与API模块找回密码相同的重置token存储发送重置邮件
+	module.userLogic = logic.NewAdminUserLogic(module.config, module.userRepo, module.adminRepo, sessions, revoked, passwordreset.NewStore(module.redis, module.config.Account), module.notifier)
+
+	// 创建用户批量导入业务逻辑
+	module.importLogic = logic.NewUserImportLogic(module.config, module.userRepo, module.importRepo, module.cacheRepo, module.notifier)
+
+	// 创建日志级别业务逻辑
+	module.logLevelLogic = logic.NewAdminLogLevelLogic(loglevel.NewSync(module.redis))
+
+	// 创建消息自动化业务逻辑，并订阅事件总线执行规则
+	module.automationLogic = logic.NewAdminAutomationLogic(module.userRepo, module.ruleRepo, defaultLanguage)
+	module.automationEngine = automation.NewEngine(module.ruleRepo, module.userRepo, module.notifier, defaultLanguage)
+	module.automationEngine.Subscribe(events.DefaultBus())
+
+	// 创建用户概览业务逻辑
+	module.overviewLogic = logic.NewAdminUserOverviewLogic(module.userRepo, module.deletionRepo, module.retentionRepo, module.messageRepo, module.permissions)
+
+	// 创建IP访问控制业务逻辑，与IP访问控制中间件共用名单存储
+	module.ipAccessLogic = logic.NewAdminIPAccessLogic(module.middlewareManager.IPAccess().Store(), module.config.IPAccess)
+
+	// 创建维护模式业务逻辑，与维护模式中间件共用状态存储
+	module.maintenanceLogic = logic.NewAdminMaintenanceLogic(module.middlewareManager.Maintenance().Store(), module.config.Maintenance)
+
+	// 创建翻译管理业务逻辑，翻译覆盖通过Redis通知所有实例重新加载
+	translationSync := translation.NewSync(translation.NewStore(module.mongodb), module.redis, i18n.GetGlobalI18n())
+	module.translationLogic = logic.NewAdminTranslationLogic(i18n.GetGlobalI18n(), translationSync)
+
+	// 创建角色管理业务逻辑，与认证中间件共用权限检查
+	module.roleLogic = logic.NewAdminRoleLogic(module.roleRepo, module.adminRepo, module.permissions)
+
+	// 创建操作审计业务逻辑
+	module.auditLogic = logic.NewAdminAuditLogic(module.auditStore)
 
 	// 将认证逻辑设置到认证中间件中
 	module.authMiddleware.SetAuthLogic(authLogic)
@@ -136,14 +301,66 @@ func (module *Module) initHandlers() {
 		module.adminLogic, // 管理员业务逻辑
 		module.authLogic,  // 认证业务逻辑
 	)
+
+	// 创建数据保留处理器
+	module.retentionHandler = adminHandlers.NewRetentionHandler(module.retentionLogic)
+
+	// 创建用户批量导入处理器
+	module.userImportHandler = adminHandlers.NewUserImportHandler(module.importLogic)
+
+	// 创建日志级别处理器
+	module.logLevelHandler = adminHandlers.NewLogLevelHandler(module.logLevelLogic)
+
+	// 创建消息自动化处理器
+	module.automationHandler = adminHandlers.NewAutomationHandler(module.automationLogic)
+
+	// 创建通知模板处理器
+	module.templateHandler = adminHandlers.NewNotificationTemplateHandler(module.templateLogic)
+
+	// 创建用户概览处理器
+	module.overviewHandler = adminHandlers.NewUserOverviewHandler(module.overviewLogic)
+
+	// 创建IP访问控制处理器
+	module.ipAccessHandler = adminHandlers.NewIPAccessHandler(module.ipAccessLogic)
+
+	// 创建维护模式处理器
+	module.maintenanceHandler = adminHandlers.NewMaintenanceHandler(module.maintenanceLogic)
+
+	// 创建翻译管理处理器
+	module.translationHandler = adminHandlers.NewTranslationHandler(module.translationLogic)
+
+	// 创建角色管理处理器
+	module.roleHandler = adminHandlers.NewRoleHandler(module.roleLogic)
+
+	// 创建两步验证处理器
+	module.twoFactorHandler = adminHandlers.NewTwoFactorHandler(module.twoFactorLogic, module.authLogic)
+
+	// 创建操作审计处理器
+	module.auditLogHandler = adminHandlers.NewAuditLogHandler(module.auditLogic)
 }
 
 // initRoutes 初始化路由层
 func (module *Module) initRoutes() {
 	// 创建Admin路由，注入处理器和中间件
 	module.adminRouter = routes.NewAdminRouter(
-		module.adminHandler,   // 管理员处理器
-		module.authMiddleware, // Admin专用认证中间件
+		module.adminHandler,                  // 管理员处理器
+		module.retentionHandler,              // 数据保留处理器
+		module.userImportHandler,             // 用户批量导入处理器
+		module.logLevelHandler,               // 日志级别处理器
+		module.automationHandler,             // 消息自动化处理器
+		module.templateHandler,               // 通知模板处理器
+		module.overviewHandler,               // 用户概览处理器
+		module.ipAccessHandler,               // IP访问控制处理器
+		module.maintenanceHandler,            // 维护模式处理器
+		module.translationHandler,            // 翻译管理处理器
+		module.roleHandler,                   // 角色管理处理器
+		module.twoFactorHandler,              // 两步验证处理器
+		module.auditLogHandler,               // 操作审计处理器
+		module.authMiddleware,                // Admin专用认证中间件
+		module.guardMiddleware,               // 管理端限流和异常检测中间件
+		module.auditMiddleware,               // 管理员操作审计中间件
+		module.middlewareManager.RateLimit(), // 接口限流中间件
+		module.middlewareManager.IPAccess(),  // IP访问控制中间件
 	)
 }
 
@@ -166,3 +383,13 @@ func (module *Module) GetMiddlewareManager() *middleware.MiddlewareManager {
 func (module *Module) GetAuthMiddleware() *middleware.AdminAuthMiddleware {
 	return module.authMiddleware
 }
+
+// GetAuditMiddleware 获取管理员操作审计中间件（供其他服务记录管理操作）
+func (module *Module) GetAuditMiddleware() *middleware.AdminAuditMiddleware {
+	return module.auditMiddleware
+}
+
+// GetAdminHandler 获取管理员处理器（供其他服务复用登录等接口）
+func (module *Module) GetAdminHandler() *adminHandlers.AdminHandler {
+	return module.adminHandler
+}
@@ -5,32 +5,105 @@ import (
 
 	"exchange/internal/middleware"
 	adminHandlers "exchange/internal/modules/admin/handlers"
+	"exchange/internal/pkg/rbac"
+	"exchange/internal/utils"
 )
 
 // AdminRouter Admin路由管理器 - 负责设置所有Admin相关的路由
 type AdminRouter struct {
-	adminHandler   *adminHandlers.AdminHandler     // 管理员处理器
-	authMiddleware *middleware.AdminAuthMiddleware // Admin认证中间件
+	adminHandler       *adminHandlers.AdminHandler                // 管理员处理器
+	retentionHandler   *adminHandlers.RetentionHandler            // 数据保留处理器
+	userImportHandler  *adminHandlers.UserImportHandler           // 用户批量导入处理器
+	logLevelHandler    *adminHandlers.LogLevelHandler             // 日志级别处理器
+	automationHandler  *adminHandlers.AutomationHandler           // 消息自动化处理器
+	templateHandler    *adminHandlers.NotificationTemplateHandler // 通知模板处理器
+	overviewHandler    *adminHandlers.UserOverviewHandler         // 用户概览处理器
+	ipAccessHandler    *adminHandlers.IPAccessHandler             // IP访问控制处理器
+	maintenanceHandler *adminHandlers.MaintenanceHandler          // 维护模式处理器
+	translationHandler *adminHandlers.TranslationHandler          // 翻译管理处理器
+	roleHandler        *adminHandlers.RoleHandler                 // 角色管理处理器
+	twoFactorHandler   *adminHandlers.TwoFactorHandler            // 两步验证处理器
+	auditLogHandler    *adminHandlers.AuditLogHandler             // 操作审计处理器
+	authMiddleware     *middleware.AdminAuthMiddleware            // Admin认证中间件
+	guardMiddleware    *middleware.AdminGuardMiddleware           // 管理端限流和异常检测中间件
+	auditMiddleware    *middleware.AdminAuditMiddleware           // 管理员操作审计中间件
+	rateLimit          *middleware.RateLimitMiddleware            // 接口限流中间件
+	ipAccess           *middleware.IPAccessMiddleware             // IP访问控制中间件
+	engine             *gin.Engine                                // Gin引擎，用于导出路由权限矩阵
 }
 
 // NewAdminRouter 创建Admin路由管理器
 // 参数说明：
 // - adminHandler: 管理员处理器，处理管理员相关的HTTP请求
+// - retentionHandler: 数据保留处理器，处理合规报告、法律保全和会话归档策略请求
+// - userImportHandler: 用户批量导入处理器，处理CSV导入和导入任务查询请求
+// - logLevelHandler: 日志级别处理器，处理运行时调整日志级别请求
+// - automationHandler: 消息自动化处理器，处理自动化规则管理和预览请求
+// - templateHandler: 通知模板处理器，处理通知模板编辑、版本管理和预览请求
+// - overviewHandler: 用户概览处理器，处理客服查看用户概览请求
+// - ipAccessHandler: IP访问控制处理器，处理IP白名单和黑名单管理请求
+// - maintenanceHandler: 维护模式处理器，处理开启、关闭和查询维护模式请求
+// - translationHandler: 翻译管理处理器，处理查看翻译、缺失翻译和编辑翻译覆盖请求
+// - roleHandler: 角色管理处理器，处理查看角色和修改角色权限请求
+// - twoFactorHandler: 两步验证处理器，处理管理员绑定、启用、关闭两步验证和登录时确认绑定请求
+// - auditLogHandler: 操作审计处理器，处理查询管理员操作审计记录请求
 // - authMiddleware: Admin认证中间件，用于验证管理员身份
-func NewAdminRouter(adminHandler *adminHandlers.AdminHandler, authMiddleware *middleware.AdminAuthMiddleware) *AdminRouter {
+// - guardMiddleware: 管理端限流和异常检测中间件，按管理员限流并在异常操作时临时停用管理员
+// - auditMiddleware: 管理员操作审计中间件，记录管理员的每个写操作
+// - rateLimit: 接口限流中间件，在认证之后执行按用户限流的策略
+// - ipAccess: IP访问控制中间件，管理后台登录和管理路由只允许白名单中的IP访问
+func NewAdminRouter(adminHandler *adminHandlers.AdminHandler, retentionHandler *adminHandlers.RetentionHandler, userImportHandler *adminHandlers.UserImportHandler, logLevelHandler *adminHandlers.LogLevelHandler, automationHandler *adminHandlers.AutomationHandler, templateHandler *adminHandlers.NotificationTemplateHandler, overviewHandler *adminHandlers.UserOverviewHandler, ipAccessHandler *adminHandlers.IPAccessHandler, maintenanceHandler *adminHandlers.MaintenanceHandler, translationHandler *adminHandlers.TranslationHandler, roleHandler *adminHandlers.RoleHandler, twoFactorHandler *adminHandlers.TwoFactorHandler, auditLogHandler *adminHandlers.AuditLogHandler, authMiddleware *middleware.AdminAuthMiddleware, guardMiddleware *middleware.AdminGuardMiddleware, auditMiddleware *middleware.AdminAuditMiddleware, rateLimit *middleware.RateLimitMiddleware, ipAccess *middleware.IPAccessMiddleware) *AdminRouter {
 	return &AdminRouter{
-		adminHandler:   adminHandler,
-		authMiddleware: authMiddleware,
+		adminHandler:       adminHandler,
+		retentionHandler:   retentionHandler,
+		userImportHandler:  userImportHandler,
+		logLevelHandler:    logLevelHandler,
+		automationHandler:  automationHandler,
+		templateHandler:    templateHandler,
+		overviewHandler:    overviewHandler,
+		ipAccessHandler:    ipAccessHandler,
+		maintenanceHandler: maintenanceHandler,
+		translationHandler: translationHandler,
+		roleHandler:        roleHandler,
+		twoFactorHandler:   twoFactorHandler,
+		auditLogHandler:    auditLogHandler,
+		authMiddleware:     authMiddleware,
+		guardMiddleware:    guardMiddleware,
+		auditMiddleware:    auditMiddleware,
+		rateLimit:          rateLimit,
+		ipAccess:           ipAccess,
 	}
 }
 
 // SetupRoutes 设置Admin路由到Gin引擎
 // 路由结构：
-// /admin/v1/auth/login     - 管理员登录（无需认证）
-// /admin/v1/dashboard      - 获取仪表板（需要认证）
+// /admin/v1/auth/login     - 管理员登录（无需认证，已启用两步验证时需要验证码）
+// /admin/v1/auth/2fa/confirm-setup - 强制启用两步验证的管理员登录时确认绑定（无需认证，使用绑定token）
+// /admin/v1/admin/2fa              - 本人的两步验证状态/绑定、启用、关闭和重新生成备用码（已登录即可）
+// /admin/v1/admin/dashboard        - 获取仪表板（dashboard:read）
+// /admin/v1/admin/authz-matrix     - 路由权限矩阵（authz:read）
+// /admin/v1/admin/roles            - 角色和权限查询（roles:read）/创建、修改、删除角色（roles:write）
+// /admin/v1/admin/retention/report - 数据保留合规报告（retention:read）
+// /admin/v1/admin/retention/holds  - 法律保全查询（retention:read）/设置、解除（retention:write）
+// /admin/v1/admin/retention/archive-overrides - 会话归档策略查询（retention:read）/设置、删除（retention:write）
+// /admin/v1/admin/users/:id/overview - 用户概览（users:read，消息部分需要messages:read）
+// /admin/v1/admin/users/:id/logout   - 强制用户下线，撤销所有登录会话（users:logout）
+// /admin/v1/admin/users/:id/{ban,unban,lock,unlock,force-password-reset} - 封禁、锁定和要求重置密码（users:write）
+// /admin/v1/admin/users/:id        - 删除用户（DELETE）/恢复已删除的用户（POST /restore）（users:delete）
+// /admin/v1/admin/users/import     - 用户批量导入（users:import）
+// /admin/v1/admin/users/import/:id - 导入任务查询（users:read）
+// /admin/v1/admin/log-levels       - 日志级别查询（log_levels:read）/设置、重置（log_levels:write）
+// /admin/v1/admin/automation/rules - 消息自动化规则查询和预览（automation:read）/创建、修改、删除（automation:write）
+// /admin/v1/admin/notification-templates - 通知模板查询和预览（templates:read）/保存、启用版本、恢复默认（templates:write）
+// /admin/v1/admin/ip-access/:list  - IP白名单(allow)和黑名单(deny)查询（ip_access:read）/添加、删除（ip_access:write）
+// /admin/v1/admin/maintenance      - 维护状态查询（maintenance:read）/开启、关闭（maintenance:write）
+// /admin/v1/admin/translations     - 翻译、覆盖率和翻译覆盖查询（translations:read）/保存、删除翻译覆盖（translations:edit）
+// /admin/v1/admin/audit-logs       - 管理员操作审计记录查询（audit:read）
 // /admin/v1/system/ping    - 健康检查（无需认证）
 // /admin/v1/system/info    - 系统信息（无需认证）
 func (r *AdminRouter) SetupRoutes(router *gin.Engine) {
+	r.engine = router
+
 	// 创建Admin v1路由组
 	adminV1 := router.Group("/admin/v1")
 	{
@@ -48,25 +121,231 @@ func (r *AdminRouter) SetupRoutes(router *gin.Engine) {
 // setupAuthRoutes 设置管理员认证路由（无需认证）
 func (r *AdminRouter) setupAuthRoutes(adminV1 *gin.RouterGroup) {
 	auth := adminV1.Group("/auth")
+	auth.Use(r.ipAccess.AdminAllowlist()) // 开启管理后台IP白名单时只允许白名单中的IP登录
+	middleware.GetAuthMatrix().ClassifyGroup(auth, middleware.PublicRequirement())
 	{
-		auth.POST("/login", r.adminHandler.Login) // 管理员登录
+		auth.POST("/login", r.adminHandler.Login)                        // 管理员登录
+		auth.POST("/2fa/confirm-setup", r.twoFactorHandler.ConfirmSetup) // 登录时确认两步验证绑定
 	}
 }
 
-// setupAdminRoutes 设置管理员管理路由（需要认证）
+// setupAdminRoutes 设置管理员管理路由（需要认证，各路由按权限授权）
 func (r *AdminRouter) setupAdminRoutes(adminV1 *gin.RouterGroup) {
-	admin := adminV1.Group("/admin")
-	admin.Use(r.authMiddleware.RequireAuth(), r.authMiddleware.RequireAdmin()) // 添加Admin认证和角色验证中间件
+	admin := middleware.GetAuthMatrix().Routes(adminV1.Group("/admin"))
+	admin.Use(r.ipAccess.AdminAllowlist(), r.authMiddleware.RequireAuth(), r.authMiddleware.RequireAdmin(), r.rateLimit.LimitUser(), r.guardMiddleware.Guard(), r.auditMiddleware.Record()) // 添加IP白名单、Admin认证、角色验证、接口限流、限流/异常检测和操作审计中间件
 	{
-		admin.GET("/dashboard", r.adminHandler.GetDashboard) // 获取仪表板
-		admin.GET("/users", r.adminHandler.GetUsers)         // 获取用户列表
+		admin.GET("/dashboard", r.authMiddleware.RequirePermission(rbac.PermDashboardRead), r.adminHandler.GetDashboard) // 获取仪表板
+		admin.GET("/users", r.authMiddleware.RequirePermission(rbac.PermUsersRead), r.adminHandler.GetUsers)             // 获取用户列表
+
+		// 用户概览（各部分按管理员权限过滤）
+		admin.GET("/users/:id/overview", r.authMiddleware.RequirePermission(rbac.PermUsersRead), r.overviewHandler.GetUserOverview)
+
+		// 强制用户下线
+		admin.POST("/users/:id/logout", r.authMiddleware.RequirePermission(rbac.PermUsersLogout), r.adminHandler.ForceLogoutUser)
+
+		// 用户封禁、锁定、要求重置密码、删除和恢复
+		r.setupUserManagementRoutes(admin)
+
+		// 路由权限矩阵
+		admin.GET("/authz-matrix", r.authMiddleware.RequirePermission(rbac.PermAuthzRead), r.authMatrixHandler)
+
+		// 本人的两步验证
+		r.setupTwoFactorRoutes(admin)
+
+		// 角色和权限
+		r.setupRoleRoutes(admin)
+
+		// 数据保留合规报告和法律保全
+		r.setupRetentionRoutes(admin)
+
+		// 用户批量导入
+		r.setupUserImportRoutes(admin)
+
+		// 运行时日志级别
+		r.setupLogLevelRoutes(admin)
+
+		// 消息自动化规则
+		r.setupAutomationRoutes(admin)
+
+		// 通知模板
+		r.setupNotificationTemplateRoutes(admin)
+
+		// IP白名单和黑名单
+		r.setupIPAccessRoutes(admin)
+
+		// 维护模式
+		r.setupMaintenanceRoutes(admin)
+
+		// 翻译管理
+		r.setupTranslationRoutes(admin)
+
+		// 管理员操作审计记录
+		r.setupAuditLogRoutes(admin)
 		// 注意：其他管理员功能可以在这里添加
 	}
 }
 
+// setupTwoFactorRoutes 设置管理员本人的两步验证路由（在管理员路由组下，不需要额外权限）
+func (r *AdminRouter) setupTwoFactorRoutes(admin *middleware.AuthRoutes) {
+	twoFactor := admin.Group("/2fa")
+	{
+		twoFactor.GET("", r.twoFactorHandler.GetStatus)                           // 两步验证状态
+		twoFactor.POST("/setup", r.twoFactorHandler.BeginSetup)                   // 开始绑定，返回密钥和绑定地址
+		twoFactor.POST("/enable", r.twoFactorHandler.Enable)                      // 确认绑定并启用，返回备用码
+		twoFactor.POST("/disable", r.twoFactorHandler.Disable)                    // 关闭两步验证
+		twoFactor.POST("/backup-codes", r.twoFactorHandler.RegenerateBackupCodes) // 重新生成备用码
+	}
+}
+
+// setupRoleRoutes 设置角色管理路由（在管理员路由组下）
+func (r *AdminRouter) setupRoleRoutes(admin *middleware.AuthRoutes) {
+	roles := admin.Group("/roles")
+	read := r.authMiddleware.RequirePermission(rbac.PermRolesRead)
+	write := r.authMiddleware.RequirePermission(rbac.PermRolesWrite)
+	{
+		roles.GET("", read, r.roleHandler.ListRoles)                   // 角色列表及其权限
+		roles.GET("/permissions", read, r.roleHandler.ListPermissions) // 可分配的权限
+		roles.PUT("/:name", write, r.roleHandler.SaveRole)             // 创建角色或替换角色的权限
+		roles.DELETE("/:name", write, r.roleHandler.DeleteRole)        // 删除角色（内置角色恢复默认权限）
+	}
+}
+
+// setupRetentionRoutes 设置数据保留路由（在管理员路由组下）
+func (r *AdminRouter) setupRetentionRoutes(admin *middleware.AuthRoutes) {
+	retention := admin.Group("/retention")
+	read := r.authMiddleware.RequirePermission(rbac.PermRetentionRead)
+	write := r.authMiddleware.RequirePermission(rbac.PermRetentionWrite)
+	{
+		retention.GET("/report", read, r.retentionHandler.GetComplianceReport)            // 合规报告
+		retention.GET("/holds", read, r.retentionHandler.GetLegalHolds)                   // 生效中的法律保全
+		retention.GET("/archive-overrides", read, r.retentionHandler.GetArchiveOverrides) // 会话归档策略
+
+		retention.POST("/holds", write, r.retentionHandler.PlaceLegalHold)                          // 设置法律保全
+		retention.DELETE("/holds/:user_id", write, r.retentionHandler.ReleaseLegalHold)             // 解除法律保全
+		retention.PUT("/archive-overrides", write, r.retentionHandler.SetArchiveOverride)           // 设置会话归档策略
+		retention.DELETE("/archive-overrides/:id", write, r.retentionHandler.DeleteArchiveOverride) // 删除会话归档策略
+	}
+}
+
+// setupUserManagementRoutes 设置用户状态管理路由（在管理员路由组下）
+func (r *AdminRouter) setupUserManagementRoutes(admin *middleware.AuthRoutes) {
+	users := admin.Group("/users/:id")
+	write := r.authMiddleware.RequirePermission(rbac.PermUsersWrite)
+	remove := r.authMiddleware.RequirePermission(rbac.PermUsersDelete)
+	{
+		users.POST("/ban", write, r.adminHandler.BanUser)                             // 封禁并强制下线
+		users.POST("/unban", write, r.adminHandler.UnbanUser)                         // 解除封禁
+		users.POST("/lock", write, r.adminHandler.LockUser)                           // 锁定一段时间并强制下线
+		users.POST("/unlock", write, r.adminHandler.UnlockUser)                       // 解除锁定
+		users.POST("/force-password-reset", write, r.adminHandler.ForcePasswordReset) // 要求重置密码
+		users.DELETE("", remove, r.adminHandler.DeleteUser)                           // 删除（软删除）
+		users.POST("/restore", remove, r.adminHandler.RestoreUser)                    // 恢复已删除的用户
+	}
+}
+
+// setupUserImportRoutes 设置用户批量导入路由（在管理员路由组下）
+func (r *AdminRouter) setupUserImportRoutes(admin *middleware.AuthRoutes) {
+	userImport := admin.Group("/users/import")
+	{
+		userImport.POST("", r.authMiddleware.RequirePermission(rbac.PermUsersImport), r.userImportHandler.ImportUsers)   // 上传CSV发起导入
+		userImport.GET("/:id", r.authMiddleware.RequirePermission(rbac.PermUsersRead), r.userImportHandler.GetImportJob) // 导入任务详情
+	}
+}
+
+// setupLogLevelRoutes 设置日志级别路由（在管理员路由组下）
+func (r *AdminRouter) setupLogLevelRoutes(admin *middleware.AuthRoutes) {
+	logLevels := admin.Group("/log-levels")
+	write := r.authMiddleware.RequirePermission(rbac.PermLogLevelsWrite)
+	{
+		logLevels.GET("", r.authMiddleware.RequirePermission(rbac.PermLogLevelsRead), r.logLevelHandler.GetLogLevels) // 查询日志级别
+		logLevels.PUT("", write, r.logLevelHandler.SetLogLevel)                                                       // 设置日志级别
+		logLevels.DELETE("/:module", write, r.logLevelHandler.ResetLogLevel)                                          // 重置日志级别
+	}
+}
+
+// setupAutomationRoutes 设置消息自动化规则路由（在管理员路由组下）
+func (r *AdminRouter) setupAutomationRoutes(admin *middleware.AuthRoutes) {
+	rules := admin.Group("/automation/rules")
+	read := r.authMiddleware.RequirePermission(rbac.PermAutomationRead)
+	write := r.authMiddleware.RequirePermission(rbac.PermAutomationWrite)
+	{
+		rules.GET("", read, r.automationHandler.ListRules)                // 规则列表
+		rules.POST("", write, r.automationHandler.CreateRule)             // 创建规则
+		rules.GET("/:id", read, r.automationHandler.GetRule)              // 规则详情
+		rules.PUT("/:id", write, r.automationHandler.UpdateRule)          // 更新规则
+		rules.DELETE("/:id", write, r.automationHandler.DeleteRule)       // 删除规则
+		rules.POST("/:id/preview", read, r.automationHandler.PreviewRule) // 预览渲染结果
+	}
+}
+
+// setupNotificationTemplateRoutes 设置通知模板路由（在管理员路由组下）
+func (r *AdminRouter) setupNotificationTemplateRoutes(admin *middleware.AuthRoutes) {
+	templates := admin.Group("/notification-templates")
+	read := r.authMiddleware.RequirePermission(rbac.PermTemplatesRead)
+	write := r.authMiddleware.RequirePermission(rbac.PermTemplatesWrite)
+	{
+		templates.GET("", read, r.templateHandler.ListTemplates)                                    // 启用的模板列表
+		templates.GET("/events", read, r.templateHandler.ListEvents)                                // 支持模板的事件及变量
+		templates.GET("/:event/:channel/:locale", read, r.templateHandler.GetTemplate)              // 当前生效的模板和历史版本
+		templates.POST("/:event/:channel/:locale/preview", read, r.templateHandler.PreviewTemplate) // 预览渲染结果
+
+		templates.PUT("/:event/:channel/:locale", write, r.templateHandler.SaveTemplate)                                // 保存新版本
+		templates.POST("/:event/:channel/:locale/versions/:version/activate", write, r.templateHandler.ActivateVersion) // 启用历史版本
+		templates.DELETE("/:event/:channel/:locale", write, r.templateHandler.ResetTemplate)                            // 恢复内置默认模板
+	}
+}
+
+// setupIPAccessRoutes 设置IP访问控制路由（在管理员路由组下）
+func (r *AdminRouter) setupIPAccessRoutes(admin *middleware.AuthRoutes) {
+	ipAccess := admin.Group("/ip-access")
+	write := r.authMiddleware.RequirePermission(rbac.PermIPAccessWrite)
+	{
+		ipAccess.GET("/:list", r.authMiddleware.RequirePermission(rbac.PermIPAccessRead), r.ipAccessHandler.ListEntries) // 名单条目
+		ipAccess.POST("/:list", write, r.ipAccessHandler.AddEntry)                                                       // 添加条目
+		ipAccess.DELETE("/:list", write, r.ipAccessHandler.RemoveEntry)                                                  // 删除条目（cidr为查询参数）
+	}
+}
+
+// setupMaintenanceRoutes 设置维护模式路由（在管理员路由组下）
+func (r *AdminRouter) setupMaintenanceRoutes(admin *middleware.AuthRoutes) {
+	maintenance := admin.Group("/maintenance")
+	write := r.authMiddleware.RequirePermission(rbac.PermMaintenanceWrite)
+	{
+		maintenance.GET("", r.authMiddleware.RequirePermission(rbac.PermMaintenanceRead), r.maintenanceHandler.GetStatus) // 维护状态
+		maintenance.PUT("", write, r.maintenanceHandler.Enable)                                                           // 开启维护模式
+		maintenance.DELETE("", write, r.maintenanceHandler.Disable)                                                       // 关闭维护模式（reason为查询参数）
+	}
+}
+
+// setupTranslationRoutes 设置翻译管理路由（在管理员路由组下）
+func (r *AdminRouter) setupTranslationRoutes(admin *middleware.AuthRoutes) {
+	translations := admin.Group("/translations")
+	read := r.authMiddleware.RequirePermission(rbac.PermTranslationsRead)
+	edit := r.authMiddleware.RequirePermission(rbac.PermTranslationsEdit)
+	{
+		translations.GET("", read, r.translationHandler.ListTranslations)        // 语言的消息和缺失的消息键（language为查询参数）
+		translations.GET("/coverage", read, r.translationHandler.GetCoverage)    // 各语言的翻译覆盖率
+		translations.GET("/overrides", read, r.translationHandler.ListOverrides) // 翻译覆盖列表
+
+		translations.PUT("/overrides/:language/:key", edit, r.translationHandler.SaveOverride)      // 保存翻译覆盖
+		translations.DELETE("/overrides/:language/:key", edit, r.translationHandler.DeleteOverride) // 删除翻译覆盖
+	}
+}
+
+// setupAuditLogRoutes 设置管理员操作审计路由（在管理员路由组下）
+func (r *AdminRouter) setupAuditLogRoutes(admin *middleware.AuthRoutes) {
+	auditLogs := admin.Group("/audit-logs")
+	read := r.authMiddleware.RequirePermission(rbac.PermAuditRead)
+	{
+		auditLogs.GET("", read, r.auditLogHandler.ListLogs)   // 按条件分页查询审计记录
+		auditLogs.GET("/:id", read, r.auditLogHandler.GetLog) // 审计记录详情
+	}
+}
+
 // setupSystemRoutes 设置系统路由（无需认证）
 func (r *AdminRouter) setupSystemRoutes(adminV1 *gin.RouterGroup) {
 	system := adminV1.Group("/system")
+	middleware.GetAuthMatrix().ClassifyGroup(system, middleware.PublicRequirement())
 	{
 		system.GET("/ping", r.pingHandler) // 健康检查
 		system.GET("/info", r.infoHandler) // 系统信息
@@ -83,6 +362,12 @@ func (r *AdminRouter) pingHandler(c *gin.Context) {
 	})
 }
 
+// authMatrixHandler 路由权限矩阵接口
+// 返回所有已注册路由的认证要求，未声明认证要求的路由classified为false
+func (r *AdminRouter) authMatrixHandler(c *gin.Context) {
+	utils.Success(c, middleware.GetAuthMatrix().Build(r.engine.Routes()))
+}
+
 // infoHandler 系统信息接口
 // 返回Admin模块的基本信息
 func (r *AdminRouter) infoHandler(c *gin.Context) {
@@ -94,6 +379,14 @@ func (r *AdminRouter) infoHandler(c *gin.Context) {
 			"admin_login",
 			"admin_dashboard",
 			"user_management",
+			"user_import",
+			"message_automation",
+			"notification_templates",
+			"ip_access_control",
+			"translations",
+			"rbac",
+			"two_factor_auth",
+			"admin_audit_logs",
 		},
 	})
 }
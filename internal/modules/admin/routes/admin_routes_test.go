@@ -0,0 +1,57 @@
+package routes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/middleware"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/rbac"
+)
+
+// newTestRouter 创建只用于注册路由的Admin路由管理器，处理器不会被调用
+func newTestRouter() *AdminRouter {
+	cfg := &config.Config{}
+	return NewAdminRouter(
+		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		middleware.NewAdminAuthMiddleware(nil, cfg),
+		middleware.NewAdminGuardMiddleware(nil, config.AdminGuardConfig{}),
+		middleware.NewAdminAuditMiddleware(nil),
+		middleware.NewRateLimitMiddleware(nil, config.RateLimitConfig{}),
+		middleware.NewIPAccessMiddleware(nil, config.IPAccessConfig{}),
+	)
+}
+
+func TestAuthMatrixCoversAllRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	newTestRouter().SetupRoutes(engine)
+
+	if err := middleware.GetAuthMatrix().Verify(engine.Routes()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		want   middleware.AuthRequirement
+	}{
+		{"POST", "/admin/v1/auth/login", middleware.PublicRequirement()},
+		{"GET", "/admin/v1/admin/2fa", middleware.AdminRequirement()},
+		{"GET", "/admin/v1/admin/dashboard", middleware.PermissionRequirement(rbac.PermDashboardRead)},
+		{"PUT", "/admin/v1/admin/roles/:name", middleware.PermissionRequirement(rbac.PermRolesWrite)},
+		{"DELETE", "/admin/v1/admin/users/:id", middleware.PermissionRequirement(rbac.PermUsersDelete)},
+	}
+	for _, tt := range tests {
+		got, _, ok := middleware.GetAuthMatrix().Lookup(tt.method, tt.path)
+		if !ok {
+			t.Errorf("%s %s: 未声明认证要求", tt.method, tt.path)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %s: got %+v, want %+v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
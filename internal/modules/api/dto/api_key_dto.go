@@ -0,0 +1,117 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/ipaccess"
+)
+
+// maxAPIKeyAllowedIPs 每个key最多允许的IP或CIDR条目数
+const maxAPIKeyAllowedIPs = 20
+
+// CreateAPIKeyRequest 创建API key请求
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"` // 名称，便于区分用途
+	Scopes        []string `json:"scopes" binding:"required,min=1"` // 权限：read（GET、HEAD请求）、write（其他请求）
+	AllowedIPs    []string `json:"allowed_ips"`                     // 允许使用的IP或CIDR，为空表示不限制
+	RateLimit     int      `json:"rate_limit"`                      // 每个窗口的请求数上限，0或超过允许的最大值时使用最大值
+	ExpiresInDays int      `json:"expires_in_days"`                 // 有效期(天)，不能超过api_key_auth.user_key_max_ttl_days
+}
+
+// Validate 验证创建API key请求
+func (r *CreateAPIKeyRequest) Validate() error {
+	if r.RateLimit < 0 || r.ExpiresInDays < 0 {
+		return errors.New("rate_limit and expires_in_days must not be negative")
+	}
+	return validateAPIKeyFields(&r.Name, r.Scopes, r.AllowedIPs)
+}
+
+// UpdateAPIKeyRequest 修改API key请求，密钥、有效期和限流上限不能修改
+type UpdateAPIKeyRequest struct {
+	Name       string   `json:"name" binding:"required,max=100"`
+	Scopes     []string `json:"scopes" binding:"required,min=1"`
+	AllowedIPs []string `json:"allowed_ips"`
+}
+
+// Validate 验证修改API key请求
+func (r *UpdateAPIKeyRequest) Validate() error {
+	return validateAPIKeyFields(&r.Name, r.Scopes, r.AllowedIPs)
+}
+
+// validateAPIKeyFields 验证名称、权限和允许的IP
+func validateAPIKeyFields(name *string, scopes, allowedIPs []string) error {
+	*name = strings.TrimSpace(*name)
+	if *name == "" {
+		return errors.New("name is required")
+	}
+	for _, scope := range scopes {
+		if scope != config.APIKeyPermissionRead && scope != config.APIKeyPermissionWrite {
+			return fmt.Errorf("invalid scope: %s", scope)
+		}
+	}
+	if len(allowedIPs) > maxAPIKeyAllowedIPs {
+		return fmt.Errorf("allowed_ips must not exceed %d entries", maxAPIKeyAllowedIPs)
+	}
+	for i, value := range allowedIPs {
+		prefix, err := ipaccess.ParsePrefix(value)
+		if err != nil {
+			return err
+		}
+		allowedIPs[i] = prefix.String()
+	}
+	return nil
+}
+
+// APIKeyResponse API key信息，不包含密钥
+type APIKeyResponse struct {
+	KeyID         string     `json:"key_id"`
+	Name          string     `json:"name"`
+	Scopes        []string   `json:"scopes"`
+	AllowedIPs    []string   `json:"allowed_ips"`
+	RateLimit     int        `json:"rate_limit"`
+	WindowSeconds int        `json:"window_seconds"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	LastUsedAt    *time.Time `json:"last_used_at"`
+	LastUsedIP    string     `json:"last_used_ip"`
+	CreatedAt     int64      `json:"created_at"`
+}
+
+// NewAPIKeyResponse 创建API key信息响应
+func NewAPIKeyResponse(key *mysql.APIKey) *APIKeyResponse {
+	allowedIPs := key.AllowedIPList()
+	if allowedIPs == nil {
+		allowedIPs = []string{}
+	}
+	return &APIKeyResponse{
+		KeyID:         key.KeyID,
+		Name:          key.Name,
+		Scopes:        key.ScopeList(),
+		AllowedIPs:    allowedIPs,
+		RateLimit:     key.RateLimit,
+		WindowSeconds: key.WindowSeconds,
+		ExpiresAt:     key.ExpiresAt,
+		LastUsedAt:    key.LastUsedAt,
+		LastUsedIP:    key.LastUsedIP,
+		CreatedAt:     key.CreatedAt,
+	}
+}
+
+// NewAPIKeyResponses 创建API key列表响应
+func NewAPIKeyResponses(keys []*mysql.APIKey) []*APIKeyResponse {
+	responses := make([]*APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, NewAPIKeyResponse(key))
+	}
+	return responses
+}
+
+// CreateAPIKeyResponse 创建API key响应，密钥只在此时返回一次
+type CreateAPIKeyResponse struct {
+	*APIKeyResponse
+	Secret string `json:"secret"`
+}
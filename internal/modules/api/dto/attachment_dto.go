@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"time"
+
+	"exchange/internal/models/mongodb"
+)
+
+// AttachmentResponse 已上传的附件，key用于发送文件消息
+type AttachmentResponse struct {
+	Key         string              `json:"key"`
+	FileName    string              `json:"file_name"`
+	FileSize    int64               `json:"file_size"`
+	MimeType    string              `json:"mime_type"`
+	MessageType mongodb.MessageType `json:"message_type"`
+}
+
+// AttachmentLinkResponse 附件的限时下载链接
+type AttachmentLinkResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AttachmentDownloadRequest 下载本地存储的附件请求（签名链接参数）
+type AttachmentDownloadRequest struct {
+	Key       string `form:"key" binding:"required,max=512"`
+	Name      string `form:"name"`
+	Expires   int64  `form:"expires" binding:"required"`
+	Signature string `form:"signature" binding:"required"`
+}
@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"errors"
+	"time"
+
+	"exchange/internal/pkg/export"
+)
+
+// ChatExportRequest 会话导出请求
+type ChatExportRequest struct {
+	PeerID uint       `json:"peer_id" binding:"required"` // 会话对方用户ID
+	Format string     `json:"format" binding:"required"`  // json/html
+	From   *time.Time `json:"from"`                       // 开始时间（RFC3339，可选）
+	To     *time.Time `json:"to"`                         // 结束时间（RFC3339，可选）
+}
+
+// Validate 验证会话导出请求
+func (r *ChatExportRequest) Validate(userID uint) error {
+	if r.PeerID == userID {
+		return errors.New("cannot export a conversation with yourself")
+	}
+	if !export.IsValidFormat(r.Format) {
+		return errors.New("format must be one of json, html")
+	}
+	if r.From != nil && r.To != nil && r.From.After(*r.To) {
+		return errors.New("from must be before to")
+	}
+	return nil
+}
+
+// GetTimeRange 获取导出时间范围，未指定时返回零值
+func (r *ChatExportRequest) GetTimeRange() (from, to time.Time) {
+	if r.From != nil {
+		from = *r.From
+	}
+	if r.To != nil {
+		to = *r.To
+	}
+	return from, to
+}
+
+// ChatExportConsentRequest 设置导出授权请求
+type ChatExportConsentRequest struct {
+	Allow *bool `json:"allow" binding:"required"` // 是否允许会话对方导出包含自己消息内容的记录
+}
+
+// ChatExportConsentResponse 导出授权响应
+type ChatExportConsentResponse struct {
+	Allow bool `json:"allow"`
+}
+
+// ChatExportDownloadRequest 下载导出文件请求（签名链接参数）
+type ChatExportDownloadRequest struct {
+	Expires   int64  `form:"expires" binding:"required"`
+	Signature string `form:"signature" binding:"required"`
+}
@@ -0,0 +1,7 @@
+package dto
+
+// EventExportListRequest 查询领域事件导出分区请求，日期为UTC的YYYY-MM-DD，范围包含两端
+type EventExportListRequest struct {
+	From string `form:"from" binding:"required"`
+	To   string `form:"to" binding:"required"`
+}
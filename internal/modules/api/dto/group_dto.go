@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"exchange/internal/models/mongodb"
+)
+
+// CreateGroupRequest 创建群聊请求
+type CreateGroupRequest struct {
+	Name        string `json:"name" binding:"required,max=64"`
+	Description string `json:"description" binding:"omitempty,max=500"`
+	MemberIDs   []uint `json:"member_ids" binding:"omitempty,max=500"` // 同时加入的其他成员，不含本人
+}
+
+// UpdateGroupRequest 修改群资料请求
+type UpdateGroupRequest struct {
+	Name        string `json:"name" binding:"required,max=64"`
+	Description string `json:"description" binding:"omitempty,max=500"`
+}
+
+// AddGroupMembersRequest 邀请群成员请求
+type AddGroupMembersRequest struct {
+	UserIDs []uint `json:"user_ids" binding:"required,min=1,max=100"`
+}
+
+// SetGroupMemberRoleRequest 设置成员角色请求，owner表示转让群主
+type SetGroupMemberRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner admin member"`
+}
+
+// SendGroupMessageRequest 发送群聊消息请求
+// 发送文件消息时先上传附件，再以attachment_key发送，此时content被忽略
+type SendGroupMessageRequest struct {
+	Content       string `json:"content" binding:"required_without=AttachmentKey,max=5000"`
+	AttachmentKey string `json:"attachment_key" binding:"omitempty,max=512"`
+	FileName      string `json:"file_name" binding:"omitempty,max=255"` // 显示的文件名，一般为上传接口返回的file_name
+}
+
+// MarkGroupReadRequest 更新已读位置请求
+type MarkGroupReadRequest struct {
+	MessageID string `json:"message_id" binding:"required,len=24"` // 最后一条已读消息的ID
+}
+
+// GroupSummaryResponse 群聊列表项
+type GroupSummaryResponse struct {
+	Group       *mongodb.ChatGroup `json:"group"`
+	Role        mongodb.GroupRole  `json:"role"`         // 本人的角色
+	UnreadCount int64              `json:"unread_count"` // 已读位置之后其他成员发送的消息数
+}
+
+// GroupDetailResponse 群资料和成员列表
+type GroupDetailResponse struct {
+	Group   *mongodb.ChatGroup         `json:"group"`
+	Members []*mongodb.ChatGroupMember `json:"members"`
+}
@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"exchange/internal/models/mongodb"
+	wsDto "exchange/internal/modules/websocket/dto"
+)
+
+// MessageHistoryRequest 会话消息查询参数
+type MessageHistoryRequest struct {
+	Cursor string `form:"cursor" binding:"omitempty,max=128"`      // 上一页返回的next_cursor，为空时从最新的消息开始
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"` // 每页消息数，默认20
+}
+
+// MessageHistoryResponse 会话消息响应，消息从新到旧排列
+type MessageHistoryResponse struct {
+	Messages   []*mongodb.ChatMessage `json:"messages"`
+	NextCursor string                 `json:"next_cursor,omitempty"` // 获取更早的消息时传入，没有更多消息时不返回
+	HasMore    bool                   `json:"has_more"`
+}
+
+// EditMessageRequest 修改消息请求
+type EditMessageRequest struct {
+	Content string `json:"content" binding:"required,max=5000"` // 新的消息内容
+}
+
+// MarkMessagesReadRequest 标记已读请求
+type MarkMessagesReadRequest struct {
+	MessageID string `json:"message_id" binding:"required,len=24"` // 对方发来的最后一条已读消息的ID，该消息及之前的消息都标记为已读
+}
+
+// MarkAllReadRequest 批量标记已读请求
+type MarkAllReadRequest struct {
+	PeerIDs []uint `json:"peer_ids" binding:"omitempty,max=100,dive,min=1"` // 对方用户ID，为空时标记所有有未读消息的会话
+}
+
+// ConversationUnreadCount 一个会话的未读数
+type ConversationUnreadCount struct {
+	PeerID      uint  `json:"peer_id"`
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// UnreadCountsResponse 单聊未读数，会话按未读数从多到少排列
+type UnreadCountsResponse struct {
+	Total         int64                     `json:"total"`
+	Conversations []ConversationUnreadCount `json:"conversations"`
+}
+
+// MessagesDeliveredRequest 标记已投递请求（WebSocket网关在接收方确认收到后调用）
+type MessagesDeliveredRequest struct {
+	UserID     uint     `json:"user_id" binding:"required"`                               // 接收方用户ID
+	MessageIDs []string `json:"message_ids" binding:"required,min=1,max=100,dive,len=24"` // 接收方确认收到的消息ID
+}
+
+// MessageReceiptResponse 回执处理结果
+type MessageReceiptResponse struct {
+	Updated int64 `json:"updated"` // 本次状态发生变化的消息数
+}
+
+// PresenceRequest 在线状态查询参数
+type PresenceRequest struct {
+	UserIDs string `form:"user_ids" binding:"required,max=1100"` // 逗号分隔的用户ID，最多100个
+}
+
+// PresenceResponse 在线状态，在任一实例上有WebSocket连接即为在线，按请求中的顺序排列
+type PresenceResponse struct {
+	Users []wsDto.PresenceStatus `json:"users"`
+}
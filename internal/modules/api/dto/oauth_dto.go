@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"exchange/internal/models/mysql"
+)
+
+// OAuthAuthorizeResponse 发起第三方登录响应，前端跳转到authorization_url
+type OAuthAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"` // 服务商回调时原样返回，提交回调时校验
+}
+
+// OAuthCallbackRequest 第三方登录回调请求，前端把服务商回调地址中的code和state提交到服务端
+// Apple以表单POST回调，可直接转发表单
+type OAuthCallbackRequest struct {
+	Code     string `json:"code" form:"code" binding:"required,max=2048"`
+	State    string `json:"state" form:"state" binding:"required,max=128"`
+	TOTPCode string `json:"totp_code" form:"totp_code"` // 两步验证的验证码或备用码，已启用两步验证时必填（可带同一个state重新提交）
+}
+
+// OAuthLoginResponse 第三方登录响应
+type OAuthLoginResponse struct {
+	User    *mysql.PublicUser `json:"user"`
+	Token   string            `json:"token"` // 访问token，与tokens.access_token相同
+	Tokens  *TokenResponse    `json:"tokens"`
+	Created bool              `json:"created"` // 是否为本次登录创建的新用户
+	Linked  bool              `json:"linked"`  // 是否按邮箱关联了已有用户
+}
@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"errors"
+	"time"
+
+	"exchange/internal/models/mysql"
+)
+
+// RegisterPushDeviceRequest 注册推送设备请求，客户端每次启动或token更换时调用
+type RegisterPushDeviceRequest struct {
+	Platform string `json:"platform" binding:"required,oneof=ios android web"`
+	Token    string `json:"token" binding:"required,max=255"` // FCM注册token或APNs设备token
+}
+
+// PushDeviceResponse 推送设备，不返回token
+type PushDeviceResponse struct {
+	ID         uint      `json:"id"`
+	Platform   string    `json:"platform"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  int64     `json:"created_at"`
+}
+
+// NewPushDeviceResponses 创建推送设备列表响应
+func NewPushDeviceResponses(devices []*mysql.PushDevice) []*PushDeviceResponse {
+	responses := make([]*PushDeviceResponse, 0, len(devices))
+	for _, device := range devices {
+		responses = append(responses, NewPushDeviceResponse(device))
+	}
+	return responses
+}
+
+// NewPushDeviceResponse 创建推送设备响应
+func NewPushDeviceResponse(device *mysql.PushDevice) *PushDeviceResponse {
+	return &PushDeviceResponse{
+		ID:         device.ID,
+		Platform:   device.Platform,
+		LastSeenAt: device.LastSeenAt,
+		CreatedAt:  device.CreatedAt,
+	}
+}
+
+// UpdateNotificationPreferencesRequest 修改通知设置请求，未提供的字段保持不变
+type UpdateNotificationPreferencesRequest struct {
+	PushEnabled    *bool   `json:"push_enabled"`
+	DirectMessages *bool   `json:"direct_messages"`
+	GroupMessages  *bool   `json:"group_messages"`
+	ShowPreview    *bool   `json:"show_preview"`
+	QuietStart     *string `json:"quiet_start"` // HH:MM，与quiet_end同时提供，都为空字符串时关闭免打扰
+	QuietEnd       *string `json:"quiet_end"`
+	Timezone       *string `json:"timezone"` // IANA时区，如Asia/Shanghai
+}
+
+// Validate 验证修改通知设置请求，时间格式和时区在保存时校验
+func (r *UpdateNotificationPreferencesRequest) Validate() error {
+	if (r.QuietStart == nil) != (r.QuietEnd == nil) {
+		return errors.New("quiet_start and quiet_end must be provided together")
+	}
+	return nil
+}
+
+// NotificationPreferencesResponse 通知设置
+type NotificationPreferencesResponse struct {
+	PushEnabled    bool   `json:"push_enabled"`
+	DirectMessages bool   `json:"direct_messages"`
+	GroupMessages  bool   `json:"group_messages"`
+	ShowPreview    bool   `json:"show_preview"`
+	QuietStart     string `json:"quiet_start"`
+	QuietEnd       string `json:"quiet_end"`
+	Timezone       string `json:"timezone"`
+}
+
+// NewNotificationPreferencesResponse 创建通知设置响应
+func NewNotificationPreferencesResponse(pref *mysql.NotificationPreference) *NotificationPreferencesResponse {
+	return &NotificationPreferencesResponse{
+		PushEnabled:    pref.PushEnabled,
+		DirectMessages: pref.DirectMessages,
+		GroupMessages:  pref.GroupMessages,
+		ShowPreview:    pref.ShowPreview,
+		QuietStart:     pref.QuietStart,
+		QuietEnd:       pref.QuietEnd,
+		Timezone:       pref.Timezone,
+	}
+}
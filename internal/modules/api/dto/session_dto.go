@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"time"
+
+	"exchange/internal/pkg/session"
+)
+
+// SessionResponse 登录会话（设备）信息
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"` // 是否为发起本次请求的会话
+}
+
+// NewSessionResponses 构建会话列表响应，currentSessionID为当前请求的会话ID
+func NewSessionResponses(sessions []*session.Session, currentSessionID string) []*SessionResponse {
+	responses := make([]*SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		responses = append(responses, &SessionResponse{
+			ID:         s.ID,
+			IP:         s.IP,
+			UserAgent:  s.UserAgent,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			Current:    s.ID == currentSessionID,
+		})
+	}
+	return responses
+}
+
+// RevokeSessionsResponse 批量撤销会话响应
+type RevokeSessionsResponse struct {
+	Revoked int `json:"revoked"` // 撤销的会话数
+}
@@ -2,10 +2,12 @@ package dto
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
 	"strings"
 
 	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/i18n"
 )
 
 // RegisterRequest 用户注册请求
@@ -60,20 +62,37 @@ func (r *RegisterRequest) Validate() error {
 
 // RegisterResponse 用户注册响应
 type RegisterResponse struct {
-	User  *mysql.PublicUser `json:"user"`
-	Token string            `json:"token"`
+	User   *mysql.PublicUser `json:"user"`
+	Token  string            `json:"token"` // 访问token，与tokens.access_token相同
+	Tokens *TokenResponse    `json:"tokens"`
 }
 
 // LoginRequest 用户登录请求
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	TOTPCode string `json:"totp_code"` // 两步验证的验证码或备用码，已启用两步验证时必填
 }
 
 // LoginResponse 用户登录响应
 type LoginResponse struct {
-	User  *mysql.PublicUser `json:"user"`
-	Token string            `json:"token"`
+	User   *mysql.PublicUser `json:"user"`
+	Token  string            `json:"token"` // 访问token，与tokens.access_token相同
+	Tokens *TokenResponse    `json:"tokens"`
+}
+
+// TokenResponse 访问token和刷新token
+// 访问token过期后使用刷新token换取新的一对token，刷新token每次使用后失效
+type TokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`         // 访问token有效期(秒)
+	RefreshExpiresIn int64  `json:"refresh_expires_in"` // 刷新token有效期(秒)
+}
+
+// RefreshTokenRequest 刷新token请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 // UpdateProfileRequest 更新用户资料请求
@@ -142,3 +161,128 @@ func (r *ChangePasswordRequest) Validate() error {
 
 	return nil
 }
+
+// AcceptInviteRequest 接受邀请请求
+type AcceptInviteRequest struct {
+	Token    string `json:"token" binding:"required"`    // 邀请链接中的token
+	Password string `json:"password" binding:"required"` // 设置的登录密码
+}
+
+// Validate 验证接受邀请请求
+func (r *AcceptInviteRequest) Validate() error {
+	if r.Token == "" {
+		return errors.New("token is required")
+	}
+
+	if len(r.Password) < 6 {
+		return errors.New("password must be at least 6 characters long")
+	}
+	if len(r.Password) > 128 {
+		return errors.New("password must be less than 128 characters")
+	}
+
+	// 检查是否包含至少一个字母和一个数字
+	hasLetter := regexp.MustCompile(`[a-zA-Z]`).MatchString(r.Password)
+	hasNumber := regexp.MustCompile(`[0-9]`).MatchString(r.Password)
+
+	if !hasLetter || !hasNumber {
+		return errors.New("password must contain at least one letter and one number")
+	}
+
+	return nil
+}
+
+// ForgotPasswordRequest 找回密码请求
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"` // 注册邮箱
+}
+
+// ResetPasswordRequest 重置密码请求
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`    // 重置密码邮件链接中的token
+	Password string `json:"password" binding:"required"` // 新密码
+}
+
+// Validate 验证重置密码请求
+func (r *ResetPasswordRequest) Validate() error {
+	if len(r.Password) < 6 {
+		return errors.New("password must be at least 6 characters long")
+	}
+	if len(r.Password) > 128 {
+		return errors.New("password must be less than 128 characters")
+	}
+
+	// 检查是否包含至少一个字母和一个数字
+	hasLetter := regexp.MustCompile(`[a-zA-Z]`).MatchString(r.Password)
+	hasNumber := regexp.MustCompile(`[0-9]`).MatchString(r.Password)
+
+	if !hasLetter || !hasNumber {
+		return errors.New("password must contain at least one letter and one number")
+	}
+
+	return nil
+}
+
+// VerifyEmailRequest 验证邮箱请求
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"` // 验证邮件链接中的token
+}
+
+// ConfirmLoginRequest 确认新设备登录请求
+type ConfirmLoginRequest struct {
+	Token string `json:"token" binding:"required"` // 确认登录邮件链接中的token
+}
+
+// AccountDeletionRequest 账户注销申请请求
+type AccountDeletionRequest struct {
+	Password string `json:"password" binding:"required"` // 当前密码
+	Reason   string `json:"reason"`                      // 注销原因（可选）
+}
+
+// Validate 验证账户注销申请请求
+func (r *AccountDeletionRequest) Validate() error {
+	if r.Password == "" {
+		return errors.New("password is required")
+	}
+	if len(r.Reason) > 500 {
+		return errors.New("reason must be less than 500 characters")
+	}
+	return nil
+}
+
+// SetLanguageRequest 设置首选语言请求
+type SetLanguageRequest struct {
+	Language string `json:"language"` // 支持的语言代码，为空时清除（按请求头选择语言）
+}
+
+// Validate 验证设置首选语言请求
+func (r *SetLanguageRequest) Validate() error {
+	if r.Language != "" && !i18n.GetGlobalI18n().IsSupported(r.Language) {
+		return fmt.Errorf("unsupported language, supported: %s", strings.Join(i18n.GetGlobalI18n().GetSupportedLanguages(), ", "))
+	}
+	return nil
+}
+
+// AccountDeletionResponse 账户注销申请响应
+type AccountDeletionResponse struct {
+	ID           uint                        `json:"id"`
+	Status       mysql.AccountDeletionStatus `json:"status"`
+	Reason       string                      `json:"reason"`
+	ScheduledAt  int64                       `json:"scheduled_at"`
+	CompletedAt  int64                       `json:"completed_at,omitempty"`
+	RejectReason string                      `json:"reject_reason,omitempty"`
+	CreatedAt    int64                       `json:"created_at"`
+}
+
+// NewAccountDeletionResponse 转换账户注销申请响应
+func NewAccountDeletionResponse(req *mysql.AccountDeletionRequest) *AccountDeletionResponse {
+	return &AccountDeletionResponse{
+		ID:           req.ID,
+		Status:       req.Status,
+		Reason:       req.Reason,
+		ScheduledAt:  req.ScheduledAt,
+		CompletedAt:  req.CompletedAt,
+		RejectReason: req.RejectReason,
+		CreatedAt:    req.CreatedAt,
+	}
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/middleware"
+	"exchange/internal/modules/api/dto"
+	"exchange/internal/modules/api/logic"
+	"exchange/internal/utils"
+)
+
+// APIKeyHandler API key处理器 - 用户创建、查看、修改和删除供程序化客户端使用的API key
+// 以API key认证的请求不能管理API key，避免泄露的key被用来创建新key或放宽限制
+type APIKeyHandler struct {
+	keyLogic logic.APIKeyLogic
+}
+
+// NewAPIKeyHandler 创建API key处理器
+func NewAPIKeyHandler(keyLogic logic.APIKeyLogic) *APIKeyHandler {
+	return &APIKeyHandler{
+		keyLogic: keyLogic,
+	}
+}
+
+// ListKeys 本人的API key列表
+func (h *APIKeyHandler) ListKeys(c *gin.Context) {
+	userID, ok := h.requireLoginUser(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.keyLogic.ListKeys(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponse(c, "api_key_list_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.Success(c, dto.NewAPIKeyResponses(keys))
+}
+
+// CreateKey 创建API key，响应中的密钥只返回这一次
+func (h *APIKeyHandler) CreateKey(c *gin.Context) {
+	userID, ok := h.requireLoginUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	key, secret, err := h.keyLogic.CreateKey(c.Request.Context(), userID, logic.APIKeyInput{
+		Name:          req.Name,
+		Scopes:        req.Scopes,
+		AllowedIPs:    req.AllowedIPs,
+		RateLimit:     req.RateLimit,
+		ExpiresInDays: req.ExpiresInDays,
+	})
+	if err != nil {
+		apiKeyErrorResponse(c, err)
+		return
+	}
+
+	response := dto.CreateAPIKeyResponse{
+		APIKeyResponse: dto.NewAPIKeyResponse(key),
+		Secret:         secret,
+	}
+	utils.SuccessWithMessage(c, "api_key_created", response, nil)
+}
+
+// UpdateKey 修改API key的名称、权限和允许的IP
+func (h *APIKeyHandler) UpdateKey(c *gin.Context) {
+	userID, ok := h.requireLoginUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	key, err := h.keyLogic.UpdateKey(c.Request.Context(), userID, c.Param("id"), logic.APIKeyInput{
+		Name:       req.Name,
+		Scopes:     req.Scopes,
+		AllowedIPs: req.AllowedIPs,
+	})
+	if err != nil {
+		apiKeyErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "api_key_updated", dto.NewAPIKeyResponse(key), nil)
+}
+
+// DeleteKey 删除API key，使用该key的请求立即失效
+func (h *APIKeyHandler) DeleteKey(c *gin.Context) {
+	userID, ok := h.requireLoginUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.keyLogic.DeleteKey(c.Request.Context(), userID, c.Param("id")); err != nil {
+		apiKeyErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "api_key_deleted", nil, nil)
+}
+
+// requireLoginUser 获取登录用户，以API key认证的请求返回403
+func (h *APIKeyHandler) requireLoginUser(c *gin.Context) (uint, bool) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return 0, false
+	}
+	if middleware.GetAPIKeyID(c) != "" {
+		utils.Forbidden(c, "api_key_management_forbidden", nil)
+		return 0, false
+	}
+	return userID, true
+}
+
+// apiKeyErrorResponse API key管理错误响应
+func apiKeyErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, logic.ErrAPIKeyNotFound):
+		utils.ErrorResponse(c, "api_key_not_found", nil)
+	case errors.Is(err, logic.ErrAPIKeyLimitReached):
+		utils.ErrorResponse(c, "api_key_limit_reached", nil)
+	case errors.Is(err, logic.ErrAPIKeyAuthDisabled):
+		utils.ErrorResponse(c, "api_key_auth_disabled", nil)
+	case errors.Is(err, logic.ErrInvalidAPIKeyExpiry):
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+	default:
+		utils.ErrorResponse(c, "api_key_operation_failed", map[string]interface{}{"error": err.Error()})
+	}
+}
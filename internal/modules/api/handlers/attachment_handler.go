@@ -0,0 +1,134 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/modules/api/dto"
+	"exchange/internal/modules/api/logic"
+	"exchange/internal/pkg/objectstore"
+	"exchange/internal/utils"
+)
+
+// AttachmentHandler 聊天附件处理器 - 上传附件、获取消息附件的下载链接和本地存储的签名链接下载
+type AttachmentHandler struct {
+	attachmentLogic logic.AttachmentLogic
+}
+
+// NewAttachmentHandler 创建聊天附件处理器
+func NewAttachmentHandler(attachmentLogic logic.AttachmentLogic) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentLogic: attachmentLogic,
+	}
+}
+
+// Upload 上传附件（multipart表单的file字段），返回的key用于发送文件消息
+func (h *AttachmentHandler) Upload(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": "file is required"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.ErrorResponse(c, "file_upload_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.attachmentLogic.Upload(c.Request.Context(), userID, fileHeader.Filename, fileHeader.Size, file)
+	if err != nil {
+		attachmentErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "attachment_uploaded", toAttachmentResponse(attachment), nil)
+}
+
+// GetDownloadURL 获取消息附件的限时下载链接
+func (h *AttachmentHandler) GetDownloadURL(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	link, err := h.attachmentLogic.DownloadURL(c.Request.Context(), userID, c.Param("message_id"))
+	if err != nil {
+		attachmentErrorResponse(c, err)
+		return
+	}
+
+	utils.Success(c, dto.AttachmentLinkResponse{URL: link.URL, ExpiresAt: link.ExpiresAt})
+}
+
+// Download 通过签名链接下载本地存储的附件（无需登录，签名和有效期校验通过即可下载）
+func (h *AttachmentHandler) Download(c *gin.Context) {
+	var req dto.AttachmentDownloadRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	file, err := h.attachmentLogic.OpenLocalDownload(c.Request.Context(), req.Key, req.Name, req.Expires, req.Signature)
+	if err != nil {
+		attachmentErrorResponse(c, err)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		utils.ErrorResponseFromError(c, "attachment_failed", err)
+		return
+	}
+
+	if req.Name != "" {
+		c.Header("Content-Disposition", objectstore.ContentDisposition(req.Name))
+	}
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, no-store")
+	http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), file)
+}
+
+// toAttachmentResponse 已上传附件的响应
+func toAttachmentResponse(attachment *logic.Attachment) dto.AttachmentResponse {
+	return dto.AttachmentResponse{
+		Key:         attachment.Key,
+		FileName:    attachment.FileName,
+		FileSize:    attachment.FileSize,
+		MimeType:    attachment.MimeType,
+		MessageType: attachment.MessageType,
+	}
+}
+
+// attachmentErrorResponse 附件操作失败的响应
+func attachmentErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, logic.ErrAttachmentEmpty):
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": err.Error()})
+	case errors.Is(err, logic.ErrAttachmentTooLarge):
+		utils.ErrorResponse(c, "file_too_large", nil)
+	case errors.Is(err, logic.ErrAttachmentTypeNotAllowed):
+		utils.ErrorResponse(c, "attachment_type_not_allowed", nil)
+	case errors.Is(err, logic.ErrAttachmentNotFound):
+		utils.ErrorWithNotFund(c, "attachment_not_found", nil)
+	case errors.Is(err, logic.ErrMessageNotFound):
+		utils.ErrorWithNotFund(c, "message_not_found", nil)
+	case errors.Is(err, objectstore.ErrLinkExpired):
+		utils.ErrorResponse(c, "attachment_link_expired", nil)
+	case errors.Is(err, objectstore.ErrLinkSignature):
+		utils.ErrorResponse(c, "attachment_link_invalid", nil)
+	default:
+		utils.ErrorResponseFromError(c, "attachment_failed", err)
+	}
+}
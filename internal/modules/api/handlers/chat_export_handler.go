@@ -0,0 +1,170 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/modules/api/dto"
+	"exchange/internal/modules/api/logic"
+	"exchange/internal/pkg/export"
+	"exchange/internal/utils"
+)
+
+// ChatExportHandler 会话导出处理器 - 处理导出申请、进度查询、导出授权和签名链接下载
+type ChatExportHandler struct {
+	exportLogic logic.ChatExportLogic // 会话导出业务逻辑
+}
+
+// NewChatExportHandler 创建会话导出处理器
+func NewChatExportHandler(exportLogic logic.ChatExportLogic) *ChatExportHandler {
+	return &ChatExportHandler{
+		exportLogic: exportLogic,
+	}
+}
+
+// RequestExport 申请导出会话记录
+// 导出任务在后台生成，通过GetExport查询进度，完成后返回限时下载链接
+func (h *ChatExportHandler) RequestExport(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.ChatExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(userID); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	from, to := req.GetTimeRange()
+	job, err := h.exportLogic.RequestExport(c.Request.Context(), userID, req.PeerID, req.Format, from, to)
+	if err != nil {
+		if errors.Is(err, logic.ErrChatExportInProgress) {
+			utils.ErrorResponse(c, "chat_export_in_progress", map[string]interface{}{"error": err.Error()})
+			return
+		}
+		utils.ErrorResponseFromError(c, "chat_export_failed", err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "chat_export_started", job, nil)
+}
+
+// GetExport 获取导出任务详情
+func (h *ChatExportHandler) GetExport(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || jobID == 0 {
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": "invalid id"})
+		return
+	}
+
+	info, err := h.exportLogic.GetExport(c.Request.Context(), userID, uint(jobID))
+	if err != nil {
+		if errors.Is(err, logic.ErrChatExportNotFound) {
+			utils.ErrorResponse(c, "chat_export_not_found", map[string]interface{}{"error": err.Error()})
+			return
+		}
+		utils.ErrorResponseFromError(c, "chat_export_failed", err)
+		return
+	}
+
+	utils.Success(c, info)
+}
+
+// GetConsent 获取导出授权
+func (h *ChatExportHandler) GetConsent(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	allow, err := h.exportLogic.GetConsent(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponseFromError(c, "chat_export_consent_failed", err)
+		return
+	}
+
+	utils.Success(c, dto.ChatExportConsentResponse{Allow: allow})
+}
+
+// SetConsent 设置是否允许会话对方导出包含自己消息内容的记录
+func (h *ChatExportHandler) SetConsent(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.ChatExportConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := h.exportLogic.SetConsent(c.Request.Context(), userID, *req.Allow); err != nil {
+		utils.ErrorResponseFromError(c, "chat_export_consent_failed", err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "chat_export_consent_updated", dto.ChatExportConsentResponse{Allow: *req.Allow}, nil)
+}
+
+// Download 通过签名链接下载导出文件（无需登录，链接即凭证）
+func (h *ChatExportHandler) Download(c *gin.Context) {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || jobID == 0 {
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": "invalid id"})
+		return
+	}
+
+	var req dto.ChatExportDownloadRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	job, file, err := h.exportLogic.OpenDownload(c.Request.Context(), uint(jobID), req.Expires, req.Signature)
+	if err != nil {
+		switch {
+		case errors.Is(err, export.ErrLinkExpired):
+			utils.ErrorResponse(c, "chat_export_link_expired", nil)
+		case errors.Is(err, export.ErrLinkSignature):
+			utils.ErrorResponse(c, "chat_export_link_invalid", nil)
+		case errors.Is(err, logic.ErrChatExportUnavailable):
+			utils.ErrorResponse(c, "chat_export_not_found", map[string]interface{}{"error": err.Error()})
+		default:
+			utils.ErrorResponseFromError(c, "chat_export_failed", err)
+		}
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		utils.ErrorResponseFromError(c, "chat_export_failed", err)
+		return
+	}
+
+	fileName := fmt.Sprintf("chat_%d_%d.%s", job.UserID, job.PeerID, job.Format)
+	c.Header("Content-Type", export.ContentType(job.Format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Header("Cache-Control", "private, no-store")
+	http.ServeContent(c.Writer, c.Request, fileName, info.ModTime(), file)
+}
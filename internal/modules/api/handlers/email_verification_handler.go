@@ -0,0 +1,69 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/modules/api/dto"
+	"exchange/internal/modules/api/logic"
+	"exchange/internal/pkg/emailverify"
+	"exchange/internal/utils"
+)
+
+// EmailVerificationHandler 邮箱验证处理器 - 重新发送验证邮件和使用邮件中的token完成验证
+type EmailVerificationHandler struct {
+	verificationLogic logic.EmailVerificationLogic
+}
+
+// NewEmailVerificationHandler 创建邮箱验证处理器
+func NewEmailVerificationHandler(verificationLogic logic.EmailVerificationLogic) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		verificationLogic: verificationLogic,
+	}
+}
+
+// SendVerification 重新发送验证邮件，之前发送的验证链接随即失效
+func (h *EmailVerificationHandler) SendVerification(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	err := h.verificationLogic.SendVerification(c.Request.Context(), userID)
+	switch {
+	case errors.Is(err, emailverify.ErrAlreadyVerified):
+		utils.ErrorResponse(c, "email_already_verified", nil)
+		return
+	case errors.Is(err, emailverify.ErrTooManyRequests):
+		utils.TooManyRequests(c, "too_many_verification_emails", nil)
+		return
+	case err != nil:
+		utils.ErrorResponseFromError(c, "email_verification_failed", err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "email_verification_sent", nil, nil)
+}
+
+// VerifyEmail 使用验证token完成邮箱验证，无需登录（在邮件链接打开的页面中调用）
+func (h *EmailVerificationHandler) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	err := h.verificationLogic.VerifyEmail(c.Request.Context(), req.Token)
+	if errors.Is(err, emailverify.ErrInvalidToken) {
+		utils.ErrorResponse(c, "invalid_email_verification_token", nil)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponseFromError(c, "email_verification_failed", err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "email_verified", nil, nil)
+}
@@ -0,0 +1,342 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/models/mongodb"
+	"exchange/internal/modules/api/dto"
+	"exchange/internal/modules/api/logic"
+	"exchange/internal/utils"
+)
+
+// GroupHandler 群聊处理器 - 创建和管理群聊、成员和角色，收发群聊消息和更新已读位置
+type GroupHandler struct {
+	groupLogic      logic.GroupLogic
+	attachmentLogic logic.AttachmentLogic // 发送文件消息时获取已上传的附件
+}
+
+// NewGroupHandler 创建群聊处理器
+func NewGroupHandler(groupLogic logic.GroupLogic, attachmentLogic logic.AttachmentLogic) *GroupHandler {
+	return &GroupHandler{
+		groupLogic:      groupLogic,
+		attachmentLogic: attachmentLogic,
+	}
+}
+
+// CreateGroup 创建群聊，本人为群主
+func (h *GroupHandler) CreateGroup(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.CreateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	detail, err := h.groupLogic.CreateGroup(c.Request.Context(), userID, req.Name, req.Description, req.MemberIDs)
+	if err != nil {
+		groupErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "group_created", toGroupDetailResponse(detail), nil)
+}
+
+// ListGroups 本人加入的群聊及未读消息数
+func (h *GroupHandler) ListGroups(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	summaries, err := h.groupLogic.ListGroups(c.Request.Context(), userID)
+	if err != nil {
+		groupErrorResponse(c, err)
+		return
+	}
+
+	groups := make([]dto.GroupSummaryResponse, 0, len(summaries))
+	for _, summary := range summaries {
+		groups = append(groups, dto.GroupSummaryResponse{
+			Group:       summary.Group,
+			Role:        summary.Role,
+			UnreadCount: summary.UnreadCount,
+		})
+	}
+	utils.Success(c, groups)
+}
+
+// GetGroup 群资料和成员列表
+func (h *GroupHandler) GetGroup(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	detail, err := h.groupLogic.GetGroup(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		groupErrorResponse(c, err)
+		return
+	}
+
+	utils.Success(c, toGroupDetailResponse(detail))
+}
+
+// UpdateGroup 修改群名称和简介（群主和管理员）
+func (h *GroupHandler) UpdateGroup(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.UpdateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	group, err := h.groupLogic.UpdateGroup(c.Request.Context(), userID, c.Param("id"), req.Name, req.Description)
+	if err != nil {
+		groupErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "group_updated", group, nil)
+}
+
+// DeleteGroup 解散群聊（群主）
+func (h *GroupHandler) DeleteGroup(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	if err := h.groupLogic.DeleteGroup(c.Request.Context(), userID, c.Param("id")); err != nil {
+		groupErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "group_deleted", nil, nil)
+}
+
+// AddMembers 邀请用户加入群聊（群主和管理员）
+func (h *GroupHandler) AddMembers(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.AddGroupMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	detail, err := h.groupLogic.AddMembers(c.Request.Context(), userID, c.Param("id"), req.UserIDs)
+	if err != nil {
+		groupErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "group_members_added", toGroupDetailResponse(detail), nil)
+}
+
+// RemoveMember 移除成员，user_id为本人时表示退出群聊
+func (h *GroupHandler) RemoveMember(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	memberID, ok := parseMemberID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.groupLogic.RemoveMember(c.Request.Context(), userID, c.Param("id"), memberID); err != nil {
+		groupErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "group_member_removed", nil, nil)
+}
+
+// SetMemberRole 设置成员角色（群主），role为owner时转让群主
+func (h *GroupHandler) SetMemberRole(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	memberID, ok := parseMemberID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SetGroupMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := h.groupLogic.SetMemberRole(c.Request.Context(), userID, c.Param("id"), memberID, mongodb.GroupRole(req.Role)); err != nil {
+		groupErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "group_member_role_updated", nil, nil)
+}
+
+// SendMessage 发送群聊消息，带attachment_key时发送文件消息
+func (h *GroupHandler) SendMessage(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.SendGroupMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if req.AttachmentKey != "" {
+		attachment, err := h.attachmentLogic.Resolve(c.Request.Context(), userID, req.AttachmentKey, req.FileName)
+		if err != nil {
+			attachmentErrorResponse(c, err)
+			return
+		}
+		message, err := h.groupLogic.SendFileMessage(c.Request.Context(), userID, c.Param("id"), attachment)
+		if err != nil {
+			groupErrorResponse(c, err)
+			return
+		}
+		utils.Success(c, message)
+		return
+	}
+
+	message, err := h.groupLogic.SendMessage(c.Request.Context(), userID, c.Param("id"), req.Content)
+	if err != nil {
+		groupErrorResponse(c, err)
+		return
+	}
+
+	utils.Success(c, message)
+}
+
+// ListMessages 群聊消息，从新到旧排列，通过next_cursor获取更早的消息
+func (h *GroupHandler) ListMessages(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.MessageHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	page, err := h.groupLogic.ListMessages(c.Request.Context(), userID, c.Param("id"), req.Cursor, req.Limit)
+	if err != nil {
+		groupErrorResponse(c, err)
+		return
+	}
+
+	messages := page.Messages
+	if messages == nil {
+		messages = []*mongodb.ChatMessage{}
+	}
+	utils.Success(c, dto.MessageHistoryResponse{
+		Messages:   messages,
+		NextCursor: page.NextCursor,
+		HasMore:    page.NextCursor != "",
+	})
+}
+
+// MarkRead 更新本人的已读位置
+func (h *GroupHandler) MarkRead(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.MarkGroupReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := h.groupLogic.MarkRead(c.Request.Context(), userID, c.Param("id"), req.MessageID); err != nil {
+		groupErrorResponse(c, err)
+		return
+	}
+
+	utils.Success(c, nil)
+}
+
+// parseMemberID 解析路径中的成员用户ID，无效时写入错误响应
+func parseMemberID(c *gin.Context) (uint, bool) {
+	memberID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil || memberID == 0 {
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": "invalid user id"})
+		return 0, false
+	}
+	return uint(memberID), true
+}
+
+// toGroupDetailResponse 群资料和成员列表响应
+func toGroupDetailResponse(detail *logic.GroupDetail) dto.GroupDetailResponse {
+	members := detail.Members
+	if members == nil {
+		members = []*mongodb.ChatGroupMember{}
+	}
+	return dto.GroupDetailResponse{Group: detail.Group, Members: members}
+}
+
+// groupErrorResponse 群聊操作失败的响应
+func groupErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, logic.ErrGroupNotFound):
+		utils.ErrorWithNotFund(c, "group_not_found", nil)
+	case errors.Is(err, logic.ErrGroupMemberNotFound):
+		utils.ErrorWithNotFund(c, "group_member_not_found", nil)
+	case errors.Is(err, logic.ErrMessageNotFound):
+		utils.ErrorWithNotFund(c, "message_not_found", nil)
+	case errors.Is(err, logic.ErrGroupPermissionDenied):
+		utils.Forbidden(c, "group_permission_denied", nil)
+	case errors.Is(err, logic.ErrGroupNameRequired):
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+	case errors.Is(err, logic.ErrGroupFull):
+		utils.ErrorResponse(c, "group_full", nil)
+	case errors.Is(err, logic.ErrGroupLimitReached):
+		utils.ErrorResponse(c, "group_limit_reached", nil)
+	case errors.Is(err, logic.ErrGroupOwnerCannotLeave):
+		utils.ErrorResponse(c, "group_owner_cannot_leave", nil)
+	case errors.Is(err, logic.ErrInvalidGroupRole):
+		utils.ErrorResponse(c, "invalid_group_role", nil)
+	case errors.Is(err, logic.ErrInvalidMessageCursor):
+		utils.ErrorResponse(c, "invalid_message_cursor", nil)
+	case errors.Is(err, logic.ErrMessageContentInvalid):
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+	default:
+		utils.ErrorResponseFromError(c, "group_operation_failed", err)
+	}
+}
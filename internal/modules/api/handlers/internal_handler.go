@@ -0,0 +1,115 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/modules/api/dto"
+	"exchange/internal/modules/api/logic"
+	"exchange/internal/pkg/eventlog"
+	"exchange/internal/utils"
+)
+
+// InternalHandler 内部服务接口处理器（请求由调用方服务签名，不使用用户JWT）
+type InternalHandler struct {
+	userLogic        logic.UserLogic
+	eventExportLogic logic.EventExportLogic
+	cacheStatsLogic  logic.CacheStatsLogic
+}
+
+// NewInternalHandler 创建内部服务接口处理器
+func NewInternalHandler(userLogic logic.UserLogic, eventExportLogic logic.EventExportLogic, cacheStatsLogic logic.CacheStatsLogic) *InternalHandler {
+	return &InternalHandler{
+		userLogic:        userLogic,
+		eventExportLogic: eventExportLogic,
+		cacheStatsLogic:  cacheStatsLogic,
+	}
+}
+
+// GetUser 获取用户公开信息
+func (h *InternalHandler) GetUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || userID == 0 {
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": "invalid id"})
+		return
+	}
+
+	user, err := h.userLogic.GetUserByID(c.Request.Context(), uint(userID))
+	if err != nil {
+		utils.ErrorResponseFromError(c, "user_not_found", err)
+		return
+	}
+
+	utils.Success(c, user.ToPublicUser())
+}
+
+// ListEventExports 查询日期范围内已导出的领域事件分区manifest
+func (h *InternalHandler) ListEventExports(c *gin.Context) {
+	var req dto.EventExportListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	manifests, err := h.eventExportLogic.ListManifests(c.Request.Context(), req.From, req.To)
+	if err != nil {
+		h.eventExportError(c, err)
+		return
+	}
+
+	utils.Success(c, manifests)
+}
+
+// GetEventExport 获取领域事件分区manifest
+func (h *InternalHandler) GetEventExport(c *gin.Context) {
+	manifest, err := h.eventExportLogic.GetManifest(c.Request.Context(), c.Param("date"))
+	if err != nil {
+		h.eventExportError(c, err)
+		return
+	}
+
+	utils.Success(c, manifest)
+}
+
+// DownloadEventExport 下载领域事件分区数据文件（gzip压缩的NDJSON）
+func (h *InternalHandler) DownloadEventExport(c *gin.Context) {
+	name := c.Param("name")
+	file, err := h.eventExportLogic.OpenFile(c.Request.Context(), c.Param("date"), name)
+	if err != nil {
+		h.eventExportError(c, err)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		utils.ErrorResponseFromError(c, "event_export_failed", err)
+		return
+	}
+
+	c.Header("Content-Type", "application/gzip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+	c.Header("Cache-Control", "private, no-store")
+	http.ServeContent(c.Writer, c.Request, name, info.ModTime(), file)
+}
+
+// GetCacheStats 获取本实例的缓存统计
+func (h *InternalHandler) GetCacheStats(c *gin.Context) {
+	utils.Success(c, h.cacheStatsLogic.GetStats(c.Request.Context()))
+}
+
+// eventExportError 领域事件导出查询的错误响应
+func (h *InternalHandler) eventExportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, logic.ErrEventExportDateInvalid), errors.Is(err, logic.ErrEventExportRangeTooBig):
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": err.Error()})
+	case errors.Is(err, eventlog.ErrManifestNotFound), errors.Is(err, eventlog.ErrFileNotInManifest):
+		utils.ErrorResponse(c, "event_export_not_found", nil)
+	default:
+		utils.ErrorResponseFromError(c, "event_export_failed", err)
+	}
+}
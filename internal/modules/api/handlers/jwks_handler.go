@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/pkg/jwtkeys"
+)
+
+// jwksMaxAge JWKS响应的缓存时间(秒)，轮换时新key需提前发布，至少早于切换签发key这么长时间
+const jwksMaxAge = 300
+
+// JWKSHandler JWT公钥处理器 - 发布验证token的公钥，供其他服务在本地验证用户和管理员token
+type JWKSHandler struct {
+	keys *jwtkeys.Keyset
+}
+
+// NewJWKSHandler 创建JWT公钥处理器
+func NewJWKSHandler(keys *jwtkeys.Keyset) *JWKSHandler {
+	return &JWKSHandler{
+		keys: keys,
+	}
+}
+
+// GetJWKS 返回当前可用于验证的公钥（RFC 7517格式，不使用统一响应结构），HS256时为空集合
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(jwksMaxAge))
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
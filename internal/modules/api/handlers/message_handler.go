@@ -0,0 +1,230 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/models/mongodb"
+	"exchange/internal/modules/api/dto"
+	"exchange/internal/modules/api/logic"
+	"exchange/internal/utils"
+)
+
+// MessageHandler 会话消息处理器 - 按游标分页查询与其他用户的历史消息，修改和删除本人发送的消息，处理投递和已读回执，查询未读数
+type MessageHandler struct {
+	messageLogic logic.MessageLogic
+}
+
+// NewMessageHandler 创建会话消息处理器
+func NewMessageHandler(messageLogic logic.MessageLogic) *MessageHandler {
+	return &MessageHandler{
+		messageLogic: messageLogic,
+	}
+}
+
+// ListConversation 获取与对方的会话消息，从新到旧排列，通过next_cursor获取更早的消息
+func (h *MessageHandler) ListConversation(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	peerID, err := strconv.ParseUint(c.Param("peer_id"), 10, 64)
+	if err != nil || peerID == 0 || uint(peerID) == userID {
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": "invalid peer id"})
+		return
+	}
+
+	var req dto.MessageHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	page, err := h.messageLogic.ListConversation(c.Request.Context(), userID, uint(peerID), req.Cursor, req.Limit)
+	if errors.Is(err, logic.ErrInvalidMessageCursor) {
+		utils.ErrorResponse(c, "invalid_message_cursor", nil)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponseFromError(c, "message_history_failed", err)
+		return
+	}
+
+	messages := page.Messages
+	if messages == nil {
+		messages = []*mongodb.ChatMessage{}
+	}
+	utils.Success(c, dto.MessageHistoryResponse{
+		Messages:   messages,
+		NextCursor: page.NextCursor,
+		HasMore:    page.NextCursor != "",
+	})
+}
+
+// EditMessage 修改本人发送的文本消息，被替换的内容保存在修改历史中
+func (h *MessageHandler) EditMessage(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.EditMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	message, err := h.messageLogic.EditMessage(c.Request.Context(), userID, c.Param("id"), req.Content)
+	if err != nil {
+		messageErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "message_edited", message, nil)
+}
+
+// DeleteMessage 删除本人发送的消息，对方看到的内容为已删除
+func (h *MessageHandler) DeleteMessage(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	if err := h.messageLogic.DeleteMessage(c.Request.Context(), userID, c.Param("id")); err != nil {
+		messageErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "message_deleted", nil, nil)
+}
+
+// MarkRead 将对方发来的消息标记为已读（到指定消息为止），并向对方推送已读回执
+func (h *MessageHandler) MarkRead(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	peerID, err := strconv.ParseUint(c.Param("peer_id"), 10, 64)
+	if err != nil || peerID == 0 || uint(peerID) == userID {
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": "invalid peer id"})
+		return
+	}
+
+	var req dto.MarkMessagesReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	count, err := h.messageLogic.MarkRead(c.Request.Context(), userID, uint(peerID), req.MessageID)
+	if errors.Is(err, logic.ErrMessageNotFound) {
+		utils.ErrorWithNotFund(c, "message_not_found", nil)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponseFromError(c, "message_receipt_failed", err)
+		return
+	}
+
+	utils.Success(c, dto.MessageReceiptResponse{Updated: count})
+}
+
+// GetUnreadCounts 获取单聊未读总数和各会话的未读数
+func (h *MessageHandler) GetUnreadCounts(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	counts, err := h.messageLogic.UnreadCounts(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponseFromError(c, "unread_count_failed", err)
+		return
+	}
+
+	conversations := make([]dto.ConversationUnreadCount, len(counts.Conversations))
+	for i, conversation := range counts.Conversations {
+		conversations[i] = dto.ConversationUnreadCount{PeerID: conversation.PeerID, UnreadCount: conversation.UnreadCount}
+	}
+	utils.Success(c, dto.UnreadCountsResponse{
+		Total:         counts.Total,
+		Conversations: conversations,
+	})
+}
+
+// MarkAllRead 将指定会话（默认所有有未读消息的会话）中对方发来的消息全部标记为已读，并向对方推送已读回执
+func (h *MessageHandler) MarkAllRead(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.MarkAllReadRequest
+	// 请求体可以为空
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	count, err := h.messageLogic.MarkAllRead(c.Request.Context(), userID, req.PeerIDs)
+	if err != nil {
+		utils.ErrorResponseFromError(c, "message_receipt_failed", err)
+		return
+	}
+
+	utils.Success(c, dto.MessageReceiptResponse{Updated: count})
+}
+
+// MarkDelivered 接收方的WebSocket连接确认收到消息（内部接口，由WebSocket网关调用），并向发送者推送投递回执
+func (h *MessageHandler) MarkDelivered(c *gin.Context) {
+	var req dto.MessagesDeliveredRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	count, err := h.messageLogic.MarkDelivered(c.Request.Context(), req.UserID, req.MessageIDs)
+	if errors.Is(err, logic.ErrMessageNotFound) {
+		utils.ErrorWithNotFund(c, "message_not_found", nil)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponseFromError(c, "message_receipt_failed", err)
+		return
+	}
+
+	utils.Success(c, dto.MessageReceiptResponse{Updated: int64(count)})
+}
+
+// messageErrorResponse 修改或删除消息失败的响应
+func messageErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, logic.ErrMessageNotFound):
+		utils.ErrorWithNotFund(c, "message_not_found", nil)
+	case errors.Is(err, logic.ErrMessageNotSender):
+		utils.ErrorResponse(c, "message_not_sender", nil)
+	case errors.Is(err, logic.ErrMessageDeleted):
+		utils.ErrorResponse(c, "message_already_deleted", nil)
+	case errors.Is(err, logic.ErrMessageNotEditable):
+		utils.ErrorResponse(c, "message_not_editable", nil)
+	case errors.Is(err, logic.ErrMessageNotDeletable):
+		utils.ErrorResponse(c, "message_not_deletable", nil)
+	case errors.Is(err, logic.ErrMessageContentInvalid):
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+	case errors.Is(err, logic.ErrMessageEditConflict):
+		utils.ErrorResponse(c, "message_edit_conflict", nil)
+	default:
+		utils.ErrorResponseFromError(c, "message_update_failed", err)
+	}
+}
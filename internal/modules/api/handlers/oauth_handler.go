@@ -0,0 +1,127 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/middleware"
+	"exchange/internal/modules/api/dto"
+	"exchange/internal/modules/api/logic"
+	"exchange/internal/pkg/events"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/oauth"
+	"exchange/internal/utils"
+)
+
+// OAuthHandler 第三方登录处理器 - 发起授权和授权回调后登录
+type OAuthHandler struct {
+	oauthLogic     logic.OAuthLogic
+	authLogic      logic.AuthLogic
+	loginRiskLogic logic.LoginRiskLogic
+}
+
+// NewOAuthHandler 创建第三方登录处理器
+func NewOAuthHandler(oauthLogic logic.OAuthLogic, authLogic logic.AuthLogic, loginRiskLogic logic.LoginRiskLogic) *OAuthHandler {
+	return &OAuthHandler{
+		oauthLogic:     oauthLogic,
+		authLogic:      authLogic,
+		loginRiskLogic: loginRiskLogic,
+	}
+}
+
+// Authorize 发起第三方登录，返回服务商的授权地址
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	authURL, state, err := h.oauthLogic.AuthorizationURL(c.Request.Context(), c.Param("provider"))
+	if errors.Is(err, oauth.ErrUnknownProvider) {
+		utils.ErrorWithNotFund(c, "oauth_provider_not_supported", nil)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponseFromError(c, "oauth_login_failed", err)
+		return
+	}
+
+	utils.Success(c, dto.OAuthAuthorizeResponse{
+		AuthorizationURL: authURL,
+		State:            state,
+	})
+}
+
+// Callback 提交服务商回调的授权码和state，登录成功后签发token
+func (h *OAuthHandler) Callback(c *gin.Context) {
+	var req dto.OAuthCallbackRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.oauthLogic.Login(c.Request.Context(), c.Param("provider"), req.Code, req.State, req.TOTPCode)
+	if err != nil {
+		oauthErrorResponse(c, err)
+		return
+	}
+	user := result.User
+
+	if result.Created {
+		// 与注册相同，发布注册事件（第三方登录创建的用户邮箱已验证，不发送验证邮件）
+		events.DefaultBus().Publish(c.Request.Context(), events.Event{
+			Type:     events.UserRegistered,
+			UserID:   user.ID,
+			Language: middleware.GetLanguageFromContext(c),
+		})
+	}
+
+	if !checkLoginRisk(c, h.loginRiskLogic, user, user.TwoFactorEnabled) {
+		return
+	}
+
+	tokens, err := h.authLogic.IssueTokens(c.Request.Context(), user.ID, string(user.Role), requestDevice(c))
+	if err != nil {
+		utils.ErrorResponse(c, "token_generation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	appLogger.Security("用户第三方登录", map[string]interface{}{
+		"user_id":  user.ID,
+		"provider": c.Param("provider"),
+		"ip":       c.ClientIP(),
+	})
+
+	response := dto.OAuthLoginResponse{
+		User:    user.ToPublicUser(),
+		Token:   tokens.AccessToken,
+		Tokens:  newTokenResponse(tokens),
+		Created: result.Created,
+		Linked:  result.Linked,
+	}
+	utils.SuccessWithMessage(c, "login_successful", response, nil)
+}
+
+// oauthErrorResponse 第三方登录失败的响应
+func oauthErrorResponse(c *gin.Context, err error) {
+	switch {
+	case isTwoFactorError(err):
+		twoFactorErrorResponse(c, err)
+	case logic.IsAccountRestricted(err):
+		utils.ErrorResponseFromError(c, "oauth_login_failed", err)
+	case errors.Is(err, oauth.ErrUnknownProvider):
+		utils.ErrorWithNotFund(c, "oauth_provider_not_supported", nil)
+	case errors.Is(err, oauth.ErrInvalidState):
+		utils.ErrorResponse(c, "invalid_oauth_state", nil)
+	case errors.Is(err, oauth.ErrExchangeFailed):
+		appLogger.Warn("第三方登录换取token失败", map[string]interface{}{
+			"provider": c.Param("provider"),
+			"error":    err.Error(),
+		})
+		utils.ErrorResponse(c, "oauth_exchange_failed", nil)
+	case errors.Is(err, logic.ErrOAuthAccountInactive):
+		utils.ErrorResponse(c, "account_inactive", nil)
+	case errors.Is(err, logic.ErrOAuthEmailNotVerified):
+		utils.ErrorResponse(c, "oauth_email_not_verified", nil)
+	case errors.Is(err, logic.ErrOAuthLinkRequiresVerification):
+		utils.ErrorResponse(c, "oauth_link_requires_verification", nil)
+	default:
+		utils.ErrorResponseFromError(c, "oauth_login_failed", err)
+	}
+}
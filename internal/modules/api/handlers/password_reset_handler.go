@@ -0,0 +1,67 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/modules/api/dto"
+	"exchange/internal/modules/api/logic"
+	"exchange/internal/pkg/passwordreset"
+	"exchange/internal/utils"
+)
+
+// PasswordResetHandler 找回密码处理器 - 发送重置密码邮件和使用邮件中的token设置新密码
+type PasswordResetHandler struct {
+	resetLogic logic.PasswordResetLogic
+}
+
+// NewPasswordResetHandler 创建找回密码处理器
+func NewPasswordResetHandler(resetLogic logic.PasswordResetLogic) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		resetLogic: resetLogic,
+	}
+}
+
+// ForgotPassword 发送重置密码邮件
+// 无论邮箱是否已注册都返回相同的响应，避免被用来探测账户是否存在
+func (h *PasswordResetHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := h.resetLogic.RequestReset(c.Request.Context(), req.Email); err != nil {
+		utils.ErrorResponseFromError(c, "password_reset_failed", err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "password_reset_email_sent", nil, nil)
+}
+
+// ResetPassword 使用重置token设置新密码，所有设备上的登录随即失效
+func (h *PasswordResetHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	err := h.resetLogic.ResetPassword(c.Request.Context(), req.Token, req.Password)
+	if errors.Is(err, passwordreset.ErrInvalidToken) {
+		utils.ErrorResponse(c, "invalid_password_reset_token", nil)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponseFromError(c, "password_reset_failed", err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "password_reset_successful", nil, nil)
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/modules/api/dto"
+	"exchange/internal/modules/api/logic"
+	"exchange/internal/utils"
+)
+
+// PushHandler 离线推送处理器 - 注册和删除推送设备，查看和修改通知设置（免打扰时段、是否显示消息内容等）
+type PushHandler struct {
+	pushLogic logic.PushLogic
+}
+
+// NewPushHandler 创建离线推送处理器
+func NewPushHandler(pushLogic logic.PushLogic) *PushHandler {
+	return &PushHandler{
+		pushLogic: pushLogic,
+	}
+}
+
+// ListDevices 本人的推送设备列表
+func (h *PushHandler) ListDevices(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	devices, err := h.pushLogic.ListDevices(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponseFromError(c, "push_failed", err)
+		return
+	}
+
+	utils.Success(c, dto.NewPushDeviceResponses(devices))
+}
+
+// RegisterDevice 注册推送设备，同一token重复注册时更新最近注册时间
+func (h *PushHandler) RegisterDevice(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.RegisterPushDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	device, err := h.pushLogic.RegisterDevice(c.Request.Context(), userID, req.Platform, req.Token)
+	if errors.Is(err, logic.ErrPushPlatformUnsupported) {
+		utils.ErrorResponse(c, "push_platform_unsupported", nil)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponseFromError(c, "push_failed", err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "push_device_registered", dto.NewPushDeviceResponse(device), nil)
+}
+
+// DeleteDevice 删除推送设备，客户端退出登录时调用
+func (h *PushHandler) DeleteDevice(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	deviceID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": "invalid device id"})
+		return
+	}
+
+	err = h.pushLogic.DeleteDevice(c.Request.Context(), userID, uint(deviceID))
+	if errors.Is(err, logic.ErrPushDeviceNotFound) {
+		utils.ErrorWithNotFund(c, "push_device_not_found", nil)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponseFromError(c, "push_failed", err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "push_device_deleted", nil, nil)
+}
+
+// GetPreferences 本人的通知设置
+func (h *PushHandler) GetPreferences(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	pref, err := h.pushLogic.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponseFromError(c, "push_failed", err)
+		return
+	}
+
+	utils.Success(c, dto.NewNotificationPreferencesResponse(pref))
+}
+
+// UpdatePreferences 修改通知设置
+func (h *PushHandler) UpdatePreferences(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	pref, err := h.pushLogic.UpdatePreferences(c.Request.Context(), userID, logic.NotificationPreferenceInput{
+		PushEnabled:    req.PushEnabled,
+		DirectMessages: req.DirectMessages,
+		GroupMessages:  req.GroupMessages,
+		ShowPreview:    req.ShowPreview,
+		QuietStart:     req.QuietStart,
+		QuietEnd:       req.QuietEnd,
+		Timezone:       req.Timezone,
+	})
+	if errors.Is(err, logic.ErrInvalidNotificationPreference) {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		utils.ErrorResponseFromError(c, "push_failed", err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "push_preferences_updated", dto.NewNotificationPreferencesResponse(pref), nil)
+}
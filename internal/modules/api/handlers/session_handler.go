@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/modules/api/dto"
+	"exchange/internal/modules/api/logic"
+	"exchange/internal/pkg/session"
+	"exchange/internal/utils"
+)
+
+// SessionHandler 登录会话处理器 - 查看和撤销本人在各设备上的登录会话
+type SessionHandler struct {
+	sessionLogic logic.SessionLogic
+}
+
+// NewSessionHandler 创建登录会话处理器
+func NewSessionHandler(sessionLogic logic.SessionLogic) *SessionHandler {
+	return &SessionHandler{
+		sessionLogic: sessionLogic,
+	}
+}
+
+// ListSessions 本人的登录会话列表，标记发起本次请求的会话
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	sessions, err := h.sessionLogic.ListSessions(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponse(c, "session_list_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.Success(c, dto.NewSessionResponses(sessions, c.GetString("session_id")))
+}
+
+// RevokeSession 撤销本人的一个登录会话（在该设备上退出登录）
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	err := h.sessionLogic.RevokeSession(c.Request.Context(), userID, c.Param("id"))
+	if errors.Is(err, session.ErrSessionRevoked) {
+		utils.ErrorResponse(c, "session_not_found", nil)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, "session_revoke_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.SuccessWithMessage(c, "session_revoked", nil, nil)
+}
+
+// RevokeOtherSessions 撤销本人除当前会话外的所有登录会话（在其他设备上退出登录）
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	revoked, err := h.sessionLogic.RevokeOtherSessions(c.Request.Context(), userID, c.GetString("session_id"))
+	if err != nil {
+		utils.ErrorResponse(c, "session_revoke_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.SuccessWithMessage(c, "sessions_revoked", dto.RevokeSessionsResponse{Revoked: revoked}, nil)
+}
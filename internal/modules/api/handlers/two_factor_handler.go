@@ -0,0 +1,197 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/modules/api/dto"
+	"exchange/internal/modules/api/logic"
+	"exchange/internal/pkg/twofactor"
+	"exchange/internal/utils"
+)
+
+// TwoFactorHandler 用户两步验证处理器 - 处理绑定、启用、关闭两步验证和登录时确认绑定
+type TwoFactorHandler struct {
+	twoFactorLogic logic.TwoFactorLogic // 两步验证业务逻辑
+	authLogic      logic.AuthLogic      // 认证业务逻辑，确认绑定后签发登录token
+	loginRiskLogic logic.LoginRiskLogic // 新设备和异地登录检测
+}
+
+// NewTwoFactorHandler 创建用户两步验证处理器
+func NewTwoFactorHandler(twoFactorLogic logic.TwoFactorLogic, authLogic logic.AuthLogic, loginRiskLogic logic.LoginRiskLogic) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorLogic: twoFactorLogic,
+		authLogic:      authLogic,
+		loginRiskLogic: loginRiskLogic,
+	}
+}
+
+// GetStatus 获取两步验证状态
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	status, err := h.twoFactorLogic.GetStatus(c.Request.Context(), userID)
+	if err != nil {
+		twoFactorErrorResponse(c, err)
+		return
+	}
+
+	utils.Success(c, status)
+}
+
+// BeginSetup 开始绑定，返回密钥和绑定地址，使用验证器应用扫码后调用Enable确认
+func (h *TwoFactorHandler) BeginSetup(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	enrollment, err := h.twoFactorLogic.BeginSetup(c.Request.Context(), userID)
+	if err != nil {
+		twoFactorErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "two_factor_setup_started", dto.NewTwoFactorSetupResponse(enrollment, ""), nil)
+}
+
+// Enable 使用验证码确认绑定并启用两步验证，返回备用码
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	codes, err := h.twoFactorLogic.Enable(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		twoFactorErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "two_factor_enabled", dto.BackupCodesResponse{BackupCodes: codes}, nil)
+}
+
+// Disable 关闭两步验证
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := h.twoFactorLogic.Disable(c.Request.Context(), userID, req.Code); err != nil {
+		twoFactorErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "two_factor_disabled", nil, nil)
+}
+
+// RegenerateBackupCodes 重新生成备用码，之前的备用码全部失效
+func (h *TwoFactorHandler) RegenerateBackupCodes(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	codes, err := h.twoFactorLogic.RegenerateBackupCodes(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		twoFactorErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "two_factor_backup_codes_regenerated", dto.BackupCodesResponse{BackupCodes: codes}, nil)
+}
+
+// ConfirmSetup 强制启用两步验证的用户登录时确认绑定，确认后完成登录
+func (h *TwoFactorHandler) ConfirmSetup(c *gin.Context) {
+	var req dto.ConfirmTwoFactorSetupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	user, codes, err := h.twoFactorLogic.ConfirmSetup(c.Request.Context(), req.SetupToken, req.Code)
+	if err != nil {
+		twoFactorErrorResponse(c, err)
+		return
+	}
+
+	// 确认绑定时已校验验证码，新设备登录只通知用户
+	if !checkLoginRisk(c, h.loginRiskLogic, user, true) {
+		return
+	}
+
+	tokens, err := h.authLogic.IssueTokens(c.Request.Context(), user.ID, string(user.Role), requestDevice(c))
+	if err != nil {
+		utils.ErrorResponse(c, "token_generation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	response := dto.ConfirmTwoFactorSetupResponse{
+		User:        user.ToPublicUser(),
+		Token:       tokens.AccessToken,
+		Tokens:      newTokenResponse(tokens),
+		BackupCodes: codes,
+	}
+
+	utils.SuccessWithMessage(c, "two_factor_enabled", response, nil)
+}
+
+// twoFactorErrorResponse 两步验证错误响应，登录时要求绑定的响应中附带绑定信息
+func twoFactorErrorResponse(c *gin.Context, err error) {
+	var setupErr *twofactor.SetupRequiredError
+	switch {
+	case errors.As(err, &setupErr):
+		utils.ErrorWithData(c, "two_factor_setup_required", dto.NewTwoFactorSetupResponse(setupErr.Enrollment, setupErr.SetupToken), nil)
+	case errors.Is(err, twofactor.ErrCodeRequired):
+		utils.ErrorResponse(c, "two_factor_required", nil)
+	case errors.Is(err, twofactor.ErrInvalidCode):
+		utils.ErrorResponse(c, "invalid_two_factor_code", nil)
+	case errors.Is(err, twofactor.ErrNotEnabled):
+		utils.ErrorResponse(c, "two_factor_not_enabled", nil)
+	case errors.Is(err, twofactor.ErrAlreadyEnabled):
+		utils.ErrorResponse(c, "two_factor_already_enabled", nil)
+	case errors.Is(err, twofactor.ErrNoPendingSetup):
+		utils.ErrorResponse(c, "two_factor_setup_expired", nil)
+	case errors.Is(err, twofactor.ErrInvalidSetupToken):
+		utils.ErrorResponse(c, "invalid_setup_token", nil)
+	case errors.Is(err, twofactor.ErrEnforced):
+		utils.ErrorResponse(c, "two_factor_enforced", nil)
+	default:
+		utils.ErrorResponseFromError(c, "two_factor_failed", err)
+	}
+}
+
+// isTwoFactorError 是否为两步验证错误（登录时区分密码错误和两步验证错误）
+func isTwoFactorError(err error) bool {
+	return errors.Is(err, twofactor.ErrSetupRequired) ||
+		errors.Is(err, twofactor.ErrCodeRequired) ||
+		errors.Is(err, twofactor.ErrInvalidCode)
+}
@@ -1,24 +1,40 @@
 package api
 
 import (
+	"errors"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 
+	"exchange/internal/middleware"
+	"exchange/internal/models/mysql"
 	"exchange/internal/modules/api/dto"
 	"exchange/internal/modules/api/logic"
+	appErrors "exchange/internal/pkg/errors"
+	"exchange/internal/pkg/events"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/loginrisk"
+	"exchange/internal/pkg/session"
 	"exchange/internal/utils"
 )
 
 // UserHandler 用户处理器
 type UserHandler struct {
-	userLogic logic.UserLogic
-	authLogic logic.AuthLogic
+	userLogic         logic.UserLogic
+	authLogic         logic.AuthLogic
+	deletionLogic     logic.AccountDeletionLogic
+	verificationLogic logic.EmailVerificationLogic
+	loginRiskLogic    logic.LoginRiskLogic
 }
 
 // NewUserHandler 创建用户处理器
-func NewUserHandler(userLogic logic.UserLogic, authLogic logic.AuthLogic) *UserHandler {
+func NewUserHandler(userLogic logic.UserLogic, authLogic logic.AuthLogic, deletionLogic logic.AccountDeletionLogic, verificationLogic logic.EmailVerificationLogic, loginRiskLogic logic.LoginRiskLogic) *UserHandler {
 	return &UserHandler{
-		userLogic: userLogic,
-		authLogic: authLogic,
+		userLogic:         userLogic,
+		authLogic:         authLogic,
+		deletionLogic:     deletionLogic,
+		verificationLogic: verificationLogic,
+		loginRiskLogic:    loginRiskLogic,
 	}
 }
 
@@ -26,7 +42,7 @@ func NewUserHandler(userLogic logic.UserLogic, authLogic logic.AuthLogic) *UserH
 func (h *UserHandler) Register(c *gin.Context) {
 	var req dto.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": err.Error()})
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
@@ -37,19 +53,40 @@ func (h *UserHandler) Register(c *gin.Context) {
 
 	user, err := h.userLogic.CreateUser(c.Request.Context(), req.Username, req.Email, req.Password)
 	if err != nil {
-		utils.ErrorResponse(c, "user_creation_failed", map[string]interface{}{"error": err.Error()})
+		utils.ErrorResponseFromError(c, "user_creation_failed", err)
+		return
+	}
+
+	// 发布注册事件（欢迎消息等自动化规则异步执行）
+	events.DefaultBus().Publish(c.Request.Context(), events.Event{
+		Type:     events.UserRegistered,
+		UserID:   user.ID,
+		Language: middleware.GetLanguageFromContext(c),
+	})
+
+	// 发送验证邮件，发送失败不影响注册，用户可以稍后重新发送
+	if err := h.verificationLogic.SendVerification(c.Request.Context(), user.ID); err != nil {
+		appLogger.Warn("发送注册验证邮件失败", map[string]interface{}{
+			"user_id": user.ID,
+			"error":   err.Error(),
+		})
+	}
+
+	// 新用户还没有设备记录，注册设备记录为已知设备
+	if !checkLoginRisk(c, h.loginRiskLogic, user, false) {
 		return
 	}
 
-	token, err := h.authLogic.GenerateToken(user.ID, string(user.Role))
+	tokens, err := h.authLogic.IssueTokens(c.Request.Context(), user.ID, string(user.Role), requestDevice(c))
 	if err != nil {
 		utils.ErrorResponse(c, "token_generation_failed", map[string]interface{}{"error": err.Error()})
 		return
 	}
 
 	response := dto.RegisterResponse{
-		User:  user.ToPublicUser(),
-		Token: token,
+		User:   user.ToPublicUser(),
+		Token:  tokens.AccessToken,
+		Tokens: newTokenResponse(tokens),
 	}
 
 	utils.SuccessWithMessage(c, "user_registered_successfully", response, nil)
@@ -59,30 +96,154 @@ func (h *UserHandler) Register(c *gin.Context) {
 func (h *UserHandler) Login(c *gin.Context) {
 	var req dto.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": err.Error()})
+		utils.BindingErrorResponse(c, err)
 		return
 	}
 
-	user, err := h.authLogic.AuthenticateUser(c.Request.Context(), req.Username, req.Password)
+	user, err := h.authLogic.AuthenticateUser(c.Request.Context(), req.Username, req.Password, req.TOTPCode, c.ClientIP())
+	if isTwoFactorError(err) {
+		twoFactorErrorResponse(c, err)
+		return
+	}
+	if errors.Is(err, appErrors.ErrAccountLocked) {
+		accountLockedResponse(c, err)
+		return
+	}
+	if logic.IsAccountRestricted(err) {
+		utils.ErrorResponseFromError(c, "invalid_credentials", err)
+		return
+	}
 	if err != nil {
 		utils.ErrorResponse(c, "invalid_credentials", map[string]interface{}{"error": err.Error()})
 		return
 	}
 
-	token, err := h.authLogic.GenerateToken(user.ID, string(user.Role))
+	// 已启用两步验证的用户本次登录已校验验证码
+	if !checkLoginRisk(c, h.loginRiskLogic, user, user.TwoFactorEnabled) {
+		return
+	}
+
+	tokens, err := h.authLogic.IssueTokens(c.Request.Context(), user.ID, string(user.Role), requestDevice(c))
 	if err != nil {
 		utils.ErrorResponse(c, "token_generation_failed", map[string]interface{}{"error": err.Error()})
 		return
 	}
 
 	response := dto.LoginResponse{
-		User:  user.ToPublicUser(),
-		Token: token,
+		User:   user.ToPublicUser(),
+		Token:  tokens.AccessToken,
+		Tokens: newTokenResponse(tokens),
 	}
 
 	utils.SuccessWithMessage(c, "login_successful", response, nil)
 }
 
+// accountLockedResponse 登录失败次数过多的响应（HTTP 429），Retry-After头为可以再次登录的等待秒数
+func accountLockedResponse(c *gin.Context, err error) {
+	if appErr, ok := appErrors.GetAppError(err); ok {
+		if seconds, ok := appErr.Context["retry_after"].(int); ok {
+			c.Header("Retry-After", strconv.Itoa(seconds))
+		}
+	}
+	utils.ErrorResponseFromError(c, "account_locked", err)
+}
+
+// RefreshToken 使用刷新token换取新的访问token和刷新token
+// 已使用过的刷新token再次出现时撤销整个登录会话，需重新登录
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	tokens, err := h.authLogic.RefreshTokens(c.Request.Context(), req.RefreshToken, requestDevice(c))
+	switch {
+	case errors.Is(err, session.ErrRefreshTokenReused):
+		utils.ErrorResponseWithAuth(c, "refresh_token_reused", nil)
+		return
+	case errors.Is(err, session.ErrInvalidRefreshToken), errors.Is(err, session.ErrSessionRevoked):
+		utils.ErrorResponseWithAuth(c, "invalid_refresh_token", nil)
+		return
+	case err != nil:
+		utils.ErrorResponse(c, "token_generation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.SuccessWithMessage(c, "token_refreshed", newTokenResponse(tokens), nil)
+}
+
+// Logout 退出登录，撤销当前登录会话，该会话的访问token和刷新token均失效
+func (h *UserHandler) Logout(c *gin.Context) {
+	if err := h.authLogic.RevokeSession(c.Request.Context(), c.GetString("session_id")); err != nil {
+		utils.ErrorResponse(c, "logout_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.SuccessWithMessage(c, "logout_successful", nil, nil)
+}
+
+// AcceptInvite 接受邀请
+// 管理员以邀请方式批量导入的用户通过邀请链接设置密码并激活账户，激活后直接登录
+func (h *UserHandler) AcceptInvite(c *gin.Context) {
+	var req dto.AcceptInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userLogic.AcceptInvite(c.Request.Context(), req.Token, req.Password)
+	if err != nil {
+		utils.ErrorResponseFromError(c, "invite_accept_failed", err)
+		return
+	}
+
+	// 邀请链接通过邮件送达，等同于已确认邮箱
+	if !checkLoginRisk(c, h.loginRiskLogic, user, true) {
+		return
+	}
+
+	tokens, err := h.authLogic.IssueTokens(c.Request.Context(), user.ID, string(user.Role), requestDevice(c))
+	if err != nil {
+		utils.ErrorResponse(c, "token_generation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	response := dto.LoginResponse{
+		User:   user.ToPublicUser(),
+		Token:  tokens.AccessToken,
+		Tokens: newTokenResponse(tokens),
+	}
+
+	utils.SuccessWithMessage(c, "invite_accepted", response, nil)
+}
+
+// ConfirmLogin 使用确认登录邮件中的token确认新设备，确认后在该设备上重新登录
+func (h *UserHandler) ConfirmLogin(c *gin.Context) {
+	var req dto.ConfirmLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	err := h.loginRiskLogic.ConfirmLogin(c.Request.Context(), req.Token)
+	if errors.Is(err, loginrisk.ErrInvalidToken) {
+		utils.ErrorResponse(c, "invalid_login_confirmation_token", nil)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponseFromError(c, "login_confirmation_failed", err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "login_device_confirmed", nil, nil)
+}
+
 // GetProfile 获取用户资料
 func (h *UserHandler) GetProfile(c *gin.Context) {
 	userID := c.GetUint("user_id")
@@ -93,9 +254,139 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 
 	user, err := h.userLogic.GetUserByID(c.Request.Context(), userID)
 	if err != nil {
-		utils.ErrorResponse(c, "user_not_found", map[string]interface{}{"error": err.Error()})
+		utils.ErrorResponseFromError(c, "user_not_found", err)
 		return
 	}
 
 	utils.Success(c, user.ToPublicUser())
 }
+
+// SetLanguage 设置首选语言，之后的接口响应和通知邮件按该语言本地化
+func (h *UserHandler) SetLanguage(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.SetLanguageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userLogic.SetLanguage(c.Request.Context(), userID, req.Language)
+	if err != nil {
+		utils.ErrorResponseFromError(c, "user_update_failed", err)
+		return
+	}
+
+	// 本次响应即使用新的语言
+	middleware.SetUserLanguage(c, user.Language)
+
+	utils.SuccessWithMessage(c, "language_updated", user.ToPublicUser(), nil)
+}
+
+// RequestDeletion 提交账户注销申请
+func (h *UserHandler) RequestDeletion(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	var req dto.AccountDeletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.ErrorResponse(c, "validation_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	deletion, err := h.deletionLogic.RequestDeletion(c.Request.Context(), userID, req.Password, req.Reason)
+	if err != nil {
+		utils.ErrorResponseFromError(c, "account_deletion_request_failed", err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "account_deletion_requested", dto.NewAccountDeletionResponse(deletion), nil)
+}
+
+// GetDeletionStatus 获取账户注销申请状态
+func (h *UserHandler) GetDeletionStatus(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	deletion, err := h.deletionLogic.GetDeletionStatus(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponse(c, "account_deletion_not_found", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.Success(c, dto.NewAccountDeletionResponse(deletion))
+}
+
+// CancelDeletion 撤销账户注销申请
+func (h *UserHandler) CancelDeletion(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		utils.ErrorResponse(c, "unauthorized", nil)
+		return
+	}
+
+	deletion, err := h.deletionLogic.CancelDeletion(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponse(c, "account_deletion_cancel_failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	utils.SuccessWithMessage(c, "account_deletion_cancelled", dto.NewAccountDeletionResponse(deletion), nil)
+}
+
+// newTokenResponse 构建token响应
+func newTokenResponse(tokens *logic.TokenPair) *dto.TokenResponse {
+	return &dto.TokenResponse{
+		AccessToken:      tokens.AccessToken,
+		RefreshToken:     tokens.RefreshToken,
+		ExpiresIn:        int64(tokens.ExpiresIn.Seconds()),
+		RefreshExpiresIn: int64(tokens.RefreshExpiresIn.Seconds()),
+	}
+}
+
+// checkLoginRisk 签发token前检查新设备和异地登录，需要邮件确认或检查失败时写出响应并返回false
+func checkLoginRisk(c *gin.Context, loginRisk logic.LoginRiskLogic, user *mysql.User, stepUpPassed bool) bool {
+	attempt := logic.LoginAttempt{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Header:    c.Request.Header,
+	}
+	err := loginRisk.Check(c.Request.Context(), user, attempt, stepUpPassed)
+	if errors.Is(err, logic.ErrLoginConfirmationRequired) {
+		utils.ErrorResponse(c, "login_confirmation_required", nil)
+		return false
+	}
+	if err != nil {
+		utils.ErrorResponseFromError(c, "login_confirmation_failed", err)
+		return false
+	}
+	return true
+}
+
+// requestDevice 请求的设备信息，记录到登录会话
+func requestDevice(c *gin.Context) session.Device {
+	return session.Device{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
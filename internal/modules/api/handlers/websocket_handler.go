@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+
+	"exchange/internal/modules/api/dto"
+	"exchange/internal/modules/api/logic"
+	wsDto "exchange/internal/modules/websocket/dto"
+	wsLogic "exchange/internal/modules/websocket/logic"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/rbac"
+	"exchange/internal/utils"
+)
+
+// WebSocketHandler WebSocket处理器 - 认证后建立连接，推送单聊回执、群聊消息等事件，重连时补发错过的事件；查询用户在线状态
+type WebSocketHandler struct {
+	authLogic     logic.AuthLogic
+	presenceLogic logic.PresenceLogic
+	hub           *wsLogic.Hub
+}
+
+// NewWebSocketHandler 创建WebSocket处理器
+func NewWebSocketHandler(authLogic logic.AuthLogic, presenceLogic logic.PresenceLogic, hub *wsLogic.Hub) *WebSocketHandler {
+	return &WebSocketHandler{
+		authLogic:     authLogic,
+		presenceLogic: presenceLogic,
+		hub:           hub,
+	}
+}
+
+// Connect 校验访问token后升级为WebSocket连接
+// 握手失败时按普通接口返回错误；认证使用token而不是Cookie，因此不校验Origin
+func (h *WebSocketHandler) Connect(c *gin.Context) {
+	var req wsDto.HandshakeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	token := req.Token
+	if token == "" {
+		if authHeader := c.GetHeader("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+			token = strings.TrimPrefix(authHeader, "Bearer ")
+		}
+	}
+	if token == "" {
+		utils.ErrorResponseWithAuth(c, "token_required", nil)
+		return
+	}
+
+	claims, err := h.authLogic.ValidateToken(token)
+	if err != nil {
+		utils.ErrorResponseWithAuth(c, "invalid_token", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	if _, isAdmin := rbac.ParseAdminTokenRole(claims.Role); isAdmin {
+		utils.ErrorResponseWithAuth(c, "unauthorized", map[string]interface{}{"error": "管理员token不能用于用户接口"})
+		return
+	}
+
+	// 实例关闭期间拒绝新连接，客户端重试时由负载均衡分配到其他实例
+	if h.hub.Draining() {
+		utils.ErrorResponse(c, "service_unavailable", nil)
+		return
+	}
+
+	server := websocket.Server{
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			// 连接的生命周期与请求无关，不使用请求的context
+			if err := h.hub.Serve(context.Background(), claims.UserID, req.ResumeFrom, conn); err != nil {
+				appLogger.Warn("WebSocket连接异常结束", map[string]interface{}{
+					"user_id": claims.UserID,
+					"error":   err.Error(),
+				})
+			}
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// GetPresence 批量查询用户是否在线（在任一实例上有WebSocket连接）和最后在线时间
+func (h *WebSocketHandler) GetPresence(c *gin.Context) {
+	var req dto.PresenceRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BindingErrorResponse(c, err)
+		return
+	}
+
+	users, err := h.presenceLogic.Statuses(c.Request.Context(), req.UserIDs)
+	if errors.Is(err, logic.ErrInvalidPresenceQuery) {
+		utils.ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": "user_ids must be at most 100 comma separated user ids"})
+		return
+	}
+	if err != nil {
+		utils.ErrorResponseFromError(c, "presence_failed", err)
+		return
+	}
+
+	utils.Success(c, dto.PresenceResponse{Users: users})
+}
@@ -0,0 +1,323 @@
+package logic
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	appErrors "exchange/internal/pkg/errors"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/notification"
+	"exchange/internal/pkg/session"
+	"exchange/internal/pkg/tokenrevoke"
+	"exchange/internal/repository"
+)
+
+// 账户注销通知事件
+const (
+	EventAccountDeletionRequested = "account_deletion_requested" // 已提交注销申请，进入冷静期
+	EventAccountDeletionCancelled = "account_deletion_cancelled" // 用户撤销注销申请
+	EventAccountDeletionRejected  = "account_deletion_rejected"  // 执行时校验未通过
+	EventAccountDeletionCompleted = "account_deletion_completed" // 注销完成，个人信息已匿名化
+)
+
+// DeletionGuard 注销前置校验（如交易模块校验未完成订单、账户余额）
+type DeletionGuard interface {
+	Name() string
+	CheckDeletable(ctx context.Context, userID uint) error
+}
+
+// AccountDeletionLogic 账户注销业务逻辑接口
+type AccountDeletionLogic interface {
+	// RequestDeletion 提交注销申请（需验证密码），冷静期结束后由定时任务执行
+	RequestDeletion(ctx context.Context, userID uint, password, reason string) (*mysql.AccountDeletionRequest, error)
+
+	// GetDeletionStatus 获取最近一次注销申请
+	GetDeletionStatus(ctx context.Context, userID uint) (*mysql.AccountDeletionRequest, error)
+
+	// CancelDeletion 冷静期内撤销注销申请
+	CancelDeletion(ctx context.Context, userID uint) (*mysql.AccountDeletionRequest, error)
+
+	// ProcessDueDeletions 执行冷静期已结束的注销申请，返回完成数量
+	ProcessDueDeletions(ctx context.Context, limit int) (int, error)
+
+	// AddDeletionGuard 注册注销前置校验
+	AddDeletionGuard(guard DeletionGuard)
+}
+
+// APIAccountDeletionLogic 账户注销业务逻辑实现
+type APIAccountDeletionLogic struct {
+	userRepo     repository.UserRepository
+	deletionRepo repository.AccountDeletionRepository
+	notifier     notification.Notifier
+	sessions     *session.Store
+	revoked      *tokenrevoke.Store
+	graceDays    int
+
+	guardsLock sync.RWMutex
+	guards     []DeletionGuard
+}
+
+// NewAPIAccountDeletionLogic 创建账户注销业务逻辑实例
+// sessions和revoked用于注销完成后撤销用户的登录会话和已签发的token
+func NewAPIAccountDeletionLogic(cfg *config.Config, userRepo repository.UserRepository, deletionRepo repository.AccountDeletionRepository, sessions *session.Store, revoked *tokenrevoke.Store, notifier notification.Notifier) *APIAccountDeletionLogic {
+	return &APIAccountDeletionLogic{
+		userRepo:     userRepo,
+		deletionRepo: deletionRepo,
+		notifier:     notifier,
+		sessions:     sessions,
+		revoked:      revoked,
+		graceDays:    cfg.Account.DeletionGraceDays,
+	}
+}
+
+// AddDeletionGuard 注册注销前置校验
+func (l *APIAccountDeletionLogic) AddDeletionGuard(guard DeletionGuard) {
+	l.guardsLock.Lock()
+	defer l.guardsLock.Unlock()
+	l.guards = append(l.guards, guard)
+}
+
+// RequestDeletion 提交注销申请
+func (l *APIAccountDeletionLogic) RequestDeletion(ctx context.Context, userID uint, password, reason string) (*mysql.AccountDeletionRequest, error) {
+	user, err := l.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, appErrors.TranslateUserError(err, "查询用户失败", userID)
+	}
+
+	if user.IsAccountDeleted() {
+		return nil, errors.New("账户已注销")
+	}
+
+	// 验证密码，防止token泄露后被恶意注销
+	if !user.CheckPassword(password) {
+		return nil, errors.New("密码错误")
+	}
+
+	// 检查是否已有待执行的注销申请
+	latest, err := l.deletionRepo.GetLatestByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && latest.IsPending() {
+		return nil, errors.New("已存在待执行的注销申请")
+	}
+
+	// 前置校验（未完成订单、余额等）
+	if err := l.checkGuards(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	req := &mysql.AccountDeletionRequest{
+		UserID:      userID,
+		Status:      mysql.AccountDeletionStatusPending,
+		Reason:      reason,
+		ScheduledAt: clock.Now().AddDate(0, 0, l.graceDays).UnixNano(),
+	}
+	if err := l.deletionRepo.Create(ctx, req); err != nil {
+		return nil, fmt.Errorf("创建注销申请失败: %w", err)
+	}
+
+	l.notify(ctx, user.ID, user.Email, user.Language, EventAccountDeletionRequested, map[string]interface{}{
+		"request_id":   req.ID,
+		"scheduled_at": req.ScheduledAt,
+		"grace_days":   l.graceDays,
+	})
+
+	return req, nil
+}
+
+// GetDeletionStatus 获取最近一次注销申请
+func (l *APIAccountDeletionLogic) GetDeletionStatus(ctx context.Context, userID uint) (*mysql.AccountDeletionRequest, error) {
+	req, err := l.deletionRepo.GetLatestByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, errors.New("注销申请不存在")
+	}
+	return req, nil
+}
+
+// CancelDeletion 撤销注销申请
+func (l *APIAccountDeletionLogic) CancelDeletion(ctx context.Context, userID uint) (*mysql.AccountDeletionRequest, error) {
+	req, err := l.deletionRepo.GetLatestByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil || !req.IsPending() {
+		return nil, errors.New("没有可撤销的注销申请")
+	}
+
+	req.Status = mysql.AccountDeletionStatusCancelled
+	req.CompletedAt = clock.Now().UnixNano()
+	if err := l.deletionRepo.Update(ctx, req); err != nil {
+		return nil, fmt.Errorf("撤销注销申请失败: %w", err)
+	}
+
+	user, err := l.userRepo.GetByID(ctx, userID)
+	if err == nil {
+		l.notify(ctx, user.ID, user.Email, user.Language, EventAccountDeletionCancelled, map[string]interface{}{
+			"request_id": req.ID,
+		})
+	}
+
+	return req, nil
+}
+
+// ProcessDueDeletions 执行冷静期已结束的注销申请
+func (l *APIAccountDeletionLogic) ProcessDueDeletions(ctx context.Context, limit int) (int, error) {
+	reqs, err := l.deletionRepo.GetDue(ctx, clock.Now().UnixNano(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	completed := 0
+	for _, req := range reqs {
+		if err := ctx.Err(); err != nil {
+			return completed, err
+		}
+
+		if err := l.processDeletion(ctx, req); err != nil {
+			return completed, fmt.Errorf("处理注销申请 %d 失败: %w", req.ID, err)
+		}
+		if req.Status == mysql.AccountDeletionStatusCompleted {
+			completed++
+		}
+	}
+
+	return completed, nil
+}
+
+// processDeletion 执行单个注销申请
+func (l *APIAccountDeletionLogic) processDeletion(ctx context.Context, req *mysql.AccountDeletionRequest) error {
+	user, err := l.userRepo.GetByID(ctx, req.UserID)
+	if err != nil {
+		return appErrors.TranslateUserError(err, "查询用户失败", req.UserID)
+	}
+
+	// 冷静期内可能产生了新的订单或余额，执行前再次校验
+	if err := l.checkGuards(ctx, req.UserID); err != nil {
+		req.Status = mysql.AccountDeletionStatusRejected
+		req.RejectReason = err.Error()
+		req.CompletedAt = clock.Now().UnixNano()
+		if updateErr := l.deletionRepo.Update(ctx, req); updateErr != nil {
+			return updateErr
+		}
+
+		l.notify(ctx, user.ID, user.Email, user.Language, EventAccountDeletionRejected, map[string]interface{}{
+			"request_id": req.ID,
+			"reason":     req.RejectReason,
+		})
+		return nil
+	}
+
+	// 匿名化前保存邮箱快照，用于发送完成通知
+	email := user.Email
+
+	fields, err := anonymizedUserFields(user.ID)
+	if err != nil {
+		return err
+	}
+
+	req.Status = mysql.AccountDeletionStatusCompleted
+	req.CompletedAt = clock.Now().UnixNano()
+	if err := l.deletionRepo.AnonymizeUser(ctx, req, fields); err != nil {
+		return err
+	}
+	l.revokeAccess(ctx, user.ID)
+
+	l.notify(ctx, user.ID, email, user.Language, EventAccountDeletionCompleted, map[string]interface{}{
+		"request_id": req.ID,
+	})
+	return nil
+}
+
+// revokeAccess 撤销已注销用户所有设备上的登录会话和已签发的token
+// 撤销失败只记录日志：账户已匿名化，刷新token时会因账户状态被拒绝，访问token有效期很短
+func (l *APIAccountDeletionLogic) revokeAccess(ctx context.Context, userID uint) {
+	revokedSessions, err := l.sessions.RevokeAll(ctx, userID, "")
+	if err != nil {
+		appLogger.Warn("注销后撤销登录会话失败", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+
+	claims := &Claims{UserID: userID}
+	revokedTokens, err := l.revoked.RevokeAll(ctx, claims.Principal())
+	if err != nil {
+		appLogger.Warn("注销后撤销token失败", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+
+	appLogger.Security("已注销用户的登录会话和token已撤销", map[string]interface{}{
+		"user_id":          userID,
+		"revoked_sessions": revokedSessions,
+		"revoked_tokens":   revokedTokens,
+	})
+}
+
+// checkGuards 执行所有注销前置校验
+func (l *APIAccountDeletionLogic) checkGuards(ctx context.Context, userID uint) error {
+	l.guardsLock.RLock()
+	defer l.guardsLock.RUnlock()
+
+	for _, guard := range l.guards {
+		if err := guard.CheckDeletable(ctx, userID); err != nil {
+			return fmt.Errorf("%s: %w", guard.Name(), err)
+		}
+	}
+	return nil
+}
+
+// notify 发送通知（失败不影响注销流程）
+func (l *APIAccountDeletionLogic) notify(ctx context.Context, userID uint, email, language, event string, data map[string]interface{}) {
+	if l.notifier == nil {
+		return
+	}
+
+	if err := l.notifier.Notify(ctx, &notification.Notification{
+		UserID:    userID,
+		Event:     event,
+		Email:     email,
+		Language:  language,
+		Data:      data,
+		CreatedAt: clock.Now(),
+	}); err != nil {
+		fmt.Printf("failed to send notification %s to user %d: %v\n", event, userID, err)
+	}
+}
+
+// anonymizedUserFields 生成匿名化后的用户字段
+// 保留用户ID（账务流水等通过ID关联），覆盖用户名、邮箱、密码等个人信息
+func anonymizedUserFields(userID uint) (map[string]interface{}, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("生成随机密码失败: %w", err)
+	}
+
+	// 随机密码哈希，确保账户无法再登录
+	hash, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(secret)), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("生成随机密码失败: %w", err)
+	}
+
+	return map[string]interface{}{
+		"username":      fmt.Sprintf("deleted_%d", userID),
+		"email":         fmt.Sprintf("deleted_%d@anonymized.invalid", userID),
+		"password_hash": string(hash),
+		"status":        mysql.UserStatusDeleted,
+		"last_login_at": nil,
+	}, nil
+}
@@ -0,0 +1,306 @@
+package logic
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"gorm.io/gorm"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/ipaccess"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/repository"
+)
+
+const (
+	apiKeyIDPrefix    = "ak_" // 用户创建的key ID前缀，与配置中的签名key区分
+	apiKeyIDBytes     = 12
+	apiKeySecretBytes = 32
+
+	// apiKeyTouchInterval 记录最近使用时间的最小间隔，避免每个请求都写库
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	// ErrAPIKeyAuthDisabled 未开启API key认证，不能创建key
+	ErrAPIKeyAuthDisabled = errors.New("api key authentication is disabled")
+	// ErrAPIKeyNotFound key不存在或不属于当前用户
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrAPIKeyLimitReached 用户的key数量达到上限
+	ErrAPIKeyLimitReached = errors.New("api key limit reached")
+	// ErrInvalidAPIKeyExpiry 有效期超出允许的范围
+	ErrInvalidAPIKeyExpiry = errors.New("invalid api key expiry")
+	// ErrInvalidAPIKey key不存在、已删除或密钥错误
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrAPIKeyExpired key已过期
+	ErrAPIKeyExpired = errors.New("api key expired")
+	// ErrAPIKeyIPNotAllowed 客户端IP不在key允许的范围内
+	ErrAPIKeyIPNotAllowed = errors.New("client ip not allowed for api key")
+	// ErrAPIKeyOwnerDisabled key所属用户已被封禁、锁定、删除或要求重置密码
+	ErrAPIKeyOwnerDisabled = errors.New("api key owner is disabled")
+)
+
+// APIKeyInput 创建或修改API key的参数
+type APIKeyInput struct {
+	Name          string
+	Scopes        []string
+	AllowedIPs    []string
+	RateLimit     int // 0表示使用默认上限，只在创建时使用
+	ExpiresInDays int // 0表示不过期，只在创建时使用
+}
+
+// APIKeyLogic 用户API key业务逻辑接口
+type APIKeyLogic interface {
+	// CreateKey 创建key，返回key和密钥明文（只返回这一次）
+	CreateKey(ctx context.Context, userID uint, input APIKeyInput) (*mysql.APIKey, string, error)
+
+	// ListKeys 用户的全部key
+	ListKeys(ctx context.Context, userID uint) ([]*mysql.APIKey, error)
+
+	// UpdateKey 修改key的名称、权限和允许的IP
+	UpdateKey(ctx context.Context, userID uint, keyID string, input APIKeyInput) (*mysql.APIKey, error)
+
+	// DeleteKey 删除key，使用该key的请求立即失效
+	DeleteKey(ctx context.Context, userID uint, keyID string) error
+
+	// AuthenticateAPIKey 校验key的密钥、有效期和允许的IP，返回key
+	AuthenticateAPIKey(ctx context.Context, keyID, secret, clientIP string) (*mysql.APIKey, error)
+
+	// CheckAPIKeyOwner 检查key所属用户能否使用key，配置中声明的key同样需要检查
+	CheckAPIKeyOwner(ctx context.Context, userID uint) error
+}
+
+// APIAPIKeyLogic 用户API key业务逻辑实现
+type APIAPIKeyLogic struct {
+	config   config.APIKeyAuthConfig
+	keyRepo  repository.APIKeyRepository
+	userRepo repository.UserRepository
+}
+
+// NewAPIAPIKeyLogic 创建用户API key业务逻辑实例
+func NewAPIAPIKeyLogic(cfg config.APIKeyAuthConfig, keyRepo repository.APIKeyRepository, userRepo repository.UserRepository) *APIAPIKeyLogic {
+	return &APIAPIKeyLogic{
+		config:   cfg,
+		keyRepo:  keyRepo,
+		userRepo: userRepo,
+	}
+}
+
+// CreateKey 创建key
+// 业务规则：
+// 1. 每个用户最多创建api_key_auth.max_user_keys个key
+// 2. 限流上限不能超过api_key_auth.user_key_rate_limit，未指定时使用该值
+// 3. 配置了最长有效期时必须指定不超过该值的有效期
+func (l *APIAPIKeyLogic) CreateKey(ctx context.Context, userID uint, input APIKeyInput) (*mysql.APIKey, string, error) {
+	if !l.config.Enabled {
+		return nil, "", ErrAPIKeyAuthDisabled
+	}
+
+	count, err := l.keyRepo.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, "", fmt.Errorf("查询API key数量失败: %w", err)
+	}
+	if count >= int64(l.config.MaxUserKeys) {
+		return nil, "", ErrAPIKeyLimitReached
+	}
+
+	rateLimit := input.RateLimit
+	if rateLimit <= 0 || rateLimit > l.config.UserKeyRateLimit {
+		rateLimit = l.config.UserKeyRateLimit
+	}
+	if l.config.UserKeyMaxTTLDays > 0 && (input.ExpiresInDays <= 0 || input.ExpiresInDays > l.config.UserKeyMaxTTLDays) {
+		return nil, "", fmt.Errorf("%w: expires_in_days must be between 1 and %d", ErrInvalidAPIKeyExpiry, l.config.UserKeyMaxTTLDays)
+	}
+
+	keyID, err := randomHex(apiKeyIDBytes)
+	if err != nil {
+		return nil, "", fmt.Errorf("生成API key失败: %w", err)
+	}
+	secret, err := randomHex(apiKeySecretBytes)
+	if err != nil {
+		return nil, "", fmt.Errorf("生成API key失败: %w", err)
+	}
+
+	key := &mysql.APIKey{
+		KeyID:         apiKeyIDPrefix + keyID,
+		UserID:        userID,
+		Name:          input.Name,
+		SecretHash:    hashAPIKeySecret(secret),
+		RateLimit:     rateLimit,
+		WindowSeconds: l.config.UserKeyWindowSeconds,
+	}
+	key.SetScopes(input.Scopes)
+	key.SetAllowedIPs(input.AllowedIPs)
+	if input.ExpiresInDays > 0 {
+		expiresAt := clock.Now().AddDate(0, 0, input.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := l.keyRepo.Create(ctx, key); err != nil {
+		return nil, "", fmt.Errorf("创建API key失败: %w", err)
+	}
+
+	appLogger.Security("用户创建API key", map[string]interface{}{
+		"user_id":     userID,
+		"key_id":      key.KeyID,
+		"scopes":      key.Scopes,
+		"allowed_ips": key.AllowedIPs,
+	})
+	return key, secret, nil
+}
+
+// ListKeys 用户的全部key
+func (l *APIAPIKeyLogic) ListKeys(ctx context.Context, userID uint) ([]*mysql.APIKey, error) {
+	keys, err := l.keyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("查询API key失败: %w", err)
+	}
+	return keys, nil
+}
+
+// UpdateKey 修改key的名称、权限和允许的IP，密钥、有效期和限流上限不能修改
+func (l *APIAPIKeyLogic) UpdateKey(ctx context.Context, userID uint, keyID string, input APIKeyInput) (*mysql.APIKey, error) {
+	key, err := l.getUserKey(ctx, userID, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Name = input.Name
+	key.SetScopes(input.Scopes)
+	key.SetAllowedIPs(input.AllowedIPs)
+	if err := l.keyRepo.Update(ctx, key); err != nil {
+		return nil, fmt.Errorf("更新API key失败: %w", err)
+	}
+
+	appLogger.Security("用户修改API key", map[string]interface{}{
+		"user_id":     userID,
+		"key_id":      key.KeyID,
+		"scopes":      key.Scopes,
+		"allowed_ips": key.AllowedIPs,
+	})
+	return key, nil
+}
+
+// DeleteKey 删除key
+func (l *APIAPIKeyLogic) DeleteKey(ctx context.Context, userID uint, keyID string) error {
+	key, err := l.getUserKey(ctx, userID, keyID)
+	if err != nil {
+		return err
+	}
+
+	if err := l.keyRepo.Delete(ctx, key.ID); err != nil {
+		return fmt.Errorf("删除API key失败: %w", err)
+	}
+
+	appLogger.Security("用户删除API key", map[string]interface{}{
+		"user_id": userID,
+		"key_id":  key.KeyID,
+	})
+	return nil
+}
+
+// AuthenticateAPIKey 校验key
+// key不存在和密钥错误返回相同的错误，不向请求方透露key是否存在；所属用户不能登录时返回ErrAPIKeyOwnerDisabled
+func (l *APIAPIKeyLogic) AuthenticateAPIKey(ctx context.Context, keyID, secret, clientIP string) (*mysql.APIKey, error) {
+	key, err := l.keyRepo.GetByKeyID(ctx, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("查询API key失败: %w", err)
+	}
+	if key == nil || subtle.ConstantTimeCompare([]byte(key.SecretHash), []byte(hashAPIKeySecret(secret))) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := clock.Now()
+	if key.IsExpired(now) {
+		return nil, ErrAPIKeyExpired
+	}
+	if !ipAllowed(key.AllowedIPList(), clientIP) {
+		appLogger.Security("API key在未允许的IP使用", map[string]interface{}{
+			"user_id": key.UserID,
+			"key_id":  key.KeyID,
+			"ip":      clientIP,
+		})
+		return nil, ErrAPIKeyIPNotAllowed
+	}
+
+	if err := l.CheckAPIKeyOwner(ctx, key.UserID); err != nil {
+		return nil, err
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval || key.LastUsedIP != clientIP {
+		if err := l.keyRepo.Touch(ctx, key.ID, clientIP, now); err != nil {
+			appLogger.Warn("记录API key使用时间失败", map[string]interface{}{
+				"key_id": key.KeyID,
+				"error":  err.Error(),
+			})
+		}
+	}
+	return key, nil
+}
+
+// CheckAPIKeyOwner 检查key所属用户，用户不能登录时（封禁、锁定、删除或要求重置密码）key同样不能使用，返回ErrAPIKeyOwnerDisabled
+func (l *APIAPIKeyLogic) CheckAPIKeyOwner(ctx context.Context, userID uint) error {
+	user, err := l.userRepo.GetByID(ctx, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("查询API key所属用户失败: %w", err)
+	}
+	if user == nil || !user.CanLogin() || user.IsLocked(clock.Now()) || user.PasswordResetRequired {
+		return ErrAPIKeyOwnerDisabled
+	}
+	return nil
+}
+
+// getUserKey 获取属于用户的key
+func (l *APIAPIKeyLogic) getUserKey(ctx context.Context, userID uint, keyID string) (*mysql.APIKey, error) {
+	key, err := l.keyRepo.GetByKeyID(ctx, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("查询API key失败: %w", err)
+	}
+	if key == nil || key.UserID != userID {
+		return nil, ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+// ipAllowed 客户端IP是否在允许的范围内，未限制时全部允许
+func ipAllowed(allowed []string, clientIP string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, value := range allowed {
+		prefix, err := ipaccess.ParsePrefix(value)
+		if err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// hashAPIKeySecret 密钥的哈希
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex 随机字节的十六进制编码
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
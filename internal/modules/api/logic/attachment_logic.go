@@ -0,0 +1,288 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	mongoModel "exchange/internal/models/mongodb"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	appErrors "exchange/internal/pkg/errors"
+	"exchange/internal/pkg/objectstore"
+	"exchange/internal/repository"
+)
+
+// 保留在对象key中的文件扩展名，其他扩展名被丢弃
+var attachmentExtPattern = regexp.MustCompile(`^\.[a-z0-9]{1,10}$`)
+
+var (
+	// ErrAttachmentEmpty 上传的文件为空
+	ErrAttachmentEmpty = errors.New("attachment is empty")
+	// ErrAttachmentTooLarge 文件超过attachment.max_size_mb
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+	// ErrAttachmentTypeNotAllowed 按文件内容识别的类型不在attachment.allowed_types中
+	ErrAttachmentTypeNotAllowed = errors.New("attachment type not allowed")
+	// ErrAttachmentNotFound 附件不存在、不属于当前用户，或消息没有附件
+	ErrAttachmentNotFound = errors.New("attachment not found")
+)
+
+// Attachment 已上传的附件
+type Attachment struct {
+	Key         string
+	FileName    string
+	FileSize    int64
+	MimeType    string
+	MessageType mongoModel.MessageType
+}
+
+// AttachmentLink 附件的限时下载链接
+type AttachmentLink struct {
+	URL       string
+	ExpiresAt time.Time
+}
+
+// AttachmentLogic 聊天附件业务逻辑接口
+type AttachmentLogic interface {
+	// Upload 校验大小和类型后上传附件，返回的key用于发送文件消息
+	Upload(ctx context.Context, userID uint, fileName string, size int64, file io.Reader) (*Attachment, error)
+
+	// Resolve 发送文件消息前获取本人上传的附件信息
+	Resolve(ctx context.Context, userID uint, key, fileName string) (*Attachment, error)
+
+	// DownloadURL 生成消息附件的限时下载链接，只有会话双方或群成员可以获取
+	DownloadURL(ctx context.Context, userID uint, messageID string) (*AttachmentLink, error)
+
+	// OpenLocalDownload 校验本地存储的签名链接并打开文件（仅local驱动）
+	OpenLocalDownload(ctx context.Context, key, fileName string, expires int64, signature string) (*os.File, error)
+}
+
+// APIAttachmentLogic 聊天附件业务逻辑实现
+type APIAttachmentLogic struct {
+	config      config.AttachmentConfig
+	store       objectstore.Store
+	messageRepo repository.ConversationMessageRepository
+	groupRepo   repository.GroupRepository
+}
+
+// NewAPIAttachmentLogic 创建聊天附件业务逻辑实例
+func NewAPIAttachmentLogic(cfg *config.Config, store objectstore.Store, messageRepo repository.ConversationMessageRepository, groupRepo repository.GroupRepository) *APIAttachmentLogic {
+	return &APIAttachmentLogic{
+		config:      cfg.Attachment,
+		store:       store,
+		messageRepo: messageRepo,
+		groupRepo:   groupRepo,
+	}
+}
+
+// Upload 上传附件
+// 业务规则：
+// 1. 大小不超过attachment.max_size_mb，类型按文件内容识别，不信任客户端声明的Content-Type
+// 2. 对象key为<key_prefix>/<用户ID>/<日期>/<随机串><扩展名>，发送消息时据此确认附件属于发送者
+func (l *APIAttachmentLogic) Upload(ctx context.Context, userID uint, fileName string, size int64, file io.Reader) (*Attachment, error) {
+	if size <= 0 {
+		return nil, ErrAttachmentEmpty
+	}
+	if size > int64(l.config.MaxSizeMB)<<20 {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if errors.Is(err, io.EOF) {
+		return nil, ErrAttachmentEmpty
+	}
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("读取附件失败: %w", err)
+	}
+	head = head[:n]
+	mimeType := detectMimeType(head)
+	if !l.allowed(mimeType) {
+		return nil, ErrAttachmentTypeNotAllowed
+	}
+
+	key, err := l.newKey(userID, fileName)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.store.Put(ctx, key, io.MultiReader(bytes.NewReader(head), file), size, mimeType); err != nil {
+		return nil, fmt.Errorf("保存附件失败: %w", err)
+	}
+
+	return &Attachment{
+		Key:         key,
+		FileName:    cleanFileName(fileName),
+		FileSize:    size,
+		MimeType:    mimeType,
+		MessageType: mongoModel.FileMessageType(mimeType),
+	}, nil
+}
+
+// Resolve 获取本人上传的附件信息，大小和类型以存储中的对象为准
+func (l *APIAttachmentLogic) Resolve(ctx context.Context, userID uint, key, fileName string) (*Attachment, error) {
+	if !strings.HasPrefix(key, l.ownerPrefix(userID)) || !objectstore.ValidKey(key) {
+		return nil, ErrAttachmentNotFound
+	}
+
+	info, err := l.store.Stat(ctx, key)
+	if errors.Is(err, objectstore.ErrNotFound) {
+		return nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询附件失败: %w", err)
+	}
+
+	mimeType := normalizeMimeType(info.ContentType)
+	if fileName = cleanFileName(fileName); fileName == "" {
+		fileName = filepath.Base(key)
+	}
+	return &Attachment{
+		Key:         key,
+		FileName:    fileName,
+		FileSize:    info.Size,
+		MimeType:    mimeType,
+		MessageType: mongoModel.FileMessageType(mimeType),
+	}, nil
+}
+
+// DownloadURL 生成消息附件的下载链接
+// 单聊消息只有发送方和接收方可以下载，群聊消息只有当前群成员可以下载；
+// 其他人、已删除的消息和没有对象key的旧消息均视为附件不存在
+func (l *APIAttachmentLogic) DownloadURL(ctx context.Context, userID uint, messageID string) (*AttachmentLink, error) {
+	if _, err := primitive.ObjectIDFromHex(messageID); err != nil {
+		return nil, ErrMessageNotFound
+	}
+
+	message, err := l.messageRepo.GetByID(ctx, messageID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrMessageNotFound
+	}
+	if err != nil {
+		return nil, appErrors.TranslateMessageError(err, "查询消息失败", messageID)
+	}
+
+	self := formatUserID(userID)
+	if message.IsGroupMessage() {
+		_, err := l.groupRepo.GetMember(ctx, message.GroupID, self)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrMessageNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("查询群成员失败: %w", err)
+		}
+	} else if message.FromUserID != self && message.ToUserID != self {
+		return nil, ErrMessageNotFound
+	}
+
+	key := message.ObjectKey()
+	if message.IsDeleted() || key == "" {
+		return nil, ErrAttachmentNotFound
+	}
+
+	fileName, _ := message.Metadata["file_name"].(string)
+	expiresAt := clock.Now().Add(time.Duration(l.config.URLTTL) * time.Second)
+	url, err := l.store.PresignGet(ctx, key, fileName, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("生成附件下载链接失败: %w", err)
+	}
+	return &AttachmentLink{URL: url, ExpiresAt: expiresAt}, nil
+}
+
+// OpenLocalDownload 校验签名链接并打开本地文件，使用其他存储驱动时链接不会指向本服务
+func (l *APIAttachmentLogic) OpenLocalDownload(ctx context.Context, key, fileName string, expires int64, signature string) (*os.File, error) {
+	local, ok := l.store.(*objectstore.LocalStore)
+	if !ok {
+		return nil, ErrAttachmentNotFound
+	}
+	if err := local.Verify(ctx, key, fileName, expires, signature, clock.Now()); err != nil {
+		return nil, err
+	}
+
+	file, err := local.Open(key)
+	if errors.Is(err, objectstore.ErrNotFound) || errors.Is(err, objectstore.ErrInvalidKey) {
+		return nil, ErrAttachmentNotFound
+	}
+	return file, err
+}
+
+// allowed 类型是否在允许列表中，列表项为type/*时匹配该大类
+func (l *APIAttachmentLogic) allowed(mimeType string) bool {
+	for _, pattern := range l.config.AllowedTypes {
+		if pattern == mimeType {
+			return true
+		}
+		if strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mimeType, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// newKey 生成对象key
+func (l *APIAttachmentLogic) newKey(userID uint, fileName string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成附件key失败: %w", err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if !attachmentExtPattern.MatchString(ext) {
+		ext = ""
+	}
+	return l.ownerPrefix(userID) + clock.Now().UTC().Format("20060102") + "/" + hex.EncodeToString(buf) + ext, nil
+}
+
+// ownerPrefix 用户上传的附件的key前缀
+func (l *APIAttachmentLogic) ownerPrefix(userID uint) string {
+	prefix := strings.Trim(l.config.KeyPrefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return prefix + formatUserID(userID) + "/"
+}
+
+// detectMimeType 按文件开头的内容识别类型
+func detectMimeType(head []byte) string {
+	return normalizeMimeType(http.DetectContentType(head))
+}
+
+// normalizeMimeType 去掉类型参数（如charset）并转为小写
+func normalizeMimeType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+// cleanFileName 去掉客户端文件名中的路径和控制字符，限制长度
+func cleanFileName(fileName string) string {
+	fileName = filepath.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	fileName = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, fileName)
+	if fileName == "." || fileName == "/" {
+		return ""
+	}
+	if runes := []rune(fileName); len(runes) > 255 {
+		fileName = string(runes[:255])
+	}
+	return fileName
+}
@@ -10,9 +10,19 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 
 	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/clock"
 	"exchange/internal/pkg/config"
+	appErrors "exchange/internal/pkg/errors"
+	"exchange/internal/pkg/jwtkeys"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/loginthrottle"
+	"exchange/internal/pkg/rbac"
+	"exchange/internal/pkg/session"
+	"exchange/internal/pkg/tokenrevoke"
+	"exchange/internal/pkg/twofactor"
 	"exchange/internal/repository"
 )
 
@@ -21,20 +31,24 @@ type AuthLogic interface {
 	// Token相关方法
 	GenerateToken(userID uint, role string) (string, error)
 	ValidateToken(tokenString string) (*Claims, error)
-	RefreshToken(tokenString string) (string, error)
+
+	// 登录会话：短期访问token + 轮换的刷新token
+	IssueTokens(ctx context.Context, userID uint, role string, device session.Device) (*TokenPair, error)
+	RefreshTokens(ctx context.Context, refreshToken string, device session.Device) (*TokenPair, error)
+	RevokeSession(ctx context.Context, sessionID string) error
 
 	// 密码相关方法
 	HashPassword(password string) (string, error)
 	CheckPassword(password, hash string) bool
 
-	// 用户认证方法
-	AuthenticateUser(ctx context.Context, username, password string) (*mysql.User, error)
-	AuthenticateAdmin(ctx context.Context, username, password string) (*mysql.Admin, error)
+	// 用户认证方法，code为两步验证的验证码或备用码（未启用两步验证时忽略），clientIP用于统计登录失败次数
+	AuthenticateUser(ctx context.Context, username, password, code, clientIP string) (*mysql.User, error)
+	AuthenticateAdmin(ctx context.Context, username, password, code, clientIP string) (*mysql.Admin, error)
 
 	// Token生成方法
 	GenerateAdminToken(adminID uint, role string) (string, error)
 
-	// Token黑名单管理
+	// Token撤销（按jti记录，token过期后自动清除）
 	RevokeToken(ctx context.Context, tokenString string) error
 	IsTokenRevoked(ctx context.Context, tokenString string) (bool, error)
 
@@ -47,51 +61,85 @@ type AuthLogic interface {
 
 // Claims JWT声明结构
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Role   string `json:"role"`
+	UserID    uint   `json:"user_id"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"` // 登录会话ID，会话撤销后token失效
 	jwt.RegisteredClaims
 }
 
+// Principal token所属主体，管理员token为admin:<ID>，用户token为user:<ID>，按主体登记和撤销token
+func (c *Claims) Principal() string {
+	if _, ok := rbac.ParseAdminTokenRole(c.Role); ok {
+		return fmt.Sprintf("admin:%d", c.UserID)
+	}
+	return fmt.Sprintf("user:%d", c.UserID)
+}
+
+// TokenPair 登录签发的访问token和刷新token
+type TokenPair struct {
+	AccessToken      string
+	RefreshToken     string
+	ExpiresIn        time.Duration // 访问token有效期
+	RefreshExpiresIn time.Duration // 刷新token有效期，每次刷新后重新计算
+}
+
 // APIAuthLogic API认证业务逻辑实现
 type APIAuthLogic struct {
 	config    *config.Config
-	secretKey []byte
+	keys      *jwtkeys.Keyset
 	userRepo  repository.UserRepository
 	adminRepo repository.AdminRepository
 	cacheRepo repository.CacheRepository
+	sessions  *session.Store
+	revoked   *tokenrevoke.Store
+	twoFactor *twofactor.Service
+	throttle  *loginthrottle.Throttle
 }
 
 // NewAPIAuthLogic 创建API认证业务逻辑
-func NewAPIAuthLogic(cfg *config.Config, userRepo repository.UserRepository, adminRepo repository.AdminRepository, cacheRepo repository.CacheRepository) (*APIAuthLogic, error) {
-	// 从配置中获取密钥，如果没有则生成一个
-	secretKey := []byte(cfg.JWT.SecretKey)
-	if len(secretKey) == 0 {
-		// 生成随机密钥
-		key := make([]byte, 32)
-		if _, err := rand.Read(key); err != nil {
-			return nil, fmt.Errorf("failed to generate secret key: %w", err)
-		}
-		secretKey = key
+// keys为签发和验证JWT的密钥，所有实例（包括管理后台）必须使用相同的配置，否则token在其他实例验证失败
+// revoked为token撤销记录，签发的token按jti登记，验证时拒绝已撤销的token
+// twoFactor为nil时登录不进行两步验证，throttle为nil时不限制登录失败次数
+func NewAPIAuthLogic(cfg *config.Config, keys *jwtkeys.Keyset, userRepo repository.UserRepository, adminRepo repository.AdminRepository, cacheRepo repository.CacheRepository, sessions *session.Store, revoked *tokenrevoke.Store, twoFactor *twofactor.Service, throttle *loginthrottle.Throttle) (*APIAuthLogic, error) {
+	if keys == nil {
+		return nil, errors.New("jwt keyset is required")
 	}
 
 	return &APIAuthLogic{
 		config:    cfg,
-		secretKey: secretKey,
+		keys:      keys,
 		userRepo:  userRepo,
 		adminRepo: adminRepo,
 		cacheRepo: cacheRepo,
+		sessions:  sessions,
+		revoked:   revoked,
+		twoFactor: twoFactor,
+		throttle:  throttle,
 	}, nil
 }
 
-// GenerateToken 生成JWT token
+// GenerateToken 生成不属于登录会话的JWT token，有效期为expiration_hours
+// 用户登录使用IssueTokens签发短期访问token和刷新token
 func (l *APIAuthLogic) GenerateToken(userID uint, role string) (string, error) {
-	now := time.Now()
-	expirationTime := now.Add(time.Duration(l.config.JWT.ExpirationHours) * time.Hour)
+	return l.signToken(userID, role, "", time.Duration(l.config.JWT.ExpirationHours)*time.Hour)
+}
+
+// signToken 签发JWT token
+func (l *APIAuthLogic) signToken(userID uint, role, sessionID string, ttl time.Duration) (string, error) {
+	now := clock.Now()
+	expirationTime := now.Add(ttl)
+
+	jti, err := tokenrevoke.NewJTI()
+	if err != nil {
+		return "", err
+	}
 
 	claims := &Claims{
-		UserID: userID,
-		Role:   role,
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
@@ -100,53 +148,140 @@ func (l *APIAuthLogic) GenerateToken(userID uint, role string) (string, error) {
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(l.secretKey)
+	tokenString, err := l.keys.Sign(claims)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
 
+	// 登记到所属主体的有效token集合，撤销主体的全部token时使用
+	if l.revoked != nil {
+		if err := l.revoked.Register(context.Background(), claims.Principal(), jti, expirationTime); err != nil {
+			return "", err
+		}
+	}
+
 	return tokenString, nil
 }
 
 // ValidateToken 验证JWT token
 func (l *APIAuthLogic) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return l.secretKey, nil
-	})
+	token, err := l.keys.Parse(tokenString, &Claims{}, jwt.WithTimeFunc(clock.Now)) // 过期时间按业务时间校验（模拟时钟）
 
 	if err != nil {
 		return nil, fmt.Errorf("invalid token: %w", err)
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	// 已按jti撤销的token拒绝访问，查询失败时与会话检查一样放行，只记录日志
+	if claims.ID != "" && l.revoked != nil {
+		revoked, err := l.revoked.IsRevoked(context.Background(), claims.ID)
+		if err != nil {
+			appLogger.Warn("查询token撤销记录失败", map[string]interface{}{
+				"jti":   claims.ID,
+				"error": err.Error(),
+			})
+		} else if revoked {
+			return nil, fmt.Errorf("invalid token: %w", tokenrevoke.ErrRevoked)
+		}
+	}
+
+	// 登录会话已撤销（退出登录或检测到刷新token被重复使用）时拒绝该会话的访问token
+	// 查询会话失败时放行，访问token有效期很短，只记录日志
+	// 会话有效时顺带更新最近使用时间
+	if claims.SessionID != "" && l.sessions != nil {
+		sess, err := l.sessions.Get(context.Background(), claims.SessionID)
+		if err != nil {
+			if errors.Is(err, session.ErrSessionRevoked) {
+				return nil, fmt.Errorf("invalid token: %w", err)
+			}
+			appLogger.Warn("查询登录会话失败", map[string]interface{}{
+				"session_id": claims.SessionID,
+				"error":      err.Error(),
+			})
+		} else if err := l.sessions.Touch(context.Background(), sess); err != nil {
+			appLogger.Warn("更新登录会话最近使用时间失败", map[string]interface{}{
+				"session_id": claims.SessionID,
+				"error":      err.Error(),
+			})
+		}
 	}
 
-	return nil, fmt.Errorf("invalid token claims")
+	return claims, nil
 }
 
-// RefreshToken 刷新token
-func (l *APIAuthLogic) RefreshToken(tokenString string) (string, error) {
-	claims, err := l.ValidateToken(tokenString)
+// IssueTokens 创建登录会话，签发短期访问token和刷新token，device为登录设备
+func (l *APIAuthLogic) IssueTokens(ctx context.Context, userID uint, role string, device session.Device) (*TokenPair, error) {
+	if l.sessions == nil {
+		return nil, errors.New("session store not configured")
+	}
+	sess, refreshToken, err := l.sessions.Create(ctx, userID, role, device)
 	if err != nil {
-		return "", fmt.Errorf("invalid token: %w", err)
+		return nil, err
 	}
+	return l.tokenPair(sess, refreshToken)
+}
 
-	// 检查token是否被撤销
-	revoked, err := l.IsTokenRevoked(context.Background(), tokenString)
+// RefreshTokens 使用刷新token换取新的访问token和刷新token，旧的刷新token随即失效
+// 已使用过的刷新token再次出现时撤销整个会话并返回session.ErrRefreshTokenReused；
+// 用户已不能登录（封禁、锁定、注销或要求重置密码）时撤销会话并返回session.ErrSessionRevoked
+func (l *APIAuthLogic) RefreshTokens(ctx context.Context, refreshToken string, device session.Device) (*TokenPair, error) {
+	if l.sessions == nil {
+		return nil, errors.New("session store not configured")
+	}
+	sess, next, err := l.sessions.Rotate(ctx, refreshToken, device)
 	if err != nil {
-		return "", fmt.Errorf("failed to check token revocation: %w", err)
+		if errors.Is(err, session.ErrRefreshTokenReused) {
+			appLogger.Warn("刷新token被重复使用，已撤销会话", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		return nil, err
 	}
-	if revoked {
-		return "", errors.New("token has been revoked")
+
+	// 刷新token有效期长且每次刷新重新计算，每次刷新都重新检查用户状态
+	user, err := l.userRepo.GetByID(ctx, sess.UserID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("查询用户失败: %w", err)
 	}
+	if user == nil || !user.CanLogin() || user.IsLocked(clock.Now()) || user.PasswordResetRequired {
+		if err := l.sessions.Revoke(ctx, sess.ID); err != nil {
+			appLogger.Warn("撤销不能登录用户的会话失败", map[string]interface{}{
+				"user_id":    sess.UserID,
+				"session_id": sess.ID,
+				"error":      err.Error(),
+			})
+		}
+		return nil, fmt.Errorf("user can no longer log in: %w", session.ErrSessionRevoked)
+	}
+
+	return l.tokenPair(sess, next)
+}
 
-	// 生成新token
-	return l.GenerateToken(claims.UserID, claims.Role)
+// RevokeSession 撤销登录会话（退出登录），该会话的刷新token和访问token均失效
+func (l *APIAuthLogic) RevokeSession(ctx context.Context, sessionID string) error {
+	if l.sessions == nil || sessionID == "" {
+		return nil
+	}
+	return l.sessions.Revoke(ctx, sessionID)
+}
+
+// tokenPair 为会话签发访问token
+func (l *APIAuthLogic) tokenPair(sess *session.Session, refreshToken string) (*TokenPair, error) {
+	accessTTL := time.Duration(l.config.JWT.AccessTokenMinutes) * time.Minute
+	accessToken, err := l.signToken(sess.UserID, sess.Role, sess.ID, accessTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		ExpiresIn:        accessTTL,
+		RefreshExpiresIn: l.sessions.TTL(),
+	}, nil
 }
 
 // HashPassword 哈希密码
@@ -165,7 +300,28 @@ func (l *APIAuthLogic) CheckPassword(password, hash string) bool {
 }
 
 // AuthenticateUser 用户认证
-func (l *APIAuthLogic) AuthenticateUser(ctx context.Context, username, password string) (*mysql.User, error) {
+// 用户名或客户端IP登录失败次数过多时直接返回appErrors.ErrAccountLocked，不校验密码
+// 已启用两步验证时校验code；角色强制启用两步验证但未启用时返回*twofactor.SetupRequiredError
+func (l *APIAuthLogic) AuthenticateUser(ctx context.Context, username, password, code, clientIP string) (*mysql.User, error) {
+	if err := l.throttle.Check(ctx, loginthrottle.ScopeUser, username, clientIP); err != nil {
+		return nil, err
+	}
+
+	user, err := l.authenticateUser(ctx, username, password, code)
+	if err != nil {
+		// 需要输入两步验证码或完成绑定、账户被锁定或需要重置密码时不计为失败
+		if !errors.Is(err, twofactor.ErrCodeRequired) && !errors.Is(err, twofactor.ErrSetupRequired) && !IsAccountRestricted(err) {
+			l.throttle.Fail(ctx, loginthrottle.ScopeUser, username, clientIP)
+		}
+		return nil, err
+	}
+
+	l.throttle.Succeed(ctx, loginthrottle.ScopeUser, username)
+	return user, nil
+}
+
+// authenticateUser 校验用户名、密码和两步验证码
+func (l *APIAuthLogic) authenticateUser(ctx context.Context, username, password, code string) (*mysql.User, error) {
 	// 获取用户
 	user, err := l.userRepo.GetByUsername(ctx, username)
 	if err != nil {
@@ -182,6 +338,22 @@ func (l *APIAuthLogic) AuthenticateUser(ctx context.Context, username, password
 		return nil, errors.New("invalid password")
 	}
 
+	// 管理员锁定或要求重置密码（密码正确后才提示，不向猜测密码的请求透露账户状态）
+	if user.IsLocked(clock.Now()) {
+		return nil, appErrors.AccountSuspendedError(user.LockedUntil.Format(time.RFC3339))
+	}
+	if user.PasswordResetRequired {
+		return nil, appErrors.ErrPasswordResetRequired
+	}
+
+	// 两步验证
+	if l.twoFactor != nil {
+		subject := twofactor.Subject{Type: mysql.TwoFactorSubjectUser, ID: user.ID}
+		if err := l.twoFactor.CheckLogin(ctx, subject, user.TwoFactorEnabled, string(user.Role), user.Username, code); err != nil {
+			return nil, err
+		}
+	}
+
 	// 更新登录信息
 	user.UpdateLoginInfo()
 	if err := l.userRepo.UpdateLastLogin(ctx, user.ID); err != nil {
@@ -193,7 +365,28 @@ func (l *APIAuthLogic) AuthenticateUser(ctx context.Context, username, password
 }
 
 // AuthenticateAdmin 管理员认证
-func (l *APIAuthLogic) AuthenticateAdmin(ctx context.Context, username, password string) (*mysql.Admin, error) {
+// 用户名或客户端IP登录失败次数过多时直接返回appErrors.ErrAccountLocked，不校验密码
+// 已启用两步验证时校验code；角色强制启用两步验证但未启用时返回*twofactor.SetupRequiredError
+func (l *APIAuthLogic) AuthenticateAdmin(ctx context.Context, username, password, code, clientIP string) (*mysql.Admin, error) {
+	if err := l.throttle.Check(ctx, loginthrottle.ScopeAdmin, username, clientIP); err != nil {
+		return nil, err
+	}
+
+	admin, err := l.authenticateAdmin(ctx, username, password, code)
+	if err != nil {
+		// 需要输入两步验证码或完成绑定时不计为失败
+		if !errors.Is(err, twofactor.ErrCodeRequired) && !errors.Is(err, twofactor.ErrSetupRequired) {
+			l.throttle.Fail(ctx, loginthrottle.ScopeAdmin, username, clientIP)
+		}
+		return nil, err
+	}
+
+	l.throttle.Succeed(ctx, loginthrottle.ScopeAdmin, username)
+	return admin, nil
+}
+
+// authenticateAdmin 校验用户名、密码和两步验证码
+func (l *APIAuthLogic) authenticateAdmin(ctx context.Context, username, password, code string) (*mysql.Admin, error) {
 	// 获取管理员
 	admin, err := l.adminRepo.GetByUsername(ctx, username)
 	if err != nil {
@@ -210,6 +403,14 @@ func (l *APIAuthLogic) AuthenticateAdmin(ctx context.Context, username, password
 		return nil, errors.New("invalid password")
 	}
 
+	// 两步验证
+	if l.twoFactor != nil {
+		subject := twofactor.Subject{Type: mysql.TwoFactorSubjectAdmin, ID: admin.ID}
+		if err := l.twoFactor.CheckLogin(ctx, subject, admin.TwoFactorEnabled, string(admin.Role), admin.Username, code); err != nil {
+			return nil, err
+		}
+	}
+
 	// 更新登录信息
 	admin.UpdateLoginInfo()
 	if err := l.adminRepo.UpdateLastLogin(ctx, admin.ID); err != nil {
@@ -222,46 +423,32 @@ func (l *APIAuthLogic) AuthenticateAdmin(ctx context.Context, username, password
 
 // GenerateAdminToken 生成管理员token
 func (l *APIAuthLogic) GenerateAdminToken(adminID uint, role string) (string, error) {
-	// 管理员token带有"admin:"前缀
-	adminRole := "admin:" + role
-	return l.GenerateToken(adminID, adminRole)
+	// 管理员token的角色带有前缀，与用户token区分
+	return l.GenerateToken(adminID, rbac.AdminTokenRole(role))
 }
 
-// RevokeToken 撤销token
+// RevokeToken 按jti撤销token，撤销后至过期前验证失败；未带jti的旧token无法单独撤销
 func (l *APIAuthLogic) RevokeToken(ctx context.Context, tokenString string) error {
-	// 解析token获取过期时间
 	claims, err := l.ValidateToken(tokenString)
 	if err != nil {
 		return fmt.Errorf("invalid token: %w", err)
 	}
-
-	// 计算剩余时间
-	expirationTime := time.Unix(claims.ExpiresAt.Unix(), 0)
-	remainingTime := time.Until(expirationTime)
-
-	if remainingTime <= 0 {
-		// token已过期，无需撤销
-		return nil
+	if claims.ID == "" || l.revoked == nil {
+		return errors.New("token cannot be revoked individually")
 	}
-
-	// 将token加入黑名单
-	key := l.getRevokedTokenKey(tokenString)
-	return l.cacheRepo.Set(key, "revoked", remainingTime)
+	return l.revoked.Revoke(ctx, claims.Principal(), claims.ID, claims.ExpiresAt.Time)
 }
 
 // IsTokenRevoked 检查token是否被撤销
 func (l *APIAuthLogic) IsTokenRevoked(ctx context.Context, tokenString string) (bool, error) {
-	key := l.getRevokedTokenKey(tokenString)
-	exists, err := l.cacheRepo.Exists(key)
-	if err != nil {
-		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	claims := &Claims{}
+	if _, err := l.keys.Parse(tokenString, claims, jwt.WithTimeFunc(clock.Now)); err != nil {
+		return false, fmt.Errorf("invalid token: %w", err)
 	}
-	return exists, nil
-}
-
-// getRevokedTokenKey 获取撤销token的缓存键
-func (l *APIAuthLogic) getRevokedTokenKey(tokenString string) string {
-	return "revoked_token:" + tokenString
+	if claims.ID == "" || l.revoked == nil {
+		return false, nil
+	}
+	return l.revoked.IsRevoked(ctx, claims.ID)
 }
 
 // ValidatePasswordStrength 验证密码强度
@@ -308,3 +495,8 @@ func (l *APIAuthLogic) GenerateRandomToken(length int) (string, error) {
 
 	return hex.EncodeToString(bytes), nil
 }
+
+// IsAccountRestricted 登录失败是否因为账户被管理员锁定或要求重置密码（密码已校验通过）
+func IsAccountRestricted(err error) bool {
+	return errors.Is(err, appErrors.ErrAccountSuspended) || errors.Is(err, appErrors.ErrPasswordResetRequired)
+}
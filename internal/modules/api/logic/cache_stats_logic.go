@@ -0,0 +1,33 @@
+package logic
+
+import (
+	"context"
+
+	"exchange/internal/pkg/cache"
+)
+
+// CacheStatsLogic 缓存统计查询业务逻辑接口（供监控系统通过内部接口拉取）
+type CacheStatsLogic interface {
+	// GetStats 获取本实例的缓存统计：内存缓存的容量和淘汰统计，内存缓存和Redis的命中、未命中、错误数和平均耗时
+	GetStats(ctx context.Context) map[string]interface{}
+}
+
+// APICacheStatsLogic 缓存统计查询业务逻辑实现
+type APICacheStatsLogic struct {
+	cacheManager *cache.CacheManager
+}
+
+// NewAPICacheStatsLogic 创建缓存统计查询业务逻辑实例，cacheManager为nil时（未配置Redis）返回空统计
+func NewAPICacheStatsLogic(cacheManager *cache.CacheManager) *APICacheStatsLogic {
+	return &APICacheStatsLogic{
+		cacheManager: cacheManager,
+	}
+}
+
+// GetStats 获取本实例的缓存统计
+func (l *APICacheStatsLogic) GetStats(ctx context.Context) map[string]interface{} {
+	if l.cacheManager == nil {
+		return map[string]interface{}{}
+	}
+	return l.cacheManager.GetCacheStats()
+}
@@ -0,0 +1,399 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	mongoModel "exchange/internal/models/mongodb"
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	appErrors "exchange/internal/pkg/errors"
+	"exchange/internal/pkg/export"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/repository"
+)
+
+// 会话导出错误
+var (
+	ErrChatExportNotFound    = errors.New("导出任务不存在")
+	ErrChatExportInProgress  = errors.New("已有导出任务正在生成，请稍后再试")
+	ErrChatExportUnavailable = errors.New("导出文件不存在或已过期")
+)
+
+// errTooManyMessages 消息数超过单次导出上限
+var errTooManyMessages = errors.New("会话消息数超过单次导出上限，请缩小时间范围")
+
+var exportLogger = appLogger.Module("export")
+
+// ChatExportLogic 会话导出业务逻辑接口
+type ChatExportLogic interface {
+	// RequestExport 创建会话导出任务并在后台生成文件，from、to为零值时不限时间范围
+	RequestExport(ctx context.Context, userID, peerID uint, format string, from, to time.Time) (*mysql.ChatExportJob, error)
+
+	// GetExport 获取导出任务，文件可下载时附带限时下载链接
+	GetExport(ctx context.Context, userID, jobID uint) (*ChatExportInfo, error)
+
+	// OpenDownload 验证下载链接并打开导出文件
+	OpenDownload(ctx context.Context, jobID uint, expires int64, signature string) (*mysql.ChatExportJob, export.File, error)
+
+	// GetConsent 获取用户是否同意对方导出包含其消息内容的会话
+	GetConsent(ctx context.Context, userID uint) (bool, error)
+
+	// SetConsent 设置导出授权
+	SetConsent(ctx context.Context, userID uint, allow bool) error
+
+	// CleanupExpired 删除已过期的导出文件，返回清理数量
+	CleanupExpired(ctx context.Context, limit int) (int, error)
+}
+
+// ChatExportInfo 导出任务详情
+type ChatExportInfo struct {
+	*mysql.ChatExportJob
+	DownloadURL   string `json:"download_url,omitempty"`    // 限时下载链接，文件可下载时返回
+	LinkExpiresAt int64  `json:"link_expires_at,omitempty"` // 下载链接过期时间（Unix秒）
+}
+
+// APIChatExportLogic 会话导出业务逻辑实现
+type APIChatExportLogic struct {
+	config      config.ExportConfig
+	userRepo    repository.UserRepository
+	exportRepo  repository.ChatExportRepository
+	messageRepo repository.ConversationMessageRepository
+	storage     export.Storage
+	signer      *export.LinkSigner
+}
+
+// NewAPIChatExportLogic 创建会话导出业务逻辑实例
+func NewAPIChatExportLogic(cfg *config.Config, userRepo repository.UserRepository, exportRepo repository.ChatExportRepository, messageRepo repository.ConversationMessageRepository, storage export.Storage, signer *export.LinkSigner) *APIChatExportLogic {
+	return &APIChatExportLogic{
+		config:      cfg.Export,
+		userRepo:    userRepo,
+		exportRepo:  exportRepo,
+		messageRepo: messageRepo,
+		storage:     storage,
+		signer:      signer,
+	}
+}
+
+// RequestExport 创建会话导出任务
+// 每个用户同时只能有一个生成中的任务，避免大量导出占用数据库和存储
+func (l *APIChatExportLogic) RequestExport(ctx context.Context, userID, peerID uint, format string, from, to time.Time) (*mysql.ChatExportJob, error) {
+	if _, err := l.userRepo.GetByID(ctx, peerID); err != nil {
+		return nil, appErrors.TranslateUserError(err, "查询会话对方失败", peerID)
+	}
+
+	unfinished, err := l.exportRepo.CountUnfinishedJobs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if unfinished > 0 {
+		return nil, ErrChatExportInProgress
+	}
+
+	job := &mysql.ChatExportJob{
+		UserID: userID,
+		PeerID: peerID,
+		Format: format,
+		Status: mysql.ChatExportStatusPending,
+	}
+	if !from.IsZero() {
+		job.FromTime = from.UnixNano()
+	}
+	if !to.IsZero() {
+		job.ToTime = to.UnixNano()
+	}
+	if err := l.exportRepo.CreateJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("创建导出任务失败: %w", err)
+	}
+
+	// 返回创建时的快照，后台执行过程中会持续修改job
+	snapshot := *job
+
+	// 请求结束后任务仍需继续执行，不使用请求的context
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				l.finishJob(context.Background(), job, fmt.Errorf("导出任务异常: %v", r))
+			}
+		}()
+		l.execute(context.Background(), job)
+	}()
+
+	return &snapshot, nil
+}
+
+// GetExport 获取导出任务
+func (l *APIChatExportLogic) GetExport(ctx context.Context, userID, jobID uint) (*ChatExportInfo, error) {
+	job, err := l.exportRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil || job.UserID != userID {
+		return nil, ErrChatExportNotFound
+	}
+
+	info := &ChatExportInfo{ChatExportJob: job}
+	if !job.IsDownloadable(clock.Now().UnixNano()) {
+		return info, nil
+	}
+
+	// 链接有效期不超过文件过期时间
+	expiresAt := time.Now().Add(time.Duration(l.config.LinkTTL) * time.Second)
+	if fileExpiresAt := time.Unix(0, job.ExpiresAt).Add(-clock.Offset()); fileExpiresAt.Before(expiresAt) {
+		expiresAt = fileExpiresAt
+	}
+	link, err := l.signer.Sign(ctx, l.config.BaseURL, job.ID, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("生成下载链接失败: %w", err)
+	}
+	info.DownloadURL = link
+	info.LinkExpiresAt = expiresAt.Unix()
+	return info, nil
+}
+
+// OpenDownload 验证下载链接并打开导出文件
+func (l *APIChatExportLogic) OpenDownload(ctx context.Context, jobID uint, expires int64, signature string) (*mysql.ChatExportJob, export.File, error) {
+	if err := l.signer.Verify(ctx, jobID, expires, signature, time.Now()); err != nil {
+		return nil, nil, err
+	}
+
+	job, err := l.exportRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job == nil || !job.IsDownloadable(clock.Now().UnixNano()) {
+		return nil, nil, ErrChatExportUnavailable
+	}
+
+	file, err := l.storage.Open(job.FileName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrChatExportUnavailable, err)
+	}
+	return job, file, nil
+}
+
+// GetConsent 获取导出授权，未设置时使用配置的默认值
+func (l *APIChatExportLogic) GetConsent(ctx context.Context, userID uint) (bool, error) {
+	consent, err := l.exportRepo.GetConsent(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if consent == nil {
+		return l.config.DefaultConsent, nil
+	}
+	return consent.Allow, nil
+}
+
+// SetConsent 设置导出授权
+func (l *APIChatExportLogic) SetConsent(ctx context.Context, userID uint, allow bool) error {
+	if err := l.exportRepo.SetConsent(ctx, &mysql.ChatExportConsent{UserID: userID, Allow: allow}); err != nil {
+		return fmt.Errorf("设置导出授权失败: %w", err)
+	}
+	return nil
+}
+
+// CleanupExpired 删除已过期的导出文件
+func (l *APIChatExportLogic) CleanupExpired(ctx context.Context, limit int) (int, error) {
+	jobs, err := l.exportRepo.GetExpiredJobs(ctx, clock.Now().UnixNano(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	cleaned := 0
+	for _, job := range jobs {
+		if err := ctx.Err(); err != nil {
+			return cleaned, err
+		}
+
+		if err := l.storage.Delete(job.FileName); err != nil {
+			return cleaned, fmt.Errorf("删除导出文件 %d 失败: %w", job.ID, err)
+		}
+		job.Status = mysql.ChatExportStatusExpired
+		job.FileName = ""
+		if err := l.exportRepo.UpdateJob(ctx, job); err != nil {
+			return cleaned, err
+		}
+		cleaned++
+	}
+
+	return cleaned, nil
+}
+
+// execute 生成导出文件
+// 同意规则：发起人自己发送的消息完整导出；对方发送的消息仅在对方同意导出时保留内容，
+// 否则内容替换为[redacted]并去除附件等元数据；对方账户已注销时视为不同意
+func (l *APIChatExportLogic) execute(ctx context.Context, job *mysql.ChatExportJob) {
+	job.Status = mysql.ChatExportStatusRunning
+	job.StartedAt = clock.Now().UnixNano()
+	if err := l.exportRepo.UpdateJob(ctx, job); err != nil {
+		l.finishJob(ctx, job, fmt.Errorf("更新导出任务状态失败: %w", err))
+		return
+	}
+
+	header, err := l.buildHeader(ctx, job)
+	if err != nil {
+		l.finishJob(ctx, job, err)
+		return
+	}
+	peerConsent := header.Participants[1].Consent
+
+	fileName := fmt.Sprintf("chat_export_%d_%d.%s", job.ID, job.StartedAt, job.Format)
+	file, err := l.storage.Create(fileName)
+	if err != nil {
+		l.finishJob(ctx, job, err)
+		return
+	}
+	counter := &countingWriter{w: file}
+	writer, err := export.NewConversationWriter(job.Format, counter)
+	if err != nil {
+		file.Abort()
+		l.finishJob(ctx, job, err)
+		return
+	}
+
+	var from, to time.Time
+	if job.FromTime > 0 {
+		from = time.Unix(0, job.FromTime)
+	}
+	if job.ToTime > 0 {
+		to = time.Unix(0, job.ToTime)
+	}
+
+	err = writer.Begin(header)
+	if err == nil {
+		userID := strconv.FormatUint(uint64(job.UserID), 10)
+		peerID := strconv.FormatUint(uint64(job.PeerID), 10)
+		err = l.messageRepo.ForEachConversationMessage(ctx, userID, peerID, from, to, func(message *mongoModel.ChatMessage) error {
+			if job.MessageCount >= l.config.MaxMessages {
+				return errTooManyMessages
+			}
+
+			message.Redact()
+			exported := toExportMessage(message)
+			if exported.FromUserID != job.UserID && !peerConsent {
+				exported.Content = export.RedactedContent
+				exported.Metadata = nil
+				exported.Redacted = true
+				job.RedactedCount++
+			}
+			job.MessageCount++
+			return writer.Write(exported)
+		})
+	}
+	if err == nil {
+		err = writer.End()
+	}
+	if err != nil {
+		file.Abort()
+		l.finishJob(ctx, job, fmt.Errorf("生成导出文件失败: %w", err))
+		return
+	}
+	if err := file.Close(); err != nil {
+		l.finishJob(ctx, job, err)
+		return
+	}
+
+	job.FileName = fileName
+	job.FileSize = counter.n
+	job.ExpiresAt = clock.Now().Add(time.Duration(l.config.FileTTLHours) * time.Hour).UnixNano()
+	l.finishJob(ctx, job, nil)
+
+	appLogger.Audit("用户导出会话记录", map[string]interface{}{
+		"user_id":        job.UserID,
+		"peer_id":        job.PeerID,
+		"job_id":         job.ID,
+		"message_count":  job.MessageCount,
+		"redacted_count": job.RedactedCount,
+	})
+}
+
+// buildHeader 构造文件头，参与者顺序为发起人、对方
+func (l *APIChatExportLogic) buildHeader(ctx context.Context, job *mysql.ChatExportJob) (*export.ConversationHeader, error) {
+	user, err := l.userRepo.GetByID(ctx, job.UserID)
+	if err != nil {
+		return nil, appErrors.TranslateUserError(err, "查询用户失败", job.UserID)
+	}
+	peer, err := l.userRepo.GetByID(ctx, job.PeerID)
+	if err != nil {
+		return nil, appErrors.TranslateUserError(err, "查询会话对方失败", job.PeerID)
+	}
+
+	peerConsent := false
+	if !peer.IsAccountDeleted() {
+		if peerConsent, err = l.GetConsent(ctx, peer.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	header := &export.ConversationHeader{
+		ExportedAt:  clock.Now(),
+		RequestedBy: job.UserID,
+		Participants: []export.Participant{
+			{UserID: user.ID, Username: user.Username, Consent: true},
+			{UserID: peer.ID, Username: peer.Username, Consent: peerConsent},
+		},
+	}
+	if job.FromTime > 0 {
+		from := time.Unix(0, job.FromTime)
+		header.From = &from
+	}
+	if job.ToTime > 0 {
+		to := time.Unix(0, job.ToTime)
+		header.To = &to
+	}
+	return header, nil
+}
+
+// finishJob 结束导出任务，err不为空时任务标记为失败
+func (l *APIChatExportLogic) finishJob(ctx context.Context, job *mysql.ChatExportJob, err error) {
+	job.Status = mysql.ChatExportStatusCompleted
+	if err != nil {
+		job.Status = mysql.ChatExportStatusFailed
+		job.Error = err.Error()
+		exportLogger.Warn("会话导出失败", map[string]interface{}{
+			"job_id": job.ID,
+			"error":  err.Error(),
+		})
+	}
+	job.FinishedAt = clock.Now().UnixNano()
+
+	// 任务被取消时仍需记录最终状态
+	if updateErr := l.exportRepo.UpdateJob(context.WithoutCancel(ctx), job); updateErr != nil {
+		exportLogger.Error("更新导出任务状态失败", map[string]interface{}{
+			"job_id": job.ID,
+			"error":  updateErr.Error(),
+		})
+	}
+}
+
+// toExportMessage 转换为导出的消息
+func toExportMessage(message *mongoModel.ChatMessage) *export.Message {
+	fromUserID, _ := strconv.ParseUint(message.FromUserID, 10, 64)
+	toUserID, _ := strconv.ParseUint(message.ToUserID, 10, 64)
+	return &export.Message{
+		ID:         message.ID.Hex(),
+		FromUserID: uint(fromUserID),
+		ToUserID:   uint(toUserID),
+		Type:       string(message.MessageType),
+		Content:    message.Content,
+		Metadata:   message.Metadata,
+		CreatedAt:  message.CreatedAt,
+	}
+}
+
+// countingWriter 统计写入的字节数
+type countingWriter struct {
+	w interface{ Write([]byte) (int, error) }
+	n int64
+}
+
+// Write 写入并计数
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
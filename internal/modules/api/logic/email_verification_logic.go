@@ -0,0 +1,131 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/emailverify"
+	appErrors "exchange/internal/pkg/errors"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/notification"
+	"exchange/internal/repository"
+)
+
+// EmailVerificationLogic 邮箱验证业务逻辑接口
+type EmailVerificationLogic interface {
+	// SendVerification 向用户当前邮箱发送验证邮件，已验证时返回emailverify.ErrAlreadyVerified
+	SendVerification(ctx context.Context, userID uint) error
+
+	// VerifyEmail 使用验证token完成验证
+	VerifyEmail(ctx context.Context, token string) error
+
+	// RequireVerifiedEmail 敏感操作（如提现、会话导出）前检查邮箱是否已验证，未验证时返回emailverify.ErrNotVerified
+	// 配置account.email_verification_required为false时不检查
+	RequireVerifiedEmail(ctx context.Context, userID uint) error
+}
+
+// APIEmailVerificationLogic 邮箱验证业务逻辑实现
+type APIEmailVerificationLogic struct {
+	config   *config.Config
+	userRepo repository.UserRepository
+	tokens   *emailverify.Store
+	notifier notification.Notifier
+}
+
+// NewAPIEmailVerificationLogic 创建邮箱验证业务逻辑实例
+func NewAPIEmailVerificationLogic(cfg *config.Config, userRepo repository.UserRepository, tokens *emailverify.Store, notifier notification.Notifier) *APIEmailVerificationLogic {
+	return &APIEmailVerificationLogic{
+		config:   cfg,
+		userRepo: userRepo,
+		tokens:   tokens,
+		notifier: notifier,
+	}
+}
+
+// SendVerification 签发验证token并发送验证邮件
+func (l *APIEmailVerificationLogic) SendVerification(ctx context.Context, userID uint) error {
+	user, err := l.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return appErrors.TranslateUserError(err, "查询用户失败", userID)
+	}
+	if user == nil {
+		return appErrors.ErrUserNotFound.WithContext("user_id", userID)
+	}
+	if user.IsEmailVerified() {
+		return emailverify.ErrAlreadyVerified
+	}
+
+	token, expiresAt, err := l.tokens.Issue(ctx, user.ID, user.Email)
+	if err != nil {
+		return err
+	}
+
+	if err := l.notifier.Notify(ctx, &notification.Notification{
+		UserID:   user.ID,
+		Event:    "email_verification_requested",
+		Email:    user.Email,
+		Language: user.Language,
+		Data: map[string]interface{}{
+			"verify_link": l.config.Account.EmailVerificationURL + token,
+			"expires_at":  expiresAt,
+		},
+		CreatedAt: clock.Now(),
+	}); err != nil {
+		return fmt.Errorf("发送验证邮件失败: %w", err)
+	}
+	return nil
+}
+
+// VerifyEmail 完成邮箱验证
+// 业务规则：
+// 1. 验证token有效期内只能使用一次，签发新token后旧token失效
+// 2. token签发后修改过邮箱的，token失效，需要向新邮箱重新发送
+func (l *APIEmailVerificationLogic) VerifyEmail(ctx context.Context, token string) error {
+	userID, email, err := l.tokens.Consume(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	user, err := l.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return appErrors.TranslateUserError(err, "查询用户失败", userID)
+	}
+	if user == nil || user.IsAccountDeleted() || !strings.EqualFold(user.Email, email) {
+		return emailverify.ErrInvalidToken
+	}
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	user.MarkEmailVerified()
+	if err := l.userRepo.Update(ctx, user); err != nil {
+		return appErrors.TranslateUserError(err, "用户更新失败", user.ID)
+	}
+
+	appLogger.Module("user").WithContext(ctx).Info("用户已验证邮箱", map[string]interface{}{
+		"user_id": user.ID,
+	})
+	return nil
+}
+
+// RequireVerifiedEmail 检查邮箱是否已验证
+func (l *APIEmailVerificationLogic) RequireVerifiedEmail(ctx context.Context, userID uint) error {
+	if !l.config.Account.EmailVerificationRequired {
+		return nil
+	}
+
+	user, err := l.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return appErrors.TranslateUserError(err, "查询用户失败", userID)
+	}
+	if user == nil {
+		return appErrors.ErrUserNotFound.WithContext("user_id", userID)
+	}
+	if !user.IsEmailVerified() {
+		return emailverify.ErrNotVerified
+	}
+	return nil
+}
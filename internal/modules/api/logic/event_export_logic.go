@@ -0,0 +1,77 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"exchange/internal/pkg/eventlog"
+	"exchange/internal/pkg/export"
+)
+
+// maxEventExportRangeDays 一次查询manifest的最大日期范围（天）
+const maxEventExportRangeDays = 31
+
+// 领域事件导出错误
+var (
+	ErrEventExportDateInvalid = errors.New("日期格式应为YYYY-MM-DD，且开始日期不能晚于结束日期")
+	ErrEventExportRangeTooBig = errors.New("日期范围不能超过31天")
+)
+
+// EventExportLogic 领域事件导出查询业务逻辑接口（供数据仓库通过内部接口拉取）
+type EventExportLogic interface {
+	// ListManifests 获取[from, to]内已导出分区的manifest，日期格式为YYYY-MM-DD（UTC）
+	ListManifests(ctx context.Context, from, to string) ([]*eventlog.Manifest, error)
+
+	// GetManifest 获取分区manifest
+	GetManifest(ctx context.Context, date string) (*eventlog.Manifest, error)
+
+	// OpenFile 打开分区manifest中列出的数据文件
+	OpenFile(ctx context.Context, date, name string) (export.File, error)
+}
+
+// APIEventExportLogic 领域事件导出查询业务逻辑实现
+type APIEventExportLogic struct {
+	catalog *eventlog.Catalog
+}
+
+// NewAPIEventExportLogic 创建领域事件导出查询业务逻辑实例
+func NewAPIEventExportLogic(storage export.Storage) *APIEventExportLogic {
+	return &APIEventExportLogic{
+		catalog: eventlog.NewCatalog(storage),
+	}
+}
+
+// ListManifests 获取日期范围内已导出分区的manifest
+func (l *APIEventExportLogic) ListManifests(ctx context.Context, from, to string) ([]*eventlog.Manifest, error) {
+	fromDate, err := eventlog.ParseDate(from)
+	if err != nil {
+		return nil, ErrEventExportDateInvalid
+	}
+	toDate, err := eventlog.ParseDate(to)
+	if err != nil || toDate.Before(fromDate) {
+		return nil, ErrEventExportDateInvalid
+	}
+	if toDate.Sub(fromDate) >= maxEventExportRangeDays*24*time.Hour {
+		return nil, ErrEventExportRangeTooBig
+	}
+	return l.catalog.Manifests(fromDate, toDate)
+}
+
+// GetManifest 获取分区manifest
+func (l *APIEventExportLogic) GetManifest(ctx context.Context, date string) (*eventlog.Manifest, error) {
+	partition, err := eventlog.ParseDate(date)
+	if err != nil {
+		return nil, ErrEventExportDateInvalid
+	}
+	return l.catalog.Manifest(partition)
+}
+
+// OpenFile 打开分区数据文件
+func (l *APIEventExportLogic) OpenFile(ctx context.Context, date, name string) (export.File, error) {
+	partition, err := eventlog.ParseDate(date)
+	if err != nil {
+		return nil, ErrEventExportDateInvalid
+	}
+	return l.catalog.Open(partition, name)
+}
@@ -0,0 +1,593 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	mongoModel "exchange/internal/models/mongodb"
+	wsDto "exchange/internal/modules/websocket/dto"
+	wsLogic "exchange/internal/modules/websocket/logic"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	appErrors "exchange/internal/pkg/errors"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/repository"
+)
+
+// GroupMessageEvent 推送给群成员的新消息事件类型
+const GroupMessageEvent = "group_message"
+
+var (
+	// ErrGroupNotFound 群聊不存在，或当前用户不是群成员
+	ErrGroupNotFound = errors.New("group not found")
+	// ErrGroupPermissionDenied 当前角色不能执行该操作
+	ErrGroupPermissionDenied = errors.New("group permission denied")
+	// ErrGroupNameRequired 群名称为空
+	ErrGroupNameRequired = errors.New("group name is required")
+	// ErrGroupFull 群成员数已达上限
+	ErrGroupFull = errors.New("group member limit reached")
+	// ErrGroupLimitReached 创建的群聊数已达上限
+	ErrGroupLimitReached = errors.New("group creation limit reached")
+	// ErrGroupMemberNotFound 目标用户不是群成员
+	ErrGroupMemberNotFound = errors.New("group member not found")
+	// ErrGroupOwnerCannotLeave 群主需要先转让群主或解散群才能退出
+	ErrGroupOwnerCannotLeave = errors.New("group owner cannot leave the group")
+	// ErrInvalidGroupRole 角色无效或不能设置为该角色
+	ErrInvalidGroupRole = errors.New("invalid group role")
+)
+
+// GroupSummary 用户加入的群聊及本人的角色和未读消息数
+type GroupSummary struct {
+	Group       *mongoModel.ChatGroup
+	Role        mongoModel.GroupRole
+	UnreadCount int64
+}
+
+// GroupDetail 群聊及成员列表
+type GroupDetail struct {
+	Group   *mongoModel.ChatGroup
+	Members []*mongoModel.ChatGroupMember
+}
+
+// GroupLogic 群聊业务逻辑接口
+type GroupLogic interface {
+	// CreateGroup 创建群聊，创建者为群主，memberIDs为同时加入的其他成员
+	CreateGroup(ctx context.Context, ownerID uint, name, description string, memberIDs []uint) (*GroupDetail, error)
+
+	// ListGroups 本人加入的群聊，包含本人的角色和未读消息数
+	ListGroups(ctx context.Context, userID uint) ([]*GroupSummary, error)
+
+	// GetGroup 获取群资料和成员列表，只有群成员可以查看
+	GetGroup(ctx context.Context, userID uint, groupID string) (*GroupDetail, error)
+
+	// UpdateGroup 修改群名称和简介（群主和管理员）
+	UpdateGroup(ctx context.Context, userID uint, groupID, name, description string) (*mongoModel.ChatGroup, error)
+
+	// DeleteGroup 解散群聊（群主）
+	DeleteGroup(ctx context.Context, userID uint, groupID string) error
+
+	// AddMembers 邀请用户加入群聊（群主和管理员），已是成员的用户被忽略
+	AddMembers(ctx context.Context, userID uint, groupID string, memberIDs []uint) (*GroupDetail, error)
+
+	// RemoveMember 移除成员，memberID为本人时表示退出群聊
+	RemoveMember(ctx context.Context, userID uint, groupID string, memberID uint) error
+
+	// SetMemberRole 设置成员角色（群主），设置为owner时转让群主，原群主成为管理员
+	SetMemberRole(ctx context.Context, userID uint, groupID string, memberID uint, role mongoModel.GroupRole) error
+
+	// SendMessage 发送群聊文本消息并推送给其他成员
+	SendMessage(ctx context.Context, userID uint, groupID, content string) (*mongoModel.ChatMessage, error)
+
+	// SendFileMessage 发送群聊文件消息，attachment为本人已上传的附件（见AttachmentLogic.Resolve）
+	SendFileMessage(ctx context.Context, userID uint, groupID string, attachment *Attachment) (*mongoModel.ChatMessage, error)
+
+	// ListMessages 获取群聊消息，从新到旧排列，分页规则与MessageLogic.ListConversation相同
+	ListMessages(ctx context.Context, userID uint, groupID, cursor string, limit int) (*MessagePage, error)
+
+	// MarkRead 将本人的已读位置移动到指定消息，已读位置只向后移动
+	MarkRead(ctx context.Context, userID uint, groupID, messageID string) error
+}
+
+// APIGroupLogic 群聊业务逻辑实现
+type APIGroupLogic struct {
+	config      config.ChatConfig
+	userRepo    repository.UserRepository
+	groupRepo   repository.GroupRepository
+	messageRepo repository.GroupMessageRepository
+	replay      wsLogic.ReplayBuffer
+}
+
+// NewAPIGroupLogic 创建群聊业务逻辑实例
+func NewAPIGroupLogic(cfg *config.Config, userRepo repository.UserRepository, groupRepo repository.GroupRepository, messageRepo repository.GroupMessageRepository, replay wsLogic.ReplayBuffer) *APIGroupLogic {
+	return &APIGroupLogic{
+		config:      cfg.Chat,
+		userRepo:    userRepo,
+		groupRepo:   groupRepo,
+		messageRepo: messageRepo,
+		replay:      replay,
+	}
+}
+
+// CreateGroup 创建群聊
+// 业务规则：
+// 1. 每个用户最多创建chat.max_groups_per_user个群，成员数（含群主）不超过chat.max_group_members
+// 2. 初始成员必须是存在的用户，重复的ID和群主本人被忽略
+func (l *APIGroupLogic) CreateGroup(ctx context.Context, ownerID uint, name, description string, memberIDs []uint) (*GroupDetail, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrGroupNameRequired
+	}
+
+	owner := formatUserID(ownerID)
+	created, err := l.groupRepo.CountCreatedGroups(ctx, owner)
+	if err != nil {
+		return nil, fmt.Errorf("查询已创建的群聊数失败: %w", err)
+	}
+	if created >= int64(l.config.MaxGroupsPerUser) {
+		return nil, ErrGroupLimitReached
+	}
+
+	memberIDs = uniqueUserIDs(memberIDs, ownerID)
+	if len(memberIDs)+1 > l.config.MaxGroupMembers {
+		return nil, ErrGroupFull
+	}
+	if err := l.checkUsersExist(ctx, memberIDs); err != nil {
+		return nil, err
+	}
+
+	now := clock.Now()
+	group := &mongoModel.ChatGroup{
+		Name:        name,
+		Description: strings.TrimSpace(description),
+		OwnerID:     owner,
+		CreatorID:   owner,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	members := []*mongoModel.ChatGroupMember{{UserID: owner, Role: mongoModel.GroupRoleOwner, JoinedAt: now}}
+	for _, id := range memberIDs {
+		members = append(members, &mongoModel.ChatGroupMember{UserID: formatUserID(id), Role: mongoModel.GroupRoleMember, JoinedAt: now})
+	}
+
+	if err := l.groupRepo.CreateGroup(ctx, group, members); err != nil {
+		return nil, fmt.Errorf("创建群聊失败: %w", err)
+	}
+	return &GroupDetail{Group: group, Members: members}, nil
+}
+
+// ListGroups 获取本人加入的群聊，按群资料的更新时间倒序
+func (l *APIGroupLogic) ListGroups(ctx context.Context, userID uint) ([]*GroupSummary, error) {
+	self := formatUserID(userID)
+	memberships, err := l.groupRepo.ListMemberships(ctx, self)
+	if err != nil {
+		return nil, fmt.Errorf("查询加入的群聊失败: %w", err)
+	}
+
+	byGroup := make(map[string]*mongoModel.ChatGroupMember, len(memberships))
+	groupIDs := make([]string, 0, len(memberships))
+	for _, membership := range memberships {
+		byGroup[membership.GroupID] = membership
+		groupIDs = append(groupIDs, membership.GroupID)
+	}
+
+	groups, err := l.groupRepo.GetGroupsByIDs(ctx, groupIDs)
+	if err != nil {
+		return nil, fmt.Errorf("查询群聊失败: %w", err)
+	}
+
+	summaries := make([]*GroupSummary, 0, len(groups))
+	for _, group := range groups {
+		membership := byGroup[group.ID.Hex()]
+		unread, err := l.messageRepo.CountGroupUnread(ctx, membership.GroupID, self, readCursor(membership))
+		if err != nil {
+			return nil, fmt.Errorf("统计群聊未读消息失败: %w", err)
+		}
+		summaries = append(summaries, &GroupSummary{Group: group, Role: membership.Role, UnreadCount: unread})
+	}
+	return summaries, nil
+}
+
+// GetGroup 获取群资料和成员列表
+func (l *APIGroupLogic) GetGroup(ctx context.Context, userID uint, groupID string) (*GroupDetail, error) {
+	if _, err := l.membership(ctx, userID, groupID); err != nil {
+		return nil, err
+	}
+	return l.detail(ctx, groupID)
+}
+
+// UpdateGroup 修改群名称和简介
+func (l *APIGroupLogic) UpdateGroup(ctx context.Context, userID uint, groupID, name, description string) (*mongoModel.ChatGroup, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrGroupNameRequired
+	}
+
+	member, err := l.membership(ctx, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.CanManage() {
+		return nil, ErrGroupPermissionDenied
+	}
+
+	if err := l.groupRepo.UpdateGroup(ctx, groupID, name, strings.TrimSpace(description), clock.Now()); err != nil {
+		return nil, l.translate(err, ErrGroupNotFound, "修改群资料失败")
+	}
+	group, err := l.groupRepo.GetGroup(ctx, groupID)
+	if err != nil {
+		return nil, l.translate(err, ErrGroupNotFound, "查询群聊失败")
+	}
+	return group, nil
+}
+
+// DeleteGroup 解散群聊，成员记录一并删除，群消息保留
+func (l *APIGroupLogic) DeleteGroup(ctx context.Context, userID uint, groupID string) error {
+	member, err := l.membership(ctx, userID, groupID)
+	if err != nil {
+		return err
+	}
+	if member.Role != mongoModel.GroupRoleOwner {
+		return ErrGroupPermissionDenied
+	}
+
+	if err := l.groupRepo.DeleteGroup(ctx, groupID); err != nil {
+		return l.translate(err, ErrGroupNotFound, "解散群聊失败")
+	}
+
+	appLogger.Info("群聊已解散", map[string]interface{}{
+		"group_id": groupID,
+		"user_id":  userID,
+	})
+	return nil
+}
+
+// AddMembers 邀请用户加入群聊
+// 成员数上限在仓储中原子地检查，并发邀请时不会超过chat.max_group_members
+func (l *APIGroupLogic) AddMembers(ctx context.Context, userID uint, groupID string, memberIDs []uint) (*GroupDetail, error) {
+	member, err := l.membership(ctx, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.CanManage() {
+		return nil, ErrGroupPermissionDenied
+	}
+
+	existing, err := l.groupRepo.ListMembers(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("查询群成员失败: %w", err)
+	}
+	joined := make(map[string]bool, len(existing))
+	for _, m := range existing {
+		joined[m.UserID] = true
+	}
+
+	var newIDs []uint
+	for _, id := range uniqueUserIDs(memberIDs, userID) {
+		if !joined[formatUserID(id)] {
+			newIDs = append(newIDs, id)
+		}
+	}
+	if err := l.checkUsersExist(ctx, newIDs); err != nil {
+		return nil, err
+	}
+
+	now := clock.Now()
+	members := make([]*mongoModel.ChatGroupMember, 0, len(newIDs))
+	for _, id := range newIDs {
+		members = append(members, &mongoModel.ChatGroupMember{UserID: formatUserID(id), Role: mongoModel.GroupRoleMember, JoinedAt: now})
+	}
+	// 查询成员列表后被其他管理员邀请的用户视为已加入
+	if err := l.groupRepo.AddMembers(ctx, groupID, members, l.config.MaxGroupMembers); err != nil && !errors.Is(err, repository.ErrGroupMemberExists) {
+		return nil, l.translate(err, ErrGroupNotFound, "邀请群成员失败")
+	}
+	return l.detail(ctx, groupID)
+}
+
+// RemoveMember 移除成员或退出群聊
+// 业务规则：
+// 1. 任何成员都可以退出，群主需要先转让群主或解散群
+// 2. 群主可以移除其他任何成员，管理员只能移除普通成员
+func (l *APIGroupLogic) RemoveMember(ctx context.Context, userID uint, groupID string, memberID uint) error {
+	actor, err := l.membership(ctx, userID, groupID)
+	if err != nil {
+		return err
+	}
+
+	if memberID == userID {
+		if actor.Role == mongoModel.GroupRoleOwner {
+			return ErrGroupOwnerCannotLeave
+		}
+	} else {
+		target, err := l.target(ctx, groupID, memberID)
+		if err != nil {
+			return err
+		}
+		switch {
+		case actor.Role == mongoModel.GroupRoleOwner:
+		case actor.Role == mongoModel.GroupRoleAdmin && target.Role == mongoModel.GroupRoleMember:
+		default:
+			return ErrGroupPermissionDenied
+		}
+	}
+
+	if err := l.groupRepo.RemoveMember(ctx, groupID, formatUserID(memberID)); err != nil {
+		return l.translate(err, ErrGroupMemberNotFound, "移除群成员失败")
+	}
+	return nil
+}
+
+// SetMemberRole 设置成员角色
+// 只有群主可以设置；设置为owner时转让群主，先修改群资料中的群主再调整双方角色
+func (l *APIGroupLogic) SetMemberRole(ctx context.Context, userID uint, groupID string, memberID uint, role mongoModel.GroupRole) error {
+	if role != mongoModel.GroupRoleOwner && role != mongoModel.GroupRoleAdmin && role != mongoModel.GroupRoleMember {
+		return ErrInvalidGroupRole
+	}
+	if memberID == userID {
+		return ErrInvalidGroupRole
+	}
+
+	actor, err := l.membership(ctx, userID, groupID)
+	if err != nil {
+		return err
+	}
+	if actor.Role != mongoModel.GroupRoleOwner {
+		return ErrGroupPermissionDenied
+	}
+	if _, err := l.target(ctx, groupID, memberID); err != nil {
+		return err
+	}
+
+	member := formatUserID(memberID)
+	if role == mongoModel.GroupRoleOwner {
+		if err := l.groupRepo.SetOwner(ctx, groupID, member, clock.Now()); err != nil {
+			return l.translate(err, ErrGroupNotFound, "转让群主失败")
+		}
+		if err := l.groupRepo.SetMemberRole(ctx, groupID, actor.UserID, mongoModel.GroupRoleAdmin); err != nil {
+			return l.translate(err, ErrGroupNotFound, "转让群主失败")
+		}
+		appLogger.Info("群主已转让", map[string]interface{}{
+			"group_id":  groupID,
+			"from_user": userID,
+			"to_user":   memberID,
+		})
+	}
+
+	if err := l.groupRepo.SetMemberRole(ctx, groupID, member, role); err != nil {
+		return l.translate(err, ErrGroupMemberNotFound, "设置成员角色失败")
+	}
+	return nil
+}
+
+// SendMessage 发送群聊消息
+// 消息只保存一份，保存后逐个写入其他成员的推送缓冲区（在线成员实时收到，断线重连后补发）；
+// 推送失败不影响发送结果，成员可以通过历史消息和未读数获取
+func (l *APIGroupLogic) SendMessage(ctx context.Context, userID uint, groupID, content string) (*mongoModel.ChatMessage, error) {
+	return l.send(ctx, userID, groupID, mongoModel.CreateGroupTextMessage(formatUserID(userID), groupID, content))
+}
+
+// SendFileMessage 发送群聊文件消息，消息中只保存附件的对象key
+func (l *APIGroupLogic) SendFileMessage(ctx context.Context, userID uint, groupID string, attachment *Attachment) (*mongoModel.ChatMessage, error) {
+	message := mongoModel.CreateGroupFileMessage(formatUserID(userID), groupID, attachment.Key, attachment.MessageType, attachment.FileName, attachment.FileSize, attachment.MimeType)
+	return l.send(ctx, userID, groupID, message)
+}
+
+// send 保存消息并推送给其他成员
+func (l *APIGroupLogic) send(ctx context.Context, userID uint, groupID string, message *mongoModel.ChatMessage) (*mongoModel.ChatMessage, error) {
+	if _, err := l.membership(ctx, userID, groupID); err != nil {
+		return nil, err
+	}
+
+	if err := message.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMessageContentInvalid, err)
+	}
+	if err := l.messageRepo.Create(ctx, message); err != nil {
+		return nil, fmt.Errorf("发送群聊消息失败: %w", err)
+	}
+
+	members, err := l.groupRepo.ListMembers(ctx, groupID)
+	if err != nil {
+		appLogger.Warn("查询群成员失败，消息未推送", map[string]interface{}{
+			"group_id":   groupID,
+			"message_id": message.ID.Hex(),
+			"error":      err.Error(),
+		})
+		return message, nil
+	}
+	l.fanOut(ctx, message, members)
+	return message, nil
+}
+
+// fanOut 将新消息写入除发送者外每个成员的推送缓冲区
+func (l *APIGroupLogic) fanOut(ctx context.Context, message *mongoModel.ChatMessage, members []*mongoModel.ChatGroupMember) {
+	from, _ := strconv.ParseUint(message.FromUserID, 10, 64)
+	failed := 0
+	for _, member := range members {
+		if member.UserID == message.FromUserID {
+			continue
+		}
+		to, err := strconv.ParseUint(member.UserID, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		event := &wsDto.Message{
+			Type: GroupMessageEvent,
+			Data: map[string]interface{}{
+				"message_id":   message.ID.Hex(),
+				"group_id":     message.GroupID,
+				"message_type": message.MessageType,
+				"content":      message.Content,
+				"file_info":    message.GetFileInfo(),
+				"created_at":   message.CreatedAt,
+			},
+			Timestamp: message.CreatedAt,
+			From:      uint(from),
+			To:        uint(to),
+			RoomID:    message.GroupID,
+		}
+		if _, err := l.replay.Push(ctx, uint(to), event); err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		appLogger.Warn("群聊消息推送失败", map[string]interface{}{
+			"group_id":   message.GroupID,
+			"message_id": message.ID.Hex(),
+			"failed":     failed,
+		})
+	}
+}
+
+// ListMessages 按游标分页获取群聊消息
+func (l *APIGroupLogic) ListMessages(ctx context.Context, userID uint, groupID, cursor string, limit int) (*MessagePage, error) {
+	if limit <= 0 {
+		limit = DefaultMessagePageSize
+	}
+	if limit > MaxMessagePageSize {
+		limit = MaxMessagePageSize
+	}
+
+	var before *repository.MessageCursor
+	if cursor != "" {
+		decoded, err := decodeMessageCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		before = decoded
+	}
+
+	if _, err := l.membership(ctx, userID, groupID); err != nil {
+		return nil, err
+	}
+
+	messages, err := l.messageRepo.GetGroupMessages(ctx, groupID, before, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("查询群聊消息失败: %w", err)
+	}
+
+	page := &MessagePage{Messages: messages}
+	if len(messages) > limit {
+		page.Messages = messages[:limit]
+		last := page.Messages[limit-1]
+		page.NextCursor = encodeMessageCursor(last.CreatedAt, last.ID)
+	}
+	for _, message := range page.Messages {
+		message.Redact()
+	}
+	return page, nil
+}
+
+// MarkRead 更新本人的已读位置
+func (l *APIGroupLogic) MarkRead(ctx context.Context, userID uint, groupID, messageID string) error {
+	if _, err := l.membership(ctx, userID, groupID); err != nil {
+		return err
+	}
+
+	message, err := l.messageRepo.GetByID(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrMessageNotFound
+		}
+		return appErrors.TranslateMessageError(err, "查询消息失败", messageID)
+	}
+	if message.GroupID != groupID {
+		return ErrMessageNotFound
+	}
+
+	cursor := repository.MessageCursor{CreatedAt: message.CreatedAt, ID: message.ID.Hex()}
+	if err := l.groupRepo.UpdateReadCursor(ctx, groupID, formatUserID(userID), cursor); err != nil {
+		return l.translate(err, ErrGroupNotFound, "更新已读位置失败")
+	}
+	return nil
+}
+
+// membership 获取当前用户的成员记录，不是群成员时视为群不存在
+func (l *APIGroupLogic) membership(ctx context.Context, userID uint, groupID string) (*mongoModel.ChatGroupMember, error) {
+	member, err := l.groupRepo.GetMember(ctx, groupID, formatUserID(userID))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrGroupNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询群成员失败: %w", err)
+	}
+	return member, nil
+}
+
+// target 获取被操作的成员记录
+func (l *APIGroupLogic) target(ctx context.Context, groupID string, memberID uint) (*mongoModel.ChatGroupMember, error) {
+	member, err := l.groupRepo.GetMember(ctx, groupID, formatUserID(memberID))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrGroupMemberNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询群成员失败: %w", err)
+	}
+	return member, nil
+}
+
+// detail 获取群资料和成员列表
+func (l *APIGroupLogic) detail(ctx context.Context, groupID string) (*GroupDetail, error) {
+	group, err := l.groupRepo.GetGroup(ctx, groupID)
+	if err != nil {
+		return nil, l.translate(err, ErrGroupNotFound, "查询群聊失败")
+	}
+	members, err := l.groupRepo.ListMembers(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("查询群成员失败: %w", err)
+	}
+	return &GroupDetail{Group: group, Members: members}, nil
+}
+
+// checkUsersExist 检查用户是否存在
+func (l *APIGroupLogic) checkUsersExist(ctx context.Context, userIDs []uint) error {
+	for _, id := range userIDs {
+		if _, err := l.userRepo.GetByID(ctx, id); err != nil {
+			return appErrors.TranslateUserError(err, "查询群成员用户失败", id)
+		}
+	}
+	return nil
+}
+
+// translate 将仓储错误转换为群聊业务错误，记录不存在时返回notFound
+func (l *APIGroupLogic) translate(err, notFound error, msg string) error {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return notFound
+	case errors.Is(err, repository.ErrGroupFull):
+		return ErrGroupFull
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// readCursor 成员的已读位置，还没有已读消息时为nil
+func readCursor(member *mongoModel.ChatGroupMember) *repository.MessageCursor {
+	if member.LastReadAt == nil || member.LastReadMessageID == "" {
+		return nil
+	}
+	return &repository.MessageCursor{CreatedAt: *member.LastReadAt, ID: member.LastReadMessageID}
+}
+
+// uniqueUserIDs 去除重复的用户ID、0和exclude
+func uniqueUserIDs(ids []uint, exclude uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 || id == exclude || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
+
+// formatUserID 消息和群成员中的用户ID以十进制字符串保存
+func formatUserID(userID uint) string {
+	return strconv.FormatUint(uint64(userID), 10)
+}
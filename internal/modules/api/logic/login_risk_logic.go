@@ -0,0 +1,250 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/geoip"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/loginrisk"
+	"exchange/internal/pkg/notification"
+	"exchange/internal/repository"
+)
+
+// ErrLoginConfirmationRequired 从新设备或新的国家登录，需要通过邮件中的链接确认后重新登录
+var ErrLoginConfirmationRequired = errors.New("login confirmation required")
+
+// LoginAttempt 一次登录的客户端信息
+type LoginAttempt struct {
+	IP        string
+	UserAgent string
+	Header    http.Header // 请求头，读取客户端上报的设备ID和CDN写入的国家代码
+}
+
+// LoginRiskLogic 新设备和异地登录检测业务逻辑接口
+type LoginRiskLogic interface {
+	// Check 凭据校验通过、签发token前检查登录设备和国家
+	// stepUpPassed表示本次登录已通过两步验证码，新设备登录只通知用户，不再要求邮件确认
+	// 需要邮件确认时返回ErrLoginConfirmationRequired，此时不能签发token
+	Check(ctx context.Context, user *mysql.User, attempt LoginAttempt, stepUpPassed bool) error
+
+	// ConfirmLogin 使用邮件中的确认token确认登录设备，确认后该设备可以正常登录
+	ConfirmLogin(ctx context.Context, token string) error
+}
+
+// APILoginRiskLogic 新设备和异地登录检测业务逻辑实现
+type APILoginRiskLogic struct {
+	config     *config.Config
+	deviceRepo repository.LoginDeviceRepository
+	locator    geoip.Locator
+	confirms   *loginrisk.ConfirmStore
+	notifier   notification.Notifier
+}
+
+// NewAPILoginRiskLogic 创建新设备和异地登录检测业务逻辑实例
+func NewAPILoginRiskLogic(cfg *config.Config, deviceRepo repository.LoginDeviceRepository, locator geoip.Locator, confirms *loginrisk.ConfirmStore, notifier notification.Notifier) *APILoginRiskLogic {
+	return &APILoginRiskLogic{
+		config:     cfg,
+		deviceRepo: deviceRepo,
+		locator:    locator,
+		confirms:   confirms,
+		notifier:   notifier,
+	}
+}
+
+// Check 检查登录设备和国家
+// 业务规则：
+// 1. 用户还没有任何设备记录时（刚注册或功能上线前的用户）直接记录为已知设备
+// 2. 已知设备且国家与已知国家一致时只更新最近登录信息
+// 3. 新设备或新的国家记录安全日志；处理方式为notify或本次登录已通过两步验证时记录设备并通知用户，
+// 为step_up时发送确认邮件并拒绝本次登录，用户确认后重新登录
+// 4. 设备记录读写失败时不影响登录（记录警告日志）
+func (l *APILoginRiskLogic) Check(ctx context.Context, user *mysql.User, attempt LoginAttempt, stepUpPassed bool) error {
+	cfg := l.config.LoginRisk
+	if !cfg.Enabled {
+		return nil
+	}
+
+	now := clock.Now()
+	fingerprint := loginrisk.Fingerprint(attempt.Header.Get(cfg.DeviceHeader), attempt.UserAgent)
+	country := l.locator.Country(attempt.IP, attempt.Header)
+
+	devices, err := l.deviceRepo.ListByUser(ctx, user.ID)
+	if err != nil {
+		l.warn("查询登录设备失败", user.ID, err)
+		return nil
+	}
+	if len(devices) == 0 {
+		l.record(ctx, user.ID, nil, fingerprint, attempt.UserAgent, attempt.IP, country, now)
+		return nil
+	}
+
+	var device *mysql.LoginDevice
+	knownCountry := country == ""
+	for _, d := range devices {
+		if d.Fingerprint == fingerprint {
+			device = d
+		}
+		if d.Country == country {
+			knownCountry = true
+		}
+	}
+	if device != nil && knownCountry {
+		l.record(ctx, user.ID, device, fingerprint, attempt.UserAgent, attempt.IP, country, now)
+		return nil
+	}
+
+	stepUp := cfg.Action == config.LoginRiskActionStepUp && !stepUpPassed
+	appLogger.Security("新设备或异地登录", map[string]interface{}{
+		"user_id":     user.ID,
+		"ip":          attempt.IP,
+		"country":     country,
+		"new_device":  device == nil,
+		"new_country": !knownCountry,
+		"step_up":     stepUp,
+		"two_factor":  stepUpPassed,
+	})
+
+	if stepUp {
+		return l.requestConfirmation(ctx, user, loginrisk.Pending{
+			UserID:      user.ID,
+			Fingerprint: fingerprint,
+			UserAgent:   attempt.UserAgent,
+			IP:          attempt.IP,
+			Country:     country,
+			CreatedAt:   now,
+		})
+	}
+
+	l.record(ctx, user.ID, device, fingerprint, attempt.UserAgent, attempt.IP, country, now)
+	if err := l.notifier.Notify(ctx, &notification.Notification{
+		UserID:   user.ID,
+		Event:    "new_device_login",
+		Email:    user.Email,
+		Language: user.Language,
+		Data: map[string]interface{}{
+			"device":   attempt.UserAgent,
+			"ip":       attempt.IP,
+			"country":  country,
+			"login_at": now,
+		},
+		CreatedAt: now,
+	}); err != nil {
+		l.warn("发送新设备登录通知失败", user.ID, err)
+	}
+	return nil
+}
+
+// requestConfirmation 签发确认token并发送确认邮件
+func (l *APILoginRiskLogic) requestConfirmation(ctx context.Context, user *mysql.User, pending loginrisk.Pending) error {
+	token, expiresAt, err := l.confirms.Issue(ctx, pending)
+	if err != nil {
+		return fmt.Errorf("签发登录确认token失败: %w", err)
+	}
+
+	if err := l.notifier.Notify(ctx, &notification.Notification{
+		UserID:   user.ID,
+		Event:    "login_confirmation_requested",
+		Email:    user.Email,
+		Language: user.Language,
+		Data: map[string]interface{}{
+			"confirm_link": l.config.LoginRisk.ConfirmURL + token,
+			"expires_at":   expiresAt,
+			"device":       pending.UserAgent,
+			"ip":           pending.IP,
+			"country":      pending.Country,
+		},
+		CreatedAt: clock.Now(),
+	}); err != nil {
+		return fmt.Errorf("发送登录确认邮件失败: %w", err)
+	}
+	return ErrLoginConfirmationRequired
+}
+
+// ConfirmLogin 确认登录设备
+// 确认链接可能在其他设备上打开，记录的是发起登录时的设备和国家；确认后不签发token，需在原设备上重新登录
+func (l *APILoginRiskLogic) ConfirmLogin(ctx context.Context, token string) error {
+	pending, err := l.confirms.Consume(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	device, err := l.deviceRepo.GetByFingerprint(ctx, pending.UserID, pending.Fingerprint)
+	if err != nil {
+		return fmt.Errorf("查询登录设备失败: %w", err)
+	}
+	if device != nil {
+		if err := l.deviceRepo.Touch(ctx, device.ID, pending.IP, pending.Country, clock.Now()); err != nil {
+			return fmt.Errorf("更新登录设备失败: %w", err)
+		}
+	} else {
+		device = &mysql.LoginDevice{
+			UserID:      pending.UserID,
+			Fingerprint: pending.Fingerprint,
+			UserAgent:   pending.UserAgent,
+			LastIP:      pending.IP,
+			Country:     pending.Country,
+			LastSeenAt:  clock.Now(),
+		}
+		if err := l.deviceRepo.Create(ctx, device); err != nil {
+			return fmt.Errorf("记录登录设备失败: %w", err)
+		}
+		l.prune(ctx, pending.UserID)
+	}
+
+	appLogger.Security("用户确认新设备登录", map[string]interface{}{
+		"user_id": pending.UserID,
+		"ip":      pending.IP,
+		"country": pending.Country,
+	})
+	return nil
+}
+
+// record 记录设备，已知设备更新最近登录信息
+func (l *APILoginRiskLogic) record(ctx context.Context, userID uint, device *mysql.LoginDevice, fingerprint, userAgent, ip, country string, at time.Time) {
+	if device != nil {
+		// 本次无法确定国家时保留之前的国家
+		if country == "" {
+			country = device.Country
+		}
+		if err := l.deviceRepo.Touch(ctx, device.ID, ip, country, at); err != nil {
+			l.warn("更新登录设备失败", userID, err)
+		}
+		return
+	}
+
+	device = &mysql.LoginDevice{
+		UserID:      userID,
+		Fingerprint: fingerprint,
+		UserAgent:   userAgent,
+		LastIP:      ip,
+		Country:     country,
+		LastSeenAt:  at,
+	}
+	if err := l.deviceRepo.Create(ctx, device); err != nil {
+		l.warn("记录登录设备失败", userID, err)
+		return
+	}
+	l.prune(ctx, userID)
+}
+
+// prune 删除超过保留数量的最久未使用的设备
+func (l *APILoginRiskLogic) prune(ctx context.Context, userID uint) {
+	if _, err := l.deviceRepo.PruneOldest(ctx, userID, l.config.LoginRisk.MaxDevices); err != nil {
+		l.warn("清理登录设备失败", userID, err)
+	}
+}
+
+// warn 记录设备读写失败
+func (l *APILoginRiskLogic) warn(msg string, userID uint, err error) {
+	appLogger.Warn(msg, map[string]interface{}{
+		"user_id": userID,
+		"error":   err.Error(),
+	})
+}
@@ -0,0 +1,434 @@
+package logic
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	mongoModel "exchange/internal/models/mongodb"
+	wsDto "exchange/internal/modules/websocket/dto"
+	wsLogic "exchange/internal/modules/websocket/logic"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	appErrors "exchange/internal/pkg/errors"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/repository"
+)
+
+const (
+	// DefaultMessagePageSize 每页消息数的默认值
+	DefaultMessagePageSize = 20
+	// MaxMessagePageSize 每页消息数的最大值
+	MaxMessagePageSize = 100
+	// MaxBulkReadPeers 批量标记已读时一次处理的会话数上限
+	MaxBulkReadPeers = 100
+
+	// MessageReceiptEvent 推送给发送者的投递和已读回执事件类型
+	MessageReceiptEvent = "message_receipt"
+)
+
+var (
+	// ErrInvalidMessageCursor 分页游标无法解析
+	ErrInvalidMessageCursor = errors.New("invalid message cursor")
+	// ErrMessageNotFound 消息不存在，或当前用户不是会话的一方
+	ErrMessageNotFound = errors.New("message not found")
+	// ErrMessageNotSender 只有发送者可以修改或删除消息
+	ErrMessageNotSender = errors.New("only the sender can modify the message")
+	// ErrMessageDeleted 消息已删除
+	ErrMessageDeleted = errors.New("message already deleted")
+	// ErrMessageNotEditable 非文本消息、超过可修改时间或修改次数已达上限
+	ErrMessageNotEditable = errors.New("message can no longer be edited")
+	// ErrMessageNotDeletable 超过可删除时间
+	ErrMessageNotDeletable = errors.New("message can no longer be deleted")
+	// ErrMessageContentInvalid 修改后的内容为空或超过长度上限
+	ErrMessageContentInvalid = errors.New("invalid message content")
+	// ErrMessageEditConflict 读取后消息已被修改或删除，需重新获取后再修改
+	ErrMessageEditConflict = errors.New("message was modified concurrently")
+)
+
+// ConversationUnread 与一个对方的会话中对方发来的未读消息数
+type ConversationUnread struct {
+	PeerID      uint
+	UnreadCount int64
+}
+
+// UnreadCounts 用户的单聊未读数
+type UnreadCounts struct {
+	Total         int64
+	Conversations []ConversationUnread // 按未读数从多到少排列，不含没有未读消息的会话
+}
+
+// MessagePage 一页会话消息
+type MessagePage struct {
+	Messages   []*mongoModel.ChatMessage
+	NextCursor string // 下一页（更早的消息）的游标，没有更多消息时为空
+}
+
+// MessageLogic 会话消息业务逻辑接口
+type MessageLogic interface {
+	// ListConversation 获取与对方的会话消息，从新到旧排列；cursor为上一页返回的游标，为空时从最新的消息开始
+	// 已删除的消息内容替换为DeletedMessageContent
+	ListConversation(ctx context.Context, userID, peerID uint, cursor string, limit int) (*MessagePage, error)
+
+	// EditMessage 修改本人发送的文本消息，返回修改后的消息
+	EditMessage(ctx context.Context, userID uint, messageID, content string) (*mongoModel.ChatMessage, error)
+
+	// DeleteMessage 删除本人发送的消息（软删除）
+	DeleteMessage(ctx context.Context, userID uint, messageID string) error
+
+	// MarkDelivered 接收方的WebSocket连接确认收到消息后标记为已投递，并向发送者推送回执，返回本次标记的消息数
+	// 不是发给userID的消息和已投递的消息被忽略
+	MarkDelivered(ctx context.Context, userID uint, messageIDs []string) (int, error)
+
+	// MarkRead 将对方发来的、不晚于messageID的消息标记为已读，并向对方推送回执，返回本次标记的消息数
+	MarkRead(ctx context.Context, userID, peerID uint, messageID string) (int64, error)
+
+	// UnreadCounts 获取用户的单聊未读总数和各会话的未读数
+	UnreadCounts(ctx context.Context, userID uint) (*UnreadCounts, error)
+
+	// MarkAllRead 将指定对方发来的所有消息标记为已读，并向各对方推送回执，返回本次标记的消息数
+	// peerIDs为空时处理所有有未读消息的会话（未读数最多的MaxBulkReadPeers个）
+	MarkAllRead(ctx context.Context, userID uint, peerIDs []uint) (int64, error)
+}
+
+// APIMessageLogic 会话消息业务逻辑实现
+type APIMessageLogic struct {
+	config      config.ChatConfig
+	userRepo    repository.UserRepository
+	messageRepo repository.ConversationMessageRepository
+	replay      wsLogic.ReplayBuffer
+}
+
+// NewAPIMessageLogic 创建会话消息业务逻辑实例
+func NewAPIMessageLogic(cfg *config.Config, userRepo repository.UserRepository, messageRepo repository.ConversationMessageRepository, replay wsLogic.ReplayBuffer) *APIMessageLogic {
+	return &APIMessageLogic{
+		config:      cfg.Chat,
+		userRepo:    userRepo,
+		messageRepo: messageRepo,
+		replay:      replay,
+	}
+}
+
+// ListConversation 按游标分页获取会话消息
+// 多取一条判断是否还有更早的消息，游标指向本页最后一条消息
+func (l *APIMessageLogic) ListConversation(ctx context.Context, userID, peerID uint, cursor string, limit int) (*MessagePage, error) {
+	if limit <= 0 {
+		limit = DefaultMessagePageSize
+	}
+	if limit > MaxMessagePageSize {
+		limit = MaxMessagePageSize
+	}
+
+	var before *repository.MessageCursor
+	if cursor != "" {
+		decoded, err := decodeMessageCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		before = decoded
+	}
+
+	if _, err := l.userRepo.GetByID(ctx, peerID); err != nil {
+		return nil, appErrors.TranslateUserError(err, "查询会话对方失败", peerID)
+	}
+
+	messages, err := l.messageRepo.GetConversationMessages(ctx,
+		strconv.FormatUint(uint64(userID), 10), strconv.FormatUint(uint64(peerID), 10), before, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("查询会话消息失败: %w", err)
+	}
+
+	page := &MessagePage{Messages: messages}
+	if len(messages) > limit {
+		page.Messages = messages[:limit]
+		last := page.Messages[limit-1]
+		page.NextCursor = encodeMessageCursor(last.CreatedAt, last.ID)
+	}
+	for _, message := range page.Messages {
+		message.Redact()
+		message.FillStatus()
+	}
+	return page, nil
+}
+
+// EditMessage 修改消息
+// 业务规则：
+// 1. 只有发送者可以修改，已删除的消息不能修改
+// 2. 只能修改文本消息，且在发送后chat.edit_window_minutes分钟内、修改次数未达到chat.max_edits
+// 3. 被替换的内容保存在修改历史中，会话双方都可以看到
+func (l *APIMessageLogic) EditMessage(ctx context.Context, userID uint, messageID, content string) (*mongoModel.ChatMessage, error) {
+	message, err := l.ownMessage(ctx, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := clock.Now()
+	window := time.Duration(l.config.EditWindowMinutes) * time.Minute
+	if message.MessageType != mongoModel.MessageTypeText || now.Sub(message.CreatedAt) > window || len(message.EditHistory) >= l.config.MaxEdits {
+		return nil, ErrMessageNotEditable
+	}
+	if content == message.Content {
+		return message, nil
+	}
+
+	edited := *message
+	edited.Content = content
+	if err := edited.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMessageContentInvalid, err)
+	}
+
+	if err := l.messageRepo.EditMessage(ctx, messageID, message.Content, content, now); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrMessageEditConflict
+		}
+		return nil, appErrors.TranslateMessageError(err, "修改消息失败", messageID)
+	}
+
+	edited.EditHistory = append(edited.EditHistory, mongoModel.MessageEdit{Content: message.Content, EditedAt: now})
+	edited.EditedAt = &now
+	edited.UpdatedAt = now
+	edited.FillStatus()
+	return &edited, nil
+}
+
+// DeleteMessage 删除消息
+// 只有发送者可以删除，chat.delete_window_minutes大于0时只能在发送后该时间内删除；原内容保留在数据库中
+func (l *APIMessageLogic) DeleteMessage(ctx context.Context, userID uint, messageID string) error {
+	message, err := l.ownMessage(ctx, userID, messageID)
+	if err != nil {
+		return err
+	}
+
+	now := clock.Now()
+	if l.config.DeleteWindowMinutes > 0 && now.Sub(message.CreatedAt) > time.Duration(l.config.DeleteWindowMinutes)*time.Minute {
+		return ErrMessageNotDeletable
+	}
+
+	if err := l.messageRepo.SoftDelete(ctx, messageID, now); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrMessageDeleted
+		}
+		return appErrors.TranslateMessageError(err, "删除消息失败", messageID)
+	}
+	return nil
+}
+
+// MarkDelivered 标记消息为已投递
+// 由WebSocket网关在接收方确认收到（ack）后调用，按发送者分组推送回执
+func (l *APIMessageLogic) MarkDelivered(ctx context.Context, userID uint, messageIDs []string) (int, error) {
+	for _, id := range messageIDs {
+		if _, err := primitive.ObjectIDFromHex(id); err != nil {
+			return 0, ErrMessageNotFound
+		}
+	}
+
+	now := clock.Now()
+	messages, err := l.messageRepo.MarkDelivered(ctx, formatUserID(userID), messageIDs, now)
+	if err != nil {
+		return 0, fmt.Errorf("标记消息已投递失败: %w", err)
+	}
+
+	bySender := make(map[string][]string)
+	for _, message := range messages {
+		bySender[message.FromUserID] = append(bySender[message.FromUserID], message.ID.Hex())
+	}
+	for sender, ids := range bySender {
+		l.pushReceipt(ctx, sender, userID, map[string]interface{}{
+			"status":      mongoModel.MessageStatusDelivered,
+			"message_ids": ids,
+			"at":          now,
+		})
+	}
+	return len(messages), nil
+}
+
+// MarkRead 标记对方发来的消息为已读
+// 回执只包含已读位置（up_to），发送者将该位置及之前发给对方的消息都视为已读
+func (l *APIMessageLogic) MarkRead(ctx context.Context, userID, peerID uint, messageID string) (int64, error) {
+	if _, err := primitive.ObjectIDFromHex(messageID); err != nil {
+		return 0, ErrMessageNotFound
+	}
+
+	message, err := l.messageRepo.GetByID(ctx, messageID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, ErrMessageNotFound
+	}
+	if err != nil {
+		return 0, appErrors.TranslateMessageError(err, "查询消息失败", messageID)
+	}
+	peer := formatUserID(peerID)
+	if message.FromUserID != peer || message.ToUserID != formatUserID(userID) {
+		return 0, ErrMessageNotFound
+	}
+
+	now := clock.Now()
+	cursor := repository.MessageCursor{CreatedAt: message.CreatedAt, ID: messageID}
+	count, err := l.messageRepo.MarkReadUpTo(ctx, formatUserID(userID), peer, cursor, now)
+	if err != nil {
+		return 0, fmt.Errorf("标记消息已读失败: %w", err)
+	}
+
+	if count > 0 {
+		l.pushReceipt(ctx, peer, userID, map[string]interface{}{
+			"status": mongoModel.MessageStatusRead,
+			"up_to":  messageID,
+			"count":  count,
+			"at":     now,
+		})
+	}
+	return count, nil
+}
+
+// UnreadCounts 获取单聊未读数，由Redis计数器提供，缓存不存在时从MongoDB统计
+func (l *APIMessageLogic) UnreadCounts(ctx context.Context, userID uint) (*UnreadCounts, error) {
+	bySender, err := l.messageRepo.CountUnreadBySender(ctx, formatUserID(userID))
+	if err != nil {
+		return nil, fmt.Errorf("获取未读数失败: %w", err)
+	}
+
+	counts := &UnreadCounts{Conversations: make([]ConversationUnread, 0, len(bySender))}
+	for sender, count := range bySender {
+		peerID, err := strconv.ParseUint(sender, 10, 64)
+		if err != nil || count <= 0 {
+			continue
+		}
+		counts.Total += count
+		counts.Conversations = append(counts.Conversations, ConversationUnread{PeerID: uint(peerID), UnreadCount: count})
+	}
+	sort.Slice(counts.Conversations, func(i, j int) bool {
+		a, b := counts.Conversations[i], counts.Conversations[j]
+		if a.UnreadCount != b.UnreadCount {
+			return a.UnreadCount > b.UnreadCount
+		}
+		return a.PeerID < b.PeerID
+	})
+	return counts, nil
+}
+
+// MarkAllRead 批量标记已读
+// 回执不带已读位置（all为true），发送者将此前发给对方的消息都视为已读
+func (l *APIMessageLogic) MarkAllRead(ctx context.Context, userID uint, peerIDs []uint) (int64, error) {
+	if len(peerIDs) == 0 {
+		counts, err := l.UnreadCounts(ctx, userID)
+		if err != nil {
+			return 0, err
+		}
+		for _, conversation := range counts.Conversations {
+			if len(peerIDs) == MaxBulkReadPeers {
+				break
+			}
+			peerIDs = append(peerIDs, conversation.PeerID)
+		}
+	}
+
+	self := formatUserID(userID)
+	seen := make(map[uint]bool, len(peerIDs))
+	var total int64
+	for _, peerID := range peerIDs {
+		if peerID == 0 || peerID == userID || seen[peerID] {
+			continue
+		}
+		seen[peerID] = true
+
+		peer := formatUserID(peerID)
+		count, err := l.messageRepo.MarkConversationAsRead(ctx, peer, self)
+		if err != nil {
+			return total, fmt.Errorf("标记消息已读失败: %w", err)
+		}
+		total += count
+
+		if count > 0 {
+			l.pushReceipt(ctx, peer, userID, map[string]interface{}{
+				"status": mongoModel.MessageStatusRead,
+				"all":    true,
+				"count":  count,
+				"at":     clock.Now(),
+			})
+		}
+	}
+	return total, nil
+}
+
+// pushReceipt 向发送者推送回执，推送失败只记录日志，发送者可以通过历史消息获取状态
+func (l *APIMessageLogic) pushReceipt(ctx context.Context, sender string, recipientID uint, data map[string]interface{}) {
+	senderID, err := strconv.ParseUint(sender, 10, 64)
+	if err != nil {
+		return
+	}
+
+	data["peer_id"] = recipientID
+	event := &wsDto.Message{
+		Type:      MessageReceiptEvent,
+		Data:      data,
+		Timestamp: clock.Now(),
+		From:      recipientID,
+		To:        uint(senderID),
+	}
+	if _, err := l.replay.Push(ctx, uint(senderID), event); err != nil {
+		appLogger.Warn("消息回执推送失败", map[string]interface{}{
+			"user_id": senderID,
+			"status":  data["status"],
+			"error":   err.Error(),
+		})
+	}
+}
+
+// ownMessage 获取本人发送且未删除的消息；不是会话一方时视为消息不存在
+func (l *APIMessageLogic) ownMessage(ctx context.Context, userID uint, messageID string) (*mongoModel.ChatMessage, error) {
+	if _, err := primitive.ObjectIDFromHex(messageID); err != nil {
+		return nil, ErrMessageNotFound
+	}
+
+	message, err := l.messageRepo.GetByID(ctx, messageID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrMessageNotFound
+	}
+	if err != nil {
+		return nil, appErrors.TranslateMessageError(err, "查询消息失败", messageID)
+	}
+
+	self := strconv.FormatUint(uint64(userID), 10)
+	switch {
+	case message.FromUserID != self && message.ToUserID != self:
+		return nil, ErrMessageNotFound
+	case message.FromUserID != self:
+		return nil, ErrMessageNotSender
+	case message.IsDeleted():
+		return nil, ErrMessageDeleted
+	}
+	return message, nil
+}
+
+// encodeMessageCursor 生成分页游标：base64url("<Unix毫秒>.<消息ID>")，MongoDB的时间精度为毫秒
+func encodeMessageCursor(createdAt time.Time, id primitive.ObjectID) string {
+	raw := strconv.FormatInt(createdAt.UnixMilli(), 10) + "." + id.Hex()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeMessageCursor 解析分页游标
+func decodeMessageCursor(cursor string) (*repository.MessageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidMessageCursor
+	}
+	millis, id, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return nil, ErrInvalidMessageCursor
+	}
+	ms, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil || ms < 0 {
+		return nil, ErrInvalidMessageCursor
+	}
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, ErrInvalidMessageCursor
+	}
+	return &repository.MessageCursor{CreatedAt: time.UnixMilli(ms).UTC(), ID: id}, nil
+}
@@ -0,0 +1,304 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/clock"
+	appErrors "exchange/internal/pkg/errors"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/oauth"
+	"exchange/internal/pkg/twofactor"
+	"exchange/internal/repository"
+)
+
+const (
+	// oauthMaxCodeAttempts 第三方登录后两步验证码的最多尝试次数，超过后需要重新授权
+	oauthMaxCodeAttempts = 5
+	// oauthUsernameAttempts 创建用户时生成不重复用户名的最多尝试次数
+	oauthUsernameAttempts = 5
+	// oauthPasswordBytes 第三方登录创建的用户的随机密码字节数，用户可通过找回密码设置自己的密码
+	oauthPasswordBytes = 32
+)
+
+var (
+	// ErrOAuthAccountInactive 关联的用户已封禁、停用或注销
+	ErrOAuthAccountInactive = errors.New("oauth user account is not active")
+	// ErrOAuthEmailNotVerified 服务商未返回已验证的邮箱，不能关联或创建账户
+	ErrOAuthEmailNotVerified = errors.New("oauth email not verified")
+	// ErrOAuthLinkRequiresVerification 邮箱已被未验证邮箱的用户使用，需要先以密码登录并验证邮箱
+	ErrOAuthLinkRequiresVerification = errors.New("existing account email not verified")
+
+	// usernameInvalidChars 用户名中不允许的字符
+	usernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+)
+
+// OAuthLoginResult 第三方登录结果
+type OAuthLoginResult struct {
+	User    *mysql.User
+	Created bool // 第一次登录时创建了新用户
+	Linked  bool // 第一次登录时按邮箱关联了已有用户
+}
+
+// OAuthLogic 第三方登录业务逻辑接口
+type OAuthLogic interface {
+	// AuthorizationURL 发起授权，返回服务商的授权地址和state
+	AuthorizationURL(ctx context.Context, provider string) (string, string, error)
+
+	// Login 授权回调后登录，code为服务商返回的授权码，totpCode为两步验证码（未启用两步验证时忽略）
+	// 需要两步验证码时返回twofactor.ErrCodeRequired，此时可带同一个state和验证码重试，不需要重新授权
+	Login(ctx context.Context, provider, code, state, totpCode string) (*OAuthLoginResult, error)
+}
+
+// APIOAuthLogic 第三方登录业务逻辑实现
+type APIOAuthLogic struct {
+	providers    map[string]oauth.Provider
+	states       *oauth.StateStore
+	userRepo     repository.UserRepository
+	identityRepo repository.OAuthIdentityRepository
+	twoFactor    *twofactor.Service
+}
+
+// NewAPIOAuthLogic 创建第三方登录业务逻辑实例，providers为启用的服务商，twoFactor为nil时登录不进行两步验证
+func NewAPIOAuthLogic(providers map[string]oauth.Provider, states *oauth.StateStore, userRepo repository.UserRepository, identityRepo repository.OAuthIdentityRepository, twoFactor *twofactor.Service) *APIOAuthLogic {
+	return &APIOAuthLogic{
+		providers:    providers,
+		states:       states,
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		twoFactor:    twoFactor,
+	}
+}
+
+// AuthorizationURL 发起授权，生成state和PKCE的code_verifier保存到Redis
+func (l *APIOAuthLogic) AuthorizationURL(ctx context.Context, provider string) (string, string, error) {
+	p, ok := l.providers[provider]
+	if !ok {
+		return "", "", oauth.ErrUnknownProvider
+	}
+
+	verifier, err := oauth.NewVerifier()
+	if err != nil {
+		return "", "", fmt.Errorf("发起第三方登录失败: %w", err)
+	}
+	state, err := l.states.Create(ctx, oauth.State{Provider: provider, Verifier: verifier})
+	if err != nil {
+		return "", "", fmt.Errorf("发起第三方登录失败: %w", err)
+	}
+	return p.AuthCodeURL(state, oauth.Challenge(verifier)), state, nil
+}
+
+// Login 授权回调后登录
+// 业务规则：
+// 1. state只能使用一次，且必须是同一服务商发起的授权
+// 2. 已关联的第三方账户直接登录关联的用户
+// 3. 未关联时按服务商已验证的邮箱关联已有用户（已有用户的邮箱也必须已验证，防止他人预先以该邮箱注册后接管账户），
+// 邮箱不存在时创建新用户（邮箱视为已验证）
+// 4. 与密码登录相同，用户被封禁、锁定或要求重置密码时不能登录，已启用两步验证时需要验证码
+func (l *APIOAuthLogic) Login(ctx context.Context, provider, code, state, totpCode string) (*OAuthLoginResult, error) {
+	p, ok := l.providers[provider]
+	if !ok {
+		return nil, oauth.ErrUnknownProvider
+	}
+
+	pending, ttl, err := l.states.Consume(ctx, state, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &OAuthLoginResult{}
+	if pending.UserID != 0 {
+		// 授权码已换取成功，本次只校验两步验证码
+		user, err := l.userRepo.GetByID(ctx, pending.UserID)
+		if err != nil {
+			return nil, appErrors.TranslateUserError(err, "查询用户失败", pending.UserID)
+		}
+		result.User, result.Created = user, pending.Created
+	} else {
+		profile, err := p.Exchange(ctx, code, pending.Verifier)
+		if err != nil {
+			return nil, err
+		}
+		if result, err = l.resolveUser(ctx, profile); err != nil {
+			return nil, err
+		}
+	}
+
+	user := result.User
+	if !user.CanLogin() {
+		return nil, ErrOAuthAccountInactive
+	}
+	if user.IsLocked(clock.Now()) {
+		return nil, appErrors.AccountSuspendedError(user.LockedUntil.Format(time.RFC3339))
+	}
+	if user.PasswordResetRequired {
+		return nil, appErrors.ErrPasswordResetRequired
+	}
+
+	if l.twoFactor != nil {
+		subject := twofactor.Subject{Type: mysql.TwoFactorSubjectUser, ID: user.ID}
+		if err := l.twoFactor.CheckLogin(ctx, subject, user.TwoFactorEnabled, string(user.Role), user.Username, totpCode); err != nil {
+			l.keepPending(ctx, state, pending, result, ttl, err)
+			return nil, err
+		}
+	}
+
+	user.UpdateLoginInfo()
+	if err := l.userRepo.UpdateLastLogin(ctx, user.ID); err != nil {
+		appLogger.Warn("更新第三方登录信息失败", map[string]interface{}{
+			"user_id": user.ID,
+			"error":   err.Error(),
+		})
+	}
+	return result, nil
+}
+
+// keepPending 需要两步验证码或验证码错误时保留state，允许带验证码重试；错误次数达到上限后需要重新授权
+func (l *APIOAuthLogic) keepPending(ctx context.Context, id string, pending *oauth.State, result *OAuthLoginResult, ttl time.Duration, err error) {
+	if !errors.Is(err, twofactor.ErrCodeRequired) && !errors.Is(err, twofactor.ErrInvalidCode) {
+		return
+	}
+	if errors.Is(err, twofactor.ErrInvalidCode) {
+		pending.Attempts++
+	}
+	if pending.Attempts >= oauthMaxCodeAttempts || ttl <= 0 {
+		return
+	}
+
+	pending.UserID, pending.Created = result.User.ID, result.Created
+	if saveErr := l.states.Save(ctx, id, *pending, ttl); saveErr != nil {
+		appLogger.Warn("保存第三方登录状态失败", map[string]interface{}{
+			"user_id": result.User.ID,
+			"error":   saveErr.Error(),
+		})
+	}
+}
+
+// resolveUser 查找第三方账户关联的用户，未关联时按邮箱关联或创建用户
+func (l *APIOAuthLogic) resolveUser(ctx context.Context, profile *oauth.Profile) (*OAuthLoginResult, error) {
+	identity, err := l.identityRepo.GetByProviderSubject(ctx, profile.Provider, profile.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("查询第三方登录账户失败: %w", err)
+	}
+	if identity != nil {
+		user, err := l.userRepo.GetByID(ctx, identity.UserID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOAuthAccountInactive
+		}
+		if err != nil {
+			return nil, appErrors.TranslateUserError(err, "查询用户失败", identity.UserID)
+		}
+		return &OAuthLoginResult{User: user}, nil
+	}
+
+	email := strings.ToLower(strings.TrimSpace(profile.Email))
+	if email == "" || !profile.EmailVerified {
+		return nil, ErrOAuthEmailNotVerified
+	}
+
+	result := &OAuthLoginResult{}
+	user, err := l.userRepo.GetByEmail(ctx, email)
+	switch {
+	case err == nil:
+		if user.EmailVerifiedAt == nil {
+			return nil, ErrOAuthLinkRequiresVerification
+		}
+		result.User, result.Linked = user, true
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if result.User, err = l.createUser(ctx, profile, email); err != nil {
+			return nil, err
+		}
+		result.Created = true
+	default:
+		return nil, appErrors.TranslateUserError(err, "查询用户失败", 0)
+	}
+
+	// 关联失败时用户已创建，下次登录会按已验证的邮箱重新关联
+	identity = &mysql.OAuthIdentity{
+		Provider: profile.Provider,
+		Subject:  profile.Subject,
+		UserID:   result.User.ID,
+		Email:    email,
+	}
+	if err := l.identityRepo.Create(ctx, identity); err != nil {
+		return nil, fmt.Errorf("关联第三方登录账户失败: %w", err)
+	}
+
+	appLogger.Security("关联第三方登录账户", map[string]interface{}{
+		"user_id":  result.User.ID,
+		"provider": profile.Provider,
+		"created":  result.Created,
+	})
+	return result, nil
+}
+
+// createUser 以服务商返回的邮箱创建用户，密码随机生成（用户可通过找回密码设置）
+func (l *APIOAuthLogic) createUser(ctx context.Context, profile *oauth.Profile, email string) (*mysql.User, error) {
+	password, err := randomHex(oauthPasswordBytes)
+	if err != nil {
+		return nil, fmt.Errorf("生成随机密码失败: %w", err)
+	}
+	username, err := l.availableUsername(ctx, profile, email)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &mysql.User{
+		Username: username,
+		Email:    email,
+		Role:     mysql.UserRoleUser,
+		Status:   mysql.UserStatusActive,
+	}
+	if err := user.SetPassword(password); err != nil {
+		return nil, fmt.Errorf("密码加密失败: %w", err)
+	}
+	user.MarkEmailVerified()
+
+	if err := user.Validate(); err != nil {
+		return nil, appErrors.InvalidUserError(err, user.ID)
+	}
+	if err := l.userRepo.Create(ctx, user); err != nil {
+		return nil, appErrors.UserTranslator.Translate(err, "用户创建失败", map[string]interface{}{"username": user.Username})
+	}
+	return user, nil
+}
+
+// availableUsername 生成未被使用的用户名：优先使用服务商的用户名，没有时使用邮箱前缀，已被使用时追加随机后缀
+func (l *APIOAuthLogic) availableUsername(ctx context.Context, profile *oauth.Profile, email string) (string, error) {
+	base := usernameInvalidChars.ReplaceAllString(profile.Name, "")
+	if len(base) < 3 {
+		local, _, _ := strings.Cut(email, "@")
+		base = usernameInvalidChars.ReplaceAllString(local, "")
+	}
+	if len(base) < 3 {
+		base = "user"
+	}
+	if len(base) > 40 {
+		base = base[:40]
+	}
+
+	candidate := base
+	for i := 0; i < oauthUsernameAttempts; i++ {
+		_, err := l.userRepo.GetByUsername(ctx, candidate)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", appErrors.TranslateUserError(err, "查询用户失败", 0)
+		}
+
+		suffix, err := randomHex(3)
+		if err != nil {
+			return "", fmt.Errorf("生成用户名失败: %w", err)
+		}
+		candidate = base + "_" + suffix
+	}
+	return "", appErrors.UserConflictError("用户名已存在", "username", candidate)
+}
@@ -0,0 +1,147 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	appErrors "exchange/internal/pkg/errors"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/notification"
+	"exchange/internal/pkg/passwordreset"
+	"exchange/internal/pkg/session"
+	"exchange/internal/pkg/tokenrevoke"
+	"exchange/internal/repository"
+)
+
+// PasswordResetLogic 找回密码业务逻辑接口
+type PasswordResetLogic interface {
+	// RequestReset 向邮箱对应的账户发送重置密码邮件
+	// 邮箱未注册、账户不可用或超过发送上限时同样返回nil，不向请求方透露账户是否存在
+	RequestReset(ctx context.Context, email string) error
+
+	// ResetPassword 使用重置token设置新密码，成功后撤销该用户的所有登录会话和已签发的token
+	ResetPassword(ctx context.Context, token, password string) error
+}
+
+// APIPasswordResetLogic 找回密码业务逻辑实现
+type APIPasswordResetLogic struct {
+	config   *config.Config
+	userRepo repository.UserRepository
+	resets   *passwordreset.Store
+	sessions *session.Store
+	revoked  *tokenrevoke.Store
+	notifier notification.Notifier
+}
+
+// NewAPIPasswordResetLogic 创建找回密码业务逻辑实例
+func NewAPIPasswordResetLogic(cfg *config.Config, userRepo repository.UserRepository, resets *passwordreset.Store, sessions *session.Store, revoked *tokenrevoke.Store, notifier notification.Notifier) *APIPasswordResetLogic {
+	return &APIPasswordResetLogic{
+		config:   cfg,
+		userRepo: userRepo,
+		resets:   resets,
+		sessions: sessions,
+		revoked:  revoked,
+		notifier: notifier,
+	}
+}
+
+// RequestReset 签发重置token并发送重置密码邮件
+func (l *APIPasswordResetLogic) RequestReset(ctx context.Context, email string) error {
+	user, err := l.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		err = appErrors.TranslateUserError(err, "查询用户失败", 0)
+		if appErrors.CategoryOf(err) == appErrors.CategoryNotFound {
+			return nil
+		}
+		return err
+	}
+	if user == nil || !user.CanLogin() {
+		return nil
+	}
+
+	token, expiresAt, err := l.resets.Issue(ctx, user.ID)
+	if errors.Is(err, passwordreset.ErrTooManyRequests) {
+		appLogger.Security("重置密码邮件超过发送上限", map[string]interface{}{
+			"user_id": user.ID,
+			"limit":   l.config.Account.PasswordResetLimit,
+		})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := l.notifier.Notify(ctx, &notification.Notification{
+		UserID:   user.ID,
+		Event:    "password_reset_requested",
+		Email:    user.Email,
+		Language: user.Language,
+		Data: map[string]interface{}{
+			"reset_link": l.config.Account.PasswordResetURL + token,
+			"expires_at": expiresAt,
+		},
+		CreatedAt: clock.Now(),
+	}); err != nil {
+		return fmt.Errorf("发送重置密码邮件失败: %w", err)
+	}
+	return nil
+}
+
+// ResetPassword 设置新密码
+// 业务规则：
+// 1. 重置token有效期内只能使用一次，签发新token后旧token失效
+// 2. 只有可以登录的账户可以重置密码
+// 3. 重置后撤销所有登录会话，已登录的设备需要使用新密码重新登录
+// 4. 重置链接发送到用户邮箱，重置成功即视为已验证邮箱
+// 5. 清除管理员设置的需要重置密码标记
+func (l *APIPasswordResetLogic) ResetPassword(ctx context.Context, token, password string) error {
+	userID, err := l.resets.Consume(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	user, err := l.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return appErrors.TranslateUserError(err, "查询用户失败", userID)
+	}
+	if user == nil || !user.CanLogin() {
+		return passwordreset.ErrInvalidToken
+	}
+
+	if err := user.SetPassword(password); err != nil {
+		return fmt.Errorf("密码设置失败: %w", err)
+	}
+	user.MarkEmailVerified()
+	user.PasswordResetRequired = false
+	if err := l.userRepo.Update(ctx, user); err != nil {
+		return appErrors.TranslateUserError(err, "用户更新失败", user.ID)
+	}
+
+	revoked, err := l.sessions.RevokeAll(ctx, user.ID, "")
+	if err != nil {
+		appLogger.Warn("重置密码后撤销登录会话失败", map[string]interface{}{
+			"user_id": user.ID,
+			"error":   err.Error(),
+		})
+	}
+
+	// 不属于登录会话的token按jti撤销
+	claims := &Claims{UserID: user.ID}
+	revokedTokens, err := l.revoked.RevokeAll(ctx, claims.Principal())
+	if err != nil {
+		appLogger.Warn("重置密码后撤销token失败", map[string]interface{}{
+			"user_id": user.ID,
+			"error":   err.Error(),
+		})
+	}
+
+	appLogger.Security("用户已通过邮件重置密码", map[string]interface{}{
+		"user_id":          user.ID,
+		"revoked_sessions": revoked,
+		"revoked_tokens":   revokedTokens,
+	})
+	return nil
+}
@@ -0,0 +1,167 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
+
+	wsDto "exchange/internal/modules/websocket/dto"
+	wsLogic "exchange/internal/modules/websocket/logic"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	"exchange/internal/repository"
+)
+
+// 单次查询在线状态的用户数上限
+const maxPresenceUsers = 100
+
+var (
+	// ErrInvalidPresenceQuery 用户ID列表为空、格式错误或超过上限
+	ErrInvalidPresenceQuery = errors.New("invalid presence query")
+	// ErrTypingTargetNotFound 正在输入的会话对方不存在，或当前用户不是群成员
+	ErrTypingTargetNotFound = errors.New("typing target not found")
+)
+
+// PresenceLogic 在线状态和正在输入业务逻辑接口
+type PresenceLogic interface {
+	// Statuses 批量查询用户是否在线和最后在线时间，userIDs为逗号分隔的用户ID
+	Statuses(ctx context.Context, userIDs string) ([]wsDto.PresenceStatus, error)
+
+	// Typing 向会话对方或其他群成员推送正在输入事件（不保存、不补发，接收方离线时丢弃）
+	Typing(ctx context.Context, userID uint, req wsDto.TypingRequest) error
+}
+
+// APIPresenceLogic 在线状态和正在输入业务逻辑实现
+type APIPresenceLogic struct {
+	config    config.WebSocketConfig
+	presence  wsLogic.Presence
+	notifier  wsLogic.Notifier
+	userRepo  repository.UserRepository
+	groupRepo repository.GroupRepository
+}
+
+// NewAPIPresenceLogic 创建在线状态和正在输入业务逻辑实例
+func NewAPIPresenceLogic(cfg *config.Config, presence wsLogic.Presence, notifier wsLogic.Notifier, userRepo repository.UserRepository, groupRepo repository.GroupRepository) *APIPresenceLogic {
+	return &APIPresenceLogic{
+		config:    cfg.WebSocket,
+		presence:  presence,
+		notifier:  notifier,
+		userRepo:  userRepo,
+		groupRepo: groupRepo,
+	}
+}
+
+// Statuses 批量查询用户的在线状态，按请求中的顺序返回，重复的ID只返回一次
+func (l *APIPresenceLogic) Statuses(ctx context.Context, userIDs string) ([]wsDto.PresenceStatus, error) {
+	seen := make(map[uint]bool)
+	ids := make([]uint, 0)
+	for _, part := range strings.Split(userIDs, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+		if err != nil || id == 0 {
+			return nil, ErrInvalidPresenceQuery
+		}
+		if seen[uint(id)] {
+			continue
+		}
+		seen[uint(id)] = true
+		ids = append(ids, uint(id))
+	}
+	if len(ids) > maxPresenceUsers {
+		return nil, ErrInvalidPresenceQuery
+	}
+
+	statuses, err := l.presence.Statuses(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("查询在线状态失败: %w", err)
+	}
+
+	result := make([]wsDto.PresenceStatus, 0, len(ids))
+	for _, id := range ids {
+		status := statuses[id]
+		result = append(result, wsDto.PresenceStatus{UserID: id, Online: status.Online, LastSeenAt: status.LastSeenAt})
+	}
+	return result, nil
+}
+
+// Typing 推送正在输入事件
+// 单聊推送给对方，群聊推送给除本人外的所有成员；发送者需要是群成员，单聊对方需要存在
+func (l *APIPresenceLogic) Typing(ctx context.Context, userID uint, req wsDto.TypingRequest) error {
+	data := map[string]interface{}{
+		"user_id":    userID,
+		"typing":     req.Typing,
+		"expires_in": l.config.TypingTimeout,
+	}
+
+	var recipients []uint
+	if req.GroupID != "" {
+		members, err := l.groupMembers(ctx, userID, req.GroupID)
+		if err != nil {
+			return err
+		}
+		recipients = members
+		data["group_id"] = req.GroupID
+	} else {
+		_, err := l.userRepo.GetByID(ctx, req.PeerID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTypingTargetNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("查询会话对方失败: %w", err)
+		}
+		recipients = []uint{req.PeerID}
+		data["peer_id"] = userID // 接收方视角的会话对方
+	}
+
+	now := clock.Now()
+	failed := 0
+	for _, to := range recipients {
+		event := &wsDto.Message{
+			Type:      wsLogic.EventTyping,
+			Data:      data,
+			Timestamp: now,
+			From:      userID,
+			To:        to,
+			RoomID:    req.GroupID,
+		}
+		if err := l.notifier.Notify(ctx, to, event); err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("推送正在输入事件失败: %d个接收方", failed)
+	}
+	return nil
+}
+
+// groupMembers 确认本人是群成员，返回其他成员
+func (l *APIPresenceLogic) groupMembers(ctx context.Context, userID uint, groupID string) ([]uint, error) {
+	self := formatUserID(userID)
+	if _, err := l.groupRepo.GetMember(ctx, groupID, self); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrTypingTargetNotFound
+		}
+		return nil, fmt.Errorf("查询群成员失败: %w", err)
+	}
+
+	members, err := l.groupRepo.ListMembers(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("查询群成员失败: %w", err)
+	}
+	recipients := make([]uint, 0, len(members))
+	for _, member := range members {
+		if member.UserID == self {
+			continue
+		}
+		id, err := strconv.ParseUint(member.UserID, 10, 64)
+		if err != nil {
+			continue
+		}
+		recipients = append(recipients, uint(id))
+	}
+	return recipients, nil
+}
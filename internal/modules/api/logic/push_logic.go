@@ -0,0 +1,448 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	mongoModel "exchange/internal/models/mongodb"
+	"exchange/internal/models/mysql"
+	wsDto "exchange/internal/modules/websocket/dto"
+	wsLogic "exchange/internal/modules/websocket/logic"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/i18n"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/push"
+	"exchange/internal/repository"
+	mysqlRepo "exchange/internal/repository/mysql"
+)
+
+// 通知正文中消息内容的最大字符数
+const maxPushPreviewRunes = 100
+
+var (
+	// ErrPushDeviceNotFound 推送设备不存在或不属于当前用户
+	ErrPushDeviceNotFound = errors.New("push device not found")
+	// ErrPushPlatformUnsupported 设备平台没有配置推送服务
+	ErrPushPlatformUnsupported = errors.New("push platform not configured")
+	// ErrInvalidNotificationPreference 免打扰时间格式或时区无效
+	ErrInvalidNotificationPreference = errors.New("invalid notification preference")
+)
+
+// NotificationPreferenceInput 修改通知设置的参数，为nil的字段保持不变
+type NotificationPreferenceInput struct {
+	PushEnabled    *bool
+	DirectMessages *bool
+	GroupMessages  *bool
+	ShowPreview    *bool
+	QuietStart     *string // 与QuietEnd同时设置，都为空字符串时关闭免打扰
+	QuietEnd       *string
+	Timezone       *string
+}
+
+// PushDispatchResult 一次推送发送的统计
+type PushDispatchResult struct {
+	Jobs    int // 取出的任务数
+	Sent    int // 至少发送到一个设备的任务数
+	Skipped int // 用户已上线、关闭了推送、处于免打扰时段或没有设备而不发送的任务数
+	Retried int // 发送失败后重新入队的任务数
+	Dropped int // 过期或超过最多尝试次数而放弃的任务数
+}
+
+// PushLogic 离线推送业务逻辑接口
+type PushLogic interface {
+	// RegisterDevice 注册推送设备，同一token重复注册时更新所属用户和最近注册时间
+	RegisterDevice(ctx context.Context, userID uint, platform, token string) (*mysql.PushDevice, error)
+
+	// ListDevices 用户的推送设备
+	ListDevices(ctx context.Context, userID uint) ([]*mysql.PushDevice, error)
+
+	// DeleteDevice 删除推送设备（如退出登录时）
+	DeleteDevice(ctx context.Context, userID, deviceID uint) error
+
+	// GetPreferences 用户的通知设置，未设置时为默认设置
+	GetPreferences(ctx context.Context, userID uint) (*mysql.NotificationPreference, error)
+
+	// UpdatePreferences 修改通知设置
+	UpdatePreferences(ctx context.Context, userID uint, input NotificationPreferenceInput) (*mysql.NotificationPreference, error)
+
+	// NotifyOffline 接收者离线时将新消息事件写入推送队列（Broker的OfflineHandler）
+	NotifyOffline(ctx context.Context, userID uint, msg *wsDto.Message)
+
+	// Dispatch 取出最多batchSize个推送任务，按用户的通知设置发送（由定时任务调用）
+	Dispatch(ctx context.Context, batchSize int) (*PushDispatchResult, error)
+}
+
+// APIPushLogic 离线推送业务逻辑实现
+type APIPushLogic struct {
+	config     config.PushConfig
+	router     *push.Router
+	queue      *push.Queue
+	presence   wsLogic.Presence
+	userRepo   repository.UserRepository
+	groupRepo  repository.GroupRepository
+	deviceRepo repository.PushDeviceRepository
+	prefRepo   repository.NotificationPreferenceRepository
+}
+
+// NewAPIPushLogic 创建离线推送业务逻辑实例，未启用推送时router可以为nil
+func NewAPIPushLogic(
+	cfg *config.Config,
+	router *push.Router,
+	queue *push.Queue,
+	presence wsLogic.Presence,
+	userRepo repository.UserRepository,
+	groupRepo repository.GroupRepository,
+	deviceRepo repository.PushDeviceRepository,
+	prefRepo repository.NotificationPreferenceRepository,
+) *APIPushLogic {
+	return &APIPushLogic{
+		config:     cfg.Push,
+		router:     router,
+		queue:      queue,
+		presence:   presence,
+		userRepo:   userRepo,
+		groupRepo:  groupRepo,
+		deviceRepo: deviceRepo,
+		prefRepo:   prefRepo,
+	}
+}
+
+// RegisterDevice 注册推送设备，超过每个用户的设备数上限时删除最久未注册的设备
+func (l *APIPushLogic) RegisterDevice(ctx context.Context, userID uint, platform, token string) (*mysql.PushDevice, error) {
+	if l.router == nil || !l.router.Supports(platform) {
+		return nil, ErrPushPlatformUnsupported
+	}
+
+	device := &mysql.PushDevice{
+		UserID:     userID,
+		Platform:   platform,
+		Token:      token,
+		LastSeenAt: clock.Now(),
+	}
+	if err := l.deviceRepo.Upsert(ctx, device); err != nil {
+		return nil, fmt.Errorf("注册推送设备失败: %w", err)
+	}
+	if _, err := l.deviceRepo.PruneOldest(ctx, userID, l.config.MaxDevicesPerUser); err != nil {
+		appLogger.Warn("清理推送设备失败", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+	return device, nil
+}
+
+// ListDevices 用户的推送设备，最近注册的在前
+func (l *APIPushLogic) ListDevices(ctx context.Context, userID uint) ([]*mysql.PushDevice, error) {
+	devices, err := l.deviceRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("查询推送设备失败: %w", err)
+	}
+	return devices, nil
+}
+
+// DeleteDevice 删除推送设备
+func (l *APIPushLogic) DeleteDevice(ctx context.Context, userID, deviceID uint) error {
+	err := l.deviceRepo.Delete(ctx, userID, deviceID)
+	if errors.Is(err, mysqlRepo.ErrPushDeviceNotFound) {
+		return ErrPushDeviceNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("删除推送设备失败: %w", err)
+	}
+	return nil
+}
+
+// GetPreferences 用户的通知设置
+func (l *APIPushLogic) GetPreferences(ctx context.Context, userID uint) (*mysql.NotificationPreference, error) {
+	pref, err := l.prefRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("查询通知设置失败: %w", err)
+	}
+	return pref, nil
+}
+
+// UpdatePreferences 修改通知设置，只修改提供的字段
+func (l *APIPushLogic) UpdatePreferences(ctx context.Context, userID uint, input NotificationPreferenceInput) (*mysql.NotificationPreference, error) {
+	pref, err := l.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.PushEnabled != nil {
+		pref.PushEnabled = *input.PushEnabled
+	}
+	if input.DirectMessages != nil {
+		pref.DirectMessages = *input.DirectMessages
+	}
+	if input.GroupMessages != nil {
+		pref.GroupMessages = *input.GroupMessages
+	}
+	if input.ShowPreview != nil {
+		pref.ShowPreview = *input.ShowPreview
+	}
+	if input.QuietStart != nil {
+		pref.QuietStart = *input.QuietStart
+	}
+	if input.QuietEnd != nil {
+		pref.QuietEnd = *input.QuietEnd
+	}
+	if input.Timezone != nil {
+		pref.Timezone = *input.Timezone
+	}
+
+	if err := pref.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNotificationPreference, err)
+	}
+	if err := l.prefRepo.Save(ctx, pref); err != nil {
+		return nil, fmt.Errorf("保存通知设置失败: %w", err)
+	}
+	return pref, nil
+}
+
+// NotifyOffline 将群聊新消息写入推送队列，其他事件（如回执）不推送
+// 在发送消息的请求中调用，只写入Redis队列，是否发送由定时任务按用户的通知设置决定
+func (l *APIPushLogic) NotifyOffline(ctx context.Context, userID uint, msg *wsDto.Message) {
+	if !l.config.Enabled || msg.Type != GroupMessageEvent {
+		return
+	}
+
+	job := &push.Job{
+		UserID:      userID,
+		Kind:        msg.Type,
+		From:        msg.From,
+		GroupID:     msg.RoomID,
+		MessageID:   fmt.Sprint(msg.Data["message_id"]),
+		MessageType: fmt.Sprint(msg.Data["message_type"]),
+		CreatedAt:   msg.Timestamp,
+	}
+	if content, ok := msg.Data["content"].(string); ok {
+		job.Content = content
+	}
+	if err := l.queue.Enqueue(ctx, job); err != nil {
+		appLogger.Warn("写入推送队列失败", map[string]interface{}{
+			"user_id":    userID,
+			"message_id": job.MessageID,
+			"error":      err.Error(),
+		})
+	}
+}
+
+// Dispatch 发送一批推送任务
+// 用户已重新上线（WebSocket重连后会补发）、关闭了对应类型的推送或处于免打扰时段时不发送；
+// 设备token失效时删除设备，其他发送失败时重新入队，同一消息的重复通知按CollapseKey合并
+func (l *APIPushLogic) Dispatch(ctx context.Context, batchSize int) (*PushDispatchResult, error) {
+	result := &PushDispatchResult{}
+	if !l.config.Enabled || l.router == nil {
+		return result, nil
+	}
+
+	jobs, err := l.queue.Pop(ctx, batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("读取推送队列失败: %w", err)
+	}
+	result.Jobs = len(jobs)
+	if len(jobs) == 0 {
+		return result, nil
+	}
+
+	// 同一批任务的接收者一次查询在线状态，查询失败时按离线处理
+	userIDs := make([]uint, 0, len(jobs))
+	seen := make(map[uint]bool)
+	for _, job := range jobs {
+		if !seen[job.UserID] {
+			seen[job.UserID] = true
+			userIDs = append(userIDs, job.UserID)
+		}
+	}
+	statuses, err := l.presence.Statuses(ctx, userIDs)
+	if err != nil {
+		appLogger.Warn("查询在线状态失败，按离线发送推送", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	now := clock.Now()
+	ttl := time.Duration(l.config.JobTTL) * time.Second
+	batch := newPushBatch(l)
+	for _, job := range jobs {
+		if now.Sub(job.CreatedAt) > ttl {
+			result.Dropped++
+			continue
+		}
+		if statuses[job.UserID].Online {
+			result.Skipped++
+			continue
+		}
+
+		sent, err := l.send(ctx, batch, job, now)
+		switch {
+		case err == nil && sent:
+			result.Sent++
+		case err == nil:
+			result.Skipped++
+		case job.Attempts+1 < l.config.MaxAttempts:
+			job.Attempts++
+			if err := l.queue.Enqueue(ctx, job); err != nil {
+				result.Dropped++
+				continue
+			}
+			result.Retried++
+		default:
+			result.Dropped++
+			appLogger.Warn("推送发送失败，超过最多尝试次数", map[string]interface{}{
+				"user_id":    job.UserID,
+				"message_id": job.MessageID,
+				"error":      err.Error(),
+			})
+		}
+	}
+	return result, nil
+}
+
+// send 按用户的通知设置发送一个任务，返回是否发送到了至少一个设备；任一设备发送失败（token失效除外）时返回错误
+func (l *APIPushLogic) send(ctx context.Context, batch *pushBatch, job *push.Job, now time.Time) (bool, error) {
+	pref, err := l.prefRepo.Get(ctx, job.UserID)
+	if err != nil {
+		return false, err
+	}
+	if !pref.PushEnabled || (job.GroupID != "" && !pref.GroupMessages) || (job.GroupID == "" && !pref.DirectMessages) {
+		return false, nil
+	}
+	if pref.InQuietHours(now) {
+		return false, nil
+	}
+
+	devices, err := l.deviceRepo.ListByUser(ctx, job.UserID)
+	if err != nil {
+		return false, err
+	}
+	if len(devices) == 0 {
+		return false, nil
+	}
+
+	notification := batch.notification(ctx, job, pref)
+	sent := false
+	var sendErr error
+	for _, device := range devices {
+		n := *notification
+		n.Token = device.Token
+		err := l.router.Send(ctx, device.Platform, &n)
+		switch {
+		case err == nil:
+			sent = true
+		case errors.Is(err, push.ErrUnregistered):
+			if err := l.deviceRepo.DeleteByToken(ctx, device.Token); err != nil {
+				appLogger.Warn("删除失效的推送设备失败", map[string]interface{}{
+					"device_id": device.ID,
+					"error":     err.Error(),
+				})
+			}
+		case errors.Is(err, push.ErrUnsupportedPlatform):
+			// 配置中移除了该平台的推送服务，保留设备等待重新配置
+		default:
+			sendErr = err
+		}
+	}
+	return sent, sendErr
+}
+
+// pushBatch 一批任务内共用的用户名、语言和群名称，避免同一发送者或群的消息重复查询
+type pushBatch struct {
+	logic     *APIPushLogic
+	users     map[uint]*mysql.User
+	groups    map[string]string
+	i18n      *i18n.I18nManager
+	defaultLn string
+}
+
+func newPushBatch(l *APIPushLogic) *pushBatch {
+	manager := i18n.GetGlobalI18n()
+	return &pushBatch{
+		logic:     l,
+		users:     make(map[uint]*mysql.User),
+		groups:    make(map[string]string),
+		i18n:      manager,
+		defaultLn: manager.GetDefaultLanguage(),
+	}
+}
+
+// notification 按接收者的语言生成通知，标题为群名称，关闭内容预览时只提示有新消息
+func (b *pushBatch) notification(ctx context.Context, job *push.Job, pref *mysql.NotificationPreference) *push.Notification {
+	lang := b.defaultLn
+	if recipient := b.user(ctx, job.UserID); recipient != nil && recipient.Language != "" {
+		lang = recipient.Language
+	}
+	sender := strconv.FormatUint(uint64(job.From), 10)
+	if user := b.user(ctx, job.From); user != nil {
+		sender = user.Username
+	}
+
+	title := b.group(ctx, job.GroupID)
+	if title == "" {
+		title = b.i18n.Translate(lang, "push_message_title", nil)
+	}
+
+	var body string
+	switch {
+	case !pref.ShowPreview:
+		body = b.i18n.Translate(lang, "push_message_hidden", map[string]interface{}{"Sender": sender})
+	case job.MessageType != string(mongoModel.MessageTypeText):
+		body = b.i18n.Translate(lang, "push_message_attachment", map[string]interface{}{"Sender": sender})
+	default:
+		body = b.i18n.Translate(lang, "push_message_preview", map[string]interface{}{"Sender": sender, "Content": truncateRunes(job.Content, maxPushPreviewRunes)})
+	}
+
+	return &push.Notification{
+		Title: title,
+		Body:  body,
+		Data: map[string]string{
+			"type":       job.Kind,
+			"message_id": job.MessageID,
+			"group_id":   job.GroupID,
+			"from":       strconv.FormatUint(uint64(job.From), 10),
+		},
+		CollapseKey: job.MessageID,
+	}
+}
+
+// user 查询用户，不存在或查询失败时返回nil
+func (b *pushBatch) user(ctx context.Context, userID uint) *mysql.User {
+	if user, ok := b.users[userID]; ok {
+		return user
+	}
+	user, err := b.logic.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		user = nil
+	}
+	b.users[userID] = user
+	return user
+}
+
+// group 查询群名称，单聊、群不存在或查询失败时返回空字符串
+func (b *pushBatch) group(ctx context.Context, groupID string) string {
+	if groupID == "" {
+		return ""
+	}
+	if name, ok := b.groups[groupID]; ok {
+		return name
+	}
+	name := ""
+	if group, err := b.logic.groupRepo.GetGroup(ctx, groupID); err == nil {
+		name = group.Name
+	}
+	b.groups[groupID] = name
+	return name
+}
+
+// truncateRunes 截断到最多n个字符，截断时以省略号结尾
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "…"
+}
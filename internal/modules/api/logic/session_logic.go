@@ -0,0 +1,54 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"exchange/internal/pkg/session"
+)
+
+// SessionLogic 用户登录会话管理业务逻辑接口
+type SessionLogic interface {
+	// ListSessions 用户的所有有效登录会话，按最近使用时间倒序
+	ListSessions(ctx context.Context, userID uint) ([]*session.Session, error)
+
+	// RevokeSession 撤销用户的一个登录会话，会话不存在或不属于该用户时返回session.ErrSessionRevoked
+	RevokeSession(ctx context.Context, userID uint, sessionID string) error
+
+	// RevokeOtherSessions 撤销用户除当前会话外的所有登录会话，返回撤销的会话数
+	RevokeOtherSessions(ctx context.Context, userID uint, currentSessionID string) (int, error)
+}
+
+// APISessionLogic 用户登录会话管理业务逻辑实现
+type APISessionLogic struct {
+	sessions *session.Store
+}
+
+// NewAPISessionLogic 创建用户登录会话管理业务逻辑实例
+func NewAPISessionLogic(sessions *session.Store) *APISessionLogic {
+	return &APISessionLogic{
+		sessions: sessions,
+	}
+}
+
+// ListSessions 用户的所有有效登录会话
+func (l *APISessionLogic) ListSessions(ctx context.Context, userID uint) ([]*session.Session, error) {
+	return l.sessions.List(ctx, userID)
+}
+
+// RevokeSession 撤销用户的一个登录会话，该会话的访问token和刷新token均失效
+func (l *APISessionLogic) RevokeSession(ctx context.Context, userID uint, sessionID string) error {
+	if sessionID == "" {
+		return session.ErrSessionRevoked
+	}
+	return l.sessions.RevokeUserSession(ctx, userID, sessionID)
+}
+
+// RevokeOtherSessions 撤销其他设备上的登录会话，当前会话保留
+// 以API key认证的请求没有当前会话，不允许调用，避免误撤销所有会话
+func (l *APISessionLogic) RevokeOtherSessions(ctx context.Context, userID uint, currentSessionID string) (int, error) {
+	if currentSessionID == "" {
+		return 0, errors.New("current session required")
+	}
+	return l.sessions.RevokeAll(ctx, userID, currentSessionID)
+}
@@ -0,0 +1,120 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"exchange/internal/models/mysql"
+	appErrors "exchange/internal/pkg/errors"
+	"exchange/internal/pkg/twofactor"
+	"exchange/internal/repository"
+)
+
+// TwoFactorLogic 用户两步验证业务逻辑接口
+type TwoFactorLogic interface {
+	// GetStatus 两步验证状态
+	GetStatus(ctx context.Context, userID uint) (*twofactor.Status, error)
+
+	// BeginSetup 开始绑定，返回密钥和验证器应用的绑定地址
+	BeginSetup(ctx context.Context, userID uint) (*twofactor.Enrollment, error)
+
+	// Enable 使用验证码确认绑定，返回备用码
+	Enable(ctx context.Context, userID uint, code string) ([]string, error)
+
+	// Disable 校验验证码后关闭两步验证
+	Disable(ctx context.Context, userID uint, code string) error
+
+	// RegenerateBackupCodes 校验验证码后重新生成备用码
+	RegenerateBackupCodes(ctx context.Context, userID uint, code string) ([]string, error)
+
+	// ConfirmSetup 强制启用两步验证的用户登录时确认绑定，返回用户和备用码
+	ConfirmSetup(ctx context.Context, setupToken, code string) (*mysql.User, []string, error)
+}
+
+// APITwoFactorLogic 用户两步验证业务逻辑实现
+type APITwoFactorLogic struct {
+	userRepo  repository.UserRepository
+	twoFactor *twofactor.Service
+}
+
+// NewAPITwoFactorLogic 创建用户两步验证业务逻辑实例
+func NewAPITwoFactorLogic(userRepo repository.UserRepository, twoFactor *twofactor.Service) *APITwoFactorLogic {
+	return &APITwoFactorLogic{
+		userRepo:  userRepo,
+		twoFactor: twoFactor,
+	}
+}
+
+// GetStatus 两步验证状态
+func (l *APITwoFactorLogic) GetStatus(ctx context.Context, userID uint) (*twofactor.Status, error) {
+	user, err := l.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return l.twoFactor.Status(ctx, userSubject(user.ID), string(user.Role))
+}
+
+// BeginSetup 开始绑定，验证器应用中以用户名作为账户名
+func (l *APITwoFactorLogic) BeginSetup(ctx context.Context, userID uint) (*twofactor.Enrollment, error) {
+	user, err := l.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return l.twoFactor.Begin(ctx, userSubject(user.ID), user.Username)
+}
+
+// Enable 使用验证码确认绑定
+func (l *APITwoFactorLogic) Enable(ctx context.Context, userID uint, code string) ([]string, error) {
+	return l.twoFactor.Enable(ctx, userSubject(userID), code)
+}
+
+// Disable 关闭两步验证，强制启用的角色不能关闭
+func (l *APITwoFactorLogic) Disable(ctx context.Context, userID uint, code string) error {
+	user, err := l.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return l.twoFactor.Disable(ctx, userSubject(user.ID), string(user.Role), code)
+}
+
+// RegenerateBackupCodes 重新生成备用码
+func (l *APITwoFactorLogic) RegenerateBackupCodes(ctx context.Context, userID uint, code string) ([]string, error) {
+	return l.twoFactor.RegenerateBackupCodes(ctx, userSubject(userID), code)
+}
+
+// ConfirmSetup 确认登录时开始的绑定，确认后用户完成登录
+func (l *APITwoFactorLogic) ConfirmSetup(ctx context.Context, setupToken, code string) (*mysql.User, []string, error) {
+	subject, codes, err := l.twoFactor.ConfirmSetup(ctx, setupToken, code)
+	if err != nil {
+		return nil, nil, err
+	}
+	if subject.Type != mysql.TwoFactorSubjectUser {
+		return nil, nil, twofactor.ErrInvalidSetupToken
+	}
+
+	user, err := l.getUser(ctx, subject.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !user.CanLogin() {
+		return nil, nil, fmt.Errorf("user account is not active")
+	}
+	return user, codes, nil
+}
+
+// getUser 获取用户
+func (l *APITwoFactorLogic) getUser(ctx context.Context, userID uint) (*mysql.User, error) {
+	user, err := l.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, appErrors.TranslateUserError(err, "查询用户失败", userID)
+	}
+	if user == nil {
+		return nil, appErrors.ErrUserNotFound.WithContext("user_id", userID)
+	}
+	return user, nil
+}
+
+// userSubject 用户的两步验证账户
+func userSubject(userID uint) twofactor.Subject {
+	return twofactor.Subject{Type: mysql.TwoFactorSubjectUser, ID: userID}
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"exchange/internal/models/mysql"
+	appErrors "exchange/internal/pkg/errors"
 	"exchange/internal/repository"
 )
 
@@ -15,19 +18,27 @@ type UserLogic interface {
 	GetUserByID(ctx context.Context, userID uint) (*mysql.User, error)
 	UpdateUser(ctx context.Context, userID uint, username, email string) (*mysql.User, error)
 	ChangePassword(ctx context.Context, userID uint, oldPassword, newPassword string) error
+	AcceptInvite(ctx context.Context, token, password string) (*mysql.User, error)
+	GetLanguage(ctx context.Context, userID uint) (string, error)
+	SetLanguage(ctx context.Context, userID uint, language string) (*mysql.User, error)
 }
 
+// userLanguageTTL 用户首选语言的缓存时间，修改时删除缓存
+const userLanguageTTL = time.Hour
+
 // APIUserLogic 用户业务逻辑实现
 type APIUserLogic struct {
 	userRepo  repository.UserRepository
 	adminRepo repository.AdminRepository
+	cacheRepo repository.CacheRepository
 }
 
 // NewAPIUserLogic 创建用户业务逻辑实例
-func NewAPIUserLogic(userRepo repository.UserRepository, adminRepo repository.AdminRepository) *APIUserLogic {
+func NewAPIUserLogic(userRepo repository.UserRepository, adminRepo repository.AdminRepository, cacheRepo repository.CacheRepository) *APIUserLogic {
 	return &APIUserLogic{
 		userRepo:  userRepo,
 		adminRepo: adminRepo,
+		cacheRepo: cacheRepo,
 	}
 }
 
@@ -36,13 +47,13 @@ func (l *APIUserLogic) CreateUser(ctx context.Context, username, email, password
 	// 检查用户名是否已存在
 	existingUser, err := l.userRepo.GetByUsername(ctx, username)
 	if err == nil && existingUser != nil {
-		return nil, errors.New("用户名已存在")
+		return nil, appErrors.UserConflictError("用户名已存在", "username", username)
 	}
 
 	// 检查邮箱是否已存在
 	existingUser, err = l.userRepo.GetByEmail(ctx, email)
 	if err == nil && existingUser != nil {
-		return nil, errors.New("邮箱已存在")
+		return nil, appErrors.UserConflictError("邮箱已存在", "email", email)
 	}
 
 	// 创建用户
@@ -58,11 +69,11 @@ func (l *APIUserLogic) CreateUser(ctx context.Context, username, email, password
 	}
 
 	if err := user.Validate(); err != nil {
-		return nil, fmt.Errorf("用户数据验证失败: %w", err)
+		return nil, appErrors.InvalidUserError(err, user.ID)
 	}
 
 	if err := l.userRepo.Create(ctx, user); err != nil {
-		return nil, fmt.Errorf("用户创建失败: %w", err)
+		return nil, appErrors.UserTranslator.Translate(err, "用户创建失败", map[string]interface{}{"username": user.Username})
 	}
 
 	return user, nil
@@ -72,11 +83,11 @@ func (l *APIUserLogic) CreateUser(ctx context.Context, username, email, password
 func (l *APIUserLogic) GetUserByID(ctx context.Context, userID uint) (*mysql.User, error) {
 	user, err := l.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("查询用户失败: %w", err)
+		return nil, appErrors.TranslateUserError(err, "查询用户失败", userID)
 	}
 
 	if user == nil {
-		return nil, errors.New("用户不存在")
+		return nil, appErrors.ErrUserNotFound.WithContext("user_id", userID)
 	}
 
 	return user, nil
@@ -86,18 +97,18 @@ func (l *APIUserLogic) GetUserByID(ctx context.Context, userID uint) (*mysql.Use
 func (l *APIUserLogic) UpdateUser(ctx context.Context, userID uint, username, email string) (*mysql.User, error) {
 	user, err := l.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("查询用户失败: %w", err)
+		return nil, appErrors.TranslateUserError(err, "查询用户失败", userID)
 	}
 
 	if user == nil {
-		return nil, errors.New("用户不存在")
+		return nil, appErrors.ErrUserNotFound.WithContext("user_id", userID)
 	}
 
 	// 检查用户名是否已被其他用户使用
 	if username != user.Username {
 		existingUser, err := l.userRepo.GetByUsername(ctx, username)
 		if err == nil && existingUser != nil && existingUser.ID != userID {
-			return nil, errors.New("用户名已被使用")
+			return nil, appErrors.UserConflictError("用户名已被使用", "username", username)
 		}
 	}
 
@@ -105,20 +116,25 @@ func (l *APIUserLogic) UpdateUser(ctx context.Context, userID uint, username, em
 	if email != user.Email {
 		existingUser, err := l.userRepo.GetByEmail(ctx, email)
 		if err == nil && existingUser != nil && existingUser.ID != userID {
-			return nil, errors.New("邮箱已被使用")
+			return nil, appErrors.UserConflictError("邮箱已被使用", "email", email)
 		}
 	}
 
+	// 修改邮箱后需要重新验证
+	if !strings.EqualFold(email, user.Email) {
+		user.EmailVerifiedAt = nil
+	}
+
 	// 更新用户信息
 	user.Username = username
 	user.Email = email
 
 	if err := user.Validate(); err != nil {
-		return nil, fmt.Errorf("用户数据验证失败: %w", err)
+		return nil, appErrors.InvalidUserError(err, user.ID)
 	}
 
 	if err := l.userRepo.Update(ctx, user); err != nil {
-		return nil, fmt.Errorf("用户更新失败: %w", err)
+		return nil, appErrors.TranslateUserError(err, "用户更新失败", user.ID)
 	}
 
 	return user, nil
@@ -128,11 +144,11 @@ func (l *APIUserLogic) UpdateUser(ctx context.Context, userID uint, username, em
 func (l *APIUserLogic) ChangePassword(ctx context.Context, userID uint, oldPassword, newPassword string) error {
 	user, err := l.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return fmt.Errorf("查询用户失败: %w", err)
+		return appErrors.TranslateUserError(err, "查询用户失败", userID)
 	}
 
 	if user == nil {
-		return errors.New("用户不存在")
+		return appErrors.ErrUserNotFound.WithContext("user_id", userID)
 	}
 
 	// 验证旧密码
@@ -152,3 +168,94 @@ func (l *APIUserLogic) ChangePassword(ctx context.Context, userID uint, oldPassw
 
 	return nil
 }
+
+// GetLanguage 获取用户保存的首选语言（带缓存），未设置时返回空
+// 认证中间件对每个请求调用，缓存中保存"-"表示未设置，避免反复查询数据库
+func (l *APIUserLogic) GetLanguage(ctx context.Context, userID uint) (string, error) {
+	key := mysql.UserLanguageKey(userID)
+
+	var language string
+	if err := l.cacheRepo.Get(key, &language); err == nil && language != "" {
+		if language == "-" {
+			return "", nil
+		}
+		return language, nil
+	}
+
+	user, err := l.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	cached := user.Language
+	if cached == "" {
+		cached = "-"
+	}
+	if err := l.cacheRepo.Set(key, cached, userLanguageTTL); err != nil {
+		fmt.Printf("failed to cache language for user %d: %v\n", userID, err)
+	}
+	return user.Language, nil
+}
+
+// SetLanguage 设置用户首选语言，为空时清除（按请求头选择语言）
+func (l *APIUserLogic) SetLanguage(ctx context.Context, userID uint, language string) (*mysql.User, error) {
+	user, err := l.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Language = language
+	if err := l.userRepo.Update(ctx, user); err != nil {
+		return nil, appErrors.TranslateUserError(err, "用户更新失败", user.ID)
+	}
+
+	if err := l.cacheRepo.Delete(mysql.UserLanguageKey(userID)); err != nil {
+		fmt.Printf("failed to delete cached language for user %d: %v\n", userID, err)
+	}
+
+	return user, nil
+}
+
+// AcceptInvite 接受邀请并设置密码
+// 业务规则：
+// 1. 邀请token由管理员批量导入（invite模式）时生成，有效期内只能使用一次
+// 2. 只有未激活的用户可以通过邀请激活，设置密码后状态改为active
+// 3. 邀请链接发送到用户邮箱，接受邀请即视为已验证邮箱
+func (l *APIUserLogic) AcceptInvite(ctx context.Context, token, password string) (*mysql.User, error) {
+	key := mysql.UserInviteKey(token)
+
+	var userID uint
+	if err := l.cacheRepo.Get(key, &userID); err != nil || userID == 0 {
+		return nil, errors.New("邀请链接无效或已过期")
+	}
+
+	user, err := l.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, appErrors.TranslateUserError(err, "查询用户失败", userID)
+	}
+
+	if user == nil {
+		return nil, appErrors.ErrUserNotFound.WithContext("user_id", userID)
+	}
+
+	if user.Status != mysql.UserStatusInactive {
+		return nil, errors.New("用户已激活或不可用")
+	}
+
+	if err := user.SetPassword(password); err != nil {
+		return nil, fmt.Errorf("密码设置失败: %w", err)
+	}
+	user.Status = mysql.UserStatusActive
+	user.MarkEmailVerified()
+
+	if err := l.userRepo.Update(ctx, user); err != nil {
+		return nil, appErrors.TranslateUserError(err, "用户激活失败", user.ID)
+	}
+
+	// 邀请token只能使用一次
+	if err := l.cacheRepo.Delete(key); err != nil {
+		fmt.Printf("failed to delete invite token for user %d: %v\n", user.ID, err)
+	}
+
+	return user, nil
+}
@@ -1,15 +1,45 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"github.com/gin-gonic/gin"
 
 	"exchange/internal/middleware"
 	apiHandlers "exchange/internal/modules/api/handlers"
 	"exchange/internal/modules/api/logic"
 	"exchange/internal/modules/api/routes"
+	wsDto "exchange/internal/modules/websocket/dto"
+	wsLogic "exchange/internal/modules/websocket/logic"
+	"exchange/internal/pkg/captcha"
 	"exchange/internal/pkg/config"
 	"exchange/internal/pkg/database"
+	"exchange/internal/pkg/emailverify"
+	"exchange/internal/pkg/export"
+	"exchange/internal/pkg/geoip"
+	"exchange/internal/pkg/i18n"
+	"exchange/internal/pkg/jwtkeys"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/loginrisk"
+	"exchange/internal/pkg/loginthrottle"
+	"exchange/internal/pkg/mailer"
+	"exchange/internal/pkg/notification"
+	"exchange/internal/pkg/oauth"
+	"exchange/internal/pkg/objectstore"
+	"exchange/internal/pkg/passwordreset"
+	"exchange/internal/pkg/push"
+	"exchange/internal/pkg/retention"
+	"exchange/internal/pkg/secrets"
+	"exchange/internal/pkg/session"
+	"exchange/internal/pkg/signing"
+	"exchange/internal/pkg/tokenrevoke"
+	"exchange/internal/pkg/trading"
+	"exchange/internal/pkg/twofactor"
 	"exchange/internal/repository"
+	"exchange/internal/repository/cached"
+	"exchange/internal/repository/mongodb"
 	"exchange/internal/repository/mysql"
 )
 
@@ -18,24 +48,84 @@ type Module struct {
 	config *config.Config
 
 	// 数据库服务
-	mysql *database.MySQLService
-	redis *database.RedisService
+	mysql   *database.MySQLService
+	redis   *database.RedisService
+	mongodb *database.MongoDBService
 
 	// 数据访问层
-	userRepo  repository.UserRepository
-	adminRepo repository.AdminRepository
-	cacheRepo repository.CacheRepository
+	userRepo     repository.UserRepository
+	adminRepo    repository.AdminRepository
+	cacheRepo    repository.CacheRepository
+	deletionRepo repository.AccountDeletionRepository
+	exportRepo   repository.ChatExportRepository
+	messageRepo  repository.ConversationMessageRepository
+	groupRepo    repository.GroupRepository
+	apiKeyRepo   repository.APIKeyRepository
+
+	// 密钥提供者（内部服务签名、API key、导出链接签名、附件存储密钥、JWT非对称密钥和SMTP密码）
+	secrets secrets.Provider
+
+	// JWT签名和验证密钥
+	jwtKeys *jwtkeys.Keyset
+
+	// 会话导出
+	exportStorage export.Storage
+	linkSigner    *export.LinkSigner
+
+	// 领域事件导出文件（由定时任务生成，供数据仓库拉取）
+	eventExportStorage export.Storage
+
+	// 聊天附件存储
+	attachmentStore objectstore.Store
+
+	// 本实例的WebSocket连接，事件经Redis发布到接收者连接所在的实例
+	websocketHub *wsLogic.Hub
 
 	// 中间件
 	middlewareManager *middleware.MiddlewareManager
 	authMiddleware    *middleware.UserAuthMiddleware
+	apiKeyAuth        *middleware.APIKeyAuthMiddleware
+	serviceAuth       *middleware.ServiceAuthMiddleware
+	captcha           *middleware.CaptchaMiddleware
 
 	// 业务逻辑层
-	userLogic logic.UserLogic
-	authLogic logic.AuthLogic
+	userLogic     logic.UserLogic
+	authLogic     logic.AuthLogic
+	deletionLogic logic.AccountDeletionLogic
+	exportLogic   logic.ChatExportLogic
+
+	twoFactorLogic  logic.TwoFactorLogic
+	sessionLogic    logic.SessionLogic
+	resetLogic      logic.PasswordResetLogic
+	verifyLogic     logic.EmailVerificationLogic
+	apiKeyLogic     logic.APIKeyLogic
+	oauthLogic      logic.OAuthLogic
+	loginRiskLogic  logic.LoginRiskLogic
+	messageLogic    logic.MessageLogic
+	groupLogic      logic.GroupLogic
+	attachmentLogic logic.AttachmentLogic
+	presenceLogic   logic.PresenceLogic
+	pushLogic       logic.PushLogic
+
+	eventExportLogic logic.EventExportLogic
+	cacheStatsLogic  logic.CacheStatsLogic
 
 	// 处理器层
-	userHandler *apiHandlers.UserHandler
+	userHandler       *apiHandlers.UserHandler
+	chatExportHandler *apiHandlers.ChatExportHandler
+	internalHandler   *apiHandlers.InternalHandler
+	twoFactorHandler  *apiHandlers.TwoFactorHandler
+	sessionHandler    *apiHandlers.SessionHandler
+	resetHandler      *apiHandlers.PasswordResetHandler
+	verifyHandler     *apiHandlers.EmailVerificationHandler
+	apiKeyHandler     *apiHandlers.APIKeyHandler
+	jwksHandler       *apiHandlers.JWKSHandler
+	oauthHandler      *apiHandlers.OAuthHandler
+	messageHandler    *apiHandlers.MessageHandler
+	groupHandler      *apiHandlers.GroupHandler
+	attachmentHandler *apiHandlers.AttachmentHandler
+	websocketHandler  *apiHandlers.WebSocketHandler
+	pushHandler       *apiHandlers.PushHandler
 
 	// 路由层
 	apiRouter *routes.APIRouter
@@ -46,11 +136,13 @@ func NewModule(
 	cfg *config.Config,
 	mysql *database.MySQLService,
 	redis *database.RedisService,
+	mongodb *database.MongoDBService,
 ) *Module {
 	module := &Module{
-		config: cfg,
-		mysql:  mysql,
-		redis:  redis,
+		config:  cfg,
+		mysql:   mysql,
+		redis:   redis,
+		mongodb: mongodb,
 	}
 
 	module.init()
@@ -71,36 +163,196 @@ func (module *Module) initRepositories() {
 	module.userRepo = mysql.NewUserRepository(module.mysql.DB())
 	module.adminRepo = mysql.NewAdminRepository(module.mysql.DB())
 	module.cacheRepo = repository.NewRedisCacheRepository(module.redis)
+	module.deletionRepo = mysql.NewAccountDeletionRepository(module.mysql.DB())
+	module.exportRepo = mysql.NewChatExportRepository(module.mysql.DB())
+	module.messageRepo = cached.NewCachedMessageRepository(mongodb.NewMessageRepository(module.mongodb), module.redis, module.config.Chat)
+	module.groupRepo = mongodb.NewGroupRepository(module.mongodb)
+	module.apiKeyRepo = mysql.NewAPIKeyRepository(module.mysql.DB())
 }
 
 // initMiddlewares 初始化中间件
 func (module *Module) initMiddlewares() {
-	module.middlewareManager = middleware.NewMiddlewareManager(module.redis)
+	module.middlewareManager = middleware.NewMiddlewareManager(module.redis, module.config)
 	module.authMiddleware = middleware.NewUserAuthMiddleware(module.redis, module.config)
+
+	// 内部服务签名密钥从密钥提供者加载，缓存过期后重新读取以支持密钥轮换
+	provider, err := secrets.NewProvider(module.config.Secrets)
+	if err != nil {
+		panic("密钥提供者初始化失败: " + err.Error())
+	}
+	module.secrets = provider
+	keyring := signing.NewKeyring(provider, time.Duration(module.config.ServiceAuth.KeyCacheTTL)*time.Second)
+	module.serviceAuth = middleware.NewServiceAuthMiddleware(module.redis, module.config, keyring)
+
+	// 程序化客户端的API key签名密钥同样从密钥提供者加载，key单独的限流上限与接口限流共用计数存储
+	apiKeyring := signing.NewAPIKeyring(provider, time.Duration(module.config.APIKeyAuth.KeyCacheTTL)*time.Second)
+	module.apiKeyAuth = middleware.NewAPIKeyAuthMiddleware(module.redis, module.config.APIKeyAuth, apiKeyring, module.middlewareManager.RateLimit())
+	module.authMiddleware.SetAPIKeyAuth(module.apiKeyAuth)
+
+	// 导出下载链接签名密钥与内部服务密钥使用同一个密钥提供者
+	module.linkSigner = export.NewLinkSigner(provider)
+	storage, err := export.NewLocalStorage(module.config.Export.Dir)
+	if err != nil {
+		panic("导出文件存储初始化失败: " + err.Error())
+	}
+	module.exportStorage = storage
+
+	eventStorage, err := export.NewLocalStorage(module.config.EventLog.ExportDir)
+	if err != nil {
+		panic("领域事件导出存储初始化失败: " + err.Error())
+	}
+	module.eventExportStorage = eventStorage
+
+	// 聊天附件存储，S3和MinIO的访问密钥、本地存储下载链接的签名密钥同样从密钥提供者读取
+	attachmentStore, err := objectstore.NewStore(module.config.Attachment, provider)
+	if err != nil {
+		panic("聊天附件存储初始化失败: " + err.Error())
+	}
+	module.attachmentStore = attachmentStore
+
+	// 登录、注册和找回密码失败次数过多后需要人机验证，校验密钥同样从密钥提供者读取
+	var verifier captcha.Verifier
+	if module.config.Captcha.Enabled {
+		if verifier, err = captcha.NewVerifier(module.config.Captcha, provider); err != nil {
+			panic("人机验证初始化失败: " + err.Error())
+		}
+	}
+	module.captcha = middleware.NewCaptchaMiddleware(verifier, captcha.NewFailureCounter(module.redis, module.config.Captcha), module.config.Captcha)
 }
 
 // initLogic 初始化业务逻辑层
 func (module *Module) initLogic() {
-	module.userLogic = logic.NewAPIUserLogic(module.userRepo, module.adminRepo)
+	module.userLogic = logic.NewAPIUserLogic(module.userRepo, module.adminRepo, module.cacheRepo)
 
-	authLogic, err := logic.NewAPIAuthLogic(module.config, module.userRepo, module.adminRepo, module.cacheRepo)
+	// 两步验证服务，登录时校验验证码并按角色强制启用
+	twoFactor := twofactor.NewService(mysql.NewTwoFactorRepository(module.mysql.DB()), module.redis, module.config.TwoFactor)
+
+	// 登录失败限制，失败次数过多时暂时禁止登录
+	throttle := loginthrottle.NewThrottle(module.redis, module.config.LoginThrottle)
+
+	// JWT密钥在启动时加载，非对称算法的轮换（增删key、切换签发key）通过修改配置并重启完成
+	jwtKeys, err := jwtkeys.Load(context.Background(), module.config.JWT, module.secrets)
+	if err != nil {
+		panic("JWT密钥加载失败: " + err.Error())
+	}
+	module.jwtKeys = jwtKeys
+
+	sessions := session.NewStore(module.redis, time.Duration(module.config.JWT.RefreshTokenDays)*24*time.Hour)
+	revoked := tokenrevoke.NewStore(module.redis, module.config.JWT.MaxTokenLifetime())
+	authLogic, err := logic.NewAPIAuthLogic(module.config, module.jwtKeys, module.userRepo, module.adminRepo, module.cacheRepo, sessions, revoked, twoFactor, throttle)
 	if err != nil {
 		panic("API认证逻辑初始化失败: " + err.Error())
 	}
 	module.authLogic = authLogic
+	module.twoFactorLogic = logic.NewAPITwoFactorLogic(module.userRepo, twoFactor)
+	module.sessionLogic = logic.NewAPISessionLogic(sessions)
+
+	// 通知按模板渲染后通过邮件发送（email.provider为log时只写日志）
+	sender, err := mailer.NewSender(module.config.Email, module.secrets)
+	if err != nil {
+		panic("邮件发送初始化失败: " + err.Error())
+	}
+	renderer := notification.NewRenderer(mysql.NewNotificationTemplateRepository(module.mysql.DB()), i18n.GetGlobalI18n().GetDefaultLanguage())
+	notifier := notification.NewAsyncNotifier(notification.NewTemplateNotifier(notification.NewEmailNotifier(notification.NewLogNotifier(), sender), renderer))
+	module.deletionLogic = logic.NewAPIAccountDeletionLogic(module.config, module.userRepo, module.deletionRepo, sessions, revoked, notifier)
+	module.deletionLogic.AddDeletionGuard(retention.NewLegalHoldGuard(mysql.NewRetentionRepository(module.mysql.DB()))) // 法律保全期间不允许注销
+	module.deletionLogic.AddDeletionGuard(trading.NewDeletionGuard(nil))                                                // 尚未接入订单和余额数据，注销一律拒绝
+	module.resetLogic = logic.NewAPIPasswordResetLogic(module.config, module.userRepo, passwordreset.NewStore(module.redis, module.config.Account), sessions, revoked, notifier)
+	module.verifyLogic = logic.NewAPIEmailVerificationLogic(module.config, module.userRepo, emailverify.NewStore(module.redis, module.config.Account), notifier)
+
+	// 新设备和异地登录检测，csv方式的IP段文件在启动时加载，更新后重启生效
+	locator, err := geoip.NewLocator(module.config.LoginRisk.GeoIP)
+	if err != nil {
+		panic("GeoIP初始化失败: " + err.Error())
+	}
+	confirms := loginrisk.NewConfirmStore(module.redis, time.Duration(module.config.LoginRisk.ConfirmTTLMinutes)*time.Minute)
+	module.loginRiskLogic = logic.NewAPILoginRiskLogic(module.config, mysql.NewLoginDeviceRepository(module.mysql.DB()), locator, confirms, notifier)
+
+	// 群聊消息和消息回执写入用户的推送缓冲区（与WebSocket断线补发共用），再按在线状态发布到接收者连接所在的实例
+	presence := wsLogic.NewRedisPresence(module.redis, module.config.WebSocket)
+	broker := wsLogic.NewBroker(wsLogic.NewRedisReplayBuffer(module.redis, module.config.WebSocket), module.redis, presence)
+	module.messageLogic = logic.NewAPIMessageLogic(module.config, module.userRepo, module.messageRepo, broker)
+	module.groupLogic = logic.NewAPIGroupLogic(module.config, module.userRepo, module.groupRepo, mongodb.NewMessageRepository(module.mongodb), broker)
+	module.presenceLogic = logic.NewAPIPresenceLogic(module.config, presence, broker, module.userRepo, module.groupRepo)
+
+	// 接收者离线时新消息写入推送队列，由定时任务PushDispatchTask按通知设置发送；推送服务的凭据同样从密钥提供者读取
+	var pushRouter *push.Router
+	if module.config.Push.Enabled {
+		if pushRouter, err = push.NewRouter(module.config.Push, module.secrets); err != nil {
+			panic("离线推送初始化失败: " + err.Error())
+		}
+	}
+	module.pushLogic = logic.NewAPIPushLogic(module.config, pushRouter, push.NewQueue(module.redis), presence, module.userRepo, module.groupRepo,
+		mysql.NewPushDeviceRepository(module.mysql.DB()), mysql.NewNotificationPreferenceRepository(module.mysql.DB()))
+	broker.SetOfflineHandler(module.pushLogic.NotifyOffline)
+
+	// 客户端通过WebSocket确认收到单聊消息后标记为已投递，正在输入事件校验会话后转发给对方
+	module.websocketHub = wsLogic.NewHub(module.redis, presence, broker, module.config.WebSocket)
+	module.websocketHub.SetAckHandler(func(ctx context.Context, userID uint, messageIDs []string) {
+		if _, err := module.messageLogic.MarkDelivered(ctx, userID, messageIDs); err != nil && !errors.Is(err, logic.ErrMessageNotFound) {
+			appLogger.Warn("标记消息已投递失败", map[string]interface{}{
+				"user_id": userID,
+				"error":   err.Error(),
+			})
+		}
+	})
+	module.websocketHub.SetTypingHandler(func(ctx context.Context, userID uint, req wsDto.TypingRequest) error {
+		err := module.presenceLogic.Typing(ctx, userID, req)
+		if err != nil && !errors.Is(err, logic.ErrTypingTargetNotFound) {
+			appLogger.Warn("推送正在输入事件失败", map[string]interface{}{
+				"user_id": userID,
+				"error":   err.Error(),
+			})
+			return nil
+		}
+		return err
+	})
+	module.websocketHub.Start(context.Background())
+	module.attachmentLogic = logic.NewAPIAttachmentLogic(module.config, module.attachmentStore, module.messageRepo, module.groupRepo)
+	module.exportLogic = logic.NewAPIChatExportLogic(module.config, module.userRepo, module.exportRepo, module.messageRepo, module.exportStorage, module.linkSigner)
+	module.eventExportLogic = logic.NewAPIEventExportLogic(module.eventExportStorage)
+	module.cacheStatsLogic = logic.NewAPICacheStatsLogic(module.middlewareManager.Cache())
 
 	// 设置认证逻辑到中间件
 	module.authMiddleware.SetAuthLogic(module.authLogic)
+	module.authMiddleware.SetLanguageResolver(module.userLogic)
+	module.authMiddleware.SetEmailVerificationChecker(module.verifyLogic)
+
+	// 用户创建的API key保存在数据库中，以密钥认证
+	module.apiKeyLogic = logic.NewAPIAPIKeyLogic(module.config.APIKeyAuth, module.apiKeyRepo, module.userRepo)
+	module.apiKeyAuth.SetStoredKeys(module.apiKeyLogic)
+
+	// 第三方登录的客户端密钥在启动时从密钥提供者读取，修改后重启生效
+	providers, err := oauth.NewProviders(context.Background(), module.config.OAuth, module.secrets)
+	if err != nil {
+		panic("第三方登录初始化失败: " + err.Error())
+	}
+	states := oauth.NewStateStore(module.redis, time.Duration(module.config.OAuth.StateTTL)*time.Second)
+	module.oauthLogic = logic.NewAPIOAuthLogic(providers, states, module.userRepo, mysql.NewOAuthIdentityRepository(module.mysql.DB()), twoFactor)
 }
 
 // initHandlers 初始化处理器层
 func (module *Module) initHandlers() {
-	module.userHandler = apiHandlers.NewUserHandler(module.userLogic, module.authLogic)
+	module.userHandler = apiHandlers.NewUserHandler(module.userLogic, module.authLogic, module.deletionLogic, module.verifyLogic, module.loginRiskLogic)
+	module.chatExportHandler = apiHandlers.NewChatExportHandler(module.exportLogic)
+	module.internalHandler = apiHandlers.NewInternalHandler(module.userLogic, module.eventExportLogic, module.cacheStatsLogic)
+	module.twoFactorHandler = apiHandlers.NewTwoFactorHandler(module.twoFactorLogic, module.authLogic, module.loginRiskLogic)
+	module.sessionHandler = apiHandlers.NewSessionHandler(module.sessionLogic)
+	module.resetHandler = apiHandlers.NewPasswordResetHandler(module.resetLogic)
+	module.verifyHandler = apiHandlers.NewEmailVerificationHandler(module.verifyLogic)
+	module.apiKeyHandler = apiHandlers.NewAPIKeyHandler(module.apiKeyLogic)
+	module.jwksHandler = apiHandlers.NewJWKSHandler(module.jwtKeys)
+	module.oauthHandler = apiHandlers.NewOAuthHandler(module.oauthLogic, module.authLogic, module.loginRiskLogic)
+	module.messageHandler = apiHandlers.NewMessageHandler(module.messageLogic)
+	module.groupHandler = apiHandlers.NewGroupHandler(module.groupLogic, module.attachmentLogic)
+	module.attachmentHandler = apiHandlers.NewAttachmentHandler(module.attachmentLogic)
+	module.websocketHandler = apiHandlers.NewWebSocketHandler(module.authLogic, module.presenceLogic, module.websocketHub)
+	module.pushHandler = apiHandlers.NewPushHandler(module.pushLogic)
 }
 
 // initRoutes 初始化路由层
 func (module *Module) initRoutes() {
-	module.apiRouter = routes.NewAPIRouter(module.userHandler, module.authMiddleware)
+	module.apiRouter = routes.NewAPIRouter(module.userHandler, module.chatExportHandler, module.internalHandler, module.twoFactorHandler, module.sessionHandler, module.resetHandler, module.verifyHandler, module.apiKeyHandler, module.jwksHandler, module.oauthHandler, module.messageHandler, module.groupHandler, module.attachmentHandler, module.websocketHandler, module.pushHandler, module.authMiddleware, module.serviceAuth, module.middlewareManager.RateLimit(), module.captcha)
 }
 
 // SetupRoutes 设置路由
@@ -113,6 +365,16 @@ func (module *Module) GetMiddlewareManager() *middleware.MiddlewareManager {
 	return module.middlewareManager
 }
 
+// GetAccountDeletionLogic 获取账户注销业务逻辑（供其他模块注册注销前置校验）
+func (module *Module) GetAccountDeletionLogic() logic.AccountDeletionLogic {
+	return module.deletionLogic
+}
+
+// DrainWebSockets 关闭前断开本实例的WebSocket连接并清除在线状态，客户端重连到其他实例后补发错过的事件
+func (module *Module) DrainWebSockets(ctx context.Context) error {
+	return module.websocketHub.Drain(ctx)
+}
+
 // GetAuthMiddleware 获取认证中间件
 func (module *Module) GetAuthMiddleware() *middleware.UserAuthMiddleware {
 	return module.authMiddleware
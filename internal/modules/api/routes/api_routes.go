@@ -5,32 +5,141 @@ import (
 
 	"exchange/internal/middleware"
 	apiHandlers "exchange/internal/modules/api/handlers"
+	"exchange/internal/pkg/captcha"
 )
 
 // APIRouter API路由管理器 - 负责设置所有API相关的路由
 type APIRouter struct {
-	userHandler    *apiHandlers.UserHandler       // 用户处理器
-	authMiddleware *middleware.UserAuthMiddleware // 用户认证中间件
+	userHandler           *apiHandlers.UserHandler              // 用户处理器
+	chatExportHandler     *apiHandlers.ChatExportHandler        // 会话导出处理器
+	internalHandler       *apiHandlers.InternalHandler          // 内部服务接口处理器
+	twoFactorHandler      *apiHandlers.TwoFactorHandler         // 两步验证处理器
+	sessionHandler        *apiHandlers.SessionHandler           // 登录会话处理器
+	resetHandler          *apiHandlers.PasswordResetHandler     // 找回密码处理器
+	verifyHandler         *apiHandlers.EmailVerificationHandler // 邮箱验证处理器
+	apiKeyHandler         *apiHandlers.APIKeyHandler            // API key处理器
+	jwksHandler           *apiHandlers.JWKSHandler              // JWT公钥处理器
+	oauthHandler          *apiHandlers.OAuthHandler             // 第三方登录处理器
+	messageHandler        *apiHandlers.MessageHandler           // 会话消息处理器
+	groupHandler          *apiHandlers.GroupHandler             // 群聊处理器
+	attachmentHandler     *apiHandlers.AttachmentHandler        // 聊天附件处理器
+	websocketHandler      *apiHandlers.WebSocketHandler         // WebSocket处理器
+	pushHandler           *apiHandlers.PushHandler              // 离线推送处理器
+	authMiddleware        *middleware.UserAuthMiddleware        // 用户认证中间件
+	serviceAuthMiddleware *middleware.ServiceAuthMiddleware     // 内部服务签名认证中间件
+	rateLimitMiddleware   *middleware.RateLimitMiddleware       // 接口限流中间件
+	captchaMiddleware     *middleware.CaptchaMiddleware         // 人机验证中间件
 }
 
 // NewAPIRouter 创建API路由管理器
 // 参数说明：
 // - userHandler: 用户处理器，处理用户相关的HTTP请求
+// - chatExportHandler: 会话导出处理器，处理会话导出和签名链接下载
+// - internalHandler: 内部服务接口处理器，供其他内部服务调用
+// - twoFactorHandler: 两步验证处理器，处理绑定、启用、关闭两步验证和登录时确认绑定
+// - sessionHandler: 登录会话处理器，查看和撤销本人在各设备上的登录会话
+// - resetHandler: 找回密码处理器，发送重置密码邮件和设置新密码
+// - verifyHandler: 邮箱验证处理器，重新发送验证邮件和完成验证
+// - apiKeyHandler: API key处理器，管理供程序化客户端使用的API key
+// - jwksHandler: JWT公钥处理器，发布验证token的公钥
+// - oauthHandler: 第三方登录处理器，发起Google、GitHub、Apple授权和回调后登录
+// - messageHandler: 会话消息处理器，按游标分页查询历史消息
+// - groupHandler: 群聊处理器，管理群聊、成员和角色，收发群聊消息
+// - attachmentHandler: 聊天附件处理器，上传附件和生成消息附件的下载链接
+// - websocketHandler: WebSocket处理器，建立推送事件的连接和查询用户在线状态
+// - pushHandler: 离线推送处理器，注册推送设备和修改通知设置
 // - authMiddleware: 用户认证中间件，用于验证用户身份
-func NewAPIRouter(userHandler *apiHandlers.UserHandler, authMiddleware *middleware.UserAuthMiddleware) *APIRouter {
+// - serviceAuthMiddleware: 内部服务签名认证中间件，用于验证调用方服务
+// - rateLimitMiddleware: 接口限流中间件，在认证之后执行按用户限流的策略
+// - captchaMiddleware: 人机验证中间件，登录、注册和找回密码失败次数过多后要求人机验证
+func NewAPIRouter(
+	userHandler *apiHandlers.UserHandler,
+	chatExportHandler *apiHandlers.ChatExportHandler,
+	internalHandler *apiHandlers.InternalHandler,
+	twoFactorHandler *apiHandlers.TwoFactorHandler,
+	sessionHandler *apiHandlers.SessionHandler,
+	resetHandler *apiHandlers.PasswordResetHandler,
+	verifyHandler *apiHandlers.EmailVerificationHandler,
+	apiKeyHandler *apiHandlers.APIKeyHandler,
+	jwksHandler *apiHandlers.JWKSHandler,
+	oauthHandler *apiHandlers.OAuthHandler,
+	messageHandler *apiHandlers.MessageHandler,
+	groupHandler *apiHandlers.GroupHandler,
+	attachmentHandler *apiHandlers.AttachmentHandler,
+	websocketHandler *apiHandlers.WebSocketHandler,
+	pushHandler *apiHandlers.PushHandler,
+	authMiddleware *middleware.UserAuthMiddleware,
+	serviceAuthMiddleware *middleware.ServiceAuthMiddleware,
+	rateLimitMiddleware *middleware.RateLimitMiddleware,
+	captchaMiddleware *middleware.CaptchaMiddleware,
+) *APIRouter {
 	return &APIRouter{
-		userHandler:    userHandler,
-		authMiddleware: authMiddleware,
+		userHandler:           userHandler,
+		chatExportHandler:     chatExportHandler,
+		internalHandler:       internalHandler,
+		twoFactorHandler:      twoFactorHandler,
+		sessionHandler:        sessionHandler,
+		resetHandler:          resetHandler,
+		verifyHandler:         verifyHandler,
+		apiKeyHandler:         apiKeyHandler,
+		jwksHandler:           jwksHandler,
+		oauthHandler:          oauthHandler,
+		messageHandler:        messageHandler,
+		groupHandler:          groupHandler,
+		attachmentHandler:     attachmentHandler,
+		websocketHandler:      websocketHandler,
+		pushHandler:           pushHandler,
+		authMiddleware:        authMiddleware,
+		serviceAuthMiddleware: serviceAuthMiddleware,
+		rateLimitMiddleware:   rateLimitMiddleware,
+		captchaMiddleware:     captchaMiddleware,
 	}
 }
 
 // SetupRoutes 设置API路由到Gin引擎
 // 路由结构：
 // /api/v1/user/register - 用户注册（无需认证）
-// /api/v1/user/login    - 用户登录（无需认证）
+// /api/v1/user/login    - 用户登录（无需认证），返回访问token和刷新token；已启用两步验证时需要验证码
+// /api/v1/user/login/confirm - 使用确认登录邮件中的token确认新设备（无需认证）
+// /api/v1/user/2fa/confirm-setup - 强制启用两步验证的用户登录时确认绑定（无需认证，使用绑定token）
+// /api/v1/user/token/refresh - 使用刷新token换取新token（无需认证）
+// /api/v1/user/invite/accept - 接受邀请并设置密码（无需认证）
+// /api/v1/user/password/forgot - 发送重置密码邮件（无需认证）
+// /api/v1/user/password/reset  - 使用邮件中的token设置新密码（无需认证）
+// /api/v1/user/oauth/:provider/authorize - 发起第三方登录（无需认证）
+// /api/v1/user/oauth/:provider/callback  - 提交授权码完成第三方登录（无需认证）
+// /api/v1/user/email/verify - 使用验证邮件中的token完成邮箱验证（无需认证）
+// /api/v1/user/email/verification - 重新发送验证邮件（需要认证）
 // /api/v1/user/profile  - 获取用户资料（需要认证）
+// /api/v1/user/language - 设置首选语言（需要认证）
+// /api/v1/user/logout   - 退出登录（需要认证）
+// /api/v1/user/2fa      - 两步验证状态/绑定、启用、关闭和重新生成备用码（需要认证）
+// /api/v1/user/sessions - 登录会话（设备）列表/撤销单个会话、撤销其他会话（需要认证）
+// /api/v1/user/api-keys - API key列表/创建/修改/删除（需要JWT认证，创建需要已验证邮箱）
+// /api/v1/user/deletion - 账户注销申请/查询/撤销（需要认证）
+// /api/v1/user/messages/unread - 单聊未读总数和各会话的未读数（需要认证）
+// /api/v1/user/messages/read - 批量标记已读，默认处理所有有未读消息的会话（需要认证）
+// /api/v1/user/messages/:peer_id - 与对方的历史消息，按游标分页（需要认证）
+// /api/v1/user/messages/:id - 修改/删除本人发送的消息（需要认证）
+// /api/v1/user/messages/:peer_id/read - 将对方发来的消息标记为已读并推送已读回执（需要认证）
+// /api/v1/user/groups - 群聊列表/创建、群资料、成员和角色管理、群聊消息和已读位置（需要认证）
+// /api/v1/user/attachments - 上传聊天附件（需要认证）
+// /api/v1/user/attachments/:message_id - 获取消息附件的限时下载链接（需要认证）
+// /api/v1/user/presence - 批量查询用户在线状态（需要认证）
+// /api/v1/user/push/devices - 推送设备列表/注册/删除（需要认证）
+// /api/v1/user/push/preferences - 通知设置查询/修改（需要认证）
+// /api/v1/user/exports/chats - 会话导出申请/查询（需要认证）
+// /api/v1/user/exports/chat-consent - 会话导出授权（需要认证）
+// /api/v1/exports/:id/download - 通过签名链接下载导出文件（无需认证，校验签名）
+// /api/v1/attachments/download - 通过签名链接下载本地存储的附件（无需认证，校验签名）
+// /api/v1/ws - 建立WebSocket连接（握手时校验token参数或Authorization请求头中的访问token）
 // /api/v1/system/ping   - 健康检查（无需认证）
 // /api/v1/system/info   - 系统信息（无需认证）
+// /.well-known/jwks.json - 验证JWT的公钥（无需认证）
+// /internal/v1/users/:id - 获取用户信息（需要内部服务签名）
+// /internal/v1/event-exports - 领域事件导出分区manifest查询和文件下载（需要内部服务签名）
+// /internal/v1/cache/stats - 本实例的缓存统计（需要内部服务签名）
+// /internal/v1/messages/delivered - 独立部署的WebSocket网关转发接收方的确认，标记消息已投递（需要内部服务签名）
 func (r *APIRouter) SetupRoutes(router *gin.Engine) {
 	// 创建API v1路由组
 	apiV1 := router.Group("/api/v1")
@@ -41,40 +150,206 @@ func (r *APIRouter) SetupRoutes(router *gin.Engine) {
 		// 设置用户管理路由（需要认证）
 		r.setupUserRoutes(apiV1)
 
+		// 设置导出文件和附件下载路由（签名链接）
+		r.setupExportRoutes(apiV1)
+
+		// 设置WebSocket路由（握手时自行校验token）
+		r.setupWebSocketRoutes(apiV1)
+
 		// 设置系统路由（无需认证）
 		r.setupSystemRoutes(apiV1)
 	}
+
+	// 设置JWT公钥路由（无需认证）
+	r.setupWellKnownRoutes(router)
+
+	// 设置内部服务路由（需要内部服务签名）
+	r.setupInternalRoutes(router)
 }
 
 // setupAuthRoutes 设置用户认证路由（无需认证）
 func (r *APIRouter) setupAuthRoutes(apiV1 *gin.RouterGroup) {
 	auth := apiV1.Group("/user")
 	{
-		auth.POST("/register", r.userHandler.Register) // 用户注册
-		auth.POST("/login", r.userHandler.Login)       // 用户登录
+		auth.POST("/register", r.captchaMiddleware.Protect(captcha.ScopeRegister), r.userHandler.Register) // 用户注册
+		auth.POST("/login", r.captchaMiddleware.Protect(captcha.ScopeLogin), r.userHandler.Login)          // 用户登录
+		auth.POST("/login/confirm", r.userHandler.ConfirmLogin)                                            // 确认新设备登录
+
+		auth.POST("/token/refresh", r.userHandler.RefreshToken) // 使用刷新token换取新token
+
+		auth.POST("/invite/accept", r.userHandler.AcceptInvite) // 接受邀请并设置密码
+
+		auth.POST("/password/forgot", r.captchaMiddleware.ProtectRequests(captcha.ScopePasswordReset), r.resetHandler.ForgotPassword) // 发送重置密码邮件
+		auth.POST("/password/reset", r.captchaMiddleware.Protect(captcha.ScopePasswordReset), r.resetHandler.ResetPassword)           // 使用重置token设置新密码
+
+		auth.POST("/email/verify", r.verifyHandler.VerifyEmail) // 使用验证token完成邮箱验证
+
+		auth.POST("/2fa/confirm-setup", r.twoFactorHandler.ConfirmSetup) // 登录时确认两步验证绑定
+
+		auth.GET("/oauth/:provider/authorize", r.oauthHandler.Authorize) // 发起第三方登录
+		auth.POST("/oauth/:provider/callback", r.oauthHandler.Callback)  // 提交授权码完成第三方登录
 	}
+
+	// 与用户管理路由共用/user前缀，需逐个声明为公开路由
+	matrix := middleware.GetAuthMatrix()
+	matrix.ClassifyRoute("POST", auth.BasePath()+"/register", middleware.PublicRequirement())
+	matrix.ClassifyRoute("POST", auth.BasePath()+"/login", middleware.PublicRequirement())
+	matrix.ClassifyRoute("POST", auth.BasePath()+"/login/confirm", middleware.PublicRequirement())
+	matrix.ClassifyRoute("POST", auth.BasePath()+"/token/refresh", middleware.PublicRequirement())
+	matrix.ClassifyRoute("POST", auth.BasePath()+"/invite/accept", middleware.PublicRequirement())
+	matrix.ClassifyRoute("POST", auth.BasePath()+"/password/forgot", middleware.PublicRequirement())
+	matrix.ClassifyRoute("POST", auth.BasePath()+"/password/reset", middleware.PublicRequirement())
+	matrix.ClassifyRoute("POST", auth.BasePath()+"/email/verify", middleware.PublicRequirement())
+	matrix.ClassifyRoute("POST", auth.BasePath()+"/2fa/confirm-setup", middleware.PublicRequirement())
+	matrix.ClassifyRoute("GET", auth.BasePath()+"/oauth/:provider/authorize", middleware.PublicRequirement())
+	matrix.ClassifyRoute("POST", auth.BasePath()+"/oauth/:provider/callback", middleware.PublicRequirement())
 }
 
 // setupUserRoutes 设置用户管理路由（需要认证）
 func (r *APIRouter) setupUserRoutes(apiV1 *gin.RouterGroup) {
-	user := apiV1.Group("/user")
-	user.Use(r.authMiddleware.RequireAuth()) // 添加认证中间件
+	user := middleware.GetAuthMatrix().Routes(apiV1.Group("/user"))
+	user.Use(r.authMiddleware.RequireAuth(), r.rateLimitMiddleware.LimitUser()) // 添加认证中间件和按用户限流中间件
+	jwtOnly := r.authMiddleware.RequireJWT()                                    // 账户管理操作只允许登录token，不接受API key
 	{
-		user.GET("/profile", r.userHandler.GetProfile) // 获取用户资料
-		// 注意：UpdateProfile、ChangePassword、Logout方法已在handler中删除
+		user.GET("/profile", r.userHandler.GetProfile)      // 获取用户资料
+		user.PUT("/language", r.userHandler.SetLanguage)    // 设置首选语言
+		user.POST("/logout", jwtOnly, r.userHandler.Logout) // 退出登录（撤销当前登录会话）
+
+		user.POST("/email/verification", jwtOnly, r.verifyHandler.SendVerification) // 重新发送验证邮件
+
+		// 两步验证（TOTP）
+		user.GET("/2fa", jwtOnly, r.twoFactorHandler.GetStatus)                           // 两步验证状态
+		user.POST("/2fa/setup", jwtOnly, r.twoFactorHandler.BeginSetup)                   // 开始绑定，返回密钥和绑定地址
+		user.POST("/2fa/enable", jwtOnly, r.twoFactorHandler.Enable)                      // 确认绑定并启用，返回备用码
+		user.POST("/2fa/disable", jwtOnly, r.twoFactorHandler.Disable)                    // 关闭两步验证
+		user.POST("/2fa/backup-codes", jwtOnly, r.twoFactorHandler.RegenerateBackupCodes) // 重新生成备用码
+
+		// 登录会话（设备）
+		user.GET("/sessions", jwtOnly, r.sessionHandler.ListSessions)           // 本人的登录会话列表
+		user.DELETE("/sessions/:id", jwtOnly, r.sessionHandler.RevokeSession)   // 撤销一个会话（在该设备上退出登录）
+		user.DELETE("/sessions", jwtOnly, r.sessionHandler.RevokeOtherSessions) // 撤销当前会话以外的所有会话
+
+		// API key（程序化客户端使用，不能以API key认证管理）
+		user.GET("/api-keys", jwtOnly, r.apiKeyHandler.ListKeys)                                            // 本人的API key列表
+		user.POST("/api-keys", jwtOnly, r.authMiddleware.RequireVerifiedEmail(), r.apiKeyHandler.CreateKey) // 创建API key，需要已验证邮箱
+		user.PUT("/api-keys/:id", jwtOnly, r.apiKeyHandler.UpdateKey)                                       // 修改名称、权限和允许的IP
+		user.DELETE("/api-keys/:id", jwtOnly, r.apiKeyHandler.DeleteKey)                                    // 删除API key
+
+		// 账户注销（冷静期后由定时任务匿名化）
+		user.POST("/deletion", jwtOnly, r.userHandler.RequestDeletion)  // 提交注销申请
+		user.GET("/deletion", jwtOnly, r.userHandler.GetDeletionStatus) // 查询注销申请
+		user.DELETE("/deletion", jwtOnly, r.userHandler.CancelDeletion) // 撤销注销申请
+
+		user.GET("/messages/unread", r.messageHandler.GetUnreadCounts)    // 单聊未读数
+		user.POST("/messages/read", r.messageHandler.MarkAllRead)         // 批量标记已读
+		user.GET("/messages/:peer_id", r.messageHandler.ListConversation) // 与对方的历史消息，按游标分页
+		user.PUT("/messages/:id", r.messageHandler.EditMessage)           // 修改本人发送的消息
+		user.DELETE("/messages/:id", r.messageHandler.DeleteMessage)      // 删除本人发送的消息
+		user.POST("/messages/:peer_id/read", r.messageHandler.MarkRead)   // 标记对方发来的消息为已读
+
+		// 群聊
+		user.GET("/groups", r.groupHandler.ListGroups)                              // 本人加入的群聊及未读消息数
+		user.POST("/groups", r.groupHandler.CreateGroup)                            // 创建群聊
+		user.GET("/groups/:id", r.groupHandler.GetGroup)                            // 群资料和成员列表
+		user.PUT("/groups/:id", r.groupHandler.UpdateGroup)                         // 修改群资料（群主和管理员）
+		user.DELETE("/groups/:id", r.groupHandler.DeleteGroup)                      // 解散群聊（群主）
+		user.POST("/groups/:id/members", r.groupHandler.AddMembers)                 // 邀请成员（群主和管理员）
+		user.DELETE("/groups/:id/members/:user_id", r.groupHandler.RemoveMember)    // 移除成员，user_id为本人时退出群聊
+		user.PUT("/groups/:id/members/:user_id/role", r.groupHandler.SetMemberRole) // 设置成员角色或转让群主（群主）
+		user.GET("/groups/:id/messages", r.groupHandler.ListMessages)               // 群聊消息，按游标分页
+		user.POST("/groups/:id/messages", r.groupHandler.SendMessage)               // 发送群聊消息
+		user.PUT("/groups/:id/read", r.groupHandler.MarkRead)                       // 更新已读位置
+
+		// 聊天附件（消息中只保存对象key，下载时生成限时链接）
+		user.POST("/attachments", r.attachmentHandler.Upload)                    // 上传附件，返回的key用于发送文件消息
+		user.GET("/attachments/:message_id", r.attachmentHandler.GetDownloadURL) // 获取消息附件的下载链接
+
+		user.GET("/presence", r.websocketHandler.GetPresence) // 批量查询用户在线状态
+
+		// 离线推送（没有WebSocket连接时新消息通过FCM/APNs推送到已注册的设备）
+		user.GET("/push/devices", r.pushHandler.ListDevices)           // 本人的推送设备列表
+		user.POST("/push/devices", r.pushHandler.RegisterDevice)       // 注册推送设备
+		user.DELETE("/push/devices/:id", r.pushHandler.DeleteDevice)   // 删除推送设备
+		user.GET("/push/preferences", r.pushHandler.GetPreferences)    // 通知设置
+		user.PUT("/push/preferences", r.pushHandler.UpdatePreferences) // 修改通知设置和免打扰时段
+
+		// 会话导出（后台生成，完成后通过限时签名链接下载）
+		user.POST("/exports/chats", jwtOnly, r.authMiddleware.RequireVerifiedEmail(), r.chatExportHandler.RequestExport) // 申请导出会话，需要已验证邮箱
+
+		user.GET("/exports/chats/:id", jwtOnly, r.chatExportHandler.GetExport)     // 查询导出任务和下载链接
+		user.GET("/exports/chat-consent", jwtOnly, r.chatExportHandler.GetConsent) // 获取导出授权
+		user.PUT("/exports/chat-consent", jwtOnly, r.chatExportHandler.SetConsent) // 设置导出授权
+		// 注意：UpdateProfile、ChangePassword方法已在handler中删除
 		// 如果需要这些功能，可以重新添加
 	}
 }
 
+// setupExportRoutes 设置导出文件下载路由
+// 下载链接由签名和过期时间保护，可在未登录的浏览器中直接打开
+func (r *APIRouter) setupExportRoutes(apiV1 *gin.RouterGroup) {
+	exports := apiV1.Group("/exports")
+	middleware.GetAuthMatrix().ClassifyGroup(exports, middleware.PublicRequirement())
+	{
+		exports.GET("/:id/download", r.chatExportHandler.Download) // 下载导出文件
+	}
+
+	// 本地存储的附件下载链接（S3和MinIO的预签名链接直接指向存储服务）
+	attachments := apiV1.Group("/attachments")
+	middleware.GetAuthMatrix().ClassifyGroup(attachments, middleware.PublicRequirement())
+	{
+		attachments.GET("/download", r.attachmentHandler.Download) // 下载附件
+	}
+}
+
+// setupWebSocketRoutes 设置WebSocket路由
+// 浏览器无法为WebSocket握手设置请求头，不经过认证中间件，由处理器校验token参数
+func (r *APIRouter) setupWebSocketRoutes(apiV1 *gin.RouterGroup) {
+	ws := apiV1.Group("/ws")
+	middleware.GetAuthMatrix().ClassifyGroup(ws, middleware.PublicRequirement())
+	{
+		ws.GET("", r.websocketHandler.Connect) // 建立WebSocket连接
+	}
+}
+
 // setupSystemRoutes 设置系统路由（无需认证）
 func (r *APIRouter) setupSystemRoutes(apiV1 *gin.RouterGroup) {
 	system := apiV1.Group("/system")
+	middleware.GetAuthMatrix().ClassifyGroup(system, middleware.PublicRequirement())
 	{
 		system.GET("/ping", r.pingHandler) // 健康检查
 		system.GET("/info", r.infoHandler) // 系统信息
 	}
 }
 
+// setupWellKnownRoutes 设置JWT公钥路由（无需认证）
+// 其他服务按token头中的kid从这里获取公钥验证token，轮换期间新旧公钥同时发布
+func (r *APIRouter) setupWellKnownRoutes(router *gin.Engine) {
+	wellKnown := router.Group("/.well-known")
+	middleware.GetAuthMatrix().ClassifyGroup(wellKnown, middleware.PublicRequirement())
+	{
+		wellKnown.GET("/jwks.json", r.jwksHandler.GetJWKS) // JWT公钥
+	}
+}
+
+// setupInternalRoutes 设置内部服务路由（需要内部服务签名）
+// 允许的调用方由配置service_auth.services决定
+func (r *APIRouter) setupInternalRoutes(router *gin.Engine) {
+	internal := middleware.GetAuthMatrix().Routes(router.Group("/internal/v1"))
+	internal.Use(r.serviceAuthMiddleware.RequireService())
+	{
+		internal.GET("/users/:id", r.internalHandler.GetUser) // 获取用户信息
+
+		// 领域事件导出（数据仓库按manifest拉取，不直接访问生产库）
+		internal.GET("/event-exports", r.internalHandler.ListEventExports)                      // 查询日期范围内的分区manifest
+		internal.GET("/event-exports/:date", r.internalHandler.GetEventExport)                  // 获取分区manifest
+		internal.GET("/event-exports/:date/files/:name", r.internalHandler.DownloadEventExport) // 下载分区数据文件
+
+		internal.GET("/cache/stats", r.internalHandler.GetCacheStats) // 本实例的缓存统计
+
+		internal.POST("/messages/delivered", r.messageHandler.MarkDelivered) // 接收方确认收到消息，标记为已投递
+	}
+}
+
 // pingHandler 健康检查接口
 // 用于监控系统是否正常运行
 func (r *APIRouter) pingHandler(c *gin.Context) {
@@ -96,6 +371,15 @@ func (r *APIRouter) infoHandler(c *gin.Context) {
 			"user_registration",
 			"user_login",
 			"user_profile",
+			"password_reset",
+			"email_verification",
+			"two_factor_auth",
+			"session_management",
+			"api_keys",
+			"jwks",
+			"account_deletion",
+			"chat_export",
+			"websocket",
 		},
 	})
 }
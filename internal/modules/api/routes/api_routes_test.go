@@ -0,0 +1,86 @@
+package routes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/middleware"
+	"exchange/internal/pkg/config"
+)
+
+// newTestRouter 创建只用于注册路由的API路由管理器，处理器不会被调用
+func newTestRouter() *APIRouter {
+	cfg := &config.Config{}
+	cfg.ServiceAuth.Services = []string{"billing"}
+	return NewAPIRouter(
+		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		middleware.NewUserAuthMiddleware(nil, cfg),
+		middleware.NewServiceAuthMiddleware(nil, cfg, nil),
+		middleware.NewRateLimitMiddleware(nil, config.RateLimitConfig{}),
+		middleware.NewCaptchaMiddleware(nil, nil, config.CaptchaConfig{}),
+	)
+}
+
+// authCase 路由及其期望的认证要求
+type authCase struct {
+	method string
+	path   string
+	want   middleware.AuthRequirement
+}
+
+func TestAuthMatrixCoversAllRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	newTestRouter().SetupRoutes(engine)
+
+	if err := middleware.GetAuthMatrix().Verify(engine.Routes()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []authCase{
+		{"POST", "/api/v1/user/login", middleware.PublicRequirement()},
+		{"GET", "/api/v1/user/profile", middleware.UserRequirement()},
+		{"GET", "/api/v1/user/messages/unread", middleware.UserRequirement()},
+		{"GET", "/internal/v1/users/:id", middleware.ServiceRequirement("billing")},
+	}
+	// 账户管理路由只允许登录token，API key认证的请求被拒绝
+	jwtOnly := middleware.AuthRequirement{Auth: middleware.AuthUser, JWTOnly: true}
+	for _, route := range []struct{ method, path string }{
+		{"POST", "/api/v1/user/logout"},
+		{"POST", "/api/v1/user/email/verification"},
+		{"GET", "/api/v1/user/2fa"},
+		{"POST", "/api/v1/user/2fa/setup"},
+		{"POST", "/api/v1/user/2fa/enable"},
+		{"POST", "/api/v1/user/2fa/disable"},
+		{"POST", "/api/v1/user/2fa/backup-codes"},
+		{"GET", "/api/v1/user/sessions"},
+		{"DELETE", "/api/v1/user/sessions"},
+		{"DELETE", "/api/v1/user/sessions/:id"},
+		{"GET", "/api/v1/user/api-keys"},
+		{"POST", "/api/v1/user/api-keys"},
+		{"PUT", "/api/v1/user/api-keys/:id"},
+		{"DELETE", "/api/v1/user/api-keys/:id"},
+		{"POST", "/api/v1/user/deletion"},
+		{"GET", "/api/v1/user/deletion"},
+		{"DELETE", "/api/v1/user/deletion"},
+		{"POST", "/api/v1/user/exports/chats"},
+		{"GET", "/api/v1/user/exports/chats/:id"},
+		{"GET", "/api/v1/user/exports/chat-consent"},
+		{"PUT", "/api/v1/user/exports/chat-consent"},
+	} {
+		tests = append(tests, authCase{route.method, route.path, jwtOnly})
+	}
+
+	for _, tt := range tests {
+		got, _, ok := middleware.GetAuthMatrix().Lookup(tt.method, tt.path)
+		if !ok {
+			t.Errorf("%s %s: 未声明认证要求", tt.method, tt.path)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %s: got %+v, want %+v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
@@ -7,9 +7,10 @@ import (
 
 // ConnectRequest WebSocket连接请求
 type ConnectRequest struct {
-	UserID   uint   `json:"user_id" binding:"required"`
-	UserType string `json:"user_type" binding:"required"` // user, admin
-	Token    string `json:"token" binding:"required"`
+	UserID     uint   `json:"user_id" binding:"required"`
+	UserType   string `json:"user_type" binding:"required"` // user, admin
+	Token      string `json:"token" binding:"required"`
+	ResumeFrom uint64 `json:"resume_from" form:"resume_from"` // 重连时携带最后收到的事件序号，补发之后的事件；0表示新连接
 }
 
 // Validate 验证连接请求
@@ -31,6 +32,7 @@ func (r *ConnectRequest) Validate() error {
 
 // Message WebSocket消息
 type Message struct {
+	Seq       uint64                 `json:"seq,omitempty"` // 推送给用户的事件序号（按用户递增），客户端重连时作为resume_from
 	Type      string                 `json:"type" binding:"required"`
 	Data      map[string]interface{} `json:"data"`
 	Timestamp time.Time              `json:"timestamp"`
@@ -39,6 +41,13 @@ type Message struct {
 	RoomID    string                 `json:"room_id,omitempty"`
 }
 
+// ReplayResponse 重连补发结果
+type ReplayResponse struct {
+	Events  []Message `json:"events"`   // 需要补发的事件，按序号升序
+	LastSeq uint64    `json:"last_seq"` // 当前最新序号
+	Gap     bool      `json:"gap"`      // 缓冲区已不包含resume_from之后的全部事件，客户端需要全量同步
+}
+
 // ChatMessage 聊天消息
 type ChatMessage struct {
 	Type      string    `json:"type" binding:"required"`
@@ -49,6 +58,39 @@ type ChatMessage struct {
 	RoomID    string    `json:"room_id,omitempty"`
 }
 
+// HandshakeRequest WebSocket握手参数（GET /api/v1/ws的查询参数）
+// 浏览器无法为WebSocket设置请求头，令牌可以放在token参数中，也可以使用Authorization: Bearer请求头
+type HandshakeRequest struct {
+	Token      string `form:"token"`
+	ResumeFrom uint64 `form:"resume_from"` // 最后收到的事件序号，补发之后的事件；0表示新连接
+}
+
+// AckRequest 客户端确认收到单聊消息（type为ack的帧，data为本结构）
+// 标记消息已投递，发送者收到message_receipt事件；独立部署的网关可以调用内部接口POST /internal/v1/messages/delivered
+type AckRequest struct {
+	MessageIDs []string `json:"message_ids" binding:"required,min=1,max=100"`
+}
+
+// TypingRequest 正在输入（type为typing的帧，data为本结构），peer_id和group_id二选一
+// typing为false表示停止输入；接收方超过typing_timeout未收到新的事件时也应视为停止
+type TypingRequest struct {
+	PeerID  uint   `json:"peer_id"`
+	GroupID string `json:"group_id"`
+	Typing  bool   `json:"typing"`
+}
+
+// PresenceSubscribeRequest 订阅用户的在线状态变化（type为presence_subscribe的帧），替换之前的订阅，空列表表示取消订阅
+type PresenceSubscribeRequest struct {
+	UserIDs []uint `json:"user_ids"`
+}
+
+// PresenceStatus 用户的在线状态
+type PresenceStatus struct {
+	UserID     uint       `json:"user_id"`
+	Online     bool       `json:"online"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"` // 最后在线时间，在线时为最近一次刷新的时间
+}
+
 // JoinRoomRequest 加入房间请求
 type JoinRoomRequest struct {
 	RoomID string `json:"room_id" binding:"required"`
@@ -0,0 +1,112 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"exchange/internal/modules/websocket/dto"
+	"exchange/internal/pkg/database"
+	appLogger "exchange/internal/pkg/logger"
+)
+
+// 在线状态变化频道，所有实例都订阅，消息内容为上线或下线的用户ID
+const presenceChannel = "ws_presence_events"
+
+// Notifier 推送不需要补发的临时事件（如正在输入）
+type Notifier interface {
+	// Notify 发布到用户连接所在的实例，用户离线时丢弃
+	Notify(ctx context.Context, userID uint, msg *dto.Message) error
+}
+
+// OfflineHandler 接收者在任何实例上都没有连接时调用（如转为移动推送）
+type OfflineHandler func(ctx context.Context, userID uint, msg *dto.Message)
+
+// envelope 发布到实例频道的事件
+type envelope struct {
+	UserID  uint         `json:"user_id"`
+	Message *dto.Message `json:"message"`
+}
+
+// Broker 跨实例事件分发
+// 实现ReplayBuffer：事件先写入接收者的补发缓冲区，再发布到接收者连接所在实例的频道，由该实例的Hub推送给客户端；
+// 发布失败或用户不在线时事件仍在缓冲区中，客户端重连后补发
+type Broker struct {
+	buffer    ReplayBuffer
+	redis     *database.RedisService
+	presence  Presence
+	onOffline OfflineHandler
+}
+
+// NewBroker 创建事件分发器
+func NewBroker(buffer ReplayBuffer, redis *database.RedisService, presence Presence) *Broker {
+	return &Broker{
+		buffer:   buffer,
+		redis:    redis,
+		presence: presence,
+	}
+}
+
+// SetOfflineHandler 设置接收者离线时的处理，在开始推送事件前调用
+func (b *Broker) SetOfflineHandler(handler OfflineHandler) {
+	b.onOffline = handler
+}
+
+// Push 写入补发缓冲区并发布到接收者所在的实例，接收者离线时调用OfflineHandler
+// 查询在线状态失败时不视为离线，避免在线用户重复收到移动推送
+func (b *Broker) Push(ctx context.Context, userID uint, msg *dto.Message) (uint64, error) {
+	seq, err := b.buffer.Push(ctx, userID, msg)
+	if err != nil {
+		return 0, err
+	}
+
+	online, err := b.publish(ctx, userID, msg)
+	if err != nil {
+		appLogger.Warn("发布WebSocket事件失败，事件等待重连补发", map[string]interface{}{
+			"user_id": userID,
+			"seq":     seq,
+			"error":   err.Error(),
+		})
+	} else if !online && b.onOffline != nil {
+		b.onOffline(ctx, userID, msg)
+	}
+	return seq, nil
+}
+
+// Notify 只发布到接收者所在的实例，不写入补发缓冲区
+func (b *Broker) Notify(ctx context.Context, userID uint, msg *dto.Message) error {
+	_, err := b.publish(ctx, userID, msg)
+	return err
+}
+
+// publish 发布到用户有连接的每个实例，返回用户是否在线
+func (b *Broker) publish(ctx context.Context, userID uint, msg *dto.Message) (bool, error) {
+	instances, err := b.presence.Instances(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if len(instances) == 0 {
+		return false, nil
+	}
+
+	data, err := json.Marshal(envelope{UserID: userID, Message: msg})
+	if err != nil {
+		return true, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	for _, instanceID := range instances {
+		if err := b.redis.Client().Publish(ctx, instanceChannel(instanceID), data).Err(); err != nil {
+			return true, fmt.Errorf("failed to publish event to %s: %w", instanceID, err)
+		}
+	}
+	return true, nil
+}
+
+// Resume 获取序号大于resumeFrom的事件
+func (b *Broker) Resume(ctx context.Context, userID uint, resumeFrom uint64) (*dto.ReplayResponse, error) {
+	return b.buffer.Resume(ctx, userID, resumeFrom)
+}
+
+// instanceChannel 实例的事件频道
+func instanceChannel(instanceID string) string {
+	return "ws_events:" + instanceID
+}
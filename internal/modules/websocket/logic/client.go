@@ -0,0 +1,187 @@
+package logic
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"exchange/internal/modules/websocket/dto"
+	"exchange/internal/pkg/clock"
+)
+
+// Client 一个WebSocket连接
+// 补发完成前收到的实时事件先暂存，补发完成后按序号去重再进入发送队列；
+// 发送队列满说明客户端消费过慢，直接断开，由客户端携带resume_from重连补发
+type Client struct {
+	userID uint
+	conn   *websocket.Conn
+
+	// 只在读取协程中访问（订阅列表的修改同时持有Hub的锁）
+	watching []uint               // 订阅了在线状态的用户
+	typingAt map[string]time.Time // 各会话最近一次转发开始输入的时间
+
+	mu      sync.Mutex
+	ready   bool
+	closed  bool
+	pending []*dto.Message
+	lastSeq uint64
+	final   *dto.Message
+	queue   chan *dto.Message
+	done    chan struct{}
+}
+
+// newClient 创建连接
+func newClient(userID uint, conn *websocket.Conn, bufferSize int) *Client {
+	return &Client{
+		userID: userID,
+		conn:   conn,
+		queue:  make(chan *dto.Message, bufferSize),
+		done:   make(chan struct{}),
+	}
+}
+
+// replay 补发事件，直接写入连接（发送协程尚未启动，不会并发写）
+func (c *Client) replay(events []dto.Message) error {
+	for i := range events {
+		event := &events[i]
+		if err := c.write(event); err != nil {
+			return err
+		}
+		c.mu.Lock()
+		if event.Seq > c.lastSeq {
+			c.lastSeq = event.Seq
+		}
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+// start 补发完成：发送暂存的实时事件和connected事件，然后启动发送协程
+func (c *Client) start(connected *dto.Message) {
+	c.mu.Lock()
+	c.ready = true
+	pending := c.pending
+	c.pending = nil
+	for _, msg := range pending {
+		c.enqueueLocked(msg)
+	}
+	c.enqueueLocked(connected)
+	c.mu.Unlock()
+
+	go c.writeLoop()
+}
+
+// send 发送事件，已发送过的序号会被跳过
+func (c *Client) send(msg *dto.Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	if !c.ready {
+		c.pending = append(c.pending, msg)
+		if len(c.pending) > cap(c.queue) {
+			c.closeLocked(nil)
+		}
+		return
+	}
+	c.enqueueLocked(msg)
+}
+
+// sendError 发送error事件
+func (c *Client) sendError(message string) {
+	c.send(&dto.Message{
+		Type:      EventError,
+		Data:      map[string]interface{}{"error": message},
+		Timestamp: clock.Now(),
+	})
+}
+
+// allowTyping 同一会话的开始输入在interval内只转发一次，停止输入总是转发
+func (c *Client) allowTyping(req dto.TypingRequest, interval time.Duration) bool {
+	key := "g:" + req.GroupID
+	if req.PeerID != 0 {
+		key = "u:" + strconv.FormatUint(uint64(req.PeerID), 10)
+	}
+	if !req.Typing {
+		delete(c.typingAt, key)
+		return true
+	}
+
+	now := time.Now()
+	if last, ok := c.typingAt[key]; ok && now.Sub(last) < interval {
+		return false
+	}
+	if c.typingAt == nil {
+		c.typingAt = make(map[string]time.Time)
+	}
+	c.typingAt[key] = now
+	return true
+}
+
+// enqueueLocked 按序号去重后放入发送队列，队列满时断开连接
+func (c *Client) enqueueLocked(msg *dto.Message) {
+	if c.closed {
+		return
+	}
+	if msg.Seq != 0 {
+		if msg.Seq <= c.lastSeq {
+			return
+		}
+		c.lastSeq = msg.Seq
+	}
+	select {
+	case c.queue <- msg:
+	default:
+		c.closeLocked(nil)
+	}
+}
+
+// close 断开连接，final不为空时先发送该事件
+func (c *Client) close(final *dto.Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closeLocked(final)
+}
+
+// closeLocked 标记关闭并通知发送协程
+func (c *Client) closeLocked(final *dto.Message) {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.final = final
+	close(c.done)
+}
+
+// writeLoop 发送协程，连接关闭时发送final事件后关闭底层连接，使读取协程退出
+func (c *Client) writeLoop() {
+	defer c.conn.Close()
+	for {
+		select {
+		case msg := <-c.queue:
+			if err := c.write(msg); err != nil {
+				c.close(nil)
+				return
+			}
+		case <-c.done:
+			c.mu.Lock()
+			final := c.final
+			c.mu.Unlock()
+			if final != nil {
+				_ = c.write(final)
+			}
+			return
+		}
+	}
+}
+
+// write 写入一帧
+func (c *Client) write(msg *dto.Message) error {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	return websocket.JSON.Send(c.conn, msg)
+}
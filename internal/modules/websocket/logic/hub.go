@@ -0,0 +1,493 @@
+package logic
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/net/websocket"
+
+	"exchange/internal/modules/websocket/dto"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/database"
+	appLogger "exchange/internal/pkg/logger"
+)
+
+// 客户端发送的帧类型
+const (
+	FramePing      = "ping"               // 心跳，服务端回复pong
+	FrameAck       = "ack"                // 确认收到单聊消息，data为AckRequest
+	FrameTyping    = "typing"             // 正在输入，data为TypingRequest
+	FrameSubscribe = "presence_subscribe" // 订阅在线状态变化，data为PresenceSubscribeRequest
+)
+
+// 服务端发送的控制事件类型（不分配序号，不写入补发缓冲区）
+const (
+	EventConnected = "connected" // 补发完成，data包含last_seq和gap
+	EventPong      = "pong"      // 心跳回复
+	EventReconnect = "reconnect" // 实例即将关闭，客户端应携带resume_from重连（会连接到其他实例）
+	EventError     = "error"     // 帧无法处理
+	EventTyping    = "typing"    // 对方正在输入，data包含user_id、peer_id或group_id、typing和expires_in(秒)
+	EventPresence  = "presence"  // 在线状态，data.users为PresenceStatus列表（订阅时返回当前状态，之后在订阅的用户上线或下线时推送）
+)
+
+// 写入单帧的超时
+const writeTimeout = 10 * time.Second
+
+// ErrHubDraining 实例正在关闭，不再接受新连接
+var ErrHubDraining = errors.New("websocket hub is draining")
+
+// AckHandler 处理客户端确认收到的单聊消息（标记已投递并通知发送者）
+type AckHandler func(ctx context.Context, userID uint, messageIDs []string)
+
+// TypingHandler 校验并转发正在输入事件，返回错误时客户端收到error事件
+type TypingHandler func(ctx context.Context, userID uint, req dto.TypingRequest) error
+
+// Hub 本实例的WebSocket连接管理
+// 实例启动时生成唯一ID并订阅自己的事件频道，Broker按在线状态把事件发布到接收者所在实例，
+// Hub收到后推送给该用户在本实例的所有连接；关闭时先通知客户端重连，再清除在线状态
+type Hub struct {
+	instanceID string
+	redis      *database.RedisService
+	presence   Presence
+	replay     ReplayBuffer
+	config     config.WebSocketConfig
+	onAck      AckHandler
+	onTyping   TypingHandler
+
+	mu       sync.RWMutex
+	clients  map[uint]map[*Client]struct{}
+	watchers map[uint]map[*Client]struct{} // 订阅了用户在线状态的本地连接
+	draining bool
+	conns    sync.WaitGroup
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewHub 创建连接管理
+func NewHub(redis *database.RedisService, presence Presence, replay ReplayBuffer, cfg config.WebSocketConfig) *Hub {
+	return &Hub{
+		instanceID: newInstanceID(),
+		redis:      redis,
+		presence:   presence,
+		replay:     replay,
+		config:     cfg,
+		clients:    make(map[uint]map[*Client]struct{}),
+		watchers:   make(map[uint]map[*Client]struct{}),
+	}
+}
+
+// SetAckHandler 设置客户端确认收到消息时的处理
+func (h *Hub) SetAckHandler(onAck AckHandler) {
+	h.onAck = onAck
+}
+
+// SetTypingHandler 设置客户端发送正在输入事件时的处理
+func (h *Hub) SetTypingHandler(onTyping TypingHandler) {
+	h.onTyping = onTyping
+}
+
+// InstanceID 本实例的ID
+func (h *Hub) InstanceID() string {
+	return h.instanceID
+}
+
+// Start 订阅本实例的事件频道和在线状态变化频道，并定期刷新在线状态
+func (h *Hub) Start(ctx context.Context) {
+	ctx, h.cancel = context.WithCancel(ctx)
+	h.done = make(chan struct{})
+
+	pubsub := h.redis.Client().Subscribe(ctx, instanceChannel(h.instanceID), presenceChannel)
+	go h.listen(ctx, pubsub)
+}
+
+// listen 分发收到的事件和在线状态变化，定期刷新本地用户的在线状态
+func (h *Hub) listen(ctx context.Context, pubsub *redis.PubSub) {
+	defer close(h.done)
+	defer pubsub.Close()
+
+	ticker := time.NewTicker(time.Duration(h.config.PresenceTTL) * time.Second / 3)
+	defer ticker.Stop()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			if msg.Channel == presenceChannel {
+				if userID, err := strconv.ParseUint(msg.Payload, 10, 64); err == nil {
+					h.notifyWatchers(ctx, uint(userID))
+				}
+				continue
+			}
+			var event envelope
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil || event.Message == nil {
+				appLogger.Warn("WebSocket事件格式错误", map[string]interface{}{"instance": h.instanceID})
+				continue
+			}
+			h.deliver(event.UserID, event.Message)
+		case <-ticker.C:
+			if err := h.presence.Refresh(ctx, h.instanceID, h.userIDs()); err != nil && ctx.Err() == nil {
+				appLogger.Warn("刷新在线状态失败", map[string]interface{}{
+					"instance": h.instanceID,
+					"error":    err.Error(),
+				})
+			}
+		}
+	}
+}
+
+// Serve 处理一个已完成握手的连接，连接断开后返回
+// 先注册连接再补发resume_from之后的事件，补发期间收到的实时事件在补发完成后按序号去重发送，不会丢失或重复
+func (h *Hub) Serve(ctx context.Context, userID uint, resumeFrom uint64, conn *websocket.Conn) error {
+	client := newClient(userID, conn, h.config.SendBufferSize)
+	if err := h.register(ctx, client); err != nil {
+		conn.Close()
+		return err
+	}
+	defer h.unregister(client)
+
+	replay, err := h.replay.Resume(ctx, userID, resumeFrom)
+	if err != nil {
+		client.close(nil)
+		conn.Close()
+		return fmt.Errorf("failed to resume events: %w", err)
+	}
+	if err := client.replay(replay.Events); err != nil {
+		client.close(nil)
+		conn.Close()
+		return nil
+	}
+	client.start(&dto.Message{
+		Type:      EventConnected,
+		Data:      map[string]interface{}{"last_seq": replay.LastSeq, "gap": replay.Gap},
+		Timestamp: clock.Now(),
+	})
+
+	h.readLoop(ctx, client)
+	return nil
+}
+
+// readLoop 读取客户端的帧，超过heartbeat_timeout未收到任何帧时断开
+func (h *Hub) readLoop(ctx context.Context, client *Client) {
+	defer client.close(nil)
+
+	client.conn.MaxPayloadBytes = h.config.MaxMessageBytes
+	timeout := time.Duration(h.config.HeartbeatTimeout) * time.Second
+	for {
+		if err := client.conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+			return
+		}
+		var frame dto.Message
+		if err := websocket.JSON.Receive(client.conn, &frame); err != nil {
+			return
+		}
+
+		switch frame.Type {
+		case FramePing:
+			client.send(&dto.Message{Type: EventPong, Timestamp: clock.Now()})
+		case FrameAck:
+			h.handleAck(ctx, client, frame.Data)
+		case FrameTyping:
+			h.handleTyping(ctx, client, frame.Data)
+		case FrameSubscribe:
+			h.handleSubscribe(ctx, client, frame.Data)
+		default:
+			client.sendError("unsupported frame type")
+		}
+	}
+}
+
+// handleAck 处理确认帧
+func (h *Hub) handleAck(ctx context.Context, client *Client, data map[string]interface{}) {
+	var req dto.AckRequest
+	if err := decodeFrame(data, &req); err != nil || len(req.MessageIDs) == 0 || len(req.MessageIDs) > 100 {
+		client.sendError("invalid ack")
+		return
+	}
+	if h.onAck != nil {
+		h.onAck(ctx, client.userID, req.MessageIDs)
+	}
+}
+
+// handleTyping 处理正在输入帧，同一会话每三分之一typing_timeout最多转发一次开始输入
+func (h *Hub) handleTyping(ctx context.Context, client *Client, data map[string]interface{}) {
+	var req dto.TypingRequest
+	if err := decodeFrame(data, &req); err != nil || (req.PeerID == 0) == (req.GroupID == "") || req.PeerID == client.userID {
+		client.sendError("invalid typing")
+		return
+	}
+
+	interval := time.Duration(h.config.TypingTimeout) * time.Second / 3
+	if !client.allowTyping(req, interval) || h.onTyping == nil {
+		return
+	}
+	if err := h.onTyping(ctx, client.userID, req); err != nil {
+		client.sendError(err.Error())
+	}
+}
+
+// handleSubscribe 替换连接订阅的用户，并返回这些用户当前的在线状态
+func (h *Hub) handleSubscribe(ctx context.Context, client *Client, data map[string]interface{}) {
+	var req dto.PresenceSubscribeRequest
+	if err := decodeFrame(data, &req); err != nil || len(req.UserIDs) > h.config.MaxSubscriptions {
+		client.sendError("invalid presence subscription")
+		return
+	}
+
+	h.mu.Lock()
+	h.unwatchLocked(client)
+	watching := make([]uint, 0, len(req.UserIDs))
+	for _, userID := range req.UserIDs {
+		if userID == 0 {
+			continue
+		}
+		if h.watchers[userID] == nil {
+			h.watchers[userID] = make(map[*Client]struct{})
+		}
+		if _, ok := h.watchers[userID][client]; !ok {
+			h.watchers[userID][client] = struct{}{}
+			watching = append(watching, userID)
+		}
+	}
+	client.watching = watching
+	h.mu.Unlock()
+
+	if len(watching) == 0 {
+		return
+	}
+	statuses, err := h.presence.Statuses(ctx, watching)
+	if err != nil {
+		client.sendError("presence unavailable")
+		return
+	}
+	client.send(presenceEvent(watching, statuses))
+}
+
+// notifyWatchers 用户上线或下线后，向本实例订阅了该用户的连接推送最新状态
+func (h *Hub) notifyWatchers(ctx context.Context, userID uint) {
+	h.mu.RLock()
+	watched := len(h.watchers[userID]) > 0
+	h.mu.RUnlock()
+	if !watched {
+		return
+	}
+
+	statuses, err := h.presence.Statuses(ctx, []uint{userID})
+	if err != nil {
+		appLogger.Warn("查询在线状态失败", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		return
+	}
+	event := presenceEvent([]uint{userID}, statuses)
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.watchers[userID] {
+		client.send(event)
+	}
+}
+
+// publishPresence 通知所有实例用户上线或下线
+func (h *Hub) publishPresence(ctx context.Context, userID uint) {
+	if err := h.redis.Client().Publish(ctx, presenceChannel, strconv.FormatUint(uint64(userID), 10)).Err(); err != nil {
+		appLogger.Warn("发布在线状态变化失败", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// unwatchLocked 取消连接的所有在线状态订阅
+func (h *Hub) unwatchLocked(client *Client) {
+	for _, userID := range client.watching {
+		delete(h.watchers[userID], client)
+		if len(h.watchers[userID]) == 0 {
+			delete(h.watchers, userID)
+		}
+	}
+	client.watching = nil
+}
+
+// register 注册连接，用户在本实例的第一个连接写入在线状态
+func (h *Hub) register(ctx context.Context, client *Client) error {
+	h.mu.Lock()
+	if h.draining {
+		h.mu.Unlock()
+		return ErrHubDraining
+	}
+	first := len(h.clients[client.userID]) == 0
+	if first {
+		h.clients[client.userID] = make(map[*Client]struct{})
+	}
+	h.clients[client.userID][client] = struct{}{}
+	h.conns.Add(1)
+	h.mu.Unlock()
+
+	if first {
+		if err := h.presence.Add(ctx, client.userID, h.instanceID); err != nil {
+			appLogger.Warn("写入在线状态失败", map[string]interface{}{
+				"user_id": client.userID,
+				"error":   err.Error(),
+			})
+		}
+		h.publishPresence(ctx, client.userID)
+	}
+	return nil
+}
+
+// unregister 注销连接，用户在本实例的最后一个连接断开时删除在线状态
+func (h *Hub) unregister(client *Client) {
+	h.mu.Lock()
+	h.unwatchLocked(client)
+	delete(h.clients[client.userID], client)
+	last := len(h.clients[client.userID]) == 0
+	if last {
+		delete(h.clients, client.userID)
+	}
+	draining := h.draining
+	h.mu.Unlock()
+	defer h.conns.Done()
+
+	// 关闭时由Drain统一清除在线状态
+	if last && !draining {
+		if err := h.presence.Remove(context.Background(), client.userID, h.instanceID); err != nil {
+			appLogger.Warn("删除在线状态失败", map[string]interface{}{
+				"user_id": client.userID,
+				"error":   err.Error(),
+			})
+		}
+		h.publishPresence(context.Background(), client.userID)
+	}
+}
+
+// deliver 推送给用户在本实例的所有连接
+func (h *Hub) deliver(userID uint, msg *dto.Message) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.clients[userID] {
+		client.send(msg)
+	}
+}
+
+// userIDs 本实例有连接的用户
+func (h *Hub) userIDs() []uint {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	ids := make([]uint, 0, len(h.clients))
+	for userID := range h.clients {
+		ids = append(ids, userID)
+	}
+	return ids
+}
+
+// Stats 本实例的连接数和用户数
+func (h *Hub) Stats() (connections, users int) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, clients := range h.clients {
+		connections += len(clients)
+	}
+	return connections, len(h.clients)
+}
+
+// Draining 是否正在关闭
+func (h *Hub) Draining() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.draining
+}
+
+// Drain 关闭前断开所有连接
+// 不再接受新连接，通知客户端重连（负载均衡会把新连接分配到其他实例，客户端携带resume_from补发错过的事件），
+// 等待连接断开或ctx超时，然后清除本实例的在线状态并停止订阅
+func (h *Hub) Drain(ctx context.Context) error {
+	h.mu.Lock()
+	h.draining = true
+	userIDs := make([]uint, 0, len(h.clients))
+	for userID, clients := range h.clients {
+		userIDs = append(userIDs, userID)
+		for client := range clients {
+			client.close(&dto.Message{Type: EventReconnect, Timestamp: clock.Now()})
+		}
+	}
+	h.mu.Unlock()
+
+	waited := make(chan struct{})
+	go func() {
+		h.conns.Wait()
+		close(waited)
+	}()
+
+	var err error
+	select {
+	case <-waited:
+	case <-ctx.Done():
+		err = fmt.Errorf("websocket connections not closed before timeout: %w", ctx.Err())
+	}
+
+	cleanup, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, userID := range userIDs {
+		if removeErr := h.presence.Remove(cleanup, userID, h.instanceID); removeErr != nil {
+			appLogger.Warn("删除在线状态失败", map[string]interface{}{
+				"user_id": userID,
+				"error":   removeErr.Error(),
+			})
+		}
+		h.publishPresence(cleanup, userID)
+	}
+
+	if h.cancel != nil {
+		h.cancel()
+		<-h.done
+	}
+	return err
+}
+
+// presenceEvent 在线状态事件
+func presenceEvent(userIDs []uint, statuses map[uint]Status) *dto.Message {
+	users := make([]dto.PresenceStatus, 0, len(userIDs))
+	for _, userID := range userIDs {
+		status := statuses[userID]
+		users = append(users, dto.PresenceStatus{UserID: userID, Online: status.Online, LastSeenAt: status.LastSeenAt})
+	}
+	return &dto.Message{
+		Type:      EventPresence,
+		Data:      map[string]interface{}{"users": users},
+		Timestamp: clock.Now(),
+	}
+}
+
+// decodeFrame 将帧的data解析为请求结构
+func decodeFrame(data map[string]interface{}, v interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// newInstanceID 生成实例ID：主机名-进程ID-随机串，重启后ID变化，旧实例的在线状态随过期时间失效
+func newInstanceID() string {
+	host, _ := os.Hostname()
+	buf := make([]byte, 4)
+	rand.Read(buf)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(buf))
+}
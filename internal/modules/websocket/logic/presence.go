@@ -0,0 +1,159 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/database"
+)
+
+// Presence 跨实例同步的在线状态
+// 每个用户一个哈希，field为用户有连接的实例ID，value为该记录的过期时间(Unix秒)；
+// 实例定期刷新本地连接的用户，异常退出的实例的记录在presence_ttl之后视为离线；
+// 写入和刷新时同时更新用户的最后在线时间，保留last_seen_days天
+type Presence interface {
+	// Add 记录用户在实例上有连接
+	Add(ctx context.Context, userID uint, instanceID string) error
+
+	// Remove 用户在实例上的最后一个连接断开
+	Remove(ctx context.Context, userID uint, instanceID string) error
+
+	// Refresh 刷新实例上所有在线用户的过期时间
+	Refresh(ctx context.Context, instanceID string, userIDs []uint) error
+
+	// Instances 用户有连接的实例
+	Instances(ctx context.Context, userID uint) ([]string, error)
+
+	// Statuses 批量查询用户是否在线（在任一实例上有连接）和最后在线时间
+	Statuses(ctx context.Context, userIDs []uint) (map[uint]Status, error)
+}
+
+// Status 用户的在线状态
+type Status struct {
+	Online     bool
+	LastSeenAt *time.Time // 在线时为最近一次刷新的时间，没有记录或已超过保留天数时为空
+}
+
+// RedisPresence 基于Redis哈希的在线状态
+type RedisPresence struct {
+	redis       *database.RedisService
+	ttl         time.Duration
+	lastSeenTTL time.Duration
+}
+
+// NewRedisPresence 创建Redis在线状态
+func NewRedisPresence(redis *database.RedisService, cfg config.WebSocketConfig) *RedisPresence {
+	return &RedisPresence{
+		redis:       redis,
+		ttl:         time.Duration(cfg.PresenceTTL) * time.Second,
+		lastSeenTTL: time.Duration(cfg.LastSeenDays) * 24 * time.Hour,
+	}
+}
+
+// Add 记录用户在实例上有连接
+func (p *RedisPresence) Add(ctx context.Context, userID uint, instanceID string) error {
+	return p.Refresh(ctx, instanceID, []uint{userID})
+}
+
+// Remove 删除用户在实例上的记录，并记录最后在线时间
+func (p *RedisPresence) Remove(ctx context.Context, userID uint, instanceID string) error {
+	now := strconv.FormatInt(clock.Now().Unix(), 10)
+	_, err := p.redis.Client().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, p.key(userID), instanceID)
+		pipe.Set(ctx, p.lastSeenKey(userID), now, p.lastSeenTTL)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove presence: %w", err)
+	}
+	return nil
+}
+
+// Refresh 写入新的过期时间，并延长哈希本身的过期时间
+func (p *RedisPresence) Refresh(ctx context.Context, instanceID string, userIDs []uint) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	now := clock.Now()
+	expiresAt := strconv.FormatInt(now.Add(p.ttl).Unix(), 10)
+	lastSeen := strconv.FormatInt(now.Unix(), 10)
+	_, err := p.redis.Client().Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, userID := range userIDs {
+			pipe.HSet(ctx, p.key(userID), instanceID, expiresAt)
+			pipe.Expire(ctx, p.key(userID), p.ttl)
+			pipe.Set(ctx, p.lastSeenKey(userID), lastSeen, p.lastSeenTTL)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refresh presence: %w", err)
+	}
+	return nil
+}
+
+// Instances 用户有连接且记录未过期的实例
+func (p *RedisPresence) Instances(ctx context.Context, userID uint) ([]string, error) {
+	entries, err := p.redis.Client().HGetAll(ctx, p.key(userID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get presence: %w", err)
+	}
+	return p.alive(entries), nil
+}
+
+// Statuses 批量查询用户的在线状态和最后在线时间
+func (p *RedisPresence) Statuses(ctx context.Context, userIDs []uint) (map[uint]Status, error) {
+	instances := make([]*redis.MapStringStringCmd, len(userIDs))
+	lastSeen := make([]*redis.StringCmd, len(userIDs))
+	_, err := p.redis.Client().Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, userID := range userIDs {
+			instances[i] = pipe.HGetAll(ctx, p.key(userID))
+			lastSeen[i] = pipe.Get(ctx, p.lastSeenKey(userID))
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get presence: %w", err)
+	}
+
+	statuses := make(map[uint]Status, len(userIDs))
+	for i, userID := range userIDs {
+		status := Status{Online: len(p.alive(instances[i].Val())) > 0}
+		if seconds, err := lastSeen[i].Int64(); err == nil {
+			at := time.Unix(seconds, 0).UTC()
+			status.LastSeenAt = &at
+		}
+		statuses[userID] = status
+	}
+	return statuses, nil
+}
+
+// alive 过滤掉已过期的实例记录
+func (p *RedisPresence) alive(entries map[string]string) []string {
+	now := clock.Now().Unix()
+	instances := make([]string, 0, len(entries))
+	for instanceID, value := range entries {
+		expiresAt, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || expiresAt < now {
+			continue
+		}
+		instances = append(instances, instanceID)
+	}
+	return instances
+}
+
+// key 用户在线状态键
+func (p *RedisPresence) key(userID uint) string {
+	return fmt.Sprintf("ws_presence:{%d}", userID)
+}
+
+// lastSeenKey 用户最后在线时间键
+func (p *RedisPresence) lastSeenKey(userID uint) string {
+	return fmt.Sprintf("ws_last_seen:{%d}", userID)
+}
@@ -0,0 +1,136 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"exchange/internal/modules/websocket/dto"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/database"
+)
+
+// 序号计数器保留时间，远长于缓冲区保留时间，避免短时间内序号被重置
+const replaySeqTTL = 24 * time.Hour
+
+// ReplayBuffer 断线重连补发缓冲区接口
+// 每次向用户推送事件前调用Push分配序号并写入缓冲区，客户端重连时携带resume_from调用Resume获取错过的事件
+type ReplayBuffer interface {
+	// Push 为事件分配序号（写入msg.Seq）并写入用户的缓冲区
+	Push(ctx context.Context, userID uint, msg *dto.Message) (uint64, error)
+
+	// Resume 获取序号大于resumeFrom的事件
+	Resume(ctx context.Context, userID uint, resumeFrom uint64) (*dto.ReplayResponse, error)
+}
+
+// RedisReplayBuffer 基于Redis有序集合的补发缓冲区
+// 每个用户一个有序集合（score为序号），只保留最近size条，超过ttl未推送时整体过期
+type RedisReplayBuffer struct {
+	redis *database.RedisService
+	size  int
+	ttl   time.Duration
+}
+
+// NewRedisReplayBuffer 创建Redis补发缓冲区
+func NewRedisReplayBuffer(redis *database.RedisService, cfg config.WebSocketConfig) *RedisReplayBuffer {
+	return &RedisReplayBuffer{
+		redis: redis,
+		size:  cfg.ReplayBufferSize,
+		ttl:   time.Duration(cfg.ReplayTTL) * time.Second,
+	}
+}
+
+// Push 为事件分配序号并写入用户的缓冲区
+func (b *RedisReplayBuffer) Push(ctx context.Context, userID uint, msg *dto.Message) (uint64, error) {
+	client := b.redis.Client()
+
+	seq, err := client.Incr(ctx, b.seqKey(userID)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate event seq: %w", err)
+	}
+	msg.Seq = uint64(seq)
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	// 写入后裁剪到最近size条，并刷新过期时间
+	key := b.bufferKey(userID)
+	_, err = client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, key, redis.Z{Score: float64(seq), Member: data})
+		pipe.ZRemRangeByRank(ctx, key, 0, int64(-b.size-1))
+		pipe.Expire(ctx, key, b.ttl)
+		pipe.Expire(ctx, b.seqKey(userID), replaySeqTTL)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to write replay buffer: %w", err)
+	}
+
+	return msg.Seq, nil
+}
+
+// Resume 获取序号大于resumeFrom的事件
+// 以下情况返回Gap=true，客户端需要全量同步：
+// 1. resumeFrom大于当前序号（计数器已过期重置）
+// 2. 缓冲区中最早的事件序号大于resumeFrom+1（中间事件已被淘汰或过期）
+func (b *RedisReplayBuffer) Resume(ctx context.Context, userID uint, resumeFrom uint64) (*dto.ReplayResponse, error) {
+	client := b.redis.Client()
+
+	lastSeq, err := client.Get(ctx, b.seqKey(userID)).Uint64()
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get event seq: %w", err)
+	}
+
+	response := &dto.ReplayResponse{
+		Events:  []dto.Message{},
+		LastSeq: lastSeq,
+	}
+
+	// 新连接或没有错过事件
+	if resumeFrom == 0 || resumeFrom == lastSeq {
+		return response, nil
+	}
+	if resumeFrom > lastSeq {
+		response.Gap = true
+		return response, nil
+	}
+
+	members, err := client.ZRangeByScore(ctx, b.bufferKey(userID), &redis.ZRangeBy{
+		Min: "(" + strconv.FormatUint(resumeFrom, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replay buffer: %w", err)
+	}
+
+	for _, member := range members {
+		var msg dto.Message
+		if err := json.Unmarshal([]byte(member), &msg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+		}
+		response.Events = append(response.Events, msg)
+	}
+
+	if len(response.Events) == 0 || response.Events[0].Seq > resumeFrom+1 {
+		response.Gap = true
+	}
+
+	return response, nil
+}
+
+// bufferKey 用户补发缓冲区键
+// 缓冲区和序号计数器在同一事务中写入，用户ID作为哈希标签，集群模式下两个键在同一槽位
+func (b *RedisReplayBuffer) bufferKey(userID uint) string {
+	return fmt.Sprintf("ws_replay:{%d}", userID)
+}
+
+// seqKey 用户事件序号计数器键
+func (b *RedisReplayBuffer) seqKey(userID uint) string {
+	return fmt.Sprintf("ws_seq:{%d}", userID)
+}
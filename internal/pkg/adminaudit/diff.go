@@ -0,0 +1,53 @@
+package adminaudit
+
+import (
+	"encoding/json"
+	"reflect"
+
+	mongoModel "exchange/internal/models/mongodb"
+)
+
+// Snapshot 将数据转换为按JSON字段名索引的map，与接口返回的字段一致（json:"-"的字段如密码哈希不会记录）
+// 不是JSON对象的数据记录在value字段中，nil返回nil
+func Snapshot(v interface{}) map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(data, &snapshot); err == nil {
+		return snapshot
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+	return map[string]interface{}{"value": value}
+}
+
+// Diff 比较变更前后的数据，返回值不同的字段
+func Diff(before, after map[string]interface{}) map[string]mongoModel.AdminAuditChange {
+	changes := make(map[string]mongoModel.AdminAuditChange)
+	for field, old := range before {
+		if value, ok := after[field]; !ok || !reflect.DeepEqual(old, value) {
+			changes[field] = mongoModel.AdminAuditChange{Before: old, After: value}
+		}
+	}
+	for field, value := range after {
+		if _, ok := before[field]; !ok {
+			changes[field] = mongoModel.AdminAuditChange{After: value}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
@@ -0,0 +1,155 @@
+// Package adminaudit 管理员操作审计
+// 管理后台的写操作（用户修改、删除、配置变更、任务控制等）记录到MongoDB的admin_audit_logs集合，
+// 每条记录包含操作者、操作对象、变更前后的数据和差异、客户端IP和请求ID，供管理后台按条件分页查询
+package adminaudit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongoModel "exchange/internal/models/mongodb"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/database"
+)
+
+// ErrNotFound 审计记录不存在
+var ErrNotFound = errors.New("admin audit log not found")
+
+// Filter 审计记录查询条件，零值字段不参与过滤
+type Filter struct {
+	ActorID    uint
+	Action     string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	Success    *bool
+	From       time.Time // 包含
+	To         time.Time // 不包含
+}
+
+// Store 管理员操作审计记录存储
+type Store struct {
+	mongo *database.MongoDBService
+}
+
+// NewStore 创建管理员操作审计记录存储
+func NewStore(mongo *database.MongoDBService) *Store {
+	return &Store{mongo: mongo}
+}
+
+// collection 管理员操作审计集合
+func (s *Store) collection() *mongo.Collection {
+	return s.mongo.Collection(mongoModel.AdminAuditLog{}.CollectionName())
+}
+
+// EnsureIndexes 创建按时间、操作者、操作对象和请求ID查询的索引
+func (s *Store) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "request_id", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create admin audit log indexes: %w", err)
+	}
+	return nil
+}
+
+// Append 写入审计记录，未设置时间时使用当前时间
+func (s *Store) Append(ctx context.Context, log *mongoModel.AdminAuditLog) error {
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = clock.Now().UTC()
+	}
+	result, err := s.collection().InsertOne(ctx, log)
+	if err != nil {
+		return fmt.Errorf("failed to insert admin audit log: %w", err)
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		log.ID = id
+	}
+	return nil
+}
+
+// Query 按条件分页查询审计记录，按时间倒序
+func (s *Store) Query(ctx context.Context, filter Filter, page, pageSize int64) ([]*mongoModel.AdminAuditLog, int64, error) {
+	query := filter.toBSON()
+
+	total, err := s.collection().CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count admin audit logs: %w", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip((page - 1) * pageSize).
+		SetLimit(pageSize)
+	cursor, err := s.collection().Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query admin audit logs: %w", err)
+	}
+	logs := make([]*mongoModel.AdminAuditLog, 0)
+	if err := cursor.All(ctx, &logs); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode admin audit logs: %w", err)
+	}
+	return logs, total, nil
+}
+
+// Get 按ID获取审计记录
+func (s *Store) Get(ctx context.Context, id string) (*mongoModel.AdminAuditLog, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	var log mongoModel.AdminAuditLog
+	if err := s.collection().FindOne(ctx, bson.M{"_id": objectID}).Decode(&log); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get admin audit log: %w", err)
+	}
+	return &log, nil
+}
+
+// toBSON 转换为MongoDB查询条件
+func (f Filter) toBSON() bson.M {
+	query := bson.M{}
+	if f.ActorID != 0 {
+		query["actor_id"] = f.ActorID
+	}
+	if f.Action != "" {
+		query["action"] = f.Action
+	}
+	if f.TargetType != "" {
+		query["target_type"] = f.TargetType
+	}
+	if f.TargetID != "" {
+		query["target_id"] = f.TargetID
+	}
+	if f.RequestID != "" {
+		query["request_id"] = f.RequestID
+	}
+	if f.Success != nil {
+		query["success"] = *f.Success
+	}
+
+	createdAt := bson.M{}
+	if !f.From.IsZero() {
+		createdAt["$gte"] = f.From.UTC()
+	}
+	if !f.To.IsZero() {
+		createdAt["$lt"] = f.To.UTC()
+	}
+	if len(createdAt) > 0 {
+		query["created_at"] = createdAt
+	}
+	return query
+}
@@ -0,0 +1,213 @@
+// Package apidocs 接口文档示例
+// 开发和测试环境运行接口测试时记录真实的请求/响应，按认证层级（public/user/admin/service）整理后作为文档示例，
+// 示例始终与处理器的实际行为一致
+package apidocs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redactedValue 敏感字段替换后的值
+const redactedValue = "***"
+
+// sensitiveFields 记录示例时脱敏的字段（不区分大小写，包含即匹配）
+var sensitiveFields = []string{"password", "token", "secret", "authorization", "api_key", "signature", "otp"}
+
+// Example 一次请求的示例
+type Example struct {
+	Method       string          `json:"method"`
+	Route        string          `json:"route"` // 路由模板，如 /api/v1/user/exports/chats/:id
+	Auth         string          `json:"auth"`  // 认证层级
+	Status       int             `json:"status"`
+	Code         int             `json:"code"` // 响应中的业务码
+	Query        string          `json:"query,omitempty"`
+	RequestBody  json.RawMessage `json:"request_body,omitempty"`
+	ResponseBody json.RawMessage `json:"response_body,omitempty"`
+	CapturedAt   time.Time       `json:"captured_at"`
+}
+
+// key 示例的唯一键，同一路由的每种状态码和业务码保留最近一次
+func (e *Example) key() string {
+	return fmt.Sprintf("%s %s %d %d", e.Method, e.Route, e.Status, e.Code)
+}
+
+// Document 按认证层级分组的示例文档
+type Document struct {
+	GeneratedAt time.Time            `json:"generated_at"`
+	Tiers       map[string][]Example `json:"tiers"`
+}
+
+// Recorder 示例记录器，每次记录后写入示例文件，重启后从文件恢复
+type Recorder struct {
+	mu       sync.RWMutex
+	file     string
+	examples map[string]Example
+}
+
+// NewRecorder 创建示例记录器，示例文件已存在时加载其中的示例
+func NewRecorder(file string) (*Recorder, error) {
+	r := &Recorder{
+		file:     file,
+		examples: make(map[string]Example),
+	}
+
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read api examples: %w", err)
+	}
+
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse api examples %s: %w", file, err)
+	}
+	for _, examples := range doc.Tiers {
+		for _, example := range examples {
+			r.examples[example.key()] = example
+		}
+	}
+	return r, nil
+}
+
+// Record 记录示例，请求和响应体中的敏感字段脱敏后保存
+func (r *Recorder) Record(example Example) error {
+	example.Query = redactQuery(example.Query)
+	example.RequestBody = redact(example.RequestBody)
+	example.ResponseBody = redact(example.ResponseBody)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.examples[example.key()] = example
+	return r.save()
+}
+
+// Document 生成示例文档，各层级内按路由、方法、状态码和业务码排序
+func (r *Recorder) Document() Document {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.document()
+}
+
+// document 生成示例文档，调用方需持有锁
+func (r *Recorder) document() Document {
+	doc := Document{
+		GeneratedAt: time.Now().UTC(),
+		Tiers:       make(map[string][]Example),
+	}
+	for _, example := range r.examples {
+		doc.Tiers[example.Auth] = append(doc.Tiers[example.Auth], example)
+	}
+	for _, examples := range doc.Tiers {
+		sort.Slice(examples, func(i, j int) bool {
+			a, b := examples[i], examples[j]
+			if a.Route != b.Route {
+				return a.Route < b.Route
+			}
+			if a.Method != b.Method {
+				return a.Method < b.Method
+			}
+			if a.Status != b.Status {
+				return a.Status < b.Status
+			}
+			return a.Code < b.Code
+		})
+	}
+	return doc
+}
+
+// save 写入示例文件（先写临时文件再替换），调用方需持有锁
+func (r *Recorder) save() error {
+	data, err := json.MarshalIndent(r.document(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.file), 0o755); err != nil {
+		return fmt.Errorf("failed to create api examples dir: %w", err)
+	}
+
+	tmp := r.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write api examples: %w", err)
+	}
+	return os.Rename(tmp, r.file)
+}
+
+// redact 脱敏JSON中的敏感字段，非JSON内容不记录
+func redact(body json.RawMessage) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil
+	}
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+// redactQuery 脱敏查询参数中的敏感字段（如下载链接的签名令牌）
+func redactQuery(query string) string {
+	if query == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return ""
+	}
+	for key := range values {
+		if isSensitive(key) {
+			values.Set(key, redactedValue)
+		}
+	}
+	return values.Encode()
+}
+
+// redactValue 递归替换敏感字段的值
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isSensitive(key) {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	case string:
+		// 带签名参数的链接（如导出文件下载地址）
+		if u, err := url.Parse(v); err == nil && u.RawQuery != "" && u.Scheme != "" {
+			u.RawQuery = redactQuery(u.RawQuery)
+			return u.String()
+		}
+	}
+	return value
+}
+
+// isSensitive 字段名是否为敏感字段
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, field := range sensitiveFields {
+		if strings.Contains(key, field) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,20 +1,35 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"time"
 
+	"exchange/internal/pkg/audit"
 	"exchange/internal/pkg/config"
+	appErrors "exchange/internal/pkg/errors"
+	"exchange/internal/pkg/eventlog"
+	"exchange/internal/pkg/events"
+	"exchange/internal/pkg/i18n"
 	"exchange/internal/pkg/logger"
+	"exchange/internal/pkg/loglevel"
 	"exchange/internal/pkg/modules"
+	"exchange/internal/pkg/notification"
 	"exchange/internal/pkg/server"
 	"exchange/internal/pkg/services"
+	"exchange/internal/pkg/tracing"
+	"exchange/internal/pkg/translation"
 )
 
 // Application 应用程序结构
 type Application struct {
-	config        *config.Config
-	server        *server.GinServer
-	moduleManager *modules.ModuleManager
+	config          *config.Config
+	server          *server.GinServer
+	moduleManager   *modules.ModuleManager
+	logLevelSync    *loglevel.Sync    // 运行时日志级别同步
+	i18nWatcher     *i18n.Watcher     // 外部翻译目录监听，未启用时为nil
+	translationSync *translation.Sync // 管理后台编辑的翻译同步
+	auditPipeline   *audit.Pipeline   // 审计事件写入管道，未启用时为nil
 }
 
 // NewApplication 创建新的应用程序实例
@@ -32,10 +47,29 @@ func (app *Application) Initialize() error {
 
 	logger.Info("正在初始化应用...", nil)
 
+	if err := app.initializeErrorCatalog(); err != nil {
+		return fmt.Errorf("加载错误目录失败: %w", err)
+	}
+
+	if err := app.initializeI18n(); err != nil {
+		return fmt.Errorf("加载外部翻译失败: %w", err)
+	}
+
+	if err := app.initializeAudit(); err != nil {
+		return fmt.Errorf("初始化审计日志失败: %w", err)
+	}
+
+	if err := app.initializeEventLog(); err != nil {
+		return fmt.Errorf("初始化领域事件日志失败: %w", err)
+	}
+
 	if err := app.initializeModuleManager(); err != nil {
 		return fmt.Errorf("初始化模块管理器失败: %w", err)
 	}
 
+	app.initializeLogLevelSync()
+	app.initializeTranslationSync()
+
 	if err := app.initializeServer(); err != nil {
 		return fmt.Errorf("初始化服务器失败: %w", err)
 	}
@@ -49,9 +83,98 @@ func (app *Application) initializeLogger() error {
 	if err := logger.Init(&app.config.Log); err != nil {
 		return fmt.Errorf("初始化日志失败: %w", err)
 	}
+
+	// 错误上报到Sentry或通用错误收集接口
+	if err := appErrors.EnableReporting(app.config.ErrorTracking, "exchange"); err != nil {
+		return fmt.Errorf("初始化错误上报失败: %w", err)
+	}
+
+	// HTTP请求、数据库调用的span导出到OTLP Collector
+	if err := tracing.Enable(app.config.Tracing, "exchange"); err != nil {
+		return fmt.Errorf("初始化分布式追踪失败: %w", err)
+	}
+
+	// 用户通知异步发送
+	notification.EnableAsyncDelivery(app.config.Notification)
+	return nil
+}
+
+// initializeErrorCatalog 加载错误目录文件中的业务错误定义，消息键缺少翻译时记录警告
+func (app *Application) initializeErrorCatalog() error {
+	file := app.config.ErrorCatalog.File
+	if file == "" {
+		return nil
+	}
+
+	count, err := appErrors.LoadCatalog(file)
+	if err != nil {
+		return err
+	}
+
+	manager := i18n.GetGlobalI18n()
+	for _, def := range appErrors.DefaultCatalog().Definitions() {
+		if !manager.HasMessage(manager.GetDefaultLanguage(), def.MessageKey) {
+			logger.Warn("错误定义的消息键缺少翻译", map[string]interface{}{
+				"code":        def.Code,
+				"message_key": def.MessageKey,
+			})
+		}
+	}
+
+	logger.Info("错误目录加载完成", map[string]interface{}{
+		"file":  file,
+		"count": count,
+	})
+	return nil
+}
+
+// initializeI18n 设置语言回退链，加载外部目录中的翻译文件，启用热加载时监听目录变更
+func (app *Application) initializeI18n() error {
+	cfg := app.config.I18n
+	manager := i18n.GetGlobalI18n()
+	if err := manager.SetFallbacks(cfg.Fallbacks); err != nil {
+		return err
+	}
+	if cfg.LocalesDir == "" {
+		manager.LogMissingKeys()
+		return nil
+	}
+
+	if err := manager.SetLocalesDir(cfg.LocalesDir); err != nil {
+		return err
+	}
+	manager.LogMissingKeys()
+	if !cfg.HotReload {
+		return nil
+	}
+
+	watcher, err := manager.Watch()
+	if err != nil {
+		return err
+	}
+	app.i18nWatcher = watcher
+
+	logger.Info("外部翻译目录热加载已启用", map[string]interface{}{
+		"dir": cfg.LocalesDir,
+	})
 	return nil
 }
 
+// initializeAudit 启用审计日志持久化，logger.Audit记录的事件同时写入MongoDB哈希链
+func (app *Application) initializeAudit() error {
+	pipeline, err := audit.Enable(app.config.Audit, services.GetGlobalServices().GetMongoDB())
+	if err != nil {
+		return err
+	}
+	app.auditPipeline = pipeline
+	return nil
+}
+
+// initializeEventLog 启用领域事件日志，事件总线上的事件写入MongoDB供定时任务导出
+func (app *Application) initializeEventLog() error {
+	return eventlog.Enable(app.config.EventLog, services.GetGlobalServices().GetMongoDB(), events.DefaultBus())
+}
+
 // initializeModuleManager 初始化模块管理器
 func (app *Application) initializeModuleManager() error {
 	globalServices := services.GetGlobalServices()
@@ -72,6 +195,32 @@ func (app *Application) initializeModuleManager() error {
 	return app.moduleManager.Initialize()
 }
 
+// initializeLogLevelSync 加载管理后台设置的日志级别并订阅变更
+// 同步失败只影响运行时调整日志级别，不阻止应用启动
+func (app *Application) initializeLogLevelSync() {
+	app.logLevelSync = loglevel.NewSync(services.GetGlobalServices().GetRedis())
+	if err := app.logLevelSync.Start(context.Background()); err != nil {
+		logger.Warn("日志级别同步启动失败", map[string]interface{}{
+			"error": err.Error(),
+		})
+		app.logLevelSync = nil
+	}
+}
+
+// initializeTranslationSync 加载管理后台编辑的翻译并订阅变更
+// 同步失败时只使用翻译文件中的消息，不阻止应用启动
+func (app *Application) initializeTranslationSync() {
+	globalServices := services.GetGlobalServices()
+	store := translation.NewStore(globalServices.GetMongoDB())
+	app.translationSync = translation.NewSync(store, globalServices.GetRedis(), i18n.GetGlobalI18n())
+	if err := app.translationSync.Start(context.Background()); err != nil {
+		logger.Warn("翻译覆盖同步启动失败", map[string]interface{}{
+			"error": err.Error(),
+		})
+		app.translationSync = nil
+	}
+}
+
 // initializeServer 初始化服务器
 func (app *Application) initializeServer() error {
 	app.server = server.NewGinServer(app.config)
@@ -89,6 +238,43 @@ func (app *Application) Start() error {
 
 // Shutdown 关闭应用程序
 func (app *Application) Shutdown() error {
+	// 断开WebSocket连接并清除在线状态（需在关闭Redis之前，客户端重连到其他实例）
+	if app.moduleManager != nil {
+		app.moduleManager.DrainConnections(time.Duration(app.config.WebSocket.DrainTimeout) * time.Second)
+	}
+
+	// 停止日志级别同步
+	if app.logLevelSync != nil {
+		app.logLevelSync.Stop()
+	}
+
+	// 停止翻译覆盖同步
+	if app.translationSync != nil {
+		app.translationSync.Stop()
+	}
+
+	// 停止外部翻译目录监听
+	if app.i18nWatcher != nil {
+		app.i18nWatcher.Close()
+	}
+
+	// 等待正在执行的事件处理（如自动化消息）结束
+	events.DefaultBus().Wait()
+
+	// 发送队列中的用户通知（事件处理可能产生新通知，需在其之后）
+	notification.CloseAsyncDelivery(10 * time.Second)
+
+	// 写完队列中的审计事件（需在关闭日志系统和数据库连接之前）
+	if app.auditPipeline != nil {
+		app.auditPipeline.Close()
+	}
+
+	// 发送队列中的错误事件
+	appErrors.CloseReporting(5 * time.Second)
+
+	// 导出缓冲中的span
+	tracing.Shutdown(5 * time.Second)
+
 	// 关闭日志系统
 	if err := logger.Close(); err != nil {
 		logger.Error("关闭日志系统失败", map[string]interface{}{
@@ -0,0 +1,122 @@
+// Package audit 审计日志持久化
+// logger.Audit记录的审计事件经Pipeline异步写入MongoDB audit_logs集合，记录按序号组成哈希链，
+// 每条记录保存上一条记录的哈希，Verify从头校验整条链，用于证明审计记录未被修改、删除或插入
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	mongoModel "exchange/internal/models/mongodb"
+	appLogger "exchange/internal/pkg/logger"
+)
+
+// GenesisHash 第一条记录的prev_hash
+var GenesisHash = strings.Repeat("0", 64)
+
+// payload 参与哈希计算的记录内容，字段顺序固定，fields的键由encoding/json排序
+type payload struct {
+	Seq       int64                  `json:"seq"`
+	Service   string                 `json:"service"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields"`
+	CreatedAt string                 `json:"created_at"`
+	PrevHash  string                 `json:"prev_hash"`
+}
+
+// NewRecord 根据审计事件创建哈希链记录
+// 字段先经过JSON往返转换为基础类型，时间截断到毫秒，保证存入MongoDB再读出后内容不变
+func NewRecord(event *appLogger.AuditEvent, seq int64, prevHash string) (*mongoModel.AuditLog, error) {
+	fields, err := normalizeFields(event.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt := event.Time.UTC().Truncate(time.Millisecond)
+	data, err := json.Marshal(payload{
+		Seq:       seq,
+		Service:   event.Service,
+		Message:   event.Message,
+		Fields:    fields,
+		CreatedAt: createdAt.Format(time.RFC3339Nano),
+		PrevHash:  prevHash,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit payload: %w", err)
+	}
+
+	return &mongoModel.AuditLog{
+		Seq:       seq,
+		Service:   event.Service,
+		Message:   event.Message,
+		Fields:    fields,
+		CreatedAt: createdAt,
+		PrevHash:  prevHash,
+		Hash:      hashPayload(string(data)),
+		Payload:   string(data),
+	}, nil
+}
+
+// normalizeFields 将字段转换为JSON基础类型，空字段使用空map（nil在存储后会变为null）
+func normalizeFields(fields map[string]interface{}) (map[string]interface{}, error) {
+	normalized := make(map[string]interface{})
+	if len(fields) == 0 {
+		return normalized, nil
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit fields: %w", err)
+	}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, fmt.Errorf("failed to decode audit fields: %w", err)
+	}
+	return normalized, nil
+}
+
+// hashPayload 计算记录哈希
+func hashPayload(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkRecord 校验单条记录，expectedSeq和expectedPrev为按链推算出的序号和上一条哈希
+// 返回空字符串表示校验通过，否则返回失败原因
+func checkRecord(record *mongoModel.AuditLog, expectedSeq int64, expectedPrev string) string {
+	if record.Seq != expectedSeq {
+		return fmt.Sprintf("序号不连续，期望 %d", expectedSeq)
+	}
+	if record.PrevHash != expectedPrev {
+		return "prev_hash与上一条记录的哈希不一致"
+	}
+	if hashPayload(record.Payload) != record.Hash {
+		return "哈希不匹配，记录内容被修改"
+	}
+
+	var p payload
+	if err := json.Unmarshal([]byte(record.Payload), &p); err != nil {
+		return "payload无法解析"
+	}
+	if p.Seq != record.Seq || p.PrevHash != record.PrevHash || p.Service != record.Service || p.Message != record.Message {
+		return "记录字段与payload不一致"
+	}
+	if createdAt, err := time.Parse(time.RFC3339Nano, p.CreatedAt); err != nil || !createdAt.Equal(record.CreatedAt) {
+		return "created_at与payload不一致"
+	}
+
+	stored, err := json.Marshal(record.Fields)
+	if err != nil {
+		return "fields无法编码"
+	}
+	expected, _ := json.Marshal(p.Fields)
+	if !bytes.Equal(stored, expected) {
+		return "fields与payload不一致"
+	}
+
+	return ""
+}
@@ -0,0 +1,135 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/database"
+	appErrors "exchange/internal/pkg/errors"
+	appLogger "exchange/internal/pkg/logger"
+)
+
+const (
+	enqueueTimeout = time.Second      // 队列满时等待的最长时间
+	writeTimeout   = 5 * time.Second  // 单次写入超时
+	writeAttempts  = 3                // 写入最多尝试次数
+	closeTimeout   = 10 * time.Second // 关闭时等待队列写完的最长时间
+)
+
+var auditLogger = appLogger.Module("audit")
+
+// Pipeline 审计事件异步写入管道，实现logger.AuditSink
+// 写入失败的事件仍保留在日志文件中（logger.Audit先写日志），管道记录错误日志便于补录
+type Pipeline struct {
+	store *Store
+	queue chan *appLogger.AuditEvent
+	done  chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewPipeline 创建并启动写入管道
+func NewPipeline(store *Store, queueSize int) *Pipeline {
+	p := &Pipeline{
+		store: store,
+		queue: make(chan *appLogger.AuditEvent, queueSize),
+		done:  make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// WriteAudit 提交审计事件，队列满时最多等待enqueueTimeout
+func (p *Pipeline) WriteAudit(event *appLogger.AuditEvent) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		auditLogger.Error("审计管道已关闭，事件未写入审计存储", map[string]interface{}{
+			"audit_message": event.Message,
+		})
+		return
+	}
+
+	select {
+	case p.queue <- event:
+	case <-time.After(enqueueTimeout):
+		auditLogger.Error("审计事件队列已满，事件未写入审计存储", map[string]interface{}{
+			"audit_message": event.Message,
+			"queue_size":    cap(p.queue),
+		})
+	}
+}
+
+// run 按提交顺序写入事件
+func (p *Pipeline) run() {
+	defer close(p.done)
+	for event := range p.queue {
+		p.write(event)
+	}
+}
+
+// write 写入单个事件，MongoDB连接失败、超时等可重试的错误按指数退避重试
+func (p *Pipeline) write(event *appLogger.AuditEvent) {
+	policy := appErrors.DefaultRetryPolicy()
+	policy.MaxAttempts = writeAttempts
+	policy.InitialDelay = 200 * time.Millisecond
+
+	err := appErrors.Retry(context.Background(), policy, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, writeTimeout)
+		defer cancel()
+		_, err := p.store.Append(ctx, event)
+		return err
+	})
+	if err == nil {
+		return
+	}
+
+	auditLogger.Error("审计事件写入失败", map[string]interface{}{
+		"audit_message": event.Message,
+		"audit_time":    event.Time.Format(time.RFC3339Nano),
+		"error":         err.Error(),
+	})
+}
+
+// Close 取消logger.Audit的持久化目标，停止接收事件并等待队列中的事件写完
+func (p *Pipeline) Close() {
+	appLogger.SetAuditSink(nil)
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.queue)
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+	case <-time.After(closeTimeout):
+		auditLogger.Error("等待审计事件写入超时", map[string]interface{}{
+			"pending": len(p.queue),
+		})
+	}
+}
+
+// Enable 按配置创建审计存储和写入管道，并设置为logger.Audit的持久化目标；未启用时返回nil
+func Enable(cfg config.AuditConfig, mongo *database.MongoDBService) (*Pipeline, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	store := NewStore(mongo)
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	if err := store.EnsureIndexes(ctx); err != nil {
+		return nil, err
+	}
+
+	pipeline := NewPipeline(store, cfg.QueueSize)
+	appLogger.SetAuditSink(pipeline)
+	return pipeline, nil
+}
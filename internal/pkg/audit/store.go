@@ -0,0 +1,188 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongoModel "exchange/internal/models/mongodb"
+	"exchange/internal/pkg/database"
+	appLogger "exchange/internal/pkg/logger"
+)
+
+// maxAppendRetries 序号冲突（其他实例先写入了同一序号）时的最大重试次数
+const maxAppendRetries = 5
+
+// maxVerifyFailures 校验报告中最多记录的失败条数
+const maxVerifyFailures = 100
+
+// Store 审计日志哈希链存储
+// seq上的唯一索引保证多个实例并发写入时链不会分叉：序号冲突的实例重新读取链尾后重试
+type Store struct {
+	mongo *database.MongoDBService
+
+	mu       sync.Mutex
+	loaded   bool
+	headSeq  int64
+	headHash string
+}
+
+// NewStore 创建审计日志存储
+func NewStore(mongo *database.MongoDBService) *Store {
+	return &Store{mongo: mongo}
+}
+
+// collection 审计日志集合
+func (s *Store) collection() *mongo.Collection {
+	return s.mongo.Collection(mongoModel.AuditLog{}.CollectionName())
+}
+
+// EnsureIndexes 创建序号唯一索引和时间索引
+func (s *Store) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "seq", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create audit log indexes: %w", err)
+	}
+	return nil
+}
+
+// Append 追加审计事件到链尾
+func (s *Store) Append(ctx context.Context, event *appLogger.AuditEvent) (*mongoModel.AuditLog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for attempt := 0; attempt < maxAppendRetries; attempt++ {
+		if !s.loaded {
+			if err := s.loadHead(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		record, err := NewRecord(event, s.headSeq+1, s.headHash)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := s.collection().InsertOne(ctx, record); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				s.loaded = false
+				continue
+			}
+			return nil, fmt.Errorf("failed to insert audit log: %w", err)
+		}
+
+		s.headSeq, s.headHash = record.Seq, record.Hash
+		return record, nil
+	}
+
+	return nil, fmt.Errorf("failed to append audit log: sequence conflict after %d attempts", maxAppendRetries)
+}
+
+// loadHead 读取链尾的序号和哈希，空链时从创世哈希开始
+func (s *Store) loadHead(ctx context.Context) error {
+	var head mongoModel.AuditLog
+	opts := options.FindOne().SetSort(bson.D{{Key: "seq", Value: -1}})
+	err := s.collection().FindOne(ctx, bson.M{}, opts).Decode(&head)
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		s.headSeq, s.headHash = 0, GenesisHash
+	case err != nil:
+		return fmt.Errorf("failed to load audit chain head: %w", err)
+	default:
+		s.headSeq, s.headHash = head.Seq, head.Hash
+	}
+	s.loaded = true
+	return nil
+}
+
+// Get 按序号获取记录
+func (s *Store) Get(ctx context.Context, seq int64) (*mongoModel.AuditLog, error) {
+	var record mongoModel.AuditLog
+	if err := s.collection().FindOne(ctx, bson.M{"seq": seq}).Decode(&record); err != nil {
+		return nil, fmt.Errorf("failed to get audit log %d: %w", seq, err)
+	}
+	return &record, nil
+}
+
+// VerifyFailure 校验失败的记录
+type VerifyFailure struct {
+	Seq    int64  `json:"seq"`
+	Reason string `json:"reason"`
+}
+
+// VerifyReport 哈希链校验报告
+type VerifyReport struct {
+	FromSeq  int64           `json:"from_seq"`
+	Checked  int64           `json:"checked"`
+	LastSeq  int64           `json:"last_seq"`
+	HeadHash string          `json:"head_hash"`
+	Failures []VerifyFailure `json:"failures"`
+}
+
+// Valid 链是否完整
+func (r *VerifyReport) Valid() bool {
+	return len(r.Failures) == 0
+}
+
+// Verify 按序号顺序校验哈希链
+// fromSeq<=1时从创世记录开始；否则以第fromSeq条记录保存的prev_hash为起点，用于大集合的增量校验
+// 发现断链后以当前记录为新的起点继续校验，报告中列出每一处断点
+func (s *Store) Verify(ctx context.Context, fromSeq int64) (*VerifyReport, error) {
+	if fromSeq < 1 {
+		fromSeq = 1
+	}
+	report := &VerifyReport{FromSeq: fromSeq}
+
+	opts := options.Find().SetSort(bson.D{{Key: "seq", Value: 1}})
+	cursor, err := s.collection().Find(ctx, bson.M{"seq": bson.M{"$gte": fromSeq}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit logs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	expectedSeq := fromSeq
+	expectedPrev := ""
+	for cursor.Next(ctx) {
+		var record mongoModel.AuditLog
+		if err := cursor.Decode(&record); err != nil {
+			return nil, fmt.Errorf("failed to decode audit log: %w", err)
+		}
+
+		if report.Checked == 0 {
+			expectedPrev = GenesisHash
+			if fromSeq > 1 {
+				expectedPrev = record.PrevHash
+			}
+		}
+
+		if reason := checkRecord(&record, expectedSeq, expectedPrev); reason != "" {
+			report.addFailure(record.Seq, reason)
+		}
+
+		report.Checked++
+		report.LastSeq = record.Seq
+		report.HeadHash = record.Hash
+		expectedSeq = record.Seq + 1
+		expectedPrev = record.Hash
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate audit logs: %w", err)
+	}
+
+	return report, nil
+}
+
+// addFailure 记录失败，超过上限后不再记录
+func (r *VerifyReport) addFailure(seq int64, reason string) {
+	if len(r.Failures) < maxVerifyFailures {
+		r.Failures = append(r.Failures, VerifyFailure{Seq: seq, Reason: reason})
+	}
+}
@@ -0,0 +1,207 @@
+package automation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/events"
+	"exchange/internal/pkg/notification"
+	"exchange/internal/repository"
+)
+
+// NotificationEvent 自动化消息的通知事件名
+const NotificationEvent = "automation_message"
+
+// TemplateData 模板可用的数据，如 {{.Username}}、{{.Data.amount}}
+type TemplateData struct {
+	UserID     uint
+	Username   string
+	Email      string
+	Trigger    string
+	Language   string
+	Data       map[string]interface{}
+	OccurredAt time.Time
+}
+
+// Message 渲染后的消息
+type Message struct {
+	Language string `json:"language"`
+	Title    string `json:"title"`
+	Body     string `json:"body"`
+}
+
+// ValidateTemplate 检查模板语法
+func ValidateTemplate(tpl mysql.MessageTemplate) error {
+	if strings.TrimSpace(tpl.Title) == "" && strings.TrimSpace(tpl.Body) == "" {
+		return fmt.Errorf("title and body cannot both be empty")
+	}
+	if _, err := template.New("title").Parse(tpl.Title); err != nil {
+		return fmt.Errorf("invalid title template: %w", err)
+	}
+	if _, err := template.New("body").Parse(tpl.Body); err != nil {
+		return fmt.Errorf("invalid body template: %w", err)
+	}
+	return nil
+}
+
+// SelectTemplate 按语言选择模板
+// 匹配顺序：完整语言代码（zh-CN）→ 主语言（zh）→ 默认语言 → 按语言代码排序的第一个模板
+func SelectTemplate(templates map[string]mysql.MessageTemplate, lang, defaultLang string) (string, mysql.MessageTemplate, bool) {
+	candidates := []string{lang}
+	if base, _, found := strings.Cut(lang, "-"); found {
+		candidates = append(candidates, base)
+	}
+	candidates = append(candidates, defaultLang)
+
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if tpl, ok := templates[candidate]; ok {
+			return candidate, tpl, true
+		}
+	}
+
+	languages := make([]string, 0, len(templates))
+	for language := range templates {
+		languages = append(languages, language)
+	}
+	if len(languages) == 0 {
+		return "", mysql.MessageTemplate{}, false
+	}
+	sort.Strings(languages)
+	return languages[0], templates[languages[0]], true
+}
+
+// Render 渲染规则中指定语言的消息
+func Render(rule *mysql.AutomationRule, data TemplateData, defaultLang string) (*Message, error) {
+	templates, err := rule.GetTemplates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode templates: %w", err)
+	}
+
+	language, tpl, ok := SelectTemplate(templates, data.Language, defaultLang)
+	if !ok {
+		return nil, fmt.Errorf("rule %d has no template", rule.ID)
+	}
+
+	title, err := execute("title", tpl.Title, data)
+	if err != nil {
+		return nil, err
+	}
+	body, err := execute("body", tpl.Body, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Message{Language: language, Title: title, Body: body}, nil
+}
+
+// execute 执行单个模板，缺失的字段渲染为空
+func execute(name, text string, data TemplateData) (string, error) {
+	tpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// Engine 消息自动化执行引擎
+// 订阅事件总线，事件发生时按触发事件加载启用的规则，渲染用户语言的模板并通过通知框架发送
+type Engine struct {
+	ruleRepo        repository.AutomationRuleRepository
+	userRepo        repository.UserRepository
+	notifier        notification.Notifier
+	defaultLanguage string
+}
+
+// NewEngine 创建消息自动化执行引擎
+func NewEngine(ruleRepo repository.AutomationRuleRepository, userRepo repository.UserRepository, notifier notification.Notifier, defaultLanguage string) *Engine {
+	return &Engine{
+		ruleRepo:        ruleRepo,
+		userRepo:        userRepo,
+		notifier:        notifier,
+		defaultLanguage: defaultLanguage,
+	}
+}
+
+// Subscribe 订阅所有支持的触发事件
+func (e *Engine) Subscribe(bus *events.Bus) {
+	for _, trigger := range mysql.AutomationTriggers {
+		bus.Subscribe(string(trigger), e.Handle)
+	}
+}
+
+// Handle 处理事件，单条规则失败不影响其他规则
+func (e *Engine) Handle(ctx context.Context, event events.Event) error {
+	rules, err := e.ruleRepo.GetEnabledByTrigger(ctx, mysql.AutomationTrigger(event.Type))
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	user, err := e.userRepo.GetByID(ctx, event.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user %d: %w", event.UserID, err)
+	}
+
+	// 用户保存的首选语言优先于事件发生时请求的语言
+	language := event.Language
+	if user.Language != "" {
+		language = user.Language
+	}
+
+	data := TemplateData{
+		UserID:     user.ID,
+		Username:   user.Username,
+		Email:      user.Email,
+		Trigger:    event.Type,
+		Language:   language,
+		Data:       event.Data,
+		OccurredAt: event.OccurredAt,
+	}
+
+	var errs []string
+	for _, rule := range rules {
+		message, err := Render(rule, data, e.defaultLanguage)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("rule %d: %v", rule.ID, err))
+			continue
+		}
+
+		if err := e.notifier.Notify(ctx, &notification.Notification{
+			UserID: user.ID,
+			Event:  NotificationEvent,
+			Email:  user.Email,
+			Data: map[string]interface{}{
+				"rule_id":  rule.ID,
+				"trigger":  event.Type,
+				"language": message.Language,
+				"title":    message.Title,
+				"body":     message.Body,
+			},
+			CreatedAt: clock.Now(),
+		}); err != nil {
+			errs = append(errs, fmt.Sprintf("rule %d: %v", rule.ID, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("automation rules failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
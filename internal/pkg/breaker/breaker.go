@@ -0,0 +1,308 @@
+// Package breaker 依赖服务熔断
+// 每个下游依赖（MySQL、MongoDB、Redis、外部行情接口）一个熔断器：连续失败达到阈值后打开，打开期间调用直接返回
+// CodeTimeout/CodeConnectionFailed错误而不等待超时；打开时间结束后进入半开状态，放行少量探测请求，全部成功后关闭，任一失败重新打开。
+// 熔断状态在进程内统计，不在实例间共享
+package breaker
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"exchange/internal/pkg/config"
+	appErrors "exchange/internal/pkg/errors"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/metrics"
+)
+
+// 依赖名称
+const (
+	MySQL    = "mysql"
+	MongoDB  = "mongodb"
+	Redis    = "redis"
+	PriceAPI = "price_api"
+)
+
+// State 熔断器状态
+type State int
+
+const (
+	StateClosed   State = iota // 关闭：正常调用
+	StateHalfOpen              // 半开：放行探测请求
+	StateOpen                  // 打开：直接拒绝
+)
+
+// String 状态名称
+func (s State) String() string {
+	switch s {
+	case StateHalfOpen:
+		return "half_open"
+	case StateOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// 调用结果
+const (
+	resultSuccess  = "success"
+	resultFailure  = "failure"
+	resultRejected = "rejected"
+)
+
+var (
+	callsTotal = metrics.NewCounterVec("exchange_circuit_breaker_calls_total",
+		"Dependency calls through the circuit breaker by result.", "dependency", "result")
+	transitionsTotal = metrics.NewCounterVec("exchange_circuit_breaker_transitions_total",
+		"Circuit breaker state transitions.", "dependency", "state")
+	stateGauge = metrics.NewGaugeVec("exchange_circuit_breaker_state",
+		"Circuit breaker state (0 closed, 1 half-open, 2 open).", "dependency")
+)
+
+// ErrUnavailable 依赖返回了表示不可用的结果（如上游5xx），传给done时计为失败
+var ErrUnavailable = errors.New("dependency unavailable")
+
+// breakerLogger 熔断日志
+var breakerLogger = appLogger.Module("breaker")
+
+// Breaker 单个依赖的熔断器，nil或未启用时放行所有调用
+type Breaker struct {
+	name string
+
+	mu         sync.Mutex
+	enabled    bool
+	policy     config.CircuitBreakerPolicy
+	state      State
+	failures   int       // 关闭状态下的连续失败次数
+	probes     int       // 半开状态已放行的探测请求数
+	successes  int       // 半开状态成功的探测请求数
+	openedAt   time.Time // 最近一次打开的时间
+	timeout    bool      // 最近一次失败是否为超时，决定打开期间返回的错误码
+	generation uint64    // 状态变化时递增，之前放行的调用的结果不再计入
+}
+
+// newBreaker 创建熔断器
+func newBreaker(name string, enabled bool, policy config.CircuitBreakerPolicy) *Breaker {
+	stateGauge.Set(float64(StateClosed), name)
+	return &Breaker{name: name, enabled: enabled, policy: policy}
+}
+
+// Name 依赖名称
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// configure 更新策略，关闭熔断时恢复到关闭状态
+func (b *Breaker) configure(enabled bool, policy config.CircuitBreakerPolicy) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.enabled, b.policy = enabled, policy
+	if !enabled && b.state != StateClosed {
+		b.setState(StateClosed)
+	}
+}
+
+// Allow 判断是否可以调用依赖，可以时返回done，调用结束后必须以调用结果执行一次；打开期间返回CircuitOpenError
+func (b *Breaker) Allow() (done func(err error), err error) {
+	if b == nil {
+		return func(error) {}, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.enabled {
+		return func(error) {}, nil
+	}
+
+	if b.state == StateOpen {
+		if wait := b.openRemaining(); wait > 0 {
+			callsTotal.Inc(b.name, resultRejected)
+			return nil, appErrors.CircuitOpenError(b.name, b.timeout, retryAfterSeconds(wait))
+		}
+		b.setState(StateHalfOpen)
+	}
+	if b.state == StateHalfOpen {
+		if b.probes >= b.policy.HalfOpenProbes {
+			callsTotal.Inc(b.name, resultRejected)
+			return nil, appErrors.CircuitOpenError(b.name, b.timeout, 1)
+		}
+		b.probes++
+	}
+
+	generation := b.generation
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() { b.record(generation, err) })
+	}, nil
+}
+
+// Execute 通过熔断器调用fn
+func (b *Breaker) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	err = fn(ctx)
+	done(err)
+	return err
+}
+
+// Observe 记录不经过Allow的调用结果（如驱动事件中观察到的连接失败），打开期间结束后的结果作为探测请求计入
+func (b *Breaker) Observe(err error) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	if !b.enabled {
+		b.mu.Unlock()
+		return
+	}
+	if b.state == StateOpen {
+		if b.openRemaining() > 0 {
+			b.mu.Unlock()
+			return
+		}
+		b.setState(StateHalfOpen)
+	}
+	if b.state == StateHalfOpen {
+		b.probes++
+	}
+	generation := b.generation
+	b.mu.Unlock()
+
+	b.record(generation, err)
+}
+
+// OpenError 熔断器处于打开期间时返回CircuitOpenError，否则返回nil（不占用半开状态的探测名额）
+func (b *Breaker) OpenError() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.enabled || b.state != StateOpen {
+		return nil
+	}
+	if wait := b.openRemaining(); wait > 0 {
+		return appErrors.CircuitOpenError(b.name, b.timeout, retryAfterSeconds(wait))
+	}
+	return nil
+}
+
+// State 当前状态，打开期间已结束但尚未有调用时为半开
+func (b *Breaker) State() State {
+	if b == nil {
+		return StateClosed
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateOpen && b.openRemaining() <= 0 {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// record 记录调用结果并按结果转换状态
+func (b *Breaker) record(generation uint64, err error) {
+	failure := IsFailure(err)
+	if failure {
+		callsTotal.Inc(b.name, resultFailure)
+	} else {
+		callsTotal.Inc(b.name, resultSuccess)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if generation != b.generation {
+		return
+	}
+
+	switch b.state {
+	case StateClosed:
+		if !failure {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		b.timeout = IsTimeout(err)
+		if b.failures >= b.policy.FailureThreshold {
+			b.open(err)
+		}
+	case StateHalfOpen:
+		if failure {
+			b.timeout = IsTimeout(err)
+			b.open(err)
+			return
+		}
+		b.successes++
+		if b.successes >= b.policy.HalfOpenProbes {
+			b.setState(StateClosed)
+			breakerLogger.Info("依赖服务恢复，熔断器关闭", map[string]interface{}{
+				"dependency": b.name,
+			})
+		}
+	}
+}
+
+// open 打开熔断器
+func (b *Breaker) open(cause error) {
+	b.setState(StateOpen)
+	b.openedAt = time.Now()
+	breakerLogger.Warn("依赖服务连续失败，熔断器打开", map[string]interface{}{
+		"dependency":   b.name,
+		"timeout":      b.timeout,
+		"open_seconds": b.policy.OpenSeconds,
+		"error":        cause.Error(),
+	})
+}
+
+// setState 转换状态并重置计数
+func (b *Breaker) setState(state State) {
+	b.state = state
+	b.failures, b.probes, b.successes = 0, 0, 0
+	b.generation++
+	transitionsTotal.Inc(b.name, state.String())
+	stateGauge.Set(float64(state), b.name)
+}
+
+// openRemaining 距打开期间结束的时间
+func (b *Breaker) openRemaining() time.Duration {
+	return time.Duration(b.policy.OpenSeconds)*time.Second - time.Since(b.openedAt)
+}
+
+// retryAfterSeconds 等待时间向上取整为秒，至少1秒
+func retryAfterSeconds(wait time.Duration) int {
+	return max(1, int(math.Ceil(wait.Seconds())))
+}
+
+// IsFailure 调用结果是否表示依赖不可用：超时、连接错误、网络错误和ErrUnavailable计为失败，
+// 记录不存在、唯一键冲突、锁等待等业务或数据错误以及调用方取消不计入
+func IsFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, ErrUnavailable) || IsTimeout(err) || errors.Is(err, driver.ErrBadConn) || mongo.IsNetworkError(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// IsTimeout 错误是否为超时
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || mongo.IsTimeout(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,192 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/event"
+	"gorm.io/gorm"
+)
+
+// gormDoneKey GORM语句实例中保存done的键
+const gormDoneKey = "breaker:done"
+
+// GormPlugin GORM插件：执行SQL前检查熔断器，打开期间不执行并返回CircuitOpenError，执行后记录结果
+type GormPlugin struct {
+	breaker *Breaker
+}
+
+// NewGormPlugin 创建GORM熔断插件
+func NewGormPlugin(b *Breaker) *GormPlugin {
+	return &GormPlugin{breaker: b}
+}
+
+// Name 插件名称
+func (p *GormPlugin) Name() string {
+	return "breaker"
+}
+
+// Initialize 在增删改查（含默认事务）和Row、Raw的执行前后注册回调
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	err := errors.Join(
+		cb.Create().Before("gorm:begin_transaction").Register("breaker:before_create", p.before),
+		cb.Create().After("gorm:commit_or_rollback_transaction").Register("breaker:after_create", p.after),
+		cb.Query().Before("gorm:query").Register("breaker:before_query", p.before),
+		cb.Query().After("gorm:after_query").Register("breaker:after_query", p.after),
+		cb.Update().Before("gorm:begin_transaction").Register("breaker:before_update", p.before),
+		cb.Update().After("gorm:commit_or_rollback_transaction").Register("breaker:after_update", p.after),
+		cb.Delete().Before("gorm:begin_transaction").Register("breaker:before_delete", p.before),
+		cb.Delete().After("gorm:commit_or_rollback_transaction").Register("breaker:after_delete", p.after),
+		cb.Row().Before("gorm:row").Register("breaker:before_row", p.before),
+		cb.Row().After("gorm:row").Register("breaker:after_row", p.after),
+		cb.Raw().Before("gorm:raw").Register("breaker:before_raw", p.before),
+		cb.Raw().After("gorm:raw").Register("breaker:after_raw", p.after),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register breaker callbacks: %w", err)
+	}
+	return nil
+}
+
+// before 检查熔断器，打开期间设置错误使后续回调跳过执行
+func (p *GormPlugin) before(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	done, err := p.breaker.Allow()
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	db.InstanceSet(gormDoneKey, done)
+}
+
+// after 记录执行结果
+func (p *GormPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormDoneKey)
+	if !ok {
+		return
+	}
+	if done, ok := value.(func(error)); ok {
+		done(db.Error)
+	}
+}
+
+// RedisHook go-redis钩子：打开期间命令不发送到Redis，直接返回CircuitOpenError
+type RedisHook struct {
+	breaker *Breaker
+}
+
+// NewRedisHook 创建Redis熔断钩子
+func NewRedisHook(b *Breaker) *RedisHook {
+	return &RedisHook{breaker: b}
+}
+
+// DialHook 建立连接不经过熔断器（连接失败由命令结果体现）
+func (h *RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook 执行命令，redis.Nil等命令结果不计为失败
+func (h *RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		done, err := h.breaker.Allow()
+		if err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		err = next(ctx, cmd)
+		done(redisFailure(err))
+		return err
+	}
+}
+
+// ProcessPipelineHook 执行管道命令
+func (h *RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		done, err := h.breaker.Allow()
+		if err != nil {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+			return err
+		}
+		err = next(ctx, cmds)
+		done(redisFailure(err))
+		return err
+	}
+}
+
+// redisFailure 命令错误中表示Redis不可用的部分，连接池等待超时也计为失败
+func redisFailure(err error) error {
+	if errors.Is(err, redis.ErrPoolTimeout) {
+		return context.DeadlineExceeded
+	}
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
+
+// NewMongoPoolMonitor MongoDB连接池监视器：获取连接超时或失败、连接因网络错误关闭计为失败，成功获取连接计为成功
+// 驱动不支持在执行前拦截命令，MongoDB熔断器只反映状态（供fail_fast和Execute使用），不拒绝驱动自身的调用
+func NewMongoPoolMonitor(b *Breaker) *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch {
+			case e.Type == event.GetSucceeded:
+				b.Observe(nil)
+			case e.Type == event.GetFailed && e.Reason == event.ReasonTimedOut:
+				b.Observe(context.DeadlineExceeded)
+			case e.Type == event.GetFailed && e.Reason == event.ReasonConnectionErrored,
+				e.Type == event.ConnectionClosed && e.Reason == event.ReasonError:
+				b.Observe(mongoPoolError(e))
+			}
+		},
+	}
+}
+
+// mongoPoolError 连接池事件中的错误
+func mongoPoolError(e *event.PoolEvent) error {
+	if e.Error != nil {
+		return fmt.Errorf("%w: %s: %w", ErrUnavailable, e.Type, e.Error)
+	}
+	return fmt.Errorf("%w: %s (%s)", ErrUnavailable, e.Type, e.Reason)
+}
+
+// Transport HTTP客户端熔断：打开期间不发送请求，网络错误、超时和5xx响应计为失败
+type Transport struct {
+	breaker *Breaker
+	base    http.RoundTripper
+}
+
+// NewTransport 创建带熔断的HTTP传输层，base为nil时使用http.DefaultTransport
+func NewTransport(b *Breaker, base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{breaker: b, base: base}
+}
+
+// RoundTrip 发送请求
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	done, err := t.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	switch {
+	case err != nil:
+		done(err)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		done(fmt.Errorf("%w: %s returned %d", ErrUnavailable, req.URL.Host, resp.StatusCode))
+	default:
+		done(nil)
+	}
+	return resp, err
+}
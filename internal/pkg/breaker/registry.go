@@ -0,0 +1,69 @@
+package breaker
+
+import (
+	"sync"
+
+	"exchange/internal/pkg/config"
+)
+
+// Registry 按依赖名称管理熔断器
+type Registry struct {
+	mu       sync.Mutex
+	cfg      config.CircuitBreakerConfig
+	breakers map[string]*Breaker
+}
+
+// defaultRegistry 全局熔断器，Configure之前不启用
+var defaultRegistry = NewRegistry(config.CircuitBreakerConfig{})
+
+// NewRegistry 创建熔断器注册表
+func NewRegistry(cfg config.CircuitBreakerConfig) *Registry {
+	return &Registry{cfg: cfg, breakers: make(map[string]*Breaker)}
+}
+
+// Default 获取全局熔断器注册表
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Configure 按配置设置全局熔断器（数据库连接创建前调用），已创建的熔断器同时更新策略
+func Configure(cfg config.CircuitBreakerConfig) {
+	defaultRegistry.Configure(cfg)
+}
+
+// Get 获取全局熔断器
+func Get(name string) *Breaker {
+	return defaultRegistry.Get(name)
+}
+
+// Configure 更新配置
+func (r *Registry) Configure(cfg config.CircuitBreakerConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cfg = cfg
+	for name, b := range r.breakers {
+		b.configure(cfg.Enabled, cfg.PolicyFor(name))
+	}
+}
+
+// Get 获取依赖的熔断器，不存在时按配置创建
+func (r *Registry) Get(name string) *Breaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if b, ok := r.breakers[name]; ok {
+		return b
+	}
+	b := newBreaker(name, r.cfg.Enabled, r.cfg.PolicyFor(name))
+	r.breakers[name] = b
+	return b
+}
+
+// FirstOpen 依次检查依赖，返回第一个处于打开期间的熔断器的CircuitOpenError，都未打开时返回nil
+func (r *Registry) FirstOpen(names []string) error {
+	for _, name := range names {
+		if err := r.Get(name).OpenError(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
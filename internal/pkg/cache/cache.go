@@ -1,8 +1,14 @@
 package cache
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"exchange/internal/pkg/database"
 )
 
 // CacheType 缓存类型
@@ -17,14 +23,33 @@ const (
 type CacheManager struct {
 	memoryCache Cache
 	redisCache  Cache
+
+	loads       singleflight.Group // GetOrLoad合并同一key的并发加载
+	negativeTTL time.Duration      // GetOrLoad空值标记的过期时间
+
+	codecMu      sync.RWMutex
+	codec        Codec         // 默认序列化方式
+	prefixCodecs []prefixCodec // 按键前缀的序列化方式，最长的前缀在前
+
+	refreshMu  sync.RWMutex
+	refreshers []*refresher // 提前刷新
+
+	ttlMu       sync.RWMutex
+	ttlPolicies []prefixTTLPolicy // 按键前缀的过期策略，最长的前缀在前
 }
 
 // NewCacheManager 创建缓存管理器
 func NewCacheManager(memoryCache, redisCache Cache) *CacheManager {
-	return &CacheManager{
+	cm := &CacheManager{
 		memoryCache: memoryCache,
 		redisCache:  redisCache,
+		negativeTTL: DefaultNegativeTTL,
+		codec:       JSONCodec,
 	}
+	for prefix, policy := range defaultTTLPolicies {
+		cm.SetTTLPolicy(prefix, policy)
+	}
+	return cm
 }
 
 // getCache 根据类型获取缓存实例
@@ -49,24 +74,39 @@ const (
 )
 
 // Redis缓存键前缀常量 - 适合存储需要持久化的数据
+// 用户相关的键通过userRedisKey带上用户ID的哈希标签，集群模式下同一用户的键在同一槽位，可以在一条命令中删除
 const (
 	RedisUserSessionPrefix  = "redis:user:session:"
 	RedisRateLimitPrefix    = "redis:rate_limit:"
 	RedisLockPrefix         = "redis:lock:"
 	RedisQueuePrefix        = "redis:queue:"
 	RedisNotificationPrefix = "redis:notification:"
+	RedisFreshPrefix        = "redis:fresh:"
 )
 
-// SetUserInfo 设置用户信息到内存缓存（频繁访问）
+// userRedisKey 用户相关的Redis键，如"redis:user:session:{42}"
+func userRedisKey(prefix, userID string) string {
+	return prefix + database.HashTag(userID)
+}
+
+// DefaultFreshWindow 写操作后绕过缓存的默认时长
+const DefaultFreshWindow = 5 * time.Second
+
+// SetUserInfo 设置用户信息到内存缓存（频繁访问），expiration为DefaultTTL时使用前缀的默认过期时间
 func (cm *CacheManager) SetUserInfo(userID string, userInfo interface{}, expiration time.Duration) error {
 	key := MemoryUserInfoPrefix + userID
-	return cm.memoryCache.Set(key, userInfo, expiration)
+	return cm.setValue(CacheTypeMemory, key, userInfo, expiration, UserTag(userID))
 }
 
 // GetUserInfo 从内存缓存获取用户信息
 func (cm *CacheManager) GetUserInfo(userID string, dest interface{}) error {
 	key := MemoryUserInfoPrefix + userID
-	return cm.memoryCache.GetJSON(key, dest)
+	return cm.getValue(CacheTypeMemory, key, dest)
+}
+
+// GetUserInfoOrLoad 从内存缓存获取用户信息，未命中时通过loader加载（并发未命中只加载一次）
+func (cm *CacheManager) GetUserInfoOrLoad(userID string, expiration time.Duration, dest interface{}, loader LoadFunc) error {
+	return cm.getOrLoad(CacheTypeMemory, MemoryUserInfoPrefix+userID, expiration, dest, loader, UserTag(userID))
 }
 
 // DeleteUserInfo 删除内存中的用户信息
@@ -77,20 +117,25 @@ func (cm *CacheManager) DeleteUserInfo(userID string) error {
 
 // SetUserSession 设置用户会话到Redis（需要持久化）
 func (cm *CacheManager) SetUserSession(userID string, token string, expiration time.Duration) error {
-	key := RedisUserSessionPrefix + userID
+	key := userRedisKey(RedisUserSessionPrefix, userID)
+	expiration = cm.TTLFor(key, expiration)
 	sessionData := map[string]interface{}{
 		"token":      token,
 		"created_at": time.Now().Unix(),
 		"expires_at": time.Now().Add(expiration).Unix(),
 	}
-	return cm.redisCache.Set(key, sessionData, expiration)
+	data, err := cm.codecFor(key).Marshal(sessionData)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache value %s: %w", key, err)
+	}
+	return cm.setTagged(CacheTypeRedis, key, data, expiration, UserTag(userID))
 }
 
 // GetUserSession 从Redis获取用户会话
 func (cm *CacheManager) GetUserSession(userID string) (map[string]interface{}, error) {
-	key := RedisUserSessionPrefix + userID
+	key := userRedisKey(RedisUserSessionPrefix, userID)
 	var sessionData map[string]interface{}
-	if err := cm.redisCache.GetJSON(key, &sessionData); err != nil {
+	if err := cm.getValue(CacheTypeRedis, key, &sessionData); err != nil {
 		return nil, fmt.Errorf("failed to get user session: %w", err)
 	}
 	return sessionData, nil
@@ -98,20 +143,25 @@ func (cm *CacheManager) GetUserSession(userID string) (map[string]interface{}, e
 
 // DeleteUserSession 删除Redis中的用户会话
 func (cm *CacheManager) DeleteUserSession(userID string) error {
-	key := RedisUserSessionPrefix + userID
+	key := userRedisKey(RedisUserSessionPrefix, userID)
 	return cm.redisCache.Delete(key)
 }
 
 // SetConfig 设置配置到内存缓存（频繁读取）
 func (cm *CacheManager) SetConfig(configKey string, value interface{}, expiration time.Duration) error {
 	key := MemoryConfigPrefix + configKey
-	return cm.memoryCache.Set(key, value, expiration)
+	return cm.setValue(CacheTypeMemory, key, value, expiration)
 }
 
 // GetConfig 从内存缓存获取配置
 func (cm *CacheManager) GetConfig(configKey string, dest interface{}) error {
 	key := MemoryConfigPrefix + configKey
-	return cm.memoryCache.GetJSON(key, dest)
+	return cm.getValue(CacheTypeMemory, key, dest)
+}
+
+// GetConfigOrLoad 从内存缓存获取配置，未命中时通过loader加载（并发未命中只加载一次）
+func (cm *CacheManager) GetConfigOrLoad(configKey string, expiration time.Duration, dest interface{}, loader LoadFunc) error {
+	return cm.GetOrLoad(CacheTypeMemory, MemoryConfigPrefix+configKey, expiration, dest, loader)
 }
 
 // IncrementCounter 递增内存中的计数器（高频操作）
@@ -120,48 +170,65 @@ func (cm *CacheManager) IncrementCounter(counterName string) (int64, error) {
 	return cm.memoryCache.Increment(key)
 }
 
-// GetCounter 获取内存中的计数器值
+// GetCounter 获取内存中的计数器值，计数器不存在时返回ErrMiss
 func (cm *CacheManager) GetCounter(counterName string) (int64, error) {
-	key := MemoryCounterPrefix + counterName
-	countStr, err := cm.memoryCache.Get(key)
-	if err != nil {
-		return 0, err
+	count, ok, err := NewTyped[int64](cm.memoryCache, MemoryCounterPrefix).Get(context.Background(), counterName)
+	if err == nil && !ok {
+		err = fmt.Errorf("%w: %s", ErrMiss, MemoryCounterPrefix+counterName)
 	}
-
-	var count int64
-	fmt.Sscanf(countStr, "%d", &count)
-	return count, nil
+	return count, err
 }
 
 // SetRateLimit 设置限流计数到Redis（需要分布式共享）
 func (cm *CacheManager) SetRateLimit(ip, endpoint string, count int64, expiration time.Duration) error {
 	key := fmt.Sprintf("%s%s:%s", RedisRateLimitPrefix, ip, endpoint)
-	return cm.redisCache.Set(key, count, expiration)
+	return cm.redisCache.Set(key, count, cm.TTLFor(key, expiration))
 }
 
-// IncrementRateLimit 递增Redis中的限流计数
-func (cm *CacheManager) IncrementRateLimit(ip, endpoint string) (int64, error) {
+// IncrementRateLimit 递增Redis中的限流计数，首次计数时设置过期时间，返回递增后的计数
+// ip为限流对象（客户端IP或用户标识），endpoint为限流策略和窗口编号
+func (cm *CacheManager) IncrementRateLimit(ip, endpoint string, expiration time.Duration) (int64, error) {
 	key := fmt.Sprintf("%s%s:%s", RedisRateLimitPrefix, ip, endpoint)
-	return cm.redisCache.Increment(key)
+	count, err := cm.redisCache.Increment(key)
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		if err := cm.redisCache.Expire(key, cm.TTLFor(key, expiration)); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
 }
 
-// GetRateLimit 获取Redis中的限流计数
-func (cm *CacheManager) GetRateLimit(ip, endpoint string) (int64, error) {
+// ReleaseRateLimit 撤销一次限流计数（被拒绝的请求不占用配额）
+func (cm *CacheManager) ReleaseRateLimit(ip, endpoint string) error {
 	key := fmt.Sprintf("%s%s:%s", RedisRateLimitPrefix, ip, endpoint)
-	countStr, err := cm.redisCache.Get(key)
-	if err != nil {
-		return 0, err
+	_, err := cm.redisCache.IncrementBy(key, -1)
+	return err
+}
+
+// TakeRateLimitToken 从Redis中的令牌桶取一个令牌，桶容量为capacity，每隔refillEvery补充一个令牌
+// Redis缓存需支持令牌桶操作（TokenBucketCache）
+func (cm *CacheManager) TakeRateLimitToken(ip, endpoint string, capacity int64, refillEvery time.Duration) (TokenBucketResult, error) {
+	bucket, ok := cm.redisCache.(TokenBucketCache)
+	if !ok {
+		return TokenBucketResult{}, fmt.Errorf("cache does not support token buckets")
 	}
+	key := fmt.Sprintf("%s%s:%s", RedisRateLimitPrefix, ip, endpoint)
+	return bucket.TakeToken(key, capacity, refillEvery)
+}
 
-	var count int64
-	fmt.Sscanf(countStr, "%d", &count)
-	return count, nil
+// GetRateLimit 获取Redis中的限流计数，计数不存在时为0
+func (cm *CacheManager) GetRateLimit(ip, endpoint string) (int64, error) {
+	count, _, err := NewTyped[int64](cm.redisCache, RedisRateLimitPrefix).Get(context.Background(), ip+":"+endpoint)
+	return count, err
 }
 
-// AddOnlineUser 添加在线用户到内存（实时状态）
+// AddOnlineUser 添加在线用户到内存（实时状态），过期时间为前缀的默认过期时间
 func (cm *CacheManager) AddOnlineUser(userID string) error {
 	key := MemoryOnlineUsersPrefix + userID
-	return cm.memoryCache.Set(key, "online", 24*time.Hour)
+	return cm.SetTagged(CacheTypeMemory, key, "online", DefaultTTL, UserTag(userID))
 }
 
 // RemoveOnlineUser 从内存中移除在线用户
@@ -176,125 +243,100 @@ func (cm *CacheManager) IsUserOnline(userID string) (bool, error) {
 	return cm.memoryCache.Exists(key)
 }
 
-// SetLock 设置分布式锁到Redis
-func (cm *CacheManager) SetLock(lockKey string, value string, expiration time.Duration) error {
-	key := RedisLockPrefix + lockKey
-	return cm.redisCache.Set(key, value, expiration)
-}
-
-// ReleaseLock 释放Redis中的分布式锁
-func (cm *CacheManager) ReleaseLock(lockKey string) error {
-	key := RedisLockPrefix + lockKey
-	return cm.redisCache.Delete(key)
-}
-
-// CheckLock 检查Redis中的锁是否存在
-func (cm *CacheManager) CheckLock(lockKey string) (bool, error) {
-	key := RedisLockPrefix + lockKey
-	return cm.redisCache.Exists(key)
-}
-
 // SetTempData 设置临时数据（根据大小和重要性选择存储位置）
 func (cm *CacheManager) SetTempData(key string, value interface{}, expiration time.Duration, useMemory bool) error {
-	var fullKey string
-	var cache Cache
-
-	if useMemory {
-		fullKey = MemoryTempPrefix + key
-		cache = cm.memoryCache
-	} else {
-		fullKey = "temp:" + key
-		cache = cm.redisCache
-	}
-
-	return cache.Set(fullKey, value, expiration)
+	fullKey, cacheType := tempDataKey(key, useMemory)
+	return cm.setValue(cacheType, fullKey, value, expiration)
 }
 
 // GetTempData 获取临时数据（需要指定存储位置）
 func (cm *CacheManager) GetTempData(key string, dest interface{}, fromMemory bool) error {
-	var fullKey string
-	var cache Cache
-
-	if fromMemory {
-		fullKey = MemoryTempPrefix + key
-		cache = cm.memoryCache
-	} else {
-		fullKey = "temp:" + key
-		cache = cm.redisCache
-	}
-
-	return cache.GetJSON(fullKey, dest)
+	fullKey, cacheType := tempDataKey(key, fromMemory)
+	return cm.getValue(cacheType, fullKey, dest)
 }
 
 // DeleteTempData 删除临时数据（需要指定存储位置）
 func (cm *CacheManager) DeleteTempData(key string, fromMemory bool) error {
-	var fullKey string
-	var cache Cache
-
-	if fromMemory {
-		fullKey = MemoryTempPrefix + key
-		cache = cm.memoryCache
-	} else {
-		fullKey = "temp:" + key
-		cache = cm.redisCache
-	}
+	fullKey, cacheType := tempDataKey(key, fromMemory)
+	return cm.getCache(cacheType).Delete(fullKey)
+}
 
-	return cache.Delete(fullKey)
+// tempDataKey 临时数据的键和存储位置
+func tempDataKey(key string, memory bool) (string, CacheType) {
+	if memory {
+		return MemoryTempPrefix + key, CacheTypeMemory
+	}
+	return "temp:" + key, CacheTypeRedis
 }
 
 // SetNotification 设置通知到Redis（需要持久化和分布式访问）
 func (cm *CacheManager) SetNotification(userID string, notification interface{}, expiration time.Duration) error {
-	key := RedisNotificationPrefix + userID
-	return cm.redisCache.Set(key, notification, expiration)
+	key := userRedisKey(RedisNotificationPrefix, userID)
+	return cm.setValue(CacheTypeRedis, key, notification, expiration, UserTag(userID))
 }
 
 // GetNotification 从Redis获取通知
 func (cm *CacheManager) GetNotification(userID string, dest interface{}) error {
-	key := RedisNotificationPrefix + userID
-	return cm.redisCache.GetJSON(key, dest)
+	key := userRedisKey(RedisNotificationPrefix, userID)
+	return cm.getValue(CacheTypeRedis, key, dest)
 }
 
-// ClearUserCache 清除用户相关的所有缓存（内存和Redis）
-func (cm *CacheManager) ClearUserCache(userID string) error {
-	// 清除内存中的用户数据
-	memoryKeys := []string{
-		MemoryUserInfoPrefix + userID,
-		MemoryOnlineUsersPrefix + userID,
+// MarkFresh 写操作后设置短期"新鲜"标记（Redis，所有实例共享）
+// 标记有效期内对该数据的读取应绕过缓存直接查询数据库，保证写后读一致
+func (cm *CacheManager) MarkFresh(scope, id string, window time.Duration) error {
+	if window <= 0 {
+		return nil
 	}
+	key := fmt.Sprintf("%s%s:%s", RedisFreshPrefix, scope, id)
+	return cm.redisCache.Set(key, time.Now().UnixNano(), window)
+}
 
-	// 清除Redis中的用户数据
-	redisKeys := []string{
-		RedisUserSessionPrefix + userID,
-		RedisNotificationPrefix + userID,
-	}
+// IsFresh 检查数据是否处于"新鲜"标记有效期内
+func (cm *CacheManager) IsFresh(scope, id string) (bool, error) {
+	key := fmt.Sprintf("%s%s:%s", RedisFreshPrefix, scope, id)
+	return cm.redisCache.Exists(key)
+}
 
-	// 清除内存缓存
-	if err := cm.memoryCache.Delete(memoryKeys...); err != nil {
-		return fmt.Errorf("failed to clear memory cache: %w", err)
-	}
+// MGet 批量读取键的原始值，返回的map只包含命中的键（Redis通过管道一次发送）
+func (cm *CacheManager) MGet(cacheType CacheType, keys ...string) (map[string]string, error) {
+	return cm.getCache(cacheType).MGet(keys...)
+}
 
-	// 清除Redis缓存
-	if err := cm.redisCache.Delete(redisKeys...); err != nil {
-		return fmt.Errorf("failed to clear redis cache: %w", err)
-	}
+// MSet 批量写入，如预热行情数据；字符串和[]byte原样保存，其他值保存为JSON
+func (cm *CacheManager) MSet(cacheType CacheType, items map[string]interface{}, expiration time.Duration) error {
+	return cm.getCache(cacheType).MSet(items, expiration)
+}
 
+// DeleteByPrefix 删除以prefix开头的键（Redis使用SCAN，不阻塞服务器），如清除某个交易对的所有缓存
+func (cm *CacheManager) DeleteByPrefix(cacheType CacheType, prefix string) (int64, error) {
+	return cm.getCache(cacheType).DeleteByPrefix(prefix)
+}
+
+// ClearUserCache 清除用户相关的所有缓存（内存和Redis中带有用户标签的键）
+func (cm *CacheManager) ClearUserCache(userID string) error {
+	if _, err := cm.InvalidateTags(UserTag(userID)); err != nil {
+		return fmt.Errorf("failed to clear user cache: %w", err)
+	}
 	return nil
 }
 
 // GetCacheStats 获取缓存统计信息
+// memory包含内存缓存的容量、淘汰统计和读写操作统计，redis包含读写操作统计和连接池统计
 func (cm *CacheManager) GetCacheStats() map[string]interface{} {
 	stats := make(map[string]interface{})
 
-	// 获取内存缓存统计
 	if memoryAdapter, ok := cm.memoryCache.(*MemoryAdapter); ok {
-		stats["memory"] = memoryAdapter.GetStats()
+		stats["memory"] = map[string]interface{}{
+			"cache":      memoryAdapter.GetStats(),
+			"operations": memoryAdapter.OperationStats(),
+		}
 	}
 
-	// Redis统计信息需要通过RedisAdapter获取
-	// 这里可以扩展获取Redis统计信息的方法
-	stats["redis"] = map[string]interface{}{
-		"type":   "redis",
-		"status": "connected",
+	if redisAdapter, ok := cm.redisCache.(*RedisAdapter); ok {
+		stats["redis"] = map[string]interface{}{
+			"operations": redisAdapter.OperationStats(),
+			"pool":       redisAdapter.PoolStats(),
+		}
 	}
 
 	return stats
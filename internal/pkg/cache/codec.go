@@ -0,0 +1,258 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"exchange/internal/pkg/config"
+)
+
+// Codec 缓存值的序列化方式
+type Codec interface {
+	Name() string
+	Marshal(value interface{}) ([]byte, error)
+	Unmarshal(data []byte, dest interface{}) error
+}
+
+// 内置的序列化方式
+var (
+	JSONCodec    Codec = jsonCodec{}    // 默认，可读性好，Increment写入的计数器也能解析
+	MsgpackCodec Codec = msgpackCodec{} // 体积和编解码开销小于JSON，结构体字段按json标签命名
+	GobCodec     Codec = gobCodec{}     // Go专用，接口类型的字段需要gob.Register
+)
+
+// NewCodec 按名称创建序列化方式，gzipMinBytes大于0时序列化后达到该大小的值以gzip压缩保存
+func NewCodec(name string, gzipMinBytes int) (Codec, error) {
+	var codec Codec
+	switch name {
+	case "", "json":
+		codec = JSONCodec
+	case "msgpack":
+		codec = MsgpackCodec
+	case "gob":
+		codec = GobCodec
+	default:
+		return nil, fmt.Errorf("unknown cache codec %q", name)
+	}
+	if gzipMinBytes > 0 {
+		codec = Gzip(codec, gzipMinBytes)
+	}
+	return codec, nil
+}
+
+// Configure 按配置设置默认序列化方式、按键前缀的序列化方式和过期策略
+func (cm *CacheManager) Configure(cfg config.CacheConfig) error {
+	codec, err := NewCodec(cfg.Codec, cfg.GzipMinBytes)
+	if err != nil {
+		return err
+	}
+	cm.SetCodec(codec)
+	for prefix, name := range cfg.PrefixCodecs {
+		codec, err := NewCodec(name, cfg.GzipMinBytes)
+		if err != nil {
+			return err
+		}
+		cm.SetPrefixCodec(prefix, codec)
+	}
+	for prefix, policy := range cfg.TTLPolicies {
+		cm.SetTTLPolicy(prefix, TTLPolicy{
+			TTL:    time.Duration(policy.TTLSeconds) * time.Second,
+			Jitter: policy.Jitter,
+		})
+	}
+	return nil
+}
+
+// SetCodec 设置默认序列化方式
+// 修改后已缓存的值可能无法解析，读取时按未命中处理
+func (cm *CacheManager) SetCodec(codec Codec) {
+	cm.codecMu.Lock()
+	defer cm.codecMu.Unlock()
+	cm.codec = codec
+}
+
+// SetPrefixCodec 为指定前缀的键设置序列化方式，多个前缀匹配时使用最长的前缀
+func (cm *CacheManager) SetPrefixCodec(prefix string, codec Codec) {
+	cm.codecMu.Lock()
+	defer cm.codecMu.Unlock()
+	for i := range cm.prefixCodecs {
+		if cm.prefixCodecs[i].prefix == prefix {
+			cm.prefixCodecs[i].codec = codec
+			return
+		}
+	}
+	cm.prefixCodecs = append(cm.prefixCodecs, prefixCodec{prefix: prefix, codec: codec})
+	sort.Slice(cm.prefixCodecs, func(i, j int) bool {
+		return len(cm.prefixCodecs[i].prefix) > len(cm.prefixCodecs[j].prefix)
+	})
+}
+
+// prefixCodec 键前缀的序列化方式
+type prefixCodec struct {
+	prefix string
+	codec  Codec
+}
+
+// codecFor 键使用的序列化方式
+func (cm *CacheManager) codecFor(key string) Codec {
+	cm.codecMu.RLock()
+	defer cm.codecMu.RUnlock()
+	for _, pc := range cm.prefixCodecs {
+		if strings.HasPrefix(key, pc.prefix) {
+			return pc.codec
+		}
+	}
+	if cm.codec == nil {
+		return JSONCodec
+	}
+	return cm.codec
+}
+
+// setValue 按键的序列化方式编码后写入，expiration按键前缀的过期策略处理
+func (cm *CacheManager) setValue(cacheType CacheType, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	data, err := cm.codecFor(key).Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache value %s: %w", key, err)
+	}
+	return cm.SetTagged(cacheType, key, data, expiration, tags...)
+}
+
+// getValue 读取并按键的序列化方式解码到dest
+func (cm *CacheManager) getValue(cacheType CacheType, key string, dest interface{}) error {
+	data, err := cm.getCache(cacheType).Get(key)
+	if err != nil {
+		return err
+	}
+	if err := cm.codecFor(key).Unmarshal([]byte(data), dest); err != nil {
+		return fmt.Errorf("failed to decode cache value %s: %w", key, err)
+	}
+	return nil
+}
+
+// jsonCodec JSON序列化
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                                  { return "json" }
+func (jsonCodec) Marshal(value interface{}) ([]byte, error)     { return json.Marshal(value) }
+func (jsonCodec) Unmarshal(data []byte, dest interface{}) error { return json.Unmarshal(data, dest) }
+
+// msgpackCodec MessagePack序列化，结构体字段按json标签命名，与JSON编码的字段名一致
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string { return "msgpack" }
+
+func (msgpackCodec) Marshal(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, dest interface{}) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(dest)
+}
+
+// gobCodec gob序列化
+type gobCodec struct{}
+
+func (gobCodec) Name() string { return "gob" }
+
+func (gobCodec) Marshal(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, dest interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(dest)
+}
+
+// gzip压缩标记，保存在序列化结果的第一个字节
+const (
+	gzipPlain      byte = 0
+	gzipCompressed byte = 1
+)
+
+// gzipWriters 复用gzip.Writer
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(io.Discard)
+	},
+}
+
+// Gzip 序列化后达到minBytes的值以gzip压缩保存，小于minBytes的值不压缩
+// 结果的第一个字节标记是否压缩，读取时不依赖minBytes
+func Gzip(codec Codec, minBytes int) Codec {
+	return gzipCodec{codec: codec, minBytes: minBytes}
+}
+
+// gzipCodec 压缩大值的序列化方式
+type gzipCodec struct {
+	codec    Codec
+	minBytes int
+}
+
+func (c gzipCodec) Name() string { return c.codec.Name() + "+gzip" }
+
+func (c gzipCodec) Marshal(value interface{}) ([]byte, error) {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < c.minBytes {
+		return append([]byte{gzipPlain}, data...), nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(gzipCompressed)
+	zw := gzipWriters.Get().(*gzip.Writer)
+	defer gzipWriters.Put(zw)
+	zw.Reset(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c gzipCodec) Unmarshal(data []byte, dest interface{}) error {
+	if len(data) == 0 {
+		return fmt.Errorf("empty gzip cache value")
+	}
+	switch data[0] {
+	case gzipPlain:
+		return c.codec.Unmarshal(data[1:], dest)
+	case gzipCompressed:
+		zr, err := gzip.NewReader(bytes.NewReader(data[1:]))
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		plain, err := io.ReadAll(zr)
+		if err != nil {
+			return err
+		}
+		return c.codec.Unmarshal(plain, dest)
+	default:
+		return fmt.Errorf("invalid gzip cache value header %d", data[0])
+	}
+}
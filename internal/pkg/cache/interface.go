@@ -1,6 +1,14 @@
 package cache
 
-import "time"
+import (
+	"context"
+	"time"
+
+	"exchange/internal/pkg/database"
+)
+
+// ErrMiss 键不存在或已过期，Get和GetJSON未命中时返回的错误链中包含该错误
+var ErrMiss = database.ErrKeyNotFound
 
 // Cache 缓存接口
 type Cache interface {
@@ -8,9 +16,46 @@ type Cache interface {
 	Get(key string) (string, error)
 	GetJSON(key string, dest interface{}) error
 	Delete(keys ...string) error
+	// MGet 批量获取值，返回的map只包含命中的键
+	MGet(keys ...string) (map[string]string, error)
+	// MSet 批量设置键值对，所有键使用相同的过期时间
+	MSet(items map[string]interface{}, expiration time.Duration) error
+	// DeleteByPrefix 删除以prefix开头的键，返回删除的键数
+	DeleteByPrefix(prefix string) (int64, error)
 	Exists(key string) (bool, error)
 	Expire(key string, expiration time.Duration) error
 	TTL(key string) (time.Duration, error)
 	Increment(key string) (int64, error)
 	IncrementBy(key string, value int64) (int64, error)
 }
+
+// TokenBucketCache 支持原子令牌桶操作的缓存（限流使用）
+type TokenBucketCache interface {
+	// TakeToken 取一个令牌，桶不存在时视为已满；桶容量为capacity，每隔refillEvery补充一个令牌
+	TakeToken(key string, capacity int64, refillEvery time.Duration) (TokenBucketResult, error)
+}
+
+// TaggableCache 支持按标签失效的缓存
+type TaggableCache interface {
+	// Tag 为已写入的键添加标签，expiration为键的过期时间（标签索引至少保留到键过期）
+	Tag(key string, expiration time.Duration, tags ...string) error
+	// InvalidateTags 删除带有任一标签的键，返回删除的键数
+	InvalidateTags(tags ...string) (int64, error)
+}
+
+// LockableCache 支持分布式锁的缓存
+type LockableCache interface {
+	// TryLock 以token获取锁，成功时返回递增的fencing token，锁已被持有时返回0
+	TryLock(ctx context.Context, key, token string, ttl time.Duration) (int64, error)
+	// Unlock 锁仍由token持有时释放
+	Unlock(ctx context.Context, key, token string) (bool, error)
+	// ExtendLock 锁仍由token持有时重设过期时间
+	ExtendLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+}
+
+// TokenBucketResult 取令牌的结果
+type TokenBucketResult struct {
+	Allowed    bool          // 是否取到令牌
+	Remaining  int64         // 剩余令牌数（向下取整）
+	RetryAfter time.Duration // 未取到令牌时距补充下一个令牌的时间
+}
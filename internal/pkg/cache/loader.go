@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	appLogger "exchange/internal/pkg/logger"
+)
+
+// ErrNotFound 数据不存在
+// 加载函数在数据不存在时返回该错误（可用%w包装原始错误），GetOrLoad缓存空值标记，标记有效期内直接返回该错误
+var ErrNotFound = errors.New("cache: value not found")
+
+// DefaultNegativeTTL 空值标记的默认过期时间
+const DefaultNegativeTTL = 30 * time.Second
+
+// negativeValue 空值标记，正常缓存的值为JSON，不会以\x00开头
+const negativeValue = "\x00cache:not_found"
+
+// cacheLogger 缓存日志
+var cacheLogger = appLogger.Module("cache")
+
+// LoadFunc 缓存未命中时加载数据，数据不存在时返回ErrNotFound
+type LoadFunc func() (interface{}, error)
+
+// GetOrLoad 从缓存读取key并反序列化到dest，未命中时调用loader加载并以ttl写入缓存（按键前缀的过期策略处理，可传入DefaultTTL）
+// 同一实例内同一key的并发未命中只调用一次loader（singleflight），其他调用等待并共享结果，避免缓存击穿时请求同时打到数据库；
+// loader返回ErrNotFound时写入空值标记（过期时间为negative TTL），避免不存在的数据反复穿透。
+// 缓存读写失败时不影响结果，直接使用loader的返回值
+func (cm *CacheManager) GetOrLoad(cacheType CacheType, key string, ttl time.Duration, dest interface{}, loader LoadFunc) error {
+	return cm.getOrLoad(cacheType, key, ttl, dest, loader)
+}
+
+// getOrLoad GetOrLoad的实现，加载的结果（包括空值标记）写入缓存时添加tags
+func (cm *CacheManager) getOrLoad(cacheType CacheType, key string, ttl time.Duration, dest interface{}, loader LoadFunc, tags ...string) error {
+	cache := cm.getCache(cacheType)
+	codec := cm.codecFor(key)
+	refresher := cm.refresherFor(cacheType, key)
+	if data, err := cache.Get(key); err == nil {
+		if data == negativeValue {
+			return ErrNotFound
+		}
+		if err := codec.Unmarshal([]byte(data), dest); err == nil {
+			if refresher != nil {
+				cm.refreshIfExpiring(refresher, key, tags)
+			}
+			return nil
+		}
+		// 缓存的数据与dest的类型或序列化方式不一致时按未命中处理，重新加载后覆盖
+	}
+
+	flightKey := strconv.Itoa(int(cacheType)) + ":" + key
+	result, err, _ := cm.loads.Do(flightKey, func() (interface{}, error) {
+		value, err := loader()
+		if errors.Is(err, ErrNotFound) {
+			if negativeTTL := cm.negativeTTL; negativeTTL > 0 {
+				cm.storeLoaded(cache, key, negativeValue, negativeTTL, tags)
+			}
+			return nil, err
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := codec.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal loaded value: %w", err)
+		}
+		cm.storeLoaded(cache, key, data, ttl, tags)
+		if refresher != nil {
+			refresher.track(key, tags)
+		}
+		return data, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return codec.Unmarshal(result.([]byte), dest)
+}
+
+// SetNegativeTTL 设置空值标记的过期时间，为0时不缓存不存在的结果
+func (cm *CacheManager) SetNegativeTTL(ttl time.Duration) {
+	cm.negativeTTL = ttl
+}
+
+// storeLoaded 写入加载的结果，失败时只记录日志
+func (cm *CacheManager) storeLoaded(cache Cache, key string, value interface{}, ttl time.Duration, tags []string) {
+	ttl = cm.TTLFor(key, ttl)
+	err := cache.Set(key, value, ttl)
+	if err == nil {
+		err = cm.tag(cache, key, ttl, tags...)
+	}
+	if err != nil {
+		cacheLogger.Warn("写入加载结果失败", map[string]interface{}{
+			"key":   key,
+			"error": err.Error(),
+		})
+	}
+}
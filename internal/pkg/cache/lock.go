@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"exchange/internal/pkg/database"
+)
+
+var (
+	// ErrLockNotAcquired 锁已被其他持有者持有
+	ErrLockNotAcquired = errors.New("cache: lock not acquired")
+	// ErrLockNotHeld 锁已过期或已被其他持有者获取，释放或续期失败
+	ErrLockNotHeld = errors.New("cache: lock not held")
+)
+
+// Lock 已获取的分布式锁
+type Lock struct {
+	Key   string // 锁名（不含前缀）
+	Token string // 持有者令牌，只有持有者可以释放和续期
+	// Fence 单调递增的fencing token，每次获取锁时加1
+	// 锁过期后旧的持有者可能仍在执行，写入共享资源时携带Fence，由资源拒绝小于已见过的值的写入
+	Fence int64
+
+	key string
+}
+
+// lockRedisKey 锁的Redis键，fencing计数器为同一哈希槽的"<键>:fence"
+func lockRedisKey(lockKey string) string {
+	return RedisLockPrefix + database.HashTag(lockKey)
+}
+
+// lockCache Redis缓存的分布式锁操作
+func (cm *CacheManager) lockCache() (LockableCache, error) {
+	locker, ok := cm.redisCache.(LockableCache)
+	if !ok {
+		return nil, fmt.Errorf("cache does not support locks")
+	}
+	return locker, nil
+}
+
+// AcquireLock 获取Redis分布式锁，锁已被持有时返回ErrLockNotAcquired
+// 获取（SET NX PX）和递增fencing token在Lua脚本中原子执行，返回的Lock用于释放和续期
+func (cm *CacheManager) AcquireLock(ctx context.Context, lockKey string, ttl time.Duration) (*Lock, error) {
+	locker, err := cm.lockCache()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+	key := lockRedisKey(lockKey)
+	fence, err := locker.TryLock(ctx, key, token, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", lockKey, err)
+	}
+	if fence == 0 {
+		return nil, ErrLockNotAcquired
+	}
+	return &Lock{Key: lockKey, Token: token, Fence: fence, key: key}, nil
+}
+
+// ReleaseLock 释放分布式锁，锁已过期或已被其他持有者获取时不删除并返回ErrLockNotHeld
+func (cm *CacheManager) ReleaseLock(ctx context.Context, lock *Lock) error {
+	locker, err := cm.lockCache()
+	if err != nil {
+		return err
+	}
+
+	released, err := locker.Unlock(ctx, lock.key, lock.Token)
+	if err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", lock.Key, err)
+	}
+	if !released {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// ExtendLock 将仍持有的锁的过期时间重设为ttl，锁已丢失时返回ErrLockNotHeld
+func (cm *CacheManager) ExtendLock(ctx context.Context, lock *Lock, ttl time.Duration) error {
+	locker, err := cm.lockCache()
+	if err != nil {
+		return err
+	}
+
+	extended, err := locker.ExtendLock(ctx, lock.key, lock.Token, ttl)
+	if err != nil {
+		return fmt.Errorf("failed to extend lock %s: %w", lock.Key, err)
+	}
+	if !extended {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// WithLock 获取锁后执行fn，fn返回后释放锁；锁已被持有时返回ErrLockNotAcquired，不执行fn
+// fn执行期间每ttl/3续期一次，续期发现锁已丢失时取消传给fn的ctx，fn应在ctx取消后尽快返回
+func (cm *CacheManager) WithLock(ctx context.Context, lockKey string, ttl time.Duration, fn func(ctx context.Context, lock *Lock) error) error {
+	lock, err := cm.AcquireLock(ctx, lockKey, ttl)
+	if err != nil {
+		return err
+	}
+
+	fnCtx, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(max(ttl/3, time.Millisecond))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-fnCtx.Done():
+				return
+			case <-ticker.C:
+				if err := cm.ExtendLock(fnCtx, lock, ttl); err != nil {
+					cacheLogger.Warn("分布式锁续期失败", map[string]interface{}{
+						"lock_key": lockKey,
+						"error":    err.Error(),
+					})
+					if errors.Is(err, ErrLockNotHeld) {
+						cancel(err)
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	fnErr := fn(fnCtx, lock)
+	close(done)
+	lost := context.Cause(fnCtx)
+	cancel(nil)
+
+	// 使用独立的ctx释放，调用方的ctx已取消时也能释放锁
+	releaseErr := cm.ReleaseLock(context.WithoutCancel(ctx), lock)
+	if fnErr != nil {
+		return fnErr
+	}
+	if errors.Is(lost, ErrLockNotHeld) {
+		return fmt.Errorf("lock %s lost while held: %w", lockKey, ErrLockNotHeld)
+	}
+	return releaseErr
+}
+
+// CheckLock 检查Redis中的锁是否存在
+func (cm *CacheManager) CheckLock(lockKey string) (bool, error) {
+	return cm.redisCache.Exists(lockRedisKey(lockKey))
+}
+
+// newLockToken 生成随机的持有者令牌
+func newLockToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
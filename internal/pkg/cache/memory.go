@@ -3,7 +3,11 @@ package cache
 import (
 	"container/list"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 )
@@ -12,8 +16,11 @@ import (
 type MemoryCacheItem struct {
 	Key        string
 	Value      interface{}
-	ExpireTime int64 // Unix纳秒时间戳，0表示永不过期
-	AccessTime int64 // 最后访问时间，用于LRU
+	ExpireTime int64    // Unix纳秒时间戳，0表示永不过期
+	AccessTime int64    // 最后访问时间，用于LRU
+	Tags       []string // 标签，DeleteByTags按标签删除
+	Size       int64    // 估算的占用字节数
+	Frequency  uint32   // 访问次数（LFU淘汰使用，定期减半）
 	element    *list.Element
 }
 
@@ -25,35 +32,71 @@ func (item *MemoryCacheItem) IsExpired() bool {
 	return time.Now().UnixNano() > item.ExpireTime
 }
 
+// 淘汰策略
+const (
+	EvictionLRU = "lru" // 淘汰最近最少使用的项
+	EvictionLFU = "lfu" // 淘汰访问次数最少的项（近似：从随机抽样的项中选择），访问次数定期减半，不再访问的热点数据逐渐被淘汰
+)
+
+// lfuSamples LFU淘汰时抽样的缓存项数
+const lfuSamples = 5
+
+// itemOverhead 每个缓存项的固定开销估计（缓存项结构、链表节点和map条目）
+const itemOverhead = 128
+
+// MemoryCacheOptions 内存缓存选项
+type MemoryCacheOptions struct {
+	MaxItems int    // 最大缓存项数
+	MaxBytes int64  // 最大占用字节数（按键和值的大小估算），0表示不限制
+	Policy   string // 淘汰策略: lru（默认）, lfu
+}
+
 // MemoryCache 内存缓存实现
 type MemoryCache struct {
 	items    map[string]*MemoryCacheItem
 	lruList  *list.List
 	mutex    sync.RWMutex
 	maxSize  int
+	maxBytes int64
+	bytes    int64 // 当前估算的占用字节数
+	policy   string
 	stats    *MemoryCacheStats
 	stopChan chan struct{}
 }
 
 // MemoryCacheStats 内存缓存统计
 type MemoryCacheStats struct {
-	Hits        int64 `json:"hits"`
-	Misses      int64 `json:"misses"`
-	Sets        int64 `json:"sets"`
-	Deletes     int64 `json:"deletes"`
-	Evictions   int64 `json:"evictions"`
-	Expirations int64 `json:"expirations"`
-	Size        int64 `json:"size"`
-	MaxSize     int64 `json:"max_size"`
+	Hits        int64  `json:"hits"`
+	Misses      int64  `json:"misses"`
+	Sets        int64  `json:"sets"`
+	Deletes     int64  `json:"deletes"`
+	Evictions   int64  `json:"evictions"`
+	Expirations int64  `json:"expirations"`
+	Size        int64  `json:"size"`
+	MaxSize     int64  `json:"max_size"`
+	Bytes       int64  `json:"bytes"`     // 估算的占用字节数
+	MaxBytes    int64  `json:"max_bytes"` // 0表示不限制
+	Policy      string `json:"policy"`
 }
 
-// NewMemoryCache 创建内存缓存
+// NewMemoryCache 创建内存缓存（只限制缓存项数，LRU淘汰）
 func NewMemoryCache(maxSize int) *MemoryCache {
+	return NewMemoryCacheWithOptions(MemoryCacheOptions{MaxItems: maxSize})
+}
+
+// NewMemoryCacheWithOptions 按选项创建内存缓存
+func NewMemoryCacheWithOptions(opts MemoryCacheOptions) *MemoryCache {
+	policy := opts.Policy
+	if policy != EvictionLFU {
+		policy = EvictionLRU
+	}
 	mc := &MemoryCache{
 		items:    make(map[string]*MemoryCacheItem),
 		lruList:  list.New(),
-		maxSize:  maxSize,
-		stats:    &MemoryCacheStats{MaxSize: int64(maxSize)},
+		maxSize:  opts.MaxItems,
+		maxBytes: opts.MaxBytes,
+		policy:   policy,
+		stats:    &MemoryCacheStats{MaxSize: int64(opts.MaxItems), MaxBytes: opts.MaxBytes, Policy: policy},
 		stopChan: make(chan struct{}),
 	}
 	
@@ -67,27 +110,57 @@ func NewMemoryCache(maxSize int) *MemoryCache {
 func (mc *MemoryCache) Set(key string, value interface{}, expiration time.Duration) error {
 	mc.mutex.Lock()
 	defer mc.mutex.Unlock()
-	
+	return mc.set(key, value, expiration)
+}
+
+// MSet 批量设置键值对，在一次加锁内写入；超过字节上限的值不写入，其他值照常写入
+func (mc *MemoryCache) MSet(items map[string]interface{}, expiration time.Duration) error {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	var errs []error
+	for key, value := range items {
+		if err := mc.set(key, value, expiration); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// set 设置键值对，调用方持有写锁
+func (mc *MemoryCache) set(key string, value interface{}, expiration time.Duration) error {
 	now := time.Now().UnixNano()
 	var expireTime int64
 	if expiration > 0 {
 		expireTime = now + expiration.Nanoseconds()
 	}
 	
+	// 超过字节上限的值不缓存，同时删除旧值
+	size := estimateSize(key, value)
+	if mc.maxBytes > 0 && size > mc.maxBytes {
+		if existingItem, exists := mc.items[key]; exists {
+			mc.removeItem(existingItem)
+			mc.stats.Size = int64(len(mc.items))
+		}
+		return fmt.Errorf("value of %s (%d bytes) exceeds memory cache limit %d", key, size, mc.maxBytes)
+	}
+	
 	// 如果键已存在，更新值并移到前面
 	if existingItem, exists := mc.items[key]; exists {
+		mc.evictFor(size-existingItem.Size, existingItem)
+		mc.bytes += size - existingItem.Size
+		existingItem.Size = size
 		existingItem.Value = value
 		existingItem.ExpireTime = expireTime
 		existingItem.AccessTime = now
+		existingItem.Tags = nil
 		mc.lruList.MoveToFront(existingItem.element)
 		mc.stats.Sets++
 		return nil
 	}
 	
 	// 检查是否需要淘汰
-	if mc.lruList.Len() >= mc.maxSize {
-		mc.evictLRU()
-	}
+	mc.evictFor(size, nil)
 	
 	// 创建新项
 	item := &MemoryCacheItem{
@@ -95,11 +168,13 @@ func (mc *MemoryCache) Set(key string, value interface{}, expiration time.Durati
 		Value:      value,
 		ExpireTime: expireTime,
 		AccessTime: now,
+		Size:       size,
 	}
 	
 	// 添加到LRU列表前面
 	item.element = mc.lruList.PushFront(item)
 	mc.items[key] = item
+	mc.bytes += size
 	
 	mc.stats.Sets++
 	mc.stats.Size = int64(len(mc.items))
@@ -111,11 +186,34 @@ func (mc *MemoryCache) Set(key string, value interface{}, expiration time.Durati
 func (mc *MemoryCache) Get(key string) (string, error) {
 	mc.mutex.Lock()
 	defer mc.mutex.Unlock()
-	
+	return mc.get(key)
+}
+
+// MGet 批量获取值，在一次加锁内读取，返回的map只包含命中的键
+func (mc *MemoryCache) MGet(keys ...string) (map[string]string, error) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	values := make(map[string]string, len(keys))
+	for _, key := range keys {
+		value, err := mc.get(key)
+		if errors.Is(err, ErrMiss) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// get 获取值，调用方持有写锁（读取会更新访问时间和频率）
+func (mc *MemoryCache) get(key string) (string, error) {
 	item, exists := mc.items[key]
 	if !exists {
 		mc.stats.Misses++
-		return "", fmt.Errorf("key %s not found", key)
+		return "", fmt.Errorf("%w: %s", ErrMiss, key)
 	}
 	
 	// 检查是否过期
@@ -123,12 +221,15 @@ func (mc *MemoryCache) Get(key string) (string, error) {
 		mc.removeItem(item)
 		mc.stats.Misses++
 		mc.stats.Expirations++
-		return "", fmt.Errorf("key %s not found", key)
+		return "", fmt.Errorf("%w: %s", ErrMiss, key)
 	}
 	
 	// 更新访问时间并移到前面
 	item.AccessTime = time.Now().UnixNano()
 	mc.lruList.MoveToFront(item.element)
+	if item.Frequency < math.MaxUint32 {
+		item.Frequency++
+	}
 	
 	mc.stats.Hits++
 	
@@ -173,6 +274,62 @@ func (mc *MemoryCache) Delete(keys ...string) error {
 	return nil
 }
 
+// DeleteByPrefix 删除以prefix开头的键，返回删除的键数
+func (mc *MemoryCache) DeleteByPrefix(prefix string) int64 {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	var deleted int64
+	for key, item := range mc.items {
+		if strings.HasPrefix(key, prefix) {
+			mc.removeItem(item)
+			mc.stats.Deletes++
+			deleted++
+		}
+	}
+	mc.stats.Size = int64(len(mc.items))
+	return deleted
+}
+
+// Tag 为键添加标签，键不存在时返回ErrMiss
+func (mc *MemoryCache) Tag(key string, tags ...string) error {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	item, exists := mc.items[key]
+	if !exists || item.IsExpired() {
+		return fmt.Errorf("%w: %s", ErrMiss, key)
+	}
+	for _, tag := range tags {
+		if !slices.Contains(item.Tags, tag) {
+			item.Tags = append(item.Tags, tag)
+		}
+	}
+	return nil
+}
+
+// DeleteByTags 删除带有任一标签的键，返回删除的键数
+// 遍历所有缓存项，缓存大小受maxSize限制
+func (mc *MemoryCache) DeleteByTags(tags ...string) int64 {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	var deleted int64
+	for _, item := range mc.items {
+		for _, tag := range tags {
+			if slices.Contains(item.Tags, tag) {
+				mc.removeItem(item)
+				deleted++
+				break
+			}
+		}
+	}
+
+	mc.stats.Deletes += deleted
+	mc.stats.Size = int64(len(mc.items))
+	return deleted
+}
+
 // Exists 检查键是否存在
 func (mc *MemoryCache) Exists(key string) (bool, error) {
 	mc.mutex.RLock()
@@ -266,18 +423,19 @@ func (mc *MemoryCache) Increment(key string) (int64, error) {
 		mc.lruList.MoveToFront(existingItem.element)
 	} else {
 		// 检查是否需要淘汰
-		if mc.lruList.Len() >= mc.maxSize {
-			mc.evictLRU()
-		}
+		size := estimateSize(key, current)
+		mc.evictFor(size, nil)
 		
 		item := &MemoryCacheItem{
 			Key:        key,
 			Value:      current,
 			ExpireTime: 0,
 			AccessTime: now,
+			Size:       size,
 		}
 		item.element = mc.lruList.PushFront(item)
 		mc.items[key] = item
+		mc.bytes += size
 		mc.stats.Size = int64(len(mc.items))
 	}
 	
@@ -309,18 +467,19 @@ func (mc *MemoryCache) IncrementBy(key string, value int64) (int64, error) {
 		mc.lruList.MoveToFront(existingItem.element)
 	} else {
 		// 检查是否需要淘汰
-		if mc.lruList.Len() >= mc.maxSize {
-			mc.evictLRU()
-		}
+		size := estimateSize(key, current)
+		mc.evictFor(size, nil)
 		
 		item := &MemoryCacheItem{
 			Key:        key,
 			Value:      current,
 			ExpireTime: 0,
 			AccessTime: now,
+			Size:       size,
 		}
 		item.element = mc.lruList.PushFront(item)
 		mc.items[key] = item
+		mc.bytes += size
 		mc.stats.Size = int64(len(mc.items))
 	}
 	
@@ -334,6 +493,7 @@ func (mc *MemoryCache) GetStats() *MemoryCacheStats {
 	
 	stats := *mc.stats
 	stats.Size = int64(len(mc.items))
+	stats.Bytes = mc.bytes
 	return &stats
 }
 
@@ -344,6 +504,7 @@ func (mc *MemoryCache) Clear() {
 	
 	mc.items = make(map[string]*MemoryCacheItem)
 	mc.lruList = list.New()
+	mc.bytes = 0
 	mc.stats.Size = 0
 }
 
@@ -352,24 +513,77 @@ func (mc *MemoryCache) Close() {
 	close(mc.stopChan)
 }
 
-// evictLRU 淘汰最近最少使用的项
-func (mc *MemoryCache) evictLRU() {
-	if mc.lruList.Len() == 0 {
-		return
-	}
-	
-	// 获取最后一个元素（最少使用的）
-	element := mc.lruList.Back()
-	if element != nil {
-		item := element.Value.(*MemoryCacheItem)
-		mc.removeItem(item)
+// evictFor 为新增size字节的数据淘汰缓存项，直到缓存项数和字节数都在上限内
+// keep为正在更新的项（不增加缓存项数），不会被淘汰
+func (mc *MemoryCache) evictFor(size int64, keep *MemoryCacheItem) {
+	for {
+		overCount := keep == nil && mc.lruList.Len() >= mc.maxSize
+		overBytes := mc.maxBytes > 0 && mc.bytes+size > mc.maxBytes
+		if !overCount && !overBytes {
+			return
+		}
+		victim := mc.victim(keep)
+		if victim == nil {
+			return
+		}
+		mc.removeItem(victim)
 		mc.stats.Evictions++
 	}
 }
 
+// victim 按淘汰策略选择要淘汰的项，没有可淘汰的项时返回nil
+func (mc *MemoryCache) victim(keep *MemoryCacheItem) *MemoryCacheItem {
+	if mc.policy == EvictionLFU {
+		var victim *MemoryCacheItem
+		sampled := 0
+		for _, item := range mc.items {
+			if item == keep {
+				continue
+			}
+			if victim == nil || item.Frequency < victim.Frequency ||
+				(item.Frequency == victim.Frequency && item.AccessTime < victim.AccessTime) {
+				victim = item
+			}
+			if sampled++; sampled >= lfuSamples {
+				break
+			}
+		}
+		return victim
+	}
+
+	// 从最近最少使用的一端开始
+	for element := mc.lruList.Back(); element != nil; element = element.Prev() {
+		if item := element.Value.(*MemoryCacheItem); item != keep {
+			return item
+		}
+	}
+	return nil
+}
+
+// estimateSize 估算缓存项占用的字节数
+func estimateSize(key string, value interface{}) int64 {
+	size := int64(len(key) + itemOverhead)
+	switch v := value.(type) {
+	case string:
+		size += int64(len(v))
+	case []byte:
+		size += int64(len(v))
+	case int, int64, uint, uint64, float64:
+		size += 8
+	case bool:
+		size++
+	default:
+		if data, err := json.Marshal(v); err == nil {
+			size += int64(len(data))
+		}
+	}
+	return size
+}
+
 // removeItem 移除缓存项
 func (mc *MemoryCache) removeItem(item *MemoryCacheItem) {
 	delete(mc.items, item.Key)
+	mc.bytes -= item.Size
 	if item.element != nil {
 		mc.lruList.Remove(item.element)
 	}
@@ -398,6 +612,13 @@ func (mc *MemoryCache) cleanupExpired() {
 				}
 			}
 			
+			// LFU访问次数减半，过去的热点数据不再访问后逐渐被淘汰
+			if mc.policy == EvictionLFU {
+				for _, item := range mc.items {
+					item.Frequency /= 2
+				}
+			}
+			
 			mc.stats.Size = int64(len(mc.items))
 			mc.mutex.Unlock()
 			
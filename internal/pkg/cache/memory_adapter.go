@@ -1,39 +1,93 @@
 package cache
 
 import (
+	"fmt"
+	"math"
+	"sync"
 	"time"
 )
 
 // MemoryAdapter 内存缓存适配器
 type MemoryAdapter struct {
-	memory *MemoryCache
+	memory   *MemoryCache
+	bucketMu sync.Mutex // 令牌桶的读取和写回需要原子执行
+	ops      *opRecorder
 }
 
 // NewMemoryAdapter 创建内存缓存适配器
 func NewMemoryAdapter(maxSize int) *MemoryAdapter {
 	return &MemoryAdapter{
 		memory: NewMemoryCache(maxSize),
+		ops:    newOpRecorder("memory"),
+	}
+}
+
+// NewMemoryAdapterWithOptions 按选项创建内存缓存适配器（字节数上限、淘汰策略）
+func NewMemoryAdapterWithOptions(opts MemoryCacheOptions) *MemoryAdapter {
+	return &MemoryAdapter{
+		memory: NewMemoryCacheWithOptions(opts),
+		ops:    newOpRecorder("memory"),
 	}
 }
 
 // Set 设置键值对
 func (m *MemoryAdapter) Set(key string, value interface{}, expiration time.Duration) error {
-	return m.memory.Set(key, value, expiration)
+	start := time.Now()
+	err := m.memory.Set(key, value, expiration)
+	m.ops.write("set", start, err)
+	return err
 }
 
 // Get 获取值
 func (m *MemoryAdapter) Get(key string) (string, error) {
-	return m.memory.Get(key)
+	start := time.Now()
+	value, err := m.memory.Get(key)
+	m.ops.lookup("get", start, err)
+	return value, err
 }
 
 // GetJSON 获取JSON值并反序列化
 func (m *MemoryAdapter) GetJSON(key string, dest interface{}) error {
-	return m.memory.GetJSON(key, dest)
+	start := time.Now()
+	err := m.memory.GetJSON(key, dest)
+	m.ops.lookup("get", start, err)
+	return err
 }
 
 // Delete 删除键
 func (m *MemoryAdapter) Delete(keys ...string) error {
-	return m.memory.Delete(keys...)
+	start := time.Now()
+	err := m.memory.Delete(keys...)
+	m.ops.write("delete", start, err)
+	return err
+}
+
+// MGet 批量获取值，返回的map只包含命中的键
+func (m *MemoryAdapter) MGet(keys ...string) (map[string]string, error) {
+	start := time.Now()
+	values, err := m.memory.MGet(keys...)
+	if err != nil {
+		m.ops.read("mget", start, 0, 0, err)
+		return nil, err
+	}
+	m.ops.read("mget", start, len(values), len(keys)-len(values), nil)
+	return values, nil
+}
+
+// MSet 批量设置键值对
+func (m *MemoryAdapter) MSet(items map[string]interface{}, expiration time.Duration) error {
+	start := time.Now()
+	err := m.memory.MSet(items, expiration)
+	m.ops.write("mset", start, err)
+	return err
+}
+
+// DeleteByPrefix 删除以prefix开头的键，返回删除的键数
+func (m *MemoryAdapter) DeleteByPrefix(prefix string) (int64, error) {
+	start := time.Now()
+	deleted := m.memory.DeleteByPrefix(prefix)
+	m.ops.write("delete_prefix", start, nil)
+	return deleted, nil
 }
 
 // Exists 检查键是否存在
@@ -53,12 +107,61 @@ func (m *MemoryAdapter) TTL(key string) (time.Duration, error) {
 
 // Increment 原子递增
 func (m *MemoryAdapter) Increment(key string) (int64, error) {
-	return m.memory.Increment(key)
+	start := time.Now()
+	value, err := m.memory.Increment(key)
+	m.ops.write("incr", start, err)
+	return value, err
 }
 
 // IncrementBy 原子递增指定值
 func (m *MemoryAdapter) IncrementBy(key string, value int64) (int64, error) {
-	return m.memory.IncrementBy(key, value)
+	start := time.Now()
+	result, err := m.memory.IncrementBy(key, value)
+	m.ops.write("incr", start, err)
+	return result, err
+}
+
+// TakeToken 从令牌桶取一个令牌，桶状态以"令牌数 更新时间(UnixNano)"保存
+func (m *MemoryAdapter) TakeToken(key string, capacity int64, refillEvery time.Duration) (TokenBucketResult, error) {
+	m.bucketMu.Lock()
+	defer m.bucketMu.Unlock()
+
+	now := time.Now()
+	tokens := float64(capacity)
+	if state, err := m.memory.Get(key); err == nil {
+		var last int64
+		if _, err := fmt.Sscanf(state, "%g %d", &tokens, &last); err != nil {
+			tokens = float64(capacity)
+		} else if elapsed := now.Sub(time.Unix(0, last)); elapsed > 0 {
+			tokens = math.Min(float64(capacity), tokens+float64(elapsed)/float64(refillEvery))
+		}
+	}
+
+	result := TokenBucketResult{}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((1 - tokens) * float64(refillEvery)))
+	}
+	result.Remaining = int64(tokens)
+
+	// 桶补满后状态与不存在相同，过期时间为补满所需时间
+	ttl := time.Duration(capacity)*refillEvery + time.Second
+	if err := m.memory.Set(key, fmt.Sprintf("%g %d", tokens, now.UnixNano()), ttl); err != nil {
+		return TokenBucketResult{}, err
+	}
+	return result, nil
+}
+
+// Tag 为键添加标签，标签保存在缓存项上，随缓存项过期或淘汰
+func (m *MemoryAdapter) Tag(key string, expiration time.Duration, tags ...string) error {
+	return m.memory.Tag(key, tags...)
+}
+
+// InvalidateTags 删除带有任一标签的键
+func (m *MemoryAdapter) InvalidateTags(tags ...string) (int64, error) {
+	return m.memory.DeleteByTags(tags...), nil
 }
 
 // GetStats 获取统计信息
@@ -66,6 +169,11 @@ func (m *MemoryAdapter) GetStats() *MemoryCacheStats {
 	return m.memory.GetStats()
 }
 
+// OperationStats 获取读写操作统计
+func (m *MemoryAdapter) OperationStats() OperationStats {
+	return m.ops.stats()
+}
+
 // Clear 清空缓存
 func (m *MemoryAdapter) Clear() {
 	m.memory.Clear()
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"exchange/internal/pkg/metrics"
+)
+
+// 缓存操作结果
+const (
+	resultHit   = "hit"   // 读取命中
+	resultMiss  = "miss"  // 读取未命中
+	resultOK    = "ok"    // 写入、删除成功
+	resultError = "error" // 操作失败
+)
+
+// cacheLatencyBuckets 缓存操作耗时的桶上界(秒)，内存缓存在微秒级，Redis在毫秒级
+var cacheLatencyBuckets = []float64{0.00005, 0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+
+var (
+	cacheRequestsTotal = metrics.NewCounterVec("exchange_cache_requests_total",
+		"Cache operations by backend, operation and result (hit, miss, ok, error).", "backend", "op", "result")
+	cacheOperationSeconds = metrics.NewHistogramVec("exchange_cache_operation_duration_seconds",
+		"Cache operation latency by backend and operation.", cacheLatencyBuckets, "backend", "op")
+)
+
+// OperationStats 缓存适配器的操作统计（进程启动以来）
+type OperationStats struct {
+	Operations   int64   `json:"operations"`
+	Hits         int64   `json:"hits"`
+	Misses       int64   `json:"misses"`
+	Errors       int64   `json:"errors"`
+	HitRate      float64 `json:"hit_rate"`       // 命中数/(命中数+未命中数)
+	AvgLatencyMs float64 `json:"avg_latency_ms"` // 平均耗时(毫秒)
+}
+
+// opRecorder 记录适配器的读写、删除和递增操作，同时更新Prometheus指标
+type opRecorder struct {
+	backend string
+
+	operations atomic.Int64
+	hits       atomic.Int64
+	misses     atomic.Int64
+	errors     atomic.Int64
+	latencyNs  atomic.Int64
+}
+
+// newOpRecorder 创建操作记录，backend为指标的backend标签（memory、redis）
+func newOpRecorder(backend string) *opRecorder {
+	return &opRecorder{backend: backend}
+}
+
+// write 记录写入、删除等操作
+func (r *opRecorder) write(op string, start time.Time, err error) {
+	result := resultOK
+	if err != nil {
+		result = resultError
+		r.errors.Add(1)
+	}
+	cacheRequestsTotal.Inc(r.backend, op, result)
+	r.observe(op, start)
+}
+
+// lookup 记录单个键的读取
+func (r *opRecorder) lookup(op string, start time.Time, err error) {
+	hits := 0
+	if err == nil {
+		hits = 1
+	}
+	r.read(op, start, hits, 0, err)
+}
+
+// read 记录读取操作，hits和misses为命中和未命中的键数；未命中（ErrMiss）不计为错误
+func (r *opRecorder) read(op string, start time.Time, hits, misses int, err error) {
+	switch {
+	case errors.Is(err, ErrMiss):
+		misses++
+	case err != nil:
+		r.errors.Add(1)
+		cacheRequestsTotal.Inc(r.backend, op, resultError)
+	}
+	if hits > 0 {
+		r.hits.Add(int64(hits))
+		cacheRequestsTotal.Add(uint64(hits), r.backend, op, resultHit)
+	}
+	if misses > 0 {
+		r.misses.Add(int64(misses))
+		cacheRequestsTotal.Add(uint64(misses), r.backend, op, resultMiss)
+	}
+	r.observe(op, start)
+}
+
+// observe 记录耗时
+func (r *opRecorder) observe(op string, start time.Time) {
+	elapsed := time.Since(start)
+	r.operations.Add(1)
+	r.latencyNs.Add(elapsed.Nanoseconds())
+	cacheOperationSeconds.Observe(elapsed.Seconds(), r.backend, op)
+}
+
+// stats 获取操作统计
+func (r *opRecorder) stats() OperationStats {
+	stats := OperationStats{
+		Operations: r.operations.Load(),
+		Hits:       r.hits.Load(),
+		Misses:     r.misses.Load(),
+		Errors:     r.errors.Load(),
+	}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(lookups)
+	}
+	if stats.Operations > 0 {
+		stats.AvgLatencyMs = float64(r.latencyNs.Load()) / float64(stats.Operations) / 1e6
+	}
+	return stats
+}
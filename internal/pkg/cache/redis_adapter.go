@@ -1,41 +1,158 @@
 package cache
 
 import (
+	"context"
+	"fmt"
 	"time"
 
+	"github.com/redis/go-redis/v9"
+
 	"exchange/internal/pkg/database"
 )
 
+// takeTokenScript 令牌桶取令牌，使用Redis服务器时间，多实例之间没有时钟偏差
+// ARGV: 桶容量、补充一个令牌的间隔(微秒)；返回 {是否取到, 剩余令牌数, 需等待的微秒数}
+var takeTokenScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) / interval)
+
+local allowed, wait = 0, 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) * interval)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', string.format('%.6f', tokens), 'ts', string.format('%.0f', now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity * interval / 1000) + 1000)
+return {allowed, math.floor(tokens), wait}
+`)
+
+// tagKeyPrefix 标签索引集合的键前缀，集合成员为带有该标签的键
+const tagKeyPrefix = "redis:tag:"
+
+// tagScript 将键加入标签集合，集合的过期时间延长到不早于键的过期时间
+// ARGV: 键的过期时间(毫秒，0为不过期)、键
+var tagScript = redis.NewScript(`
+local existed = redis.call('EXISTS', KEYS[1])
+redis.call('SADD', KEYS[1], ARGV[2])
+local ttl = tonumber(ARGV[1])
+if ttl == 0 then
+	redis.call('PERSIST', KEYS[1])
+else
+	local current = redis.call('PTTL', KEYS[1])
+	if existed == 0 or (current >= 0 and current < ttl) then
+		redis.call('PEXPIRE', KEYS[1], ttl)
+	end
+end
+return 1
+`)
+
+// acquireLockScript SET NX PX获取锁，成功时递增锁的fencing计数器并返回新值，失败时返回0
+// KEYS: 锁、fencing计数器（同一哈希槽，不过期，保证重新获取时仍然递增）；ARGV: 持有者令牌、过期时间(毫秒)
+var acquireLockScript = redis.NewScript(`
+if not redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return 0
+end
+return redis.call('INCR', KEYS[2])
+`)
+
+// releaseLockScript 锁的值等于持有者令牌时删除，返回删除的键数
+var releaseLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// extendLockScript 锁的值等于持有者令牌时重设过期时间(毫秒)
+var extendLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
 // RedisAdapter Redis缓存适配器
 type RedisAdapter struct {
 	redis *database.RedisService
+	ops   *opRecorder
 }
 
 // NewRedisAdapter 创建Redis适配器
 func NewRedisAdapter(redis *database.RedisService) *RedisAdapter {
 	return &RedisAdapter{
 		redis: redis,
+		ops:   newOpRecorder("redis"),
 	}
 }
 
 // Set 设置键值对
 func (r *RedisAdapter) Set(key string, value interface{}, expiration time.Duration) error {
-	return r.redis.Set(key, value, expiration)
+	start := time.Now()
+	err := r.redis.Set(key, value, expiration)
+	r.ops.write("set", start, err)
+	return err
 }
 
 // Get 获取值
 func (r *RedisAdapter) Get(key string) (string, error) {
-	return r.redis.Get(key)
+	start := time.Now()
+	value, err := r.redis.Get(key)
+	r.ops.lookup("get", start, err)
+	return value, err
 }
 
 // GetJSON 获取JSON值并反序列化
 func (r *RedisAdapter) GetJSON(key string, dest interface{}) error {
-	return r.redis.GetJSON(key, dest)
+	start := time.Now()
+	err := r.redis.GetJSON(key, dest)
+	r.ops.lookup("get", start, err)
+	return err
 }
 
 // Delete 删除键
 func (r *RedisAdapter) Delete(keys ...string) error {
-	return r.redis.Delete(keys...)
+	start := time.Now()
+	err := r.redis.Delete(keys...)
+	r.ops.write("delete", start, err)
+	return err
+}
+
+// MGet 批量获取值，返回的map只包含命中的键
+func (r *RedisAdapter) MGet(keys ...string) (map[string]string, error) {
+	start := time.Now()
+	values, err := r.redis.MGet(keys...)
+	if err != nil {
+		r.ops.read("mget", start, 0, 0, err)
+		return nil, err
+	}
+	r.ops.read("mget", start, len(values), len(keys)-len(values), nil)
+	return values, nil
+}
+
+// MSet 批量设置键值对
+func (r *RedisAdapter) MSet(items map[string]interface{}, expiration time.Duration) error {
+	start := time.Now()
+	err := r.redis.MSet(items, expiration)
+	r.ops.write("mset", start, err)
+	return err
+}
+
+// DeleteByPrefix 删除以prefix开头的键，返回删除的键数
+func (r *RedisAdapter) DeleteByPrefix(prefix string) (int64, error) {
+	start := time.Now()
+	deleted, err := r.redis.DeleteByPrefix(prefix)
+	r.ops.write("delete_prefix", start, err)
+	return deleted, err
 }
 
 // Exists 检查键是否存在
@@ -55,10 +172,96 @@ func (r *RedisAdapter) TTL(key string) (time.Duration, error) {
 
 // Increment 原子递增
 func (r *RedisAdapter) Increment(key string) (int64, error) {
-	return r.redis.Increment(key)
+	start := time.Now()
+	value, err := r.redis.Increment(key)
+	r.ops.write("incr", start, err)
+	return value, err
 }
 
 // IncrementBy 原子递增指定值
 func (r *RedisAdapter) IncrementBy(key string, value int64) (int64, error) {
-	return r.redis.IncrementBy(key, value)
-}
\ No newline at end of file
+	start := time.Now()
+	result, err := r.redis.IncrementBy(key, value)
+	r.ops.write("incr", start, err)
+	return result, err
+}
+
+// Tag 将键加入各标签的索引集合（redis:tag:<标签>）
+func (r *RedisAdapter) Tag(key string, expiration time.Duration, tags ...string) error {
+	ctx := context.Background()
+	for _, tag := range tags {
+		if err := tagScript.Run(ctx, r.redis.Client(), []string{tagKeyPrefix + tag}, expiration.Milliseconds(), key).Err(); err != nil {
+			return fmt.Errorf("failed to tag key %s with %s: %w", key, tag, err)
+		}
+	}
+	return nil
+}
+
+// InvalidateTags 删除各标签索引集合中的键，返回删除的键数（包括已过期的键）
+// 删除后只从集合中移除已删除的成员，期间新加入的键保留在集合中
+func (r *RedisAdapter) InvalidateTags(tags ...string) (int64, error) {
+	ctx := context.Background()
+	var total int64
+	for _, tag := range tags {
+		tagKey := tagKeyPrefix + tag
+		keys, err := r.redis.Client().SMembers(ctx, tagKey).Result()
+		if err != nil {
+			return total, fmt.Errorf("failed to read tag %s: %w", tag, err)
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		if err := r.redis.Delete(keys...); err != nil {
+			return total, err
+		}
+		members := make([]interface{}, len(keys))
+		for i, key := range keys {
+			members[i] = key
+		}
+		if err := r.redis.Client().SRem(ctx, tagKey, members...).Err(); err != nil {
+			return total, fmt.Errorf("failed to update tag %s: %w", tag, err)
+		}
+		total += int64(len(keys))
+	}
+	return total, nil
+}
+
+// TakeToken 从令牌桶取一个令牌，读取、补充和扣减在Lua脚本中原子执行
+func (r *RedisAdapter) TakeToken(key string, capacity int64, refillEvery time.Duration) (TokenBucketResult, error) {
+	result, err := takeTokenScript.Run(context.Background(), r.redis.Client(), []string{key}, capacity, refillEvery.Microseconds()).Int64Slice()
+	if err != nil {
+		return TokenBucketResult{}, err
+	}
+	return TokenBucketResult{
+		Allowed:    result[0] == 1,
+		Remaining:  result[1],
+		RetryAfter: time.Duration(result[2]) * time.Microsecond,
+	}, nil
+}
+
+// TryLock 以token获取锁，成功时返回递增的fencing token，锁已被持有时返回0
+func (r *RedisAdapter) TryLock(ctx context.Context, key, token string, ttl time.Duration) (int64, error) {
+	return acquireLockScript.Run(ctx, r.redis.Client(), []string{key, key + ":fence"}, token, ttl.Milliseconds()).Int64()
+}
+
+// Unlock 锁仍由token持有时释放
+func (r *RedisAdapter) Unlock(ctx context.Context, key, token string) (bool, error) {
+	n, err := releaseLockScript.Run(ctx, r.redis.Client(), []string{key}, token).Int64()
+	return n == 1, err
+}
+
+// ExtendLock 锁仍由token持有时重设过期时间
+func (r *RedisAdapter) ExtendLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	n, err := extendLockScript.Run(ctx, r.redis.Client(), []string{key}, token, ttl.Milliseconds()).Int64()
+	return n == 1, err
+}
+
+// OperationStats 获取读写操作统计
+func (r *RedisAdapter) OperationStats() OperationStats {
+	return r.ops.stats()
+}
+
+// PoolStats 获取连接池统计
+func (r *RedisAdapter) PoolStats() *redis.PoolStats {
+	return r.redis.Client().PoolStats()
+}
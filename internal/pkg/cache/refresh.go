@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RefreshLoader 按完整的键重新加载数据，数据不存在时返回ErrNotFound
+type RefreshLoader func(ctx context.Context, key string) (interface{}, error)
+
+// RefreshAhead 提前刷新配置
+type RefreshAhead struct {
+	TTL    time.Duration // 刷新和GetRefreshed加载时写入缓存的过期时间
+	Window time.Duration // 剩余有效期少于Window时在后台刷新，为0时取TTL的1/5
+	Loader RefreshLoader
+}
+
+// refresher 一个键前缀的提前刷新
+type refresher struct {
+	cacheType CacheType
+	prefix    string
+	opts      RefreshAhead
+
+	mu   sync.Mutex
+	keys map[string][]string // 已加载的键及其标签，后台定期检查
+}
+
+// RegisterRefreshAhead 为cacheType中以prefix开头的键注册提前刷新
+// 注册后这些键通过GetOrLoad（及GetConfigOrLoad等）或GetRefreshed读取时：命中且剩余有效期少于Window的在后台刷新，读取不等待；
+// 已加载过的键由后台goroutine每Window/2检查一次，即将过期或已被淘汰的键同样在后台重新加载，长时间没有读取的键也不会冷启动。
+// 刷新失败时保留旧值并记录日志，数据不存在（ErrNotFound）时写入空值标记并停止跟踪该键。
+// 后台goroutine在进程生命周期内运行
+func (cm *CacheManager) RegisterRefreshAhead(cacheType CacheType, prefix string, opts RefreshAhead) error {
+	if opts.Loader == nil {
+		return fmt.Errorf("refresh-ahead loader is required")
+	}
+	if opts.TTL <= 0 {
+		return fmt.Errorf("refresh-ahead TTL must be positive")
+	}
+	if opts.Window <= 0 {
+		opts.Window = opts.TTL / 5
+	}
+	if opts.Window >= opts.TTL {
+		return fmt.Errorf("refresh-ahead window %s must be shorter than TTL %s", opts.Window, opts.TTL)
+	}
+
+	r := &refresher{
+		cacheType: cacheType,
+		prefix:    prefix,
+		opts:      opts,
+		keys:      make(map[string][]string),
+	}
+	cm.refreshMu.Lock()
+	cm.refreshers = append(cm.refreshers, r)
+	cm.refreshMu.Unlock()
+
+	go cm.runRefresher(r)
+	return nil
+}
+
+// GetRefreshed 读取注册了提前刷新的键，未命中时使用注册的loader加载
+func (cm *CacheManager) GetRefreshed(ctx context.Context, cacheType CacheType, key string, dest interface{}) error {
+	r := cm.refresherFor(cacheType, key)
+	if r == nil {
+		return fmt.Errorf("no refresh-ahead loader registered for %s", key)
+	}
+	return cm.GetOrLoad(cacheType, key, r.opts.TTL, dest, func() (interface{}, error) {
+		return r.opts.Loader(ctx, key)
+	})
+}
+
+// refresherFor 键所属的提前刷新，没有时返回nil
+func (cm *CacheManager) refresherFor(cacheType CacheType, key string) *refresher {
+	cm.refreshMu.RLock()
+	defer cm.refreshMu.RUnlock()
+	for _, r := range cm.refreshers {
+		if r.cacheType == cacheType && strings.HasPrefix(key, r.prefix) {
+			return r
+		}
+	}
+	return nil
+}
+
+// track 记录已加载的键，由后台goroutine定期检查
+func (r *refresher) track(key string, tags []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[key] = tags
+}
+
+// untrack 停止跟踪键
+func (r *refresher) untrack(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.keys, key)
+}
+
+// refreshIfExpiring 键剩余有效期少于Window或已不存在时在后台刷新，永不过期（-1）的键不刷新
+func (cm *CacheManager) refreshIfExpiring(r *refresher, key string, tags []string) {
+	r.track(key, tags)
+	ttl, err := cm.getCache(r.cacheType).TTL(key)
+	if err == nil && (ttl == -1 || ttl > r.opts.Window) {
+		return
+	}
+	cm.refresh(r, key, tags)
+}
+
+// runRefresher 定期刷新即将过期或已被淘汰的键
+func (cm *CacheManager) runRefresher(r *refresher) {
+	ticker := time.NewTicker(max(r.opts.Window/2, time.Second))
+	defer ticker.Stop()
+
+	for range ticker.C {
+		r.mu.Lock()
+		keys := make(map[string][]string, len(r.keys))
+		for key, tags := range r.keys {
+			keys[key] = tags
+		}
+		r.mu.Unlock()
+
+		for key, tags := range keys {
+			cm.refreshIfExpiring(r, key, tags)
+		}
+	}
+}
+
+// refresh 在后台重新加载键，与GetOrLoad共用singleflight，同一键同时只有一次加载
+func (cm *CacheManager) refresh(r *refresher, key string, tags []string) {
+	cache := cm.getCache(r.cacheType)
+	codec := cm.codecFor(key)
+	flightKey := strconv.Itoa(int(r.cacheType)) + ":" + key
+
+	// DoChan在新的goroutine中执行加载，结果通道有缓冲，不读取也不会阻塞
+	cm.loads.DoChan(flightKey, func() (interface{}, error) {
+		value, err := r.opts.Loader(context.Background(), key)
+		if errors.Is(err, ErrNotFound) {
+			r.untrack(key)
+			if negativeTTL := cm.negativeTTL; negativeTTL > 0 {
+				cm.storeLoaded(cache, key, negativeValue, negativeTTL, tags)
+			}
+			return nil, err
+		}
+		if err != nil {
+			cacheLogger.Warn("提前刷新缓存失败，保留旧值", map[string]interface{}{
+				"key":   key,
+				"error": err.Error(),
+			})
+			return nil, err
+		}
+
+		data, err := codec.Marshal(value)
+		if err != nil {
+			cacheLogger.Warn("提前刷新缓存失败，保留旧值", map[string]interface{}{
+				"key":   key,
+				"error": err.Error(),
+			})
+			return nil, fmt.Errorf("failed to marshal loaded value: %w", err)
+		}
+		cm.storeLoaded(cache, key, data, r.opts.TTL, tags)
+		return data, nil
+	})
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// UserTag 用户相关缓存的标签，ClearUserCache按该标签清除
+func UserTag(userID string) string {
+	return "user:" + userID
+}
+
+// SetTagged 写入缓存并添加标签，之后可通过InvalidateTags一次删除带有同一标签的所有键
+// 缓存不支持标签（TaggableCache）时只写入值
+// expiration按键前缀的过期策略处理（DefaultTTL和随机浮动，见TTLFor）
+func (cm *CacheManager) SetTagged(cacheType CacheType, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	return cm.setTagged(cacheType, key, value, cm.TTLFor(key, expiration), tags...)
+}
+
+// setTagged 以已确定的过期时间写入缓存并添加标签
+func (cm *CacheManager) setTagged(cacheType CacheType, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	cache := cm.getCache(cacheType)
+	if err := cache.Set(key, value, expiration); err != nil {
+		return err
+	}
+	return cm.tag(cache, key, expiration, tags...)
+}
+
+// InvalidateTags 删除内存和Redis中带有任一标签的键，返回删除的键数
+// 内存缓存只清除本实例
+func (cm *CacheManager) InvalidateTags(tags ...string) (int64, error) {
+	var total int64
+	for _, cache := range []Cache{cm.memoryCache, cm.redisCache} {
+		taggable, ok := cache.(TaggableCache)
+		if !ok {
+			continue
+		}
+		deleted, err := taggable.InvalidateTags(tags...)
+		total += deleted
+		if err != nil {
+			return total, fmt.Errorf("failed to invalidate tags: %w", err)
+		}
+	}
+	return total, nil
+}
+
+// tag 为已写入的键添加标签
+func (cm *CacheManager) tag(cache Cache, key string, expiration time.Duration, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	if taggable, ok := cache.(TaggableCache); ok {
+		return taggable.Tag(key, expiration, tags...)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultTTL 使用键前缀的默认过期时间，作为expiration参数传入
+// 没有匹配的前缀策略时使用fallbackTTL
+const DefaultTTL time.Duration = -1
+
+// fallbackTTL 没有匹配的前缀策略时DefaultTTL对应的过期时间
+const fallbackTTL = 30 * time.Minute
+
+// TTLPolicy 键前缀的过期策略
+type TTLPolicy struct {
+	TTL time.Duration // 传入DefaultTTL时使用的过期时间
+	// Jitter 过期时间的随机浮动比例（0~1），如0.1表示在±10%内随机，避免同时写入的大量键同时过期、同时回源
+	// 显式传入的过期时间同样浮动；0表示不浮动（限流窗口等需要精确过期的键）
+	Jitter float64
+}
+
+// defaultTTLPolicies 内置的前缀策略，可通过配置cache.ttl_policies覆盖
+var defaultTTLPolicies = map[string]TTLPolicy{
+	MemoryUserInfoPrefix:    {TTL: 30 * time.Minute, Jitter: 0.1},
+	MemoryConfigPrefix:      {TTL: 10 * time.Minute, Jitter: 0.1},
+	MemoryTempPrefix:        {TTL: 5 * time.Minute, Jitter: 0.1},
+	MemoryOnlineUsersPrefix: {TTL: 24 * time.Hour, Jitter: 0.05},
+	RedisUserSessionPrefix:  {TTL: 24 * time.Hour},
+	RedisRateLimitPrefix:    {TTL: time.Minute},
+	RedisNotificationPrefix: {TTL: 7 * 24 * time.Hour, Jitter: 0.05},
+}
+
+// prefixTTLPolicy 键前缀的过期策略
+type prefixTTLPolicy struct {
+	prefix string
+	policy TTLPolicy
+}
+
+// SetTTLPolicy 设置键前缀的过期策略，多个前缀匹配时使用最长的前缀
+func (cm *CacheManager) SetTTLPolicy(prefix string, policy TTLPolicy) {
+	cm.ttlMu.Lock()
+	defer cm.ttlMu.Unlock()
+	for i := range cm.ttlPolicies {
+		if cm.ttlPolicies[i].prefix == prefix {
+			cm.ttlPolicies[i].policy = policy
+			return
+		}
+	}
+	cm.ttlPolicies = append(cm.ttlPolicies, prefixTTLPolicy{prefix: prefix, policy: policy})
+	sort.Slice(cm.ttlPolicies, func(i, j int) bool {
+		return len(cm.ttlPolicies[i].prefix) > len(cm.ttlPolicies[j].prefix)
+	})
+}
+
+// TTLFor 键实际使用的过期时间：DefaultTTL替换为前缀的默认过期时间，再按前缀的浮动比例随机调整；0（不过期）不变
+func (cm *CacheManager) TTLFor(key string, expiration time.Duration) time.Duration {
+	if expiration == 0 {
+		return 0
+	}
+
+	policy, ok := cm.ttlPolicy(key)
+	if expiration == DefaultTTL {
+		expiration = fallbackTTL
+		if ok {
+			expiration = policy.TTL
+		}
+	}
+	if !ok || policy.Jitter <= 0 {
+		return expiration
+	}
+
+	delta := time.Duration((rand.Float64()*2 - 1) * policy.Jitter * float64(expiration))
+	return max(expiration+delta, time.Millisecond)
+}
+
+// ttlPolicy 键匹配的最长前缀的过期策略
+func (cm *CacheManager) ttlPolicy(key string) (TTLPolicy, bool) {
+	cm.ttlMu.RLock()
+	defer cm.ttlMu.RUnlock()
+	for _, p := range cm.ttlPolicies {
+		if strings.HasPrefix(key, p.prefix) {
+			return p.policy, true
+		}
+	}
+	return TTLPolicy{}, false
+}
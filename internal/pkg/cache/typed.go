@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Typed 类型安全的缓存访问，键为prefix+key，值默认以JSON保存
+// 整数也是合法的JSON，Typed[int64]可以直接读取Increment写入的计数器
+type Typed[T any] struct {
+	cache  Cache
+	prefix string
+	codec  Codec
+}
+
+// NewTyped 创建类型安全的缓存访问
+func NewTyped[T any](cache Cache, prefix string) Typed[T] {
+	return Typed[T]{
+		cache:  cache,
+		prefix: prefix,
+		codec:  JSONCodec,
+	}
+}
+
+// WithCodec 使用指定的序列化方式
+func (t Typed[T]) WithCodec(codec Codec) Typed[T] {
+	t.codec = codec
+	return t
+}
+
+// Get 读取值，未命中时返回ok=false和nil错误；缓存中的值无法解析为T时返回错误
+func (t Typed[T]) Get(ctx context.Context, key string) (value T, ok bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return value, false, err
+	}
+
+	data, err := t.cache.Get(t.prefix + key)
+	if errors.Is(err, ErrMiss) {
+		return value, false, nil
+	}
+	if err != nil {
+		return value, false, err
+	}
+	if err := t.codec.Unmarshal([]byte(data), &value); err != nil {
+		return value, false, fmt.Errorf("failed to decode cached value %s: %w", t.prefix+key, err)
+	}
+	return value, true, nil
+}
+
+// Set 写入值，expiration为0时不过期
+func (t Typed[T]) Set(ctx context.Context, key string, value T, expiration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := t.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache value %s: %w", t.prefix+key, err)
+	}
+	return t.cache.Set(t.prefix+key, data, expiration)
+}
+
+// Delete 删除键
+func (t Typed[T]) Delete(ctx context.Context, keys ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	fullKeys := make([]string, len(keys))
+	for i, key := range keys {
+		fullKeys[i] = t.prefix + key
+	}
+	return t.cache.Delete(fullKeys...)
+}
+
+// TypedGetOrLoad 类型安全的GetOrLoad，未命中时调用loader加载（并发未命中只加载一次）
+// loader返回ErrNotFound时缓存空结果，之后在空值标记有效期内返回ErrNotFound
+func TypedGetOrLoad[T any](cm *CacheManager, cacheType CacheType, key string, ttl time.Duration, loader func() (T, error)) (T, error) {
+	var value T
+	err := cm.GetOrLoad(cacheType, key, ttl, &value, func() (interface{}, error) {
+		return loader()
+	})
+	return value, err
+}
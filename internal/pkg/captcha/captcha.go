@@ -0,0 +1,70 @@
+// Package captcha 人机验证
+// 校验服务由配置captcha.provider决定：hcaptcha、recaptcha（v2和v3）、turnstile（Cloudflare）使用各自的siteverify接口，
+// 其他服务通过RegisterProvider注册后在配置中按名称选用；需要验证的时机（失败次数统计）见FailureCounter
+package captcha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/secrets"
+)
+
+// 需要人机验证的操作，按操作分别统计失败次数
+const (
+	ScopeLogin         = "login"
+	ScopeRegister      = "register"
+	ScopePasswordReset = "password_reset"
+)
+
+var (
+	// ErrInvalidToken 验证token无效、已使用、已过期或分数过低
+	ErrInvalidToken = errors.New("invalid captcha token")
+	// ErrUnavailable 校验服务不可用（网络错误、密钥缺失或服务返回非预期的响应）
+	ErrUnavailable = errors.New("captcha service unavailable")
+)
+
+// Verifier 人机验证校验接口
+type Verifier interface {
+	// Verify 校验前端验证组件返回的token，remoteIP为客户端IP；未通过时返回ErrInvalidToken
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// Factory 根据配置创建校验服务，密钥从密钥提供者读取
+type Factory func(cfg config.CaptchaConfig, provider secrets.Provider) (Verifier, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Factory{
+		"hcaptcha": func(cfg config.CaptchaConfig, provider secrets.Provider) (Verifier, error) {
+			return NewSiteVerifier(hcaptchaVerifyURL, cfg, provider), nil
+		},
+		"recaptcha": func(cfg config.CaptchaConfig, provider secrets.Provider) (Verifier, error) {
+			return NewSiteVerifier(recaptchaVerifyURL, cfg, provider), nil
+		},
+		"turnstile": func(cfg config.CaptchaConfig, provider secrets.Provider) (Verifier, error) {
+			return NewSiteVerifier(turnstileVerifyURL, cfg, provider), nil
+		},
+	}
+)
+
+// RegisterProvider 注册校验服务，名称已存在时覆盖
+func RegisterProvider(name string, factory Factory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = factory
+}
+
+// NewVerifier 按配置创建校验服务
+func NewVerifier(cfg config.CaptchaConfig, provider secrets.Provider) (Verifier, error) {
+	providersMu.RLock()
+	factory, ok := providers[cfg.Provider]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported captcha provider: %s", cfg.Provider)
+	}
+	return factory(cfg, provider)
+}
@@ -0,0 +1,71 @@
+package captcha
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/database"
+)
+
+const failureKeyPrefix = "captcha:fail:" // 失败次数，键为"操作:客户端IP"，第一次失败时设置统计窗口
+
+// failScript 失败次数加1，第一次失败时设置统计窗口
+// KEYS: 次数键；ARGV: 窗口(毫秒)
+var failScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// FailureCounter 按操作和客户端IP统计失败次数，保存在Redis中，多实例共享
+type FailureCounter struct {
+	redis     *database.RedisService
+	threshold int
+	window    time.Duration
+}
+
+// NewFailureCounter 创建失败次数统计
+func NewFailureCounter(redis *database.RedisService, cfg config.CaptchaConfig) *FailureCounter {
+	return &FailureCounter{
+		redis:     redis,
+		threshold: cfg.FailureThreshold,
+		window:    time.Duration(cfg.WindowMinutes) * time.Minute,
+	}
+}
+
+// Required 是否需要人机验证：失败次数阈值为0时始终需要，否则窗口内失败次数达到阈值时需要
+func (f *FailureCounter) Required(ctx context.Context, scope, clientIP string) (bool, error) {
+	if f.threshold <= 0 {
+		return true, nil
+	}
+	count, err := f.redis.Client().Get(ctx, failureKey(scope, clientIP)).Int()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get captcha failures: %w", err)
+	}
+	return count >= f.threshold, nil
+}
+
+// Fail 记录一次失败
+func (f *FailureCounter) Fail(ctx context.Context, scope, clientIP string) error {
+	if f.threshold <= 0 {
+		return nil
+	}
+	if err := failScript.Run(ctx, f.redis.Client(), []string{failureKey(scope, clientIP)}, f.window.Milliseconds()).Err(); err != nil {
+		return fmt.Errorf("failed to record captcha failure: %w", err)
+	}
+	return nil
+}
+
+// failureKey 失败次数的键
+func failureKey(scope, clientIP string) string {
+	return failureKeyPrefix + scope + ":" + clientIP
+}
@@ -0,0 +1,97 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/secrets"
+)
+
+const (
+	hcaptchaVerifyURL  = "https://api.hcaptcha.com/siteverify"
+	recaptchaVerifyURL = "https://www.google.com/recaptcha/api/siteverify"
+	turnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+
+	// maxResponseBytes 校验服务响应的最大读取字节数
+	maxResponseBytes = 64 << 10
+)
+
+// siteVerifyResponse siteverify接口的响应（hCaptcha、reCAPTCHA和Turnstile格式相同）
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	Score      *float64 `json:"score"` // 只有reCAPTCHA v3（及hCaptcha企业版）返回
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// SiteVerifier 通过siteverify接口校验token
+// 密钥在每次校验时从密钥提供者读取，密钥轮换后无需重启
+type SiteVerifier struct {
+	endpoint string
+	cfg      config.CaptchaConfig
+	provider secrets.Provider
+	client   *http.Client
+}
+
+// NewSiteVerifier 创建siteverify校验，endpoint为校验服务的siteverify地址
+func NewSiteVerifier(endpoint string, cfg config.CaptchaConfig, provider secrets.Provider) *SiteVerifier {
+	return &SiteVerifier{
+		endpoint: endpoint,
+		cfg:      cfg,
+		provider: provider,
+		client:   &http.Client{Timeout: time.Duration(cfg.TimeoutMs) * time.Millisecond},
+	}
+}
+
+// Verify 校验token，返回分数时低于min_score视为未通过
+func (v *SiteVerifier) Verify(ctx context.Context, token, remoteIP string) error {
+	if token == "" {
+		return ErrInvalidToken
+	}
+
+	secret, err := v.provider.Get(ctx, v.cfg.SecretName)
+	if err != nil {
+		return fmt.Errorf("%w: failed to load secret: %v", ErrUnavailable, err)
+	}
+
+	form := url.Values{}
+	form.Set("secret", secret)
+	form.Set("response", token)
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	var result siteVerifyResponse
+	if resp.StatusCode != http.StatusOK || json.Unmarshal(body, &result) != nil {
+		return fmt.Errorf("%w: unexpected response (status %d)", ErrUnavailable, resp.StatusCode)
+	}
+
+	if !result.Success {
+		return fmt.Errorf("%w: %s", ErrInvalidToken, strings.Join(result.ErrorCodes, ","))
+	}
+	if result.Score != nil && *result.Score < v.cfg.MinScore {
+		return fmt.Errorf("%w: score %.2f below %.2f", ErrInvalidToken, *result.Score, v.cfg.MinScore)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Package clock 业务时间
+// 业务逻辑（令牌签发与过期、注销冷静期、数据保留截止时间、记录时间戳等）通过Now获取当前时间，
+// 预发环境启用模拟时钟后整体偏移，无需等待真实时间即可测试到期相关逻辑。
+// 耗时统计、缓存过期、服务间签名等与外部系统交互的时间仍使用time.Now
+package clock
+
+import (
+	"sync/atomic"
+	"time"
+
+	"exchange/internal/pkg/config"
+	appLogger "exchange/internal/pkg/logger"
+)
+
+// offset 模拟时钟相对真实时间的偏移（纳秒）
+var offset atomic.Int64
+
+// Now 当前业务时间
+func Now() time.Time {
+	return time.Now().Add(Offset())
+}
+
+// Since 业务时间自t起经过的时长
+func Since(t time.Time) time.Duration {
+	return Now().Sub(t)
+}
+
+// Until 业务时间距t的时长
+func Until(t time.Time) time.Duration {
+	return t.Sub(Now())
+}
+
+// Offset 当前偏移，未启用模拟时钟时为0
+func Offset() time.Duration {
+	return time.Duration(offset.Load())
+}
+
+// SetOffset 设置偏移
+func SetOffset(d time.Duration) {
+	offset.Store(int64(d))
+}
+
+// Configure 按配置设置模拟时钟，未启用时偏移为0
+func Configure(cfg config.ClockConfig) error {
+	if !cfg.Simulated {
+		SetOffset(0)
+		return nil
+	}
+
+	d, err := cfg.GetOffset()
+	if err != nil {
+		return err
+	}
+	SetOffset(d)
+
+	appLogger.Warn("模拟时钟已启用，业务时间与真实时间存在偏移", map[string]interface{}{
+		"offset":       d.String(),
+		"virtual_time": Now().Format(time.RFC3339),
+	})
+	return nil
+}
@@ -3,27 +3,109 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net/netip"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
 )
 
 // Config 应用程序配置
 type Config struct {
-	Server   ServerConfig   `json:"server"`
-	Database DatabaseConfig `json:"database"`
-	Redis    RedisConfig    `json:"redis"`
-	MongoDB  MongoConfig    `json:"mongodb"`
-	JWT      JWTConfig      `json:"jwt"`
-	Log      LogConfig      `json:"log"`
+	Server         ServerConfig               `json:"server"`
+	Database       DatabaseConfig             `json:"database"`
+	Redis          RedisConfig                `json:"redis"`
+	Cache          CacheConfig                `json:"cache"`
+	MongoDB        MongoConfig                `json:"mongodb"`
+	JWT            JWTConfig                  `json:"jwt"`
+	TwoFactor      TwoFactorConfig            `json:"two_factor"`
+	LoginThrottle  LoginThrottleConfig        `json:"login_throttle"`
+	AdminGuard     AdminGuardConfig           `json:"admin_guard"`
+	IPAccess       IPAccessConfig             `json:"ip_access"`
+	RBAC           RBACConfig                 `json:"rbac"`
+	Maintenance    MaintenanceConfig          `json:"maintenance"`
+	RateLimit      RateLimitConfig            `json:"rate_limit"`
+	Concurrency    ConcurrencyLimitConfig     `json:"concurrency_limit"`
+	CircuitBreaker CircuitBreakerConfig       `json:"circuit_breaker"`
+	RequestTimeout RequestTimeoutConfig       `json:"request_timeout"`
+	Log            LogConfig                  `json:"log"`
+	Monitor        MonitorConfig              `json:"monitor"`
+	Metrics        MetricsConfig              `json:"metrics"`
+	Tracing        TracingConfig              `json:"tracing"`
+	ErrorCatalog   ErrorCatalogConfig         `json:"error_catalog"`
+	I18n           I18nConfig                 `json:"i18n"`
+	DocExamples    DocExamplesConfig          `json:"doc_examples"`
+	Distributed    DistributedConfig          `json:"distributed"`
+	Account        AccountConfig              `json:"account"`
+	Retention      RetentionConfig            `json:"retention"`
+	WebSocket      WebSocketConfig            `json:"websocket"`
+	Chat           ChatConfig                 `json:"chat"`
+	Attachment     AttachmentConfig           `json:"attachment"`
+	Secrets        SecretsConfig              `json:"secrets"`
+	ServiceAuth    ServiceAuthConfig          `json:"service_auth"`
+	APIKeyAuth     APIKeyAuthConfig           `json:"api_key_auth"`
+	OAuth          OAuthConfig                `json:"oauth"`
+	Captcha        CaptchaConfig              `json:"captcha"`
+	LoginRisk      LoginRiskConfig            `json:"login_risk"`
+	Audit          AuditConfig                `json:"audit"`
+	Clock          ClockConfig                `json:"clock"`
+	Export         ExportConfig               `json:"export"`
+	EventLog       EventLogConfig             `json:"event_log"`
+	ErrorTracking  ErrorTrackingConfig        `json:"error_tracking"`
+	Notification   NotificationConfig         `json:"notification"`
+	Email          EmailConfig                `json:"email"`
+	Push           PushConfig                 `json:"push"`
+	Tasks          map[string]json.RawMessage `json:"tasks"` // 定时任务独立配置块（按任务名），在任务注册时解析校验
 }
 
 // ServerConfig HTTP服务器配置
+// 超时限制慢速客户端（如slow loris）长期占用连接，时间单位均为秒，0表示不限制
 type ServerConfig struct {
-	Address      string `json:"address"`
-	Port         int    `json:"port"`
-	Mode         string `json:"mode"`
-	ReadTimeout  int    `json:"read_timeout"`
-	WriteTimeout int    `json:"write_timeout"`
+	Address           string             `json:"address"`
+	Port              int                `json:"port"`
+	Mode              string             `json:"mode"`
+	ReadTimeout       int                `json:"read_timeout"`        // 读取整个请求（含请求体）的超时
+	ReadHeaderTimeout int                `json:"read_header_timeout"` // 读取请求头的超时
+	WriteTimeout      int                `json:"write_timeout"`       // 写出响应的超时
+	IdleTimeout       int                `json:"idle_timeout"`        // keep-alive连接的空闲超时
+	MaxHeaderBytes    int                `json:"max_header_bytes"`    // 请求头最大字节数
+	H2C               bool               `json:"h2c"`                 // 是否接受未加密的HTTP/2（TLS在负载均衡终止时使用）
+	TCPKeepAlive      TCPKeepAliveConfig `json:"tcp_keepalive"`       // TCP keepalive探测
+	BodyLimit         BodyLimitConfig    `json:"body_limit"`          // 请求体大小限制
+	Compression       CompressionConfig  `json:"compression"`         // 响应压缩
+}
+
+// TCPKeepAliveConfig TCP keepalive配置，用于及时发现并释放对端已断开的连接
+type TCPKeepAliveConfig struct {
+	Enabled  bool `json:"enabled"`
+	Idle     int  `json:"idle"`     // 连接空闲多久后开始探测(秒)
+	Interval int  `json:"interval"` // 探测间隔(秒)
+	Count    int  `json:"count"`    // 连续探测失败多少次后断开
+}
+
+// BodyLimitConfig 请求体大小限制配置
+// 声明的Content-Length超过上限时直接返回413，未声明长度（分块传输）的请求体读取到上限时读取失败
+type BodyLimitConfig struct {
+	Enabled      bool             `json:"enabled"`
+	DefaultBytes int64            `json:"default_bytes"` // 未单独配置的路由的上限(字节)
+	Routes       []BodyLimitRoute `json:"routes"`        // 按路由配置的上限，优先于默认值
+}
+
+// BodyLimitRoute 路由请求体大小上限
+type BodyLimitRoute struct {
+	Route string `json:"route"` // "METHOD 路由模板"，METHOD为*时匹配所有方法
+	Bytes int64  `json:"bytes"` // 上限(字节)，0表示该路由不限制
+}
+
+// CompressionConfig 响应压缩配置
+// 客户端声明支持时按gzip、deflate的顺序选择编码，只压缩content_types中的类型且大小不小于min_bytes的响应
+type CompressionConfig struct {
+	Enabled      bool     `json:"enabled"`
+	Level        int      `json:"level"`         // 压缩级别，1（最快）-9（最小），-1为默认级别
+	MinBytes     int      `json:"min_bytes"`     // 小于该大小的响应不压缩(字节)
+	ContentTypes []string `json:"content_types"` // 压缩的媒体类型（不含参数）
 }
 
 // DatabaseConfig MySQL数据库配置
@@ -37,15 +119,73 @@ type DatabaseConfig struct {
 	MaxIdleConns    int    `json:"max_idle_conns"`
 	MaxOpenConns    int    `json:"max_open_conns"`
 	ConnMaxLifetime int    `json:"conn_max_lifetime"`
+
+	TLS  TLSConfig          `json:"tls"`  // TLS连接
+	Auth DatabaseAuthConfig `json:"auth"` // 认证插件（云IAM认证等）
+}
+
+// TLSConfig 数据库连接TLS配置
+type TLSConfig struct {
+	Enabled            bool   `json:"enabled"`
+	CAFile             string `json:"ca_file"`              // CA证书（PEM），为空时使用系统根证书
+	CertFile           string `json:"cert_file"`            // 客户端证书（PEM），与key_file同时设置时启用双向TLS
+	KeyFile            string `json:"key_file"`             // 客户端私钥（PEM）
+	ServerName         string `json:"server_name"`          // 校验证书使用的主机名，为空时使用连接地址
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"` // 跳过证书校验，仅用于开发环境
+}
+
+// DatabaseAuthConfig MySQL认证插件配置
+// password为配置中的静态密码；aws_iam使用RDS IAM认证令牌；command执行外部命令获取令牌（如GCP/Azure的CLI）
+type DatabaseAuthConfig struct {
+	Plugin   string   `json:"plugin"`    // 认证插件: password, aws_iam, command
+	Region   string   `json:"region"`    // aws_iam: 数据库所在区域，为空时读取AWS_REGION
+	Command  []string `json:"command"`   // command: 输出令牌的命令及参数
+	TokenTTL int      `json:"token_ttl"` // 令牌缓存时间(秒)，过期后新建连接时重新获取
 }
 
 // RedisConfig Redis配置
+// mode为standalone时连接host:port；sentinel时addrs为哨兵地址，通过master_name发现主节点并在故障转移后自动切换；
+// cluster时addrs为集群节点地址（部分即可），集群不支持database
 type RedisConfig struct {
 	Host     string `json:"host"`
 	Port     int    `json:"port"`
 	Password string `json:"password"`
 	Database int    `json:"database"`
 	PoolSize int    `json:"pool_size"`
+
+	Mode             string   `json:"mode"`              // 部署模式: standalone, sentinel, cluster
+	Addrs            []string `json:"addrs"`             // sentinel: 哨兵地址；cluster: 集群节点地址
+	MasterName       string   `json:"master_name"`       // sentinel: 主节点名称
+	SentinelPassword string   `json:"sentinel_password"` // sentinel: 哨兵的密码，为空时不认证
+	ReadMode         string   `json:"read_mode"`         // 只读命令的路由: primary, replica（仅cluster）, latency, random
+}
+
+// CacheConfig 缓存配置
+// codec为缓存值的默认序列化方式，prefix_codecs按键前缀覆盖（多个前缀匹配时使用最长的前缀）；
+// gzip_min_bytes大于0时序列化后达到该大小的值以gzip压缩保存，修改序列化方式后已缓存的值按未命中处理
+type CacheConfig struct {
+	Codec        string            `json:"codec"`          // json, msgpack, gob
+	PrefixCodecs map[string]string `json:"prefix_codecs"`  // 键前缀 -> 序列化方式，如 "redis:user:session:": "msgpack"
+	GzipMinBytes int               `json:"gzip_min_bytes"` // 压缩阈值(字节)，0表示不压缩
+
+	Memory MemoryCacheConfig `json:"memory"` // 内存缓存
+
+	// TTLPolicies 按键前缀覆盖内置的过期策略，如 "mem:user:info:": {"ttl_seconds": 1800, "jitter": 0.1}
+	TTLPolicies map[string]CacheTTLPolicy `json:"ttl_policies"`
+}
+
+// CacheTTLPolicy 键前缀的过期策略
+// 调用方传入cache.DefaultTTL时使用ttl_seconds，过期时间在±jitter比例内随机浮动，避免大量键同时过期
+type CacheTTLPolicy struct {
+	TTLSeconds int     `json:"ttl_seconds"` // 默认过期时间(秒)
+	Jitter     float64 `json:"jitter"`      // 随机浮动比例，0~1，0表示不浮动
+}
+
+// MemoryCacheConfig 内存缓存配置
+// 缓存项数由各缓存管理器指定，max_bytes按键和值的大小估算，超过时按policy淘汰，单个值超过max_bytes时不缓存
+type MemoryCacheConfig struct {
+	MaxBytes int64  `json:"max_bytes"` // 最大占用字节数，0表示不限制
+	Policy   string `json:"policy"`    // 淘汰策略: lru, lfu
 }
 
 // MongoConfig MongoDB配置
@@ -53,13 +193,227 @@ type MongoConfig struct {
 	URI      string `json:"uri"`
 	Database string `json:"database"`
 	Timeout  int    `json:"timeout"`
+
+	// 认证信息，设置username或auth_mechanism时覆盖URI中的认证设置
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	AuthSource    string `json:"auth_source"`    // 认证数据库，默认admin
+	AuthMechanism string `json:"auth_mechanism"` // SCRAM-SHA-256, SCRAM-SHA-1, MONGODB-X509, MONGODB-AWS，为空时由驱动协商
+
+	TLS TLSConfig `json:"tls"` // TLS连接
 }
 
 // JWTConfig JWT配置
 type JWTConfig struct {
-	SecretKey       string `json:"secret_key"`
-	ExpirationHours int    `json:"expiration_hours"`
+	SecretKey       string `json:"secret_key"`       // HS256的共享密钥
+	ExpirationHours int    `json:"expiration_hours"` // 不属于登录会话的token（如管理员token）的有效期(小时)
 	Issuer          string `json:"issuer"`
+
+	AccessTokenMinutes int `json:"access_token_minutes"` // 用户登录签发的访问token有效期(分钟)
+	RefreshTokenDays   int `json:"refresh_token_days"`   // 刷新token和登录会话的有效期(天)，每次刷新后重新计算
+
+	Algorithm    string         `json:"algorithm"`      // 签名算法: HS256, RS256, EdDSA；非对称算法的公钥通过/.well-known/jwks.json发布
+	SigningKeyID string         `json:"signing_key_id"` // 非对称算法签发token使用的key
+	Keys         []JWTKeyConfig `json:"keys"`           // 非对称算法的签发和验证key，PEM从密钥提供者的jwt_key_<key_id>读取
+}
+
+// MaxTokenLifetime 签发的token中最长的有效期
+func (c JWTConfig) MaxTokenLifetime() time.Duration {
+	lifetime := time.Duration(c.ExpirationHours) * time.Hour
+	if access := time.Duration(c.AccessTokenMinutes) * time.Minute; access > lifetime {
+		lifetime = access
+	}
+	return lifetime
+}
+
+// JWTKeyConfig 单个JWT签名key
+// 私钥可签发和验证，公钥只用于验证；轮换后旧key设置verify_until，到期后不再接受其签发的token
+type JWTKeyConfig struct {
+	KeyID       string `json:"key_id"`       // 写入token头的kid
+	VerifyUntil string `json:"verify_until"` // 验证截止时间(RFC3339)，为空表示一直有效
+}
+
+// TwoFactorConfig 两步验证（TOTP）配置
+// 启用两步验证的账户登录时需要验证码或备用码；enforced_*_roles中的角色未启用时，登录需先完成绑定
+type TwoFactorConfig struct {
+	Issuer             string   `json:"issuer"`               // 验证器应用中显示的发行方
+	Skew               int      `json:"skew"`                 // 允许的时间偏差（前后各skew个30秒时间步）
+	BackupCodes        int      `json:"backup_codes"`         // 每次生成的备用码数量
+	SetupMinutes       int      `json:"setup_minutes"`        // 未确认的绑定（密钥和登录时签发的绑定token）有效期(分钟)
+	EnforcedUserRoles  []string `json:"enforced_user_roles"`  // 必须启用两步验证的用户角色
+	EnforcedAdminRoles []string `json:"enforced_admin_roles"` // 必须启用两步验证的管理员角色
+}
+
+// LoginThrottleConfig 登录失败限制配置
+// 按用户名和客户端IP分别统计窗口内的登录失败次数：超过free_attempts后每次失败需等待的时间按指数增长（base_delay_seconds起翻倍，
+// 不超过max_delay_seconds），达到锁定阈值后锁定lockout_minutes，等待和锁定期间的登录请求直接拒绝
+type LoginThrottleConfig struct {
+	Enabled              bool `json:"enabled"`
+	WindowMinutes        int  `json:"window_minutes"`         // 登录失败次数的统计窗口(分钟)，从第一次失败开始计算
+	FreeAttempts         int  `json:"free_attempts"`          // 不需要等待的失败次数
+	BaseDelaySeconds     int  `json:"base_delay_seconds"`     // 超过free_attempts后第一次失败的等待时间(秒)
+	MaxDelaySeconds      int  `json:"max_delay_seconds"`      // 等待时间上限(秒)
+	UserLockoutThreshold int  `json:"user_lockout_threshold"` // 同一用户名失败次数达到该值时锁定该用户名
+	IPLockoutThreshold   int  `json:"ip_lockout_threshold"`   // 同一IP失败次数达到该值时锁定该IP（撞库时一个IP尝试大量用户名）
+	LockoutMinutes       int  `json:"lockout_minutes"`        // 锁定时长(分钟)
+}
+
+// AdminGuardConfig 管理端按管理员限流和异常操作检测配置
+// 检测到批量删除或非工作时间的批量导出时临时停用该管理员并发送安全告警，降低管理员账号被盗用后的影响
+type AdminGuardConfig struct {
+	Enabled             bool     `json:"enabled"`
+	RequestsPerMinute   int      `json:"requests_per_minute"`   // 每个管理员每分钟请求数上限
+	WritesPerMinute     int      `json:"writes_per_minute"`     // 每个管理员每分钟写操作(POST/PUT/PATCH/DELETE)上限
+	DeleteThreshold     int      `json:"delete_threshold"`      // 检测窗口内删除操作达到该数量视为批量删除
+	BulkRoutes          []string `json:"bulk_routes"`           // 批量导出/导入类路由，格式为"METHOD 路由模板"
+	OffHoursStart       int      `json:"off_hours_start"`       // 非工作时间开始(时，0-23)
+	OffHoursEnd         int      `json:"off_hours_end"`         // 非工作时间结束(时，0-23)，小于开始时间表示跨零点
+	OffHoursBulkLimit   int      `json:"off_hours_bulk_limit"`  // 检测窗口内非工作时间批量操作达到该数量视为异常
+	DetectWindowSeconds int      `json:"detect_window_seconds"` // 异常检测窗口(秒)
+	SuspendMinutes      int      `json:"suspend_minutes"`       // 检测到异常后停用管理员的时长(分钟)
+}
+
+// IPAccessConfig IP访问控制配置
+// 白名单和黑名单的条目为IP地址或CIDR；配置文件中的条目始终生效，管理接口添加的条目保存在Redis中，所有实例共享
+type IPAccessConfig struct {
+	Enabled        bool     `json:"enabled"`         // 拒绝黑名单中的客户端IP访问所有接口
+	AdminAllowlist bool     `json:"admin_allowlist"` // 管理后台（登录和管理接口）只允许白名单中的IP访问
+	Allow          []string `json:"allow"`           // 管理后台白名单，开启admin_allowlist时不能为空（避免管理接口误删后无法登录）
+	Deny           []string `json:"deny"`            // 客户端IP黑名单
+	CacheSeconds   int      `json:"cache_seconds"`   // 各实例重新加载Redis中名单的间隔(秒)
+}
+
+// RBACConfig 管理员角色权限配置
+// 角色的权限保存在MySQL中，各实例在内存中缓存，通过本实例修改角色时立即重新加载
+type RBACConfig struct {
+	CacheSeconds int `json:"cache_seconds"` // 各实例重新加载角色权限的间隔(秒)
+}
+
+// MaintenanceConfig 维护模式配置
+// 维护模式通过管理接口开启，状态保存在Redis中，所有实例共享；开启期间exempt_prefixes之外的请求返回503
+type MaintenanceConfig struct {
+	CheckSeconds   int      `json:"check_seconds"`   // 各实例重新加载维护状态的间隔(秒)
+	RetryAfter     int      `json:"retry_after"`     // 开启时未指定重试时间使用的Retry-After(秒)
+	ExemptPrefixes []string `json:"exempt_prefixes"` // 不受维护模式影响的路径前缀（管理后台、健康检查、指标导出）
+}
+
+// 限流算法
+const (
+	RateLimitSlidingWindow = "sliding_window" // 滑动窗口（按上一窗口的计数加权估算）
+	RateLimitTokenBucket   = "token_bucket"   // 令牌桶（允许突发，平均速率为limit/window）
+)
+
+// 限流对象
+const (
+	RateLimitByIP   = "ip"   // 按客户端IP
+	RateLimitByUser = "user" // 按已认证的用户或管理员，未认证的请求按客户端IP
+)
+
+// RateLimitConfig 接口限流配置
+// 计数保存在Redis中，多实例共享；Redis不可用时放行请求
+type RateLimitConfig struct {
+	Enabled bool              `json:"enabled"`
+	Default RateLimitPolicy   `json:"default"` // 未单独配置的路由共用的策略，limit为0表示不限流
+	Routes  []RateLimitPolicy `json:"routes"`  // 按路由配置的策略，优先于默认策略
+}
+
+// RateLimitPolicy 限流策略
+type RateLimitPolicy struct {
+	Route         string `json:"route,omitempty"` // "METHOD 路由模板"，如"POST /api/v1/user/login"，METHOD为*时匹配所有方法
+	Algorithm     string `json:"algorithm"`       // sliding_window, token_bucket
+	Key           string `json:"key"`             // ip, user
+	Limit         int    `json:"limit"`           // 窗口内请求数上限，令牌桶为桶容量
+	WindowSeconds int    `json:"window_seconds"`  // 窗口长度(秒)，令牌桶在该时长内补满
+}
+
+// validIPOrCIDR 是否为有效的IP地址或CIDR
+func validIPOrCIDR(value string) bool {
+	if _, err := netip.ParsePrefix(value); err == nil {
+		return true
+	}
+	_, err := netip.ParseAddr(value)
+	return err == nil
+}
+
+// validate 检查限流策略，name为策略名称（错误信息使用）
+func (p RateLimitPolicy) validate(name string) error {
+	switch p.Algorithm {
+	case RateLimitSlidingWindow, RateLimitTokenBucket:
+	default:
+		return fmt.Errorf("%s限流算法无效: %s", name, p.Algorithm)
+	}
+	switch p.Key {
+	case RateLimitByIP, RateLimitByUser:
+	default:
+		return fmt.Errorf("%s限流对象无效: %s", name, p.Key)
+	}
+	if p.Limit < 0 || p.WindowSeconds <= 0 {
+		return fmt.Errorf("%s限流上限不能小于0，窗口长度必须大于0", name)
+	}
+	return nil
+}
+
+// ConcurrencyLimitConfig 并发请求数限制配置
+// 在进程内统计正在处理的请求数，超过全局或路由的上限时直接返回429（负载卸载），不排队等待
+type ConcurrencyLimitConfig struct {
+	Enabled      bool                    `json:"enabled"`
+	MaxInFlight  int                     `json:"max_in_flight"` // 每个实例同时处理的请求数上限，0表示不限制
+	Routes       []ConcurrencyLimitRoute `json:"routes"`        // 按路由配置的上限，与全局上限同时生效
+	ExemptRoutes []string                `json:"exempt_routes"` // 不计数也不受限制的路由（"METHOD 路由模板"，如健康检查）
+}
+
+// ConcurrencyLimitRoute 路由并发请求数上限
+type ConcurrencyLimitRoute struct {
+	Route string `json:"route"` // "METHOD 路由模板"，METHOD为*时匹配所有方法
+	Limit int    `json:"limit"` // 每个实例同时处理该路由的请求数上限
+}
+
+// CircuitBreakerConfig 依赖服务熔断配置
+// 每个依赖（mysql、mongodb、redis、price_api）在进程内独立统计，连续失败达到阈值后熔断，熔断期间直接返回错误
+type CircuitBreakerConfig struct {
+	Enabled      bool                            `json:"enabled"`
+	Default      CircuitBreakerPolicy            `json:"default"`       // 未单独配置的依赖使用的策略
+	Dependencies map[string]CircuitBreakerPolicy `json:"dependencies"`  // 按依赖名配置的策略
+	FailFast     []string                        `json:"fail_fast"`     // 这些依赖熔断时HTTP请求直接返回503，不进入处理器
+	ExemptRoutes []string                        `json:"exempt_routes"` // 不受fail_fast影响的路由（"METHOD 路由模板"，如健康检查）
+}
+
+// CircuitBreakerPolicy 熔断策略
+type CircuitBreakerPolicy struct {
+	FailureThreshold int `json:"failure_threshold"` // 连续失败多少次后熔断
+	OpenSeconds      int `json:"open_seconds"`      // 熔断持续时间(秒)，之后进入半开状态
+	HalfOpenProbes   int `json:"half_open_probes"`  // 半开状态放行的探测请求数，全部成功后恢复，任一失败重新熔断
+}
+
+// PolicyFor 依赖使用的熔断策略
+func (c CircuitBreakerConfig) PolicyFor(dependency string) CircuitBreakerPolicy {
+	if policy, ok := c.Dependencies[dependency]; ok {
+		return policy
+	}
+	return c.Default
+}
+
+// validate 检查熔断策略，name为策略名称（错误信息使用）
+func (p CircuitBreakerPolicy) validate(name string) error {
+	if p.FailureThreshold <= 0 || p.OpenSeconds <= 0 || p.HalfOpenProbes <= 0 {
+		return fmt.Errorf("%s熔断策略的失败阈值、熔断时间和探测请求数必须大于0", name)
+	}
+	return nil
+}
+
+// RequestTimeoutConfig 请求处理超时配置
+// 超过处理时间后处理器的context被取消，中间件立即写出超时响应（仍在读取请求体时返回408，否则返回504），
+// 处理器之后的写入被丢弃；超时需小于server.write_timeout，否则连接会先于超时响应被关闭
+type RequestTimeoutConfig struct {
+	Enabled bool                  `json:"enabled"`
+	Default int                   `json:"default"` // 未单独配置的路由的处理超时(秒)，0表示不限制
+	Routes  []RequestTimeoutRoute `json:"routes"`  // 按路由配置的处理超时，优先于默认值
+}
+
+// RequestTimeoutRoute 路由处理超时
+type RequestTimeoutRoute struct {
+	Route   string `json:"route"`   // "METHOD 路由模板"，METHOD为*时匹配所有方法
+	Seconds int    `json:"seconds"` // 处理超时(秒)，0表示该路由不限制（如流式下载，响应不能缓冲）
 }
 
 // LogConfig 日志配置
@@ -80,6 +434,620 @@ type LogConfig struct {
 	AccessLogFile string `json:"access_log_file"` // 访问日志文件名
 	ErrorLogFile  string `json:"error_log_file"`  // 错误日志文件名
 	CronLogFile   string `json:"cron_log_file"`   // Cron服务日志文件名
+
+	Backend  string            `json:"backend"`  // 日志后端: slog, zap
+	Sampling LogSamplingConfig `json:"sampling"` // 日志采样
+	Async    LogAsyncConfig    `json:"async"`    // 异步写入
+	Redact   LogRedactConfig   `json:"redact"`   // 敏感信息脱敏
+	Access   LogAccessConfig   `json:"access"`   // HTTP访问日志
+	OTLP     LogOTLPConfig     `json:"otlp"`     // OTLP日志导出
+	Outputs  []LogOutputConfig `json:"outputs"`  // 额外的日志输出（kafka、elasticsearch），与控制台/文件输出并行
+	Alert    LogAlertConfig    `json:"alert"`    // 错误率告警
+
+	Modules map[string]string `json:"modules"` // 按模块覆盖日志级别，如 {"cron": "debug", "cache": "warn"}
+}
+
+// LogAsyncConfig 异步日志写入配置
+type LogAsyncConfig struct {
+	Enabled         bool   `json:"enabled"`
+	BufferSize      int    `json:"buffer_size"`       // 队列容量(条)
+	BatchSize       int    `json:"batch_size"`        // 每批最多写入条数
+	FlushIntervalMs int    `json:"flush_interval_ms"` // 最长刷新间隔(毫秒)
+	Overflow        string `json:"overflow"`          // 队列满时的策略: drop 丢弃, block 阻塞等待
+}
+
+// LogAccessConfig HTTP访问日志配置
+// 每个请求记录一条访问日志（写入access_log_file）；错误响应（状态码>=400）按比例附带请求体和响应体，便于排查
+type LogAccessConfig struct {
+	BodySampleRate float64  `json:"body_sample_rate"` // 错误响应附带请求体和响应体的比例(0-1)，0表示不记录
+	MaxBodyBytes   int      `json:"max_body_bytes"`   // 请求体和响应体各自最多记录的字节数
+	SkipPaths      []string `json:"skip_paths"`       // 不记录访问日志的路径（完全匹配，如负载均衡的健康检查）
+}
+
+// LogRedactConfig 日志敏感信息脱敏配置
+type LogRedactConfig struct {
+	Enabled     bool     `json:"enabled"`
+	Keys        []string `json:"keys"`         // 需要脱敏的字段名（不区分大小写，字段名包含即匹配）
+	Mask        string   `json:"mask"`         // 替换值
+	CardNumbers bool     `json:"card_numbers"` // 是否屏蔽字符串值中的银行卡号（只保留后4位）
+}
+
+// LogOTLPConfig OTLP日志导出配置（OTLP/HTTP JSON）
+type LogOTLPConfig struct {
+	Enabled            bool              `json:"enabled"`
+	Endpoint           string            `json:"endpoint"`            // Collector日志接收地址，如 http://localhost:4318/v1/logs
+	Headers            map[string]string `json:"headers"`             // 附加请求头（如认证信息）
+	ResourceAttributes map[string]string `json:"resource_attributes"` // 附加资源属性，service.name固定为服务名
+	QueueSize          int               `json:"queue_size"`          // 导出队列容量(条)，满时丢弃
+	BatchSize          int               `json:"batch_size"`          // 每批最多发送条数
+	FlushIntervalMs    int               `json:"flush_interval_ms"`   // 最长发送间隔(毫秒)
+	TimeoutMs          int               `json:"timeout_ms"`          // 单次请求超时(毫秒)
+}
+
+// LogAlertConfig 错误率告警配置
+// 按类别（模块名，未指定模块时为日志类型或app）统计滑动窗口内的Error/Fatal日志数，达到阈值时触发告警
+type LogAlertConfig struct {
+	Enabled         bool                `json:"enabled"`
+	WindowSeconds   int                 `json:"window_seconds"`   // 统计窗口(秒)
+	Threshold       int                 `json:"threshold"`        // 窗口内错误数达到该值时告警
+	CooldownSeconds int                 `json:"cooldown_seconds"` // 同一类别两次告警的最小间隔(秒)
+	Categories      map[string]int      `json:"categories"`       // 按类别覆盖阈值，如 {"cron": 5, "database": 3}，0表示该类别不告警
+	Webhook         LogAlertWebhook     `json:"webhook"`          // Webhook告警（如PagerDuty、钉钉、Slack转发服务）
+	Email           LogAlertEmailConfig `json:"email"`            // 邮件告警
+}
+
+// LogAlertWebhook 告警Webhook配置，告警以JSON POST发送
+type LogAlertWebhook struct {
+	URL       string            `json:"url"` // 为空时不发送
+	Headers   map[string]string `json:"headers"`
+	TimeoutMs int               `json:"timeout_ms"`
+}
+
+// LogAlertEmailConfig 告警邮件配置（SMTP）
+type LogAlertEmailConfig struct {
+	Host     string   `json:"host"` // 为空时不发送
+	Port     int      `json:"port"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// LogOutputConfig 集中日志输出配置
+// 未设置的数值参数使用默认值（见WithDefaults）
+type LogOutputConfig struct {
+	Type            string            `json:"type"`              // 输出类型: kafka, elasticsearch
+	URL             string            `json:"url"`               // kafka: REST Proxy地址；elasticsearch: 集群地址
+	Topic           string            `json:"topic"`             // kafka: 主题
+	Index           string            `json:"index"`             // elasticsearch: 索引名，{}中的内容按Go时间格式替换，如 exchange-logs-{2006.01.02}
+	Username        string            `json:"username"`          // Basic认证用户名
+	Password        string            `json:"password"`          // Basic认证密码
+	Headers         map[string]string `json:"headers"`           // 附加请求头
+	QueueSize       int               `json:"queue_size"`        // 发送队列容量(条)，满时丢弃
+	BatchSize       int               `json:"batch_size"`        // 每批最多发送条数
+	FlushIntervalMs int               `json:"flush_interval_ms"` // 最长发送间隔(毫秒)
+	TimeoutMs       int               `json:"timeout_ms"`        // 单次请求超时(毫秒)
+	MaxRetries      int               `json:"max_retries"`       // 失败重试次数
+	RetryBackoffMs  int               `json:"retry_backoff_ms"`  // 首次重试等待(毫秒)，之后每次翻倍
+}
+
+// WithDefaults 返回补全默认值后的配置
+func (c LogOutputConfig) WithDefaults() LogOutputConfig {
+	if c.QueueSize <= 0 {
+		c.QueueSize = 10000
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 500
+	}
+	if c.FlushIntervalMs <= 0 {
+		c.FlushIntervalMs = 1000
+	}
+	if c.TimeoutMs <= 0 {
+		c.TimeoutMs = 5000
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	}
+	if c.RetryBackoffMs <= 0 {
+		c.RetryBackoffMs = 200
+	}
+	return c
+}
+
+// LogSamplingConfig 日志采样配置
+// 每秒内同一条消息先记录Initial条，之后每Thereafter条记录一条，错误日志不采样
+type LogSamplingConfig struct {
+	Enabled    bool `json:"enabled"`
+	Initial    int  `json:"initial"`
+	Thereafter int  `json:"thereafter"`
+}
+
+// MonitorConfig 定时任务监控界面配置
+type MonitorConfig struct {
+	Address      string   `json:"address"`       // 监听地址
+	ControlRoles []string `json:"control_roles"` // 允许触发/暂停/终止任务的管理员角色
+}
+
+// AccountConfig 用户账户配置
+type AccountConfig struct {
+	DeletionGraceDays int    `json:"deletion_grace_days"` // 注销冷静期(天)，期间可撤销
+	InviteURL         string `json:"invite_url"`          // 邀请链接前缀，后接邀请token
+	InviteTTLHours    int    `json:"invite_ttl_hours"`    // 邀请链接有效期(小时)
+
+	PasswordResetURL           string `json:"password_reset_url"`            // 重置密码链接前缀，后接重置token
+	PasswordResetTTLMinutes    int    `json:"password_reset_ttl_minutes"`    // 重置密码链接有效期(分钟)
+	PasswordResetLimit         int    `json:"password_reset_limit"`          // 每个账户在统计窗口内最多发送的重置邮件数
+	PasswordResetWindowMinutes int    `json:"password_reset_window_minutes"` // 重置邮件数的统计窗口(分钟)
+
+	EmailVerificationRequired      bool   `json:"email_verification_required"`       // 是否禁止未验证邮箱的用户执行敏感操作（如会话导出）
+	EmailVerificationURL           string `json:"email_verification_url"`            // 验证邮箱链接前缀，后接验证token
+	EmailVerificationTTLHours      int    `json:"email_verification_ttl_hours"`      // 验证邮箱链接有效期(小时)
+	EmailVerificationLimit         int    `json:"email_verification_limit"`          // 每个账户在统计窗口内最多发送的验证邮件数
+	EmailVerificationWindowMinutes int    `json:"email_verification_window_minutes"` // 验证邮件数的统计窗口(分钟)
+}
+
+// WebSocketConfig WebSocket配置
+// 事件通过Redis pub/sub发布到接收者连接所在的实例，多实例部署时无需会话保持
+type WebSocketConfig struct {
+	ReplayBufferSize int `json:"replay_buffer_size"` // 每个用户保留的最近推送事件条数，用于断线重连补发
+	ReplayTTL        int `json:"replay_ttl"`         // 补发缓冲区保留时间(秒)，超过后重连需全量同步
+	HeartbeatTimeout int `json:"heartbeat_timeout"`  // 客户端超过该时间(秒)未发送任何帧（如ping）时断开连接
+	PresenceTTL      int `json:"presence_ttl"`       // 在线状态保留时间(秒)，实例每隔三分之一TTL刷新一次，实例异常退出后在线状态最多保留该时间
+	SendBufferSize   int `json:"send_buffer_size"`   // 每个连接待发送事件的队列长度，队列满时断开连接，客户端重连后补发
+	MaxMessageBytes  int `json:"max_message_bytes"`  // 客户端发送的单帧大小上限(字节)
+	DrainTimeout     int `json:"drain_timeout"`      // 关闭时等待连接断开的时间(秒)
+	LastSeenDays     int `json:"last_seen_days"`     // 最后在线时间保留天数
+	TypingTimeout    int `json:"typing_timeout"`     // 正在输入状态的显示时间(秒)，客户端持续输入时每隔三分之一该时间重新发送，同一会话更频繁的事件被忽略
+	MaxSubscriptions int `json:"max_subscriptions"`  // 每个连接最多订阅在线状态变化的用户数
+}
+
+// ChatConfig 聊天消息配置
+type ChatConfig struct {
+	EditWindowMinutes   int `json:"edit_window_minutes"`   // 发送后可以修改的时间(分钟)
+	DeleteWindowMinutes int `json:"delete_window_minutes"` // 发送后可以删除的时间(分钟)，0表示不限
+	MaxEdits            int `json:"max_edits"`             // 每条消息最多修改次数（即保留的修改历史条数）
+	MaxGroupMembers     int `json:"max_group_members"`     // 群聊最多成员数（含群主）
+	MaxGroupsPerUser    int `json:"max_groups_per_user"`   // 每个用户最多创建的群聊数
+	UnreadCacheTTL      int `json:"unread_cache_ttl"`      // Redis中单聊未读数的缓存时间(秒)，过期后从MongoDB重新统计
+}
+
+// 聊天附件存储驱动
+const (
+	AttachmentDriverLocal = "local" // 本地目录，通过本服务的签名链接下载
+	AttachmentDriverS3    = "s3"    // AWS S3
+	AttachmentDriverMinIO = "minio" // MinIO等S3兼容服务
+)
+
+// AttachmentConfig 聊天附件配置
+// 消息中只保存对象key，下载时生成限时的预签名链接
+type AttachmentConfig struct {
+	Driver       string                `json:"driver"`        // local, s3, minio
+	MaxSizeMB    int                   `json:"max_size_mb"`   // 单个附件的大小上限(MB)，上传路由的请求体上限需相应调整
+	AllowedTypes []string              `json:"allowed_types"` // 允许的MIME类型（按文件内容识别），支持image/*形式
+	URLTTL       int                   `json:"url_ttl"`       // 下载链接有效期(秒)
+	KeyPrefix    string                `json:"key_prefix"`    // 对象key前缀
+	Local        LocalAttachmentConfig `json:"local"`
+	S3           S3AttachmentConfig    `json:"s3"`
+}
+
+// LocalAttachmentConfig 本地目录存储配置
+type LocalAttachmentConfig struct {
+	Dir     string `json:"dir"`      // 存储目录（多实例部署需为共享存储）
+	BaseURL string `json:"base_url"` // 下载链接的前缀（对外访问的API地址）
+}
+
+// S3AttachmentConfig S3和MinIO存储配置
+type S3AttachmentConfig struct {
+	Endpoint      string `json:"endpoint"`        // 服务地址，s3为空时使用https://s3.<region>.amazonaws.com
+	Region        string `json:"region"`          // 签名使用的区域，MinIO一般为us-east-1
+	Bucket        string `json:"bucket"`          // 存储桶
+	PathStyle     bool   `json:"path_style"`      // 使用路径风格地址(endpoint/bucket/key)，MinIO需开启
+	AccessKeyName string `json:"access_key_name"` // access key ID在密钥提供者中的名称
+	SecretKeyName string `json:"secret_key_name"` // secret access key在密钥提供者中的名称
+	TimeoutMs     int    `json:"timeout_ms"`      // 上传、查询和删除对象的请求超时(毫秒)
+}
+
+// SecretsConfig 密钥提供者配置
+type SecretsConfig struct {
+	Provider  string            `json:"provider"`   // 提供者: env, file, config
+	Dir       string            `json:"dir"`        // file: 密钥目录，每个密钥一个文件（如Kubernetes Secret挂载目录）
+	EnvPrefix string            `json:"env_prefix"` // env: 环境变量前缀，密钥名转大写后拼接
+	Values    map[string]string `json:"values"`     // config: 直接配置的密钥，仅用于开发环境
+}
+
+// ServiceAuthConfig 内部服务请求签名配置
+type ServiceAuthConfig struct {
+	Enabled      bool     `json:"enabled"`
+	ServiceName  string   `json:"service_name"`  // 本服务调用其他内部服务时使用的名称
+	Services     []string `json:"services"`      // 允许调用本服务内部接口的服务
+	ReplayWindow int      `json:"replay_window"` // 签名时间戳允许的偏差(秒)，同时为nonce的保留时间
+	KeyCacheTTL  int      `json:"key_cache_ttl"` // 密钥缓存时间(秒)，轮换后的密钥最迟在此时间后生效
+}
+
+// API key权限
+const (
+	APIKeyPermissionRead  = "read"  // GET、HEAD请求
+	APIKeyPermissionWrite = "write" // 其他请求
+)
+
+// APIKeyAuthConfig 程序化交易客户端（机器人、做市商）的API key认证配置
+// keys中配置的key以签名认证，签名方式与内部服务请求签名相同，签名密钥由密钥提供者提供，key ID为bot1的密钥名为api_secret_bot1；
+// 用户通过接口自助创建的key保存在api_keys表中，以密钥认证
+type APIKeyAuthConfig struct {
+	Enabled      bool           `json:"enabled"`
+	ReplayWindow int            `json:"replay_window"` // 签名时间戳允许的偏差(秒)，同时为nonce的保留时间
+	KeyCacheTTL  int            `json:"key_cache_ttl"` // 密钥缓存时间(秒)，轮换后的密钥最迟在此时间后生效
+	Keys         []APIKeyConfig `json:"keys"`
+
+	MaxUserKeys          int `json:"max_user_keys"`           // 每个用户最多创建的key数
+	UserKeyRateLimit     int `json:"user_key_rate_limit"`     // 用户创建的key每个窗口的请求数上限（默认值，也是创建时可设置的最大值）
+	UserKeyWindowSeconds int `json:"user_key_window_seconds"` // 用户创建的key的限流窗口长度(秒)
+	UserKeyMaxTTLDays    int `json:"user_key_max_ttl_days"`   // 用户创建的key的最长有效期(天)，0表示允许不过期
+}
+
+// APIKeyConfig 单个API key
+type APIKeyConfig struct {
+	KeyID         string   `json:"key_id"`
+	UserID        uint     `json:"user_id"`        // key所属的用户，认证后以该用户身份访问用户接口
+	Permissions   []string `json:"permissions"`    // read, write
+	RateLimit     int      `json:"rate_limit"`     // 每个窗口的请求数上限（滑动窗口），0表示只受接口限流约束
+	WindowSeconds int      `json:"window_seconds"` // 限流窗口长度(秒)
+	Disabled      bool     `json:"disabled"`       // 停用的key签名正确也拒绝
+}
+
+// 支持的第三方登录服务
+const (
+	OAuthProviderGoogle = "google"
+	OAuthProviderGitHub = "github"
+	OAuthProviderApple  = "apple"
+)
+
+// OAuthConfig 第三方登录（OAuth2授权码 + PKCE）配置
+type OAuthConfig struct {
+	Enabled   bool                           `json:"enabled"`
+	StateTTL  int                            `json:"state_ttl"` // 授权state有效期(秒)，需在此时间内完成授权并回调
+	Providers map[string]OAuthProviderConfig `json:"providers"` // google, github, apple
+}
+
+// OAuthProviderConfig 单个第三方登录服务
+type OAuthProviderConfig struct {
+	Enabled          bool     `json:"enabled"`
+	ClientID         string   `json:"client_id"`          // apple为Services ID
+	ClientSecretName string   `json:"client_secret_name"` // 客户端密钥在密钥提供者中的名称，apple为签名client_secret的ES256私钥(PEM)
+	RedirectURL      string   `json:"redirect_url"`       // 授权后回调的前端地址，需与服务商后台登记的一致
+	Scopes           []string `json:"scopes"`             // 为空时使用默认权限（读取用户标识和邮箱）
+	TeamID           string   `json:"team_id"`            // apple开发者团队ID
+	KeyID            string   `json:"key_id"`             // apple私钥ID
+}
+
+// CaptchaConfig 人机验证配置
+// 同一客户端IP在窗口内登录、注册或找回密码失败达到failure_threshold次后，请求需携带X-Captcha-Token头，
+// 由provider对应的服务校验（hcaptcha、recaptcha、turnstile或通过captcha.RegisterProvider注册的服务）
+type CaptchaConfig struct {
+	Enabled          bool    `json:"enabled"`
+	Provider         string  `json:"provider"`          // hcaptcha, recaptcha, turnstile或已注册的服务
+	SiteKey          string  `json:"site_key"`          // 前端渲染验证组件使用的站点key，需要验证时随响应返回
+	SecretName       string  `json:"secret_name"`       // 服务端校验密钥在密钥提供者中的名称
+	FailureThreshold int     `json:"failure_threshold"` // 窗口内失败次数达到该值后需要验证，0表示始终需要
+	WindowMinutes    int     `json:"window_minutes"`    // 失败次数统计窗口(分钟)
+	MinScore         float64 `json:"min_score"`         // reCAPTCHA v3的最低分数(0-1)，返回分数低于该值时视为未通过
+	TimeoutMs        int     `json:"timeout_ms"`        // 请求校验服务的超时(毫秒)
+}
+
+// 新设备或异地登录的处理方式
+const (
+	LoginRiskActionNotify = "notify"  // 只通知用户
+	LoginRiskActionStepUp = "step_up" // 未启用两步验证的用户需通过邮件确认设备后重新登录（已启用两步验证的登录已通过验证码确认）
+)
+
+// LoginRiskConfig 新设备和异地登录检测配置
+type LoginRiskConfig struct {
+	Enabled           bool        `json:"enabled"`
+	Action            string      `json:"action"`              // notify或step_up
+	DeviceHeader      string      `json:"device_header"`       // 客户端上报设备ID的请求头，没有时按User-Agent识别设备
+	ConfirmURL        string      `json:"confirm_url"`         // 确认登录链接前缀，后接确认token
+	ConfirmTTLMinutes int         `json:"confirm_ttl_minutes"` // 确认登录链接有效期(分钟)
+	MaxDevices        int         `json:"max_devices"`         // 每个用户保留的已知设备数，超过时删除最久未使用的
+	GeoIP             GeoIPConfig `json:"geoip"`
+}
+
+// GeoIPConfig 客户端IP所在国家的查询方式
+type GeoIPConfig struct {
+	Provider string `json:"provider"` // none: 不查询; header: 读取CDN或负载均衡写入的请求头; csv: 本地IP段文件
+	Header   string `json:"header"`   // header: 国家代码请求头，如Cloudflare的CF-IPCountry（只在请求必经CDN时可信）
+	File     string `json:"file"`     // csv: IP段文件，每行"CIDR,国家代码"
+}
+
+// AuditConfig 审计日志持久化配置
+type AuditConfig struct {
+	Enabled   bool `json:"enabled"`    // 是否将审计事件写入MongoDB哈希链（audit_logs）
+	QueueSize int  `json:"queue_size"` // 待写入事件的缓冲队列长度
+}
+
+// ClockConfig 模拟时钟配置，用于预发环境快进时间测试到期、清理等逻辑，生产环境不可启用
+type ClockConfig struct {
+	Simulated bool   `json:"simulated"` // 是否启用模拟时钟
+	Offset    string `json:"offset"`    // 相对真实时间的偏移，如"72h"、"7d"、"-1d12h"
+}
+
+// MetricsConfig 指标导出配置（Prometheus文本格式）
+type MetricsConfig struct {
+	Enabled bool   `json:"enabled"`
+	Path    string `json:"path"`  // 导出路径
+	Token   string `json:"token"` // 不为空时抓取请求需携带 Authorization: Bearer <token>
+}
+
+// TracingConfig 分布式追踪配置（OpenTelemetry，OTLP/HTTP导出）
+// 记录HTTP请求、MySQL/MongoDB/Redis调用和定时任务执行的span，请求头中的traceparent作为上游链路
+type TracingConfig struct {
+	Enabled            bool              `json:"enabled"`
+	Endpoint           string            `json:"endpoint"`            // Collector追踪接收地址，如 http://localhost:4318/v1/traces
+	Headers            map[string]string `json:"headers"`             // 附加请求头（如认证信息）
+	ResourceAttributes map[string]string `json:"resource_attributes"` // 附加资源属性，service.name固定为服务名
+	SampleRatio        float64           `json:"sample_ratio"`        // 新链路的采样比例(0-1)，带有上游链路的请求跟随上游的采样结果
+	TimeoutMs          int               `json:"timeout_ms"`          // 单次导出超时(毫秒)
+}
+
+// ErrorCatalogConfig 错误目录配置
+// 业务错误码（错误码、分类、严重级别、i18n键、HTTP状态码）在文件中定义，启动时加载，无需修改Go代码
+type ErrorCatalogConfig struct {
+	File string `json:"file"` // 错误目录文件路径（.yaml/.yml或.json），为空时不加载
+}
+
+// I18nConfig 国际化配置
+// 外部目录中的<语言>.json在内嵌翻译之后加载，同一消息键覆盖内嵌的翻译，无需重新发布即可修正或新增翻译
+type I18nConfig struct {
+	LocalesDir string `json:"locales_dir"` // 外部翻译文件目录，为空时只使用内嵌的翻译
+	HotReload  bool   `json:"hot_reload"`  // 监听目录变更并重新加载，文件无法解析时保留当前翻译
+
+	// 语言的回退链，如 "zh-TW": ["zh", "en"]，缺少的消息依次使用回退语言的翻译
+	// 未配置的语言回退到基础语言（如ja-JP回退到ja），最后都回退到默认语言
+	Fallbacks map[string][]string `json:"fallbacks"`
+}
+
+// DocExamplesConfig 接口文档示例配置
+// 启用后记录每个路由真实的请求/响应作为文档示例，仅用于开发和测试环境运行接口测试时，生产环境不可启用
+type DocExamplesConfig struct {
+	Enabled      bool   `json:"enabled"`
+	File         string `json:"file"`           // 示例文件路径
+	MaxBodyBytes int    `json:"max_body_bytes"` // 记录的请求体/响应体大小上限(字节)，超过时不记录
+}
+
+// EventLogConfig 领域事件日志配置
+// 事件总线上的事件写入MongoDB（domain_events），每日由定时任务按UTC日期分区导出供数据仓库加载
+type EventLogConfig struct {
+	Enabled   bool   `json:"enabled"`    // 是否记录领域事件
+	ExportDir string `json:"export_dir"` // 导出文件和manifest的存储目录
+}
+
+// ErrorTrackingConfig 错误上报配置（Sentry或通用错误收集接口）
+// 严重级别不低于min_severity的AppError和panic在后台发送，不影响请求
+type ErrorTrackingConfig struct {
+	Enabled     bool              `json:"enabled"`
+	Provider    string            `json:"provider"`     // sentry 或 webhook
+	DSN         string            `json:"dsn"`          // sentry: 项目DSN，如 https://<key>@o1.ingest.sentry.io/<project>
+	URL         string            `json:"url"`          // webhook: 接收地址，事件以JSON POST发送
+	Headers     map[string]string `json:"headers"`      // webhook: 附加请求头
+	Environment string            `json:"environment"`  // 环境名，为空时使用APP_ENV
+	MinSeverity string            `json:"min_severity"` // 上报的最低严重级别: low, medium, high, critical
+	QueueSize   int               `json:"queue_size"`   // 发送队列容量(条)，满时丢弃
+	TimeoutMs   int               `json:"timeout_ms"`   // 单次发送超时(毫秒)
+	Workers     WorkerPoolConfig  `json:"workers"`      // 发送工作池
+}
+
+// NotificationConfig 用户通知发送配置
+// 启用异步发送后通知进入有界队列由工作池发送，接口请求不等待邮件/推送渠道
+type NotificationConfig struct {
+	Async     bool             `json:"async"`
+	QueueSize int              `json:"queue_size"` // 发送队列容量(条)，满时拒绝
+	TimeoutMs int              `json:"timeout_ms"` // 单条通知发送超时(毫秒)
+	Workers   WorkerPoolConfig `json:"workers"`    // 发送工作池
+}
+
+// EmailConfig 邮件发送配置
+// provider为log时只写日志（开发环境），smtp时通过SMTP服务器发送，其他值为通过mailer.RegisterProvider注册的发送服务
+type EmailConfig struct {
+	Provider string     `json:"provider"`  // 发送方式: log, smtp或已注册的发送服务
+	From     string     `json:"from"`      // 发件地址
+	FromName string     `json:"from_name"` // 发件人名称
+	SMTP     SMTPConfig `json:"smtp"`
+}
+
+// SMTPConfig SMTP服务器配置，密码由密钥提供者提供
+type SMTPConfig struct {
+	Host           string `json:"host"`
+	Port           int    `json:"port"`
+	Username       string `json:"username"`        // 为空时不认证
+	PasswordSecret string `json:"password_secret"` // 密码的密钥名
+	Security       string `json:"security"`        // 连接加密: starttls, tls（隐式TLS，通常为465端口）, none
+}
+
+// 推送设备平台
+const (
+	PushPlatformIOS     = "ios"
+	PushPlatformAndroid = "android"
+	PushPlatformWeb     = "web"
+)
+
+// PushConfig 离线推送配置
+// 接收者在任何实例上都没有WebSocket连接时，新消息写入Redis推送队列，由定时任务PushDispatchTask按用户的通知设置和免打扰时段发送；
+// platforms指定各设备平台使用的推送服务：log只写日志（开发环境），fcm、apns或通过push.RegisterProvider注册的服务
+type PushConfig struct {
+	Enabled           bool              `json:"enabled"`
+	Platforms         map[string]string `json:"platforms"`            // 设备平台(ios, android, web) -> 推送服务
+	MaxAttempts       int               `json:"max_attempts"`         // 发送失败（设备失效除外）时的最多尝试次数
+	JobTTL            int               `json:"job_ttl"`              // 推送任务的有效期(秒)，超过后不再发送
+	MaxDevicesPerUser int               `json:"max_devices_per_user"` // 每个用户保留的设备数，超出时删除最久未使用的设备
+	TimeoutMs         int               `json:"timeout_ms"`           // 请求推送服务的超时(毫秒)
+	FCM               FCMConfig         `json:"fcm"`
+	APNs              APNsConfig        `json:"apns"`
+}
+
+// FCMConfig Firebase Cloud Messaging（HTTP v1接口）配置
+type FCMConfig struct {
+	ProjectID       string `json:"project_id"`
+	CredentialsName string `json:"credentials_name"` // 服务账号JSON在密钥提供者中的名称
+}
+
+// APNsConfig Apple推送服务配置，以.p8私钥签名的provider token认证
+type APNsConfig struct {
+	TeamID     string `json:"team_id"`
+	KeyID      string `json:"key_id"`
+	KeyName    string `json:"key_name"`   // .p8私钥(PEM)在密钥提供者中的名称
+	Topic      string `json:"topic"`      // 应用的bundle ID
+	Production bool   `json:"production"` // false时发送到sandbox环境（开发版应用）
+}
+
+// WorkerPoolConfig 异步投递工作池配置
+// 工作协程数在min_workers和max_workers之间按队列积压调整；统计窗口内死信（重试后仍失败的任务）比例过高时
+// 暂停接收新任务，避免外部接口变慢时积压的任务耗尽内存
+type WorkerPoolConfig struct {
+	MinWorkers              int     `json:"min_workers"`
+	MaxWorkers              int     `json:"max_workers"`
+	ScaleIntervalMs         int     `json:"scale_interval_ms"`          // 调整工作协程数的间隔(毫秒)
+	ScaleUpLagMs            int     `json:"scale_up_lag_ms"`            // 任务排队时间超过该值时增加工作协程(毫秒)
+	DeadLetterWindowSeconds int     `json:"dead_letter_window_seconds"` // 死信比例的统计窗口(秒)
+	DeadLetterRate          float64 `json:"dead_letter_rate"`           // 窗口内死信比例达到该值时暂停接收(0-1)
+	DeadLetterMinSamples    int     `json:"dead_letter_min_samples"`    // 窗口内完成的任务数达到该值才判断死信比例
+	PauseSeconds            int     `json:"pause_seconds"`              // 暂停接收的时长(秒)
+}
+
+// DefaultWorkerPoolConfig 默认工作池配置
+func DefaultWorkerPoolConfig() WorkerPoolConfig {
+	return WorkerPoolConfig{
+		MinWorkers:              1,
+		MaxWorkers:              8,
+		ScaleIntervalMs:         1000,
+		ScaleUpLagMs:            2000,
+		DeadLetterWindowSeconds: 60,
+		DeadLetterRate:          0.5,
+		DeadLetterMinSamples:    20,
+		PauseSeconds:            30,
+	}
+}
+
+// validate 检查工作池配置
+func (c WorkerPoolConfig) validate(name string) error {
+	if c.MinWorkers <= 0 || c.MaxWorkers < c.MinWorkers {
+		return fmt.Errorf("%s工作协程数必须大于0，且max_workers不能小于min_workers", name)
+	}
+	if c.ScaleIntervalMs <= 0 || c.ScaleUpLagMs <= 0 || c.DeadLetterWindowSeconds <= 0 || c.PauseSeconds <= 0 {
+		return fmt.Errorf("%s工作池的调整间隔、排队阈值、统计窗口和暂停时长必须大于0", name)
+	}
+	if c.DeadLetterRate <= 0 || c.DeadLetterRate > 1 || c.DeadLetterMinSamples <= 0 {
+		return fmt.Errorf("%s工作池的死信比例必须在0-1之间，最小样本数必须大于0", name)
+	}
+	return nil
+}
+
+// ExportConfig 用户数据导出配置
+type ExportConfig struct {
+	Dir            string `json:"dir"`             // 导出文件的存储目录
+	BaseURL        string `json:"base_url"`        // 下载链接的前缀（对外访问的API地址）
+	LinkTTL        int    `json:"link_ttl"`        // 下载链接有效期(秒)
+	FileTTLHours   int    `json:"file_ttl_hours"`  // 导出文件保留时间(小时)，过期后由定时任务删除
+	MaxMessages    int    `json:"max_messages"`    // 单次导出的最大消息数
+	DefaultConsent bool   `json:"default_consent"` // 对方未设置导出授权时是否视为同意
+}
+
+// GetOffset 解析时间偏移，支持time.ParseDuration格式及"d"（天）单位
+func (c ClockConfig) GetOffset() (time.Duration, error) {
+	value := strings.TrimSpace(c.Offset)
+	if value == "" {
+		return 0, nil
+	}
+
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimLeft(value, "+-")
+
+	var offset time.Duration
+	if days, rest, ok := strings.Cut(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("无效的时间偏移: %s", c.Offset)
+		}
+		offset = time.Duration(n) * 24 * time.Hour
+		value = rest
+	}
+	if value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("无效的时间偏移: %s", c.Offset)
+		}
+		offset += d
+	}
+
+	if negative {
+		offset = -offset
+	}
+	return offset, nil
+}
+
+// RetentionPolicy 单类数据的保留策略
+type RetentionPolicy struct {
+	Enabled bool `json:"enabled"` // 是否执行清理
+	Days    int  `json:"days"`    // 保留天数，超过后清理
+}
+
+// RetentionConfig 数据保留配置（按数据类别）
+type RetentionConfig struct {
+	Logs      RetentionPolicy      `json:"logs"`      // 系统日志（MongoDB system_logs）
+	Messages  RetentionPolicy      `json:"messages"`  // 聊天消息（MongoDB chat_messages）
+	Audit     RetentionPolicy      `json:"audit"`     // 管理员审计日志（MySQL admin_logs）
+	Sessions  RetentionPolicy      `json:"sessions"`  // 会话/令牌数据（Redis）
+	Analytics RetentionPolicy      `json:"analytics"` // 统计分析事件（MongoDB analytics_events）
+	Archive   MessageArchiveConfig `json:"archive"`   // 聊天消息归档
+}
+
+// 消息归档目标
+const (
+	ArchiveTargetMongoDB = "mongodb" // 写入MongoDB冷集合chat_messages_archive
+	ArchiveTargetStorage = "storage" // 按批导出为gzip压缩的JSON Lines文件，写入附件使用的对象存储
+)
+
+// MessageArchiveConfig 聊天消息归档配置
+// 发送超过days天的消息复制到归档目标后从chat_messages删除；管理员可按会话设置不同的天数或不归档
+type MessageArchiveConfig struct {
+	Enabled   bool   `json:"enabled"`
+	Days      int    `json:"days"`       // 消息在热集合中保留的天数，需小于messages的保留天数
+	Target    string `json:"target"`     // 归档目标: mongodb, storage
+	KeyPrefix string `json:"key_prefix"` // storage: 对象key前缀
+	BatchSize int    `json:"batch_size"` // 每批归档并删除的消息数
+}
+
+// Policies 按类别名返回保留策略
+func (c RetentionConfig) Policies() map[string]RetentionPolicy {
+	return map[string]RetentionPolicy{
+		"logs":      c.Logs,
+		"messages":  c.Messages,
+		"audit":     c.Audit,
+		"sessions":  c.Sessions,
+		"analytics": c.Analytics,
+	}
+}
+
+// DistributedConfig 分布式定时任务配置
+type DistributedConfig struct {
+	HeartbeatInterval int `json:"heartbeat_interval"` // 实例心跳间隔(秒)
+	InstanceTTL       int `json:"instance_ttl"`       // 实例存活窗口(秒)，超过该时间未心跳视为失效
+}
+
+// DefaultDistributedConfig 默认分布式定时任务配置
+func DefaultDistributedConfig() DistributedConfig {
+	return DistributedConfig{
+		HeartbeatInterval: 10,
+		InstanceTTL:       30,
+	}
+}
+
+// GetHeartbeatInterval 获取心跳间隔
+func (c DistributedConfig) GetHeartbeatInterval() time.Duration {
+	return time.Duration(c.HeartbeatInterval) * time.Second
+}
+
+// GetInstanceTTL 获取实例存活窗口
+func (c DistributedConfig) GetInstanceTTL() time.Duration {
+	return time.Duration(c.InstanceTTL) * time.Second
 }
 
 // Load 加载配置
@@ -88,8 +1056,17 @@ func Load() (*Config, error) {
 	setDefaults(cfg)
 
 	// 从配置文件加载
-	if err := loadFromFile(cfg); err != nil {
+	report, err := loadFromFile(cfg)
+	if err != nil {
 		fmt.Printf("警告: 配置文件加载失败，使用默认配置: %v\n", err)
+	} else {
+		// 未知及废弃配置项：默认仅警告，严格模式下拒绝启动
+		report.PrintWarnings()
+		if IsStrict() {
+			if err := report.StrictError(); err != nil {
+				return nil, fmt.Errorf("配置严格模式检查失败: %w", err)
+			}
+		}
 	}
 
 	// 从环境变量覆盖配置
@@ -110,7 +1087,38 @@ func setDefaults(cfg *Config) {
 	cfg.Server.Port = 8080
 	cfg.Server.Mode = "debug"
 	cfg.Server.ReadTimeout = 30
+	cfg.Server.ReadHeaderTimeout = 10
 	cfg.Server.WriteTimeout = 30
+	cfg.Server.IdleTimeout = 60
+	cfg.Server.MaxHeaderBytes = 64 * 1024
+	cfg.Server.H2C = false
+	cfg.Server.TCPKeepAlive = TCPKeepAliveConfig{
+		Enabled:  true,
+		Idle:     30,
+		Interval: 10,
+		Count:    3,
+	}
+	// 请求体默认不超过1MB，用户批量导入上传CSV文件（10MB，另加multipart开销），上传聊天附件（20MB，另加multipart开销）
+	cfg.Server.BodyLimit = BodyLimitConfig{
+		Enabled:      true,
+		DefaultBytes: 1 << 20,
+		Routes: []BodyLimitRoute{
+			{Route: "POST /admin/v1/admin/users/import", Bytes: 11 << 20},
+			{Route: "POST /api/v1/user/attachments", Bytes: 21 << 20},
+		},
+	}
+	cfg.Server.Compression = CompressionConfig{
+		Enabled:  true,
+		Level:    -1,
+		MinBytes: 1024,
+		ContentTypes: []string{
+			"application/json",
+			"application/problem+json",
+			"text/plain",
+			"text/html",
+			"text/csv",
+		},
+	}
 
 	// 数据库默认配置
 	cfg.Database.Host = "localhost"
@@ -123,22 +1131,57 @@ func setDefaults(cfg *Config) {
 	cfg.Database.MaxOpenConns = 100
 	cfg.Database.ConnMaxLifetime = 3600
 
+	cfg.Database.Auth.Plugin = "password"
+	cfg.Database.Auth.TokenTTL = 600
+
 	// Redis默认配置
 	cfg.Redis.Host = "localhost"
 	cfg.Redis.Port = 6379
 	cfg.Redis.Password = ""
 	cfg.Redis.Database = 0
 	cfg.Redis.PoolSize = 10
+	cfg.Redis.Mode = "standalone"
+	cfg.Redis.ReadMode = "primary"
 
 	// MongoDB默认配置
 	cfg.MongoDB.URI = "mongodb://localhost:27017"
 	cfg.MongoDB.Database = "exchange"
 	cfg.MongoDB.Timeout = 10
 
+	// 缓存默认配置
+	cfg.Cache.Codec = "json"
+	cfg.Cache.Memory.MaxBytes = 64 << 20
+	cfg.Cache.Memory.Policy = "lru"
+
 	// JWT默认配置
 	cfg.JWT.SecretKey = "your-secret-key"
 	cfg.JWT.ExpirationHours = 24
 	cfg.JWT.Issuer = "exchange"
+	cfg.JWT.AccessTokenMinutes = 15
+	cfg.JWT.RefreshTokenDays = 30
+	cfg.JWT.Algorithm = "HS256"
+
+	// 两步验证默认配置：不强制任何角色启用
+	cfg.TwoFactor = TwoFactorConfig{
+		Issuer:             "Exchange",
+		Skew:               1,
+		BackupCodes:        10,
+		SetupMinutes:       10,
+		EnforcedUserRoles:  []string{},
+		EnforcedAdminRoles: []string{},
+	}
+
+	// 登录失败限制默认配置：15分钟内3次失败后开始等待，同一用户名10次或同一IP 50次失败后锁定15分钟
+	cfg.LoginThrottle = LoginThrottleConfig{
+		Enabled:              true,
+		WindowMinutes:        15,
+		FreeAttempts:         3,
+		BaseDelaySeconds:     1,
+		MaxDelaySeconds:      60,
+		UserLockoutThreshold: 10,
+		IPLockoutThreshold:   50,
+		LockoutMinutes:       15,
+	}
 
 	// 日志默认配置
 	cfg.Log.Level = "info"
@@ -157,33 +1200,345 @@ func setDefaults(cfg *Config) {
 	cfg.Log.AccessLogFile = "access.log"
 	cfg.Log.ErrorLogFile = "error.log"
 	cfg.Log.CronLogFile = "cron.log"
-}
-
-// loadFromFile 从配置文件加载
-func loadFromFile(cfg *Config) error {
-	configFile := "configs/development.json"
-	if os.Getenv("ENV") == "production" {
-		configFile = "configs/production.json"
+	cfg.Log.Backend = "slog"
+	cfg.Log.Sampling = LogSamplingConfig{Enabled: false, Initial: 100, Thereafter: 100}
+	cfg.Log.Async = LogAsyncConfig{Enabled: false, BufferSize: 8192, BatchSize: 256, FlushIntervalMs: 200, Overflow: "drop"}
+	cfg.Log.OTLP = LogOTLPConfig{
+		Enabled:         false,
+		Endpoint:        "http://localhost:4318/v1/logs",
+		QueueSize:       10000,
+		BatchSize:       512,
+		FlushIntervalMs: 1000,
+		TimeoutMs:       5000,
 	}
-
-	data, err := os.ReadFile(configFile)
-	if err != nil {
-		return err
+	cfg.Log.Alert = LogAlertConfig{
+		Enabled:         false,
+		WindowSeconds:   60,
+		Threshold:       20,
+		CooldownSeconds: 300,
+		Webhook:         LogAlertWebhook{TimeoutMs: 5000},
+		Email:           LogAlertEmailConfig{Port: 587},
+	}
+	cfg.Log.Access = LogAccessConfig{BodySampleRate: 0.1, MaxBodyBytes: 4096, SkipPaths: []string{}}
+	cfg.Log.Redact = LogRedactConfig{
+		Enabled:     true,
+		Keys:        []string{"password", "passwd", "secret", "token", "authorization", "cookie", "api_key", "card_number", "cvv"},
+		Mask:        "***",
+		CardNumbers: true,
 	}
 
-	return json.Unmarshal(data, cfg)
-}
+	// 监控界面默认配置
+	cfg.Monitor.Address = ":8081"
+	cfg.Monitor.ControlRoles = []string{"super"}
 
-// loadFromEnv 从环境变量加载
-func loadFromEnv(cfg *Config) {
-	// 服务器配置
-	if val := os.Getenv("SERVER_ADDRESS"); val != "" {
-		cfg.Server.Address = val
-	}
-	if val := os.Getenv("SERVER_PORT"); val != "" {
-		if port, err := strconv.Atoi(val); err == nil {
-			cfg.Server.Port = port
-		}
+	// 分布式定时任务默认配置
+	cfg.Distributed = DefaultDistributedConfig()
+
+	// 用户账户默认配置
+	cfg.Account.DeletionGraceDays = 7
+	cfg.Account.InviteURL = "http://localhost:8080/invite?token="
+	cfg.Account.InviteTTLHours = 72
+	cfg.Account.PasswordResetURL = "http://localhost:8080/reset-password?token="
+	cfg.Account.PasswordResetTTLMinutes = 30
+	cfg.Account.PasswordResetLimit = 3
+	cfg.Account.PasswordResetWindowMinutes = 60
+	cfg.Account.EmailVerificationRequired = true
+	cfg.Account.EmailVerificationURL = "http://localhost:8080/verify-email?token="
+	cfg.Account.EmailVerificationTTLHours = 24
+	cfg.Account.EmailVerificationLimit = 3
+	cfg.Account.EmailVerificationWindowMinutes = 60
+
+	// WebSocket默认配置
+	cfg.WebSocket.ReplayBufferSize = 200
+	cfg.WebSocket.ReplayTTL = 300
+	cfg.WebSocket.HeartbeatTimeout = 60
+	cfg.WebSocket.PresenceTTL = 90
+	cfg.WebSocket.SendBufferSize = 256
+	cfg.WebSocket.MaxMessageBytes = 64 << 10
+	cfg.WebSocket.DrainTimeout = 10
+	cfg.WebSocket.LastSeenDays = 30
+	cfg.WebSocket.TypingTimeout = 6
+	cfg.WebSocket.MaxSubscriptions = 200
+
+	// 聊天消息默认配置
+	cfg.Chat.EditWindowMinutes = 15
+	cfg.Chat.DeleteWindowMinutes = 0
+	cfg.Chat.MaxEdits = 20
+	cfg.Chat.MaxGroupMembers = 500
+	cfg.Chat.MaxGroupsPerUser = 50
+	cfg.Chat.UnreadCacheTTL = 86400
+
+	// 聊天附件默认配置
+	cfg.Attachment.Driver = AttachmentDriverLocal
+	cfg.Attachment.MaxSizeMB = 20
+	cfg.Attachment.AllowedTypes = []string{"image/*", "audio/*", "video/*", "application/pdf", "application/zip", "text/plain"}
+	cfg.Attachment.URLTTL = 300
+	cfg.Attachment.KeyPrefix = "attachments"
+	cfg.Attachment.Local.Dir = "./storage/attachments"
+	cfg.Attachment.Local.BaseURL = "http://localhost:8080"
+	cfg.Attachment.S3.Region = "us-east-1"
+	cfg.Attachment.S3.AccessKeyName = "attachment_access_key"
+	cfg.Attachment.S3.SecretKeyName = "attachment_secret_key"
+	cfg.Attachment.S3.TimeoutMs = 30000
+
+	// 密钥提供者默认配置
+	cfg.Secrets.Provider = "env"
+	cfg.Secrets.Dir = "/run/secrets"
+	cfg.Secrets.EnvPrefix = "SECRET_"
+
+	// 内部服务请求签名默认配置
+	cfg.ServiceAuth.Enabled = false
+	cfg.ServiceAuth.ServiceName = "exchange"
+	cfg.ServiceAuth.ReplayWindow = 300
+	cfg.ServiceAuth.KeyCacheTTL = 60
+
+	// API key签名认证默认配置
+	cfg.APIKeyAuth.Enabled = false
+	cfg.APIKeyAuth.ReplayWindow = 60
+	cfg.APIKeyAuth.KeyCacheTTL = 60
+	cfg.APIKeyAuth.MaxUserKeys = 10
+	cfg.APIKeyAuth.UserKeyRateLimit = 600
+	cfg.APIKeyAuth.UserKeyWindowSeconds = 60
+	cfg.APIKeyAuth.UserKeyMaxTTLDays = 365
+
+	// 人机验证默认配置
+	cfg.Captcha.Enabled = false
+	cfg.Captcha.Provider = "turnstile"
+	cfg.Captcha.SecretName = "captcha_secret"
+	cfg.Captcha.FailureThreshold = 3
+	cfg.Captcha.WindowMinutes = 15
+	cfg.Captcha.MinScore = 0.5
+	cfg.Captcha.TimeoutMs = 5000
+
+	// 新设备和异地登录检测默认配置
+	cfg.LoginRisk.Enabled = true
+	cfg.LoginRisk.Action = LoginRiskActionNotify
+	cfg.LoginRisk.DeviceHeader = "X-Device-ID"
+	cfg.LoginRisk.ConfirmURL = "http://localhost:8080/confirm-login?token="
+	cfg.LoginRisk.ConfirmTTLMinutes = 30
+	cfg.LoginRisk.MaxDevices = 20
+	cfg.LoginRisk.GeoIP.Provider = "none"
+
+	// 第三方登录默认配置
+	cfg.OAuth.Enabled = false
+	cfg.OAuth.StateTTL = 600
+
+	// 审计日志默认配置
+	cfg.Audit.Enabled = true
+	cfg.Audit.QueueSize = 1024
+
+	// 用户数据导出默认配置
+	cfg.Export.Dir = "exports"
+	cfg.Export.BaseURL = "http://localhost:8080"
+	cfg.Export.LinkTTL = 900
+	cfg.Export.FileTTLHours = 24
+	cfg.Export.MaxMessages = 50000
+	cfg.Export.DefaultConsent = false
+
+	// 领域事件日志默认配置
+	cfg.EventLog.Enabled = true
+	cfg.EventLog.ExportDir = "event-exports"
+
+	// 管理端限流和异常检测默认配置
+	cfg.AdminGuard = AdminGuardConfig{
+		Enabled:           true,
+		RequestsPerMinute: 120,
+		WritesPerMinute:   30,
+		DeleteThreshold:   20,
+		BulkRoutes: []string{
+			"GET /admin/v1/admin/users",
+			"POST /admin/v1/admin/users/import",
+			"GET /admin/v1/admin/retention/report",
+		},
+		OffHoursStart:       0,
+		OffHoursEnd:         6,
+		OffHoursBulkLimit:   3,
+		DetectWindowSeconds: 600,
+		SuspendMinutes:      30,
+	}
+
+	// IP访问控制默认配置
+	cfg.IPAccess = IPAccessConfig{
+		Enabled:        true,
+		AdminAllowlist: false,
+		Allow:          []string{},
+		Deny:           []string{},
+		CacheSeconds:   10,
+	}
+
+	// 角色权限默认配置
+	cfg.RBAC = RBACConfig{
+		CacheSeconds: 30,
+	}
+
+	// 维护模式默认配置：管理后台、健康检查和指标导出不受影响
+	cfg.Maintenance = MaintenanceConfig{
+		CheckSeconds:   5,
+		RetryAfter:     300,
+		ExemptPrefixes: []string{"/admin/", "/ping", "/metrics"},
+	}
+
+	// 接口限流默认配置：默认按IP每分钟600次，登录、注册和找回密码单独限制
+	cfg.RateLimit = RateLimitConfig{
+		Enabled: true,
+		Default: RateLimitPolicy{Algorithm: RateLimitSlidingWindow, Key: RateLimitByIP, Limit: 600, WindowSeconds: 60},
+		Routes: []RateLimitPolicy{
+			{Route: "POST /api/v1/user/login", Algorithm: RateLimitSlidingWindow, Key: RateLimitByIP, Limit: 10, WindowSeconds: 60},
+			{Route: "POST /api/v1/user/register", Algorithm: RateLimitSlidingWindow, Key: RateLimitByIP, Limit: 5, WindowSeconds: 60},
+			{Route: "POST /api/v1/user/password/forgot", Algorithm: RateLimitSlidingWindow, Key: RateLimitByIP, Limit: 5, WindowSeconds: 60},
+			{Route: "POST /admin/v1/auth/login", Algorithm: RateLimitSlidingWindow, Key: RateLimitByIP, Limit: 10, WindowSeconds: 60},
+			{Route: "POST /api/v1/user/exports/chats", Algorithm: RateLimitTokenBucket, Key: RateLimitByUser, Limit: 3, WindowSeconds: 3600},
+		},
+	}
+
+	// 并发请求数限制默认配置：登录和注册计算密码哈希，会话导出查询大量消息，单独限制
+	cfg.Concurrency = ConcurrencyLimitConfig{
+		Enabled:     true,
+		MaxInFlight: 1000,
+		Routes: []ConcurrencyLimitRoute{
+			{Route: "POST /api/v1/user/login", Limit: 64},
+			{Route: "POST /api/v1/user/register", Limit: 32},
+			{Route: "POST /admin/v1/auth/login", Limit: 16},
+			{Route: "POST /api/v1/user/exports/chats", Limit: 8},
+		},
+		ExemptRoutes: []string{
+			"GET /ping",
+			"GET /api/v1/system/ping",
+			"GET /admin/v1/system/ping",
+			"GET /api/v1/ws",
+		},
+	}
+
+	// 依赖服务熔断默认配置
+	cfg.CircuitBreaker = CircuitBreakerConfig{
+		Enabled: true,
+		Default: CircuitBreakerPolicy{FailureThreshold: 5, OpenSeconds: 30, HalfOpenProbes: 3},
+		Dependencies: map[string]CircuitBreakerPolicy{
+			"price_api": {FailureThreshold: 3, OpenSeconds: 60, HalfOpenProbes: 1},
+		},
+		FailFast: []string{"mysql", "redis"},
+		ExemptRoutes: []string{
+			"GET /ping",
+			"GET /api/v1/system/ping",
+			"GET /api/v1/system/info",
+			"GET /admin/v1/system/ping",
+			"GET /admin/v1/system/info",
+		},
+	}
+
+	// 请求处理超时默认配置：文件下载流式写出响应，不限制处理时间；上传附件需要写入对象存储，放宽到120秒；WebSocket接管连接，不能缓冲响应
+	cfg.RequestTimeout = RequestTimeoutConfig{
+		Enabled: true,
+		Default: 20,
+		Routes: []RequestTimeoutRoute{
+			{Route: "GET /api/v1/exports/:id/download", Seconds: 0},
+			{Route: "GET /api/v1/attachments/download", Seconds: 0},
+			{Route: "POST /api/v1/user/attachments", Seconds: 120},
+			{Route: "GET /api/v1/ws", Seconds: 0},
+			{Route: "GET /internal/v1/event-exports/:date/files/:name", Seconds: 0},
+		},
+	}
+
+	// 指标导出默认配置
+	cfg.Metrics.Enabled = true
+	cfg.Metrics.Path = "/metrics"
+
+	// 分布式追踪默认配置
+	cfg.Tracing = TracingConfig{
+		Enabled:     false,
+		Endpoint:    "http://localhost:4318/v1/traces",
+		SampleRatio: 1,
+		TimeoutMs:   10000,
+	}
+
+	// 接口文档示例默认配置
+	cfg.I18n.LocalesDir = ""
+	cfg.I18n.HotReload = true
+	cfg.I18n.Fallbacks = map[string][]string{
+		"zh-TW": {"zh", "en"},
+		"zh-HK": {"zh-TW", "zh", "en"},
+	}
+
+	cfg.DocExamples.Enabled = false
+	cfg.DocExamples.File = "docs/api-examples.json"
+	cfg.DocExamples.MaxBodyBytes = 64 * 1024
+
+	// 错误上报默认配置
+	cfg.ErrorTracking.Enabled = false
+	cfg.ErrorTracking.Provider = "sentry"
+	cfg.ErrorTracking.MinSeverity = "high"
+	cfg.ErrorTracking.QueueSize = 256
+	cfg.ErrorTracking.TimeoutMs = 5000
+	cfg.ErrorTracking.Workers = DefaultWorkerPoolConfig()
+	cfg.ErrorTracking.Workers.MaxWorkers = 4
+
+	// 用户通知发送默认配置
+	cfg.Notification.Async = true
+	cfg.Notification.QueueSize = 1000
+	cfg.Notification.TimeoutMs = 10000
+	cfg.Notification.Workers = DefaultWorkerPoolConfig()
+
+	// 邮件发送默认配置：只写日志，生产环境改为smtp
+	cfg.Email = EmailConfig{
+		Provider: "log",
+		From:     "no-reply@example.com",
+		FromName: "Exchange",
+		SMTP: SMTPConfig{
+			Host:           "localhost",
+			Port:           587,
+			PasswordSecret: "smtp_password",
+			Security:       "starttls",
+		},
+	}
+
+	// 离线推送默认配置：各平台只写日志，生产环境改为fcm和apns
+	cfg.Push = PushConfig{
+		Enabled: true,
+		Platforms: map[string]string{
+			PushPlatformIOS:     "log",
+			PushPlatformAndroid: "log",
+			PushPlatformWeb:     "log",
+		},
+		MaxAttempts:       3,
+		JobTTL:            3600,
+		MaxDevicesPerUser: 10,
+		TimeoutMs:         10000,
+		FCM:               FCMConfig{CredentialsName: "fcm_credentials"},
+		APNs:              APNsConfig{KeyName: "apns_key"},
+	}
+
+	// 数据保留默认配置
+	cfg.Retention = RetentionConfig{
+		Logs:      RetentionPolicy{Enabled: true, Days: 30},
+		Messages:  RetentionPolicy{Enabled: true, Days: 365},
+		Audit:     RetentionPolicy{Enabled: true, Days: 730},
+		Sessions:  RetentionPolicy{Enabled: true, Days: 30},
+		Analytics: RetentionPolicy{Enabled: true, Days: 90},
+		Archive: MessageArchiveConfig{
+			Enabled:   false,
+			Days:      180,
+			Target:    ArchiveTargetMongoDB,
+			KeyPrefix: "archive/messages",
+			BatchSize: 500,
+		},
+	}
+}
+
+// loadFromFile 从配置文件加载（base + 环境覆盖层 + 本地覆盖层）
+func loadFromFile(cfg *Config) (*LoadReport, error) {
+	return loadLayers(cfg, GetConfigDir(), GetEnv())
+}
+
+// loadFromEnv 从环境变量加载
+func loadFromEnv(cfg *Config) {
+	// 服务器配置
+	if val := os.Getenv("SERVER_ADDRESS"); val != "" {
+		cfg.Server.Address = val
+	}
+	if val := os.Getenv("SERVER_PORT"); val != "" {
+		if port, err := strconv.Atoi(val); err == nil {
+			cfg.Server.Port = port
+		}
 	}
 	if val := os.Getenv("SERVER_MODE"); val != "" {
 		cfg.Server.Mode = val
@@ -208,6 +1563,14 @@ func loadFromEnv(cfg *Config) {
 		cfg.Database.Database = val
 	}
 
+	// MongoDB配置
+	if val := os.Getenv("MONGODB_USERNAME"); val != "" {
+		cfg.MongoDB.Username = val
+	}
+	if val := os.Getenv("MONGODB_PASSWORD"); val != "" {
+		cfg.MongoDB.Password = val
+	}
+
 	// Redis配置
 	if val := os.Getenv("REDIS_HOST"); val != "" {
 		cfg.Redis.Host = val
@@ -220,11 +1583,115 @@ func loadFromEnv(cfg *Config) {
 	if val := os.Getenv("REDIS_PASSWORD"); val != "" {
 		cfg.Redis.Password = val
 	}
+	if val := os.Getenv("REDIS_MODE"); val != "" {
+		cfg.Redis.Mode = val
+	}
+	// 格式: host1:26379,host2:26379
+	if val := os.Getenv("REDIS_ADDRS"); val != "" {
+		cfg.Redis.Addrs = nil
+		for _, addr := range strings.Split(val, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				cfg.Redis.Addrs = append(cfg.Redis.Addrs, addr)
+			}
+		}
+	}
+	if val := os.Getenv("REDIS_MASTER_NAME"); val != "" {
+		cfg.Redis.MasterName = val
+	}
+	if val := os.Getenv("REDIS_SENTINEL_PASSWORD"); val != "" {
+		cfg.Redis.SentinelPassword = val
+	}
 
 	// JWT配置
 	if val := os.Getenv("JWT_SECRET_KEY"); val != "" {
 		cfg.JWT.SecretKey = val
 	}
+
+	// 分布式定时任务配置
+	if val := os.Getenv("CRON_HEARTBEAT_INTERVAL"); val != "" {
+		if interval, err := strconv.Atoi(val); err == nil {
+			cfg.Distributed.HeartbeatInterval = interval
+		}
+	}
+	if val := os.Getenv("CRON_INSTANCE_TTL"); val != "" {
+		if ttl, err := strconv.Atoi(val); err == nil {
+			cfg.Distributed.InstanceTTL = ttl
+		}
+	}
+
+	// 日志配置
+	if val := os.Getenv("LOG_LEVEL"); val != "" {
+		cfg.Log.Level = val
+	}
+	if val := os.Getenv("LOG_BACKEND"); val != "" {
+		cfg.Log.Backend = val
+	}
+	// 与OpenTelemetry SDK的环境变量保持一致
+	if val := os.Getenv("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT"); val != "" {
+		cfg.Log.OTLP.Enabled = true
+		cfg.Log.OTLP.Endpoint = val
+	}
+	// 格式: cron=debug,cache=warn
+	if val := os.Getenv("LOG_MODULES"); val != "" {
+		if cfg.Log.Modules == nil {
+			cfg.Log.Modules = make(map[string]string)
+		}
+		for _, item := range strings.Split(val, ",") {
+			if module, level, ok := strings.Cut(strings.TrimSpace(item), "="); ok {
+				cfg.Log.Modules[strings.TrimSpace(module)] = strings.TrimSpace(level)
+			}
+		}
+	}
+
+	// 模拟时钟偏移（只在配置中启用了simulated时生效）
+	if val := os.Getenv("CLOCK_OFFSET"); val != "" {
+		cfg.Clock.Offset = val
+	}
+
+	// 导出下载链接前缀
+	if val := os.Getenv("EXPORT_BASE_URL"); val != "" {
+		cfg.Export.BaseURL = val
+	}
+
+	// 聊天附件存储
+	if val := os.Getenv("ATTACHMENT_DRIVER"); val != "" {
+		cfg.Attachment.Driver = val
+	}
+	if val := os.Getenv("ATTACHMENT_BASE_URL"); val != "" {
+		cfg.Attachment.Local.BaseURL = val
+	}
+	if val := os.Getenv("ATTACHMENT_S3_ENDPOINT"); val != "" {
+		cfg.Attachment.S3.Endpoint = val
+	}
+	if val := os.Getenv("ATTACHMENT_S3_BUCKET"); val != "" {
+		cfg.Attachment.S3.Bucket = val
+	}
+
+	// 错误目录文件
+	if val := os.Getenv("ERROR_CATALOG_FILE"); val != "" {
+		cfg.ErrorCatalog.File = val
+	}
+
+	// 外部翻译文件目录
+	if val := os.Getenv("I18N_LOCALES_DIR"); val != "" {
+		cfg.I18n.LocalesDir = val
+	}
+
+	// 指标抓取令牌
+	if val := os.Getenv("METRICS_TOKEN"); val != "" {
+		cfg.Metrics.Token = val
+	}
+
+	// 与OpenTelemetry SDK的环境变量保持一致
+	if val := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); val != "" {
+		cfg.Tracing.Enabled = true
+		cfg.Tracing.Endpoint = val
+	}
+
+	// 与Sentry SDK的环境变量保持一致
+	if val := os.Getenv("SENTRY_DSN"); val != "" {
+		cfg.ErrorTracking.DSN = val
+	}
 }
 
 // validate 验证配置
@@ -233,6 +1700,44 @@ func validate(cfg *Config) error {
 	if cfg.Server.Port <= 0 || cfg.Server.Port > 65535 {
 		return fmt.Errorf("无效的服务器端口: %d", cfg.Server.Port)
 	}
+	if cfg.Server.ReadTimeout < 0 || cfg.Server.ReadHeaderTimeout < 0 || cfg.Server.WriteTimeout < 0 || cfg.Server.IdleTimeout < 0 {
+		return fmt.Errorf("服务器超时时间不能为负数")
+	}
+	if cfg.Server.MaxHeaderBytes <= 0 {
+		return fmt.Errorf("请求头最大字节数必须大于0")
+	}
+	if keepAlive := cfg.Server.TCPKeepAlive; keepAlive.Enabled && (keepAlive.Idle <= 0 || keepAlive.Interval <= 0 || keepAlive.Count <= 0) {
+		return fmt.Errorf("TCP keepalive的空闲时间、探测间隔和探测次数必须大于0")
+	}
+	if bodyLimit := cfg.Server.BodyLimit; bodyLimit.Enabled {
+		if bodyLimit.DefaultBytes < 0 {
+			return fmt.Errorf("请求体大小上限不能小于0")
+		}
+		seen := make(map[string]bool, len(bodyLimit.Routes))
+		for _, route := range bodyLimit.Routes {
+			if len(strings.Fields(route.Route)) != 2 {
+				return fmt.Errorf("无效的请求体大小限制路由: %s", route.Route)
+			}
+			if seen[route.Route] {
+				return fmt.Errorf("请求体大小限制路由重复: %s", route.Route)
+			}
+			seen[route.Route] = true
+			if route.Bytes < 0 {
+				return fmt.Errorf("请求体大小上限不能小于0: %s", route.Route)
+			}
+		}
+	}
+	if compression := cfg.Server.Compression; compression.Enabled {
+		if compression.Level != -1 && (compression.Level < 1 || compression.Level > 9) {
+			return fmt.Errorf("无效的响应压缩级别: %d", compression.Level)
+		}
+		if compression.MinBytes < 0 {
+			return fmt.Errorf("响应压缩的最小大小不能小于0")
+		}
+		if len(compression.ContentTypes) == 0 {
+			return fmt.Errorf("开启响应压缩时压缩的媒体类型不能为空")
+		}
+	}
 
 	// 验证数据库配置
 	if cfg.Database.Host == "" {
@@ -248,22 +1753,761 @@ func validate(cfg *Config) error {
 		return fmt.Errorf("数据库名不能为空")
 	}
 
+	// 插件名在创建连接时检查（支持通过database.RegisterAuthPlugin注册自定义插件）
+	if cfg.Database.Auth.Plugin == "" {
+		return fmt.Errorf("数据库认证插件不能为空")
+	}
+	if cfg.Database.Auth.Plugin == "command" && len(cfg.Database.Auth.Command) == 0 {
+		return fmt.Errorf("数据库认证命令不能为空")
+	}
+	if cfg.Database.Auth.Plugin != "password" && !cfg.Database.TLS.Enabled {
+		return fmt.Errorf("数据库认证插件 %s 需要启用TLS", cfg.Database.Auth.Plugin)
+	}
+	if err := validateTLS("数据库", cfg.Database.TLS); err != nil {
+		return err
+	}
+
+	// 验证MongoDB配置
+	switch cfg.MongoDB.AuthMechanism {
+	case "", "SCRAM-SHA-256", "SCRAM-SHA-1", "MONGODB-X509", "MONGODB-AWS":
+	default:
+		return fmt.Errorf("无效的MongoDB认证机制: %s", cfg.MongoDB.AuthMechanism)
+	}
+	if cfg.MongoDB.AuthMechanism == "MONGODB-X509" && (!cfg.MongoDB.TLS.Enabled || cfg.MongoDB.TLS.CertFile == "") {
+		return fmt.Errorf("MongoDB X.509认证需要启用TLS并配置客户端证书")
+	}
+	if err := validateTLS("MongoDB", cfg.MongoDB.TLS); err != nil {
+		return err
+	}
+
 	// 验证Redis配置
-	if cfg.Redis.Host == "" {
-		return fmt.Errorf("Redis主机不能为空")
+	if err := validateRedis(cfg.Redis); err != nil {
+		return err
+	}
+
+	// 验证缓存配置
+	validCodecs := map[string]bool{"json": true, "msgpack": true, "gob": true}
+	if !validCodecs[cfg.Cache.Codec] {
+		return fmt.Errorf("无效的缓存序列化方式: %s", cfg.Cache.Codec)
 	}
-	if cfg.Redis.Port <= 0 || cfg.Redis.Port > 65535 {
-		return fmt.Errorf("无效的Redis端口: %d", cfg.Redis.Port)
+	for prefix, codec := range cfg.Cache.PrefixCodecs {
+		if !validCodecs[codec] {
+			return fmt.Errorf("无效的缓存序列化方式: %s（前缀 %s）", codec, prefix)
+		}
+	}
+	if cfg.Cache.GzipMinBytes < 0 {
+		return fmt.Errorf("缓存压缩阈值不能为负数")
+	}
+	if cfg.Cache.Memory.MaxBytes < 0 {
+		return fmt.Errorf("内存缓存字节数上限不能为负数")
+	}
+	if cfg.Cache.Memory.Policy != "lru" && cfg.Cache.Memory.Policy != "lfu" {
+		return fmt.Errorf("无效的内存缓存淘汰策略: %s", cfg.Cache.Memory.Policy)
+	}
+	for prefix, policy := range cfg.Cache.TTLPolicies {
+		if policy.TTLSeconds <= 0 {
+			return fmt.Errorf("缓存过期策略 %s 的ttl_seconds必须大于0", prefix)
+		}
+		if policy.Jitter < 0 || policy.Jitter >= 1 {
+			return fmt.Errorf("缓存过期策略 %s 的jitter必须在0到1之间", prefix)
+		}
 	}
 
 	// 验证JWT配置
-	if cfg.JWT.SecretKey == "" {
-		return fmt.Errorf("JWT密钥不能为空")
+	switch cfg.JWT.Algorithm {
+	case "HS256":
+		if cfg.JWT.SecretKey == "" {
+			return fmt.Errorf("JWT密钥不能为空")
+		}
+	case "RS256", "EdDSA":
+		signingKeyFound := false
+		keyIDs := make(map[string]bool, len(cfg.JWT.Keys))
+		for _, key := range cfg.JWT.Keys {
+			if key.KeyID == "" || keyIDs[key.KeyID] {
+				return fmt.Errorf("JWT key的key_id不能为空且不能重复")
+			}
+			keyIDs[key.KeyID] = true
+			if key.VerifyUntil != "" {
+				if _, err := time.Parse(time.RFC3339, key.VerifyUntil); err != nil {
+					return fmt.Errorf("JWT key %s 的verify_until必须是RFC3339时间: %w", key.KeyID, err)
+				}
+			}
+			if key.KeyID == cfg.JWT.SigningKeyID {
+				signingKeyFound = true
+				if key.VerifyUntil != "" {
+					return fmt.Errorf("JWT签发key %s 不能设置verify_until", key.KeyID)
+				}
+			}
+		}
+		if !signingKeyFound {
+			return fmt.Errorf("JWT签发key %s 不在keys中", cfg.JWT.SigningKeyID)
+		}
+	default:
+		return fmt.Errorf("不支持的JWT签名算法: %s", cfg.JWT.Algorithm)
 	}
 	if cfg.JWT.ExpirationHours <= 0 {
 		return fmt.Errorf("JWT过期时间必须大于0")
 	}
+	if cfg.JWT.AccessTokenMinutes <= 0 || cfg.JWT.RefreshTokenDays <= 0 {
+		return fmt.Errorf("访问token和刷新token的有效期必须大于0")
+	}
+
+	// 验证两步验证配置
+	if cfg.TwoFactor.Issuer == "" || strings.Contains(cfg.TwoFactor.Issuer, ":") {
+		return fmt.Errorf("两步验证发行方不能为空且不能包含冒号")
+	}
+	if cfg.TwoFactor.Skew < 0 || cfg.TwoFactor.Skew > 3 {
+		return fmt.Errorf("两步验证时间偏差必须在0-3之间")
+	}
+	if cfg.TwoFactor.BackupCodes <= 0 || cfg.TwoFactor.BackupCodes > 20 {
+		return fmt.Errorf("两步验证备用码数量必须在1-20之间")
+	}
+	if cfg.TwoFactor.SetupMinutes <= 0 {
+		return fmt.Errorf("两步验证绑定有效期必须大于0")
+	}
+	for _, role := range cfg.TwoFactor.EnforcedUserRoles {
+		if role != "user" && role != "admin" {
+			return fmt.Errorf("无效的两步验证强制用户角色: %s", role)
+		}
+	}
+	for _, role := range cfg.TwoFactor.EnforcedAdminRoles {
+		if strings.TrimSpace(role) == "" {
+			return fmt.Errorf("两步验证强制管理员角色不能为空")
+		}
+	}
+
+	// 验证登录失败限制配置
+	if cfg.LoginThrottle.Enabled {
+		throttle := cfg.LoginThrottle
+		if throttle.WindowMinutes <= 0 || throttle.LockoutMinutes <= 0 {
+			return fmt.Errorf("登录失败统计窗口和锁定时长必须大于0")
+		}
+		if throttle.FreeAttempts < 0 {
+			return fmt.Errorf("登录失败免等待次数不能为负数")
+		}
+		if throttle.BaseDelaySeconds <= 0 || throttle.MaxDelaySeconds < throttle.BaseDelaySeconds {
+			return fmt.Errorf("登录失败等待时间必须大于0且上限不能小于初始值")
+		}
+		if throttle.UserLockoutThreshold <= throttle.FreeAttempts || throttle.IPLockoutThreshold <= throttle.FreeAttempts {
+			return fmt.Errorf("登录锁定阈值必须大于免等待次数")
+		}
+	}
 
+	// 验证用户账户配置
+	if cfg.Account.DeletionGraceDays < 0 {
+		return fmt.Errorf("注销冷静期不能为负数")
+	}
+	if cfg.Account.InviteTTLHours <= 0 {
+		return fmt.Errorf("邀请链接有效期必须大于0")
+	}
+	if cfg.Account.PasswordResetTTLMinutes <= 0 {
+		return fmt.Errorf("重置密码链接有效期必须大于0")
+	}
+	if cfg.Account.PasswordResetLimit <= 0 || cfg.Account.PasswordResetWindowMinutes <= 0 {
+		return fmt.Errorf("重置密码邮件数上限和统计窗口必须大于0")
+	}
+	if cfg.Account.EmailVerificationTTLHours <= 0 {
+		return fmt.Errorf("验证邮箱链接有效期必须大于0")
+	}
+	if cfg.Account.EmailVerificationLimit <= 0 || cfg.Account.EmailVerificationWindowMinutes <= 0 {
+		return fmt.Errorf("验证邮件数上限和统计窗口必须大于0")
+	}
+
+	// 验证WebSocket配置
+	if cfg.WebSocket.ReplayBufferSize <= 0 || cfg.WebSocket.ReplayTTL <= 0 {
+		return fmt.Errorf("WebSocket补发缓冲区大小和保留时间必须大于0")
+	}
+	if cfg.WebSocket.HeartbeatTimeout <= 0 || cfg.WebSocket.PresenceTTL < 3 || cfg.WebSocket.SendBufferSize <= 0 || cfg.WebSocket.MaxMessageBytes <= 0 || cfg.WebSocket.DrainTimeout < 0 {
+		return fmt.Errorf("WebSocket心跳超时、发送队列长度和帧大小上限必须大于0，在线状态保留时间不能小于3秒")
+	}
+	if cfg.WebSocket.LastSeenDays <= 0 || cfg.WebSocket.TypingTimeout < 3 || cfg.WebSocket.MaxSubscriptions <= 0 {
+		return fmt.Errorf("WebSocket最后在线时间保留天数和在线状态订阅上限必须大于0，正在输入状态显示时间不能小于3秒")
+	}
+
+	// 验证聊天消息配置
+	if cfg.Chat.EditWindowMinutes <= 0 || cfg.Chat.MaxEdits <= 0 {
+		return fmt.Errorf("消息可修改时间和最多修改次数必须大于0")
+	}
+	if cfg.Chat.DeleteWindowMinutes < 0 {
+		return fmt.Errorf("消息可删除时间不能小于0")
+	}
+	if cfg.Chat.MaxGroupMembers < 2 || cfg.Chat.MaxGroupsPerUser <= 0 {
+		return fmt.Errorf("群聊最多成员数不能小于2，每个用户最多创建的群聊数必须大于0")
+	}
+	if cfg.Chat.UnreadCacheTTL <= 0 {
+		return fmt.Errorf("未读数缓存时间必须大于0")
+	}
+
+	// 验证聊天附件配置
+	if cfg.Attachment.MaxSizeMB <= 0 || cfg.Attachment.URLTTL <= 0 {
+		return fmt.Errorf("附件大小上限和下载链接有效期必须大于0")
+	}
+	if len(cfg.Attachment.AllowedTypes) == 0 {
+		return fmt.Errorf("允许的附件类型不能为空")
+	}
+	switch cfg.Attachment.Driver {
+	case AttachmentDriverLocal:
+		if cfg.Attachment.Local.Dir == "" || cfg.Attachment.Local.BaseURL == "" {
+			return fmt.Errorf("本地附件存储需要配置目录和下载链接前缀")
+		}
+	case AttachmentDriverS3, AttachmentDriverMinIO:
+		s3 := cfg.Attachment.S3
+		if s3.Bucket == "" || s3.Region == "" || s3.AccessKeyName == "" || s3.SecretKeyName == "" {
+			return fmt.Errorf("S3附件存储需要配置存储桶、区域和访问密钥名称")
+		}
+		if cfg.Attachment.Driver == AttachmentDriverMinIO && s3.Endpoint == "" {
+			return fmt.Errorf("MinIO附件存储需要配置服务地址")
+		}
+		if s3.TimeoutMs <= 0 {
+			return fmt.Errorf("S3请求超时必须大于0")
+		}
+	default:
+		return fmt.Errorf("不支持的附件存储驱动: %s", cfg.Attachment.Driver)
+	}
+
+	// 验证审计日志配置
+	if cfg.Audit.Enabled && cfg.Audit.QueueSize <= 0 {
+		return fmt.Errorf("审计日志队列长度必须大于0")
+	}
+
+	// 验证用户数据导出配置
+	if cfg.Export.Dir == "" || cfg.Export.BaseURL == "" {
+		return fmt.Errorf("导出目录和下载链接前缀不能为空")
+	}
+	if cfg.Export.LinkTTL <= 0 || cfg.Export.FileTTLHours <= 0 || cfg.Export.MaxMessages <= 0 {
+		return fmt.Errorf("导出链接有效期、文件保留时间和最大消息数必须大于0")
+	}
+
+	if cfg.EventLog.ExportDir == "" {
+		return fmt.Errorf("事件日志导出目录不能为空")
+	}
+
+	// 验证指标导出配置
+	if cfg.Metrics.Enabled && !strings.HasPrefix(cfg.Metrics.Path, "/") {
+		return fmt.Errorf("指标导出路径必须以/开头: %s", cfg.Metrics.Path)
+	}
+
+	// 验证分布式追踪配置
+	if cfg.Tracing.Enabled {
+		if cfg.Tracing.Endpoint == "" {
+			return fmt.Errorf("追踪数据导出地址不能为空")
+		}
+		if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+			return fmt.Errorf("追踪采样比例必须在0-1之间")
+		}
+		if cfg.Tracing.TimeoutMs <= 0 {
+			return fmt.Errorf("追踪数据导出超时必须大于0")
+		}
+	}
+
+	// 验证错误上报配置
+	if cfg.ErrorTracking.Enabled {
+		switch cfg.ErrorTracking.Provider {
+		case "sentry":
+			if cfg.ErrorTracking.DSN == "" {
+				return fmt.Errorf("错误上报使用sentry时dsn不能为空")
+			}
+		case "webhook":
+			if cfg.ErrorTracking.URL == "" {
+				return fmt.Errorf("错误上报使用webhook时url不能为空")
+			}
+		default:
+			return fmt.Errorf("不支持的错误上报方式: %s", cfg.ErrorTracking.Provider)
+		}
+		switch cfg.ErrorTracking.MinSeverity {
+		case "low", "medium", "high", "critical":
+		default:
+			return fmt.Errorf("无效的错误上报级别: %s", cfg.ErrorTracking.MinSeverity)
+		}
+		if cfg.ErrorTracking.QueueSize <= 0 || cfg.ErrorTracking.TimeoutMs <= 0 {
+			return fmt.Errorf("错误上报队列长度和超时时间必须大于0")
+		}
+		if err := cfg.ErrorTracking.Workers.validate("错误上报"); err != nil {
+			return err
+		}
+	}
+
+	// 验证用户通知发送配置
+	if cfg.Notification.Async {
+		if cfg.Notification.QueueSize <= 0 || cfg.Notification.TimeoutMs <= 0 {
+			return fmt.Errorf("通知发送队列长度和超时时间必须大于0")
+		}
+		if err := cfg.Notification.Workers.validate("通知发送"); err != nil {
+			return err
+		}
+	}
+
+	// 验证邮件发送配置
+	if cfg.Email.Provider == "" {
+		return fmt.Errorf("邮件发送方式不能为空")
+	}
+	if cfg.Email.Provider == "smtp" {
+		if cfg.Email.From == "" || cfg.Email.SMTP.Host == "" || cfg.Email.SMTP.Port <= 0 {
+			return fmt.Errorf("SMTP发件地址、服务器地址和端口不能为空")
+		}
+		switch cfg.Email.SMTP.Security {
+		case "starttls", "tls", "none":
+		default:
+			return fmt.Errorf("无效的SMTP连接加密方式: %s", cfg.Email.SMTP.Security)
+		}
+	}
+
+	// 验证离线推送配置
+	if cfg.Push.Enabled {
+		if cfg.Push.MaxAttempts <= 0 || cfg.Push.JobTTL <= 0 || cfg.Push.MaxDevicesPerUser <= 0 || cfg.Push.TimeoutMs <= 0 {
+			return fmt.Errorf("推送最多尝试次数、任务有效期、每个用户的设备数和请求超时必须大于0")
+		}
+		for platform, provider := range cfg.Push.Platforms {
+			switch platform {
+			case PushPlatformIOS, PushPlatformAndroid, PushPlatformWeb:
+			default:
+				return fmt.Errorf("无效的推送设备平台: %s", platform)
+			}
+			if provider == "" {
+				return fmt.Errorf("推送设备平台%s的推送服务不能为空", platform)
+			}
+			if provider == "fcm" && (cfg.Push.FCM.ProjectID == "" || cfg.Push.FCM.CredentialsName == "") {
+				return fmt.Errorf("FCM推送需要配置项目ID和服务账号密钥名称")
+			}
+			if provider == "apns" && (cfg.Push.APNs.TeamID == "" || cfg.Push.APNs.KeyID == "" || cfg.Push.APNs.KeyName == "" || cfg.Push.APNs.Topic == "") {
+				return fmt.Errorf("APNs推送需要配置团队ID、私钥ID、私钥名称和topic")
+			}
+		}
+	}
+
+	// 验证管理端限流和异常检测配置
+	if cfg.AdminGuard.Enabled {
+		guard := cfg.AdminGuard
+		if guard.RequestsPerMinute <= 0 || guard.WritesPerMinute <= 0 {
+			return fmt.Errorf("管理端每分钟请求数和写操作数上限必须大于0")
+		}
+		if guard.DeleteThreshold <= 0 || guard.OffHoursBulkLimit <= 0 || guard.DetectWindowSeconds <= 0 || guard.SuspendMinutes <= 0 {
+			return fmt.Errorf("管理端异常检测阈值、检测窗口和停用时长必须大于0")
+		}
+		if guard.OffHoursStart < 0 || guard.OffHoursStart > 23 || guard.OffHoursEnd < 0 || guard.OffHoursEnd > 23 {
+			return fmt.Errorf("管理端非工作时间必须在0-23之间")
+		}
+		for _, route := range guard.BulkRoutes {
+			if len(strings.Fields(route)) != 2 {
+				return fmt.Errorf("无效的管理端批量操作路由: %s", route)
+			}
+		}
+	}
+
+	// 验证IP访问控制配置
+	if cfg.IPAccess.CacheSeconds <= 0 {
+		return fmt.Errorf("IP名单重新加载间隔必须大于0")
+	}
+	if cfg.IPAccess.AdminAllowlist && len(cfg.IPAccess.Allow) == 0 {
+		return fmt.Errorf("开启管理后台IP白名单时配置文件中的白名单不能为空")
+	}
+	for _, entry := range append(append([]string{}, cfg.IPAccess.Allow...), cfg.IPAccess.Deny...) {
+		if !validIPOrCIDR(entry) {
+			return fmt.Errorf("无效的IP名单条目: %s", entry)
+		}
+	}
+
+	// 验证角色权限配置
+	if cfg.RBAC.CacheSeconds <= 0 {
+		return fmt.Errorf("角色权限重新加载间隔必须大于0")
+	}
+
+	// 验证维护模式配置
+	if cfg.Maintenance.CheckSeconds <= 0 || cfg.Maintenance.RetryAfter <= 0 {
+		return fmt.Errorf("维护状态重新加载间隔和默认重试时间必须大于0")
+	}
+	for _, prefix := range cfg.Maintenance.ExemptPrefixes {
+		if !strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("无效的维护模式豁免路径: %s", prefix)
+		}
+	}
+
+	// 验证接口限流配置
+	if cfg.RateLimit.Enabled {
+		if err := cfg.RateLimit.Default.validate("默认"); err != nil {
+			return err
+		}
+		seen := make(map[string]bool, len(cfg.RateLimit.Routes))
+		for _, policy := range cfg.RateLimit.Routes {
+			if len(strings.Fields(policy.Route)) != 2 {
+				return fmt.Errorf("无效的限流路由: %s", policy.Route)
+			}
+			if seen[policy.Route] {
+				return fmt.Errorf("限流路由重复: %s", policy.Route)
+			}
+			seen[policy.Route] = true
+			if err := policy.validate("路由" + policy.Route); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 验证并发请求数限制配置
+	if cfg.Concurrency.Enabled {
+		if cfg.Concurrency.MaxInFlight < 0 {
+			return fmt.Errorf("并发请求数上限不能小于0")
+		}
+		seen := make(map[string]bool, len(cfg.Concurrency.Routes))
+		for _, route := range cfg.Concurrency.Routes {
+			if len(strings.Fields(route.Route)) != 2 {
+				return fmt.Errorf("无效的并发限制路由: %s", route.Route)
+			}
+			if seen[route.Route] {
+				return fmt.Errorf("并发限制路由重复: %s", route.Route)
+			}
+			seen[route.Route] = true
+			if route.Limit <= 0 {
+				return fmt.Errorf("路由%s的并发请求数上限必须大于0", route.Route)
+			}
+		}
+		for _, route := range cfg.Concurrency.ExemptRoutes {
+			if len(strings.Fields(route)) != 2 {
+				return fmt.Errorf("无效的并发限制豁免路由: %s", route)
+			}
+		}
+	}
+
+	// 验证熔断配置
+	if cfg.CircuitBreaker.Enabled {
+		if err := cfg.CircuitBreaker.Default.validate("默认"); err != nil {
+			return err
+		}
+		for name, policy := range cfg.CircuitBreaker.Dependencies {
+			if err := policy.validate("依赖" + name); err != nil {
+				return err
+			}
+		}
+		for _, route := range cfg.CircuitBreaker.ExemptRoutes {
+			if len(strings.Fields(route)) != 2 {
+				return fmt.Errorf("无效的熔断豁免路由: %s", route)
+			}
+		}
+	}
+
+	// 验证请求处理超时配置
+	if cfg.RequestTimeout.Enabled {
+		timeouts := []int{cfg.RequestTimeout.Default}
+		seen := make(map[string]bool, len(cfg.RequestTimeout.Routes))
+		for _, route := range cfg.RequestTimeout.Routes {
+			if len(strings.Fields(route.Route)) != 2 {
+				return fmt.Errorf("无效的请求超时路由: %s", route.Route)
+			}
+			if seen[route.Route] {
+				return fmt.Errorf("请求超时路由重复: %s", route.Route)
+			}
+			seen[route.Route] = true
+			timeouts = append(timeouts, route.Seconds)
+		}
+		for _, seconds := range timeouts {
+			if seconds < 0 {
+				return fmt.Errorf("请求处理超时不能小于0")
+			}
+			if cfg.Server.WriteTimeout > 0 && seconds >= cfg.Server.WriteTimeout {
+				return fmt.Errorf("请求处理超时(%d秒)必须小于server.write_timeout(%d秒)", seconds, cfg.Server.WriteTimeout)
+			}
+		}
+	}
+
+	// 验证接口文档示例配置
+	if cfg.DocExamples.Enabled {
+		if GetEnv() == "production" {
+			return fmt.Errorf("生产环境不能启用接口文档示例记录")
+		}
+		if cfg.DocExamples.File == "" || cfg.DocExamples.MaxBodyBytes <= 0 {
+			return fmt.Errorf("接口文档示例文件不能为空，请求体大小上限必须大于0")
+		}
+	}
+
+	// 验证模拟时钟配置
+	if cfg.Clock.Simulated {
+		if GetEnv() == "production" {
+			return fmt.Errorf("生产环境不能启用模拟时钟")
+		}
+		if _, err := cfg.Clock.GetOffset(); err != nil {
+			return err
+		}
+	}
+
+	// 验证密钥提供者配置
+	switch cfg.Secrets.Provider {
+	case "env", "config":
+	case "file":
+		if cfg.Secrets.Dir == "" {
+			return fmt.Errorf("密钥目录不能为空")
+		}
+	default:
+		return fmt.Errorf("无效的密钥提供者: %s", cfg.Secrets.Provider)
+	}
+
+	// 验证内部服务请求签名配置
+	if cfg.ServiceAuth.Enabled {
+		if cfg.ServiceAuth.ServiceName == "" {
+			return fmt.Errorf("内部服务名称不能为空")
+		}
+		if cfg.ServiceAuth.ReplayWindow <= 0 || cfg.ServiceAuth.KeyCacheTTL <= 0 {
+			return fmt.Errorf("签名时间窗口和密钥缓存时间必须大于0")
+		}
+	}
+
+	// 验证API key签名认证配置
+	if cfg.APIKeyAuth.Enabled {
+		if cfg.APIKeyAuth.ReplayWindow <= 0 || cfg.APIKeyAuth.KeyCacheTTL <= 0 {
+			return fmt.Errorf("API key签名时间窗口和密钥缓存时间必须大于0")
+		}
+		if cfg.APIKeyAuth.MaxUserKeys < 0 || cfg.APIKeyAuth.UserKeyMaxTTLDays < 0 {
+			return fmt.Errorf("用户API key数量上限和最长有效期不能为负数")
+		}
+		if cfg.APIKeyAuth.UserKeyRateLimit <= 0 || cfg.APIKeyAuth.UserKeyWindowSeconds <= 0 {
+			return fmt.Errorf("用户API key的限流上限和窗口长度必须大于0")
+		}
+		seen := make(map[string]bool, len(cfg.APIKeyAuth.Keys))
+		for _, key := range cfg.APIKeyAuth.Keys {
+			if key.KeyID == "" || key.UserID == 0 {
+				return fmt.Errorf("API key的key_id和user_id不能为空")
+			}
+			if seen[key.KeyID] {
+				return fmt.Errorf("API key重复: %s", key.KeyID)
+			}
+			seen[key.KeyID] = true
+			for _, permission := range key.Permissions {
+				if permission != APIKeyPermissionRead && permission != APIKeyPermissionWrite {
+					return fmt.Errorf("API key %s 的权限无效: %s", key.KeyID, permission)
+				}
+			}
+			if key.RateLimit < 0 || (key.RateLimit > 0 && key.WindowSeconds <= 0) {
+				return fmt.Errorf("API key %s 的限流上限不能小于0，窗口长度必须大于0", key.KeyID)
+			}
+		}
+	}
+
+	// 验证新设备和异地登录检测配置
+	if cfg.LoginRisk.Enabled {
+		if cfg.LoginRisk.Action != LoginRiskActionNotify && cfg.LoginRisk.Action != LoginRiskActionStepUp {
+			return fmt.Errorf("不支持的新设备登录处理方式: %s", cfg.LoginRisk.Action)
+		}
+		if cfg.LoginRisk.Action == LoginRiskActionStepUp && (cfg.LoginRisk.ConfirmURL == "" || cfg.LoginRisk.ConfirmTTLMinutes <= 0) {
+			return fmt.Errorf("新设备登录需要确认时确认链接和有效期不能为空")
+		}
+		if cfg.LoginRisk.MaxDevices <= 0 {
+			return fmt.Errorf("每个用户保留的已知设备数必须大于0")
+		}
+		switch cfg.LoginRisk.GeoIP.Provider {
+		case "none":
+		case "header":
+			if cfg.LoginRisk.GeoIP.Header == "" {
+				return fmt.Errorf("GeoIP国家代码请求头不能为空")
+			}
+		case "csv":
+			if cfg.LoginRisk.GeoIP.File == "" {
+				return fmt.Errorf("GeoIP IP段文件不能为空")
+			}
+		default:
+			return fmt.Errorf("不支持的GeoIP查询方式: %s", cfg.LoginRisk.GeoIP.Provider)
+		}
+	}
+
+	// 验证第三方登录配置
+	if cfg.OAuth.Enabled {
+		if cfg.OAuth.StateTTL <= 0 {
+			return fmt.Errorf("第三方登录state有效期必须大于0")
+		}
+		for name, provider := range cfg.OAuth.Providers {
+			if name != OAuthProviderGoogle && name != OAuthProviderGitHub && name != OAuthProviderApple {
+				return fmt.Errorf("不支持的第三方登录服务: %s", name)
+			}
+			if !provider.Enabled {
+				continue
+			}
+			if provider.ClientID == "" || provider.ClientSecretName == "" || provider.RedirectURL == "" {
+				return fmt.Errorf("第三方登录服务 %s 的client_id、client_secret_name和redirect_url不能为空", name)
+			}
+			if name == OAuthProviderApple && (provider.TeamID == "" || provider.KeyID == "") {
+				return fmt.Errorf("第三方登录服务 apple 的team_id和key_id不能为空")
+			}
+		}
+	}
+
+	// 验证人机验证配置
+	if cfg.Captcha.Enabled {
+		if cfg.Captcha.Provider == "" || cfg.Captcha.SecretName == "" {
+			return fmt.Errorf("人机验证服务和密钥名称不能为空")
+		}
+		if cfg.Captcha.FailureThreshold < 0 || cfg.Captcha.WindowMinutes <= 0 || cfg.Captcha.TimeoutMs <= 0 {
+			return fmt.Errorf("人机验证失败次数不能为负数，统计窗口和超时必须大于0")
+		}
+		if cfg.Captcha.MinScore < 0 || cfg.Captcha.MinScore > 1 {
+			return fmt.Errorf("人机验证最低分数必须在0到1之间")
+		}
+	}
+
+	// 验证数据保留配置
+	for category, policy := range cfg.Retention.Policies() {
+		if policy.Enabled && policy.Days <= 0 {
+			return fmt.Errorf("数据保留天数必须大于0: %s", category)
+		}
+	}
+	if archive := cfg.Retention.Archive; archive.Enabled {
+		if archive.Target != ArchiveTargetMongoDB && archive.Target != ArchiveTargetStorage {
+			return fmt.Errorf("不支持的消息归档目标: %s", archive.Target)
+		}
+		if archive.Days <= 0 || archive.BatchSize <= 0 {
+			return fmt.Errorf("消息归档天数和每批数量必须大于0")
+		}
+		if cfg.Retention.Messages.Enabled && archive.Days >= cfg.Retention.Messages.Days {
+			return fmt.Errorf("消息归档天数必须小于消息保留天数")
+		}
+		if archive.Target == ArchiveTargetStorage && archive.KeyPrefix == "" {
+			return fmt.Errorf("消息归档到对象存储时key_prefix不能为空")
+		}
+	}
+
+	// 验证日志配置
+	if cfg.Log.Backend != "slog" && cfg.Log.Backend != "zap" {
+		return fmt.Errorf("不支持的日志后端: %s", cfg.Log.Backend)
+	}
+	for module, level := range cfg.Log.Modules {
+		if !isValidLogLevel(level) {
+			return fmt.Errorf("无效的模块日志级别: %s=%s", module, level)
+		}
+	}
+	if cfg.Log.Access.BodySampleRate < 0 || cfg.Log.Access.BodySampleRate > 1 {
+		return fmt.Errorf("访问日志请求体记录比例必须在0到1之间")
+	}
+	if cfg.Log.Access.BodySampleRate > 0 && cfg.Log.Access.MaxBodyBytes <= 0 {
+		return fmt.Errorf("记录请求体时访问日志的最大记录字节数必须大于0")
+	}
+	if cfg.Log.Sampling.Enabled && (cfg.Log.Sampling.Initial <= 0 || cfg.Log.Sampling.Thereafter <= 0) {
+		return fmt.Errorf("日志采样参数必须大于0")
+	}
+	if cfg.Log.OTLP.Enabled {
+		if cfg.Log.OTLP.Endpoint == "" {
+			return fmt.Errorf("OTLP日志导出地址不能为空")
+		}
+		if cfg.Log.OTLP.QueueSize <= 0 || cfg.Log.OTLP.BatchSize <= 0 || cfg.Log.OTLP.FlushIntervalMs <= 0 || cfg.Log.OTLP.TimeoutMs <= 0 {
+			return fmt.Errorf("OTLP日志导出参数必须大于0")
+		}
+	}
+	if cfg.Log.Alert.Enabled {
+		if cfg.Log.Alert.WindowSeconds <= 0 || cfg.Log.Alert.Threshold <= 0 || cfg.Log.Alert.CooldownSeconds < 0 {
+			return fmt.Errorf("错误率告警参数必须大于0")
+		}
+		if cfg.Log.Alert.Email.Host != "" && (cfg.Log.Alert.Email.From == "" || len(cfg.Log.Alert.Email.To) == 0) {
+			return fmt.Errorf("告警邮件的发件人和收件人不能为空")
+		}
+	}
+	for i, output := range cfg.Log.Outputs {
+		if output.URL == "" {
+			return fmt.Errorf("日志输出[%d]地址不能为空", i)
+		}
+		switch output.Type {
+		case "kafka":
+			if output.Topic == "" {
+				return fmt.Errorf("日志输出[%d]的Kafka主题不能为空", i)
+			}
+		case "elasticsearch":
+			if output.Index == "" {
+				return fmt.Errorf("日志输出[%d]的Elasticsearch索引不能为空", i)
+			}
+		default:
+			return fmt.Errorf("日志输出[%d]类型无效: %s", i, output.Type)
+		}
+	}
+	if cfg.Log.Async.Enabled {
+		if cfg.Log.Async.BufferSize <= 0 || cfg.Log.Async.BatchSize <= 0 || cfg.Log.Async.FlushIntervalMs <= 0 {
+			return fmt.Errorf("异步日志参数必须大于0")
+		}
+		if cfg.Log.Async.Overflow != "drop" && cfg.Log.Async.Overflow != "block" {
+			return fmt.Errorf("不支持的异步日志溢出策略: %s", cfg.Log.Async.Overflow)
+		}
+	}
+
+	// 验证分布式定时任务配置
+	if cfg.Distributed.HeartbeatInterval <= 0 {
+		return fmt.Errorf("心跳间隔必须大于0")
+	}
+	if cfg.Distributed.InstanceTTL <= 0 {
+		return fmt.Errorf("实例存活窗口必须大于0")
+	}
+	if cfg.Distributed.HeartbeatInterval >= cfg.Distributed.InstanceTTL {
+		return fmt.Errorf("心跳间隔(%ds)必须小于实例存活窗口(%ds)", cfg.Distributed.HeartbeatInterval, cfg.Distributed.InstanceTTL)
+	}
+
+	// 验证语言回退链配置
+	for lang, chain := range cfg.I18n.Fallbacks {
+		if _, err := language.Parse(lang); err != nil {
+			return fmt.Errorf("无效的回退语言: %s", lang)
+		}
+		for _, fallback := range chain {
+			if _, err := language.Parse(fallback); err != nil {
+				return fmt.Errorf("%s的回退链包含无效的语言: %s", lang, fallback)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isValidLogLevel 检查日志级别是否有效
+func isValidLogLevel(level string) bool {
+	switch strings.ToLower(level) {
+	case "debug", "info", "warn", "warning", "error":
+		return true
+	default:
+		return false
+	}
+}
+
+// validateRedis 验证Redis配置
+func validateRedis(redis RedisConfig) error {
+	switch redis.Mode {
+	case "standalone":
+		if redis.Host == "" {
+			return fmt.Errorf("Redis主机不能为空")
+		}
+		if redis.Port <= 0 || redis.Port > 65535 {
+			return fmt.Errorf("无效的Redis端口: %d", redis.Port)
+		}
+	case "sentinel":
+		if len(redis.Addrs) == 0 {
+			return fmt.Errorf("Redis哨兵模式需要配置addrs（哨兵地址）")
+		}
+		if redis.MasterName == "" {
+			return fmt.Errorf("Redis哨兵模式需要配置master_name")
+		}
+	case "cluster":
+		if len(redis.Addrs) == 0 {
+			return fmt.Errorf("Redis集群模式需要配置addrs（集群节点地址）")
+		}
+		if redis.Database != 0 {
+			return fmt.Errorf("Redis集群模式不支持database: %d", redis.Database)
+		}
+	default:
+		return fmt.Errorf("无效的Redis部署模式: %s", redis.Mode)
+	}
+
+	switch redis.ReadMode {
+	case "primary", "latency", "random":
+	case "replica":
+		if redis.Mode != "cluster" {
+			return fmt.Errorf("Redis read_mode=replica仅支持集群模式，哨兵模式请使用latency或random")
+		}
+	default:
+		return fmt.Errorf("无效的Redis read_mode: %s", redis.ReadMode)
+	}
+	if redis.Mode == "standalone" && redis.ReadMode != "primary" {
+		return fmt.Errorf("Redis单节点模式的read_mode只能为primary")
+	}
+	return nil
+}
+
+// validateTLS 验证TLS配置，客户端证书和私钥需同时设置
+func validateTLS(name string, tls TLSConfig) error {
+	if !tls.Enabled {
+		return nil
+	}
+	if (tls.CertFile == "") != (tls.KeyFile == "") {
+		return fmt.Errorf("%s TLS客户端证书和私钥需同时设置", name)
+	}
 	return nil
 }
 
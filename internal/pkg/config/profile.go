@@ -0,0 +1,261 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// 配置分层说明：
+//   1. configs/base.json          所有环境共享的基础配置
+//   2. configs/<env>.json         环境覆盖层（development、production等）
+//   3. configs/<env>.local.json   本地覆盖层（不提交到仓库）
+// 后面的层按字段深度合并覆盖前面的层，最后再由环境变量覆盖。
+
+const (
+	// DefaultConfigDir 默认配置目录
+	DefaultConfigDir = "configs"
+	// DefaultEnv 默认运行环境
+	DefaultEnv = "development"
+
+	maskedValue = "******"
+)
+
+// GetEnv 获取当前运行环境（ENV环境变量，默认development）
+func GetEnv() string {
+	if env := os.Getenv("ENV"); env != "" {
+		return env
+	}
+	return DefaultEnv
+}
+
+// GetConfigDir 获取配置目录（CONFIG_DIR环境变量，默认configs）
+func GetConfigDir() string {
+	if dir := os.Getenv("CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	return DefaultConfigDir
+}
+
+// LayerFiles 返回指定环境按合并顺序排列的配置层文件
+func LayerFiles(dir, env string) []string {
+	return []string{
+		filepath.Join(dir, "base.json"),
+		filepath.Join(dir, env+".json"),
+		filepath.Join(dir, env+".local.json"),
+	}
+}
+
+// LoadProfile 加载指定环境的配置（默认值 + 配置层，不读取环境变量）
+func LoadProfile(dir, env string) (*Config, error) {
+	cfg := &Config{}
+	setDefaults(cfg)
+
+	report, err := loadLayers(cfg, dir, env)
+	if err != nil {
+		return nil, err
+	}
+	if IsStrict() {
+		if err := report.StrictError(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validate(cfg); err != nil {
+		return nil, fmt.Errorf("配置验证失败: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// CheckProfile 检查指定环境的配置层，返回未知及废弃配置项报告
+func CheckProfile(dir, env string) (*LoadReport, error) {
+	cfg := &Config{}
+	setDefaults(cfg)
+	return loadLayers(cfg, dir, env)
+}
+
+// loadLayers 读取并合并所有存在的配置层，返回加载报告
+func loadLayers(cfg *Config, dir, env string) (*LoadReport, error) {
+	merged := make(map[string]interface{})
+	report := &LoadReport{}
+
+	for _, file := range LayerFiles(dir, env) {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return report, fmt.Errorf("读取配置文件 %s 失败: %w", file, err)
+		}
+
+		var layer map[string]interface{}
+		if err := json.Unmarshal(data, &layer); err != nil {
+			return report, fmt.Errorf("解析配置文件 %s 失败: %w", file, err)
+		}
+
+		checkLayer(file, layer, report)
+		mergeMaps(merged, layer)
+		report.Files = append(report.Files, file)
+	}
+
+	if len(report.Files) == 0 {
+		return report, fmt.Errorf("未找到环境 %s 的配置文件（目录: %s）", env, dir)
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return report, fmt.Errorf("序列化合并配置失败: %w", err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return report, fmt.Errorf("解析合并配置失败: %w", err)
+	}
+
+	return report, nil
+}
+
+// mergeMaps 将src深度合并到dst，嵌套对象逐字段覆盖，其他值直接替换
+func mergeMaps(dst, src map[string]interface{}) {
+	for key, srcVal := range src {
+		srcMap, srcIsMap := srcVal.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeMaps(dstMap, srcMap)
+			continue
+		}
+		dst[key] = srcVal
+	}
+}
+
+// Masked 返回敏感信息已脱敏的配置（以map形式，便于打印）
+func (cfg *Config) Masked() map[string]interface{} {
+	result := cfg.toMap()
+	maskMap(result)
+	return result
+}
+
+// toMap 将配置转换为map
+func (cfg *Config) toMap() map[string]interface{} {
+	result := make(map[string]interface{})
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return result
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result
+	}
+	return result
+}
+
+// maskMap 递归脱敏map中的敏感字段
+func maskMap(m map[string]interface{}) {
+	for key, val := range m {
+		switch v := val.(type) {
+		case map[string]interface{}:
+			maskMap(v)
+		case string:
+			if v == "" {
+				continue
+			}
+			if isSensitiveKey(key) {
+				m[key] = maskedValue
+			} else if key == "uri" || key == "url" || key == "dsn" {
+				m[key] = maskURI(v)
+			}
+		}
+	}
+}
+
+// isSensitiveKey 判断字段名是否为敏感字段
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, word := range []string{"password", "secret", "token", "private_key", "api_key"} {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskURI 脱敏URI中的认证信息
+func maskURI(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		u.User = url.UserPassword(u.User.Username(), maskedValue)
+	}
+	return u.String()
+}
+
+// DiffEntry 配置差异项
+type DiffEntry struct {
+	Path  string      `json:"path"`
+	Left  interface{} `json:"left"`
+	Right interface{} `json:"right"`
+}
+
+// Diff 比较两份配置，按原始值判断差异，输出脱敏后的值，结果按路径排序
+func Diff(left, right *Config) []DiffEntry {
+	leftRaw, rightRaw := flattenConfig(left, false), flattenConfig(right, false)
+	leftMasked, rightMasked := flattenConfig(left, true), flattenConfig(right, true)
+
+	paths := make(map[string]struct{})
+	for path := range leftRaw {
+		paths[path] = struct{}{}
+	}
+	for path := range rightRaw {
+		paths[path] = struct{}{}
+	}
+
+	var diffs []DiffEntry
+	for path := range paths {
+		if reflect.DeepEqual(leftRaw[path], rightRaw[path]) {
+			continue
+		}
+		l, r := leftMasked[path], rightMasked[path]
+		if reflect.DeepEqual(l, r) {
+			// 敏感字段脱敏后相同，仅提示已变更
+			r = fmt.Sprintf("%v (已变更)", r)
+		}
+		diffs = append(diffs, DiffEntry{Path: path, Left: l, Right: r})
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Path < diffs[j].Path
+	})
+	return diffs
+}
+
+// flattenConfig 将配置展开为点分路径，masked为true时先脱敏
+func flattenConfig(cfg *Config, masked bool) map[string]interface{} {
+	out := make(map[string]interface{})
+	m := cfg.toMap()
+	if masked {
+		maskMap(m)
+	}
+	flatten("", m, out)
+	return out
+}
+
+// flatten 将嵌套map展开为点分路径
+func flatten(prefix string, m map[string]interface{}, out map[string]interface{}) {
+	for key, val := range m {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := val.(map[string]interface{}); ok {
+			flatten(path, nested, out)
+			continue
+		}
+		out[path] = val
+	}
+}
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DeprecatedKey 已废弃的配置项
+type DeprecatedKey struct {
+	Key         string `json:"key"`         // 废弃的配置项（点分路径）
+	Replacement string `json:"replacement"` // 替代配置项
+	RemovedIn   string `json:"removed_in"`  // 计划移除的版本
+}
+
+// deprecatedKeys 已废弃配置项列表
+var deprecatedKeys = []DeprecatedKey{
+	{Key: "server.address", Replacement: "server.port", RemovedIn: "2.0.0"},
+	{Key: "log.output", Replacement: "log.enable_console, log.enable_file", RemovedIn: "2.0.0"},
+}
+
+// UnknownKey 未知配置项
+type UnknownKey struct {
+	File       string `json:"file"`
+	Key        string `json:"key"`
+	Suggestion string `json:"suggestion,omitempty"` // 可能想写的配置项
+}
+
+// DeprecationWarning 废弃配置项警告
+type DeprecationWarning struct {
+	File string `json:"file"`
+	DeprecatedKey
+}
+
+// LoadReport 配置加载报告
+type LoadReport struct {
+	Files        []string             `json:"files"`        // 实际加载的配置层文件
+	UnknownKeys  []UnknownKey         `json:"unknown_keys"` // 未知配置项
+	Deprecations []DeprecationWarning `json:"deprecations"` // 废弃配置项
+}
+
+// HasIssues 是否存在未知或废弃配置项
+func (r *LoadReport) HasIssues() bool {
+	return len(r.UnknownKeys) > 0 || len(r.Deprecations) > 0
+}
+
+// StrictError 返回严格模式下的错误（存在未知配置项时）
+func (r *LoadReport) StrictError() error {
+	if len(r.UnknownKeys) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(r.UnknownKeys))
+	for _, u := range r.UnknownKeys {
+		keys = append(keys, fmt.Sprintf("%s(%s)", u.Key, u.File))
+	}
+	return fmt.Errorf("存在未知配置项: %s", strings.Join(keys, ", "))
+}
+
+// PrintWarnings 打印结构化警告（日志系统初始化前使用标准输出）
+func (r *LoadReport) PrintWarnings() {
+	for _, u := range r.UnknownKeys {
+		if u.Suggestion != "" {
+			fmt.Printf("警告: 未知配置项 key=%s file=%s suggestion=%s\n", u.Key, u.File, u.Suggestion)
+		} else {
+			fmt.Printf("警告: 未知配置项 key=%s file=%s\n", u.Key, u.File)
+		}
+	}
+	for _, d := range r.Deprecations {
+		fmt.Printf("警告: 配置项已废弃 key=%s replacement=%s removed_in=%s file=%s\n", d.Key, d.Replacement, d.RemovedIn, d.File)
+	}
+}
+
+// IsStrict 是否开启严格模式（CONFIG_STRICT环境变量）
+func IsStrict() bool {
+	val := strings.ToLower(os.Getenv("CONFIG_STRICT"))
+	return val == "true" || val == "1"
+}
+
+// checkLayer 检查单个配置层中的未知及废弃配置项
+func checkLayer(file string, layer map[string]interface{}, report *LoadReport) {
+	checkKeys(file, "", layer, reflect.TypeOf(Config{}), report)
+}
+
+// checkKeys 递归对比配置层与配置结构体的json字段
+func checkKeys(file, prefix string, layer map[string]interface{}, typ reflect.Type, report *LoadReport) {
+	fields := jsonFields(typ)
+
+	// 按键名排序，保证输出稳定
+	keys := make([]string, 0, len(layer))
+	for key := range layer {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		fieldType, ok := fields[key]
+		if !ok {
+			report.UnknownKeys = append(report.UnknownKeys, UnknownKey{
+				File:       file,
+				Key:        path,
+				Suggestion: suggestKey(prefix, key, fields),
+			})
+			continue
+		}
+
+		for _, d := range deprecatedKeys {
+			if d.Key == path {
+				report.Deprecations = append(report.Deprecations, DeprecationWarning{File: file, DeprecatedKey: d})
+			}
+		}
+
+		checkValue(file, path, layer[key], fieldType, report)
+	}
+}
+
+// checkValue 按字段类型递归检查配置值：结构体检查字段，切片、数组和map检查每个元素
+// 元素的路径带下标或map键，如 rate_limit.routes[0].algorithm、cache.ttl_policies[user].ttl
+func checkValue(file, path string, value interface{}, typ reflect.Type, report *LoadReport) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		if nested, ok := value.(map[string]interface{}); ok {
+			checkKeys(file, path, nested, typ, report)
+		}
+	case reflect.Slice, reflect.Array:
+		if items, ok := value.([]interface{}); ok {
+			for i, item := range items {
+				checkValue(file, fmt.Sprintf("%s[%d]", path, i), item, typ.Elem(), report)
+			}
+		}
+	case reflect.Map:
+		if entries, ok := value.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(entries))
+			for key := range entries {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				checkValue(file, fmt.Sprintf("%s[%s]", path, key), entries[key], typ.Elem(), report)
+			}
+		}
+	}
+}
+
+// jsonFields 获取结构体json标签到字段类型的映射
+func jsonFields(typ reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// suggestKey 为拼写错误的配置项推荐最相近的合法配置项
+func suggestKey(prefix, key string, fields map[string]reflect.Type) string {
+	best, bestDistance := "", 3 // 编辑距离超过2不推荐
+	for name := range fields {
+		if d := editDistance(key, name); d < bestDistance || (d == bestDistance && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	if prefix != "" {
+		return prefix + "." + best
+	}
+	return best
+}
+
+// editDistance 计算两个字符串的编辑距离
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"exchange/internal/pkg/database"
-	appLogger "exchange/internal/pkg/logger"
 )
 
 // DistributedLock 分布式锁管理器
@@ -32,7 +31,7 @@ func (dl *DistributedLock) TryAcquireLock(ctx context.Context, lockKey, instance
 	}
 
 	if success {
-		appLogger.Info("分布式锁获取成功", map[string]interface{}{
+		cronLogger.Info("分布式锁获取成功", map[string]interface{}{
 			"lock_key":    lockKey,
 			"instance_id": instanceID,
 			"ttl":         ttl.String(),
@@ -41,13 +40,13 @@ func (dl *DistributedLock) TryAcquireLock(ctx context.Context, lockKey, instance
 		// 获取当前持有锁的实例ID
 		currentHolder, err := dl.redis.Client().Get(ctx, key).Result()
 		if err != nil {
-			appLogger.Warn("分布式锁获取失败，无法获取当前持有者", map[string]interface{}{
+			cronLogger.Warn("分布式锁获取失败，无法获取当前持有者", map[string]interface{}{
 				"lock_key":    lockKey,
 				"instance_id": instanceID,
 				"error":       err.Error(),
 			})
 		} else {
-			appLogger.Info("分布式锁获取失败，已被其他实例持有", map[string]interface{}{
+			cronLogger.Info("分布式锁获取失败，已被其他实例持有", map[string]interface{}{
 				"lock_key":       lockKey,
 				"instance_id":    instanceID,
 				"current_holder": currentHolder,
@@ -78,12 +77,12 @@ func (dl *DistributedLock) ReleaseLock(ctx context.Context, lockKey, instanceID
 	}
 
 	if result.(int64) == 1 {
-		appLogger.Info("分布式锁释放成功", map[string]interface{}{
+		cronLogger.Info("分布式锁释放成功", map[string]interface{}{
 			"lock_key":    lockKey,
 			"instance_id": instanceID,
 		})
 	} else {
-		appLogger.Warn("分布式锁释放失败，可能不是锁的持有者", map[string]interface{}{
+		cronLogger.Warn("分布式锁释放失败，可能不是锁的持有者", map[string]interface{}{
 			"lock_key":    lockKey,
 			"instance_id": instanceID,
 		})
@@ -112,7 +111,7 @@ func (dl *DistributedLock) RenewLock(ctx context.Context, lockKey, instanceID st
 
 	success := result.(int64) == 1
 	if success {
-		appLogger.Info("分布式锁续期成功", map[string]interface{}{
+		cronLogger.Info("分布式锁续期成功", map[string]interface{}{
 			"lock_key":    lockKey,
 			"instance_id": instanceID,
 			"ttl":         ttl.String(),
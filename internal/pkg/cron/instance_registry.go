@@ -5,12 +5,98 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
+	"github.com/redis/go-redis/v9"
+
+	"exchange/internal/pkg/config"
 	"exchange/internal/pkg/database"
 	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/services"
+)
+
+// 实例注册表的Redis键
+// 实例信息和心跳时间分别保存在两个哈希中，心跳只写一个字段，不需要读取和重写实例信息
+// 两个键在同一脚本和事务中访问，使用相同的哈希标签，集群模式下在同一槽位
+const (
+	instancesKey          = "{cron_instances}"            // 实例ID -> 实例信息JSON
+	instanceHeartbeatsKey = "{cron_instances}:heartbeats" // 实例ID -> 最近心跳时间（Unix毫秒）
 )
 
+// redisNowMillis 脚本开头读取Redis服务器时间（Unix毫秒）到now
+// 心跳的写入和超时判断都使用Redis时间，各实例本地时钟的偏差不会导致存活实例被误清理或失效实例被当作存活
+// Redis 7起脚本按效果复制，TIME之后可以执行写命令
+const redisNowMillis = `
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+`
+
+// registerScript 写入实例信息和首次心跳，列表中不会出现缺少心跳的实例
+var registerScript = redis.NewScript(redisNowMillis + `
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('HSET', KEYS[2], ARGV[1], now)
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+redis.call('PEXPIRE', KEYS[2], ARGV[3])
+return 1
+`)
+
+// heartbeatScript 更新心跳，实例信息不存在（已被清理）时返回0由调用方重新注册
+// 每次心跳刷新两个哈希的过期时间，所有实例都停止后注册表自动过期
+var heartbeatScript = redis.NewScript(redisNowMillis + `
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[2], ARGV[1], now)
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+redis.call('PEXPIRE', KEYS[2], ARGV[2])
+return 1
+`)
+
+// listInstancesScript 清理心跳超时或缺少心跳的实例并返回存活实例
+// 返回值：[清理数量, 实例信息1, 心跳时间1, 实例信息2, 心跳时间2, ...]
+var listInstancesScript = redis.NewScript(redisNowMillis + `
+local ttl = tonumber(ARGV[1])
+local removed = 0
+local alive = {}
+local beats = redis.call('HGETALL', KEYS[2])
+for i = 1, #beats, 2 do
+	if now - tonumber(beats[i + 1]) > ttl then
+		redis.call('HDEL', KEYS[1], beats[i])
+		redis.call('HDEL', KEYS[2], beats[i])
+		removed = removed + 1
+	else
+		alive[beats[i]] = beats[i + 1]
+	end
+end
+local result = {0}
+local infos = redis.call('HGETALL', KEYS[1])
+for i = 1, #infos, 2 do
+	local beat = alive[infos[i]]
+	if beat then
+		table.insert(result, infos[i + 1])
+		table.insert(result, beat)
+	else
+		redis.call('HDEL', KEYS[1], infos[i])
+		removed = removed + 1
+	end
+end
+result[1] = removed
+return result
+`)
+
+// instanceAliveScript 检查单个实例是否存活，判断条件与listInstancesScript相同（实例信息存在且心跳未超时），只读不清理
+var instanceAliveScript = redis.NewScript(redisNowMillis + `
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+local beat = redis.call('HGET', KEYS[2], ARGV[1])
+if not beat or now - tonumber(beat) > tonumber(ARGV[2]) then
+	return 0
+end
+return 1
+`)
+
 // InstanceInfo 实例信息
 type InstanceInfo struct {
 	InstanceID    string    `json:"instance_id"`
@@ -31,38 +117,56 @@ type InstanceRegistry struct {
 	pid        int
 	startTime  time.Time
 	version    string
+	tasks      []string // 注册的任务列表，实例信息被清理后重新注册时使用
 	stopChan   chan struct{}
+	logger     *appLogger.Logger // 绑定instance_id的日志记录器
+
+	heartbeatInterval time.Duration // 心跳间隔
+	instanceTTL       time.Duration // 实例存活窗口
 }
 
 // NewInstanceRegistry 创建实例注册管理器
-func NewInstanceRegistry(redis *database.RedisService, version string) *InstanceRegistry {
+func NewInstanceRegistry(redis *database.RedisService, version string, distCfg config.DistributedConfig) *InstanceRegistry {
 	hostname, _ := os.Hostname()
 	if hostname == "" {
 		hostname = "unknown"
 	}
 
+	instanceID := fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().Unix())
 	return &InstanceRegistry{
 		redis:      redis,
-		instanceID: fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().Unix()),
+		instanceID: instanceID,
 		hostname:   hostname,
 		pid:        os.Getpid(),
 		startTime:  time.Now(),
 		version:    version,
 		stopChan:   make(chan struct{}),
+		logger:     cronLogger.With(map[string]interface{}{"instance_id": instanceID}),
+
+		heartbeatInterval: distCfg.GetHeartbeatInterval(),
+		instanceTTL:       distCfg.GetInstanceTTL(),
 	}
 }
 
+// distributedConfig 获取分布式配置（全局配置未初始化时使用默认值）
+func distributedConfig() config.DistributedConfig {
+	if cfg := services.GetGlobalServices().GetConfig(); cfg != nil {
+		return cfg.Distributed
+	}
+	return config.DefaultDistributedConfig()
+}
+
 // Register 注册实例
 func (ir *InstanceRegistry) Register(ctx context.Context, tasks []string) error {
+	ir.tasks = tasks
 	instanceInfo := &InstanceInfo{
-		InstanceID:    ir.instanceID,
-		Hostname:      ir.hostname,
-		PID:           ir.pid,
-		StartTime:     ir.startTime,
-		LastHeartbeat: time.Now(),
-		Status:        "running",
-		Version:       ir.version,
-		Tasks:         tasks,
+		InstanceID: ir.instanceID,
+		Hostname:   ir.hostname,
+		PID:        ir.pid,
+		StartTime:  ir.startTime,
+		Status:     "running",
+		Version:    ir.version,
+		Tasks:      tasks,
 	}
 
 	// 序列化实例信息
@@ -71,23 +175,16 @@ func (ir *InstanceRegistry) Register(ctx context.Context, tasks []string) error
 		return fmt.Errorf("failed to marshal instance info: %w", err)
 	}
 
-	// 注册实例，设置过期时间为30秒
-	key := fmt.Sprintf("cron_instance:%s", ir.instanceID)
-	if err := ir.redis.Set(key, string(data), 30*time.Second); err != nil {
+	// 实例信息和首次心跳在同一脚本中写入
+	err = registerScript.Run(ctx, ir.redis.Client(),
+		[]string{instancesKey, instanceHeartbeatsKey},
+		ir.instanceID, data, ir.instanceTTL.Milliseconds(),
+	).Err()
+	if err != nil {
 		return fmt.Errorf("failed to register instance: %w", err)
 	}
 
-	// 添加到活跃实例列表
-	activeKey := "cron_active_instances"
-	if err := ir.redis.SetAdd(activeKey, ir.instanceID); err != nil {
-		appLogger.Warn("添加到活跃实例列表失败", map[string]interface{}{
-			"instance_id": ir.instanceID,
-			"error":       err.Error(),
-		})
-	}
-
-	appLogger.Info("定时任务实例注册成功", map[string]interface{}{
-		"instance_id": ir.instanceID,
+	ir.logger.Info("定时任务实例注册成功", map[string]interface{}{
 		"hostname":    ir.hostname,
 		"pid":         ir.pid,
 		"tasks_count": len(tasks),
@@ -99,34 +196,25 @@ func (ir *InstanceRegistry) Register(ctx context.Context, tasks []string) error
 
 // Unregister 注销实例
 func (ir *InstanceRegistry) Unregister(ctx context.Context) error {
-	// 从活跃实例列表中移除
-	activeKey := "cron_active_instances"
-	if err := ir.redis.SetRemove(activeKey, ir.instanceID); err != nil {
-		appLogger.Warn("从活跃实例列表移除失败", map[string]interface{}{
-			"instance_id": ir.instanceID,
-			"error":       err.Error(),
-		})
-	}
-
-	// 删除实例信息
-	key := fmt.Sprintf("cron_instance:%s", ir.instanceID)
-	if err := ir.redis.Delete(key); err != nil {
-		appLogger.Warn("删除实例信息失败", map[string]interface{}{
-			"instance_id": ir.instanceID,
-			"error":       err.Error(),
+	_, err := ir.redis.Client().Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, instancesKey, ir.instanceID)
+		pipe.HDel(ctx, instanceHeartbeatsKey, ir.instanceID)
+		return nil
+	})
+	if err != nil {
+		ir.logger.Warn("删除实例信息失败", map[string]interface{}{
+			"error": err.Error(),
 		})
 	}
 
-	appLogger.Info("定时任务实例注销成功", map[string]interface{}{
-		"instance_id": ir.instanceID,
-	})
+	ir.logger.Info("定时任务实例注销成功")
 
 	return nil
 }
 
 // StartHeartbeat 开始心跳
 func (ir *InstanceRegistry) StartHeartbeat(ctx context.Context) {
-	ticker := time.NewTicker(10 * time.Second) // 每10秒发送一次心跳
+	ticker := time.NewTicker(ir.heartbeatInterval)
 	defer ticker.Stop()
 
 	for {
@@ -137,9 +225,8 @@ func (ir *InstanceRegistry) StartHeartbeat(ctx context.Context) {
 			return
 		case <-ticker.C:
 			if err := ir.sendHeartbeat(ctx); err != nil {
-				appLogger.Error("发送心跳失败", map[string]interface{}{
-					"instance_id": ir.instanceID,
-					"error":       err.Error(),
+				ir.logger.Error("发送心跳失败", map[string]interface{}{
+					"error": err.Error(),
 				})
 			}
 		}
@@ -151,33 +238,20 @@ func (ir *InstanceRegistry) StopHeartbeat() {
 	close(ir.stopChan)
 }
 
-// sendHeartbeat 发送心跳
+// sendHeartbeat 发送心跳（一次脚本调用）
 func (ir *InstanceRegistry) sendHeartbeat(ctx context.Context) error {
-	key := fmt.Sprintf("cron_instance:%s", ir.instanceID)
-
-	// 获取当前实例信息
-	var instanceInfo InstanceInfo
-	if err := ir.redis.GetJSON(key, &instanceInfo); err != nil {
-		// 如果实例信息不存在，重新注册
-		// 这里需要从当前管理器获取任务列表，但我们没有直接访问
-		// 所以先尝试重新注册，如果失败则记录错误
-		appLogger.Warn("实例信息不存在，尝试重新注册", map[string]interface{}{
-			"instance_id": ir.instanceID,
-		})
-		return ir.Register(ctx, []string{}) // 先注册空任务列表，后续会通过心跳更新
-	}
-
-	// 更新心跳时间
-	instanceInfo.LastHeartbeat = time.Now()
-
-	// 序列化并更新
-	data, err := json.Marshal(instanceInfo)
+	updated, err := heartbeatScript.Run(ctx, ir.redis.Client(),
+		[]string{instancesKey, instanceHeartbeatsKey},
+		ir.instanceID, ir.instanceTTL.Milliseconds(),
+	).Int()
 	if err != nil {
-		return fmt.Errorf("failed to marshal instance info for heartbeat: %w", err)
+		return fmt.Errorf("failed to update heartbeat: %w", err)
 	}
 
-	if err := ir.redis.Set(key, string(data), 30*time.Second); err != nil {
-		return fmt.Errorf("failed to update heartbeat: %w", err)
+	if updated == 0 {
+		// 实例信息已被清理（如Redis重启或心跳长时间中断），使用原任务列表重新注册
+		ir.logger.Warn("实例信息不存在，重新注册")
+		return ir.Register(ctx, ir.tasks)
 	}
 
 	return nil
@@ -188,132 +262,117 @@ func (ir *InstanceRegistry) GetInstanceID() string {
 	return ir.instanceID
 }
 
-// GetActiveInstances 获取活跃实例列表
+// GetActiveInstances 获取活跃实例列表，同时清理失效的实例
 func (ir *InstanceRegistry) GetActiveInstances(ctx context.Context) ([]*InstanceInfo, error) {
-	activeKey := "cron_active_instances"
-
-	// 获取活跃实例ID列表
-	instanceIDs, err := ir.redis.SetMembers(activeKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get active instance IDs: %w", err)
-	}
-
-	var instances []*InstanceInfo
-
-	for _, instanceID := range instanceIDs {
-		key := fmt.Sprintf("cron_instance:%s", instanceID)
-
-		var instanceInfo InstanceInfo
-		if err := ir.redis.GetJSON(key, &instanceInfo); err != nil {
-			// 如果实例信息不存在，从活跃列表中移除
-			ir.redis.SetRemove(activeKey, instanceID)
-			continue
-		}
-
-		// 检查心跳是否超时（超过30秒）
-		if time.Since(instanceInfo.LastHeartbeat) > 30*time.Second {
-			// 标记为失败并从活跃列表中移除
-			instanceInfo.Status = "failed"
-			ir.redis.SetRemove(activeKey, instanceID)
-			ir.redis.Delete(key)
-			continue
-		}
-
-		instances = append(instances, &instanceInfo)
-	}
-
-	return instances, nil
+	instances, _, err := ir.listInstances(ctx)
+	return instances, err
 }
 
 // GetInstanceInfo 获取指定实例信息
 func (ir *InstanceRegistry) GetInstanceInfo(ctx context.Context, instanceID string) (*InstanceInfo, error) {
-	key := fmt.Sprintf("cron_instance:%s", instanceID)
-
-	var instanceInfo InstanceInfo
-	if err := ir.redis.GetJSON(key, &instanceInfo); err != nil {
+	var infoCmd, beatCmd *redis.StringCmd
+	_, err := ir.redis.Client().Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		infoCmd = pipe.HGet(ctx, instancesKey, instanceID)
+		beatCmd = pipe.HGet(ctx, instanceHeartbeatsKey, instanceID)
+		return nil
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to get instance info for %s: %w", instanceID, err)
 	}
 
-	return &instanceInfo, nil
+	return decodeInstanceInfo(infoCmd.Val(), beatCmd.Val())
 }
 
-// IsInstanceActive 检查实例是否活跃
+// isInstanceAlive 检查实例是否存活（实例信息存在且心跳未超时），查询失败时视为不存活
+func (ir *InstanceRegistry) isInstanceAlive(ctx context.Context, instanceID string) bool {
+	if instanceID == "" {
+		return false
+	}
+	alive, err := ir.IsInstanceActive(ctx, instanceID)
+	return err == nil && alive
+}
+
+// IsInstanceActive 检查实例是否活跃，与活跃实例列表使用相同的心跳超时判断（按Redis服务器时间）
 func (ir *InstanceRegistry) IsInstanceActive(ctx context.Context, instanceID string) (bool, error) {
-	activeKey := "cron_active_instances"
-	return ir.redis.SetIsMember(activeKey, instanceID)
+	alive, err := instanceAliveScript.Run(ctx, ir.redis.Client(),
+		[]string{instancesKey, instanceHeartbeatsKey},
+		instanceID, ir.instanceTTL.Milliseconds(),
+	).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to check instance %s: %w", instanceID, err)
+	}
+	return alive == 1, nil
 }
 
 // GetInstanceCount 获取活跃实例数量
 func (ir *InstanceRegistry) GetInstanceCount(ctx context.Context) (int, error) {
-	activeKey := "cron_active_instances"
-
-	// 获取活跃实例ID列表
-	instanceIDs, err := ir.redis.SetMembers(activeKey)
+	instances, _, err := ir.listInstances(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get active instance count: %w", err)
 	}
+	return len(instances), nil
+}
 
-	// 过滤掉已失效的实例
-	activeCount := 0
-	for _, instanceID := range instanceIDs {
-		key := fmt.Sprintf("cron_instance:%s", instanceID)
-
-		var instanceInfo InstanceInfo
-		if err := ir.redis.GetJSON(key, &instanceInfo); err != nil {
-			// 如果实例信息不存在，从活跃列表中移除
-			ir.redis.SetRemove(activeKey, instanceID)
-			continue
-		}
+// CleanupDeadInstances 清理失效的实例
+func (ir *InstanceRegistry) CleanupDeadInstances(ctx context.Context) error {
+	_, cleanedCount, err := ir.listInstances(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup dead instances: %w", err)
+	}
 
-		// 检查心跳是否超时
-		if time.Since(instanceInfo.LastHeartbeat) <= 30*time.Second {
-			activeCount++
-		} else {
-			// 标记为失败并从活跃列表中移除
-			ir.redis.SetRemove(activeKey, instanceID)
-			ir.redis.Delete(key)
-		}
+	if cleanedCount > 0 {
+		cronLogger.Info("清理失效实例完成", map[string]interface{}{
+			"cleaned_count": cleanedCount,
+		})
 	}
 
-	return activeCount, nil
+	return nil
 }
 
-// CleanupDeadInstances 清理失效的实例
-func (ir *InstanceRegistry) CleanupDeadInstances(ctx context.Context) error {
-	activeKey := "cron_active_instances"
-
-	// 获取活跃实例ID列表
-	instanceIDs, err := ir.redis.SetMembers(activeKey)
+// listInstances 在Redis端清理失效实例并返回存活实例，返回清理数量
+func (ir *InstanceRegistry) listInstances(ctx context.Context) ([]*InstanceInfo, int, error) {
+	values, err := listInstancesScript.Run(ctx, ir.redis.Client(),
+		[]string{instancesKey, instanceHeartbeatsKey},
+		ir.instanceTTL.Milliseconds(),
+	).Slice()
 	if err != nil {
-		return fmt.Errorf("failed to get active instance IDs for cleanup: %w", err)
+		return nil, 0, fmt.Errorf("failed to list active instances: %w", err)
+	}
+	if len(values) == 0 {
+		return nil, 0, nil
 	}
 
-	cleanedCount := 0
-	for _, instanceID := range instanceIDs {
-		key := fmt.Sprintf("cron_instance:%s", instanceID)
-
-		var instanceInfo InstanceInfo
-		if err := ir.redis.GetJSON(key, &instanceInfo); err != nil {
-			// 如果实例信息不存在，从活跃列表中移除
-			ir.redis.SetRemove(activeKey, instanceID)
-			cleanedCount++
+	cleaned, _ := values[0].(int64)
+	instances := make([]*InstanceInfo, 0, (len(values)-1)/2)
+	for i := 1; i+1 < len(values); i += 2 {
+		data, _ := values[i].(string)
+		beat, _ := values[i+1].(string)
+		info, err := decodeInstanceInfo(data, beat)
+		if err != nil {
+			cronLogger.Warn("实例信息解析失败", map[string]interface{}{
+				"error": err.Error(),
+			})
 			continue
 		}
+		instances = append(instances, info)
+	}
 
-		// 检查心跳是否超时
-		if time.Since(instanceInfo.LastHeartbeat) > 30*time.Second {
-			// 从活跃列表中移除并删除实例信息
-			ir.redis.SetRemove(activeKey, instanceID)
-			ir.redis.Delete(key)
-			cleanedCount++
-		}
+	return instances, int(cleaned), nil
+}
+
+// decodeInstanceInfo 解析实例信息和心跳时间
+func decodeInstanceInfo(data, beat string) (*InstanceInfo, error) {
+	if data == "" {
+		return nil, fmt.Errorf("instance info not found")
 	}
 
-	if cleanedCount > 0 {
-		appLogger.Info("清理失效实例完成", map[string]interface{}{
-			"cleaned_count": cleanedCount,
-		})
+	var instanceInfo InstanceInfo
+	if err := json.Unmarshal([]byte(data), &instanceInfo); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal instance info: %w", err)
+	}
+	if millis, err := strconv.ParseInt(beat, 10, 64); err == nil {
+		instanceInfo.LastHeartbeat = time.UnixMilli(millis)
 	}
 
-	return nil
+	return &instanceInfo, nil
 }
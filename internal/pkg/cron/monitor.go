@@ -2,26 +2,65 @@ package cron
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	mysqlModel "exchange/internal/models/mysql"
 	"exchange/internal/pkg/database"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
+// MonitorAuth 监控界面认证配置
+type MonitorAuth struct {
+	Login   gin.HandlerFunc   // 登录接口
+	View    []gin.HandlerFunc // 查看监控数据所需的中间件
+	Control []gin.HandlerFunc // 控制任务（触发/暂停/恢复/终止）所需的中间件
+}
+
+// AuditLogRepository 审计日志存储接口
+type AuditLogRepository interface {
+	Create(ctx context.Context, log *mysqlModel.AdminLog) error
+}
+
 // Monitor Web监控界面
 type Monitor struct {
-	redis *database.RedisService
+	redis      *database.RedisService
+	controller *TaskController
+	auth       *MonitorAuth
+	auditRepo  AuditLogRepository
+	logReader  *appLogger.LogReader
 }
 
 // NewMonitor 创建Web监控界面
 func NewMonitor(redis *database.RedisService) *Monitor {
 	return &Monitor{
-		redis: redis,
+		redis:      redis,
+		controller: NewTaskController(redis),
+		auth:       &MonitorAuth{},
 	}
 }
 
+// SetAuth 设置认证配置
+func (m *Monitor) SetAuth(auth *MonitorAuth) {
+	m.auth = auth
+}
+
+// SetAuditLogRepository 设置审计日志存储
+func (m *Monitor) SetAuditLogRepository(repo AuditLogRepository) {
+	m.auditRepo = repo
+}
+
+// SetLogReader 设置日志文件读取器，未设置时日志查询接口不可用
+func (m *Monitor) SetLogReader(reader *appLogger.LogReader) {
+	m.logReader = reader
+}
+
 // RegisterRoutes 注册Web路由
 func (m *Monitor) RegisterRoutes(r *gin.Engine) {
 	// 静态文件
@@ -30,12 +69,30 @@ func (m *Monitor) RegisterRoutes(r *gin.Engine) {
 	// 主页
 	r.GET("/", m.Index)
 
-	// API接口
-	api := r.Group("/api")
+	// 登录接口
+	if m.auth.Login != nil {
+		r.POST("/api/login", m.auth.Login)
+	}
+
+	// API接口（需要管理员权限）
+	api := r.Group("/api", m.auth.View...)
 	{
 		api.GET("/status", m.GetStatus)
 		api.GET("/instances", m.GetInstances)
 		api.GET("/tasks", m.GetTasks)
+		api.GET("/schedule/preview", m.PreviewSchedule)
+		api.GET("/logs/sources", m.GetLogSources)
+		api.GET("/logs", m.QueryLogs)
+		api.GET("/logs/tail", m.TailLogs)
+
+		// 任务控制（需要特定角色）
+		control := api.Group("/tasks/:name", m.auth.Control...)
+		{
+			control.POST("/trigger", m.controlTask(TaskActionTrigger))
+			control.POST("/pause", m.controlTask(TaskActionPause))
+			control.POST("/resume", m.controlTask(TaskActionResume))
+			control.POST("/kill", m.controlTask(TaskActionKill))
+		}
 	}
 }
 
@@ -105,10 +162,12 @@ func (m *Monitor) GetTasks(c *gin.Context) {
 	for _, instance := range instances {
 		for _, taskName := range instance.Tasks {
 			if _, exists := taskMap[taskName]; !exists {
+				paused, _ := m.controller.IsPaused(c.Request.Context(), taskName)
 				taskMap[taskName] = map[string]interface{}{
 					"name":        taskName,
 					"description": "任务描述",
 					"instances":   []string{instance.InstanceID},
+					"paused":      paused,
 				}
 			} else {
 				// 添加实例到现有任务
@@ -134,8 +193,253 @@ func (m *Monitor) GetTasks(c *gin.Context) {
 	})
 }
 
+// PreviewSchedule 校验cron表达式并预览后N次执行时间
+// 查询参数：expr（cron表达式）、tz（时区，默认本地时区）、count（预览次数，默认5，最大100）
+func (m *Monitor) PreviewSchedule(c *gin.Context) {
+	expr := c.Query("expr")
+	timezone := c.Query("tz")
+
+	count := DefaultPreviewCount
+	if val := c.Query("count"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "无效的count参数",
+			})
+			return
+		}
+		count = n
+	}
+
+	times, err := PreviewSchedule(expr, timezone, count)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": map[string]interface{}{
+			"expr":     expr,
+			"timezone": timezone,
+			"next":     times,
+		},
+	})
+}
+
+// GetLogSources 获取可查询的日志来源
+func (m *Monitor) GetLogSources(c *gin.Context) {
+	if !m.requireLogReader(c) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    m.logReader.Sources(),
+	})
+}
+
+// QueryLogs 查询本地日志文件
+// 查询参数：source（日志来源）、level（级别，多个用逗号分隔）、since/until（RFC3339或"2006-01-02 15:04:05"，默认最近24小时）、
+// keyword（关键字）、request_id（请求ID）、limit（返回条数，默认200，最大1000）
+func (m *Monitor) QueryLogs(c *gin.Context) {
+	if !m.requireLogReader(c) {
+		return
+	}
+
+	query, err := parseLogQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	entries, err := m.logReader.Query(query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": map[string]interface{}{
+			"source":  query.Source,
+			"entries": entries,
+		},
+	})
+}
+
+// TailLogs 实时跟踪日志文件
+// 查询参数：cursor（上次返回的游标，为空时从当天文件末尾开始），其余过滤参数同QueryLogs（忽略since/until）
+func (m *Monitor) TailLogs(c *gin.Context) {
+	if !m.requireLogReader(c) {
+		return
+	}
+
+	query, err := parseLogQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	entries, cursor, err := m.logReader.Tail(query, c.Query("cursor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": map[string]interface{}{
+			"source":  query.Source,
+			"entries": entries,
+			"cursor":  cursor,
+		},
+	})
+}
+
+// requireLogReader 检查日志查询是否可用
+func (m *Monitor) requireLogReader(c *gin.Context) bool {
+	if m.logReader != nil {
+		return true
+	}
+	c.JSON(http.StatusServiceUnavailable, gin.H{
+		"success": false,
+		"error":   "日志查询未启用",
+	})
+	return false
+}
+
+// parseLogQuery 解析日志查询参数
+func parseLogQuery(c *gin.Context) (appLogger.LogQuery, error) {
+	query := appLogger.LogQuery{
+		Source:    c.DefaultQuery("source", "cron"),
+		Keyword:   c.Query("keyword"),
+		RequestID: c.Query("request_id"),
+	}
+
+	if val := c.Query("level"); val != "" {
+		for _, level := range strings.Split(val, ",") {
+			if level = strings.TrimSpace(level); level != "" {
+				query.Levels = append(query.Levels, level)
+			}
+		}
+	}
+
+	var err error
+	if query.Since, err = parseLogTime(c.Query("since")); err != nil {
+		return query, fmt.Errorf("无效的since参数: %w", err)
+	}
+	if query.Until, err = parseLogTime(c.Query("until")); err != nil {
+		return query, fmt.Errorf("无效的until参数: %w", err)
+	}
+	if !query.Since.IsZero() && !query.Until.IsZero() && query.Since.After(query.Until) {
+		return query, fmt.Errorf("since不能晚于until")
+	}
+
+	if val := c.Query("limit"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n <= 0 {
+			return query, fmt.Errorf("无效的limit参数")
+		}
+		query.Limit = n
+	}
+
+	return query, nil
+}
+
+// parseLogTime 解析时间参数，支持RFC3339和本地时间"2006-01-02 15:04:05"
+func parseLogTime(val string) (time.Time, error) {
+	if val == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, val); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02 15:04:05", val, time.Local)
+}
+
+// controlTask 任务控制接口
+func (m *Monitor) controlTask(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		taskName := c.Param("name")
+		utils.SetAuditAction(c, "cron_task."+action)
+		utils.SetAuditTarget(c, "cron_task", taskName)
+		err := m.controller.Apply(c.Request.Context(), action, taskName)
+
+		// 无论成功与否都写入审计日志
+		m.writeAuditLog(c, action, taskName, err)
+
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data": map[string]interface{}{
+				"task":   taskName,
+				"action": action,
+			},
+		})
+	}
+}
+
+// writeAuditLog 写入任务控制审计日志
+func (m *Monitor) writeAuditLog(c *gin.Context, action, taskName string, actionErr error) {
+	adminID, _ := utils.GetAdminID(c)
+	adminRole, _ := utils.GetAdminRole(c)
+
+	details := map[string]interface{}{
+		"admin_role": adminRole,
+		"success":    actionErr == nil,
+	}
+	if actionErr != nil {
+		details["error"] = actionErr.Error()
+	}
+
+	appLogger.Audit("定时任务控制", map[string]interface{}{
+		"admin_id":  adminID,
+		"action":    action,
+		"task_name": taskName,
+		"success":   actionErr == nil,
+	})
+
+	if m.auditRepo == nil {
+		return
+	}
+
+	log := mysqlModel.CreateTaskLog(adminID, mysqlModel.AdminLogAction(action), taskName, details, c.ClientIP(), c.Request.UserAgent())
+	if err := m.auditRepo.Create(c.Request.Context(), log); err != nil {
+		cronLogger.Error("写入任务控制审计日志失败", map[string]interface{}{
+			"admin_id":  adminID,
+			"action":    action,
+			"task_name": taskName,
+			"error":     err.Error(),
+		})
+	}
+}
+
 // getActiveInstances 获取活跃实例
 func (m *Monitor) getActiveInstances(ctx context.Context) ([]*InstanceInfo, error) {
-	instanceRegistry := NewInstanceRegistry(m.redis, "1.0.0")
+	instanceRegistry := NewInstanceRegistry(m.redis, "1.0.0", distributedConfig())
 	return instanceRegistry.GetActiveInstances(ctx)
 }
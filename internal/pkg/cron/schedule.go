@@ -0,0 +1,79 @@
+package cron
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	robfigCron "github.com/robfig/cron/v3"
+)
+
+const (
+	// DefaultPreviewCount 默认预览的执行次数
+	DefaultPreviewCount = 5
+	// MaxPreviewCount 最大预览的执行次数
+	MaxPreviewCount = 100
+)
+
+// ParseSchedule 解析cron表达式
+// 支持标准5段表达式（分 时 日 月 周）及描述符（@every 30s、@hourly、@daily等），与调度器的Cron解析规则一致
+func ParseSchedule(expr string) (robfigCron.Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("cron表达式不能为空")
+	}
+
+	schedule, err := robfigCron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf("无效的cron表达式 %q: %w", expr, err)
+	}
+
+	return schedule, nil
+}
+
+// ValidateSchedule 校验cron表达式
+func ValidateSchedule(expr string) error {
+	_, err := ParseSchedule(expr)
+	return err
+}
+
+// PreviewSchedule 预览cron表达式在指定时区下从当前时间开始的后N次执行时间
+func PreviewSchedule(expr, timezone string, count int) ([]time.Time, error) {
+	return previewScheduleFrom(expr, timezone, count, time.Now())
+}
+
+// previewScheduleFrom 预览从指定时间开始的后N次执行时间
+func previewScheduleFrom(expr, timezone string, count int, from time.Time) ([]time.Time, error) {
+	schedule, err := ParseSchedule(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := time.Local
+	if timezone != "" {
+		loc, err = time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("无效的时区 %q: %w", timezone, err)
+		}
+	}
+
+	if count <= 0 {
+		count = DefaultPreviewCount
+	}
+	if count > MaxPreviewCount {
+		count = MaxPreviewCount
+	}
+
+	times := make([]time.Time, 0, count)
+	next := from.In(loc)
+	for i := 0; i < count; i++ {
+		next = schedule.Next(next)
+		if next.IsZero() {
+			// 表达式不会再触发（例如不存在的日期）
+			break
+		}
+		times = append(times, next)
+	}
+
+	return times, nil
+}
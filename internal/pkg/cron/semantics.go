@@ -0,0 +1,257 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ExecutionSemantics 任务执行语义
+type ExecutionSemantics string
+
+const (
+	// AtMostOnce 至多执行一次：执行中丢失锁时立即终止，实例宕机时不补偿（默认）
+	AtMostOnce ExecutionSemantics = "at-most-once"
+	// AtLeastOnce 至少执行一次：执行前写入pending令牌，实例宕机或执行失败时由其他实例补偿执行
+	AtLeastOnce ExecutionSemantics = "at-least-once"
+)
+
+const (
+	// taskLockTTL 任务分布式锁有效期
+	taskLockTTL = 60 * time.Second
+	// taskLockRenewInterval 任务执行期间的锁续期间隔
+	taskLockRenewInterval = taskLockTTL / 3
+	// maxPendingAttempts at-least-once任务的最大补偿执行次数
+	maxPendingAttempts = 3
+)
+
+var (
+	// errTaskKilled 任务被监控端终止（取消原因）
+	errTaskKilled = errors.New("task killed")
+	// errTaskLockLost 任务执行中丢失分布式锁（取消原因）
+	errTaskLockLost = errors.New("task lock lost")
+)
+
+// SemanticTask 声明执行语义的任务（未实现时默认AtMostOnce）
+type SemanticTask interface {
+	Task
+	Semantics() ExecutionSemantics
+}
+
+// PendingToken at-least-once任务的待完成令牌
+type PendingToken struct {
+	TaskName   string    `json:"task_name"`
+	InstanceID string    `json:"instance_id"` // 当前负责执行的实例
+	Attempts   int       `json:"attempts"`    // 已尝试次数
+	Failed     bool      `json:"failed"`      // 上次执行是否失败
+	LastError  string    `json:"last_error,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// taskSemantics 获取任务执行语义
+func taskSemantics(task Task) ExecutionSemantics {
+	if st, ok := task.(SemanticTask); ok && st.Semantics() == AtLeastOnce {
+		return AtLeastOnce
+	}
+	return AtMostOnce
+}
+
+// pendingKey pending令牌键
+func pendingKey(taskName string) string {
+	return fmt.Sprintf("cron_task_pending:%s", taskName)
+}
+
+// getPending 获取pending令牌，不存在时返回nil
+func (w *Worker) getPending(ctx context.Context, taskName string) (*PendingToken, error) {
+	data, err := w.redis.Client().Get(ctx, pendingKey(taskName)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get pending token of task %s: %w", taskName, err)
+	}
+
+	var token PendingToken
+	if err := json.Unmarshal([]byte(data), &token); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pending token of task %s: %w", taskName, err)
+	}
+	return &token, nil
+}
+
+// markPending 执行前写入（或接管）pending令牌
+func (w *Worker) markPending(ctx context.Context, taskName string) error {
+	token, err := w.getPending(ctx, taskName)
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		token = &PendingToken{TaskName: taskName}
+	}
+
+	token.InstanceID = w.instanceID
+	token.Attempts++
+	token.Failed = false
+	token.LastError = ""
+	token.UpdatedAt = time.Now()
+
+	return w.savePending(ctx, token)
+}
+
+// completePending 执行结束后更新pending令牌：成功或被终止时删除，失败标记等待补偿
+// 被终止是人工决定，不再补偿执行，否则终止的任务会被其他实例重新执行
+func (w *Worker) completePending(ctx context.Context, taskName string, taskErr error, killed bool) {
+	var err error
+	if taskErr == nil || killed {
+		err = w.redis.Client().Del(ctx, pendingKey(taskName)).Err()
+	} else {
+		var token *PendingToken
+		token, err = w.getPending(ctx, taskName)
+		if err == nil && token != nil {
+			token.Failed = true
+			token.LastError = taskErr.Error()
+			token.UpdatedAt = time.Now()
+			err = w.savePending(ctx, token)
+		}
+	}
+
+	if err != nil {
+		w.logger.Warn("更新pending令牌失败", map[string]interface{}{
+			"task_name": taskName,
+			"error":     err.Error(),
+		})
+	}
+}
+
+// savePending 保存pending令牌
+func (w *Worker) savePending(ctx context.Context, token *PendingToken) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pending token: %w", err)
+	}
+	if err := w.redis.Client().Set(ctx, pendingKey(token.TaskName), data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save pending token of task %s: %w", token.TaskName, err)
+	}
+	return nil
+}
+
+// watchPendingTasks 定期检查at-least-once任务的pending令牌并补偿执行
+func (w *Worker) watchPendingTasks() {
+	ticker := time.NewTicker(w.instanceRegistry.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopChan:
+			return
+		case <-ticker.C:
+			w.recoverPendingTasks()
+		}
+	}
+}
+
+// recoverPendingTasks 补偿执行所属实例已失效或执行失败的at-least-once任务
+func (w *Worker) recoverPendingTasks() {
+	ctx := context.Background()
+
+	w.taskLock.Lock()
+	tasks := make(map[string]Task, len(w.tasks))
+	for _, task := range w.tasks {
+		if taskSemantics(task) == AtLeastOnce {
+			tasks[task.Name()] = task
+		}
+	}
+	w.taskLock.Unlock()
+
+	for name, task := range tasks {
+		if w.isRunning(name) {
+			continue
+		}
+
+		token, err := w.getPending(ctx, name)
+		if err != nil {
+			cronLogger.Warn("读取pending令牌失败", map[string]interface{}{
+				"task_name": name,
+				"error":     err.Error(),
+			})
+			continue
+		}
+		if token == nil {
+			continue
+		}
+
+		// 所属实例仍存活且未失败，说明任务正在执行
+		if !token.Failed && w.instanceRegistry.isInstanceAlive(ctx, token.InstanceID) {
+			continue
+		}
+
+		if token.Attempts >= maxPendingAttempts {
+			cronLogger.Error("at-least-once任务补偿次数已达上限，放弃执行", map[string]interface{}{
+				"task_name":  name,
+				"attempts":   token.Attempts,
+				"last_error": token.LastError,
+			})
+			w.redis.Client().Del(ctx, pendingKey(name))
+			continue
+		}
+
+		// 与按调度执行一致，暂停的任务不补偿执行，恢复后再处理pending令牌
+		paused, err := w.controller.IsPaused(ctx, name)
+		if err != nil {
+			cronLogger.Warn("检查任务暂停状态失败", map[string]interface{}{
+				"task_name": name,
+				"error":     err.Error(),
+			})
+		}
+		if paused {
+			continue
+		}
+
+		w.logger.Warn("补偿执行at-least-once任务", map[string]interface{}{
+			"task_name":      name,
+			"owner_instance": token.InstanceID,
+			"failed":         token.Failed,
+			"attempts":       token.Attempts,
+		})
+		go w.runTask(task)
+	}
+}
+
+// keepLock 任务执行期间定期续期分布式锁，锁丢失时按执行语义处理
+func (w *Worker) keepLock(ctx context.Context, task Task, lockKey string, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(taskLockRenewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			renewed, err := w.distributedLock.RenewLock(context.Background(), lockKey, w.instanceID, taskLockTTL)
+			if err == nil && renewed {
+				continue
+			}
+
+			fields := map[string]interface{}{
+				"task_name": task.Name(),
+				"semantics": string(taskSemantics(task)),
+			}
+			if err != nil {
+				fields["error"] = err.Error()
+			}
+
+			if taskSemantics(task) == AtMostOnce {
+				// 锁已丢失，其他实例可能开始执行，终止本次执行避免重复
+				w.logger.Error("任务执行中丢失分布式锁，终止执行", fields)
+				cancel(errTaskLockLost)
+				return
+			}
+
+			// at-least-once任务继续执行，由pending令牌保证完成
+			w.logger.Warn("任务执行中丢失分布式锁，继续执行", fields)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package cron
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ConfigurableTask 支持独立配置块的任务
+// 配置文件中 tasks.<任务名> 的内容会在注册时解析到 DefaultConfig 返回的结构体上并校验
+type ConfigurableTask interface {
+	Task
+	DefaultConfig() interface{} // 返回带默认值的配置结构体指针
+}
+
+// TaskConfigValidator 任务配置校验接口（由配置结构体可选实现）
+type TaskConfigValidator interface {
+	Validate() error
+}
+
+// TaskContext 任务执行上下文
+type TaskContext struct {
+	TaskName   string      // 任务名称
+	InstanceID string      // 执行实例ID
+	Config     interface{} // 任务配置（DefaultConfig返回的类型），未实现ConfigurableTask时为nil
+	StartedAt  time.Time   // 开始执行时间
+}
+
+// taskContextKey 任务上下文在context中的键
+type taskContextKey struct{}
+
+// withTaskContext 将任务上下文附加到context
+func withTaskContext(ctx context.Context, tc *TaskContext) context.Context {
+	return context.WithValue(ctx, taskContextKey{}, tc)
+}
+
+// GetTaskContext 从Run的ctx中获取任务上下文
+func GetTaskContext(ctx context.Context) (*TaskContext, bool) {
+	tc, ok := ctx.Value(taskContextKey{}).(*TaskContext)
+	return tc, ok
+}
+
+// resolveTaskConfig 解析并校验任务配置
+func resolveTaskConfig(task Task, blocks map[string]json.RawMessage) (interface{}, error) {
+	configurable, ok := task.(ConfigurableTask)
+	if !ok {
+		if _, exists := blocks[task.Name()]; exists {
+			return nil, fmt.Errorf("任务 %s 不支持配置，但配置文件中存在 tasks.%s", task.Name(), task.Name())
+		}
+		return nil, nil
+	}
+
+	taskConfig := configurable.DefaultConfig()
+	if raw, exists := blocks[task.Name()]; exists {
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(taskConfig); err != nil {
+			return nil, fmt.Errorf("解析任务 %s 的配置失败: %w", task.Name(), err)
+		}
+	}
+
+	if validator, ok := taskConfig.(TaskConfigValidator); ok {
+		if err := validator.Validate(); err != nil {
+			return nil, fmt.Errorf("任务 %s 的配置无效: %w", task.Name(), err)
+		}
+	}
+
+	return taskConfig, nil
+}
@@ -0,0 +1,132 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"exchange/internal/pkg/database"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 任务控制操作
+const (
+	TaskActionTrigger = "trigger" // 立即触发执行
+	TaskActionPause   = "pause"   // 暂停调度
+	TaskActionResume  = "resume"  // 恢复调度
+	TaskActionKill    = "kill"    // 终止正在执行的任务
+)
+
+const (
+	// taskSignalTTL 触发/终止信号的有效期，超时未被消费则丢弃
+	taskSignalTTL = 30 * time.Second
+)
+
+// TaskController 任务控制器（通过Redis在监控界面与各执行实例之间传递控制信号）
+type TaskController struct {
+	redis *database.RedisService
+}
+
+// NewTaskController 创建任务控制器
+func NewTaskController(redis *database.RedisService) *TaskController {
+	return &TaskController{
+		redis: redis,
+	}
+}
+
+// Pause 暂停任务调度
+func (tc *TaskController) Pause(ctx context.Context, taskName string) error {
+	if err := tc.redis.Client().Set(ctx, tc.pausedKey(taskName), time.Now().Unix(), 0).Err(); err != nil {
+		return fmt.Errorf("failed to pause task %s: %w", taskName, err)
+	}
+	return nil
+}
+
+// Resume 恢复任务调度
+func (tc *TaskController) Resume(ctx context.Context, taskName string) error {
+	if err := tc.redis.Client().Del(ctx, tc.pausedKey(taskName)).Err(); err != nil {
+		return fmt.Errorf("failed to resume task %s: %w", taskName, err)
+	}
+	return nil
+}
+
+// IsPaused 检查任务是否已暂停
+func (tc *TaskController) IsPaused(ctx context.Context, taskName string) (bool, error) {
+	count, err := tc.redis.Client().Exists(ctx, tc.pausedKey(taskName)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check task %s paused: %w", taskName, err)
+	}
+	return count > 0, nil
+}
+
+// Trigger 请求立即执行一次任务（由任一持有该任务的实例消费）
+func (tc *TaskController) Trigger(ctx context.Context, taskName string) error {
+	if err := tc.redis.Client().Set(ctx, tc.triggerKey(taskName), time.Now().Unix(), taskSignalTTL).Err(); err != nil {
+		return fmt.Errorf("failed to trigger task %s: %w", taskName, err)
+	}
+	return nil
+}
+
+// ConsumeTrigger 消费触发信号，返回是否存在待执行的触发请求
+func (tc *TaskController) ConsumeTrigger(ctx context.Context, taskName string) (bool, error) {
+	_, err := tc.redis.Client().GetDel(ctx, tc.triggerKey(taskName)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to consume trigger of task %s: %w", taskName, err)
+	}
+	return true, nil
+}
+
+// Kill 请求终止正在执行的任务
+func (tc *TaskController) Kill(ctx context.Context, taskName string) error {
+	if err := tc.redis.Client().Set(ctx, tc.killKey(taskName), time.Now().Unix(), taskSignalTTL).Err(); err != nil {
+		return fmt.Errorf("failed to kill task %s: %w", taskName, err)
+	}
+	return nil
+}
+
+// ConsumeKill 消费终止信号，返回是否存在终止请求
+func (tc *TaskController) ConsumeKill(ctx context.Context, taskName string) (bool, error) {
+	_, err := tc.redis.Client().GetDel(ctx, tc.killKey(taskName)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to consume kill of task %s: %w", taskName, err)
+	}
+	return true, nil
+}
+
+// Apply 执行控制操作
+func (tc *TaskController) Apply(ctx context.Context, action, taskName string) error {
+	switch action {
+	case TaskActionTrigger:
+		return tc.Trigger(ctx, taskName)
+	case TaskActionPause:
+		return tc.Pause(ctx, taskName)
+	case TaskActionResume:
+		return tc.Resume(ctx, taskName)
+	case TaskActionKill:
+		return tc.Kill(ctx, taskName)
+	default:
+		return fmt.Errorf("unknown task action: %s", action)
+	}
+}
+
+// pausedKey 暂停标记键
+func (tc *TaskController) pausedKey(taskName string) string {
+	return fmt.Sprintf("cron_task_paused:%s", taskName)
+}
+
+// triggerKey 触发信号键
+func (tc *TaskController) triggerKey(taskName string) string {
+	return fmt.Sprintf("cron_task_trigger:%s", taskName)
+}
+
+// killKey 终止信号键
+func (tc *TaskController) killKey(taskName string) string {
+	return fmt.Sprintf("cron_task_kill:%s", taskName)
+}
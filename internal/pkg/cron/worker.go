@@ -2,17 +2,27 @@ package cron
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"exchange/internal/pkg/database"
+	appErrors "exchange/internal/pkg/errors"
 	appLogger "exchange/internal/pkg/logger"
 	"exchange/internal/pkg/services"
+	"exchange/internal/pkg/tracing"
 
 	"github.com/go-co-op/gocron"
 )
 
+// cronLogger 定时任务模块日志，级别可通过log.modules.cron单独设置
+var cronLogger = appLogger.Module("cron")
+
 type Task interface {
 	Name() string                                                           // 任务名称
 	Description() string                                                    // 任务描述
@@ -30,6 +40,11 @@ type Worker struct {
 	stopChan         chan struct{}
 	globalServices   *services.GlobalServices
 	redis            *database.RedisService
+	controller       *TaskController
+	running          map[string]context.CancelCauseFunc // 正在执行的任务
+	runningLock      sync.Mutex
+	taskConfigs      map[string]interface{} // 任务配置（注册时解析）
+	logger           *appLogger.Logger      // 绑定instance_id的日志记录器
 }
 
 // NewWorker 创建任务执行器
@@ -38,22 +53,89 @@ func NewWorker(redis *database.RedisService) *Worker {
 		tasks:            []Task{},
 		scheduler:        gocron.NewScheduler(time.Local),
 		distributedLock:  NewDistributedLock(redis),
-		instanceRegistry: NewInstanceRegistry(redis, "1.0.0"),
+		instanceRegistry: NewInstanceRegistry(redis, "1.0.0", distributedConfig()),
 		stopChan:         make(chan struct{}),
 		globalServices:   services.GetGlobalServices(),
 		redis:            redis,
+		controller:       NewTaskController(redis),
+		running:          make(map[string]context.CancelCauseFunc),
+		taskConfigs:      make(map[string]interface{}),
 	}
 
 	worker.instanceID = worker.instanceRegistry.GetInstanceID()
+	worker.logger = cronLogger.With(map[string]interface{}{"instance_id": worker.instanceID})
 	return worker
 }
 
+// addTask 解析任务配置并加入任务列表（调用方需持有taskLock）
+func (w *Worker) addTask(task Task) error {
+	var blocks map[string]json.RawMessage
+	if cfg := w.globalServices.GetConfig(); cfg != nil {
+		blocks = cfg.Tasks
+	}
+
+	taskConfig, err := resolveTaskConfig(task, blocks)
+	if err != nil {
+		cronLogger.Error("任务配置校验失败，任务未注册", map[string]interface{}{
+			"task_name": task.Name(),
+			"error":     err.Error(),
+		})
+		return err
+	}
+
+	if taskConfig != nil {
+		w.taskConfigs[task.Name()] = taskConfig
+	}
+	w.tasks = append(w.tasks, task)
+	return nil
+}
+
+// RegisterTask 按cron表达式注册任务，表达式无效时返回错误且不注册
+func (w *Worker) RegisterTask(task Task, spec string) error {
+	if err := ValidateSchedule(spec); err != nil {
+		cronLogger.Error("注册cron任务失败", map[string]interface{}{
+			"task_name": task.Name(),
+			"spec":      spec,
+			"error":     err.Error(),
+		})
+		return fmt.Errorf("注册任务 %s 失败: %w", task.Name(), err)
+	}
+
+	w.taskLock.Lock()
+	defer w.taskLock.Unlock()
+
+	if err := w.addTask(task); err != nil {
+		return err
+	}
+
+	// 注册到调度器
+	_, err := w.scheduler.Cron(spec).Do(func() {
+		w.executeTask(task)
+	})
+	if err != nil {
+		cronLogger.Error("注册cron任务失败", map[string]interface{}{
+			"task_name": task.Name(),
+			"spec":      spec,
+			"error":     err.Error(),
+		})
+		return fmt.Errorf("注册任务 %s 失败: %w", task.Name(), err)
+	}
+
+	cronLogger.Info("注册cron任务成功", map[string]interface{}{
+		"task_name": task.Name(),
+		"schedule":  spec,
+	})
+	return nil
+}
+
 // RegisterTaskEverySeconds 注册每N秒执行的任务
 func (w *Worker) RegisterTaskEverySeconds(task Task, seconds int) {
 	w.taskLock.Lock()
 	defer w.taskLock.Unlock()
 
-	w.tasks = append(w.tasks, task)
+	if err := w.addTask(task); err != nil {
+		return
+	}
 
 	// 注册到调度器
 	_, err := w.scheduler.Every(seconds).Seconds().Do(func() {
@@ -61,13 +143,13 @@ func (w *Worker) RegisterTaskEverySeconds(task Task, seconds int) {
 	})
 
 	if err != nil {
-		appLogger.Error("注册秒级任务失败", map[string]interface{}{
+		cronLogger.Error("注册秒级任务失败", map[string]interface{}{
 			"task_name": task.Name(),
 			"seconds":   seconds,
 			"error":     err.Error(),
 		})
 	} else {
-		appLogger.Info("注册秒级任务成功", map[string]interface{}{
+		cronLogger.Info("注册秒级任务成功", map[string]interface{}{
 			"task_name": task.Name(),
 			"schedule":  fmt.Sprintf("每%d秒执行", seconds),
 		})
@@ -79,7 +161,9 @@ func (w *Worker) RegisterTaskEveryMinutes(task Task, minutes int) {
 	w.taskLock.Lock()
 	defer w.taskLock.Unlock()
 
-	w.tasks = append(w.tasks, task)
+	if err := w.addTask(task); err != nil {
+		return
+	}
 
 	// 注册到调度器
 	_, err := w.scheduler.Every(minutes).Minutes().Do(func() {
@@ -87,13 +171,13 @@ func (w *Worker) RegisterTaskEveryMinutes(task Task, minutes int) {
 	})
 
 	if err != nil {
-		appLogger.Error("注册分钟级任务失败", map[string]interface{}{
+		cronLogger.Error("注册分钟级任务失败", map[string]interface{}{
 			"task_name": task.Name(),
 			"minutes":   minutes,
 			"error":     err.Error(),
 		})
 	} else {
-		appLogger.Info("注册分钟级任务成功", map[string]interface{}{
+		cronLogger.Info("注册分钟级任务成功", map[string]interface{}{
 			"task_name": task.Name(),
 			"schedule":  fmt.Sprintf("每%d分钟执行", minutes),
 		})
@@ -105,7 +189,9 @@ func (w *Worker) RegisterTaskEveryHours(task Task, hours int) {
 	w.taskLock.Lock()
 	defer w.taskLock.Unlock()
 
-	w.tasks = append(w.tasks, task)
+	if err := w.addTask(task); err != nil {
+		return
+	}
 
 	// 注册到调度器
 	_, err := w.scheduler.Every(hours).Hours().Do(func() {
@@ -113,13 +199,13 @@ func (w *Worker) RegisterTaskEveryHours(task Task, hours int) {
 	})
 
 	if err != nil {
-		appLogger.Error("注册小时级任务失败", map[string]interface{}{
+		cronLogger.Error("注册小时级任务失败", map[string]interface{}{
 			"task_name": task.Name(),
 			"hours":     hours,
 			"error":     err.Error(),
 		})
 	} else {
-		appLogger.Info("注册小时级任务成功", map[string]interface{}{
+		cronLogger.Info("注册小时级任务成功", map[string]interface{}{
 			"task_name": task.Name(),
 			"schedule":  fmt.Sprintf("每%d小时执行", hours),
 		})
@@ -131,7 +217,9 @@ func (w *Worker) RegisterTaskEveryDays(task Task, days int) {
 	w.taskLock.Lock()
 	defer w.taskLock.Unlock()
 
-	w.tasks = append(w.tasks, task)
+	if err := w.addTask(task); err != nil {
+		return
+	}
 
 	// 注册到调度器
 	_, err := w.scheduler.Every(days).Days().Do(func() {
@@ -139,13 +227,13 @@ func (w *Worker) RegisterTaskEveryDays(task Task, days int) {
 	})
 
 	if err != nil {
-		appLogger.Error("注册天级任务失败", map[string]interface{}{
+		cronLogger.Error("注册天级任务失败", map[string]interface{}{
 			"task_name": task.Name(),
 			"days":      days,
 			"error":     err.Error(),
 		})
 	} else {
-		appLogger.Info("注册天级任务成功", map[string]interface{}{
+		cronLogger.Info("注册天级任务成功", map[string]interface{}{
 			"task_name": task.Name(),
 			"schedule":  fmt.Sprintf("每%d天执行", days),
 		})
@@ -157,7 +245,9 @@ func (w *Worker) RegisterTaskDailyAt(task Task, timeStr string) {
 	w.taskLock.Lock()
 	defer w.taskLock.Unlock()
 
-	w.tasks = append(w.tasks, task)
+	if err := w.addTask(task); err != nil {
+		return
+	}
 
 	// 注册到调度器
 	_, err := w.scheduler.Every(1).Day().At(timeStr).Do(func() {
@@ -165,13 +255,13 @@ func (w *Worker) RegisterTaskDailyAt(task Task, timeStr string) {
 	})
 
 	if err != nil {
-		appLogger.Error("注册每日定时任务失败", map[string]interface{}{
+		cronLogger.Error("注册每日定时任务失败", map[string]interface{}{
 			"task_name": task.Name(),
 			"time":      timeStr,
 			"error":     err.Error(),
 		})
 	} else {
-		appLogger.Info("注册每日定时任务成功", map[string]interface{}{
+		cronLogger.Info("注册每日定时任务成功", map[string]interface{}{
 			"task_name": task.Name(),
 			"schedule":  fmt.Sprintf("每天 %s 执行", timeStr),
 		})
@@ -187,7 +277,7 @@ func (w *Worker) Start() {
 	}
 
 	if err := w.instanceRegistry.Register(context.Background(), taskNames); err != nil {
-		appLogger.Error("注册实例失败", map[string]interface{}{
+		cronLogger.Error("注册实例失败", map[string]interface{}{
 			"error": err.Error(),
 		})
 		return
@@ -196,11 +286,16 @@ func (w *Worker) Start() {
 	// 启动心跳
 	go w.instanceRegistry.StartHeartbeat(context.Background())
 
+	// 启动控制信号监听
+	go w.watchControlSignals()
+
+	// 启动at-least-once任务补偿
+	go w.watchPendingTasks()
+
 	// 启动调度器
 	w.scheduler.StartAsync()
 
-	appLogger.Info("任务执行器已启动", map[string]interface{}{
-		"instance_id": w.instanceID,
+	w.logger.Info("任务执行器已启动", map[string]interface{}{
 		"tasks_count": len(w.tasks),
 	})
 }
@@ -213,23 +308,122 @@ func (w *Worker) Stop() {
 	// 注销实例
 	w.instanceRegistry.Unregister(context.Background())
 
-	appLogger.Info("任务执行器已停止", map[string]interface{}{
-		"instance_id": w.instanceID,
-	})
+	w.logger.Info("任务执行器已停止")
+}
+
+// watchControlSignals 监听监控界面下发的触发/终止信号
+func (w *Worker) watchControlSignals() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopChan:
+			return
+		case <-ticker.C:
+			w.handleControlSignals()
+		}
+	}
+}
+
+// handleControlSignals 处理一轮控制信号
+func (w *Worker) handleControlSignals() {
+	ctx := context.Background()
+
+	w.taskLock.Lock()
+	tasks := make(map[string]Task, len(w.tasks))
+	for _, task := range w.tasks {
+		tasks[task.Name()] = task
+	}
+	w.taskLock.Unlock()
+
+	for name, task := range tasks {
+		// 终止信号只由正在执行该任务的实例消费
+		if w.isRunning(name) {
+			killed, err := w.controller.ConsumeKill(ctx, name)
+			if err != nil {
+				cronLogger.Warn("读取任务终止信号失败", map[string]interface{}{
+					"task_name": name,
+					"error":     err.Error(),
+				})
+			} else if killed {
+				w.cancelTask(name)
+			}
+		}
+
+		triggered, err := w.controller.ConsumeTrigger(ctx, name)
+		if err != nil {
+			cronLogger.Warn("读取任务触发信号失败", map[string]interface{}{
+				"task_name": name,
+				"error":     err.Error(),
+			})
+			continue
+		}
+		if triggered {
+			w.logger.Info("收到手动触发信号", map[string]interface{}{
+				"task_name": name,
+			})
+			go w.runTask(task)
+		}
+	}
+}
+
+// getTaskConfig 获取任务配置
+func (w *Worker) getTaskConfig(taskName string) interface{} {
+	w.taskLock.Lock()
+	defer w.taskLock.Unlock()
+	return w.taskConfigs[taskName]
+}
+
+// isRunning 检查任务是否正在本实例执行
+func (w *Worker) isRunning(taskName string) bool {
+	w.runningLock.Lock()
+	defer w.runningLock.Unlock()
+	_, exists := w.running[taskName]
+	return exists
 }
 
-// executeTask 执行任务（带分布式锁）
+// cancelTask 取消正在本实例执行的任务
+func (w *Worker) cancelTask(taskName string) {
+	w.runningLock.Lock()
+	cancel, exists := w.running[taskName]
+	w.runningLock.Unlock()
+
+	if exists {
+		cancel(errTaskKilled)
+		w.logger.Warn("任务已被终止", map[string]interface{}{
+			"task_name": taskName,
+		})
+	}
+}
+
+// executeTask 按调度执行任务（任务暂停时跳过）
 func (w *Worker) executeTask(task Task) {
+	paused, err := w.controller.IsPaused(context.Background(), task.Name())
+	if err != nil {
+		cronLogger.Warn("检查任务暂停状态失败", map[string]interface{}{
+			"task_name": task.Name(),
+			"error":     err.Error(),
+		})
+	}
+	if paused {
+		return
+	}
+
+	w.runTask(task)
+}
+
+// runTask 执行任务（带分布式锁，可被终止）
+func (w *Worker) runTask(task Task) {
 	ctx := context.Background()
 	lockKey := fmt.Sprintf("task_lock:%s", task.Name())
 
 	// 尝试获取分布式锁
-	locked, err := w.distributedLock.TryAcquireLock(ctx, lockKey, w.instanceID, 60*time.Second)
+	locked, err := w.distributedLock.TryAcquireLock(ctx, lockKey, w.instanceID, taskLockTTL)
 	if err != nil {
-		appLogger.Error("获取分布式锁失败", map[string]interface{}{
-			"task_name":   task.Name(),
-			"instance_id": w.instanceID,
-			"error":       err.Error(),
+		w.logger.Error("获取分布式锁失败", map[string]interface{}{
+			"task_name": task.Name(),
+			"error":     err.Error(),
 		})
 		return
 	}
@@ -242,44 +436,95 @@ func (w *Worker) executeTask(task Task) {
 	// 确保锁会被释放
 	defer func() {
 		if err := w.distributedLock.ReleaseLock(ctx, lockKey, w.instanceID); err != nil {
-			appLogger.Warn("释放分布式锁失败", map[string]interface{}{
-				"task_name":   task.Name(),
-				"instance_id": w.instanceID,
-				"error":       err.Error(),
+			w.logger.Warn("释放分布式锁失败", map[string]interface{}{
+				"task_name": task.Name(),
+				"error":     err.Error(),
 			})
 		}
 	}()
 
-	// 执行任务
+	// 创建可取消的任务上下文，用于响应终止信号
+	taskCtx, cancel := context.WithCancelCause(ctx)
+	w.runningLock.Lock()
+	w.running[task.Name()] = cancel
+	w.runningLock.Unlock()
+	defer func() {
+		cancel(nil)
+		w.runningLock.Lock()
+		delete(w.running, task.Name())
+		w.runningLock.Unlock()
+	}()
+
+	// 执行期间续期分布式锁
+	go w.keepLock(taskCtx, task, lockKey, cancel)
+
+	// at-least-once任务执行前写入pending令牌
+	semantics := taskSemantics(task)
+	if semantics == AtLeastOnce {
+		if err := w.markPending(ctx, task.Name()); err != nil {
+			w.logger.Error("写入pending令牌失败", map[string]interface{}{
+				"task_name": task.Name(),
+				"error":     err.Error(),
+			})
+			return
+		}
+	}
+
+	// 执行任务，每次执行一个span（任务中的数据库调用为其子span）
 	startTime := time.Now()
 	var taskErr error
+	spanCtx, span := tracing.Tracer().Start(taskCtx, "cron "+task.Name(),
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.String("cron.task", task.Name()),
+			attribute.String("cron.instance", w.instanceID),
+			attribute.String("cron.semantics", string(semantics)),
+		),
+	)
 
 	func() {
 		defer func() {
 			if r := recover(); r != nil {
+				appErrors.ReportPanic(r, nil)
 				taskErr = fmt.Errorf("task panic: %v", r)
 			}
 		}()
 
-		taskErr = task.Run(ctx, w.globalServices)
+		taskErr = task.Run(withTaskContext(spanCtx, &TaskContext{
+			TaskName:   task.Name(),
+			InstanceID: w.instanceID,
+			Config:     w.getTaskConfig(task.Name()),
+			StartedAt:  startTime,
+		}), w.globalServices)
 	}()
+	tracing.RecordError(spanCtx, taskErr)
+	span.End()
+
+	killed := errors.Is(context.Cause(taskCtx), errTaskKilled)
+	if semantics == AtLeastOnce {
+		w.completePending(ctx, task.Name(), taskErr, killed)
+	}
 
 	// 记录执行结果
 	completedAt := time.Now()
 	duration := completedAt.Sub(startTime)
 
-	if taskErr != nil {
-		appLogger.Error("任务执行失败", map[string]interface{}{
-			"task_name":   task.Name(),
-			"instance_id": w.instanceID,
-			"duration":    duration.String(),
-			"error":       taskErr.Error(),
+	if killed {
+		w.logger.Warn("任务被终止，本次执行结束", map[string]interface{}{
+			"task_name": task.Name(),
+			"duration":  duration.String(),
+		})
+	} else if taskErr != nil {
+		appErrors.Report(taskErr, nil)
+		w.logger.Error("任务执行失败", map[string]interface{}{
+			"task_name": task.Name(),
+			"duration":  duration.String(),
+			"error":     taskErr.Error(),
 		})
 	} else {
-		appLogger.Info("任务执行成功", map[string]interface{}{
-			"task_name":   task.Name(),
-			"instance_id": w.instanceID,
-			"duration":    duration.String(),
+		w.logger.Info("任务执行成功", map[string]interface{}{
+			"task_name": task.Name(),
+			"duration":  duration.String(),
 		})
 	}
 }
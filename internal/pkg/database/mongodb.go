@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -10,8 +11,10 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 
+	"exchange/internal/pkg/breaker"
 	"exchange/internal/pkg/config"
 	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/tracing"
 )
 
 // MongoDBService MongoDB文档数据库服务
@@ -33,7 +36,33 @@ func NewMongoDBService(cfg *config.Config) (*MongoDBService, error) {
 		SetMaxConnIdleTime(30 * time.Second).
 		SetConnectTimeout(time.Duration(cfg.MongoDB.Timeout) * time.Second).
 		SetSocketTimeout(time.Duration(cfg.MongoDB.Timeout) * time.Second).
-		SetServerSelectionTimeout(time.Duration(cfg.MongoDB.Timeout) * time.Second)
+		SetServerSelectionTimeout(time.Duration(cfg.MongoDB.Timeout) * time.Second).
+		SetPoolMonitor(breaker.NewMongoPoolMonitor(breaker.Get(breaker.MongoDB))). // 连接失败计入MongoDB熔断器
+		SetMonitor(tracing.NewMongoCommandMonitor())                               // 每条命令一个追踪span
+
+	// TLS，服务器名为空时驱动按各节点地址校验证书
+	tlsConfig, err := buildTLSConfig(cfg.MongoDB.TLS, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure MongoDB TLS: %w", err)
+	}
+	if tlsConfig != nil {
+		clientOptions.SetTLSConfig(tlsConfig)
+	}
+
+	// 认证（SCRAM、X.509、AWS IAM），未配置时使用URI中的设置
+	if cfg.MongoDB.Username != "" || cfg.MongoDB.AuthMechanism != "" {
+		authSource := cfg.MongoDB.AuthSource
+		if authSource == "" && (cfg.MongoDB.AuthMechanism == "" || strings.HasPrefix(cfg.MongoDB.AuthMechanism, "SCRAM")) {
+			authSource = "admin"
+		}
+		clientOptions.SetAuth(options.Credential{
+			AuthMechanism: cfg.MongoDB.AuthMechanism,
+			AuthSource:    authSource,
+			Username:      cfg.MongoDB.Username,
+			Password:      cfg.MongoDB.Password,
+			PasswordSet:   cfg.MongoDB.Password != "",
+		})
+	}
 
 	// 连接MongoDB
 	client, err := mongo.Connect(ctx, clientOptions)
@@ -52,6 +81,8 @@ func NewMongoDBService(cfg *config.Config) (*MongoDBService, error) {
 	appLogger.Info("MongoDB connected successfully", map[string]interface{}{
 		"uri":      cfg.MongoDB.URI,
 		"database": cfg.MongoDB.Database,
+		"tls":      cfg.MongoDB.TLS.Enabled,
+		"auth":     cfg.MongoDB.AuthMechanism,
 	})
 
 	return &MongoDBService{
@@ -1,15 +1,21 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
+	mysqlDriver "github.com/go-sql-driver/mysql"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 
+	"exchange/internal/pkg/breaker"
+	"exchange/internal/pkg/clock"
 	"exchange/internal/pkg/config"
 	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/tracing"
 )
 
 // MySQLService MySQL数据库服务
@@ -38,19 +44,34 @@ func NewMySQLService(cfg *config.Config) (*MySQLService, error) {
 	gormConfig := &gorm.Config{
 		Logger: logger.Default.LogMode(logLevel),
 		NowFunc: func() time.Time {
-			return time.Now().Local()
+			return clock.Now().Local()
 		},
 		DisableForeignKeyConstraintWhenMigrating: true,
 	}
 
+	dialector, err := newMySQLDialector(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure MySQL connection: %w", err)
+	}
+
 	// 连接数据库
-	db, err := gorm.Open(mysql.Open(cfg.GetDSN()), gormConfig)
+	db, err := gorm.Open(dialector, gormConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to MySQL: %w", err)
 	}
 
 	// 软删除插件会自动工作，无需手动注册
 
+	// 追踪插件：每条SQL一个span（熔断拒绝的调用也记录在内）
+	if err := db.Use(tracing.NewGormPlugin()); err != nil {
+		return nil, fmt.Errorf("failed to register tracing plugin: %w", err)
+	}
+
+	// 熔断插件：MySQL熔断期间不执行SQL，直接返回错误
+	if err := db.Use(breaker.NewGormPlugin(breaker.Get(breaker.MySQL))); err != nil {
+		return nil, fmt.Errorf("failed to register circuit breaker plugin: %w", err)
+	}
+
 	// 获取底层sql.DB对象进行连接池配置
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -71,11 +92,60 @@ func NewMySQLService(cfg *config.Config) (*MySQLService, error) {
 		"host":     cfg.Database.Host,
 		"port":     cfg.Database.Port,
 		"database": cfg.Database.Database,
+		"tls":      cfg.Database.TLS.Enabled,
+		"auth":     cfg.Database.Auth.Plugin,
 	})
 
 	return &MySQLService{db: db}, nil
 }
 
+// newMySQLDialector 创建MySQL连接，按配置启用TLS和认证插件
+// 使用认证插件时每次新建连接前获取密码，令牌过期后新连接自动使用新令牌
+func newMySQLDialector(cfg *config.Config) (gorm.Dialector, error) {
+	if !cfg.Database.TLS.Enabled && cfg.Database.Auth.Plugin == "password" {
+		return mysql.Open(cfg.GetDSN()), nil
+	}
+
+	dsnConfig, err := mysqlDriver.ParseDSN(cfg.GetDSN())
+	if err != nil {
+		return nil, fmt.Errorf("invalid DSN: %w", err)
+	}
+
+	tlsConfig, err := buildTLSConfig(cfg.Database.TLS, cfg.Database.Host)
+	if err != nil {
+		return nil, err
+	}
+	dsnConfig.TLS = tlsConfig
+
+	if cfg.Database.Auth.Plugin != "password" {
+		plugin, err := newAuthPlugin(cfg)
+		if err != nil {
+			return nil, err
+		}
+		// 云IAM令牌通过mysql_clear_password发送，已强制要求TLS
+		dsnConfig.AllowCleartextPasswords = true
+		if err := dsnConfig.Apply(mysqlDriver.BeforeConnect(func(ctx context.Context, c *mysqlDriver.Config) error {
+			password, err := plugin.Password(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get database password: %w", err)
+			}
+			c.Passwd = password
+			return nil
+		})); err != nil {
+			return nil, err
+		}
+	}
+
+	connector, err := mysqlDriver.NewConnector(dsnConfig)
+	if err != nil {
+		return nil, err
+	}
+	return mysql.New(mysql.Config{
+		Conn:      sql.OpenDB(connector),
+		DSNConfig: dsnConfig,
+	}), nil
+}
+
 // DB 获取GORM数据库实例
 func (s *MySQLService) DB() *gorm.DB {
 	return s.db
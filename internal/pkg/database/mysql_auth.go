@@ -0,0 +1,258 @@
+package database
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"exchange/internal/pkg/config"
+)
+
+// AuthPlugin MySQL认证插件，每次新建连接前获取密码（云IAM认证时为短期令牌）
+type AuthPlugin interface {
+	Password(ctx context.Context) (string, error)
+}
+
+// AuthPluginFactory 认证插件工厂
+type AuthPluginFactory func(cfg *config.Config) (AuthPlugin, error)
+
+var (
+	authPluginsMu sync.RWMutex
+	authPlugins   = map[string]AuthPluginFactory{
+		"password": newPasswordAuthPlugin,
+		"aws_iam":  newAWSIAMAuthPlugin,
+		"command":  newCommandAuthPlugin,
+	}
+)
+
+// RegisterAuthPlugin 注册认证插件，需在创建MySQL服务之前调用
+func RegisterAuthPlugin(name string, factory AuthPluginFactory) {
+	authPluginsMu.Lock()
+	defer authPluginsMu.Unlock()
+	authPlugins[name] = factory
+}
+
+// newAuthPlugin 根据配置创建认证插件
+func newAuthPlugin(cfg *config.Config) (AuthPlugin, error) {
+	authPluginsMu.RLock()
+	factory, ok := authPlugins[cfg.Database.Auth.Plugin]
+	authPluginsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown database auth plugin: %s", cfg.Database.Auth.Plugin)
+	}
+	return factory(cfg)
+}
+
+// passwordAuthPlugin 使用配置中的静态密码
+type passwordAuthPlugin struct {
+	password string
+}
+
+func newPasswordAuthPlugin(cfg *config.Config) (AuthPlugin, error) {
+	return &passwordAuthPlugin{password: cfg.Database.Password}, nil
+}
+
+// Password 返回静态密码
+func (p *passwordAuthPlugin) Password(ctx context.Context) (string, error) {
+	return p.password, nil
+}
+
+// cachedToken 令牌缓存，过期后重新获取
+type cachedToken struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	token     string
+	expiresAt time.Time
+	fetch     func(ctx context.Context) (string, error)
+}
+
+// get 获取令牌
+func (c *cachedToken) get(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Now().Before(c.expiresAt) {
+		return c.token, nil
+	}
+
+	token, err := c.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+	c.expiresAt = time.Now().Add(c.ttl)
+	return token, nil
+}
+
+// awsIAMAuthPlugin RDS IAM数据库认证
+// 令牌为SigV4预签名的连接请求，有效期15分钟；凭证从AWS_ACCESS_KEY_ID、AWS_SECRET_ACCESS_KEY、AWS_SESSION_TOKEN读取
+type awsIAMAuthPlugin struct {
+	endpoint string
+	user     string
+	region   string
+	cache    *cachedToken
+}
+
+// rds令牌有效期固定为15分钟，缓存时间不能超过此值
+const awsIAMTokenExpires = 15 * time.Minute
+
+func newAWSIAMAuthPlugin(cfg *config.Config) (AuthPlugin, error) {
+	region := cfg.Database.Auth.Region
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		return nil, fmt.Errorf("aws_iam auth requires region")
+	}
+
+	p := &awsIAMAuthPlugin{
+		endpoint: fmt.Sprintf("%s:%d", cfg.Database.Host, cfg.Database.Port),
+		user:     cfg.Database.Username,
+		region:   region,
+	}
+	p.cache = &cachedToken{ttl: tokenTTL(cfg, awsIAMTokenExpires-time.Minute), fetch: p.generate}
+	return p, nil
+}
+
+// Password 返回缓存的认证令牌
+func (p *awsIAMAuthPlugin) Password(ctx context.Context) (string, error) {
+	return p.cache.get(ctx)
+}
+
+// generate 生成RDS认证令牌
+func (p *awsIAMAuthPlugin) generate(ctx context.Context) (string, error) {
+	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return "", fmt.Errorf("aws credentials not found in environment")
+	}
+	return buildRDSAuthToken(p.endpoint, p.region, p.user, accessKey, secretKey, os.Getenv("AWS_SESSION_TOKEN"), time.Now().UTC()), nil
+}
+
+// buildRDSAuthToken 按SigV4预签名 GET https://{endpoint}/?Action=connect&DBUser={user}，去掉协议前缀即为令牌
+func buildRDSAuthToken(endpoint, region, user, accessKey, secretKey, sessionToken string, now time.Time) string {
+	const service = "rds-db"
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+
+	params := map[string]string{
+		"Action":              "connect",
+		"DBUser":              user,
+		"X-Amz-Algorithm":     "AWS4-HMAC-SHA256",
+		"X-Amz-Credential":    accessKey + "/" + scope,
+		"X-Amz-Date":          amzDate,
+		"X-Amz-Expires":       fmt.Sprintf("%d", int(awsIAMTokenExpires.Seconds())),
+		"X-Amz-SignedHeaders": "host",
+	}
+	if sessionToken != "" {
+		params["X-Amz-Security-Token"] = sessionToken
+	}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, awsEscape(key)+"="+awsEscape(params[key]))
+	}
+	query := strings.Join(pairs, "&")
+
+	emptyHash := sha256.Sum256(nil)
+	canonicalRequest := strings.Join([]string{
+		"GET",
+		"/",
+		query,
+		"host:" + endpoint + "\n",
+		"host",
+		hex.EncodeToString(emptyHash[:]),
+	}, "\n")
+
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	return endpoint + "/?" + query + "&X-Amz-Signature=" + signature
+}
+
+// awsEscape SigV4要求的URI编码（空格编码为%20）
+func awsEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// hmacSHA256 计算HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// commandAuthPlugin 执行外部命令获取令牌，命令标准输出（去掉首尾空白）即为密码
+// 例如GCP Cloud SQL: gcloud sql generate-login-token；Azure: az account get-access-token --resource-type oss-rdbms --query accessToken -o tsv
+type commandAuthPlugin struct {
+	command []string
+	cache   *cachedToken
+}
+
+func newCommandAuthPlugin(cfg *config.Config) (AuthPlugin, error) {
+	if len(cfg.Database.Auth.Command) == 0 {
+		return nil, fmt.Errorf("command auth requires command")
+	}
+	p := &commandAuthPlugin{command: cfg.Database.Auth.Command}
+	p.cache = &cachedToken{ttl: tokenTTL(cfg, 0), fetch: p.run}
+	return p, nil
+}
+
+// Password 返回缓存的令牌
+func (p *commandAuthPlugin) Password(ctx context.Context) (string, error) {
+	return p.cache.get(ctx)
+}
+
+// run 执行命令
+func (p *commandAuthPlugin) run(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, p.command[0], p.command[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("auth command failed: %w", err)
+	}
+	token := strings.TrimSpace(string(output))
+	if token == "" {
+		return "", fmt.Errorf("auth command returned empty token")
+	}
+	return token, nil
+}
+
+// tokenTTL 令牌缓存时间，max大于0时不超过max
+func tokenTTL(cfg *config.Config, max time.Duration) time.Duration {
+	ttl := time.Duration(cfg.Database.Auth.TokenTTL) * time.Second
+	if ttl <= 0 {
+		ttl = 10 * time.Minute
+	}
+	if max > 0 && ttl > max {
+		ttl = max
+	}
+	return ttl
+}
@@ -3,39 +3,35 @@ package database
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 
+	"exchange/internal/pkg/breaker"
 	"exchange/internal/pkg/config"
 	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/tracing"
 )
 
+// ErrKeyNotFound 键不存在，Get和GetJSON未命中时返回的错误链中包含该错误
+var ErrKeyNotFound = errors.New("key not found")
+
 // RedisService Redis缓存服务
 type RedisService struct {
-	client *redis.Client
+	client redis.UniversalClient
 	ctx    context.Context
 }
 
 // NewRedisService 创建Redis服务实例
 func NewRedisService(cfg *config.Config) (*RedisService, error) {
-	// Redis客户端配置
-	options := &redis.Options{
-		Addr:         cfg.GetRedisAddr(),
-		Password:     cfg.Redis.Password,
-		DB:           cfg.Redis.Database,
-		PoolSize:     cfg.Redis.PoolSize,
-		MinIdleConns: cfg.Redis.PoolSize / 2,
-		MaxRetries:   3,
-		DialTimeout:  5 * time.Second,
-		ReadTimeout:  3 * time.Second,
-		WriteTimeout: 3 * time.Second,
-		PoolTimeout:  4 * time.Second,
-	}
-
 	// 创建Redis客户端
-	client := redis.NewClient(options)
+	client := newRedisClient(cfg)
+	client.AddHook(tracing.NewRedisHook())                           // 每条命令一个追踪span（熔断拒绝的命令也记录在内）
+	client.AddHook(breaker.NewRedisHook(breaker.Get(breaker.Redis))) // Redis熔断期间命令直接返回错误
 	ctx := context.Background()
 
 	// 测试连接
@@ -44,7 +40,8 @@ func NewRedisService(cfg *config.Config) (*RedisService, error) {
 	}
 
 	appLogger.Info("Redis connected successfully", map[string]interface{}{
-		"addr":     cfg.GetRedisAddr(),
+		"mode":     cfg.Redis.Mode,
+		"addr":     redisEndpoint(cfg),
 		"database": cfg.Redis.Database,
 		"pool_size": cfg.Redis.PoolSize,
 	})
@@ -55,8 +52,8 @@ func NewRedisService(cfg *config.Config) (*RedisService, error) {
 	}, nil
 }
 
-// Client 获取Redis客户端
-func (s *RedisService) Client() *redis.Client {
+// Client 获取Redis客户端（单节点和哨兵模式为*redis.Client，集群为*redis.ClusterClient）
+func (s *RedisService) Client() redis.UniversalClient {
 	return s.client
 }
 
@@ -95,26 +92,54 @@ func (s *RedisService) GetStats() (map[string]interface{}, error) {
 
 // Set 设置键值对
 func (s *RedisService) Set(key string, value interface{}, expiration time.Duration) error {
-	var data []byte
-	var err error
+	data, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
 
+	if err := s.client.Set(s.ctx, key, data, expiration).Err(); err != nil {
+		return fmt.Errorf("failed to set key %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// MSet 批量设置键值对，通过管道发送（集群模式下按节点分批）
+func (s *RedisService) MSet(items map[string]interface{}, expiration time.Duration) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	_, err := s.client.Pipelined(s.ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range items {
+			data, err := encodeValue(value)
+			if err != nil {
+				return fmt.Errorf("failed to encode key %s: %w", key, err)
+			}
+			pipe.Set(s.ctx, key, data, expiration)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set keys: %w", err)
+	}
+	return nil
+}
+
+// encodeValue 字符串和[]byte原样保存，其他值序列化为JSON
+func encodeValue(value interface{}) ([]byte, error) {
 	switch v := value.(type) {
 	case string:
-		data = []byte(v)
+		return []byte(v), nil
 	case []byte:
-		data = v
+		return v, nil
 	default:
-		data, err = json.Marshal(value)
+		data, err := json.Marshal(value)
 		if err != nil {
-			return fmt.Errorf("failed to marshal value: %w", err)
+			return nil, fmt.Errorf("failed to marshal value: %w", err)
 		}
+		return data, nil
 	}
-
-	if err := s.client.Set(s.ctx, key, data, expiration).Err(); err != nil {
-		return fmt.Errorf("failed to set key %s: %w", key, err)
-	}
-
-	return nil
 }
 
 // Get 获取值
@@ -122,13 +147,44 @@ func (s *RedisService) Get(key string) (string, error) {
 	result, err := s.client.Get(s.ctx, key).Result()
 	if err != nil {
 		if err == redis.Nil {
-			return "", fmt.Errorf("key %s not found", key)
+			return "", fmt.Errorf("%w: %s", ErrKeyNotFound, key)
 		}
 		return "", fmt.Errorf("failed to get key %s: %w", key, err)
 	}
 	return result, nil
 }
 
+// MGet 批量获取值，通过管道发送（集群模式下MGET要求所有键在同一槽位），返回的map只包含存在的键
+func (s *RedisService) MGet(keys ...string) (map[string]string, error) {
+	values := make(map[string]string, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	cmds := make([]*redis.StringCmd, len(keys))
+	_, err := s.client.Pipelined(s.ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(s.ctx, key)
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get keys: %w", err)
+	}
+
+	for i, cmd := range cmds {
+		value, err := cmd.Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get key %s: %w", keys[i], err)
+		}
+		values[keys[i]] = value
+	}
+	return values, nil
+}
+
 // GetJSON 获取JSON值并反序列化
 func (s *RedisService) GetJSON(key string, dest interface{}) error {
 	data, err := s.Get(key)
@@ -149,6 +205,20 @@ func (s *RedisService) Delete(keys ...string) error {
 		return nil
 	}
 
+	// 集群模式下多键命令要求所有键在同一槽位，逐个删除（管道按节点批量发送）
+	if len(keys) > 1 && s.IsCluster() {
+		_, err := s.client.Pipelined(s.ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				pipe.Del(s.ctx, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete keys: %w", err)
+		}
+		return nil
+	}
+
 	if err := s.client.Del(s.ctx, keys...).Err(); err != nil {
 		return fmt.Errorf("failed to delete keys: %w", err)
 	}
@@ -156,6 +226,58 @@ func (s *RedisService) Delete(keys ...string) error {
 	return nil
 }
 
+// DeleteByPrefix 通过SCAN查找以prefix开头的键并删除，返回删除的键数
+// 不会阻塞Redis（不使用KEYS），集群模式下扫描所有主节点；扫描期间新写入的键可能不会被删除
+func (s *RedisService) DeleteByPrefix(prefix string) (int64, error) {
+	pattern := escapeGlob(prefix) + "*"
+	var deleted atomic.Int64
+
+	err := s.ForEachMaster(s.ctx, func(ctx context.Context, client *redis.Client) error {
+		var cursor uint64
+		for {
+			keys, next, err := client.Scan(ctx, cursor, pattern, 500).Result()
+			if err != nil {
+				return fmt.Errorf("failed to scan keys: %w", err)
+			}
+
+			if len(keys) > 0 {
+				// 同一节点的键可能在不同槽位，逐个删除
+				cmds, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+					for _, key := range keys {
+						pipe.Del(ctx, key)
+					}
+					return nil
+				})
+				if err != nil {
+					return fmt.Errorf("failed to delete keys: %w", err)
+				}
+				for _, cmd := range cmds {
+					deleted.Add(cmd.(*redis.IntCmd).Val())
+				}
+			}
+
+			cursor = next
+			if cursor == 0 {
+				return nil
+			}
+		}
+	})
+	return deleted.Load(), err
+}
+
+// escapeGlob 转义SCAN MATCH模式中的特殊字符
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
 // Exists 检查键是否存在
 func (s *RedisService) Exists(key string) (bool, error) {
 	result, err := s.client.Exists(s.ctx, key).Result()
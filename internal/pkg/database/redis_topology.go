@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"exchange/internal/pkg/config"
+)
+
+// newRedisClient 按部署模式创建Redis客户端
+// standalone直连单个节点；sentinel通过哨兵发现主节点，故障转移后自动切换到新的主节点；cluster按槽位将命令路由到各分片。
+// read_mode不为primary时只读命令发往从节点（latency选择延迟最低的节点，random随机选择），可能读到复制延迟内的旧数据
+func newRedisClient(appCfg *config.Config) redis.UniversalClient {
+	cfg := appCfg.Redis
+	const (
+		maxRetries   = 3
+		dialTimeout  = 5 * time.Second
+		readTimeout  = 3 * time.Second
+		writeTimeout = 3 * time.Second
+		poolTimeout  = 4 * time.Second
+	)
+
+	switch cfg.Mode {
+	case "sentinel":
+		options := &redis.FailoverOptions{
+			MasterName:       cfg.MasterName,
+			SentinelAddrs:    cfg.Addrs,
+			SentinelPassword: cfg.SentinelPassword,
+			Password:         cfg.Password,
+			DB:               cfg.Database,
+			PoolSize:         cfg.PoolSize,
+			MinIdleConns:     cfg.PoolSize / 2,
+			MaxRetries:       maxRetries,
+			DialTimeout:      dialTimeout,
+			ReadTimeout:      readTimeout,
+			WriteTimeout:     writeTimeout,
+			PoolTimeout:      poolTimeout,
+		}
+		if cfg.ReadMode == "primary" {
+			return redis.NewFailoverClient(options)
+		}
+		// 读写分离时主节点和从节点组成单槽位的"集群"，写命令仍发往主节点
+		options.RouteByLatency = cfg.ReadMode == "latency"
+		options.RouteRandomly = cfg.ReadMode == "random"
+		return redis.NewFailoverClusterClient(options)
+	case "cluster":
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:          cfg.Addrs,
+			Password:       cfg.Password,
+			ReadOnly:       cfg.ReadMode == "replica",
+			RouteByLatency: cfg.ReadMode == "latency",
+			RouteRandomly:  cfg.ReadMode == "random",
+			PoolSize:       cfg.PoolSize,
+			MinIdleConns:   cfg.PoolSize / 2,
+			MaxRetries:     maxRetries,
+			DialTimeout:    dialTimeout,
+			ReadTimeout:    readTimeout,
+			WriteTimeout:   writeTimeout,
+			PoolTimeout:    poolTimeout,
+		})
+	default:
+		return redis.NewClient(&redis.Options{
+			Addr:         appCfg.GetRedisAddr(),
+			Password:     cfg.Password,
+			DB:           cfg.Database,
+			PoolSize:     cfg.PoolSize,
+			MinIdleConns: cfg.PoolSize / 2,
+			MaxRetries:   maxRetries,
+			DialTimeout:  dialTimeout,
+			ReadTimeout:  readTimeout,
+			WriteTimeout: writeTimeout,
+			PoolTimeout:  poolTimeout,
+		})
+	}
+}
+
+// redisEndpoint 日志中记录的连接地址
+func redisEndpoint(cfg *config.Config) string {
+	switch cfg.Redis.Mode {
+	case "sentinel":
+		return cfg.Redis.MasterName + "@" + strings.Join(cfg.Redis.Addrs, ",")
+	case "cluster":
+		return strings.Join(cfg.Redis.Addrs, ",")
+	default:
+		return cfg.GetRedisAddr()
+	}
+}
+
+// HashTag 将id包装为Redis集群哈希标签
+// 集群只按键中第一个{}内的内容计算槽位，多键命令（DEL多个键、MULTI、Lua脚本）要求所有键在同一槽位，
+// 同一用户或同一组的键在相同位置使用HashTag(id)，如"redis:user:session:{42}"和"redis:notification:{42}"
+func HashTag(id string) string {
+	return "{" + id + "}"
+}
+
+// IsCluster 是否为集群客户端（cluster模式，或sentinel模式下的读写分离）
+// 集群客户端的多键命令要求所有键在同一槽位，SCAN等命令只作用于单个节点
+func (s *RedisService) IsCluster() bool {
+	_, ok := s.client.(*redis.ClusterClient)
+	return ok
+}
+
+// ForEachMaster 在每个主节点上执行fn，用于SCAN等只作用于单个节点的命令
+// 集群模式下各分片并发执行，fn需要自行同步；单节点和哨兵模式只有一个主节点
+func (s *RedisService) ForEachMaster(ctx context.Context, fn func(ctx context.Context, client *redis.Client) error) error {
+	switch client := s.client.(type) {
+	case *redis.ClusterClient:
+		return client.ForEachMaster(ctx, fn)
+	case *redis.Client:
+		return fn(ctx, client)
+	default:
+		return fmt.Errorf("unsupported redis client type %T", client)
+	}
+}
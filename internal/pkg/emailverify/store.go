@@ -0,0 +1,153 @@
+// Package emailverify 注册邮箱验证的一次性验证token
+// token只以SHA-256哈希保存在Redis中，有效期内只能使用一次，并记录签发时的邮箱（修改邮箱后旧token失效）；
+// 同一账户签发新token后旧token失效，并按账户限制统计窗口内签发的次数
+package emailverify
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/database"
+)
+
+const (
+	tokenKeyPrefix = "email_verify:token:" // token的哈希，值为"用户ID:邮箱"
+	userKeyPrefix  = "email_verify:user:"  // 用户当前有效token的哈希，签发新token时删除旧token
+	limitKeyPrefix = "email_verify:limit:" // 统计窗口内的签发次数
+
+	tokenBytes = 32
+)
+
+var (
+	// ErrInvalidToken 验证token不存在、已使用、已过期或邮箱已修改
+	ErrInvalidToken = errors.New("invalid email verification token")
+	// ErrTooManyRequests 账户在统计窗口内签发的验证token达到上限
+	ErrTooManyRequests = errors.New("too many email verification requests")
+	// ErrAlreadyVerified 邮箱已验证
+	ErrAlreadyVerified = errors.New("email already verified")
+	// ErrNotVerified 邮箱未验证，不能执行敏感操作
+	ErrNotVerified = errors.New("email not verified")
+)
+
+// issueScript 签发次数加1（第一次时设置统计窗口），超过上限时返回-1；否则删除用户的旧token，保存新token
+// KEYS: 次数键, 用户键, 新token键；ARGV: 上限, 窗口(毫秒), 有效期(毫秒), token值, 新token哈希, token键前缀
+var issueScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+if count > tonumber(ARGV[1]) then
+	return -1
+end
+local previous = redis.call('GET', KEYS[2])
+if previous then
+	redis.call('DEL', ARGV[6] .. previous)
+end
+redis.call('SET', KEYS[3], ARGV[4], 'PX', ARGV[3])
+redis.call('SET', KEYS[2], ARGV[5], 'PX', ARGV[3])
+return count
+`)
+
+// consumeScript 取出并删除token，同时删除用户当前token的记录（仍指向该token时）
+// KEYS: token键；ARGV: token哈希, 用户键前缀
+var consumeScript = redis.NewScript(`
+local value = redis.call('GET', KEYS[1])
+if not value then
+	return false
+end
+redis.call('DEL', KEYS[1])
+local userID = string.match(value, '^(%d+):')
+if userID then
+	local userKey = ARGV[2] .. userID
+	if redis.call('GET', userKey) == ARGV[1] then
+		redis.call('DEL', userKey)
+	end
+end
+return value
+`)
+
+// Store 验证token存储，保存在Redis中，多实例共享
+type Store struct {
+	redis  *database.RedisService
+	ttl    time.Duration
+	limit  int
+	window time.Duration
+}
+
+// NewStore 创建验证token存储
+func NewStore(redis *database.RedisService, cfg config.AccountConfig) *Store {
+	return &Store{
+		redis:  redis,
+		ttl:    time.Duration(cfg.EmailVerificationTTLHours) * time.Hour,
+		limit:  cfg.EmailVerificationLimit,
+		window: time.Duration(cfg.EmailVerificationWindowMinutes) * time.Minute,
+	}
+}
+
+// Issue 为用户的邮箱签发验证token，返回token和过期时间
+// 统计窗口内签发次数达到上限时返回ErrTooManyRequests
+func (s *Store) Issue(ctx context.Context, userID uint, email string) (string, time.Time, error) {
+	buf := make([]byte, tokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate verification token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+	hash := hashToken(token)
+	id := strconv.FormatUint(uint64(userID), 10)
+	value := id + ":" + strings.ToLower(email)
+
+	keys := []string{limitKeyPrefix + id, userKeyPrefix + id, tokenKeyPrefix + hash}
+	count, err := issueScript.Run(ctx, s.redis.Client(), keys,
+		s.limit, s.window.Milliseconds(), s.ttl.Milliseconds(), value, hash, tokenKeyPrefix).Int()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to save verification token: %w", err)
+	}
+	if count < 0 {
+		return "", time.Time{}, ErrTooManyRequests
+	}
+	return token, clock.Now().Add(s.ttl), nil
+}
+
+// Consume 使用验证token，返回用户ID和签发时的邮箱；token随即失效
+func (s *Store) Consume(ctx context.Context, token string) (uint, string, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return 0, "", ErrInvalidToken
+	}
+
+	hash := hashToken(token)
+	value, err := consumeScript.Run(ctx, s.redis.Client(), []string{tokenKeyPrefix + hash}, hash, userKeyPrefix).Text()
+	if errors.Is(err, redis.Nil) {
+		return 0, "", ErrInvalidToken
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to consume verification token: %w", err)
+	}
+
+	id, email, ok := strings.Cut(value, ":")
+	if !ok {
+		return 0, "", ErrInvalidToken
+	}
+	userID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || userID == 0 {
+		return 0, "", ErrInvalidToken
+	}
+	return uint(userID), email, nil
+}
+
+// hashToken token的哈希
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
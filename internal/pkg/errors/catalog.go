@@ -0,0 +1,266 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MinCatalogCode 错误目录文件中业务错误码的起始值，低于该值的区间保留给代码中定义的模块错误码
+const MinCatalogCode ErrorCode = 40000
+
+// Definition 错误定义
+// 内置错误码由各模块的错误转换器注册，业务错误码从错误目录文件（YAML或JSON）加载
+type Definition struct {
+	Code       ErrorCode `json:"code" yaml:"code"`
+	Module     string    `json:"module,omitempty" yaml:"module"`
+	Category   Category  `json:"category" yaml:"category"`
+	Severity   Severity  `json:"severity,omitempty" yaml:"severity"`       // 为空时按分类确定
+	MessageKey string    `json:"message_key" yaml:"message_key"`           // 响应消息i18n键
+	Message    string    `json:"message,omitempty" yaml:"message"`         // 默认错误描述（日志和错误详情使用）
+	HTTPStatus int       `json:"http_status,omitempty" yaml:"http_status"` // 响应HTTP状态码，为0时沿用统一的200响应
+	Retryable  bool      `json:"retryable,omitempty" yaml:"retryable"`     // 是否可重试（errors.Retry据此判断）
+}
+
+// catalogFile 错误目录文件格式
+type catalogFile struct {
+	Errors []Definition `json:"errors" yaml:"errors"`
+}
+
+// Catalog 错误目录，按错误码查找错误定义
+type Catalog struct {
+	mu          sync.RWMutex
+	definitions map[ErrorCode]Definition
+}
+
+// defaultCatalog 全局错误目录，启动时注册通用错误码
+var defaultCatalog = newDefaultCatalog()
+
+// NewCatalog 创建空的错误目录
+func NewCatalog() *Catalog {
+	return &Catalog{definitions: make(map[ErrorCode]Definition)}
+}
+
+// newDefaultCatalog 创建注册了通用错误码的错误目录
+func newDefaultCatalog() *Catalog {
+	catalog := NewCatalog()
+	catalog.registerCodes("", defaultCodes, defaultKeys)
+	for _, def := range dependencyDefinitions {
+		catalog.definitions[def.Code] = def
+	}
+	for _, def := range timeoutDefinitions {
+		catalog.definitions[def.Code] = def
+	}
+	for _, def := range maintenanceDefinitions {
+		catalog.definitions[def.Code] = def
+	}
+	for _, def := range requestDefinitions {
+		catalog.definitions[def.Code] = def
+	}
+	for _, def := range accountDefinitions {
+		catalog.definitions[def.Code] = def
+	}
+	return catalog
+}
+
+// DefaultCatalog 获取全局错误目录
+func DefaultCatalog() *Catalog {
+	return defaultCatalog
+}
+
+// categoryOrder 注册内置错误码时的分类顺序，多个分类共用一个错误码时取最先出现的分类
+var categoryOrder = []Category{
+	CategoryNotFound,
+	CategoryConflict,
+	CategoryInvalid,
+	CategoryDatabase,
+	CategoryUnavailable,
+	CategoryRateLimited,
+	CategoryInternal,
+}
+
+// registerCodes 注册模块的内置错误码，消息键未配置的分类使用通用消息键
+func (c *Catalog) registerCodes(module string, codes map[Category]ErrorCode, keys map[Category]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, category := range categoryOrder {
+		code, ok := codes[category]
+		if !ok {
+			continue
+		}
+		if _, exists := c.definitions[code]; exists {
+			continue
+		}
+		key, ok := keys[category]
+		if !ok {
+			key = defaultKeys[category]
+		}
+		c.definitions[code] = Definition{
+			Code:       code,
+			Module:     module,
+			Category:   category,
+			MessageKey: key,
+			HTTPStatus: defaultStatuses[category],
+		}
+	}
+}
+
+// Register 注册错误定义，错误码已存在时返回错误
+func (c *Catalog) Register(def Definition) error {
+	if err := def.validate(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.definitions[def.Code]; exists {
+		return fmt.Errorf("error code %d already registered", def.Code)
+	}
+	c.definitions[def.Code] = def
+	return nil
+}
+
+// Lookup 按错误码查找错误定义
+func (c *Catalog) Lookup(code ErrorCode) (Definition, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	def, ok := c.definitions[code]
+	return def, ok
+}
+
+// Definitions 按错误码排序的全部错误定义
+func (c *Catalog) Definitions() []Definition {
+	c.mu.RLock()
+	defs := make([]Definition, 0, len(c.definitions))
+	for _, def := range c.definitions {
+		defs = append(defs, def)
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Code < defs[j].Code
+	})
+	return defs
+}
+
+// LoadFile 从错误目录文件加载业务错误定义，按扩展名识别YAML（.yaml/.yml）或JSON
+// 文件中任一定义无效或与已注册的错误码重复时不注册任何定义，返回加载的定义数量
+func (c *Catalog) LoadFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read error catalog: %w", err)
+	}
+
+	var file catalogFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	default:
+		err = json.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse error catalog %s: %w", path, err)
+	}
+
+	seen := make(map[ErrorCode]bool, len(file.Errors))
+	for i, def := range file.Errors {
+		if err := def.validate(); err != nil {
+			return 0, fmt.Errorf("invalid error catalog entry #%d: %w", i+1, err)
+		}
+		if def.Code < MinCatalogCode {
+			return 0, fmt.Errorf("invalid error catalog entry #%d: code %d is below %d", i+1, def.Code, MinCatalogCode)
+		}
+		if seen[def.Code] {
+			return 0, fmt.Errorf("invalid error catalog entry #%d: duplicate code %d", i+1, def.Code)
+		}
+		seen[def.Code] = true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, def := range file.Errors {
+		if _, exists := c.definitions[def.Code]; exists {
+			return 0, fmt.Errorf("error code %d already registered", def.Code)
+		}
+	}
+	for _, def := range file.Errors {
+		c.definitions[def.Code] = def
+	}
+	return len(file.Errors), nil
+}
+
+// validate 检查错误定义的字段
+func (d Definition) validate() error {
+	if d.Code <= 0 {
+		return fmt.Errorf("code must be positive")
+	}
+	if _, ok := categorySeverity[d.Category]; !ok {
+		return fmt.Errorf("code %d: unknown category %q", d.Code, d.Category)
+	}
+	if _, ok := severityRank[d.Severity]; d.Severity != "" && !ok {
+		return fmt.Errorf("code %d: unknown severity %q", d.Code, d.Severity)
+	}
+	if d.MessageKey == "" {
+		return fmt.Errorf("code %d: message_key is required", d.Code)
+	}
+	if d.HTTPStatus != 0 && (d.HTTPStatus < 100 || d.HTTPStatus > 599) {
+		return fmt.Errorf("code %d: invalid http_status %d", d.Code, d.HTTPStatus)
+	}
+	return nil
+}
+
+// Register 在全局错误目录中注册错误定义
+func Register(def Definition) error {
+	return defaultCatalog.Register(def)
+}
+
+// Lookup 在全局错误目录中按错误码查找错误定义
+func Lookup(code ErrorCode) (Definition, bool) {
+	return defaultCatalog.Lookup(code)
+}
+
+// LoadCatalog 加载错误目录文件到全局错误目录
+func LoadCatalog(path string) (int, error) {
+	return defaultCatalog.LoadFile(path)
+}
+
+// FromCode 按错误目录中的定义创建业务错误，未注册的错误码按内部错误处理
+func FromCode(code ErrorCode) *AppError {
+	def, ok := defaultCatalog.Lookup(code)
+	if !ok {
+		return &AppError{
+			Code:       code,
+			Category:   CategoryInternal,
+			MessageKey: defaultKeys[CategoryInternal],
+			Message:    fmt.Sprintf("unregistered error code %d", code),
+		}
+	}
+
+	return &AppError{
+		Code:       def.Code,
+		Category:   def.Category,
+		Module:     def.Module,
+		MessageKey: def.MessageKey,
+		Message:    def.Message,
+		Severity:   def.Severity,
+		Retryable:  def.Retryable,
+	}
+}
+
+// ResponseStatus 错误响应使用的HTTP状态码，错误定义配置了http_status时使用该值，否则为200
+func ResponseStatus(err error) int {
+	if appErr, ok := GetAppError(err); ok {
+		if def, ok := defaultCatalog.Lookup(appErr.Code); ok && def.HTTPStatus != 0 {
+			return def.HTTPStatus
+		}
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,49 @@
+package errors
+
+import (
+	stderrors "errors"
+	"net/http"
+)
+
+// 依赖服务错误码（分类均为依赖服务不可用），熔断器打开时按导致熔断的失败类型返回
+const (
+	CodeTimeout          ErrorCode = 10007 // 依赖服务超时
+	CodeConnectionFailed ErrorCode = 10008 // 依赖服务连接失败
+)
+
+// ErrCircuitOpen 依赖服务熔断期间未调用依赖、直接拒绝的原因，位于CircuitOpenError的错误链中
+var ErrCircuitOpen = stderrors.New("circuit breaker is open")
+
+// 依赖服务错误（响应HTTP状态码503）
+var (
+	ErrTimeout = &AppError{
+		Code:       CodeTimeout,
+		Category:   CategoryUnavailable,
+		MessageKey: "service_unavailable",
+		Message:    "依赖服务超时",
+	}
+	ErrConnectionFailed = &AppError{
+		Code:       CodeConnectionFailed,
+		Category:   CategoryUnavailable,
+		MessageKey: "service_unavailable",
+		Message:    "依赖服务连接失败",
+	}
+)
+
+// dependencyDefinitions 依赖服务错误码的定义，与通用错误码一起注册
+var dependencyDefinitions = []Definition{
+	{Code: CodeTimeout, Category: CategoryUnavailable, Severity: SeverityMedium, MessageKey: "service_unavailable", Message: "依赖服务超时", HTTPStatus: http.StatusServiceUnavailable},
+	{Code: CodeConnectionFailed, Category: CategoryUnavailable, Severity: SeverityMedium, MessageKey: "service_unavailable", Message: "依赖服务连接失败", HTTPStatus: http.StatusServiceUnavailable},
+}
+
+// CircuitOpenError 依赖服务熔断期间直接拒绝的错误，timeout表示熔断由超时引起，retryAfter为距进入半开状态的秒数
+func CircuitOpenError(dependency string, timeout bool, retryAfter int) error {
+	base := ErrConnectionFailed
+	if timeout {
+		base = ErrTimeout
+	}
+	return base.Wrap(ErrCircuitOpen).
+		WithContext("dependency", dependency).
+		WithContext("circuit", "open").
+		WithContext("retry_after", retryAfter)
+}
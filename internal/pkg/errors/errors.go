@@ -0,0 +1,208 @@
+// Package errors 业务错误定义
+// 仓储层返回的数据库错误经各模块的错误转换器转换为带模块错误码、分类和上下文的AppError，
+// 处理器和中间件据此选择响应消息，不再依赖错误字符串
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrorCode 业务错误码，按模块划分区间：
+// 10000-19999 通用，20000-29999 用户，30000-39999 消息，40000起为错误目录文件定义的业务错误
+type ErrorCode int
+
+// 通用错误码
+const (
+	CodeInternal    ErrorCode = 10000 // 内部错误
+	CodeDatabase    ErrorCode = 10001 // 数据库错误
+	CodeNotFound    ErrorCode = 10002 // 记录不存在
+	CodeConflict    ErrorCode = 10003 // 记录冲突（唯一键重复）
+	CodeInvalid     ErrorCode = 10004 // 参数无效
+	CodeUnavailable ErrorCode = 10005 // 依赖服务不可用（连接失败、超时）
+	CodeRateLimited ErrorCode = 10006 // 请求超过限流上限
+)
+
+// Category 错误分类
+type Category string
+
+const (
+	CategoryInternal    Category = "internal"
+	CategoryDatabase    Category = "database"
+	CategoryNotFound    Category = "not_found"
+	CategoryConflict    Category = "conflict"
+	CategoryInvalid     Category = "invalid"
+	CategoryUnavailable Category = "unavailable"
+	CategoryRateLimited Category = "rate_limited"
+)
+
+// AppError 业务错误
+type AppError struct {
+	Code       ErrorCode              // 错误码
+	Category   Category               // 错误分类
+	Module     string                 // 所属模块
+	MessageKey string                 // 响应消息的i18n键
+	Message    string                 // 错误描述（日志和错误详情使用）
+	Context    map[string]interface{} // 错误上下文（如实体ID）
+	Cause      error                  // 原始错误
+	Severity   Severity               // 严重级别，为空时按分类确定
+	Retryable  bool                   // 是否可重试，依赖服务不可用的分类无需设置
+}
+
+// Error 错误描述，保留原始错误信息
+func (e *AppError) Error() string {
+	if e.Cause == nil {
+		return e.Message
+	}
+	if e.Message == "" {
+		return e.Cause.Error()
+	}
+	return e.Message + ": " + e.Cause.Error()
+}
+
+// Unwrap 返回原始错误
+func (e *AppError) Unwrap() error {
+	return e.Cause
+}
+
+// Is 按错误码比较，使errors.Is(err, ErrUserNotFound)能匹配WithContext、Wrap生成的副本
+// 目标错误码为0时按分类比较，如errors.Is(err, &AppError{Category: CategoryNotFound})
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	if !ok {
+		return false
+	}
+	if t.Code != 0 {
+		return e.Code == t.Code
+	}
+	return t.Category != "" && e.Category == t.Category
+}
+
+// New 创建业务错误
+func New(code ErrorCode, category Category, messageKey, message string) *AppError {
+	return &AppError{
+		Code:       code,
+		Category:   category,
+		MessageKey: messageKey,
+		Message:    message,
+	}
+}
+
+// WithContext 返回附加上下文后的副本
+func (e *AppError) WithContext(key string, value interface{}) *AppError {
+	clone := *e
+	clone.Context = make(map[string]interface{}, len(e.Context)+1)
+	for k, v := range e.Context {
+		clone.Context[k] = v
+	}
+	clone.Context[key] = value
+	return &clone
+}
+
+// Wrap 返回包装原始错误后的副本
+// 已有原始错误时两者都保留在错误链中，errors.Is/As可以匹配任意一个
+func (e *AppError) Wrap(cause error) *AppError {
+	clone := *e
+	if e.Cause != nil && cause != nil {
+		clone.Cause = fmt.Errorf("%w: %w", cause, e.Cause)
+	} else if cause != nil {
+		clone.Cause = cause
+	}
+	return &clone
+}
+
+// WrapError 为错误补充操作描述，保留完整错误链
+// 错误链中包含AppError时返回继承其错误码、分类、模块、消息键和上下文的AppError，调用方无需再查找错误链；
+// 否则等同于fmt.Errorf("%s: %w", message, err)
+func WrapError(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+
+	appErr, ok := GetAppError(err)
+	if !ok {
+		return fmt.Errorf("%s: %w", message, err)
+	}
+
+	wrapped := &AppError{
+		Code:       appErr.Code,
+		Category:   appErr.Category,
+		Module:     appErr.Module,
+		MessageKey: appErr.MessageKey,
+		Message:    message,
+		Cause:      err,
+		Severity:   appErr.Severity,
+		Retryable:  appErr.Retryable,
+	}
+	if len(appErr.Context) > 0 {
+		wrapped.Context = make(map[string]interface{}, len(appErr.Context))
+		for k, v := range appErr.Context {
+			wrapped.Context[k] = v
+		}
+	}
+	return wrapped
+}
+
+// IsAppError 检查错误链中是否包含AppError
+func IsAppError(err error) bool {
+	_, ok := GetAppError(err)
+	return ok
+}
+
+// GetAppError 获取错误链中的AppError
+func GetAppError(err error) (*AppError, bool) {
+	var appErr *AppError
+	if stderrors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// CodeOf 错误链中第一个AppError的错误码，nil返回0，非AppError返回CodeInternal
+func CodeOf(err error) ErrorCode {
+	if err == nil {
+		return 0
+	}
+	if appErr, ok := GetAppError(err); ok {
+		return appErr.Code
+	}
+	return CodeInternal
+}
+
+// CategoryOf 错误链中第一个AppError的分类，nil返回空，非AppError返回CategoryInternal
+func CategoryOf(err error) Category {
+	if err == nil {
+		return ""
+	}
+	if appErr, ok := GetAppError(err); ok {
+		return appErr.Category
+	}
+	return CategoryInternal
+}
+
+// GetHTTPStatus 错误对应的HTTP状态码，错误目录中配置了http_status时使用该值，非AppError按内部错误处理
+func GetHTTPStatus(err error) int {
+	appErr, ok := GetAppError(err)
+	if !ok {
+		return http.StatusInternalServerError
+	}
+	if def, ok := defaultCatalog.Lookup(appErr.Code); ok && def.HTTPStatus != 0 {
+		return def.HTTPStatus
+	}
+
+	switch appErr.Category {
+	case CategoryNotFound:
+		return http.StatusNotFound
+	case CategoryConflict:
+		return http.StatusConflict
+	case CategoryInvalid:
+		return http.StatusBadRequest
+	case CategoryUnavailable:
+		return http.StatusServiceUnavailable
+	case CategoryRateLimited:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
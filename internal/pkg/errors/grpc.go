@@ -0,0 +1,164 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"exchange/internal/pkg/requestid"
+)
+
+// grpcErrorDomain gRPC错误详情ErrorInfo的domain
+const grpcErrorDomain = "exchange"
+
+// GetGRPCCode 错误对应的gRPC状态码，与GetHTTPStatus的映射保持一致：
+// 错误目录中配置了http_status（4xx/5xx）时按HTTP状态码转换，否则按分类确定，非AppError按内部错误处理
+func GetGRPCCode(err error) codes.Code {
+	appErr, ok := GetAppError(err)
+	if !ok {
+		return codes.Internal
+	}
+	if def, ok := defaultCatalog.Lookup(appErr.Code); ok && def.HTTPStatus >= http.StatusBadRequest {
+		return httpStatusToGRPCCode(def.HTTPStatus)
+	}
+
+	switch appErr.Category {
+	case CategoryNotFound:
+		return codes.NotFound
+	case CategoryConflict:
+		return codes.AlreadyExists
+	case CategoryInvalid:
+		return codes.InvalidArgument
+	case CategoryUnavailable:
+		return codes.Unavailable
+	case CategoryRateLimited:
+		return codes.ResourceExhausted
+	default:
+		return codes.Internal
+	}
+}
+
+// httpStatusToGRPCCode 错误目录中配置的HTTP状态码转换为gRPC状态码
+func httpStatusToGRPCCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable, http.StatusBadGateway:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	if httpStatus < http.StatusInternalServerError {
+		return codes.FailedPrecondition
+	}
+	return codes.Internal
+}
+
+// GRPCStatus 转换为gRPC状态，grpc的status.FromError据此识别AppError
+// 状态消息为响应消息的i18n键（客户端按键翻译），不包含原始错误；详情中的ErrorInfo携带错误码、分类、模块、严重级别和是否可重试
+func (e *AppError) GRPCStatus() *status.Status {
+	code := GetGRPCCode(e)
+	messageKey := e.MessageKey
+	if messageKey == "" {
+		messageKey = defaultKeys[CategoryInternal]
+	}
+
+	st := status.New(code, messageKey)
+	metadata := map[string]string{
+		"code":        strconv.Itoa(int(e.Code)),
+		"category":    string(e.Category),
+		"severity":    string(SeverityOf(e)),
+		"message_key": messageKey,
+		"retryable":   strconv.FormatBool(IsRetryable(e)),
+	}
+	if e.Module != "" {
+		metadata["module"] = e.Module
+	}
+
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   strings.ToUpper(messageKey),
+		Domain:   grpcErrorDomain,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return st
+	}
+	return detailed
+}
+
+// ToGRPCError 转换为gRPC错误：AppError使用GRPCStatus，已是gRPC状态的错误原样返回，
+// 上下文取消和超时转换为对应状态码，其他错误按内部错误处理且不返回原始错误信息
+func ToGRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if appErr, ok := GetAppError(err); ok {
+		return appErr.GRPCStatus().Err()
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case stderrors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, context.Canceled.Error())
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, context.DeadlineExceeded.Error())
+	}
+	return status.Error(codes.Internal, defaultKeys[CategoryInternal])
+}
+
+// UnaryServerInterceptor gRPC一元调用拦截器，将处理函数返回的错误转换为gRPC状态并记录错误指标和上报
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			observeGRPC(ctx, info.FullMethod, err)
+			return resp, ToGRPCError(err)
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor gRPC流式调用拦截器，错误处理与UnaryServerInterceptor相同
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			observeGRPC(ss.Context(), info.FullMethod, err)
+			return ToGRPCError(err)
+		}
+		return nil
+	}
+}
+
+// observeGRPC 记录错误指标并上报，route为gRPC方法全名（如 /exchange.user.v1.UserService/GetUser）
+// 处理函数自行返回的gRPC状态不是AppError，不计入指标；上下文中有请求ID时随上报一起发送
+func observeGRPC(ctx context.Context, method string, err error) {
+	if _, ok := GetAppError(err); !ok {
+		if _, ok := status.FromError(err); ok {
+			return
+		}
+	}
+	Observe(method, err)
+	Report(err, &RequestInfo{Method: "gRPC", Route: method, RequestID: requestid.FromContext(ctx)})
+}
@@ -0,0 +1,28 @@
+package errors
+
+import "net/http"
+
+// CodeMaintenance 系统维护中（分类为依赖服务不可用），由维护模式中间件在维护期间返回
+const CodeMaintenance ErrorCode = 10011
+
+// ErrMaintenance 系统维护中，暂停处理请求
+var ErrMaintenance = &AppError{
+	Code:       CodeMaintenance,
+	Category:   CategoryUnavailable,
+	MessageKey: "maintenance_mode",
+	Message:    "系统维护中",
+}
+
+// MaintenanceError 维护期间的请求，retryAfter为建议的重试等待秒数（与Retry-After响应头一致），notice为管理员填写的说明
+func MaintenanceError(retryAfter int, notice string) error {
+	err := ErrMaintenance.WithContext("retry_after", retryAfter)
+	if notice != "" {
+		err = err.WithContext("notice", notice)
+	}
+	return err
+}
+
+// maintenanceDefinitions 维护模式错误码的定义，与通用错误码一起注册
+var maintenanceDefinitions = []Definition{
+	{Code: CodeMaintenance, Category: CategoryUnavailable, Severity: SeverityLow, MessageKey: "maintenance_mode", Message: "系统维护中", HTTPStatus: http.StatusServiceUnavailable},
+}
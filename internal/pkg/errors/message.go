@@ -0,0 +1,31 @@
+package errors
+
+// 消息模块错误码
+const (
+	CodeMessageNotFound    ErrorCode = 30001 // 消息不存在
+	CodeMessageConflict    ErrorCode = 30002 // 消息重复
+	CodeMessageInvalid     ErrorCode = 30003 // 消息数据无效
+	CodeMessageStorage     ErrorCode = 30004 // 消息读写失败
+	CodeMessageUnavailable ErrorCode = 30005 // 消息存储不可用
+)
+
+// MessageTranslator 消息模块错误转换器
+var MessageTranslator = NewTranslator(TranslatorConfig{
+	Module: "message",
+	Codes: map[Category]ErrorCode{
+		CategoryNotFound:    CodeMessageNotFound,
+		CategoryConflict:    CodeMessageConflict,
+		CategoryInvalid:     CodeMessageInvalid,
+		CategoryDatabase:    CodeMessageStorage,
+		CategoryInternal:    CodeMessageStorage,
+		CategoryUnavailable: CodeMessageUnavailable,
+	},
+	Keys: map[Category]string{
+		CategoryNotFound: "message_not_found",
+	},
+})
+
+// TranslateMessageError 转换消息仓储错误，附带消息ID
+func TranslateMessageError(err error, message string, messageID string) error {
+	return MessageTranslator.Translate(err, message, map[string]interface{}{"message_id": messageID})
+}
@@ -0,0 +1,40 @@
+package errors
+
+import (
+	"net/http"
+	"strconv"
+
+	"exchange/internal/pkg/metrics"
+)
+
+// errorsTotal 按路由、错误码、分类、严重级别和HTTP状态统计的错误数
+var errorsTotal = metrics.NewCounterVec(
+	"exchange_errors_total",
+	"Errors returned to clients by route, error code, category, severity and HTTP status.",
+	"route", "code", "category", "severity", "status",
+)
+
+// Observe 记录错误指标，route为路由模板（如 /api/v1/users/:id）
+func Observe(route string, err error) {
+	if err == nil {
+		return
+	}
+	errorsTotal.Inc(
+		route,
+		strconv.Itoa(int(CodeOf(err))),
+		string(CategoryOf(err)),
+		string(SeverityOf(err)),
+		strconv.Itoa(GetHTTPStatus(err)),
+	)
+}
+
+// ObservePanic 记录panic指标
+func ObservePanic(route string) {
+	errorsTotal.Inc(
+		route,
+		strconv.Itoa(int(CodeInternal)),
+		string(CategoryInternal),
+		string(SeverityCritical),
+		strconv.Itoa(http.StatusInternalServerError),
+	)
+}
@@ -0,0 +1,23 @@
+package errors
+
+// ErrRateLimited 请求超过限流上限（错误码10006，响应HTTP状态码429）
+var ErrRateLimited = &AppError{
+	Code:       CodeRateLimited,
+	Category:   CategoryRateLimited,
+	MessageKey: "too_many_requests",
+	Message:    "请求超过限流上限",
+}
+
+// RateLimitedError 请求超过限流策略的上限，retryAfter为建议的重试等待秒数（与Retry-After响应头一致）
+func RateLimitedError(policy string, limit, retryAfter int) error {
+	return ErrRateLimited.WithContext("policy", policy).
+		WithContext("limit", limit).
+		WithContext("retry_after", retryAfter)
+}
+
+// ConcurrencyLimitedError 同时处理的请求数达到上限（负载卸载），scope为global或路由，与限流使用相同的错误码
+func ConcurrencyLimitedError(scope string, limit, retryAfter int) error {
+	return ErrRateLimited.WithContext("concurrency_scope", scope).
+		WithContext("limit", limit).
+		WithContext("retry_after", retryAfter)
+}
@@ -0,0 +1,265 @@
+package errors
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"exchange/internal/pkg/config"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/workerpool"
+)
+
+// maxStackFrames 上报的最大调用栈深度
+const maxStackFrames = 64
+
+// RequestInfo 发生错误的请求信息
+type RequestInfo struct {
+	Method    string `json:"method"`
+	URL       string `json:"url"`
+	Route     string `json:"route,omitempty"` // 路由模板，如 /api/v1/users/:id
+	RequestID string `json:"request_id,omitempty"`
+	UserID    uint   `json:"user_id,omitempty"`
+	ClientIP  string `json:"client_ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+// StackFrame 调用栈帧
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// Event 上报的错误事件
+type Event struct {
+	ID          string                 `json:"event_id"`
+	Timestamp   time.Time              `json:"timestamp"`
+	Service     string                 `json:"service"`
+	Environment string                 `json:"environment"`
+	ServerName  string                 `json:"server_name"`
+	Type        string                 `json:"type"` // 错误类型：AppError、error或panic
+	Message     string                 `json:"message"`
+	Code        ErrorCode              `json:"code,omitempty"`
+	Category    Category               `json:"category,omitempty"`
+	Module      string                 `json:"module,omitempty"`
+	MessageKey  string                 `json:"message_key,omitempty"`
+	Severity    Severity               `json:"severity"`
+	Context     map[string]interface{} `json:"context,omitempty"`
+	Stack       []StackFrame           `json:"stack"` // 由外到内（最内层调用在最后）
+	Request     *RequestInfo           `json:"request,omitempty"`
+}
+
+// Reporter 错误上报目标（Sentry、通用错误收集接口）
+type Reporter interface {
+	Report(ctx context.Context, event *Event) error
+}
+
+// reporting 错误上报队列，未启用时为nil
+var reporting atomic.Pointer[reportQueue]
+
+// reportQueue 异步发送队列，由工作池按积压调整并发；队列满或上报接口持续失败（暂停接收）时丢弃事件，不阻塞请求
+type reportQueue struct {
+	reporter    Reporter
+	minSeverity Severity
+	service     string
+	environment string
+	serverName  string
+	timeout     time.Duration
+
+	pool    *workerpool.Pool
+	dropped atomic.Int64
+}
+
+// EnableReporting 按配置启用错误上报，未启用时不做任何操作
+func EnableReporting(cfg config.ErrorTrackingConfig, service string) error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	var reporter Reporter
+	switch cfg.Provider {
+	case "sentry":
+		sentry, err := NewSentryReporter(cfg.DSN, time.Duration(cfg.TimeoutMs)*time.Millisecond)
+		if err != nil {
+			return err
+		}
+		reporter = sentry
+	case "webhook":
+		reporter = NewWebhookReporter(cfg.URL, cfg.Headers, time.Duration(cfg.TimeoutMs)*time.Millisecond)
+	default:
+		return fmt.Errorf("unsupported error tracking provider: %s", cfg.Provider)
+	}
+
+	environment := cfg.Environment
+	if environment == "" {
+		environment = config.GetEnv()
+	}
+	hostname, _ := os.Hostname()
+
+	SetReporter(reporter, Severity(cfg.MinSeverity), cfg.QueueSize, cfg.Workers, &Event{
+		Service:     service,
+		Environment: environment,
+		ServerName:  hostname,
+	})
+	return nil
+}
+
+// SetReporter 设置错误上报目标，defaults提供服务名、环境和主机名；reporter为nil时停止上报
+// 发送失败只输出到标准日志，避免上报错误再次触发上报
+func SetReporter(reporter Reporter, minSeverity Severity, queueSize int, workers config.WorkerPoolConfig, defaults *Event) {
+	var queue *reportQueue
+	if reporter != nil {
+		queue = &reportQueue{
+			reporter:    reporter,
+			minSeverity: minSeverity,
+			service:     defaults.Service,
+			environment: defaults.Environment,
+			serverName:  defaults.ServerName,
+			timeout:     5 * time.Second,
+		}
+		queue.pool = workerpool.New("error_report", queueSize, workers, func(err error) {
+			log.Printf("[ERROR-TRACKING] %v", err)
+		})
+	}
+
+	if previous := reporting.Swap(queue); previous != nil {
+		previous.close(0)
+	}
+}
+
+// CloseReporting 停止接收新事件并等待队列发送完成，超时后返回
+func CloseReporting(timeout time.Duration) {
+	if queue := reporting.Swap(nil); queue != nil {
+		queue.close(timeout)
+	}
+}
+
+// Report 上报错误，严重级别低于配置时忽略；req为nil表示非请求上下文（如定时任务）
+func Report(err error, req *RequestInfo) {
+	queue := reporting.Load()
+	if queue == nil || err == nil {
+		return
+	}
+
+	severity := SeverityOf(err)
+	if !severity.AtLeast(queue.minSeverity) {
+		return
+	}
+
+	event := queue.newEvent(severity, req, callers(3))
+	event.Type = "error"
+	event.Message = err.Error()
+	if appErr, ok := GetAppError(err); ok {
+		event.Type = "AppError"
+		event.Code = appErr.Code
+		event.Category = appErr.Category
+		event.Module = appErr.Module
+		event.MessageKey = appErr.MessageKey
+		event.Context = appErr.Context
+	}
+	queue.enqueue(event)
+}
+
+// ReportPanic 上报recover得到的panic，需在defer的recover所在函数中调用，调用栈包含panic位置
+func ReportPanic(recovered interface{}, req *RequestInfo) {
+	queue := reporting.Load()
+	if queue == nil {
+		return
+	}
+
+	event := queue.newEvent(SeverityCritical, req, callers(3))
+	event.Type = "panic"
+	event.Message = fmt.Sprint(recovered)
+	if err, ok := recovered.(error); ok {
+		if appErr, ok := GetAppError(err); ok {
+			event.Code = appErr.Code
+			event.Category = appErr.Category
+			event.Module = appErr.Module
+			event.Context = appErr.Context
+		}
+	}
+	queue.enqueue(event)
+}
+
+// newEvent 创建事件并填充公共字段
+func (q *reportQueue) newEvent(severity Severity, req *RequestInfo, stack []StackFrame) *Event {
+	return &Event{
+		ID:          newEventID(),
+		Timestamp:   time.Now().UTC(),
+		Service:     q.service,
+		Environment: q.environment,
+		ServerName:  q.serverName,
+		Severity:    severity,
+		Stack:       stack,
+		Request:     req,
+	}
+}
+
+// enqueue 加入发送队列，队列满、暂停接收或已关闭时丢弃
+// 错误上下文按日志的脱敏配置处理后再发送，Sentry和通用错误收集接口都不会收到敏感字段
+func (q *reportQueue) enqueue(event *Event) {
+	event.Context = appLogger.RedactContext(event.Context)
+	err := q.pool.Submit(func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, q.timeout)
+		defer cancel()
+		if err := q.reporter.Report(ctx, event); err != nil {
+			return fmt.Errorf("failed to report event %s: %w", event.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		if q.dropped.Add(1)%100 == 1 {
+			log.Printf("[ERROR-TRACKING] %v, dropped %d events", err, q.dropped.Load())
+		}
+	}
+}
+
+// close 关闭队列并等待发送完成
+func (q *reportQueue) close(timeout time.Duration) {
+	q.pool.Close(timeout)
+}
+
+// callers 获取调用栈，跳过skip层（含runtime.Callers本身）和运行时内部帧
+func callers(skip int) []StackFrame {
+	pcs := make([]uintptr, maxStackFrames)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []StackFrame
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			stack = append(stack, StackFrame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+			})
+		}
+		if !more {
+			break
+		}
+	}
+
+	// 反转为由外到内，与Sentry的帧顺序一致
+	for i, j := 0, len(stack)-1; i < j; i, j = i+1, j-1 {
+		stack[i], stack[j] = stack[j], stack[i]
+	}
+	return stack
+}
+
+// newEventID 生成32位十六进制事件ID
+func newEventID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%032x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,24 @@
+package errors
+
+import "net/http"
+
+// CodeFileTooLarge 请求体超过大小上限（分类为参数无效），由请求体大小限制中间件返回
+const CodeFileTooLarge ErrorCode = 10012
+
+// ErrFileTooLarge 请求体超过大小上限
+var ErrFileTooLarge = &AppError{
+	Code:       CodeFileTooLarge,
+	Category:   CategoryInvalid,
+	MessageKey: "file_too_large",
+	Message:    "请求体超过大小上限",
+}
+
+// FileTooLargeError 请求体超过路由的大小上限，limit为上限(字节)
+func FileTooLargeError(limit int64) error {
+	return ErrFileTooLarge.WithContext("max_bytes", limit)
+}
+
+// requestDefinitions 请求体错误码的定义，与通用错误码一起注册
+var requestDefinitions = []Definition{
+	{Code: CodeFileTooLarge, Category: CategoryInvalid, Severity: SeverityLow, MessageKey: "file_too_large", Message: "请求体超过大小上限", HTTPStatus: http.StatusRequestEntityTooLarge},
+}
@@ -0,0 +1,123 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net"
+	"time"
+)
+
+// RetryPolicy 重试策略，未设置的字段使用DefaultRetryPolicy中的值（Jitter为0表示不浮动）
+type RetryPolicy struct {
+	MaxAttempts  int              // 最大尝试次数（含首次）
+	InitialDelay time.Duration    // 首次重试前的等待时间
+	MaxDelay     time.Duration    // 单次等待时间上限
+	Multiplier   float64          // 每次重试后等待时间的倍数
+	Jitter       float64          // 等待时间的随机浮动比例(0-1)，避免多个调用方同时重试
+	RetryIf      func(error) bool // 是否重试，为空时使用IsRetryable
+}
+
+// DefaultRetryPolicy 默认重试策略：最多3次，等待100ms、200ms（±20%），单次不超过5秒
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:  3,
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     5 * time.Second,
+		Multiplier:   2,
+		Jitter:       0.2,
+	}
+}
+
+// withDefaults 补全未设置的字段
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	defaults := DefaultRetryPolicy()
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaults.MaxAttempts
+	}
+	if p.InitialDelay <= 0 {
+		p.InitialDelay = defaults.InitialDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = defaults.MaxDelay
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = defaults.Multiplier
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		p.Jitter = defaults.Jitter
+	}
+	if p.RetryIf == nil {
+		p.RetryIf = IsRetryable
+	}
+	return p
+}
+
+// delay 第attempt次失败后的等待时间
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	delay := float64(p.InitialDelay) * math.Pow(p.Multiplier, float64(attempt-1))
+	if delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		delay *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(delay)
+}
+
+// IsRetryable 错误是否可以重试
+// AppError设置了Retryable或分类为依赖服务不可用（连接失败、超时、锁等待、死锁）时可重试；
+// 其他错误中网络错误（Redis、外部接口）可重试，数据库驱动错误按分类判断；上下文取消不重试
+func IsRetryable(err error) bool {
+	if err == nil || stderrors.Is(err, context.Canceled) {
+		return false
+	}
+	if appErr, ok := GetAppError(err); ok {
+		return appErr.Retryable || appErr.Category == CategoryUnavailable
+	}
+	var netErr net.Error
+	if stderrors.As(err, &netErr) {
+		return true
+	}
+	return ClassifyDBError(err) == CategoryUnavailable
+}
+
+// WithRetryable 返回标记为可重试后的副本
+func (e *AppError) WithRetryable() *AppError {
+	clone := *e
+	clone.Retryable = true
+	return &clone
+}
+
+// Retry 执行fn，返回可重试的错误时按指数退避加随机抖动重试
+// 达到最大尝试次数或错误不可重试时返回最后一次的错误；等待期间ctx结束时返回的错误同时包含ctx.Err()和最后一次的错误
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	_, err := RetryValue(ctx, policy, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// RetryValue 同Retry，用于有返回值的调用（如仓储查询）
+func RetryValue[T any](ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) (T, error)) (T, error) {
+	policy = policy.withDefaults()
+
+	var result T
+	var err error
+	for attempt := 1; ; attempt++ {
+		result, err = fn(ctx)
+		if err == nil || attempt >= policy.MaxAttempts || !policy.RetryIf(err) {
+			return result, err
+		}
+
+		timer := time.NewTimer(policy.delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
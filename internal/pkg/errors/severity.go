@@ -0,0 +1,64 @@
+package errors
+
+// Severity 错误严重级别，决定是否上报到错误跟踪服务
+type Severity string
+
+const (
+	SeverityLow      Severity = "low"      // 客户端错误（不存在、冲突、参数无效）
+	SeverityMedium   Severity = "medium"   // 可自动恢复的错误
+	SeverityHigh     Severity = "high"     // 数据库、依赖服务和内部错误
+	SeverityCritical Severity = "critical" // panic等需要立即处理的错误
+)
+
+// severityRank 严重级别排序
+var severityRank = map[Severity]int{
+	SeverityLow:      1,
+	SeverityMedium:   2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+// AtLeast 是否不低于指定级别
+func (s Severity) AtLeast(min Severity) bool {
+	return severityRank[s] >= severityRank[min]
+}
+
+// categorySeverity 分类对应的默认严重级别
+var categorySeverity = map[Category]Severity{
+	CategoryNotFound:    SeverityLow,
+	CategoryConflict:    SeverityLow,
+	CategoryInvalid:     SeverityLow,
+	CategoryUnavailable: SeverityHigh,
+	CategoryRateLimited: SeverityLow,
+	CategoryDatabase:    SeverityHigh,
+	CategoryInternal:    SeverityHigh,
+}
+
+// GetSeverity 错误的严重级别，未设置时依次使用错误目录中的定义和分类
+func (e *AppError) GetSeverity() Severity {
+	if e.Severity != "" {
+		return e.Severity
+	}
+	if def, ok := defaultCatalog.Lookup(e.Code); ok && def.Severity != "" {
+		return def.Severity
+	}
+	if severity, ok := categorySeverity[e.Category]; ok {
+		return severity
+	}
+	return SeverityHigh
+}
+
+// WithSeverity 返回指定严重级别后的副本
+func (e *AppError) WithSeverity(severity Severity) *AppError {
+	clone := *e
+	clone.Severity = severity
+	return &clone
+}
+
+// SeverityOf 错误链中第一个AppError的严重级别，非AppError按内部错误处理
+func SeverityOf(err error) Severity {
+	if appErr, ok := GetAppError(err); ok {
+		return appErr.GetSeverity()
+	}
+	return SeverityHigh
+}
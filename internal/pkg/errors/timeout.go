@@ -0,0 +1,31 @@
+package errors
+
+import "net/http"
+
+// 请求处理超时错误码（分类为依赖服务不可用），由请求超时中间件在处理器超过处理时间时返回
+const (
+	CodeRequestTimeout     ErrorCode = 10009 // 请求处理超时
+	CodeRequestBodyTimeout ErrorCode = 10010 // 读取请求体超时（客户端发送过慢）
+)
+
+// 请求处理超时错误
+var (
+	ErrRequestTimeout = &AppError{
+		Code:       CodeRequestTimeout,
+		Category:   CategoryUnavailable,
+		MessageKey: "request_timeout",
+		Message:    "请求处理超时",
+	}
+	ErrRequestBodyTimeout = &AppError{
+		Code:       CodeRequestBodyTimeout,
+		Category:   CategoryUnavailable,
+		MessageKey: "request_timeout",
+		Message:    "读取请求体超时",
+	}
+)
+
+// timeoutDefinitions 请求处理超时错误码的定义，与通用错误码一起注册
+var timeoutDefinitions = []Definition{
+	{Code: CodeRequestTimeout, Category: CategoryUnavailable, Severity: SeverityMedium, MessageKey: "request_timeout", Message: "请求处理超时", HTTPStatus: http.StatusGatewayTimeout},
+	{Code: CodeRequestBodyTimeout, Category: CategoryUnavailable, Severity: SeverityLow, MessageKey: "request_timeout", Message: "读取请求体超时", HTTPStatus: http.StatusRequestTimeout},
+}
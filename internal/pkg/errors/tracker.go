@@ -0,0 +1,196 @@
+package errors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// sentryClient 上报时使用的客户端标识
+const sentryClient = "exchange-reporter/1.0"
+
+// SentryReporter 通过Sentry的store接口上报事件（不依赖Sentry SDK）
+type SentryReporter struct {
+	endpoint  string
+	publicKey string
+	client    *http.Client
+}
+
+// NewSentryReporter 根据DSN创建Sentry上报，DSN格式：https://<public_key>@<host>[/<path>]/<project_id>
+func NewSentryReporter(dsn string, timeout time.Duration) (*SentryReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sentry dsn: %w", err)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("invalid sentry dsn: missing public key")
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	idx := strings.LastIndex(path, "/")
+	if idx < 0 || path[idx+1:] == "" {
+		return nil, fmt.Errorf("invalid sentry dsn: missing project id")
+	}
+	prefix, projectID := path[:idx], path[idx+1:]
+
+	return &SentryReporter{
+		endpoint:  fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, prefix, projectID),
+		publicKey: u.User.Username(),
+		client:    &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Report 发送事件
+func (r *SentryReporter) Report(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(sentryPayload(event))
+	if err != nil {
+		return err
+	}
+
+	return Retry(ctx, reportRetryPolicy, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", sentryClient, r.publicKey))
+		return doReport(r.client, req, "sentry")
+	})
+}
+
+// sentryPayload 转换为Sentry事件格式
+func sentryPayload(event *Event) map[string]interface{} {
+	level := "error"
+	if event.Severity == SeverityCritical {
+		level = "fatal"
+	}
+
+	frames := make([]map[string]interface{}, 0, len(event.Stack))
+	for _, frame := range event.Stack {
+		frames = append(frames, map[string]interface{}{
+			"function": frame.Function,
+			"abs_path": frame.File,
+			"lineno":   frame.Line,
+			"in_app":   strings.HasPrefix(frame.Function, "exchange/"),
+		})
+	}
+
+	exceptionType := event.Type
+	if event.Code != 0 {
+		exceptionType = fmt.Sprintf("%s(%d)", event.Type, event.Code)
+	}
+
+	tags := map[string]string{
+		"service":  event.Service,
+		"severity": string(event.Severity),
+	}
+	if event.Code != 0 {
+		tags["error_code"] = fmt.Sprint(event.Code)
+	}
+	if event.Category != "" {
+		tags["category"] = string(event.Category)
+	}
+	if event.Module != "" {
+		tags["module"] = event.Module
+	}
+
+	payload := map[string]interface{}{
+		"event_id":    event.ID,
+		"timestamp":   event.Timestamp.Format(time.RFC3339Nano),
+		"level":       level,
+		"platform":    "go",
+		"logger":      event.Module,
+		"server_name": event.ServerName,
+		"environment": event.Environment,
+		"exception": map[string]interface{}{
+			"values": []map[string]interface{}{{
+				"type":       exceptionType,
+				"value":      event.Message,
+				"stacktrace": map[string]interface{}{"frames": frames},
+			}},
+		},
+		"tags": tags,
+	}
+	if len(event.Context) > 0 {
+		payload["extra"] = event.Context
+	}
+
+	if req := event.Request; req != nil {
+		payload["request"] = map[string]interface{}{
+			"method":  req.Method,
+			"url":     req.URL,
+			"headers": map[string]string{"User-Agent": req.UserAgent},
+			"env":     map[string]string{"REMOTE_ADDR": req.ClientIP},
+		}
+		if req.RequestID != "" {
+			tags["request_id"] = req.RequestID
+		}
+		if req.Route != "" {
+			payload["transaction"] = req.Method + " " + req.Route
+		}
+		if req.UserID != 0 {
+			payload["user"] = map[string]interface{}{"id": fmt.Sprint(req.UserID), "ip_address": req.ClientIP}
+		}
+	}
+	return payload
+}
+
+// WebhookReporter 以JSON POST发送事件到通用错误收集接口
+type WebhookReporter struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhookReporter 创建通用错误收集上报
+func NewWebhookReporter(url string, headers map[string]string, timeout time.Duration) *WebhookReporter {
+	return &WebhookReporter{
+		url:     url,
+		headers: headers,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Report 发送事件
+func (r *WebhookReporter) Report(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return Retry(ctx, reportRetryPolicy, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for key, value := range r.headers {
+			req.Header.Set(key, value)
+		}
+		return doReport(r.client, req, "webhook")
+	})
+}
+
+// reportRetryPolicy 上报请求的重试策略，网络错误、429和5xx响应重试
+var reportRetryPolicy = RetryPolicy{MaxAttempts: 3, InitialDelay: 500 * time.Millisecond}
+
+// doReport 发送请求并检查响应状态
+func doReport(client *http.Client, req *http.Request, name string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return New(CodeUnavailable, CategoryUnavailable, "", fmt.Sprintf("%s returned status %d", name, resp.StatusCode))
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d", name, resp.StatusCode)
+	}
+	return nil
+}
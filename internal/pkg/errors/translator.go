@@ -0,0 +1,158 @@
+package errors
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/go-sql-driver/mysql"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
+)
+
+// MySQL错误号
+const (
+	mysqlErrDupEntry       = 1062 // 唯一键重复
+	mysqlErrNoReferenced   = 1452 // 外键不存在
+	mysqlErrLockWait       = 1205 // 锁等待超时
+	mysqlErrLockDeadlock   = 1213 // 死锁
+	mysqlErrDataTooLong    = 1406 // 数据过长
+	mysqlErrBadNullValue   = 1048 // 非空字段为空
+	mysqlErrTruncatedWrong = 1292 // 数据格式错误
+)
+
+// ClassifyDBError 根据数据库驱动错误判断错误分类
+func ClassifyDBError(err error) Category {
+	if err == nil {
+		return ""
+	}
+
+	if appErr, ok := GetAppError(err); ok {
+		return appErr.Category
+	}
+
+	if stderrors.Is(err, gorm.ErrRecordNotFound) || stderrors.Is(err, mongo.ErrNoDocuments) || stderrors.Is(err, sql.ErrNoRows) {
+		return CategoryNotFound
+	}
+	if stderrors.Is(err, gorm.ErrDuplicatedKey) || mongo.IsDuplicateKeyError(err) {
+		return CategoryConflict
+	}
+	if stderrors.Is(err, context.DeadlineExceeded) || stderrors.Is(err, context.Canceled) ||
+		stderrors.Is(err, driver.ErrBadConn) || mongo.IsTimeout(err) || mongo.IsNetworkError(err) {
+		return CategoryUnavailable
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if stderrors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case mysqlErrDupEntry:
+			return CategoryConflict
+		case mysqlErrNoReferenced, mysqlErrDataTooLong, mysqlErrBadNullValue, mysqlErrTruncatedWrong:
+			return CategoryInvalid
+		case mysqlErrLockWait, mysqlErrLockDeadlock:
+			return CategoryUnavailable
+		}
+		return CategoryDatabase
+	}
+
+	var mongoErr mongo.ServerError
+	if stderrors.As(err, &mongoErr) {
+		return CategoryDatabase
+	}
+
+	return CategoryInternal
+}
+
+// TranslatorConfig 模块错误转换配置，未配置的分类使用通用错误码和消息
+type TranslatorConfig struct {
+	Module string
+	Codes  map[Category]ErrorCode // 分类 -> 模块错误码
+	Keys   map[Category]string    // 分类 -> 响应消息i18n键
+}
+
+// Translator 模块错误转换器，将仓储层/数据库错误转换为带模块错误码的AppError
+type Translator struct {
+	cfg TranslatorConfig
+}
+
+// 通用错误码和消息
+var (
+	defaultCodes = map[Category]ErrorCode{
+		CategoryInternal:    CodeInternal,
+		CategoryDatabase:    CodeDatabase,
+		CategoryNotFound:    CodeNotFound,
+		CategoryConflict:    CodeConflict,
+		CategoryInvalid:     CodeInvalid,
+		CategoryUnavailable: CodeUnavailable,
+		CategoryRateLimited: CodeRateLimited,
+	}
+	defaultKeys = map[Category]string{
+		CategoryInternal:    "internal_server_error",
+		CategoryDatabase:    "database_error",
+		CategoryNotFound:    "record_not_found",
+		CategoryConflict:    "record_conflict",
+		CategoryInvalid:     "invalid_request_data",
+		CategoryUnavailable: "service_unavailable",
+		CategoryRateLimited: "too_many_requests",
+	}
+	// defaultStatuses 内置错误码的响应HTTP状态码，未列出的分类与其他错误响应一样返回200
+	defaultStatuses = map[Category]int{
+		CategoryRateLimited: http.StatusTooManyRequests,
+	}
+)
+
+// NewTranslator 创建模块错误转换器，模块错误码同时注册到全局错误目录
+func NewTranslator(cfg TranslatorConfig) *Translator {
+	defaultCatalog.registerCodes(cfg.Module, cfg.Codes, cfg.Keys)
+	return &Translator{cfg: cfg}
+}
+
+// Translate 转换错误，message为操作描述（如"查询用户失败"），context为实体ID等上下文
+// 已经转换过的错误不重复转换，只补充缺少的上下文；被fmt.Errorf("%w")包装过的AppError同样保留错误码
+func (t *Translator) Translate(err error, message string, context map[string]interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	if appErr, ok := GetAppError(err); ok {
+		if appErr != err {
+			// AppError位于错误链内部时保留外层描述，返回的AppError包装整个错误链
+			appErr = WrapError(err, message).(*AppError)
+		}
+		for key, value := range context {
+			if _, exists := appErr.Context[key]; !exists {
+				appErr = appErr.WithContext(key, value)
+			}
+		}
+		return appErr
+	}
+
+	category := ClassifyDBError(err)
+	return &AppError{
+		Code:       t.code(category),
+		Category:   category,
+		Module:     t.cfg.Module,
+		MessageKey: t.key(category),
+		Message:    message,
+		Context:    context,
+		Cause:      err,
+	}
+}
+
+// code 分类对应的错误码
+func (t *Translator) code(category Category) ErrorCode {
+	if code, ok := t.cfg.Codes[category]; ok {
+		return code
+	}
+	return defaultCodes[category]
+}
+
+// key 分类对应的i18n键
+func (t *Translator) key(category Category) string {
+	if key, ok := t.cfg.Keys[category]; ok {
+		return key
+	}
+	return defaultKeys[category]
+}
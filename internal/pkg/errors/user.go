@@ -0,0 +1,148 @@
+package errors
+
+import "net/http"
+
+// 用户模块错误码
+const (
+	CodeUserNotFound    ErrorCode = 20001 // 用户不存在
+	CodeUserConflict    ErrorCode = 20002 // 用户名或邮箱已存在
+	CodeUserInvalid     ErrorCode = 20003 // 用户数据无效
+	CodeUserStorage     ErrorCode = 20004 // 用户数据读写失败
+	CodeUserUnavailable ErrorCode = 20005 // 用户存储不可用
+	CodeAccountLocked   ErrorCode = 20006 // 登录失败次数过多，暂时禁止登录
+
+	CodeAccountSuspended      ErrorCode = 20007 // 账户被管理员锁定
+	CodePasswordResetRequired ErrorCode = 20008 // 管理员要求重置密码
+
+	CodeDeletionStateUnavailable ErrorCode = 20009 // 无法确认未完成订单和余额，暂不能注销
+	CodeDeletionOpenOrders       ErrorCode = 20010 // 存在未完成的订单，暂不能注销
+	CodeDeletionBalanceRemaining ErrorCode = 20011 // 账户仍有余额，暂不能注销
+)
+
+// UserTranslator 用户模块错误转换器
+var UserTranslator = NewTranslator(TranslatorConfig{
+	Module: "user",
+	Codes: map[Category]ErrorCode{
+		CategoryNotFound:    CodeUserNotFound,
+		CategoryConflict:    CodeUserConflict,
+		CategoryInvalid:     CodeUserInvalid,
+		CategoryDatabase:    CodeUserStorage,
+		CategoryInternal:    CodeUserStorage,
+		CategoryUnavailable: CodeUserUnavailable,
+	},
+	Keys: map[Category]string{
+		CategoryNotFound: "user_not_found",
+		CategoryConflict: "user_already_exists",
+	},
+})
+
+// TranslateUserError 转换用户仓储错误，附带用户ID
+func TranslateUserError(err error, message string, userID uint) error {
+	return UserTranslator.Translate(err, message, map[string]interface{}{"user_id": userID})
+}
+
+// ErrUserNotFound 用户不存在（仓储返回空结果时使用）
+var ErrUserNotFound = &AppError{
+	Code:       CodeUserNotFound,
+	Category:   CategoryNotFound,
+	Module:     "user",
+	MessageKey: "user_not_found",
+	Message:    "用户不存在",
+}
+
+// UserConflictError 用户名或邮箱已被使用，field为冲突字段
+func UserConflictError(message, field, value string) error {
+	return &AppError{
+		Code:       CodeUserConflict,
+		Category:   CategoryConflict,
+		Module:     "user",
+		MessageKey: "user_already_exists",
+		Message:    message,
+		Context:    map[string]interface{}{field: value},
+	}
+}
+
+// InvalidUserError 用户数据验证失败
+func InvalidUserError(err error, userID uint) error {
+	return &AppError{
+		Code:       CodeUserInvalid,
+		Category:   CategoryInvalid,
+		Module:     "user",
+		MessageKey: "validation_failed",
+		Message:    "用户数据验证失败",
+		Context:    map[string]interface{}{"user_id": userID},
+		Cause:      err,
+	}
+}
+
+// ErrAccountLocked 登录失败次数过多，暂时禁止登录（响应HTTP状态码429）
+var ErrAccountLocked = &AppError{
+	Code:       CodeAccountLocked,
+	Category:   CategoryRateLimited,
+	Module:     "user",
+	MessageKey: "account_locked",
+	Message:    "登录失败次数过多，请稍后再试",
+}
+
+// AccountLockedError 登录失败次数过多，retryAfter为可以再次登录的等待秒数
+func AccountLockedError(retryAfter int) error {
+	return ErrAccountLocked.WithContext("retry_after", retryAfter)
+}
+
+// ErrAccountSuspended 账户被管理员锁定，锁定期间不能登录（响应HTTP状态码403）
+var ErrAccountSuspended = &AppError{
+	Code:       CodeAccountSuspended,
+	Category:   CategoryInvalid,
+	Module:     "user",
+	MessageKey: "account_suspended",
+	Message:    "账户已被锁定",
+}
+
+// AccountSuspendedError 账户被管理员锁定，until为锁定截止时间（RFC3339）
+func AccountSuspendedError(until string) error {
+	return ErrAccountSuspended.WithContext("locked_until", until)
+}
+
+// ErrPasswordResetRequired 管理员要求重置密码，通过找回密码设置新密码前不能登录（响应HTTP状态码403）
+var ErrPasswordResetRequired = &AppError{
+	Code:       CodePasswordResetRequired,
+	Category:   CategoryInvalid,
+	Module:     "user",
+	MessageKey: "password_reset_required",
+	Message:    "需要重置密码",
+}
+
+// 账户注销前置校验未通过（响应HTTP状态码409）
+var (
+	ErrDeletionStateUnavailable = &AppError{
+		Code:       CodeDeletionStateUnavailable,
+		Category:   CategoryConflict,
+		Module:     "user",
+		MessageKey: "account_deletion_state_unavailable",
+		Message:    "无法校验未完成订单和账户余额，暂不能注销",
+	}
+	ErrDeletionOpenOrders = &AppError{
+		Code:       CodeDeletionOpenOrders,
+		Category:   CategoryConflict,
+		Module:     "user",
+		MessageKey: "account_deletion_open_orders",
+		Message:    "存在未完成的订单，暂不能注销",
+	}
+	ErrDeletionBalanceRemaining = &AppError{
+		Code:       CodeDeletionBalanceRemaining,
+		Category:   CategoryConflict,
+		Module:     "user",
+		MessageKey: "account_deletion_balance_remaining",
+		Message:    "账户仍有余额，暂不能注销",
+	}
+)
+
+// accountDefinitions 登录限制和注销校验错误码的定义，与通用错误码一起注册
+var accountDefinitions = []Definition{
+	{Code: CodeAccountLocked, Module: "user", Category: CategoryRateLimited, Severity: SeverityLow, MessageKey: "account_locked", Message: "登录失败次数过多，请稍后再试", HTTPStatus: http.StatusTooManyRequests},
+	{Code: CodeAccountSuspended, Module: "user", Category: CategoryInvalid, Severity: SeverityLow, MessageKey: "account_suspended", Message: "账户已被锁定", HTTPStatus: http.StatusForbidden},
+	{Code: CodePasswordResetRequired, Module: "user", Category: CategoryInvalid, Severity: SeverityLow, MessageKey: "password_reset_required", Message: "需要重置密码", HTTPStatus: http.StatusForbidden},
+	{Code: CodeDeletionStateUnavailable, Module: "user", Category: CategoryConflict, Severity: SeverityLow, MessageKey: "account_deletion_state_unavailable", Message: "无法校验未完成订单和账户余额，暂不能注销", HTTPStatus: http.StatusConflict},
+	{Code: CodeDeletionOpenOrders, Module: "user", Category: CategoryConflict, Severity: SeverityLow, MessageKey: "account_deletion_open_orders", Message: "存在未完成的订单，暂不能注销", HTTPStatus: http.StatusConflict},
+	{Code: CodeDeletionBalanceRemaining, Module: "user", Category: CategoryConflict, Severity: SeverityLow, MessageKey: "account_deletion_balance_remaining", Message: "账户仍有余额，暂不能注销", HTTPStatus: http.StatusConflict},
+}
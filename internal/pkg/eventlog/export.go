@@ -0,0 +1,354 @@
+package eventlog
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"time"
+
+	mongoModel "exchange/internal/models/mongodb"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/export"
+)
+
+// Format 导出文件格式：gzip压缩的NDJSON，每行一条Record
+const Format = "ndjson.gz"
+
+// DateLayout 分区日期格式（UTC）
+const DateLayout = "2006-01-02"
+
+// 导出错误
+var (
+	ErrManifestNotFound  = errors.New("event export manifest not found")
+	ErrFileNotInManifest = errors.New("file is not listed in event export manifest")
+)
+
+// SchemaField 导出记录的字段定义
+type SchemaField struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // string、int64、timestamp(RFC3339纳秒，UTC)、json(任意JSON对象)
+}
+
+// Schema 当前结构版本的字段定义，随manifest发布，数据仓库按schema_version选择加载方式
+var Schema = []SchemaField{
+	{Name: "schema_version", Type: "int64"},
+	{Name: "event_id", Type: "string"},
+	{Name: "type", Type: "string"},
+	{Name: "user_id", Type: "int64"},
+	{Name: "language", Type: "string"},
+	{Name: "data", Type: "json"},
+	{Name: "occurred_at", Type: "timestamp"},
+	{Name: "recorded_at", Type: "timestamp"},
+}
+
+// Record 导出记录，重放或重新导出时同一事件的event_id不变，数据仓库按event_id去重
+type Record struct {
+	SchemaVersion int                    `json:"schema_version"`
+	EventID       string                 `json:"event_id"`
+	Type          string                 `json:"type"`
+	UserID        uint                   `json:"user_id"`
+	Language      string                 `json:"language"`
+	Data          map[string]interface{} `json:"data"`
+	OccurredAt    time.Time              `json:"occurred_at"`
+	RecordedAt    time.Time              `json:"recorded_at"`
+}
+
+// ManifestFile manifest中的导出文件
+type ManifestFile struct {
+	Name    string `json:"name"`
+	Records int64  `json:"records"`
+	Bytes   int64  `json:"bytes"`
+	SHA256  string `json:"sha256"` // 压缩后文件内容的SHA-256
+}
+
+// Manifest 一个日期分区的导出清单
+// manifest在所有文件写入完成后最后写入，存在即表示该分区导出完成；文件按事件发生时间和event_id排序
+type Manifest struct {
+	Date          string         `json:"date"` // 分区日期（UTC）
+	SchemaVersion int            `json:"schema_version"`
+	Schema        []SchemaField  `json:"schema"`
+	Format        string         `json:"format"`
+	From          time.Time      `json:"from"` // 分区时间范围[from, to)
+	To            time.Time      `json:"to"`
+	Records       int64          `json:"records"`
+	Files         []ManifestFile `json:"files"`
+	GeneratedAt   time.Time      `json:"generated_at"`
+}
+
+// ParseDate 解析分区日期
+func ParseDate(value string) (time.Time, error) {
+	date, err := time.ParseInLocation(DateLayout, value, time.UTC)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid partition date %q: %w", value, err)
+	}
+	return date, nil
+}
+
+// manifestName 分区manifest文件名
+func manifestName(date time.Time) string {
+	return fmt.Sprintf("events_%s_manifest.json", date.Format("20060102"))
+}
+
+// partName 分区数据文件名，包含结构版本和导出批次，重新导出时不覆盖旧manifest引用的文件
+func partName(date time.Time, run int64, part int) string {
+	return fmt.Sprintf("events_%s_v%d_%d_%04d.%s", date.Format("20060102"), SchemaVersion, run, part, Format)
+}
+
+// Catalog 已导出分区的查询
+type Catalog struct {
+	storage export.Storage
+}
+
+// NewCatalog 创建导出分区查询
+func NewCatalog(storage export.Storage) *Catalog {
+	return &Catalog{storage: storage}
+}
+
+// Manifest 获取分区manifest，分区未导出时返回ErrManifestNotFound
+func (c *Catalog) Manifest(date time.Time) (*Manifest, error) {
+	file, err := c.storage.Open(manifestName(date))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrManifestNotFound
+		}
+		return nil, fmt.Errorf("failed to open event export manifest: %w", err)
+	}
+	defer file.Close()
+
+	var manifest Manifest
+	if err := json.NewDecoder(file).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode event export manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// Manifests 获取[from, to]内已导出分区的manifest，按日期升序，未导出的日期跳过
+func (c *Catalog) Manifests(from, to time.Time) ([]*Manifest, error) {
+	manifests := make([]*Manifest, 0)
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		manifest, err := c.Manifest(date)
+		if errors.Is(err, ErrManifestNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, manifest)
+	}
+	return manifests, nil
+}
+
+// Open 打开分区中的数据文件，只允许打开manifest中列出的文件
+func (c *Catalog) Open(date time.Time, name string) (export.File, error) {
+	manifest, err := c.Manifest(date)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range manifest.Files {
+		if file.Name == name {
+			f, err := c.storage.Open(name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open event export file: %w", err)
+			}
+			return f, nil
+		}
+	}
+	return nil, ErrFileNotInManifest
+}
+
+// Exporter 按日期分区导出领域事件
+type Exporter struct {
+	*Catalog
+	store          *Store
+	storage        export.Storage
+	fileMaxRecords int64
+}
+
+// NewExporter 创建事件导出器，fileMaxRecords为单个文件的最大记录数
+func NewExporter(store *Store, storage export.Storage, fileMaxRecords int) *Exporter {
+	return &Exporter{
+		Catalog:        NewCatalog(storage),
+		store:          store,
+		storage:        storage,
+		fileMaxRecords: int64(fileMaxRecords),
+	}
+}
+
+// Export 导出date（UTC）当天发生的事件并写入manifest，已导出的分区重新导出后替换
+// 没有事件的日期也写入manifest（files为空），数据仓库据此判断分区已完成
+func (e *Exporter) Export(ctx context.Context, date time.Time) (*Manifest, error) {
+	from := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	previous, err := e.Manifest(from)
+	if err != nil && !errors.Is(err, ErrManifestNotFound) {
+		return nil, err
+	}
+
+	now := clock.Now().UTC()
+	manifest := &Manifest{
+		Date:          from.Format(DateLayout),
+		SchemaVersion: SchemaVersion,
+		Schema:        Schema,
+		Format:        Format,
+		From:          from,
+		To:            from.AddDate(0, 0, 1),
+		Files:         make([]ManifestFile, 0),
+		GeneratedAt:   now,
+	}
+
+	if err := e.writeParts(ctx, manifest, now.UnixNano()); err != nil {
+		e.deleteFiles(manifest.Files)
+		return nil, err
+	}
+	if err := e.writeManifest(manifest); err != nil {
+		e.deleteFiles(manifest.Files)
+		return nil, err
+	}
+
+	// 新manifest发布后删除旧批次的文件
+	if previous != nil {
+		e.deleteFiles(previous.Files)
+	}
+	return manifest, nil
+}
+
+// writeParts 按记录数切分写入数据文件，写完的文件追加到manifest.Files
+func (e *Exporter) writeParts(ctx context.Context, manifest *Manifest, run int64) error {
+	var current *partWriter
+	err := e.store.Scan(ctx, manifest.From, manifest.To, func(event *mongoModel.DomainEvent) error {
+		if current != nil && current.records >= e.fileMaxRecords {
+			file, err := current.close()
+			current = nil
+			if err != nil {
+				return err
+			}
+			manifest.Files = append(manifest.Files, file)
+		}
+		if current == nil {
+			w, err := newPartWriter(e.storage, partName(manifest.From, run, len(manifest.Files)))
+			if err != nil {
+				return err
+			}
+			current = w
+		}
+		manifest.Records++
+		return current.write(event)
+	})
+	if err != nil {
+		if current != nil {
+			current.abort()
+		}
+		return err
+	}
+
+	if current != nil {
+		file, err := current.close()
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+	return nil
+}
+
+// writeManifest 写入manifest
+func (e *Exporter) writeManifest(manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode event export manifest: %w", err)
+	}
+
+	w, err := e.storage.Create(manifestName(manifest.From))
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Abort()
+		return fmt.Errorf("failed to write event export manifest: %w", err)
+	}
+	return w.Close()
+}
+
+// deleteFiles 删除导出文件，失败时只影响存储空间
+func (e *Exporter) deleteFiles(files []ManifestFile) {
+	for _, file := range files {
+		e.storage.Delete(file.Name)
+	}
+}
+
+// partWriter 写入中的数据文件，记录压缩后的字节数和SHA-256
+type partWriter struct {
+	name    string
+	w       export.Writer
+	hash    hash.Hash
+	bytes   int64
+	gz      *gzip.Writer
+	enc     *json.Encoder
+	records int64
+}
+
+// newPartWriter 创建数据文件
+func newPartWriter(storage export.Storage, name string) (*partWriter, error) {
+	w, err := storage.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	p := &partWriter{name: name, w: w, hash: sha256.New()}
+	p.gz = gzip.NewWriter(io.MultiWriter(p, p.hash))
+	p.enc = json.NewEncoder(p.gz)
+	return p, nil
+}
+
+// Write 写入底层文件并计数
+func (p *partWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.bytes += int64(n)
+	return n, err
+}
+
+// write 写入一条记录
+func (p *partWriter) write(event *mongoModel.DomainEvent) error {
+	record := Record{
+		SchemaVersion: event.SchemaVersion,
+		EventID:       event.EventID,
+		Type:          event.Type,
+		UserID:        event.UserID,
+		Language:      event.Language,
+		Data:          event.Data,
+		OccurredAt:    event.OccurredAt.UTC(),
+		RecordedAt:    event.RecordedAt.UTC(),
+	}
+	if err := p.enc.Encode(&record); err != nil {
+		return fmt.Errorf("failed to write event export record: %w", err)
+	}
+	p.records++
+	return nil
+}
+
+// close 完成压缩并发布文件
+func (p *partWriter) close() (ManifestFile, error) {
+	if err := p.gz.Close(); err != nil {
+		p.w.Abort()
+		return ManifestFile{}, fmt.Errorf("failed to flush event export file: %w", err)
+	}
+	if err := p.w.Close(); err != nil {
+		return ManifestFile{}, err
+	}
+	return ManifestFile{
+		Name:    p.name,
+		Records: p.records,
+		Bytes:   p.bytes,
+		SHA256:  hex.EncodeToString(p.hash.Sum(nil)),
+	}, nil
+}
+
+// abort 丢弃写入中的文件
+func (p *partWriter) abort() {
+	p.w.Abort()
+}
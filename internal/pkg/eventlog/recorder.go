@@ -0,0 +1,39 @@
+package eventlog
+
+import (
+	"context"
+	"time"
+
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/database"
+	"exchange/internal/pkg/events"
+)
+
+// writeTimeout 单个事件的写入超时时间
+const writeTimeout = 5 * time.Second
+
+// Enable 按配置创建事件存储并订阅事件总线上的所有事件，未启用时不订阅
+func Enable(cfg config.EventLogConfig, mongo *database.MongoDBService, bus *events.Bus) error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	store := NewStore(mongo)
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	if err := store.EnsureIndexes(ctx); err != nil {
+		return err
+	}
+
+	bus.Subscribe(events.AllEvents, Recorder(store))
+	return nil
+}
+
+// Recorder 将事件写入存储的事件处理函数，写入失败由事件总线记录日志
+func Recorder(store *Store) events.Handler {
+	return func(ctx context.Context, event events.Event) error {
+		ctx, cancel := context.WithTimeout(ctx, writeTimeout)
+		defer cancel()
+		return store.Append(ctx, event)
+	}
+}
@@ -0,0 +1,100 @@
+// Package eventlog 领域事件日志
+// 事件总线上的事件持久化到MongoDB，按UTC日期分区导出为带manifest的NDJSON文件，供数据仓库离线加载而不直接访问生产库
+package eventlog
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongoModel "exchange/internal/models/mongodb"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/database"
+	"exchange/internal/pkg/events"
+)
+
+// SchemaVersion 当前事件记录的结构版本，导出记录和文件名中携带，字段不兼容变更时递增
+const SchemaVersion = 1
+
+// scanBatchSize 按时间范围读取事件时每批读取的数量
+const scanBatchSize = 1000
+
+// Store 领域事件存储
+type Store struct {
+	mongo *database.MongoDBService
+}
+
+// NewStore 创建领域事件存储
+func NewStore(mongo *database.MongoDBService) *Store {
+	return &Store{mongo: mongo}
+}
+
+// collection 领域事件集合
+func (s *Store) collection() *mongo.Collection {
+	return s.mongo.Collection(mongoModel.DomainEvent{}.CollectionName())
+}
+
+// EnsureIndexes 创建事件ID唯一索引和发生时间索引
+func (s *Store) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "event_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "occurred_at", Value: 1}, {Key: "event_id", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create domain event indexes: %w", err)
+	}
+	return nil
+}
+
+// Append 写入事件，同一事件ID重复写入时忽略
+func (s *Store) Append(ctx context.Context, event events.Event) error {
+	record := &mongoModel.DomainEvent{
+		EventID:       event.ID,
+		Type:          event.Type,
+		UserID:        event.UserID,
+		Language:      event.Language,
+		Data:          event.Data,
+		OccurredAt:    event.OccurredAt.UTC(),
+		RecordedAt:    clock.Now().UTC(),
+		SchemaVersion: SchemaVersion,
+	}
+	if _, err := s.collection().InsertOne(ctx, record); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to insert domain event %s: %w", event.ID, err)
+	}
+	return nil
+}
+
+// Scan 按发生时间和事件ID顺序遍历[from, to)内的事件，fn返回错误时停止
+func (s *Store) Scan(ctx context.Context, from, to time.Time, fn func(*mongoModel.DomainEvent) error) error {
+	filter := bson.M{"occurred_at": bson.M{"$gte": from, "$lt": to}}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "occurred_at", Value: 1}, {Key: "event_id", Value: 1}}).
+		SetBatchSize(scanBatchSize)
+
+	cursor, err := s.collection().Find(ctx, filter, opts)
+	if err != nil {
+		return fmt.Errorf("failed to query domain events: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var record mongoModel.DomainEvent
+		if err := cursor.Decode(&record); err != nil {
+			return fmt.Errorf("failed to decode domain event: %w", err)
+		}
+		if err := fn(&record); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to iterate domain events: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"exchange/internal/pkg/clock"
+	appLogger "exchange/internal/pkg/logger"
+)
+
+// 事件类型
+const (
+	UserRegistered = "user.registered" // 用户注册
+	FirstDeposit   = "deposit.first"   // 用户首次充值
+	KYCApproved    = "kyc.approved"    // 用户KYC审核通过
+
+	// AllEvents 订阅所有类型的事件（如事件日志持久化）
+	AllEvents = "*"
+)
+
+// Event 业务事件
+type Event struct {
+	ID         string                 `json:"id"` // 事件ID，发布时生成，下游按此去重
+	Type       string                 `json:"type"`
+	UserID     uint                   `json:"user_id"`
+	Language   string                 `json:"language,omitempty"` // 触发事件时用户使用的语言
+	Data       map[string]interface{} `json:"data,omitempty"`
+	OccurredAt time.Time              `json:"occurred_at"`
+}
+
+// Handler 事件处理函数
+type Handler func(ctx context.Context, event Event) error
+
+// Bus 进程内事件总线
+// 发布方不感知订阅方，处理函数在独立协程中执行，不阻塞发布方，处理失败只记录日志
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	wg       sync.WaitGroup
+}
+
+var (
+	defaultBus *Bus
+	once       sync.Once
+)
+
+// DefaultBus 获取全局事件总线
+func DefaultBus() *Bus {
+	once.Do(func() {
+		defaultBus = NewBus()
+	})
+	return defaultBus
+}
+
+// NewBus 创建事件总线
+func NewBus() *Bus {
+	return &Bus{
+		handlers: make(map[string][]Handler),
+	}
+}
+
+// Subscribe 订阅事件，eventType为AllEvents时订阅所有类型
+func (b *Bus) Subscribe(eventType string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Publish 发布事件，请求结束后处理函数仍会继续执行
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = clock.Now()
+	}
+	if event.ID == "" {
+		event.ID = newEventID(event.OccurredAt)
+	}
+
+	b.mu.RLock()
+	handlers := append(append([]Handler(nil), b.handlers[event.Type]...), b.handlers[AllEvents]...)
+	b.mu.RUnlock()
+
+	ctx = context.WithoutCancel(ctx)
+	for _, handler := range handlers {
+		b.wg.Add(1)
+		go b.run(ctx, handler, event)
+	}
+}
+
+// newEventID 生成事件ID：发生时间(毫秒)加随机数，按字典序大致与发生时间一致
+func newEventID(occurredAt time.Time) string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%013d%016x", occurredAt.UnixMilli(), time.Now().UnixNano())
+	}
+	return fmt.Sprintf("%013d%s", occurredAt.UnixMilli(), hex.EncodeToString(buf))
+}
+
+// Wait 等待正在执行的处理函数结束（优雅关闭时调用）
+func (b *Bus) Wait() {
+	b.wg.Wait()
+}
+
+// run 执行处理函数
+func (b *Bus) run(ctx context.Context, handler Handler, event Event) {
+	defer b.wg.Done()
+	defer func() {
+		if r := recover(); r != nil {
+			appLogger.Error("事件处理异常", map[string]interface{}{
+				"event":   event.Type,
+				"user_id": event.UserID,
+				"panic":   r,
+			})
+		}
+	}()
+
+	if err := handler(ctx, event); err != nil {
+		appLogger.Error("事件处理失败", map[string]interface{}{
+			"event":    event.Type,
+			"event_id": event.ID,
+			"user_id":  event.UserID,
+			"error":    err.Error(),
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"time"
+)
+
+// 导出格式
+const (
+	FormatJSON = "json"
+	FormatHTML = "html"
+)
+
+// IsValidFormat 是否为支持的导出格式
+func IsValidFormat(format string) bool {
+	return format == FormatJSON || format == FormatHTML
+}
+
+// ContentType 导出格式对应的Content-Type
+func ContentType(format string) string {
+	if format == FormatHTML {
+		return "text/html; charset=utf-8"
+	}
+	return "application/json; charset=utf-8"
+}
+
+// RedactedContent 未获对方授权的消息内容替换为此文本
+const RedactedContent = "[redacted]"
+
+// Participant 会话参与者
+type Participant struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Consent  bool   `json:"consent"` // 是否同意其消息内容被对方导出
+}
+
+// ConversationHeader 导出文件头
+type ConversationHeader struct {
+	ExportedAt   time.Time     `json:"exported_at"`
+	RequestedBy  uint          `json:"requested_by"`
+	Participants []Participant `json:"participants"`
+	From         *time.Time    `json:"from,omitempty"`
+	To           *time.Time    `json:"to,omitempty"`
+}
+
+// Message 导出的消息
+type Message struct {
+	ID         string                 `json:"id"`
+	FromUserID uint                   `json:"from_user_id"`
+	ToUserID   uint                   `json:"to_user_id"`
+	Type       string                 `json:"type"`
+	Content    string                 `json:"content"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	Redacted   bool                   `json:"redacted,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// ConversationWriter 流式写入会话导出文件，消息逐条写入，不在内存中保留全部消息
+type ConversationWriter interface {
+	Begin(header *ConversationHeader) error
+	Write(message *Message) error
+	End() error
+}
+
+// NewConversationWriter 按格式创建写入器
+func NewConversationWriter(format string, w io.Writer) (ConversationWriter, error) {
+	switch format {
+	case FormatJSON:
+		return &jsonConversationWriter{w: w}, nil
+	case FormatHTML:
+		return &htmlConversationWriter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// jsonConversationWriter JSON格式：{"header": {...}, "messages": [...]}
+type jsonConversationWriter struct {
+	w     io.Writer
+	count int
+}
+
+// Begin 写入文件头
+func (j *jsonConversationWriter) Begin(header *ConversationHeader) error {
+	data, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(j.w, "{\"header\":%s,\"messages\":[", data)
+	return err
+}
+
+// Write 写入一条消息
+func (j *jsonConversationWriter) Write(message *Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	if j.count > 0 {
+		if _, err := io.WriteString(j.w, ",\n"); err != nil {
+			return err
+		}
+	}
+	j.count++
+	_, err = j.w.Write(data)
+	return err
+}
+
+// End 结束文件
+func (j *jsonConversationWriter) End() error {
+	_, err := io.WriteString(j.w, "]}\n")
+	return err
+}
+
+// htmlConversationWriter 可直接在浏览器打开的HTML格式，所有用户内容均经过转义
+type htmlConversationWriter struct {
+	w     io.Writer
+	names map[uint]string
+}
+
+// Begin 写入文件头
+func (h *htmlConversationWriter) Begin(header *ConversationHeader) error {
+	h.names = make(map[uint]string, len(header.Participants))
+	for _, p := range header.Participants {
+		h.names[p.UserID] = p.Username
+	}
+
+	_, err := fmt.Fprintf(h.w, `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Conversation export</title>
+<style>body{font-family:sans-serif;max-width:800px;margin:2em auto}.m{margin:.5em 0;padding:.5em;border-radius:4px;background:#f3f3f3}.r{color:#888;font-style:italic}.t{color:#888;font-size:.85em}</style>
+</head><body>
+<h1>Conversation export</h1>
+<p class="t">Exported at %s</p>
+`, html.EscapeString(header.ExportedAt.UTC().Format(time.RFC3339)))
+	return err
+}
+
+// Write 写入一条消息
+func (h *htmlConversationWriter) Write(message *Message) error {
+	class := "m"
+	if message.Redacted {
+		class = "m r"
+	}
+	_, err := fmt.Fprintf(h.w, "<div class=\"%s\"><div class=\"t\">%s · %s</div><div>%s</div></div>\n",
+		class,
+		html.EscapeString(h.name(message.FromUserID)),
+		html.EscapeString(message.CreatedAt.UTC().Format(time.RFC3339)),
+		html.EscapeString(message.Content))
+	return err
+}
+
+// End 结束文件
+func (h *htmlConversationWriter) End() error {
+	_, err := io.WriteString(h.w, "</body></html>\n")
+	return err
+}
+
+// name 参与者显示名
+func (h *htmlConversationWriter) name(userID uint) string {
+	if name, ok := h.names[userID]; ok && name != "" {
+		return name
+	}
+	return fmt.Sprintf("user %d", userID)
+}
@@ -0,0 +1,88 @@
+package export
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"exchange/internal/pkg/secrets"
+	"exchange/internal/pkg/signing"
+)
+
+// LinkKeyName 下载链接签名密钥在密钥提供者中的名称
+// 值可包含多个版本（逗号或换行分隔），第一个用于签名，其余在轮换过渡期内仍可验证
+const LinkKeyName = "export_link_key"
+
+// 下载链接验证错误
+var (
+	ErrLinkExpired   = errors.New("download link expired")
+	ErrLinkSignature = errors.New("invalid download link signature")
+)
+
+// LinkSigner 下载链接签名器
+// 签名覆盖任务ID和过期时间，链接泄露后最多在有效期内可用
+type LinkSigner struct {
+	provider secrets.Provider
+}
+
+// NewLinkSigner 创建下载链接签名器
+func NewLinkSigner(provider secrets.Provider) *LinkSigner {
+	return &LinkSigner{provider: provider}
+}
+
+// keys 读取签名密钥（第一个为当前版本）
+func (s *LinkSigner) keys(ctx context.Context) ([]string, error) {
+	value, err := s.provider.Get(ctx, LinkKeyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export link key: %w", err)
+	}
+	keys := secrets.Versions(value)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("export link key is empty")
+	}
+	return keys, nil
+}
+
+// stringToSign 待签名字符串
+func stringToSign(jobID uint, expires int64) string {
+	return strings.Join([]string{"export", strconv.FormatUint(uint64(jobID), 10), strconv.FormatInt(expires, 10)}, "\n")
+}
+
+// Sign 生成下载链接，expiresAt之后链接失效
+func (s *LinkSigner) Sign(ctx context.Context, baseURL string, jobID uint, expiresAt time.Time) (string, error) {
+	keys, err := s.keys(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	expires := expiresAt.Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", signing.Compute(keys[0], stringToSign(jobID, expires)))
+	return fmt.Sprintf("%s/api/v1/exports/%d/download?%s", strings.TrimRight(baseURL, "/"), jobID, query.Encode()), nil
+}
+
+// Verify 验证下载链接的签名和有效期
+func (s *LinkSigner) Verify(ctx context.Context, jobID uint, expires int64, signature string, now time.Time) error {
+	if now.Unix() > expires {
+		return ErrLinkExpired
+	}
+
+	keys, err := s.keys(ctx)
+	if err != nil {
+		return err
+	}
+
+	expected := stringToSign(jobID, expires)
+	for _, key := range keys {
+		if hmac.Equal([]byte(signing.Compute(key, expected)), []byte(strings.ToLower(signature))) {
+			return nil
+		}
+	}
+	return ErrLinkSignature
+}
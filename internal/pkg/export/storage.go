@@ -0,0 +1,122 @@
+// Package export 用户数据导出
+// 导出任务在后台生成文件并保存到存储，用户通过带签名的限时链接下载，无需登录态
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// 文件名只能包含字母、数字、下划线、点和连字符，避免路径穿越
+var fileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,128}$`)
+
+// File 已保存的导出文件
+type File interface {
+	io.ReadSeekCloser
+	Stat() (os.FileInfo, error)
+}
+
+// Storage 导出文件存储
+type Storage interface {
+	// Create 创建文件，Close后文件才对Open可见；写入失败时调用Abort丢弃
+	Create(name string) (Writer, error)
+
+	// Open 打开文件
+	Open(name string) (File, error)
+
+	// Delete 删除文件，文件不存在时不返回错误
+	Delete(name string) error
+}
+
+// Writer 写入中的导出文件
+type Writer interface {
+	io.WriteCloser
+	Abort() error
+}
+
+// LocalStorage 本地目录存储（多实例部署时目录需为共享存储）
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage 创建本地目录存储
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create export dir: %w", err)
+	}
+	return &LocalStorage{dir: dir}, nil
+}
+
+// path 文件路径
+func (s *LocalStorage) path(name string) (string, error) {
+	if !fileNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid export file name: %s", name)
+	}
+	return filepath.Join(s.dir, name), nil
+}
+
+// Create 创建文件，先写入临时文件，Close时重命名
+func (s *LocalStorage) Create(name string) (Writer, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, name+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export file: %w", err)
+	}
+	return &localWriter{File: tmp, path: path}, nil
+}
+
+// Open 打开文件
+func (s *LocalStorage) Open(name string) (File, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Delete 删除文件
+func (s *LocalStorage) Delete(name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete export file: %w", err)
+	}
+	return nil
+}
+
+// localWriter 写入临时文件，Close时重命名为目标文件
+type localWriter struct {
+	*os.File
+	path string
+}
+
+// Close 关闭并发布文件
+func (w *localWriter) Close() error {
+	if err := w.File.Close(); err != nil {
+		os.Remove(w.File.Name())
+		return fmt.Errorf("failed to close export file: %w", err)
+	}
+	if err := os.Rename(w.File.Name(), w.path); err != nil {
+		os.Remove(w.File.Name())
+		return fmt.Errorf("failed to publish export file: %w", err)
+	}
+	return nil
+}
+
+// Abort 丢弃写入中的文件
+func (w *localWriter) Abort() error {
+	w.File.Close()
+	if err := os.Remove(w.File.Name()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
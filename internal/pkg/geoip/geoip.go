@@ -0,0 +1,156 @@
+// Package geoip 查询客户端IP所在的国家
+// 查询方式由配置login_risk.geoip.provider决定：header读取CDN或负载均衡写入的国家代码请求头，
+// csv从本地IP段文件（每行"CIDR,国家代码"，如由GeoLite2 Country CSV转换）加载到内存后按IP段二分查找
+package geoip
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+
+	"exchange/internal/pkg/config"
+)
+
+// Locator 国家查询接口
+type Locator interface {
+	// Country 客户端IP所在国家的ISO 3166-1两位代码（大写），无法确定时返回空字符串
+	Country(clientIP string, header http.Header) string
+}
+
+// NewLocator 按配置创建国家查询
+func NewLocator(cfg config.GeoIPConfig) (Locator, error) {
+	switch cfg.Provider {
+	case "", "none":
+		return noneLocator{}, nil
+	case "header":
+		return headerLocator{header: cfg.Header}, nil
+	case "csv":
+		return LoadCSV(cfg.File)
+	default:
+		return nil, fmt.Errorf("unsupported geoip provider: %s", cfg.Provider)
+	}
+}
+
+// noneLocator 不查询国家
+type noneLocator struct{}
+
+// Country 总是返回空字符串
+func (noneLocator) Country(string, http.Header) string {
+	return ""
+}
+
+// headerLocator 读取CDN或负载均衡写入的国家代码请求头
+type headerLocator struct {
+	header string
+}
+
+// Country 请求头中的国家代码，Cloudflare的XX（未知）和T1（Tor）视为无法确定
+func (l headerLocator) Country(_ string, header http.Header) string {
+	return normalize(header.Get(l.header))
+}
+
+// ipRange 一个IP段
+type ipRange struct {
+	start   netip.Addr
+	end     netip.Addr
+	country string
+}
+
+// CSVLocator 从本地IP段文件查询国家，IP段之间不能重叠
+type CSVLocator struct {
+	ranges []ipRange // 按起始地址排序
+}
+
+// LoadCSV 加载IP段文件，空行和#开头的行忽略
+func LoadCSV(path string) (*CSVLocator, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open geoip file: %w", err)
+	}
+	defer file.Close()
+
+	locator := &CSVLocator{}
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		cidr, country, ok := strings.Cut(text, ",")
+		if !ok {
+			return nil, fmt.Errorf("invalid geoip line %d: %q", line, text)
+		}
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid geoip line %d: %w", line, err)
+		}
+		prefix = prefix.Masked()
+		locator.ranges = append(locator.ranges, ipRange{
+			start:   prefix.Addr(),
+			end:     lastAddr(prefix),
+			country: normalize(country),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read geoip file: %w", err)
+	}
+
+	sort.Slice(locator.ranges, func(i, j int) bool {
+		return locator.ranges[i].start.Less(locator.ranges[j].start)
+	})
+	return locator, nil
+}
+
+// Country 客户端IP所在的国家
+func (l *CSVLocator) Country(clientIP string, _ http.Header) string {
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+
+	// 起始地址不大于addr的最后一个IP段
+	i := sort.Search(len(l.ranges), func(i int) bool {
+		return addr.Less(l.ranges[i].start)
+	}) - 1
+	if i < 0 {
+		return ""
+	}
+	r := l.ranges[i]
+	if r.start.BitLen() != addr.BitLen() || r.end.Less(addr) {
+		return ""
+	}
+	return r.country
+}
+
+// lastAddr IP段的最后一个地址
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Addr().AsSlice()
+	bits := prefix.Bits()
+	for i := range bytes {
+		hostBits := len(bytes)*8 - bits - (len(bytes)-1-i)*8
+		switch {
+		case hostBits >= 8:
+			bytes[i] = 0xff
+		case hostBits > 0:
+			bytes[i] |= byte(1<<hostBits - 1)
+		}
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return addr
+}
+
+// normalize 国家代码转为大写，未知代码返回空字符串
+func normalize(country string) string {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if len(country) != 2 || country == "XX" || country == "T1" {
+		return ""
+	}
+	return country
+}
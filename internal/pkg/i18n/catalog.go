@@ -0,0 +1,216 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+
+	appLogger "exchange/internal/pkg/logger"
+)
+
+// 消息来源，后加载的来源覆盖先加载的同名消息
+const (
+	SourceEmbedded  = "embedded"  // 内嵌的翻译文件
+	SourceDirectory = "directory" // 外部翻译目录
+	SourceOverride  = "override"  // 管理后台编辑的翻译
+)
+
+// PluralForms 消息的复数形式（CLDR），只有一种形式的消息使用other
+var PluralForms = []string{"zero", "one", "two", "few", "many", "other"}
+
+// Message 已加载的翻译消息
+type Message struct {
+	Key    string            `json:"key"`
+	Forms  map[string]string `json:"forms"`  // 复数形式到消息模板，至少包含other
+	Source string            `json:"source"` // embedded/directory/override
+}
+
+// Override 管理后台编辑的翻译，覆盖内嵌和外部目录中同一语言的同名消息
+type Override struct {
+	Language string
+	Key      string
+	Forms    map[string]string
+}
+
+// SetOverrides 设置管理后台编辑的翻译并重新加载，替换之前设置的全部覆盖
+func (m *I18nManager) SetOverrides(overrides []Override) error {
+	m.mutex.Lock()
+	m.overrides = overrides
+	m.mutex.Unlock()
+	return m.Reload()
+}
+
+// applyOverrides 加载管理后台编辑的翻译，语言代码无效或缺少other形式的覆盖跳过并记录日志
+func (m *I18nManager) applyOverrides() {
+	for _, override := range m.overrides {
+		langTag, err := language.Parse(override.Language)
+		if err != nil || override.Forms["other"] == "" {
+			appLogger.Warn("忽略无效的翻译覆盖", map[string]interface{}{
+				"language": override.Language,
+				"key":      override.Key,
+			})
+			continue
+		}
+
+		message := newMessage(override.Key, override.Forms)
+		if err := m.bundle.AddMessages(langTag, message); err != nil {
+			appLogger.Warn("忽略无效的翻译覆盖", map[string]interface{}{
+				"language": override.Language,
+				"key":      override.Key,
+				"error":    err.Error(),
+			})
+			continue
+		}
+		m.record(langTag, SourceOverride, message)
+
+		if !m.containsLanguage(langTag) {
+			m.supportedLangs = append(m.supportedLangs, langTag)
+		}
+	}
+}
+
+// record 记录已加载的消息
+func (m *I18nManager) record(lang language.Tag, source string, messages ...*i18n.Message) {
+	entries := m.catalog[lang]
+	if entries == nil {
+		entries = make(map[string]Message)
+		m.catalog[lang] = entries
+	}
+	for _, message := range messages {
+		entries[message.ID] = Message{
+			Key:    message.ID,
+			Forms:  messageForms(message),
+			Source: source,
+		}
+	}
+}
+
+// Messages 获取语言已加载的消息（不含回退到默认语言的消息），按消息键排序
+func (m *I18nManager) Messages(lang string) ([]Message, error) {
+	langTag, err := language.Parse(lang)
+	if err != nil {
+		return nil, fmt.Errorf("invalid language %q: %w", lang, err)
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	messages := make([]Message, 0, len(m.catalog[langTag]))
+	for _, message := range m.catalog[langTag] {
+		messages = append(messages, message)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].Key < messages[j].Key
+	})
+	return messages, nil
+}
+
+// Keys 获取所有语言中出现过的消息键，按字母排序
+func (m *I18nManager) Keys() []string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.keysLocked()
+}
+
+// MissingKeys 获取其他语言中存在、该语言缺少的消息键，按字母排序
+// 该语言有基础消息时，按性别选择的消息（<key>_male等）不算缺少，TranslateGender会使用基础消息
+func (m *I18nManager) MissingKeys(lang string) ([]string, error) {
+	langTag, err := language.Parse(lang)
+	if err != nil {
+		return nil, fmt.Errorf("invalid language %q: %w", lang, err)
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var missing []string
+	for _, key := range m.keysLocked() {
+		if _, ok := m.catalog[langTag][key]; ok {
+			continue
+		}
+		if base, ok := genderBase(key); ok {
+			if _, ok := m.catalog[langTag][base]; ok {
+				continue
+			}
+		}
+		missing = append(missing, key)
+	}
+	return missing, nil
+}
+
+// LogMissingKeys 以Warn级别记录各支持语言缺少的消息键，启动时调用以发现未翻译的消息
+func (m *I18nManager) LogMissingKeys() {
+	for _, lang := range m.GetSupportedLanguages() {
+		missing, err := m.MissingKeys(lang)
+		if err != nil || len(missing) == 0 {
+			continue
+		}
+		appLogger.Warn("翻译缺少消息", map[string]interface{}{
+			"language": lang,
+			"fallback": m.FallbackChain(lang)[1:],
+			"count":    len(missing),
+			"keys":     missing,
+		})
+	}
+}
+
+// genderBase 按性别选择的消息键对应的基础消息键
+func genderBase(key string) (string, bool) {
+	for _, gender := range []string{GenderMale, GenderFemale, GenderOther} {
+		if base := strings.TrimSuffix(key, "_"+gender); base != key {
+			return base, true
+		}
+	}
+	return "", false
+}
+
+// keysLocked 所有语言的消息键，调用方需持有读锁
+func (m *I18nManager) keysLocked() []string {
+	seen := make(map[string]bool)
+	for _, entries := range m.catalog {
+		for key := range entries {
+			seen[key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// newMessage 由复数形式构造消息
+func newMessage(key string, forms map[string]string) *i18n.Message {
+	return &i18n.Message{
+		ID:    key,
+		Zero:  forms["zero"],
+		One:   forms["one"],
+		Two:   forms["two"],
+		Few:   forms["few"],
+		Many:  forms["many"],
+		Other: forms["other"],
+	}
+}
+
+// messageForms 消息中非空的复数形式
+func messageForms(message *i18n.Message) map[string]string {
+	forms := make(map[string]string, 1)
+	for form, text := range map[string]string{
+		"zero":  message.Zero,
+		"one":   message.One,
+		"two":   message.Two,
+		"few":   message.Few,
+		"many":  message.Many,
+		"other": message.Other,
+	} {
+		if text != "" {
+			forms[form] = text
+		}
+	}
+	return forms
+}
@@ -0,0 +1,100 @@
+package i18n
+
+import (
+	"fmt"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+)
+
+// SetFallbacks 设置语言的回退链，如 "zh-TW": ["zh", "en"] 表示zh-TW缺少的消息依次使用zh、en的翻译
+// 未配置的语言回退到基础语言（如ja-JP回退到ja），所有回退链最后都回退到默认语言
+func (m *I18nManager) SetFallbacks(fallbacks map[string][]string) error {
+	parsed := make(map[language.Tag][]language.Tag, len(fallbacks))
+	for lang, chain := range fallbacks {
+		tag, err := language.Parse(lang)
+		if err != nil {
+			return fmt.Errorf("invalid fallback language %q: %w", lang, err)
+		}
+		for _, fallback := range chain {
+			fallbackTag, err := language.Parse(fallback)
+			if err != nil {
+				return fmt.Errorf("invalid fallback language %q for %s: %w", fallback, lang, err)
+			}
+			parsed[tag] = append(parsed[tag], fallbackTag)
+		}
+	}
+
+	m.mutex.Lock()
+	m.fallbacks = parsed
+	m.mutex.Unlock()
+	return nil
+}
+
+// FallbackChain 获取语言的回退链（含语言本身和默认语言），按优先级排列
+func (m *I18nManager) FallbackChain(lang string) []string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	chain := m.fallbackChain(m.parseLanguage(lang))
+	languages := make([]string, len(chain))
+	for i, tag := range chain {
+		languages[i] = tag.String()
+	}
+	return languages
+}
+
+// parseLanguage 解析语言代码，无效时使用默认语言
+func (m *I18nManager) parseLanguage(lang string) language.Tag {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return m.defaultLanguage
+	}
+	return tag
+}
+
+// candidates 语言本身和配置的回退链，未配置时为语言本身和基础语言，不含默认语言；调用方需持有读锁
+func (m *I18nManager) candidates(tag language.Tag) []language.Tag {
+	chain := []language.Tag{tag}
+	if fallbacks, ok := m.fallbacks[tag]; ok {
+		return append(chain, fallbacks...)
+	}
+	if base, confidence := tag.Base(); confidence != language.No {
+		if baseTag := language.Make(base.String()); baseTag != tag {
+			chain = append(chain, baseTag)
+		}
+	}
+	return chain
+}
+
+// fallbackChain 候选语言加上默认语言，去掉重复的语言；调用方需持有读锁
+func (m *I18nManager) fallbackChain(tag language.Tag) []language.Tag {
+	chain := make([]language.Tag, 0, 4)
+	seen := make(map[language.Tag]bool, 4)
+	for _, candidate := range append(m.candidates(tag), m.defaultLanguage) {
+		if !seen[candidate] {
+			seen[candidate] = true
+			chain = append(chain, candidate)
+		}
+	}
+	return chain
+}
+
+// messageLanguage 回退链中第一个包含消息的语言，都不包含时返回false；调用方需持有读锁
+func (m *I18nManager) messageLanguage(tag language.Tag, key string) (language.Tag, bool) {
+	for _, candidate := range m.fallbackChain(tag) {
+		if _, ok := m.catalog[candidate][key]; ok {
+			return candidate, true
+		}
+	}
+	return tag, false
+}
+
+// localizerFor 按回退链选择包含消息的语言创建本地化器，复数规则使用该语言的规则
+func (m *I18nManager) localizerFor(lang, key string) *i18n.Localizer {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	tag, _ := m.messageLanguage(m.parseLanguage(lang), key)
+	return i18n.NewLocalizer(m.bundle, tag.String(), m.defaultLanguage.String())
+}
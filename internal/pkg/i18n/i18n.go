@@ -17,12 +17,44 @@ import (
 //go:embed locales/*.json
 var localeFS embed.FS
 
+// 模板数据中有特殊含义的键
+const (
+	CountKey  = "Count"  // 数量，决定消息的复数形式
+	GenderKey = "Gender" // 性别，TranslateGender按该值选择消息
+)
+
+// UserLanguageKey 上下文中认证用户保存的首选语言，由认证中间件设置
+const UserLanguageKey = "user_language"
+
+// 性别取值
+const (
+	GenderMale   = "male"
+	GenderFemale = "female"
+	GenderOther  = "other"
+)
+
+// Data 由键值对构造模板数据，如 Data("Count", 3, "Name", name)；键必须为字符串，参数为奇数个时忽略最后一个
+func Data(pairs ...interface{}) map[string]interface{} {
+	data := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if key, ok := pairs[i].(string); ok {
+			data[key] = pairs[i+1]
+		}
+	}
+	return data
+}
+
 // I18nManager 国际化管理器
 type I18nManager struct {
 	bundle          *i18n.Bundle
 	supportedLangs  []language.Tag
 	defaultLanguage language.Tag
+	localesDir      string                              // 外部翻译文件目录，覆盖和补充内嵌的翻译
+	overrides       []Override                          // 管理后台编辑的翻译，最后加载
+	catalog         map[language.Tag]map[string]Message // 已加载的消息，按语言和消息键
+	fallbacks       map[language.Tag][]language.Tag     // 配置的语言回退链
 	mutex           sync.RWMutex
+	reloadMutex     sync.Mutex // 串行执行重新加载，避免较早的加载结果覆盖较新的
 }
 
 // NewI18nManager 创建国际化管理器
@@ -33,28 +65,42 @@ func NewI18nManager(defaultLang string) *I18nManager {
 		defaultTag = language.English
 	}
 
-	// 创建Bundle
-	bundle := i18n.NewBundle(defaultTag)
-	bundle.RegisterUnmarshalFunc("json", func(data []byte, v interface{}) error {
-		return json.Unmarshal(data, v)
-	})
-
 	manager := &I18nManager{
-		bundle:          bundle,
 		defaultLanguage: defaultTag,
-		supportedLangs:  []language.Tag{defaultTag},
 	}
+	manager.load()
+
+	return manager
+}
+
+// load 创建Bundle并加载内嵌的翻译文件，设置了外部目录时再加载目录中的翻译文件
+// 返回外部目录中无法加载的文件的错误，其他文件照常加载
+func (m *I18nManager) load() error {
+	// 创建Bundle
+	m.bundle = i18n.NewBundle(m.defaultLanguage)
+	m.bundle.RegisterUnmarshalFunc("json", func(data []byte, v interface{}) error {
+		return json.Unmarshal(data, v)
+	})
+	m.supportedLangs = []language.Tag{m.defaultLanguage}
+	m.catalog = make(map[language.Tag]map[string]Message)
 
 	// 加载内嵌的翻译文件
-	manager.loadEmbeddedTranslations()
+	m.loadEmbeddedTranslations()
 
-	return manager
+	var err error
+	if m.localesDir != "" {
+		err = m.loadDirectoryTranslations()
+	}
+
+	// 管理后台编辑的翻译优先级最高
+	m.applyOverrides()
+	return err
 }
 
 // loadEmbeddedTranslations 加载内嵌的翻译文件
 func (m *I18nManager) loadEmbeddedTranslations() {
 	// 支持的语言列表
-	languages := []string{"en", "zh"}
+	languages := []string{"en", "zh", "ja", "ko", "es", "ru"}
 
 	for _, lang := range languages {
 		filename := fmt.Sprintf("locales/%s.json", lang)
@@ -78,7 +124,7 @@ func (m *I18nManager) loadEmbeddedTranslations() {
 		}
 
 		// 加载翻译文件
-		_, err = m.bundle.ParseMessageFileBytes(data, filename)
+		file, err := m.bundle.ParseMessageFileBytes(data, filename)
 		if err != nil {
 			appLogger.Error("Failed to parse translation file", map[string]interface{}{
 				"file":  filename,
@@ -89,6 +135,8 @@ func (m *I18nManager) loadEmbeddedTranslations() {
 			continue
 		}
 
+		m.record(langTag, SourceEmbedded, file.Messages...)
+
 		// 添加到支持的语言列表
 		if !m.containsLanguage(langTag) {
 			m.supportedLangs = append(m.supportedLangs, langTag)
@@ -121,6 +169,7 @@ func (m *I18nManager) loadDefaultTranslations(lang string) {
 			Other: value,
 		}
 		m.bundle.AddMessages(langTag, message)
+		m.record(langTag, SourceEmbedded, message)
 	}
 
 	// 添加到支持的语言列表
@@ -193,8 +242,9 @@ func (m *I18nManager) getDefaultTranslations(lang string) map[string]string {
 			"file_too_large":     "File too large",
 
 			// 系统相关
-			"not_implemented": "Feature not implemented yet",
-			"request_timeout": "Request timeout",
+			"not_implemented":  "Feature not implemented yet",
+			"request_timeout":  "Request timeout",
+			"maintenance_mode": "Service is under maintenance, please try again later",
 		}
 	case "zh":
 		return map[string]string{
@@ -252,8 +302,9 @@ func (m *I18nManager) getDefaultTranslations(lang string) map[string]string {
 			"file_too_large":     "文件过大",
 
 			// 系统相关
-			"not_implemented": "功能尚未实现",
-			"request_timeout": "请求超时",
+			"not_implemented":  "功能尚未实现",
+			"request_timeout":  "请求超时",
+			"maintenance_mode": "系统维护中，请稍后再试",
 		}
 	default:
 		return nil
@@ -275,25 +326,28 @@ func (m *I18nManager) GetLocalizer(lang string) *i18n.Localizer {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
-	// 解析语言标签
-	langTag, err := language.Parse(lang)
-	if err != nil {
-		langTag = m.defaultLanguage
+	// 创建本地化器，按回退链匹配语言
+	chain := m.fallbackChain(m.parseLanguage(lang))
+	languages := make([]string, len(chain))
+	for i, tag := range chain {
+		languages[i] = tag.String()
 	}
-
-	// 创建本地化器，支持语言回退
-	return i18n.NewLocalizer(m.bundle, langTag.String(), m.defaultLanguage.String())
+	return i18n.NewLocalizer(m.bundle, languages...)
 }
 
-// Translate 翻译文本
+// Translate 翻译文本，语言缺少该消息时按回退链（如zh-TW → zh → en）使用第一个有翻译的语言
+// templateData中包含Count时按语言的复数规则（CLDR）选择消息的复数形式，如英文的one/other，中文只有other
 func (m *I18nManager) Translate(lang, key string, templateData map[string]interface{}) string {
-	localizer := m.GetLocalizer(lang)
+	localizer := m.localizerFor(lang, key)
 
 	// 创建本地化配置
 	config := &i18n.LocalizeConfig{
 		MessageID:    key,
 		TemplateData: templateData,
 	}
+	if count, ok := templateData[CountKey]; ok {
+		config.PluralCount = count
+	}
 
 	// 执行翻译
 	result, err := localizer.Localize(config)
@@ -310,6 +364,40 @@ func (m *I18nManager) Translate(lang, key string, templateData map[string]interf
 	return result
 }
 
+// TranslatePlural 按count选择复数形式翻译，count同时作为模板数据Count
+// 消息在翻译文件中按复数形式定义，如 "unread_messages": {"one": "{{.Count}} unread message", "other": "{{.Count}} unread messages"}
+func (m *I18nManager) TranslatePlural(lang, key string, count interface{}, templateData map[string]interface{}) string {
+	data := make(map[string]interface{}, len(templateData)+1)
+	for k, v := range templateData {
+		data[k] = v
+	}
+	data[CountKey] = count
+	return m.Translate(lang, key, data)
+}
+
+// TranslateGender 按性别选择消息翻译：优先使用"<key>_<gender>"，不存在时使用key，gender同时作为模板数据Gender
+// 如 "profile_updated_male": "{{.Name}} updated his profile"，"profile_updated": "{{.Name}} updated their profile"
+func (m *I18nManager) TranslateGender(lang, key, gender string, templateData map[string]interface{}) string {
+	data := make(map[string]interface{}, len(templateData)+1)
+	for k, v := range templateData {
+		data[k] = v
+	}
+	data[GenderKey] = gender
+	if gender != "" && m.HasMessage(lang, key+"_"+gender) {
+		key = key + "_" + gender
+	}
+	return m.Translate(lang, key, data)
+}
+
+// HasMessage 检查翻译是否存在（按回退链，最后回退到默认语言），不记录翻译失败日志
+func (m *I18nManager) HasMessage(lang, key string) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	_, ok := m.messageLanguage(m.parseLanguage(lang), key)
+	return ok
+}
+
 // GetSupportedLanguages 获取支持的语言列表
 func (m *I18nManager) GetSupportedLanguages() []string {
 	m.mutex.RLock()
@@ -322,6 +410,23 @@ func (m *I18nManager) GetSupportedLanguages() []string {
 	return languages
 }
 
+// IsSupported 语言或其回退链中的语言（不含默认语言）是否在支持的语言列表中，如支持ja时ja-JP也支持
+func (m *I18nManager) IsSupported(lang string) bool {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return false
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	for _, candidate := range m.candidates(tag) {
+		if m.containsLanguage(candidate) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetDefaultLanguage 获取默认语言
 func (m *I18nManager) GetDefaultLanguage() string {
 	m.mutex.RLock()
@@ -341,7 +446,12 @@ func GetLanguageFromContext(c *gin.Context) string {
 		return lang
 	}
 
-	// 3. 从Accept-Language头获取
+	// 3. 认证用户保存的首选语言
+	if lang := c.GetString(UserLanguageKey); lang != "" {
+		return lang
+	}
+
+	// 4. 从Accept-Language头获取
 	if acceptLang := c.GetHeader("Accept-Language"); acceptLang != "" {
 		// 解析Accept-Language头，取第一个语言
 		langs := parseAcceptLanguage(acceptLang)
@@ -350,14 +460,14 @@ func GetLanguageFromContext(c *gin.Context) string {
 		}
 	}
 
-	// 4. 从上下文获取（可能由中间件设置）
+	// 5. 从上下文获取（可能由中间件设置）
 	if lang, exists := c.Get("language"); exists {
 		if langStr, ok := lang.(string); ok {
 			return langStr
 		}
 	}
 
-	// 5. 返回默认语言（中文）
+	// 6. 返回默认语言（中文）
 	return "zh"
 }
 
@@ -406,3 +516,8 @@ func GetTranslatedMessage(c *gin.Context, messageKey string, templateData map[st
 	// 翻译消息
 	return manager.Translate(lang, messageKey, templateData)
 }
+
+// GetTranslatedPlural 从上下文中获取语言，按count选择复数形式翻译
+func GetTranslatedPlural(c *gin.Context, messageKey string, count interface{}, templateData map[string]interface{}) string {
+	return GetGlobalI18n().TranslatePlural(GetLanguageFromContext(c), messageKey, count, templateData)
+}
@@ -0,0 +1,174 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"golang.org/x/text/language"
+
+	appLogger "exchange/internal/pkg/logger"
+)
+
+// reloadDebounce 目录变更后等待的时间，编辑器保存时的多次写入只触发一次重新加载
+const reloadDebounce = 300 * time.Millisecond
+
+// SetLocalesDir 设置外部翻译文件目录并重新加载
+// 目录中的<语言>.json（如en.json、ja.json）在内嵌翻译之后加载，同一消息键覆盖内嵌的翻译，新的语言加入支持的语言列表
+func (m *I18nManager) SetLocalesDir(dir string) error {
+	m.mutex.Lock()
+	m.localesDir = dir
+	m.mutex.Unlock()
+	return m.Reload()
+}
+
+// Reload 重新加载内嵌和外部目录中的翻译，整体替换当前翻译
+// 外部目录中有文件无法解析时保留当前翻译并返回错误，避免写了一半的文件使线上翻译丢失
+func (m *I18nManager) Reload() error {
+	m.reloadMutex.Lock()
+	defer m.reloadMutex.Unlock()
+
+	m.mutex.RLock()
+	next := &I18nManager{
+		defaultLanguage: m.defaultLanguage,
+		localesDir:      m.localesDir,
+		overrides:       m.overrides,
+	}
+	m.mutex.RUnlock()
+
+	if err := next.load(); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	m.bundle = next.bundle
+	m.supportedLangs = next.supportedLangs
+	m.catalog = next.catalog
+	m.mutex.Unlock()
+
+	appLogger.Info("翻译已重新加载", map[string]interface{}{
+		"dir":       next.localesDir,
+		"languages": len(next.supportedLangs),
+	})
+	return nil
+}
+
+// loadDirectoryTranslations 加载外部目录中的翻译文件，文件名（去掉.json）为语言代码
+func (m *I18nManager) loadDirectoryTranslations() error {
+	files, err := filepath.Glob(filepath.Join(m.localesDir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("failed to list locale files in %s: %w", m.localesDir, err)
+	}
+
+	var errs []error
+	for _, file := range files {
+		lang := strings.TrimSuffix(filepath.Base(file), ".json")
+		langTag, err := language.Parse(lang)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid locale file name %s: %w", file, err))
+			continue
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read locale file %s: %w", file, err))
+			continue
+		}
+		// 文件名为语言代码，ParseMessageFileBytes按文件名识别语言
+		parsed, err := m.bundle.ParseMessageFileBytes(data, langTag.String()+".json")
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse locale file %s: %w", file, err))
+			continue
+		}
+		m.record(langTag, SourceDirectory, parsed.Messages...)
+
+		if !m.containsLanguage(langTag) {
+			m.supportedLangs = append(m.supportedLangs, langTag)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Watcher 外部翻译目录的监听
+type Watcher struct {
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// Watch 监听外部翻译目录，目录中的.json文件创建、修改、删除或重命名后重新加载
+// 重新加载失败时保留当前翻译并记录错误，修正文件后会再次加载
+func (m *I18nManager) Watch() (*Watcher, error) {
+	m.mutex.RLock()
+	dir := m.localesDir
+	m.mutex.RUnlock()
+	if dir == "" {
+		return nil, fmt.Errorf("locales directory is not set")
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create locale watcher: %w", err)
+	}
+	// 监听目录而不是文件，编辑器以重命名替换的方式保存时也能收到事件
+	if err := fsWatcher.Add(dir); err != nil {
+		fsWatcher.Close()
+		return nil, fmt.Errorf("failed to watch locales directory %s: %w", dir, err)
+	}
+
+	w := &Watcher{
+		watcher: fsWatcher,
+		done:    make(chan struct{}),
+	}
+	go w.run(m)
+	return w, nil
+}
+
+// run 处理目录变更事件，合并reloadDebounce内的多次变更
+func (w *Watcher) run(m *I18nManager) {
+	defer close(w.done)
+
+	var timer *time.Timer
+	var reload <-chan time.Time
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if !strings.HasSuffix(event.Name, ".json") || event.Op == fsnotify.Chmod {
+				continue
+			}
+			if timer == nil {
+				timer = time.NewTimer(reloadDebounce)
+			} else {
+				timer.Reset(reloadDebounce)
+			}
+			reload = timer.C
+		case <-reload:
+			reload = nil
+			if err := m.Reload(); err != nil {
+				appLogger.Error("翻译重新加载失败，继续使用当前翻译", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			appLogger.Warn("翻译目录监听错误", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+// Close 停止监听
+func (w *Watcher) Close() error {
+	err := w.watcher.Close()
+	<-w.done
+	return err
+}
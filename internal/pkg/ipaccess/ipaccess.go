@@ -0,0 +1,269 @@
+// Package ipaccess IP访问控制
+// 管理后台IP白名单和客户端IP黑名单保存在Redis集合中（元素为规范化的CIDR），所有实例共享；各实例在内存中保存快照，
+// 快照超过cache_seconds后重新加载，通过本实例修改时立即重新加载。配置文件中的条目始终生效，不能通过管理接口删除
+package ipaccess
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/database"
+	appLogger "exchange/internal/pkg/logger"
+)
+
+// 名单
+const (
+	ListAllow = "allow" // 管理后台IP白名单
+	ListDeny  = "deny"  // 客户端IP黑名单
+)
+
+// 条目来源
+const (
+	SourceConfig = "config" // 配置文件
+	SourceAdmin  = "admin"  // 管理接口添加（Redis）
+)
+
+// keyPrefix 名单的Redis键前缀，后接名单名
+const keyPrefix = "ip_access:"
+
+// 修改名单的错误
+var (
+	ErrUnknownList  = errors.New("unknown ip list")
+	ErrConfigEntry  = errors.New("entry is defined in the config file")
+	ErrEntryMissing = errors.New("entry not found")
+)
+
+// ipAccessLogger IP访问控制日志
+var ipAccessLogger = appLogger.Module("ip_access")
+
+// Entry 名单条目
+type Entry struct {
+	CIDR   string `json:"cidr"`
+	Source string `json:"source"` // config, admin
+}
+
+// Store IP名单存储
+type Store struct {
+	redis  *database.RedisService
+	ttl    time.Duration
+	static map[string][]netip.Prefix // 配置文件中的条目
+
+	reloadMu sync.Mutex // 同一时间只有一个请求重新加载
+
+	mu       sync.RWMutex
+	dynamic  map[string][]netip.Prefix // Redis中的条目
+	loadedAt time.Time
+}
+
+// NewStore 创建IP名单存储，配置文件中的条目无效时返回错误；redis为nil时只使用配置文件中的条目
+func NewStore(redis *database.RedisService, cfg config.IPAccessConfig) (*Store, error) {
+	s := &Store{
+		redis:   redis,
+		ttl:     time.Duration(cfg.CacheSeconds) * time.Second,
+		static:  make(map[string][]netip.Prefix, 2),
+		dynamic: make(map[string][]netip.Prefix, 2),
+	}
+	for list, entries := range map[string][]string{ListAllow: cfg.Allow, ListDeny: cfg.Deny} {
+		for _, entry := range entries {
+			prefix, err := ParsePrefix(entry)
+			if err != nil {
+				return nil, err
+			}
+			s.static[list] = append(s.static[list], prefix)
+		}
+	}
+	return s, nil
+}
+
+// ParsePrefix 解析IP地址或CIDR，单个地址按/32（IPv6为/128）处理，返回主机位清零后的网段
+func ParsePrefix(value string) (netip.Prefix, error) {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, "/") {
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid ip address %q: %w", value, err)
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid cidr %q: %w", value, err)
+	}
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	return prefix.Masked(), nil
+}
+
+// Contains 名单中是否有包含ip的条目
+func (s *Store) Contains(ctx context.Context, list string, ip netip.Addr) bool {
+	ip = ip.Unmap()
+	for _, prefix := range s.static[list] {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	for _, prefix := range s.snapshot(ctx)[list] {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// List 名单的所有条目，配置文件中的条目在前
+func (s *Store) List(ctx context.Context, list string) ([]Entry, error) {
+	if err := checkList(list); err != nil {
+		return nil, err
+	}
+	if err := s.reload(ctx); err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(s.static[list]))
+	for _, prefix := range s.static[list] {
+		entries = append(entries, Entry{CIDR: prefix.String(), Source: SourceConfig})
+	}
+	s.mu.RLock()
+	for _, prefix := range s.dynamic[list] {
+		entries = append(entries, Entry{CIDR: prefix.String(), Source: SourceAdmin})
+	}
+	s.mu.RUnlock()
+	return entries, nil
+}
+
+// Add 添加条目，返回规范化的CIDR；条目已存在时不做修改
+func (s *Store) Add(ctx context.Context, list, value string) (string, error) {
+	if err := checkList(list); err != nil {
+		return "", err
+	}
+	prefix, err := ParsePrefix(value)
+	if err != nil {
+		return "", err
+	}
+	if err := s.redis.Client().SAdd(ctx, keyPrefix+list, prefix.String()).Err(); err != nil {
+		return "", fmt.Errorf("failed to add ip entry: %w", err)
+	}
+	s.invalidate(ctx)
+	return prefix.String(), nil
+}
+
+// Remove 删除条目，返回规范化的CIDR；配置文件中的条目返回ErrConfigEntry，不存在时返回ErrEntryMissing
+func (s *Store) Remove(ctx context.Context, list, value string) (string, error) {
+	if err := checkList(list); err != nil {
+		return "", err
+	}
+	prefix, err := ParsePrefix(value)
+	if err != nil {
+		return "", err
+	}
+	for _, static := range s.static[list] {
+		if static == prefix {
+			return "", ErrConfigEntry
+		}
+	}
+	removed, err := s.redis.Client().SRem(ctx, keyPrefix+list, prefix.String()).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to remove ip entry: %w", err)
+	}
+	if removed == 0 {
+		return "", ErrEntryMissing
+	}
+	s.invalidate(ctx)
+	return prefix.String(), nil
+}
+
+// snapshot Redis中条目的快照，超过缓存时间时重新加载；加载失败时沿用旧快照
+func (s *Store) snapshot(ctx context.Context) map[string][]netip.Prefix {
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) >= s.ttl
+	dynamic := s.dynamic
+	s.mu.RUnlock()
+	if !stale || s.redis == nil {
+		return dynamic
+	}
+
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	s.mu.RLock()
+	stale = time.Since(s.loadedAt) >= s.ttl
+	s.mu.RUnlock()
+	if stale {
+		if err := s.load(ctx); err != nil {
+			ipAccessLogger.WithContext(ctx).Warn("加载IP名单失败，使用上次加载的名单", map[string]interface{}{
+				"error": err.Error(),
+			})
+			s.mu.Lock()
+			s.loadedAt = time.Now()
+			s.mu.Unlock()
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dynamic
+}
+
+// reload 立即重新加载
+func (s *Store) reload(ctx context.Context) error {
+	if s.redis == nil {
+		return nil
+	}
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	return s.load(ctx)
+}
+
+// invalidate 修改后重新加载，失败时在下次匹配时重试
+func (s *Store) invalidate(ctx context.Context) {
+	if err := s.reload(ctx); err != nil {
+		s.mu.Lock()
+		s.loadedAt = time.Time{}
+		s.mu.Unlock()
+	}
+}
+
+// load 从Redis加载所有名单，需持有reloadMu
+func (s *Store) load(ctx context.Context) error {
+	dynamic := make(map[string][]netip.Prefix, 2)
+	for _, list := range []string{ListAllow, ListDeny} {
+		members, err := s.redis.Client().SMembers(ctx, keyPrefix+list).Result()
+		if err != nil {
+			return fmt.Errorf("failed to load ip list %s: %w", list, err)
+		}
+		sort.Strings(members)
+		for _, member := range members {
+			prefix, err := ParsePrefix(member)
+			if err != nil {
+				ipAccessLogger.WithContext(ctx).Warn("忽略无效的IP名单条目", map[string]interface{}{
+					"list":  list,
+					"entry": member,
+				})
+				continue
+			}
+			dynamic[list] = append(dynamic[list], prefix)
+		}
+	}
+
+	s.mu.Lock()
+	s.dynamic = dynamic
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// checkList 检查名单名
+func checkList(list string) error {
+	if list != ListAllow && list != ListDeny {
+		return fmt.Errorf("%w: %s", ErrUnknownList, list)
+	}
+	return nil
+}
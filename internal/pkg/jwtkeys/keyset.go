@@ -0,0 +1,260 @@
+// Package jwtkeys JWT签名密钥
+// 支持HS256（共享密钥jwt.secret_key）和非对称算法RS256、EdDSA（Ed25519）。非对称算法的密钥从密钥提供者加载（PEM），
+// token头中带有签发key的kid，验证时按kid选择公钥；轮换时新key先作为验证key发布到所有实例，再切换signing_key_id，
+// 旧key保留到verify_until（不早于旧key签发的最后一个token过期）后删除，期间新旧token都能通过验证
+package jwtkeys
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/secrets"
+)
+
+// 支持的签名算法
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var (
+	// ErrUnknownKey token头中的kid不存在或已过验证截止时间
+	ErrUnknownKey = errors.New("unknown jwt key id")
+	// ErrUnexpectedAlgorithm token的签名算法与配置不一致
+	ErrUnexpectedAlgorithm = errors.New("unexpected jwt signing method")
+)
+
+// SecretName 非对称密钥（PEM）在密钥提供者中的名称
+func SecretName(keyID string) string {
+	return "jwt_key_" + keyID
+}
+
+// key 一个非对称密钥
+type key struct {
+	id          string
+	private     crypto.Signer    // 只用于验证的key为nil
+	public      crypto.PublicKey // *rsa.PublicKey或ed25519.PublicKey
+	verifyUntil time.Time        // 零值表示一直有效
+}
+
+// Keyset JWT签名和验证密钥
+type Keyset struct {
+	algorithm string
+	method    jwt.SigningMethod
+	secret    []byte          // HS256的共享密钥
+	signing   *key            // 非对称算法的签发key
+	keys      map[string]*key // kid -> key
+}
+
+// Load 按JWT配置加载密钥，非对称算法的PEM密钥从密钥提供者读取
+func Load(ctx context.Context, cfg config.JWTConfig, provider secrets.Provider) (*Keyset, error) {
+	algorithm := cfg.Algorithm
+	if algorithm == "" {
+		algorithm = AlgorithmHS256
+	}
+
+	if algorithm == AlgorithmHS256 {
+		if cfg.SecretKey == "" {
+			return nil, errors.New("jwt secret key is required for HS256")
+		}
+		return &Keyset{algorithm: algorithm, method: jwt.SigningMethodHS256, secret: []byte(cfg.SecretKey)}, nil
+	}
+
+	ks := &Keyset{algorithm: algorithm, keys: make(map[string]*key, len(cfg.Keys))}
+	switch algorithm {
+	case AlgorithmRS256:
+		ks.method = jwt.SigningMethodRS256
+	case AlgorithmEdDSA:
+		ks.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm: %s", algorithm)
+	}
+
+	for _, keyCfg := range cfg.Keys {
+		value, err := provider.Get(ctx, SecretName(keyCfg.KeyID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load jwt key %s: %w", keyCfg.KeyID, err)
+		}
+		k, err := parseKey(keyCfg.KeyID, value, algorithm)
+		if err != nil {
+			return nil, err
+		}
+		if keyCfg.VerifyUntil != "" {
+			if k.verifyUntil, err = time.Parse(time.RFC3339, keyCfg.VerifyUntil); err != nil {
+				return nil, fmt.Errorf("invalid verify_until for jwt key %s: %w", keyCfg.KeyID, err)
+			}
+		}
+		ks.keys[k.id] = k
+	}
+
+	signing, ok := ks.keys[cfg.SigningKeyID]
+	if !ok {
+		return nil, fmt.Errorf("jwt signing key %s not found", cfg.SigningKeyID)
+	}
+	if signing.private == nil {
+		return nil, fmt.Errorf("jwt signing key %s has no private key", cfg.SigningKeyID)
+	}
+	ks.signing = signing
+	return ks, nil
+}
+
+// LoadFromConfig 按应用配置创建密钥提供者并加载JWT密钥（没有现成密钥提供者的模块使用）
+func LoadFromConfig(ctx context.Context, cfg *config.Config) (*Keyset, error) {
+	provider, err := secrets.NewProvider(cfg.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	return Load(ctx, cfg.JWT, provider)
+}
+
+// Algorithm 签名算法
+func (ks *Keyset) Algorithm() string {
+	return ks.algorithm
+}
+
+// SigningKeyID 签发key的kid，HS256为空
+func (ks *Keyset) SigningKeyID() string {
+	if ks.signing == nil {
+		return ""
+	}
+	return ks.signing.id
+}
+
+// Sign 签发token，非对称算法在头中写入kid
+func (ks *Keyset) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.method, claims)
+	if ks.signing == nil {
+		return token.SignedString(ks.secret)
+	}
+	token.Header["kid"] = ks.signing.id
+	return token.SignedString(ks.signing.private)
+}
+
+// Parse 验证token签名并解析声明，签名算法必须与配置一致（拒绝alg为none或以公钥作为HMAC密钥的token）
+func (ks *Keyset) Parse(tokenString string, claims jwt.Claims, options ...jwt.ParserOption) (*jwt.Token, error) {
+	options = append(options, jwt.WithValidMethods([]string{ks.method.Alg()}))
+	return jwt.ParseWithClaims(tokenString, claims, ks.keyFunc, options...)
+}
+
+// keyFunc 按token头中的kid选择验证密钥
+func (ks *Keyset) keyFunc(token *jwt.Token) (interface{}, error) {
+	if token.Method.Alg() != ks.method.Alg() {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedAlgorithm, token.Header["alg"])
+	}
+	if ks.signing == nil {
+		return ks.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	k, ok := ks.keys[kid]
+	if !ok || k.expired(clock.Now()) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	return k.public, nil
+}
+
+// expired 是否已过验证截止时间
+func (k *key) expired(now time.Time) bool {
+	return !k.verifyUntil.IsZero() && now.After(k.verifyUntil)
+}
+
+// JWK JSON Web Key（RFC 7517）中的公钥
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`   // RSA模数
+	E         string `json:"e,omitempty"`   // RSA公开指数
+	Curve     string `json:"crv,omitempty"` // OKP曲线
+	X         string `json:"x,omitempty"`   // OKP公钥
+}
+
+// JWKS JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 当前可用于验证的公钥，供其他服务验证token；HS256没有公钥，返回空集合
+func (ks *Keyset) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	now := clock.Now()
+	for _, k := range ks.keys {
+		if k.expired(now) {
+			continue
+		}
+		jwk := JWK{KeyID: k.id, Use: "sig", Algorithm: ks.algorithm}
+		switch pub := k.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+	return set
+}
+
+// parseKey 解析PEM密钥：私钥（PKCS#8，RSA也可以是PKCS#1）可签发和验证，公钥（PKIX）只用于验证
+func parseKey(id, value, algorithm string) (*key, error) {
+	block, _ := pem.Decode([]byte(value))
+	if block == nil {
+		return nil, fmt.Errorf("jwt key %s is not PEM encoded", id)
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("jwt key %s has unsupported PEM type %s", id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse jwt key %s: %w", id, err)
+	}
+
+	k := &key{id: id}
+	switch v := parsed.(type) {
+	case *rsa.PrivateKey:
+		k.private, k.public = v, &v.PublicKey
+	case *rsa.PublicKey:
+		k.public = v
+	case ed25519.PrivateKey:
+		k.private, k.public = v, v.Public()
+	case ed25519.PublicKey:
+		k.public = v
+	default:
+		return nil, fmt.Errorf("jwt key %s has unsupported key type %T", id, parsed)
+	}
+
+	_, isRSA := k.public.(*rsa.PublicKey)
+	if isRSA != (algorithm == AlgorithmRS256) {
+		return nil, fmt.Errorf("jwt key %s does not match algorithm %s", id, algorithm)
+	}
+	return k, nil
+}
@@ -0,0 +1,344 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"exchange/internal/pkg/config"
+)
+
+// fatalAlertWait Fatal退出前等待告警发送的最长时间
+const fatalAlertWait = 5 * time.Second
+
+// Alert 错误率告警
+type Alert struct {
+	Service   string        `json:"service"`
+	Category  string        `json:"category"`  // 类别：模块名，未指定模块时为日志类型或app
+	Count     int           `json:"count"`     // 窗口内的错误数
+	Window    time.Duration `json:"-"`         // 统计窗口
+	FirstAt   time.Time     `json:"first_at"`  // 窗口内最早一条错误的时间
+	LastAt    time.Time     `json:"last_at"`   // 最近一条错误的时间
+	Message   string        `json:"message"`   // 最近一条错误的消息
+	Fatal     bool          `json:"fatal"`     // 是否为致命错误（进程即将退出）
+	Security  bool          `json:"security"`  // 是否为安全告警（如管理员账号异常操作）
+	Threshold int           `json:"threshold"` // 触发阈值
+}
+
+// Title 告警标题
+func (a *Alert) Title() string {
+	if a.Fatal {
+		return fmt.Sprintf("[%s] 致命错误: %s", a.Service, a.Message)
+	}
+	if a.Security {
+		return fmt.Sprintf("[%s] 安全告警: %s", a.Service, a.Message)
+	}
+	return fmt.Sprintf("[%s] %s 错误率告警: %s内%d条错误", a.Service, a.Category, a.Window, a.Count)
+}
+
+// AlertHook 告警发送接口
+type AlertHook interface {
+	Fire(ctx context.Context, alert *Alert) error
+}
+
+// AlertHookFunc 函数形式的告警发送
+type AlertHookFunc func(ctx context.Context, alert *Alert) error
+
+// Fire 发送告警
+func (f AlertHookFunc) Fire(ctx context.Context, alert *Alert) error {
+	return f(ctx, alert)
+}
+
+// AddAlertHook 添加告警发送目标（如短信、IM机器人），未启用错误率告警时不生效
+func AddAlertHook(hook AlertHook) {
+	if defaultLogger == nil || defaultLogger.alerter == nil {
+		return
+	}
+	defaultLogger.alerter.addHook(hook)
+}
+
+// errorWindow 最近threshold条错误的时间（环形缓冲区）
+// 最早一条仍在窗口内时，说明窗口内的错误数已达到阈值
+type errorWindow struct {
+	times []time.Time
+	next  int
+	size  int
+}
+
+// add 记录一条错误，返回窗口内最早一条的时间及是否达到阈值
+func (w *errorWindow) add(now time.Time, window time.Duration) (time.Time, bool) {
+	w.times[w.next] = now
+	w.next = (w.next + 1) % len(w.times)
+	if w.size < len(w.times) {
+		w.size++
+	}
+	if w.size < len(w.times) {
+		return time.Time{}, false
+	}
+
+	oldest := w.times[w.next]
+	return oldest, now.Sub(oldest) <= window
+}
+
+// alerter 按类别统计错误率并触发告警
+type alerter struct {
+	service    string
+	window     time.Duration
+	cooldown   time.Duration
+	threshold  int
+	thresholds map[string]int
+
+	mu        sync.Mutex
+	windows   map[string]*errorWindow
+	lastFired map[string]time.Time
+	hooks     []AlertHook
+
+	pending sync.WaitGroup
+}
+
+// newAlerter 创建告警器，未启用时返回nil
+func newAlerter(cfg config.LogAlertConfig, service string) *alerter {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	a := &alerter{
+		service:    service,
+		window:     time.Duration(cfg.WindowSeconds) * time.Second,
+		cooldown:   time.Duration(cfg.CooldownSeconds) * time.Second,
+		threshold:  cfg.Threshold,
+		thresholds: cfg.Categories,
+		windows:    make(map[string]*errorWindow),
+		lastFired:  make(map[string]time.Time),
+	}
+	if cfg.Webhook.URL != "" {
+		a.hooks = append(a.hooks, newWebhookAlertHook(cfg.Webhook))
+	}
+	if cfg.Email.Host != "" {
+		a.hooks = append(a.hooks, newEmailAlertHook(cfg.Email))
+	}
+	return a
+}
+
+// addHook 添加告警发送目标
+func (a *alerter) addHook(hook AlertHook) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.hooks = append(a.hooks, hook)
+}
+
+// categoryOf 日志类别：模块名，未指定模块时为日志类型（security等），否则为app
+func categoryOf(module string, context map[string]interface{}) string {
+	if module != "" {
+		return module
+	}
+	if logType, ok := context["type"].(string); ok && logType != "" {
+		return logType
+	}
+	return "app"
+}
+
+// record 记录一条错误日志，达到阈值且不在冷却期内时触发告警
+func (a *alerter) record(category, message string, now time.Time) {
+	threshold := a.threshold
+	if value, ok := a.thresholds[category]; ok {
+		threshold = value
+	}
+	if threshold <= 0 {
+		return
+	}
+
+	a.mu.Lock()
+	w, ok := a.windows[category]
+	if !ok || len(w.times) != threshold {
+		w = &errorWindow{times: make([]time.Time, threshold)}
+		a.windows[category] = w
+	}
+	firstAt, exceeded := w.add(now, a.window)
+	if !exceeded || now.Sub(a.lastFired[category]) < a.cooldown {
+		a.mu.Unlock()
+		return
+	}
+	a.lastFired[category] = now
+	hooks := a.hooks
+	a.mu.Unlock()
+
+	a.send(hooks, &Alert{
+		Service:   a.service,
+		Category:  category,
+		Count:     threshold,
+		Window:    a.window,
+		FirstAt:   firstAt,
+		LastAt:    now,
+		Message:   message,
+		Threshold: threshold,
+	})
+}
+
+// fatal 致命错误不经过阈值和冷却期，立即告警并等待发送完成
+func (a *alerter) fatal(category, message string, now time.Time) {
+	a.mu.Lock()
+	hooks := a.hooks
+	a.mu.Unlock()
+
+	a.send(hooks, &Alert{
+		Service:  a.service,
+		Category: category,
+		Count:    1,
+		Window:   a.window,
+		FirstAt:  now,
+		LastAt:   now,
+		Message:  message,
+		Fatal:    true,
+	})
+	a.wait(fatalAlertWait)
+}
+
+// security 安全告警不经过阈值和冷却期，立即异步发送
+func (a *alerter) security(message string, now time.Time) {
+	a.mu.Lock()
+	hooks := a.hooks
+	a.mu.Unlock()
+
+	a.send(hooks, &Alert{
+		Service:  a.service,
+		Category: "security",
+		Count:    1,
+		Window:   a.window,
+		FirstAt:  now,
+		LastAt:   now,
+		Message:  message,
+		Security: true,
+	})
+}
+
+// send 异步发送告警
+// 发送失败只输出到标准日志，避免告警错误再次计入错误率
+func (a *alerter) send(hooks []AlertHook, alert *Alert) {
+	for _, hook := range hooks {
+		a.pending.Add(1)
+		go func(hook AlertHook) {
+			defer a.pending.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), fatalAlertWait)
+			defer cancel()
+			if err := hook.Fire(ctx, alert); err != nil {
+				log.Printf("[ALERT] failed to send alert %q: %v", alert.Title(), err)
+			}
+		}(hook)
+	}
+}
+
+// wait 等待发送中的告警完成，超时后返回
+func (a *alerter) wait(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		a.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// webhookAlertHook 以JSON POST发送告警
+type webhookAlertHook struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// newWebhookAlertHook 创建Webhook告警
+func newWebhookAlertHook(cfg config.LogAlertWebhook) *webhookAlertHook {
+	return &webhookAlertHook{
+		url:     cfg.URL,
+		headers: cfg.Headers,
+		client:  &http.Client{Timeout: time.Duration(cfg.TimeoutMs) * time.Millisecond},
+	}
+}
+
+// Fire 发送告警
+func (h *webhookAlertHook) Fire(ctx context.Context, alert *Alert) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"title":  alert.Title(),
+		"alert":  alert,
+		"window": alert.Window.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range h.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// emailAlertHook 通过SMTP发送告警邮件
+type emailAlertHook struct {
+	cfg config.LogAlertEmailConfig
+}
+
+// newEmailAlertHook 创建邮件告警
+func newEmailAlertHook(cfg config.LogAlertEmailConfig) *emailAlertHook {
+	return &emailAlertHook{cfg: cfg}
+}
+
+// Fire 发送告警
+func (h *emailAlertHook) Fire(ctx context.Context, alert *Alert) error {
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", h.cfg.From)
+	fmt.Fprintf(&body, "To: %s\r\n", strings.Join(h.cfg.To, ", "))
+	fmt.Fprintf(&body, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", alert.Title()))
+	body.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	fmt.Fprintf(&body, "服务: %s\r\n类别: %s\r\n", alert.Service, alert.Category)
+	if !alert.Fatal {
+		fmt.Fprintf(&body, "错误数: %d（阈值 %d，窗口 %s）\r\n", alert.Count, alert.Threshold, alert.Window)
+	}
+	fmt.Fprintf(&body, "首次: %s\r\n最近: %s\r\n最近一条错误: %s\r\n",
+		alert.FirstAt.Format(timestampLayout), alert.LastAt.Format(timestampLayout), alert.Message)
+
+	var auth smtp.Auth
+	if h.cfg.Username != "" {
+		auth = smtp.PlainAuth("", h.cfg.Username, h.cfg.Password, h.cfg.Host)
+	}
+
+	// smtp.SendMail不支持context，放在协程中执行以遵守超时
+	addr := net.JoinHostPort(h.cfg.Host, strconv.Itoa(h.cfg.Port))
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- smtp.SendMail(addr, auth, h.cfg.From, h.cfg.To, []byte(body.String()))
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,180 @@
+package logger
+
+import (
+	"bufio"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"exchange/internal/pkg/config"
+)
+
+// 队列满时的策略
+const (
+	OverflowDrop  = "drop"  // 丢弃日志（错误日志仍阻塞等待）
+	OverflowBlock = "block" // 阻塞等待队列空出
+)
+
+// asyncEntry 异步队列中的一条日志
+type asyncEntry struct {
+	record Record
+	access bool          // 是否为访问日志
+	flush  chan struct{} // 非空时表示刷新请求，处理完之前的日志后关闭
+}
+
+// asyncWriter 异步日志写入器
+// 日志先进入有界队列，由后台协程按批写入后端，每批结束后统一刷新缓冲区
+type asyncWriter struct {
+	queue         chan asyncEntry
+	batchSize     int
+	flushInterval time.Duration
+	overflow      string
+	write         func(entry asyncEntry)
+	flushBuffers  func()
+
+	mu      sync.RWMutex // 保护closed，防止向已关闭的队列发送
+	closed  bool
+	dropped atomic.Uint64
+	wg      sync.WaitGroup
+}
+
+// newAsyncWriter 创建并启动异步写入器
+func newAsyncWriter(cfg config.LogAsyncConfig, write func(entry asyncEntry), flushBuffers func()) *asyncWriter {
+	w := &asyncWriter{
+		queue:         make(chan asyncEntry, cfg.BufferSize),
+		batchSize:     cfg.BatchSize,
+		flushInterval: time.Duration(cfg.FlushIntervalMs) * time.Millisecond,
+		overflow:      cfg.Overflow,
+		write:         write,
+		flushBuffers:  flushBuffers,
+	}
+
+	w.wg.Add(1)
+	go w.run()
+	return w
+}
+
+// enqueue 将日志放入队列，返回false表示写入器已关闭，调用方应同步写入
+func (w *asyncWriter) enqueue(entry asyncEntry) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return false
+	}
+
+	if w.overflow == OverflowBlock || entry.record.Level >= ErrorLevel {
+		w.queue <- entry
+		return true
+	}
+
+	select {
+	case w.queue <- entry:
+	default:
+		w.dropped.Add(1)
+	}
+	return true
+}
+
+// run 后台写入协程
+func (w *asyncWriter) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	pending := 0
+	for {
+		select {
+		case entry, ok := <-w.queue:
+			if !ok {
+				w.flushBuffers()
+				return
+			}
+			if entry.flush != nil {
+				w.flushBuffers()
+				pending = 0
+				close(entry.flush)
+				continue
+			}
+
+			w.write(entry)
+			pending++
+			if pending >= w.batchSize {
+				w.flushBuffers()
+				pending = 0
+			}
+		case <-ticker.C:
+			if pending > 0 {
+				w.flushBuffers()
+				pending = 0
+			}
+		}
+	}
+}
+
+// Flush 等待队列中已有的日志全部写入
+func (w *asyncWriter) Flush() {
+	done := make(chan struct{})
+
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return
+	}
+	w.queue <- asyncEntry{flush: done}
+	w.mu.RUnlock()
+
+	<-done
+}
+
+// Close 停止接收新日志，写完队列中剩余日志后退出
+func (w *asyncWriter) Close() {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+
+	w.wg.Wait()
+}
+
+// Stats 返回异步写入统计
+func (w *asyncWriter) Stats() map[string]interface{} {
+	return map[string]interface{}{
+		"enabled":  true,
+		"queued":   len(w.queue),
+		"capacity": cap(w.queue),
+		"dropped":  w.dropped.Load(),
+		"overflow": w.overflow,
+	}
+}
+
+// bufferedWriter 带缓冲的写入器，异步模式下由后台协程按批刷新
+type bufferedWriter struct {
+	mu  sync.Mutex
+	buf *bufio.Writer
+}
+
+// newBufferedWriter 创建带缓冲的写入器
+func newBufferedWriter(w io.Writer) *bufferedWriter {
+	return &bufferedWriter{buf: bufio.NewWriterSize(w, 64*1024)}
+}
+
+// Write 写入缓冲区
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// Flush 将缓冲区写入底层输出
+func (b *bufferedWriter) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Flush()
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// AuditEvent 审计事件
+type AuditEvent struct {
+	Time    time.Time
+	Service string
+	Message string
+	Fields  map[string]interface{}
+}
+
+// AuditSink 审计事件持久化接口，WriteAudit不应阻塞调用方
+type AuditSink interface {
+	WriteAudit(event *AuditEvent)
+}
+
+var (
+	auditSink   AuditSink
+	auditSinkMu sync.RWMutex
+)
+
+// SetAuditSink 设置审计事件持久化目标，为nil时审计事件只写入日志文件
+func SetAuditSink(sink AuditSink) {
+	auditSinkMu.Lock()
+	defer auditSinkMu.Unlock()
+	auditSink = sink
+}
+
+// dispatchAudit 将审计事件交给持久化目标，字段复制一份，调用方之后修改不影响已提交的事件
+func dispatchAudit(message string, context map[string]interface{}) {
+	auditSinkMu.RLock()
+	sink := auditSink
+	auditSinkMu.RUnlock()
+	if sink == nil {
+		return
+	}
+
+	fields := make(map[string]interface{}, len(context))
+	for key, value := range context {
+		if key == "type" {
+			continue
+		}
+		fields[key] = value
+	}
+
+	service := "exchange"
+	if defaultLogger != nil {
+		service = defaultLogger.service
+	}
+
+	sink.WriteAudit(&AuditEvent{
+		Time:    time.Now(),
+		Service: service,
+		Message: message,
+		Fields:  fields,
+	})
+}
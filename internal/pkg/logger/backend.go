@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 时间格式
+const timestampLayout = "2006-01-02 15:04:05.000"
+
+// Record 交给后端输出的一条日志
+type Record struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Module  string // 模块名，通过Module()记录时非空
+	Context map[string]interface{}
+	File    string
+	Line    int
+}
+
+// Backend 日志后端接口
+// 级别过滤、采样和按级别分流由Logger完成，后端只负责编码并写入自己的输出
+type Backend interface {
+	// Log 输出一条日志
+	Log(record Record)
+	// Sync 刷新缓冲区
+	Sync() error
+}
+
+// BackendOptions 创建后端的参数
+type BackendOptions struct {
+	Format    string    // json, text
+	Service   string    // 服务名，写入每条日志
+	LevelName string    // 覆盖级别名称（如访问日志输出ACCESS），为空时使用级别本身
+	Output    io.Writer // 输出目标，需支持并发写入
+}
+
+// BackendFactory 后端构造函数
+type BackendFactory func(opts BackendOptions) (Backend, error)
+
+var (
+	backendFactories = map[string]BackendFactory{
+		"slog": newSlogBackend,
+		"zap":  newZapBackend,
+	}
+	backendMu sync.RWMutex
+)
+
+// RegisterBackend 注册自定义日志后端，需在Init之前调用
+func RegisterBackend(name string, factory BackendFactory) {
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	backendFactories[name] = factory
+}
+
+// Backends 返回已注册的后端名称
+func Backends() []string {
+	backendMu.RLock()
+	defer backendMu.RUnlock()
+
+	names := make([]string, 0, len(backendFactories))
+	for name := range backendFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newBackend 按名称创建后端，名称为空时使用slog
+func newBackend(name string, opts BackendOptions) (Backend, error) {
+	if name == "" {
+		name = "slog"
+	}
+
+	backendMu.RLock()
+	factory, ok := backendFactories[name]
+	backendMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown log backend: %s", name)
+	}
+
+	return factory(opts)
+}
+
+// levelName 返回后端输出的级别名称
+func (o BackendOptions) levelName(level Level) string {
+	if o.LevelName != "" {
+		return o.LevelName
+	}
+	return level.String()
+}
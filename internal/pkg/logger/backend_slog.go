@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// slogBackend 基于标准库log/slog的日志后端
+type slogBackend struct {
+	logger *slog.Logger
+	opts   BackendOptions
+}
+
+// newSlogBackend 创建slog后端
+func newSlogBackend(opts BackendOptions) (Backend, error) {
+	b := &slogBackend{opts: opts}
+
+	handlerOpts := &slog.HandlerOptions{
+		Level:       slog.LevelDebug, // 级别过滤由Logger完成
+		ReplaceAttr: b.replaceAttr,
+	}
+
+	var handler slog.Handler
+	if opts.Format == "json" {
+		handler = slog.NewJSONHandler(opts.Output, handlerOpts)
+	} else {
+		handler = slog.NewTextHandler(opts.Output, handlerOpts)
+	}
+
+	b.logger = slog.New(handler).With(slog.String("service", opts.Service))
+	return b, nil
+}
+
+// Log 输出一条日志
+func (b *slogBackend) Log(record Record) {
+	attrs := make([]slog.Attr, 0, 4)
+	if record.Module != "" {
+		attrs = append(attrs, slog.String("module", record.Module))
+	}
+	if len(record.Context) > 0 {
+		attrs = append(attrs, slog.Any("context", record.Context))
+	}
+	if record.File != "" {
+		attrs = append(attrs, slog.String("file", record.File), slog.Int("line", record.Line))
+	}
+
+	r := slog.NewRecord(record.Time, toSlogLevel(record.Level), record.Message, 0)
+	r.AddAttrs(attrs...)
+	_ = b.logger.Handler().Handle(context.Background(), r)
+}
+
+// Sync 刷新缓冲区，slog直接写入输出无需刷新
+func (b *slogBackend) Sync() error {
+	return nil
+}
+
+// replaceAttr 将slog内置字段转换为原有日志字段名和格式
+func (b *slogBackend) replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+
+	switch a.Key {
+	case slog.TimeKey:
+		return slog.String("timestamp", a.Value.Time().Format(timestampLayout))
+	case slog.LevelKey:
+		level, _ := a.Value.Any().(slog.Level)
+		return slog.String("level", b.opts.levelName(fromSlogLevel(level)))
+	case slog.MessageKey:
+		return slog.String("message", a.Value.String())
+	}
+	return a
+}
+
+// toSlogLevel 转换为slog级别
+func toSlogLevel(level Level) slog.Level {
+	switch level {
+	case DebugLevel:
+		return slog.LevelDebug
+	case WarnLevel:
+		return slog.LevelWarn
+	case ErrorLevel:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// fromSlogLevel 从slog级别转换
+func fromSlogLevel(level slog.Level) Level {
+	switch {
+	case level >= slog.LevelError:
+		return ErrorLevel
+	case level >= slog.LevelWarn:
+		return WarnLevel
+	case level >= slog.LevelInfo:
+		return InfoLevel
+	default:
+		return DebugLevel
+	}
+}
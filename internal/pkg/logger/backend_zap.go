@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// zapBackend 基于zap的日志后端
+type zapBackend struct {
+	core zapcore.Core
+}
+
+// newZapBackend 创建zap后端
+func newZapBackend(opts BackendOptions) (Backend, error) {
+	encoderCfg := zapcore.EncoderConfig{
+		TimeKey:        "timestamp",
+		LevelKey:       "level",
+		MessageKey:     "message",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeDuration: zapcore.StringDurationEncoder,
+		EncodeTime: func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendString(t.Format(timestampLayout))
+		},
+		EncodeLevel: func(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendString(opts.levelName(fromZapLevel(l)))
+		},
+	}
+
+	var encoder zapcore.Encoder
+	if opts.Format == "json" {
+		encoder = zapcore.NewJSONEncoder(encoderCfg)
+	} else {
+		encoder = zapcore.NewConsoleEncoder(encoderCfg)
+	}
+
+	// 级别过滤由Logger完成
+	core := zapcore.NewCore(encoder, zapcore.Lock(zapcore.AddSync(opts.Output)), zapcore.DebugLevel).
+		With([]zapcore.Field{zap.String("service", opts.Service)})
+
+	return &zapBackend{core: core}, nil
+}
+
+// Log 输出一条日志
+func (b *zapBackend) Log(record Record) {
+	fields := make([]zapcore.Field, 0, 4)
+	if record.Module != "" {
+		fields = append(fields, zap.String("module", record.Module))
+	}
+	if len(record.Context) > 0 {
+		fields = append(fields, zap.Any("context", record.Context))
+	}
+	if record.File != "" {
+		fields = append(fields, zap.String("file", record.File), zap.Int("line", record.Line))
+	}
+
+	_ = b.core.Write(zapcore.Entry{
+		Level:   toZapLevel(record.Level),
+		Time:    record.Time,
+		Message: record.Message,
+	}, fields)
+}
+
+// Sync 刷新缓冲区
+func (b *zapBackend) Sync() error {
+	return b.core.Sync()
+}
+
+// toZapLevel 转换为zap级别
+func toZapLevel(level Level) zapcore.Level {
+	switch level {
+	case DebugLevel:
+		return zapcore.DebugLevel
+	case WarnLevel:
+		return zapcore.WarnLevel
+	case ErrorLevel:
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}
+
+// fromZapLevel 从zap级别转换
+func fromZapLevel(level zapcore.Level) Level {
+	switch {
+	case level >= zapcore.ErrorLevel:
+		return ErrorLevel
+	case level >= zapcore.WarnLevel:
+		return WarnLevel
+	case level >= zapcore.InfoLevel:
+		return InfoLevel
+	default:
+		return DebugLevel
+	}
+}
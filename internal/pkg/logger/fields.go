@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"context"
+	"log"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"exchange/internal/pkg/requestid"
+)
+
+// With 创建绑定字段的子日志记录器，绑定的字段会合并到每条日志的上下文中
+// fields中的module字段作为模块名（与Module()相同，按模块级别过滤）；调用时传入的同名字段优先
+// 子日志记录器在写入时才使用默认日志记录器，可以在Init之前创建（如包级变量）
+func With(fields map[string]interface{}) *Logger {
+	return (&Logger{}).With(fields)
+}
+
+// With 在当前日志记录器的绑定字段基础上创建子日志记录器
+func (l *Logger) With(fields map[string]interface{}) *Logger {
+	merged := make(map[string]interface{}, len(l.fields)+len(fields))
+	for key, value := range l.fields {
+		merged[key] = value
+	}
+
+	module := l.module
+	for key, value := range fields {
+		if key == "module" {
+			if name, ok := value.(string); ok {
+				module = name
+				continue
+			}
+		}
+		merged[key] = value
+	}
+
+	return &Logger{
+		child:  true,
+		module: module,
+		fields: merged,
+	}
+}
+
+// With 创建绑定字段的模块子日志记录器
+func (ml *ModuleLogger) With(fields map[string]interface{}) *Logger {
+	return (&Logger{module: ml.name}).With(fields)
+}
+
+// Fields 返回绑定的字段（副本）
+func (l *Logger) Fields() map[string]interface{} {
+	fields := make(map[string]interface{}, len(l.fields))
+	for key, value := range l.fields {
+		fields[key] = value
+	}
+	return fields
+}
+
+// Debug 记录调试日志
+func (l *Logger) Debug(message string, context ...map[string]interface{}) {
+	l.output(DebugLevel, message, context)
+}
+
+// Info 记录信息日志
+func (l *Logger) Info(message string, context ...map[string]interface{}) {
+	l.output(InfoLevel, message, context)
+}
+
+// Warn 记录警告日志
+func (l *Logger) Warn(message string, context ...map[string]interface{}) {
+	l.output(WarnLevel, message, context)
+}
+
+// Error 记录错误日志
+func (l *Logger) Error(message string, context ...map[string]interface{}) {
+	l.output(ErrorLevel, message, context)
+}
+
+// output 合并绑定字段后写入
+func (l *Logger) output(level Level, message string, context []map[string]interface{}) {
+	target := l
+	if l.child {
+		target = defaultLogger
+	}
+	if target == nil {
+		log.Printf("[%s] %s", level, message)
+		return
+	}
+
+	target.logModule(l.module, level, message, l.mergeFields(context))
+}
+
+// mergeFields 合并绑定字段和调用时传入的字段，没有绑定字段时直接使用传入的字段
+func (l *Logger) mergeFields(context []map[string]interface{}) map[string]interface{} {
+	var ctx map[string]interface{}
+	if len(context) > 0 {
+		ctx = context[0]
+	}
+	if len(l.fields) == 0 {
+		return ctx
+	}
+	if len(ctx) == 0 {
+		return l.fields
+	}
+
+	merged := make(map[string]interface{}, len(l.fields)+len(ctx))
+	for key, value := range l.fields {
+		merged[key] = value
+	}
+	for key, value := range ctx {
+		merged[key] = value
+	}
+	return merged
+}
+
+// FromContext 创建绑定上下文中请求ID的子日志记录器，上下文中没有请求ID时不绑定
+func FromContext(ctx context.Context) *Logger {
+	return (&Logger{}).WithContext(ctx)
+}
+
+// WithContext 在当前日志记录器的绑定字段基础上绑定上下文中的请求ID，以及正在记录的span的trace_id和span_id
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	fields := make(map[string]interface{}, 3)
+	if id := requestid.FromContext(ctx); id != "" {
+		fields[requestid.ContextKey] = id
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields["trace_id"] = sc.TraceID().String()
+		fields["span_id"] = sc.SpanID().String()
+	}
+	if len(fields) == 0 {
+		return l.With(nil)
+	}
+	return l.With(fields)
+}
+
+// WithContext 创建绑定上下文中请求ID的模块子日志记录器
+func (ml *ModuleLogger) WithContext(ctx context.Context) *Logger {
+	return (&Logger{module: ml.name}).WithContext(ctx)
+}
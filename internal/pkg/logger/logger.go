@@ -2,7 +2,6 @@ package logger
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -11,10 +10,9 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"gopkg.in/natefinch/lumberjack.v2"
-
 	"exchange/internal/pkg/config"
 )
 
@@ -44,29 +42,36 @@ func (l Level) String() string {
 	}
 }
 
-// LogEntry 日志条目
-type LogEntry struct {
-	Timestamp string                 `json:"timestamp"`
-	Level     string                 `json:"level"`
-	Message   string                 `json:"message"`
-	Service   string                 `json:"service"`
-	Context   map[string]interface{} `json:"context,omitempty"`
-	File      string                 `json:"file,omitempty"`
-	Line      int                    `json:"line,omitempty"`
-}
-
 // Logger 日志记录器
+// 负责级别过滤、采样以及按级别分流到通用/错误/访问日志，具体编码和写入交给Backend
+// 启用异步模式后，日志先进入有界队列，由后台协程按批写入
 type Logger struct {
-	level         Level
-	format        string
-	outputs       []io.Writer
-	service       string
-	accessLogger  *lumberjack.Logger
-	errorLogger   *lumberjack.Logger
-	generalLogger *lumberjack.Logger
+	level       atomic.Int32
+	modules     moduleLevels // 按模块覆盖的日志级别
+	service     string
+	backendName string
+	sampler     *sampler
+	redactor    *redactor // 敏感信息脱敏，未启用时为nil
+
+	backend       Backend // 控制台 + 通用日志文件
+	errorBackend  Backend // 错误日志文件
+	accessBackend Backend // 访问日志文件 + 控制台
+
+	generalWriter *dailyWriter
+	accessWriter  *dailyWriter
+	errorWriter   *dailyWriter
 	cleanupMgr    *LogCleanupManager
-	currentDate   string // 当前日期，用于跟踪日期变化
-	mu            sync.RWMutex
+
+	async   *asyncWriter      // 异步写入器，未启用时为nil
+	otlp    *otlpExporter     // OTLP导出器，未启用时为nil
+	alerter *alerter          // 错误率告警，未启用时为nil
+	sinks   []*sinkWriter     // 集中日志输出（kafka、elasticsearch）
+	buffers []*bufferedWriter // 异步模式下各后端输出的缓冲区
+
+	// With创建的子日志记录器只使用以下字段，写入时交给默认日志记录器
+	child  bool
+	module string
+	fields map[string]interface{}
 }
 
 var (
@@ -85,8 +90,6 @@ func Init(cfg *config.LogConfig) error {
 
 // initLogger 初始化日志记录器
 func initLogger(cfg *config.LogConfig) error {
-	level := parseLevel(cfg.Level)
-
 	// 创建日志目录
 	if cfg.EnableFile && cfg.LogDir != "" {
 		if err := os.MkdirAll(cfg.LogDir, 0755); err != nil {
@@ -95,57 +98,68 @@ func initLogger(cfg *config.LogConfig) error {
 	}
 
 	logger := &Logger{
-		level:   level,
-		format:  cfg.Format,
-		service: "exchange",
-		outputs: make([]io.Writer, 0),
+		service:     "exchange",
+		backendName: cfg.Backend,
+		sampler:     newSampler(cfg.Sampling),
+		redactor:    newRedactor(cfg.Redact),
 	}
+	logger.level.Store(int32(parseLevel(cfg.Level)))
+	logger.modules.init(cfg.Modules)
+
+	var outputs, accessOutputs []io.Writer
 
 	// 添加控制台输出
 	if cfg.EnableConsole {
-		logger.outputs = append(logger.outputs, os.Stdout)
+		outputs = append(outputs, os.Stdout)
+		accessOutputs = append(accessOutputs, os.Stdout)
 	}
 
-	// 添加文件输出
+	// 添加文件输出（按天切换文件）
 	if cfg.EnableFile && cfg.LogDir != "" {
-		// 生成按天的日志文件名
-		today := time.Now().Format("2006-01-02")
-		logger.currentDate = today // 初始化当前日期
-
 		// 通用日志文件
 		if cfg.Filename != "" {
-			generalLogFile := filepath.Join(cfg.LogDir, fmt.Sprintf("%s_%s.log", strings.TrimSuffix(cfg.Filename, ".log"), today))
-			logger.generalLogger = &lumberjack.Logger{
-				Filename:   generalLogFile,
-				MaxSize:    cfg.MaxSize, // MB
-				MaxBackups: cfg.MaxBackups,
-				MaxAge:     cfg.MaxAge, // days
-				Compress:   cfg.Compress,
-			}
-			logger.outputs = append(logger.outputs, logger.generalLogger)
+			logger.generalWriter = newDailyWriter(cfg, cfg.Filename)
+			outputs = append(outputs, logger.generalWriter)
 		}
 
 		// 访问日志文件
-		accessLogFile := filepath.Join(cfg.LogDir, fmt.Sprintf("access_%s.log", today))
-		logger.accessLogger = &lumberjack.Logger{
-			Filename:   accessLogFile,
-			MaxSize:    cfg.MaxSize,
-			MaxBackups: cfg.MaxBackups,
-			MaxAge:     cfg.MaxAge,
-			Compress:   cfg.Compress,
-		}
+		logger.accessWriter = newDailyWriter(cfg, "access")
+		accessOutputs = append(accessOutputs, logger.accessWriter)
 
 		// 错误日志文件
-		errorLogFile := filepath.Join(cfg.LogDir, fmt.Sprintf("error_%s.log", today))
-		logger.errorLogger = &lumberjack.Logger{
-			Filename:   errorLogFile,
-			MaxSize:    cfg.MaxSize,
-			MaxBackups: cfg.MaxBackups,
-			MaxAge:     cfg.MaxAge,
-			Compress:   cfg.Compress,
+		logger.errorWriter = newDailyWriter(cfg, "error")
+	}
+
+	// 添加集中日志输出，通用日志和访问日志都会发送
+	sinks, err := newSinkWriters(cfg.Outputs)
+	if err != nil {
+		return err
+	}
+	logger.sinks = sinks
+	for _, sink := range sinks {
+		outputs = append(outputs, sink)
+		accessOutputs = append(accessOutputs, sink)
+	}
+
+	if logger.backend, err = logger.newBackend(cfg, "", outputs...); err != nil {
+		return err
+	}
+	if logger.accessBackend, err = logger.newBackend(cfg, "ACCESS", accessOutputs...); err != nil {
+		return err
+	}
+	if logger.errorWriter != nil {
+		if logger.errorBackend, err = logger.newBackend(cfg, "", logger.errorWriter); err != nil {
+			return err
 		}
 	}
 
+	logger.otlp = newOTLPExporter(cfg.OTLP, logger.service)
+	logger.alerter = newAlerter(cfg.Alert, logger.service)
+
+	if cfg.Async.Enabled {
+		logger.async = newAsyncWriter(cfg.Async, logger.write, logger.flushBuffers)
+	}
+
 	defaultLogger = logger
 
 	// 清理任务已注册到定时任务系统中，每天凌晨2点自动执行
@@ -155,160 +169,171 @@ func initLogger(cfg *config.LogConfig) error {
 	}
 
 	Info("Logger initialized with daily rotation", map[string]interface{}{
-		"log_dir": cfg.LogDir,
-		"level":   cfg.Level,
-		"format":  cfg.Format,
+		"log_dir":  cfg.LogDir,
+		"level":    cfg.Level,
+		"format":   cfg.Format,
+		"backend":  cfg.Backend,
+		"sampling": cfg.Sampling.Enabled,
+		"async":    cfg.Async.Enabled,
+		"modules":  cfg.Modules,
+		"redact":   cfg.Redact.Enabled,
+		"otlp":     cfg.OTLP.Enabled,
+		"outputs":  len(cfg.Outputs),
 	})
 
 	return nil
 }
 
-// parseLevel 解析日志级别
+// newBackend 创建写入指定输出的后端
+func (l *Logger) newBackend(cfg *config.LogConfig, levelName string, outputs ...io.Writer) (Backend, error) {
+	var output io.Writer = io.Discard
+	if len(outputs) == 1 {
+		output = outputs[0]
+	} else if len(outputs) > 1 {
+		output = io.MultiWriter(outputs...)
+	}
+
+	// 异步模式下输出先写入缓冲区，由后台协程按批刷新
+	if cfg.Async.Enabled && len(outputs) > 0 {
+		buffered := newBufferedWriter(output)
+		l.buffers = append(l.buffers, buffered)
+		output = buffered
+	}
+
+	return newBackend(l.backendName, BackendOptions{
+		Format:    cfg.Format,
+		Service:   l.service,
+		LevelName: levelName,
+		Output:    output,
+	})
+}
+
+// parseLevel 解析日志级别，无法识别时为InfoLevel
 func parseLevel(levelStr string) Level {
+	level, err := ParseLevel(levelStr)
+	if err != nil {
+		return InfoLevel
+	}
+	return level
+}
+
+// ParseLevel 解析日志级别
+func ParseLevel(levelStr string) (Level, error) {
 	switch strings.ToLower(levelStr) {
 	case "debug":
-		return DebugLevel
+		return DebugLevel, nil
 	case "info":
-		return InfoLevel
+		return InfoLevel, nil
 	case "warn", "warning":
-		return WarnLevel
+		return WarnLevel, nil
 	case "error":
-		return ErrorLevel
+		return ErrorLevel, nil
 	default:
-		return InfoLevel
+		return InfoLevel, fmt.Errorf("unknown log level: %s", levelStr)
 	}
 }
 
-// getDailyLogFile 获取当天的日志文件名
-func (l *Logger) getDailyLogFile(baseName string) string {
-	today := time.Now().Format("2006-01-02")
-	return fmt.Sprintf("%s_%s.log", strings.TrimSuffix(baseName, ".log"), today)
-}
-
-// updateLogFiles 更新日志文件为当天的文件
-func (l *Logger) updateLogFiles() {
-	today := time.Now().Format("2006-01-02")
-
-	// 检查日期是否变化
-	if l.currentDate == today {
-		return
-	}
-	l.currentDate = today
-
-	// 更新通用日志文件
-	if l.generalLogger != nil {
-		newFilename := filepath.Join(filepath.Dir(l.generalLogger.Filename), l.getDailyLogFile(filepath.Base(l.generalLogger.Filename)))
-		if l.generalLogger.Filename != newFilename {
-			l.generalLogger.Filename = newFilename
-		}
-	}
-
-	// 更新访问日志文件
-	if l.accessLogger != nil {
-		newFilename := filepath.Join(filepath.Dir(l.accessLogger.Filename), fmt.Sprintf("access_%s.log", today))
-		if l.accessLogger.Filename != newFilename {
-			l.accessLogger.Filename = newFilename
-		}
-	}
-
-	// 更新错误日志文件
-	if l.errorLogger != nil {
-		newFilename := filepath.Join(filepath.Dir(l.errorLogger.Filename), fmt.Sprintf("error_%s.log", today))
-		if l.errorLogger.Filename != newFilename {
-			l.errorLogger.Filename = newFilename
+// enabled 判断级别是否需要记录，模块设置了级别时以模块级别为准
+func (l *Logger) enabled(module string, level Level) bool {
+	if module != "" {
+		if moduleLevel, ok := l.modules.get(module); ok {
+			return level >= moduleLevel
 		}
 	}
+	return level >= Level(l.level.Load())
 }
 
 // log 记录日志
 func (l *Logger) log(level Level, message string, context map[string]interface{}) {
+	l.logModule("", level, message, context)
+}
+
+// logModule 记录指定模块的日志
+func (l *Logger) logModule(module string, level Level, message string, context map[string]interface{}) {
 	// 检查日志级别
-	if level < l.level {
+	if !l.enabled(module, level) {
 		return
 	}
 
-	l.mu.Lock()
-	defer l.mu.Unlock()
+	now := time.Now()
 
-	// 检查是否需要更新日志文件（每天更新一次）
-	l.updateLogFiles()
-
-	entry := LogEntry{
-		Timestamp: time.Now().Format("2006-01-02 15:04:05.000"),
-		Level:     level.String(),
-		Message:   message,
-		Service:   l.service,
-		Context:   context,
+	// 错误率统计在采样之前，被采样丢弃的错误也计入
+	if level >= ErrorLevel && l.alerter != nil {
+		l.alerter.record(categoryOf(module, context), message, now)
 	}
 
-	// 添加调用位置信息
-	if _, file, line, ok := runtime.Caller(2); ok {
-		entry.File = filepath.Base(file)
-		entry.Line = line
+	if !l.sampler.allow(level, message, now) {
+		return
 	}
 
-	var output string
-	if l.format == "json" {
-		data, _ := json.Marshal(entry)
-		output = string(data)
-	} else {
-		output = l.formatText(entry)
+	record := Record{
+		Time:    now,
+		Level:   level,
+		Message: message,
+		Module:  module,
+		Context: l.redactor.redact(context),
 	}
 
-	// 写入到所有输出
-	for _, writer := range l.outputs {
-		fmt.Fprintln(writer, output)
+	// 添加调用位置信息
+	if _, file, line, ok := runtime.Caller(3); ok {
+		record.File = filepath.Base(file)
+		record.Line = line
 	}
 
-	// 根据日志级别写入到特定文件
-	if level >= ErrorLevel && l.errorLogger != nil {
-		fmt.Fprintln(l.errorLogger, output)
-	}
+	l.dispatch(asyncEntry{record: record})
 }
 
 // logAccess 记录访问日志
-func (l *Logger) logAccess(entry LogEntry) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
+func (l *Logger) logAccess(message string, context map[string]interface{}) {
+	l.dispatch(asyncEntry{
+		record: Record{
+			Time:    time.Now(),
+			Level:   InfoLevel,
+			Message: message,
+			Context: l.redactor.redact(context),
+		},
+		access: true,
+	})
+}
 
-	// 检查是否需要更新日志文件
-	l.updateLogFiles()
+// dispatch 异步模式下放入队列，否则直接写入
+func (l *Logger) dispatch(entry asyncEntry) {
+	if l.async != nil && l.async.enqueue(entry) {
+		return
+	}
 
-	var output string
-	if l.format == "json" {
-		data, _ := json.Marshal(entry)
-		output = string(data)
-	} else {
-		output = l.formatText(entry)
+	l.write(entry)
+
+	// 异步写入器关闭后仍有日志写入时，直接刷新缓冲区
+	if l.async != nil {
+		l.flushBuffers()
 	}
+}
 
-	// 写入访问日志文件
-	if l.accessLogger != nil {
-		fmt.Fprintln(l.accessLogger, output)
+// write 将日志写入对应的后端
+func (l *Logger) write(entry asyncEntry) {
+	if l.otlp != nil {
+		l.otlp.export(entry.record, entry.access)
 	}
 
-	// 如果启用控制台输出，也写入控制台
-	for _, writer := range l.outputs {
-		if writer == os.Stdout || writer == os.Stderr {
-			fmt.Fprintln(writer, output)
-		}
+	if entry.access {
+		l.accessBackend.Log(entry.record)
+		return
 	}
-}
 
-// formatText 格式化文本日志
-func (l *Logger) formatText(entry LogEntry) string {
-	var contextStr string
-	if len(entry.Context) > 0 {
-		contextData, _ := json.Marshal(entry.Context)
-		contextStr = fmt.Sprintf(" context=%s", string(contextData))
+	l.backend.Log(entry.record)
+
+	// 根据日志级别写入到特定文件
+	if entry.record.Level >= ErrorLevel && l.errorBackend != nil {
+		l.errorBackend.Log(entry.record)
 	}
+}
 
-	return fmt.Sprintf("[%s] %s %s%s",
-		entry.Timestamp,
-		entry.Level,
-		entry.Message,
-		contextStr,
-	)
+// flushBuffers 刷新异步模式下的输出缓冲区
+func (l *Logger) flushBuffers() {
+	for _, buffered := range l.buffers {
+		_ = buffered.Flush()
+	}
 }
 
 // Debug 记录调试日志
@@ -378,15 +403,7 @@ func Access(message string, context map[string]interface{}) {
 		return
 	}
 
-	entry := LogEntry{
-		Timestamp: time.Now().Format(time.RFC3339),
-		Level:     "ACCESS",
-		Message:   message,
-		Service:   defaultLogger.service,
-		Context:   context,
-	}
-
-	defaultLogger.logAccess(entry)
+	defaultLogger.logAccess(message, context)
 }
 
 // Performance 记录性能日志
@@ -419,8 +436,28 @@ func Security(message string, context map[string]interface{}) {
 	defaultLogger.log(WarnLevel, message, context)
 }
 
-// Audit 记录审计日志
+// SecurityAlert 记录安全日志并立即发送安全告警（不受错误率阈值和冷却期限制），未启用告警时只记录日志
+func SecurityAlert(message string, context map[string]interface{}) {
+	if defaultLogger == nil {
+		log.Printf("[SECURITY] %s", message)
+		return
+	}
+
+	if context == nil {
+		context = make(map[string]interface{})
+	}
+	context["type"] = "security"
+
+	defaultLogger.log(WarnLevel, message, context)
+	if defaultLogger.alerter != nil {
+		defaultLogger.alerter.security(message, time.Now())
+	}
+}
+
+// Audit 记录审计日志，设置了持久化目标（SetAuditSink）时同时写入审计存储
 func Audit(message string, context map[string]interface{}) {
+	dispatchAudit(message, context)
+
 	if defaultLogger == nil {
 		log.Printf("[AUDIT] %s", message)
 		return
@@ -443,6 +480,11 @@ func Fatal(message string, context ...map[string]interface{}) {
 
 	if defaultLogger != nil {
 		defaultLogger.log(ErrorLevel, message, ctx)
+		// 退出前确保异步队列中的日志已写入
+		_ = Flush()
+		if defaultLogger.alerter != nil {
+			defaultLogger.alerter.fatal(categoryOf("", ctx), message, time.Now())
+		}
 	} else {
 		log.Printf("[FATAL] %s", message)
 	}
@@ -456,25 +498,22 @@ func Rotate() error {
 		return fmt.Errorf("logger not initialized")
 	}
 
-	defaultLogger.mu.Lock()
-	defer defaultLogger.mu.Unlock()
-
 	var errs []error
 
-	if defaultLogger.generalLogger != nil {
-		if err := defaultLogger.generalLogger.Rotate(); err != nil {
+	if defaultLogger.generalWriter != nil {
+		if err := defaultLogger.generalWriter.Rotate(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to rotate general log: %w", err))
 		}
 	}
 
-	if defaultLogger.accessLogger != nil {
-		if err := defaultLogger.accessLogger.Rotate(); err != nil {
+	if defaultLogger.accessWriter != nil {
+		if err := defaultLogger.accessWriter.Rotate(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to rotate access log: %w", err))
 		}
 	}
 
-	if defaultLogger.errorLogger != nil {
-		if err := defaultLogger.errorLogger.Rotate(); err != nil {
+	if defaultLogger.errorWriter != nil {
+		if err := defaultLogger.errorWriter.Rotate(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to rotate error log: %w", err))
 		}
 	}
@@ -492,12 +531,31 @@ func Flush() error {
 		return nil
 	}
 
-	defaultLogger.mu.Lock()
-	defer defaultLogger.mu.Unlock()
+	if defaultLogger.async != nil {
+		defaultLogger.async.Flush()
+	}
+	if defaultLogger.otlp != nil {
+		defaultLogger.otlp.Flush()
+	}
+
+	var errs []error
+	for _, backend := range []Backend{defaultLogger.backend, defaultLogger.errorBackend, defaultLogger.accessBackend} {
+		if backend == nil {
+			continue
+		}
+		if err := backend.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, sink := range defaultLogger.sinks {
+		if err := sink.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 
-	// 对于lumberjack，我们可以通过写入一个空字符串来强制刷新
-	// 但这会在日志中留下空行，所以我们不这样做
-	// 相反，我们依赖于lumberjack的内部缓冲机制
+	if len(errs) > 0 {
+		return fmt.Errorf("log flush errors: %v", errs)
+	}
 
 	return nil
 }
@@ -508,25 +566,40 @@ func Close() error {
 		return nil
 	}
 
-	defaultLogger.mu.Lock()
-	defer defaultLogger.mu.Unlock()
+	// 先写完异步队列并刷新后端缓冲区，再关闭文件；控制台的Sync错误忽略
+	if defaultLogger.async != nil {
+		defaultLogger.async.Close()
+	}
+	_ = Flush()
+	if defaultLogger.otlp != nil {
+		defaultLogger.otlp.Close()
+	}
+	if defaultLogger.alerter != nil {
+		defaultLogger.alerter.wait(fatalAlertWait)
+	}
 
 	var errs []error
 
-	if defaultLogger.generalLogger != nil {
-		if err := defaultLogger.generalLogger.Close(); err != nil {
+	for _, sink := range defaultLogger.sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close log output %s: %w", sink.sender.name(), err))
+		}
+	}
+
+	if defaultLogger.generalWriter != nil {
+		if err := defaultLogger.generalWriter.Close(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to close general log: %w", err))
 		}
 	}
 
-	if defaultLogger.accessLogger != nil {
-		if err := defaultLogger.accessLogger.Close(); err != nil {
+	if defaultLogger.accessWriter != nil {
+		if err := defaultLogger.accessWriter.Close(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to close access log: %w", err))
 		}
 	}
 
-	if defaultLogger.errorLogger != nil {
-		if err := defaultLogger.errorLogger.Close(); err != nil {
+	if defaultLogger.errorWriter != nil {
+		if err := defaultLogger.errorWriter.Close(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to close error log: %w", err))
 		}
 	}
@@ -546,12 +619,18 @@ func GetLogger() *Logger {
 // SetLevel 设置日志级别
 func SetLevel(level Level) {
 	if defaultLogger != nil {
-		defaultLogger.mu.Lock()
-		defaultLogger.level = level
-		defaultLogger.mu.Unlock()
+		defaultLogger.level.Store(int32(level))
 	}
 }
 
+// GetLevel 获取全局日志级别
+func GetLevel() Level {
+	if defaultLogger == nil {
+		return InfoLevel
+	}
+	return Level(defaultLogger.level.Load())
+}
+
 // WithContext 创建带上下文的日志记录器
 func WithContext(ctx context.Context) *ContextLogger {
 	return &ContextLogger{
@@ -601,7 +680,33 @@ func GetLogStats() (map[string]interface{}, error) {
 		return nil, fmt.Errorf("logger or cleanup manager not initialized")
 	}
 
-	return defaultLogger.cleanupMgr.GetLogStats()
+	stats, err := defaultLogger.cleanupMgr.GetLogStats()
+	if err != nil {
+		return nil, err
+	}
+
+	// 异步写入统计（队列长度、丢弃条数）
+	if defaultLogger.async != nil {
+		stats["async"] = defaultLogger.async.Stats()
+	} else {
+		stats["async"] = map[string]interface{}{"enabled": false}
+	}
+
+	// OTLP导出统计（已发送、丢弃、失败条数）
+	if defaultLogger.otlp != nil {
+		stats["otlp"] = defaultLogger.otlp.Stats()
+	} else {
+		stats["otlp"] = map[string]interface{}{"enabled": false}
+	}
+
+	// 集中日志输出统计
+	outputStats := make([]map[string]interface{}, 0, len(defaultLogger.sinks))
+	for _, sink := range defaultLogger.sinks {
+		outputStats = append(outputStats, sink.Stats())
+	}
+	stats["outputs"] = outputStats
+
+	return stats, nil
 }
 
 // StartCleanupScheduler 启动清理调度器（如果还没有启动）
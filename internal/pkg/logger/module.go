@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"log"
+	"sort"
+	"sync"
+)
+
+// moduleLevels 按模块覆盖的日志级别
+// configured为配置文件中的级别，overrides为运行时设置的级别（优先），重置后恢复为配置文件中的级别
+type moduleLevels struct {
+	mu         sync.RWMutex
+	configured map[string]Level
+	overrides  map[string]Level
+}
+
+// init 加载配置文件中的模块级别，无法识别的级别按info处理
+func (m *moduleLevels) init(modules map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.configured = make(map[string]Level, len(modules))
+	m.overrides = make(map[string]Level)
+	for module, level := range modules {
+		m.configured[module] = parseLevel(level)
+	}
+}
+
+// get 获取模块的日志级别，未设置时返回false
+func (m *moduleLevels) get(module string) (Level, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if level, ok := m.overrides[module]; ok {
+		return level, true
+	}
+	level, ok := m.configured[module]
+	return level, ok
+}
+
+// set 运行时设置模块级别
+func (m *moduleLevels) set(module string, level Level) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.overrides[module] = level
+}
+
+// reset 清除运行时设置的模块级别
+func (m *moduleLevels) reset(module string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.overrides, module)
+}
+
+// snapshot 返回所有模块当前生效的级别
+func (m *moduleLevels) snapshot() map[string]Level {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	levels := make(map[string]Level, len(m.configured)+len(m.overrides))
+	for module, level := range m.configured {
+		levels[module] = level
+	}
+	for module, level := range m.overrides {
+		levels[module] = level
+	}
+	return levels
+}
+
+// ModuleLogger 模块日志记录器
+// 日志带module字段，级别以模块设置为准（未设置时使用全局级别）
+type ModuleLogger struct {
+	name string
+}
+
+// Module 获取指定模块的日志记录器
+func Module(name string) *ModuleLogger {
+	return &ModuleLogger{name: name}
+}
+
+// Name 模块名
+func (ml *ModuleLogger) Name() string {
+	return ml.name
+}
+
+// Debug 记录调试日志
+func (ml *ModuleLogger) Debug(message string, context ...map[string]interface{}) {
+	ml.log(DebugLevel, message, context)
+}
+
+// Info 记录信息日志
+func (ml *ModuleLogger) Info(message string, context ...map[string]interface{}) {
+	ml.log(InfoLevel, message, context)
+}
+
+// Warn 记录警告日志
+func (ml *ModuleLogger) Warn(message string, context ...map[string]interface{}) {
+	ml.log(WarnLevel, message, context)
+}
+
+// Error 记录错误日志
+func (ml *ModuleLogger) Error(message string, context ...map[string]interface{}) {
+	ml.log(ErrorLevel, message, context)
+}
+
+// log 记录日志
+func (ml *ModuleLogger) log(level Level, message string, context []map[string]interface{}) {
+	if defaultLogger == nil {
+		log.Printf("[%s] [%s] %s", level, ml.name, message)
+		return
+	}
+
+	var ctx map[string]interface{}
+	if len(context) > 0 {
+		ctx = context[0]
+	}
+
+	defaultLogger.logModule(ml.name, level, message, ctx)
+}
+
+// SetModuleLevel 运行时设置模块日志级别
+func SetModuleLevel(module string, level Level) {
+	if defaultLogger != nil {
+		defaultLogger.modules.set(module, level)
+	}
+}
+
+// ResetModuleLevel 清除运行时设置的模块日志级别，恢复为配置文件中的级别（未配置时使用全局级别）
+func ResetModuleLevel(module string) {
+	if defaultLogger != nil {
+		defaultLogger.modules.reset(module)
+	}
+}
+
+// ModuleLevels 获取所有已设置级别的模块及其当前生效的级别
+func ModuleLevels() map[string]Level {
+	if defaultLogger == nil {
+		return map[string]Level{}
+	}
+	return defaultLogger.modules.snapshot()
+}
+
+// ModuleNames 获取所有已设置级别的模块名（已排序）
+func ModuleNames() []string {
+	levels := ModuleLevels()
+	names := make([]string, 0, len(levels))
+	for name := range levels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,406 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"exchange/internal/pkg/config"
+)
+
+// otlpScopeName 导出日志的instrumentation scope
+const otlpScopeName = "exchange/internal/pkg/logger"
+
+// otlpExporter OTLP日志导出器
+// 以OTLP/HTTP JSON协议将日志批量发送到Collector（POST {endpoint}），与控制台/文件输出并行
+// 队列满时丢弃并计数，发送失败只输出到stderr，不影响业务和本地日志
+type otlpExporter struct {
+	endpoint      string
+	headers       map[string]string
+	client        *http.Client
+	batchSize     int
+	flushInterval time.Duration
+	resource      []otlpKeyValue
+
+	queue chan asyncEntry
+
+	mu       sync.RWMutex // 保护closed，防止向已关闭的队列发送
+	closed   bool
+	wg       sync.WaitGroup
+	exported atomic.Uint64
+	dropped  atomic.Uint64
+	failed   atomic.Uint64
+}
+
+// newOTLPExporter 根据配置创建并启动导出器，未启用时返回nil
+func newOTLPExporter(cfg config.LogOTLPConfig, service string) *otlpExporter {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	e := &otlpExporter{
+		endpoint:      cfg.Endpoint,
+		headers:       cfg.Headers,
+		client:        &http.Client{Timeout: time.Duration(cfg.TimeoutMs) * time.Millisecond},
+		batchSize:     cfg.BatchSize,
+		flushInterval: time.Duration(cfg.FlushIntervalMs) * time.Millisecond,
+		queue:         make(chan asyncEntry, cfg.QueueSize),
+	}
+
+	// 资源属性，按键排序保证输出稳定
+	attributes := map[string]string{"service.name": service}
+	for key, value := range cfg.ResourceAttributes {
+		attributes[key] = value
+	}
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		e.resource = append(e.resource, otlpKeyValue{Key: key, Value: otlpString(attributes[key])})
+	}
+
+	e.wg.Add(1)
+	go e.run()
+	return e
+}
+
+// export 将日志放入导出队列，队列满时丢弃
+func (e *otlpExporter) export(record Record, access bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closed {
+		return
+	}
+
+	select {
+	case e.queue <- asyncEntry{record: record, access: access}:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// Flush 发送队列中已有的日志
+func (e *otlpExporter) Flush() {
+	e.mu.RLock()
+	if e.closed {
+		e.mu.RUnlock()
+		return
+	}
+	done := make(chan struct{})
+	e.queue <- asyncEntry{flush: done}
+	e.mu.RUnlock()
+
+	<-done
+}
+
+// Close 发送剩余日志后停止
+func (e *otlpExporter) Close() {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return
+	}
+	e.closed = true
+	close(e.queue)
+	e.mu.Unlock()
+
+	e.wg.Wait()
+}
+
+// Stats 导出统计
+func (e *otlpExporter) Stats() map[string]interface{} {
+	return map[string]interface{}{
+		"enabled":  true,
+		"endpoint": e.endpoint,
+		"queued":   len(e.queue),
+		"exported": e.exported.Load(),
+		"dropped":  e.dropped.Load(),
+		"failed":   e.failed.Load(),
+	}
+}
+
+// run 后台发送协程，攒满batchSize条或到达flushInterval时发送一批
+func (e *otlpExporter) run() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]asyncEntry, 0, e.batchSize)
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		e.send(batch)
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case entry, ok := <-e.queue:
+			if !ok {
+				send()
+				return
+			}
+			if entry.flush != nil {
+				send()
+				close(entry.flush)
+				continue
+			}
+			batch = append(batch, entry)
+			if len(batch) >= e.batchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		}
+	}
+}
+
+// send 发送一批日志
+func (e *otlpExporter) send(batch []asyncEntry) {
+	body, err := json.Marshal(e.encode(batch))
+	if err != nil {
+		e.fail(len(batch), fmt.Errorf("failed to encode otlp logs: %w", err))
+		return
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		e.fail(len(batch), err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		e.fail(len(batch), err)
+		return
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		e.fail(len(batch), fmt.Errorf("otlp collector returned status %d", resp.StatusCode))
+		return
+	}
+
+	e.exported.Add(uint64(len(batch)))
+}
+
+// fail 记录发送失败，不能写入日志本身，否则会再次进入导出队列
+func (e *otlpExporter) fail(count int, err error) {
+	e.failed.Add(uint64(count))
+	fmt.Fprintf(os.Stderr, "otlp log export failed (%d records): %v\n", count, err)
+}
+
+// encode 编码为OTLP ExportLogsServiceRequest
+func (e *otlpExporter) encode(batch []asyncEntry) otlpLogsRequest {
+	records := make([]otlpLogRecord, 0, len(batch))
+	observed := strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	for _, entry := range batch {
+		record := entry.record
+
+		var attributes []otlpKeyValue
+		if entry.access {
+			attributes = append(attributes, otlpKeyValue{Key: "log.type", Value: otlpString("access")})
+		}
+		if record.Module != "" {
+			attributes = append(attributes, otlpKeyValue{Key: "module", Value: otlpString(record.Module)})
+		}
+		if record.File != "" {
+			attributes = append(attributes,
+				otlpKeyValue{Key: "code.filepath", Value: otlpString(record.File)},
+				otlpKeyValue{Key: "code.lineno", Value: otlpAnyValue{IntValue: strconv.Itoa(record.Line)}},
+			)
+		}
+		attributes = append(attributes, otlpAttributes(record.Context)...)
+
+		severityText := record.Level.String()
+		if entry.access {
+			severityText = "ACCESS"
+		}
+
+		records = append(records, otlpLogRecord{
+			TimeUnixNano:         strconv.FormatInt(record.Time.UnixNano(), 10),
+			ObservedTimeUnixNano: observed,
+			SeverityNumber:       otlpSeverity(record.Level),
+			SeverityText:         severityText,
+			Body:                 otlpString(record.Message),
+			Attributes:           attributes,
+		})
+	}
+
+	return otlpLogsRequest{
+		ResourceLogs: []otlpResourceLogs{{
+			Resource: otlpResource{Attributes: e.resource},
+			ScopeLogs: []otlpScopeLogs{{
+				Scope:      otlpScope{Name: otlpScopeName},
+				LogRecords: records,
+			}},
+		}},
+	}
+}
+
+// otlpSeverity 日志级别对应的OTLP SeverityNumber
+func otlpSeverity(level Level) int {
+	switch level {
+	case DebugLevel:
+		return 5
+	case InfoLevel:
+		return 9
+	case WarnLevel:
+		return 13
+	case ErrorLevel:
+		return 17
+	default:
+		return 0
+	}
+}
+
+// otlpAttributes 将日志上下文转换为OTLP属性，按键排序
+func otlpAttributes(context map[string]interface{}) []otlpKeyValue {
+	if len(context) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(context))
+	for key := range context {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attributes := make([]otlpKeyValue, 0, len(keys))
+	for _, key := range keys {
+		attributes = append(attributes, otlpKeyValue{Key: key, Value: otlpValue(context[key])})
+	}
+	return attributes
+}
+
+// otlpValue 将任意值转换为OTLP AnyValue
+func otlpValue(value interface{}) otlpAnyValue {
+	switch v := value.(type) {
+	case nil:
+		return otlpAnyValue{}
+	case string:
+		return otlpString(v)
+	case bool:
+		return otlpAnyValue{BoolValue: &v}
+	case int:
+		return otlpAnyValue{IntValue: strconv.FormatInt(int64(v), 10)}
+	case int32:
+		return otlpAnyValue{IntValue: strconv.FormatInt(int64(v), 10)}
+	case int64:
+		return otlpAnyValue{IntValue: strconv.FormatInt(v, 10)}
+	case uint:
+		return otlpAnyValue{IntValue: strconv.FormatUint(uint64(v), 10)}
+	case uint32:
+		return otlpAnyValue{IntValue: strconv.FormatUint(uint64(v), 10)}
+	case uint64:
+		return otlpAnyValue{IntValue: strconv.FormatUint(v, 10)}
+	case float32:
+		f := float64(v)
+		return otlpAnyValue{DoubleValue: &f}
+	case float64:
+		return otlpAnyValue{DoubleValue: &v}
+	case map[string]interface{}:
+		values := otlpAttributes(v)
+		if values == nil {
+			values = []otlpKeyValue{}
+		}
+		return otlpAnyValue{KvlistValue: &otlpKeyValueList{Values: values}}
+	case []interface{}:
+		values := make([]otlpAnyValue, 0, len(v))
+		for _, item := range v {
+			values = append(values, otlpValue(item))
+		}
+		return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+	case error:
+		return otlpString(v.Error())
+	case fmt.Stringer:
+		return otlpString(v.String())
+	default:
+		// 其他类型按JSON编码为字符串
+		if data, err := json.Marshal(v); err == nil {
+			return otlpString(string(data))
+		}
+		return otlpString(fmt.Sprintf("%v", v))
+	}
+}
+
+// otlpString 字符串AnyValue
+func otlpString(s string) otlpAnyValue {
+	return otlpAnyValue{StringValue: &s}
+}
+
+// OTLP/HTTP JSON编码的数据结构（opentelemetry-proto logs/v1）
+// 64位整数按规范编码为字符串
+
+type otlpLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string           `json:"stringValue,omitempty"`
+	BoolValue   *bool             `json:"boolValue,omitempty"`
+	IntValue    string            `json:"intValue,omitempty"`
+	DoubleValue *float64          `json:"doubleValue,omitempty"`
+	ArrayValue  *otlpArrayValue   `json:"arrayValue,omitempty"`
+	KvlistValue *otlpKeyValueList `json:"kvlistValue,omitempty"`
+}
+
+type otlpArrayValue struct {
+	Values []otlpAnyValue `json:"values"`
+}
+
+type otlpKeyValueList struct {
+	Values []otlpKeyValue `json:"values"`
+}
@@ -0,0 +1,374 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"exchange/internal/pkg/config"
+)
+
+const (
+	// DefaultQueryLimit 默认返回条数
+	DefaultQueryLimit = 200
+	// MaxQueryLimit 最多返回条数
+	MaxQueryLimit = 1000
+
+	// tail首次读取的文件末尾长度
+	tailInitialBytes = 64 * 1024
+	// tail单次最多读取的长度
+	tailMaxBytes = 1 << 20
+	// 单行日志最大长度
+	maxLineSize = 1 << 20
+)
+
+// text格式日志的字段提取（slog文本格式为 key=value，zap控制台格式为制表符分隔）
+var (
+	textTimestampPattern = regexp.MustCompile(`timestamp="([^"]+)"`)
+	textLevelPattern     = regexp.MustCompile(`level=(\w+)`)
+	textMessagePattern   = regexp.MustCompile(`message=("(?:[^"\\]|\\.)*"|\S+)`)
+	textRequestIDPattern = regexp.MustCompile(`request_id[=:]"?([\w-]+)`)
+)
+
+// LogQuery 日志查询条件
+type LogQuery struct {
+	Source    string    // 日志来源，见LogReader.Sources
+	Levels    []string  // 日志级别，为空时不过滤
+	Since     time.Time // 开始时间，为零时为Until前24小时
+	Until     time.Time // 结束时间，为零时为当前时间
+	Keyword   string    // 关键字（不区分大小写，匹配整行）
+	RequestID string    // 请求ID
+	Limit     int       // 最多返回条数，默认DefaultQueryLimit，最大MaxQueryLimit
+}
+
+// LogEntry 日志条目
+type LogEntry struct {
+	Timestamp string `json:"timestamp"`
+	Level     string `json:"level"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+	Raw       string `json:"raw"` // 原始日志行
+
+	time time.Time
+}
+
+// LogReader 本地按天日志文件读取器
+// 只允许读取日志目录中已知来源的文件，文件名为 <前缀>_<日期>.log
+type LogReader struct {
+	dir     string
+	sources map[string]string // 来源名称 -> 文件前缀
+}
+
+// NewLogReader 创建日志文件读取器
+func NewLogReader(cfg *config.LogConfig) *LogReader {
+	sources := map[string]string{
+		"access": "access",
+		"error":  "error",
+	}
+	if cfg.Filename != "" {
+		sources["app"] = strings.TrimSuffix(cfg.Filename, ".log")
+	}
+	if cfg.CronLogFile != "" {
+		sources["cron"] = strings.TrimSuffix(cfg.CronLogFile, ".log")
+	}
+	return &LogReader{dir: cfg.LogDir, sources: sources}
+}
+
+// Sources 可查询的日志来源
+func (r *LogReader) Sources() []string {
+	names := make([]string, 0, len(r.sources))
+	for name := range r.sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Query 查询日志，按时间倒序返回最近的Limit条
+func (r *LogReader) Query(q LogQuery) ([]LogEntry, error) {
+	prefix, err := r.prefix(q.Source)
+	if err != nil {
+		return nil, err
+	}
+	q = normalizeQuery(q)
+
+	// 从结束日期往前逐天读取，已满足条数时停止
+	var result []LogEntry
+	startDay := truncateDay(q.Since)
+	for day := truncateDay(q.Until); !day.Before(startDay) && len(result) < q.Limit; day = day.AddDate(0, 0, -1) {
+		entries, err := r.scanFile(r.filename(prefix, day), q, q.Limit-len(result))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, entries...)
+	}
+	return result, nil
+}
+
+// Tail 读取cursor之后新写入的日志（cursor为空时读取当天文件末尾），返回下一次调用使用的cursor
+// cursor格式为 <日期>:<偏移量>，日期变化时从新一天的文件开头继续读取
+func (r *LogReader) Tail(q LogQuery, cursor string) ([]LogEntry, string, error) {
+	prefix, err := r.prefix(q.Source)
+	if err != nil {
+		return nil, "", err
+	}
+	q.Since, q.Until = time.Time{}, time.Time{}
+	if q.Limit <= 0 || q.Limit > MaxQueryLimit {
+		q.Limit = MaxQueryLimit
+	}
+
+	today := time.Now().Format("2006-01-02")
+	date, offset, initial := today, int64(0), cursor == ""
+	if !initial {
+		if date, offset, err = parseCursor(cursor); err != nil {
+			return nil, "", err
+		}
+	}
+
+	day, _ := time.ParseInLocation("2006-01-02", date, time.Local)
+	file, err := os.Open(r.filename(prefix, day))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []LogEntry{}, formatCursor(today, 0), nil
+		}
+		return nil, "", fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to stat log file: %w", err)
+	}
+	size := info.Size()
+
+	// 首次读取文件末尾；文件变小说明已被轮转，从头读取
+	if initial {
+		offset = max(size-tailInitialBytes, 0)
+	} else if offset > size {
+		offset = 0
+	}
+
+	data := make([]byte, min(size-offset, tailMaxBytes))
+	if _, err := file.ReadAt(data, offset); err != nil && err != io.EOF {
+		return nil, "", fmt.Errorf("failed to read log file: %w", err)
+	}
+
+	// 只处理完整的行，首次读取时丢弃第一行（可能不完整）
+	end := bytes.LastIndexByte(data, '\n') + 1
+	lines := data[:end]
+	if initial && offset > 0 {
+		if idx := bytes.IndexByte(lines, '\n'); idx >= 0 {
+			lines = lines[idx+1:]
+		}
+	}
+
+	entries := make([]LogEntry, 0)
+	for _, line := range bytes.Split(lines, []byte{'\n'}) {
+		if entry, ok := matchLine(line, q); ok {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) > q.Limit {
+		entries = entries[len(entries)-q.Limit:]
+	}
+
+	next := formatCursor(date, offset+int64(end))
+	// 已读到旧日期文件末尾，下次从当天文件开头读取
+	if date != today && offset+int64(end) >= size {
+		next = formatCursor(today, 0)
+	}
+	return entries, next, nil
+}
+
+// prefix 获取日志来源的文件前缀
+func (r *LogReader) prefix(source string) (string, error) {
+	prefix, ok := r.sources[source]
+	if !ok {
+		return "", fmt.Errorf("unknown log source: %s", source)
+	}
+	return prefix, nil
+}
+
+// filename 指定日期的日志文件路径
+func (r *LogReader) filename(prefix string, day time.Time) string {
+	return filepath.Join(r.dir, fmt.Sprintf("%s_%s.log", prefix, day.Format("2006-01-02")))
+}
+
+// scanFile 扫描单个文件，返回最后limit条匹配的日志（时间倒序）
+func (r *LogReader) scanFile(path string, q LogQuery, limit int) ([]LogEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer file.Close()
+
+	// 环形缓冲区保留最后limit条
+	ring := make([]LogEntry, 0, limit)
+	next := 0
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		entry, ok := matchLine(scanner.Bytes(), q)
+		if !ok {
+			continue
+		}
+		if len(ring) < limit {
+			ring = append(ring, entry)
+		} else {
+			ring[next] = entry
+			next = (next + 1) % limit
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read log file: %w", err)
+	}
+
+	result := make([]LogEntry, 0, len(ring))
+	for i := len(ring) - 1; i >= 0; i-- {
+		result = append(result, ring[(next+i)%len(ring)])
+	}
+	return result, nil
+}
+
+// normalizeQuery 补全查询条件默认值
+func normalizeQuery(q LogQuery) LogQuery {
+	if q.Until.IsZero() {
+		q.Until = time.Now()
+	}
+	if q.Since.IsZero() {
+		q.Since = q.Until.Add(-24 * time.Hour)
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultQueryLimit
+	}
+	if q.Limit > MaxQueryLimit {
+		q.Limit = MaxQueryLimit
+	}
+	return q
+}
+
+// matchLine 解析日志行并检查是否满足查询条件
+func matchLine(line []byte, q LogQuery) (LogEntry, bool) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return LogEntry{}, false
+	}
+
+	raw := string(line)
+	if q.Keyword != "" && !strings.Contains(strings.ToLower(raw), strings.ToLower(q.Keyword)) {
+		return LogEntry{}, false
+	}
+	if q.RequestID != "" && !strings.Contains(raw, q.RequestID) {
+		return LogEntry{}, false
+	}
+
+	entry := parseLine(raw)
+	if len(q.Levels) > 0 {
+		matched := false
+		for _, level := range q.Levels {
+			if strings.EqualFold(level, entry.Level) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return LogEntry{}, false
+		}
+	}
+	if !entry.time.IsZero() {
+		if !q.Since.IsZero() && entry.time.Before(q.Since) {
+			return LogEntry{}, false
+		}
+		if !q.Until.IsZero() && entry.time.After(q.Until) {
+			return LogEntry{}, false
+		}
+	}
+	return entry, true
+}
+
+// parseLine 解析json或text格式的日志行，无法识别的字段为空
+func parseLine(raw string) LogEntry {
+	entry := LogEntry{Raw: raw}
+
+	if strings.HasPrefix(raw, "{") {
+		var fields struct {
+			Timestamp string `json:"timestamp"`
+			Level     string `json:"level"`
+			Message   string `json:"message"`
+			Context   struct {
+				RequestID string `json:"request_id"`
+			} `json:"context"`
+		}
+		if err := json.Unmarshal([]byte(raw), &fields); err == nil {
+			entry.Timestamp = fields.Timestamp
+			entry.Level = fields.Level
+			entry.Message = fields.Message
+			entry.RequestID = fields.Context.RequestID
+		}
+	} else if match := textTimestampPattern.FindStringSubmatch(raw); match != nil {
+		// slog文本格式
+		entry.Timestamp = match[1]
+		if match := textLevelPattern.FindStringSubmatch(raw); match != nil {
+			entry.Level = match[1]
+		}
+		if match := textMessagePattern.FindStringSubmatch(raw); match != nil {
+			entry.Message = match[1]
+			if unquoted, err := strconv.Unquote(match[1]); err == nil {
+				entry.Message = unquoted
+			}
+		}
+	} else if parts := strings.SplitN(raw, "\t", 4); len(parts) >= 3 {
+		// zap控制台格式
+		entry.Timestamp, entry.Level, entry.Message = parts[0], parts[1], parts[2]
+	}
+
+	if entry.RequestID == "" {
+		if match := textRequestIDPattern.FindStringSubmatch(raw); match != nil {
+			entry.RequestID = match[1]
+		}
+	}
+	if t, err := time.ParseInLocation(timestampLayout, entry.Timestamp, time.Local); err == nil {
+		entry.time = t
+	}
+	return entry
+}
+
+// truncateDay 截取到当天零点
+func truncateDay(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// parseCursor 解析tail游标
+func parseCursor(cursor string) (string, int64, error) {
+	date, rawOffset, ok := strings.Cut(cursor, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("invalid cursor: %s", cursor)
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return "", 0, fmt.Errorf("invalid cursor date: %s", date)
+	}
+	offset, err := strconv.ParseInt(rawOffset, 10, 64)
+	if err != nil || offset < 0 {
+		return "", 0, fmt.Errorf("invalid cursor offset: %s", rawOffset)
+	}
+	return date, offset, nil
+}
+
+// formatCursor 生成tail游标
+func formatCursor(date string, offset int64) string {
+	return date + ":" + strconv.FormatInt(offset, 10)
+}
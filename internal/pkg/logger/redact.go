@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"exchange/internal/pkg/config"
+)
+
+// 疑似银行卡号：13-19位数字，允许空格或连字符分隔
+var cardNumberPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+// redactor 日志上下文脱敏器
+// 在序列化之前处理context：字段名命中敏感关键字的值整体替换为mask，字符串值中的银行卡号只保留后4位
+// 嵌套的map和切片同样处理；原map不修改，调用方可以继续使用
+type redactor struct {
+	keys        []string
+	mask        string
+	cardNumbers bool
+}
+
+// newRedactor 根据配置创建脱敏器，未启用时返回nil
+func newRedactor(cfg config.LogRedactConfig) *redactor {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	r := &redactor{
+		mask:        cfg.Mask,
+		cardNumbers: cfg.CardNumbers,
+	}
+	if r.mask == "" {
+		r.mask = "***"
+	}
+	for _, key := range cfg.Keys {
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			r.keys = append(r.keys, key)
+		}
+	}
+	return r
+}
+
+// redact 返回脱敏后的context副本
+func (r *redactor) redact(context map[string]interface{}) map[string]interface{} {
+	if r == nil || len(context) == 0 {
+		return context
+	}
+	return r.redactMap(context)
+}
+
+// RedactContext 按日志的脱敏配置返回context的脱敏副本，用于日志以外的输出（如错误上报）
+// 日志系统未初始化或未启用脱敏时原样返回
+func RedactContext(context map[string]interface{}) map[string]interface{} {
+	if defaultLogger == nil {
+		return context
+	}
+	return defaultLogger.redactor.redact(context)
+}
+
+// redactMap 脱敏map
+func (r *redactor) redactMap(m map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		if r.sensitiveKey(key) {
+			result[key] = r.mask
+			continue
+		}
+		result[key] = r.redactValue(value)
+	}
+	return result
+}
+
+// redactValue 脱敏单个值
+func (r *redactor) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return r.redactString(v)
+	case map[string]interface{}:
+		return r.redactMap(v)
+	case map[string]string:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if r.sensitiveKey(key) {
+				result[key] = r.mask
+			} else {
+				result[key] = r.redactString(item)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = r.redactValue(item)
+		}
+		return result
+	case []string:
+		result := make([]string, len(v))
+		for i, item := range v {
+			result[i] = r.redactString(item)
+		}
+		return result
+	case error:
+		return r.redactString(v.Error())
+	case fmt.Stringer:
+		return r.redactString(v.String())
+	default:
+		return value
+	}
+}
+
+// redactString 屏蔽字符串中的银行卡号
+func (r *redactor) redactString(s string) string {
+	if !r.cardNumbers {
+		return s
+	}
+	return cardNumberPattern.ReplaceAllStringFunc(s, func(match string) string {
+		digits := strings.Map(func(c rune) rune {
+			if c >= '0' && c <= '9' {
+				return c
+			}
+			return -1
+		}, match)
+		if !luhnValid(digits) {
+			return match
+		}
+		return strings.Repeat("*", len(digits)-4) + digits[len(digits)-4:]
+	})
+}
+
+// sensitiveKey 字段名是否命中敏感关键字
+func (r *redactor) sensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range r.keys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// luhnValid Luhn校验，避免把订单号、时间戳等长数字误判为卡号
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"exchange/internal/pkg/config"
+)
+
+// sampler 日志采样器
+// 每个周期内同一级别同一消息先记录initial条，之后每thereafter条记录一条
+type sampler struct {
+	initial    int
+	thereafter int
+	tick       time.Duration
+
+	mu      sync.Mutex
+	resetAt time.Time
+	counts  map[samplerKey]int
+}
+
+// samplerKey 采样计数键
+type samplerKey struct {
+	level   Level
+	message string
+}
+
+// newSampler 根据配置创建采样器，未启用时返回nil
+func newSampler(cfg config.LogSamplingConfig) *sampler {
+	if !cfg.Enabled || cfg.Initial <= 0 || cfg.Thereafter <= 0 {
+		return nil
+	}
+
+	return &sampler{
+		initial:    cfg.Initial,
+		thereafter: cfg.Thereafter,
+		tick:       time.Second,
+		counts:     make(map[samplerKey]int),
+	}
+}
+
+// allow 判断日志是否应被记录，错误日志始终记录
+func (s *sampler) allow(level Level, message string, now time.Time) bool {
+	if s == nil || level >= ErrorLevel {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 进入新周期时重置计数
+	if now.After(s.resetAt) {
+		s.resetAt = now.Add(s.tick)
+		s.counts = make(map[samplerKey]int, len(s.counts))
+	}
+
+	key := samplerKey{level: level, message: message}
+	s.counts[key]++
+	n := s.counts[key]
+
+	if n <= s.initial {
+		return true
+	}
+	return (n-s.initial)%s.thereafter == 0
+}
@@ -0,0 +1,413 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"exchange/internal/pkg/config"
+)
+
+// sinkSender 将一批日志发送到集中日志系统
+type sinkSender interface {
+	// name 输出名称，用于统计和错误输出
+	name() string
+	// send 发送一批日志（每条为一行JSON），返回retryableError时按退避策略重试
+	send(ctx context.Context, docs [][]byte) error
+}
+
+// retryableError 可重试的发送错误（网络错误、429、5xx）
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// sinkWriter 集中日志输出写入器
+// 作为后端的io.Writer接收格式化后的日志行，放入队列由后台协程按批发送，失败时按退避策略重试
+// 队列满时丢弃并计数，发送失败只输出到stderr，不影响业务和本地日志
+type sinkWriter struct {
+	sender        sinkSender
+	batchSize     int
+	flushInterval time.Duration
+	maxRetries    int
+	retryBackoff  time.Duration
+
+	mu      sync.Mutex // 保护pending和closed
+	pending []byte     // 未遇到换行符的不完整日志行（缓冲区刷新可能截断日志行）
+	closed  bool
+	queue   chan []byte
+	flushCh chan chan struct{}
+	done    chan struct{}
+
+	sent    atomic.Uint64
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+	retried atomic.Uint64
+}
+
+// newSinkWriters 根据配置创建所有集中日志输出
+func newSinkWriters(outputs []config.LogOutputConfig) ([]*sinkWriter, error) {
+	sinks := make([]*sinkWriter, 0, len(outputs))
+	for _, output := range outputs {
+		output = output.WithDefaults()
+		client := &http.Client{Timeout: time.Duration(output.TimeoutMs) * time.Millisecond}
+
+		var sender sinkSender
+		switch output.Type {
+		case "kafka":
+			sender = &kafkaSender{cfg: output, client: client}
+		case "elasticsearch":
+			sender = &elasticsearchSender{cfg: output, client: client}
+		default:
+			for _, sink := range sinks {
+				sink.Close()
+			}
+			return nil, fmt.Errorf("unsupported log output: %s", output.Type)
+		}
+		sinks = append(sinks, newSinkWriter(sender, output))
+	}
+	return sinks, nil
+}
+
+// newSinkWriter 创建并启动集中日志输出写入器
+func newSinkWriter(sender sinkSender, cfg config.LogOutputConfig) *sinkWriter {
+	w := &sinkWriter{
+		sender:        sender,
+		batchSize:     cfg.BatchSize,
+		flushInterval: time.Duration(cfg.FlushIntervalMs) * time.Millisecond,
+		maxRetries:    cfg.MaxRetries,
+		retryBackoff:  time.Duration(cfg.RetryBackoffMs) * time.Millisecond,
+		queue:         make(chan []byte, cfg.QueueSize),
+		flushCh:       make(chan chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write 按行拆分后放入发送队列，队列满时丢弃
+func (w *sinkWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return len(p), nil
+	}
+
+	data := p
+	if len(w.pending) > 0 {
+		data = append(w.pending, p...)
+		w.pending = nil
+	}
+
+	for {
+		idx := bytes.IndexByte(data, '\n')
+		if idx < 0 {
+			break
+		}
+		if line := bytes.TrimSpace(data[:idx]); len(line) > 0 {
+			w.enqueue(bytes.Clone(line))
+		}
+		data = data[idx+1:]
+	}
+	if len(data) > 0 {
+		w.pending = bytes.Clone(data)
+	}
+
+	return len(p), nil
+}
+
+// enqueue 放入发送队列
+func (w *sinkWriter) enqueue(line []byte) {
+	select {
+	case w.queue <- line:
+	default:
+		w.dropped.Add(1)
+	}
+}
+
+// Sync 发送队列中已有的日志
+func (w *sinkWriter) Sync() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.mu.Unlock()
+
+	ack := make(chan struct{})
+	select {
+	case w.flushCh <- ack:
+		<-ack
+	case <-w.done:
+	}
+	return nil
+}
+
+// Close 发送剩余日志后停止
+func (w *sinkWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	if line := bytes.TrimSpace(w.pending); len(line) > 0 {
+		w.enqueue(line)
+	}
+	w.pending = nil
+	close(w.queue)
+	w.mu.Unlock()
+
+	<-w.done
+	return nil
+}
+
+// Stats 输出统计
+func (w *sinkWriter) Stats() map[string]interface{} {
+	return map[string]interface{}{
+		"name":    w.sender.name(),
+		"queued":  len(w.queue),
+		"sent":    w.sent.Load(),
+		"dropped": w.dropped.Load(),
+		"failed":  w.failed.Load(),
+		"retried": w.retried.Load(),
+	}
+}
+
+// run 后台发送协程，攒满batchSize条或到达flushInterval时发送一批
+func (w *sinkWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, w.batchSize)
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		w.sendWithRetry(batch)
+		batch = make([][]byte, 0, w.batchSize)
+	}
+
+	for {
+		select {
+		case line, ok := <-w.queue:
+			if !ok {
+				send()
+				return
+			}
+			batch = append(batch, line)
+			if len(batch) >= w.batchSize {
+				send()
+			}
+		case ack := <-w.flushCh:
+			// 先取出队列中已有的日志
+			for drained := false; !drained; {
+				select {
+				case line, ok := <-w.queue:
+					if !ok {
+						drained = true
+						break
+					}
+					batch = append(batch, line)
+					if len(batch) >= w.batchSize {
+						send()
+					}
+				default:
+					drained = true
+				}
+			}
+			send()
+			close(ack)
+		case <-ticker.C:
+			send()
+		}
+	}
+}
+
+// sendWithRetry 发送一批日志，可重试的错误按指数退避重试
+func (w *sinkWriter) sendWithRetry(batch [][]byte) {
+	backoff := w.retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := w.sender.send(context.Background(), batch)
+		if err == nil {
+			w.sent.Add(uint64(len(batch)))
+			return
+		}
+
+		var retryable *retryableError
+		if !errors.As(err, &retryable) || attempt >= w.maxRetries {
+			w.failed.Add(uint64(len(batch)))
+			fmt.Fprintf(os.Stderr, "log output %s failed (%d records): %v\n", w.sender.name(), len(batch), err)
+			return
+		}
+
+		w.retried.Add(1)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// postJSON 发送HTTP请求并检查状态码，网络错误、429和5xx可重试
+func postJSON(ctx context.Context, client *http.Client, cfg config.LogOutputConfig, url, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if cfg.Username != "" {
+		req.SetBasicAuth(cfg.Username, cfg.Password)
+	}
+	for key, value := range cfg.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, &retryableError{err: err}
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, &retryableError{err: err}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err := fmt.Errorf("status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return nil, &retryableError{err: err}
+		}
+		return nil, err
+	}
+	return respBody, nil
+}
+
+// asJSONDocument 日志行为JSON对象时原样使用，否则（text格式）包装为JSON对象
+func asJSONDocument(line []byte) json.RawMessage {
+	if len(line) > 0 && line[0] == '{' && json.Valid(line) {
+		return line
+	}
+	doc, _ := json.Marshal(map[string]interface{}{
+		"timestamp": time.Now().Format("2006-01-02 15:04:05.000"),
+		"message":   string(line),
+	})
+	return doc
+}
+
+// kafkaSender 通过Kafka REST Proxy（v2 API）写入主题
+// 使用HTTP接口避免在日志库中引入Kafka客户端及其连接管理
+type kafkaSender struct {
+	cfg    config.LogOutputConfig
+	client *http.Client
+}
+
+func (s *kafkaSender) name() string {
+	return "kafka:" + s.cfg.Topic
+}
+
+// send POST {url}/topics/{topic}，每条日志为一条JSON消息
+func (s *kafkaSender) send(ctx context.Context, docs [][]byte) error {
+	type kafkaRecord struct {
+		Value json.RawMessage `json:"value"`
+	}
+	records := make([]kafkaRecord, 0, len(docs))
+	for _, doc := range docs {
+		records = append(records, kafkaRecord{Value: asJSONDocument(doc)})
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"records": records})
+	if err != nil {
+		return err
+	}
+
+	url := strings.TrimSuffix(s.cfg.URL, "/") + "/topics/" + s.cfg.Topic
+	_, err = postJSON(ctx, s.client, s.cfg, url, "application/vnd.kafka.json.v2+json", body)
+	return err
+}
+
+// elasticsearchSender 通过Bulk API写入Elasticsearch
+type elasticsearchSender struct {
+	cfg    config.LogOutputConfig
+	client *http.Client
+}
+
+func (s *elasticsearchSender) name() string {
+	return "elasticsearch:" + s.cfg.Index
+}
+
+// send POST {url}/_bulk，部分文档写入失败时不重试整批，避免重复写入
+func (s *elasticsearchSender) send(ctx context.Context, docs [][]byte) error {
+	action, err := json.Marshal(map[string]interface{}{
+		"index": map[string]string{"_index": s.index(time.Now())},
+	})
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	for _, doc := range docs {
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(asJSONDocument(doc))
+		body.WriteByte('\n')
+	}
+
+	url := strings.TrimSuffix(s.cfg.URL, "/") + "/_bulk"
+	respBody, err := postJSON(ctx, s.client, s.cfg, url, "application/x-ndjson", body.Bytes())
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int `json:"status"`
+			Error  struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil || !result.Errors {
+		return nil
+	}
+
+	failed := 0
+	reason := ""
+	for _, item := range result.Items {
+		for _, op := range item {
+			if op.Status >= 300 {
+				failed++
+				if reason == "" {
+					reason = op.Error.Type + ": " + op.Error.Reason
+				}
+			}
+		}
+	}
+	return fmt.Errorf("%d of %d documents rejected: %s", failed, len(docs), reason)
+}
+
+// index 当前索引名，{}中的内容按Go时间格式替换
+func (s *elasticsearchSender) index(now time.Time) string {
+	index := s.cfg.Index
+	start := strings.IndexByte(index, '{')
+	end := strings.IndexByte(index, '}')
+	if start < 0 || end < start {
+		return index
+	}
+	return index[:start] + now.Format(index[start+1:end]) + index[end+1:]
+}
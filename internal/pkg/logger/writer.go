@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"exchange/internal/pkg/config"
+)
+
+// dailyWriter 按天切换文件的日志写入器
+// 文件名为 <前缀>_<日期>.log，每天本地时间零点由后台协程切换到新文件，单个文件过大时由lumberjack轮转
+type dailyWriter struct {
+	mu          sync.Mutex
+	dir         string
+	prefix      string
+	currentDate string
+	file        *lumberjack.Logger
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// newDailyWriter 创建按天切换文件的写入器
+func newDailyWriter(cfg *config.LogConfig, baseName string) *dailyWriter {
+	today := time.Now().Format("2006-01-02")
+	w := &dailyWriter{
+		dir:         cfg.LogDir,
+		prefix:      strings.TrimSuffix(baseName, ".log"),
+		currentDate: today,
+	}
+	w.file = &lumberjack.Logger{
+		Filename:   w.filename(today),
+		MaxSize:    cfg.MaxSize, // MB
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAge, // days
+		Compress:   cfg.Compress,
+	}
+	w.stop = make(chan struct{})
+	go w.rotateAtMidnight()
+	return w
+}
+
+// filename 返回指定日期的日志文件路径
+func (w *dailyWriter) filename(date string) string {
+	return filepath.Join(w.dir, fmt.Sprintf("%s_%s.log", w.prefix, date))
+}
+
+// Write 写入日志，日期变化时切换文件
+func (w *dailyWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// 零点切换由后台协程完成，这里兜底处理定时器延迟（如系统休眠）的情况
+	today := time.Now().Format("2006-01-02")
+	if w.currentDate != today {
+		w.switchDate(today)
+	}
+
+	return w.file.Write(p)
+}
+
+// rotateAtMidnight 每天本地时间零点切换到新文件，避免日志较少的服务一直打开前一天的文件
+func (w *dailyWriter) rotateAtMidnight() {
+	for {
+		midnight := nextMidnight(time.Now())
+		timer := time.NewTimer(time.Until(midnight))
+		select {
+		case <-timer.C:
+			w.mu.Lock()
+			// 定时器可能略早于零点触发，按目标日期切换
+			if date := midnight.Format("2006-01-02"); date > w.currentDate {
+				w.switchDate(date)
+				// 立即创建新文件，便于日志查询和采集工具跟踪
+				_, _ = w.file.Write(nil)
+			}
+			w.mu.Unlock()
+		case <-w.stop:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// switchDate 切换到指定日期的文件，旧文件落盘后关闭（调用方需持有锁）
+func (w *dailyWriter) switchDate(date string) {
+	previous := w.file.Filename
+	w.currentDate = date
+	_ = w.file.Close()
+	syncFile(previous)
+	// 下次写入时lumberjack会打开新文件
+	w.file.Filename = w.filename(date)
+}
+
+// nextMidnight 下一个本地时间零点
+func nextMidnight(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+}
+
+// syncFile 将已关闭文件的内容刷到磁盘
+func syncFile(name string) {
+	f, err := os.OpenFile(name, os.O_WRONLY, 0)
+	if err != nil {
+		return
+	}
+	_ = f.Sync()
+	_ = f.Close()
+}
+
+// Rotate 手动轮转当前文件
+func (w *dailyWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Rotate()
+}
+
+// Close 停止零点切换并关闭文件
+func (w *dailyWriter) Close() error {
+	w.stopOnce.Do(func() { close(w.stop) })
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
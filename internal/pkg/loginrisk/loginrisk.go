@@ -0,0 +1,122 @@
+// Package loginrisk 新设备和异地登录检测
+// 设备按客户端上报的设备ID识别，没有设备ID时按去掉版本号的User-Agent识别（浏览器升级不视为新设备）；
+// 需要用户确认的登录签发一次性确认token，只以SHA-256哈希保存在Redis中
+package loginrisk
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/database"
+)
+
+const (
+	confirmKeyPrefix = "login_confirm:token:" // 确认token的哈希，值为Pending的JSON
+
+	tokenBytes = 32
+)
+
+// ErrInvalidToken 确认token不存在、已使用或已过期
+var ErrInvalidToken = errors.New("invalid login confirmation token")
+
+// versionPattern User-Agent中的版本号
+var versionPattern = regexp.MustCompile(`[0-9]+([._][0-9]+)*`)
+
+// Fingerprint 设备指纹，deviceID为客户端上报的设备ID（可以为空）
+func Fingerprint(deviceID, userAgent string) string {
+	source := "ua:" + strings.ToLower(versionPattern.ReplaceAllString(userAgent, ""))
+	if deviceID = strings.TrimSpace(deviceID); deviceID != "" {
+		source = "id:" + deviceID
+	}
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
+
+// Pending 等待用户确认的登录
+type Pending struct {
+	UserID      uint      `json:"user_id"`
+	Fingerprint string    `json:"fingerprint"`
+	UserAgent   string    `json:"user_agent"`
+	IP          string    `json:"ip"`
+	Country     string    `json:"country"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ConfirmStore 登录确认token存储，保存在Redis中，多实例共享
+type ConfirmStore struct {
+	redis *database.RedisService
+	ttl   time.Duration
+}
+
+// NewConfirmStore 创建登录确认token存储，ttl为确认链接有效期
+func NewConfirmStore(redis *database.RedisService, ttl time.Duration) *ConfirmStore {
+	return &ConfirmStore{
+		redis: redis,
+		ttl:   ttl,
+	}
+}
+
+// Issue 签发确认token，返回token和过期时间
+func (s *ConfirmStore) Issue(ctx context.Context, pending Pending) (string, time.Time, error) {
+	buf := make([]byte, tokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate login confirmation token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	value, err := json.Marshal(pending)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to encode login confirmation: %w", err)
+	}
+	if err := s.redis.Client().Set(ctx, confirmKeyPrefix+hashToken(token), value, s.ttl).Err(); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to save login confirmation token: %w", err)
+	}
+	return token, clock.Now().Add(s.ttl), nil
+}
+
+// Consume 使用确认token，返回等待确认的登录；token随即失效
+func (s *ConfirmStore) Consume(ctx context.Context, token string) (*Pending, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+
+	key := confirmKeyPrefix + hashToken(token)
+	pipe := s.redis.Client().TxPipeline()
+	get := pipe.Get(ctx, key)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to consume login confirmation token: %w", err)
+	}
+
+	value, err := get.Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume login confirmation token: %w", err)
+	}
+
+	var pending Pending
+	if err := json.Unmarshal(value, &pending); err != nil || pending.UserID == 0 {
+		return nil, ErrInvalidToken
+	}
+	return &pending, nil
+}
+
+// hashToken token的哈希
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,204 @@
+// Package loginthrottle 登录失败限制
+// 按用户名和客户端IP分别统计登录失败次数（保存在Redis中，多实例共享）：超过免等待次数后每次失败需等待的时间按指数增长，
+// 达到锁定阈值后锁定一段时间；等待和锁定期间的登录请求不校验密码，直接返回appErrors.ErrAccountLocked，防止撞库和暴力破解
+package loginthrottle
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/database"
+	appErrors "exchange/internal/pkg/errors"
+	appLogger "exchange/internal/pkg/logger"
+)
+
+// 登录入口，不同入口分别统计
+const (
+	ScopeUser  = "user"  // 用户登录
+	ScopeAdmin = "admin" // 管理员登录
+)
+
+const (
+	failKeyPrefix = "login:fail:" // 窗口内的失败次数
+	lockKeyPrefix = "login:lock:" // 等待或锁定，有效期为剩余时间
+)
+
+// throttleLogger 登录失败限制日志
+var throttleLogger = appLogger.Module("login_throttle")
+
+// failScript 增加失败次数，第一次失败时设置统计窗口，返回失败次数
+var failScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// Throttle 登录失败限制，scope为登录入口（ScopeUser/ScopeAdmin）
+type Throttle struct {
+	redis *database.RedisService
+	cfg   config.LoginThrottleConfig
+}
+
+// NewThrottle 创建登录失败限制，未启用时返回nil（nil的Throttle不做任何限制）
+func NewThrottle(redis *database.RedisService, cfg config.LoginThrottleConfig) *Throttle {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &Throttle{
+		redis: redis,
+		cfg:   cfg,
+	}
+}
+
+// Check 登录前检查用户名和IP是否处于等待或锁定期间，是时返回appErrors.ErrAccountLocked
+// 查询失败时放行，只记录日志
+func (t *Throttle) Check(ctx context.Context, scope, username, ip string) error {
+	if t == nil {
+		return nil
+	}
+
+	pipe := t.redis.Client().Pipeline()
+	userTTL := pipe.PTTL(ctx, lockKeyPrefix+userKey(scope, username))
+	ipTTL := pipe.PTTL(ctx, lockKeyPrefix+ipKey(scope, ip))
+	if _, err := pipe.Exec(ctx); err != nil {
+		throttleLogger.WithContext(ctx).Warn("查询登录锁定状态失败", map[string]interface{}{
+			"scope": scope,
+			"error": err.Error(),
+		})
+		return nil
+	}
+
+	remaining := userTTL.Val()
+	if ipTTL.Val() > remaining {
+		remaining = ipTTL.Val()
+	}
+	if remaining <= 0 {
+		return nil
+	}
+
+	appLogger.Security("登录请求被拒绝：失败次数过多", map[string]interface{}{
+		"scope":       scope,
+		"username":    username,
+		"ip":          ip,
+		"retry_after": retryAfterSeconds(remaining),
+	})
+	return appErrors.AccountLockedError(retryAfterSeconds(remaining))
+}
+
+// Fail 记录一次登录失败，超过免等待次数后设置等待时间，达到锁定阈值时锁定
+func (t *Throttle) Fail(ctx context.Context, scope, username, ip string) {
+	if t == nil {
+		return
+	}
+
+	t.fail(ctx, scope, "username", userKey(scope, username), t.cfg.UserLockoutThreshold, map[string]interface{}{
+		"username": username,
+		"ip":       ip,
+	})
+	t.fail(ctx, scope, "ip", ipKey(scope, ip), t.cfg.IPLockoutThreshold, map[string]interface{}{
+		"username": username,
+		"ip":       ip,
+	})
+}
+
+// Succeed 登录成功后清除该用户名的失败次数和等待，IP的失败次数保留到窗口结束
+func (t *Throttle) Succeed(ctx context.Context, scope, username string) {
+	if t == nil {
+		return
+	}
+
+	key := userKey(scope, username)
+	if err := t.redis.Client().Del(ctx, failKeyPrefix+key, lockKeyPrefix+key).Err(); err != nil {
+		throttleLogger.WithContext(ctx).Warn("清除登录失败次数失败", map[string]interface{}{
+			"scope": scope,
+			"error": err.Error(),
+		})
+	}
+}
+
+// fail 增加失败次数并按次数设置等待或锁定
+func (t *Throttle) fail(ctx context.Context, scope, dimension, key string, threshold int, fields map[string]interface{}) {
+	window := time.Duration(t.cfg.WindowMinutes) * time.Minute
+	count, err := failScript.Run(ctx, t.redis.Client(), []string{failKeyPrefix + key}, window.Milliseconds()).Int()
+	if err != nil {
+		throttleLogger.WithContext(ctx).Warn("记录登录失败次数失败", map[string]interface{}{
+			"scope": scope,
+			"error": err.Error(),
+		})
+		return
+	}
+
+	wait, locked := t.wait(count, threshold)
+	if wait <= 0 {
+		return
+	}
+	if err := t.redis.Client().Set(ctx, lockKeyPrefix+key, count, wait).Err(); err != nil {
+		throttleLogger.WithContext(ctx).Warn("设置登录等待时间失败", map[string]interface{}{
+			"scope": scope,
+			"error": err.Error(),
+		})
+		return
+	}
+
+	entry := map[string]interface{}{
+		"scope":       scope,
+		"dimension":   dimension,
+		"failures":    count,
+		"retry_after": retryAfterSeconds(wait),
+	}
+	for k, v := range fields {
+		entry[k] = v
+	}
+	if locked {
+		appLogger.Security("登录失败次数达到锁定阈值，已临时锁定", entry)
+		return
+	}
+	appLogger.Security("登录失败次数过多，需等待后再试", entry)
+}
+
+// wait 第count次失败后需等待的时间，达到锁定阈值时为锁定时长
+func (t *Throttle) wait(count, threshold int) (time.Duration, bool) {
+	if count >= threshold {
+		return time.Duration(t.cfg.LockoutMinutes) * time.Minute, true
+	}
+	if count <= t.cfg.FreeAttempts {
+		return 0, false
+	}
+
+	exponent := count - t.cfg.FreeAttempts - 1
+	seconds := float64(t.cfg.BaseDelaySeconds) * math.Pow(2, float64(exponent))
+	if seconds > float64(t.cfg.MaxDelaySeconds) {
+		seconds = float64(t.cfg.MaxDelaySeconds)
+	}
+	return time.Duration(seconds) * time.Second, false
+}
+
+// userKey 用户名的键，用户名忽略大小写并取哈希，避免特殊字符进入键名
+func userKey(scope, username string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(username))))
+	return fmt.Sprintf("%s:user:%s", scope, hex.EncodeToString(sum[:16]))
+}
+
+// ipKey 客户端IP的键
+func ipKey(scope, ip string) string {
+	return fmt.Sprintf("%s:ip:%s", scope, ip)
+}
+
+// retryAfterSeconds 剩余时间向上取整为秒，至少1秒
+func retryAfterSeconds(d time.Duration) int {
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
@@ -0,0 +1,179 @@
+package loglevel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"exchange/internal/pkg/database"
+	"exchange/internal/pkg/logger"
+)
+
+const (
+	// RootModule 表示全局日志级别
+	RootModule = "root"
+
+	// 运行时设置的级别保存在Redis哈希中（field为模块名，value为级别），所有实例共享
+	levelsKey = "log:levels"
+	// 级别变更通知频道，消息内容为变更的模块名
+	changedChannel = "log:levels:changed"
+
+	// 定期全量同步的间隔，避免因订阅断开错过变更
+	resyncInterval = time.Minute
+)
+
+// Sync 日志级别同步器
+// 管理后台通过Set/Reset修改级别并发布通知，各实例调用Start订阅通知并应用到本地日志记录器
+type Sync struct {
+	redis *database.RedisService
+
+	mu        sync.Mutex
+	rootLevel logger.Level    // 启动时的全局级别，重置root时恢复
+	applied   map[string]bool // 已应用的运行时模块级别
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewSync 创建日志级别同步器
+func NewSync(redis *database.RedisService) *Sync {
+	return &Sync{
+		redis:   redis,
+		applied: make(map[string]bool),
+	}
+}
+
+// Start 加载已保存的级别并订阅变更通知
+func (s *Sync) Start(ctx context.Context) error {
+	s.mu.Lock()
+	s.rootLevel = logger.GetLevel()
+	s.mu.Unlock()
+
+	if err := s.reload(ctx); err != nil {
+		return err
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+
+	pubsub := s.redis.Client().Subscribe(ctx, changedChannel)
+	go s.listen(ctx, pubsub)
+
+	return nil
+}
+
+// Stop 停止订阅
+func (s *Sync) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+// Set 设置模块日志级别并通知所有实例，module为root时设置全局级别
+func (s *Sync) Set(ctx context.Context, module, level string) error {
+	module = strings.TrimSpace(module)
+	if module == "" {
+		return fmt.Errorf("module is required")
+	}
+	parsed, err := logger.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	client := s.redis.Client()
+	if err := client.HSet(ctx, levelsKey, module, strings.ToLower(parsed.String())).Err(); err != nil {
+		return fmt.Errorf("failed to save log level: %w", err)
+	}
+	if err := client.Publish(ctx, changedChannel, module).Err(); err != nil {
+		return fmt.Errorf("failed to publish log level change: %w", err)
+	}
+	return nil
+}
+
+// Reset 清除模块运行时日志级别并通知所有实例，恢复为配置文件中的级别
+func (s *Sync) Reset(ctx context.Context, module string) error {
+	client := s.redis.Client()
+	if err := client.HDel(ctx, levelsKey, module).Err(); err != nil {
+		return fmt.Errorf("failed to delete log level: %w", err)
+	}
+	if err := client.Publish(ctx, changedChannel, module).Err(); err != nil {
+		return fmt.Errorf("failed to publish log level change: %w", err)
+	}
+	return nil
+}
+
+// Overrides 获取运行时设置的级别
+func (s *Sync) Overrides(ctx context.Context) (map[string]string, error) {
+	levels, err := s.redis.Client().HGetAll(ctx, levelsKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log levels: %w", err)
+	}
+	return levels, nil
+}
+
+// listen 处理变更通知，收到通知或定期全量同步时重新加载所有级别
+func (s *Sync) listen(ctx context.Context, pubsub *redis.PubSub) {
+	defer close(s.done)
+	defer pubsub.Close()
+
+	ticker := time.NewTicker(resyncInterval)
+	defer ticker.Stop()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			logger.Debug("收到日志级别变更通知", map[string]interface{}{"module": msg.Payload})
+		case <-ticker.C:
+		}
+
+		if err := s.reload(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn("同步日志级别失败", map[string]interface{}{"error": err.Error()})
+		}
+	}
+}
+
+// reload 从Redis加载所有运行时级别并应用，已删除的模块恢复为配置文件中的级别
+func (s *Sync) reload(ctx context.Context) error {
+	levels, err := s.Overrides(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rootLevel := s.rootLevel
+	for module, value := range levels {
+		level, err := logger.ParseLevel(value)
+		if err != nil {
+			continue
+		}
+		if module == RootModule {
+			rootLevel = level
+			continue
+		}
+		logger.SetModuleLevel(module, level)
+		s.applied[module] = true
+	}
+	logger.SetLevel(rootLevel)
+
+	for module := range s.applied {
+		if _, ok := levels[module]; !ok {
+			logger.ResetModuleLevel(module)
+			delete(s.applied, module)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package mailer
+
+import (
+	"context"
+
+	appLogger "exchange/internal/pkg/logger"
+)
+
+// mailLogger 邮件发送日志
+var mailLogger = appLogger.Module("mailer")
+
+// LogSender 将邮件写入日志，不实际发送（开发环境和未配置邮件服务时使用）
+// 日志中只记录收件人和主题，正文可能包含重置密码链接等敏感信息
+type LogSender struct{}
+
+// NewLogSender 创建日志邮件发送
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+// Send 记录邮件
+func (s *LogSender) Send(ctx context.Context, msg *Message) error {
+	mailLogger.WithContext(ctx).Info("发送邮件（仅记录日志）", map[string]interface{}{
+		"to":      msg.To,
+		"subject": msg.Subject,
+	})
+	return nil
+}
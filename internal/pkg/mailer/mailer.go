@@ -0,0 +1,67 @@
+// Package mailer 邮件发送
+// 发送方式由配置email.provider决定：log只写日志，smtp通过SMTP服务器发送；
+// 第三方邮件服务（如SES、SendGrid）通过RegisterProvider注册后在配置中按名称选用
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/secrets"
+)
+
+// Message 邮件，正文为纯文本
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender 邮件发送接口
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Factory 根据配置创建发送服务，密码等凭据从密钥提供者读取
+type Factory func(cfg config.EmailConfig, provider secrets.Provider) (Sender, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Factory{
+		"log": func(config.EmailConfig, secrets.Provider) (Sender, error) {
+			return NewLogSender(), nil
+		},
+		"smtp": func(cfg config.EmailConfig, provider secrets.Provider) (Sender, error) {
+			return NewSMTPSender(cfg, provider), nil
+		},
+	}
+)
+
+// RegisterProvider 注册邮件发送服务，名称已存在时覆盖
+func RegisterProvider(name string, factory Factory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = factory
+}
+
+// NewSender 按配置创建邮件发送服务
+func NewSender(cfg config.EmailConfig, provider secrets.Provider) (Sender, error) {
+	providersMu.RLock()
+	factory, ok := providers[cfg.Provider]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported email provider: %s", cfg.Provider)
+	}
+	return factory(cfg, provider)
+}
+
+// NewSenderFromConfig 按应用配置创建密钥提供者和邮件发送服务（命令行工具、定时任务等没有现成密钥提供者时使用）
+func NewSenderFromConfig(cfg *config.Config) (Sender, error) {
+	provider, err := secrets.NewProvider(cfg.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	return NewSender(cfg.Email, provider)
+}
@@ -0,0 +1,176 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/secrets"
+)
+
+// SMTPSender 通过SMTP服务器发送邮件
+// 每封邮件建立一个连接；密码在每次发送时从密钥提供者读取，密钥轮换后无需重启
+type SMTPSender struct {
+	cfg      config.EmailConfig
+	provider secrets.Provider
+}
+
+// NewSMTPSender 创建SMTP邮件发送
+func NewSMTPSender(cfg config.EmailConfig, provider secrets.Provider) *SMTPSender {
+	return &SMTPSender{
+		cfg:      cfg,
+		provider: provider,
+	}
+}
+
+// Send 发送邮件，ctx的截止时间同时作为连接和收发的超时
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+	data, err := s.build(msg, to)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := s.auth(ctx, client); err != nil {
+		return err
+	}
+	if err := client.Mail(s.cfg.From); err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %w", err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return fmt.Errorf("smtp RCPT TO failed: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	return client.Quit()
+}
+
+// dial 连接SMTP服务器，security为tls时使用隐式TLS，starttls时要求服务器支持STARTTLS
+func (s *SMTPSender) dial(ctx context.Context) (*smtp.Client, error) {
+	host := s.cfg.SMTP.Host
+	addr := net.JoinHostPort(host, strconv.Itoa(s.cfg.SMTP.Port))
+	tlsConfig := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if s.cfg.SMTP.Security == "tls" {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	if s.cfg.SMTP.Security == "starttls" {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, errors.New("smtp server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp STARTTLS failed: %w", err)
+		}
+	}
+	return client, nil
+}
+
+// auth 配置了用户名时使用PLAIN认证（net/smtp只允许在TLS连接或localhost上发送明文密码）
+func (s *SMTPSender) auth(ctx context.Context, client *smtp.Client) error {
+	if s.cfg.SMTP.Username == "" {
+		return nil
+	}
+	password, err := s.provider.Get(ctx, s.cfg.SMTP.PasswordSecret)
+	if err != nil {
+		return fmt.Errorf("failed to load smtp password: %w", err)
+	}
+	if err := client.Auth(smtp.PlainAuth("", s.cfg.SMTP.Username, password, s.cfg.SMTP.Host)); err != nil {
+		return fmt.Errorf("smtp auth failed: %w", err)
+	}
+	return nil
+}
+
+// build 构建邮件内容，主题按RFC 2047编码，正文为UTF-8纯文本（quoted-printable）
+func (s *SMTPSender) build(msg *Message, to *mail.Address) ([]byte, error) {
+	from := mail.Address{Name: s.cfg.FromName, Address: s.cfg.From}
+	messageID, err := newMessageID(s.cfg.From)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	headers := [][2]string{
+		{"From", from.String()},
+		{"To", to.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", clock.Now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=utf-8"},
+		{"Content-Transfer-Encoding", "quoted-printable"},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", h[0], h[1])
+	}
+	buf.WriteString("\r\n")
+
+	body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n"))); err != nil {
+		return nil, fmt.Errorf("failed to encode message body: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode message body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// newMessageID 生成Message-ID，域名取发件地址的域名
+func newMessageID(from string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate message id: %w", err)
+	}
+	domain := "localhost"
+	if _, d, ok := strings.Cut(from, "@"); ok && d != "" {
+		domain = d
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(buf), domain), nil
+}
@@ -0,0 +1,176 @@
+// Package maintenance 维护模式
+// 维护状态保存在Redis中，所有实例共享；各实例在内存中保存快照，快照超过check_seconds后重新加载，
+// 通过本实例修改时立即重新加载。Redis不可用时沿用上次加载的状态
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/database"
+	appLogger "exchange/internal/pkg/logger"
+)
+
+// stateKey 维护状态的Redis键
+const stateKey = "maintenance:state"
+
+// maintenanceLogger 维护模式日志
+var maintenanceLogger = appLogger.Module("maintenance")
+
+// State 维护状态
+type State struct {
+	Enabled    bool       `json:"enabled"`
+	Notice     string     `json:"notice,omitempty"`     // 附加说明，随503响应返回给客户端
+	RetryAfter int        `json:"retry_after"`          // 建议客户端重试的等待秒数（Retry-After响应头）
+	StartedAt  *time.Time `json:"started_at,omitempty"` // 开启时间
+	AdminID    uint       `json:"admin_id,omitempty"`   // 开启维护模式的管理员
+}
+
+// Store 维护状态存储
+type Store struct {
+	redis *database.RedisService
+	ttl   time.Duration
+
+	reloadMu sync.Mutex // 同一时间只有一个请求重新加载
+
+	mu       sync.RWMutex
+	state    State
+	loadedAt time.Time
+}
+
+// NewStore 创建维护状态存储，redis为nil时维护模式始终关闭
+func NewStore(redis *database.RedisService, cfg config.MaintenanceConfig) *Store {
+	return &Store{
+		redis: redis,
+		ttl:   time.Duration(cfg.CheckSeconds) * time.Second,
+	}
+}
+
+// Current 当前的维护状态（使用快照），用于请求路径
+func (s *Store) Current(ctx context.Context) State {
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) >= s.ttl
+	state := s.state
+	s.mu.RUnlock()
+	if !stale || s.redis == nil {
+		return state
+	}
+
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	s.mu.RLock()
+	stale = time.Since(s.loadedAt) >= s.ttl
+	s.mu.RUnlock()
+	if stale {
+		if err := s.load(ctx); err != nil {
+			maintenanceLogger.WithContext(ctx).Warn("加载维护状态失败，使用上次加载的状态", map[string]interface{}{
+				"error": err.Error(),
+			})
+			s.mu.Lock()
+			s.loadedAt = time.Now()
+			s.mu.Unlock()
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state
+}
+
+// Status 立即从Redis加载维护状态，用于管理接口
+func (s *Store) Status(ctx context.Context) (State, error) {
+	if s.redis == nil {
+		return State{}, nil
+	}
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	if err := s.load(ctx); err != nil {
+		return State{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state, nil
+}
+
+// Enable 开启维护模式，已开启时更新说明和重试时间（保留开启时间）
+func (s *Store) Enable(ctx context.Context, state State) (State, error) {
+	if s.redis == nil {
+		return State{}, errors.New("maintenance mode requires redis")
+	}
+	current, err := s.Status(ctx)
+	if err != nil {
+		return State{}, err
+	}
+
+	now := time.Now()
+	state.Enabled = true
+	state.StartedAt = &now
+	if current.Enabled && current.StartedAt != nil {
+		state.StartedAt = current.StartedAt
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return State{}, fmt.Errorf("failed to encode maintenance state: %w", err)
+	}
+	if err := s.redis.Client().Set(ctx, stateKey, data, 0).Err(); err != nil {
+		return State{}, fmt.Errorf("failed to save maintenance state: %w", err)
+	}
+	s.invalidate(ctx)
+	return state, nil
+}
+
+// Disable 关闭维护模式，返回关闭前的状态
+func (s *Store) Disable(ctx context.Context) (State, error) {
+	if s.redis == nil {
+		return State{}, nil
+	}
+	current, err := s.Status(ctx)
+	if err != nil {
+		return State{}, err
+	}
+	if err := s.redis.Client().Del(ctx, stateKey).Err(); err != nil {
+		return State{}, fmt.Errorf("failed to clear maintenance state: %w", err)
+	}
+	s.invalidate(ctx)
+	return current, nil
+}
+
+// invalidate 修改后重新加载，失败时在下次请求时重试
+func (s *Store) invalidate(ctx context.Context) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	if err := s.load(ctx); err != nil {
+		s.mu.Lock()
+		s.loadedAt = time.Time{}
+		s.mu.Unlock()
+	}
+}
+
+// load 从Redis加载维护状态，需持有reloadMu
+func (s *Store) load(ctx context.Context) error {
+	var state State
+	data, err := s.redis.Client().Get(ctx, stateKey).Bytes()
+	switch {
+	case errors.Is(err, redis.Nil):
+	case err != nil:
+		return fmt.Errorf("failed to load maintenance state: %w", err)
+	default:
+		if err := json.Unmarshal(data, &state); err != nil {
+			return fmt.Errorf("failed to decode maintenance state: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	s.state = state
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
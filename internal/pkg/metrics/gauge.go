@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewGaugeVec 在默认注册表中创建带标签的仪表
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return defaultRegistry.NewGaugeVec(name, help, labels...)
+}
+
+// NewGaugeVec 创建带标签的仪表，同名指标重复创建时返回已有的仪表
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	vec := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, labels)
+	return &GaugeVec{vec: register(r, name, vec)}
+}
+
+// GaugeVec 带标签的仪表，记录可增可减的当前值（如队列长度、工作协程数）
+type GaugeVec struct {
+	vec *prometheus.GaugeVec
+}
+
+// Set 设置当前值，标签值按创建时的标签顺序传入
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.vec.WithLabelValues(labelValues...).Set(value)
+}
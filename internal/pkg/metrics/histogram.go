@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewHistogramVec 在默认注册表中创建带标签的直方图
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return defaultRegistry.NewHistogramVec(name, help, buckets, labels...)
+}
+
+// NewHistogramVec 创建带标签的直方图，buckets为升序的桶上界（不含+Inf），同名指标重复创建时返回已有的直方图
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: help, Buckets: buckets}, labels)
+	return &HistogramVec{vec: register(r, name, vec)}
+}
+
+// HistogramVec 带标签的直方图，记录观测值的分布（如耗时）
+type HistogramVec struct {
+	vec *prometheus.HistogramVec
+}
+
+// Observe 记录一个观测值，标签值按创建时的标签顺序传入
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.vec.WithLabelValues(labelValues...).Observe(value)
+}
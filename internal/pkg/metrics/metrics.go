@@ -0,0 +1,88 @@
+// Package metrics 进程内指标，基于Prometheus客户端库以Prometheus文本格式导出
+// 封装计数器、仪表和直方图，满足错误统计、队列积压、操作耗时等场景；标签值应取自有限集合（如路由模板、错误码），避免基数膨胀
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry 指标注册表
+type Registry struct {
+	registry *prometheus.Registry
+}
+
+// defaultRegistry 默认注册表，/metrics导出该注册表中的指标，包含Go运行时和进程指标
+var defaultRegistry = newDefaultRegistry()
+
+// newDefaultRegistry 创建默认注册表
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return r
+}
+
+// NewRegistry 创建指标注册表
+func NewRegistry() *Registry {
+	return &Registry{registry: prometheus.NewRegistry()}
+}
+
+// Default 获取默认注册表
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// register 注册指标，同名指标重复注册时返回已有的指标，类型或标签不一致时panic
+func register[T prometheus.Collector](r *Registry, name string, c T) T {
+	err := r.registry.Register(c)
+	if err == nil {
+		return c
+	}
+
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		if existing, ok := registered.ExistingCollector.(T); ok {
+			return existing
+		}
+	}
+	panic(fmt.Sprintf("metrics: %s already registered with a different type or labels: %v", name, err))
+}
+
+// NewCounterVec 在默认注册表中创建带标签的计数器
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return defaultRegistry.NewCounterVec(name, help, labels...)
+}
+
+// NewCounterVec 创建带标签的计数器，同名指标重复创建时返回已有的计数器
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labels)
+	return &CounterVec{vec: register(r, name, vec)}
+}
+
+// CounterVec 带标签的计数器
+type CounterVec struct {
+	vec *prometheus.CounterVec
+}
+
+// Inc 计数加1，标签值按创建时的标签顺序传入
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.vec.WithLabelValues(labelValues...).Inc()
+}
+
+// Add 计数增加delta
+func (c *CounterVec) Add(delta uint64, labelValues ...string) {
+	c.vec.WithLabelValues(labelValues...).Add(float64(delta))
+}
+
+// Handler 导出指标的HTTP处理器
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{})
+}
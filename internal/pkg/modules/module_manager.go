@@ -1,7 +1,11 @@
 package modules
 
 import (
+	"context"
+	"crypto/subtle"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -9,10 +13,12 @@ import (
 	"exchange/internal/middleware"
 	"exchange/internal/modules/admin"
 	"exchange/internal/modules/api"
+	"exchange/internal/pkg/apidocs"
 	"exchange/internal/pkg/config"
 	"exchange/internal/pkg/database"
 	"exchange/internal/pkg/i18n"
 	"exchange/internal/pkg/logger"
+	"exchange/internal/pkg/metrics"
 	"exchange/internal/pkg/services"
 )
 
@@ -127,9 +133,10 @@ func (m *ModuleManager) initI18n() error {
 func (m *ModuleManager) initAPIModule() error {
 	// 创建API模块，传入数据库服务
 	m.apiModule = api.NewModule(
-		m.config, // 应用配置
-		m.mysql,  // MySQL数据库服务
-		m.redis,  // Redis缓存服务
+		m.config,  // 应用配置
+		m.mysql,   // MySQL数据库服务
+		m.redis,   // Redis缓存服务
+		m.mongodb, // MongoDB服务（会话导出读取消息）
 	)
 
 	// 将API模块的路由设置函数添加到列表中
@@ -143,9 +150,10 @@ func (m *ModuleManager) initAPIModule() error {
 func (m *ModuleManager) initAdminModule() error {
 	// 创建Admin模块，传入数据库服务
 	m.adminModule = admin.NewModule(
-		m.config, // 应用配置
-		m.mysql,  // MySQL数据库服务
-		m.redis,  // Redis缓存服务
+		m.config,  // 应用配置
+		m.mysql,   // MySQL数据库服务
+		m.redis,   // Redis缓存服务
+		m.mongodb, // MongoDB服务（用户概览读取消息）
 	)
 
 	// 将Admin模块的路由设置函数添加到列表中
@@ -160,6 +168,11 @@ func (m *ModuleManager) SetupRoutes(engine *gin.Engine) {
 	// 添加i18n中间件
 	engine.Use(middleware.I18nMiddleware(m.i18nManager))
 
+	// 记录接口文档示例（开发和测试环境）
+	if m.config.DocExamples.Enabled {
+		m.setupDocExamples(engine)
+	}
+
 	// 设置各模块的路由
 	for _, setupFunc := range m.routeSetupFuncs {
 		setupFunc(engine)
@@ -172,10 +185,73 @@ func (m *ModuleManager) SetupRoutes(engine *gin.Engine) {
 			"time":    time.Now().Unix(),
 		})
 	})
+	middleware.GetAuthMatrix().ClassifyRoute("GET", "/ping", middleware.PublicRequirement())
+
+	// 指标导出（由令牌保护，不经过用户认证）
+	if m.config.Metrics.Enabled {
+		engine.GET(m.config.Metrics.Path, m.metricsHandler)
+		middleware.GetAuthMatrix().ClassifyRoute("GET", m.config.Metrics.Path, middleware.PublicRequirement())
+	}
+
+	// 检查是否有路由未声明认证要求，开发模式下直接终止启动
+	if err := middleware.GetAuthMatrix().Verify(engine.Routes()); err != nil {
+		if gin.Mode() == gin.DebugMode {
+			panic(err)
+		}
+		logger.Error("路由权限矩阵检查失败", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
 
 	logger.Info("所有路由设置成功", nil)
 }
 
+// setupDocExamples 注册示例记录中间件和示例文档接口 GET /docs/examples
+func (m *ModuleManager) setupDocExamples(engine *gin.Engine) {
+	recorder, err := apidocs.NewRecorder(m.config.DocExamples.File)
+	if err != nil {
+		logger.Error("接口文档示例加载失败", map[string]interface{}{
+			"file":  m.config.DocExamples.File,
+			"error": err.Error(),
+		})
+		return
+	}
+
+	engine.Use(middleware.DocExamplesMiddleware(recorder, m.config.DocExamples.MaxBodyBytes))
+	engine.GET(middleware.DocExamplesPath, func(c *gin.Context) {
+		c.JSON(http.StatusOK, recorder.Document())
+	})
+	middleware.GetAuthMatrix().ClassifyRoute("GET", middleware.DocExamplesPath, middleware.PublicRequirement())
+}
+
+// metricsHandler 以Prometheus文本格式导出指标
+func (m *ModuleManager) metricsHandler(c *gin.Context) {
+	if token := m.config.Metrics.Token; token != "" {
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+	}
+	metrics.Default().Handler().ServeHTTP(c.Writer, c.Request)
+}
+
+// DrainConnections 断开本实例的WebSocket连接，在关闭Redis之前调用
+// 客户端收到reconnect事件后重连到其他实例，超过timeout仍未断开的连接在服务器关闭时强制断开
+func (m *ModuleManager) DrainConnections(timeout time.Duration) {
+	if m.apiModule == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := m.apiModule.DrainWebSockets(ctx); err != nil {
+		logger.Warn("WebSocket连接未全部断开", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
 // Shutdown 关闭模块管理器
 func (m *ModuleManager) Shutdown() error {
 	// 注意：不关闭数据库连接，因为由全局服务管理
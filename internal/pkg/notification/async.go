@@ -0,0 +1,82 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"exchange/internal/pkg/config"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/workerpool"
+)
+
+// delivery 异步发送工作池，未启用时为nil
+var delivery atomic.Pointer[asyncDelivery]
+
+// asyncDelivery 异步发送工作池和单条通知的发送超时
+type asyncDelivery struct {
+	pool    *workerpool.Pool
+	timeout time.Duration
+}
+
+// EnableAsyncDelivery 按配置启用通知异步发送，未启用时AsyncNotifier同步发送
+func EnableAsyncDelivery(cfg config.NotificationConfig) {
+	if !cfg.Async {
+		return
+	}
+
+	pool := workerpool.New("notification", cfg.QueueSize, cfg.Workers, func(err error) {
+		appLogger.Error("通知发送失败", map[string]interface{}{
+			"error": err.Error(),
+		})
+	})
+	previous := delivery.Swap(&asyncDelivery{
+		pool:    pool,
+		timeout: time.Duration(cfg.TimeoutMs) * time.Millisecond,
+	})
+	if previous != nil {
+		previous.pool.Close(0)
+	}
+}
+
+// CloseAsyncDelivery 停止接收新通知并等待队列中的通知发送完成，超时后返回
+func CloseAsyncDelivery(timeout time.Duration) {
+	if previous := delivery.Swap(nil); previous != nil {
+		previous.pool.Close(timeout)
+	}
+}
+
+// AsyncNotifier 将通知交给异步发送工作池，不等待发送结果
+// 工作池队列满或暂停接收（发送持续失败）时返回错误，由调用方按发送失败处理；未启用异步发送时同步调用next
+type AsyncNotifier struct {
+	next Notifier
+}
+
+// NewAsyncNotifier 创建异步通知器
+func NewAsyncNotifier(next Notifier) *AsyncNotifier {
+	return &AsyncNotifier{next: next}
+}
+
+// Notify 提交通知
+func (n *AsyncNotifier) Notify(ctx context.Context, notification *Notification) error {
+	d := delivery.Load()
+	if d == nil {
+		return n.next.Notify(ctx, notification)
+	}
+
+	// 请求结束后仍需发送，保留上下文中的值（如请求ID）但不随请求取消
+	ctx = context.WithoutCancel(ctx)
+	err := d.pool.Submit(func(context.Context) error {
+		sendCtx, cancel := context.WithTimeout(ctx, d.timeout)
+		defer cancel()
+		if err := n.next.Notify(sendCtx, notification); err != nil {
+			return fmt.Errorf("notify user %d (%s): %w", notification.UserID, notification.Event, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enqueue notification: %w", err)
+	}
+	return nil
+}
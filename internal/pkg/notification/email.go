@@ -0,0 +1,41 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	"exchange/internal/pkg/mailer"
+)
+
+// EmailNotifier 通过邮件发送TemplateNotifier渲染的邮件消息，再交给下一个通知器
+// 通知没有收件邮箱或没有渲染邮件消息时直接转发
+type EmailNotifier struct {
+	next   Notifier
+	sender mailer.Sender
+}
+
+// NewEmailNotifier 创建邮件通知器
+func NewEmailNotifier(next Notifier, sender mailer.Sender) *EmailNotifier {
+	return &EmailNotifier{next: next, sender: sender}
+}
+
+// Notify 发送邮件并转发通知
+func (n *EmailNotifier) Notify(ctx context.Context, notification *Notification) error {
+	if notification.Email != "" {
+		for _, message := range notification.Messages {
+			if message.Channel != ChannelEmail {
+				continue
+			}
+			err := n.sender.Send(ctx, &mailer.Message{
+				To:      notification.Email,
+				Subject: message.Subject,
+				Body:    message.Body,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to send %s email: %w", notification.Event, err)
+			}
+		}
+	}
+
+	return n.next.Notify(ctx, notification)
+}
@@ -0,0 +1,65 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"exchange/internal/pkg/clock"
+	appLogger "exchange/internal/pkg/logger"
+)
+
+// Notification 用户通知
+type Notification struct {
+	UserID    uint                   `json:"user_id"`
+	Event     string                 `json:"event"`              // 通知事件
+	Email     string                 `json:"email,omitempty"`    // 收件邮箱（账户匿名化前的快照）
+	Language  string                 `json:"language,omitempty"` // 用户语言，为空时使用默认语言
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Messages  []*RenderedMessage     `json:"messages,omitempty"` // 按渠道渲染后的消息（TemplateNotifier填充）
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// Notifier 通知发送接口
+type Notifier interface {
+	Notify(ctx context.Context, n *Notification) error
+}
+
+// LogNotifier 将通知写入日志（未接入邮件/推送渠道时的默认实现）
+type LogNotifier struct{}
+
+// NewLogNotifier 创建日志通知器
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+// Notify 发送通知
+func (n *LogNotifier) Notify(ctx context.Context, notification *Notification) error {
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = clock.Now()
+	}
+
+	appLogger.Info("发送用户通知", map[string]interface{}{
+		"user_id":   notification.UserID,
+		"event":     notification.Event,
+		"has_email": notification.Email != "",
+		"data":      notification.Data,
+		"messages":  summarizeMessages(notification.Messages),
+	})
+
+	return nil
+}
+
+// summarizeMessages 日志中只记录消息的渠道、语言、来源和主题，不记录正文
+func summarizeMessages(messages []*RenderedMessage) []map[string]interface{} {
+	summary := make([]map[string]interface{}, 0, len(messages))
+	for _, message := range messages {
+		summary = append(summary, map[string]interface{}{
+			"channel": message.Channel,
+			"locale":  message.Locale,
+			"source":  message.Source,
+			"version": message.Version,
+			"subject": message.Subject,
+		})
+	}
+	return summary
+}
@@ -0,0 +1,366 @@
+package notification
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// 通知渠道
+const (
+	ChannelEmail = "email"  // 邮件，需要主题
+	ChannelInApp = "in_app" // 站内信，主题作为标题
+)
+
+// Channels 所有支持的渠道
+var Channels = []string{ChannelEmail, ChannelInApp}
+
+// IsValidChannel 是否为支持的渠道
+func IsValidChannel(channel string) bool {
+	for _, c := range Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// 模板变量类型
+const (
+	VarString = "string"
+	VarNumber = "number"
+	VarTime   = "time" // time.Time或Unix纳秒时间戳，使用 {{formatTime .Data.xxx}} 输出
+	VarURL    = "url"
+)
+
+// Variable 事件数据中可在模板里使用的变量（{{.Data.name}}）
+type Variable struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Description string      `json:"description"`
+	Example     interface{} `json:"example"` // 预览时使用的示例值
+}
+
+// EventSchema 通知事件的变量定义，与发送方写入Notification.Data的字段一致
+type EventSchema struct {
+	Event       string     `json:"event"`
+	Description string     `json:"description"`
+	Channels    []string   `json:"channels"`
+	Variables   []Variable `json:"variables"`
+}
+
+// TemplateData 模板可用的数据，如 {{.Email}}、{{.Data.invite_link}}
+type TemplateData struct {
+	UserID    uint
+	Email     string
+	Event     string
+	Locale    string
+	Data      map[string]interface{}
+	CreatedAt time.Time
+}
+
+// templateFields TemplateData中可在模板根上下文使用的字段
+var templateFields = map[string]bool{
+	"UserID": true, "Email": true, "Event": true, "Locale": true, "Data": true, "CreatedAt": true,
+}
+
+// exampleTime 示例数据使用的时间
+var exampleTime = time.Date(2024, 1, 15, 8, 30, 0, 0, time.UTC)
+
+// schemas 支持模板的通知事件；未在此注册的事件（如自动化消息）由发送方自行组织内容
+var schemas = map[string]*EventSchema{
+	"user_invited": {
+		Event:       "user_invited",
+		Description: "管理员批量导入用户后发送的邀请",
+		Channels:    []string{ChannelEmail},
+		Variables: []Variable{
+			{Name: "invite_link", Type: VarURL, Description: "设置密码的邀请链接", Example: "https://example.com/invite?token=example"},
+			{Name: "expires_at", Type: VarTime, Description: "邀请链接过期时间", Example: exampleTime.Add(72 * time.Hour)},
+		},
+	},
+	"account_deletion_requested": {
+		Event:       "account_deletion_requested",
+		Description: "用户提交注销申请，进入冷静期",
+		Channels:    []string{ChannelEmail, ChannelInApp},
+		Variables: []Variable{
+			{Name: "request_id", Type: VarNumber, Description: "注销申请ID", Example: 1001},
+			{Name: "scheduled_at", Type: VarTime, Description: "计划执行注销的时间", Example: exampleTime.AddDate(0, 0, 15).UnixNano()},
+			{Name: "grace_days", Type: VarNumber, Description: "冷静期天数", Example: 15},
+		},
+	},
+	"account_deletion_cancelled": {
+		Event:       "account_deletion_cancelled",
+		Description: "用户撤销注销申请",
+		Channels:    []string{ChannelEmail, ChannelInApp},
+		Variables: []Variable{
+			{Name: "request_id", Type: VarNumber, Description: "注销申请ID", Example: 1001},
+		},
+	},
+	"account_deletion_rejected": {
+		Event:       "account_deletion_rejected",
+		Description: "冷静期结束后注销前置校验未通过",
+		Channels:    []string{ChannelEmail, ChannelInApp},
+		Variables: []Variable{
+			{Name: "request_id", Type: VarNumber, Description: "注销申请ID", Example: 1001},
+			{Name: "reason", Type: VarString, Description: "未通过的原因", Example: "legal_hold: account is under legal hold"},
+		},
+	},
+	"account_deletion_completed": {
+		Event:       "account_deletion_completed",
+		Description: "注销完成，个人信息已匿名化",
+		Channels:    []string{ChannelEmail},
+		Variables: []Variable{
+			{Name: "request_id", Type: VarNumber, Description: "注销申请ID", Example: 1001},
+		},
+	},
+	"password_reset_requested": {
+		Event:       "password_reset_requested",
+		Description: "用户申请找回密码",
+		Channels:    []string{ChannelEmail},
+		Variables: []Variable{
+			{Name: "reset_link", Type: VarURL, Description: "重置密码链接", Example: "https://example.com/reset-password?token=example"},
+			{Name: "expires_at", Type: VarTime, Description: "重置链接过期时间", Example: exampleTime.Add(30 * time.Minute)},
+		},
+	},
+	"email_verification_requested": {
+		Event:       "email_verification_requested",
+		Description: "用户注册或重新发送验证邮件",
+		Channels:    []string{ChannelEmail},
+		Variables: []Variable{
+			{Name: "verify_link", Type: VarURL, Description: "验证邮箱链接", Example: "https://example.com/verify-email?token=example"},
+			{Name: "expires_at", Type: VarTime, Description: "验证链接过期时间", Example: exampleTime.Add(24 * time.Hour)},
+		},
+	},
+	"new_device_login": {
+		Event:       "new_device_login",
+		Description: "用户从新设备或新的国家登录",
+		Channels:    []string{ChannelEmail, ChannelInApp},
+		Variables: []Variable{
+			{Name: "device", Type: VarString, Description: "登录设备的User-Agent", Example: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0"},
+			{Name: "ip", Type: VarString, Description: "登录IP", Example: "203.0.113.10"},
+			{Name: "country", Type: VarString, Description: "登录所在国家代码，无法确定时为空", Example: "US"},
+			{Name: "login_at", Type: VarTime, Description: "登录时间", Example: exampleTime},
+		},
+	},
+	"login_confirmation_requested": {
+		Event:       "login_confirmation_requested",
+		Description: "从新设备或新的国家登录需要通过邮件确认",
+		Channels:    []string{ChannelEmail},
+		Variables: []Variable{
+			{Name: "confirm_link", Type: VarURL, Description: "确认登录链接", Example: "https://example.com/confirm-login?token=example"},
+			{Name: "expires_at", Type: VarTime, Description: "确认链接过期时间", Example: exampleTime.Add(30 * time.Minute)},
+			{Name: "device", Type: VarString, Description: "登录设备的User-Agent", Example: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0"},
+			{Name: "ip", Type: VarString, Description: "登录IP", Example: "203.0.113.10"},
+			{Name: "country", Type: VarString, Description: "登录所在国家代码，无法确定时为空", Example: "US"},
+		},
+	},
+}
+
+// Schemas 所有支持模板的事件，按事件名排序
+func Schemas() []*EventSchema {
+	list := make([]*EventSchema, 0, len(schemas))
+	for _, schema := range schemas {
+		list = append(list, schema)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Event < list[j].Event })
+	return list
+}
+
+// LookupSchema 获取事件的变量定义
+func LookupSchema(event string) (*EventSchema, bool) {
+	schema, ok := schemas[event]
+	return schema, ok
+}
+
+// SupportsChannel 事件是否支持指定渠道
+func (s *EventSchema) SupportsChannel(channel string) bool {
+	for _, c := range s.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// SampleData 使用示例值构造的模板数据，用于预览
+func (s *EventSchema) SampleData(locale string) TemplateData {
+	data := make(map[string]interface{}, len(s.Variables))
+	for _, v := range s.Variables {
+		data[v.Name] = v.Example
+	}
+	return TemplateData{
+		UserID:    10001,
+		Email:     "user@example.com",
+		Event:     s.Event,
+		Locale:    locale,
+		Data:      data,
+		CreatedAt: exampleTime,
+	}
+}
+
+// variableNames 变量名列表
+func (s *EventSchema) variableNames() []string {
+	names := make([]string, 0, len(s.Variables))
+	for _, v := range s.Variables {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+// hasVariable 是否定义了变量
+func (s *EventSchema) hasVariable(name string) bool {
+	for _, v := range s.Variables {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTemplate 检查模板语法，并检查模板引用的变量是否都在事件的变量定义中
+// with/range内部的点已不再指向根数据，只检查通过$引用的变量
+func ValidateTemplate(event, channel, subject, body string) error {
+	schema, ok := LookupSchema(event)
+	if !ok {
+		return fmt.Errorf("unsupported event: %s", event)
+	}
+	if !schema.SupportsChannel(channel) {
+		return fmt.Errorf("event %s does not support channel %s", event, channel)
+	}
+	if channel == ChannelEmail && strings.TrimSpace(subject) == "" {
+		return fmt.Errorf("subject is required for email")
+	}
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("body is required")
+	}
+
+	for name, text := range map[string]string{"subject": subject, "body": body} {
+		tmpl, err := parseTemplate(name, text)
+		if err != nil {
+			return fmt.Errorf("invalid %s template: %w", name, err)
+		}
+		for _, t := range tmpl.Templates() {
+			if t.Tree == nil {
+				continue
+			}
+			if err := checkNode(schema, t.Tree.Root, true); err != nil {
+				return fmt.Errorf("%s template: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkNode 检查节点中引用的变量，atRoot表示点是否指向根数据
+func checkNode(schema *EventSchema, node parse.Node, atRoot bool) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkNode(schema, child, atRoot); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkNode(schema, n.Pipe, atRoot)
+	case *parse.IfNode:
+		return checkBranch(schema, &n.BranchNode, atRoot, atRoot)
+	case *parse.RangeNode:
+		return checkBranch(schema, &n.BranchNode, atRoot, false)
+	case *parse.WithNode:
+		return checkBranch(schema, &n.BranchNode, atRoot, false)
+	case *parse.TemplateNode:
+		if n.Pipe != nil {
+			return checkNode(schema, n.Pipe, atRoot)
+		}
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				if err := checkNode(schema, arg, atRoot); err != nil {
+					return err
+				}
+			}
+		}
+	case *parse.FieldNode:
+		if atRoot {
+			return checkField(schema, n.Ident)
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			return checkField(schema, n.Ident[1:])
+		}
+	}
+	return nil
+}
+
+// checkBranch 检查if/range/with，listAtRoot表示分支内的点是否仍指向根数据
+func checkBranch(schema *EventSchema, n *parse.BranchNode, atRoot, listAtRoot bool) error {
+	if err := checkNode(schema, n.Pipe, atRoot); err != nil {
+		return err
+	}
+	if err := checkNode(schema, n.List, listAtRoot); err != nil {
+		return err
+	}
+	return checkNode(schema, n.ElseList, atRoot)
+}
+
+// checkField 检查根数据上的字段引用，如 .Email、.Data.invite_link
+func checkField(schema *EventSchema, ident []string) error {
+	if len(ident) == 0 {
+		return nil
+	}
+	if !templateFields[ident[0]] {
+		return fmt.Errorf("unknown field .%s", ident[0])
+	}
+	if ident[0] == "Data" && len(ident) > 1 && !schema.hasVariable(ident[1]) {
+		return fmt.Errorf("unknown variable .Data.%s for event %s, available: %s",
+			ident[1], schema.Event, strings.Join(schema.variableNames(), ", "))
+	}
+	return nil
+}
+
+// parseTemplate 解析模板，缺失的键输出零值
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+}
+
+// templateFuncs 模板可用的函数
+var templateFuncs = template.FuncMap{
+	"formatTime": formatTime,
+}
+
+// formatTime 格式化时间（UTC），支持time.Time和Unix纳秒时间戳，可选第二个参数指定格式
+func formatTime(value interface{}, layout ...string) string {
+	format := "2006-01-02 15:04 MST"
+	if len(layout) > 0 && layout[0] != "" {
+		format = layout[0]
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC().Format(format)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.UTC().Format(format)
+	case int64:
+		return time.Unix(0, v).UTC().Format(format)
+	case int:
+		return time.Unix(0, int64(v)).UTC().Format(format)
+	case float64:
+		return time.Unix(0, int64(v)).UTC().Format(format)
+	case string:
+		return v
+	default:
+		return ""
+	}
+}
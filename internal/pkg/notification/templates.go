@@ -0,0 +1,229 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"exchange/internal/models/mysql"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/repository"
+)
+
+// 模板来源
+const (
+	SourceCustom  = "custom"  // 管理员编辑的模板
+	SourceDefault = "default" // 内置默认模板
+)
+
+// fallbackLocale 内置默认模板一定包含的语言
+const fallbackLocale = "en"
+
+//go:embed defaults/templates.json
+var defaultTemplatesJSON []byte
+
+// Template 模板内容
+type Template struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// defaultTemplates 内置默认模板：事件 → 渠道 → 语言 → 模板
+var defaultTemplates = mustLoadDefaults()
+
+// mustLoadDefaults 加载内置默认模板
+func mustLoadDefaults() map[string]map[string]map[string]Template {
+	var templates map[string]map[string]map[string]Template
+	if err := json.Unmarshal(defaultTemplatesJSON, &templates); err != nil {
+		panic("invalid default notification templates: " + err.Error())
+	}
+	return templates
+}
+
+// DefaultTemplate 获取内置默认模板
+func DefaultTemplate(event, channel, locale string) (Template, bool) {
+	tpl, ok := defaultTemplates[event][channel][locale]
+	return tpl, ok
+}
+
+// DefaultLocales 内置默认模板支持的语言
+func DefaultLocales(event, channel string) []string {
+	locales := make([]string, 0, len(defaultTemplates[event][channel]))
+	for locale := range defaultTemplates[event][channel] {
+		locales = append(locales, locale)
+	}
+	return locales
+}
+
+// RenderedMessage 渲染后的消息
+type RenderedMessage struct {
+	Channel string `json:"channel"`
+	Locale  string `json:"locale"`            // 实际使用的模板语言
+	Source  string `json:"source"`            // custom/default
+	Version int    `json:"version,omitempty"` // 管理员模板的版本号，内置模板为0
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// RenderTemplate 渲染模板内容
+func RenderTemplate(tpl Template, data TemplateData) (subject, body string, err error) {
+	if subject, err = execute("subject", tpl.Subject, data); err != nil {
+		return "", "", err
+	}
+	if body, err = execute("body", tpl.Body, data); err != nil {
+		return "", "", err
+	}
+	return subject, body, nil
+}
+
+// execute 执行单个模板
+func execute(name, text string, data TemplateData) (string, error) {
+	tmpl, err := parseTemplate(name, text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// Renderer 按事件、渠道、语言选择模板并渲染
+// 每个候选语言依次尝试管理员启用的模板和内置默认模板；管理员模板读取或渲染失败时回退到内置默认模板，不影响通知发送
+type Renderer struct {
+	repo          repository.NotificationTemplateRepository // 为nil时只使用内置默认模板
+	defaultLocale string
+}
+
+// NewRenderer 创建模板渲染器
+func NewRenderer(repo repository.NotificationTemplateRepository, defaultLocale string) *Renderer {
+	return &Renderer{repo: repo, defaultLocale: defaultLocale}
+}
+
+// LocaleCandidates 模板语言匹配顺序：完整语言代码（zh-CN）→ 主语言（zh）→ 默认语言 → en
+func (r *Renderer) LocaleCandidates(locale string) []string {
+	candidates := make([]string, 0, 4)
+	seen := make(map[string]bool, 4)
+	add := func(l string) {
+		if l != "" && !seen[l] {
+			seen[l] = true
+			candidates = append(candidates, l)
+		}
+	}
+
+	add(locale)
+	if base, _, found := strings.Cut(locale, "-"); found {
+		add(base)
+	}
+	add(r.defaultLocale)
+	add(fallbackLocale)
+	return candidates
+}
+
+// Render 渲染通知消息
+func (r *Renderer) Render(ctx context.Context, event, channel, locale string, data TemplateData) (*RenderedMessage, error) {
+	for _, candidate := range r.LocaleCandidates(locale) {
+		if custom := r.activeTemplate(ctx, event, channel, candidate); custom != nil {
+			subject, body, err := RenderTemplate(Template{Subject: custom.Subject, Body: custom.Body}, data)
+			if err == nil {
+				return &RenderedMessage{
+					Channel: channel,
+					Locale:  candidate,
+					Source:  SourceCustom,
+					Version: custom.Version,
+					Subject: subject,
+					Body:    body,
+				}, nil
+			}
+			appLogger.Warn("通知模板渲染失败，使用内置默认模板", map[string]interface{}{
+				"event":   event,
+				"channel": channel,
+				"locale":  candidate,
+				"version": custom.Version,
+				"error":   err.Error(),
+			})
+		}
+
+		if tpl, ok := DefaultTemplate(event, channel, candidate); ok {
+			subject, body, err := RenderTemplate(tpl, data)
+			if err != nil {
+				return nil, err
+			}
+			return &RenderedMessage{
+				Channel: channel,
+				Locale:  candidate,
+				Source:  SourceDefault,
+				Subject: subject,
+				Body:    body,
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no template for event %s channel %s", event, channel)
+}
+
+// activeTemplate 获取管理员启用的模板，读取失败时返回nil
+func (r *Renderer) activeTemplate(ctx context.Context, event, channel, locale string) *mysql.NotificationTemplate {
+	if r.repo == nil {
+		return nil
+	}
+
+	tpl, err := r.repo.GetActive(ctx, event, channel, locale)
+	if err != nil {
+		appLogger.Warn("读取通知模板失败，使用内置默认模板", map[string]interface{}{
+			"event":   event,
+			"channel": channel,
+			"locale":  locale,
+			"error":   err.Error(),
+		})
+		return nil
+	}
+	return tpl
+}
+
+// TemplateNotifier 发送前按事件支持的渠道渲染消息，附加到Notification.Messages后交给下一个通知器
+// 未注册变量定义的事件直接转发；没有收件邮箱时不渲染邮件
+type TemplateNotifier struct {
+	next     Notifier
+	renderer *Renderer
+}
+
+// NewTemplateNotifier 创建模板通知器
+func NewTemplateNotifier(next Notifier, renderer *Renderer) *TemplateNotifier {
+	return &TemplateNotifier{next: next, renderer: renderer}
+}
+
+// Notify 渲染并发送通知
+func (n *TemplateNotifier) Notify(ctx context.Context, notification *Notification) error {
+	schema, ok := LookupSchema(notification.Event)
+	if !ok {
+		return n.next.Notify(ctx, notification)
+	}
+
+	data := TemplateData{
+		UserID:    notification.UserID,
+		Email:     notification.Email,
+		Event:     notification.Event,
+		Locale:    notification.Language,
+		Data:      notification.Data,
+		CreatedAt: notification.CreatedAt,
+	}
+	for _, channel := range schema.Channels {
+		if channel == ChannelEmail && notification.Email == "" {
+			continue
+		}
+
+		message, err := n.renderer.Render(ctx, notification.Event, channel, notification.Language, data)
+		if err != nil {
+			return fmt.Errorf("failed to render %s notification: %w", channel, err)
+		}
+		notification.Messages = append(notification.Messages, message)
+	}
+
+	return n.next.Notify(ctx, notification)
+}
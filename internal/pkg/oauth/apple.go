@@ -0,0 +1,97 @@
+package oauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+)
+
+const (
+	appleAuthURL  = "https://appleid.apple.com/auth/authorize"
+	appleTokenURL = "https://appleid.apple.com/auth/token"
+	appleIssuer   = "https://appleid.apple.com"
+
+	// appleClientSecretTTL 每次换取token时签发的client_secret有效期（Apple允许最长6个月）
+	appleClientSecretTTL = 5 * time.Minute
+)
+
+var appleDefaultScopes = []string{"name", "email"}
+
+// appleProvider Sign in with Apple，client_secret为以开发者私钥签名的ES256 JWT，用户信息取自id_token
+// 请求name或email权限时Apple以表单POST回调redirect_url
+type appleProvider struct {
+	config     config.OAuthProviderConfig
+	privateKey *ecdsa.PrivateKey
+	client     *http.Client
+}
+
+// newAppleProvider 创建Apple登录服务，privateKey为Apple开发者后台下载的私钥（PEM）
+func newAppleProvider(cfg config.OAuthProviderConfig, privateKey string, client *http.Client) (*appleProvider, error) {
+	key, err := jwt.ParseECPrivateKeyFromPEM([]byte(privateKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse apple private key: %w", err)
+	}
+	return &appleProvider{config: cfg, privateKey: key, client: client}, nil
+}
+
+// Name 服务商名称
+func (p *appleProvider) Name() string {
+	return config.OAuthProviderApple
+}
+
+// AuthCodeURL 授权地址
+func (p *appleProvider) AuthCodeURL(state, challenge string) string {
+	return authCodeURL(appleAuthURL, p.config, scopesOrDefault(p.config.Scopes, appleDefaultScopes), state, challenge,
+		url.Values{"response_mode": {"form_post"}})
+}
+
+// Exchange 用授权码换取token，从id_token读取用户标识和邮箱
+// 用户选择隐藏邮箱时为Apple的转发地址（privaterelay.appleid.com），同样视为已验证
+func (p *appleProvider) Exchange(ctx context.Context, code, verifier string) (*Profile, error) {
+	secret, err := p.clientSecret()
+	if err != nil {
+		return nil, err
+	}
+	token, err := exchangeCode(ctx, p.client, appleTokenURL, codeForm(p.config, secret, code, verifier))
+	if err != nil {
+		return nil, err
+	}
+	claims, err := parseIDToken(token.IDToken, []string{appleIssuer}, p.config.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Profile{
+		Provider:      p.Name(),
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+	}, nil
+}
+
+// clientSecret 签发client_secret
+func (p *appleProvider) clientSecret() (string, error) {
+	now := clock.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+		Issuer:    p.config.TeamID,
+		Subject:   p.config.ClientID,
+		Audience:  jwt.ClaimStrings{appleIssuer},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(appleClientSecretTTL)),
+	})
+	token.Header["kid"] = p.config.KeyID
+
+	secret, err := token.SignedString(p.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign apple client secret: %w", err)
+	}
+	return secret, nil
+}
@@ -0,0 +1,108 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"exchange/internal/pkg/config"
+)
+
+const (
+	githubAuthURL   = "https://github.com/login/oauth/authorize"
+	githubTokenURL  = "https://github.com/login/oauth/access_token"
+	githubUserURL   = "https://api.github.com/user"
+	githubEmailsURL = "https://api.github.com/user/emails"
+)
+
+var githubDefaultScopes = []string{"read:user", "user:email"}
+
+// githubProvider GitHub登录，用户信息和已验证的主邮箱通过REST API读取
+type githubProvider struct {
+	config config.OAuthProviderConfig
+	secret string
+	client *http.Client
+}
+
+// githubUser GitHub用户信息
+type githubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+}
+
+// githubEmail GitHub用户的邮箱
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// newGitHubProvider 创建GitHub登录服务
+func newGitHubProvider(cfg config.OAuthProviderConfig, secret string, client *http.Client) *githubProvider {
+	return &githubProvider{config: cfg, secret: secret, client: client}
+}
+
+// Name 服务商名称
+func (p *githubProvider) Name() string {
+	return config.OAuthProviderGitHub
+}
+
+// AuthCodeURL 授权地址
+func (p *githubProvider) AuthCodeURL(state, challenge string) string {
+	return authCodeURL(githubAuthURL, p.config, scopesOrDefault(p.config.Scopes, githubDefaultScopes), state, challenge, nil)
+}
+
+// Exchange 用授权码换取access token，读取用户ID和主邮箱（GitHub的公开邮箱可能为空或未验证，使用邮箱接口中的主邮箱）
+func (p *githubProvider) Exchange(ctx context.Context, code, verifier string) (*Profile, error) {
+	token, err := exchangeCode(ctx, p.client, githubTokenURL, codeForm(p.config, p.secret, code, verifier))
+	if err != nil {
+		return nil, err
+	}
+
+	var user githubUser
+	if err := p.get(ctx, githubUserURL, token.AccessToken, &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("%w: github user id missing", ErrExchangeFailed)
+	}
+
+	var emails []githubEmail
+	if err := p.get(ctx, githubEmailsURL, token.AccessToken, &emails); err != nil {
+		return nil, err
+	}
+
+	profile := &Profile{
+		Provider: p.Name(),
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Name:     user.Login,
+	}
+	for _, email := range emails {
+		if email.Primary {
+			profile.Email = email.Email
+			profile.EmailVerified = email.Verified
+			break
+		}
+	}
+	return profile, nil
+}
+
+// get 以access token请求GitHub API
+func (p *githubProvider) get(ctx context.Context, endpoint, accessToken string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create github request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	status, err := doJSON(p.client, req, out)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("%w: github api returned status %d", ErrExchangeFailed, status)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"exchange/internal/pkg/config"
+)
+
+const (
+	googleAuthURL  = "https://accounts.google.com/o/oauth2/v2/auth"
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+)
+
+var (
+	googleIssuers       = []string{"https://accounts.google.com", "accounts.google.com"}
+	googleDefaultScopes = []string{"openid", "email", "profile"}
+)
+
+// googleProvider Google登录（OpenID Connect），用户信息取自id_token
+type googleProvider struct {
+	config config.OAuthProviderConfig
+	secret string
+	client *http.Client
+}
+
+// newGoogleProvider 创建Google登录服务
+func newGoogleProvider(cfg config.OAuthProviderConfig, secret string, client *http.Client) *googleProvider {
+	return &googleProvider{config: cfg, secret: secret, client: client}
+}
+
+// Name 服务商名称
+func (p *googleProvider) Name() string {
+	return config.OAuthProviderGoogle
+}
+
+// AuthCodeURL 授权地址
+func (p *googleProvider) AuthCodeURL(state, challenge string) string {
+	return authCodeURL(googleAuthURL, p.config, scopesOrDefault(p.config.Scopes, googleDefaultScopes), state, challenge,
+		url.Values{"prompt": {"select_account"}})
+}
+
+// Exchange 用授权码换取token，从id_token读取用户标识和邮箱
+func (p *googleProvider) Exchange(ctx context.Context, code, verifier string) (*Profile, error) {
+	token, err := exchangeCode(ctx, p.client, googleTokenURL, codeForm(p.config, p.secret, code, verifier))
+	if err != nil {
+		return nil, err
+	}
+	claims, err := parseIDToken(token.IDToken, googleIssuers, p.config.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Profile{
+		Provider:      p.Name(),
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+	}, nil
+}
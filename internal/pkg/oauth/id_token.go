@@ -0,0 +1,77 @@
+package oauth
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"exchange/internal/pkg/clock"
+)
+
+// idTokenClaims OpenID Connect id_token中使用的声明
+type idTokenClaims struct {
+	Email         string   `json:"email"`
+	EmailVerified flexBool `json:"email_verified"`
+	Name          string   `json:"name"`
+	jwt.RegisteredClaims
+}
+
+// flexBool 布尔值声明，Apple的email_verified为字符串"true"/"false"
+type flexBool bool
+
+// UnmarshalJSON 同时接受布尔值和字符串
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	if unquoted, err := strconv.Unquote(string(data)); err == nil {
+		data = []byte(unquoted)
+	}
+	value, err := strconv.ParseBool(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid boolean claim: %s", data)
+	}
+	*b = flexBool(value)
+	return nil
+}
+
+// parseIDToken 解析token接口返回的id_token并校验签发方、受众和有效期
+// id_token是服务端通过TLS直接从服务商token接口取得的，按OpenID Connect Core 3.1.3.7以TLS校验服务商身份代替签名验证
+func parseIDToken(raw string, issuers []string, audience string) (*idTokenClaims, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("%w: id_token missing", ErrExchangeFailed)
+	}
+
+	var claims idTokenClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(raw, &claims); err != nil {
+		return nil, fmt.Errorf("%w: invalid id_token: %v", ErrExchangeFailed, err)
+	}
+
+	issuerOK := false
+	for _, issuer := range issuers {
+		if claims.Issuer == issuer {
+			issuerOK = true
+			break
+		}
+	}
+	if !issuerOK {
+		return nil, fmt.Errorf("%w: unexpected id_token issuer %q", ErrExchangeFailed, claims.Issuer)
+	}
+
+	audienceOK := false
+	for _, aud := range claims.Audience {
+		if aud == audience {
+			audienceOK = true
+			break
+		}
+	}
+	if !audienceOK {
+		return nil, fmt.Errorf("%w: unexpected id_token audience", ErrExchangeFailed)
+	}
+
+	if claims.ExpiresAt == nil || !clock.Now().Before(claims.ExpiresAt.Time) {
+		return nil, fmt.Errorf("%w: id_token expired", ErrExchangeFailed)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: id_token subject missing", ErrExchangeFailed)
+	}
+	return &claims, nil
+}
@@ -0,0 +1,196 @@
+// Package oauth 第三方登录（Google、GitHub、Apple）
+// 使用OAuth2授权码流程和PKCE（S256）：发起授权时生成state和code_verifier保存在Redis中，
+// 回调时校验并一次性取出state，用授权码和code_verifier换取token后读取用户的唯一标识和邮箱
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/secrets"
+)
+
+const (
+	verifierBytes = 32
+
+	// httpTimeout 请求服务商接口的超时时间
+	httpTimeout = 10 * time.Second
+	// maxResponseBytes 服务商响应的最大读取字节数
+	maxResponseBytes = 1 << 20
+)
+
+var (
+	// ErrUnknownProvider 服务商不存在或未启用
+	ErrUnknownProvider = errors.New("unknown oauth provider")
+	// ErrExchangeFailed 授权码换取token或读取用户信息失败（授权码无效、已使用或服务商返回错误）
+	ErrExchangeFailed = errors.New("oauth code exchange failed")
+)
+
+// Profile 服务商返回的用户信息
+type Profile struct {
+	Provider      string
+	Subject       string // 服务商内用户的唯一标识，不随邮箱修改而变化
+	Email         string
+	EmailVerified bool   // 服务商是否已验证该邮箱，未验证的邮箱不能用于关联或创建账户
+	Name          string // 用户名建议（GitHub登录名或邮箱前缀），可能为空
+}
+
+// Provider 第三方登录服务
+type Provider interface {
+	// Name 服务商名称
+	Name() string
+
+	// AuthCodeURL 授权地址，challenge为PKCE的code_challenge（S256）
+	AuthCodeURL(state, challenge string) string
+
+	// Exchange 用授权码和code_verifier换取token，返回用户信息
+	Exchange(ctx context.Context, code, verifier string) (*Profile, error)
+}
+
+// NewProviders 按配置创建启用的服务商，客户端密钥在创建时从密钥提供者读取
+func NewProviders(ctx context.Context, cfg config.OAuthConfig, provider secrets.Provider) (map[string]Provider, error) {
+	providers := make(map[string]Provider, len(cfg.Providers))
+	if !cfg.Enabled {
+		return providers, nil
+	}
+
+	client := &http.Client{Timeout: httpTimeout}
+	for name, providerCfg := range cfg.Providers {
+		if !providerCfg.Enabled {
+			continue
+		}
+		secret, err := provider.Get(ctx, providerCfg.ClientSecretName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load oauth client secret for %s: %w", name, err)
+		}
+
+		switch name {
+		case config.OAuthProviderGoogle:
+			providers[name] = newGoogleProvider(providerCfg, secret, client)
+		case config.OAuthProviderGitHub:
+			providers[name] = newGitHubProvider(providerCfg, secret, client)
+		case config.OAuthProviderApple:
+			apple, err := newAppleProvider(providerCfg, secret, client)
+			if err != nil {
+				return nil, err
+			}
+			providers[name] = apple
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+		}
+	}
+	return providers, nil
+}
+
+// NewVerifier 生成PKCE的code_verifier
+func NewVerifier() (string, error) {
+	buf := make([]byte, verifierBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate code verifier: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Challenge code_verifier对应的code_challenge（S256）
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// tokenResponse 服务商token接口的响应
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// exchangeCode 请求token接口用授权码换取token
+func exchangeCode(ctx context.Context, client *http.Client, tokenURL string, form url.Values) (*tokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token tokenResponse
+	status, err := doJSON(client, req, &token)
+	if err != nil {
+		return nil, err
+	}
+	// GitHub出错时同样返回200，错误信息在响应体中
+	if token.Error != "" {
+		return nil, fmt.Errorf("%w: %s %s", ErrExchangeFailed, token.Error, token.ErrorDescription)
+	}
+	if status != http.StatusOK || (token.AccessToken == "" && token.IDToken == "") {
+		return nil, fmt.Errorf("%w: token endpoint returned status %d", ErrExchangeFailed, status)
+	}
+	return &token, nil
+}
+
+// doJSON 发送请求并解析JSON响应，返回HTTP状态码
+func doJSON(client *http.Client, req *http.Request, out interface{}) (int, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("oauth request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to read oauth response: %w", err)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return resp.StatusCode, fmt.Errorf("%w: invalid response (status %d)", ErrExchangeFailed, resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// authCodeURL 拼接授权地址
+func authCodeURL(endpoint string, cfg config.OAuthProviderConfig, scopes []string, state, challenge string, extra url.Values) string {
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", cfg.ClientID)
+	params.Set("redirect_uri", cfg.RedirectURL)
+	params.Set("scope", strings.Join(scopes, " "))
+	params.Set("state", state)
+	params.Set("code_challenge", challenge)
+	params.Set("code_challenge_method", "S256")
+	for key, values := range extra {
+		params[key] = values
+	}
+	return endpoint + "?" + params.Encode()
+}
+
+// codeForm token接口的公共参数
+func codeForm(cfg config.OAuthProviderConfig, clientSecret, code, verifier string) url.Values {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", cfg.RedirectURL)
+	form.Set("client_id", cfg.ClientID)
+	form.Set("client_secret", clientSecret)
+	form.Set("code_verifier", verifier)
+	return form
+}
+
+// scopesOrDefault 配置的权限，为空时使用默认权限
+func scopesOrDefault(scopes, defaults []string) []string {
+	if len(scopes) == 0 {
+		return defaults
+	}
+	return scopes
+}
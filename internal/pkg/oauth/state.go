@@ -0,0 +1,104 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"exchange/internal/pkg/database"
+)
+
+const (
+	stateKeyPrefix = "oauth:state:" // 授权state，值为State的JSON
+
+	stateBytes = 32
+)
+
+// ErrInvalidState state不存在、已使用、已过期或与服务商不匹配
+var ErrInvalidState = errors.New("invalid oauth state")
+
+// State 发起授权时保存的状态
+type State struct {
+	Provider string `json:"provider"`
+	Verifier string `json:"verifier"` // PKCE的code_verifier
+
+	// 授权码已换取成功但登录还需要两步验证码时记录用户，带验证码重试时不再换取token（授权码只能使用一次）
+	UserID   uint `json:"user_id,omitempty"`
+	Created  bool `json:"created,omitempty"`  // 用户是本次授权创建的
+	Attempts int  `json:"attempts,omitempty"` // 两步验证码错误次数
+}
+
+// StateStore 授权state存储，保存在Redis中，多实例共享
+type StateStore struct {
+	redis *database.RedisService
+	ttl   time.Duration
+}
+
+// NewStateStore 创建授权state存储，ttl为state有效期
+func NewStateStore(redis *database.RedisService, ttl time.Duration) *StateStore {
+	return &StateStore{
+		redis: redis,
+		ttl:   ttl,
+	}
+}
+
+// Create 生成state并保存
+func (s *StateStore) Create(ctx context.Context, state State) (string, error) {
+	buf := make([]byte, stateBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate oauth state: %w", err)
+	}
+	id := hex.EncodeToString(buf)
+	if err := s.Save(ctx, id, state, s.ttl); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Save 保存state，ttl为剩余有效期
+func (s *StateStore) Save(ctx context.Context, id string, state State, ttl time.Duration) error {
+	value, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode oauth state: %w", err)
+	}
+	if err := s.redis.Client().Set(ctx, stateKeyPrefix+id, value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save oauth state: %w", err)
+	}
+	return nil
+}
+
+// Consume 取出并删除state，返回state和剩余有效期；state只能使用一次
+func (s *StateStore) Consume(ctx context.Context, id, provider string) (*State, time.Duration, error) {
+	if id == "" {
+		return nil, 0, ErrInvalidState
+	}
+
+	key := stateKeyPrefix + id
+	pipe := s.redis.Client().TxPipeline()
+	get := pipe.Get(ctx, key)
+	ttl := pipe.PTTL(ctx, key)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, 0, fmt.Errorf("failed to consume oauth state: %w", err)
+	}
+
+	value, err := get.Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, 0, ErrInvalidState
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to consume oauth state: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(value, &state); err != nil || state.Provider != provider {
+		return nil, 0, ErrInvalidState
+	}
+	return &state, ttl.Val(), nil
+}
@@ -0,0 +1,206 @@
+package objectstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/secrets"
+	"exchange/internal/pkg/signing"
+)
+
+// LinkKeyName 本地存储下载链接签名密钥在密钥提供者中的名称
+// 值可包含多个版本（逗号或换行分隔），第一个用于签名，其余在轮换过渡期内仍可验证
+const LinkKeyName = "attachment_link_key"
+
+// DownloadPath 本地存储下载链接的路径（公开路由，通过签名校验）
+const DownloadPath = "/api/v1/attachments/download"
+
+// 下载链接验证错误
+var (
+	ErrLinkExpired   = errors.New("download link expired")
+	ErrLinkSignature = errors.New("invalid download link signature")
+)
+
+// LocalStore 本地目录存储（多实例部署时目录需为共享存储）
+// 下载链接指向本服务的DownloadPath，签名覆盖对象key、文件名和过期时间
+type LocalStore struct {
+	dir      string
+	baseURL  string
+	provider secrets.Provider
+}
+
+// NewLocalStore 创建本地目录存储
+func NewLocalStore(cfg config.LocalAttachmentConfig, provider secrets.Provider) (*LocalStore, error) {
+	if err := os.MkdirAll(cfg.Dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create attachment dir: %w", err)
+	}
+	return &LocalStore{
+		dir:      cfg.Dir,
+		baseURL:  strings.TrimRight(cfg.BaseURL, "/"),
+		provider: provider,
+	}, nil
+}
+
+// path 对象的文件路径
+func (s *LocalStore) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// Put 先写入临时文件，完整写入后重命名，读取方不会看到写了一半的文件
+func (s *LocalStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create attachment dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create attachment file: %w", err)
+	}
+	written, err := io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && written != size {
+		err = fmt.Errorf("attachment size mismatch: expected %d bytes, got %d", size, written)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write attachment file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to publish attachment file: %w", err)
+	}
+	return nil
+}
+
+// Stat 获取文件信息，内容类型按文件内容识别（与上传时的校验方式相同）
+func (s *LocalStore) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	file, err := s.Open(key)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat attachment file: %w", err)
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read attachment file: %w", err)
+	}
+
+	return &ObjectInfo{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: http.DetectContentType(head[:n]),
+		ModTime:     info.ModTime(),
+	}, nil
+}
+
+// Open 打开文件，不存在时返回ErrNotFound
+func (s *LocalStore) Open(key string) (*os.File, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open attachment file: %w", err)
+	}
+	return file, nil
+}
+
+// Delete 删除文件
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete attachment file: %w", err)
+	}
+	return nil
+}
+
+// PresignGet 生成本服务的签名下载链接
+func (s *LocalStore) PresignGet(ctx context.Context, key, fileName string, expiresAt time.Time) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	keys, err := s.keys(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	expires := expiresAt.Unix()
+	query := url.Values{}
+	query.Set("key", key)
+	if fileName != "" {
+		query.Set("name", fileName)
+	}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", signing.Compute(keys[0], linkStringToSign(key, fileName, expires)))
+	return s.baseURL + DownloadPath + "?" + query.Encode(), nil
+}
+
+// Verify 验证下载链接的签名和有效期
+func (s *LocalStore) Verify(ctx context.Context, key, fileName string, expires int64, signature string, now time.Time) error {
+	if now.Unix() > expires {
+		return ErrLinkExpired
+	}
+
+	keys, err := s.keys(ctx)
+	if err != nil {
+		return err
+	}
+
+	expected := linkStringToSign(key, fileName, expires)
+	for _, linkKey := range keys {
+		if hmac.Equal([]byte(signing.Compute(linkKey, expected)), []byte(strings.ToLower(signature))) {
+			return nil
+		}
+	}
+	return ErrLinkSignature
+}
+
+// keys 读取签名密钥（第一个为当前版本）
+func (s *LocalStore) keys(ctx context.Context) ([]string, error) {
+	value, err := s.provider.Get(ctx, LinkKeyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment link key: %w", err)
+	}
+	keys := secrets.Versions(value)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("attachment link key is empty")
+	}
+	return keys, nil
+}
+
+// linkStringToSign 待签名字符串
+func linkStringToSign(key, fileName string, expires int64) string {
+	return strings.Join([]string{"attachment", key, fileName, strconv.FormatInt(expires, 10)}, "\n")
+}
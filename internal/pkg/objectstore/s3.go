@@ -0,0 +1,305 @@
+package objectstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/secrets"
+)
+
+const (
+	// s3Algorithm 签名算法（AWS Signature Version 4）
+	s3Algorithm = "AWS4-HMAC-SHA256"
+	// s3UnsignedPayload 上传时不对请求体计算摘要，避免为计算摘要而缓存整个文件
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	// s3MaxPresignTTL 预签名链接的最长有效期（7天）
+	s3MaxPresignTTL = 7 * 24 * time.Hour
+)
+
+// S3Store AWS S3和MinIO等S3兼容服务的存储
+// 请求使用Signature Version 4签名，访问密钥每次请求时从密钥提供者读取，轮换时无需重启
+type S3Store struct {
+	endpoint *url.URL
+	region   string
+	bucket   string
+	style    bool // 路径风格地址
+	client   *http.Client
+	provider secrets.Provider
+	access   string
+	secret   string
+}
+
+// NewS3Store 创建S3存储
+func NewS3Store(cfg config.S3AttachmentConfig, provider secrets.Provider) (*S3Store, error) {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid s3 endpoint: %s", endpoint)
+	}
+
+	return &S3Store{
+		endpoint: u,
+		region:   cfg.Region,
+		bucket:   cfg.Bucket,
+		style:    cfg.PathStyle,
+		client:   &http.Client{Timeout: time.Duration(cfg.TimeoutMs) * time.Millisecond},
+		provider: provider,
+		access:   cfg.AccessKeyName,
+		secret:   cfg.SecretKeyName,
+	}, nil
+}
+
+// Put 上传对象
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload object: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to upload object: %s", responseError(resp))
+	}
+	return nil
+}
+
+// Stat 获取对象信息
+func (s *S3Store) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	req, err := s.newRequest(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat object: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("failed to stat object: %s", resp.Status)
+	}
+
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &ObjectInfo{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+		ModTime:     modTime,
+	}, nil
+}
+
+// Delete 删除对象，S3删除不存在的对象同样返回204
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to delete object: %s", responseError(resp))
+	}
+	return nil
+}
+
+// PresignGet 生成预签名的GET链接，客户端直接从存储服务下载，不经过本服务
+// 有效期最长7天（S3的限制）
+func (s *S3Store) PresignGet(ctx context.Context, key, fileName string, expiresAt time.Time) (string, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+	accessKey, secretKey, err := s.credentials(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	now := clock.Now().UTC()
+	ttl := expiresAt.Unix() - now.Unix()
+	if ttl <= 0 {
+		return "", fmt.Errorf("presign expiry must be in the future")
+	}
+	if ttl > int64(s3MaxPresignTTL/time.Second) {
+		ttl = int64(s3MaxPresignTTL / time.Second)
+	}
+
+	amzDate := now.Format("20060102T150405Z")
+	scope := s.scope(now)
+	query := map[string]string{
+		"X-Amz-Algorithm":     s3Algorithm,
+		"X-Amz-Credential":    accessKey + "/" + scope,
+		"X-Amz-Date":          amzDate,
+		"X-Amz-Expires":       strconv.FormatInt(ttl, 10),
+		"X-Amz-SignedHeaders": "host",
+	}
+	if fileName != "" {
+		query["response-content-disposition"] = ContentDisposition(fileName)
+	}
+	rawQuery := canonicalQuery(query)
+
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		rawQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+	signature := s.sign(secretKey, now, amzDate, scope, canonical)
+
+	u.RawQuery = rawQuery + "&X-Amz-Signature=" + signature
+	return u.String(), nil
+}
+
+// newRequest 创建对象请求
+func (s *S3Store) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 request: %w", err)
+	}
+	return req, nil
+}
+
+// do 对请求签名并发送
+// 签名覆盖host、x-amz-content-sha256和x-amz-date，请求体不参与签名
+func (s *S3Store) do(req *http.Request) (*http.Response, error) {
+	accessKey, secretKey, err := s.credentials(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	now := clock.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := s.scope(now)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"",
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + s3UnsignedPayload + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		s3UnsignedPayload,
+	}, "\n")
+	signature := s.sign(secretKey, now, amzDate, scope, canonical)
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, accessKey, scope, signedHeaders, signature))
+	return s.client.Do(req)
+}
+
+// objectURL 对象地址，路径风格为endpoint/bucket/key，否则为bucket.endpoint/key
+func (s *S3Store) objectURL(key string) (*url.URL, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	u := *s.endpoint
+	if s.style {
+		u.Path = s.endpoint.Path + "/" + s.bucket + "/" + key
+	} else {
+		u.Host = s.bucket + "." + s.endpoint.Host
+		u.Path = s.endpoint.Path + "/" + key
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	return &u, nil
+}
+
+// credentials 读取访问密钥
+func (s *S3Store) credentials(ctx context.Context) (string, string, error) {
+	accessKey, err := s.provider.Get(ctx, s.access)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get s3 access key: %w", err)
+	}
+	secretKey, err := s.provider.Get(ctx, s.secret)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get s3 secret key: %w", err)
+	}
+	accessKey, secretKey = strings.TrimSpace(accessKey), strings.TrimSpace(secretKey)
+	if accessKey == "" || secretKey == "" {
+		return "", "", fmt.Errorf("s3 access key is empty")
+	}
+	return accessKey, secretKey, nil
+}
+
+// scope 签名范围：日期/区域/s3/aws4_request
+func (s *S3Store) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.region + "/s3/aws4_request"
+}
+
+// sign 计算签名
+func (s *S3Store) sign(secretKey string, now time.Time, amzDate, scope, canonicalRequest string) string {
+	digest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, hex.EncodeToString(digest[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// hmacSHA256 计算HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery 按参数名排序并编码的查询字符串
+func canonicalQuery(params map[string]string) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, uriEncode(name, true)+"="+uriEncode(params[name], true))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// responseError 错误响应的状态和错误信息（S3返回XML格式的错误说明）
+func responseError(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Sprintf("%s %s", resp.Status, strings.TrimSpace(string(body)))
+}
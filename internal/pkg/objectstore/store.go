@@ -0,0 +1,102 @@
+// Package objectstore 聊天附件的对象存储
+// 支持本地目录、AWS S3和MinIO，业务数据中只保存对象key，下载时生成限时的预签名链接
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/secrets"
+)
+
+// 对象key由"/"分隔的若干段组成，每段只能包含字母、数字、下划线、点和连字符
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+(/[A-Za-z0-9_.-]+)*$`)
+
+// 对象存储错误
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// ObjectInfo 对象信息
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Store 对象存储
+type Store interface {
+	// Put 上传对象，size为内容长度，同名对象被覆盖
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+
+	// Stat 获取对象信息，不存在时返回ErrNotFound
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+
+	// PresignGet 生成下载链接，expiresAt之后失效；fileName不为空时作为下载的文件名
+	PresignGet(ctx context.Context, key, fileName string, expiresAt time.Time) (string, error)
+
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStore 根据配置创建对象存储
+// S3和MinIO的访问密钥、本地存储下载链接的签名密钥从密钥提供者读取
+func NewStore(cfg config.AttachmentConfig, provider secrets.Provider) (Store, error) {
+	switch cfg.Driver {
+	case config.AttachmentDriverLocal:
+		return NewLocalStore(cfg.Local, provider)
+	case config.AttachmentDriverS3, config.AttachmentDriverMinIO:
+		return NewS3Store(cfg.S3, provider)
+	default:
+		return nil, fmt.Errorf("unsupported attachment driver: %s", cfg.Driver)
+	}
+}
+
+// ValidKey 检查对象key是否合法，不允许"."和".."段，避免路径穿越
+func ValidKey(key string) bool {
+	if len(key) > 512 || !keyPattern.MatchString(key) {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// ContentDisposition 下载文件名的Content-Disposition，非ASCII文件名使用RFC 5987编码
+func ContentDisposition(fileName string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, fileName)
+	return fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, fallback, uriEncode(fileName, true))
+}
+
+// uriEncode 按RFC 3986编码，只保留非保留字符；encodeSlash为false时保留"/"
+func uriEncode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
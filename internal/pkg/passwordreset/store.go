@@ -0,0 +1,141 @@
+// Package passwordreset 找回密码的一次性重置token
+// token只以SHA-256哈希保存在Redis中，有效期内只能使用一次；同一账户签发新token后旧token失效，
+// 并按账户限制统计窗口内签发的次数，防止被用来向他人邮箱批量发送邮件
+package passwordreset
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/database"
+)
+
+const (
+	tokenKeyPrefix = "password_reset:token:" // token的哈希，值为用户ID
+	userKeyPrefix  = "password_reset:user:"  // 用户当前有效token的哈希，签发新token时删除旧token
+	limitKeyPrefix = "password_reset:limit:" // 统计窗口内的签发次数
+
+	tokenBytes = 32
+)
+
+var (
+	// ErrInvalidToken 重置token不存在、已使用或已过期
+	ErrInvalidToken = errors.New("invalid password reset token")
+	// ErrTooManyRequests 账户在统计窗口内签发的重置token达到上限
+	ErrTooManyRequests = errors.New("too many password reset requests")
+)
+
+// issueScript 签发次数加1（第一次时设置统计窗口），超过上限时返回-1；否则删除用户的旧token，保存新token
+// KEYS: 次数键, 用户键, 新token键；ARGV: 上限, 窗口(毫秒), 有效期(毫秒), 用户ID, 新token哈希, token键前缀
+var issueScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+if count > tonumber(ARGV[1]) then
+	return -1
+end
+local previous = redis.call('GET', KEYS[2])
+if previous then
+	redis.call('DEL', ARGV[6] .. previous)
+end
+redis.call('SET', KEYS[3], ARGV[4], 'PX', ARGV[3])
+redis.call('SET', KEYS[2], ARGV[5], 'PX', ARGV[3])
+return count
+`)
+
+// consumeScript 取出并删除token，同时删除用户当前token的记录（仍指向该token时）
+// KEYS: token键；ARGV: token哈希, 用户键前缀
+var consumeScript = redis.NewScript(`
+local userID = redis.call('GET', KEYS[1])
+if not userID then
+	return false
+end
+redis.call('DEL', KEYS[1])
+local userKey = ARGV[2] .. userID
+if redis.call('GET', userKey) == ARGV[1] then
+	redis.call('DEL', userKey)
+end
+return userID
+`)
+
+// Store 重置token存储，保存在Redis中，多实例共享
+type Store struct {
+	redis  *database.RedisService
+	ttl    time.Duration
+	limit  int
+	window time.Duration
+}
+
+// NewStore 创建重置token存储
+func NewStore(redis *database.RedisService, cfg config.AccountConfig) *Store {
+	return &Store{
+		redis:  redis,
+		ttl:    time.Duration(cfg.PasswordResetTTLMinutes) * time.Minute,
+		limit:  cfg.PasswordResetLimit,
+		window: time.Duration(cfg.PasswordResetWindowMinutes) * time.Minute,
+	}
+}
+
+// Issue 为用户签发重置token，返回token和过期时间
+// 统计窗口内签发次数达到上限时返回ErrTooManyRequests
+func (s *Store) Issue(ctx context.Context, userID uint) (string, time.Time, error) {
+	buf := make([]byte, tokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+	hash := hashToken(token)
+	id := strconv.FormatUint(uint64(userID), 10)
+
+	keys := []string{limitKeyPrefix + id, userKeyPrefix + id, tokenKeyPrefix + hash}
+	count, err := issueScript.Run(ctx, s.redis.Client(), keys,
+		s.limit, s.window.Milliseconds(), s.ttl.Milliseconds(), id, hash, tokenKeyPrefix).Int()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to save reset token: %w", err)
+	}
+	if count < 0 {
+		return "", time.Time{}, ErrTooManyRequests
+	}
+	return token, clock.Now().Add(s.ttl), nil
+}
+
+// Consume 使用重置token，返回用户ID；token随即失效
+func (s *Store) Consume(ctx context.Context, token string) (uint, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return 0, ErrInvalidToken
+	}
+
+	hash := hashToken(token)
+	value, err := consumeScript.Run(ctx, s.redis.Client(), []string{tokenKeyPrefix + hash}, hash, userKeyPrefix).Text()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrInvalidToken
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to consume reset token: %w", err)
+	}
+
+	userID, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || userID == 0 {
+		return 0, ErrInvalidToken
+	}
+	return uint(userID), nil
+}
+
+// hashToken token的哈希
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
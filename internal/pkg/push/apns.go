@@ -0,0 +1,150 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/secrets"
+)
+
+const (
+	apnsProductionURL = "https://api.push.apple.com/3/device/"
+	apnsSandboxURL    = "https://api.sandbox.push.apple.com/3/device/"
+
+	// apnsTokenTTL provider token的复用时间（Apple要求在20到60分钟之间更换）
+	apnsTokenTTL = 50 * time.Minute
+	// apnsMaxCollapseID apns-collapse-id的最大字节数
+	apnsMaxCollapseID = 64
+)
+
+// APNsSender 通过Apple推送服务（HTTP/2接口）发送
+// 以.p8私钥签名的ES256 provider token认证，token在有效期内复用；私钥在签发token时从密钥提供者读取，密钥轮换后无需重启
+type APNsSender struct {
+	cfg      config.APNsConfig
+	provider secrets.Provider
+	client   *http.Client
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// NewAPNsSender 创建APNs推送发送
+func NewAPNsSender(cfg config.PushConfig, provider secrets.Provider) *APNsSender {
+	return &APNsSender{
+		cfg:      cfg.APNs,
+		provider: provider,
+		// 默认Transport在TLS握手时协商HTTP/2
+		client: &http.Client{Timeout: time.Duration(cfg.TimeoutMs) * time.Millisecond},
+	}
+}
+
+// Send 发送通知，设备token失效（410、BadDeviceToken或Unregistered）时返回ErrUnregistered
+func (s *APNsSender) Send(ctx context.Context, n *Notification) error {
+	providerToken, err := s.providerToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 自定义数据与aps同级
+	payload := make(map[string]interface{}, len(n.Data)+1)
+	for key, value := range n.Data {
+		payload[key] = value
+	}
+	payload["aps"] = map[string]interface{}{
+		"alert": map[string]string{"title": n.Title, "body": n.Body},
+		"sound": "default",
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal apns payload: %w", err)
+	}
+
+	endpoint := apnsSandboxURL
+	if s.cfg.Production {
+		endpoint = apnsProductionURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+n.Token, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create apns request: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+providerToken)
+	req.Header.Set("apns-topic", s.cfg.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+	if n.CollapseKey != "" && len(n.CollapseKey) <= apnsMaxCollapseID {
+		req.Header.Set("apns-collapse-id", n.CollapseKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send apns notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var errResp struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&errResp)
+	switch {
+	case resp.StatusCode == http.StatusGone, errResp.Reason == "BadDeviceToken", errResp.Reason == "Unregistered":
+		return ErrUnregistered
+	case errResp.Reason == "ExpiredProviderToken", errResp.Reason == "InvalidProviderToken":
+		s.resetToken()
+	}
+	return fmt.Errorf("apns send failed: status %d: %s", resp.StatusCode, errResp.Reason)
+}
+
+// providerToken 获取provider token，超过复用时间后重新签发
+func (s *APNsSender) providerToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := clock.Now()
+	if s.token != "" && now.Sub(s.issuedAt) < apnsTokenTTL {
+		return s.token, nil
+	}
+
+	pem, err := s.provider.Get(ctx, s.cfg.KeyName)
+	if err != nil {
+		return "", fmt.Errorf("failed to load apns key: %w", err)
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM([]byte(pem))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse apns key: %w", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": s.cfg.TeamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = s.cfg.KeyID
+	signed, err := token.SignedString(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign apns provider token: %w", err)
+	}
+
+	s.token = signed
+	s.issuedAt = now
+	return s.token, nil
+}
+
+// resetToken provider token被拒绝时丢弃，下次发送重新签发
+func (s *APNsSender) resetToken() {
+	s.mu.Lock()
+	s.token = ""
+	s.mu.Unlock()
+}
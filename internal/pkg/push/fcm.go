@@ -0,0 +1,219 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/secrets"
+)
+
+const (
+	fcmSendURL = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+	fcmScope   = "https://www.googleapis.com/auth/firebase.messaging"
+
+	// fcmAssertionTTL 换取访问token时签发的JWT有效期（Google允许最长1小时）
+	fcmAssertionTTL = time.Hour
+	// fcmTokenRefreshMargin 访问token在过期前提前刷新的时间
+	fcmTokenRefreshMargin = time.Minute
+)
+
+// serviceAccount Firebase服务账号JSON中用到的字段
+type serviceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// fcmRequest HTTP v1接口的发送请求
+type fcmRequest struct {
+	Message fcmMessage `json:"message"`
+}
+
+type fcmMessage struct {
+	Token        string            `json:"token"`
+	Notification fcmNotification   `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+	Android      *fcmAndroid       `json:"android,omitempty"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type fcmAndroid struct {
+	CollapseKey string `json:"collapse_key"`
+}
+
+// fcmErrorResponse HTTP v1接口的错误响应
+type fcmErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// FCMSender 通过Firebase Cloud Messaging HTTP v1接口发送
+// 以服务账号私钥签名的JWT换取OAuth2访问token，token过期前复用；服务账号在换取token时从密钥提供者读取，密钥轮换后无需重启
+type FCMSender struct {
+	cfg      config.FCMConfig
+	provider secrets.Provider
+	client   *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewFCMSender 创建FCM推送发送
+func NewFCMSender(cfg config.PushConfig, provider secrets.Provider) *FCMSender {
+	return &FCMSender{
+		cfg:      cfg.FCM,
+		provider: provider,
+		client:   &http.Client{Timeout: time.Duration(cfg.TimeoutMs) * time.Millisecond},
+	}
+}
+
+// Send 发送通知，token未注册（404或UNREGISTERED）时返回ErrUnregistered
+func (s *FCMSender) Send(ctx context.Context, n *Notification) error {
+	accessToken, err := s.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	message := fcmMessage{
+		Token:        n.Token,
+		Notification: fcmNotification{Title: n.Title, Body: n.Body},
+		Data:         n.Data,
+	}
+	if n.CollapseKey != "" {
+		message.Android = &fcmAndroid{CollapseKey: n.CollapseKey}
+	}
+	body, err := json.Marshal(fcmRequest{Message: message})
+	if err != nil {
+		return fmt.Errorf("failed to marshal fcm message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(fcmSendURL, s.cfg.ProjectID), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create fcm request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send fcm message: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var errResp fcmErrorResponse
+	_ = json.Unmarshal(data, &errResp)
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrUnregistered
+	}
+	for _, detail := range errResp.Error.Details {
+		if detail.ErrorCode == "UNREGISTERED" {
+			return ErrUnregistered
+		}
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		s.resetToken()
+	}
+	return fmt.Errorf("fcm send failed: status %d: %s", resp.StatusCode, errResp.Error.Message)
+}
+
+// accessToken 获取访问token，过期前一分钟重新换取
+func (s *FCMSender) accessToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := clock.Now()
+	if s.token != "" && now.Before(s.expiresAt.Add(-fcmTokenRefreshMargin)) {
+		return s.token, nil
+	}
+
+	credentials, err := s.provider.Get(ctx, s.cfg.CredentialsName)
+	if err != nil {
+		return "", fmt.Errorf("failed to load fcm credentials: %w", err)
+	}
+	var account serviceAccount
+	if err := json.Unmarshal([]byte(credentials), &account); err != nil {
+		return "", fmt.Errorf("failed to parse fcm credentials: %w", err)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse fcm private key: %w", err)
+	}
+
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   account.ClientEmail,
+		"scope": fcmScope,
+		"aud":   account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(fcmAssertionTTL).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign fcm assertion: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create fcm token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request fcm access token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fcm token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode fcm access token: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("fcm token endpoint returned no access token")
+	}
+
+	s.token = token.AccessToken
+	s.expiresAt = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+	return s.token, nil
+}
+
+// resetToken 访问token被拒绝时丢弃，下次发送重新换取
+func (s *FCMSender) resetToken() {
+	s.mu.Lock()
+	s.token = ""
+	s.mu.Unlock()
+}
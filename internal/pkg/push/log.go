@@ -0,0 +1,32 @@
+package push
+
+import (
+	"context"
+
+	appLogger "exchange/internal/pkg/logger"
+)
+
+// pushLogger 推送发送日志
+var pushLogger = appLogger.Module("push")
+
+// LogSender 将通知写入日志，不实际发送（开发环境和未配置推送服务时使用）
+// 日志中只记录token前缀和标题，正文可能包含消息内容
+type LogSender struct{}
+
+// NewLogSender 创建日志推送发送
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+// Send 记录通知
+func (s *LogSender) Send(ctx context.Context, n *Notification) error {
+	token := n.Token
+	if len(token) > 8 {
+		token = token[:8] + "..."
+	}
+	pushLogger.WithContext(ctx).Info("发送推送通知（仅记录日志）", map[string]interface{}{
+		"token": token,
+		"title": n.Title,
+	})
+	return nil
+}
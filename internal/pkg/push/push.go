@@ -0,0 +1,125 @@
+// Package push 移动推送
+// 各设备平台使用的推送服务由配置push.platforms决定：log只写日志，fcm通过Firebase Cloud Messaging发送，apns通过Apple推送服务发送；
+// 其他推送服务通过RegisterProvider注册后在配置中按名称选用
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/secrets"
+)
+
+const (
+	// maxResponseBytes 推送服务响应的最大读取字节数
+	maxResponseBytes = 64 << 10
+)
+
+var (
+	// ErrUnregistered 设备token已失效（应用已卸载或token已更换），应删除该设备
+	ErrUnregistered = errors.New("push token is no longer registered")
+	// ErrUnsupportedPlatform 设备平台没有配置推送服务
+	ErrUnsupportedPlatform = errors.New("unsupported push platform")
+)
+
+// Notification 推送通知
+type Notification struct {
+	Token       string            // 设备推送token
+	Title       string            // 标题
+	Body        string            // 正文
+	Data        map[string]string // 随通知下发给客户端的数据（如消息ID、群聊ID）
+	CollapseKey string            // 相同key的未展示通知只保留最新一条，为空时不合并
+}
+
+// Sender 推送发送接口
+type Sender interface {
+	// Send 发送通知，设备token已失效时返回ErrUnregistered
+	Send(ctx context.Context, n *Notification) error
+}
+
+// Factory 根据配置创建推送服务，服务账号、私钥等凭据从密钥提供者读取
+type Factory func(cfg config.PushConfig, provider secrets.Provider) (Sender, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Factory{
+		"log": func(config.PushConfig, secrets.Provider) (Sender, error) {
+			return NewLogSender(), nil
+		},
+		"fcm": func(cfg config.PushConfig, provider secrets.Provider) (Sender, error) {
+			return NewFCMSender(cfg, provider), nil
+		},
+		"apns": func(cfg config.PushConfig, provider secrets.Provider) (Sender, error) {
+			return NewAPNsSender(cfg, provider), nil
+		},
+	}
+)
+
+// RegisterProvider 注册推送服务，名称已存在时覆盖
+func RegisterProvider(name string, factory Factory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = factory
+}
+
+// NewSender 按名称创建推送服务
+func NewSender(name string, cfg config.PushConfig, provider secrets.Provider) (Sender, error) {
+	providersMu.RLock()
+	factory, ok := providers[name]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported push provider: %s", name)
+	}
+	return factory(cfg, provider)
+}
+
+// Router 按设备平台选择推送服务
+// 使用同一推送服务的平台共用一个实例（如android和web都使用fcm时共用访问token缓存）
+type Router struct {
+	senders map[string]Sender
+}
+
+// NewRouter 按配置创建各平台的推送服务
+func NewRouter(cfg config.PushConfig, provider secrets.Provider) (*Router, error) {
+	byName := make(map[string]Sender)
+	senders := make(map[string]Sender, len(cfg.Platforms))
+	for platform, name := range cfg.Platforms {
+		sender, ok := byName[name]
+		if !ok {
+			var err error
+			if sender, err = NewSender(name, cfg, provider); err != nil {
+				return nil, err
+			}
+			byName[name] = sender
+		}
+		senders[platform] = sender
+	}
+	return &Router{senders: senders}, nil
+}
+
+// NewRouterFromConfig 按应用配置创建密钥提供者和推送服务（定时任务等没有现成密钥提供者时使用）
+func NewRouterFromConfig(cfg *config.Config) (*Router, error) {
+	provider, err := secrets.NewProvider(cfg.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	return NewRouter(cfg.Push, provider)
+}
+
+// Send 通过设备平台对应的推送服务发送
+func (r *Router) Send(ctx context.Context, platform string, n *Notification) error {
+	sender, ok := r.senders[platform]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedPlatform, platform)
+	}
+	return sender.Send(ctx, n)
+}
+
+// Supports 设备平台是否配置了推送服务
+func (r *Router) Supports(platform string) bool {
+	_, ok := r.senders[platform]
+	return ok
+}
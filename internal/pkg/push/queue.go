@@ -0,0 +1,83 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"exchange/internal/pkg/database"
+)
+
+// queueKey 推送队列键
+const queueKey = "push_queue"
+
+// Job 推送任务，接收者离线时由API服务写入，定时任务取出后按用户的通知设置发送
+type Job struct {
+	UserID      uint      `json:"user_id"`  // 接收者
+	Kind        string    `json:"kind"`     // 触发推送的事件类型（如group_message）
+	From        uint      `json:"from"`     // 发送者
+	GroupID     string    `json:"group_id"` // 群聊消息所在的群，单聊为空
+	MessageID   string    `json:"message_id"`
+	MessageType string    `json:"message_type"` // text, image, file等，非文本消息不展示内容
+	Content     string    `json:"content"`
+	Attempts    int       `json:"attempts"` // 已尝试发送的次数
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Queue 基于Redis列表的推送队列，所有实例共用，先进先出
+type Queue struct {
+	redis *database.RedisService
+}
+
+// NewQueue 创建推送队列
+func NewQueue(redis *database.RedisService) *Queue {
+	return &Queue{redis: redis}
+}
+
+// Enqueue 写入推送任务
+func (q *Queue) Enqueue(ctx context.Context, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal push job: %w", err)
+	}
+	if err := q.redis.Client().RPush(ctx, queueKey, data).Err(); err != nil {
+		return fmt.Errorf("failed to enqueue push job: %w", err)
+	}
+	return nil
+}
+
+// Pop 取出最多n个推送任务，取出后即从队列中删除，无法解析的任务直接丢弃
+func (q *Queue) Pop(ctx context.Context, n int) ([]*Job, error) {
+	members, err := q.redis.Client().LPopCount(ctx, queueKey, n).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to pop push jobs: %w", err)
+	}
+
+	jobs := make([]*Job, 0, len(members))
+	for _, member := range members {
+		var job Job
+		if err := json.Unmarshal([]byte(member), &job); err != nil {
+			pushLogger.Warn("丢弃无法解析的推送任务", map[string]interface{}{
+				"error": err.Error(),
+			})
+			continue
+		}
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
+
+// Len 队列中等待发送的任务数
+func (q *Queue) Len(ctx context.Context) (int64, error) {
+	n, err := q.redis.Client().LLen(ctx, queueKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get push queue length: %w", err)
+	}
+	return n, nil
+}
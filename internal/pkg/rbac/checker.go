@@ -0,0 +1,183 @@
+package rbac
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"exchange/internal/models/mysql"
+	appLogger "exchange/internal/pkg/logger"
+)
+
+// rbacLogger 权限检查日志
+var rbacLogger = appLogger.Module("rbac")
+
+// Store 角色存储
+type Store interface {
+	// ListRoles 所有角色及其权限
+	ListRoles(ctx context.Context) ([]*mysql.Role, error)
+}
+
+// Role 角色及其生效的权限
+type Role struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	Builtin     bool     `json:"builtin"`
+	Customized  bool     `json:"customized"` // 是否已保存到roles表（内置角色保存后不再使用默认权限）
+}
+
+// PermissionChecker 权限检查
+// 各实例在内存中保存角色权限的快照，超过ttl后重新加载，通过本实例修改角色时调用Invalidate立即重新加载；
+// 加载失败时沿用上次加载的快照，从未加载成功时只有内置角色的默认权限
+type PermissionChecker struct {
+	store Store
+	ttl   time.Duration
+
+	reloadMu sync.Mutex // 同一时间只有一个请求重新加载
+
+	mu       sync.RWMutex
+	roles    map[string]*Role
+	loadedAt time.Time
+}
+
+// NewPermissionChecker 创建权限检查，store为nil时只使用内置角色的默认权限
+func NewPermissionChecker(store Store, ttl time.Duration) *PermissionChecker {
+	return &PermissionChecker{
+		store: store,
+		ttl:   ttl,
+		roles: builtinRoles(),
+	}
+}
+
+// HasPermission 角色是否拥有权限
+func (c *PermissionChecker) HasPermission(ctx context.Context, role, permission string) bool {
+	r, ok := c.snapshot(ctx)[role]
+	return ok && Match(r.Permissions, permission)
+}
+
+// RoleExists 角色是否存在
+func (c *PermissionChecker) RoleExists(ctx context.Context, role string) bool {
+	_, ok := c.snapshot(ctx)[role]
+	return ok
+}
+
+// Roles 所有角色，按名称排序
+func (c *PermissionChecker) Roles(ctx context.Context) ([]*Role, error) {
+	if err := c.reload(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	roles := make([]*Role, 0, len(c.roles))
+	for _, role := range c.roles {
+		roles = append(roles, role)
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+	return roles, nil
+}
+
+// Invalidate 角色修改后立即重新加载，失败时在下次检查时重试
+func (c *PermissionChecker) Invalidate(ctx context.Context) {
+	if err := c.reload(ctx); err != nil {
+		c.mu.Lock()
+		c.loadedAt = time.Time{}
+		c.mu.Unlock()
+	}
+}
+
+// snapshot 角色权限的快照，超过缓存时间时重新加载；加载失败时沿用旧快照
+func (c *PermissionChecker) snapshot(ctx context.Context) map[string]*Role {
+	c.mu.RLock()
+	stale := time.Since(c.loadedAt) >= c.ttl
+	roles := c.roles
+	c.mu.RUnlock()
+	if !stale || c.store == nil {
+		return roles
+	}
+
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+	c.mu.RLock()
+	stale = time.Since(c.loadedAt) >= c.ttl
+	c.mu.RUnlock()
+	if stale {
+		if err := c.load(ctx); err != nil {
+			rbacLogger.WithContext(ctx).Warn("加载角色权限失败，使用上次加载的权限", map[string]interface{}{
+				"error": err.Error(),
+			})
+			c.mu.Lock()
+			c.loadedAt = time.Now()
+			c.mu.Unlock()
+		}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.roles
+}
+
+// reload 立即重新加载
+func (c *PermissionChecker) reload(ctx context.Context) error {
+	if c.store == nil {
+		return nil
+	}
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+	return c.load(ctx)
+}
+
+// load 加载角色权限，表中的角色覆盖内置角色的默认权限
+func (c *PermissionChecker) load(ctx context.Context) error {
+	stored, err := c.store.ListRoles(ctx)
+	if err != nil {
+		return err
+	}
+
+	roles := builtinRoles()
+	for _, s := range stored {
+		permissions := make([]string, 0, len(s.Permissions))
+		for _, p := range s.Permissions {
+			permissions = append(permissions, p.Permission)
+		}
+		sort.Strings(permissions)
+		roles[s.Name] = &Role{
+			Name:        s.Name,
+			Description: s.Description,
+			Permissions: permissions,
+			Builtin:     IsBuiltin(s.Name),
+			Customized:  true,
+		}
+	}
+
+	c.mu.Lock()
+	c.roles = roles
+	c.loadedAt = time.Now()
+	c.mu.Unlock()
+	return nil
+}
+
+// builtinRoles 内置角色的默认权限
+func builtinRoles() map[string]*Role {
+	roles := make(map[string]*Role, len(BuiltinRoles))
+	for name, permissions := range BuiltinRoles {
+		roles[name] = &Role{
+			Name:        name,
+			Description: builtinDescriptions[name],
+			Permissions: append([]string(nil), permissions...),
+			Builtin:     true,
+		}
+	}
+	return roles
+}
+
+// builtinDescriptions 内置角色的说明
+var builtinDescriptions = map[string]string{
+	RoleSuper: "超级管理员",
+	RoleAdmin: "普通管理员",
+}
@@ -0,0 +1,149 @@
+// Package rbac 管理员基于角色的访问控制
+// 管理接口按权限（如"users:write"）授权，角色的权限保存在MySQL的roles/role_permissions表中，
+// 内置角色未保存到表中时使用代码中的默认权限。权限支持通配符："*"为所有权限，"users:*"为users下的所有权限
+package rbac
+
+import (
+	"strings"
+)
+
+// 管理接口的权限，格式为"资源:操作"
+const (
+	PermDashboardRead    = "dashboard:read"    // 查看仪表板
+	PermUsersRead        = "users:read"        // 查看用户列表、用户概览和导入任务
+	PermUsersImport      = "users:import"      // 批量导入用户
+	PermUsersLogout      = "users:logout"      // 强制用户下线（撤销所有登录会话）
+	PermUsersWrite       = "users:write"       // 封禁、锁定用户和要求用户重置密码
+	PermUsersDelete      = "users:delete"      // 删除和恢复用户
+	PermMessagesRead     = "messages:read"     // 在用户概览中查看消息内容
+	PermRetentionRead    = "retention:read"    // 查看合规报告和法律保全
+	PermRetentionWrite   = "retention:write"   // 设置和解除法律保全
+	PermLogLevelsRead    = "log_levels:read"   // 查看日志级别
+	PermLogLevelsWrite   = "log_levels:write"  // 设置和重置日志级别
+	PermAutomationRead   = "automation:read"   // 查看和预览消息自动化规则
+	PermAutomationWrite  = "automation:write"  // 创建、修改和删除消息自动化规则
+	PermTemplatesRead    = "templates:read"    // 查看和预览通知模板
+	PermTemplatesWrite   = "templates:write"   // 保存、启用和恢复通知模板
+	PermIPAccessRead     = "ip_access:read"    // 查看IP名单
+	PermIPAccessWrite    = "ip_access:write"   // 添加和删除IP名单条目
+	PermMaintenanceRead  = "maintenance:read"  // 查看维护状态
+	PermMaintenanceWrite = "maintenance:write" // 开启和关闭维护模式
+	PermTranslationsRead = "translations:read" // 查看翻译和翻译覆盖
+	PermTranslationsEdit = "translations:edit" // 保存和删除翻译覆盖
+	PermAuthzRead        = "authz:read"        // 查看路由权限矩阵
+	PermRolesRead        = "roles:read"        // 查看角色和权限
+	PermRolesWrite       = "roles:write"       // 创建、修改和删除角色
+	PermAuditRead        = "audit:read"        // 查看管理员操作审计记录
+
+	// PermAll 所有权限
+	PermAll = "*"
+)
+
+// Permission 权限说明
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Permissions 所有权限
+var Permissions = []Permission{
+	{PermDashboardRead, "查看仪表板"},
+	{PermUsersRead, "查看用户列表、用户概览和导入任务"},
+	{PermUsersImport, "批量导入用户"},
+	{PermUsersLogout, "强制用户下线（撤销所有登录会话）"},
+	{PermUsersWrite, "封禁、锁定用户和要求用户重置密码"},
+	{PermUsersDelete, "删除和恢复用户"},
+	{PermMessagesRead, "在用户概览中查看消息内容"},
+	{PermRetentionRead, "查看合规报告和法律保全"},
+	{PermRetentionWrite, "设置和解除法律保全"},
+	{PermLogLevelsRead, "查看日志级别"},
+	{PermLogLevelsWrite, "设置和重置日志级别"},
+	{PermAutomationRead, "查看和预览消息自动化规则"},
+	{PermAutomationWrite, "创建、修改和删除消息自动化规则"},
+	{PermTemplatesRead, "查看和预览通知模板"},
+	{PermTemplatesWrite, "保存、启用和恢复通知模板"},
+	{PermIPAccessRead, "查看IP名单"},
+	{PermIPAccessWrite, "添加和删除IP名单条目"},
+	{PermMaintenanceRead, "查看维护状态"},
+	{PermMaintenanceWrite, "开启和关闭维护模式"},
+	{PermTranslationsRead, "查看翻译和翻译覆盖"},
+	{PermTranslationsEdit, "保存和删除翻译覆盖"},
+	{PermAuthzRead, "查看路由权限矩阵"},
+	{PermRolesRead, "查看角色和权限"},
+	{PermRolesWrite, "创建、修改和删除角色"},
+	{PermAuditRead, "查看管理员操作审计记录"},
+}
+
+// 内置角色
+const (
+	RoleSuper = "super" // 超级管理员
+	RoleAdmin = "admin" // 普通管理员
+)
+
+// BuiltinRoles 内置角色的默认权限，角色保存到roles表后以表中的权限为准
+var BuiltinRoles = map[string][]string{
+	RoleSuper: {PermAll},
+	RoleAdmin: {
+		PermDashboardRead,
+		PermUsersRead,
+		PermRetentionRead,
+		PermLogLevelsRead,
+		PermAutomationRead,
+		PermAutomationWrite,
+		PermTemplatesRead,
+		PermIPAccessRead,
+		PermMaintenanceRead,
+		PermTranslationsRead,
+	},
+}
+
+// IsBuiltin 是否为内置角色，内置角色不能删除
+func IsBuiltin(role string) bool {
+	_, ok := BuiltinRoles[role]
+	return ok
+}
+
+// IsValid 权限是否有效：已定义的权限、"*"或"资源:*"
+func IsValid(permission string) bool {
+	if permission == PermAll {
+		return true
+	}
+	if resource, ok := strings.CutSuffix(permission, ":*"); ok {
+		for _, p := range Permissions {
+			if strings.HasPrefix(p.Name, resource+":") {
+				return true
+			}
+		}
+		return false
+	}
+	for _, p := range Permissions {
+		if p.Name == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Match 授予的权限中是否包含permission
+func Match(granted []string, permission string) bool {
+	resource, _, _ := strings.Cut(permission, ":")
+	for _, g := range granted {
+		if g == PermAll || g == permission || g == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// adminTokenPrefix 管理员token中角色的前缀，用于区分管理员token和用户token
+const adminTokenPrefix = "admin:"
+
+// AdminTokenRole 管理员token中保存的角色
+func AdminTokenRole(role string) string {
+	return adminTokenPrefix + role
+}
+
+// ParseAdminTokenRole 从token的角色中解析管理员角色，不是管理员token时返回false
+func ParseAdminTokenRole(tokenRole string) (string, bool) {
+	return strings.CutPrefix(tokenRole, adminTokenPrefix)
+}
@@ -0,0 +1,60 @@
+// Package requestid 请求ID的生成、校验和在context.Context中的传递
+// HTTP请求由请求ID中间件写入，日志记录器、错误上报和异步任务（通知发送等）从上下文中读取，用于关联同一请求的日志
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+const (
+	Header     = "X-Request-ID" // 请求和响应头
+	ContextKey = "request_id"   // Gin上下文键和日志字段名
+	MaxLength  = 128            // 客户端传入的请求ID最大长度
+)
+
+// contextKey context.Context中的键
+type contextKey struct{}
+
+// NewContext 返回携带请求ID的上下文
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext 获取上下文中的请求ID，不存在时返回空字符串
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Generate 生成32位十六进制请求ID
+func Generate() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%032x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// Valid 客户端传入的请求ID是否可以沿用：非空、不超过MaxLength，只包含字母、数字和 - _ . :
+// 不满足时重新生成，避免换行等字符进入日志
+func Valid(id string) bool {
+	if id == "" || len(id) > MaxLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
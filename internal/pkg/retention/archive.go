@@ -0,0 +1,277 @@
+package retention
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	mongoModel "exchange/internal/models/mongodb"
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/logger"
+	"exchange/internal/pkg/objectstore"
+	"exchange/internal/pkg/secrets"
+	"exchange/internal/repository"
+)
+
+// CategoryArchive 消息归档的执行记录类别
+const CategoryArchive = "archive"
+
+// ArchiveStore 法律保全、会话归档策略查询和执行记录存储
+type ArchiveStore interface {
+	GetHeldUserIDs(ctx context.Context) ([]uint, error)
+	ListArchiveOverrides(ctx context.Context) ([]*mysql.MessageArchiveOverride, error)
+	CreateRun(ctx context.Context, run *mysql.RetentionRun) error
+}
+
+// ArchiveSink 消息归档目标，写入成功后消息才会从热集合删除
+type ArchiveSink interface {
+	// Name 集合名或对象key前缀，写入执行记录
+	Name() string
+	// Write 写入一批消息，同一批消息重复写入不产生重复数据
+	Write(ctx context.Context, messages []*mongoModel.ChatMessage) error
+}
+
+// NewArchiveSink 根据retention.archive.target创建归档目标
+func NewArchiveSink(cfg *config.Config, messages repository.MessageArchiveRepository) (ArchiveSink, error) {
+	switch cfg.Retention.Archive.Target {
+	case config.ArchiveTargetMongoDB:
+		return &CollectionSink{messages: messages}, nil
+	case config.ArchiveTargetStorage:
+		if !objectstore.ValidKey(cfg.Retention.Archive.KeyPrefix) {
+			return nil, fmt.Errorf("invalid archive key prefix: %s", cfg.Retention.Archive.KeyPrefix)
+		}
+		provider, err := secrets.NewProvider(cfg.Secrets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create secrets provider: %w", err)
+		}
+		store, err := objectstore.NewStore(cfg.Attachment, provider)
+		if err != nil {
+			return nil, err
+		}
+		return &StorageSink{store: store, prefix: cfg.Retention.Archive.KeyPrefix}, nil
+	default:
+		return nil, fmt.Errorf("unsupported archive target: %s", cfg.Retention.Archive.Target)
+	}
+}
+
+// CollectionSink 归档到MongoDB冷集合
+type CollectionSink struct {
+	messages repository.MessageArchiveRepository
+}
+
+// Name 冷集合名
+func (s *CollectionSink) Name() string { return mongoModel.ArchivedMessageCollection }
+
+// Write 写入冷集合，已存在的消息忽略
+func (s *CollectionSink) Write(ctx context.Context, messages []*mongoModel.ChatMessage) error {
+	return s.messages.SaveArchived(ctx, messages)
+}
+
+// StorageSink 归档到对象存储，每批消息一个gzip压缩的JSON Lines文件
+// key为<前缀>/<归档日期>/<第一条消息ID>-<最后一条消息ID>.jsonl.gz，重复写入同一批消息时覆盖
+type StorageSink struct {
+	store  objectstore.Store
+	prefix string
+}
+
+// Name 对象key前缀
+func (s *StorageSink) Name() string { return s.prefix }
+
+// Write 上传一批消息
+func (s *StorageSink) Write(ctx context.Context, messages []*mongoModel.ChatMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gz)
+	for _, message := range messages {
+		if err := encoder.Encode(message); err != nil {
+			return fmt.Errorf("failed to encode archived message: %w", err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress archived messages: %w", err)
+	}
+
+	key := fmt.Sprintf("%s/%s/%s-%s.jsonl.gz",
+		s.prefix,
+		clock.Now().UTC().Format("2006/01/02"),
+		messages[0].ID.Hex(),
+		messages[len(messages)-1].ID.Hex(),
+	)
+	if err := s.store.Put(ctx, key, &buf, int64(buf.Len()), "application/gzip"); err != nil {
+		return fmt.Errorf("failed to upload archived messages: %w", err)
+	}
+	return nil
+}
+
+// Archiver 聊天消息归档执行器
+// 默认归档早于retention.archive.days的消息，单独设置了策略的会话按各自的天数归档或不归档；
+// 与保留期清理一致，处于法律保全中的用户发送或接收的消息不归档
+type Archiver struct {
+	config   config.MessageArchiveConfig
+	store    ArchiveStore
+	messages repository.MessageArchiveRepository
+	sink     ArchiveSink
+}
+
+// NewArchiver 创建消息归档执行器
+func NewArchiver(cfg config.MessageArchiveConfig, store ArchiveStore, messages repository.MessageArchiveRepository, sink ArchiveSink) *Archiver {
+	return &Archiver{
+		config:   cfg,
+		store:    store,
+		messages: messages,
+		sink:     sink,
+	}
+}
+
+// archivePass 一次归档遍历：默认策略或单个会话的策略
+type archivePass struct {
+	days  int
+	scope repository.MessageArchiveScope
+}
+
+// Run 执行一次归档，dryRun为true时只统计不归档
+// 执行结果写入retention_runs，Matched为待归档数量，Purged为已归档（从热集合删除）的数量
+func (a *Archiver) Run(ctx context.Context, dryRun bool) (*mysql.RetentionRun, error) {
+	now := clock.Now()
+	run := &mysql.RetentionRun{
+		Category:   CategoryArchive,
+		Store:      StoreMongoDB,
+		Target:     a.sink.Name(),
+		DryRun:     dryRun,
+		RetainDays: a.config.Days,
+		StartedAt:  now.UnixNano(),
+	}
+
+	err := a.run(ctx, now, dryRun, run)
+	if err != nil {
+		run.Status = mysql.RetentionRunStatusFailed
+		run.Error = err.Error()
+	}
+	run.FinishedAt = clock.Now().UnixNano()
+
+	// 执行记录写入失败不影响归档本身
+	if createErr := a.store.CreateRun(ctx, run); createErr != nil {
+		logger.Error("保存消息归档执行记录失败", map[string]interface{}{
+			"target": run.Target,
+			"error":  createErr.Error(),
+		})
+	}
+
+	return run, err
+}
+
+// run 按默认策略和各会话的策略依次归档
+func (a *Archiver) run(ctx context.Context, now time.Time, dryRun bool, run *mysql.RetentionRun) error {
+	if !a.config.Enabled || a.config.Days <= 0 {
+		run.Status = mysql.RetentionRunStatusSkipped
+		return nil
+	}
+
+	heldUserIDs, err := a.store.GetHeldUserIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("获取法律保全用户失败: %w", err)
+	}
+	held := make([]string, len(heldUserIDs))
+	for i, id := range heldUserIDs {
+		held[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	run.HeldUsers = len(heldUserIDs)
+
+	overrides, err := a.store.ListArchiveOverrides(ctx)
+	if err != nil {
+		return fmt.Errorf("获取会话归档策略失败: %w", err)
+	}
+
+	passes := []archivePass{{days: a.config.Days}}
+	for _, override := range overrides {
+		conversation, ok := archiveConversation(override)
+		if !ok {
+			continue
+		}
+		// 所有单独设置了策略的会话都不按默认策略归档，天数为0的会话不归档
+		passes[0].scope.Exclude = append(passes[0].scope.Exclude, conversation)
+		if override.Days > 0 {
+			passes = append(passes, archivePass{days: override.Days, scope: repository.MessageArchiveScope{Only: &conversation}})
+		}
+	}
+
+	for i := range passes {
+		pass := &passes[i]
+		pass.scope.Before = now.AddDate(0, 0, -pass.days)
+		pass.scope.HeldUserIDs = held
+		if i == 0 {
+			run.Cutoff = pass.scope.Before.UnixNano()
+		}
+
+		matched, archived, err := a.archive(ctx, pass.scope, dryRun)
+		run.Matched += matched
+		run.Purged += archived
+		if err != nil {
+			return err
+		}
+	}
+
+	run.Status = mysql.RetentionRunStatusSuccess
+	return nil
+}
+
+// archive 按批将范围内的消息写入归档目标后从热集合删除，返回待归档和已归档的数量
+func (a *Archiver) archive(ctx context.Context, scope repository.MessageArchiveScope, dryRun bool) (int64, int64, error) {
+	matched, err := a.messages.CountArchivable(ctx, scope)
+	if err != nil {
+		return 0, 0, err
+	}
+	if dryRun || matched == 0 {
+		return matched, 0, nil
+	}
+
+	var archived int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return matched, archived, err
+		}
+
+		messages, err := a.messages.FindArchivable(ctx, scope, a.config.BatchSize)
+		if err != nil {
+			return matched, archived, err
+		}
+		if len(messages) == 0 {
+			return matched, archived, nil
+		}
+
+		if err := a.sink.Write(ctx, messages); err != nil {
+			return matched, archived, err
+		}
+		deleted, err := a.messages.DeleteArchived(ctx, messages)
+		archived += deleted
+		if err != nil {
+			return matched, archived, err
+		}
+
+		if len(messages) < a.config.BatchSize {
+			return matched, archived, nil
+		}
+	}
+}
+
+// archiveConversation 将会话的归档策略转换为查询范围中的会话，无效的策略跳过
+func archiveConversation(override *mysql.MessageArchiveOverride) (repository.ArchiveConversation, bool) {
+	if override.ConversationType == mysql.ArchiveConversationGroup {
+		return repository.ArchiveConversation{GroupID: override.ConversationID}, override.ConversationID != ""
+	}
+	userID1, userID2, ok := override.DirectUsers()
+	if !ok || userID1 == "" || userID2 == "" {
+		return repository.ArchiveConversation{}, false
+	}
+	return repository.ArchiveConversation{UserID1: userID1, UserID2: userID2}, true
+}
@@ -0,0 +1,160 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/logger"
+)
+
+// 数据类别
+const (
+	CategoryLogs      = "logs"
+	CategoryMessages  = "messages"
+	CategoryAudit     = "audit"
+	CategorySessions  = "sessions"
+	CategoryAnalytics = "analytics"
+)
+
+// 存储类型
+const (
+	StoreMySQL   = "mysql"
+	StoreMongoDB = "mongodb"
+	StoreRedis   = "redis"
+)
+
+// Target 一类数据在某个存储中的清理目标
+type Target interface {
+	// Category 数据类别
+	Category() string
+	// Store 存储类型
+	Store() string
+	// Name 表名/集合名/键模式
+	Name() string
+	// Count 统计早于cutoff且不属于保全用户的数据量
+	Count(ctx context.Context, cutoff time.Time, heldUserIDs []uint) (int64, error)
+	// Purge 清理早于cutoff且不属于保全用户的数据，返回清理数量
+	Purge(ctx context.Context, cutoff time.Time, heldUserIDs []uint) (int64, error)
+}
+
+// Store 法律保全查询和执行记录存储
+type Store interface {
+	GetHeldUserIDs(ctx context.Context) ([]uint, error)
+	CreateRun(ctx context.Context, run *mysql.RetentionRun) error
+}
+
+// Report 一次保留期清理的执行报告
+type Report struct {
+	DryRun     bool                  `json:"dry_run"`
+	StartedAt  time.Time             `json:"started_at"`
+	FinishedAt time.Time             `json:"finished_at"`
+	HeldUsers  int                   `json:"held_users"`
+	Runs       []*mysql.RetentionRun `json:"runs"`
+}
+
+// Engine 数据保留策略执行引擎
+// 按类别读取保留天数，依次在各存储中统计并清理过期数据，处于法律保全中的用户数据不清理
+type Engine struct {
+	policies map[string]config.RetentionPolicy
+	store    Store
+	targets  []Target
+}
+
+// NewEngine 创建数据保留引擎
+func NewEngine(cfg config.RetentionConfig, store Store, targets ...Target) *Engine {
+	return &Engine{
+		policies: cfg.Policies(),
+		store:    store,
+		targets:  targets,
+	}
+}
+
+// Run 执行一次保留期清理，dryRun为true时只统计不删除
+// 单个目标失败不影响其他目标，所有失败会合并返回
+func (e *Engine) Run(ctx context.Context, dryRun bool) (*Report, error) {
+	heldUserIDs, err := e.store.GetHeldUserIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取法律保全用户失败: %w", err)
+	}
+
+	report := &Report{
+		DryRun:    dryRun,
+		StartedAt: clock.Now(),
+		HeldUsers: len(heldUserIDs),
+	}
+
+	var errs []error
+	for _, target := range e.targets {
+		run := e.runTarget(ctx, target, dryRun, heldUserIDs)
+		report.Runs = append(report.Runs, run)
+
+		if run.Status == mysql.RetentionRunStatusFailed {
+			errs = append(errs, fmt.Errorf("%s/%s: %s", target.Category(), target.Name(), run.Error))
+		}
+
+		// 执行记录写入失败不影响清理本身
+		if err := e.store.CreateRun(ctx, run); err != nil {
+			logger.Error("保存数据保留执行记录失败", map[string]interface{}{
+				"category": run.Category,
+				"target":   run.Target,
+				"error":    err.Error(),
+			})
+		}
+	}
+
+	report.FinishedAt = clock.Now()
+	return report, errors.Join(errs...)
+}
+
+// runTarget 对单个目标执行统计和清理
+func (e *Engine) runTarget(ctx context.Context, target Target, dryRun bool, heldUserIDs []uint) *mysql.RetentionRun {
+	policy := e.policies[target.Category()]
+	now := clock.Now()
+
+	run := &mysql.RetentionRun{
+		Category:   target.Category(),
+		Store:      target.Store(),
+		Target:     target.Name(),
+		DryRun:     dryRun,
+		RetainDays: policy.Days,
+		HeldUsers:  len(heldUserIDs),
+		StartedAt:  now.UnixNano(),
+	}
+	defer func() {
+		run.FinishedAt = clock.Now().UnixNano()
+	}()
+
+	if !policy.Enabled || policy.Days <= 0 {
+		run.Status = mysql.RetentionRunStatusSkipped
+		return run
+	}
+
+	cutoff := now.AddDate(0, 0, -policy.Days)
+	run.Cutoff = cutoff.UnixNano()
+
+	matched, err := target.Count(ctx, cutoff, heldUserIDs)
+	if err != nil {
+		run.Status = mysql.RetentionRunStatusFailed
+		run.Error = err.Error()
+		return run
+	}
+	run.Matched = matched
+
+	if !dryRun && matched > 0 {
+		purged, err := target.Purge(ctx, cutoff, heldUserIDs)
+		run.Purged = purged
+		if err != nil {
+			run.Status = mysql.RetentionRunStatusFailed
+			run.Error = err.Error()
+			return run
+		}
+	}
+
+	run.Status = mysql.RetentionRunStatusSuccess
+	return run
+}
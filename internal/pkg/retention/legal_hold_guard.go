@@ -0,0 +1,45 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrUserUnderLegalHold 用户处于法律保全中
+var ErrUserUnderLegalHold = errors.New("用户处于法律保全中，暂不能注销")
+
+// HoldChecker 法律保全查询
+type HoldChecker interface {
+	GetHeldUserIDs(ctx context.Context) ([]uint, error)
+}
+
+// LegalHoldGuard 账户注销前置校验：法律保全期间不允许匿名化用户数据
+type LegalHoldGuard struct {
+	holds HoldChecker
+}
+
+// NewLegalHoldGuard 创建法律保全注销校验
+func NewLegalHoldGuard(holds HoldChecker) *LegalHoldGuard {
+	return &LegalHoldGuard{holds: holds}
+}
+
+// Name 校验名称
+func (g *LegalHoldGuard) Name() string {
+	return "legal_hold"
+}
+
+// CheckDeletable 用户处于法律保全中时返回错误
+func (g *LegalHoldGuard) CheckDeletable(ctx context.Context, userID uint) error {
+	userIDs, err := g.holds.GetHeldUserIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("查询法律保全失败: %w", err)
+	}
+
+	for _, id := range userIDs {
+		if id == userID {
+			return ErrUserUnderLegalHold
+		}
+	}
+	return nil
+}
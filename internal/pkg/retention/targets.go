@@ -0,0 +1,282 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"gorm.io/gorm"
+
+	mongoModel "exchange/internal/models/mongodb"
+	mysqlModel "exchange/internal/models/mysql"
+	"exchange/internal/pkg/database"
+)
+
+// MySQL每批删除的行数，避免长时间锁表
+const mysqlDeleteBatchSize = 1000
+
+// DefaultTargets 返回各类数据的默认清理目标，未初始化的存储会被跳过
+// 日志文件由LogCleanupTask按log配置清理，这里只处理入库的系统日志
+func DefaultTargets(mysqlService *database.MySQLService, mongoService *database.MongoDBService, redisService *database.RedisService) []Target {
+	var targets []Target
+
+	if mongoService != nil {
+		targets = append(targets,
+			&MongoTarget{
+				category:   CategoryLogs,
+				collection: mongoModel.SystemLog{}.CollectionName(),
+				timeField:  "timestamp",
+				mongo:      mongoService,
+			},
+			&MongoTarget{
+				category:      CategoryMessages,
+				collection:    mongoModel.ChatMessage{}.CollectionName(),
+				timeField:     "created_at",
+				userFields:    []string{"from_user_id", "to_user_id"},
+				userIDsString: true,
+				mongo:         mongoService,
+			},
+			// 归档到冷集合的消息同样按消息保留期清理
+			&MongoTarget{
+				category:      CategoryMessages,
+				collection:    mongoModel.ArchivedMessageCollection,
+				timeField:     "created_at",
+				userFields:    []string{"from_user_id", "to_user_id"},
+				userIDsString: true,
+				mongo:         mongoService,
+			},
+			&MongoTarget{
+				category:   CategoryAnalytics,
+				collection: "analytics_events",
+				timeField:  "created_at",
+				userFields: []string{"user_id"},
+				mongo:      mongoService,
+			},
+		)
+	}
+
+	if mysqlService != nil {
+		targets = append(targets, &AuditTarget{db: mysqlService.DB()})
+	}
+
+	if redisService != nil {
+		targets = append(targets, &RedisTarget{
+			category: CategorySessions,
+			pattern:  "revoked_token:*",
+			redis:    redisService,
+		})
+	}
+
+	return targets
+}
+
+// MongoTarget MongoDB集合清理目标
+type MongoTarget struct {
+	category      string
+	collection    string
+	timeField     string   // 时间字段
+	userFields    []string // 用户ID字段，保全用户的数据不清理
+	userIDsString bool     // 用户ID是否以字符串存储
+	mongo         *database.MongoDBService
+}
+
+// Category 数据类别
+func (t *MongoTarget) Category() string { return t.category }
+
+// Store 存储类型
+func (t *MongoTarget) Store() string { return StoreMongoDB }
+
+// Name 集合名
+func (t *MongoTarget) Name() string { return t.collection }
+
+// Count 统计过期文档
+func (t *MongoTarget) Count(ctx context.Context, cutoff time.Time, heldUserIDs []uint) (int64, error) {
+	count, err := t.mongo.Collection(t.collection).CountDocuments(ctx, t.filter(cutoff, heldUserIDs))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count documents in %s: %w", t.collection, err)
+	}
+	return count, nil
+}
+
+// Purge 删除过期文档
+func (t *MongoTarget) Purge(ctx context.Context, cutoff time.Time, heldUserIDs []uint) (int64, error) {
+	result, err := t.mongo.Collection(t.collection).DeleteMany(ctx, t.filter(cutoff, heldUserIDs))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete documents from %s: %w", t.collection, err)
+	}
+	return result.DeletedCount, nil
+}
+
+// filter 构建过期且不属于保全用户的查询条件
+func (t *MongoTarget) filter(cutoff time.Time, heldUserIDs []uint) bson.M {
+	filter := bson.M{t.timeField: bson.M{"$lt": cutoff}}
+	if len(heldUserIDs) == 0 {
+		return filter
+	}
+
+	var held interface{} = heldUserIDs
+	if t.userIDsString {
+		ids := make([]string, len(heldUserIDs))
+		for i, id := range heldUserIDs {
+			ids[i] = strconv.FormatUint(uint64(id), 10)
+		}
+		held = ids
+	}
+
+	for _, field := range t.userFields {
+		filter[field] = bson.M{"$nin": held}
+	}
+	return filter
+}
+
+// AuditTarget 管理员审计日志清理目标，操作对象为保全用户的日志不清理
+type AuditTarget struct {
+	db *gorm.DB
+}
+
+// Category 数据类别
+func (t *AuditTarget) Category() string { return CategoryAudit }
+
+// Store 存储类型
+func (t *AuditTarget) Store() string { return StoreMySQL }
+
+// Name 表名
+func (t *AuditTarget) Name() string { return mysqlModel.AdminLog{}.TableName() }
+
+// Count 统计过期审计日志
+func (t *AuditTarget) Count(ctx context.Context, cutoff time.Time, heldUserIDs []uint) (int64, error) {
+	var count int64
+	if err := t.query(ctx, cutoff, heldUserIDs).Model(&mysqlModel.AdminLog{}).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count admin logs: %w", err)
+	}
+	return count, nil
+}
+
+// Purge 分批物理删除过期审计日志
+func (t *AuditTarget) Purge(ctx context.Context, cutoff time.Time, heldUserIDs []uint) (int64, error) {
+	var total int64
+	for {
+		result := t.query(ctx, cutoff, heldUserIDs).Limit(mysqlDeleteBatchSize).Delete(&mysqlModel.AdminLog{})
+		if result.Error != nil {
+			return total, fmt.Errorf("failed to delete admin logs: %w", result.Error)
+		}
+		total += result.RowsAffected
+		if result.RowsAffected < mysqlDeleteBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// query 构建过期且不涉及保全用户的查询（包含已软删除的记录）
+func (t *AuditTarget) query(ctx context.Context, cutoff time.Time, heldUserIDs []uint) *gorm.DB {
+	query := t.db.WithContext(ctx).Unscoped().Where("created_at < ?", cutoff.UnixNano())
+	if len(heldUserIDs) > 0 {
+		ids := make([]string, len(heldUserIDs))
+		for i, id := range heldUserIDs {
+			ids[i] = strconv.FormatUint(uint64(id), 10)
+		}
+		query = query.Where("NOT (target_type = ? AND target_id IN ?)", mysqlModel.AdminLogTargetUser, ids)
+	}
+	return query
+}
+
+// RedisTarget Redis键清理目标
+// Redis键不记录创建时间，以空闲时间（OBJECT IDLETIME）超过保留期作为过期依据；键不含用户信息，不做保全排除
+type RedisTarget struct {
+	category string
+	pattern  string
+	redis    *database.RedisService
+}
+
+// Category 数据类别
+func (t *RedisTarget) Category() string { return t.category }
+
+// Store 存储类型
+func (t *RedisTarget) Store() string { return StoreRedis }
+
+// Name 键模式
+func (t *RedisTarget) Name() string { return t.pattern }
+
+// Count 统计空闲超过保留期的键
+func (t *RedisTarget) Count(ctx context.Context, cutoff time.Time, heldUserIDs []uint) (int64, error) {
+	var count int64
+	err := t.scanIdle(ctx, cutoff, func(ctx context.Context, client *redis.Client, keys []string) error {
+		count += int64(len(keys))
+		return nil
+	})
+	return count, err
+}
+
+// Purge 删除空闲超过保留期的键
+// 集群模式下同一节点上的键也可能属于不同槽位，逐个删除（管道批量发送）
+func (t *RedisTarget) Purge(ctx context.Context, cutoff time.Time, heldUserIDs []uint) (int64, error) {
+	var total int64
+	err := t.scanIdle(ctx, cutoff, func(ctx context.Context, client *redis.Client, keys []string) error {
+		if !t.redis.IsCluster() {
+			deleted, err := client.Del(ctx, keys...).Result()
+			total += deleted
+			return err
+		}
+
+		cmds, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				pipe.Del(ctx, key)
+			}
+			return nil
+		})
+		for _, cmd := range cmds {
+			total += cmd.(*redis.IntCmd).Val()
+		}
+		return err
+	})
+	return total, err
+}
+
+// scanIdle 在每个主节点上扫描匹配的键，按批回调空闲超过保留期的键
+// 集群模式下各节点并发扫描，回调串行执行
+func (t *RedisTarget) scanIdle(ctx context.Context, cutoff time.Time, fn func(ctx context.Context, client *redis.Client, keys []string) error) error {
+	maxIdle := time.Since(cutoff)
+	var mu sync.Mutex
+
+	return t.redis.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+		var cursor uint64
+		for {
+			keys, next, err := client.Scan(ctx, cursor, t.pattern, 500).Result()
+			if err != nil {
+				return fmt.Errorf("failed to scan redis keys: %w", err)
+			}
+
+			var idleKeys []string
+			for _, key := range keys {
+				idle, err := client.ObjectIdleTime(ctx, key).Result()
+				if err == redis.Nil {
+					continue // 扫描后已过期
+				}
+				if err != nil {
+					return fmt.Errorf("failed to get idle time of %s: %w", key, err)
+				}
+				if idle >= maxIdle {
+					idleKeys = append(idleKeys, key)
+				}
+			}
+
+			if len(idleKeys) > 0 {
+				mu.Lock()
+				err := fn(ctx, client, idleKeys)
+				mu.Unlock()
+				if err != nil {
+					return fmt.Errorf("failed to process redis keys: %w", err)
+				}
+			}
+
+			cursor = next
+			if cursor == 0 {
+				return nil
+			}
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"exchange/internal/pkg/config"
+)
+
+// ErrNotFound 密钥不存在
+var ErrNotFound = errors.New("secret not found")
+
+// Provider 密钥提供者
+// 每次调用都从来源读取最新值，密钥轮换时只需更新来源（环境变量、挂载文件等），由调用方决定缓存策略
+type Provider interface {
+	// Get 获取密钥，不存在时返回ErrNotFound
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// NewProvider 根据配置创建密钥提供者
+func NewProvider(cfg config.SecretsConfig) (Provider, error) {
+	switch cfg.Provider {
+	case "env":
+		return NewEnvProvider(cfg.EnvPrefix), nil
+	case "file":
+		return NewFileProvider(cfg.Dir), nil
+	case "config":
+		return NewStaticProvider(cfg.Values), nil
+	default:
+		return nil, fmt.Errorf("unsupported secrets provider: %s", cfg.Provider)
+	}
+}
+
+// Versions 拆分多版本密钥
+// 值中可包含多个版本（逗号或换行分隔），第一个为当前版本，其余为轮换过渡期内仍然有效的旧版本
+func Versions(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	})
+
+	versions := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			versions = append(versions, field)
+		}
+	}
+	return versions
+}
+
+// EnvProvider 从环境变量读取密钥
+// 变量名为前缀加上转大写的密钥名，非字母数字字符替换为下划线，如 SECRET_SERVICE_SECRET_BILLING
+type EnvProvider struct {
+	prefix string
+}
+
+// NewEnvProvider 创建环境变量密钥提供者
+func NewEnvProvider(prefix string) *EnvProvider {
+	return &EnvProvider{prefix: prefix}
+}
+
+// Get 获取密钥
+func (p *EnvProvider) Get(ctx context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(p.EnvName(name))
+	if !ok || value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// EnvName 密钥对应的环境变量名
+func (p *EnvProvider) EnvName(name string) string {
+	return p.prefix + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// FileProvider 从目录读取密钥，每个密钥一个文件，文件名为密钥名
+// 适用于Kubernetes Secret、Docker secrets等挂载方式，更新挂载内容即可完成轮换
+type FileProvider struct {
+	dir string
+}
+
+// NewFileProvider 创建文件密钥提供者
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{dir: dir}
+}
+
+// Get 获取密钥
+func (p *FileProvider) Get(ctx context.Context, name string) (string, error) {
+	// 密钥名不允许包含路径
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid secret name: %q", name)
+	}
+
+	data, err := os.ReadFile(filepath.Join(p.dir, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("failed to read secret %s: %w", name, err)
+	}
+
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// StaticProvider 使用配置文件中的密钥，仅用于开发环境
+type StaticProvider struct {
+	values map[string]string
+}
+
+// NewStaticProvider 创建静态密钥提供者
+func NewStaticProvider(values map[string]string) *StaticProvider {
+	return &StaticProvider{values: values}
+}
+
+// Get 获取密钥
+func (p *StaticProvider) Get(ctx context.Context, name string) (string, error) {
+	value, ok := p.values[name]
+	if !ok || value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
@@ -0,0 +1,265 @@
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/cron"
+	"exchange/internal/pkg/database"
+	"exchange/internal/pkg/notification"
+	"exchange/internal/pkg/secrets"
+	"exchange/internal/pkg/signing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// 自检使用的集合、键名前缀，写入的数据在检查结束前删除
+const (
+	selftestCollection = "selftest"
+	selftestKeyPrefix  = "selftest:"
+	selftestLockTTL    = 10 * time.Second
+)
+
+// Checker 按配置创建依赖连接并提供各项检查，连接在多项检查间复用
+type Checker struct {
+	cfg        *config.Config
+	instanceID string
+
+	mysql   *database.MySQLService
+	redis   *database.RedisService
+	mongodb *database.MongoDBService
+}
+
+// NewChecker 创建检查器
+func NewChecker(cfg *config.Config) *Checker {
+	return &Checker{
+		cfg:        cfg,
+		instanceID: fmt.Sprintf("selftest-%d", time.Now().UnixNano()),
+	}
+}
+
+// Checks 返回全部检查项，按依赖顺序排列
+func (c *Checker) Checks() []Check {
+	return []Check{
+		{Name: "mysql", Description: "MySQL连接及查询往返", Run: c.checkMySQL},
+		{Name: "mongodb", Description: "MongoDB写入、读取、删除临时文档", Run: c.checkMongoDB},
+		{Name: "redis", Description: "Redis写入、读取、删除临时键", Run: c.checkRedis},
+		{Name: "redis_lock", Description: "分布式锁获取与释放", Run: c.checkRedisLock},
+		{Name: "notification", Description: "通知发送", Run: c.checkNotification},
+		{Name: "signing", Description: "内部服务请求签名及验证", Run: c.checkSigning},
+		{Name: "storage", Description: "对象存储上传与删除", Run: c.checkStorage},
+	}
+}
+
+// Close 关闭检查中创建的连接
+func (c *Checker) Close() {
+	if c.mysql != nil {
+		c.mysql.Close()
+	}
+	if c.redis != nil {
+		c.redis.Close()
+	}
+	if c.mongodb != nil {
+		c.mongodb.Close()
+	}
+}
+
+// checkMySQL 查询服务器版本，并在事务中执行查询后回滚
+func (c *Checker) checkMySQL(ctx context.Context) (string, error) {
+	if c.mysql == nil {
+		service, err := database.NewMySQLService(c.cfg)
+		if err != nil {
+			return "", err
+		}
+		c.mysql = service
+	}
+
+	db := c.mysql.DB().WithContext(ctx)
+	var version string
+	if err := db.Raw("SELECT VERSION()").Scan(&version).Error; err != nil {
+		return "", fmt.Errorf("查询服务器版本失败: %w", err)
+	}
+
+	tx := db.Begin()
+	if tx.Error != nil {
+		return "", fmt.Errorf("开启事务失败: %w", tx.Error)
+	}
+	var one int
+	err := tx.Raw("SELECT 1").Scan(&one).Error
+	if rollbackErr := tx.Rollback().Error; err == nil && rollbackErr != nil {
+		err = fmt.Errorf("回滚事务失败: %w", rollbackErr)
+	}
+	if err != nil {
+		return "", fmt.Errorf("事务内查询失败: %w", err)
+	}
+
+	return fmt.Sprintf("%s:%d/%s, version %s", c.cfg.Database.Host, c.cfg.Database.Port, c.cfg.Database.Database, version), nil
+}
+
+// checkMongoDB 写入临时文档，读回后删除
+func (c *Checker) checkMongoDB(ctx context.Context) (string, error) {
+	if c.mongodb == nil {
+		service, err := database.NewMongoDBService(c.cfg)
+		if err != nil {
+			return "", err
+		}
+		c.mongodb = service
+	}
+
+	collection := c.mongodb.Collection(selftestCollection)
+	filter := bson.M{"_id": c.instanceID}
+	if _, err := collection.InsertOne(ctx, bson.M{"_id": c.instanceID, "created_at": time.Now()}); err != nil {
+		return "", fmt.Errorf("写入临时文档失败: %w", err)
+	}
+	defer collection.DeleteOne(context.Background(), filter)
+
+	var doc bson.M
+	if err := collection.FindOne(ctx, filter).Decode(&doc); err != nil {
+		return "", fmt.Errorf("读取临时文档失败: %w", err)
+	}
+	result, err := collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return "", fmt.Errorf("删除临时文档失败: %w", err)
+	}
+	if result.DeletedCount != 1 {
+		return "", fmt.Errorf("删除临时文档失败: 删除数量为 %d", result.DeletedCount)
+	}
+
+	return fmt.Sprintf("%s.%s", c.cfg.MongoDB.Database, selftestCollection), nil
+}
+
+// connectRedis 按需创建Redis连接
+func (c *Checker) connectRedis() (*database.RedisService, error) {
+	if c.redis == nil {
+		service, err := database.NewRedisService(c.cfg)
+		if err != nil {
+			return nil, err
+		}
+		c.redis = service
+	}
+	return c.redis, nil
+}
+
+// checkRedis 写入带过期时间的临时键，读回比较后删除
+func (c *Checker) checkRedis(ctx context.Context) (string, error) {
+	redisService, err := c.connectRedis()
+	if err != nil {
+		return "", err
+	}
+
+	client := redisService.Client()
+	key := selftestKeyPrefix + c.instanceID
+	if err := client.Set(ctx, key, c.instanceID, time.Minute).Err(); err != nil {
+		return "", fmt.Errorf("写入临时键失败: %w", err)
+	}
+	value, err := client.Get(ctx, key).Result()
+	if err != nil {
+		return "", fmt.Errorf("读取临时键失败: %w", err)
+	}
+	if value != c.instanceID {
+		return "", fmt.Errorf("读取的值不一致: %q", value)
+	}
+	if err := client.Del(ctx, key).Err(); err != nil {
+		return "", fmt.Errorf("删除临时键失败: %w", err)
+	}
+
+	return fmt.Sprintf("%s/%d", c.cfg.GetRedisAddr(), c.cfg.Redis.Database), nil
+}
+
+// checkRedisLock 使用定时任务的分布式锁实现获取并释放锁，同时确认锁已被持有时无法再次获取
+func (c *Checker) checkRedisLock(ctx context.Context) (string, error) {
+	redisService, err := c.connectRedis()
+	if err != nil {
+		return "", err
+	}
+
+	lock := cron.NewDistributedLock(redisService)
+	lockKey := selftestKeyPrefix + c.instanceID
+	acquired, err := lock.TryAcquireLock(ctx, lockKey, c.instanceID, selftestLockTTL)
+	if err != nil {
+		return "", err
+	}
+	if !acquired {
+		return "", fmt.Errorf("获取锁失败: 锁已被持有")
+	}
+
+	again, err := lock.TryAcquireLock(ctx, lockKey, c.instanceID+"-other", selftestLockTTL)
+	if err != nil {
+		lock.ReleaseLock(context.Background(), lockKey, c.instanceID)
+		return "", err
+	}
+	if again {
+		lock.ReleaseLock(context.Background(), lockKey, c.instanceID+"-other")
+		return "", fmt.Errorf("锁未生效: 其他实例可以重复获取")
+	}
+
+	if err := lock.ReleaseLock(ctx, lockKey, c.instanceID); err != nil {
+		return "", err
+	}
+	return "acquire/release ok", nil
+}
+
+// checkNotification 向通知渠道发送自检通知
+// 当前只有日志通知器，通知写入应用日志而不会投递给用户
+func (c *Checker) checkNotification(ctx context.Context) (string, error) {
+	var notifier notification.Notifier = notification.NewLogNotifier()
+	err := notifier.Notify(ctx, &notification.Notification{
+		Event: "selftest",
+		Data:  map[string]interface{}{"instance_id": c.instanceID},
+	})
+	if err != nil {
+		return "", err
+	}
+	return "log notifier（未接入邮件渠道）", nil
+}
+
+// checkSigning 从密钥提供者加载签名密钥，对测试请求签名后验证
+func (c *Checker) checkSigning(ctx context.Context) (string, error) {
+	authCfg := c.cfg.ServiceAuth
+	if !authCfg.Enabled {
+		return "", Skip("service_auth未启用")
+	}
+	if authCfg.ServiceName == "" {
+		return "", fmt.Errorf("service_auth.service_name未配置")
+	}
+
+	provider, err := secrets.NewProvider(c.cfg.Secrets)
+	if err != nil {
+		return "", fmt.Errorf("密钥提供者初始化失败: %w", err)
+	}
+	keyring := signing.NewKeyring(provider, time.Duration(authCfg.KeyCacheTTL)*time.Second)
+	key, err := keyring.SigningKey(ctx, authCfg.ServiceName)
+	if err != nil {
+		return "", fmt.Errorf("加载签名密钥失败: %w", err)
+	}
+
+	body := `{"selftest":true}`
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://selftest/internal/selftest?probe=1", strings.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	if err := signing.SignRequest(req, authCfg.ServiceName, key, now); err != nil {
+		return "", fmt.Errorf("签名失败: %w", err)
+	}
+
+	sig, err := signing.ParseHeaders(req.Header)
+	if err != nil {
+		return "", fmt.Errorf("解析签名头失败: %w", err)
+	}
+	window := time.Duration(authCfg.ReplayWindow) * time.Second
+	if err := keyring.Verify(ctx, sig, req.Method, req.URL.RequestURI(), []byte(body), window, now); err != nil {
+		return "", fmt.Errorf("验证签名失败: %w", err)
+	}
+
+	return fmt.Sprintf("service %s, secret %s", authCfg.ServiceName, signing.SecretName(authCfg.ServiceName)), nil
+}
+
+// checkStorage 对象存储检查，当前版本未集成对象存储
+func (c *Checker) checkStorage(ctx context.Context) (string, error) {
+	return "", Skip("未集成对象存储")
+}
@@ -0,0 +1,119 @@
+// Package selftest 外部依赖自检
+// 逐项验证已配置的外部依赖（数据库读写、Redis锁、通知、签名等），输出通过/失败报告，
+// 供部署流水线和值班人员在发布后确认环境可用
+package selftest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Status 检查结果状态
+type Status string
+
+const (
+	StatusPass Status = "PASS"
+	StatusFail Status = "FAIL"
+	StatusSkip Status = "SKIP"
+)
+
+// Check 单项检查，Run返回的detail为成功时的说明
+type Check struct {
+	Name        string
+	Description string
+	Run         func(ctx context.Context) (detail string, err error)
+}
+
+// Result 单项检查结果
+type Result struct {
+	Name     string        `json:"name"`
+	Status   Status        `json:"status"`
+	Duration time.Duration `json:"duration"`
+	Detail   string        `json:"detail,omitempty"`
+}
+
+// skipError 跳过检查的原因
+type skipError struct {
+	reason string
+}
+
+func (e *skipError) Error() string {
+	return e.reason
+}
+
+// Skip 返回跳过检查的错误（依赖未配置或未启用时使用）
+func Skip(reason string) error {
+	return &skipError{reason: reason}
+}
+
+// Run 依次执行检查，每项检查使用独立的超时时间
+func Run(ctx context.Context, checks []Check, timeout time.Duration) []Result {
+	results := make([]Result, 0, len(checks))
+	for _, check := range checks {
+		results = append(results, runCheck(ctx, check, timeout))
+	}
+	return results
+}
+
+// runCheck 执行单项检查，检查中的panic按失败处理
+func runCheck(ctx context.Context, check Check, timeout time.Duration) (result Result) {
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result.Name = check.Name
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			result.Status = StatusFail
+			result.Detail = fmt.Sprintf("panic: %v", r)
+		}
+		result.Duration = time.Since(start)
+	}()
+
+	detail, err := check.Run(checkCtx)
+	var skip *skipError
+	switch {
+	case errors.As(err, &skip):
+		result.Status = StatusSkip
+		result.Detail = skip.reason
+	case err != nil:
+		result.Status = StatusFail
+		result.Detail = err.Error()
+	default:
+		result.Status = StatusPass
+		result.Detail = detail
+	}
+	return result
+}
+
+// Failed 是否有检查失败（跳过不算失败）
+func Failed(results []Result) bool {
+	for _, result := range results {
+		if result.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintReport 打印检查报告
+func PrintReport(w io.Writer, results []Result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "检查项\t结果\t耗时\t说明")
+
+	counts := make(map[Status]int)
+	for _, result := range results {
+		counts[result.Status]++
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
+			result.Name, result.Status, result.Duration.Round(time.Millisecond), strings.ReplaceAll(result.Detail, "\n", " "))
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n共 %d 项：通过 %d，失败 %d，跳过 %d\n",
+		len(results), counts[StatusPass], counts[StatusFail], counts[StatusSkip])
+}
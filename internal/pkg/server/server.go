@@ -9,8 +9,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"exchange/internal/middleware"
 	"exchange/internal/pkg/config"
 	"exchange/internal/pkg/logger"
+	"exchange/internal/utils"
 )
 
 // GinServer Gin服务器
@@ -25,8 +27,13 @@ func NewGinServer(cfg *config.Config) *GinServer {
 	// 设置Gin模式
 	gin.SetMode(cfg.Server.Mode)
 
-	// 创建Gin引擎
+	// 创建Gin引擎，校验错误使用请求中的字段名
 	engine := gin.New()
+	utils.RegisterValidationFieldNames()
+
+	// 响应压缩和请求体大小限制（在各模块的中间件之前执行，压缩所有响应，包括错误响应）
+	engine.Use(middleware.CompressionMiddleware(cfg.Server.Compression))
+	engine.Use(middleware.BodyLimitMiddleware(cfg.Server.BodyLimit))
 
 	server := &GinServer{
 		config: cfg,
@@ -44,34 +51,48 @@ func (s *GinServer) SetupRoutes(setupFunc func(*gin.Engine)) {
 
 // Start 启动服务器
 func (s *GinServer) Start() error {
+	serverCfg := s.config.Server
+
 	// 创建HTTP服务器
 	s.httpServer = &http.Server{
-		Addr:         fmt.Sprintf(":%d", s.config.Server.Port),
-		Handler:      s.engine,
-		ReadTimeout:  time.Duration(s.config.Server.ReadTimeout) * time.Second,
-		WriteTimeout: time.Duration(s.config.Server.WriteTimeout) * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Addr:              fmt.Sprintf(":%d", serverCfg.Port),
+		Handler:           s.engine,
+		ReadTimeout:       time.Duration(serverCfg.ReadTimeout) * time.Second,
+		ReadHeaderTimeout: time.Duration(serverCfg.ReadHeaderTimeout) * time.Second,
+		WriteTimeout:      time.Duration(serverCfg.WriteTimeout) * time.Second,
+		IdleTimeout:       time.Duration(serverCfg.IdleTimeout) * time.Second,
+		MaxHeaderBytes:    serverCfg.MaxHeaderBytes,
+	}
+
+	// 未加密的HTTP/2（h2c），同时保留HTTP/1.1
+	if serverCfg.H2C {
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(true)
+		protocols.SetUnencryptedHTTP2(true)
+		s.httpServer.Protocols = protocols
 	}
 
 	// 启动服务器
 	logger.Info("启动HTTP服务器", map[string]interface{}{
-		"address": fmt.Sprintf(":%d", s.config.Server.Port),
-		"mode":    s.config.Server.Mode,
+		"address":       s.httpServer.Addr,
+		"mode":          serverCfg.Mode,
+		"h2c":           serverCfg.H2C,
+		"tcp_keepalive": serverCfg.TCPKeepAlive.Enabled,
 	})
 
-	// 检查端口是否被占用
-	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	// 监听端口（端口被占用时直接返回错误）
+	listenConfig := newListenConfig(serverCfg.TCPKeepAlive)
+	listener, err := listenConfig.Listen(context.Background(), "tcp", s.httpServer.Addr)
 	if err != nil {
 		logger.Error("端口已被占用", map[string]interface{}{
-			"port":  s.config.Server.Port,
+			"port":  serverCfg.Port,
 			"error": err.Error(),
 		})
-		return fmt.Errorf("端口 %d 已被占用: %w", s.config.Server.Port, err)
+		return fmt.Errorf("端口 %d 已被占用: %w", serverCfg.Port, err)
 	}
-	listener.Close()
 
 	// 启动HTTP服务器
-	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if err := s.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
 		logger.Error("HTTP服务器启动失败", map[string]interface{}{
 			"error": err.Error(),
 			"port":  s.config.Server.Port,
@@ -82,6 +103,21 @@ func (s *GinServer) Start() error {
 	return nil
 }
 
+// newListenConfig 按TCP keepalive配置创建监听配置，未启用时关闭探测（KeepAlive为负数）
+func newListenConfig(cfg config.TCPKeepAliveConfig) net.ListenConfig {
+	if !cfg.Enabled {
+		return net.ListenConfig{KeepAlive: -1}
+	}
+	return net.ListenConfig{
+		KeepAliveConfig: net.KeepAliveConfig{
+			Enable:   true,
+			Idle:     time.Duration(cfg.Idle) * time.Second,
+			Interval: time.Duration(cfg.Interval) * time.Second,
+			Count:    cfg.Count,
+		},
+	}
+}
+
 // Shutdown 优雅关闭服务器
 func (s *GinServer) Shutdown() error {
 	// 创建超时上下文
@@ -1,6 +1,8 @@
 package services
 
 import (
+	"exchange/internal/pkg/breaker"
+	"exchange/internal/pkg/clock"
 	"exchange/internal/pkg/config"
 	"exchange/internal/pkg/database"
 	appLogger "exchange/internal/pkg/logger"
@@ -42,6 +44,14 @@ func (gs *GlobalServices) Init() error {
 	}
 	gs.config = cfg
 
+	// 模拟时钟（仅非生产环境），需在其他服务使用业务时间之前设置
+	if err := clock.Configure(cfg.Clock); err != nil {
+		return err
+	}
+
+	// 依赖服务熔断，需在创建数据库连接之前设置
+	breaker.Configure(cfg.CircuitBreaker)
+
 	// 初始化MySQL连接
 	mysqlService, err := database.NewMySQLService(cfg)
 	if err != nil {
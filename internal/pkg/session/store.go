@@ -0,0 +1,465 @@
+// Package session 管理用户登录会话和刷新token
+// 每次登录创建一个会话，会话内的刷新token每次使用后轮换为新token（同一会话的刷新token构成一个家族）；
+// 已轮换的旧token再次出现说明可能被窃取，此时撤销整个会话，该会话的所有刷新token和访问token随之失效。
+// 会话记录登录设备（客户端IP、User-Agent）和最近使用时间，用户可以查看自己的会话并单独撤销
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/database"
+)
+
+const (
+	sessionKeyPrefix = "session:"         // 会话，值为JSON
+	refreshKeyPrefix = "session:refresh:" // 刷新token的哈希，值为会话ID，轮换后为"used:"+会话ID
+	userKeyPrefix    = "session:user:"    // 用户的会话ID集合，已过期的会话在查询时清理
+	onlineKey        = "session:online"   // 在线用户（有序集合，成员为用户ID，分数为会话最近使用时间），超过会话有效期的成员在统计时清理
+	loginsKeyPrefix  = "session:logins:"  // 按UTC日期统计的登录次数（创建会话的次数）
+	usedMarker       = "used:"
+
+	refreshTokenBytes = 32
+
+	maxUserAgentLength = 512         // 保存的User-Agent最大长度
+	touchInterval      = time.Minute // 访问时更新最近使用时间的最小间隔，避免每个请求都写Redis
+
+	loginStatsDays = 90 // 按日登录次数的保留天数
+)
+
+var (
+	// ErrInvalidRefreshToken 刷新token不存在或已过期
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused 已轮换的刷新token被再次使用，会话已撤销
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+	// ErrSessionRevoked 会话已撤销或已过期
+	ErrSessionRevoked = errors.New("session revoked")
+)
+
+// rotateScript 标记刷新token为已使用（保留剩余有效期，用于检测重复使用），返回{状态, 会话ID}
+// 状态：0不存在，1有效（本次轮换），2已轮换过
+var rotateScript = redis.NewScript(`
+local sid = redis.call('GET', KEYS[1])
+if not sid then
+	return {0, ''}
+end
+if string.sub(sid, 1, 5) == ARGV[1] then
+	return {2, string.sub(sid, 6)}
+end
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl > 0 then
+	redis.call('SET', KEYS[1], ARGV[1] .. sid, 'PX', ttl)
+else
+	redis.call('SET', KEYS[1], ARGV[1] .. sid)
+end
+return {1, sid}
+`)
+
+// Device 登录设备信息，登录和刷新token时记录
+type Device struct {
+	IP        string
+	UserAgent string
+}
+
+// Session 登录会话
+type Session struct {
+	ID          string    `json:"id"`
+	UserID      uint      `json:"user_id"`
+	Role        string    `json:"role"`
+	IP          string    `json:"ip"`         // 最近一次登录或刷新token的客户端IP
+	UserAgent   string    `json:"user_agent"` // 最近一次登录或刷新token的User-Agent
+	CreatedAt   time.Time `json:"created_at"`
+	RefreshedAt time.Time `json:"refreshed_at"` // 最近一次轮换刷新token的时间
+	LastSeenAt  time.Time `json:"last_seen_at"` // 最近一次使用时间（精确到touchInterval）
+}
+
+// Store 会话存储，保存在Redis中，多实例共享
+// 会话和刷新token的有效期为ttl，每次轮换后重新计算（滑动过期）
+type Store struct {
+	redis *database.RedisService
+	ttl   time.Duration
+}
+
+// NewStore 创建会话存储
+func NewStore(redis *database.RedisService, ttl time.Duration) *Store {
+	return &Store{
+		redis: redis,
+		ttl:   ttl,
+	}
+}
+
+// Create 创建会话，返回会话和第一个刷新token
+func (s *Store) Create(ctx context.Context, userID uint, role string, device Device) (*Session, string, error) {
+	id, err := randomHex(16)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := clock.Now()
+	session := &Session{
+		ID:          id,
+		UserID:      userID,
+		Role:        role,
+		CreatedAt:   now,
+		RefreshedAt: now,
+		LastSeenAt:  now,
+	}
+	session.setDevice(device)
+	if err := s.save(ctx, session); err != nil {
+		return nil, "", err
+	}
+	if err := s.index(ctx, session); err != nil {
+		return nil, "", err
+	}
+	if err := s.countLogin(ctx, now); err != nil {
+		return nil, "", err
+	}
+
+	refreshToken, err := s.issue(ctx, session.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	return session, refreshToken, nil
+}
+
+// Rotate 使用刷新token换取新的刷新token，旧token随即失效，会话的设备信息更新为device
+// 旧token已被使用过时撤销整个会话并返回ErrRefreshTokenReused
+func (s *Store) Rotate(ctx context.Context, refreshToken string, device Device) (*Session, string, error) {
+	if refreshToken == "" {
+		return nil, "", ErrInvalidRefreshToken
+	}
+
+	result, err := rotateScript.Run(ctx, s.redis.Client(), []string{refreshKey(refreshToken)}, usedMarker).Slice()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	state, _ := result[0].(int64)
+	sessionID, _ := result[1].(string)
+
+	switch state {
+	case 1:
+	case 2:
+		if err := s.Revoke(ctx, sessionID); err != nil {
+			return nil, "", err
+		}
+		return nil, "", ErrRefreshTokenReused
+	default:
+		return nil, "", ErrInvalidRefreshToken
+	}
+
+	session, err := s.Get(ctx, sessionID)
+	if err != nil {
+		return nil, "", err
+	}
+	session.RefreshedAt = clock.Now()
+	session.LastSeenAt = session.RefreshedAt
+	session.setDevice(device)
+	if err := s.save(ctx, session); err != nil {
+		return nil, "", err
+	}
+	if err := s.index(ctx, session); err != nil {
+		return nil, "", err
+	}
+
+	next, err := s.issue(ctx, session.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	return session, next, nil
+}
+
+// Get 获取会话，会话已撤销或已过期时返回ErrSessionRevoked
+func (s *Store) Get(ctx context.Context, sessionID string) (*Session, error) {
+	data, err := s.redis.Client().Get(ctx, sessionKeyPrefix+sessionID).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrSessionRevoked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	return &session, nil
+}
+
+// Touch 更新会话的最近使用时间，距上次更新不足touchInterval时不写Redis，不延长会话有效期
+func (s *Store) Touch(ctx context.Context, session *Session) error {
+	now := clock.Now()
+	if now.Sub(session.LastSeenAt) < touchInterval {
+		return nil
+	}
+	session.LastSeenAt = now
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	// XX：会话已撤销时不重新写入
+	if err := s.redis.Client().SetArgs(ctx, sessionKeyPrefix+session.ID, data, redis.SetArgs{Mode: "XX", KeepTTL: true}).Err(); err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+	return s.markOnline(ctx, session)
+}
+
+// List 用户的所有有效会话，按最近使用时间倒序；已撤销或已过期的会话从用户的会话集合中清理
+func (s *Store) List(ctx context.Context, userID uint) ([]*Session, error) {
+	client := s.redis.Client()
+	userKey := userSessionsKey(userID)
+
+	ids, err := client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	if len(ids) == 0 {
+		return []*Session{}, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = sessionKeyPrefix + id
+	}
+	values, err := client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	sessions := make([]*Session, 0, len(ids))
+	var stale []interface{}
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			stale = append(stale, ids[i])
+			continue
+		}
+		var session Session
+		if err := json.Unmarshal([]byte(data), &session); err != nil {
+			return nil, fmt.Errorf("failed to decode session: %w", err)
+		}
+		sessions = append(sessions, &session)
+	}
+	if len(stale) > 0 {
+		if err := client.SRem(ctx, userKey, stale...).Err(); err != nil {
+			return nil, fmt.Errorf("failed to prune sessions: %w", err)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+// Revoke 撤销会话，该会话的刷新token无法再轮换，携带该会话ID的访问token校验失败
+func (s *Store) Revoke(ctx context.Context, sessionID string) error {
+	if err := s.redis.Client().Del(ctx, sessionKeyPrefix+sessionID).Err(); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// RevokeUserSession 撤销用户的一个会话，会话不属于该用户时返回ErrSessionRevoked
+func (s *Store) RevokeUserSession(ctx context.Context, userID uint, sessionID string) error {
+	session, err := s.Get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return ErrSessionRevoked
+	}
+	if err := s.Revoke(ctx, sessionID); err != nil {
+		return err
+	}
+	if err := s.redis.Client().SRem(ctx, userSessionsKey(userID), sessionID).Err(); err != nil {
+		return fmt.Errorf("failed to unindex session: %w", err)
+	}
+	return nil
+}
+
+// RevokeAll 撤销用户除exceptID外的所有会话（exceptID为空时撤销全部），返回撤销的会话数
+func (s *Store) RevokeAll(ctx context.Context, userID uint, exceptID string) (int, error) {
+	client := s.redis.Client()
+	userKey := userSessionsKey(userID)
+
+	ids, err := client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	var keys []string
+	var members []interface{}
+	for _, id := range ids {
+		if id == exceptID {
+			continue
+		}
+		keys = append(keys, sessionKeyPrefix+id)
+		members = append(members, id)
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	revoked, err := client.Del(ctx, keys...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := client.SRem(ctx, userKey, members...).Err(); err != nil {
+		return 0, fmt.Errorf("failed to unindex sessions: %w", err)
+	}
+	return int(revoked), nil
+}
+
+// CountOnline 统计最近window内有活动的用户数（按会话最近使用时间，精确到touchInterval），同时清理超过会话有效期的记录
+func (s *Store) CountOnline(ctx context.Context, window time.Duration) (int64, error) {
+	client := s.redis.Client()
+	now := clock.Now()
+
+	if err := client.ZRemRangeByScore(ctx, onlineKey, "-inf", "("+strconv.FormatInt(now.Add(-s.ttl).Unix(), 10)).Err(); err != nil {
+		return 0, fmt.Errorf("failed to prune online users: %w", err)
+	}
+	count, err := client.ZCount(ctx, onlineKey, strconv.FormatInt(now.Add(-window).Unix(), 10), "+inf").Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count online users: %w", err)
+	}
+	return count, nil
+}
+
+// LoginsPerDay 最近days天（含当天，UTC）每天的登录次数，返回日期(2006-01-02)到次数，最多保留loginStatsDays天
+func (s *Store) LoginsPerDay(ctx context.Context, days int) (map[string]int64, error) {
+	if days <= 0 {
+		return map[string]int64{}, nil
+	}
+	today := clock.Now().UTC()
+	pipe := s.redis.Client().Pipeline()
+	cmds := make(map[string]*redis.StringCmd, days)
+	for i := 0; i < days; i++ {
+		day := today.AddDate(0, 0, -i)
+		cmds[day.Format("2006-01-02")] = pipe.Get(ctx, loginsKey(day))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to get login counts: %w", err)
+	}
+
+	counts := make(map[string]int64, days)
+	for day, cmd := range cmds {
+		count, err := cmd.Int64()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get login counts: %w", err)
+		}
+		counts[day] = count
+	}
+	return counts, nil
+}
+
+// TTL 会话和刷新token的有效期
+func (s *Store) TTL() time.Duration {
+	return s.ttl
+}
+
+// save 保存会话并重新计算有效期
+func (s *Store) save(ctx context.Context, session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if err := s.redis.Client().Set(ctx, sessionKeyPrefix+session.ID, data, s.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return s.markOnline(ctx, session)
+}
+
+// markOnline 记录用户的最近活动时间
+func (s *Store) markOnline(ctx context.Context, session *Session) error {
+	member := strconv.FormatUint(uint64(session.UserID), 10)
+	if err := s.redis.Client().ZAdd(ctx, onlineKey, redis.Z{Score: float64(session.LastSeenAt.Unix()), Member: member}).Err(); err != nil {
+		return fmt.Errorf("failed to mark user online: %w", err)
+	}
+	return nil
+}
+
+// countLogin 累加当天的登录次数
+func (s *Store) countLogin(ctx context.Context, now time.Time) error {
+	key := loginsKey(now)
+	pipe := s.redis.Client().TxPipeline()
+	pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, loginStatsDays*24*time.Hour)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to count login: %w", err)
+	}
+	return nil
+}
+
+// index 将会话加入用户的会话集合，集合的有效期与最近使用的会话一致
+func (s *Store) index(ctx context.Context, session *Session) error {
+	key := userSessionsKey(session.UserID)
+	pipe := s.redis.Client().TxPipeline()
+	pipe.SAdd(ctx, key, session.ID)
+	pipe.Expire(ctx, key, s.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to index session: %w", err)
+	}
+	return nil
+}
+
+// setDevice 记录登录设备信息
+func (session *Session) setDevice(device Device) {
+	session.IP = device.IP
+	session.UserAgent = device.UserAgent
+	if len(session.UserAgent) > maxUserAgentLength {
+		session.UserAgent = session.UserAgent[:maxUserAgentLength]
+	}
+}
+
+// issue 为会话签发新的刷新token，Redis中只保存token的哈希
+func (s *Store) issue(ctx context.Context, sessionID string) (string, error) {
+	token, err := randomHex(refreshTokenBytes)
+	if err != nil {
+		return "", err
+	}
+	if err := s.redis.Client().Set(ctx, refreshKey(token), sessionID, s.ttl).Err(); err != nil {
+		return "", fmt.Errorf("failed to save refresh token: %w", err)
+	}
+	return token, nil
+}
+
+// refreshKey 刷新token的Redis键
+func refreshKey(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return refreshKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+// userSessionsKey 用户的会话ID集合的Redis键
+func userSessionsKey(userID uint) string {
+	return fmt.Sprintf("%s%d", userKeyPrefix, userID)
+}
+
+// loginsKey 某天（UTC）登录次数的Redis键
+func loginsKey(t time.Time) string {
+	return loginsKeyPrefix + t.UTC().Format("2006-01-02")
+}
+
+// randomHex 生成n字节的随机数，以十六进制表示
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
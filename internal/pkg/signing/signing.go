@@ -0,0 +1,309 @@
+package signing
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"exchange/internal/pkg/secrets"
+)
+
+// 签名请求头
+const (
+	HeaderService   = "X-Service-Name"        // 调用方服务名
+	HeaderTimestamp = "X-Signature-Timestamp" // 签名时间（Unix秒）
+	HeaderNonce     = "X-Signature-Nonce"     // 随机数，同一服务在时间窗口内不可重复
+	HeaderSignature = "X-Signature"           // HMAC-SHA256签名（十六进制）
+)
+
+// API key签名请求头（程序化交易客户端），签名方式与内部服务相同
+const (
+	HeaderAPIKey          = "X-API-Key"       // API key ID
+	HeaderAPITimestamp    = "X-API-Timestamp" // 签名时间（Unix秒）
+	HeaderAPINonce        = "X-API-Nonce"     // 随机数，同一key在时间窗口内不可重复
+	HeaderAPIKeySignature = "X-API-Signature" // HMAC-SHA256签名（十六进制）
+)
+
+// 验证错误
+var (
+	ErrMissingSignature = errors.New("missing signature headers")
+	ErrUnknownService   = errors.New("unknown service")
+	ErrUnknownAPIKey    = errors.New("unknown api key")
+	ErrExpired          = errors.New("signature timestamp outside replay window")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// headerSet 一组签名请求头
+type headerSet struct {
+	id, timestamp, nonce, signature string
+}
+
+var (
+	serviceHeaders = headerSet{HeaderService, HeaderTimestamp, HeaderNonce, HeaderSignature}
+	apiKeyHeaders  = headerSet{HeaderAPIKey, HeaderAPITimestamp, HeaderAPINonce, HeaderAPIKeySignature}
+)
+
+// SecretName 服务共享密钥在密钥提供者中的名称
+func SecretName(service string) string {
+	return "service_secret_" + service
+}
+
+// APIKeySecretName API key签名密钥在密钥提供者中的名称
+func APIKeySecretName(keyID string) string {
+	return "api_secret_" + keyID
+}
+
+// StringToSign 待签名字符串
+// 格式：METHOD\nPATH?QUERY\nTIMESTAMP\nNONCE\nhex(sha256(body))
+func StringToSign(method, uri string, timestamp int64, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		uri,
+		strconv.FormatInt(timestamp, 10),
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+// Compute 计算签名
+func Compute(key, stringToSign string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(stringToSign))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest 为请求添加签名头，读取后会恢复请求体
+func SignRequest(req *http.Request, service, key string, now time.Time) error {
+	return signRequest(req, serviceHeaders, service, key, now)
+}
+
+// SignAPIRequest 以API key为请求添加签名头，供程序化交易客户端使用
+func SignAPIRequest(req *http.Request, keyID, secret string, now time.Time) error {
+	return signRequest(req, apiKeyHeaders, keyID, secret, now)
+}
+
+// signRequest 按指定的请求头添加签名
+func signRequest(req *http.Request, headers headerSet, id, key string, now time.Time) error {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body.Close()
+		body = data
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	nonce, err := newNonce()
+	if err != nil {
+		return err
+	}
+	timestamp := now.Unix()
+
+	req.Header.Set(headers.id, id)
+	req.Header.Set(headers.timestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(headers.nonce, nonce)
+	req.Header.Set(headers.signature, Compute(key, StringToSign(req.Method, req.URL.RequestURI(), timestamp, nonce, body)))
+	return nil
+}
+
+// Signature 从请求头解析出的签名信息
+type Signature struct {
+	Service   string // 调用方服务名，API key签名时为key ID
+	Timestamp int64
+	Nonce     string
+	Value     string
+}
+
+// ParseHeaders 解析签名请求头
+func ParseHeaders(header http.Header) (*Signature, error) {
+	return parseHeaders(header, serviceHeaders)
+}
+
+// ParseAPIKeyHeaders 解析API key签名请求头
+func ParseAPIKeyHeaders(header http.Header) (*Signature, error) {
+	return parseHeaders(header, apiKeyHeaders)
+}
+
+// parseHeaders 按指定的请求头解析签名
+func parseHeaders(header http.Header, headers headerSet) (*Signature, error) {
+	sig := &Signature{
+		Service: header.Get(headers.id),
+		Nonce:   header.Get(headers.nonce),
+		Value:   header.Get(headers.signature),
+	}
+	rawTimestamp := header.Get(headers.timestamp)
+	if sig.Service == "" || sig.Nonce == "" || sig.Value == "" || rawTimestamp == "" {
+		return nil, ErrMissingSignature
+	}
+	if len(sig.Nonce) < 16 || len(sig.Nonce) > 64 {
+		return nil, fmt.Errorf("%w: nonce length must be between 16 and 64", ErrInvalidSignature)
+	}
+
+	timestamp, err := strconv.ParseInt(rawTimestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid timestamp", ErrInvalidSignature)
+	}
+	sig.Timestamp = timestamp
+	return sig, nil
+}
+
+// Keyring 服务共享密钥环
+// 从密钥提供者加载并缓存各服务的密钥，缓存过期后重新加载以获取轮换后的密钥
+// 签名使用当前版本，验证时接受所有版本，轮换过渡期内新旧密钥签名的请求都能通过
+type Keyring struct {
+	provider   secrets.Provider
+	ttl        time.Duration
+	secretName func(id string) string // 密钥在密钥提供者中的名称
+	unknown    error                  // 密钥不存在时返回的错误
+
+	mu    sync.Mutex
+	cache map[string]cachedKeys
+}
+
+// cachedKeys 缓存的密钥
+type cachedKeys struct {
+	keys      []string
+	expiresAt time.Time
+}
+
+// NewKeyring 创建内部服务密钥环
+func NewKeyring(provider secrets.Provider, ttl time.Duration) *Keyring {
+	return &Keyring{
+		provider:   provider,
+		ttl:        ttl,
+		secretName: SecretName,
+		unknown:    ErrUnknownService,
+		cache:      make(map[string]cachedKeys),
+	}
+}
+
+// NewAPIKeyring 创建API key密钥环，密钥名为api_secret_<key ID>
+func NewAPIKeyring(provider secrets.Provider, ttl time.Duration) *Keyring {
+	return &Keyring{
+		provider:   provider,
+		ttl:        ttl,
+		secretName: APIKeySecretName,
+		unknown:    ErrUnknownAPIKey,
+		cache:      make(map[string]cachedKeys),
+	}
+}
+
+// Keys 获取服务的所有有效密钥（第一个为当前版本）
+func (k *Keyring) Keys(ctx context.Context, service string) ([]string, error) {
+	k.mu.Lock()
+	cached, ok := k.cache[service]
+	k.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.keys, nil
+	}
+
+	value, err := k.provider.Get(ctx, k.secretName(service))
+	if err != nil {
+		if errors.Is(err, secrets.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", k.unknown, service)
+		}
+		return nil, err
+	}
+	keys := secrets.Versions(value)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: %s", k.unknown, service)
+	}
+
+	k.mu.Lock()
+	k.cache[service] = cachedKeys{keys: keys, expiresAt: time.Now().Add(k.ttl)}
+	k.mu.Unlock()
+
+	return keys, nil
+}
+
+// SigningKey 获取服务当前版本的签名密钥
+func (k *Keyring) SigningKey(ctx context.Context, service string) (string, error) {
+	keys, err := k.Keys(ctx, service)
+	if err != nil {
+		return "", err
+	}
+	return keys[0], nil
+}
+
+// Verify 验证签名，window为时间戳允许的偏差
+// 不检查nonce是否重复，由调用方结合共享存储处理
+func (k *Keyring) Verify(ctx context.Context, sig *Signature, method, uri string, body []byte, window time.Duration, now time.Time) error {
+	signedAt := time.Unix(sig.Timestamp, 0)
+	if signedAt.Before(now.Add(-window)) || signedAt.After(now.Add(window)) {
+		return ErrExpired
+	}
+
+	keys, err := k.Keys(ctx, sig.Service)
+	if err != nil {
+		return err
+	}
+
+	stringToSign := StringToSign(method, uri, sig.Timestamp, sig.Nonce, body)
+	for _, key := range keys {
+		if hmac.Equal([]byte(Compute(key, stringToSign)), []byte(strings.ToLower(sig.Value))) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// Transport 为请求自动签名的http.RoundTripper，供内部服务调用方使用
+type Transport struct {
+	Base    http.RoundTripper // 为nil时使用http.DefaultTransport
+	Service string            // 调用方服务名
+	Keyring *Keyring
+}
+
+// RoundTrip 签名后发送请求
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := t.Keyring.SigningKey(req.Context(), t.Service)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signing key: %w", err)
+	}
+
+	// RoundTripper不能修改原请求
+	signed := req.Clone(req.Context())
+	if err := SignRequest(signed, t.Service, key, time.Now()); err != nil {
+		return nil, err
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(signed)
+}
+
+// NewClient 创建自动签名的HTTP客户端
+func NewClient(service string, keyring *Keyring, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &Transport{Service: service, Keyring: keyring},
+	}
+}
+
+// newNonce 生成随机数
+func newNonce() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
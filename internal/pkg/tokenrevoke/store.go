@@ -0,0 +1,114 @@
+// Package tokenrevoke 按JTI撤销JWT
+// 签发的每个token带有唯一的jti，并登记到所属主体（用户或管理员）的有效token集合中；
+// 撤销单个token时记录其jti直到token过期，撤销主体的全部token时按登记集合逐个记录，不需要保存token原文
+package tokenrevoke
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/database"
+)
+
+const (
+	revokedKeyPrefix   = "token:revoked:" // 已撤销的jti，保留到token过期
+	principalKeyPrefix = "token:active:"  // 主体的有效token集合（有序集合，成员为jti，分数为过期时间），过期的成员在登记时清理
+
+	jtiBytes = 16
+)
+
+// ErrRevoked token已撤销
+var ErrRevoked = errors.New("token revoked")
+
+// Store token撤销记录，保存在Redis中，多实例共享
+type Store struct {
+	redis *database.RedisService
+	ttl   time.Duration // 最长token有效期，主体的有效token集合按此时间过期
+}
+
+// NewStore 创建token撤销记录，ttl为签发的token中最长的有效期
+func NewStore(redis *database.RedisService, ttl time.Duration) *Store {
+	return &Store{
+		redis: redis,
+		ttl:   ttl,
+	}
+}
+
+// NewJTI 生成token的唯一ID
+func NewJTI() (string, error) {
+	buf := make([]byte, jtiBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate jti: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Register 登记主体新签发的token，同时清理集合中已过期的token
+func (s *Store) Register(ctx context.Context, principal, jti string, expiresAt time.Time) error {
+	key := principalKeyPrefix + principal
+	pipe := s.redis.Client().TxPipeline()
+	pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(clock.Now().Unix(), 10))
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(expiresAt.Unix()), Member: jti})
+	pipe.Expire(ctx, key, s.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to register token: %w", err)
+	}
+	return nil
+}
+
+// Revoke 撤销单个token，已过期的token无需记录
+func (s *Store) Revoke(ctx context.Context, principal, jti string, expiresAt time.Time) error {
+	pipe := s.redis.Client().TxPipeline()
+	if remaining := clock.Until(expiresAt); remaining > 0 {
+		pipe.Set(ctx, revokedKeyPrefix+jti, principal, remaining)
+	}
+	pipe.ZRem(ctx, principalKeyPrefix+principal, jti)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+// RevokeAll 撤销主体登记的全部有效token，返回撤销的token数
+func (s *Store) RevokeAll(ctx context.Context, principal string) (int, error) {
+	client := s.redis.Client()
+	key := principalKeyPrefix + principal
+	now := clock.Now()
+
+	tokens, err := client.ZRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(now.Unix(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list tokens: %w", err)
+	}
+
+	pipe := client.TxPipeline()
+	for _, token := range tokens {
+		jti, _ := token.Member.(string)
+		expiresAt := time.Unix(int64(token.Score), 0)
+		pipe.Set(ctx, revokedKeyPrefix+jti, principal, expiresAt.Sub(now))
+	}
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+	return len(tokens), nil
+}
+
+// IsRevoked token是否已撤销
+func (s *Store) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	exists, err := s.redis.Client().Exists(ctx, revokedKeyPrefix+jti).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	return exists > 0, nil
+}
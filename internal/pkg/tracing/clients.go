@@ -0,0 +1,204 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// gormSpanKey GORM语句实例中保存span的键
+const gormSpanKey = "tracing:span"
+
+// attrRowsAffected MySQL写操作影响的行数
+const attrRowsAffected = attribute.Key("db.rows_affected")
+
+// gormSpan 执行中的SQL语句的span
+type gormSpan struct {
+	span      trace.Span
+	operation string
+}
+
+// GormPlugin GORM插件：每条SQL一个client span，记录表名和SQL语句（参数为占位符，不含参数值）
+type GormPlugin struct{}
+
+// NewGormPlugin 创建GORM追踪插件
+func NewGormPlugin() *GormPlugin {
+	return &GormPlugin{}
+}
+
+// Name 插件名称
+func (p *GormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize 在增删改查（含默认事务）和Row、Raw的执行前后注册回调
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	err := errors.Join(
+		cb.Create().Before("gorm:begin_transaction").Register("tracing:before_create", p.before("INSERT")),
+		cb.Create().After("gorm:commit_or_rollback_transaction").Register("tracing:after_create", p.after),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", p.before("SELECT")),
+		cb.Query().After("gorm:after_query").Register("tracing:after_query", p.after),
+		cb.Update().Before("gorm:begin_transaction").Register("tracing:before_update", p.before("UPDATE")),
+		cb.Update().After("gorm:commit_or_rollback_transaction").Register("tracing:after_update", p.after),
+		cb.Delete().Before("gorm:begin_transaction").Register("tracing:before_delete", p.before("DELETE")),
+		cb.Delete().After("gorm:commit_or_rollback_transaction").Register("tracing:after_delete", p.after),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", p.before("SELECT")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", p.after),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", p.before("RAW")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", p.after),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register tracing callbacks: %w", err)
+	}
+	return nil
+}
+
+// before 创建span，语句的context替换为span的context
+func (p *GormPlugin) before(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if !Enabled() {
+			return
+		}
+		ctx, span := Tracer().Start(db.Statement.Context, "mysql "+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemNameMySQL, semconv.DBOperationName(operation)),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, &gormSpan{span: span, operation: operation})
+	}
+}
+
+// after 记录表名、SQL语句和执行结果，结束span
+func (p *GormPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	s, ok := value.(*gormSpan)
+	if !ok {
+		return
+	}
+
+	span := s.span
+	if table := db.Statement.Table; table != "" {
+		span.SetName("mysql " + s.operation + " " + table)
+		span.SetAttributes(semconv.DBCollectionName(table))
+	}
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attrRowsAffected.Int64(db.RowsAffected),
+	)
+	recordError(span, db.Error)
+	span.End()
+}
+
+// RedisHook go-redis钩子：每条命令（管道为整个管道）一个client span，只记录命令名，不记录键和参数
+type RedisHook struct{}
+
+// NewRedisHook 创建Redis追踪钩子
+func NewRedisHook() *RedisHook {
+	return &RedisHook{}
+}
+
+// DialHook 建立连接不创建span
+func (h *RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook 执行命令，redis.Nil不记为错误
+func (h *RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !Enabled() {
+			return next(ctx, cmd)
+		}
+		ctx, span := Tracer().Start(ctx, "redis "+cmd.Name(),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemNameRedis, semconv.DBOperationName(cmd.Name())),
+		)
+		defer span.End()
+
+		err := next(ctx, cmd)
+		if !errors.Is(err, redis.Nil) {
+			recordError(span, err)
+		}
+		return err
+	}
+}
+
+// ProcessPipelineHook 执行管道命令
+func (h *RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !Enabled() {
+			return next(ctx, cmds)
+		}
+		ctx, span := Tracer().Start(ctx, "redis pipeline",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemNameRedis,
+				semconv.DBOperationName("pipeline"),
+				semconv.DBOperationBatchSize(len(cmds)),
+			),
+		)
+		defer span.End()
+
+		err := next(ctx, cmds)
+		if !errors.Is(err, redis.Nil) {
+			recordError(span, err)
+		}
+		return err
+	}
+}
+
+// NewMongoCommandMonitor MongoDB命令监视器：每条命令一个client span，记录数据库、集合和命令名，不记录命令内容
+func NewMongoCommandMonitor() *event.CommandMonitor {
+	var spans sync.Map // 请求ID -> span
+	finish := func(requestID int64, failure string) {
+		value, ok := spans.LoadAndDelete(requestID)
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		if failure != "" {
+			recordError(span, errors.New(failure))
+		}
+		span.End()
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			if !Enabled() {
+				return
+			}
+			name := e.CommandName
+			attributes := []attribute.KeyValue{
+				semconv.DBSystemNameMongoDB,
+				semconv.DBOperationName(e.CommandName),
+				semconv.DBNamespace(e.DatabaseName),
+			}
+			if collection, ok := e.Command.Lookup(e.CommandName).StringValueOK(); ok {
+				name += " " + collection
+				attributes = append(attributes, semconv.DBCollectionName(collection))
+			}
+			_, span := Tracer().Start(ctx, "mongodb "+name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attributes...),
+			)
+			spans.Store(e.RequestID, span)
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			finish(e.RequestID, "")
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			finish(e.RequestID, e.Failure)
+		},
+	}
+}
@@ -0,0 +1,160 @@
+// Package tracing 分布式追踪
+// 基于OpenTelemetry为HTTP请求、MySQL/MongoDB/Redis调用和定时任务执行创建span，以OTLP/HTTP导出到Collector。
+// span保存在context中：处理器将c.Request.Context()传给逻辑层和仓储层，数据库调用即成为请求span的子span。
+// 未启用时使用OpenTelemetry的空实现，数据库埋点直接跳过
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"exchange/internal/pkg/config"
+	appErrors "exchange/internal/pkg/errors"
+	appLogger "exchange/internal/pkg/logger"
+)
+
+// instrumentationName 埋点的instrumentation scope
+const instrumentationName = "exchange/internal/pkg/tracing"
+
+// AppError属性
+const (
+	attrErrorCode     = attribute.Key("app.error.code")
+	attrErrorSeverity = attribute.Key("app.error.severity")
+	attrErrorModule   = attribute.Key("app.error.module")
+)
+
+var (
+	enabled  atomic.Bool
+	mu       sync.Mutex
+	provider *sdktrace.TracerProvider
+
+	tracingLogger = appLogger.Module("tracing")
+)
+
+// Enable 按配置启用追踪，serviceName为资源属性service.name（API服务为exchange，定时任务为exchange-cron）
+func Enable(cfg config.TracingConfig, serviceName string) error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(),
+		otlptracehttp.WithEndpointURL(cfg.Endpoint),
+		otlptracehttp.WithHeaders(cfg.Headers),
+		otlptracehttp.WithTimeout(time.Duration(cfg.TimeoutMs)*time.Millisecond),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	attributes := []attribute.KeyValue{
+		semconv.ServiceName(serviceName),
+		semconv.DeploymentEnvironmentName(config.GetEnv()),
+	}
+	for key, value := range cfg.ResourceAttributes {
+		attributes = append(attributes, attribute.String(key, value))
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, attributes...))
+	if err != nil {
+		return fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		tracingLogger.Warn("追踪数据导出失败", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}))
+
+	mu.Lock()
+	provider = tp
+	mu.Unlock()
+	enabled.Store(true)
+
+	tracingLogger.Info("分布式追踪已启用", map[string]interface{}{
+		"service":      serviceName,
+		"endpoint":     cfg.Endpoint,
+		"sample_ratio": cfg.SampleRatio,
+	})
+	return nil
+}
+
+// Shutdown 导出缓冲中的span并关闭追踪，最多等待timeout
+func Shutdown(timeout time.Duration) {
+	mu.Lock()
+	tp := provider
+	provider = nil
+	mu.Unlock()
+	if tp == nil {
+		return
+	}
+
+	enabled.Store(false)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := tp.Shutdown(ctx); err != nil {
+		tracingLogger.Warn("关闭分布式追踪失败", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// Enabled 是否已启用追踪
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Tracer 埋点使用的Tracer
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// RecordError 在ctx中的span上记录错误
+func RecordError(ctx context.Context, err error) {
+	recordError(trace.SpanFromContext(ctx), err)
+}
+
+// recordError 在span上记录错误：错误分类记录为error.type，AppError的错误码、严重级别和模块记录为属性；
+// 客户端错误（不存在、冲突、参数无效、限流）只记录属性，其他错误将span状态设为Error
+func recordError(span trace.Span, err error) {
+	if err == nil || !span.IsRecording() {
+		return
+	}
+
+	category := appErrors.ClassifyDBError(err)
+	span.SetAttributes(semconv.ErrorTypeKey.String(string(category)))
+	if appErr, ok := appErrors.GetAppError(err); ok {
+		span.SetAttributes(
+			attrErrorCode.Int(int(appErr.Code)),
+			attrErrorSeverity.String(string(appErr.GetSeverity())),
+		)
+		if appErr.Module != "" {
+			span.SetAttributes(attrErrorModule.String(appErr.Module))
+		}
+	}
+
+	switch category {
+	case appErrors.CategoryNotFound, appErrors.CategoryConflict, appErrors.CategoryInvalid, appErrors.CategoryRateLimited:
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,64 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+
+	appErrors "exchange/internal/pkg/errors"
+)
+
+// 注销校验未通过的错误带错误码和消息键，响应按请求语言翻译
+var (
+	// ErrStateUnavailable 未接入交易状态数据源，无法确认订单和余额
+	ErrStateUnavailable = appErrors.ErrDeletionStateUnavailable
+	// ErrOpenOrders 存在未完成的订单
+	ErrOpenOrders = appErrors.ErrDeletionOpenOrders
+	// ErrNonZeroBalance 账户仍有余额
+	ErrNonZeroBalance = appErrors.ErrDeletionBalanceRemaining
+)
+
+// StateSource 用户的交易状态查询，由交易模块实现
+type StateSource interface {
+	CountOpenOrders(ctx context.Context, userID uint) (int64, error)
+	HasBalance(ctx context.Context, userID uint) (bool, error)
+}
+
+// DeletionGuard 账户注销前置校验：存在未完成订单或余额时不允许注销
+// 未接入数据源时一律拒绝，避免在无法确认资产状态的情况下匿名化账户
+type DeletionGuard struct {
+	source StateSource
+}
+
+// NewDeletionGuard 创建交易状态注销校验，source为nil时拒绝所有注销
+func NewDeletionGuard(source StateSource) *DeletionGuard {
+	return &DeletionGuard{source: source}
+}
+
+// Name 校验名称
+func (g *DeletionGuard) Name() string {
+	return "trading"
+}
+
+// CheckDeletable 存在未完成订单、余额或无法查询交易状态时返回错误
+func (g *DeletionGuard) CheckDeletable(ctx context.Context, userID uint) error {
+	if g.source == nil {
+		return ErrStateUnavailable
+	}
+
+	orders, err := g.source.CountOpenOrders(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("查询未完成订单失败: %w", err)
+	}
+	if orders > 0 {
+		return ErrOpenOrders
+	}
+
+	hasBalance, err := g.source.HasBalance(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("查询账户余额失败: %w", err)
+	}
+	if hasBalance {
+		return ErrNonZeroBalance
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Package translation 管理后台编辑的翻译
+// 翻译覆盖保存在MongoDB，修改后通过Redis通知所有实例重新加载到i18n管理器，无需重新发布翻译文件
+package translation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongoModel "exchange/internal/models/mongodb"
+	"exchange/internal/pkg/database"
+)
+
+// ErrOverrideNotFound 翻译覆盖不存在
+var ErrOverrideNotFound = errors.New("translation override not found")
+
+// Store 翻译覆盖存储
+type Store struct {
+	mongo *database.MongoDBService
+}
+
+// NewStore 创建翻译覆盖存储
+func NewStore(mongo *database.MongoDBService) *Store {
+	return &Store{mongo: mongo}
+}
+
+// collection 翻译覆盖集合
+func (s *Store) collection() *mongo.Collection {
+	return s.mongo.Collection(mongoModel.TranslationOverride{}.CollectionName())
+}
+
+// EnsureIndexes 创建语言和消息键的唯一索引
+func (s *Store) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "language", Value: 1}, {Key: "key", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create translation override indexes: %w", err)
+	}
+	return nil
+}
+
+// List 获取翻译覆盖，language为空时获取所有语言，按语言和消息键排序
+func (s *Store) List(ctx context.Context, language string) ([]*mongoModel.TranslationOverride, error) {
+	filter := bson.M{}
+	if language != "" {
+		filter["language"] = language
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "language", Value: 1}, {Key: "key", Value: 1}})
+
+	cursor, err := s.collection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query translation overrides: %w", err)
+	}
+	overrides := make([]*mongoModel.TranslationOverride, 0)
+	if err := cursor.All(ctx, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to decode translation overrides: %w", err)
+	}
+	return overrides, nil
+}
+
+// Upsert 保存翻译覆盖，同一语言和消息键已存在时替换消息
+func (s *Store) Upsert(ctx context.Context, override *mongoModel.TranslationOverride) error {
+	override.SetTimestamps()
+	if err := override.Validate(); err != nil {
+		return fmt.Errorf("translation override validation failed: %w", err)
+	}
+
+	filter := bson.M{"language": override.Language, "key": override.Key}
+	update := bson.M{
+		"$set": bson.M{
+			"forms":      override.Forms,
+			"updated_by": override.UpdatedBy,
+			"updated_at": override.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{"created_at": override.CreatedAt},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	if err := s.collection().FindOneAndUpdate(ctx, filter, update, opts).Decode(override); err != nil {
+		return fmt.Errorf("failed to save translation override: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除翻译覆盖
+func (s *Store) Delete(ctx context.Context, language, key string) error {
+	result, err := s.collection().DeleteOne(ctx, bson.M{"language": language, "key": key})
+	if err != nil {
+		return fmt.Errorf("failed to delete translation override: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrOverrideNotFound
+	}
+	return nil
+}
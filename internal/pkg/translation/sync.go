@@ -0,0 +1,158 @@
+package translation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	mongoModel "exchange/internal/models/mongodb"
+	"exchange/internal/pkg/database"
+	"exchange/internal/pkg/i18n"
+	"exchange/internal/pkg/logger"
+)
+
+const (
+	// 翻译覆盖变更通知频道，消息内容为变更的语言和消息键
+	changedChannel = "i18n:overrides:changed"
+
+	// 定期全量同步的间隔，避免因订阅断开错过变更
+	resyncInterval = time.Minute
+)
+
+// Sync 翻译覆盖同步器
+// 管理后台通过Save/Delete修改翻译覆盖并发布通知，各实例调用Start订阅通知并重新加载到i18n管理器
+type Sync struct {
+	store   *Store
+	redis   *database.RedisService
+	manager *i18n.I18nManager
+
+	mu      sync.Mutex
+	applied []i18n.Override // 已应用的翻译覆盖，未变化时不重新加载
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewSync 创建翻译覆盖同步器
+func NewSync(store *Store, redis *database.RedisService, manager *i18n.I18nManager) *Sync {
+	return &Sync{
+		store:   store,
+		redis:   redis,
+		manager: manager,
+	}
+}
+
+// Start 加载已保存的翻译覆盖并订阅变更通知
+func (s *Sync) Start(ctx context.Context) error {
+	if err := s.store.EnsureIndexes(ctx); err != nil {
+		return err
+	}
+	if err := s.reload(ctx); err != nil {
+		return err
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+
+	pubsub := s.redis.Client().Subscribe(ctx, changedChannel)
+	go s.listen(ctx, pubsub)
+
+	return nil
+}
+
+// Stop 停止订阅
+func (s *Sync) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+// Save 保存翻译覆盖并通知所有实例
+func (s *Sync) Save(ctx context.Context, override *mongoModel.TranslationOverride) error {
+	if err := s.store.Upsert(ctx, override); err != nil {
+		return err
+	}
+	return s.publish(ctx, override.Language, override.Key)
+}
+
+// Delete 删除翻译覆盖并通知所有实例，恢复为翻译文件中的消息
+func (s *Sync) Delete(ctx context.Context, language, key string) error {
+	if err := s.store.Delete(ctx, language, key); err != nil {
+		return err
+	}
+	return s.publish(ctx, language, key)
+}
+
+// Overrides 获取翻译覆盖，language为空时获取所有语言
+func (s *Sync) Overrides(ctx context.Context, language string) ([]*mongoModel.TranslationOverride, error) {
+	return s.store.List(ctx, language)
+}
+
+// publish 发布变更通知
+func (s *Sync) publish(ctx context.Context, language, key string) error {
+	if err := s.redis.Client().Publish(ctx, changedChannel, language+":"+key).Err(); err != nil {
+		return fmt.Errorf("failed to publish translation override change: %w", err)
+	}
+	return nil
+}
+
+// listen 处理变更通知，收到通知或定期全量同步时重新加载所有翻译覆盖
+func (s *Sync) listen(ctx context.Context, pubsub *redis.PubSub) {
+	defer close(s.done)
+	defer pubsub.Close()
+
+	ticker := time.NewTicker(resyncInterval)
+	defer ticker.Stop()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			logger.Debug("收到翻译覆盖变更通知", map[string]interface{}{"message": msg.Payload})
+		case <-ticker.C:
+		}
+
+		if err := s.reload(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn("同步翻译覆盖失败", map[string]interface{}{"error": err.Error()})
+		}
+	}
+}
+
+// reload 从MongoDB加载所有翻译覆盖，与已应用的不同时重新加载翻译
+func (s *Sync) reload(ctx context.Context) error {
+	records, err := s.store.List(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	overrides := make([]i18n.Override, len(records))
+	for i, record := range records {
+		overrides[i] = i18n.Override{
+			Language: record.Language,
+			Key:      record.Key,
+			Forms:    record.Forms,
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.applied != nil && reflect.DeepEqual(s.applied, overrides) {
+		return nil
+	}
+	if err := s.manager.SetOverrides(overrides); err != nil {
+		return err
+	}
+	s.applied = overrides
+	return nil
+}
@@ -0,0 +1,439 @@
+// Package twofactor 用户和管理员的两步验证（TOTP）
+// 启用流程：Begin生成密钥和绑定地址（保存在Redis中等待确认），Enable校验验证器应用生成的验证码后保存凭据并返回备用码；
+// 启用后登录需要验证码或备用码，同一验证码和备用码只能使用一次。强制启用的角色未启用时登录签发绑定token，确认绑定后才能登录
+package twofactor
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/database"
+)
+
+const (
+	pendingKeyPrefix = "2fa:pending:" // 未确认的密钥，键为"类型:ID"
+	setupKeyPrefix   = "2fa:setup:"   // 登录时签发的绑定token的哈希，值为"类型:ID"
+
+	setupTokenBytes = 32
+)
+
+var (
+	// ErrCodeRequired 已启用两步验证，登录需要验证码
+	ErrCodeRequired = errors.New("two-factor code required")
+	// ErrInvalidCode 验证码或备用码错误，或已使用过
+	ErrInvalidCode = errors.New("invalid two-factor code")
+	// ErrNotEnabled 未启用两步验证
+	ErrNotEnabled = errors.New("two-factor authentication not enabled")
+	// ErrAlreadyEnabled 已启用两步验证
+	ErrAlreadyEnabled = errors.New("two-factor authentication already enabled")
+	// ErrNoPendingSetup 没有未确认的绑定（未开始或已过期）
+	ErrNoPendingSetup = errors.New("no pending two-factor setup")
+	// ErrInvalidSetupToken 绑定token不存在或已过期
+	ErrInvalidSetupToken = errors.New("invalid two-factor setup token")
+	// ErrEnforced 角色必须启用两步验证，不能关闭
+	ErrEnforced = errors.New("two-factor authentication enforced for role")
+	// ErrSetupRequired 角色必须启用两步验证，登录前需完成绑定
+	ErrSetupRequired = errors.New("two-factor setup required")
+)
+
+// Store 两步验证凭据存储
+type Store interface {
+	GetCredential(ctx context.Context, subjectType string, subjectID uint) (*mysql.TwoFactorCredential, error)
+	Enable(ctx context.Context, credential *mysql.TwoFactorCredential) error
+	Disable(ctx context.Context, subjectType string, subjectID uint) error
+	UseStep(ctx context.Context, id uint, step int64) (bool, error)
+	ReplaceBackupCodes(ctx context.Context, id uint, old, codes string) (bool, error)
+}
+
+// Subject 两步验证的账户
+type Subject struct {
+	Type string // mysql.TwoFactorSubjectUser/mysql.TwoFactorSubjectAdmin
+	ID   uint
+}
+
+// key Redis键中的账户标识
+func (s Subject) key() string {
+	return s.Type + ":" + strconv.FormatUint(uint64(s.ID), 10)
+}
+
+// Enrollment 绑定信息，客户端将URI渲染为二维码，或让用户手动输入密钥
+type Enrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// Status 两步验证状态
+type Status struct {
+	Enabled              bool  `json:"enabled"`
+	Enforced             bool  `json:"enforced"`
+	BackupCodesRemaining int   `json:"backup_codes_remaining"`
+	EnabledAt            int64 `json:"enabled_at,omitempty"`
+}
+
+// SetupRequiredError 强制启用两步验证的角色登录时尚未启用，需使用SetupToken确认绑定
+type SetupRequiredError struct {
+	SetupToken string
+	Enrollment *Enrollment
+}
+
+func (e *SetupRequiredError) Error() string {
+	return ErrSetupRequired.Error()
+}
+
+// Is 支持errors.Is(err, ErrSetupRequired)
+func (e *SetupRequiredError) Is(target error) bool {
+	return target == ErrSetupRequired
+}
+
+// Service 两步验证服务
+type Service struct {
+	store Store
+	redis *database.RedisService
+	cfg   config.TwoFactorConfig
+}
+
+// NewService 创建两步验证服务
+func NewService(store Store, redis *database.RedisService, cfg config.TwoFactorConfig) *Service {
+	return &Service{
+		store: store,
+		redis: redis,
+		cfg:   cfg,
+	}
+}
+
+// Enforced 角色是否必须启用两步验证
+func (s *Service) Enforced(subjectType, role string) bool {
+	roles := s.cfg.EnforcedUserRoles
+	if subjectType == mysql.TwoFactorSubjectAdmin {
+		roles = s.cfg.EnforcedAdminRoles
+	}
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Status 账户的两步验证状态
+func (s *Service) Status(ctx context.Context, subject Subject, role string) (*Status, error) {
+	credential, err := s.store.GetCredential(ctx, subject.Type, subject.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{Enforced: s.Enforced(subject.Type, role)}
+	if credential != nil {
+		hashes, err := decodeBackupCodes(credential.BackupCodes)
+		if err != nil {
+			return nil, err
+		}
+		status.Enabled = true
+		status.BackupCodesRemaining = len(hashes)
+		status.EnabledAt = credential.EnabledAt
+	}
+	return status, nil
+}
+
+// Begin 开始绑定：生成新密钥，保存为未确认的绑定（覆盖之前未确认的绑定），account为验证器应用中显示的账户名
+func (s *Service) Begin(ctx context.Context, subject Subject, account string) (*Enrollment, error) {
+	credential, err := s.store.GetCredential(ctx, subject.Type, subject.ID)
+	if err != nil {
+		return nil, err
+	}
+	if credential != nil {
+		return nil, ErrAlreadyEnabled
+	}
+
+	secret, err := GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.redis.Client().Set(ctx, pendingKeyPrefix+subject.key(), secret, s.setupTTL()).Err(); err != nil {
+		return nil, fmt.Errorf("failed to save pending two-factor secret: %w", err)
+	}
+
+	return &Enrollment{
+		Secret: secret,
+		URI:    ProvisioningURI(s.cfg.Issuer, account, secret),
+	}, nil
+}
+
+// Enable 使用验证器应用生成的验证码确认绑定，启用两步验证并返回备用码（只返回这一次）
+func (s *Service) Enable(ctx context.Context, subject Subject, code string) ([]string, error) {
+	pendingKey := pendingKeyPrefix + subject.key()
+	secret, err := s.redis.Client().Get(ctx, pendingKey).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNoPendingSetup
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending two-factor secret: %w", err)
+	}
+
+	step, ok := Match(secret, code, clock.Now(), s.cfg.Skew)
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	existing, err := s.store.GetCredential(ctx, subject.Type, subject.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrAlreadyEnabled
+	}
+
+	codes, encoded, err := s.newBackupCodes()
+	if err != nil {
+		return nil, err
+	}
+	credential := &mysql.TwoFactorCredential{
+		SubjectType:  subject.Type,
+		SubjectID:    subject.ID,
+		Secret:       secret,
+		BackupCodes:  encoded,
+		LastUsedStep: step,
+		EnabledAt:    clock.Now().Unix(),
+	}
+	if err := s.store.Enable(ctx, credential); err != nil {
+		return nil, err
+	}
+
+	s.redis.Client().Del(ctx, pendingKey)
+	return codes, nil
+}
+
+// Verify 校验验证码或备用码，验证码的时间步和备用码使用后失效
+func (s *Service) Verify(ctx context.Context, subject Subject, code string) error {
+	credential, err := s.store.GetCredential(ctx, subject.Type, subject.ID)
+	if err != nil {
+		return err
+	}
+	if credential == nil {
+		return ErrNotEnabled
+	}
+	return s.verify(ctx, credential, code)
+}
+
+// Disable 校验验证码后关闭两步验证，强制启用的角色不能关闭
+func (s *Service) Disable(ctx context.Context, subject Subject, role, code string) error {
+	if s.Enforced(subject.Type, role) {
+		return ErrEnforced
+	}
+	if err := s.Verify(ctx, subject, code); err != nil {
+		return err
+	}
+	return s.store.Disable(ctx, subject.Type, subject.ID)
+}
+
+// RegenerateBackupCodes 校验验证码后重新生成备用码，之前的备用码全部失效
+func (s *Service) RegenerateBackupCodes(ctx context.Context, subject Subject, code string) ([]string, error) {
+	if err := s.Verify(ctx, subject, code); err != nil {
+		return nil, err
+	}
+
+	// 使用备用码校验时备用码已变化，重新获取
+	credential, err := s.store.GetCredential(ctx, subject.Type, subject.ID)
+	if err != nil {
+		return nil, err
+	}
+	if credential == nil {
+		return nil, ErrNotEnabled
+	}
+
+	codes, encoded, err := s.newBackupCodes()
+	if err != nil {
+		return nil, err
+	}
+	replaced, err := s.store.ReplaceBackupCodes(ctx, credential.ID, credential.BackupCodes, encoded)
+	if err != nil {
+		return nil, err
+	}
+	if !replaced {
+		return nil, ErrInvalidCode
+	}
+	return codes, nil
+}
+
+// CheckLogin 密码校验通过后的两步验证检查
+// enabled为账户的two_factor_enabled：已启用时校验code；未启用但角色强制启用时开始绑定并返回*SetupRequiredError
+func (s *Service) CheckLogin(ctx context.Context, subject Subject, enabled bool, role, account, code string) error {
+	enforced := s.Enforced(subject.Type, role)
+	if !enabled && !enforced {
+		return nil
+	}
+
+	// 以凭据为准：标记与凭据不一致时按凭据是否存在处理
+	err := s.Verify(ctx, subject, code)
+	if !errors.Is(err, ErrNotEnabled) {
+		return err
+	}
+	if !enforced {
+		return nil
+	}
+
+	enrollment, err := s.Begin(ctx, subject, account)
+	if err != nil {
+		return err
+	}
+	token, err := randomHex(setupTokenBytes)
+	if err != nil {
+		return err
+	}
+	if err := s.redis.Client().Set(ctx, setupKey(token), subject.key(), s.setupTTL()).Err(); err != nil {
+		return fmt.Errorf("failed to save two-factor setup token: %w", err)
+	}
+	return &SetupRequiredError{SetupToken: token, Enrollment: enrollment}
+}
+
+// ConfirmSetup 使用登录时签发的绑定token和验证码确认绑定，返回账户和备用码；绑定token使用后失效
+func (s *Service) ConfirmSetup(ctx context.Context, setupToken, code string) (Subject, []string, error) {
+	key := setupKey(setupToken)
+	value, err := s.redis.Client().Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return Subject{}, nil, ErrInvalidSetupToken
+	}
+	if err != nil {
+		return Subject{}, nil, fmt.Errorf("failed to get two-factor setup token: %w", err)
+	}
+
+	subject, ok := parseSubject(value)
+	if !ok {
+		return Subject{}, nil, ErrInvalidSetupToken
+	}
+	codes, err := s.Enable(ctx, subject, code)
+	if err != nil {
+		return Subject{}, nil, err
+	}
+
+	s.redis.Client().Del(ctx, key)
+	return subject, codes, nil
+}
+
+// verify 先按验证码校验（时间步不能重复使用），再按备用码校验
+func (s *Service) verify(ctx context.Context, credential *mysql.TwoFactorCredential, code string) error {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return ErrCodeRequired
+	}
+
+	if step, ok := Match(credential.Secret, code, clock.Now(), s.cfg.Skew); ok {
+		used, err := s.store.UseStep(ctx, credential.ID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidCode
+		}
+		return nil
+	}
+
+	hashes, err := decodeBackupCodes(credential.BackupCodes)
+	if err != nil {
+		return err
+	}
+	hash := HashBackupCode(code)
+	for i, h := range hashes {
+		if h != hash {
+			continue
+		}
+		remaining := append(hashes[:i:i], hashes[i+1:]...)
+		encoded, err := encodeBackupCodes(remaining)
+		if err != nil {
+			return err
+		}
+		replaced, err := s.store.ReplaceBackupCodes(ctx, credential.ID, credential.BackupCodes, encoded)
+		if err != nil {
+			return err
+		}
+		if !replaced {
+			return ErrInvalidCode
+		}
+		return nil
+	}
+	return ErrInvalidCode
+}
+
+// newBackupCodes 生成备用码，返回明文和保存的哈希
+func (s *Service) newBackupCodes() ([]string, string, error) {
+	codes, err := GenerateBackupCodes(s.cfg.BackupCodes)
+	if err != nil {
+		return nil, "", err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = HashBackupCode(code)
+	}
+	encoded, err := encodeBackupCodes(hashes)
+	if err != nil {
+		return nil, "", err
+	}
+	return codes, encoded, nil
+}
+
+// setupTTL 未确认的绑定的有效期
+func (s *Service) setupTTL() time.Duration {
+	return time.Duration(s.cfg.SetupMinutes) * time.Minute
+}
+
+// encodeBackupCodes 备用码哈希编码为JSON数组
+func encodeBackupCodes(hashes []string) (string, error) {
+	data, err := json.Marshal(hashes)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode backup codes: %w", err)
+	}
+	return string(data), nil
+}
+
+// decodeBackupCodes 解析JSON数组格式的备用码哈希
+func decodeBackupCodes(encoded string) ([]string, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+	var hashes []string
+	if err := json.Unmarshal([]byte(encoded), &hashes); err != nil {
+		return nil, fmt.Errorf("failed to decode backup codes: %w", err)
+	}
+	return hashes, nil
+}
+
+// parseSubject 解析"类型:ID"
+func parseSubject(value string) (Subject, bool) {
+	subjectType, rawID, ok := strings.Cut(value, ":")
+	if !ok {
+		return Subject{}, false
+	}
+	id, err := strconv.ParseUint(rawID, 10, 64)
+	if err != nil || id == 0 {
+		return Subject{}, false
+	}
+	return Subject{Type: subjectType, ID: uint(id)}, true
+}
+
+// setupKey 绑定token的Redis键
+func setupKey(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return setupKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+// randomHex 生成n字节的随机数，以十六进制表示
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
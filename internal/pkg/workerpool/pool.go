@@ -0,0 +1,373 @@
+// Package workerpool 异步投递工作池（通知发送、错误上报等调用外部接口的后台任务）
+// 任务进入有界队列，工作协程数在最小值和最大值之间按积压自动调整；统计窗口内死信比例过高时暂停接收新任务，
+// 外部接口变慢或不可用时拒绝新任务而不是无限积压，避免协程和内存随之增长
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"exchange/internal/pkg/config"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/metrics"
+)
+
+// 提交任务失败的原因
+var (
+	ErrQueueFull = errors.New("worker pool queue is full")
+	ErrPaused    = errors.New("worker pool intake is paused")
+	ErrClosed    = errors.New("worker pool is closed")
+)
+
+// 任务结果
+const (
+	ResultSucceeded      = "succeeded"       // 执行成功
+	ResultDeadLetter     = "dead_letter"     // 执行失败（任务自身的重试已用尽）
+	ResultRejectedFull   = "rejected_full"   // 队列已满被拒绝
+	ResultRejectedPaused = "rejected_paused" // 暂停接收期间被拒绝
+)
+
+var (
+	jobsTotal = metrics.NewCounterVec("exchange_delivery_jobs_total",
+		"Async delivery jobs by pool and result.", "pool", "result")
+	queueDepth = metrics.NewGaugeVec("exchange_delivery_queue_depth",
+		"Jobs waiting in the async delivery queue.", "pool")
+	workerCount = metrics.NewGaugeVec("exchange_delivery_workers",
+		"Running async delivery workers.", "pool")
+	queueLag = metrics.NewGaugeVec("exchange_delivery_queue_lag_seconds",
+		"Time the most recently started job spent waiting in the queue.", "pool")
+	pausedGauge = metrics.NewGaugeVec("exchange_delivery_paused",
+		"Whether the async delivery pool is rejecting new jobs (1) or not (0).", "pool")
+)
+
+// poolLogger 工作池日志
+var poolLogger = appLogger.Module("workerpool")
+
+// Job 投递任务，返回错误表示重试后仍失败，计为死信
+type Job func(ctx context.Context) error
+
+// task 队列中的任务
+type task struct {
+	job        Job
+	enqueuedAt time.Time
+}
+
+// Pool 自适应并发的工作池
+type Pool struct {
+	name         string
+	cfg          config.WorkerPoolConfig
+	onDeadLetter func(err error)
+
+	mu      sync.RWMutex // 保护tasks的关闭，提交时持读锁
+	closing bool
+	tasks   chan task
+	retire  chan struct{} // 通知一个空闲的工作协程退出
+	stop    chan struct{} // 停止调整协程
+	wg      sync.WaitGroup
+
+	workers     atomic.Int32
+	lag         atomic.Int64 // 最近开始执行的任务的排队时间(纳秒)
+	pausedUntil atomic.Int64 // 暂停接收的截止时间(UnixNano)，0表示未暂停
+	succeeded   atomic.Int64 // 当前统计窗口内成功的任务数
+	deadLetters atomic.Int64 // 当前统计窗口内的死信数
+}
+
+// New 创建工作池并启动最小数量的工作协程
+// name用于日志和指标标签；onDeadLetter在任务失败时调用（可为nil），不应阻塞
+func New(name string, queueSize int, cfg config.WorkerPoolConfig, onDeadLetter func(err error)) *Pool {
+	cfg = withDefaults(cfg)
+	if queueSize <= 0 {
+		queueSize = 1
+	}
+
+	p := &Pool{
+		name:         name,
+		cfg:          cfg,
+		onDeadLetter: onDeadLetter,
+		tasks:        make(chan task, queueSize),
+		retire:       make(chan struct{}),
+		stop:         make(chan struct{}),
+	}
+	for i := 0; i < cfg.MinWorkers; i++ {
+		p.startWorker()
+	}
+	pausedGauge.Set(0, name)
+	p.updateGauges()
+
+	go p.control()
+	return p
+}
+
+// withDefaults 补全未设置的字段
+func withDefaults(cfg config.WorkerPoolConfig) config.WorkerPoolConfig {
+	defaults := config.DefaultWorkerPoolConfig()
+	if cfg.MinWorkers <= 0 {
+		cfg.MinWorkers = defaults.MinWorkers
+	}
+	if cfg.MaxWorkers < cfg.MinWorkers {
+		cfg.MaxWorkers = cfg.MinWorkers
+	}
+	if cfg.ScaleIntervalMs <= 0 {
+		cfg.ScaleIntervalMs = defaults.ScaleIntervalMs
+	}
+	if cfg.ScaleUpLagMs <= 0 {
+		cfg.ScaleUpLagMs = defaults.ScaleUpLagMs
+	}
+	if cfg.DeadLetterWindowSeconds <= 0 {
+		cfg.DeadLetterWindowSeconds = defaults.DeadLetterWindowSeconds
+	}
+	if cfg.DeadLetterRate <= 0 || cfg.DeadLetterRate > 1 {
+		cfg.DeadLetterRate = defaults.DeadLetterRate
+	}
+	if cfg.DeadLetterMinSamples <= 0 {
+		cfg.DeadLetterMinSamples = defaults.DeadLetterMinSamples
+	}
+	if cfg.PauseSeconds <= 0 {
+		cfg.PauseSeconds = defaults.PauseSeconds
+	}
+	return cfg
+}
+
+// Submit 提交任务，不阻塞；队列满、暂停接收或已关闭时返回错误，由调用方决定丢弃或降级
+func (p *Pool) Submit(job Job) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closing {
+		return ErrClosed
+	}
+	if p.Paused() {
+		jobsTotal.Inc(p.name, ResultRejectedPaused)
+		return ErrPaused
+	}
+
+	select {
+	case p.tasks <- task{job: job, enqueuedAt: time.Now()}:
+		return nil
+	default:
+		jobsTotal.Inc(p.name, ResultRejectedFull)
+		return ErrQueueFull
+	}
+}
+
+// Paused 是否暂停接收新任务
+func (p *Pool) Paused() bool {
+	until := p.pausedUntil.Load()
+	return until != 0 && time.Now().UnixNano() < until
+}
+
+// Stats 工作池当前状态
+type Stats struct {
+	QueueDepth int           `json:"queue_depth"`
+	Workers    int           `json:"workers"`
+	Lag        time.Duration `json:"lag"`
+	Paused     bool          `json:"paused"`
+}
+
+// Stats 获取工作池当前状态
+func (p *Pool) Stats() Stats {
+	return Stats{
+		QueueDepth: len(p.tasks),
+		Workers:    int(p.workers.Load()),
+		Lag:        time.Duration(p.lag.Load()),
+		Paused:     p.Paused(),
+	}
+}
+
+// Close 停止接收新任务，等待队列中的任务执行完成，超时后返回false（剩余任务在后台继续执行）
+func (p *Pool) Close(timeout time.Duration) bool {
+	p.mu.Lock()
+	if !p.closing {
+		p.closing = true
+		close(p.stop)
+		close(p.tasks)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	if timeout <= 0 {
+		return false
+	}
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// startWorker 启动一个工作协程
+func (p *Pool) startWorker() {
+	p.workers.Add(1)
+	p.wg.Add(1)
+	go p.work()
+}
+
+// work 工作协程，队列关闭且取空后或收到退出通知时退出
+func (p *Pool) work() {
+	defer p.wg.Done()
+	defer p.workers.Add(-1)
+
+	for {
+		select {
+		case <-p.retire:
+			return
+		case t, ok := <-p.tasks:
+			if !ok {
+				return
+			}
+			p.execute(t)
+		}
+	}
+}
+
+// execute 执行任务并记录结果
+func (p *Pool) execute(t task) {
+	p.lag.Store(int64(time.Since(t.enqueuedAt)))
+
+	err := p.run(t.job)
+	if err == nil {
+		p.succeeded.Add(1)
+		jobsTotal.Inc(p.name, ResultSucceeded)
+		return
+	}
+
+	p.deadLetters.Add(1)
+	jobsTotal.Inc(p.name, ResultDeadLetter)
+	if p.onDeadLetter != nil {
+		p.onDeadLetter(err)
+	}
+}
+
+// run 执行任务，panic按失败处理
+func (p *Pool) run(job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicError{value: r}
+		}
+	}()
+	return job(context.Background())
+}
+
+// control 定期按积压调整工作协程数，并检查死信比例
+func (p *Pool) control() {
+	ticker := time.NewTicker(time.Duration(p.cfg.ScaleIntervalMs) * time.Millisecond)
+	defer ticker.Stop()
+
+	window := time.Duration(p.cfg.DeadLetterWindowSeconds) * time.Second
+	windowStart := time.Now()
+	wasPaused := false
+
+	for {
+		select {
+		case <-p.stop:
+			p.updateGauges()
+			return
+		case now := <-ticker.C:
+			p.scale()
+
+			if p.checkDeadLetters() || now.Sub(windowStart) >= window {
+				p.succeeded.Store(0)
+				p.deadLetters.Store(0)
+				windowStart = now
+			}
+
+			paused := p.Paused()
+			if wasPaused && !paused {
+				poolLogger.Info("工作池恢复接收任务", map[string]interface{}{"pool": p.name})
+			}
+			wasPaused = paused
+			p.updateGauges()
+		}
+	}
+}
+
+// scale 队列积压超过工作协程数或排队时间超过阈值时扩容（每次增加一半，至少1个），队列为空时缩容1个
+func (p *Pool) scale() {
+	depth := len(p.tasks)
+	workers := int(p.workers.Load())
+	lag := time.Duration(p.lag.Load())
+	scaleUpLag := time.Duration(p.cfg.ScaleUpLagMs) * time.Millisecond
+
+	switch {
+	case (depth > workers || lag >= scaleUpLag) && workers < p.cfg.MaxWorkers:
+		add := max(1, workers/2)
+		add = min(add, p.cfg.MaxWorkers-workers)
+		for i := 0; i < add; i++ {
+			p.startWorker()
+		}
+		poolLogger.Debug("工作池扩容", map[string]interface{}{
+			"pool":        p.name,
+			"workers":     workers + add,
+			"queue_depth": depth,
+			"lag_ms":      lag.Milliseconds(),
+		})
+	case depth == 0 && lag < scaleUpLag && workers > p.cfg.MinWorkers:
+		// 只通知空闲的工作协程，都在执行任务时下次再缩容
+		select {
+		case p.retire <- struct{}{}:
+		default:
+		}
+	}
+
+	if depth == 0 {
+		// 队列已取空，排队时间不再反映当前积压
+		p.lag.Store(0)
+	}
+}
+
+// checkDeadLetters 统计窗口内死信比例达到阈值时暂停接收，返回是否触发暂停
+func (p *Pool) checkDeadLetters() bool {
+	deadLetters := p.deadLetters.Load()
+	total := deadLetters + p.succeeded.Load()
+	if total < int64(p.cfg.DeadLetterMinSamples) {
+		return false
+	}
+
+	rate := float64(deadLetters) / float64(total)
+	if rate < p.cfg.DeadLetterRate {
+		return false
+	}
+
+	pause := time.Duration(p.cfg.PauseSeconds) * time.Second
+	p.pausedUntil.Store(time.Now().Add(pause).UnixNano())
+	poolLogger.Warn("工作池死信比例过高，暂停接收任务", map[string]interface{}{
+		"pool":          p.name,
+		"dead_letters":  deadLetters,
+		"total":         total,
+		"rate":          rate,
+		"pause_seconds": p.cfg.PauseSeconds,
+		"queue_depth":   len(p.tasks),
+	})
+	return true
+}
+
+// updateGauges 更新指标
+func (p *Pool) updateGauges() {
+	queueDepth.Set(float64(len(p.tasks)), p.name)
+	workerCount.Set(float64(p.workers.Load()), p.name)
+	queueLag.Set(time.Duration(p.lag.Load()).Seconds(), p.name)
+	paused := 0.0
+	if p.Paused() {
+		paused = 1
+	}
+	pausedGauge.Set(paused, p.name)
+}
+
+// panicError 任务panic时的错误
+type panicError struct {
+	value interface{}
+}
+
+func (e panicError) Error() string {
+	return fmt.Sprintf("job panicked: %v", e.value)
+}
@@ -15,6 +15,7 @@ type CachedAdminRepository struct {
 	repo         *mysqlRepo.AdminRepository
 	cacheManager *cache.CacheManager
 	cacheTTL     time.Duration
+	freshWindow  time.Duration // 写操作后绕过缓存的时长
 }
 
 // NewCachedAdminRepository 创建带缓存的管理员Repository
@@ -22,7 +23,8 @@ func NewCachedAdminRepository(repo *mysqlRepo.AdminRepository, cacheManager *cac
 	return &CachedAdminRepository{
 		repo:         repo,
 		cacheManager: cacheManager,
-		cacheTTL:     30 * time.Minute, // 默认缓存30分钟
+		cacheTTL:     cache.DefaultTTL, // 使用缓存管理器的前缀过期策略（默认30分钟，带随机浮动）
+		freshWindow:  cache.DefaultFreshWindow,
 	}
 }
 
@@ -41,12 +43,14 @@ func (r *CachedAdminRepository) Create(ctx context.Context, admin *mysql.Admin)
 
 // GetByID 根据ID获取管理员（带缓存）
 func (r *CachedAdminRepository) GetByID(ctx context.Context, id uint) (*mysql.Admin, error) {
-	// 先尝试从缓存获取
-	cacheKey := fmt.Sprintf("admin_%d", id)
-	var cachedAdmin mysql.Admin
-	err := r.cacheManager.GetUserInfo(cacheKey, &cachedAdmin)
-	if err == nil {
-		return &cachedAdmin, nil
+	// 写操作后的短时间内绕过缓存直接查询数据库（其他实例的内存缓存可能还是旧数据）
+	if !r.isFresh(id) {
+		cacheKey := fmt.Sprintf("admin_%d", id)
+		var cachedAdmin mysql.Admin
+		err := r.cacheManager.GetUserInfo(cacheKey, &cachedAdmin)
+		if err == nil {
+			return &cachedAdmin, nil
+		}
 	}
 
 	// 缓存未命中，从数据库获取
@@ -96,8 +100,9 @@ func (r *CachedAdminRepository) Update(ctx context.Context, admin *mysql.Admin)
 		return err
 	}
 
-	// 更新缓存
+	// 更新缓存，并标记其他实例在短时间内绕过缓存
 	r.cacheAdminInfo(admin)
+	r.markFresh(admin.ID)
 
 	return nil
 }
@@ -158,7 +163,7 @@ func (r *CachedAdminRepository) Count(ctx context.Context) (int64, error) {
 	}
 
 	// 缓存计数（短期缓存5分钟）
-	r.cacheManager.SetTempData("admin_count", count, 5*time.Minute, true)
+	r.cacheManager.SetTempData("admin_count", count, cache.DefaultTTL, true)
 
 	return count, nil
 }
@@ -180,7 +185,7 @@ func (r *CachedAdminRepository) CountByStatus(ctx context.Context, status mysql.
 	}
 
 	// 缓存计数（短期缓存5分钟）
-	r.cacheManager.SetTempData(cacheKey, count, 5*time.Minute, true)
+	r.cacheManager.SetTempData(cacheKey, count, cache.DefaultTTL, true)
 
 	return count, nil
 }
@@ -231,6 +236,8 @@ func (r *CachedAdminRepository) cacheAdminInfo(admin *mysql.Admin) {
 
 // clearAdminCache 清除管理员缓存
 func (r *CachedAdminRepository) clearAdminCache(adminID uint) {
+	r.markFresh(adminID)
+
 	cacheKey := fmt.Sprintf("admin_%d", adminID)
 	r.cacheManager.DeleteUserInfo(cacheKey)
 
@@ -246,6 +253,25 @@ func (r *CachedAdminRepository) SetCacheTTL(ttl time.Duration) {
 	r.cacheTTL = ttl
 }
 
+// SetFreshWindow 设置写操作后绕过缓存的时长，为0时不设置标记
+func (r *CachedAdminRepository) SetFreshWindow(window time.Duration) {
+	r.freshWindow = window
+}
+
+// markFresh 设置"新鲜"标记（Redis，跨实例生效），标记有效期内GetByID直接查询数据库
+func (r *CachedAdminRepository) markFresh(id uint) {
+	r.cacheManager.MarkFresh("admin", fmt.Sprintf("%d", id), r.freshWindow)
+}
+
+// isFresh 检查是否需要绕过缓存，Redis不可用时按未标记处理
+func (r *CachedAdminRepository) isFresh(id uint) bool {
+	if r.freshWindow <= 0 {
+		return false
+	}
+	fresh, err := r.cacheManager.IsFresh("admin", fmt.Sprintf("%d", id))
+	return err == nil && fresh
+}
+
 // ClearAllCache 清除所有管理员相关缓存
 func (r *CachedAdminRepository) ClearAllCache() {
 	// 这里可以实现批量清除逻辑
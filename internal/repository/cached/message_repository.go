@@ -0,0 +1,340 @@
+package cached
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"exchange/internal/models/mongodb"
+	"exchange/internal/pkg/config"
+	"exchange/internal/pkg/database"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/repository"
+	mongoRepo "exchange/internal/repository/mongodb"
+)
+
+// 未读数缓存键前缀，每个用户一个hash：total为未读总数，其余字段为发送者ID及其未读数
+const (
+	unreadKeyPrefix  = "unread_count:"
+	unreadTotalField = "total"
+)
+
+// adjustUnreadScript 调整单个会话的未读数并同步总数，结果不小于0
+// 缓存不存在时不调整（返回-1），下次读取时从MongoDB重新统计，避免只含部分会话的计数
+var adjustUnreadScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+local current = tonumber(redis.call('HGET', KEYS[1], ARGV[1]) or '0')
+local updated = current + tonumber(ARGV[2])
+if updated < 0 then
+	updated = 0
+end
+if updated == 0 then
+	redis.call('HDEL', KEYS[1], ARGV[1])
+else
+	redis.call('HSET', KEYS[1], ARGV[1], updated)
+end
+local total = tonumber(redis.call('HGET', KEYS[1], 'total') or '0') + updated - current
+if total < 0 then
+	total = 0
+end
+redis.call('HSET', KEYS[1], 'total', total)
+return total
+`)
+
+// CachedMessageRepository 在Redis中维护单聊未读数的消息Repository装饰器
+// 发送时增加接收方的计数，标记已读时减去本次标记的数量；缓存不存在或过期时从MongoDB重新统计，
+// Redis不可用时直接查询MongoDB。计数与MongoDB的偏差（如消息被保留期清理或归档）由定时任务对账修正
+type CachedMessageRepository struct {
+	repo  *mongoRepo.MessageRepository
+	redis *database.RedisService
+	ttl   time.Duration
+}
+
+// NewCachedMessageRepository 创建带未读数缓存的消息Repository
+func NewCachedMessageRepository(repo *mongoRepo.MessageRepository, redisService *database.RedisService, cfg config.ChatConfig) *CachedMessageRepository {
+	return &CachedMessageRepository{
+		repo:  repo,
+		redis: redisService,
+		ttl:   time.Duration(cfg.UnreadCacheTTL) * time.Second,
+	}
+}
+
+// Create 保存消息，单聊消息增加接收方的未读数
+func (r *CachedMessageRepository) Create(ctx context.Context, message *mongodb.ChatMessage) error {
+	if err := r.repo.Create(ctx, message); err != nil {
+		return err
+	}
+
+	if !message.IsGroupMessage() && !message.IsRead {
+		r.adjust(ctx, message.ToUserID, message.FromUserID, 1)
+	}
+	return nil
+}
+
+// SaveMessage 保存消息（实现接口方法）
+func (r *CachedMessageRepository) SaveMessage(ctx context.Context, message *mongodb.ChatMessage) error {
+	return r.Create(ctx, message)
+}
+
+// ForEachConversationMessage 按时间顺序遍历会话消息（不缓存）
+func (r *CachedMessageRepository) ForEachConversationMessage(ctx context.Context, userID1, userID2 string, from, to time.Time, fn func(message *mongodb.ChatMessage) error) error {
+	return r.repo.ForEachConversationMessage(ctx, userID1, userID2, from, to, fn)
+}
+
+// GetConversationMessages 按游标分页获取会话消息（不缓存）
+func (r *CachedMessageRepository) GetConversationMessages(ctx context.Context, userID1, userID2 string, before *repository.MessageCursor, limit int) ([]*mongodb.ChatMessage, error) {
+	return r.repo.GetConversationMessages(ctx, userID1, userID2, before, limit)
+}
+
+// GetByID 根据ID获取消息（不缓存）
+func (r *CachedMessageRepository) GetByID(ctx context.Context, messageID string) (*mongodb.ChatMessage, error) {
+	return r.repo.GetByID(ctx, messageID)
+}
+
+// EditMessage 修改消息内容
+func (r *CachedMessageRepository) EditMessage(ctx context.Context, messageID, previousContent, content string, at time.Time) error {
+	return r.repo.EditMessage(ctx, messageID, previousContent, content, at)
+}
+
+// SoftDelete 软删除消息（未读数与MongoDB的统计口径一致，包含已删除的消息）
+func (r *CachedMessageRepository) SoftDelete(ctx context.Context, messageID string, at time.Time) error {
+	return r.repo.SoftDelete(ctx, messageID, at)
+}
+
+// MarkDelivered 标记消息为已投递（不影响未读数）
+func (r *CachedMessageRepository) MarkDelivered(ctx context.Context, recipientID string, messageIDs []string, at time.Time) ([]*mongodb.ChatMessage, error) {
+	return r.repo.MarkDelivered(ctx, recipientID, messageIDs, at)
+}
+
+// MarkReadUpTo 标记已读，并减去接收方与该发送者会话的未读数
+func (r *CachedMessageRepository) MarkReadUpTo(ctx context.Context, recipientID, senderID string, upTo repository.MessageCursor, at time.Time) (int64, error) {
+	count, err := r.repo.MarkReadUpTo(ctx, recipientID, senderID, upTo, at)
+	if err != nil {
+		return 0, err
+	}
+
+	if count > 0 {
+		r.adjust(ctx, recipientID, senderID, -count)
+	}
+	return count, nil
+}
+
+// MarkConversationAsRead 将发送者发来的所有未读消息标记为已读，并减去相应的未读数
+func (r *CachedMessageRepository) MarkConversationAsRead(ctx context.Context, fromUserID, toUserID string) (int64, error) {
+	count, err := r.repo.MarkConversationAsRead(ctx, fromUserID, toUserID)
+	if err != nil {
+		return 0, err
+	}
+
+	if count > 0 {
+		r.adjust(ctx, toUserID, fromUserID, -count)
+	}
+	return count, nil
+}
+
+// GetUnreadCount 获取用户的单聊未读总数（带缓存）
+func (r *CachedMessageRepository) GetUnreadCount(ctx context.Context, userID string) (int64, error) {
+	counts, err := r.counts(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return counts.total, nil
+}
+
+// GetConversationUnreadCount 获取fromUserID发给toUserID的未读消息数（带缓存）
+func (r *CachedMessageRepository) GetConversationUnreadCount(ctx context.Context, fromUserID, toUserID string) (int64, error) {
+	counts, err := r.counts(ctx, toUserID)
+	if err != nil {
+		return 0, err
+	}
+	return counts.bySender[fromUserID], nil
+}
+
+// CountUnreadBySender 按发送者获取用户的单聊未读数（带缓存）
+func (r *CachedMessageRepository) CountUnreadBySender(ctx context.Context, userID string) (map[string]int64, error) {
+	counts, err := r.counts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return counts.bySender, nil
+}
+
+// ReconcileUnread 从MongoDB重新统计用户的未读数并覆盖缓存，返回缓存是否与MongoDB不一致
+// 缓存不存在时不创建，下次读取时再统计
+func (r *CachedMessageRepository) ReconcileUnread(ctx context.Context, userID string) (bool, error) {
+	cached, err := r.load(ctx, userID)
+	if err != nil || cached == nil {
+		return false, err
+	}
+
+	counts, err := r.count(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	if cached.equal(counts) {
+		return false, nil
+	}
+	if err := r.store(ctx, userID, counts); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// ForEachUnreadUser 遍历缓存了未读数的用户ID
+func (r *CachedMessageRepository) ForEachUnreadUser(ctx context.Context, fn func(ctx context.Context, userID string) error) error {
+	return r.redis.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+		var cursor uint64
+		for {
+			keys, next, err := client.Scan(ctx, cursor, unreadKeyPrefix+"*", 500).Result()
+			if err != nil {
+				return fmt.Errorf("failed to scan unread count keys: %w", err)
+			}
+			for _, key := range keys {
+				if err := fn(ctx, key[len(unreadKeyPrefix):]); err != nil {
+					return err
+				}
+			}
+
+			cursor = next
+			if cursor == 0 {
+				return nil
+			}
+		}
+	})
+}
+
+// unreadCounts 用户的单聊未读总数和各发送者的未读数
+type unreadCounts struct {
+	total    int64
+	bySender map[string]int64
+}
+
+// equal 比较两组未读数，数量为0的会话视为不存在
+func (c *unreadCounts) equal(other *unreadCounts) bool {
+	if c.total != other.total {
+		return false
+	}
+	nonZero := 0
+	for senderID, count := range c.bySender {
+		if count == 0 {
+			continue
+		}
+		nonZero++
+		if other.bySender[senderID] != count {
+			return false
+		}
+	}
+	for _, count := range other.bySender {
+		if count != 0 {
+			nonZero--
+		}
+	}
+	return nonZero == 0
+}
+
+// counts 获取用户的未读数，缓存不存在时从MongoDB统计并写入缓存
+func (r *CachedMessageRepository) counts(ctx context.Context, userID string) (*unreadCounts, error) {
+	cached, err := r.load(ctx, userID)
+	if err == nil && cached != nil {
+		return cached, nil
+	}
+	if err != nil {
+		appLogger.Warn("读取未读数缓存失败，从MongoDB统计", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+
+	counts, err := r.count(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.store(ctx, userID, counts); err != nil {
+		appLogger.Warn("写入未读数缓存失败", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+	return counts, nil
+}
+
+// count 从MongoDB统计用户的未读数
+func (r *CachedMessageRepository) count(ctx context.Context, userID string) (*unreadCounts, error) {
+	bySender, err := r.repo.CountUnreadBySender(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := &unreadCounts{bySender: bySender}
+	for _, count := range bySender {
+		counts.total += count
+	}
+	return counts, nil
+}
+
+// load 读取缓存的未读数，缓存不存在时返回nil
+func (r *CachedMessageRepository) load(ctx context.Context, userID string) (*unreadCounts, error) {
+	values, err := r.redis.Client().HGetAll(ctx, unreadKeyPrefix+userID).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unread counts: %w", err)
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	counts := &unreadCounts{bySender: make(map[string]int64, len(values))}
+	for field, value := range values {
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unread count %s=%s", field, value)
+		}
+		if field == unreadTotalField {
+			counts.total = count
+		} else {
+			counts.bySender[field] = count
+		}
+	}
+	return counts, nil
+}
+
+// store 用统计结果替换缓存，总数字段保证没有未读消息的用户也有缓存
+func (r *CachedMessageRepository) store(ctx context.Context, userID string, counts *unreadCounts) error {
+	key := unreadKeyPrefix + userID
+	values := make([]interface{}, 0, len(counts.bySender)*2+2)
+	for senderID, count := range counts.bySender {
+		values = append(values, senderID, count)
+	}
+	values = append(values, unreadTotalField, counts.total)
+
+	_, err := r.redis.Client().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, values...)
+		pipe.Expire(ctx, key, r.ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store unread counts: %w", err)
+	}
+	return nil
+}
+
+// adjust 调整用户与发送者会话的未读数
+// 调整失败时删除缓存，下次读取时重新统计，不影响消息本身的写入
+func (r *CachedMessageRepository) adjust(ctx context.Context, userID, senderID string, delta int64) {
+	key := unreadKeyPrefix + userID
+	if err := adjustUnreadScript.Run(ctx, r.redis.Client(), []string{key}, senderID, delta).Err(); err != nil {
+		appLogger.Warn("更新未读数缓存失败", map[string]interface{}{
+			"user_id":   userID,
+			"sender_id": senderID,
+			"error":     err.Error(),
+		})
+		r.redis.Client().Del(ctx, key)
+	}
+}
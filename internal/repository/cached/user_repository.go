@@ -2,6 +2,7 @@ package cached
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -15,6 +16,7 @@ type CachedUserRepository struct {
 	repo         *mysqlRepo.UserRepository
 	cacheManager *cache.CacheManager
 	cacheTTL     time.Duration
+	freshWindow  time.Duration // 写操作后绕过缓存的时长
 }
 
 // NewCachedUserRepository 创建带缓存的用户Repository
@@ -22,7 +24,8 @@ func NewCachedUserRepository(repo *mysqlRepo.UserRepository, cacheManager *cache
 	return &CachedUserRepository{
 		repo:         repo,
 		cacheManager: cacheManager,
-		cacheTTL:     30 * time.Minute, // 默认缓存30分钟
+		cacheTTL:     cache.DefaultTTL, // 使用缓存管理器的前缀过期策略（默认30分钟，带随机浮动）
+		freshWindow:  cache.DefaultFreshWindow,
 	}
 }
 
@@ -40,25 +43,32 @@ func (r *CachedUserRepository) Create(ctx context.Context, user *mysql.User) err
 }
 
 // GetByID 根据ID获取用户（带缓存）
+// 并发的缓存未命中只查询一次数据库，不存在的用户短时间内缓存空结果
 func (r *CachedUserRepository) GetByID(ctx context.Context, id uint) (*mysql.User, error) {
-	// 先尝试从缓存获取
-	cacheKey := fmt.Sprintf("%d", id)
-	var cachedUser mysql.User
-	err := r.cacheManager.GetUserInfo(cacheKey, &cachedUser)
-	if err == nil {
-		return &cachedUser, nil
+	// 写操作后的短时间内绕过缓存直接查询数据库（其他实例的内存缓存可能还是旧数据）
+	if r.isFresh(id) {
+		return r.repo.GetByID(ctx, id)
 	}
 
-	// 缓存未命中，从数据库获取
-	user, err := r.repo.GetByID(ctx, id)
+	var user mysql.User
+	err := r.cacheManager.GetUserInfoOrLoad(fmt.Sprintf("%d", id), r.cacheTTL, &user, func() (interface{}, error) {
+		loaded, err := r.repo.GetByID(ctx, id)
+		if errors.Is(err, mysqlRepo.ErrUserNotFound) {
+			return nil, fmt.Errorf("%w: %w", cache.ErrNotFound, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return loaded.ToPublicUser(), nil
+	})
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, mysqlRepo.ErrUserNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// 缓存用户信息
-	r.cacheUserInfo(user)
-
-	return user, nil
+	return &user, nil
 }
 
 // GetByUsername 根据用户名获取用户（带缓存）
@@ -96,8 +106,9 @@ func (r *CachedUserRepository) Update(ctx context.Context, user *mysql.User) err
 		return err
 	}
 
-	// 更新缓存
+	// 更新缓存，并标记其他实例在短时间内绕过缓存
 	r.cacheUserInfo(user)
+	r.markFresh(user.ID)
 
 	return nil
 }
@@ -158,7 +169,7 @@ func (r *CachedUserRepository) Count(ctx context.Context) (int64, error) {
 	}
 
 	// 缓存计数（短期缓存5分钟）
-	r.cacheManager.SetTempData("user_count", count, 5*time.Minute, true)
+	r.cacheManager.SetTempData("user_count", count, cache.DefaultTTL, true)
 
 	return count, nil
 }
@@ -180,7 +191,7 @@ func (r *CachedUserRepository) CountByStatus(ctx context.Context, status mysql.U
 	}
 
 	// 缓存计数（短期缓存5分钟）
-	r.cacheManager.SetTempData(cacheKey, count, 5*time.Minute, true)
+	r.cacheManager.SetTempData(cacheKey, count, cache.DefaultTTL, true)
 
 	return count, nil
 }
@@ -231,6 +242,8 @@ func (r *CachedUserRepository) cacheUserInfo(user *mysql.User) {
 
 // clearUserCache 清除用户缓存
 func (r *CachedUserRepository) clearUserCache(userID uint) {
+	r.markFresh(userID)
+
 	cacheKey := fmt.Sprintf("%d", userID)
 	r.cacheManager.DeleteUserInfo(cacheKey)
 
@@ -246,6 +259,25 @@ func (r *CachedUserRepository) SetCacheTTL(ttl time.Duration) {
 	r.cacheTTL = ttl
 }
 
+// SetFreshWindow 设置写操作后绕过缓存的时长，为0时不设置标记
+func (r *CachedUserRepository) SetFreshWindow(window time.Duration) {
+	r.freshWindow = window
+}
+
+// markFresh 设置"新鲜"标记（Redis，跨实例生效），标记有效期内GetByID直接查询数据库
+func (r *CachedUserRepository) markFresh(id uint) {
+	r.cacheManager.MarkFresh("user", fmt.Sprintf("%d", id), r.freshWindow)
+}
+
+// isFresh 检查是否需要绕过缓存，Redis不可用时按未标记处理
+func (r *CachedUserRepository) isFresh(id uint) bool {
+	if r.freshWindow <= 0 {
+		return false
+	}
+	fresh, err := r.cacheManager.IsFresh("user", fmt.Sprintf("%d", id))
+	return err == nil && fresh
+}
+
 // ClearAllCache 清除所有用户相关缓存
 func (r *CachedUserRepository) ClearAllCache() {
 	// 这里可以实现批量清除逻辑
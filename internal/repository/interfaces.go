@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"exchange/internal/models/mongodb"
@@ -20,6 +21,27 @@ type BaseRepository[T any] interface {
 	Count(ctx context.Context) (int64, error)
 }
 
+// 用户列表的排序字段
+const (
+	UserSortID          = "id"
+	UserSortUsername    = "username"
+	UserSortCreatedAt   = "created_at"
+	UserSortLastLoginAt = "last_login_at"
+	UserSortLoginCount  = "login_count"
+)
+
+// UserQuery 用户列表查询条件
+type UserQuery struct {
+	Keyword string           // 按用户名或邮箱模糊搜索，为空表示不限
+	Status  mysql.UserStatus // 为空表示不限
+	Role    mysql.UserRole   // 为空表示不限
+	Deleted bool             // true时只查询已删除（软删除）的用户
+	SortBy  string           // 排序字段（UserSort*），为空时按ID
+	Desc    bool             // 是否倒序
+	Limit   int
+	Offset  int
+}
+
 // UserRepository 用户Repository接口
 type UserRepository interface {
 	Create(ctx context.Context, user *mysql.User) error
@@ -34,7 +56,12 @@ type UserRepository interface {
 	GetUsersByRole(ctx context.Context, role mysql.UserRole, limit, offset int) ([]*mysql.User, error)
 	Count(ctx context.Context) (int64, error)
 	CountByStatus(ctx context.Context, status mysql.UserStatus) (int64, error)
+	CountLoggedInSince(ctx context.Context, since time.Time) (int64, error)
+	CountCreatedPerDay(ctx context.Context, since time.Time) (map[string]int64, error)
 	Search(ctx context.Context, keyword string, limit, offset int) ([]*mysql.User, error)
+	Query(ctx context.Context, query UserQuery) ([]*mysql.User, int64, error)
+	GetDeletedByID(ctx context.Context, id uint) (*mysql.User, error)
+	Restore(ctx context.Context, id uint) error
 	UpdateStatus(ctx context.Context, userID uint, status mysql.UserStatus) error
 	BatchUpdateStatus(ctx context.Context, userIDs []uint, status mysql.UserStatus) error
 	DB() *gorm.DB // 获取数据库实例
@@ -59,6 +86,65 @@ type AdminRepository interface {
 	BatchUpdateStatus(ctx context.Context, adminIDs []uint, status mysql.AdminStatus) error
 }
 
+// RoleRepository 管理员角色Repository接口
+type RoleRepository interface {
+	ListRoles(ctx context.Context) ([]*mysql.Role, error)
+	GetRole(ctx context.Context, name string) (*mysql.Role, error)
+	SaveRole(ctx context.Context, role *mysql.Role, permissions []string) error
+	DeleteRole(ctx context.Context, name string) error
+}
+
+// TwoFactorRepository 两步验证凭据Repository接口
+type TwoFactorRepository interface {
+	GetCredential(ctx context.Context, subjectType string, subjectID uint) (*mysql.TwoFactorCredential, error)
+	Enable(ctx context.Context, credential *mysql.TwoFactorCredential) error
+	Disable(ctx context.Context, subjectType string, subjectID uint) error
+	UseStep(ctx context.Context, id uint, step int64) (bool, error)
+	ReplaceBackupCodes(ctx context.Context, id uint, old, codes string) (bool, error)
+}
+
+// APIKeyRepository 用户API key Repository接口
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *mysql.APIKey) error
+	GetByKeyID(ctx context.Context, keyID string) (*mysql.APIKey, error)
+	ListByUser(ctx context.Context, userID uint) ([]*mysql.APIKey, error)
+	CountByUser(ctx context.Context, userID uint) (int64, error)
+	Update(ctx context.Context, key *mysql.APIKey) error
+	Delete(ctx context.Context, id uint) error
+	Touch(ctx context.Context, id uint, ip string, at time.Time) error
+}
+
+// OAuthIdentityRepository 第三方登录账户Repository接口
+type OAuthIdentityRepository interface {
+	Create(ctx context.Context, identity *mysql.OAuthIdentity) error
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*mysql.OAuthIdentity, error)
+	ListByUser(ctx context.Context, userID uint) ([]*mysql.OAuthIdentity, error)
+}
+
+// LoginDeviceRepository 登录设备Repository接口
+type LoginDeviceRepository interface {
+	Create(ctx context.Context, device *mysql.LoginDevice) error
+	GetByFingerprint(ctx context.Context, userID uint, fingerprint string) (*mysql.LoginDevice, error)
+	ListByUser(ctx context.Context, userID uint) ([]*mysql.LoginDevice, error)
+	Touch(ctx context.Context, id uint, ip, country string, at time.Time) error
+	PruneOldest(ctx context.Context, userID uint, keep int) (int64, error)
+}
+
+// PushDeviceRepository 推送设备Repository接口
+type PushDeviceRepository interface {
+	Upsert(ctx context.Context, device *mysql.PushDevice) error
+	ListByUser(ctx context.Context, userID uint) ([]*mysql.PushDevice, error)
+	Delete(ctx context.Context, userID, id uint) error
+	DeleteByToken(ctx context.Context, token string) error
+	PruneOldest(ctx context.Context, userID uint, keep int) (int64, error)
+}
+
+// NotificationPreferenceRepository 推送通知设置Repository接口
+type NotificationPreferenceRepository interface {
+	Get(ctx context.Context, userID uint) (*mysql.NotificationPreference, error)
+	Save(ctx context.Context, pref *mysql.NotificationPreference) error
+}
+
 // AdminLogRepository 管理员日志Repository接口
 type AdminLogRepository interface {
 	BaseRepository[mysql.AdminLog]
@@ -67,6 +153,123 @@ type AdminLogRepository interface {
 	GetByDateRange(ctx context.Context, startTime, endTime int64, limit, offset int) ([]*mysql.AdminLog, error)
 }
 
+// AccountDeletionRepository 账户注销请求Repository接口
+type AccountDeletionRepository interface {
+	Create(ctx context.Context, req *mysql.AccountDeletionRequest) error
+	Update(ctx context.Context, req *mysql.AccountDeletionRequest) error
+	GetLatestByUserID(ctx context.Context, userID uint) (*mysql.AccountDeletionRequest, error)
+	GetDue(ctx context.Context, before int64, limit int) ([]*mysql.AccountDeletionRequest, error)
+	AnonymizeUser(ctx context.Context, req *mysql.AccountDeletionRequest, fields map[string]interface{}) error
+}
+
+// RetentionRepository 数据保留Repository接口（法律保全、清理执行记录和会话的消息归档策略）
+type RetentionRepository interface {
+	CreateHold(ctx context.Context, hold *mysql.LegalHold) error
+	ReleaseHolds(ctx context.Context, userID, adminID uint, releasedAt int64) (int64, error)
+	GetActiveHolds(ctx context.Context) ([]*mysql.LegalHold, error)
+	GetHeldUserIDs(ctx context.Context) ([]uint, error)
+	CreateRun(ctx context.Context, run *mysql.RetentionRun) error
+	GetRuns(ctx context.Context, category string, since int64, limit int) ([]*mysql.RetentionRun, error)
+	SetArchiveOverride(ctx context.Context, override *mysql.MessageArchiveOverride) error
+	ListArchiveOverrides(ctx context.Context) ([]*mysql.MessageArchiveOverride, error)
+	GetArchiveOverride(ctx context.Context, id uint) (*mysql.MessageArchiveOverride, error)
+	DeleteArchiveOverride(ctx context.Context, id uint) error
+}
+
+// UserImportRepository 用户批量导入任务Repository接口
+type UserImportRepository interface {
+	Create(ctx context.Context, job *mysql.UserImportJob) error
+	Update(ctx context.Context, job *mysql.UserImportJob) error
+	GetByID(ctx context.Context, id uint) (*mysql.UserImportJob, error)
+}
+
+// AutomationRuleRepository 消息自动化规则Repository接口
+type AutomationRuleRepository interface {
+	Create(ctx context.Context, rule *mysql.AutomationRule) error
+	Update(ctx context.Context, rule *mysql.AutomationRule) error
+	Delete(ctx context.Context, id uint) error
+	GetByID(ctx context.Context, id uint) (*mysql.AutomationRule, error)
+	List(ctx context.Context, trigger mysql.AutomationTrigger) ([]*mysql.AutomationRule, error)
+	GetEnabledByTrigger(ctx context.Context, trigger mysql.AutomationTrigger) ([]*mysql.AutomationRule, error)
+}
+
+// NotificationTemplateRepository 通知模板Repository接口
+type NotificationTemplateRepository interface {
+	CreateVersion(ctx context.Context, tpl *mysql.NotificationTemplate) error
+	Activate(ctx context.Context, tpl *mysql.NotificationTemplate) error
+	Deactivate(ctx context.Context, event, channel, locale string) error
+	GetVersion(ctx context.Context, event, channel, locale string, version int) (*mysql.NotificationTemplate, error)
+	GetActive(ctx context.Context, event, channel, locale string) (*mysql.NotificationTemplate, error)
+	ListActive(ctx context.Context, event, channel string) ([]*mysql.NotificationTemplate, error)
+	ListVersions(ctx context.Context, event, channel, locale string) ([]*mysql.NotificationTemplate, error)
+}
+
+// ChatExportRepository 会话导出任务和导出授权Repository接口
+type ChatExportRepository interface {
+	CreateJob(ctx context.Context, job *mysql.ChatExportJob) error
+	UpdateJob(ctx context.Context, job *mysql.ChatExportJob) error
+	GetJobByID(ctx context.Context, id uint) (*mysql.ChatExportJob, error)
+	CountUnfinishedJobs(ctx context.Context, userID uint) (int64, error)
+	GetExpiredJobs(ctx context.Context, now int64, limit int) ([]*mysql.ChatExportJob, error)
+	GetConsent(ctx context.Context, userID uint) (*mysql.ChatExportConsent, error)
+	SetConsent(ctx context.Context, consent *mysql.ChatExportConsent) error
+}
+
+// ConversationMessageRepository 单聊消息Repository接口（按会话遍历消息时不在内存中保留全部消息；未读数可由Redis计数器提供）
+type ConversationMessageRepository interface {
+	ForEachConversationMessage(ctx context.Context, userID1, userID2 string, from, to time.Time, fn func(message *mongodb.ChatMessage) error) error
+	GetConversationMessages(ctx context.Context, userID1, userID2 string, before *MessageCursor, limit int) ([]*mongodb.ChatMessage, error)
+	GetByID(ctx context.Context, messageID string) (*mongodb.ChatMessage, error)
+	EditMessage(ctx context.Context, messageID, previousContent, content string, at time.Time) error
+	SoftDelete(ctx context.Context, messageID string, at time.Time) error
+	MarkDelivered(ctx context.Context, recipientID string, messageIDs []string, at time.Time) ([]*mongodb.ChatMessage, error)
+	MarkReadUpTo(ctx context.Context, recipientID, senderID string, upTo MessageCursor, at time.Time) (int64, error)
+	MarkConversationAsRead(ctx context.Context, fromUserID, toUserID string) (int64, error)
+	GetUnreadCount(ctx context.Context, userID string) (int64, error)
+	GetConversationUnreadCount(ctx context.Context, fromUserID, toUserID string) (int64, error)
+	CountUnreadBySender(ctx context.Context, userID string) (map[string]int64, error)
+}
+
+// MessageCursor 消息分页位置，即上一页最后一条消息的(created_at, _id)
+type MessageCursor struct {
+	CreatedAt time.Time
+	ID        string // 消息ID（ObjectID的十六进制）
+}
+
+// GroupMessageRepository 群聊消息Repository接口，群消息只保存一份，按群ID查询
+type GroupMessageRepository interface {
+	Create(ctx context.Context, message *mongodb.ChatMessage) error
+	GetByID(ctx context.Context, messageID string) (*mongodb.ChatMessage, error)
+	GetGroupMessages(ctx context.Context, groupID string, before *MessageCursor, limit int) ([]*mongodb.ChatMessage, error)
+	CountGroupUnread(ctx context.Context, groupID, userID string, after *MessageCursor) (int64, error)
+}
+
+var (
+	// ErrGroupFull 群成员数已达上限（GroupRepository.AddMembers）
+	ErrGroupFull = errors.New("group member limit reached")
+	// ErrGroupMemberExists 用户已经是群成员（GroupRepository.AddMembers）
+	ErrGroupMemberExists = errors.New("user is already a group member")
+)
+
+// GroupRepository 群聊和群成员Repository接口
+// 群聊或成员不存在时返回的错误链中包含mongo.ErrNoDocuments
+type GroupRepository interface {
+	CreateGroup(ctx context.Context, group *mongodb.ChatGroup, members []*mongodb.ChatGroupMember) error
+	GetGroup(ctx context.Context, groupID string) (*mongodb.ChatGroup, error)
+	GetGroupsByIDs(ctx context.Context, groupIDs []string) ([]*mongodb.ChatGroup, error)
+	CountCreatedGroups(ctx context.Context, userID string) (int64, error)
+	UpdateGroup(ctx context.Context, groupID, name, description string, at time.Time) error
+	SetOwner(ctx context.Context, groupID, ownerID string, at time.Time) error
+	DeleteGroup(ctx context.Context, groupID string) error
+	GetMember(ctx context.Context, groupID, userID string) (*mongodb.ChatGroupMember, error)
+	ListMembers(ctx context.Context, groupID string) ([]*mongodb.ChatGroupMember, error)
+	ListMemberships(ctx context.Context, userID string) ([]*mongodb.ChatGroupMember, error)
+	AddMembers(ctx context.Context, groupID string, members []*mongodb.ChatGroupMember, maxMembers int) error
+	RemoveMember(ctx context.Context, groupID, userID string) error
+	SetMemberRole(ctx context.Context, groupID, userID string, role mongodb.GroupRole) error
+	UpdateReadCursor(ctx context.Context, groupID, userID string, cursor MessageCursor) error
+}
+
 // MessageRepository 消息Repository接口
 type MessageRepository interface {
 	Create(ctx context.Context, message *mongodb.ChatMessage) error
@@ -81,6 +284,37 @@ type MessageRepository interface {
 	CountByRoomID(ctx context.Context, roomID string) (int64, error)
 }
 
+// ArchiveConversation 单独设置了归档策略的会话，GroupID为空时为UserID1和UserID2之间的单聊
+type ArchiveConversation struct {
+	GroupID string
+	UserID1 string
+	UserID2 string
+}
+
+// MessageArchiveScope 消息归档的范围：早于Before、不涉及保全用户的消息
+// Only不为空时只匹配该会话，否则匹配除Exclude以外的所有会话
+type MessageArchiveScope struct {
+	Before      time.Time
+	Only        *ArchiveConversation
+	Exclude     []ArchiveConversation
+	HeldUserIDs []string
+}
+
+// MessageArchiveRepository 消息归档Repository接口，热集合chat_messages中的旧消息复制到冷集合后删除
+type MessageArchiveRepository interface {
+	CountArchivable(ctx context.Context, scope MessageArchiveScope) (int64, error)
+	FindArchivable(ctx context.Context, scope MessageArchiveScope, limit int) ([]*mongodb.ChatMessage, error)
+	SaveArchived(ctx context.Context, messages []*mongodb.ChatMessage) error
+	DeleteArchived(ctx context.Context, messages []*mongodb.ChatMessage) (int64, error)
+}
+
+// UserMessageRepository 按用户查询消息的Repository接口（管理端用户概览）
+type UserMessageRepository interface {
+	GetUserMessages(ctx context.Context, userID string, limit, offset int) ([]*mongodb.ChatMessage, error)
+	GetMessageStats(ctx context.Context, userID string) (map[string]interface{}, error)
+	CountPerDay(ctx context.Context, since time.Time) (map[string]int64, error) // 按UTC日期统计消息数（管理端仪表板）
+}
+
 // CacheRepository 缓存Repository接口
 type CacheRepository interface {
 	Set(key string, value interface{}, expiration time.Duration) error
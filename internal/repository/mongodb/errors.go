@@ -0,0 +1,20 @@
+package mongodb
+
+import "go.mongodb.org/mongo-driver/mongo"
+
+// notFoundError 文档不存在错误，错误链中包含mongo.ErrNoDocuments，便于上层按分类转换
+type notFoundError struct {
+	msg string
+}
+
+func (e *notFoundError) Error() string { return e.msg }
+func (e *notFoundError) Unwrap() error { return mongo.ErrNoDocuments }
+
+// ErrMessageNotFound 消息不存在
+var ErrMessageNotFound error = &notFoundError{msg: "message not found"}
+
+// ErrGroupNotFound 群聊不存在
+var ErrGroupNotFound error = &notFoundError{msg: "group not found"}
+
+// ErrGroupMemberNotFound 用户不是群成员
+var ErrGroupMemberNotFound error = &notFoundError{msg: "group member not found"}
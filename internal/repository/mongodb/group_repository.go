@@ -0,0 +1,370 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"exchange/internal/models/mongodb"
+	"exchange/internal/pkg/database"
+	"exchange/internal/repository"
+)
+
+// GroupRepository MongoDB群聊Repository实现
+// 群资料和成员分别保存，成员数保存在群资料中，加入成员前原子地占用名额，避免并发加入超过上限
+type GroupRepository struct {
+	db *database.MongoDBService
+}
+
+// NewGroupRepository 创建群聊Repository
+func NewGroupRepository(db *database.MongoDBService) *GroupRepository {
+	return &GroupRepository{db: db}
+}
+
+// groups 群资料集合
+func (r *GroupRepository) groups() *mongo.Collection {
+	return r.db.Collection(mongodb.ChatGroup{}.CollectionName())
+}
+
+// members 群成员集合
+func (r *GroupRepository) members() *mongo.Collection {
+	return r.db.Collection(mongodb.ChatGroupMember{}.CollectionName())
+}
+
+// CreateGroup 创建群聊和初始成员，group.MemberCount按members设置
+// 写入成员失败时删除已创建的群
+func (r *GroupRepository) CreateGroup(ctx context.Context, group *mongodb.ChatGroup, members []*mongodb.ChatGroupMember) error {
+	group.MemberCount = len(members)
+	result, err := r.groups().InsertOne(ctx, group)
+	if err != nil {
+		return fmt.Errorf("failed to create group: %w", err)
+	}
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		group.ID = oid
+	}
+
+	for _, member := range members {
+		member.GroupID = group.ID.Hex()
+	}
+	if err := r.insertMembers(ctx, members); err != nil {
+		if _, cleanupErr := r.groups().DeleteOne(ctx, bson.M{"_id": group.ID}); cleanupErr != nil {
+			return fmt.Errorf("failed to create group members: %w (cleanup failed: %v)", err, cleanupErr)
+		}
+		return fmt.Errorf("failed to create group members: %w", err)
+	}
+
+	return nil
+}
+
+// GetGroup 根据ID获取群聊
+func (r *GroupRepository) GetGroup(ctx context.Context, groupID string) (*mongodb.ChatGroup, error) {
+	oid, err := primitive.ObjectIDFromHex(groupID)
+	if err != nil {
+		return nil, ErrGroupNotFound
+	}
+
+	var group mongodb.ChatGroup
+	if err := r.groups().FindOne(ctx, bson.M{"_id": oid}).Decode(&group); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrGroupNotFound
+		}
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+
+	return &group, nil
+}
+
+// GetGroupsByIDs 批量获取群聊，不存在的ID被忽略
+func (r *GroupRepository) GetGroupsByIDs(ctx context.Context, groupIDs []string) ([]*mongodb.ChatGroup, error) {
+	oids := make([]primitive.ObjectID, 0, len(groupIDs))
+	for _, id := range groupIDs {
+		if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+			oids = append(oids, oid)
+		}
+	}
+	if len(oids) == 0 {
+		return nil, nil
+	}
+
+	var groups []*mongodb.ChatGroup
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}})
+	if err := r.db.Find(mongodb.ChatGroup{}.CollectionName(), bson.M{"_id": bson.M{"$in": oids}}, &groups, opts); err != nil {
+		return nil, fmt.Errorf("failed to get groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// CountCreatedGroups 统计用户创建的群聊数
+func (r *GroupRepository) CountCreatedGroups(ctx context.Context, userID string) (int64, error) {
+	count, err := r.groups().CountDocuments(ctx, bson.M{"creator_id": userID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count groups: %w", err)
+	}
+	return count, nil
+}
+
+// UpdateGroup 修改群名称和简介
+func (r *GroupRepository) UpdateGroup(ctx context.Context, groupID, name, description string, at time.Time) error {
+	return r.updateGroup(ctx, groupID, bson.M{"name": name, "description": description, "updated_at": at})
+}
+
+// SetOwner 修改群主
+func (r *GroupRepository) SetOwner(ctx context.Context, groupID, ownerID string, at time.Time) error {
+	return r.updateGroup(ctx, groupID, bson.M{"owner_id": ownerID, "updated_at": at})
+}
+
+// updateGroup 修改群资料字段
+func (r *GroupRepository) updateGroup(ctx context.Context, groupID string, fields bson.M) error {
+	oid, err := primitive.ObjectIDFromHex(groupID)
+	if err != nil {
+		return ErrGroupNotFound
+	}
+
+	result, err := r.groups().UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$set": fields})
+	if err != nil {
+		return fmt.Errorf("failed to update group: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrGroupNotFound
+	}
+
+	return nil
+}
+
+// DeleteGroup 删除群聊和全部成员，群消息保留（按数据保留策略清理）
+func (r *GroupRepository) DeleteGroup(ctx context.Context, groupID string) error {
+	oid, err := primitive.ObjectIDFromHex(groupID)
+	if err != nil {
+		return ErrGroupNotFound
+	}
+
+	result, err := r.groups().DeleteOne(ctx, bson.M{"_id": oid})
+	if err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrGroupNotFound
+	}
+
+	if _, err := r.members().DeleteMany(ctx, bson.M{"group_id": groupID}); err != nil {
+		return fmt.Errorf("failed to delete group members: %w", err)
+	}
+
+	return nil
+}
+
+// GetMember 获取群成员
+func (r *GroupRepository) GetMember(ctx context.Context, groupID, userID string) (*mongodb.ChatGroupMember, error) {
+	var member mongodb.ChatGroupMember
+	err := r.members().FindOne(ctx, bson.M{"group_id": groupID, "user_id": userID}).Decode(&member)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrGroupMemberNotFound
+		}
+		return nil, fmt.Errorf("failed to get group member: %w", err)
+	}
+
+	return &member, nil
+}
+
+// ListMembers 获取群成员列表，按加入时间排列
+func (r *GroupRepository) ListMembers(ctx context.Context, groupID string) ([]*mongodb.ChatGroupMember, error) {
+	var members []*mongodb.ChatGroupMember
+	opts := options.Find().SetSort(bson.D{{Key: "joined_at", Value: 1}, {Key: "_id", Value: 1}})
+	if err := r.db.Find(mongodb.ChatGroupMember{}.CollectionName(), bson.M{"group_id": groupID}, &members, opts); err != nil {
+		return nil, fmt.Errorf("failed to list group members: %w", err)
+	}
+
+	return members, nil
+}
+
+// ListMemberships 获取用户加入的全部群的成员记录
+func (r *GroupRepository) ListMemberships(ctx context.Context, userID string) ([]*mongodb.ChatGroupMember, error) {
+	var members []*mongodb.ChatGroupMember
+	if err := r.db.Find(mongodb.ChatGroupMember{}.CollectionName(), bson.M{"user_id": userID}, &members); err != nil {
+		return nil, fmt.Errorf("failed to list group memberships: %w", err)
+	}
+
+	return members, nil
+}
+
+// AddMembers 加入成员
+// 先在成员数不超过maxMembers的条件下原子地增加成员数，超过时返回repository.ErrGroupFull；
+// 写入失败的成员释放占用的名额，已是成员时返回repository.ErrGroupMemberExists
+func (r *GroupRepository) AddMembers(ctx context.Context, groupID string, members []*mongodb.ChatGroupMember, maxMembers int) error {
+	if len(members) == 0 {
+		return nil
+	}
+	oid, err := primitive.ObjectIDFromHex(groupID)
+	if err != nil {
+		return ErrGroupNotFound
+	}
+
+	n := len(members)
+	result, err := r.groups().UpdateOne(ctx,
+		bson.M{"_id": oid, "member_count": bson.M{"$lte": maxMembers - n}},
+		bson.M{"$inc": bson.M{"member_count": n}})
+	if err != nil {
+		return fmt.Errorf("failed to reserve group seats: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.GetGroup(ctx, groupID); err != nil {
+			return err
+		}
+		return repository.ErrGroupFull
+	}
+
+	for _, member := range members {
+		member.GroupID = groupID
+	}
+	inserted, err := r.insertMemberCount(ctx, members)
+	if err != nil {
+		if released := n - inserted; released > 0 {
+			if _, releaseErr := r.groups().UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$inc": bson.M{"member_count": -released}}); releaseErr != nil {
+				return fmt.Errorf("failed to add group members: %w (release seats failed: %v)", err, releaseErr)
+			}
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return repository.ErrGroupMemberExists
+		}
+		return fmt.Errorf("failed to add group members: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveMember 移除群成员并减少成员数
+func (r *GroupRepository) RemoveMember(ctx context.Context, groupID, userID string) error {
+	result, err := r.members().DeleteOne(ctx, bson.M{"group_id": groupID, "user_id": userID})
+	if err != nil {
+		return fmt.Errorf("failed to remove group member: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrGroupMemberNotFound
+	}
+
+	oid, err := primitive.ObjectIDFromHex(groupID)
+	if err != nil {
+		return ErrGroupNotFound
+	}
+	if _, err := r.groups().UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$inc": bson.M{"member_count": -1}}); err != nil {
+		return fmt.Errorf("failed to update group member count: %w", err)
+	}
+
+	return nil
+}
+
+// SetMemberRole 修改成员角色
+func (r *GroupRepository) SetMemberRole(ctx context.Context, groupID, userID string, role mongodb.GroupRole) error {
+	result, err := r.members().UpdateOne(ctx,
+		bson.M{"group_id": groupID, "user_id": userID},
+		bson.M{"$set": bson.M{"role": role}})
+	if err != nil {
+		return fmt.Errorf("failed to update group member role: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrGroupMemberNotFound
+	}
+
+	return nil
+}
+
+// UpdateReadCursor 更新成员的已读位置，只向后移动；已读位置已在cursor之后时不修改
+func (r *GroupRepository) UpdateReadCursor(ctx context.Context, groupID, userID string, cursor repository.MessageCursor) error {
+	filter := bson.M{
+		"group_id": groupID,
+		"user_id":  userID,
+		"$or": []bson.M{
+			{"last_read_at": bson.M{"$exists": false}},
+			{"last_read_at": bson.M{"$lt": cursor.CreatedAt}},
+			{"last_read_at": cursor.CreatedAt, "last_read_message_id": bson.M{"$lt": cursor.ID}},
+		},
+	}
+	update := bson.M{"$set": bson.M{
+		"last_read_message_id": cursor.ID,
+		"last_read_at":         cursor.CreatedAt,
+	}}
+
+	result, err := r.members().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update read cursor: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		// 区分已读位置更靠后和不是群成员
+		if _, err := r.GetMember(ctx, groupID, userID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// insertMembers 写入成员
+func (r *GroupRepository) insertMembers(ctx context.Context, members []*mongodb.ChatGroupMember) error {
+	_, err := r.insertMemberCount(ctx, members)
+	return err
+}
+
+// insertMemberCount 写入成员（遇到重复成员时继续写入其余成员），返回成功写入的数量
+func (r *GroupRepository) insertMemberCount(ctx context.Context, members []*mongodb.ChatGroupMember) (int, error) {
+	docs := make([]interface{}, len(members))
+	for i, member := range members {
+		docs[i] = member
+	}
+
+	result, err := r.members().InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err != nil {
+		// 批量写入错误中只有写入失败的文档，其他错误无法确定写入了多少，按全部失败处理
+		var bulkErr mongo.BulkWriteException
+		if errors.As(err, &bulkErr) {
+			return len(members) - len(bulkErr.WriteErrors), err
+		}
+		return 0, err
+	}
+
+	for i, id := range result.InsertedIDs {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			members[i].ID = oid
+		}
+	}
+	return len(members), nil
+}
+
+// CreateIndexes 创建群成员集合的索引
+func (r *GroupRepository) CreateIndexes(ctx context.Context) error {
+	collectionName := mongodb.ChatGroupMember{}.CollectionName()
+
+	// 创建唯一索引：group_id + user_id（同一用户在群中只有一条成员记录）
+	_, err := r.db.CreateIndex(collectionName, bson.D{
+		{Key: "group_id", Value: 1},
+		{Key: "user_id", Value: 1},
+	}, options.Index().SetUnique(true))
+	if err != nil {
+		return fmt.Errorf("failed to create group member index: %w", err)
+	}
+
+	// 创建用户索引：user_id（用户加入的群列表）
+	_, err = r.db.CreateIndex(collectionName, bson.D{
+		{Key: "user_id", Value: 1},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create group membership index: %w", err)
+	}
+
+	// 创建创建者索引：creator_id（统计每个用户创建的群数）
+	_, err = r.db.CreateIndex(mongodb.ChatGroup{}.CollectionName(), bson.D{
+		{Key: "creator_id", Value: 1},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create group creator index: %w", err)
+	}
+
+	return nil
+}
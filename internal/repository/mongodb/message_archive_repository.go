@@ -0,0 +1,142 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"exchange/internal/models/mongodb"
+	"exchange/internal/pkg/database"
+	"exchange/internal/repository"
+)
+
+// MongoDB重复键错误码
+const duplicateKeyCode = 11000
+
+// MessageArchiveRepository MongoDB消息归档Repository实现
+type MessageArchiveRepository struct {
+	db *database.MongoDBService
+}
+
+// NewMessageArchiveRepository 创建消息归档Repository
+func NewMessageArchiveRepository(db *database.MongoDBService) *MessageArchiveRepository {
+	return &MessageArchiveRepository{db: db}
+}
+
+// CountArchivable 统计范围内待归档的消息数
+func (r *MessageArchiveRepository) CountArchivable(ctx context.Context, scope repository.MessageArchiveScope) (int64, error) {
+	count, err := r.db.Collection(mongodb.ChatMessage{}.CollectionName()).CountDocuments(ctx, archiveFilter(scope))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count archivable messages: %w", err)
+	}
+	return count, nil
+}
+
+// FindArchivable 按_id顺序获取范围内最多limit条待归档的消息
+func (r *MessageArchiveRepository) FindArchivable(ctx context.Context, scope repository.MessageArchiveScope, limit int) ([]*mongodb.ChatMessage, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.db.Collection(mongodb.ChatMessage{}.CollectionName()).Find(ctx, archiveFilter(scope), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find archivable messages: %w", err)
+	}
+
+	var messages []*mongodb.ChatMessage
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, fmt.Errorf("failed to decode archivable messages: %w", err)
+	}
+	return messages, nil
+}
+
+// SaveArchived 将消息写入冷集合
+// 上次归档写入后删除前中断时消息会再次写入，已存在的消息（重复的_id）忽略
+func (r *MessageArchiveRepository) SaveArchived(ctx context.Context, messages []*mongodb.ChatMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	documents := make([]interface{}, len(messages))
+	for i, message := range messages {
+		documents[i] = message
+	}
+
+	_, err := r.db.Collection(mongodb.ArchivedMessageCollection).InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	if err != nil && !onlyDuplicateKeys(err) {
+		return fmt.Errorf("failed to save archived messages: %w", err)
+	}
+	return nil
+}
+
+// DeleteArchived 从热集合删除已归档的消息，返回删除数量
+func (r *MessageArchiveRepository) DeleteArchived(ctx context.Context, messages []*mongodb.ChatMessage) (int64, error) {
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]primitive.ObjectID, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID
+	}
+
+	result, err := r.db.Collection(mongodb.ChatMessage{}.CollectionName()).DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete archived messages: %w", err)
+	}
+	return result.DeletedCount, nil
+}
+
+// archiveFilter 构建归档范围的查询条件
+func archiveFilter(scope repository.MessageArchiveScope) bson.M {
+	conditions := []bson.M{{"created_at": bson.M{"$lt": scope.Before}}}
+
+	if scope.Only != nil {
+		conditions = append(conditions, conversationFilter(*scope.Only))
+	} else if len(scope.Exclude) > 0 {
+		excluded := make([]bson.M, len(scope.Exclude))
+		for i, conversation := range scope.Exclude {
+			excluded[i] = conversationFilter(conversation)
+		}
+		conditions = append(conditions, bson.M{"$nor": excluded})
+	}
+
+	if len(scope.HeldUserIDs) > 0 {
+		conditions = append(conditions, bson.M{
+			"from_user_id": bson.M{"$nin": scope.HeldUserIDs},
+			"to_user_id":   bson.M{"$nin": scope.HeldUserIDs},
+		})
+	}
+
+	return bson.M{"$and": conditions}
+}
+
+// conversationFilter 匹配单个会话的消息
+func conversationFilter(conversation repository.ArchiveConversation) bson.M {
+	if conversation.GroupID != "" {
+		return bson.M{"group_id": conversation.GroupID}
+	}
+	return bson.M{"$or": []bson.M{
+		{"from_user_id": conversation.UserID1, "to_user_id": conversation.UserID2},
+		{"from_user_id": conversation.UserID2, "to_user_id": conversation.UserID1},
+	}}
+}
+
+// onlyDuplicateKeys 批量写入的错误是否全部为重复键
+func onlyDuplicateKeys(err error) bool {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil || len(bulkErr.WriteErrors) == 0 {
+		return false
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Code != duplicateKeyCode {
+			return false
+		}
+	}
+	return true
+}
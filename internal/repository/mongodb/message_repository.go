@@ -2,15 +2,19 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"exchange/internal/models/mongodb"
+	"exchange/internal/pkg/clock"
 	"exchange/internal/pkg/database"
+	"exchange/internal/repository"
 )
 
 // MessageRepository MongoDB消息Repository实现
@@ -55,8 +59,11 @@ func (r *MessageRepository) CountDocuments(ctx context.Context, filter interface
 
 // Create 创建消息
 func (r *MessageRepository) Create(ctx context.Context, message *mongodb.ChatMessage) error {
-	// 设置时间戳
+	// 设置时间戳，单聊消息的初始状态为已发送
 	message.SetTimestamps()
+	if message.Status == "" && !message.IsGroupMessage() {
+		message.Status = mongodb.MessageStatusSent
+	}
 
 	// 验证消息
 	if err := message.Validate(); err != nil {
@@ -87,8 +94,11 @@ func (r *MessageRepository) GetByID(ctx context.Context, messageID string) (*mon
 	filter := bson.M{"_id": oid}
 	var message mongodb.ChatMessage
 
-	err = r.db.FindOne(message.CollectionName(), filter, &message)
+	err = r.db.Collection(message.CollectionName()).FindOne(ctx, filter).Decode(&message)
 	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrMessageNotFound
+		}
 		return nil, fmt.Errorf("failed to get message: %w", err)
 	}
 
@@ -101,7 +111,7 @@ func (r *MessageRepository) Update(ctx context.Context, message *mongodb.ChatMes
 		return fmt.Errorf("message validation failed: %w", err)
 	}
 
-	message.UpdatedAt = time.Now()
+	message.UpdatedAt = clock.Now()
 
 	filter := bson.M{"_id": message.ID}
 	update := bson.M{"$set": message}
@@ -112,7 +122,7 @@ func (r *MessageRepository) Update(ctx context.Context, message *mongodb.ChatMes
 	}
 
 	if result.ModifiedCount == 0 {
-		return fmt.Errorf("message not found")
+		return ErrMessageNotFound
 	}
 
 	return nil
@@ -132,7 +142,71 @@ func (r *MessageRepository) Delete(ctx context.Context, messageID string) error
 	}
 
 	if result.DeletedCount == 0 {
-		return fmt.Errorf("message not found")
+		return ErrMessageNotFound
+	}
+
+	return nil
+}
+
+// EditMessage 修改消息内容，被替换的内容追加到修改历史
+// previousContent为读取时的内容，消息在此期间已被修改或删除时返回ErrMessageNotFound
+func (r *MessageRepository) EditMessage(ctx context.Context, messageID, previousContent, content string, at time.Time) error {
+	oid, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return fmt.Errorf("invalid message ID: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":        oid,
+		"content":    previousContent,
+		"deleted_at": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"content":    content,
+			"edited_at":  at,
+			"updated_at": at,
+		},
+		"$push": bson.M{
+			"edit_history": mongodb.MessageEdit{Content: previousContent, EditedAt: at},
+		},
+	}
+
+	result, err := r.db.Collection(mongodb.ChatMessage{}.CollectionName()).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to edit message: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrMessageNotFound
+	}
+
+	return nil
+}
+
+// SoftDelete 标记消息为已删除，保留原内容和修改历史
+func (r *MessageRepository) SoftDelete(ctx context.Context, messageID string, at time.Time) error {
+	oid, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return fmt.Errorf("invalid message ID: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":        oid,
+		"deleted_at": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"deleted_at": at,
+			"updated_at": at,
+		},
+	}
+
+	result, err := r.db.Collection(mongodb.ChatMessage{}.CollectionName()).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to delete message: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrMessageNotFound
 	}
 
 	return nil
@@ -154,21 +228,29 @@ func (r *MessageRepository) List(ctx context.Context, limit, offset int) ([]*mon
 	return messages, nil
 }
 
-// GetConversationMessages 获取会话消息
-func (r *MessageRepository) GetConversationMessages(ctx context.Context, userID1, userID2 string, limit, offset int) ([]*mongodb.ChatMessage, error) {
+// GetConversationMessages 获取会话消息，按(created_at, _id)倒序，before为nil时从最新的消息开始
+// 使用游标定位而不是跳过前面的记录，翻页的耗时与消息所在位置无关；_id保证同一时间的消息顺序稳定
+func (r *MessageRepository) GetConversationMessages(ctx context.Context, userID1, userID2 string, before *repository.MessageCursor, limit int) ([]*mongodb.ChatMessage, error) {
 	// 构建查询条件：双向消息
-	filter := bson.M{
-		"$or": []bson.M{
+	conditions := []bson.M{
+		{"$or": []bson.M{
 			{"from_user_id": userID1, "to_user_id": userID2},
 			{"from_user_id": userID2, "to_user_id": userID1},
-		},
+		}},
 	}
+	if before != nil {
+		condition, err := cursorCondition(before, "$lt")
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+	filter := bson.M{"$and": conditions}
 
-	// 设置查询选项：按时间倒序，分页
+	// 设置查询选项：按时间倒序，_id作为同一时间的消息的次序
 	opts := options.Find().
-		SetSort(bson.D{{Key: "created_at", Value: -1}}).
-		SetLimit(int64(limit)).
-		SetSkip(int64(offset))
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
 
 	var messages []*mongodb.ChatMessage
 	err := r.db.Find(mongodb.ChatMessage{}.CollectionName(), filter, &messages, opts)
@@ -179,6 +261,66 @@ func (r *MessageRepository) GetConversationMessages(ctx context.Context, userID1
 	return messages, nil
 }
 
+// GetGroupMessages 获取群聊消息，排序和游标规则与GetConversationMessages相同
+func (r *MessageRepository) GetGroupMessages(ctx context.Context, groupID string, before *repository.MessageCursor, limit int) ([]*mongodb.ChatMessage, error) {
+	conditions := []bson.M{{"group_id": groupID}}
+	if before != nil {
+		condition, err := cursorCondition(before, "$lt")
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+	filter := bson.M{"$and": conditions}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+
+	var messages []*mongodb.ChatMessage
+	err := r.db.Find(mongodb.ChatMessage{}.CollectionName(), filter, &messages, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// CountGroupUnread 统计成员的群聊未读消息数：已读位置之后其他成员发送且未删除的消息，after为nil时统计全部
+func (r *MessageRepository) CountGroupUnread(ctx context.Context, groupID, userID string, after *repository.MessageCursor) (int64, error) {
+	conditions := []bson.M{{
+		"group_id":     groupID,
+		"from_user_id": bson.M{"$ne": userID},
+		"deleted_at":   bson.M{"$exists": false},
+	}}
+	if after != nil {
+		condition, err := cursorCondition(after, "$gt")
+		if err != nil {
+			return 0, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	count, err := r.db.Collection(mongodb.ChatMessage{}.CollectionName()).CountDocuments(ctx, bson.M{"$and": conditions})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count group unread messages: %w", err)
+	}
+
+	return count, nil
+}
+
+// cursorCondition 构建游标位置的查询条件，op为$lt时匹配游标之前（更早）的消息，为$gt时匹配之后的消息
+func cursorCondition(cursor *repository.MessageCursor, op string) (bson.M, error) {
+	oid, err := primitive.ObjectIDFromHex(cursor.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message cursor: %w", err)
+	}
+	return bson.M{"$or": []bson.M{
+		{"created_at": bson.M{op: cursor.CreatedAt}},
+		{"created_at": cursor.CreatedAt, "_id": bson.M{op: oid}},
+	}}, nil
+}
+
 // GetUserMessages 获取用户的所有消息
 func (r *MessageRepository) GetUserMessages(ctx context.Context, userID string, limit, offset int) ([]*mongodb.ChatMessage, error) {
 	filter := bson.M{
@@ -209,15 +351,8 @@ func (r *MessageRepository) MarkAsRead(ctx context.Context, messageID string) er
 		return fmt.Errorf("invalid message ID: %w", err)
 	}
 
-	filter := bson.M{"_id": oid}
-	update := bson.M{
-		"$set": bson.M{
-			"is_read":    true,
-			"updated_at": time.Now(),
-		},
-	}
-
-	result, err := r.db.UpdateOne(mongodb.ChatMessage{}.CollectionName(), filter, update)
+	filter := bson.M{"_id": oid, "is_read": false}
+	result, err := r.db.Collection(mongodb.ChatMessage{}.CollectionName()).UpdateOne(ctx, filter, readUpdate(clock.Now()))
 	if err != nil {
 		return fmt.Errorf("failed to mark message as read: %w", err)
 	}
@@ -237,21 +372,103 @@ func (r *MessageRepository) MarkConversationAsRead(ctx context.Context, fromUser
 		"is_read":      false,
 	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"is_read":    true,
-			"updated_at": time.Now(),
+	result, err := r.db.Collection(mongodb.ChatMessage{}.CollectionName()).UpdateMany(ctx, filter, readUpdate(clock.Now()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark conversation as read: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// MarkDelivered 将接收方为recipientID且尚未投递的消息标记为已投递，返回本次标记的消息
+// 已读的消息同时记录了投递时间，不会回退为已投递
+func (r *MessageRepository) MarkDelivered(ctx context.Context, recipientID string, messageIDs []string, at time.Time) ([]*mongodb.ChatMessage, error) {
+	oids := make([]primitive.ObjectID, 0, len(messageIDs))
+	for _, id := range messageIDs {
+		oid, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid message ID: %w", err)
+		}
+		oids = append(oids, oid)
+	}
+	if len(oids) == 0 {
+		return nil, nil
+	}
+
+	filter := bson.M{
+		"_id":          bson.M{"$in": oids},
+		"to_user_id":   recipientID,
+		"delivered_at": bson.M{"$exists": false},
+		"is_read":      false,
+	}
+	var messages []*mongodb.ChatMessage
+	if err := r.db.Find(mongodb.ChatMessage{}.CollectionName(), filter, &messages); err != nil {
+		return nil, fmt.Errorf("failed to find undelivered messages: %w", err)
+	}
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	found := make([]primitive.ObjectID, len(messages))
+	for i, message := range messages {
+		found[i] = message.ID
+	}
+	filter["_id"] = bson.M{"$in": found}
+	update := bson.M{"$set": bson.M{
+		"status":       mongodb.MessageStatusDelivered,
+		"delivered_at": at,
+		"updated_at":   at,
+	}}
+	if _, err := r.db.Collection(mongodb.ChatMessage{}.CollectionName()).UpdateMany(ctx, filter, update); err != nil {
+		return nil, fmt.Errorf("failed to mark messages as delivered: %w", err)
+	}
+
+	for _, message := range messages {
+		message.Status = mongodb.MessageStatusDelivered
+		message.DeliveredAt = &at
+		message.UpdatedAt = at
+	}
+	return messages, nil
+}
+
+// MarkReadUpTo 将senderID发给recipientID、位置不晚于upTo的未读消息标记为已读，返回标记的数量
+func (r *MessageRepository) MarkReadUpTo(ctx context.Context, recipientID, senderID string, upTo repository.MessageCursor, at time.Time) (int64, error) {
+	oid, err := primitive.ObjectIDFromHex(upTo.ID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid message cursor: %w", err)
+	}
+
+	filter := bson.M{
+		"from_user_id": senderID,
+		"to_user_id":   recipientID,
+		"is_read":      false,
+		"$or": []bson.M{
+			{"created_at": bson.M{"$lt": upTo.CreatedAt}},
+			{"created_at": upTo.CreatedAt, "_id": bson.M{"$lte": oid}},
 		},
 	}
 
-	result, err := r.db.UpdateMany(mongodb.ChatMessage{}.CollectionName(), filter, update)
+	result, err := r.db.Collection(mongodb.ChatMessage{}.CollectionName()).UpdateMany(ctx, filter, readUpdate(at))
 	if err != nil {
-		return 0, fmt.Errorf("failed to mark conversation as read: %w", err)
+		return 0, fmt.Errorf("failed to mark messages as read: %w", err)
 	}
 
 	return result.ModifiedCount, nil
 }
 
+// readUpdate 标记已读的更新（聚合管道），未记录投递时间的消息同时以已读时间作为投递时间
+func readUpdate(at time.Time) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"is_read":      true,
+			"status":       mongodb.MessageStatusRead,
+			"read_at":      at,
+			"delivered_at": bson.M{"$ifNull": bson.A{"$delivered_at", at}},
+			"updated_at":   at,
+		}}},
+	}
+}
+
 // GetUnreadCount 获取用户未读消息数量
 func (r *MessageRepository) GetUnreadCount(ctx context.Context, userID string) (int64, error) {
 	filter := bson.M{
@@ -283,6 +500,33 @@ func (r *MessageRepository) GetConversationUnreadCount(ctx context.Context, from
 	return count, nil
 }
 
+// CountUnreadBySender 按发送者统计用户的单聊未读消息数，没有未读消息的会话不返回
+func (r *MessageRepository) CountUnreadBySender(ctx context.Context, userID string) (map[string]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"to_user_id": userID, "is_read": false}}},
+		{{Key: "$group", Value: bson.M{"_id": "$from_user_id", "count": bson.M{"$sum": 1}}}},
+	}
+
+	cursor, err := r.db.Collection(mongodb.ChatMessage{}.CollectionName()).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread messages by sender: %w", err)
+	}
+
+	var rows []struct {
+		SenderID string `bson:"_id"`
+		Count    int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("failed to decode unread message counts: %w", err)
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.SenderID] = row.Count
+	}
+	return counts, nil
+}
+
 // GetMessagesByTimeRange 根据时间范围获取消息
 func (r *MessageRepository) GetMessagesByTimeRange(ctx context.Context, userID1, userID2 string, startTime, endTime time.Time) ([]*mongodb.ChatMessage, error) {
 	filter := bson.M{
@@ -307,6 +551,49 @@ func (r *MessageRepository) GetMessagesByTimeRange(ctx context.Context, userID1,
 	return messages, nil
 }
 
+// ForEachConversationMessage 按时间顺序遍历两个用户之间的消息，to为零值时不限截止时间
+// 使用游标逐条读取，fn返回错误时停止遍历并返回该错误
+func (r *MessageRepository) ForEachConversationMessage(ctx context.Context, userID1, userID2 string, from, to time.Time, fn func(message *mongodb.ChatMessage) error) error {
+	filter := bson.M{
+		"$or": []bson.M{
+			{"from_user_id": userID1, "to_user_id": userID2},
+			{"from_user_id": userID2, "to_user_id": userID1},
+		},
+	}
+	timeRange := bson.M{}
+	if !from.IsZero() {
+		timeRange["$gte"] = from
+	}
+	if !to.IsZero() {
+		timeRange["$lte"] = to
+	}
+	if len(timeRange) > 0 {
+		filter["created_at"] = timeRange
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.db.Collection(mongodb.ChatMessage{}.CollectionName()).Find(ctx, filter, opts)
+	if err != nil {
+		return fmt.Errorf("failed to query conversation messages: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var message mongodb.ChatMessage
+		if err := cursor.Decode(&message); err != nil {
+			return fmt.Errorf("failed to decode message: %w", err)
+		}
+		if err := fn(&message); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to iterate conversation messages: %w", err)
+	}
+
+	return nil
+}
+
 // GetMessageStats 获取消息统计信息
 func (r *MessageRepository) GetMessageStats(ctx context.Context, userID string) (map[string]interface{}, error) {
 	pipeline := []bson.M{
@@ -379,20 +666,58 @@ func (r *MessageRepository) GetMessageStats(ctx context.Context, userID string)
 	return stats, nil
 }
 
+// CountPerDay 按UTC日期统计since之后发送的消息数，返回日期(2006-01-02)到数量，没有消息的日期不返回
+func (r *MessageRepository) CountPerDay(ctx context.Context, since time.Time) (map[string]int64, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"created_at": bson.M{"$gte": since}}},
+		{
+			"$group": bson.M{
+				"_id":   bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$created_at"}},
+				"count": bson.M{"$sum": 1},
+			},
+		},
+	}
+
+	var results []struct {
+		Day   string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err := r.db.Aggregate(mongodb.ChatMessage{}.CollectionName(), pipeline, &results); err != nil {
+		return nil, fmt.Errorf("failed to count messages per day: %w", err)
+	}
+
+	counts := make(map[string]int64, len(results))
+	for _, result := range results {
+		counts[result.Day] = result.Count
+	}
+	return counts, nil
+}
+
 // CreateIndexes 创建消息集合的索引
 func (r *MessageRepository) CreateIndexes(ctx context.Context) error {
 	collectionName := mongodb.ChatMessage{}.CollectionName()
 
-	// 创建复合索引：from_user_id + to_user_id + created_at
+	// 创建复合索引：from_user_id + to_user_id + created_at + _id（会话消息按游标分页）
 	_, err := r.db.CreateIndex(collectionName, bson.D{
 		{Key: "from_user_id", Value: 1},
 		{Key: "to_user_id", Value: 1},
 		{Key: "created_at", Value: -1},
+		{Key: "_id", Value: -1},
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create conversation index: %w", err)
 	}
 
+	// 创建群聊消息索引：group_id + created_at + _id（群消息按游标分页和统计未读数）
+	_, err = r.db.CreateIndex(collectionName, bson.D{
+		{Key: "group_id", Value: 1},
+		{Key: "created_at", Value: -1},
+		{Key: "_id", Value: -1},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create group messages index: %w", err)
+	}
+
 	// 创建未读消息索引：to_user_id + is_read
 	_, err = r.db.CreateIndex(collectionName, bson.D{
 		{Key: "to_user_id", Value: 1},
@@ -0,0 +1,102 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"exchange/internal/models/mysql"
+)
+
+// AccountDeletionRepository MySQL账户注销请求Repository实现
+type AccountDeletionRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountDeletionRepository 创建账户注销请求Repository
+func NewAccountDeletionRepository(db *gorm.DB) *AccountDeletionRepository {
+	return &AccountDeletionRepository{db: db}
+}
+
+// Create 创建注销请求
+func (r *AccountDeletionRepository) Create(ctx context.Context, req *mysql.AccountDeletionRequest) error {
+	if err := req.Validate(); err != nil {
+		return fmt.Errorf("account deletion request validation failed: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Create(req)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create account deletion request: %w", result.Error)
+	}
+
+	return nil
+}
+
+// Update 更新注销请求
+func (r *AccountDeletionRepository) Update(ctx context.Context, req *mysql.AccountDeletionRequest) error {
+	if err := req.Validate(); err != nil {
+		return fmt.Errorf("account deletion request validation failed: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Save(req)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update account deletion request: %w", result.Error)
+	}
+
+	return nil
+}
+
+// GetLatestByUserID 获取用户最近一次注销请求，不存在时返回nil
+func (r *AccountDeletionRepository) GetLatestByUserID(ctx context.Context, userID uint) (*mysql.AccountDeletionRequest, error) {
+	var req mysql.AccountDeletionRequest
+	result := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		First(&req)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get account deletion request: %w", result.Error)
+	}
+
+	return &req, nil
+}
+
+// GetDue 获取冷静期已结束的待执行请求
+func (r *AccountDeletionRepository) GetDue(ctx context.Context, before int64, limit int) ([]*mysql.AccountDeletionRequest, error) {
+	var reqs []*mysql.AccountDeletionRequest
+	result := r.db.WithContext(ctx).
+		Where("status = ? AND scheduled_at <= ?", mysql.AccountDeletionStatusPending, before).
+		Order("scheduled_at ASC").
+		Limit(limit).
+		Find(&reqs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get due account deletion requests: %w", result.Error)
+	}
+
+	return reqs, nil
+}
+
+// AnonymizeUser 匿名化用户个人信息并标记为已注销
+// 只覆盖个人信息字段，保留用户记录及ID，确保账务流水等关联数据完整
+func (r *AccountDeletionRepository) AnonymizeUser(ctx context.Context, req *mysql.AccountDeletionRequest, fields map[string]interface{}) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&mysql.User{}).
+			Where("id = ?", req.UserID).
+			Updates(fields)
+		if result.Error != nil {
+			return fmt.Errorf("failed to anonymize user: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("user not found")
+		}
+
+		if err := tx.Save(req).Error; err != nil {
+			return fmt.Errorf("failed to complete account deletion request: %w", err)
+		}
+
+		return nil
+	})
+}
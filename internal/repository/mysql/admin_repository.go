@@ -3,11 +3,11 @@ package mysql
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"gorm.io/gorm"
 
 	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/clock"
 )
 
 // AdminRepository MySQL管理员Repository实现
@@ -121,7 +121,7 @@ func (r *AdminRepository) List(ctx context.Context, limit, offset int) ([]*mysql
 
 // UpdateLastLogin 更新最后登录时间
 func (r *AdminRepository) UpdateLastLogin(ctx context.Context, adminID uint) error {
-	now := time.Now()
+	now := clock.Now()
 	result := r.db.WithContext(ctx).Model(&mysql.Admin{}).
 		Where("id = ?", adminID).
 		Updates(map[string]interface{}{
@@ -0,0 +1,95 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"exchange/internal/models/mysql"
+)
+
+// APIKeyRepository MySQL用户API key Repository实现
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository 创建用户API key Repository
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create 创建API key
+func (r *APIKeyRepository) Create(ctx context.Context, key *mysql.APIKey) error {
+	if err := key.Validate(); err != nil {
+		return fmt.Errorf("api key validation failed: %w", err)
+	}
+
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+// GetByKeyID 根据key ID获取API key，不存在或已删除时返回nil
+func (r *APIKeyRepository) GetByKeyID(ctx context.Context, keyID string) (*mysql.APIKey, error) {
+	var key mysql.APIKey
+	result := r.db.WithContext(ctx).Where("key_id = ?", keyID).First(&key)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", result.Error)
+	}
+
+	return &key, nil
+}
+
+// ListByUser 获取用户的API key，按创建时间倒序
+func (r *APIKeyRepository) ListByUser(ctx context.Context, userID uint) ([]*mysql.APIKey, error) {
+	var keys []*mysql.APIKey
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// CountByUser 统计用户的API key数量
+func (r *APIKeyRepository) CountByUser(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&mysql.APIKey{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count api keys: %w", err)
+	}
+	return count, nil
+}
+
+// Update 更新API key
+func (r *APIKeyRepository) Update(ctx context.Context, key *mysql.APIKey) error {
+	if err := key.Validate(); err != nil {
+		return fmt.Errorf("api key validation failed: %w", err)
+	}
+
+	if err := r.db.WithContext(ctx).Save(key).Error; err != nil {
+		return fmt.Errorf("failed to update api key: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除API key（软删除）
+func (r *APIKeyRepository) Delete(ctx context.Context, id uint) error {
+	if err := r.db.WithContext(ctx).Delete(&mysql.APIKey{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete api key: %w", err)
+	}
+	return nil
+}
+
+// Touch 记录最近一次认证通过的时间和客户端IP
+func (r *APIKeyRepository) Touch(ctx context.Context, id uint, ip string, at time.Time) error {
+	err := r.db.WithContext(ctx).Model(&mysql.APIKey{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"last_used_at": at, "last_used_ip": ip}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update api key usage: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"exchange/internal/models/mysql"
+)
+
+// AutomationRuleRepository MySQL消息自动化规则Repository实现
+type AutomationRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewAutomationRuleRepository 创建消息自动化规则Repository
+func NewAutomationRuleRepository(db *gorm.DB) *AutomationRuleRepository {
+	return &AutomationRuleRepository{db: db}
+}
+
+// Create 创建规则
+func (r *AutomationRuleRepository) Create(ctx context.Context, rule *mysql.AutomationRule) error {
+	if err := rule.Validate(); err != nil {
+		return fmt.Errorf("automation rule validation failed: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Create(rule)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create automation rule: %w", result.Error)
+	}
+
+	return nil
+}
+
+// Update 更新规则
+func (r *AutomationRuleRepository) Update(ctx context.Context, rule *mysql.AutomationRule) error {
+	if err := rule.Validate(); err != nil {
+		return fmt.Errorf("automation rule validation failed: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Save(rule)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update automation rule: %w", result.Error)
+	}
+
+	return nil
+}
+
+// Delete 删除规则
+func (r *AutomationRuleRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&mysql.AutomationRule{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete automation rule: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("automation rule not found")
+	}
+
+	return nil
+}
+
+// GetByID 根据ID获取规则，不存在时返回nil
+func (r *AutomationRuleRepository) GetByID(ctx context.Context, id uint) (*mysql.AutomationRule, error) {
+	var rule mysql.AutomationRule
+	result := r.db.WithContext(ctx).First(&rule, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get automation rule: %w", result.Error)
+	}
+
+	return &rule, nil
+}
+
+// List 获取规则列表，trigger为空时返回所有规则
+func (r *AutomationRuleRepository) List(ctx context.Context, trigger mysql.AutomationTrigger) ([]*mysql.AutomationRule, error) {
+	var rules []*mysql.AutomationRule
+	query := r.db.WithContext(ctx).Order("id DESC")
+	if trigger != "" {
+		query = query.Where("`trigger` = ?", trigger)
+	}
+
+	if result := query.Find(&rules); result.Error != nil {
+		return nil, fmt.Errorf("failed to list automation rules: %w", result.Error)
+	}
+
+	return rules, nil
+}
+
+// GetEnabledByTrigger 获取指定触发事件下启用的规则
+func (r *AutomationRuleRepository) GetEnabledByTrigger(ctx context.Context, trigger mysql.AutomationTrigger) ([]*mysql.AutomationRule, error) {
+	var rules []*mysql.AutomationRule
+	result := r.db.WithContext(ctx).
+		Where("`trigger` = ? AND enabled = ?", trigger, true).
+		Order("id ASC").
+		Find(&rules)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get enabled automation rules: %w", result.Error)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,106 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"exchange/internal/models/mysql"
+)
+
+// ChatExportRepository MySQL会话导出Repository实现
+type ChatExportRepository struct {
+	db *gorm.DB
+}
+
+// NewChatExportRepository 创建会话导出Repository
+func NewChatExportRepository(db *gorm.DB) *ChatExportRepository {
+	return &ChatExportRepository{db: db}
+}
+
+// CreateJob 创建导出任务
+func (r *ChatExportRepository) CreateJob(ctx context.Context, job *mysql.ChatExportJob) error {
+	if err := r.db.WithContext(ctx).Create(job).Error; err != nil {
+		return fmt.Errorf("failed to create chat export job: %w", err)
+	}
+	return nil
+}
+
+// UpdateJob 更新导出任务
+func (r *ChatExportRepository) UpdateJob(ctx context.Context, job *mysql.ChatExportJob) error {
+	if err := r.db.WithContext(ctx).Save(job).Error; err != nil {
+		return fmt.Errorf("failed to update chat export job: %w", err)
+	}
+	return nil
+}
+
+// GetJobByID 根据ID获取导出任务，不存在时返回nil
+func (r *ChatExportRepository) GetJobByID(ctx context.Context, id uint) (*mysql.ChatExportJob, error) {
+	var job mysql.ChatExportJob
+	result := r.db.WithContext(ctx).First(&job, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get chat export job: %w", result.Error)
+	}
+
+	return &job, nil
+}
+
+// CountUnfinishedJobs 统计用户等待执行和生成中的导出任务
+func (r *ChatExportRepository) CountUnfinishedJobs(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	result := r.db.WithContext(ctx).Model(&mysql.ChatExportJob{}).
+		Where("user_id = ? AND status IN ?", userID, []mysql.ChatExportStatus{mysql.ChatExportStatusPending, mysql.ChatExportStatusRunning}).
+		Count(&count)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to count chat export jobs: %w", result.Error)
+	}
+
+	return count, nil
+}
+
+// GetExpiredJobs 获取文件已过期但尚未清理的任务
+func (r *ChatExportRepository) GetExpiredJobs(ctx context.Context, now int64, limit int) ([]*mysql.ChatExportJob, error) {
+	var jobs []*mysql.ChatExportJob
+	result := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at <= ?", mysql.ChatExportStatusCompleted, now).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&jobs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get expired chat export jobs: %w", result.Error)
+	}
+
+	return jobs, nil
+}
+
+// GetConsent 获取用户的导出授权设置，未设置时返回nil
+func (r *ChatExportRepository) GetConsent(ctx context.Context, userID uint) (*mysql.ChatExportConsent, error) {
+	var consent mysql.ChatExportConsent
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&consent)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get chat export consent: %w", result.Error)
+	}
+
+	return &consent, nil
+}
+
+// SetConsent 设置用户的导出授权
+func (r *ChatExportRepository) SetConsent(ctx context.Context, consent *mysql.ChatExportConsent) error {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"allow", "updated_at"}),
+	}).Create(consent)
+	if result.Error != nil {
+		return fmt.Errorf("failed to set chat export consent: %w", result.Error)
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package mysql
+
+import "gorm.io/gorm"
+
+// notFoundError 记录不存在错误，错误链中包含gorm.ErrRecordNotFound，便于上层按分类转换
+type notFoundError struct {
+	msg string
+}
+
+func (e *notFoundError) Error() string { return e.msg }
+func (e *notFoundError) Unwrap() error { return gorm.ErrRecordNotFound }
+
+// ErrUserNotFound 用户不存在
+var ErrUserNotFound error = &notFoundError{msg: "user not found"}
+
+// ErrPushDeviceNotFound 推送设备不存在或不属于该用户
+var ErrPushDeviceNotFound error = &notFoundError{msg: "push device not found"}
+
+// ErrArchiveOverrideNotFound 会话的消息归档策略不存在
+var ErrArchiveOverrideNotFound error = &notFoundError{msg: "archive override not found"}
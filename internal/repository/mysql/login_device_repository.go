@@ -0,0 +1,92 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"exchange/internal/models/mysql"
+)
+
+// LoginDeviceRepository MySQL登录设备Repository实现
+type LoginDeviceRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginDeviceRepository 创建登录设备Repository
+func NewLoginDeviceRepository(db *gorm.DB) *LoginDeviceRepository {
+	return &LoginDeviceRepository{db: db}
+}
+
+// Create 记录登录设备
+func (r *LoginDeviceRepository) Create(ctx context.Context, device *mysql.LoginDevice) error {
+	if err := device.Validate(); err != nil {
+		return fmt.Errorf("login device validation failed: %w", err)
+	}
+
+	if err := r.db.WithContext(ctx).Create(device).Error; err != nil {
+		return fmt.Errorf("failed to create login device: %w", err)
+	}
+	return nil
+}
+
+// GetByFingerprint 根据用户和设备指纹获取设备，不存在时返回nil
+func (r *LoginDeviceRepository) GetByFingerprint(ctx context.Context, userID uint, fingerprint string) (*mysql.LoginDevice, error) {
+	var device mysql.LoginDevice
+	result := r.db.WithContext(ctx).Where("user_id = ? AND fingerprint = ?", userID, fingerprint).First(&device)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get login device: %w", result.Error)
+	}
+
+	return &device, nil
+}
+
+// ListByUser 获取用户的登录设备，最近使用的在前
+func (r *LoginDeviceRepository) ListByUser(ctx context.Context, userID uint) ([]*mysql.LoginDevice, error) {
+	var devices []*mysql.LoginDevice
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("last_seen_at DESC").Find(&devices).Error; err != nil {
+		return nil, fmt.Errorf("failed to list login devices: %w", err)
+	}
+	return devices, nil
+}
+
+// Touch 更新设备最近一次登录的IP、国家和时间
+func (r *LoginDeviceRepository) Touch(ctx context.Context, id uint, ip, country string, at time.Time) error {
+	result := r.db.WithContext(ctx).Model(&mysql.LoginDevice{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_ip":      ip,
+		"country":      country,
+		"last_seen_at": at,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update login device: %w", result.Error)
+	}
+	return nil
+}
+
+// PruneOldest 只保留用户最近使用的keep个设备，返回删除的数量
+func (r *LoginDeviceRepository) PruneOldest(ctx context.Context, userID uint, keep int) (int64, error) {
+	// 每个用户的设备数量很少，直接取出全部ID（MySQL的OFFSET必须带LIMIT）
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&mysql.LoginDevice{}).
+		Where("user_id = ?", userID).
+		Order("last_seen_at DESC").
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to list login devices: %w", err)
+	}
+	if len(ids) <= keep {
+		return 0, nil
+	}
+
+	// 物理删除，删除后同一设备再次登录可以重新记录（唯一索引包含指纹）
+	result := r.db.WithContext(ctx).Unscoped().Where("id IN ?", ids[keep:]).Delete(&mysql.LoginDevice{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune login devices: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,53 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"exchange/internal/models/mysql"
+)
+
+// NotificationPreferenceRepository MySQL推送通知设置Repository实现
+type NotificationPreferenceRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationPreferenceRepository 创建推送通知设置Repository
+func NewNotificationPreferenceRepository(db *gorm.DB) *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{db: db}
+}
+
+// Get 获取用户的通知设置，未设置时返回默认设置
+func (r *NotificationPreferenceRepository) Get(ctx context.Context, userID uint) (*mysql.NotificationPreference, error) {
+	var pref mysql.NotificationPreference
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&pref)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return mysql.DefaultNotificationPreference(userID), nil
+		}
+		return nil, fmt.Errorf("failed to get notification preference: %w", result.Error)
+	}
+	return &pref, nil
+}
+
+// Save 保存用户的通知设置
+func (r *NotificationPreferenceRepository) Save(ctx context.Context, pref *mysql.NotificationPreference) error {
+	if err := pref.Validate(); err != nil {
+		return fmt.Errorf("notification preference validation failed: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"push_enabled", "direct_messages", "group_messages", "show_preview",
+			"quiet_start", "quiet_end", "timezone", "updated_at",
+		}),
+	}).Create(pref)
+	if result.Error != nil {
+		return fmt.Errorf("failed to save notification preference: %w", result.Error)
+	}
+	return nil
+}
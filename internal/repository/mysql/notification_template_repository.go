@@ -0,0 +1,150 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"exchange/internal/models/mysql"
+)
+
+// NotificationTemplateRepository MySQL通知模板Repository实现
+type NotificationTemplateRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationTemplateRepository 创建通知模板Repository
+func NewNotificationTemplateRepository(db *gorm.DB) *NotificationTemplateRepository {
+	return &NotificationTemplateRepository{db: db}
+}
+
+// CreateVersion 保存新版本并设为启用，同一事件、渠道、语言下的其他版本取消启用
+// 版本号为已有最大版本号加1，并发保存同一模板时由唯一索引拒绝后保存的请求
+func (r *NotificationTemplateRepository) CreateVersion(ctx context.Context, tpl *mysql.NotificationTemplate) error {
+	if err := tpl.Validate(); err != nil {
+		return fmt.Errorf("notification template validation failed: %w", err)
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var latest int
+		err := tx.Unscoped().Model(&mysql.NotificationTemplate{}).
+			Where("event = ? AND channel = ? AND locale = ?", tpl.Event, tpl.Channel, tpl.Locale).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&latest).Error
+		if err != nil {
+			return fmt.Errorf("failed to get latest notification template version: %w", err)
+		}
+
+		if err := deactivateTemplates(tx, tpl.Event, tpl.Channel, tpl.Locale); err != nil {
+			return err
+		}
+
+		tpl.Version = latest + 1
+		tpl.Active = true
+		if err := tx.Create(tpl).Error; err != nil {
+			return fmt.Errorf("failed to create notification template: %w", err)
+		}
+		return nil
+	})
+}
+
+// Activate 启用指定版本，同一事件、渠道、语言下的其他版本取消启用
+func (r *NotificationTemplateRepository) Activate(ctx context.Context, tpl *mysql.NotificationTemplate) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := deactivateTemplates(tx, tpl.Event, tpl.Channel, tpl.Locale); err != nil {
+			return err
+		}
+
+		result := tx.Model(&mysql.NotificationTemplate{}).Where("id = ?", tpl.ID).Update("active", true)
+		if result.Error != nil {
+			return fmt.Errorf("failed to activate notification template: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("notification template not found")
+		}
+
+		tpl.Active = true
+		return nil
+	})
+}
+
+// Deactivate 取消启用事件、渠道、语言下的所有版本（恢复使用内置默认模板），历史版本保留
+func (r *NotificationTemplateRepository) Deactivate(ctx context.Context, event, channel, locale string) error {
+	return deactivateTemplates(r.db.WithContext(ctx), event, channel, locale)
+}
+
+// deactivateTemplates 取消启用事件、渠道、语言下的所有版本
+func deactivateTemplates(db *gorm.DB, event, channel, locale string) error {
+	result := db.Model(&mysql.NotificationTemplate{}).
+		Where("event = ? AND channel = ? AND locale = ? AND active = ?", event, channel, locale, true).
+		Update("active", false)
+	if result.Error != nil {
+		return fmt.Errorf("failed to deactivate notification templates: %w", result.Error)
+	}
+	return nil
+}
+
+// GetVersion 获取指定版本，不存在时返回nil
+func (r *NotificationTemplateRepository) GetVersion(ctx context.Context, event, channel, locale string, version int) (*mysql.NotificationTemplate, error) {
+	var tpl mysql.NotificationTemplate
+	result := r.db.WithContext(ctx).
+		Where("event = ? AND channel = ? AND locale = ? AND version = ?", event, channel, locale, version).
+		First(&tpl)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get notification template: %w", result.Error)
+	}
+
+	return &tpl, nil
+}
+
+// GetActive 获取启用的版本，不存在时返回nil
+func (r *NotificationTemplateRepository) GetActive(ctx context.Context, event, channel, locale string) (*mysql.NotificationTemplate, error) {
+	var tpl mysql.NotificationTemplate
+	result := r.db.WithContext(ctx).
+		Where("event = ? AND channel = ? AND locale = ? AND active = ?", event, channel, locale, true).
+		First(&tpl)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active notification template: %w", result.Error)
+	}
+
+	return &tpl, nil
+}
+
+// ListActive 获取启用的版本列表，event、channel为空时不过滤
+func (r *NotificationTemplateRepository) ListActive(ctx context.Context, event, channel string) ([]*mysql.NotificationTemplate, error) {
+	var templates []*mysql.NotificationTemplate
+	query := r.db.WithContext(ctx).Where("active = ?", true).Order("event ASC, channel ASC, locale ASC")
+	if event != "" {
+		query = query.Where("event = ?", event)
+	}
+	if channel != "" {
+		query = query.Where("channel = ?", channel)
+	}
+
+	if result := query.Find(&templates); result.Error != nil {
+		return nil, fmt.Errorf("failed to list notification templates: %w", result.Error)
+	}
+
+	return templates, nil
+}
+
+// ListVersions 获取事件、渠道、语言下的所有版本，按版本号倒序
+func (r *NotificationTemplateRepository) ListVersions(ctx context.Context, event, channel, locale string) ([]*mysql.NotificationTemplate, error) {
+	var templates []*mysql.NotificationTemplate
+	result := r.db.WithContext(ctx).
+		Where("event = ? AND channel = ? AND locale = ?", event, channel, locale).
+		Order("version DESC").
+		Find(&templates)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list notification template versions: %w", result.Error)
+	}
+
+	return templates, nil
+}
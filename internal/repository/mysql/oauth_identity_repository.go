@@ -0,0 +1,55 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"exchange/internal/models/mysql"
+)
+
+// OAuthIdentityRepository MySQL第三方登录账户Repository实现
+type OAuthIdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthIdentityRepository 创建第三方登录账户Repository
+func NewOAuthIdentityRepository(db *gorm.DB) *OAuthIdentityRepository {
+	return &OAuthIdentityRepository{db: db}
+}
+
+// Create 关联第三方登录账户
+func (r *OAuthIdentityRepository) Create(ctx context.Context, identity *mysql.OAuthIdentity) error {
+	if err := identity.Validate(); err != nil {
+		return fmt.Errorf("oauth identity validation failed: %w", err)
+	}
+
+	if err := r.db.WithContext(ctx).Create(identity).Error; err != nil {
+		return fmt.Errorf("failed to create oauth identity: %w", err)
+	}
+	return nil
+}
+
+// GetByProviderSubject 根据服务商和服务商内的用户标识获取关联记录，不存在时返回nil
+func (r *OAuthIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*mysql.OAuthIdentity, error) {
+	var identity mysql.OAuthIdentity
+	result := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get oauth identity: %w", result.Error)
+	}
+
+	return &identity, nil
+}
+
+// ListByUser 获取用户关联的第三方登录账户
+func (r *OAuthIdentityRepository) ListByUser(ctx context.Context, userID uint) ([]*mysql.OAuthIdentity, error) {
+	var identities []*mysql.OAuthIdentity
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id ASC").Find(&identities).Error; err != nil {
+		return nil, fmt.Errorf("failed to list oauth identities: %w", err)
+	}
+	return identities, nil
+}
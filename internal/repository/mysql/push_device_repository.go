@@ -0,0 +1,88 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"exchange/internal/models/mysql"
+)
+
+// PushDeviceRepository MySQL推送设备Repository实现
+type PushDeviceRepository struct {
+	db *gorm.DB
+}
+
+// NewPushDeviceRepository 创建推送设备Repository
+func NewPushDeviceRepository(db *gorm.DB) *PushDeviceRepository {
+	return &PushDeviceRepository{db: db}
+}
+
+// Upsert 注册推送设备，token已存在时更新所属用户、平台和最近注册时间
+func (r *PushDeviceRepository) Upsert(ctx context.Context, device *mysql.PushDevice) error {
+	if err := device.Validate(); err != nil {
+		return fmt.Errorf("push device validation failed: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "platform", "last_seen_at", "updated_at"}),
+	}).Create(device)
+	if result.Error != nil {
+		return fmt.Errorf("failed to upsert push device: %w", result.Error)
+	}
+	return nil
+}
+
+// ListByUser 获取用户的推送设备，最近注册的在前
+func (r *PushDeviceRepository) ListByUser(ctx context.Context, userID uint) ([]*mysql.PushDevice, error) {
+	var devices []*mysql.PushDevice
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("last_seen_at DESC").Find(&devices).Error; err != nil {
+		return nil, fmt.Errorf("failed to list push devices: %w", err)
+	}
+	return devices, nil
+}
+
+// Delete 删除用户的推送设备，设备不存在或不属于该用户时返回ErrPushDeviceNotFound
+func (r *PushDeviceRepository) Delete(ctx context.Context, userID, id uint) error {
+	// 物理删除，token有唯一索引，删除后同一设备可以重新注册
+	result := r.db.WithContext(ctx).Unscoped().Where("id = ? AND user_id = ?", id, userID).Delete(&mysql.PushDevice{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete push device: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrPushDeviceNotFound
+	}
+	return nil
+}
+
+// DeleteByToken 删除已失效的token
+func (r *PushDeviceRepository) DeleteByToken(ctx context.Context, token string) error {
+	if err := r.db.WithContext(ctx).Unscoped().Where("token = ?", token).Delete(&mysql.PushDevice{}).Error; err != nil {
+		return fmt.Errorf("failed to delete push device: %w", err)
+	}
+	return nil
+}
+
+// PruneOldest 只保留用户最近注册的keep个设备，返回删除的数量
+func (r *PushDeviceRepository) PruneOldest(ctx context.Context, userID uint, keep int) (int64, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&mysql.PushDevice{}).
+		Where("user_id = ?", userID).
+		Order("last_seen_at DESC").
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to list push devices: %w", err)
+	}
+	if len(ids) <= keep {
+		return 0, nil
+	}
+
+	result := r.db.WithContext(ctx).Unscoped().Where("id IN ?", ids[keep:]).Delete(&mysql.PushDevice{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune push devices: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
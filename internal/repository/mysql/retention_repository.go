@@ -0,0 +1,163 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"exchange/internal/models/mysql"
+)
+
+// RetentionRepository MySQL数据保留Repository实现（法律保全、清理执行记录和会话的消息归档策略）
+type RetentionRepository struct {
+	db *gorm.DB
+}
+
+// NewRetentionRepository 创建数据保留Repository
+func NewRetentionRepository(db *gorm.DB) *RetentionRepository {
+	return &RetentionRepository{db: db}
+}
+
+// CreateHold 创建法律保全
+func (r *RetentionRepository) CreateHold(ctx context.Context, hold *mysql.LegalHold) error {
+	if err := hold.Validate(); err != nil {
+		return fmt.Errorf("legal hold validation failed: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Create(hold)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create legal hold: %w", result.Error)
+	}
+
+	return nil
+}
+
+// ReleaseHolds 解除用户所有生效中的法律保全，返回解除数量
+func (r *RetentionRepository) ReleaseHolds(ctx context.Context, userID, adminID uint, releasedAt int64) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&mysql.LegalHold{}).
+		Where("user_id = ? AND released_at = 0", userID).
+		Updates(map[string]interface{}{
+			"released_by": adminID,
+			"released_at": releasedAt,
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to release legal holds: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// GetActiveHolds 获取生效中的法律保全
+func (r *RetentionRepository) GetActiveHolds(ctx context.Context) ([]*mysql.LegalHold, error) {
+	var holds []*mysql.LegalHold
+	result := r.db.WithContext(ctx).
+		Where("released_at = 0").
+		Order("id DESC").
+		Find(&holds)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get legal holds: %w", result.Error)
+	}
+
+	return holds, nil
+}
+
+// GetHeldUserIDs 获取处于法律保全中的用户ID
+func (r *RetentionRepository) GetHeldUserIDs(ctx context.Context) ([]uint, error) {
+	var userIDs []uint
+	result := r.db.WithContext(ctx).
+		Model(&mysql.LegalHold{}).
+		Where("released_at = 0").
+		Distinct().
+		Pluck("user_id", &userIDs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get held user ids: %w", result.Error)
+	}
+
+	return userIDs, nil
+}
+
+// CreateRun 记录一次清理执行
+func (r *RetentionRepository) CreateRun(ctx context.Context, run *mysql.RetentionRun) error {
+	result := r.db.WithContext(ctx).Create(run)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create retention run: %w", result.Error)
+	}
+
+	return nil
+}
+
+// GetRuns 获取指定时间之后的清理执行记录，category为空时返回所有类别
+func (r *RetentionRepository) GetRuns(ctx context.Context, category string, since int64, limit int) ([]*mysql.RetentionRun, error) {
+	var runs []*mysql.RetentionRun
+	query := r.db.WithContext(ctx).Where("started_at >= ?", since)
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	result := query.Order("started_at DESC").Limit(limit).Find(&runs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get retention runs: %w", result.Error)
+	}
+
+	return runs, nil
+}
+
+// SetArchiveOverride 设置会话的消息归档策略，会话已有策略时更新
+func (r *RetentionRepository) SetArchiveOverride(ctx context.Context, override *mysql.MessageArchiveOverride) error {
+	if err := override.Validate(); err != nil {
+		return fmt.Errorf("archive override validation failed: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "conversation_type"}, {Name: "conversation_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"days", "reason", "created_by", "updated_at"}),
+	}).Create(override)
+	if result.Error != nil {
+		return fmt.Errorf("failed to set archive override: %w", result.Error)
+	}
+
+	return nil
+}
+
+// ListArchiveOverrides 获取所有会话的消息归档策略
+func (r *RetentionRepository) ListArchiveOverrides(ctx context.Context) ([]*mysql.MessageArchiveOverride, error) {
+	var overrides []*mysql.MessageArchiveOverride
+	result := r.db.WithContext(ctx).Order("id DESC").Find(&overrides)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list archive overrides: %w", result.Error)
+	}
+
+	return overrides, nil
+}
+
+// GetArchiveOverride 根据ID获取会话的消息归档策略，不存在时返回ErrArchiveOverrideNotFound
+func (r *RetentionRepository) GetArchiveOverride(ctx context.Context, id uint) (*mysql.MessageArchiveOverride, error) {
+	var override mysql.MessageArchiveOverride
+	result := r.db.WithContext(ctx).First(&override, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrArchiveOverrideNotFound
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get archive override: %w", result.Error)
+	}
+
+	return &override, nil
+}
+
+// DeleteArchiveOverride 删除会话的消息归档策略，不存在时返回ErrArchiveOverrideNotFound
+func (r *RetentionRepository) DeleteArchiveOverride(ctx context.Context, id uint) error {
+	// 物理删除，会话有唯一索引，删除后可以重新设置
+	result := r.db.WithContext(ctx).Unscoped().Delete(&mysql.MessageArchiveOverride{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete archive override: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrArchiveOverrideNotFound
+	}
+
+	return nil
+}
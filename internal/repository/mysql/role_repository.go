@@ -0,0 +1,107 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"exchange/internal/models/mysql"
+)
+
+// RoleRepository MySQL角色Repository实现
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository 创建角色Repository
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// ListRoles 获取所有角色及其权限
+func (r *RoleRepository) ListRoles(ctx context.Context) ([]*mysql.Role, error) {
+	var roles []*mysql.Role
+	result := r.db.WithContext(ctx).Preload("Permissions").Order("name").Find(&roles)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", result.Error)
+	}
+
+	return roles, nil
+}
+
+// GetRole 根据名称获取角色及其权限，不存在时返回nil
+func (r *RoleRepository) GetRole(ctx context.Context, name string) (*mysql.Role, error) {
+	var role mysql.Role
+	result := r.db.WithContext(ctx).Preload("Permissions").Where("name = ?", name).First(&role)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get role: %w", result.Error)
+	}
+
+	return &role, nil
+}
+
+// SaveRole 创建或更新角色，并将角色的权限替换为permissions
+func (r *RoleRepository) SaveRole(ctx context.Context, role *mysql.Role, permissions []string) error {
+	if err := role.Validate(); err != nil {
+		return fmt.Errorf("role validation failed: %w", err)
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing mysql.Role
+		err := tx.Where("name = ?", role.Name).First(&existing).Error
+		switch {
+		case err == nil:
+			role.ID = existing.ID
+			role.CreatedAt = existing.CreatedAt
+			if err := tx.Omit("Permissions").Save(role).Error; err != nil {
+				return fmt.Errorf("failed to update role: %w", err)
+			}
+		case err == gorm.ErrRecordNotFound:
+			if err := tx.Omit("Permissions").Create(role).Error; err != nil {
+				return fmt.Errorf("failed to create role: %w", err)
+			}
+		default:
+			return fmt.Errorf("failed to get role: %w", err)
+		}
+
+		if err := tx.Where("role_id = ?", role.ID).Delete(&mysql.RolePermission{}).Error; err != nil {
+			return fmt.Errorf("failed to clear role permissions: %w", err)
+		}
+
+		role.Permissions = make([]mysql.RolePermission, len(permissions))
+		for i, permission := range permissions {
+			role.Permissions[i] = mysql.RolePermission{RoleID: role.ID, Permission: permission}
+		}
+		if len(role.Permissions) > 0 {
+			if err := tx.Create(&role.Permissions).Error; err != nil {
+				return fmt.Errorf("failed to save role permissions: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteRole 删除角色及其权限（物理删除，以便再次创建同名角色）
+func (r *RoleRepository) DeleteRole(ctx context.Context, name string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var role mysql.Role
+		if err := tx.Where("name = ?", name).First(&role).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("role not found")
+			}
+			return fmt.Errorf("failed to get role: %w", err)
+		}
+
+		if err := tx.Where("role_id = ?", role.ID).Delete(&mysql.RolePermission{}).Error; err != nil {
+			return fmt.Errorf("failed to delete role permissions: %w", err)
+		}
+		if err := tx.Unscoped().Delete(&role).Error; err != nil {
+			return fmt.Errorf("failed to delete role: %w", err)
+		}
+		return nil
+	})
+}
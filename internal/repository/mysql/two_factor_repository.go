@@ -0,0 +1,111 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"exchange/internal/models/mysql"
+)
+
+// TwoFactorRepository MySQL两步验证凭据Repository实现
+type TwoFactorRepository struct {
+	db *gorm.DB
+}
+
+// NewTwoFactorRepository 创建两步验证凭据Repository
+func NewTwoFactorRepository(db *gorm.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// GetCredential 获取账户的两步验证凭据，未启用时返回nil
+func (r *TwoFactorRepository) GetCredential(ctx context.Context, subjectType string, subjectID uint) (*mysql.TwoFactorCredential, error) {
+	var credential mysql.TwoFactorCredential
+	result := r.db.WithContext(ctx).
+		Where("subject_type = ? AND subject_id = ?", subjectType, subjectID).
+		First(&credential)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get two-factor credential: %w", result.Error)
+	}
+
+	return &credential, nil
+}
+
+// Enable 保存两步验证凭据（替换已有凭据）并设置账户的two_factor_enabled
+func (r *TwoFactorRepository) Enable(ctx context.Context, credential *mysql.TwoFactorCredential) error {
+	if err := credential.Validate(); err != nil {
+		return fmt.Errorf("two-factor credential validation failed: %w", err)
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := deleteTwoFactorCredential(tx, credential.SubjectType, credential.SubjectID); err != nil {
+			return err
+		}
+		if err := tx.Create(credential).Error; err != nil {
+			return fmt.Errorf("failed to create two-factor credential: %w", err)
+		}
+		return setTwoFactorEnabled(tx, credential.SubjectType, credential.SubjectID, true)
+	})
+}
+
+// Disable 删除两步验证凭据并清除账户的two_factor_enabled
+func (r *TwoFactorRepository) Disable(ctx context.Context, subjectType string, subjectID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := deleteTwoFactorCredential(tx, subjectType, subjectID); err != nil {
+			return err
+		}
+		return setTwoFactorEnabled(tx, subjectType, subjectID, false)
+	})
+}
+
+// UseStep 记录验证通过的时间步，时间步不大于已记录的值时返回false（验证码已使用过）
+func (r *TwoFactorRepository) UseStep(ctx context.Context, id uint, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&mysql.TwoFactorCredential{}).
+		Where("id = ? AND last_used_step < ?", id, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update two-factor step: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// ReplaceBackupCodes 替换备用码，备用码已被并发修改（与old不一致）时返回false
+func (r *TwoFactorRepository) ReplaceBackupCodes(ctx context.Context, id uint, old, codes string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&mysql.TwoFactorCredential{}).
+		Where("id = ? AND backup_codes = ?", id, old).
+		Update("backup_codes", codes)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update two-factor backup codes: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// deleteTwoFactorCredential 删除账户的两步验证凭据（物理删除，以便重新启用）
+func deleteTwoFactorCredential(tx *gorm.DB, subjectType string, subjectID uint) error {
+	err := tx.Unscoped().
+		Where("subject_type = ? AND subject_id = ?", subjectType, subjectID).
+		Delete(&mysql.TwoFactorCredential{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete two-factor credential: %w", err)
+	}
+	return nil
+}
+
+// setTwoFactorEnabled 设置用户或管理员的two_factor_enabled
+func setTwoFactorEnabled(tx *gorm.DB, subjectType string, subjectID uint, enabled bool) error {
+	var model interface{} = &mysql.User{}
+	if subjectType == mysql.TwoFactorSubjectAdmin {
+		model = &mysql.Admin{}
+	}
+
+	if err := tx.Model(model).Where("id = ?", subjectID).Update("two_factor_enabled", enabled).Error; err != nil {
+		return fmt.Errorf("failed to update two-factor flag: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"exchange/internal/models/mysql"
+)
+
+// UserImportRepository MySQL用户批量导入任务Repository实现
+type UserImportRepository struct {
+	db *gorm.DB
+}
+
+// NewUserImportRepository 创建用户批量导入任务Repository
+func NewUserImportRepository(db *gorm.DB) *UserImportRepository {
+	return &UserImportRepository{db: db}
+}
+
+// Create 创建导入任务
+func (r *UserImportRepository) Create(ctx context.Context, job *mysql.UserImportJob) error {
+	if err := job.Validate(); err != nil {
+		return fmt.Errorf("user import job validation failed: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Create(job)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create user import job: %w", result.Error)
+	}
+
+	return nil
+}
+
+// Update 更新导入任务
+func (r *UserImportRepository) Update(ctx context.Context, job *mysql.UserImportJob) error {
+	result := r.db.WithContext(ctx).Save(job)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update user import job: %w", result.Error)
+	}
+
+	return nil
+}
+
+// GetByID 根据ID获取导入任务，不存在时返回nil
+func (r *UserImportRepository) GetByID(ctx context.Context, id uint) (*mysql.UserImportJob, error) {
+	var job mysql.UserImportJob
+	result := r.db.WithContext(ctx).First(&job, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user import job: %w", result.Error)
+	}
+
+	return &job, nil
+}
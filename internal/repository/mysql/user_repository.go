@@ -6,8 +6,11 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"exchange/internal/models/mysql"
+	"exchange/internal/pkg/clock"
+	"exchange/internal/repository"
 )
 
 // UserRepository MySQL用户Repository实现
@@ -45,7 +48,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id uint) (*mysql.User, err
 	result := r.db.WithContext(ctx).First(&user, id)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %w", result.Error)
 	}
@@ -59,7 +62,7 @@ func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*m
 	result := r.db.WithContext(ctx).Where("username = ?", username).First(&user)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user by username: %w", result.Error)
 	}
@@ -73,7 +76,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*mysql.U
 	result := r.db.WithContext(ctx).Where("email = ?", email).First(&user)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user by email: %w", result.Error)
 	}
@@ -93,7 +96,7 @@ func (r *UserRepository) Update(ctx context.Context, user *mysql.User) error {
 	}
 
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return ErrUserNotFound
 	}
 
 	return nil
@@ -107,7 +110,7 @@ func (r *UserRepository) Delete(ctx context.Context, id uint) error {
 	}
 
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return ErrUserNotFound
 	}
 
 	return nil
@@ -126,7 +129,7 @@ func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]*mysql.
 
 // UpdateLastLogin 更新最后登录时间
 func (r *UserRepository) UpdateLastLogin(ctx context.Context, userID uint) error {
-	now := time.Now()
+	now := clock.Now()
 	result := r.db.WithContext(ctx).Model(&mysql.User{}).
 		Where("id = ?", userID).
 		Updates(map[string]interface{}{
@@ -139,7 +142,7 @@ func (r *UserRepository) UpdateLastLogin(ctx context.Context, userID uint) error
 	}
 
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return ErrUserNotFound
 	}
 
 	return nil
@@ -200,6 +203,46 @@ func (r *UserRepository) CountByStatus(ctx context.Context, status mysql.UserSta
 	return count, nil
 }
 
+// CountLoggedInSince 统计最近登录时间不早于since的用户数
+func (r *UserRepository) CountLoggedInSince(ctx context.Context, since time.Time) (int64, error) {
+	var count int64
+	result := r.db.WithContext(ctx).Model(&mysql.User{}).
+		Where("last_login_at >= ?", since).
+		Count(&count)
+
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to count logged in users: %w", result.Error)
+	}
+
+	return count, nil
+}
+
+// CountCreatedPerDay 按UTC日期统计since之后注册的用户数，返回日期(2006-01-02)到数量，没有注册的日期不返回
+// created_at为纳秒时间戳，按天整除后分组
+func (r *UserRepository) CountCreatedPerDay(ctx context.Context, since time.Time) (map[string]int64, error) {
+	const nanosPerDay = int64(24 * time.Hour)
+
+	var rows []struct {
+		Day   int64
+		Count int64
+	}
+	result := r.db.WithContext(ctx).Model(&mysql.User{}).
+		Select("created_at DIV ? AS day, COUNT(*) AS count", nanosPerDay).
+		Where("created_at >= ?", since.UnixNano()).
+		Group("day").
+		Scan(&rows)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to count users per day: %w", result.Error)
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[time.Unix(0, row.Day*nanosPerDay).UTC().Format("2006-01-02")] = row.Count
+	}
+	return counts, nil
+}
+
 // Search 搜索用户
 func (r *UserRepository) Search(ctx context.Context, keyword string, limit, offset int) ([]*mysql.User, error) {
 	var users []*mysql.User
@@ -217,6 +260,89 @@ func (r *UserRepository) Search(ctx context.Context, keyword string, limit, offs
 	return users, nil
 }
 
+// userSortColumns 用户列表允许的排序字段
+var userSortColumns = map[string]string{
+	repository.UserSortID:          "id",
+	repository.UserSortUsername:    "username",
+	repository.UserSortCreatedAt:   "created_at",
+	repository.UserSortLastLoginAt: "last_login_at",
+	repository.UserSortLoginCount:  "login_count",
+}
+
+// Query 按条件分页查询用户，返回当前页的用户和符合条件的总数
+func (r *UserRepository) Query(ctx context.Context, query repository.UserQuery) ([]*mysql.User, int64, error) {
+	db := r.db.WithContext(ctx).Model(&mysql.User{})
+	if query.Deleted {
+		db = db.Unscoped().Where("deleted_at <> 0")
+	}
+	if query.Keyword != "" {
+		searchPattern := "%" + query.Keyword + "%"
+		db = db.Where("username LIKE ? OR email LIKE ?", searchPattern, searchPattern)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.Role != "" {
+		db = db.Where("role = ?", query.Role)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	if total == 0 {
+		return []*mysql.User{}, 0, nil
+	}
+
+	column, ok := userSortColumns[query.SortBy]
+	if !ok {
+		column = "id"
+	}
+	order := clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: query.Desc}
+
+	var users []*mysql.User
+	result := db.Order(order).Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: query.Desc}).
+		Limit(query.Limit).Offset(query.Offset).
+		Find(&users)
+
+	if result.Error != nil {
+		return nil, 0, fmt.Errorf("failed to query users: %w", result.Error)
+	}
+
+	return users, total, nil
+}
+
+// GetDeletedByID 根据ID获取已删除（软删除）的用户
+func (r *UserRepository) GetDeletedByID(ctx context.Context, id uint) (*mysql.User, error) {
+	var user mysql.User
+	result := r.db.WithContext(ctx).Unscoped().Where("deleted_at <> 0").First(&user, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get deleted user: %w", result.Error)
+	}
+
+	return &user, nil
+}
+
+// Restore 恢复已删除（软删除）的用户
+func (r *UserRepository) Restore(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Unscoped().Model(&mysql.User{}).
+		Where("id = ? AND deleted_at <> 0", id).
+		Update("deleted_at", 0)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to restore user: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
 // UpdateStatus 更新用户状态
 func (r *UserRepository) UpdateStatus(ctx context.Context, userID uint, status mysql.UserStatus) error {
 	result := r.db.WithContext(ctx).Model(&mysql.User{}).
@@ -228,7 +354,7 @@ func (r *UserRepository) UpdateStatus(ctx context.Context, userID uint, status m
 	}
 
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return ErrUserNotFound
 	}
 
 	return nil
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"exchange/internal/pkg/adminaudit"
+)
+
+// 上下文中的响应结果和审计信息
+const (
+	responseCodeKey    = "response_code"
+	responseMessageKey = "response_message_key"
+	auditEntryKey      = "admin_audit_entry"
+	auditSkipKey       = "admin_audit_skip"
+)
+
+// AuditEntry 处理器为管理员操作审计补充的信息，未设置的字段由审计中间件按请求推断
+type AuditEntry struct {
+	Action     string                 // 操作，如role.save
+	TargetType string                 // 操作对象类型，如user、role
+	TargetID   string                 // 操作对象ID
+	Before     map[string]interface{} // 变更前的数据快照
+	After      map[string]interface{} // 变更后的数据快照
+}
+
+// GetResponseResult 获取处理器写出的响应码和消息键，未通过统一响应写出时返回false
+func GetResponseResult(c *gin.Context) (int, string, bool) {
+	code, exists := c.Get(responseCodeKey)
+	if !exists {
+		return 0, "", false
+	}
+	codeValue, ok := code.(int)
+	if !ok {
+		return 0, "", false
+	}
+	return codeValue, c.GetString(responseMessageKey), true
+}
+
+// SetAuditAction 设置审计记录中的操作名称
+func SetAuditAction(c *gin.Context, action string) {
+	auditEntry(c).Action = action
+}
+
+// SetAuditTarget 设置审计记录中的操作对象
+func SetAuditTarget(c *gin.Context, targetType, targetID string) {
+	entry := auditEntry(c)
+	entry.TargetType = targetType
+	entry.TargetID = targetID
+}
+
+// SetAuditChange 设置审计记录中变更前后的数据，新增时before为nil，删除时after为nil
+// 调用时即转换为快照，之后修改原数据不影响审计记录
+func SetAuditChange(c *gin.Context, before, after interface{}) {
+	entry := auditEntry(c)
+	entry.Before = adminaudit.Snapshot(before)
+	entry.After = adminaudit.Snapshot(after)
+}
+
+// SkipAudit 不记录本次请求，用于不修改数据的POST接口（如预览）
+func SkipAudit(c *gin.Context) {
+	c.Set(auditSkipKey, true)
+}
+
+// IsAuditSkipped 本次请求是否不记录
+func IsAuditSkipped(c *gin.Context) bool {
+	return c.GetBool(auditSkipKey)
+}
+
+// GetAuditEntry 获取处理器设置的审计信息
+func GetAuditEntry(c *gin.Context) AuditEntry {
+	if entry, exists := c.Get(auditEntryKey); exists {
+		if e, ok := entry.(*AuditEntry); ok {
+			return *e
+		}
+	}
+	return AuditEntry{}
+}
+
+// auditEntry 获取或创建上下文中的审计信息
+func auditEntry(c *gin.Context) *AuditEntry {
+	if entry, exists := c.Get(auditEntryKey); exists {
+		if e, ok := entry.(*AuditEntry); ok {
+			return e
+		}
+	}
+	entry := &AuditEntry{}
+	c.Set(auditEntryKey, entry)
+	return entry
+}
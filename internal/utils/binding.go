@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"exchange/internal/pkg/i18n"
+)
+
+// FieldError 字段校验失败的原因
+type FieldError struct {
+	Field   string `json:"field"`   // 字段名，与请求中的JSON/表单字段名一致
+	Rule    string `json:"rule"`    // 未通过的校验规则，如required、email、min
+	Message string `json:"message"` // 本地化的错误消息
+}
+
+// RegisterValidationFieldNames 校验错误中的字段名使用json标签（没有时依次使用form、uri标签），与请求中的字段名一致
+func RegisterValidationFieldNames() {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form", "uri"} {
+			name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+}
+
+// BindingErrorResponse 请求绑定失败的错误响应
+// 字段校验失败时返回validation_failed和每个字段本地化的错误消息，其他错误（如JSON格式错误）返回invalid_request_data
+func BindingErrorResponse(c *gin.Context, err error) {
+	fields := ValidationFieldErrors(c, err)
+	if len(fields) == 0 {
+		ErrorResponse(c, "invalid_request_data", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Message
+	}
+	ErrorWithData(c, "validation_failed", map[string]interface{}{"fields": fields}, map[string]interface{}{
+		"error": strings.Join(messages, "; "),
+	})
+}
+
+// ValidationFieldErrors 将字段校验错误转换为请求语言的错误消息，err不是校验错误时返回nil
+// 消息键为validation_<规则>，字符串、切片和map的长度规则使用validation_<规则>_length，没有对应消息时使用validation_invalid
+func ValidationFieldErrors(c *gin.Context, err error) []FieldError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	i18nManager := getI18nManager(c)
+	lang := getLanguage(c)
+	fields := make([]FieldError, len(validationErrors))
+	for i, fieldErr := range validationErrors {
+		templateData := map[string]interface{}{
+			"Field": fieldErr.Field(),
+			"Param": fieldErr.Param(),
+		}
+		fields[i] = FieldError{
+			Field:   fieldErr.Field(),
+			Rule:    fieldErr.Tag(),
+			Message: i18nManager.Translate(lang, validationMessageKey(i18nManager, lang, fieldErr), templateData),
+		}
+	}
+	return fields
+}
+
+// validationMessageKey 校验规则对应的消息键
+func validationMessageKey(i18nManager *i18n.I18nManager, lang string, fieldErr validator.FieldError) string {
+	key := "validation_" + fieldErr.Tag()
+	switch fieldErr.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		if lengthKey := key + "_length"; i18nManager.HasMessage(lang, lengthKey) {
+			return lengthKey
+		}
+	}
+	if i18nManager.HasMessage(lang, key) {
+		return key
+	}
+	return "validation_invalid"
+}
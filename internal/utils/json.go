@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"encoding/hex"
+	"time"
+	"unsafe"
+
+	"github.com/gin-gonic/gin"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/modern-go/reflect2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// jsonContentType 响应的Content-Type，与gin.Context.JSON一致
+const jsonContentType = "application/json; charset=utf-8"
+
+// maxPooledBufferSize 归还到池中的编码缓冲区上限，超过时丢弃，避免个别大响应长期占用内存
+const maxPooledBufferSize = 64 * 1024
+
+// responseJSON 响应编码器
+// 与encoding/json一样转义HTML、支持json.Marshaler，按类型缓存编码器，编码缓冲区从池中复用，响应只在写出时拷贝一次。
+// map的键不排序（排序时每个map都要分配键的切片）；响应中常见的time.Time、ObjectID和map[string]interface{}
+// 由responseExtension直接写入缓冲区，不经过MarshalJSON和反射的map迭代器分配
+var responseJSON = newResponseJSON()
+
+func newResponseJSON() jsoniter.API {
+	api := jsoniter.Config{
+		EscapeHTML:             true,
+		ValidateJsonRawMessage: true,
+	}.Froze()
+	api.RegisterExtension(&responseExtension{})
+	return api
+}
+
+var (
+	timeType     = reflect2.TypeOf(time.Time{})
+	timePtrType  = reflect2.TypeOf((*time.Time)(nil))
+	objectIDType = reflect2.TypeOf(primitive.ObjectID{})
+	mapType      = reflect2.TypeOf(map[string]interface{}(nil))
+)
+
+// responseExtension 响应中常见类型的编码器，输出与encoding/json相同：
+// time.Time为RFC3339Nano字符串，ObjectID为十六进制字符串，map[string]interface{}的键按HTML转义
+type responseExtension struct {
+	jsoniter.DummyExtension
+}
+
+func (e *responseExtension) CreateEncoder(typ reflect2.Type) jsoniter.ValEncoder {
+	switch typ {
+	case timeType:
+		return timeEncoder{}
+	case timePtrType:
+		return timePtrEncoder{}
+	case objectIDType:
+		return objectIDEncoder{}
+	case mapType:
+		return mapEncoder{}
+	}
+	return nil
+}
+
+type timeEncoder struct{}
+
+func (timeEncoder) IsEmpty(ptr unsafe.Pointer) bool {
+	return false
+}
+
+func (timeEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	writeTime(*(*time.Time)(ptr), stream)
+}
+
+type timePtrEncoder struct{}
+
+func (timePtrEncoder) IsEmpty(ptr unsafe.Pointer) bool {
+	return *(**time.Time)(ptr) == nil
+}
+
+func (timePtrEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	t := *(**time.Time)(ptr)
+	if t == nil {
+		stream.WriteNil()
+		return
+	}
+	writeTime(*t, stream)
+}
+
+// writeTime 把时间按RFC3339Nano追加到编码缓冲区；年份超出[0,9999]时与MarshalJSON一样返回错误
+func writeTime(t time.Time, stream *jsoniter.Stream) {
+	if year := t.Year(); year < 0 || year > 9999 {
+		if _, err := t.MarshalJSON(); err != nil && stream.Error == nil {
+			stream.Error = err
+		}
+		return
+	}
+	buf := append(stream.Buffer(), '"')
+	buf = t.AppendFormat(buf, time.RFC3339Nano)
+	stream.SetBuffer(append(buf, '"'))
+}
+
+type objectIDEncoder struct{}
+
+func (objectIDEncoder) IsEmpty(ptr unsafe.Pointer) bool {
+	return false
+}
+
+func (objectIDEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	id := (*primitive.ObjectID)(ptr)
+	buf := append(stream.Buffer(), '"')
+	buf = hex.AppendEncode(buf, id[:])
+	stream.SetBuffer(append(buf, '"'))
+}
+
+type mapEncoder struct{}
+
+func (mapEncoder) IsEmpty(ptr unsafe.Pointer) bool {
+	return len(*(*map[string]interface{})(ptr)) == 0
+}
+
+func (mapEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	m := *(*map[string]interface{})(ptr)
+	if m == nil {
+		stream.WriteNil()
+		return
+	}
+	stream.WriteObjectStart()
+	first := true
+	for key, value := range m {
+		if !first {
+			stream.WriteMore()
+		}
+		first = false
+		stream.WriteStringWithHTMLEscaped(key)
+		stream.WriteRaw(":")
+		stream.WriteVal(value)
+	}
+	stream.WriteObjectEnd()
+}
+
+// writeJSON 编码并写出JSON响应，替代gin.Context.JSON
+func writeJSON(c *gin.Context, status int, obj interface{}) {
+	stream := responseJSON.BorrowStream(nil)
+	defer func() {
+		if cap(stream.Buffer()) <= maxPooledBufferSize {
+			responseJSON.ReturnStream(stream)
+		}
+	}()
+
+	stream.WriteVal(obj)
+	if stream.Error != nil {
+		// 编码失败时交给gin处理，与之前的行为保持一致
+		c.JSON(status, obj)
+		return
+	}
+
+	c.Data(status, jsonContentType, stream.Buffer())
+}
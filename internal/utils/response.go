@@ -1,12 +1,29 @@
 package utils
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	appErrors "exchange/internal/pkg/errors"
 	"exchange/internal/pkg/i18n"
+	appLogger "exchange/internal/pkg/logger"
+	"exchange/internal/pkg/metrics"
+	"exchange/internal/pkg/requestid"
+	"exchange/internal/pkg/tracing"
+)
+
+// databaseLogger 数据库访问失败的日志，按database类别统计错误率告警
+var databaseLogger = appLogger.Module("database")
+
+// errorResponsesTotal 按路由和响应消息键统计的失败响应数（如各接口的validation_failed）
+var errorResponsesTotal = metrics.NewCounterVec(
+	"exchange_error_responses_total",
+	"Failed API responses by route, response code and message key.",
+	"route", "code", "message_key",
 )
 
 // 错误码定义
@@ -42,13 +59,16 @@ func getLanguage(c *gin.Context) string {
 	return i18n.GetLanguageFromContext(c)
 }
 
-// getRequestID 获取请求ID
+// getRequestID 获取请求ID，Gin上下文中没有时从请求的context.Context中获取
 func getRequestID(c *gin.Context) string {
-	if requestID, exists := c.Get("request_id"); exists {
+	if requestID, exists := c.Get(requestid.ContextKey); exists {
 		if id, ok := requestID.(string); ok {
 			return id
 		}
 	}
+	if c.Request != nil {
+		return requestid.FromContext(c.Request.Context())
+	}
 	return ""
 }
 
@@ -58,6 +78,14 @@ func buildResponse(c *gin.Context, code int, messageKey string, data interface{}
 	lang := getLanguage(c)
 	message := i18nManager.Translate(lang, messageKey, templateData)
 
+	if code != CodeSuccess {
+		errorResponsesTotal.Inc(MetricsRoute(c), strconv.Itoa(code), messageKey)
+	}
+
+	// 记录响应码和消息键，供在处理器之后执行的中间件（如管理员操作审计）判断请求结果
+	c.Set(responseCodeKey, code)
+	c.Set(responseMessageKey, messageKey)
+
 	// 如果是错误响应，将错误详情包含在Data中
 	if code != CodeSuccess && templateData != nil {
 		if data == nil {
@@ -84,35 +112,145 @@ func buildResponse(c *gin.Context, code int, messageKey string, data interface{}
 // Success 成功响应
 func Success(c *gin.Context, data interface{}) {
 	response := buildResponse(c, CodeSuccess, "success", data, nil)
-	c.JSON(http.StatusOK, response)
+	writeJSON(c, http.StatusOK, &response)
 }
 
 // SuccessWithMessage 带自定义消息的成功响应
 func SuccessWithMessage(c *gin.Context, messageKey string, data interface{}, templateData map[string]interface{}) {
 	response := buildResponse(c, CodeSuccess, messageKey, data, templateData)
-	c.JSON(http.StatusOK, response)
+	writeJSON(c, http.StatusOK, &response)
 }
 
 // ErrorResponse 错误响应
 func ErrorResponse(c *gin.Context, messageKey string, templateData map[string]interface{}) {
 	response := buildResponse(c, CodeFailure, messageKey, nil, templateData)
-	c.JSON(http.StatusOK, response)
+	writeJSON(c, http.StatusOK, &response)
 }
 
 // ErrorWithData 带数据的错误响应
 func ErrorWithData(c *gin.Context, messageKey string, data interface{}, templateData map[string]interface{}) {
 	response := buildResponse(c, CodeFailure, messageKey, data, templateData)
-	c.JSON(http.StatusOK, response)
+	writeJSON(c, http.StatusOK, &response)
 }
 
 // ErrorWithNotFund 获取不到请求
 func ErrorWithNotFund(c *gin.Context, messageKey string, templateData map[string]interface{}) {
 	response := buildResponse(c, CodeFailure, messageKey, nil, templateData)
-	c.JSON(http.StatusBadRequest, response)
+	writeJSON(c, http.StatusBadRequest, &response)
+}
+
+// TooManyRequests 限流响应，HTTP状态码为429，调用方按需设置Retry-After
+func TooManyRequests(c *gin.Context, messageKey string, templateData map[string]interface{}) {
+	response := buildResponse(c, CodeFailure, messageKey, nil, templateData)
+	writeJSON(c, http.StatusTooManyRequests, &response)
+}
+
+// Forbidden 禁止访问响应，HTTP状态码为403
+func Forbidden(c *gin.Context, messageKey string, templateData map[string]interface{}) {
+	response := buildResponse(c, CodeForbidden, messageKey, nil, templateData)
+	writeJSON(c, http.StatusForbidden, &response)
 }
 
 // ErrorResponseWithAuth 认证错误响应
 func ErrorResponseWithAuth(c *gin.Context, messageKey string, templateData map[string]interface{}) {
 	response := buildResponse(c, CodeUnauthorized, messageKey, nil, templateData)
-	c.JSON(http.StatusOK, response)
+	writeJSON(c, http.StatusOK, &response)
+}
+
+// ErrorResponseFromError 根据错误返回错误响应
+// AppError为客户端可处理的分类（不存在、冲突、参数无效）时使用其消息键，否则使用fallbackKey；错误详情中附带错误码和上下文
+// 数据库错误和存储不可用的错误记录到database模块日志（熔断期间直接拒绝的请求不逐条记录，由熔断器记录状态变化）
+func ErrorResponseFromError(c *gin.Context, fallbackKey string, err error) {
+	details := ErrorDetails(err)
+	if appErr, ok := appErrors.GetAppError(err); ok && !errors.Is(err, appErrors.ErrCircuitOpen) && !errors.Is(err, appErrors.ErrMaintenance) &&
+		(appErr.Category == appErrors.CategoryDatabase || appErr.Category == appErrors.CategoryUnavailable) {
+		databaseLogger.WithContext(c.Request.Context()).Error("数据库访问失败", map[string]interface{}{
+			"path":    c.Request.URL.Path,
+			"method":  c.Request.Method,
+			"details": details,
+		})
+	}
+
+	// 严重级别达到配置的错误上报到错误跟踪服务（未启用时忽略），错误码和分类记录到请求span
+	appErrors.Report(err, ErrorRequestInfo(c))
+	appErrors.Observe(MetricsRoute(c), err)
+	tracing.RecordError(c.Request.Context(), err)
+
+	// 错误目录为该错误码配置了http_status时使用该状态码，否则与其他错误响应一样返回200
+	response := buildResponse(c, CodeFailure, ErrorMessageKey(err, fallbackKey), nil, details)
+	writeJSON(c, appErrors.ResponseStatus(err), &response)
+}
+
+// WriteErrorFromError 与ErrorResponseFromError相同（不记录数据库日志），但响应直接写到w，对c只读取不写入
+// 用于在处理器goroutine之外写出响应的场景（如请求处理超时），调用前需在处理器goroutine中执行过c.Query，
+// 使读取语言参数时不再初始化查询参数缓存
+func WriteErrorFromError(w http.ResponseWriter, c *gin.Context, fallbackKey string, err error) {
+	appErrors.Report(err, ErrorRequestInfo(c))
+	appErrors.Observe(MetricsRoute(c), err)
+	tracing.RecordError(c.Request.Context(), err)
+
+	response := buildResponse(c, CodeFailure, ErrorMessageKey(err, fallbackKey), nil, ErrorDetails(err))
+	body, marshalErr := responseJSON.Marshal(&response)
+	if marshalErr != nil {
+		body = []byte(`{"code":` + strconv.Itoa(CodeFailure) + `}`)
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(appErrors.ResponseStatus(err))
+	_, _ = w.Write(body)
+}
+
+// MetricsRoute 指标使用的路由标签，未匹配路由的请求（404）统一为unmatched，避免路径进入标签
+func MetricsRoute(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return "unmatched"
+}
+
+// ErrorRequestInfo 错误上报使用的请求信息
+// 只上报路径，查询参数中可能带有token（如WebSocket握手、签名下载链接），不发送到外部服务
+func ErrorRequestInfo(c *gin.Context) *appErrors.RequestInfo {
+	return &appErrors.RequestInfo{
+		Method:    c.Request.Method,
+		URL:       c.Request.URL.Path,
+		Route:     c.FullPath(),
+		RequestID: getRequestID(c),
+		UserID:    c.GetUint("user_id"),
+		ClientIP:  c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+// ErrorMessageKey 错误对应的响应消息键
+func ErrorMessageKey(err error, fallbackKey string) string {
+	appErr, ok := appErrors.GetAppError(err)
+	if !ok || appErr.MessageKey == "" {
+		return fallbackKey
+	}
+
+	switch appErr.Category {
+	case appErrors.CategoryNotFound, appErrors.CategoryConflict, appErrors.CategoryInvalid, appErrors.CategoryRateLimited:
+		return appErr.MessageKey
+	default:
+		return fallbackKey
+	}
+}
+
+// ErrorDetails 错误详情，AppError附带错误码、模块和上下文
+func ErrorDetails(err error) map[string]interface{} {
+	details := map[string]interface{}{"error": err.Error()}
+
+	appErr, ok := appErrors.GetAppError(err)
+	if !ok {
+		return details
+	}
+	for key, value := range appErr.Context {
+		details[key] = value
+	}
+	details["error_code"] = appErr.Code
+	if appErr.Module != "" {
+		details["module"] = appErr.Module
+	}
+	return details
 }
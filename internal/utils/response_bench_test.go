@@ -0,0 +1,99 @@
+package utils_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"exchange/internal/models/mongodb"
+	"exchange/internal/modules/api/dto"
+	"exchange/internal/utils"
+)
+
+// messageHistory 会话消息接口（GET /user/messages/:peer_id）默认大小的一页响应，
+// 包含已投递、已读、修改过的消息和附件元数据
+func messageHistory() dto.MessageHistoryResponse {
+	base := time.Date(2026, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	messages := make([]*mongodb.ChatMessage, 20)
+	for i := range messages {
+		createdAt := base.Add(-time.Duration(i) * time.Minute)
+		deliveredAt := createdAt.Add(time.Second)
+		message := &mongodb.ChatMessage{
+			ID:          primitive.NewObjectIDFromTimestamp(createdAt),
+			FromUserID:  "1001",
+			ToUserID:    "1002",
+			MessageType: mongodb.MessageTypeText,
+			Content:     "消息内容 " + strconv.Itoa(i) + " <b>hello</b>",
+			Metadata:    map[string]interface{}{"client_id": "c-" + strconv.Itoa(i), "platform": "ios"},
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
+			DeliveredAt: &deliveredAt,
+		}
+		if i%2 == 0 {
+			readAt := deliveredAt.Add(time.Minute)
+			message.ReadAt = &readAt
+			message.IsRead = true
+		}
+		if i%5 == 0 {
+			editedAt := createdAt.Add(30 * time.Second)
+			message.EditHistory = []mongodb.MessageEdit{{Content: "原内容", EditedAt: editedAt}}
+			message.EditedAt = &editedAt
+		}
+		message.FillStatus()
+		messages[i] = message
+	}
+	return dto.MessageHistoryResponse{
+		Messages:   messages,
+		NextCursor: "eyJ0IjoxNzY3MzIzMDQ1LCJpZCI6IjY1OWEifQ",
+		HasMore:    true,
+	}
+}
+
+// discardWriter 丢弃响应内容的ResponseWriter，只统计编码和写出的开销
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardWriter) WriteHeader(int)             {}
+
+func newBenchContext() *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(&discardWriter{header: make(http.Header)})
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/v1/user/messages/1002", nil)
+	return c
+}
+
+// BenchmarkSuccessMessageHistory utils.Success（池化的json-iterator编码流）写出会话消息
+func BenchmarkSuccessMessageHistory(b *testing.B) {
+	c := newBenchContext()
+	history := messageHistory()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		utils.Success(c, history)
+	}
+}
+
+// BenchmarkGinJSONMessageHistory gin.Context.JSON（encoding/json）写出同样的响应，替换前的实现（不含消息翻译）
+func BenchmarkGinJSONMessageHistory(b *testing.B) {
+	c := newBenchContext()
+	history := messageHistory()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.JSON(http.StatusOK, utils.APIResponse{
+			Code:      utils.CodeSuccess,
+			Message:   "success",
+			Data:      history,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}